@@ -16,6 +16,10 @@ type Config struct {
 	Authentication AuthenticationConfig `yaml:"authentication"`
 	Logging        LoggingConfig        `yaml:"logging"`
 	Metrics        MetricsConfig        `yaml:"metrics"`
+	CLI            CLIConfig            `yaml:"cli"`
+	Regions        RegionsConfig        `yaml:"regions"`
+	Compression    CompressionConfig    `yaml:"compression"`
+	Audit          AuditConfig          `yaml:"audit"`
 }
 
 type ServerConfig struct {
@@ -23,12 +27,20 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+
+	// Load balancers in front of the gateway whose X-Forwarded-For is
+	// believed. Empty means the TCP peer is the client.
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 type ServicesConfig struct {
 	Auth     ServiceConfig `yaml:"auth"`
 	Merchant ServiceConfig `yaml:"merchant"`
 	Payment  ServiceConfig `yaml:"payment"`
+
+	// gRPC-only services, probed by health checks (url is host:port)
+	Tokenization ServiceConfig `yaml:"tokenization"`
+	Transaction  ServiceConfig `yaml:"transaction"`
 }
 
 type ServiceConfig struct {
@@ -36,18 +48,28 @@ type ServiceConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+// RateLimitingConfig holds the token buckets checked before proxying.
+// Enabled turns on the global per-IP limit; endpoint limits always apply.
 type RateLimitingConfig struct {
 	Enabled   bool                  `yaml:"enabled"`
-	Storage   string                `yaml:"storage"`
+	Storage   string                `yaml:"storage"` // "memory" or "redis"
 	RedisURL  string                `yaml:"redis_url"`
 	Global    GlobalRateLimitConfig `yaml:"global"`
 	Endpoints []EndpointRateLimit   `yaml:"endpoints"`
+
+	// merchant-service answering API key lookups for by: "merchant" limits
+	LookupURL        string        `yaml:"lookup_url"`
+	InternalToken    string        `yaml:"internal_token"`
+	IdentityCacheTTL time.Duration `yaml:"identity_cache_ttl"`
 }
 
 type GlobalRateLimitConfig struct {
 	RequestsPerHour int `yaml:"requests_per_hour"`
 }
 
+// EndpointRateLimit is the limit of one route group. Pattern is a request
+// path, or a prefix when it ends in "/*". By picks the bucket: "ip",
+// "api_key" or "merchant".
 type EndpointRateLimit struct {
 	Pattern           string `yaml:"pattern"`
 	RequestsPerMinute int    `yaml:"requests_per_minute,omitempty"`
@@ -56,17 +78,53 @@ type EndpointRateLimit struct {
 	By                string `yaml:"by"`
 }
 
+// DefaultEndpointRateLimits apply when the config lists no endpoints
+var DefaultEndpointRateLimits = []EndpointRateLimit{
+	{Pattern: "/api/v1/auth/login", RequestsPerMinute: 5, By: "ip"},
+	{Pattern: "/api/v1/auth/register", RequestsPerHour: 3, By: "ip"},
+	{Pattern: "/api/v1/payments/*", RequestsPerSecond: 20, By: "api_key"},
+}
+
+// Limit returns the bucket size and the time it takes to refill
+func (e EndpointRateLimit) Limit() (int, time.Duration) {
+	switch {
+	case e.RequestsPerSecond > 0:
+		return e.RequestsPerSecond, time.Second
+	case e.RequestsPerMinute > 0:
+		return e.RequestsPerMinute, time.Minute
+	default:
+		return e.RequestsPerHour, time.Hour
+	}
+}
+
+// CircuitBreakerConfig holds one breaker per upstream service. Peer
+// regions get their own breaker with the settings of the same service.
 type CircuitBreakerConfig struct {
 	Enabled         bool                        `yaml:"enabled"`
 	AuthService     ServiceCircuitBreakerConfig `yaml:"auth_service"`
 	MerchantService ServiceCircuitBreakerConfig `yaml:"merchant_service"`
 	PaymentService  ServiceCircuitBreakerConfig `yaml:"payment_service"`
+	Retry           RetryConfig                 `yaml:"retry"`
 }
 
+// ServiceCircuitBreakerConfig opens the breaker after FailureThreshold
+// consecutive 5xx responses or failed calls, keeps it open for Timeout and
+// then lets HalfOpenRequests calls through at a time until SuccessThreshold
+// of them succeed
 type ServiceCircuitBreakerConfig struct {
 	FailureThreshold int           `yaml:"failure_threshold"`
 	Timeout          time.Duration `yaml:"timeout"`
 	SuccessThreshold int           `yaml:"success_threshold"`
+	HalfOpenRequests int           `yaml:"half_open_requests"` // default 1
+}
+
+// RetryConfig retries GET and HEAD requests that could not reach the
+// service or came back 502 or 503. Other methods are never retried, since
+// the service may already have acted on them.
+type RetryConfig struct {
+	MaxRetries int           `yaml:"max_retries"`
+	Backoff    time.Duration `yaml:"backoff"`     // before the first retry, doubled after each
+	MaxBackoff time.Duration `yaml:"max_backoff"` // cap on a single wait
 }
 
 type AuthenticationConfig struct {
@@ -97,6 +155,49 @@ type MetricsConfig struct {
 	Path    string `yaml:"path"`
 }
 
+// CompressionConfig gzips responses for clients that send Accept-Encoding
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	MinSize int  `yaml:"min_size"` // bytes; smaller bodies are sent as is
+}
+
+// AuditConfig records every proxied request, with card data and secrets
+// masked, for the internal audit endpoint
+type AuditConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Storage       string        `yaml:"storage"` // "memory" or "redis"
+	RedisURL      string        `yaml:"redis_url"`
+	Retention     time.Duration `yaml:"retention"`
+	MaxEntries    int           `yaml:"max_entries"`    // memory storage only
+	MaxBodyBytes  int           `yaml:"max_body_bytes"` // larger bodies are not recorded
+	InternalToken string        `yaml:"internal_token"` // guards GET /internal/v1/audit-logs
+}
+
+// CLIConfig advertises payment-cli releases to clients calling /version
+type CLIConfig struct {
+	MinVersion    string `yaml:"min_version"`
+	LatestVersion string `yaml:"latest_version"`
+	ReleaseURL    string `yaml:"release_url"`
+}
+
+// RegionsConfig pins a merchant's traffic to its home region. Merchants
+// homed in another region are proxied straight to that region's services.
+type RegionsConfig struct {
+	Enabled       bool                            `yaml:"enabled"`
+	Local         string                          `yaml:"local"`
+	LookupURL     string                          `yaml:"lookup_url"` // merchant-service answering region lookups
+	InternalToken string                          `yaml:"internal_token"`
+	CacheTTL      time.Duration                   `yaml:"cache_ttl"`
+	Peers         map[string]RegionServicesConfig `yaml:"peers"`
+}
+
+// RegionServicesConfig holds the HTTP service URLs of one peer region
+type RegionServicesConfig struct {
+	Auth     string `yaml:"auth"`
+	Merchant string `yaml:"merchant"`
+	Payment  string `yaml:"payment"`
+}
+
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/api-gateway/internal/service"
+)
+
+// AuditLogs lists recorded requests, newest first. Filters: merchant_id,
+// method, route (a route pattern or path prefix), status, from and to
+// (RFC 3339), limit (1-500, default 50) and cursor from the previous page.
+// GET /internal/v1/audit-logs
+func AuditLogs(auditLog *service.AuditLog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := service.AuditQuery{
+			MerchantID: c.Query("merchant_id"),
+			Method:     c.Query("method"),
+			Route:      c.Query("route"),
+			Before:     c.Query("cursor"),
+			Limit:      50,
+		}
+
+		if raw := c.Query("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > 500 {
+				badAuditQuery(c, "limit must be between 1 and 500")
+				return
+			}
+			query.Limit = limit
+		}
+		if raw := c.Query("status"); raw != "" {
+			status, err := strconv.Atoi(raw)
+			if err != nil || status < 100 || status > 599 {
+				badAuditQuery(c, "status must be an HTTP status code")
+				return
+			}
+			query.Status = status
+		}
+		for param, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+			if raw := c.Query(param); raw != "" {
+				parsed, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					badAuditQuery(c, param+" must be an RFC 3339 timestamp")
+					return
+				}
+				*target = parsed
+			}
+		}
+		if query.Before != "" && !service.ValidAuditCursor(query.Before) {
+			badAuditQuery(c, "invalid cursor")
+			return
+		}
+
+		entries, next, err := auditLog.Query(c.Request.Context(), query)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "audit store unavailable",
+				"code":    ErrCodeServiceUnavailable,
+			})
+			return
+		}
+		if entries == nil {
+			entries = []service.AuditEntry{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data": gin.H{
+				"entries":     entries,
+				"has_more":    next != "",
+				"next_cursor": next,
+			},
+		})
+	}
+}
+
+func badAuditQuery(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   message,
+	})
+}
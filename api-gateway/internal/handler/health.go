@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"net"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/api-gateway/internal/config"
@@ -14,12 +17,117 @@ func HealthCheck(cfg *config.Config, cb *service.CircuitBreaker) gin.HandlerFunc
 			"status":  "ok",
 			"service": "api-gateway",
 			"version": "1.0.0",
-			"services": gin.H{
-				"auth":     cb.GetState("auth").String(),
-				"merchant": cb.GetState("merchant").String(),
-				"payment":  cb.GetState("payment").String(),
-			},
+			"services": cb.States(),
 		}
 		c.JSON(http.StatusOK, health)
 	}
 }
+
+type serviceProbe struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ServicesHealthCheck probes every backend service concurrently.
+// HTTP services are checked on /health, gRPC services with a TCP dial.
+func ServicesHealthCheck(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		httpServices := map[string]config.ServiceConfig{
+			"auth":        cfg.Services.Auth,
+			"merchant":    cfg.Services.Merchant,
+			"payment-api": cfg.Services.Payment,
+		}
+		grpcServices := map[string]config.ServiceConfig{
+			"tokenization": cfg.Services.Tokenization,
+			"transaction":  cfg.Services.Transaction,
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		results := make(map[string]serviceProbe)
+
+		record := func(name string, probe serviceProbe) {
+			mu.Lock()
+			results[name] = probe
+			mu.Unlock()
+		}
+
+		for name, svc := range httpServices {
+			if svc.URL == "" {
+				continue
+			}
+			wg.Add(1)
+			go func(name string, svc config.ServiceConfig) {
+				defer wg.Done()
+				record(name, probeHTTP(svc))
+			}(name, svc)
+		}
+
+		for name, svc := range grpcServices {
+			if svc.URL == "" {
+				continue
+			}
+			wg.Add(1)
+			go func(name string, svc config.ServiceConfig) {
+				defer wg.Done()
+				record(name, probeTCP(svc))
+			}(name, svc)
+		}
+
+		wg.Wait()
+
+		status := "ok"
+		statusCode := http.StatusOK
+		for _, probe := range results {
+			if probe.Status != "up" {
+				status = "degraded"
+				statusCode = http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		c.JSON(statusCode, gin.H{
+			"status":     status,
+			"service":    "api-gateway",
+			"checked_at": time.Now().UTC(),
+			"services":   results,
+		})
+	}
+}
+
+func probeHTTP(svc config.ServiceConfig) serviceProbe {
+	client := &http.Client{Timeout: probeTimeout(svc)}
+
+	start := time.Now()
+	resp, err := client.Get(svc.URL + "/health")
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return serviceProbe{Status: "down", LatencyMs: latency, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return serviceProbe{Status: "down", LatencyMs: latency, Error: resp.Status}
+	}
+	return serviceProbe{Status: "up", LatencyMs: latency}
+}
+
+func probeTCP(svc config.ServiceConfig) serviceProbe {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", svc.URL, probeTimeout(svc))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return serviceProbe{Status: "down", LatencyMs: latency, Error: err.Error()}
+	}
+	conn.Close()
+	return serviceProbe{Status: "up", LatencyMs: latency}
+}
+
+// probeTimeout caps health probes so one slow service can't stall the check
+func probeTimeout(svc config.ServiceConfig) time.Duration {
+	if svc.Timeout <= 0 || svc.Timeout > 3*time.Second {
+		return 3 * time.Second
+	}
+	return svc.Timeout
+}
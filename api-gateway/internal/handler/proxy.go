@@ -2,9 +2,15 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,16 +18,14 @@ import (
 	"github.com/rhaloubi/api-gateway/internal/service"
 )
 
+// Error codes sent when the gateway answers for an upstream
+const (
+	ErrCodeServiceUnavailable = "service_unavailable"
+	ErrCodeUpstreamTimeout    = "upstream_timeout"
+)
+
 func ProxyRequest(cfg *config.Config, targetService string, cb *service.CircuitBreaker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := cb.Allow(targetService); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"success": false,
-				"error":   fmt.Sprintf("service temporarily unavailable: %s", targetService),
-			})
-			return
-		}
-
 		var serviceURL string
 		var timeout time.Duration
 
@@ -43,6 +47,14 @@ func ProxyRequest(cfg *config.Config, targetService string, cb *service.CircuitB
 			return
 		}
 
+		upstream := targetService
+		servedRegion := cfg.Regions.Local
+		if peerURL, region, ok := peerServiceURL(c, cfg, targetService); ok {
+			serviceURL = peerURL
+			servedRegion = region
+			upstream = service.Upstream(targetService, region)
+		}
+
 		targetURL := serviceURL + c.Request.URL.Path
 		if c.Request.URL.RawQuery != "" {
 			targetURL += "?" + c.Request.URL.RawQuery
@@ -54,46 +66,98 @@ func ProxyRequest(cfg *config.Config, targetService string, cb *service.CircuitB
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
 
-		proxyReq, err := http.NewRequest(c.Request.Method, targetURL, bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			cb.RecordFailure(targetService)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "failed to create proxy request",
-			})
-			return
+		maxRetries := 0
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			maxRetries = cfg.CircuitBreaker.Retry.MaxRetries
 		}
 
-		for key, values := range c.Request.Header {
-			for _, value := range values {
-				proxyReq.Header.Add(key, value)
-			}
+		// Redirects are the client's to follow, e.g. a hosted invoice link
+		// sending the browser to checkout
+		client := &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
 		}
+		start := time.Now()
+		var resp *http.Response
+		var err error
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				time.Sleep(retryBackoff(cfg.CircuitBreaker.Retry, attempt))
+			}
 
-		proxyReq.Header.Set("X-Forwarded-For", c.ClientIP())
-		proxyReq.Header.Set("X-Request-ID", c.GetString("request_id"))
+			if err = cb.Allow(upstream); err != nil {
+				var open *service.CircuitOpenError
+				if errors.As(err, &open) {
+					serviceUnavailable(c, targetService, open.RetryAfter)
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"error":   err.Error(),
+				})
+				return
+			}
 
-		client := &http.Client{Timeout: timeout}
-		start := time.Now()
-		resp, err := client.Do(proxyReq)
+			var proxyReq *http.Request
+			proxyReq, err = http.NewRequest(c.Request.Method, targetURL, bytes.NewReader(bodyBytes))
+			if err != nil {
+				cb.RecordFailure(upstream)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"error":   "failed to create proxy request",
+				})
+				return
+			}
+
+			for key, values := range c.Request.Header {
+				for _, value := range values {
+					proxyReq.Header.Add(key, value)
+				}
+			}
+
+			// The gateway compresses for the client, so the hop to the service
+			// stays plain and the transport can read it
+			proxyReq.Header.Del("Accept-Encoding")
+			proxyReq.Header.Set("X-Forwarded-For", c.ClientIP())
+			proxyReq.Header.Set("X-Request-ID", c.GetString("request_id"))
+
+			resp, err = client.Do(proxyReq)
+			if err != nil || resp.StatusCode >= 500 {
+				cb.RecordFailure(upstream)
+			} else {
+				cb.RecordSuccess(upstream)
+			}
+
+			if attempt >= maxRetries || !retryable(resp, err) {
+				break
+			}
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			log.Printf("Retrying %s %s on %s (attempt %d of %d)", c.Request.Method, c.Request.URL.Path, upstream, attempt+2, maxRetries+1)
+		}
 		duration := time.Since(start)
 
 		if err != nil {
-			cb.RecordFailure(targetService)
-			c.JSON(http.StatusBadGateway, gin.H{
-				"success": false,
-				"error":   fmt.Sprintf("service request failed: %v", err),
-			})
+			log.Printf("Proxy to %s failed: %v", upstream, err)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				c.JSON(http.StatusGatewayTimeout, gin.H{
+					"success": false,
+					"error":   fmt.Sprintf("%s service did not respond in time", targetService),
+					"code":    ErrCodeUpstreamTimeout,
+					"service": targetService,
+				})
+				return
+			}
+			serviceUnavailable(c, targetService, 0)
 			return
 		}
 		defer resp.Body.Close()
 
-		if resp.StatusCode >= 500 {
-			cb.RecordFailure(targetService)
-		} else {
-			cb.RecordSuccess(targetService)
-		}
-
 		respBody, err := io.ReadAll(resp.Body)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -110,6 +174,81 @@ func ProxyRequest(cfg *config.Config, targetService string, cb *service.CircuitB
 		}
 
 		c.Header("X-Gateway-Response-Time", fmt.Sprintf("%dms", duration.Milliseconds()))
+		if servedRegion != "" {
+			c.Header("X-Gateway-Region", servedRegion)
+		}
 		c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), respBody)
 	}
 }
+
+// serviceUnavailable is the gateway's 503 for an upstream it can't reach or
+// has cut off. retryAfter is left out of the response when unknown.
+func serviceUnavailable(c *gin.Context, targetService string, retryAfter time.Duration) {
+	body := gin.H{
+		"success": false,
+		"error":   fmt.Sprintf("service temporarily unavailable: %s", targetService),
+		"code":    ErrCodeServiceUnavailable,
+		"service": targetService,
+	}
+	if retryAfter > 0 {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		body["retry_after"] = seconds
+	}
+	c.JSON(http.StatusServiceUnavailable, body)
+}
+
+// retryable reports whether a GET may be sent again: the service could not
+// be reached, or answered that it is overloaded or its own upstream failed.
+// Timeouts are not retried, since the budget is already spent.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return !(errors.As(err, &netErr) && netErr.Timeout())
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// retryBackoff doubles the configured backoff for each attempt after the
+// first retry and adds up to 50% jitter
+func retryBackoff(retry config.RetryConfig, attempt int) time.Duration {
+	wait := retry.Backoff
+	if wait <= 0 {
+		wait = 100 * time.Millisecond
+	}
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+	}
+	if retry.MaxBackoff > 0 && wait > retry.MaxBackoff {
+		wait = retry.MaxBackoff
+	}
+	return wait + time.Duration(rand.Int64N(int64(wait)/2+1))
+}
+
+// peerServiceURL returns the service URL in the merchant's home region when
+// it is not the local one. Regions without a configured peer stay local.
+func peerServiceURL(c *gin.Context, cfg *config.Config, targetService string) (string, string, bool) {
+	region := c.GetString("region")
+	if region == "" || region == cfg.Regions.Local {
+		return "", "", false
+	}
+
+	peer, ok := cfg.Regions.Peers[region]
+	if !ok {
+		return "", "", false
+	}
+
+	var url string
+	switch targetService {
+	case "auth":
+		url = peer.Auth
+	case "merchant":
+		url = peer.Merchant
+	case "payment":
+		url = peer.Payment
+	}
+	if url == "" {
+		return "", "", false
+	}
+	return url, region, true
+}
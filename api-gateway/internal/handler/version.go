@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/api-gateway/internal/config"
+)
+
+// Version reports the gateway version and the supported payment-cli range.
+// The CLI warns when it is older than cli.min_version and points the user at
+// release_url to upgrade.
+func Version(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"service": "api-gateway",
+			"version": "1.0.0",
+			"cli": gin.H{
+				"min_version":    cfg.CLI.MinVersion,
+				"latest_version": cfg.CLI.LatestVersion,
+				"release_url":    cfg.CLI.ReleaseURL,
+			},
+		})
+	}
+}
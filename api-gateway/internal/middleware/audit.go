@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/api-gateway/internal/service"
+)
+
+// Audit records every request with its route, status, latency, merchant
+// and redacted JSON bodies. Bodies that aren't JSON, or are larger than
+// maxBodyBytes, are left out and only their size is kept. Query strings are
+// never recorded, and route parameters named like a token are masked in the
+// path.
+func Audit(auditLog *service.AuditLog, maxBodyBytes int) gin.HandlerFunc {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = 8192
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		var requestBody []byte
+		if c.Request.Body != nil {
+			requestBody, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer, limit: maxBodyBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		entry := service.AuditEntry{
+			Time:         start.UTC(),
+			RequestID:    c.GetString("request_id"),
+			MerchantID:   requestMerchantID(c),
+			ClientIP:     c.ClientIP(),
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Path:         auditPath(c),
+			Status:       c.Writer.Status(),
+			LatencyMs:    time.Since(start).Milliseconds(),
+			RequestSize:  len(requestBody),
+			ResponseSize: c.Writer.Size(),
+		}
+		if entry.ResponseSize < 0 {
+			entry.ResponseSize = 0
+		}
+		if isJSON(c.ContentType()) && len(requestBody) <= maxBodyBytes {
+			entry.RequestBody, _ = RedactJSON(requestBody)
+		}
+		if writer.captured && writer.body.Len() <= maxBodyBytes {
+			entry.ResponseBody, _ = RedactJSON(writer.body.Bytes())
+		}
+
+		auditLog.Record(entry, c.GetHeader("X-API-Key"))
+	}
+}
+
+// auditPath is the request path with token parameters masked
+func auditPath(c *gin.Context) string {
+	path := c.Request.URL.Path
+	for _, param := range c.Params {
+		if strings.Contains(param.Key, "token") && param.Value != "" {
+			path = strings.Replace(path, param.Value, redacted, 1)
+		}
+	}
+	return path
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
+
+// auditResponseWriter keeps a copy of JSON responses up to one byte past
+// the limit, enough to tell when a body is too large to record
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	checked  bool
+	captured bool
+}
+
+func (w *auditResponseWriter) capture(data []byte) {
+	if !w.checked {
+		w.checked = true
+		w.captured = isJSON(w.Header().Get("Content-Type"))
+	}
+	if !w.captured {
+		return
+	}
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		w.body.Write(data)
+	}
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressibleTypes are the response types worth compressing. Exports,
+// images and archives are already compressed or streamed.
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/yaml",
+	"text/",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	},
+}
+
+// Compress gzips responses for clients that accept it once the body
+// reaches minSize bytes. Smaller bodies go out as they are, since the gzip
+// header and the CPU would cost more than they save.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer, minSize: minSize, status: http.StatusOK}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+// acceptsGzip reads Accept-Encoding, honouring q=0 as a refusal
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// gzipResponseWriter holds back the first minSize bytes to decide whether
+// compressing is worth it, then either starts gzip or flushes them as is
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minSize int
+	status  int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.decided {
+		w.status = code
+	}
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	// Callers flushing headers early get them as soon as the decision is made
+}
+
+func (w *gzipResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *gzipResponseWriter) Written() bool {
+	return w.decided || w.buf.Len() > 0
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide picks the encoding from what the handler set and the bytes held
+// back so far, then writes them out
+func (w *gzipResponseWriter) decide() error {
+	w.decided = true
+	header := w.ResponseWriter.Header()
+
+	if w.buf.Len() >= w.minSize && header.Get("Content-Encoding") == "" &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified && isCompressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		if w.buf.Len() == 0 && w.status == http.StatusOK && !w.ResponseWriter.Written() {
+			// Nothing was written at all; leave the response to gin
+			return
+		}
+		w.decide()
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+func isCompressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
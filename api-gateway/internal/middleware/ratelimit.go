@@ -2,7 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,20 +14,65 @@ import (
 	"github.com/rhaloubi/api-gateway/internal/service"
 )
 
-func RateLimiter(limiter *service.RateLimiter, cfg *config.Config) gin.HandlerFunc {
+// RateLimiter checks the global per-IP limit, when enabled, and the limit
+// of every route group whose pattern matches the request. Responses carry
+// the X-RateLimit-* headers of whichever bucket has the fewest tokens left;
+// a request over any limit gets a 429 with Retry-After.
+func RateLimiter(limiter *service.RateLimiter, resolver *service.IdentityResolver, cfg *config.Config) gin.HandlerFunc {
+	endpoints := cfg.RateLimiting.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = config.DefaultEndpointRateLimits
+	}
+
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		allowed, remaining, resetTime := limiter.Allow(clientIP, cfg.RateLimiting.Global.RequestsPerHour, time.Hour)
+		var tightest *service.RateLimitDecision
+		var tightestName string
+		check := func(name, key string, limit int, window time.Duration) bool {
+			d := limiter.Allow(c.Request.Context(), key, limit, window)
+			if d.Limit == 0 {
+				return true
+			}
+			if tightest == nil || !d.Allowed || d.Remaining < tightest.Remaining {
+				tightest, tightestName = &d, name
+			}
+			return d.Allowed
+		}
 
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", cfg.RateLimiting.Global.RequestsPerHour))
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", resetTime.Unix()))
+		allowed := true
+		if cfg.RateLimiting.Enabled {
+			allowed = check("global", "global:ip:"+c.ClientIP(), cfg.RateLimiting.Global.RequestsPerHour, time.Hour)
+		}
+		for _, endpoint := range endpoints {
+			if !allowed {
+				break
+			}
+			if !matchesPattern(endpoint.Pattern, c.Request.URL.Path) {
+				continue
+			}
+			limit, window := endpoint.Limit()
+			identity := rateLimitIdentity(c, resolver, endpoint.By)
+			allowed = check(endpoint.Pattern, endpoint.Pattern+":"+endpoint.By+":"+identity, limit, window)
+		}
+
+		if tightest != nil {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(tightest.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(tightest.ResetAt.Unix(), 10))
+			if tightestName != "global" {
+				c.Header("X-RateLimit-Endpoint", tightestName)
+			}
+		}
 
 		if !allowed {
+			retryAfter := int(math.Ceil(tightest.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"success":     false,
-				"error":       "rate limit exceeded",
-				"retry_after": time.Until(resetTime).Seconds(),
+				"error":       rateLimitMessage(tightestName),
+				"retry_after": retryAfter,
 			})
 			c.Abort()
 			return
@@ -33,29 +82,57 @@ func RateLimiter(limiter *service.RateLimiter, cfg *config.Config) gin.HandlerFu
 	}
 }
 
-func EndpointRateLimit(limiter *service.RateLimiter, endpoint string, limit int, window time.Duration) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		identifier := c.ClientIP()
-		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
-			identifier = apiKey
+// ValidateEndpointRateLimits rejects route groups the limiter can't apply
+func ValidateEndpointRateLimits(endpoints []config.EndpointRateLimit) error {
+	for _, endpoint := range endpoints {
+		if endpoint.Pattern == "" {
+			return fmt.Errorf("rate limit endpoint without a pattern")
 		}
+		switch endpoint.By {
+		case "ip", "api_key", "merchant":
+		default:
+			return fmt.Errorf("rate limit %s: by must be ip, api_key or merchant", endpoint.Pattern)
+		}
+		if limit, _ := endpoint.Limit(); limit <= 0 {
+			return fmt.Errorf("rate limit %s: no requests_per_second, minute or hour", endpoint.Pattern)
+		}
+	}
+	return nil
+}
 
-		key := fmt.Sprintf("%s:%s", endpoint, identifier)
-		allowed, remaining, resetTime := limiter.Allow(key, limit, window)
-
-		c.Header("X-RateLimit-Endpoint", endpoint)
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+// rateLimitIdentity names the bucket a request draws from. API keys are
+// hashed so they never appear in Redis. Merchants come from the API key,
+// never from X-Merchant-ID, which the caller controls; requests whose
+// merchant can't be resolved fall back to the key, then the client IP.
+func rateLimitIdentity(c *gin.Context, resolver *service.IdentityResolver, by string) string {
+	apiKey := c.GetHeader("X-API-Key")
 
-		if !allowed {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success":     false,
-				"error":       fmt.Sprintf("rate limit exceeded for %s", endpoint),
-				"retry_after": time.Until(resetTime).Seconds(),
-			})
-			c.Abort()
-			return
+	if by == "merchant" && apiKey != "" && resolver != nil {
+		merchantID, err := resolver.MerchantForAPIKey(apiKey)
+		if err != nil {
+			log.Printf("⚠️  Rate limit merchant lookup failed: %v", err)
+		}
+		if merchantID != "" {
+			return "merchant:" + merchantID
 		}
+	}
 
-		c.Next()
+	if by != "ip" && apiKey != "" {
+		return "key:" + service.HashAPIKey(apiKey)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func matchesPattern(pattern, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return path == pattern
+}
+
+func rateLimitMessage(name string) string {
+	if name == "global" {
+		return "rate limit exceeded"
 	}
+	return fmt.Sprintf("rate limit exceeded for %s", name)
 }
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFields are dropped from logged bodies whatever their value
+var secretFields = map[string]bool{
+	"cvv":             true,
+	"cvc":             true,
+	"cvv2":            true,
+	"cvc2":            true,
+	"security_code":   true,
+	"pin":             true,
+	"password":        true,
+	"api_key":         true,
+	"plain_key":       true,
+	"client_secret":   true,
+	"secret":          true,
+	"access_token":    true,
+	"refresh_token":   true,
+	"card_token":      true,
+	"cres":            true,
+	"cardholder_name": true,
+}
+
+// accountFields hold card or bank account numbers; all but the last four
+// digits are masked
+var accountFields = map[string]bool{
+	"number":         true,
+	"card_number":    true,
+	"pan":            true,
+	"account_number": true,
+	"iban":           true,
+}
+
+// panPattern finds runs of 13-19 digits, optionally split by spaces or
+// dashes, that might be a card number in a field not listed above
+var panPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// RedactJSON returns body with card data, credentials and secrets masked,
+// or ok false when body isn't JSON and so can't be checked
+func RedactJSON(body []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return "", false
+	}
+
+	out, err := json.Marshal(redactValue("", data))
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func redactValue(field string, value interface{}) interface{} {
+	name := strings.ToLower(field)
+	_, isObject := value.(map[string]interface{})
+	if !isObject && (secretFields[name] || strings.HasSuffix(name, "_secret") || strings.HasSuffix(name, "_password")) {
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = redactValue(key, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(field, child)
+		}
+		return v
+	case string:
+		if accountFields[name] {
+			return maskDigits(v)
+		}
+		return panPattern.ReplaceAllStringFunc(v, func(match string) string {
+			if luhnValid(match) {
+				return maskDigits(match)
+			}
+			return match
+		})
+	case json.Number:
+		if accountFields[name] || luhnValid(v.String()) && len(v.String()) >= 13 {
+			return maskDigits(v.String())
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// maskDigits keeps the last four digits of a number
+func maskDigits(value string) string {
+	digits := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			digits = append(digits, value[i])
+		}
+	}
+	if len(digits) <= 4 {
+		return redacted
+	}
+	return "****" + string(digits[len(digits)-4:])
+}
+
+func luhnValid(value string) bool {
+	sum, count := 0, 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		count++
+	}
+	return count > 0 && sum%10 == 0
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/api-gateway/internal/service"
+)
+
+// RegionRouting resolves the home region of the merchant a request is for
+// and stores it as "region" for the proxy. The merchant comes from the
+// X-Merchant-ID header or the route's merchant parameter; requests without
+// one, or whose lookup fails, are served by the local region.
+func RegionRouting(resolver *service.RegionResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		merchantID := requestMerchantID(c)
+		if merchantID == "" {
+			c.Next()
+			return
+		}
+
+		region, err := resolver.Resolve(merchantID)
+		if err != nil {
+			log.Printf("region lookup for merchant %s failed, serving locally: %v", merchantID, err)
+			c.Next()
+			return
+		}
+
+		c.Set("region", region)
+		c.Next()
+	}
+}
+
+func requestMerchantID(c *gin.Context) string {
+	candidates := []string{c.GetHeader("X-Merchant-ID"), c.Param("merchant_id")}
+	// :id names the merchant only on merchant routes
+	if strings.HasPrefix(c.FullPath(), "/api/v1/merchants/:id") {
+		candidates = append(candidates, c.Param("id"))
+	}
+
+	for _, candidate := range candidates {
+		if id, err := uuid.Parse(candidate); err == nil {
+			return id.String()
+		}
+	}
+	return ""
+}
@@ -1,8 +1,6 @@
 package router
 
 import (
-	"time"
-
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/api-gateway/internal/config"
 	"github.com/rhaloubi/api-gateway/internal/handler"
@@ -21,40 +19,66 @@ func Setup(cfg *config.Config) *gin.Engine {
 	}
 
 	r := gin.New()
-	rateLimiter := service.NewRateLimiter(cfg)
+	// The client IP forwarded to services and used for rate limits must not
+	// come from a header the caller controls
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		panic("invalid server.trusted_proxies: " + err.Error())
+	}
+	if err := middleware.ValidateEndpointRateLimits(cfg.RateLimiting.Endpoints); err != nil {
+		panic("invalid rate_limiting.endpoints: " + err.Error())
+	}
+	identityResolver := service.NewIdentityResolver(cfg)
+	rateLimiter := middleware.RateLimiter(service.NewRateLimiter(cfg), identityResolver, cfg)
 	circuitBreaker := service.NewCircuitBreaker(cfg)
 
 	r.GET("/health", handler.HealthCheck(cfg, circuitBreaker))
+	r.GET("/health/services", handler.ServicesHealthCheck(cfg))
+	r.GET("/version", handler.Version(cfg))
 	// Global middleware
 	r.Use(middleware.Logger(cfg))
 	r.Use(middleware.Recovery())
 	r.Use(middleware.CORS())
 	r.Use(middleware.RequestID())
+	if cfg.Compression.Enabled {
+		minSize := cfg.Compression.MinSize
+		if minSize <= 0 {
+			minSize = 1024
+		}
+		r.Use(middleware.Compress(minSize))
+	}
 
 	// Health and metrics endpoints (no auth required)
 	r.GET("/metrics", handler.Metrics())
 
+	// Request audit trail, queried by operators with the internal token
+	if cfg.Audit.Enabled {
+		auditLog := service.NewAuditLog(cfg, identityResolver)
+		if cfg.Audit.InternalToken != "" {
+			r.GET("/internal/v1/audit-logs",
+				middleware.RequireInternalToken(cfg.Audit.InternalToken),
+				handler.AuditLogs(auditLog),
+			)
+		}
+		r.Use(middleware.Audit(auditLog, cfg.Audit.MaxBodyBytes))
+	}
+
+
 	// API routes with full middleware stack
 	api := r.Group("/api/v1")
 	{
-		// Apply global rate limiting
-		if cfg.RateLimiting.Enabled {
-			api.Use(middleware.RateLimiter(rateLimiter, cfg))
+		// Global and per route group rate limits
+		api.Use(rateLimiter)
+
+		// Pin merchant traffic to the merchant's home region
+		if cfg.Regions.Enabled {
+			api.Use(middleware.RegionRouting(service.NewRegionResolver(cfg)))
 		}
 
 		// Authentication routes (no auth required)
 		auth := api.Group("/auth")
 		{
-			// Special rate limits for auth endpoints
-			auth.POST("/register",
-				middleware.EndpointRateLimit(rateLimiter, "register", 3, time.Hour),
-				handler.ProxyRequest(cfg, "auth", circuitBreaker),
-			)
-
-			auth.POST("/login",
-				middleware.EndpointRateLimit(rateLimiter, "login", 5, time.Minute),
-				handler.ProxyRequest(cfg, "auth", circuitBreaker),
-			)
+			auth.POST("/register", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			auth.POST("/login", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 
 			auth.POST("/refresh", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 
@@ -62,6 +86,7 @@ func Setup(cfg *config.Config) *gin.Engine {
 			auth.POST("/logout", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 			auth.POST("/change-password", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 			auth.GET("/sessions", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			auth.DELETE("/account", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 
 		}
 
@@ -69,6 +94,7 @@ func Setup(cfg *config.Config) *gin.Engine {
 		roles := api.Group("/roles")
 		{
 			roles.GET("", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			roles.GET("/permissions", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 			roles.GET("/:id", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 			roles.POST("/assign", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 			roles.DELETE("/assign", handler.ProxyRequest(cfg, "auth", circuitBreaker))
@@ -76,6 +102,16 @@ func Setup(cfg *config.Config) *gin.Engine {
 			roles.GET("/user/:user_id/merchant/:merchant_id/permissions", handler.ProxyRequest(cfg, "auth", circuitBreaker))
 		}
 
+		// Support impersonation routes (JWT required)
+		impersonation := api.Group("/impersonation")
+		{
+			impersonation.POST("", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			impersonation.POST("/:id/end", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			impersonation.GET("/merchants/:merchant_id", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			impersonation.GET("/merchants/:merchant_id/settings", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+			impersonation.PUT("/merchants/:merchant_id/settings", handler.ProxyRequest(cfg, "auth", circuitBreaker))
+		}
+
 		// Merchant routes (JWT required)
 		merchants := api.Group("/merchants")
 		{
@@ -88,6 +124,7 @@ func Setup(cfg *config.Config) *gin.Engine {
 				merchantApiKeys.POST("", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 				merchantApiKeys.GET("/merchant/:merchant_id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 				merchantApiKeys.PATCH("/:merchant_id/:id/deactivate", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+				merchantApiKeys.PUT("/:merchant_id/:id/allowed-cidrs", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 				merchantApiKeys.DELETE("/:merchant_id/:id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			}
 
@@ -96,6 +133,9 @@ func Setup(cfg *config.Config) *gin.Engine {
 			merchants.GET("/:id/team", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			merchants.GET("/:id/invitations", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			merchants.GET("/:id/settings", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.GET("/:id/offboarding", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.GET("/:id/bank-accounts", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.GET("/:id/bank-accounts/:account_id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 
 			merchants.PUT("/:id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			merchants.PATCH("/:id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
@@ -103,9 +143,17 @@ func Setup(cfg *config.Config) *gin.Engine {
 			merchants.PATCH("/:id/team/:user_id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 
 			merchants.POST("/:id/team/invite", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/offboarding", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/bank-accounts", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/bank-accounts/:account_id/micro-deposits", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/bank-accounts/:account_id/micro-deposits/confirm", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/bank-accounts/:account_id/documents", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.POST("/:id/bank-accounts/:account_id/default", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 
 			merchants.DELETE("/:id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			merchants.DELETE("/:id/team/:user_id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.DELETE("/:id/offboarding", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
+			merchants.DELETE("/:id/bank-accounts/:account_id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 
 		}
 		// Invitation routes (JWT required)
@@ -114,38 +162,166 @@ func Setup(cfg *config.Config) *gin.Engine {
 			invitations.POST("/:token/accept", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 			invitations.DELETE("/:id", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 		}
+		api.GET("/onboarding/status", handler.ProxyRequest(cfg, "merchant", circuitBreaker))
 
 		// Payment routes (API Key required)
 		payments := api.Group("/payments")
-		payments.Use(middleware.EndpointRateLimit(rateLimiter, "payments", 20, time.Second))
 		{
 			payments.POST("/authorize", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.POST("/sale", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.POST("/:id/authenticate", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.POST("/:id/capture", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.POST("/:id/void", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.POST("/:id/refund", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.GET("/:id/full", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.GET("/:id/refunds", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.GET("/:id/notes", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.POST("/:id/notes", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.DELETE("/:id/notes/:note_id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.POST("/:id/tags", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			payments.DELETE("/:id/tags/:tag", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			payments.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 		}
+		refunds := api.Group("/refunds")
+		{
+			refunds.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			refunds.GET("/queue", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			refunds.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
 		transactions := api.Group("/transactions")
 		{
 			transactions.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			transactions.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			transactions.GET("/:id/notes", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			transactions.POST("/:id/notes", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			transactions.DELETE("/:id/notes/:note_id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			transactions.POST("/:id/tags", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			transactions.DELETE("/:id/tags/:tag", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		disputes := api.Group("/disputes")
+		{
+			disputes.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			disputes.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			disputes.POST("/:id/evidence-files", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			disputes.GET("/:id/evidence-files/:file_id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			disputes.POST("/:id/evidence", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			disputes.POST("/:id/accept", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 		}
 		paymentIntents := api.Group("/payment-intents")
 		{
 			paymentIntents.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			paymentIntents.POST("/:id/cancel", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 		}
+		webhookSubscriptions := api.Group("/webhook-subscriptions")
+		{
+			webhookSubscriptions.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.PATCH("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.DELETE("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.POST("/:id/rotate-secret", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			webhookSubscriptions.POST("/:id/verify", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		checkoutSessions := api.Group("/checkout-sessions")
+		{
+			checkoutSessions.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			checkoutSessions.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		invoices := api.Group("/invoices")
+		{
+			invoices.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.PUT("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.DELETE("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.GET("/:id/pdf", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.POST("/:id/finalize", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.POST("/:id/send", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.POST("/:id/void", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			invoices.POST("/:id/mark-uncollectible", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		checkoutSettings := api.Group("/checkout-settings")
+		{
+			checkoutSettings.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			checkoutSettings.PUT("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			checkoutSettings.GET("/redirect-secret", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			checkoutSettings.POST("/redirect-secret/rotate", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		fraudRules := api.Group("/fraud/rules")
+		{
+			fraudRules.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			fraudRules.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			fraudRules.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			fraudRules.PATCH("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			fraudRules.DELETE("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		holdRelease := api.Group("/hold-release-notifications")
+		{
+			holdRelease.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			holdRelease.PUT("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			holdRelease.PUT("/templates/:language", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			holdRelease.DELETE("/templates/:language", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		receiptTemplates := api.Group("/receipt-templates")
+		{
+			receiptTemplates.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			receiptTemplates.PUT("/:kind/:language", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			receiptTemplates.DELETE("/:kind/:language", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		cardTesting := api.Group("/card-testing")
+		{
+			cardTesting.GET("/incidents", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			cardTesting.POST("/incidents/:id/resolve", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		exports := api.Group("/exports")
+		{
+			exports.POST("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			exports.POST("/account", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			exports.GET("", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			exports.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		api.GET("/balance", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		settlements := api.Group("/settlements")
+		{
+			settlements.GET("/instant-payout", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			settlements.POST("/instant-payout", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		accounting := api.Group("/accounting")
+		{
+			accounting.GET("/mappings/:provider", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			accounting.PUT("/mappings/:provider", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			accounting.GET("/settlements/:id/journal", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			accounting.GET("/journal", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
+		reports := api.Group("/reports")
+		{
+			reports.GET("/summary", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			reports.GET("/timeseries", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
 
+		// Sandbox reset and vault seeding (test-mode API keys only)
+		api.POST("/test/reset", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		api.POST("/test/seed-vault", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 	}
 	public := r.Group("/api/public")
+	public.Use(rateLimiter)
 	{
 		intents := public.Group("/payment-intents")
 		{
+			intents.OPTIONS("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			intents.GET("/:id", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			intents.OPTIONS("/:id/confirm", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 			intents.POST("/:id/confirm", handler.ProxyRequest(cfg, "payment", circuitBreaker))
 		}
+		public.GET("/exports/:id/download", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+
+		hostedInvoices := public.Group("/invoices")
+		{
+			hostedInvoices.GET("/:token", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			hostedInvoices.GET("/:token/pdf", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+			hostedInvoices.GET("/:token/pay", handler.ProxyRequest(cfg, "payment", circuitBreaker))
+		}
 	}
 
 	return r
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/api-gateway/internal/config"
+)
+
+// AuditEntry is one proxied request. Bodies are redacted before they get
+// here; API keys are only kept as a hash.
+type AuditEntry struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	RequestID    string    `json:"request_id"`
+	MerchantID   string    `json:"merchant_id,omitempty"`
+	APIKeyHash   string    `json:"api_key_hash,omitempty"`
+	ClientIP     string    `json:"client_ip"`
+	Method       string    `json:"method"`
+	Route        string    `json:"route"`
+	Path         string    `json:"path"`
+	Status       int       `json:"status"`
+	LatencyMs    int64     `json:"latency_ms"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+	RequestSize  int       `json:"request_size"`
+	ResponseSize int       `json:"response_size"`
+}
+
+// AuditQuery filters entries. Before is the ID of the last entry of the
+// previous page.
+type AuditQuery struct {
+	MerchantID string
+	Method     string
+	Route      string
+	Status     int
+	From       time.Time
+	To         time.Time
+	Before     string
+	Limit      int
+}
+
+// AuditLog stores entries newest last and answers queries newest first.
+// With audit.storage "redis" entries go to a Redis stream, plus one stream
+// per merchant, trimmed to the retention window; otherwise they go to a
+// bounded in-memory ring that is lost on restart. Entries are written by a
+// background worker so the request never waits on the store.
+type AuditLog struct {
+	mu       sync.RWMutex
+	entries  []AuditEntry
+	lastID   string
+	config   *config.Config
+	redis    *redis.Client
+	resolver *IdentityResolver
+	queue    chan pendingAudit
+}
+
+type pendingAudit struct {
+	entry  AuditEntry
+	apiKey string
+}
+
+const auditStream = "audit:requests"
+
+// auditScanLimit bounds how many stored entries one query looks at
+const auditScanLimit = 5000
+
+func NewAuditLog(cfg *config.Config, resolver *IdentityResolver) *AuditLog {
+	a := &AuditLog{
+		config:   cfg,
+		resolver: resolver,
+		queue:    make(chan pendingAudit, 1024),
+	}
+
+	if cfg.Audit.Storage == "redis" {
+		opts, err := redis.ParseURL(cfg.Audit.RedisURL)
+		if err != nil {
+			log.Printf("⚠️  Invalid audit.redis_url, keeping audit logs in memory: %v", err)
+		} else {
+			a.redis = redis.NewClient(opts)
+		}
+	}
+
+	go a.run()
+	return a
+}
+
+func (a *AuditLog) retention() time.Duration {
+	if a.config.Audit.Retention > 0 {
+		return a.config.Audit.Retention
+	}
+	return 30 * 24 * time.Hour
+}
+
+// Record queues an entry. apiKey, when set, is resolved to its merchant by
+// the worker and replaces any merchant the caller claimed. Entries are
+// dropped when the queue is full.
+func (a *AuditLog) Record(entry AuditEntry, apiKey string) {
+	select {
+	case a.queue <- pendingAudit{entry: entry, apiKey: apiKey}:
+	default:
+		log.Printf("⚠️  Audit queue full, dropping entry for request %s", entry.RequestID)
+	}
+}
+
+func (a *AuditLog) run() {
+	for pending := range a.queue {
+		entry := pending.entry
+		if pending.apiKey != "" {
+			entry.APIKeyHash = HashAPIKey(pending.apiKey)
+			if a.resolver != nil {
+				if merchantID, _ := a.resolver.MerchantForAPIKey(pending.apiKey); merchantID != "" {
+					entry.MerchantID = merchantID
+				}
+			}
+		}
+
+		if a.redis != nil {
+			if err := a.appendRedis(entry); err != nil {
+				log.Printf("⚠️  Failed to write audit entry for request %s to Redis: %v", entry.RequestID, err)
+			}
+			continue
+		}
+		a.appendMemory(entry)
+	}
+}
+
+func (a *AuditLog) appendMemory(entry AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// IDs follow Redis stream IDs, <unix ms>-<sequence>, so cursors work the
+	// same with either store
+	ms := entry.Time.UnixMilli()
+	var seq int64
+	if lastMs, lastSeq, ok := parseStreamID(a.lastID); ok && lastMs >= ms {
+		ms, seq = lastMs, lastSeq+1
+	}
+	entry.ID = fmt.Sprintf("%d-%d", ms, seq)
+	a.lastID = entry.ID
+
+	a.entries = append(a.entries, entry)
+
+	maxEntries := a.config.Audit.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	cutoff := time.Now().Add(-a.retention())
+	drop := 0
+	for drop < len(a.entries) && (len(a.entries)-drop > maxEntries || a.entries[drop].Time.Before(cutoff)) {
+		drop++
+	}
+	a.entries = a.entries[drop:]
+}
+
+func (a *AuditLog) appendRedis(entry AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	minID := strconv.FormatInt(time.Now().Add(-a.retention()).UnixMilli(), 10)
+
+	pipe := a.redis.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: auditStream, MinID: minID, Approx: true, Values: []string{"entry", string(data)}})
+	if entry.MerchantID != "" {
+		merchantStream := auditStream + ":merchant:" + entry.MerchantID
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: merchantStream, MinID: minID, Approx: true, Values: []string{"entry", string(data)}})
+		// A merchant that stops sending traffic doesn't leave its stream behind
+		pipe.PExpire(ctx, merchantStream, a.retention())
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Query returns up to q.Limit matching entries, newest first, and the
+// cursor for the next page, or "" when there are no more
+func (a *AuditLog) Query(ctx context.Context, q AuditQuery) ([]AuditEntry, string, error) {
+	if a.redis != nil {
+		return a.queryRedis(ctx, q)
+	}
+	matches, next := a.queryMemory(q)
+	return matches, next, nil
+}
+
+func (a *AuditLog) queryMemory(q AuditQuery) ([]AuditEntry, string) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	cutoff := time.Now().Add(-a.retention())
+	var matches []AuditEntry
+	for i := len(a.entries) - 1; i >= 0; i-- {
+		entry := a.entries[i]
+		if entry.Time.Before(cutoff) {
+			break
+		}
+		if q.Before != "" && !streamIDBefore(entry.ID, q.Before) {
+			continue
+		}
+		if matchesAudit(entry, q) {
+			matches = append(matches, entry)
+			if len(matches) == q.Limit {
+				return matches, entry.ID
+			}
+		}
+	}
+	return matches, ""
+}
+
+func (a *AuditLog) queryRedis(ctx context.Context, q AuditQuery) ([]AuditEntry, string, error) {
+	stream := auditStream
+	if q.MerchantID != "" {
+		stream = auditStream + ":merchant:" + q.MerchantID
+	}
+
+	end := "+"
+	if !q.To.IsZero() {
+		end = strconv.FormatInt(q.To.UnixMilli(), 10)
+	}
+	if q.Before != "" {
+		end = "(" + q.Before
+	}
+	start := "-"
+	if !q.From.IsZero() {
+		start = strconv.FormatInt(q.From.UnixMilli(), 10)
+	}
+
+	var matches []AuditEntry
+	scanned := 0
+	for scanned < auditScanLimit {
+		page, err := a.redis.XRevRangeN(ctx, stream, end, start, 200).Result()
+		if err != nil {
+			return nil, "", err
+		}
+		for _, message := range page {
+			scanned++
+			raw, _ := message.Values["entry"].(string)
+			var entry AuditEntry
+			if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+				continue
+			}
+			entry.ID = message.ID
+			if matchesAudit(entry, q) {
+				matches = append(matches, entry)
+				if len(matches) == q.Limit {
+					return matches, message.ID, nil
+				}
+			}
+		}
+		if len(page) < 200 {
+			return matches, "", nil
+		}
+		end = "(" + page[len(page)-1].ID
+	}
+
+	// Too many entries didn't match; let the caller carry on from here
+	return matches, strings.TrimPrefix(end, "("), nil
+}
+
+func matchesAudit(entry AuditEntry, q AuditQuery) bool {
+	if q.MerchantID != "" && entry.MerchantID != q.MerchantID {
+		return false
+	}
+	if q.Method != "" && !strings.EqualFold(entry.Method, q.Method) {
+		return false
+	}
+	if q.Route != "" && entry.Route != q.Route && !strings.HasPrefix(entry.Path, q.Route) {
+		return false
+	}
+	if q.Status != 0 && entry.Status != q.Status {
+		return false
+	}
+	if !q.From.IsZero() && entry.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && entry.Time.After(q.To) {
+		return false
+	}
+	return true
+}
+
+func parseStreamID(id string) (int64, int64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	ms, err1 := strconv.ParseInt(msPart, 10, 64)
+	seq, err2 := strconv.ParseInt(seqPart, 10, 64)
+	return ms, seq, err1 == nil && err2 == nil
+}
+
+// ValidAuditCursor reports whether cursor is an entry ID
+func ValidAuditCursor(cursor string) bool {
+	_, _, ok := parseStreamID(cursor)
+	return ok
+}
+
+func streamIDBefore(id, cursor string) bool {
+	ms, seq, _ := parseStreamID(id)
+	cursorMs, cursorSeq, _ := parseStreamID(cursor)
+	return ms < cursorMs || (ms == cursorMs && seq < cursorSeq)
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +31,20 @@ func (s CircuitState) String() string {
 	}
 }
 
+// CircuitOpenError is returned by Allow while an upstream is cut off.
+// RetryAfter is how long until the breaker lets a trial request through.
+type CircuitOpenError struct {
+	Upstream   string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for service: %s", e.Upstream)
+}
+
+// CircuitBreaker tracks one circuit per upstream. An upstream is a service
+// name ("payment"), or a service in a peer region ("payment@eu-west-1"),
+// so a dead peer doesn't cut off the local service.
 type CircuitBreaker struct {
 	mu       sync.RWMutex
 	circuits map[string]*Circuit
@@ -39,6 +55,7 @@ type Circuit struct {
 	state           CircuitState
 	failures        int
 	successes       int
+	probes          int // trial requests in flight while half-open
 	lastFailureTime time.Time
 	lastStateChange time.Time
 	config          config.ServiceCircuitBreakerConfig
@@ -50,31 +67,72 @@ func NewCircuitBreaker(cfg *config.Config) *CircuitBreaker {
 		config:   cfg,
 	}
 
-	cb.circuits["auth"] = &Circuit{
-		state:  StateClosed,
-		config: cfg.CircuitBreaker.AuthService,
+	for _, service := range []string{"auth", "merchant", "payment"} {
+		cb.circuits[service] = cb.newCircuit(service)
+	}
+
+	return cb
+}
+
+// Upstream names the circuit for a service, in region when it is a peer
+func Upstream(service, region string) string {
+	if region == "" {
+		return service
+	}
+	return service + "@" + region
+}
+
+func (cb *CircuitBreaker) newCircuit(upstream string) *Circuit {
+	service, _, _ := strings.Cut(upstream, "@")
+
+	var settings config.ServiceCircuitBreakerConfig
+	switch service {
+	case "auth":
+		settings = cb.config.CircuitBreaker.AuthService
+	case "merchant":
+		settings = cb.config.CircuitBreaker.MerchantService
+	case "payment":
+		settings = cb.config.CircuitBreaker.PaymentService
 	}
-	cb.circuits["merchant"] = &Circuit{
-		state:  StateClosed,
-		config: cfg.CircuitBreaker.MerchantService,
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
 	}
-	cb.circuits["payment"] = &Circuit{
-		state:  StateClosed,
-		config: cfg.CircuitBreaker.PaymentService,
+	if settings.SuccessThreshold <= 0 {
+		settings.SuccessThreshold = 1
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
 	}
 
-	return cb
+	return &Circuit{state: StateClosed, config: settings}
 }
 
-func (cb *CircuitBreaker) Allow(service string) error {
+func (cb *CircuitBreaker) circuit(upstream string) *Circuit {
 	cb.mu.RLock()
-	circuit, exists := cb.circuits[service]
+	circuit, exists := cb.circuits[upstream]
 	cb.mu.RUnlock()
+	if exists {
+		return circuit
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if circuit, exists = cb.circuits[upstream]; !exists {
+		circuit = cb.newCircuit(upstream)
+		cb.circuits[upstream] = circuit
+	}
+	return circuit
+}
 
-	if !exists {
-		return fmt.Errorf("circuit not found for service: %s", service)
+// Allow reports whether a request may go to upstream. Every allowed request
+// must be followed by RecordSuccess or RecordFailure.
+func (cb *CircuitBreaker) Allow(upstream string) error {
+	if !cb.config.CircuitBreaker.Enabled {
+		return nil
 	}
 
+	circuit := cb.circuit(upstream)
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -82,73 +140,98 @@ func (cb *CircuitBreaker) Allow(service string) error {
 	case StateClosed:
 		return nil
 	case StateOpen:
-		if time.Since(circuit.lastStateChange) > circuit.config.Timeout {
-			circuit.state = StateHalfOpen
-			circuit.successes = 0
-			return nil
+		if wait := circuit.config.Timeout - time.Since(circuit.lastStateChange); wait > 0 {
+			return &CircuitOpenError{Upstream: upstream, RetryAfter: wait}
 		}
-		return fmt.Errorf("circuit breaker open for service: %s", service)
+		cb.transition(upstream, circuit, StateHalfOpen)
+		circuit.probes = 1
+		return nil
 	case StateHalfOpen:
+		if circuit.probes >= circuit.config.HalfOpenRequests {
+			return &CircuitOpenError{Upstream: upstream, RetryAfter: time.Second}
+		}
+		circuit.probes++
 		return nil
 	default:
 		return fmt.Errorf("unknown circuit state")
 	}
 }
 
-func (cb *CircuitBreaker) RecordSuccess(service string) {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	circuit, exists := cb.circuits[service]
-	if !exists {
+func (cb *CircuitBreaker) RecordSuccess(upstream string) {
+	if !cb.config.CircuitBreaker.Enabled {
 		return
 	}
 
+	circuit := cb.circuit(upstream)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
 	switch circuit.state {
 	case StateClosed:
 		circuit.failures = 0
 	case StateHalfOpen:
+		circuit.probes = max(0, circuit.probes-1)
 		circuit.successes++
 		if circuit.successes >= circuit.config.SuccessThreshold {
-			circuit.state = StateClosed
-			circuit.failures = 0
-			circuit.successes = 0
-			circuit.lastStateChange = time.Now()
+			cb.transition(upstream, circuit, StateClosed)
 		}
 	}
 }
 
-func (cb *CircuitBreaker) RecordFailure(service string) {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	circuit, exists := cb.circuits[service]
-	if !exists {
+func (cb *CircuitBreaker) RecordFailure(upstream string) {
+	if !cb.config.CircuitBreaker.Enabled {
 		return
 	}
 
+	circuit := cb.circuit(upstream)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
 	circuit.lastFailureTime = time.Now()
 	circuit.failures++
 
 	switch circuit.state {
 	case StateClosed:
 		if circuit.failures >= circuit.config.FailureThreshold {
-			circuit.state = StateOpen
-			circuit.lastStateChange = time.Now()
+			cb.transition(upstream, circuit, StateOpen)
 		}
 	case StateHalfOpen:
-		circuit.state = StateOpen
-		circuit.successes = 0
-		circuit.lastStateChange = time.Now()
+		cb.transition(upstream, circuit, StateOpen)
+	}
+}
+
+// transition moves a circuit to state; callers hold cb.mu
+func (cb *CircuitBreaker) transition(upstream string, circuit *Circuit, state CircuitState) {
+	log.Printf("Circuit breaker for %s: %s → %s (%d consecutive failures)", upstream, circuit.state, state, circuit.failures)
+	circuit.state = state
+	circuit.lastStateChange = time.Now()
+	circuit.successes = 0
+	circuit.probes = 0
+	if state == StateClosed {
+		circuit.failures = 0
 	}
 }
 
-func (cb *CircuitBreaker) GetState(service string) CircuitState {
+func (cb *CircuitBreaker) GetState(upstream string) CircuitState {
 	cb.mu.RLock()
 	defer cb.mu.RUnlock()
 
-	if circuit, exists := cb.circuits[service]; exists {
+	if circuit, exists := cb.circuits[upstream]; exists {
 		return circuit.state
 	}
 	return StateClosed
 }
+
+// States returns the state of every upstream seen so far
+func (cb *CircuitBreaker) States() map[string]string {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	states := make(map[string]string, len(cb.circuits))
+	for upstream, circuit := range cb.circuits {
+		states[upstream] = circuit.state.String()
+	}
+	return states
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rhaloubi/api-gateway/internal/config"
+)
+
+// IdentityResolver looks up the merchant behind an API key from
+// merchant-service, so limits can be shared by all of a merchant's keys.
+// Answers are cached by key hash, including keys that resolve to nothing.
+// Failed lookups are remembered briefly so an outage doesn't put a slow
+// call in front of every request.
+type IdentityResolver struct {
+	mu      sync.RWMutex
+	entries map[string]identityEntry
+	config  *config.Config
+	client  *http.Client
+}
+
+type identityEntry struct {
+	merchantID string
+	expiresAt  time.Time
+}
+
+func NewIdentityResolver(cfg *config.Config) *IdentityResolver {
+	return &IdentityResolver{
+		entries: make(map[string]identityEntry),
+		config:  cfg,
+		client:  &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+// HashAPIKey is how API keys appear in cache and bucket keys
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+// MerchantForAPIKey returns the merchant an API key belongs to, or "" when
+// the key is unknown
+func (r *IdentityResolver) MerchantForAPIKey(apiKey string) (string, error) {
+	hash := HashAPIKey(apiKey)
+
+	r.mu.RLock()
+	entry, ok := r.entries[hash]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.merchantID, nil
+	}
+
+	merchantID, lookupErr := r.lookup(apiKey)
+
+	ttl := r.config.RateLimiting.IdentityCacheTTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	if lookupErr != nil {
+		ttl = min(ttl, 30*time.Second)
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	for key, e := range r.entries {
+		if now.After(e.expiresAt) {
+			delete(r.entries, key)
+		}
+	}
+	r.entries[hash] = identityEntry{merchantID: merchantID, expiresAt: now.Add(ttl)}
+	r.mu.Unlock()
+
+	return merchantID, lookupErr
+}
+
+func (r *IdentityResolver) lookup(apiKey string) (string, error) {
+	baseURL := r.config.RateLimiting.LookupURL
+	if baseURL == "" {
+		baseURL = r.config.Services.Merchant.URL
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/internal/v1/api-keys/resolve", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Internal-Token", r.config.RateLimiting.InternalToken)
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("api key lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("api key lookup returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			MerchantID string `json:"merchant_id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode api key lookup: %w", err)
+	}
+
+	return body.Data.MerchantID, nil
+}
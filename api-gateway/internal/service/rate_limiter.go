@@ -1,65 +1,161 @@
 package service
 
 import (
+	"context"
+	"log"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rhaloubi/api-gateway/internal/config"
 )
 
+// RateLimiter hands out tokens from per-key buckets. A bucket holds up to
+// limit tokens and refills at limit per window, so clients may burst to the
+// limit and then settle at the average rate. Buckets live in Redis when
+// rate_limiting.storage is "redis", which shares them between gateway
+// replicas; otherwise, and whenever Redis fails, they live in memory.
 type RateLimiter struct {
-	mu      sync.RWMutex
+	mu      sync.Mutex
 	buckets map[string]*bucket
 	config  *config.Config
+	redis   *redis.Client
 }
 
 type bucket struct {
-	tokens     int
+	tokens     float64
 	lastRefill time.Time
-	limit      int
 	window     time.Duration
 }
 
+// RateLimitDecision is the outcome of taking a token
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time     // when the bucket is full again
+	RetryAfter time.Duration // until the next token, when not allowed
+}
+
 func NewRateLimiter(cfg *config.Config) *RateLimiter {
 	rl := &RateLimiter{
 		buckets: make(map[string]*bucket),
 		config:  cfg,
 	}
+
+	if cfg.RateLimiting.Storage == "redis" {
+		opts, err := redis.ParseURL(cfg.RateLimiting.RedisURL)
+		if err != nil {
+			log.Printf("⚠️  Invalid rate_limiting.redis_url, using in-memory rate limits: %v", err)
+		} else {
+			rl.redis = redis.NewClient(opts)
+		}
+	}
+
 	go rl.cleanup()
 	return rl
 }
 
-func (rl *RateLimiter) Allow(key string, limit int, window time.Duration) (bool, int, time.Time) {
+// Allow takes one token from the bucket under key
+func (rl *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) RateLimitDecision {
+	if limit <= 0 {
+		return RateLimitDecision{Allowed: true}
+	}
+
+	var tokens float64
+	var allowed bool
+	if rl.redis != nil {
+		var err error
+		tokens, allowed, err = rl.takeRedis(ctx, key, limit, window)
+		if err != nil {
+			log.Printf("⚠️  Redis rate limit failed, using in-memory bucket: %v", err)
+			tokens, allowed = rl.takeMemory(key, limit, window)
+		}
+	} else {
+		tokens, allowed = rl.takeMemory(key, limit, window)
+	}
+
+	perToken := window / time.Duration(limit)
+	decision := RateLimitDecision{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   time.Now().Add(time.Duration((float64(limit) - tokens) * float64(perToken))),
+	}
+	if !allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+	return decision
+}
+
+func (rl *RateLimiter) takeMemory(key string, limit int, window time.Duration) (float64, bool) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	now := time.Now()
 	b, exists := rl.buckets[key]
 	if !exists {
-		b = &bucket{
-			tokens:     limit,
-			lastRefill: time.Now(),
-			limit:      limit,
-			window:     window,
-		}
+		b = &bucket{tokens: float64(limit), lastRefill: now}
 		rl.buckets[key] = b
 	}
+	b.window = window
 
-	now := time.Now()
-	if now.Sub(b.lastRefill) >= b.window {
-		b.tokens = b.limit
-		b.lastRefill = now
-	}
+	refill := now.Sub(b.lastRefill).Seconds() * float64(limit) / window.Seconds()
+	b.tokens = math.Min(float64(limit), b.tokens+refill)
+	b.lastRefill = now
 
-	if b.tokens > 0 {
-		b.tokens--
-		resetTime := b.lastRefill.Add(b.window)
-		return true, b.tokens, resetTime
+	if b.tokens < 1 {
+		return b.tokens, false
 	}
+	b.tokens--
+	return b.tokens, true
+}
+
+// tokenBucketScript refills and takes from a bucket atomically. It reads
+// the clock from Redis so replicas with drifting clocks agree.
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
 
-	resetTime := b.lastRefill.Add(b.window)
-	return false, 0, resetTime
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or limit
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(limit, tokens + math.max(0, now - ts) * limit / window_ms)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], window_ms)
+return {allowed, tostring(tokens)}
+`)
+
+func (rl *RateLimiter) takeRedis(ctx context.Context, key string, limit int, window time.Duration) (float64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	res, err := tokenBucketScript.Run(ctx, rl.redis, []string{"ratelimit:" + key}, limit, window.Milliseconds()).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	allowed, _ := res[0].(int64)
+	raw, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return tokens, allowed == 1, nil
 }
 
+// cleanup drops buckets that have had time to refill completely, since a
+// full bucket is the same as no bucket
 func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -68,7 +164,7 @@ func (rl *RateLimiter) cleanup() {
 		rl.mu.Lock()
 		now := time.Now()
 		for key, b := range rl.buckets {
-			if now.Sub(b.lastRefill) > 1*time.Hour {
+			if now.Sub(b.lastRefill) > b.window {
 				delete(rl.buckets, key)
 			}
 		}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rhaloubi/api-gateway/internal/config"
+)
+
+// RegionResolver looks up a merchant's home region from merchant-service and
+// caches the answer, since a merchant's region only changes on migration
+type RegionResolver struct {
+	mu      sync.RWMutex
+	entries map[string]regionEntry
+	config  *config.Config
+	client  *http.Client
+}
+
+type regionEntry struct {
+	region    string
+	expiresAt time.Time
+}
+
+func NewRegionResolver(cfg *config.Config) *RegionResolver {
+	return &RegionResolver{
+		entries: make(map[string]regionEntry),
+		config:  cfg,
+		client:  &http.Client{Timeout: cfg.Services.Merchant.Timeout},
+	}
+}
+
+// Resolve returns the home region of a merchant
+func (r *RegionResolver) Resolve(merchantID string) (string, error) {
+	r.mu.RLock()
+	entry, ok := r.entries[merchantID]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.region, nil
+	}
+
+	region, err := r.lookup(merchantID)
+	if err != nil {
+		return "", err
+	}
+
+	ttl := r.config.Regions.CacheTTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	r.mu.Lock()
+	r.entries[merchantID] = regionEntry{region: region, expiresAt: time.Now().Add(ttl)}
+	r.mu.Unlock()
+
+	return region, nil
+}
+
+func (r *RegionResolver) lookup(merchantID string) (string, error) {
+	baseURL := r.config.Regions.LookupURL
+	if baseURL == "" {
+		baseURL = r.config.Services.Merchant.URL
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/internal/v1/merchants/%s/region", baseURL, merchantID), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Internal-Token", r.config.Regions.InternalToken)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("region lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("region lookup returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Region string `json:"region"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode region lookup: %w", err)
+	}
+	if body.Data.Region == "" {
+		return "", fmt.Errorf("merchant %s has no region", merchantID)
+	}
+
+	return body.Data.Region, nil
+}
@@ -14,9 +14,12 @@ import (
 	"github.com/rhaloubi/payment-gateway/auth-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/auth-service/internal/api"
 	"github.com/rhaloubi/payment-gateway/auth-service/internal/handler"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/metrics"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/repository"
 	"github.com/rhaloubi/payment-gateway/auth-service/internal/util"
 	pb "github.com/rhaloubi/payment-gateway/auth-service/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 func init() {
@@ -33,16 +36,43 @@ func init() {
 func main() {
 	defer logger.Sync()
 
-	// Initialize gRPC server and register service
-	grpcServer := util.InitGRPC()
-	pb.RegisterRoleServiceServer(grpcServer, handler.NewGRPCRoleService())
-	pb.RegisterAPIKeyServiceServer(grpcServer, handler.NewGRPCAPIKeyService())
+	// Initialize gRPC server and register services. grpc.health.v1 reports
+	// NOT_SERVING while Postgres or Redis is unreachable.
+	var readiness *util.Readiness
+	grpcServer := util.InitGRPC(func(s *grpc.Server) {
+		pb.RegisterRoleServiceServer(s, handler.NewGRPCRoleService())
+		pb.RegisterAPIKeyServiceServer(s, handler.NewGRPCAPIKeyService())
+		pb.RegisterTokenServiceServer(s, handler.NewGRPCTokenService())
+		readiness = util.RegisterHealth(s)
+	})
+	readinessCtx, stopReadiness := context.WithCancel(context.Background())
+	defer stopReadiness()
+	go readiness.Run(readinessCtx)
+
+	// Prometheus metrics are served only when METRICS_PORT is set
+	if metricsPort := config.GetEnv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Log.Error("Metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
 
 	httpServer := &http.Server{
 		Addr:    ":" + config.GetEnv("PORT"),
 		Handler: inits.R,
 	}
 
+	// Persist sessions to Postgres in the background
+	writerCtx, stopWriter := context.WithCancel(context.Background())
+	writerDone := make(chan struct{})
+	go func() {
+		repository.StartSessionWriter(writerCtx)
+		close(writerDone)
+	}()
+
 	// Run HTTP server in goroutine
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -73,12 +103,19 @@ func main() {
 	// Shutdown gRPC server
 	if grpcServer != nil {
 		logger.Log.Info("🧹 Stopping gRPC server...")
+		stopReadiness()
+		readiness.Shutdown()
 		grpcServer.GracefulStop()
 	}
 
 	// Wait for HTTP goroutine to finish
 	wg.Wait()
 
+	// Flush queued session writes
+	stopWriter()
+	<-writerDone
+	logger.Log.Info("🧹 Session writes flushed.")
+
 	// Close Redis connection
 	if err := inits.RDB.Close(); err != nil {
 		logger.Log.Error("Error closing Redis", zap.Error(err))
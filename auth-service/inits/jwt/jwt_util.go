@@ -21,9 +21,18 @@ type JWTClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Type   string `json:"type"` // "access" or "refresh"
+
+	// Set only on impersonation tokens
+	ImpersonatorID  string `json:"impersonator_id,omitempty"`
+	ImpersonationID string `json:"impersonation_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IsImpersonated reports whether the token was minted for a support user
+func (c *JWTClaims) IsImpersonated() bool {
+	return c.ImpersonatorID != ""
+}
+
 // NewJWTUtil creates a new JWT utility
 func NewJWTUtil() *JWTUtil {
 	secretKey := config.GetEnv("JWT_SECRET_KEY")
@@ -54,6 +63,28 @@ func (u *JWTUtil) GenerateAccessToken(userID uuid.UUID, email string) (string, e
 	return token.SignedString([]byte(u.secretKey))
 }
 
+// GenerateImpersonationToken generates a short-lived access token that acts as
+// userID but carries the impersonator's identity. No refresh token is issued.
+func (u *JWTUtil) GenerateImpersonationToken(userID uuid.UUID, email string, impersonatorID, impersonationID uuid.UUID, ttl time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:          userID.String(),
+		Email:           email,
+		Type:            "access",
+		ImpersonatorID:  impersonatorID.String(),
+		ImpersonationID: impersonationID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "payment-gateway",
+			Subject:   userID.String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(u.secretKey))
+}
+
 // GenerateRefreshToken generates a new refresh token
 func (u *JWTUtil) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	claims := JWTClaims{
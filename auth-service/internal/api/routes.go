@@ -11,6 +11,7 @@ func Routes() {
 	r := inits.R
 	authHandler := handler.NewAuthHandler()
 	roleHandler := handler.NewRoleHandler()
+	impersonationHandler := handler.NewImpersonationHandler()
 
 	// Define your routes here
 	r.GET("/health", func(c *gin.Context) {
@@ -35,18 +36,31 @@ func Routes() {
 		{
 			authProtected.GET("/profile", authHandler.GetProfile)
 			authProtected.POST("/logout", authHandler.Logout)
-			authProtected.POST("/change-password", authHandler.ChangePassword)
+			authProtected.POST("/change-password", middleware.DenyImpersonation(), authHandler.ChangePassword)
 			authProtected.GET("/sessions", authHandler.GetSessions)
+			authProtected.DELETE("/account", middleware.DenyImpersonation(), authHandler.DeleteAccount)
 		}
 		roles := v1.Group("/roles")
 		roles.Use(middleware.AuthMiddleware())
 		{
 			roles.GET("", roleHandler.GetAllRoles)
+			roles.GET("/permissions", roleHandler.GetPermissionMatrix)
 			roles.GET("/:id", roleHandler.GetRoleByID)
-			//roles.POST("/assign", roleHandler.AssignRoleToUser)
-			//roles.DELETE("/assign", roleHandler.RemoveRoleFromUser)
+			roles.POST("/assign", roleHandler.AssignRoleToUser)
+			roles.DELETE("/assign", roleHandler.RemoveRoleFromUser)
 			roles.GET("/user/:user_id/merchant/:merchant_id", roleHandler.GetUserRoles)
 			roles.GET("/user/:user_id/merchant/:merchant_id/permissions", roleHandler.GetUserPermissions)
 		}
+
+		// Support impersonation (tokens minted here cannot manage impersonation)
+		impersonation := v1.Group("/impersonation")
+		impersonation.Use(middleware.AuthMiddleware(), middleware.DenyImpersonation())
+		{
+			impersonation.POST("", impersonationHandler.StartImpersonation)
+			impersonation.POST("/:id/end", impersonationHandler.EndImpersonation)
+			impersonation.GET("/merchants/:merchant_id", impersonationHandler.ListMerchantImpersonations)
+			impersonation.GET("/merchants/:merchant_id/settings", impersonationHandler.GetMerchantSetting)
+			impersonation.PUT("/merchants/:merchant_id/settings", impersonationHandler.UpdateMerchantSetting)
+		}
 	}
 }
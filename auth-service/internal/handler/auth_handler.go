@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -34,6 +35,10 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
 
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
@@ -268,6 +273,56 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
+// DeleteAccount anonymizes and deletes the authenticated user's account
+// DELETE /api/v1/auth/account
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "unauthorized",
+		})
+		return
+	}
+	parsedUserID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid user ID format",
+		})
+		return
+	}
+
+	// Call service
+	err = h.authService.DeleteAccount(parsedUserID, req.Password, c.ClientIP())
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, service.ErrSoleMerchantOwner) {
+			statusCode = http.StatusConflict
+		}
+		c.JSON(statusCode, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Account deleted successfully",
+	})
+}
+
 // GetSessions gets all active sessions for the user
 // GET /api/v1/auth/sessions
 func (h *AuthHandler) GetSessions(c *gin.Context) {
@@ -33,10 +33,16 @@ func (s *GRPCAPIKeyService) CreateAPIKey(ctx context.Context, req *pb.CreateAPIK
 		return nil, status.Error(codes.InvalidArgument, "invalid created_by")
 	}
 
+	if _, err := service.NormalizeCIDRs(req.AllowedCidrs); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	resp, err := s.apiKeyService.CreateAPIKey(&service.CreateAPIKeyRequest{
-		MerchantID: merchantID,
-		Name:       req.Name,
-		CreatedBy:  createdBy,
+		MerchantID:   merchantID,
+		Name:         req.Name,
+		CreatedBy:    createdBy,
+		AllowedCIDRs: req.AllowedCidrs,
+		TestMode:     req.TestMode,
 	})
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -71,12 +77,13 @@ func (s *GRPCAPIKeyService) GetMerchantAPIKeys(ctx context.Context, req *pb.GetM
 		}
 
 		protoAPIKeys = append(protoAPIKeys, &pb.APIKey{
-			Id:         key.ID.String(),
-			Name:       key.Name,
-			KeyPrefix:  key.KeyPrefix,
-			IsActive:   key.IsActive,
-			LastUsedAt: lastUsedAt,
-			CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+			Id:           key.ID.String(),
+			Name:         key.Name,
+			KeyPrefix:    key.KeyPrefix,
+			IsActive:     key.IsActive,
+			LastUsedAt:   lastUsedAt,
+			CreatedAt:    key.CreatedAt.Format(time.RFC3339),
+			AllowedCidrs: key.AllowedCIDRList(),
 		})
 	}
 
@@ -142,10 +149,39 @@ func (s *GRPCAPIKeyService) GetInfoByAPIKey(ctx context.Context, req *pb.GetInfo
 	}
 
 	return &pb.GetInfoByAPIKeyResponse{
-		Id:         resp.ID.String(),
-		Name:       resp.Name,
-		MerchantId: resp.MerchantID.String(),
-		CreatedAt:  resp.CreatedAt.Format(time.RFC3339),
-		Message:    "API key info retrieved successfully",
+		Id:           resp.ID.String(),
+		Name:         resp.Name,
+		MerchantId:   resp.MerchantID.String(),
+		CreatedAt:    resp.CreatedAt.Format(time.RFC3339),
+		Message:      "API key info retrieved successfully",
+		AllowedCidrs: resp.AllowedCIDRList(),
+		CreatedBy:    resp.CreatedBy.String(),
+	}, nil
+}
+
+func (s *GRPCAPIKeyService) UpdateAPIKeyAllowedCIDRs(ctx context.Context, req *pb.UpdateAPIKeyAllowedCIDRsRequest) (*pb.UpdateAPIKeyAllowedCIDRsResponse, error) {
+	keyID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid merchant_id")
+	}
+
+	// Check if merchant has that api-key
+	if !s.apiKeyService.CheckMerchantApikey(merchantID, keyID) {
+		return nil, status.Error(codes.PermissionDenied, "merchant does not have that api-key")
+	}
+
+	allowedCIDRs, err := s.apiKeyService.UpdateAllowedCIDRs(keyID, req.AllowedCidrs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &pb.UpdateAPIKeyAllowedCIDRsResponse{
+		AllowedCidrs: allowedCIDRs,
+		Message:      "API key IP allowlist updated successfully",
 	}, nil
 }
@@ -6,6 +6,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/auth-service/internal/service"
 	pb "github.com/rhaloubi/payment-gateway/auth-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type GRPCRoleService struct {
@@ -121,3 +123,51 @@ func (s *GRPCRoleService) AssignRoleToUser(ctx context.Context, req *pb.AssignRo
 		Message:    "Role assigned successfully",
 	}, nil
 }
+
+// BatchCheckPermissions implements the gRPC method
+func (s *GRPCRoleService) BatchCheckPermissions(ctx context.Context, req *pb.BatchCheckPermissionsRequest) (*pb.BatchCheckPermissionsResponse, error) {
+	userID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid merchant_id")
+	}
+
+	checks := make([]service.PermissionCheck, len(req.Checks))
+	for i, c := range req.Checks {
+		checks[i] = service.PermissionCheck{Resource: c.Resource, Action: c.Action}
+	}
+
+	result, err := s.roleService.BatchCheckPermissions(userID, merchantID, checks)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check permissions")
+	}
+
+	results := make([]*pb.PermissionCheckResult, len(checks))
+	for i, c := range checks {
+		results[i] = &pb.PermissionCheckResult{
+			Resource: c.Resource,
+			Action:   c.Action,
+			Allowed:  result.Allowed[i],
+		}
+	}
+
+	resp := &pb.BatchCheckPermissionsResponse{
+		UserId:     userID.String(),
+		MerchantId: merchantID.String(),
+		Results:    results,
+		Version:    result.Version,
+	}
+
+	// ETag-style revalidation: skip the full permission list if unchanged
+	if req.KnownVersion != 0 && req.KnownVersion == result.Version {
+		resp.NotModified = true
+	} else {
+		resp.Permissions = result.Permissions
+	}
+
+	return resp, nil
+}
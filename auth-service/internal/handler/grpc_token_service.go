@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/auth-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCTokenService struct {
+	pb.UnimplementedTokenServiceServer
+	introspectionService *service.TokenIntrospectionService
+}
+
+func NewGRPCTokenService() *GRPCTokenService {
+	return &GRPCTokenService{
+		introspectionService: service.NewTokenIntrospectionService(),
+	}
+}
+
+// Introspect implements the gRPC method. Unusable tokens are not an error:
+// they come back with active = false and the reason.
+func (s *GRPCTokenService) Introspect(ctx context.Context, req *pb.IntrospectRequest) (*pb.IntrospectResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	merchantID := uuid.Nil
+	if req.MerchantId != "" {
+		parsed, err := uuid.Parse(req.MerchantId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid merchant_id")
+		}
+		merchantID = parsed
+	}
+
+	record, err := s.introspectionService.Introspect(req.Token, merchantID)
+	if err != nil {
+		if errors.Is(err, service.ErrTokenInvalid) ||
+			errors.Is(err, service.ErrSessionRevoked) ||
+			errors.Is(err, service.ErrUserInactive) {
+			return &pb.IntrospectResponse{
+				Active: false,
+				Reason: err.Error(),
+			}, nil
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	roles := make([]*pb.MerchantRole, len(record.Roles))
+	for i, r := range record.Roles {
+		roles[i] = &pb.MerchantRole{
+			MerchantId: r.MerchantID,
+			RoleId:     r.RoleID,
+			RoleName:   r.RoleName,
+		}
+	}
+
+	return &pb.IntrospectResponse{
+		Active:             true,
+		UserId:             record.UserID,
+		Email:              record.Email,
+		MerchantIds:        record.MerchantIDs,
+		Roles:              roles,
+		Scopes:             record.Scopes,
+		PermissionsVersion: record.PermissionsVersion,
+		ExpiresAt:          record.ExpiresAt.Format(time.RFC3339),
+		ImpersonatorId:     record.ImpersonatorID,
+		EmailVerified:      record.EmailVerified,
+	}, nil
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/service"
+)
+
+// ImpersonationHandler handles support impersonation requests
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+	roleService          *service.RoleService
+}
+
+// NewImpersonationHandler creates a new impersonation handler
+func NewImpersonationHandler() *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: service.NewImpersonationService(),
+		roleService:          service.NewRoleService(),
+	}
+}
+
+type StartImpersonationRequest struct {
+	UserID          string `json:"user_id" binding:"required,uuid"`
+	MerchantID      string `json:"merchant_id" binding:"required,uuid"`
+	Reason          string `json:"reason" binding:"required"`
+	DurationMinutes int    `json:"duration_minutes" binding:"omitempty,min=1,max=120"`
+}
+
+type UpdateImpersonationSettingRequest struct {
+	Allowed *bool `json:"allowed" binding:"required"`
+}
+
+// StartImpersonation mints an impersonation token (platform admins only)
+// POST /api/v1/impersonation
+func (h *ImpersonationHandler) StartImpersonation(c *gin.Context) {
+	var req StartImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Call service
+	resp, err := h.impersonationService.Start(&service.StartImpersonationRequest{
+		ImpersonatorID: userID,
+		TargetUserID:   uuid.MustParse(req.UserID),
+		MerchantID:     uuid.MustParse(req.MerchantID),
+		Reason:         req.Reason,
+		Duration:       time.Duration(req.DurationMinutes) * time.Minute,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"impersonation": resp.Session,
+			"access_token":  resp.AccessToken,
+			"token_type":    "Bearer",
+			"expires_in":    resp.ExpiresIn,
+			"impersonated":  true,
+		},
+		"message": "Impersonation session started",
+	})
+}
+
+// EndImpersonation ends an impersonation session and revokes its token
+// POST /api/v1/impersonation/:id/end
+func (h *ImpersonationHandler) EndImpersonation(c *gin.Context) {
+	impersonationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid impersonation ID",
+		})
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.impersonationService.End(impersonationID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Impersonation session ended",
+	})
+}
+
+// ListMerchantImpersonations lists impersonation sessions on a merchant
+// GET /api/v1/impersonation/merchants/:merchant_id
+func (h *ImpersonationHandler) ListMerchantImpersonations(c *gin.Context) {
+	merchantID, ok := h.authorizeMerchant(c, "users", "read")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.impersonationService.ListForMerchant(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to fetch impersonation sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"sessions": sessions,
+			"count":    len(sessions),
+		},
+	})
+}
+
+// GetMerchantSetting returns whether a merchant allows impersonation
+// GET /api/v1/impersonation/merchants/:merchant_id/settings
+func (h *ImpersonationHandler) GetMerchantSetting(c *gin.Context) {
+	merchantID, ok := h.authorizeMerchant(c, "settings", "read")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.impersonationService.IsAllowed(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to fetch impersonation setting",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id": merchantID,
+			"allowed":     allowed,
+		},
+	})
+}
+
+// UpdateMerchantSetting lets a merchant opt in or out of impersonation
+// PUT /api/v1/impersonation/merchants/:merchant_id/settings
+func (h *ImpersonationHandler) UpdateMerchantSetting(c *gin.Context) {
+	var req UpdateImpersonationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	merchantID, ok := h.authorizeMerchant(c, "settings", "update")
+	if !ok {
+		return
+	}
+
+	userID, _ := h.currentUserID(c)
+	if err := h.impersonationService.SetAllowed(merchantID, userID, *req.Allowed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to update impersonation setting",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id": merchantID,
+			"allowed":     *req.Allowed,
+		},
+		"message": "Impersonation setting updated",
+	})
+}
+
+// currentUserID reads the authenticated user ID, writing a 401 if missing
+func (h *ImpersonationHandler) currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "unauthorized",
+		})
+		return uuid.Nil, false
+	}
+	parsedUserID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid user ID format",
+		})
+		return uuid.Nil, false
+	}
+	return parsedUserID, true
+}
+
+// authorizeMerchant parses :merchant_id and checks the caller's permission in it
+func (h *ImpersonationHandler) authorizeMerchant(c *gin.Context, resource, action string) (uuid.UUID, bool) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return uuid.Nil, false
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	allowed, err := h.roleService.HasPermission(userID, merchantID, resource, action)
+	if err != nil || !allowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "insufficient permissions",
+		})
+		return uuid.Nil, false
+	}
+	return merchantID, true
+}
@@ -47,6 +47,26 @@ func (h *RoleHandler) GetAllRoles(c *gin.Context) {
 	})
 }
 
+// GetPermissionMatrix gets all roles with the permissions each one grants
+// GET /api/v1/roles/permissions
+func (h *RoleHandler) GetPermissionMatrix(c *gin.Context) {
+	matrix, err := h.roleService.GetPermissionMatrix()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to fetch permission matrix",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"roles": matrix,
+		},
+	})
+}
+
 // GetRoleByID gets a role by ID with its permissions
 // GET /api/v1/roles/:id
 func (h *RoleHandler) GetRoleByID(c *gin.Context) {
@@ -99,6 +119,15 @@ func (h *RoleHandler) AssignRoleToUser(c *gin.Context) {
 		return
 	}
 
+	// Only members allowed to manage team roles may assign them
+	if !h.canManageRoles(uuid.MustParse(assignedBy.(string)), uuid.MustParse(req.MerchantID)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "insufficient permissions",
+		})
+		return
+	}
+
 	// Call service
 	err := h.roleService.AssignRoleToUser(
 		uuid.MustParse(req.UserID),
@@ -133,6 +162,23 @@ func (h *RoleHandler) RemoveRoleFromUser(c *gin.Context) {
 		return
 	}
 
+	removedBy, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "unauthorized",
+		})
+		return
+	}
+
+	if !h.canManageRoles(uuid.MustParse(removedBy.(string)), uuid.MustParse(req.MerchantID)) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "insufficient permissions",
+		})
+		return
+	}
+
 	// Call service
 	err := h.roleService.RemoveRoleFromUser(
 		uuid.MustParse(req.UserID),
@@ -199,3 +245,9 @@ func (h *RoleHandler) GetUserPermissions(c *gin.Context) {
 		},
 	})
 }
+
+// canManageRoles checks the caller holds users:update in the merchant
+func (h *RoleHandler) canManageRoles(userID, merchantID uuid.UUID) bool {
+	allowed, err := h.roleService.HasPermission(userID, merchantID, "users", "update")
+	return err == nil && allowed
+}
@@ -6,7 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/auth-service/internal/service"
+	"go.uber.org/zap"
 )
 
 // AuthMiddleware validates JWT tokens
@@ -37,7 +39,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Validate token
-		user, err := authService.ValidateToken(token)
+		user, claims, err := authService.ValidateToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"success": false,
@@ -51,6 +53,41 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("user_id", user.ID.String())
 
+		if !claims.IsImpersonated() {
+			c.Next()
+			return
+		}
+
+		// Impersonated request: flag it and leave an audit trail
+		c.Set("impersonator_id", claims.ImpersonatorID)
+		c.Set("impersonation_id", claims.ImpersonationID)
+		c.Header("X-Impersonated-By", claims.ImpersonatorID)
+
+		c.Next()
+
+		logger.Log.Info("Impersonated action",
+			zap.String("impersonation_id", claims.ImpersonationID),
+			zap.String("impersonator_id", claims.ImpersonatorID),
+			zap.String("user_id", user.ID.String()),
+			zap.String("action", c.Request.Method+" "+c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.String("ip", c.ClientIP()),
+		)
+	}
+}
+
+// DenyImpersonation blocks impersonation tokens from sensitive account actions
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonated := c.Get("impersonator_id"); impersonated {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "action not allowed while impersonating",
+			})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -82,6 +119,20 @@ func APIKeyMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if !key.IsIPAllowed(c.ClientIP()) {
+			logger.Log.Warn("API key used from IP outside allowlist",
+				zap.String("api_key_id", key.ID.String()),
+				zap.String("merchant_id", key.MerchantID.String()),
+				zap.String("ip", c.ClientIP()),
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "request IP is not allowed for this API key",
+			})
+			c.Abort()
+			return
+		}
+
 		// Set merchant info in context
 		c.Set("merchant_id", key.MerchantID.String())
 		c.Set("api_key_id", key.ID.String())
@@ -96,8 +147,8 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 		// Get user ID and merchant ID from context
-		userID, exists := c.Get("user_id")
-		if !exists {
+		userID, ok := contextUUID(c, "user_id")
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"success": false,
 				"error":   "unauthorized",
@@ -106,8 +157,8 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 			return
 		}
 
-		merchantID, exists := c.Get("merchant_id")
-		if !exists {
+		merchantID, ok := contextUUID(c, "merchant_id")
+		if !ok {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success": false,
 				"error":   "merchant_id required",
@@ -117,12 +168,7 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 		}
 
 		// Check permission
-		hasPermission, err := roleService.HasPermission(
-			userID.(uuid.UUID),
-			merchantID.(uuid.UUID),
-			resource,
-			action,
-		)
+		hasPermission, err := roleService.HasPermission(userID, merchantID, resource, action)
 
 		if err != nil || !hasPermission {
 			c.JSON(http.StatusForbidden, gin.H{
@@ -136,3 +182,22 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// contextUUID reads an ID set by an earlier middleware. IDs are stored as
+// strings; a uuid.UUID is accepted too. Missing or malformed values return false.
+func contextUUID(c *gin.Context, key string) (uuid.UUID, bool) {
+	v, exists := c.Get(key)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	switch id := v.(type) {
+	case uuid.UUID:
+		return id, id != uuid.Nil
+	case string:
+		parsed, err := uuid.Parse(id)
+		return parsed, err == nil
+	default:
+		return uuid.Nil, false
+	}
+}
@@ -28,6 +28,12 @@ func RunAuthMigrations() error {
 		&model.RolePermission{},
 		&model.Session{},
 		&model.APIKey{},
+		&model.AccountDeletion{},
+		&model.ImpersonationSession{},
+		&model.ImpersonationSetting{},
+		&model.KeyCeremony{},
+		&model.KeyCeremonyApproval{},
+		&model.KeyCeremonyEvent{},
 	}
 
 	for _, m := range models {
@@ -232,6 +238,12 @@ func RollbackAuthMigrations() error {
 	db := inits.DB
 	// Drop tables in reverse order
 	models := []interface{}{
+		&model.KeyCeremonyEvent{},
+		&model.KeyCeremonyApproval{},
+		&model.KeyCeremony{},
+		&model.ImpersonationSetting{},
+		&model.ImpersonationSession{},
+		&model.AccountDeletion{},
 		&model.APIKey{},
 		&model.Session{},
 		&model.RolePermission{},
@@ -2,6 +2,8 @@ package model
 
 import (
 	"database/sql"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +23,9 @@ type APIKey struct {
 	IsActive  bool         `gorm:"default:true;index"`
 	ExpiresAt sql.NullTime `gorm:"type:timestamp;index"`
 
+	// Network restrictions
+	AllowedCIDRs string `gorm:"column:allowed_cidrs;type:text"` // Comma-separated CIDR ranges, empty = any IP
+
 	// Usage tracking
 	LastUsedAt sql.NullTime `gorm:"type:timestamp"`
 
@@ -47,3 +52,35 @@ func (a *APIKey) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AllowedCIDRList returns the configured CIDR ranges
+func (a *APIKey) AllowedCIDRList() []string {
+	if strings.TrimSpace(a.AllowedCIDRs) == "" {
+		return []string{}
+	}
+	return strings.Split(a.AllowedCIDRs, ",")
+}
+
+// IsIPAllowed checks if the IP falls inside the key's allowlist
+func (a *APIKey) IsIPAllowed(ip string) bool {
+	cidrs := a.AllowedCIDRList()
+	if len(cidrs) == 0 {
+		return true
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
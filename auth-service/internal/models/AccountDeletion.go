@@ -0,0 +1,38 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountDeletion is the compliance record kept after a user deletes their account.
+// The original email is only stored as a SHA-256 hash.
+type AccountDeletion struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	EmailHash string         `gorm:"type:varchar(64);not null;index"`
+	IPAddress sql.NullString `gorm:"type:varchar(45)"`
+
+	// What was cleaned up
+	RevokedAPIKeys    int64 `gorm:"default:0"`
+	DetachedMerchants int   `gorm:"default:0"`
+
+	DeletedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for AccountDeletion
+func (AccountDeletion) TableName() string {
+	return "account_deletions"
+}
+
+// BeforeCreate hook
+func (d *AccountDeletion) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
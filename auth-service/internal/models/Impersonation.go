@@ -0,0 +1,59 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationSession records a support user acting as a merchant user.
+// The token itself is backed by a regular Session row so it can be revoked.
+type ImpersonationSession struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ImpersonatorID uuid.UUID `gorm:"type:uuid;not null;index"`
+	TargetUserID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	MerchantID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	SessionID      uuid.UUID `gorm:"type:uuid;not null"`
+
+	Reason    string         `gorm:"type:text;not null"`
+	IPAddress sql.NullString `gorm:"type:varchar(45)"`
+
+	ExpiresAt time.Time    `gorm:"not null"`
+	EndedAt   sql.NullTime `gorm:"type:timestamp"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for ImpersonationSession
+func (ImpersonationSession) TableName() string {
+	return "impersonation_sessions"
+}
+
+// BeforeCreate hook
+func (s *ImpersonationSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive checks if the impersonation session is still usable
+func (s *ImpersonationSession) IsActive() bool {
+	return !s.EndedAt.Valid && time.Now().Before(s.ExpiresAt)
+}
+
+// ImpersonationSetting stores a merchant's impersonation opt-out.
+// Merchants without a row allow impersonation.
+type ImpersonationSetting struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Allowed    bool      `gorm:"not null;default:true"`
+	UpdatedBy  uuid.UUID `gorm:"type:uuid"`
+	UpdatedAt  time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for ImpersonationSetting
+func (ImpersonationSetting) TableName() string {
+	return "impersonation_settings"
+}
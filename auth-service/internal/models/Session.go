@@ -20,8 +20,9 @@ type Session struct {
 	UserAgent sql.NullString `gorm:"type:text"`
 
 	// Session control
-	ExpiresAt time.Time `gorm:"not null;index"`
-	IsRevoked bool      `gorm:"default:false;index"` // Can manually revoke/logout
+	ExpiresAt  time.Time    `gorm:"not null;index"`
+	IsRevoked  bool         `gorm:"default:false;index"` // Can manually revoke/logout
+	LastSeenAt sql.NullTime `gorm:"type:timestamp"`      // Sliding expiration
 
 	// Relationships
 	User *User `gorm:"foreignKey:UserID"`
@@ -48,3 +49,12 @@ func (s *Session) BeforeCreate(tx *gorm.DB) error {
 func (s *Session) IsActive() bool {
 	return !s.IsRevoked && time.Now().Before(s.ExpiresAt)
 }
+
+// IsIdle checks if the session has not been used within timeout
+func (s *Session) IsIdle(timeout time.Duration) bool {
+	lastSeen := s.CreatedAt
+	if s.LastSeenAt.Valid {
+		lastSeen = s.LastSeenAt.Time
+	}
+	return time.Since(lastSeen) > timeout
+}
@@ -15,6 +15,7 @@ const (
 	UserStatusActive              UserStatus = "active"
 	UserStatusSuspended           UserStatus = "suspended"
 	UserStatusPendingVerification UserStatus = "pending_verification"
+	UserStatusDeleted             UserStatus = "deleted"
 )
 
 type User struct {
@@ -29,6 +30,9 @@ type User struct {
 	// Status
 	Status UserStatus `gorm:"type:varchar(50);default:'pending_verification'"`
 
+	// Platform staff (support) can impersonate merchant users. Set directly in the database.
+	IsPlatformAdmin bool `gorm:"default:false"`
+
 	// Security - Failed login tracking
 	FailedLoginAttempts int            `gorm:"default:0"`
 	LockedUntil         sql.NullTime   `gorm:"type:timestamp"`
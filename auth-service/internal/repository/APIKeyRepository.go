@@ -69,11 +69,32 @@ func (r *APIKeyRepository) Deactivate(id uuid.UUID) error {
 		Update("is_active", false).Error
 }
 
+// DeactivateByCreator deactivates every active API key created by a user
+func (r *APIKeyRepository) DeactivateByCreator(userID uuid.UUID) (int64, error) {
+	result := inits.DB.Model(&model.APIKey{}).
+		Where("created_by = ? AND is_active = true", userID).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
 // Delete deletes an API key
 func (r *APIKeyRepository) Delete(id uuid.UUID) error {
 	return inits.DB.Where("id = ?", id).Delete(&model.APIKey{}).Error
 }
 
+// UpdateAllowedCIDRs replaces the IP allowlist of an API key
+func (r *APIKeyRepository) UpdateAllowedCIDRs(id uuid.UUID, allowedCIDRs string) error {
+	return inits.DB.Model(&model.APIKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"allowed_cidrs": allowedCIDRs,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
 // UpdateLastUsed updates the last used timestamp
 func (r *APIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
 	return inits.DB.Model(&model.APIKey{}).
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+)
+
+type AccountDeletionRepository struct{}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository() *AccountDeletionRepository {
+	return &AccountDeletionRepository{}
+}
+
+// Create records an account deletion
+func (r *AccountDeletionRepository) Create(deletion *model.AccountDeletion) error {
+	return inits.DB.Create(deletion).Error
+}
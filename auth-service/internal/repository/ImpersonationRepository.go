@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImpersonationRepository struct{}
+
+// NewImpersonationRepository creates a new impersonation repository
+func NewImpersonationRepository() *ImpersonationRepository {
+	return &ImpersonationRepository{}
+}
+
+// Create creates a new impersonation session
+func (r *ImpersonationRepository) Create(session *model.ImpersonationSession) error {
+	return inits.DB.Create(session).Error
+}
+
+// FindByID finds an impersonation session by ID
+func (r *ImpersonationRepository) FindByID(id uuid.UUID) (*model.ImpersonationSession, error) {
+	var session model.ImpersonationSession
+	err := inits.DB.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("impersonation session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByMerchantID lists impersonation sessions for a merchant, newest first
+func (r *ImpersonationRepository) FindByMerchantID(merchantID uuid.UUID, limit int) ([]model.ImpersonationSession, error) {
+	var sessions []model.ImpersonationSession
+	err := inits.DB.Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// FindActiveByMerchantID lists impersonation sessions of a merchant that have not ended
+func (r *ImpersonationRepository) FindActiveByMerchantID(merchantID uuid.UUID) ([]model.ImpersonationSession, error) {
+	var sessions []model.ImpersonationSession
+	err := inits.DB.Where("merchant_id = ? AND ended_at IS NULL AND expires_at > ?", merchantID, time.Now()).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// End marks an impersonation session as ended
+func (r *ImpersonationRepository) End(id uuid.UUID) error {
+	return inits.DB.Model(&model.ImpersonationSession{}).
+		Where("id = ? AND ended_at IS NULL", id).
+		Update("ended_at", time.Now()).Error
+}
+
+// IsAllowed checks if a merchant allows impersonation (default: allowed)
+func (r *ImpersonationRepository) IsAllowed(merchantID uuid.UUID) (bool, error) {
+	var setting model.ImpersonationSetting
+	err := inits.DB.Where("merchant_id = ?", merchantID).First(&setting).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return setting.Allowed, nil
+}
+
+// SetAllowed creates or updates a merchant's impersonation setting
+func (r *ImpersonationRepository) SetAllowed(merchantID, updatedBy uuid.UUID, allowed bool) error {
+	setting := &model.ImpersonationSetting{
+		MerchantID: merchantID,
+		Allowed:    allowed,
+		UpdatedBy:  updatedBy,
+		UpdatedAt:  time.Now(),
+	}
+	return inits.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"allowed", "updated_by", "updated_at"}),
+	}).Create(setting).Error
+}
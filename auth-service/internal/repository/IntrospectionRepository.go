@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+)
+
+// Introspection results are cached per token in a Redis hash with one field
+// per merchant scope, so revoking the session drops all of them at once
+// (see invalidateSessionCache)
+const (
+	introspectionCacheKey = "introspect:%s" // token hash
+	introspectionAllScope = "*"             // field for requests without a merchant
+)
+
+// IntrospectionRecord is the cached answer for one token and merchant scope
+type IntrospectionRecord struct {
+	UserID             string             `json:"user_id"`
+	Email              string             `json:"email"`
+	MerchantIDs        []string           `json:"merchant_ids"`
+	Roles              []IntrospectedRole `json:"roles"`
+	Scopes             []string           `json:"scopes,omitempty"`
+	PermissionsVersion int64              `json:"permissions_version,omitempty"`
+	ExpiresAt          time.Time          `json:"expires_at"`
+	ImpersonatorID     string             `json:"impersonator_id,omitempty"`
+	EmailVerified      bool               `json:"-"` // Read from the user on every call, never cached
+}
+
+// IntrospectedRole is a role the user holds in a merchant
+type IntrospectedRole struct {
+	MerchantID string `json:"merchant_id"`
+	RoleID     string `json:"role_id"`
+	RoleName   string `json:"role_name"`
+}
+
+type IntrospectionRepository struct{}
+
+// NewIntrospectionRepository creates a new introspection cache repository
+func NewIntrospectionRepository() *IntrospectionRepository {
+	return &IntrospectionRepository{}
+}
+
+// Get returns the cached introspection of a token, if any. merchantID may
+// be empty.
+func (r *IntrospectionRepository) Get(tokenHash, merchantID string) (*IntrospectionRecord, bool) {
+	cached, err := inits.RDB.HGet(inits.Ctx, fmt.Sprintf(introspectionCacheKey, tokenHash), introspectionScope(merchantID)).Result()
+	if err != nil || cached == "" {
+		return nil, false
+	}
+
+	var record IntrospectionRecord
+	if err := json.Unmarshal([]byte(cached), &record); err != nil {
+		return nil, false
+	}
+	return &record, true
+}
+
+// Set caches an introspection for ttl. The whole hash shares one TTL, so a
+// later merchant scope extends the earlier ones; callers keep ttl short.
+func (r *IntrospectionRepository) Set(tokenHash, merchantID string, record *IntrospectionRecord, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf(introspectionCacheKey, tokenHash)
+	pipe := inits.RDB.TxPipeline()
+	pipe.HSet(inits.Ctx, key, introspectionScope(merchantID), data)
+	pipe.Expire(inits.Ctx, key, ttl)
+	_, err = pipe.Exec(inits.Ctx)
+	return err
+}
+
+func introspectionScope(merchantID string) string {
+	if merchantID == "" {
+		return introspectionAllScope
+	}
+	return merchantID
+}
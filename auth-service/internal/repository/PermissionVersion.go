@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+)
+
+// Permission versions let downstream services cache a user's permission set
+// and revalidate it cheaply. A user's version in a merchant is the sum of a
+// per-user counter (bumped on role assignment changes) and a global counter
+// (bumped when a role's permissions change). Both only increase, so any
+// change yields a new version.
+const (
+	userPermissionsVersionKey  = "user:permissions:version:%s:%s" // user_id:merchant_id
+	rolesPermissionsVersionKey = "roles:permissions:version"
+
+	// PermissionsInvalidationChannel is the Redis pub/sub channel downstream
+	// services listen on to drop cached permissions.
+	PermissionsInvalidationChannel = "auth:permissions:invalidated"
+)
+
+// PermissionInvalidation is published on PermissionsInvalidationChannel.
+// An empty UserID means every cached permission set is stale.
+type PermissionInvalidation struct {
+	UserID     string `json:"user_id,omitempty"`
+	MerchantID string `json:"merchant_id,omitempty"`
+	Version    int64  `json:"version,omitempty"`
+}
+
+// GetPermissionsVersion returns the current permission version of a user in a merchant
+func (r *UserRoleRepository) GetPermissionsVersion(userID, merchantID uuid.UUID) (int64, error) {
+	userKey := fmt.Sprintf(userPermissionsVersionKey, userID.String(), merchantID.String())
+
+	values, err := inits.RDB.MGet(inits.Ctx, userKey, rolesPermissionsVersionKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			n, _ := strconv.ParseInt(s, 10, 64)
+			version += n
+		}
+	}
+	return version, nil
+}
+
+// bumpUserPermissionsVersion marks a user's permissions in a merchant as changed
+func bumpUserPermissionsVersion(userID, merchantID uuid.UUID) {
+	userKey := fmt.Sprintf(userPermissionsVersionKey, userID.String(), merchantID.String())
+	userVersion, err := inits.RDB.Incr(inits.Ctx, userKey).Result()
+	if err != nil {
+		return
+	}
+	rolesVersion, _ := inits.RDB.Get(inits.Ctx, rolesPermissionsVersionKey).Int64()
+
+	publishPermissionInvalidation(PermissionInvalidation{
+		UserID:     userID.String(),
+		MerchantID: merchantID.String(),
+		Version:    userVersion + rolesVersion,
+	})
+}
+
+// bumpRolesPermissionsVersion marks every user's permissions as changed and
+// drops the cached permission sets, since any of them may include the role
+func bumpRolesPermissionsVersion() {
+	if err := inits.RDB.Incr(inits.Ctx, rolesPermissionsVersionKey).Err(); err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	iter := inits.RDB.Scan(ctx, 0, "user:permissions:*", 500).Iterator()
+	for iter.Next(ctx) {
+		// Keep the version counters, only drop cached permission lists
+		if key := iter.Val(); !strings.HasPrefix(key, "user:permissions:version:") {
+			inits.RDB.Del(ctx, key)
+		}
+	}
+
+	publishPermissionInvalidation(PermissionInvalidation{})
+}
+
+func publishPermissionInvalidation(event PermissionInvalidation) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	inits.RDB.Publish(inits.Ctx, PermissionsInvalidationChannel, payload)
+}
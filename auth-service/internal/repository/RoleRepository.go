@@ -168,6 +168,16 @@ func (r *RoleRepository) GetRoleWithPermissions(roleID uuid.UUID) (*model.Role,
 	return &role, nil
 }
 
+// FindAllWithPermissions gets every role with its permissions preloaded
+func (r *RoleRepository) FindAllWithPermissions() ([]model.Role, error) {
+	var roles []model.Role
+	err := inits.DB.Preload("Permissions").Order("name ASC").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
 // AssignPermissionToRole assigns a permission to a role
 func (r *RoleRepository) AssignPermissionToRole(roleID, permissionID uuid.UUID) error {
 	role, err := r.FindByID(roleID)
@@ -184,6 +194,7 @@ func (r *RoleRepository) AssignPermissionToRole(roleID, permissionID uuid.UUID)
 
 	// Invalidate cache
 	r.invalidateRoleCache(roleID, role.Name)
+	bumpRolesPermissionsVersion()
 
 	return nil
 }
@@ -204,6 +215,7 @@ func (r *RoleRepository) RemovePermissionFromRole(roleID, permissionID uuid.UUID
 
 	// Invalidate cache
 	r.invalidateRoleCache(roleID, role.Name)
+	bumpRolesPermissionsVersion()
 
 	return nil
 }
@@ -1,12 +1,16 @@
 package repository
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/auth-service/config"
 	"github.com/rhaloubi/payment-gateway/auth-service/inits"
 	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
 	"gorm.io/gorm"
@@ -23,18 +27,44 @@ func NewSessionRepository() *SessionRepository {
 const (
 	sessionCacheKeyByID    = "session:id:%s"
 	sessionCacheKeyByToken = "session:token:%s"
-	sessionCacheTTL        = 15 * time.Minute
+
+	// Set when a session is revoked and kept until it would have expired, so
+	// a cache entry written by a request that raced the revoke is ignored
+	sessionRevokedKey = "session:revoked:%s"
+
+	// Activity is recorded at most once per interval to keep validation cheap
+	sessionTouchInterval = time.Minute
+)
+
+var (
+	idleTimeoutOnce    sync.Once
+	sessionIdleTimeout = 30 * time.Minute
 )
 
-// Create creates a new session
+// SessionIdleTimeout returns the sliding expiration window (SESSION_IDLE_TIMEOUT, default 30m)
+func SessionIdleTimeout() time.Duration {
+	idleTimeoutOnce.Do(func() {
+		if d, err := time.ParseDuration(config.GetEnv("SESSION_IDLE_TIMEOUT")); err == nil && d > 0 {
+			sessionIdleTimeout = d
+		}
+	})
+	return sessionIdleTimeout
+}
+
+// Create stores a new session in Redis and queues the Postgres write
 func (r *SessionRepository) Create(session *model.Session) error {
-	err := inits.DB.Create(session).Error
-	if err != nil {
-		return err
+	now := time.Now()
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
 	}
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	session.LastSeenAt = sql.NullTime{Time: now, Valid: true}
 
-	// Cache the session
-	r.cacheSession(session)
+	// Fall back to a synchronous write if Redis or the writer is unavailable
+	if err := r.cacheSession(session); err != nil || !sessionWrites.enqueueCreate(session) {
+		return inits.DB.Create(session).Error
+	}
 
 	return nil
 }
@@ -99,6 +129,9 @@ func (r *SessionRepository) FindByToken(tokenHash string) (*model.Session, error
 
 // FindByUserID finds all sessions for a user
 func (r *SessionRepository) FindByUserID(userID uuid.UUID) ([]model.Session, error) {
+	// Make sure sessions still queued for Postgres are included
+	sessionWrites.flush()
+
 	var sessions []model.Session
 	err := inits.DB.Where("user_id = ? AND is_revoked = false AND expires_at > ?", userID, time.Now()).
 		Order("created_at DESC").
@@ -121,6 +154,9 @@ func (r *SessionRepository) Update(session *model.Session) error {
 
 // RevokeSession revokes a session
 func (r *SessionRepository) RevokeSession(id uuid.UUID) error {
+	// A queued insert would otherwise land after the revoke
+	sessionWrites.flush()
+
 	session, err := r.FindByID(id)
 	if err != nil {
 		return err
@@ -137,6 +173,7 @@ func (r *SessionRepository) RevokeSession(id uuid.UUID) error {
 	}
 
 	// Invalidate cache
+	r.markRevoked(session)
 	r.invalidateSessionCache(id, session.JWTToken)
 
 	return nil
@@ -158,8 +195,9 @@ func (r *SessionRepository) RevokeAllUserSessions(userID uuid.UUID) error {
 	}
 
 	// Invalidate all session caches
-	for _, session := range sessions {
-		r.invalidateSessionCache(session.ID, session.JWTToken)
+	for i := range sessions {
+		r.markRevoked(&sessions[i])
+		r.invalidateSessionCache(sessions[i].ID, sessions[i].JWTToken)
 	}
 
 	return nil
@@ -170,54 +208,127 @@ func (r *SessionRepository) DeleteExpiredSessions() error {
 	return inits.DB.Where("expires_at < ?", time.Now()).Delete(&model.Session{}).Error
 }
 
-// IsSessionValid checks if a session is valid (with Redis caching)
+// IsSessionValid checks if a session is valid and slides its idle expiration.
+// Redis answers on the hot path; Postgres is only read on a cache miss.
 func (r *SessionRepository) IsSessionValid(tokenHash string) (bool, error) {
-	// Try cache first
-	cacheKey := fmt.Sprintf(sessionCacheKeyByToken, tokenHash)
-	cachedSession, err := inits.RDB.Get(inits.Ctx, cacheKey).Result()
+	revoked, err := inits.RDB.Exists(inits.Ctx, fmt.Sprintf(sessionRevokedKey, tokenHash)).Result()
+	if err == nil && revoked > 0 {
+		return false, nil
+	}
 
-	if err == nil && cachedSession != "" {
-		var session model.Session
-		if err = json.Unmarshal([]byte(cachedSession), &session); err == nil {
-			return session.IsActive(), nil
+	session, cached := r.getCachedSession(fmt.Sprintf(sessionCacheKeyByToken, tokenHash))
+
+	if !cached {
+		var dbSession model.Session
+		err := inits.DB.Where("jwt_token = ?", tokenHash).First(&dbSession).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			return false, err
 		}
+		session = &dbSession
 	}
 
-	// Check database
-	var count int64
-	err = inits.DB.Model(&model.Session{}).
-		Where("jwt_token = ? AND is_revoked = false AND expires_at > ?", tokenHash, time.Now()).
-		Count(&count).Error
+	if !session.IsActive() || session.IsIdle(SessionIdleTimeout()) {
+		return false, nil
+	}
 
-	if err != nil {
-		return false, err
+	r.touch(session, !cached)
+
+	return true, nil
+}
+
+// touch records activity and extends the session's Redis TTL. A session
+// read from Redis is only rewritten while its keys still exist, so a touch
+// racing a revoke can't put the session back.
+func (r *SessionRepository) touch(session *model.Session, fromDB bool) {
+	now := time.Now()
+	if !fromDB && session.LastSeenAt.Valid && now.Sub(session.LastSeenAt.Time) < sessionTouchInterval {
+		return
+	}
+
+	session.LastSeenAt = sql.NullTime{Time: now, Valid: true}
+	if fromDB {
+		r.cacheSession(session)
+	} else {
+		r.refreshCachedSession(session)
+	}
+	sessionWrites.enqueueTouch(session.ID, now)
+}
+
+// Helper: Read a session from Redis
+func (r *SessionRepository) getCachedSession(cacheKey string) (*model.Session, bool) {
+	cachedSession, err := inits.RDB.Get(inits.Ctx, cacheKey).Result()
+	if err != nil || cachedSession == "" {
+		return nil, false
+	}
+
+	var session model.Session
+	if err := json.Unmarshal([]byte(cachedSession), &session); err != nil {
+		return nil, false
 	}
-	return count > 0, nil
+	return &session, true
 }
 
-// Helper: Cache session in Redis
-func (r *SessionRepository) cacheSession(session *model.Session) {
+// Helper: Cache session in Redis. The TTL is the idle timeout (capped at the
+// session expiry), so a session nobody uses drops out of Redis on its own.
+func (r *SessionRepository) cacheSession(session *model.Session) error {
 	sessionJSON, err := json.Marshal(session)
 	if err != nil {
-		return
+		return err
 	}
 
-	// Calculate TTL based on session expiry
 	ttl := time.Until(session.ExpiresAt)
-	if ttl > sessionCacheTTL {
-		ttl = sessionCacheTTL
+	if ttl > SessionIdleTimeout() {
+		ttl = SessionIdleTimeout()
 	}
 	if ttl <= 0 {
-		return // Don't cache expired sessions
+		return nil // Don't cache expired sessions
 	}
 
-	// Cache by ID
 	cacheKeyID := fmt.Sprintf(sessionCacheKeyByID, session.ID.String())
-	inits.RDB.Set(inits.Ctx, cacheKeyID, sessionJSON, ttl)
-
-	// Cache by token
 	cacheKeyToken := fmt.Sprintf(sessionCacheKeyByToken, session.JWTToken)
-	inits.RDB.Set(inits.Ctx, cacheKeyToken, sessionJSON, ttl)
+
+	_, err = inits.RDB.Pipelined(inits.Ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(inits.Ctx, cacheKeyID, sessionJSON, ttl)
+		pipe.Set(inits.Ctx, cacheKeyToken, sessionJSON, ttl)
+		return nil
+	})
+	return err
+}
+
+// Helper: Rewrite a cached session and extend its TTL, but only if it is
+// still cached. SET XX never creates a key that a revoke deleted.
+func (r *SessionRepository) refreshCachedSession(session *model.Session) error {
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	if ttl > SessionIdleTimeout() {
+		ttl = SessionIdleTimeout()
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	_, err = inits.RDB.Pipelined(inits.Ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetXX(inits.Ctx, fmt.Sprintf(sessionCacheKeyByID, session.ID.String()), sessionJSON, ttl)
+		pipe.SetXX(inits.Ctx, fmt.Sprintf(sessionCacheKeyByToken, session.JWTToken), sessionJSON, ttl)
+		return nil
+	})
+	return err
+}
+
+// Helper: Remember a revoked session until it would have expired
+func (r *SessionRepository) markRevoked(session *model.Session) {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	inits.RDB.Set(inits.Ctx, fmt.Sprintf(sessionRevokedKey, session.JWTToken), "1", ttl)
 }
 
 // Helper: Invalidate session cache
@@ -225,5 +336,5 @@ func (r *SessionRepository) invalidateSessionCache(sessionID uuid.UUID, tokenHas
 	cacheKeyID := fmt.Sprintf(sessionCacheKeyByID, sessionID.String())
 	cacheKeyToken := fmt.Sprintf(sessionCacheKeyByToken, tokenHash)
 
-	inits.RDB.Del(inits.Ctx, cacheKeyID, cacheKeyToken)
+	inits.RDB.Del(inits.Ctx, cacheKeyID, cacheKeyToken, fmt.Sprintf(introspectionCacheKey, tokenHash))
 }
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	sessionFlushInterval     = 2 * time.Second
+	maxPendingSessionCreates = 1000
+)
+
+// sessionWriter persists sessions to Postgres in the background. Redis answers
+// validity checks on the hot path; Postgres keeps the audit trail and is the
+// fallback when a session is missing from Redis.
+type sessionWriter struct {
+	mu      sync.Mutex
+	running bool
+	creates []*model.Session
+	touches map[uuid.UUID]time.Time // session_id -> last_seen_at
+
+	// flushMu serializes flushes so a synchronous flush (before a revoke)
+	// waits for one already in progress
+	flushMu sync.Mutex
+}
+
+var sessionWrites = &sessionWriter{touches: make(map[uuid.UUID]time.Time)}
+
+// StartSessionWriter flushes pending session writes every few seconds until
+// ctx is cancelled, then flushes once more. Without it sessions are written
+// synchronously.
+func StartSessionWriter(ctx context.Context) {
+	sessionWrites.mu.Lock()
+	sessionWrites.running = true
+	sessionWrites.mu.Unlock()
+
+	ticker := time.NewTicker(sessionFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			sessionWrites.mu.Lock()
+			sessionWrites.running = false
+			sessionWrites.mu.Unlock()
+
+			sessionWrites.flush()
+			return
+		case <-ticker.C:
+			sessionWrites.flush()
+		}
+	}
+}
+
+// enqueueCreate queues a session insert. It returns false when the caller
+// must write synchronously (writer not running or queue full).
+func (w *sessionWriter) enqueueCreate(session *model.Session) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running || len(w.creates) >= maxPendingSessionCreates {
+		return false
+	}
+
+	pending := *session
+	w.creates = append(w.creates, &pending)
+	return true
+}
+
+// enqueueTouch records session activity; repeated touches are coalesced
+func (w *sessionWriter) enqueueTouch(sessionID uuid.UUID, at time.Time) {
+	w.mu.Lock()
+	if w.running {
+		w.touches[sessionID] = at
+		w.mu.Unlock()
+		return
+	}
+	w.mu.Unlock()
+
+	inits.DB.Model(&model.Session{}).Where("id = ?", sessionID).Update("last_seen_at", at)
+}
+
+// flush writes all pending creates and touches to Postgres
+func (w *sessionWriter) flush() {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	creates, touches := w.creates, w.touches
+	w.creates = nil
+	w.touches = make(map[uuid.UUID]time.Time)
+	w.mu.Unlock()
+
+	if len(creates) > 0 {
+		if err := inits.DB.CreateInBatches(creates, 100).Error; err != nil {
+			// One bad row fails the whole batch; retry individually
+			for _, session := range creates {
+				if err := inits.DB.Create(session).Error; err != nil {
+					logger.Log.Error("failed to persist session",
+						zap.String("session_id", session.ID.String()),
+						zap.Error(err),
+					)
+				}
+			}
+		}
+	}
+
+	for sessionID, at := range touches {
+		if err := inits.DB.Model(&model.Session{}).
+			Where("id = ?", sessionID).
+			Update("last_seen_at", at).Error; err != nil {
+			logger.Log.Warn("failed to persist session activity",
+				zap.String("session_id", sessionID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
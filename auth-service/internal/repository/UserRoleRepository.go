@@ -187,10 +187,44 @@ func (r *UserRoleRepository) GetUsersByRole(roleID, merchantID uuid.UUID) ([]mod
 	return users, err
 }
 
+// GetUserRoleAssignments gets every role assignment of a user across all merchants
+func (r *UserRoleRepository) GetUserRoleAssignments(userID uuid.UUID) ([]model.UserRole, error) {
+	var userRoles []model.UserRole
+	err := inits.DB.Where("user_id = ?", userID).Find(&userRoles).Error
+	return userRoles, err
+}
+
+// GetUserMemberships gets every role assignment of a user with its role
+func (r *UserRoleRepository) GetUserMemberships(userID uuid.UUID) ([]model.UserRole, error) {
+	var userRoles []model.UserRole
+	err := inits.DB.Preload("Role").Where("user_id = ?", userID).Find(&userRoles).Error
+	return userRoles, err
+}
+
+// RemoveAllUserRoles removes a user from every merchant they belong to
+func (r *UserRoleRepository) RemoveAllUserRoles(userID uuid.UUID) error {
+	userRoles, err := r.GetUserRoleAssignments(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := inits.DB.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+		return err
+	}
+
+	// Invalidate cache for every merchant
+	for _, ur := range userRoles {
+		r.invalidateUserRoleCache(userID, ur.MerchantID)
+	}
+
+	return nil
+}
+
 // Helper: Invalidate user role/permission cache
 func (r *UserRoleRepository) invalidateUserRoleCache(userID, merchantID uuid.UUID) {
 	rolesKey := fmt.Sprintf(userRolesCacheKey, userID.String(), merchantID.String())
 	permissionsKey := fmt.Sprintf(userPermissionsCacheKey, userID.String(), merchantID.String())
 
 	inits.RDB.Del(inits.Ctx, rolesKey, permissionsKey)
+	bumpUserPermissionsVersion(userID, merchantID)
 }
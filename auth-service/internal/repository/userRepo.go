@@ -131,6 +131,26 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 	return nil
 }
 
+// Anonymize overwrites a user's personal data and soft deletes the record.
+// originalEmail is needed to evict the email-keyed cache entry.
+func (r *UserRepository) Anonymize(user *model.User, originalEmail string) error {
+	err := inits.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", user.ID).Delete(&model.User{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cache under both the old and the anonymized email
+	r.invalidateUserCache(user.ID, originalEmail)
+	r.invalidateUserCache(user.ID, user.Email)
+
+	return nil
+}
+
 // ExistsByEmail checks if a user exists with the given email
 func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
 	var count int64
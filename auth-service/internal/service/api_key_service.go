@@ -1,7 +1,13 @@
 package service
 
 import (
+	"crypto/rand"
 	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"net"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/auth-service/inits/jwt"
@@ -22,9 +28,11 @@ func NewAPIKeyService() *APIKeyService {
 
 // CreateAPIKeyRequest represents API key creation data
 type CreateAPIKeyRequest struct {
-	MerchantID uuid.UUID
-	Name       string
-	CreatedBy  uuid.UUID
+	MerchantID   uuid.UUID
+	Name         string
+	CreatedBy    uuid.UUID
+	AllowedCIDRs []string // Optional IP allowlist
+	TestMode     bool     // Sandbox key (pg_test_)
 }
 
 // CreateAPIKeyResponse represents created API key data
@@ -35,23 +43,35 @@ type CreateAPIKeyResponse struct {
 
 // CreateAPIKey creates a new API key
 func (s *APIKeyService) CreateAPIKey(req *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	allowedCIDRs, err := NormalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine key prefix
+	keyPrefix := APIKeyLivePrefix
+	if req.TestMode {
+		keyPrefix = APIKeyTestPrefix
+	}
+
 	// Generate random API key
-	plainKey := s.generateAPIKey()
+	plainKey, err := s.generateAPIKey(keyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
 
 	// Hash the key for storage
 	keyHash := jwt.HashSHA256(plainKey)
 
-	// Determine key prefix
-	keyPrefix := "pk_"
-
 	// Create API key
 	apiKey := &model.APIKey{
-		MerchantID: req.MerchantID,
-		KeyHash:    keyHash,
-		KeyPrefix:  keyPrefix,
-		Name:       req.Name,
-		IsActive:   true,
-		CreatedBy:  req.CreatedBy,
+		MerchantID:   req.MerchantID,
+		KeyHash:      keyHash,
+		KeyPrefix:    keyPrefix,
+		Name:         req.Name,
+		IsActive:     true,
+		AllowedCIDRs: strings.Join(allowedCIDRs, ","),
+		CreatedBy:    req.CreatedBy,
 	}
 
 	if err := s.apiKeyRepo.Create(apiKey); err != nil {
@@ -66,6 +86,11 @@ func (s *APIKeyService) CreateAPIKey(req *CreateAPIKeyRequest) (*CreateAPIKeyRes
 
 // ValidateAPIKey validates an API key
 func (s *APIKeyService) ValidateAPIKey(plainKey string) (*model.APIKey, error) {
+	// Reject malformed keys before touching the database
+	if !ValidateAPIKeyFormat(plainKey) {
+		return nil, errors.New("invalid or inactive API key")
+	}
+
 	// Hash the provided key
 	keyHash := jwt.HashSHA256(plainKey)
 
@@ -105,11 +130,118 @@ func (s *APIKeyService) DeleteAPIKey(keyID uuid.UUID) error {
 	return s.apiKeyRepo.Delete(keyID)
 }
 
-// generateAPIKey generates a random API key
-func (s *APIKeyService) generateAPIKey() string {
-	// Generate random 32 character string
-	randomBytes := uuid.New().String() + uuid.New().String()
-	return "pk_" + randomBytes[:32]
+// UpdateAllowedCIDRs replaces the IP allowlist of an API key
+func (s *APIKeyService) UpdateAllowedCIDRs(keyID uuid.UUID, cidrs []string) ([]string, error) {
+	allowedCIDRs, err := NormalizeCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apiKeyRepo.UpdateAllowedCIDRs(keyID, strings.Join(allowedCIDRs, ",")); err != nil {
+		return nil, err
+	}
+
+	return allowedCIDRs, nil
+}
+
+// NormalizeCIDRs validates CIDR ranges, turning bare IPs into /32 or /128
+func NormalizeCIDRs(cidrs []string) ([]string, error) {
+	normalized := []string{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(cidr); ip != nil {
+			if ip.To4() != nil {
+				cidr = ip.String() + "/32"
+			} else {
+				cidr = ip.String() + "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range: %s", cidr)
+		}
+		normalized = append(normalized, network.String())
+	}
+	return normalized, nil
+}
+
+// =========================================================================
+// Key Format
+// =========================================================================
+//
+// Keys look like pg_live_<30 base62 chars><6 base62 CRC32 checksum>, or
+// pg_test_ for sandbox keys. The fixed prefix lets secret scanners spot
+// leaked keys and the checksum lets us reject typos and garbage without a
+// database lookup. Keys issued before this format (pk_<32 chars>) are still
+// accepted.
+
+const (
+	APIKeyLivePrefix   = "pg_live_"
+	APIKeyTestPrefix   = "pg_test_"
+	legacyAPIKeyPrefix = "pk_"
+	apiKeyBodyLength   = 30
+	apiKeyChecksumLen  = 6
+	base62Alphabet     = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// ValidateAPIKeyFormat checks the key structure and checksum (no DB access)
+func ValidateAPIKeyFormat(key string) bool {
+	if strings.HasPrefix(key, legacyAPIKeyPrefix) {
+		return len(key) == len(legacyAPIKeyPrefix)+32
+	}
+
+	prefix := APIKeyLivePrefix
+	if strings.HasPrefix(key, APIKeyTestPrefix) {
+		prefix = APIKeyTestPrefix
+	}
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok || len(rest) != apiKeyBodyLength+apiKeyChecksumLen || !isBase62(rest) {
+		return false
+	}
+
+	body := rest[:apiKeyBodyLength]
+	return rest[apiKeyBodyLength:] == apiKeyChecksum(prefix+body)
+}
+
+// generateAPIKey generates a random API key with a checksum suffix
+func (s *APIKeyService) generateAPIKey(prefix string) (string, error) {
+	body := make([]byte, apiKeyBodyLength)
+	max := big.NewInt(int64(len(base62Alphabet)))
+	for i := range body {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		body[i] = base62Alphabet[n.Int64()]
+	}
+
+	key := prefix + string(body)
+	return key + apiKeyChecksum(key), nil
+}
+
+// apiKeyChecksum encodes the CRC32 of the key as fixed-width base62
+func apiKeyChecksum(key string) string {
+	sum := uint64(crc32.ChecksumIEEE([]byte(key)))
+	encoded := make([]byte, apiKeyChecksumLen)
+	for i := apiKeyChecksumLen - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[sum%62]
+		sum /= 62
+	}
+	return string(encoded)
+}
+
+func isBase62(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune(base62Alphabet, r) {
+			return false
+		}
+	}
+	return true
 }
 
 // get key by id
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,9 +16,17 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrSoleMerchantOwner is returned when an account cannot be deleted because
+// the user is the last admin of a merchant.
+var ErrSoleMerchantOwner = errors.New("you are the sole owner of a merchant; transfer ownership before deleting your account")
+
 type AuthService struct {
 	userRepo     *repository.UserRepository
 	sessionRepo  *repository.SessionRepository
+	userRoleRepo *repository.UserRoleRepository
+	roleRepo     *repository.RoleRepository
+	apiKeyRepo   *repository.APIKeyRepository
+	deletionRepo *repository.AccountDeletionRepository
 	jwtUtil      *jwt.JWTUtil
 	emailService *inits.EmailService
 }
@@ -23,6 +35,10 @@ func NewAuthService() *AuthService {
 	return &AuthService{
 		userRepo:     repository.NewUserRepository(),
 		sessionRepo:  repository.NewSessionRepository(),
+		userRoleRepo: repository.NewUserRoleRepository(),
+		roleRepo:     repository.NewRoleRepository(),
+		apiKeyRepo:   repository.NewAPIKeyRepository(),
+		deletionRepo: repository.NewAccountDeletionRepository(),
 		jwtUtil:      jwt.NewJWTUtil(),
 		emailService: inits.NewEmailService(),
 	}
@@ -176,37 +192,39 @@ func (s *AuthService) LogoutAll(userID uuid.UUID) error {
 	return s.sessionRepo.RevokeAllUserSessions(userID)
 }
 
-func (s *AuthService) ValidateToken(accessToken string) (*model.User, error) {
+// ValidateToken validates an access token and returns the user it acts as,
+// along with the claims so callers can detect impersonation tokens.
+func (s *AuthService) ValidateToken(accessToken string) (*model.User, *jwt.JWTClaims, error) {
 	// Parse and validate JWT
 	claims, err := s.jwtUtil.ValidateAccessToken(accessToken)
 	if err != nil {
-		return nil, errors.New("invalid or expired token")
+		return nil, nil, errors.New("invalid or expired token")
 	}
 
 	// Check if session exists and is valid
 	tokenHash := s.jwtUtil.HashToken(accessToken)
 	isValid, err := s.sessionRepo.IsSessionValid(tokenHash)
 	if err != nil || !isValid {
-		return nil, errors.New("session not found or revoked")
+		return nil, nil, errors.New("session not found or revoked")
 	}
 
 	// Get user
 	userID, err := uuid.Parse(claims.UserID)
 	if err != nil {
-		return nil, errors.New("invalid user ID in token")
+		return nil, nil, errors.New("invalid user ID in token")
 	}
 
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, nil, errors.New("user not found")
 	}
 
 	// Check if user is active
 	if user.Status != model.UserStatusActive {
-		return nil, errors.New("user account is not active")
+		return nil, nil, errors.New("user account is not active")
 	}
 
-	return user, nil
+	return user, claims, nil
 }
 
 func (s *AuthService) RefreshToken(refreshToken string) (*LoginResponse, error) {
@@ -292,6 +310,94 @@ func (s *AuthService) GetUserSessions(userID uuid.UUID) ([]model.Session, error)
 	return s.sessionRepo.FindByUserID(userID)
 }
 
+// DeleteAccount permanently deletes a user's account after confirming their password.
+// The user row is anonymized and soft deleted, sessions and API keys are revoked,
+// and the user is detached from every merchant. Sole admins must transfer
+// ownership first.
+func (s *AuthService) DeleteAccount(userID uuid.UUID, password, ipAddress string) error {
+	// Step 1: Confirm identity
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return errors.New("password is incorrect")
+	}
+
+	// Step 2: Block if the user is the only admin of a merchant
+	assignments, err := s.userRoleRepo.GetUserRoleAssignments(userID)
+	if err != nil {
+		return errors.New("failed to load merchant memberships")
+	}
+
+	adminRole, err := s.roleRepo.FindByName("Admin")
+	if err != nil {
+		return errors.New("failed to load admin role")
+	}
+
+	merchants := make(map[uuid.UUID]bool)
+	for _, ur := range assignments {
+		merchants[ur.MerchantID] = true
+		if ur.RoleID != adminRole.ID {
+			continue
+		}
+
+		admins, err := s.userRoleRepo.GetUsersByRole(adminRole.ID, ur.MerchantID)
+		if err != nil {
+			return errors.New("failed to check merchant admins")
+		}
+		if len(admins) <= 1 {
+			return fmt.Errorf("%w (merchant %s)", ErrSoleMerchantOwner, ur.MerchantID)
+		}
+	}
+
+	// Step 3: Revoke sessions and API keys
+	if err := s.sessionRepo.RevokeAllUserSessions(userID); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	revokedKeys, err := s.apiKeyRepo.DeactivateByCreator(userID)
+	if err != nil {
+		return errors.New("failed to revoke API keys")
+	}
+
+	// Step 4: Detach from merchant teams
+	if err := s.userRoleRepo.RemoveAllUserRoles(userID); err != nil {
+		return errors.New("failed to detach user from merchants")
+	}
+
+	// Step 5: Anonymize and soft delete
+	originalEmail := user.Email
+	user.Name = "Deleted User"
+	user.Email = fmt.Sprintf("deleted-%s@deleted.invalid", user.ID)
+	user.PasswordHash = ""
+	user.EmailVerified = false
+	user.Status = model.UserStatusDeleted
+	user.LastLoginIP = toNullString("")
+
+	if err := s.userRepo.Anonymize(user, originalEmail); err != nil {
+		return errors.New("failed to anonymize user")
+	}
+
+	// Step 6: Record the deletion for compliance
+	emailHash := sha256.Sum256([]byte(strings.ToLower(originalEmail)))
+	deletion := &model.AccountDeletion{
+		UserID:            userID,
+		EmailHash:         hex.EncodeToString(emailHash[:]),
+		IPAddress:         toNullString(ipAddress),
+		RevokedAPIKeys:    revokedKeys,
+		DetachedMerchants: len(merchants),
+		DeletedAt:         time.Now(),
+	}
+
+	if err := s.deletionRepo.Create(deletion); err != nil {
+		return errors.New("failed to record account deletion")
+	}
+
+	return nil
+}
+
 // validateRegistration validates registration input
 func (s *AuthService) validateRegistration(req *RegisterRequest) error {
 	if req.Name == "" {
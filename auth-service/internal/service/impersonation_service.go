@@ -0,0 +1,226 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/jwt"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultImpersonationTTL = 30 * time.Minute
+	maxImpersonationTTL     = 2 * time.Hour
+)
+
+type ImpersonationService struct {
+	impersonationRepo *repository.ImpersonationRepository
+	userRepo          *repository.UserRepository
+	userRoleRepo      *repository.UserRoleRepository
+	sessionRepo       *repository.SessionRepository
+	jwtUtil           *jwt.JWTUtil
+}
+
+func NewImpersonationService() *ImpersonationService {
+	return &ImpersonationService{
+		impersonationRepo: repository.NewImpersonationRepository(),
+		userRepo:          repository.NewUserRepository(),
+		userRoleRepo:      repository.NewUserRoleRepository(),
+		sessionRepo:       repository.NewSessionRepository(),
+		jwtUtil:           jwt.NewJWTUtil(),
+	}
+}
+
+type StartImpersonationRequest struct {
+	ImpersonatorID uuid.UUID
+	TargetUserID   uuid.UUID
+	MerchantID     uuid.UUID
+	Reason         string
+	Duration       time.Duration // 0 uses the default
+	IPAddress      string
+	UserAgent      string
+}
+
+type StartImpersonationResponse struct {
+	Session     *model.ImpersonationSession
+	AccessToken string
+	ExpiresIn   int64 // seconds
+}
+
+// Start mints an impersonation token for a platform admin acting as a merchant user
+func (s *ImpersonationService) Start(req *StartImpersonationRequest) (*StartImpersonationResponse, error) {
+	// Step 1: Only platform admins may impersonate
+	impersonator, err := s.userRepo.FindByID(req.ImpersonatorID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !impersonator.IsPlatformAdmin || impersonator.Status != model.UserStatusActive {
+		return nil, errors.New("only platform admins can impersonate users")
+	}
+
+	if req.Reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	ttl := req.Duration
+	if ttl == 0 {
+		ttl = defaultImpersonationTTL
+	}
+	if ttl < 0 || ttl > maxImpersonationTTL {
+		return nil, errors.New("duration must be between 1 and 120 minutes")
+	}
+
+	// Step 2: Validate the target user
+	if req.TargetUserID == req.ImpersonatorID {
+		return nil, errors.New("cannot impersonate yourself")
+	}
+
+	target, err := s.userRepo.FindByID(req.TargetUserID)
+	if err != nil {
+		return nil, errors.New("target user not found")
+	}
+	if target.IsPlatformAdmin {
+		return nil, errors.New("cannot impersonate another platform admin")
+	}
+	if target.Status != model.UserStatusActive {
+		return nil, errors.New("target user account is not active")
+	}
+
+	roles, err := s.userRoleRepo.GetUserRoles(target.ID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("target user is not a member of this merchant")
+	}
+
+	// Step 3: Respect the merchant's opt-out
+	allowed, err := s.impersonationRepo.IsAllowed(req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.New("merchant has disabled impersonation")
+	}
+
+	// Step 4: Mint token backed by a regular session
+	impersonationID := uuid.New()
+	expiresAt := time.Now().Add(ttl)
+
+	accessToken, err := s.jwtUtil.GenerateImpersonationToken(target.ID, target.Email, impersonator.ID, impersonationID, ttl)
+	if err != nil {
+		return nil, errors.New("failed to generate access token")
+	}
+
+	session := &model.Session{
+		UserID:    target.ID,
+		JWTToken:  s.jwtUtil.HashToken(accessToken),
+		IPAddress: toNullString(req.IPAddress),
+		UserAgent: toNullString(req.UserAgent),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	// Step 5: Record the impersonation
+	impersonation := &model.ImpersonationSession{
+		ID:             impersonationID,
+		ImpersonatorID: impersonator.ID,
+		TargetUserID:   target.ID,
+		MerchantID:     req.MerchantID,
+		SessionID:      session.ID,
+		Reason:         req.Reason,
+		IPAddress:      toNullString(req.IPAddress),
+		ExpiresAt:      expiresAt,
+	}
+	if err := s.impersonationRepo.Create(impersonation); err != nil {
+		s.sessionRepo.RevokeSession(session.ID)
+		return nil, errors.New("failed to record impersonation session")
+	}
+
+	logger.Log.Warn("Impersonation session started",
+		zap.String("impersonation_id", impersonationID.String()),
+		zap.String("impersonator_id", impersonator.ID.String()),
+		zap.String("target_user_id", target.ID.String()),
+		zap.String("merchant_id", req.MerchantID.String()),
+		zap.String("reason", req.Reason),
+	)
+
+	return &StartImpersonationResponse{
+		Session:     impersonation,
+		AccessToken: accessToken,
+		ExpiresIn:   int64(ttl.Seconds()),
+	}, nil
+}
+
+// End terminates an impersonation session and revokes its token.
+// Only the impersonator can end their own session.
+func (s *ImpersonationService) End(impersonationID, callerID uuid.UUID) error {
+	impersonation, err := s.impersonationRepo.FindByID(impersonationID)
+	if err != nil {
+		return err
+	}
+	if impersonation.ImpersonatorID != callerID {
+		return errors.New("impersonation session belongs to another user")
+	}
+
+	return s.end(impersonation)
+}
+
+// ListForMerchant lists the most recent impersonation sessions of a merchant
+func (s *ImpersonationService) ListForMerchant(merchantID uuid.UUID) ([]model.ImpersonationSession, error) {
+	return s.impersonationRepo.FindByMerchantID(merchantID, 100)
+}
+
+// IsAllowed reports whether a merchant allows impersonation
+func (s *ImpersonationService) IsAllowed(merchantID uuid.UUID) (bool, error) {
+	return s.impersonationRepo.IsAllowed(merchantID)
+}
+
+// SetAllowed updates a merchant's impersonation setting. Opting out also ends
+// every impersonation session that is still active.
+func (s *ImpersonationService) SetAllowed(merchantID, updatedBy uuid.UUID, allowed bool) error {
+	if err := s.impersonationRepo.SetAllowed(merchantID, updatedBy, allowed); err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+
+	active, err := s.impersonationRepo.FindActiveByMerchantID(merchantID)
+	if err != nil {
+		return err
+	}
+	for i := range active {
+		if err := s.end(&active[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ImpersonationService) end(impersonation *model.ImpersonationSession) error {
+	if impersonation.EndedAt.Valid {
+		return nil
+	}
+
+	if err := s.sessionRepo.RevokeSession(impersonation.SessionID); err != nil {
+		return errors.New("failed to revoke impersonation token")
+	}
+	if err := s.impersonationRepo.End(impersonation.ID); err != nil {
+		return err
+	}
+	impersonation.EndedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	logger.Log.Info("Impersonation session ended",
+		zap.String("impersonation_id", impersonation.ID.String()),
+		zap.String("merchant_id", impersonation.MerchantID.String()),
+	)
+	return nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/config"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/repository"
+)
+
+// CeremonySecrets are the platform secrets a ceremony can set here, with
+// the variable the service loads the generated file from
+var CeremonySecrets = map[string]string{
+	"jwt_signing_key": "JWT_SECRET_KEY_FILE",
+}
+
+const (
+	ceremonyApprovalsRequired = 2
+	ceremonyTTL               = 24 * time.Hour
+	minComponentPassphrase    = 16
+)
+
+var ErrNotCeremonyOperator = errors.New("operator is not listed in KEY_CEREMONY_OPERATORS")
+
+// CeremonyComponent is one custodian's passphrase. Neither custodian alone
+// knows enough to rebuild the secret.
+type CeremonyComponent struct {
+	Operator   string
+	Passphrase string
+}
+
+// KeyCeremonyService enforces dual control over platform secrets
+type KeyCeremonyService struct {
+	ceremonyRepo *repository.KeyCeremonyRepository
+	operators    map[string]bool
+}
+
+// NewKeyCeremonyService creates a key ceremony service. Operators come from
+// the comma-separated KEY_CEREMONY_OPERATORS.
+func NewKeyCeremonyService() *KeyCeremonyService {
+	operators := make(map[string]bool)
+	for _, op := range strings.Split(config.GetEnv("KEY_CEREMONY_OPERATORS"), ",") {
+		if op = strings.TrimSpace(op); op != "" {
+			operators[op] = true
+		}
+	}
+	return &KeyCeremonyService{
+		ceremonyRepo: repository.NewKeyCeremonyRepository(),
+		operators:    operators,
+	}
+}
+
+// Propose opens a ceremony for a secret. Only one can be open per secret.
+func (s *KeyCeremonyService) Propose(secret, action, reason, operator string) (*model.KeyCeremony, error) {
+	if !s.operators[operator] {
+		return nil, ErrNotCeremonyOperator
+	}
+	if _, ok := CeremonySecrets[secret]; !ok {
+		return nil, fmt.Errorf("unknown secret %q", secret)
+	}
+	if action != "initialize" && action != "rotate" {
+		return nil, errors.New("action must be initialize or rotate")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.New("reason is required")
+	}
+	if open, err := s.ceremonyRepo.FindOpenBySecret(secret); err == nil {
+		return nil, fmt.Errorf("ceremony %s is already open for %s", open.ID, secret)
+	}
+
+	ceremony := &model.KeyCeremony{
+		Secret:     secret,
+		Action:     action,
+		Reason:     reason,
+		ProposedBy: operator,
+		Status:     model.KeyCeremonyPending,
+		ExpiresAt:  time.Now().Add(ceremonyTTL),
+	}
+	if err := s.ceremonyRepo.Create(ceremony); err != nil {
+		return nil, fmt.Errorf("failed to create ceremony: %w", err)
+	}
+
+	if err := s.logEvent(ceremony.ID, "proposed", operator,
+		fmt.Sprintf("secret=%s action=%s reason=%s", secret, action, reason)); err != nil {
+		return nil, err
+	}
+
+	return ceremony, nil
+}
+
+// Approve records an operator's approval. The proposer cannot approve their
+// own ceremony, and the second distinct approval unlocks it.
+func (s *KeyCeremonyService) Approve(id uuid.UUID, operator string) (*model.KeyCeremony, error) {
+	if !s.operators[operator] {
+		return nil, ErrNotCeremonyOperator
+	}
+
+	ceremony, err := s.ceremonyRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Status != model.KeyCeremonyPending || !ceremony.IsOpen() {
+		return nil, fmt.Errorf("ceremony is %s and cannot be approved", s.effectiveStatus(ceremony))
+	}
+	if ceremony.ProposedBy == operator {
+		return nil, errors.New("the proposer cannot approve their own ceremony")
+	}
+	for _, approval := range ceremony.Approvals {
+		if approval.Operator == operator {
+			return nil, errors.New("operator has already approved this ceremony")
+		}
+	}
+
+	approval := model.KeyCeremonyApproval{CeremonyID: ceremony.ID, Operator: operator}
+	if err := s.ceremonyRepo.AddApproval(&approval); err != nil {
+		return nil, fmt.Errorf("failed to record approval: %w", err)
+	}
+	ceremony.Approvals = append(ceremony.Approvals, approval)
+
+	if err := s.logEvent(ceremony.ID, "approved", operator,
+		fmt.Sprintf("approvals=%d/%d", len(ceremony.Approvals), ceremonyApprovalsRequired)); err != nil {
+		return nil, err
+	}
+
+	if len(ceremony.Approvals) >= ceremonyApprovalsRequired {
+		ceremony.Status = model.KeyCeremonyApproved
+		if err := s.ceremonyRepo.Update(ceremony); err != nil {
+			return nil, fmt.Errorf("failed to update ceremony: %w", err)
+		}
+	}
+
+	return ceremony, nil
+}
+
+// Cancel closes an open ceremony without generating anything
+func (s *KeyCeremonyService) Cancel(id uuid.UUID, operator, reason string) error {
+	if !s.operators[operator] {
+		return ErrNotCeremonyOperator
+	}
+
+	ceremony, err := s.ceremonyRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if ceremony.Status == model.KeyCeremonyCompleted || ceremony.Status == model.KeyCeremonyCancelled {
+		return fmt.Errorf("ceremony is already %s", ceremony.Status)
+	}
+
+	ceremony.Status = model.KeyCeremonyCancelled
+	if err := s.ceremonyRepo.Update(ceremony); err != nil {
+		return fmt.Errorf("failed to update ceremony: %w", err)
+	}
+
+	return s.logEvent(ceremony.ID, "cancelled", operator, "reason="+reason)
+}
+
+// Run builds the secret from both approvers' components and writes it to
+// outputPath, which must not exist yet. Only key check values are logged.
+func (s *KeyCeremonyService) Run(id uuid.UUID, components []CeremonyComponent, outputPath string) (string, error) {
+	ceremony, err := s.ceremonyRepo.FindByID(id)
+	if err != nil {
+		return "", err
+	}
+	if ceremony.Status != model.KeyCeremonyApproved || !ceremony.IsOpen() {
+		return "", fmt.Errorf("ceremony is %s and cannot be run", s.effectiveStatus(ceremony))
+	}
+
+	if len(components) != ceremonyApprovalsRequired {
+		return "", fmt.Errorf("%d components are required", ceremonyApprovalsRequired)
+	}
+
+	approvers := make(map[string]bool)
+	for _, approval := range ceremony.Approvals {
+		approvers[approval.Operator] = true
+	}
+
+	secret := make([]byte, sha256.Size)
+	seen := make(map[string]bool)
+	for _, component := range components {
+		if !approvers[component.Operator] {
+			return "", fmt.Errorf("%s did not approve this ceremony", component.Operator)
+		}
+		if seen[component.Operator] {
+			return "", errors.New("each component must come from a different approver")
+		}
+		seen[component.Operator] = true
+
+		if len(component.Passphrase) < minComponentPassphrase {
+			return "", fmt.Errorf("passphrase components must be at least %d characters", minComponentPassphrase)
+		}
+
+		share := sha256.Sum256([]byte(component.Passphrase))
+		for i := range secret {
+			secret[i] ^= share[i]
+		}
+
+		if err := s.logEvent(ceremony.ID, "component_entered", component.Operator,
+			"kcv="+keyCheckValue(share[:])); err != nil {
+			return "", err
+		}
+	}
+
+	kcv := keyCheckValue(secret)
+
+	file, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create secret file: %w", err)
+	}
+	if _, err := file.WriteString(hex.EncodeToString(secret) + "\n"); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write secret file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write secret file: %w", err)
+	}
+
+	ceremony.Status = model.KeyCeremonyCompleted
+	ceremony.KeyCheckValue = sql.NullString{String: kcv, Valid: true}
+	ceremony.OutputPath = sql.NullString{String: outputPath, Valid: true}
+	ceremony.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.ceremonyRepo.Update(ceremony); err != nil {
+		return "", fmt.Errorf("failed to update ceremony: %w", err)
+	}
+
+	if err := s.logEvent(ceremony.ID, "completed", ceremony.ProposedBy,
+		fmt.Sprintf("kcv=%s output=%s", kcv, outputPath)); err != nil {
+		return "", err
+	}
+
+	return kcv, nil
+}
+
+// List lists recent ceremonies
+func (s *KeyCeremonyService) List(limit int) ([]model.KeyCeremony, error) {
+	return s.ceremonyRepo.List(limit)
+}
+
+// VerifyLog walks the ceremony log and checks every hash link. It returns
+// how many events were checked.
+func (s *KeyCeremonyService) VerifyLog() (int, error) {
+	events, err := s.ceremonyRepo.ListEvents()
+	if err != nil {
+		return 0, err
+	}
+
+	prevHash := ""
+	for i, event := range events {
+		if event.Sequence != int64(i+1) {
+			return i, fmt.Errorf("sequence gap before event %d", event.Sequence)
+		}
+		if event.PrevHash != prevHash {
+			return i, fmt.Errorf("event %d does not link to the previous event", event.Sequence)
+		}
+		if event.ComputeHash() != event.Hash {
+			return i, fmt.Errorf("event %d was modified", event.Sequence)
+		}
+		prevHash = event.Hash
+	}
+
+	return len(events), nil
+}
+
+func (s *KeyCeremonyService) logEvent(ceremonyID uuid.UUID, event, operator, detail string) error {
+	if err := s.ceremonyRepo.AppendEvent(&model.KeyCeremonyEvent{
+		CeremonyID: ceremonyID,
+		Event:      event,
+		Operator:   operator,
+		Detail:     detail,
+	}); err != nil {
+		return fmt.Errorf("failed to write ceremony log: %w", err)
+	}
+	return nil
+}
+
+func (s *KeyCeremonyService) effectiveStatus(ceremony *model.KeyCeremony) string {
+	if (ceremony.Status == model.KeyCeremonyPending || ceremony.Status == model.KeyCeremonyApproved) &&
+		!time.Now().Before(ceremony.ExpiresAt) {
+		return "expired"
+	}
+	return ceremony.Status
+}
+
+// keyCheckValue is the usual KCV: the first three bytes of a zero block
+// encrypted under the key, in hex
+func keyCheckValue(key []byte) string {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return ""
+	}
+	out := make([]byte, aes.BlockSize)
+	block.Encrypt(out, make([]byte, aes.BlockSize))
+	return strings.ToUpper(hex.EncodeToString(out[:3]))
+}
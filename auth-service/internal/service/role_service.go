@@ -38,6 +38,38 @@ func (s *RoleService) GetRoleWithPermissions(roleID uuid.UUID) (*model.Role, err
 	return s.roleRepo.GetRoleWithPermissions(roleID)
 }
 
+// RolePermissions is one row of the permission matrix
+type RolePermissions struct {
+	RoleID      uuid.UUID `json:"role_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"` // "resource:action"
+}
+
+// GetPermissionMatrix lists every role with the permissions it grants
+func (s *RoleService) GetPermissionMatrix() ([]RolePermissions, error) {
+	roles, err := s.roleRepo.FindAllWithPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := make([]RolePermissions, len(roles))
+	for i, role := range roles {
+		permissions := make([]string, len(role.Permissions))
+		for j, p := range role.Permissions {
+			permissions[j] = p.Resource + ":" + p.Action
+		}
+		matrix[i] = RolePermissions{
+			RoleID:      role.ID,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		}
+	}
+
+	return matrix, nil
+}
+
 func (s *RoleService) AssignRoleToUser(userID, roleID, merchantID, assignedBy uuid.UUID) error {
 	// Verify role exists
 	_, err := s.roleRepo.FindByID(roleID)
@@ -64,6 +96,52 @@ func (s *RoleService) HasPermission(userID, merchantID uuid.UUID, resource, acti
 	return s.userRoleRepo.HasPermission(userID, merchantID, resource, action)
 }
 
+// PermissionCheck is a single resource/action pair to check
+type PermissionCheck struct {
+	Resource string
+	Action   string
+}
+
+// BatchPermissionResult answers several permission checks at once
+type BatchPermissionResult struct {
+	Allowed     []bool   // aligned with the requested checks
+	Permissions []string // every permission granted in the merchant, "resource:action"
+	Version     int64
+}
+
+// BatchCheckPermissions checks several permissions for a user in one call and
+// returns the permission version so callers can cache the full set
+func (s *RoleService) BatchCheckPermissions(userID, merchantID uuid.UUID, checks []PermissionCheck) (*BatchPermissionResult, error) {
+	// Read the version first: a concurrent change then yields a newer version
+	// than the permissions returned, which only causes an extra refetch
+	version, err := s.userRoleRepo.GetPermissionsVersion(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.userRoleRepo.GetUserPermissions(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(permissions))
+	result := &BatchPermissionResult{
+		Allowed:     make([]bool, len(checks)),
+		Permissions: make([]string, 0, len(permissions)),
+		Version:     version,
+	}
+	for _, p := range permissions {
+		key := p.Resource + ":" + p.Action
+		granted[key] = true
+		result.Permissions = append(result.Permissions, key)
+	}
+	for i, check := range checks {
+		result.Allowed[i] = granted[check.Resource+":"+check.Action]
+	}
+
+	return result, nil
+}
+
 func (s *RoleService) UpdateUserRole(userID, oldRoleID, newRoleID, merchantID uuid.UUID) error {
 	// Verify new role exists
 	_, err := s.roleRepo.FindByID(newRoleID)
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/auth-service/config"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/jwt"
+	model "github.com/rhaloubi/payment-gateway/auth-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/repository"
+)
+
+// Reasons a token is reported inactive by Introspect
+var (
+	ErrTokenInvalid   = errors.New("invalid or expired token")
+	ErrSessionRevoked = errors.New("session not found or revoked")
+	ErrUserInactive   = errors.New("user account is not active")
+)
+
+var (
+	introspectionTTLOnce sync.Once
+	introspectionTTL     = time.Minute
+)
+
+// IntrospectionCacheTTL returns how long an introspection is cached
+// (INTROSPECTION_CACHE_TTL, default 1m). Revocations and permission changes
+// are seen immediately; only a new merchant membership can take this long.
+func IntrospectionCacheTTL() time.Duration {
+	introspectionTTLOnce.Do(func() {
+		if d, err := time.ParseDuration(config.GetEnv("INTROSPECTION_CACHE_TTL")); err == nil && d > 0 {
+			introspectionTTL = d
+		}
+	})
+	return introspectionTTL
+}
+
+// TokenIntrospectionService answers who an access token belongs to and what
+// it may do, so other services don't have to validate JWTs themselves
+type TokenIntrospectionService struct {
+	userRepo          *repository.UserRepository
+	sessionRepo       *repository.SessionRepository
+	userRoleRepo      *repository.UserRoleRepository
+	introspectionRepo *repository.IntrospectionRepository
+	jwtUtil           *jwt.JWTUtil
+}
+
+func NewTokenIntrospectionService() *TokenIntrospectionService {
+	return &TokenIntrospectionService{
+		userRepo:          repository.NewUserRepository(),
+		sessionRepo:       repository.NewSessionRepository(),
+		userRoleRepo:      repository.NewUserRoleRepository(),
+		introspectionRepo: repository.NewIntrospectionRepository(),
+		jwtUtil:           jwt.NewJWTUtil(),
+	}
+}
+
+// Introspect validates an access token the way ValidateToken does and
+// returns its user, memberships and, when merchantID is set, the roles and
+// permissions in that merchant. An unusable token returns ErrTokenInvalid,
+// ErrSessionRevoked or ErrUserInactive.
+//
+// The signature, session and user status are checked on every call, so a
+// revoked session or suspended user is rejected right away. Memberships and
+// permissions come from a short-lived cache that is dropped when the
+// session is revoked and refreshed when the user's permission version in
+// the merchant changes.
+func (s *TokenIntrospectionService) Introspect(token string, merchantID uuid.UUID) (*repository.IntrospectionRecord, error) {
+	claims, err := s.jwtUtil.ValidateAccessToken(token)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	tokenHash := s.jwtUtil.HashToken(token)
+	valid, err := s.sessionRepo.IsSessionValid(tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session: %w", err)
+	}
+	if !valid {
+		return nil, ErrSessionRevoked
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user.Status != model.UserStatusActive {
+		return nil, ErrUserInactive
+	}
+
+	scope := ""
+	var version int64
+	if merchantID != uuid.Nil {
+		scope = merchantID.String()
+		// Read the version before the permissions, as BatchCheckPermissions does
+		if version, err = s.userRoleRepo.GetPermissionsVersion(userID, merchantID); err != nil {
+			return nil, fmt.Errorf("failed to read permissions version: %w", err)
+		}
+	}
+
+	if record, ok := s.introspectionRepo.Get(tokenHash, scope); ok && record.PermissionsVersion == version {
+		record.EmailVerified = user.EmailVerified
+		return record, nil
+	}
+
+	record, err := s.buildIntrospection(user, claims, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	record.PermissionsVersion = version
+	record.EmailVerified = user.EmailVerified
+
+	ttl := IntrospectionCacheTTL()
+	if untilExpiry := time.Until(record.ExpiresAt); untilExpiry < ttl {
+		ttl = untilExpiry
+	}
+	_ = s.introspectionRepo.Set(tokenHash, scope, record, ttl)
+
+	return record, nil
+}
+
+func (s *TokenIntrospectionService) buildIntrospection(user *model.User, claims *jwt.JWTClaims, merchantID uuid.UUID) (*repository.IntrospectionRecord, error) {
+	memberships, err := s.userRoleRepo.GetUserMemberships(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memberships: %w", err)
+	}
+
+	record := &repository.IntrospectionRecord{
+		UserID:         user.ID.String(),
+		Email:          user.Email,
+		MerchantIDs:    []string{},
+		Roles:          []repository.IntrospectedRole{},
+		ImpersonatorID: claims.ImpersonatorID,
+	}
+	if claims.ExpiresAt != nil {
+		record.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	seen := make(map[uuid.UUID]bool, len(memberships))
+	for _, m := range memberships {
+		if !seen[m.MerchantID] {
+			seen[m.MerchantID] = true
+			record.MerchantIDs = append(record.MerchantIDs, m.MerchantID.String())
+		}
+		if merchantID != uuid.Nil && m.MerchantID != merchantID {
+			continue
+		}
+		role := repository.IntrospectedRole{
+			MerchantID: m.MerchantID.String(),
+			RoleID:     m.RoleID.String(),
+		}
+		if m.Role != nil {
+			role.RoleName = m.Role.Name
+		}
+		record.Roles = append(record.Roles, role)
+	}
+
+	if merchantID == uuid.Nil {
+		return record, nil
+	}
+
+	permissions, err := s.userRoleRepo.GetUserPermissions(user.ID, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+	record.Scopes = make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		record.Scopes = append(record.Scopes, p.Resource+":"+p.Action)
+	}
+
+	return record, nil
+}
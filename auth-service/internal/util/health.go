@@ -0,0 +1,120 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/auth-service/config"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits"
+	"github.com/rhaloubi/payment-gateway/auth-service/inits/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	readinessInterval = 10 * time.Second
+	readinessTimeout  = 2 * time.Second
+)
+
+// Readiness serves grpc.health.v1 from dependency checks: SERVING while every
+// check passes, NOT_SERVING otherwise, for the server as a whole ("") and for
+// each registered service
+type Readiness struct {
+	server   *health.Server
+	services []string
+
+	mu       sync.Mutex
+	failures map[string]error
+}
+
+// RegisterHealth registers the health service, and server reflection when
+// GRPC_REFLECTION is true. Call it after every other service is registered.
+func RegisterHealth(srv *grpc.Server) *Readiness {
+	r := &Readiness{
+		server:   health.NewServer(),
+		failures: make(map[string]error),
+	}
+	for name := range srv.GetServiceInfo() {
+		r.services = append(r.services, name)
+	}
+	healthpb.RegisterHealthServer(srv, r.server)
+
+	if config.GetEnv("GRPC_REFLECTION") == "true" {
+		reflection.Register(srv)
+		logger.Log.Warn("gRPC reflection enabled")
+	}
+	return r
+}
+
+// Report records the result of one dependency check and updates the serving
+// status
+func (r *Readiness) Report(check string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, wasFailing := r.failures[check]
+	if err != nil {
+		r.failures[check] = err
+		if !wasFailing {
+			logger.Log.Error("Readiness check failing", zap.String("check", check), zap.Error(err))
+		}
+	} else {
+		delete(r.failures, check)
+		if wasFailing {
+			logger.Log.Info("Readiness check recovered", zap.String("check", check))
+		}
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if len(r.failures) > 0 {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	r.server.SetServingStatus("", status)
+	for _, name := range r.services {
+		r.server.SetServingStatus(name, status)
+	}
+}
+
+// Run pings Postgres and Redis now and then every 10 seconds until ctx ends
+func (r *Readiness) Run(ctx context.Context) {
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Readiness) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	sqlDB, err := inits.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	r.Report("postgres", wrapCheck(err))
+	r.Report("redis", wrapCheck(inits.RDB.Ping(ctx).Err()))
+}
+
+// Shutdown reports NOT_SERVING so clients move off before the server stops
+func (r *Readiness) Shutdown() {
+	r.server.Shutdown()
+}
+
+func wrapCheck(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("ping failed: %w", err)
+}
@@ -5,17 +5,20 @@ import (
 	"net"
 
 	"github.com/rhaloubi/payment-gateway/auth-service/config"
+	"github.com/rhaloubi/payment-gateway/auth-service/internal/metrics"
 	"google.golang.org/grpc"
 )
 
-// InitGRPC initializes and returns the gRPC server (without registering services)
-func InitGRPC() *grpc.Server {
+// InitGRPC starts the gRPC server. Services are registered through register
+// before the server starts serving.
+func InitGRPC(register func(*grpc.Server)) *grpc.Server {
 	lis, err := net.Listen("tcp", ":"+config.GetEnv("GRPC_PORT"))
 	if err != nil {
 		log.Fatalf("❌ Failed to listen on port %s: %v", config.GetEnv("GRPC_PORT"), err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryServerInterceptor()))
+	register(grpcServer)
 
 	// Start serving in a goroutine
 	go func() {
@@ -465,6 +465,272 @@ func (x *GetUserRolesResponse) GetRoles() []*Role {
 	return nil
 }
 
+type PermissionCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionCheck) Reset() {
+	*x = PermissionCheck{}
+	mi := &file_proto_role_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionCheck) ProtoMessage() {}
+
+func (x *PermissionCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionCheck.ProtoReflect.Descriptor instead.
+func (*PermissionCheck) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PermissionCheck) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *PermissionCheck) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type PermissionCheckResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Allowed       bool                   `protobuf:"varint,3,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionCheckResult) Reset() {
+	*x = PermissionCheckResult{}
+	mi := &file_proto_role_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionCheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionCheckResult) ProtoMessage() {}
+
+func (x *PermissionCheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionCheckResult.ProtoReflect.Descriptor instead.
+func (*PermissionCheckResult) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PermissionCheckResult) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *PermissionCheckResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PermissionCheckResult) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+// known_version is the version from a previous response; when it still
+// matches, not_modified is set and permissions is left empty.
+type BatchCheckPermissionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Checks        []*PermissionCheck     `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
+	KnownVersion  int64                  `protobuf:"varint,4,opt,name=known_version,json=knownVersion,proto3" json:"known_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckPermissionsRequest) Reset() {
+	*x = BatchCheckPermissionsRequest{}
+	mi := &file_proto_role_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckPermissionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckPermissionsRequest) ProtoMessage() {}
+
+func (x *BatchCheckPermissionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckPermissionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCheckPermissionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchCheckPermissionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsRequest) GetChecks() []*PermissionCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *BatchCheckPermissionsRequest) GetKnownVersion() int64 {
+	if x != nil {
+		return x.KnownVersion
+	}
+	return 0
+}
+
+type BatchCheckPermissionsResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	UserId        string                   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MerchantId    string                   `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Results       []*PermissionCheckResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	Version       int64                    `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	NotModified   bool                     `protobuf:"varint,5,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	Permissions   []string                 `protobuf:"bytes,6,rep,name=permissions,proto3" json:"permissions,omitempty"` // "resource:action"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckPermissionsResponse) Reset() {
+	*x = BatchCheckPermissionsResponse{}
+	mi := &file_proto_role_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckPermissionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckPermissionsResponse) ProtoMessage() {}
+
+func (x *BatchCheckPermissionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckPermissionsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCheckPermissionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchCheckPermissionsResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsResponse) GetResults() []*PermissionCheckResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchCheckPermissionsResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *BatchCheckPermissionsResponse) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+func (x *BatchCheckPermissionsResponse) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
 var File_proto_role_service_proto protoreflect.FileDescriptor
 
 const file_proto_role_service_proto_rawDesc = "" +
@@ -508,11 +774,33 @@ const file_proto_role_service_proto_rawDesc = "" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
 	"\vmerchant_id\x18\x02 \x01(\tR\n" +
 	"merchantId\x12!\n" +
-	"\x05roles\x18\x03 \x03(\v2\v.proto.RoleR\x05roles2\x95\x02\n" +
+	"\x05roles\x18\x03 \x03(\v2\v.proto.RoleR\x05roles\"E\n" +
+	"\x0fPermissionCheck\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\"e\n" +
+	"\x15PermissionCheckResult\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x18\n" +
+	"\aallowed\x18\x03 \x01(\bR\aallowed\"\xad\x01\n" +
+	"\x1cBatchCheckPermissionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12.\n" +
+	"\x06checks\x18\x03 \x03(\v2\x16.proto.PermissionCheckR\x06checks\x12#\n" +
+	"\rknown_version\x18\x04 \x01(\x03R\fknownVersion\"\xf0\x01\n" +
+	"\x1dBatchCheckPermissionsResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x126\n" +
+	"\aresults\x18\x03 \x03(\v2\x1c.proto.PermissionCheckResultR\aresults\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\x12!\n" +
+	"\fnot_modified\x18\x05 \x01(\bR\vnotModified\x12 \n" +
+	"\vpermissions\x18\x06 \x03(\tR\vpermissions2\xf9\x02\n" +
 	"\vRoleService\x12h\n" +
 	"\x17AssignMerchantOwnerRole\x12%.proto.AssignMerchantOwnerRoleRequest\x1a&.proto.AssignMerchantOwnerRoleResponse\x12G\n" +
 	"\fGetUserRoles\x12\x1a.proto.GetUserRolesRequest\x1a\x1b.proto.GetUserRolesResponse\x12S\n" +
-	"\x10AssignRoleToUser\x12\x1e.proto.AssignRoleToUserRequest\x1a\x1f.proto.AssignRoleToUserResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
+	"\x10AssignRoleToUser\x12\x1e.proto.AssignRoleToUserRequest\x1a\x1f.proto.AssignRoleToUserResponse\x12b\n" +
+	"\x15BatchCheckPermissions\x12#.proto.BatchCheckPermissionsRequest\x1a$.proto.BatchCheckPermissionsResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
 
 var (
 	file_proto_role_service_proto_rawDescOnce sync.Once
@@ -526,7 +814,7 @@ func file_proto_role_service_proto_rawDescGZIP() []byte {
 	return file_proto_role_service_proto_rawDescData
 }
 
-var file_proto_role_service_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_role_service_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_role_service_proto_goTypes = []any{
 	(*AssignMerchantOwnerRoleRequest)(nil),  // 0: proto.AssignMerchantOwnerRoleRequest
 	(*AssignMerchantOwnerRoleResponse)(nil), // 1: proto.AssignMerchantOwnerRoleResponse
@@ -535,20 +823,28 @@ var file_proto_role_service_proto_goTypes = []any{
 	(*GetUserRolesRequest)(nil),             // 4: proto.GetUserRolesRequest
 	(*Role)(nil),                            // 5: proto.Role
 	(*GetUserRolesResponse)(nil),            // 6: proto.GetUserRolesResponse
+	(*PermissionCheck)(nil),                 // 7: proto.PermissionCheck
+	(*PermissionCheckResult)(nil),           // 8: proto.PermissionCheckResult
+	(*BatchCheckPermissionsRequest)(nil),    // 9: proto.BatchCheckPermissionsRequest
+	(*BatchCheckPermissionsResponse)(nil),   // 10: proto.BatchCheckPermissionsResponse
 }
 var file_proto_role_service_proto_depIdxs = []int32{
-	5, // 0: proto.GetUserRolesResponse.roles:type_name -> proto.Role
-	0, // 1: proto.RoleService.AssignMerchantOwnerRole:input_type -> proto.AssignMerchantOwnerRoleRequest
-	4, // 2: proto.RoleService.GetUserRoles:input_type -> proto.GetUserRolesRequest
-	2, // 3: proto.RoleService.AssignRoleToUser:input_type -> proto.AssignRoleToUserRequest
-	1, // 4: proto.RoleService.AssignMerchantOwnerRole:output_type -> proto.AssignMerchantOwnerRoleResponse
-	6, // 5: proto.RoleService.GetUserRoles:output_type -> proto.GetUserRolesResponse
-	3, // 6: proto.RoleService.AssignRoleToUser:output_type -> proto.AssignRoleToUserResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	5,  // 0: proto.GetUserRolesResponse.roles:type_name -> proto.Role
+	7,  // 1: proto.BatchCheckPermissionsRequest.checks:type_name -> proto.PermissionCheck
+	8,  // 2: proto.BatchCheckPermissionsResponse.results:type_name -> proto.PermissionCheckResult
+	0,  // 3: proto.RoleService.AssignMerchantOwnerRole:input_type -> proto.AssignMerchantOwnerRoleRequest
+	4,  // 4: proto.RoleService.GetUserRoles:input_type -> proto.GetUserRolesRequest
+	2,  // 5: proto.RoleService.AssignRoleToUser:input_type -> proto.AssignRoleToUserRequest
+	9,  // 6: proto.RoleService.BatchCheckPermissions:input_type -> proto.BatchCheckPermissionsRequest
+	1,  // 7: proto.RoleService.AssignMerchantOwnerRole:output_type -> proto.AssignMerchantOwnerRoleResponse
+	6,  // 8: proto.RoleService.GetUserRoles:output_type -> proto.GetUserRolesResponse
+	3,  // 9: proto.RoleService.AssignRoleToUser:output_type -> proto.AssignRoleToUserResponse
+	10, // 10: proto.RoleService.BatchCheckPermissions:output_type -> proto.BatchCheckPermissionsResponse
+	7,  // [7:11] is the sub-list for method output_type
+	3,  // [3:7] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_proto_role_service_proto_init() }
@@ -562,7 +858,7 @@ func file_proto_role_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_role_service_proto_rawDesc), len(file_proto_role_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,6 +22,7 @@ const (
 	RoleService_AssignMerchantOwnerRole_FullMethodName = "/proto.RoleService/AssignMerchantOwnerRole"
 	RoleService_GetUserRoles_FullMethodName            = "/proto.RoleService/GetUserRoles"
 	RoleService_AssignRoleToUser_FullMethodName        = "/proto.RoleService/AssignRoleToUser"
+	RoleService_BatchCheckPermissions_FullMethodName   = "/proto.RoleService/BatchCheckPermissions"
 )
 
 // RoleServiceClient is the client API for RoleService service.
@@ -31,6 +32,7 @@ type RoleServiceClient interface {
 	AssignMerchantOwnerRole(ctx context.Context, in *AssignMerchantOwnerRoleRequest, opts ...grpc.CallOption) (*AssignMerchantOwnerRoleResponse, error)
 	GetUserRoles(ctx context.Context, in *GetUserRolesRequest, opts ...grpc.CallOption) (*GetUserRolesResponse, error)
 	AssignRoleToUser(ctx context.Context, in *AssignRoleToUserRequest, opts ...grpc.CallOption) (*AssignRoleToUserResponse, error)
+	BatchCheckPermissions(ctx context.Context, in *BatchCheckPermissionsRequest, opts ...grpc.CallOption) (*BatchCheckPermissionsResponse, error)
 }
 
 type roleServiceClient struct {
@@ -71,6 +73,16 @@ func (c *roleServiceClient) AssignRoleToUser(ctx context.Context, in *AssignRole
 	return out, nil
 }
 
+func (c *roleServiceClient) BatchCheckPermissions(ctx context.Context, in *BatchCheckPermissionsRequest, opts ...grpc.CallOption) (*BatchCheckPermissionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCheckPermissionsResponse)
+	err := c.cc.Invoke(ctx, RoleService_BatchCheckPermissions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RoleServiceServer is the server API for RoleService service.
 // All implementations must embed UnimplementedRoleServiceServer
 // for forward compatibility.
@@ -78,6 +90,7 @@ type RoleServiceServer interface {
 	AssignMerchantOwnerRole(context.Context, *AssignMerchantOwnerRoleRequest) (*AssignMerchantOwnerRoleResponse, error)
 	GetUserRoles(context.Context, *GetUserRolesRequest) (*GetUserRolesResponse, error)
 	AssignRoleToUser(context.Context, *AssignRoleToUserRequest) (*AssignRoleToUserResponse, error)
+	BatchCheckPermissions(context.Context, *BatchCheckPermissionsRequest) (*BatchCheckPermissionsResponse, error)
 	mustEmbedUnimplementedRoleServiceServer()
 }
 
@@ -97,6 +110,9 @@ func (UnimplementedRoleServiceServer) GetUserRoles(context.Context, *GetUserRole
 func (UnimplementedRoleServiceServer) AssignRoleToUser(context.Context, *AssignRoleToUserRequest) (*AssignRoleToUserResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AssignRoleToUser not implemented")
 }
+func (UnimplementedRoleServiceServer) BatchCheckPermissions(context.Context, *BatchCheckPermissionsRequest) (*BatchCheckPermissionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCheckPermissions not implemented")
+}
 func (UnimplementedRoleServiceServer) mustEmbedUnimplementedRoleServiceServer() {}
 func (UnimplementedRoleServiceServer) testEmbeddedByValue()                     {}
 
@@ -172,6 +188,24 @@ func _RoleService_AssignRoleToUser_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RoleService_BatchCheckPermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCheckPermissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoleServiceServer).BatchCheckPermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RoleService_BatchCheckPermissions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoleServiceServer).BatchCheckPermissions(ctx, req.(*BatchCheckPermissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RoleService_ServiceDesc is the grpc.ServiceDesc for RoleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +225,10 @@ var RoleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AssignRoleToUser",
 			Handler:    _RoleService_AssignRoleToUser_Handler,
 		},
+		{
+			MethodName: "BatchCheckPermissions",
+			Handler:    _RoleService_BatchCheckPermissions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/role_service.proto",
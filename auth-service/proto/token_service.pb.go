@@ -0,0 +1,347 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: proto/token_service.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// merchant_id is optional; when set, roles and scopes are limited to that
+// merchant.
+type IntrospectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectRequest) Reset() {
+	*x = IntrospectRequest{}
+	mi := &file_proto_token_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectRequest) ProtoMessage() {}
+
+func (x *IntrospectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_token_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectRequest.ProtoReflect.Descriptor instead.
+func (*IntrospectRequest) Descriptor() ([]byte, []int) {
+	return file_proto_token_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IntrospectRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *IntrospectRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type MerchantRole struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	RoleId        string                 `protobuf:"bytes,2,opt,name=role_id,json=roleId,proto3" json:"role_id,omitempty"`
+	RoleName      string                 `protobuf:"bytes,3,opt,name=role_name,json=roleName,proto3" json:"role_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerchantRole) Reset() {
+	*x = MerchantRole{}
+	mi := &file_proto_token_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerchantRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerchantRole) ProtoMessage() {}
+
+func (x *MerchantRole) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_token_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerchantRole.ProtoReflect.Descriptor instead.
+func (*MerchantRole) Descriptor() ([]byte, []int) {
+	return file_proto_token_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MerchantRole) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *MerchantRole) GetRoleId() string {
+	if x != nil {
+		return x.RoleId
+	}
+	return ""
+}
+
+func (x *MerchantRole) GetRoleName() string {
+	if x != nil {
+		return x.RoleName
+	}
+	return ""
+}
+
+// An invalid, expired or revoked token is reported with active = false and
+// a reason; every other field is then empty.
+type IntrospectResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Active             bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	Reason             string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	UserId             string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email              string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	MerchantIds        []string               `protobuf:"bytes,5,rep,name=merchant_ids,json=merchantIds,proto3" json:"merchant_ids,omitempty"`
+	Roles              []*MerchantRole        `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes             []string               `protobuf:"bytes,7,rep,name=scopes,proto3" json:"scopes,omitempty"`                                                    // "resource:action", only with merchant_id
+	PermissionsVersion int64                  `protobuf:"varint,8,opt,name=permissions_version,json=permissionsVersion,proto3" json:"permissions_version,omitempty"` // Only with merchant_id, see BatchCheckPermissions
+	ExpiresAt          string                 `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                             // RFC3339
+	ImpersonatorId     string                 `protobuf:"bytes,10,opt,name=impersonator_id,json=impersonatorId,proto3" json:"impersonator_id,omitempty"`             // Set on impersonation tokens
+	EmailVerified      bool                   `protobuf:"varint,11,opt,name=email_verified,json=emailVerified,proto3" json:"email_verified,omitempty"`               // Read fresh on every call
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *IntrospectResponse) Reset() {
+	*x = IntrospectResponse{}
+	mi := &file_proto_token_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectResponse) ProtoMessage() {}
+
+func (x *IntrospectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_token_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectResponse.ProtoReflect.Descriptor instead.
+func (*IntrospectResponse) Descriptor() ([]byte, []int) {
+	return file_proto_token_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IntrospectResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *IntrospectResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetMerchantIds() []string {
+	if x != nil {
+		return x.MerchantIds
+	}
+	return nil
+}
+
+func (x *IntrospectResponse) GetRoles() []*MerchantRole {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *IntrospectResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *IntrospectResponse) GetPermissionsVersion() int64 {
+	if x != nil {
+		return x.PermissionsVersion
+	}
+	return 0
+}
+
+func (x *IntrospectResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetImpersonatorId() string {
+	if x != nil {
+		return x.ImpersonatorId
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetEmailVerified() bool {
+	if x != nil {
+		return x.EmailVerified
+	}
+	return false
+}
+
+var File_proto_token_service_proto protoreflect.FileDescriptor
+
+const file_proto_token_service_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/token_service.proto\x12\x05proto\"J\n" +
+	"\x11IntrospectRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"e\n" +
+	"\fMerchantRole\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x17\n" +
+	"\arole_id\x18\x02 \x01(\tR\x06roleId\x12\x1b\n" +
+	"\trole_name\x18\x03 \x01(\tR\broleName\"\xf9\x02\n" +
+	"\x12IntrospectResponse\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12!\n" +
+	"\fmerchant_ids\x18\x05 \x03(\tR\vmerchantIds\x12)\n" +
+	"\x05roles\x18\x06 \x03(\v2\x13.proto.MerchantRoleR\x05roles\x12\x16\n" +
+	"\x06scopes\x18\a \x03(\tR\x06scopes\x12/\n" +
+	"\x13permissions_version\x18\b \x01(\x03R\x12permissionsVersion\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\tR\texpiresAt\x12'\n" +
+	"\x0fimpersonator_id\x18\n" +
+	" \x01(\tR\x0eimpersonatorId\x12%\n" +
+	"\x0eemail_verified\x18\v \x01(\bR\remailVerified2Q\n" +
+	"\fTokenService\x12A\n" +
+	"\n" +
+	"Introspect\x12\x18.proto.IntrospectRequest\x1a\x19.proto.IntrospectResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
+
+var (
+	file_proto_token_service_proto_rawDescOnce sync.Once
+	file_proto_token_service_proto_rawDescData []byte
+)
+
+func file_proto_token_service_proto_rawDescGZIP() []byte {
+	file_proto_token_service_proto_rawDescOnce.Do(func() {
+		file_proto_token_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_token_service_proto_rawDesc), len(file_proto_token_service_proto_rawDesc)))
+	})
+	return file_proto_token_service_proto_rawDescData
+}
+
+var file_proto_token_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_token_service_proto_goTypes = []any{
+	(*IntrospectRequest)(nil),  // 0: proto.IntrospectRequest
+	(*MerchantRole)(nil),       // 1: proto.MerchantRole
+	(*IntrospectResponse)(nil), // 2: proto.IntrospectResponse
+}
+var file_proto_token_service_proto_depIdxs = []int32{
+	1, // 0: proto.IntrospectResponse.roles:type_name -> proto.MerchantRole
+	0, // 1: proto.TokenService.Introspect:input_type -> proto.IntrospectRequest
+	2, // 2: proto.TokenService.Introspect:output_type -> proto.IntrospectResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_token_service_proto_init() }
+func file_proto_token_service_proto_init() {
+	if File_proto_token_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_token_service_proto_rawDesc), len(file_proto_token_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_token_service_proto_goTypes,
+		DependencyIndexes: file_proto_token_service_proto_depIdxs,
+		MessageInfos:      file_proto_token_service_proto_msgTypes,
+	}.Build()
+	File_proto_token_service_proto = out.File
+	file_proto_token_service_proto_goTypes = nil
+	file_proto_token_service_proto_depIdxs = nil
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // merchant timezones must resolve in minimal images
 
 	"github.com/rhaloubi/payment-gateway/merchant-service/config"
 	"github.com/rhaloubi/payment-gateway/merchant-service/inits"
 	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/api"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/handler"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/metrics"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/merchant-service/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
+var offboardingService *service.OffboardingService
+
 func init() {
 	if config.GetEnv("APP_MODE") == "" {
 		inits.InitDotEnv()
@@ -19,7 +30,8 @@ func init() {
 	inits.InitDB()
 	inits.InitRedis()
 	logger.Init()
-	api.SetupMerchantRoutes()
+	offboardingService = service.NewOffboardingService()
+	api.SetupMerchantRoutes(offboardingService)
 }
 
 func main() {
@@ -28,6 +40,31 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.ListenForPermissionInvalidations(ctx)
+	go offboardingService.RunWorker(ctx)
+
+	// Internal gRPC API used by the transaction service for payouts.
+	// grpc.health.v1 reports NOT_SERVING while Postgres or Redis is unreachable.
+	var readiness *inits.Readiness
+	grpcServer := inits.InitGRPC(func(s *grpc.Server) {
+		pb.RegisterPayoutAccountServiceServer(s, handler.NewGRPCPayoutAccountService())
+		readiness = inits.RegisterHealth(s)
+	})
+	go readiness.Run(ctx)
+
+	// Prometheus metrics are served only when METRICS_PORT is set
+	if metricsPort := config.GetEnv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Log.Error("Metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
+
 	go func() {
 		if err := inits.R.Run(); err != nil {
 			logger.Log.Error("Server error", zap.Error(err))
@@ -38,6 +75,11 @@ func main() {
 
 	<-stop
 	logger.Log.Warn("🛑 Shutting down gracefully...")
+	cancel()
+
+	logger.Log.Info("🧹 Stopping gRPC server...")
+	readiness.Shutdown()
+	grpcServer.GracefulStop()
 
 	// ✅ Close Redis connection
 	if err := inits.RDB.Close(); err != nil {
@@ -26,3 +26,22 @@ func GetEnvWithDefault(key, defaultValue string) string {
 	}
 	return value
 }
+
+// DefaultRegion is the region of a single-region deployment
+const DefaultRegion = "primary"
+
+// Region is the home region this replica serves, from REGION. Resources
+// created here are tagged with it.
+func Region() string {
+	return strings.ToLower(GetEnvWithDefault("REGION", DefaultRegion))
+}
+
+// GetRegionalEnv reads a per-region override of key before key itself, so
+// DATABASE_DSN_EU_WEST_1 wins over DATABASE_DSN when REGION is eu-west-1
+func GetRegionalEnv(key string) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(Region(), "-", "_"))
+	if value := GetEnv(key + "_" + suffix); value != "" {
+		return value
+	}
+	return GetEnv(key)
+}
@@ -0,0 +1,33 @@
+package inits
+
+import (
+	"log"
+	"net"
+
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/metrics"
+	"google.golang.org/grpc"
+)
+
+// InitGRPC starts the internal gRPC server. Services are registered through
+// register before the server starts serving.
+func InitGRPC(register func(*grpc.Server)) *grpc.Server {
+	port := config.GetEnvWithDefault("GRPC_PORT", "50054")
+
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("❌ Failed to listen on port %s: %v", port, err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryServerInterceptor()))
+	register(grpcServer)
+
+	go func() {
+		log.Printf("🚀 gRPC server running on :%s", port)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("❌ Failed to serve gRPC: %v", err)
+		}
+	}()
+
+	return grpcServer
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -8,6 +9,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/merchant-service/config"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"go.uber.org/zap"
 )
 
 // JWTClaims represents the JWT claims structure
@@ -15,11 +18,22 @@ type JWTClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Type   string `json:"type"` // "access" or "refresh"
+
+	// Set by auth-service when a support user impersonates this user
+	ImpersonatorID  string `json:"impersonator_id,omitempty"`
+	ImpersonationID string `json:"impersonation_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenIntrospector confirms with auth-service that a token's session is
+// still live, which the signature alone cannot tell
+type TokenIntrospector interface {
+	IntrospectToken(ctx context.Context, token string) (active bool, reason string, err error)
+}
+
 type JWTValidator struct {
-	secretKey string
+	secretKey    string
+	introspector TokenIntrospector
 }
 
 func NewJWTValidator() *JWTValidator {
@@ -32,6 +46,13 @@ func NewJWTValidator() *JWTValidator {
 	}
 }
 
+// WithIntrospector makes AuthMiddleware also reject tokens whose session
+// was revoked
+func (v *JWTValidator) WithIntrospector(introspector TokenIntrospector) *JWTValidator {
+	v.introspector = introspector
+	return v
+}
+
 func (v *JWTValidator) ValidateToken(tokenString string) (*JWTClaims, error) {
 	// Remove "Bearer " prefix if present
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
@@ -86,11 +107,49 @@ func (v *JWTValidator) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if v.introspector != nil {
+			active, reason, err := v.introspector.IntrospectToken(c.Request.Context(), strings.TrimPrefix(authHeader, "Bearer "))
+			if err != nil {
+				logger.Log.Error("Token introspection failed", zap.Error(err))
+				c.JSON(503, gin.H{
+					"success": false,
+					"error":   "authentication service unavailable",
+				})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(401, gin.H{
+					"success": false,
+					"error":   "Invalid or expired token: " + reason,
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		// Set user info in context for handlers to use
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 
+		if claims.ImpersonatorID == "" {
+			c.Next()
+			return
+		}
+
+		// Tag impersonated requests in the audit log
+		c.Set("impersonator_id", claims.ImpersonatorID)
+		c.Header("X-Impersonated-By", claims.ImpersonatorID)
+
 		c.Next()
+
+		logger.Log.Info("Impersonated action",
+			zap.String("impersonation_id", claims.ImpersonationID),
+			zap.String("impersonator_id", claims.ImpersonatorID),
+			zap.String("user_id", claims.UserID),
+			zap.String("action", c.Request.Method+" "+c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+		)
 	}
 }
 
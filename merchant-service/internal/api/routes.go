@@ -2,14 +2,16 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
 	"github.com/rhaloubi/payment-gateway/merchant-service/inits"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/handler"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/middleware"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/openapi"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
 )
 
-func SetupMerchantRoutes() {
+func SetupMerchantRoutes(offboardingService *service.OffboardingService) {
 	router := inits.R
 
 	authClient := client.NewAuthServiceClient()
@@ -17,6 +19,9 @@ func SetupMerchantRoutes() {
 	teamHandler := handler.NewTeamHandler()
 	settingsHandler := handler.NewSettingsHandler()
 	apiKeyHandler := handler.NewAPIKeyHandler(authClient, service.NewTeamService())
+	offboardingHandler := handler.NewOffboardingHandler(offboardingService)
+	bankAccountHandler := handler.NewBankAccountHandler()
+	onboardingHandler := handler.NewOnboardingHandler(service.NewOnboardingService(authClient))
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -24,6 +29,21 @@ func SetupMerchantRoutes() {
 		})
 	})
 
+	// Service-to-service lookups, only exposed when a shared token is set
+	if token := config.GetEnv("INTERNAL_API_TOKEN"); token != "" {
+		internal := router.Group("/internal/v1")
+		internal.Use(middleware.RequireInternalToken(token))
+		{
+			internal.GET("/merchants/:merchant_id/region", merchantHandler.GetMerchantRegion)
+			internal.GET("/merchants/:merchant_id/account-data", merchantHandler.GetAccountData)
+			internal.POST("/bank-accounts/:account_id/review", bankAccountHandler.ReviewBankDocument)
+			internal.POST("/api-keys/resolve", apiKeyHandler.ResolveAPIKey)
+		}
+	}
+
+	// The spec is public so SDK and CLI generators can fetch it without a token
+	router.GET("/api/v1/openapi.json", openapi.Serve)
+
 	v1 := router.Group("/api/v1")
 	v1.Use(middleware.AuthMiddleware())
 	{
@@ -38,6 +58,7 @@ func SetupMerchantRoutes() {
 				apiKeys.POST("", apiKeyHandler.CreateAPIKey)
 				apiKeys.GET("/merchant/:merchant_id", apiKeyHandler.GetMerchantAPIKeys)
 				apiKeys.PATCH("/:merchant_id/:id/deactivate", apiKeyHandler.DeactivateAPIKey)
+				apiKeys.PUT("/:merchant_id/:id/allowed-cidrs", apiKeyHandler.UpdateAllowedCIDRs)
 				apiKeys.DELETE("/:merchant_id/:id", apiKeyHandler.DeleteAPIKey)
 
 			}
@@ -51,21 +72,36 @@ func SetupMerchantRoutes() {
 				merchantGroup.GET("/team", middleware.RequireRolePermission("read"), teamHandler.GetTeamMembers)
 				merchantGroup.GET("/invitations", middleware.RequireRolePermission("read"), teamHandler.GetPendingInvitations)
 				merchantGroup.GET("/settings", middleware.RequireRolePermission("read"), settingsHandler.GetSettings)
+				merchantGroup.GET("/offboarding", middleware.RequireRolePermission("read"), offboardingHandler.GetOffboarding)
+				merchantGroup.GET("/bank-accounts", middleware.RequireRolePermission("read"), bankAccountHandler.ListBankAccounts)
+				merchantGroup.GET("/bank-accounts/:account_id", middleware.RequireRolePermission("read"), bankAccountHandler.GetBankAccount)
 
 				// Update operations - Owner and Admin only
 				merchantGroup.PATCH("", middleware.RequireRolePermission("update"), merchantHandler.UpdateMerchant)
 				merchantGroup.PATCH("/settings", middleware.RequireRolePermission("update"), settingsHandler.UpdateSettings)
 				merchantGroup.PATCH("/team/:user_id", middleware.RequireRolePermission("update"), teamHandler.UpdateTeamMemberRole)
+				merchantGroup.POST("/bank-accounts/:account_id/micro-deposits", middleware.RequireRolePermission("update"), bankAccountHandler.StartMicroDeposits)
+				merchantGroup.POST("/bank-accounts/:account_id/micro-deposits/confirm", middleware.RequireRolePermission("update"), bankAccountHandler.ConfirmMicroDeposits)
+				merchantGroup.POST("/bank-accounts/:account_id/documents", middleware.RequireRolePermission("update"), bankAccountHandler.UploadBankDocument)
+				merchantGroup.POST("/bank-accounts/:account_id/default", middleware.RequireRolePermission("update"), bankAccountHandler.SetDefaultBankAccount)
 
 				// Create operations - Owner, Admin, and Manager
 				merchantGroup.POST("/team/invite", middleware.RequireRolePermission("create"), teamHandler.InviteTeamMember)
+				merchantGroup.POST("/bank-accounts", middleware.RequireRolePermission("create"), bankAccountHandler.AddBankAccount)
 
 				// Delete operations - Owner only (Admin cannot delete)
 				merchantGroup.DELETE("", middleware.RequireRolePermission("delete"), merchantHandler.DeleteMerchant)
 				merchantGroup.DELETE("/team/:user_id", middleware.RequireRolePermission("delete"), teamHandler.RemoveTeamMember)
+				merchantGroup.DELETE("/bank-accounts/:account_id", middleware.RequireRolePermission("delete"), bankAccountHandler.DeleteBankAccount)
+
+				// Account closure - Owner only
+				merchantGroup.POST("/offboarding", middleware.RequireRolePermission("delete"), offboardingHandler.StartOffboarding)
+				merchantGroup.DELETE("/offboarding", middleware.RequireRolePermission("delete"), offboardingHandler.CancelOffboarding)
 			}
 		}
 
+		v1.GET("/onboarding/status", onboardingHandler.GetOnboardingStatus)
+
 		// Invitation routes (public with auth)
 		invitations := v1.Group("/invitations")
 		{
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/merchant-service/config"
 	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/merchant-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -22,6 +23,7 @@ type AuthServiceClient struct {
 	grpcClient   pb.RoleServiceClient
 	grpcTimeout  time.Duration
 	apiKeyClient pb.APIKeyServiceClient
+	tokenClient  pb.TokenServiceClient
 }
 
 func NewAuthServiceClient() *AuthServiceClient {
@@ -36,7 +38,10 @@ func NewAuthServiceClient() *AuthServiceClient {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("auth-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -47,13 +52,14 @@ func NewAuthServiceClient() *AuthServiceClient {
 		grpcConn:     conn,
 		grpcClient:   pb.NewRoleServiceClient(conn),
 		apiKeyClient: pb.NewAPIKeyServiceClient(conn),
+		tokenClient:  pb.NewTokenServiceClient(conn),
 		grpcTimeout:  400 * time.Millisecond, // Adjustable timeout for gRPC calls
 	}
 }
 
 // AssignMerchantOwnerRole assigns the merchant owner role via gRPC
-func (c *AuthServiceClient) AssignMerchantOwnerRole(userID, merchantID uuid.UUID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) AssignMerchantOwnerRole(ctx context.Context, userID, merchantID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.AssignMerchantOwnerRoleRequest{
@@ -78,8 +84,8 @@ func (c *AuthServiceClient) AssignMerchantOwnerRole(userID, merchantID uuid.UUID
 
 // ... (Keep your existing GetUserRoles or other HTTP methods here unchanged)
 
-func (c *AuthServiceClient) AssignRoleToUser(userID, merchantID, roleId, assignedBy uuid.UUID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) AssignRoleToUser(ctx context.Context, userID, merchantID, roleId, assignedBy uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.AssignRoleToUserRequest{
@@ -102,15 +108,86 @@ func (c *AuthServiceClient) AssignRoleToUser(userID, merchantID, roleId, assigne
 	return nil
 }
 
+// CheckPermissions checks "resource:action" permissions for a user in a merchant.
+// Answers come from the local cache when fresh; otherwise auth-service is asked
+// with the cached version so an unchanged set is not re-sent.
+func (c *AuthServiceClient) CheckPermissions(ctx context.Context, userID, merchantID uuid.UUID, checks ...string) (map[string]bool, error) {
+	cached := sharedPermissionCache.get(userID, merchantID)
+	if cached != nil && time.Since(cached.fetchedAt) < permissionCacheTTL {
+		return cached.answer(checks), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	req := &pb.BatchCheckPermissionsRequest{
+		UserId:     userID.String(),
+		MerchantId: merchantID.String(),
+	}
+	if cached != nil {
+		req.KnownVersion = cached.version
+	}
+
+	resp, err := c.grpcClient.BatchCheckPermissions(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC BatchCheckPermissions failed: %w", err)
+	}
+
+	entry := &cachedPermissions{version: resp.Version, fetchedAt: time.Now()}
+	if resp.NotModified && cached != nil {
+		entry.granted = cached.granted
+	} else {
+		entry.granted = make(map[string]bool, len(resp.Permissions))
+		for _, p := range resp.Permissions {
+			entry.granted[p] = true
+		}
+	}
+	sharedPermissionCache.set(userID, merchantID, entry)
+
+	return entry.answer(checks), nil
+}
+
+// IntrospectToken asks auth-service whether an access token is still
+// usable; a token whose session was revoked or whose user was suspended
+// comes back inactive with the reason
+func (c *AuthServiceClient) IntrospectToken(ctx context.Context, token string) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.tokenClient.Introspect(ctx, &pb.IntrospectRequest{Token: token})
+	if err != nil {
+		return false, "", fmt.Errorf("gRPC Introspect failed: %w", err)
+	}
+	return resp.Active, resp.Reason, nil
+}
+
+// IsEmailVerified reports whether the user behind an access token has
+// confirmed their email address
+func (c *AuthServiceClient) IsEmailVerified(ctx context.Context, token string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.tokenClient.Introspect(ctx, &pb.IntrospectRequest{Token: token})
+	if err != nil {
+		return false, fmt.Errorf("gRPC Introspect failed: %w", err)
+	}
+	if !resp.Active {
+		return false, fmt.Errorf("token is not active: %s", resp.Reason)
+	}
+	return resp.EmailVerified, nil
+}
+
 // CreateAPIKey calls gRPC to create an API key
-func (c *AuthServiceClient) CreateAPIKey(merchantID, createdBy uuid.UUID, name string) (*pb.CreateAPIKeyResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) CreateAPIKey(ctx context.Context, merchantID, createdBy uuid.UUID, name string, allowedCIDRs []string, testMode bool) (*pb.CreateAPIKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.CreateAPIKeyRequest{
-		MerchantId: merchantID.String(),
-		Name:       name,
-		CreatedBy:  createdBy.String(),
+		MerchantId:   merchantID.String(),
+		Name:         name,
+		CreatedBy:    createdBy.String(),
+		AllowedCidrs: allowedCIDRs,
+		TestMode:     testMode,
 	}
 
 	resp, err := c.apiKeyClient.CreateAPIKey(ctx, req)
@@ -121,8 +198,8 @@ func (c *AuthServiceClient) CreateAPIKey(merchantID, createdBy uuid.UUID, name s
 }
 
 // GetMerchantAPIKeys calls gRPC to get API keys for a merchant
-func (c *AuthServiceClient) GetMerchantAPIKeys(merchantID uuid.UUID) (*pb.GetMerchantAPIKeysResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) GetMerchantAPIKeys(ctx context.Context, merchantID uuid.UUID) (*pb.GetMerchantAPIKeysResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.GetMerchantAPIKeysRequest{
@@ -136,9 +213,21 @@ func (c *AuthServiceClient) GetMerchantAPIKeys(merchantID uuid.UUID) (*pb.GetMer
 	return resp, nil
 }
 
+// GetInfoByAPIKey calls gRPC to look up the merchant an API key belongs to
+func (c *AuthServiceClient) GetInfoByAPIKey(ctx context.Context, apiKey string) (*pb.GetInfoByAPIKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.apiKeyClient.GetInfoByAPIKey(ctx, &pb.GetInfoByAPIKeyRequest{ApiKey: apiKey})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC GetInfoByAPIKey failed: %w", err)
+	}
+	return resp, nil
+}
+
 // DeactivateAPIKey calls gRPC to deactivate an API key
-func (c *AuthServiceClient) DeactivateAPIKey(keyID, merchantID uuid.UUID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) DeactivateAPIKey(ctx context.Context, keyID, merchantID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.DeactivateAPIKeyRequest{
@@ -154,8 +243,8 @@ func (c *AuthServiceClient) DeactivateAPIKey(keyID, merchantID uuid.UUID) error
 }
 
 // DeleteAPIKey calls gRPC to delete an API key
-func (c *AuthServiceClient) DeleteAPIKey(keyID, merchantID uuid.UUID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) DeleteAPIKey(ctx context.Context, keyID, merchantID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	req := &pb.DeleteAPIKeyRequest{
@@ -170,6 +259,24 @@ func (c *AuthServiceClient) DeleteAPIKey(keyID, merchantID uuid.UUID) error {
 	return nil
 }
 
+// UpdateAPIKeyAllowedCIDRs calls gRPC to replace an API key's IP allowlist
+func (c *AuthServiceClient) UpdateAPIKeyAllowedCIDRs(ctx context.Context, keyID, merchantID uuid.UUID, allowedCIDRs []string) (*pb.UpdateAPIKeyAllowedCIDRsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	req := &pb.UpdateAPIKeyAllowedCIDRsRequest{
+		Id:           keyID.String(),
+		MerchantId:   merchantID.String(),
+		AllowedCidrs: allowedCIDRs,
+	}
+
+	resp, err := c.apiKeyClient.UpdateAPIKeyAllowedCIDRs(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC UpdateAPIKeyAllowedCIDRs failed: %w", err)
+	}
+	return resp, nil
+}
+
 // Close closes the gRPC connection
 func (c *AuthServiceClient) Close() error {
 	return c.grpcConn.Close()
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/metrics"
+	pb "github.com/rhaloubi/payment-gateway/merchant-service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Tokenization key purposes shredded when a merchant closes
+const (
+	KeyPurposePaymentPII = "payment_pii"
+	KeyPurposeCardData   = "card_data"
+)
+
+// KeyManagementClient revokes a merchant's card tokens and encryption keys
+// in the tokenization service
+type KeyManagementClient struct {
+	grpcConn    *grpc.ClientConn
+	grpcTimeout time.Duration
+	keyClient   pb.KeyManagementServiceClient
+}
+
+func NewKeyManagementClient() (*KeyManagementClient, error) {
+	grpcAddress := config.GetEnv("TOKENIZATION_SERVICE_GRPC_URL")
+	if grpcAddress == "" {
+		grpcAddress = "localhost:50052"
+	}
+
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("tokenization-service")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial key management service: %w", err)
+	}
+
+	return &KeyManagementClient{
+		grpcConn:    conn,
+		grpcTimeout: 10 * time.Second, // revoking a large vault takes a while
+		keyClient:   pb.NewKeyManagementServiceClient(conn),
+	}, nil
+}
+
+// RevokeMerchantTokens revokes every active card token of the merchant
+func (c *KeyManagementClient) RevokeMerchantTokens(ctx context.Context, merchantID, revokedBy uuid.UUID, reason string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.keyClient.RevokeMerchantTokens(ctx, &pb.RevokeMerchantTokensRequest{
+		MerchantId: merchantID.String(),
+		RevokedBy:  revokedBy.String(),
+		Reason:     reason,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("gRPC RevokeMerchantTokens failed: %w", err)
+	}
+	if resp.Error != "" {
+		return 0, errors.New(resp.Error)
+	}
+	return int(resp.RevokedTokens), nil
+}
+
+// ShredMerchantKeys revokes the merchant's keys for a purpose, making the
+// data encrypted under them unrecoverable
+func (c *KeyManagementClient) ShredMerchantKeys(ctx context.Context, merchantID, requestedBy uuid.UUID, purpose, reason string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.keyClient.ShredMerchantKeys(ctx, &pb.ShredMerchantKeysRequest{
+		MerchantId:  merchantID.String(),
+		Purpose:     purpose,
+		RequestedBy: requestedBy.String(),
+		Reason:      reason,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("gRPC ShredMerchantKeys failed: %w", err)
+	}
+	if resp.Error != "" {
+		return int(resp.ShreddedKeys), errors.New(resp.Error)
+	}
+	return int(resp.ShreddedKeys), nil
+}
+
+// Close closes the gRPC connection
+func (c *KeyManagementClient) Close() error {
+	return c.grpcConn.Close()
+}
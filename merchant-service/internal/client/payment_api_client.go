@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
+)
+
+var ErrPaymentAPINotConfigured = errors.New("INTERNAL_API_TOKEN is not set")
+
+// PaymentAPIClient calls payment-api-service's internal endpoints, which are
+// guarded by the shared INTERNAL_API_TOKEN
+type PaymentAPIClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewPaymentAPIClient() *PaymentAPIClient {
+	baseURL := config.GetEnv("PAYMENT_API_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8004"
+	}
+
+	return &PaymentAPIClient{
+		baseURL:    baseURL,
+		token:      config.GetEnv("INTERNAL_API_TOKEN"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExportJob is the part of a payment-api export job the offboarding needs
+type ExportJob struct {
+	ID          uuid.UUID `json:"id"`
+	Resource    string    `json:"resource"`
+	Status      string    `json:"status"`
+	RowCount    int       `json:"row_count"`
+	DownloadURL string    `json:"download_url,omitempty"`
+}
+
+// SetLifecycle stops (winding_down) or closes a merchant's payment processing.
+// refundsUntil bounds the refund window while winding down.
+func (c *PaymentAPIClient) SetLifecycle(ctx context.Context, merchantID uuid.UUID, status string, refundsUntil *time.Time) error {
+	body := map[string]interface{}{"status": status}
+	if refundsUntil != nil {
+		body["refunds_until"] = refundsUntil
+	}
+	path := fmt.Sprintf("/internal/v1/merchants/%s/lifecycle", merchantID)
+	return c.do(ctx, http.MethodPut, path, body, nil)
+}
+
+// CreateFinalExports queues exports of the merchant's whole history
+func (c *PaymentAPIClient) CreateFinalExports(ctx context.Context, merchantID uuid.UUID) ([]ExportJob, error) {
+	var jobs []ExportJob
+	path := fmt.Sprintf("/internal/v1/merchants/%s/final-exports", merchantID)
+	if err := c.do(ctx, http.MethodPost, path, nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetExport returns an export job and, once complete, its download URL
+func (c *PaymentAPIClient) GetExport(ctx context.Context, merchantID, exportID uuid.UUID) (*ExportJob, error) {
+	var job ExportJob
+	path := fmt.Sprintf("/internal/v1/merchants/%s/exports/%s", merchantID, exportID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DisplaySettings is what payment-api needs to render dates, amounts and
+// receipts for a merchant
+type DisplaySettings struct {
+	Timezone          string
+	Locale            string
+	NumberFormat      string
+	SendEmailReceipts bool
+	NotificationEmail string
+
+	// Shown on customer receipts
+	StatementDescriptor string
+	BusinessName        string
+	LogoURL             string
+	BrandColor          string
+}
+
+// SetDisplaySettings tells payment-api how to render dates and amounts for
+// the merchant, whether and how to email receipts to its customers and
+// where to send the merchant's own notices
+func (c *PaymentAPIClient) SetDisplaySettings(ctx context.Context, merchantID uuid.UUID, settings DisplaySettings) error {
+	body := map[string]interface{}{
+		"timezone":             settings.Timezone,
+		"locale":               settings.Locale,
+		"number_format":        settings.NumberFormat,
+		"send_email_receipts":  settings.SendEmailReceipts,
+		"statement_descriptor": settings.StatementDescriptor,
+		"business_name":        settings.BusinessName,
+		"logo_url":             settings.LogoURL,
+		"brand_color":          settings.BrandColor,
+	}
+	if settings.NotificationEmail != "" {
+		body["notification_email"] = settings.NotificationEmail
+	}
+	path := fmt.Sprintf("/internal/v1/merchants/%s/display-settings", merchantID)
+	return c.do(ctx, http.MethodPut, path, body, nil)
+}
+
+// OnboardingProgress is what payment-api knows of a merchant's onboarding
+type OnboardingProgress struct {
+	FirstTestPaymentAt *time.Time `json:"first_test_payment_at"`
+	FirstLivePaymentAt *time.Time `json:"first_live_payment_at"`
+	WebhookConfigured  bool       `json:"webhook_configured"`
+}
+
+// GetOnboardingProgress returns the merchant's first test and live payments
+// and whether it has set up a webhook
+func (c *PaymentAPIClient) GetOnboardingProgress(ctx context.Context, merchantID uuid.UUID) (*OnboardingProgress, error) {
+	var progress OnboardingProgress
+	path := fmt.Sprintf("/internal/v1/merchants/%s/onboarding", merchantID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func (c *PaymentAPIClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	if c.token == "" {
+		return ErrPaymentAPINotConfigured
+	}
+	return doInternalJSON(ctx, c.httpClient, method, c.baseURL+path, "X-Internal-Token", c.token, body, out)
+}
+
+// doInternalJSON sends a JSON request to another service and decodes the
+// {"success", "data", "error"} envelope they all respond with
+func doInternalJSON(ctx context.Context, httpClient *http.Client, method, url, tokenHeader, token string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(tokenHeader, token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("unexpected response from %s (status %d)", url, resp.StatusCode)
+	}
+	if resp.StatusCode >= 300 || !envelope.Success {
+		return fmt.Errorf("%s %s failed (status %d): %s", method, url, resp.StatusCode, envelope.Error)
+	}
+
+	if out != nil && len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+		return json.Unmarshal(envelope.Data, out)
+	}
+	return nil
+}
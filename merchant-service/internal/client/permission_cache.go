@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// permissionsInvalidationChannel must match auth-service's
+// repository.PermissionsInvalidationChannel
+const permissionsInvalidationChannel = "auth:permissions:invalidated"
+
+// permissionCacheTTL bounds staleness if an invalidation message is missed
+const permissionCacheTTL = 5 * time.Minute
+
+type cachedPermissions struct {
+	granted   map[string]bool // "resource:action"
+	version   int64
+	fetchedAt time.Time
+}
+
+func (cp *cachedPermissions) answer(checks []string) map[string]bool {
+	result := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		result[check] = cp.granted[check]
+	}
+	return result
+}
+
+// permissionCache is shared by every AuthServiceClient in the process
+type permissionCache struct {
+	mu      sync.RWMutex
+	entries map[string]*cachedPermissions // user_id:merchant_id
+}
+
+var sharedPermissionCache = &permissionCache{entries: make(map[string]*cachedPermissions)}
+
+func permissionCacheKey(userID, merchantID uuid.UUID) string {
+	return userID.String() + ":" + merchantID.String()
+}
+
+func (pc *permissionCache) get(userID, merchantID uuid.UUID) *cachedPermissions {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	return pc.entries[permissionCacheKey(userID, merchantID)]
+}
+
+func (pc *permissionCache) set(userID, merchantID uuid.UUID, entry *cachedPermissions) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.entries[permissionCacheKey(userID, merchantID)] = entry
+}
+
+func (pc *permissionCache) delete(key string) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	delete(pc.entries, key)
+}
+
+func (pc *permissionCache) clear() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.entries = make(map[string]*cachedPermissions)
+}
+
+// permissionInvalidation mirrors auth-service's repository.PermissionInvalidation
+type permissionInvalidation struct {
+	UserID     string `json:"user_id,omitempty"`
+	MerchantID string `json:"merchant_id,omitempty"`
+	Version    int64  `json:"version,omitempty"`
+}
+
+// ListenForPermissionInvalidations drops cached permissions when auth-service
+// publishes a change. It blocks until ctx is cancelled.
+func ListenForPermissionInvalidations(ctx context.Context) {
+	pubsub := inits.RDB.Subscribe(ctx, permissionsInvalidationChannel)
+	defer pubsub.Close()
+
+	logger.Log.Info("Listening for permission invalidations",
+		zap.String("channel", permissionsInvalidationChannel))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return
+			}
+
+			var event permissionInvalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				logger.Log.Warn("Invalid permission invalidation message", zap.Error(err))
+				continue
+			}
+
+			if event.UserID == "" {
+				sharedPermissionCache.clear()
+				continue
+			}
+			sharedPermissionCache.delete(event.UserID + ":" + event.MerchantID)
+		}
+	}
+}
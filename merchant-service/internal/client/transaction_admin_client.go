@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
+)
+
+var ErrTransactionAdminNotConfigured = errors.New("TRANSACTION_ADMIN_TOKEN is not set")
+
+// TransactionAdminClient calls transaction-service's admin API, which is
+// only served when that service has ADMIN_API_TOKEN set
+type TransactionAdminClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewTransactionAdminClient() *TransactionAdminClient {
+	baseURL := config.GetEnv("TRANSACTION_ADMIN_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8005"
+	}
+
+	return &TransactionAdminClient{
+		baseURL:    baseURL,
+		token:      config.GetEnv("TRANSACTION_ADMIN_TOKEN"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SettlementBatch is the part of a settlement batch the offboarding records
+type SettlementBatch struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// CreateFinalSettlement settles everything the merchant has not been paid
+// out for yet. It returns nil when there was nothing left to settle.
+func (c *TransactionAdminClient) CreateFinalSettlement(ctx context.Context, merchantID uuid.UUID) (*SettlementBatch, error) {
+	if c.token == "" {
+		return nil, ErrTransactionAdminNotConfigured
+	}
+
+	var batch *SettlementBatch
+	url := fmt.Sprintf("%s/admin/merchants/%s/final-settlement", c.baseURL, merchantID)
+	if err := doInternalJSON(ctx, c.httpClient, http.MethodPost, url, "X-Admin-Token", c.token, nil, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// SettlementSchedule is the part of a merchant's settlement schedule the
+// merchant controls. The payout delay and reserve are set by operators in
+// transaction-service.
+type SettlementSchedule struct {
+	Schedule        string `json:"schedule"`
+	Day             int    `json:"day"`
+	MinPayoutAmount int64  `json:"min_payout_amount"`
+}
+
+// SetSettlementSchedule makes transaction-service batch the merchant's
+// payouts on schedule
+func (c *TransactionAdminClient) SetSettlementSchedule(ctx context.Context, merchantID uuid.UUID, schedule SettlementSchedule) error {
+	if c.token == "" {
+		return ErrTransactionAdminNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/admin/merchants/%s/settlement-schedule", c.baseURL, merchantID)
+	return doInternalJSON(ctx, c.httpClient, http.MethodPut, url, "X-Admin-Token", c.token, schedule, nil)
+}
+
+// SetSettlementTimezone makes transaction-service cut the merchant's
+// settlement days at midnight in timezone
+func (c *TransactionAdminClient) SetSettlementTimezone(ctx context.Context, merchantID uuid.UUID, timezone string) error {
+	if c.token == "" {
+		return ErrTransactionAdminNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/admin/merchants/%s/settlement-timezone", c.baseURL, merchantID)
+	return doInternalJSON(ctx, c.httpClient, http.MethodPut, url, "X-Admin-Token", c.token, map[string]string{"timezone": timezone}, nil)
+}
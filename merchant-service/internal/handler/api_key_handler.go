@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -20,8 +21,14 @@ func NewAPIKeyHandler(authClient *client.AuthServiceClient, teamService *service
 }
 
 type CreateAPIKeyRequest struct {
-	MerchantID string `json:"merchant_id" binding:"required,uuid"`
-	Name       string `json:"name" binding:"required"`
+	MerchantID   string   `json:"merchant_id" binding:"required,uuid"`
+	Name         string   `json:"name" binding:"required"`
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+	TestMode     bool     `json:"test_mode"`
+}
+
+type UpdateAllowedCIDRsRequest struct {
+	AllowedCIDRs []string `json:"allowed_cidrs"`
 }
 
 func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
@@ -32,14 +39,8 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 	}
 
 	// Get user ID from auth middleware
-	userIDStr, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
-		return
-	}
-	userID, err := uuid.Parse(userIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid user ID"})
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -59,9 +60,13 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authClient.CreateAPIKey(merchantID, userID, req.Name)
+	resp, err := h.authClient.CreateAPIKey(c.Request.Context(), merchantID, userID, req.Name, req.AllowedCIDRs, req.TestMode)
 	if err != nil {
 		st := status.Convert(err)
+		if st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": st.Message()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
 		return
 	}
@@ -70,10 +75,11 @@ func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
 		"success": true,
 		"data": gin.H{
 			"api_key": gin.H{
-				"id":         resp.Id,
-				"name":       resp.Name,
-				"key_prefix": resp.KeyPrefix,
-				"created_at": resp.CreatedAt,
+				"id":            resp.Id,
+				"name":          resp.Name,
+				"key_prefix":    resp.KeyPrefix,
+				"allowed_cidrs": req.AllowedCIDRs,
+				"created_at":    resp.CreatedAt,
 			},
 			"plain_key": resp.PlainKey,
 		},
@@ -90,14 +96,8 @@ func (h *APIKeyHandler) GetMerchantAPIKeys(c *gin.Context) {
 	}
 
 	// Get user ID from auth middleware
-	userIDStr, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
-		return
-	}
-	userID, err := uuid.Parse(userIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid user ID"})
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -111,7 +111,7 @@ func (h *APIKeyHandler) GetMerchantAPIKeys(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authClient.GetMerchantAPIKeys(merchantID)
+	resp, err := h.authClient.GetMerchantAPIKeys(c.Request.Context(), merchantID)
 	if err != nil {
 		st := status.Convert(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
@@ -122,12 +122,13 @@ func (h *APIKeyHandler) GetMerchantAPIKeys(c *gin.Context) {
 	var apiKeys []gin.H
 	for _, key := range resp.ApiKeys {
 		apiKeys = append(apiKeys, gin.H{
-			"id":           key.Id,
-			"name":         key.Name,
-			"key_prefix":   key.KeyPrefix,
-			"is_active":    key.IsActive,
-			"last_used_at": key.LastUsedAt,
-			"created_at":   key.CreatedAt,
+			"id":            key.Id,
+			"name":          key.Name,
+			"key_prefix":    key.KeyPrefix,
+			"is_active":     key.IsActive,
+			"last_used_at":  key.LastUsedAt,
+			"allowed_cidrs": key.AllowedCidrs,
+			"created_at":    key.CreatedAt,
 		})
 	}
 
@@ -152,14 +153,8 @@ func (h *APIKeyHandler) DeactivateAPIKey(c *gin.Context) {
 	}
 
 	// Get user ID from auth middleware
-	userIDStr, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
-		return
-	}
-	userID, err := uuid.Parse(userIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid user ID"})
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -173,7 +168,7 @@ func (h *APIKeyHandler) DeactivateAPIKey(c *gin.Context) {
 		return
 	}
 
-	err = h.authClient.DeactivateAPIKey(keyID, merchantID)
+	err = h.authClient.DeactivateAPIKey(c.Request.Context(), keyID, merchantID)
 	if err != nil {
 		st := status.Convert(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
@@ -198,14 +193,51 @@ func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
 	}
 
 	// Get user ID from auth middleware
-	userIDStr, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "unauthorized"})
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
-	userID, err := uuid.Parse(userIDStr.(string))
+
+	hasPermission, err := h.teamService.CheckUserPermission(merchantID, userID, "delete")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid user ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if !hasPermission {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": "forbidden"})
+		return
+	}
+	err = h.authClient.DeleteAPIKey(c.Request.Context(), keyID, merchantID)
+	if err != nil {
+		st := status.Convert(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "API key deleted successfully"})
+}
+
+func (h *APIKeyHandler) UpdateAllowedCIDRs(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid key ID"})
+		return
+	}
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "invalid merchant ID"})
+		return
+	}
+
+	var req UpdateAllowedCIDRsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	// Get user ID from auth middleware
+	userID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -218,12 +250,55 @@ func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"success": false, "error": "forbidden"})
 		return
 	}
-	err = h.authClient.DeleteAPIKey(keyID, merchantID)
+
+	resp, err := h.authClient.UpdateAPIKeyAllowedCIDRs(c.Request.Context(), keyID, merchantID, req.AllowedCIDRs)
 	if err != nil {
 		st := status.Convert(err)
-		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
+		switch st.Code() {
+		case codes.InvalidArgument:
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": st.Message()})
+		case codes.PermissionDenied:
+			c.JSON(http.StatusForbidden, gin.H{"success": false, "error": st.Message()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": st.Message()})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "API key deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"allowed_cidrs": resp.AllowedCidrs},
+		"message": resp.Message,
+	})
+}
+
+// ResolveAPIKey returns the merchant behind the key in X-API-Key, so the
+// gateway can rate limit by merchant. The key travels in a header to keep it
+// out of access logs.
+// POST /internal/v1/api-keys/resolve
+func (h *APIKeyHandler) ResolveAPIKey(c *gin.Context) {
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "X-API-Key header is required"})
+		return
+	}
+
+	resp, err := h.authClient.GetInfoByAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		switch status.Convert(err).Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+			c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "error": "auth service unavailable"})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "api key not found"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id": resp.MerchantId,
+			"key_id":      resp.Id,
+		},
+	})
 }
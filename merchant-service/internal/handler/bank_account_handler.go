@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+)
+
+// BankAccountHandler handles merchant payout bank account requests
+type BankAccountHandler struct {
+	bankAccountService *service.BankAccountService
+}
+
+// NewBankAccountHandler creates a new bank account handler
+func NewBankAccountHandler() *BankAccountHandler {
+	return &BankAccountHandler{
+		bankAccountService: service.NewBankAccountService(),
+	}
+}
+
+// AddBankAccountRequest represents a new payout bank account
+type AddBankAccountRequest struct {
+	AccountHolderName string `json:"account_holder_name" binding:"required"`
+	BankName          string `json:"bank_name"`
+	RIB               string `json:"rib" binding:"required_without=IBAN"`
+	IBAN              string `json:"iban"`
+}
+
+// ConfirmMicroDepositsRequest carries the two amounts, in centimes, the
+// merchant saw on their bank statement
+type ConfirmMicroDepositsRequest struct {
+	Amounts []int64 `json:"amounts" binding:"required,len=2"`
+}
+
+// ReviewBankDocumentRequest is an operator decision on an uploaded document
+type ReviewBankDocumentRequest struct {
+	Approve    *bool  `json:"approve" binding:"required"`
+	Reason     string `json:"reason"`
+	ReviewedBy string `json:"reviewed_by" binding:"required"`
+}
+
+// AddBankAccount adds a payout bank account pending verification
+// POST /api/v1/merchants/:id/bank-accounts
+func (h *BankAccountHandler) AddBankAccount(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	var req AddBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	account, err := h.bankAccountService.AddBankAccount(&service.AddBankAccountRequest{
+		MerchantID:        merchantID,
+		CreatedBy:         userUUID,
+		AccountHolderName: req.AccountHolderName,
+		BankName:          req.BankName,
+		RIB:               req.RIB,
+		IBAN:              req.IBAN,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrBankAccountExists) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Bank account added, verification required",
+		"data":    account,
+	})
+}
+
+// ListBankAccounts lists the merchant's payout bank accounts
+// GET /api/v1/merchants/:id/bank-accounts
+func (h *BankAccountHandler) ListBankAccounts(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	accounts, err := h.bankAccountService.ListBankAccounts(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list bank accounts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    accounts,
+	})
+}
+
+// GetBankAccount gets a payout bank account
+// GET /api/v1/merchants/:id/bank-accounts/:account_id
+func (h *BankAccountHandler) GetBankAccount(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.bankAccountService.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	account.DocumentContent = nil
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// DeleteBankAccount removes a payout bank account
+// DELETE /api/v1/merchants/:id/bank-accounts/:account_id
+func (h *BankAccountHandler) DeleteBankAccount(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	if err := h.bankAccountService.DeleteBankAccount(merchantID, accountID, userUUID); err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bank account deleted",
+	})
+}
+
+// StartMicroDeposits sends two micro-deposits to verify the account
+// POST /api/v1/merchants/:id/bank-accounts/:account_id/micro-deposits
+func (h *BankAccountHandler) StartMicroDeposits(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	account, err := h.bankAccountService.StartMicroDeposits(merchantID, accountID, userUUID)
+	if err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Micro-deposits sent, confirm the amounts once they appear on your statement",
+		"data":    account,
+	})
+}
+
+// ConfirmMicroDeposits verifies the account with the micro-deposit amounts
+// POST /api/v1/merchants/:id/bank-accounts/:account_id/micro-deposits/confirm
+func (h *BankAccountHandler) ConfirmMicroDeposits(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	var req ConfirmMicroDepositsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	account, err := h.bankAccountService.ConfirmMicroDeposits(merchantID, accountID, userUUID, [2]int64{req.Amounts[0], req.Amounts[1]})
+	if err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Bank account verified",
+		"data":    account,
+	})
+}
+
+// UploadBankDocument uploads a RIB certificate or bank statement for review
+// POST /api/v1/merchants/:id/bank-accounts/:account_id/documents
+func (h *BankAccountHandler) UploadBankDocument(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxBankDocumentSize+64<<10)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "a file field of at most 5MB is required",
+		})
+		return
+	}
+	if fileHeader.Size > service.MaxBankDocumentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   service.ErrBankDocumentTooLarge.Error(),
+		})
+		return
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read file",
+		})
+		return
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, service.MaxBankDocumentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read file",
+		})
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(content)
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	account, err := h.bankAccountService.UploadDocument(merchantID, accountID, userUUID, fileHeader.Filename, contentType, content)
+	if err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Document uploaded, the account will be verified after review",
+		"data":    account,
+	})
+}
+
+// SetDefaultBankAccount makes a verified account the payout account
+// POST /api/v1/merchants/:id/bank-accounts/:account_id/default
+func (h *BankAccountHandler) SetDefaultBankAccount(c *gin.Context) {
+	merchantID, accountID, ok := parseBankAccountParams(c)
+	if !ok {
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	account, err := h.bankAccountService.SetDefault(merchantID, accountID, userUUID)
+	if err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Default payout account updated",
+		"data":    account,
+	})
+}
+
+// ReviewBankDocument approves or rejects an uploaded bank document
+// POST /internal/v1/bank-accounts/:account_id/review
+func (h *BankAccountHandler) ReviewBankDocument(c *gin.Context) {
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid bank account ID",
+		})
+		return
+	}
+
+	var req ReviewBankDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	account, err := h.bankAccountService.ReviewDocument(accountID, &service.ReviewBankDocumentRequest{
+		Approve:    *req.Approve,
+		Reason:     req.Reason,
+		ReviewedBy: req.ReviewedBy,
+	})
+	if err != nil {
+		respondBankAccountError(c, err)
+		return
+	}
+	account.DocumentContent = nil
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// parseBankAccountParams reads the merchant and bank account IDs from the path
+func parseBankAccountParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	accountID, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid bank account ID",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, accountID, true
+}
+
+// respondBankAccountError maps bank account errors to HTTP statuses
+func respondBankAccountError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, service.ErrBankAccountNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrBankAccountInvalidState), errors.Is(err, service.ErrBankAccountNotVerified):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrMicroDepositMismatch):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBankDocumentTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrBankDocumentTypeRejected):
+		status = http.StatusUnsupportedMediaType
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/merchant-service/proto"
+	"go.uber.org/zap"
+)
+
+// GRPCPayoutAccountService serves merchant payout accounts to the
+// transaction service when it builds settlement batches
+type GRPCPayoutAccountService struct {
+	pb.UnimplementedPayoutAccountServiceServer
+	bankAccountService *service.BankAccountService
+	merchantService    *service.MerchantService
+}
+
+func NewGRPCPayoutAccountService() *GRPCPayoutAccountService {
+	return &GRPCPayoutAccountService{
+		bankAccountService: service.NewBankAccountService(),
+		merchantService:    service.NewMerchantService(),
+	}
+}
+
+// GetPayoutAccount implements the gRPC method
+func (s *GRPCPayoutAccountService) GetPayoutAccount(ctx context.Context, req *pb.GetPayoutAccountRequest) (*pb.GetPayoutAccountResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.GetPayoutAccountResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	account, err := s.bankAccountService.GetPayoutAccount(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load payout account",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		return &pb.GetPayoutAccountResponse{Error: "failed to load payout account"}, nil
+	}
+	if account == nil {
+		return &pb.GetPayoutAccountResponse{Found: false}, nil
+	}
+
+	return &pb.GetPayoutAccountResponse{
+		Found: true,
+		Account: &pb.PayoutAccount{
+			Id:                account.ID.String(),
+			MerchantId:        account.MerchantID.String(),
+			AccountHolderName: account.AccountHolderName,
+			BankName:          account.BankName,
+			BankCode:          account.BankCode,
+			Iban:              account.IBAN,
+			Rib:               account.RIB,
+			Currency:          account.Currency,
+			VerifiedAt:        account.VerifiedAt.Time.Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// GetPayoutEligibility implements the gRPC method
+func (s *GRPCPayoutAccountService) GetPayoutEligibility(ctx context.Context, req *pb.GetPayoutEligibilityRequest) (*pb.GetPayoutEligibilityResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.GetPayoutEligibilityResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	merchant, err := s.merchantService.GetMerchantWithDetails(merchantID)
+	if err != nil {
+		return &pb.GetPayoutEligibilityResponse{Error: "merchant not found"}, nil
+	}
+
+	account, err := s.bankAccountService.GetPayoutAccount(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load payout account",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		return &pb.GetPayoutEligibilityResponse{Error: "failed to load payout account"}, nil
+	}
+
+	verificationStatus := string(model.VerificationStatusUnverified)
+	if merchant.Verification != nil {
+		verificationStatus = string(merchant.Verification.VerificationStatus)
+	}
+
+	return &pb.GetPayoutEligibilityResponse{
+		MerchantStatus:     string(merchant.Status),
+		VerificationStatus: verificationStatus,
+		HasPayoutAccount:   account != nil,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/merchantctx"
+)
+
+// requireMerchantContext returns the merchant and team member set by
+// RequireMerchantAccess, or answers 401 if the route isn't behind it
+func requireMerchantContext(c *gin.Context) (*merchantctx.MerchantContext, bool) {
+	mc, ok := merchantctx.Get(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "invalid merchant context",
+		})
+		return nil, false
+	}
+	return mc, true
+}
+
+// requireUserID returns the authenticated user, or answers 401
+func requireUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := merchantctx.UserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "unauthorized",
+		})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -11,15 +11,17 @@ import (
 
 // MerchantHandler handles merchant HTTP requests
 type MerchantHandler struct {
-	merchantService *service.MerchantService
-	teamService     *service.TeamService
+	merchantService    *service.MerchantService
+	teamService        *service.TeamService
+	bankAccountService *service.BankAccountService
 }
 
 // NewMerchantHandler creates a new merchant handler
 func NewMerchantHandler() *MerchantHandler {
 	return &MerchantHandler{
-		merchantService: service.NewMerchantService(),
-		teamService:     service.NewTeamService(),
+		merchantService:    service.NewMerchantService(),
+		teamService:        service.NewTeamService(),
+		bankAccountService: service.NewBankAccountService(),
 	}
 }
 
@@ -54,20 +56,8 @@ func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "unauthorizedd",
-		})
-		return
-	}
-	userUUID, err := uuid.Parse(userID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "invalid user ID",
-		})
+	userUUID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -89,7 +79,7 @@ func (h *MerchantHandler) CreateMerchant(c *gin.Context) {
 		return
 	}
 	// Create merchant
-	merchant, err := h.merchantService.CreateMerchant(&service.CreateMerchantRequest{
+	merchant, err := h.merchantService.CreateMerchant(c.Request.Context(), &service.CreateMerchantRequest{
 		OwnerID:      userUUID,
 		BusinessName: req.BusinessName,
 		LegalName:    req.LegalName,
@@ -129,8 +119,11 @@ func (h *MerchantHandler) GetMerchant(c *gin.Context) {
 	}
 
 	// Check if user has access to merchant
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	hasAccess, err := h.teamService.IsUserInMerchant(merchantID, userUUID)
 	if err != nil || !hasAccess {
@@ -172,8 +165,11 @@ func (h *MerchantHandler) GetMerchantDetails(c *gin.Context) {
 	}
 
 	// Check access
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	hasAccess, err := h.teamService.IsUserInMerchant(merchantID, userUUID)
 	if err != nil || !hasAccess {
@@ -209,21 +205,8 @@ func (h *MerchantHandler) GetMerchantDetails(c *gin.Context) {
 // ListUserMerchants lists all merchants for the authenticated user
 // GET /api/v1/merchants
 func (h *MerchantHandler) ListUserMerchants(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "unauthorized",
-		})
-		return
-	}
-
-	userUUID, err := uuid.Parse(userID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "invalid user ID",
-		})
+	userUUID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -273,8 +256,11 @@ func (h *MerchantHandler) UpdateMerchant(c *gin.Context) {
 	}
 
 	// Get user ID
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	// Check access
 	hasAccess, err := h.teamService.IsUserInMerchant(merchantID, userUUID)
@@ -329,8 +315,11 @@ func (h *MerchantHandler) DeleteMerchant(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	// Delete merchant (only owner can delete)
 	if err := h.merchantService.DeleteMerchant(merchantID, userUUID); err != nil {
@@ -347,6 +336,87 @@ func (h *MerchantHandler) DeleteMerchant(c *gin.Context) {
 	})
 }
 
+// GetAccountData returns what the merchant service holds about a merchant,
+// for payment-api's account export. Secrets (webhook secret, account
+// numbers, bank documents) are left out.
+// GET /internal/v1/merchants/:merchant_id/account-data
+func (h *MerchantHandler) GetAccountData(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	merchant, err := h.merchantService.GetMerchantWithDetails(merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "merchant not found",
+		})
+		return
+	}
+
+	members, err := h.teamService.GetTeamMembers(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load team",
+		})
+		return
+	}
+	team := make([]gin.H, len(members))
+	for i, member := range members {
+		team[i] = gin.H{
+			"user_id":    member.UserID,
+			"role_name":  member.RoleName,
+			"status":     member.Status,
+			"invited_by": member.InvitedBy,
+			"invited_at": member.InvitedAt,
+			"joined_at":  member.JoinedAt,
+		}
+	}
+
+	bankAccounts, err := h.bankAccountService.ListBankAccounts(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load bank accounts",
+		})
+		return
+	}
+
+	var settings gin.H
+	if s := merchant.Settings; s != nil {
+		settings = gin.H{
+			"default_currency":     s.DefaultCurrency,
+			"statement_descriptor": s.StatementDescriptor.String,
+			"timezone":             s.Timezone,
+			"locale":               s.Locale,
+			"number_format":        s.NumberFormat,
+			"webhook_url":          s.WebhookURL.String,
+			"notification_email":   s.NotificationEmail.String,
+			"send_email_receipts":  s.SendEmailReceipts,
+			"auto_settle":          s.AutoSettle,
+			"settle_schedule":      s.SettleSchedule,
+			"settle_day":           s.SettleDay,
+			"min_payout_amount":    s.MinPayoutAmount,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant":      formatMerchant(merchant),
+			"settings":      settings,
+			"team":          team,
+			"bank_accounts": bankAccounts,
+		},
+	})
+}
+
 // Helper function to format merchant response
 func formatMerchant(merchant *model.Merchant) gin.H {
 	return gin.H{
@@ -363,7 +433,39 @@ func formatMerchant(merchant *model.Merchant) gin.H {
 		"country_code":  merchant.CountryCode,
 		"currency_code": merchant.CurrencyCode,
 		"timezone":      merchant.Timezone,
+		"region":        merchant.Region,
 		"created_at":    merchant.CreatedAt,
 		"updated_at":    merchant.UpdatedAt,
 	}
 }
+
+// GetMerchantRegion returns a merchant's home region for the gateway's
+// region routing
+// GET /internal/v1/merchants/:merchant_id/region
+func (h *MerchantHandler) GetMerchantRegion(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	merchant, err := h.merchantService.GetMerchantByID(merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "merchant not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id": merchant.ID,
+			"region":      merchant.Region,
+		},
+	})
+}
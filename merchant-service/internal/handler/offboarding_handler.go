@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+)
+
+// OffboardingHandler handles merchant account closure requests
+type OffboardingHandler struct {
+	offboardingService *service.OffboardingService
+}
+
+// NewOffboardingHandler creates a new offboarding handler
+func NewOffboardingHandler(offboardingService *service.OffboardingService) *OffboardingHandler {
+	return &OffboardingHandler{
+		offboardingService: offboardingService,
+	}
+}
+
+// StartOffboardingRequest represents an account closure request
+type StartOffboardingRequest struct {
+	Reason       string `json:"reason"`
+	WindDownDays *int   `json:"wind_down_days"`
+}
+
+// StartOffboarding stops new payments and schedules the merchant for closure
+// POST /api/v1/merchants/:id/offboarding
+func (h *OffboardingHandler) StartOffboarding(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	var req StartOffboardingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	offboarding, err := h.offboardingService.StartOffboarding(c.Request.Context(), &service.StartOffboardingRequest{
+		MerchantID:   merchantID,
+		RequestedBy:  userUUID,
+		Reason:       req.Reason,
+		WindDownDays: req.WindDownDays,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrOffboardingInProgress), errors.Is(err, service.ErrMerchantAlreadyClosed):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Merchant offboarding started",
+		"data":    offboarding,
+	})
+}
+
+// GetOffboarding gets the merchant's offboarding progress
+// GET /api/v1/merchants/:id/offboarding
+func (h *OffboardingHandler) GetOffboarding(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	offboarding, err := h.offboardingService.GetOffboarding(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    offboarding,
+	})
+}
+
+// CancelOffboarding resumes payments during the wind-down period
+// DELETE /api/v1/merchants/:id/offboarding
+func (h *OffboardingHandler) CancelOffboarding(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant ID",
+		})
+		return
+	}
+
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
+
+	offboarding, err := h.offboardingService.CancelOffboarding(c.Request.Context(), merchantID, userUUID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrOffboardingNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrOffboardingTooLate):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Merchant offboarding cancelled",
+		"data":    offboarding,
+	})
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// OnboardingHandler serves the onboarding checklist shown by the dashboard
+// and the CLI
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+// NewOnboardingHandler creates a new onboarding handler
+func NewOnboardingHandler(onboardingService *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{
+		onboardingService: onboardingService,
+	}
+}
+
+// GetOnboardingStatus returns which onboarding steps the user and their
+// merchant have completed, with a link to each
+// GET /api/v1/onboarding/status?merchant_id=
+func (h *OnboardingHandler) GetOnboardingStatus(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+
+	merchantID := uuid.Nil
+	if raw := c.Query("merchant_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid merchant_id",
+			})
+			return
+		}
+		merchantID = parsed
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	status, err := h.onboardingService.GetStatus(c.Request.Context(), userID, token, merchantID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotMerchantMember) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "access denied - you are not a member of this merchant",
+			})
+			return
+		}
+		logger.Log.Error("Failed to load onboarding status", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load onboarding status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
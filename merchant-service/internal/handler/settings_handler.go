@@ -24,9 +24,17 @@ type UpdateSettingsRequest struct {
 	DefaultCurrency   string `json:"default_currency" binding:"omitempty,len=3"`
 	AutoSettle        *bool  `json:"auto_settle"`
 	SettleSchedule    string `json:"settle_schedule" binding:"omitempty,oneof=daily weekly monthly"`
+	SettleDay         *int   `json:"settle_day" binding:"omitempty,min=0,max=28"`
+	MinPayoutAmount   *int64 `json:"min_payout_amount" binding:"omitempty,min=0"`
 	WebhookURL        string `json:"webhook_url" binding:"omitempty,url"`
 	NotificationEmail string `json:"notification_email" binding:"omitempty,email"`
 	SendEmailReceipts *bool  `json:"send_email_receipts"`
+	Timezone          string `json:"timezone"`
+	Locale            string `json:"locale" binding:"omitempty,min=2,max=10"`
+	NumberFormat      string `json:"number_format" binding:"omitempty,oneof=space_comma comma_dot dot_comma"`
+
+	// Empty clears it
+	StatementDescriptor *string `json:"statement_descriptor"`
 }
 
 // GET /api/v1/merchants/:id/settings
@@ -77,8 +85,11 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	// Prepare updates
 	updates := make(map[string]interface{})
@@ -91,12 +102,36 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 	if req.SettleSchedule != "" {
 		updates["settle_schedule"] = req.SettleSchedule
 	}
+	if req.SettleDay != nil {
+		updates["settle_day"] = *req.SettleDay
+	}
+	if req.MinPayoutAmount != nil {
+		updates["min_payout_amount"] = *req.MinPayoutAmount
+	}
 	if req.WebhookURL != "" {
 		updates["webhook_url"] = req.WebhookURL
 	}
+	if req.NotificationEmail != "" {
+		updates["notification_email"] = req.NotificationEmail
+	}
+	if req.SendEmailReceipts != nil {
+		updates["send_email_receipts"] = *req.SendEmailReceipts
+	}
+	if req.Timezone != "" {
+		updates["timezone"] = req.Timezone
+	}
+	if req.Locale != "" {
+		updates["locale"] = req.Locale
+	}
+	if req.NumberFormat != "" {
+		updates["number_format"] = req.NumberFormat
+	}
+	if req.StatementDescriptor != nil {
+		updates["statement_descriptor"] = *req.StatementDescriptor
+	}
 
 	// Update settings
-	if err := h.settingsService.UpdateSettings(merchantID, updates, userUUID); err != nil {
+	if err := h.settingsService.UpdateSettings(c.Request.Context(), merchantID, updates, userUUID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   err.Error(),
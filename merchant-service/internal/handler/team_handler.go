@@ -58,21 +58,8 @@ func (h *TeamHandler) InviteTeamMember(c *gin.Context) {
 	roleName := req.RoleName
 
 	// Get inviter ID
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "unauthorizedd",
-		})
-		return
-	}
-
-	userUUID, err := uuid.Parse(userID.(string))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "invalid user ID",
-		})
+	userUUID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
@@ -115,19 +102,13 @@ func (h *TeamHandler) InviteTeamMember(c *gin.Context) {
 func (h *TeamHandler) AcceptInvitation(c *gin.Context) {
 	token := c.Param("token")
 
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "unauthorized",
-		})
+	userUUID, ok := requireUserID(c)
+	if !ok {
 		return
 	}
 
-	userUUID, _ := uuid.Parse(userID.(string))
-
 	// Accept invitation
-	if err := h.teamService.AcceptInvitation(token, userUUID); err != nil {
+	if err := h.teamService.AcceptInvitation(c.Request.Context(), token, userUUID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -193,8 +174,11 @@ func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
 	}
 
 	// Get who is removing
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	// Remove team member
 	if err := h.teamService.RemoveTeamMember(merchantID, removeUserID, userUUID); err != nil {
@@ -248,8 +232,11 @@ func (h *TeamHandler) UpdateTeamMemberRole(c *gin.Context) {
 	newRoleName := req.RoleName
 
 	// Get who is updating
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	mc, ok := requireMerchantContext(c)
+	if !ok {
+		return
+	}
+	userUUID := mc.UserID
 
 	// Update role
 	if err := h.teamService.UpdateTeamMemberRole(merchantID, targetUserID, newRoleID, userUUID, newRoleName); err != nil {
@@ -308,8 +295,10 @@ func (h *TeamHandler) CancelInvitation(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	userUUID, _ := uuid.Parse(userID.(string))
+	userUUID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
 
 	if err := h.teamService.CancelInvitation(invitationID, userUUID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
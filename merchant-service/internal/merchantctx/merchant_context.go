@@ -0,0 +1,54 @@
+// Package merchantctx carries the authenticated user and the merchant they
+// act for through a request as typed values, instead of untyped strings read
+// back with type assertions.
+package merchantctx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const contextKey = "merchant_context"
+
+// MerchantContext identifies the merchant a request acts on and the team
+// member making it. It is only set once membership has been checked.
+type MerchantContext struct {
+	MerchantID uuid.UUID
+	UserID     uuid.UUID
+}
+
+// Set stores the merchant context
+func Set(c *gin.Context, mc *MerchantContext) {
+	c.Set(contextKey, mc)
+}
+
+// Get returns the merchant context set by RequireMerchantAccess
+func Get(c *gin.Context) (*MerchantContext, bool) {
+	v, exists := c.Get(contextKey)
+	if !exists {
+		return nil, false
+	}
+	mc, ok := v.(*MerchantContext)
+	if !ok || mc.MerchantID == uuid.Nil || mc.UserID == uuid.Nil {
+		return nil, false
+	}
+	return mc, true
+}
+
+// UserID returns the authenticated user on routes that don't act on a
+// merchant. The JWT middleware stores the user ID as a string.
+func UserID(c *gin.Context) (uuid.UUID, bool) {
+	v, exists := c.Get("user_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+	s, ok := v.(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(s)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
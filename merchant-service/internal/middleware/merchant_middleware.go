@@ -7,12 +7,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/merchant-service/inits/jwt"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/merchantctx"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/service"
 )
 
-// AuthMiddleware returns the JWT auth middleware (lazy initialization)
+// AuthMiddleware returns the JWT auth middleware (lazy initialization).
+// Tokens are checked locally first, then introspected so revoked sessions
+// are rejected the same way auth-service rejects them.
 func AuthMiddleware() gin.HandlerFunc {
-	return jwt.NewJWTValidator().AuthMiddleware()
+	return jwt.NewJWTValidator().WithIntrospector(client.NewAuthServiceClient()).AuthMiddleware()
 }
 
 func RequireMerchantAccess() gin.HandlerFunc {
@@ -51,6 +55,11 @@ func RequireMerchantAccess() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		merchantctx.Set(c, &merchantctx.MerchantContext{
+			MerchantID: merchantID,
+			UserID:     userID,
+		})
 		c.Next()
 	}
 }
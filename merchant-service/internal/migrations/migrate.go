@@ -25,6 +25,8 @@ func RunMerchantMigrations() error {
 		&model.MerchantBranding{},
 		&model.MerchantVerification{},
 		&model.MerchantActivityLog{},
+		&model.MerchantOffboarding{},
+		&model.MerchantBankAccount{},
 	}
 
 	for _, m := range models {
@@ -42,6 +44,8 @@ func RollbackMerchantMigrations() error {
 
 	// Drop tables in reverse order
 	models := []interface{}{
+		&model.MerchantBankAccount{},
+		&model.MerchantOffboarding{},
 		&model.MerchantActivityLog{},
 		&model.MerchantVerification{},
 		&model.MerchantBranding{},
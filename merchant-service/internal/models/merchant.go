@@ -15,6 +15,7 @@ const (
 	MerchantStatusPendingReview MerchantStatus = "pending_review"
 	MerchantStatusActive        MerchantStatus = "active"
 	MerchantStatusSuspended     MerchantStatus = "suspended"
+	MerchantStatusClosing       MerchantStatus = "closing" // offboarding in progress
 	MerchantStatusClosed        MerchantStatus = "closed"
 )
 
@@ -53,6 +54,9 @@ type Merchant struct {
 	CurrencyCode string `gorm:"type:char(3);not null;default:'MAD'"` // Default currency
 	Timezone     string `gorm:"type:varchar(50);default:'Africa/Casablanca'"`
 
+	// Home region; the gateway pins the merchant's traffic to it
+	Region string `gorm:"type:varchar(32);not null;default:'primary';index"`
+
 	// Relationships
 	Settings     *MerchantSettings     `gorm:"foreignKey:MerchantID"`
 	BusinessInfo *MerchantBusinessInfo `gorm:"foreignKey:MerchantID"`
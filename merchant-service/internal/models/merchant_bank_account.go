@@ -0,0 +1,94 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BankAccountStatus is the verification state of a payout bank account
+type BankAccountStatus string
+
+const (
+	BankAccountStatusPendingVerification BankAccountStatus = "pending_verification" // added, no verification started
+	BankAccountStatusMicroDepositsSent   BankAccountStatus = "micro_deposits_sent"  // waiting for the merchant to confirm the amounts
+	BankAccountStatusPendingReview       BankAccountStatus = "pending_review"       // bank document uploaded, waiting for an operator
+	BankAccountStatusVerified            BankAccountStatus = "verified"
+	BankAccountStatusFailed              BankAccountStatus = "failed"
+)
+
+// BankAccountVerificationMethod is how ownership of the account is proven
+type BankAccountVerificationMethod string
+
+const (
+	BankAccountVerificationMicroDeposits BankAccountVerificationMethod = "micro_deposits"
+	BankAccountVerificationDocument      BankAccountVerificationMethod = "document"
+)
+
+// MerchantBankAccount is a bank account settlements are paid out to.
+// Only verified accounts receive payouts; the default one is used when
+// settlement batches are built.
+type MerchantBankAccount struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+
+	AccountHolderName string `gorm:"type:varchar(255);not null" json:"account_holder_name"`
+	BankName          string `gorm:"type:varchar(100);not null" json:"bank_name"`
+	BankCode          string `gorm:"type:varchar(3);not null" json:"bank_code"` // First 3 digits of the RIB
+	Country           string `gorm:"type:varchar(2);not null;default:'MA'" json:"country"`
+	Currency          string `gorm:"type:varchar(3);not null;default:'MAD'" json:"currency"`
+
+	// Account numbers are never returned in full
+	RIB   string `gorm:"type:varchar(24);not null" json:"-"`
+	IBAN  string `gorm:"type:varchar(34);not null" json:"-"`
+	Last4 string `gorm:"type:varchar(4);not null" json:"last4"`
+
+	Status             BankAccountStatus             `gorm:"type:varchar(30);not null;index" json:"status"`
+	VerificationMethod BankAccountVerificationMethod `gorm:"type:varchar(20)" json:"verification_method,omitempty"`
+	IsDefault          bool                          `gorm:"default:false" json:"is_default"`
+
+	// Micro-deposit verification (amounts in centimes)
+	MicroDepositAmount1  int64        `json:"-"`
+	MicroDepositAmount2  int64        `json:"-"`
+	MicroDepositAttempts int          `gorm:"default:0" json:"micro_deposit_attempts"`
+	MicroDepositsSentAt  sql.NullTime `json:"micro_deposits_sent_at"`
+
+	// Document verification (bank-issued RIB certificate or statement)
+	DocumentFilename    sql.NullString `gorm:"type:varchar(255)" json:"document_filename"`
+	DocumentContentType sql.NullString `gorm:"type:varchar(100)" json:"document_content_type"`
+	DocumentContent     []byte         `gorm:"type:bytea" json:"-"`
+	DocumentUploadedAt  sql.NullTime   `json:"document_uploaded_at"`
+	ReviewedBy          sql.NullString `gorm:"type:varchar(255)" json:"reviewed_by"`
+
+	VerifiedAt    sql.NullTime   `json:"verified_at"`
+	FailureReason sql.NullString `gorm:"type:text" json:"failure_reason"`
+	CreatedBy     uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+
+	// Relationships
+	Merchant *Merchant `gorm:"foreignKey:MerchantID" json:"-"`
+
+	// Timestamps
+	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for MerchantBankAccount
+func (MerchantBankAccount) TableName() string {
+	return "merchant_bank_accounts"
+}
+
+// BeforeCreate hook
+func (a *MerchantBankAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsVerified reports whether the account can receive payouts
+func (a *MerchantBankAccount) IsVerified() bool {
+	return a.Status == BankAccountStatusVerified
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OffboardingStatus is the state of a merchant offboarding
+type OffboardingStatus string
+
+const (
+	OffboardingStatusWindingDown OffboardingStatus = "winding_down" // payments stopped, refunds still allowed
+	OffboardingStatusClosing     OffboardingStatus = "closing"      // wind-down over, tearing down
+	OffboardingStatusCompleted   OffboardingStatus = "completed"
+	OffboardingStatusCancelled   OffboardingStatus = "cancelled"
+)
+
+// OffboardingStep is the next step the offboarding worker will run
+type OffboardingStep string
+
+const (
+	OffboardingStepSettle        OffboardingStep = "settle"
+	OffboardingStepExport        OffboardingStep = "export"
+	OffboardingStepRevokeAPIKeys OffboardingStep = "revoke_api_keys"
+	OffboardingStepRevokeTokens  OffboardingStep = "revoke_tokens"
+	OffboardingStepShredKeys     OffboardingStep = "shred_keys"
+	OffboardingStepClose         OffboardingStep = "close"
+	OffboardingStepDone          OffboardingStep = "done"
+)
+
+// MerchantOffboarding tracks a merchant's account closure across the
+// payment, transaction and tokenization services. Each step records when it
+// finished so the worker can resume after a failure.
+type MerchantOffboarding struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+
+	Status      OffboardingStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Step        OffboardingStep   `gorm:"type:varchar(30);not null" json:"step"`
+	Reason      sql.NullString    `gorm:"type:text" json:"reason"`
+	RequestedBy uuid.UUID         `gorm:"type:uuid;not null" json:"requested_by"`
+
+	// PreviousStatus is restored on the merchant if the offboarding is cancelled
+	PreviousStatus MerchantStatus `gorm:"type:varchar(20);not null" json:"previous_status"`
+
+	// Refund and dispute window after payments stop
+	WindDownDays   int       `gorm:"not null" json:"wind_down_days"`
+	WindDownEndsAt time.Time `gorm:"not null" json:"wind_down_ends_at"`
+
+	// Step progress
+	PaymentsStoppedAt sql.NullTime   `json:"payments_stopped_at"`
+	SettledAt         sql.NullTime   `json:"settled_at"`
+	SettlementBatchID sql.NullString `gorm:"type:uuid" json:"settlement_batch_id"`
+	ExportJobIDs      []byte         `gorm:"type:jsonb" json:"export_job_ids"` // JSON array of payment-api export job IDs
+	ExportedAt        sql.NullTime   `json:"exported_at"`
+	APIKeysRevokedAt  sql.NullTime   `json:"api_keys_revoked_at"`
+	TokensRevokedAt   sql.NullTime   `json:"tokens_revoked_at"`
+	RevokedTokens     int            `gorm:"default:0" json:"revoked_tokens"`
+	KeysShreddedAt    sql.NullTime   `json:"keys_shredded_at"`
+	CompletedAt       sql.NullTime   `json:"completed_at"`
+	CancelledAt       sql.NullTime   `json:"cancelled_at"`
+
+	LastError sql.NullString `gorm:"type:text" json:"last_error"`
+	Attempts  int            `gorm:"default:0" json:"attempts"`
+
+	// Relationships
+	Merchant *Merchant `gorm:"foreignKey:MerchantID" json:"-"`
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for MerchantOffboarding
+func (MerchantOffboarding) TableName() string {
+	return "merchant_offboardings"
+}
+
+// BeforeCreate hook
+func (o *MerchantOffboarding) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsOpen reports whether the offboarding is still in progress
+func (o *MerchantOffboarding) IsOpen() bool {
+	return o.Status == OffboardingStatusWindingDown || o.Status == OffboardingStatusClosing
+}
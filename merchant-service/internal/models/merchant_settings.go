@@ -20,6 +20,11 @@ type MerchantSettings struct {
 	// Display settings
 	StatementDescriptor sql.NullString `gorm:"type:varchar(22)"` // Shows on customer card statements (max 22 chars)
 
+	// Locale settings: receipts, statements, exports and settlement days
+	Timezone     string `gorm:"type:varchar(64);not null;default:'Africa/Casablanca'"` // IANA name
+	Locale       string `gorm:"type:varchar(10);not null;default:'fr-MA'"`
+	NumberFormat string `gorm:"type:varchar(20);not null;default:'space_comma'"` // space_comma, comma_dot, dot_comma
+
 	// Webhook settings
 	WebhookURL    sql.NullString `gorm:"type:varchar(500)"`
 	WebhookSecret sql.NullString `gorm:"type:varchar(255)"` // HMAC secret
@@ -28,9 +33,11 @@ type MerchantSettings struct {
 	NotificationEmail sql.NullString `gorm:"type:varchar(255)"`
 	SendEmailReceipts bool           `gorm:"default:true"`
 
-	// Settlement settings
-	AutoSettle     bool   `gorm:"default:true"`
-	SettleSchedule string `gorm:"type:varchar(20);default:'daily'"` // daily, weekly, monthly
+	// Settlement settings, pushed to transaction-service
+	AutoSettle      bool   `gorm:"default:true"`
+	SettleSchedule  string `gorm:"type:varchar(20);default:'daily'"` // daily, weekly, monthly
+	SettleDay       int    `gorm:"not null;default:0"`               // Last day of each period: weekday 1-7 or day of month 1-28; 0 is Sunday or month end
+	MinPayoutAmount int64  `gorm:"not null;default:0"`               // MAD minor units; smaller periods roll into the next
 
 	// Relationships
 	Merchant *Merchant `gorm:"foreignKey:MerchantID"`
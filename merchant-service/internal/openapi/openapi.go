@@ -0,0 +1,19 @@
+// Package openapi serves the API's OpenAPI 3 spec. openapi.json is
+// generated by cmd/openapi; edit the handlers, not the file.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Serve returns the spec
+// GET /api/v1/openapi.json
+func Serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type BankAccountRepository struct{}
+
+// NewBankAccountRepository creates a new bank account repository
+func NewBankAccountRepository() *BankAccountRepository {
+	return &BankAccountRepository{}
+}
+
+// Create creates a bank account
+func (r *BankAccountRepository) Create(account *model.MerchantBankAccount) error {
+	return inits.DB.Create(account).Error
+}
+
+// Update saves a bank account
+func (r *BankAccountRepository) Update(account *model.MerchantBankAccount) error {
+	return inits.DB.Save(account).Error
+}
+
+// Delete soft deletes a bank account
+func (r *BankAccountRepository) Delete(id uuid.UUID) error {
+	return inits.DB.Delete(&model.MerchantBankAccount{}, "id = ?", id).Error
+}
+
+// FindByID finds a bank account by ID
+func (r *BankAccountRepository) FindByID(id uuid.UUID) (*model.MerchantBankAccount, error) {
+	var account model.MerchantBankAccount
+	err := inits.DB.Where("id = ?", id).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bank account not found")
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindByMerchant lists a merchant's bank accounts, default first
+func (r *BankAccountRepository) FindByMerchant(merchantID uuid.UUID) ([]model.MerchantBankAccount, error) {
+	var accounts []model.MerchantBankAccount
+	err := inits.DB.Omit("document_content").
+		Where("merchant_id = ?", merchantID).
+		Order("is_default DESC, created_at DESC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+// FindByMerchantAndRIB finds a merchant's account with the given RIB, if any
+func (r *BankAccountRepository) FindByMerchantAndRIB(merchantID uuid.UUID, rib string) (*model.MerchantBankAccount, error) {
+	var account model.MerchantBankAccount
+	err := inits.DB.Where("merchant_id = ? AND rib = ?", merchantID, rib).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// FindDefault finds the merchant's default verified payout account, if any
+func (r *BankAccountRepository) FindDefault(merchantID uuid.UUID) (*model.MerchantBankAccount, error) {
+	var account model.MerchantBankAccount
+	err := inits.DB.Omit("document_content").
+		Where("merchant_id = ? AND is_default = ? AND status = ?", merchantID, true, model.BankAccountStatusVerified).
+		First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SetDefault makes the account the merchant's only default account
+func (r *BankAccountRepository) SetDefault(account *model.MerchantBankAccount) error {
+	return inits.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.MerchantBankAccount{}).
+			Where("merchant_id = ? AND id <> ? AND is_default = ?", account.MerchantID, account.ID, true).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		account.IsDefault = true
+		return tx.Model(account).Update("is_default", true).Error
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type OffboardingRepository struct{}
+
+// NewOffboardingRepository creates a new offboarding repository
+func NewOffboardingRepository() *OffboardingRepository {
+	return &OffboardingRepository{}
+}
+
+// Create creates an offboarding record
+func (r *OffboardingRepository) Create(offboarding *model.MerchantOffboarding) error {
+	return inits.DB.Create(offboarding).Error
+}
+
+// Update saves an offboarding record
+func (r *OffboardingRepository) Update(offboarding *model.MerchantOffboarding) error {
+	return inits.DB.Save(offboarding).Error
+}
+
+// FindLatestByMerchant finds the merchant's most recent offboarding
+func (r *OffboardingRepository) FindLatestByMerchant(merchantID uuid.UUID) (*model.MerchantOffboarding, error) {
+	var offboarding model.MerchantOffboarding
+	err := inits.DB.Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		First(&offboarding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("offboarding not found")
+		}
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+// FindOpenByMerchant finds the merchant's in-progress offboarding, if any
+func (r *OffboardingRepository) FindOpenByMerchant(merchantID uuid.UUID) (*model.MerchantOffboarding, error) {
+	var offboarding model.MerchantOffboarding
+	err := inits.DB.Where("merchant_id = ? AND status IN ?", merchantID, []model.OffboardingStatus{
+		model.OffboardingStatusWindingDown,
+		model.OffboardingStatusClosing,
+	}).First(&offboarding).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+// FindDue finds offboardings whose wind-down has ended and still have steps left
+func (r *OffboardingRepository) FindDue(now time.Time, limit int) ([]model.MerchantOffboarding, error) {
+	var offboardings []model.MerchantOffboarding
+	err := inits.DB.Where("status = ? OR (status = ? AND wind_down_ends_at <= ?)",
+		model.OffboardingStatusClosing, model.OffboardingStatusWindingDown, now).
+		Order("wind_down_ends_at ASC").
+		Limit(limit).
+		Find(&offboardings).Error
+
+	return offboardings, err
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	maxMicroDepositAttempts = 3
+	// MaxBankDocumentSize caps uploaded RIB certificates and bank statements
+	MaxBankDocumentSize = 5 << 20
+)
+
+var (
+	ErrBankAccountNotFound      = errors.New("bank account not found")
+	ErrBankAccountExists        = errors.New("bank account already added")
+	ErrBankAccountInvalidState  = errors.New("bank account is not in a state that allows this action")
+	ErrBankAccountNotVerified   = errors.New("only verified bank accounts can be the default")
+	ErrMicroDepositMismatch     = errors.New("micro-deposit amounts do not match")
+	ErrBankDocumentTooLarge     = fmt.Errorf("document exceeds %d bytes", MaxBankDocumentSize)
+	ErrBankDocumentTypeRejected = errors.New("document must be a PDF, JPEG or PNG")
+)
+
+// allowedBankDocumentTypes are the document formats accepted for review
+var allowedBankDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// moroccanBankNames maps the common RIB bank codes to the bank's name
+var moroccanBankNames = map[string]string{
+	"007": "Attijariwafa bank",
+	"011": "Bank of Africa",
+	"013": "BMCI",
+	"021": "Crédit du Maroc",
+	"022": "Société Générale Maroc",
+	"101": "Banque Populaire",
+	"127": "Banque Populaire",
+	"145": "Banque Populaire",
+	"157": "Banque Populaire",
+	"164": "Banque Populaire",
+	"181": "Banque Populaire",
+	"190": "Banque Populaire",
+	"225": "Crédit Agricole du Maroc",
+	"230": "CIH Bank",
+}
+
+// BankAccountService manages the bank accounts a merchant is paid out to.
+// A new account must be verified, either with two micro-deposits the
+// merchant confirms or with a bank document an operator reviews, before it
+// can become the default payout account.
+type BankAccountService struct {
+	bankAccountRepo *repository.BankAccountRepository
+	activityLogRepo *repository.ActivityLogRepository
+}
+
+// NewBankAccountService creates a new bank account service
+func NewBankAccountService() *BankAccountService {
+	return &BankAccountService{
+		bankAccountRepo: repository.NewBankAccountRepository(),
+		activityLogRepo: repository.NewActivityLogRepository(),
+	}
+}
+
+// AddBankAccountRequest represents a new payout account. Either the RIB or
+// the IBAN is required; the other is derived from it.
+type AddBankAccountRequest struct {
+	MerchantID        uuid.UUID
+	CreatedBy         uuid.UUID
+	AccountHolderName string
+	BankName          string
+	RIB               string
+	IBAN              string
+}
+
+// ReviewBankDocumentRequest is an operator decision on an uploaded document
+type ReviewBankDocumentRequest struct {
+	Approve    bool
+	Reason     string
+	ReviewedBy string
+}
+
+// AddBankAccount validates and stores a new bank account pending verification
+func (s *BankAccountService) AddBankAccount(req *AddBankAccountRequest) (*model.MerchantBankAccount, error) {
+	if strings.TrimSpace(req.AccountHolderName) == "" {
+		return nil, errors.New("account_holder_name is required")
+	}
+
+	rib, iban, err := normalizeBankAccountNumber(req.RIB, req.IBAN)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.bankAccountRepo.FindByMerchantAndRIB(req.MerchantID, rib)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrBankAccountExists
+	}
+
+	bankCode := rib[:3]
+	bankName := strings.TrimSpace(req.BankName)
+	if bankName == "" {
+		bankName = moroccanBankNames[bankCode]
+	}
+	if bankName == "" {
+		return nil, fmt.Errorf("unknown bank code %s, bank_name is required", bankCode)
+	}
+
+	account := &model.MerchantBankAccount{
+		MerchantID:        req.MerchantID,
+		AccountHolderName: strings.TrimSpace(req.AccountHolderName),
+		BankName:          bankName,
+		BankCode:          bankCode,
+		Country:           "MA",
+		Currency:          "MAD",
+		RIB:               rib,
+		IBAN:              iban,
+		Last4:             rib[len(rib)-6 : len(rib)-2], // last digits of the account number, before the RIB key
+		Status:            model.BankAccountStatusPendingVerification,
+		CreatedBy:         req.CreatedBy,
+	}
+	if err := s.bankAccountRepo.Create(account); err != nil {
+		return nil, fmt.Errorf("failed to create bank account: %w", err)
+	}
+
+	go s.logActivity(req.MerchantID, req.CreatedBy, "bank_account_added", account.ID, map[string]interface{}{
+		"bank_name": account.BankName,
+		"last4":     account.Last4,
+	})
+
+	return account, nil
+}
+
+// ListBankAccounts lists a merchant's bank accounts
+func (s *BankAccountService) ListBankAccounts(merchantID uuid.UUID) ([]model.MerchantBankAccount, error) {
+	return s.bankAccountRepo.FindByMerchant(merchantID)
+}
+
+// GetBankAccount gets one of a merchant's bank accounts
+func (s *BankAccountService) GetBankAccount(merchantID, accountID uuid.UUID) (*model.MerchantBankAccount, error) {
+	account, err := s.bankAccountRepo.FindByID(accountID)
+	if err != nil || account.MerchantID != merchantID {
+		return nil, ErrBankAccountNotFound
+	}
+	return account, nil
+}
+
+// GetPayoutAccount returns the merchant's default verified account, or nil
+// if the merchant has none
+func (s *BankAccountService) GetPayoutAccount(merchantID uuid.UUID) (*model.MerchantBankAccount, error) {
+	return s.bankAccountRepo.FindDefault(merchantID)
+}
+
+// StartMicroDeposits sends two small deposits to the account. The merchant
+// confirms the amounts seen on their statement to prove ownership.
+func (s *BankAccountService) StartMicroDeposits(merchantID, accountID, userID uuid.UUID) (*model.MerchantBankAccount, error) {
+	account, err := s.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != model.BankAccountStatusPendingVerification && account.Status != model.BankAccountStatusFailed {
+		return nil, ErrBankAccountInvalidState
+	}
+
+	amount1, err := randomMicroDepositAmount()
+	if err != nil {
+		return nil, err
+	}
+	amount2, err := randomMicroDepositAmount()
+	if err != nil {
+		return nil, err
+	}
+
+	account.Status = model.BankAccountStatusMicroDepositsSent
+	account.VerificationMethod = model.BankAccountVerificationMicroDeposits
+	account.MicroDepositAmount1 = amount1
+	account.MicroDepositAmount2 = amount2
+	account.MicroDepositAttempts = 0
+	account.MicroDepositsSentAt = toNullTime(time.Now())
+	account.FailureReason = toNullString("")
+	if err := s.bankAccountRepo.Update(account); err != nil {
+		return nil, err
+	}
+
+	// Payout rails are not connected yet: the transfer is queued by the
+	// treasury team from this log line
+	logger.Log.Info("Micro-deposits requested",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("bank_account_id", account.ID.String()),
+		zap.String("bank_code", account.BankCode),
+	)
+
+	go s.logActivity(merchantID, userID, "bank_account_micro_deposits_sent", account.ID, nil)
+	return account, nil
+}
+
+// ConfirmMicroDeposits checks the amounts the merchant received, in either
+// order. Too many wrong attempts fail the verification.
+func (s *BankAccountService) ConfirmMicroDeposits(merchantID, accountID, userID uuid.UUID, amounts [2]int64) (*model.MerchantBankAccount, error) {
+	account, err := s.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != model.BankAccountStatusMicroDepositsSent {
+		return nil, ErrBankAccountInvalidState
+	}
+
+	matched := (amounts[0] == account.MicroDepositAmount1 && amounts[1] == account.MicroDepositAmount2) ||
+		(amounts[0] == account.MicroDepositAmount2 && amounts[1] == account.MicroDepositAmount1)
+
+	if !matched {
+		account.MicroDepositAttempts++
+		if account.MicroDepositAttempts >= maxMicroDepositAttempts {
+			account.Status = model.BankAccountStatusFailed
+			account.FailureReason = toNullString("too many incorrect micro-deposit attempts")
+		}
+		if err := s.bankAccountRepo.Update(account); err != nil {
+			return nil, err
+		}
+		if account.Status == model.BankAccountStatusFailed {
+			go s.logActivity(merchantID, userID, "bank_account_verification_failed", account.ID, nil)
+			return account, fmt.Errorf("%w: verification failed, request new micro-deposits", ErrMicroDepositMismatch)
+		}
+		return account, fmt.Errorf("%w: %d attempts left", ErrMicroDepositMismatch, maxMicroDepositAttempts-account.MicroDepositAttempts)
+	}
+
+	if err := s.markVerified(account); err != nil {
+		return nil, err
+	}
+	go s.logActivity(merchantID, userID, "bank_account_verified", account.ID, map[string]interface{}{
+		"method": account.VerificationMethod,
+	})
+	return account, nil
+}
+
+// UploadDocument attaches a bank-issued document and queues the account for
+// operator review
+func (s *BankAccountService) UploadDocument(merchantID, accountID, userID uuid.UUID, filename, contentType string, content []byte) (*model.MerchantBankAccount, error) {
+	if len(content) == 0 {
+		return nil, errors.New("document is empty")
+	}
+	if len(content) > MaxBankDocumentSize {
+		return nil, ErrBankDocumentTooLarge
+	}
+	if !allowedBankDocumentTypes[contentType] {
+		return nil, ErrBankDocumentTypeRejected
+	}
+
+	account, err := s.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	switch account.Status {
+	case model.BankAccountStatusPendingVerification, model.BankAccountStatusPendingReview, model.BankAccountStatusFailed:
+	default:
+		return nil, ErrBankAccountInvalidState
+	}
+
+	account.Status = model.BankAccountStatusPendingReview
+	account.VerificationMethod = model.BankAccountVerificationDocument
+	account.DocumentFilename = toNullString(filename)
+	account.DocumentContentType = toNullString(contentType)
+	account.DocumentContent = content
+	account.DocumentUploadedAt = toNullTime(time.Now())
+	account.FailureReason = toNullString("")
+	if err := s.bankAccountRepo.Update(account); err != nil {
+		return nil, err
+	}
+	account.DocumentContent = nil
+
+	go s.logActivity(merchantID, userID, "bank_account_document_uploaded", account.ID, map[string]interface{}{
+		"filename": filename,
+	})
+	return account, nil
+}
+
+// ReviewDocument records an operator's decision on an uploaded document
+func (s *BankAccountService) ReviewDocument(accountID uuid.UUID, req *ReviewBankDocumentRequest) (*model.MerchantBankAccount, error) {
+	account, err := s.bankAccountRepo.FindByID(accountID)
+	if err != nil {
+		return nil, ErrBankAccountNotFound
+	}
+	if account.Status != model.BankAccountStatusPendingReview {
+		return nil, ErrBankAccountInvalidState
+	}
+
+	account.ReviewedBy = toNullString(req.ReviewedBy)
+	if !req.Approve {
+		if req.Reason == "" {
+			return nil, errors.New("reason is required when rejecting a document")
+		}
+		account.Status = model.BankAccountStatusFailed
+		account.FailureReason = toNullString(req.Reason)
+		if err := s.bankAccountRepo.Update(account); err != nil {
+			return nil, err
+		}
+		logger.Log.Info("Bank account document rejected",
+			zap.String("bank_account_id", account.ID.String()),
+			zap.String("reviewed_by", req.ReviewedBy),
+		)
+		return account, nil
+	}
+
+	if err := s.markVerified(account); err != nil {
+		return nil, err
+	}
+	logger.Log.Info("Bank account document approved",
+		zap.String("bank_account_id", account.ID.String()),
+		zap.String("reviewed_by", req.ReviewedBy),
+	)
+	return account, nil
+}
+
+// SetDefault makes a verified account the merchant's payout account
+func (s *BankAccountService) SetDefault(merchantID, accountID, userID uuid.UUID) (*model.MerchantBankAccount, error) {
+	account, err := s.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsVerified() {
+		return nil, ErrBankAccountNotVerified
+	}
+	if err := s.bankAccountRepo.SetDefault(account); err != nil {
+		return nil, err
+	}
+
+	go s.logActivity(merchantID, userID, "bank_account_set_default", account.ID, nil)
+	return account, nil
+}
+
+// DeleteBankAccount removes an account. If it was the default, the most
+// recent other verified account takes over so payouts keep flowing.
+func (s *BankAccountService) DeleteBankAccount(merchantID, accountID, userID uuid.UUID) error {
+	account, err := s.GetBankAccount(merchantID, accountID)
+	if err != nil {
+		return err
+	}
+	if err := s.bankAccountRepo.Delete(account.ID); err != nil {
+		return err
+	}
+
+	if account.IsDefault {
+		if err := s.promoteNextDefault(merchantID); err != nil {
+			logger.Log.Warn("Failed to promote a new default bank account",
+				zap.String("merchant_id", merchantID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	go s.logActivity(merchantID, userID, "bank_account_deleted", account.ID, map[string]interface{}{
+		"last4": account.Last4,
+	})
+	return nil
+}
+
+// markVerified verifies the account and makes it the default if the
+// merchant has no default payout account yet
+func (s *BankAccountService) markVerified(account *model.MerchantBankAccount) error {
+	account.Status = model.BankAccountStatusVerified
+	account.VerifiedAt = toNullTime(time.Now())
+	account.FailureReason = toNullString("")
+	if err := s.bankAccountRepo.Update(account); err != nil {
+		return err
+	}
+
+	current, err := s.bankAccountRepo.FindDefault(account.MerchantID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return s.bankAccountRepo.SetDefault(account)
+	}
+	return nil
+}
+
+func (s *BankAccountService) promoteNextDefault(merchantID uuid.UUID) error {
+	accounts, err := s.bankAccountRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		if accounts[i].IsVerified() {
+			return s.bankAccountRepo.SetDefault(&accounts[i])
+		}
+	}
+	return nil
+}
+
+// logActivity logs bank account activity
+func (s *BankAccountService) logActivity(merchantID, userID uuid.UUID, action string, resourceID uuid.UUID, changes map[string]interface{}) {
+	log := &model.MerchantActivityLog{
+		MerchantID:   merchantID,
+		UserID:       userID,
+		Action:       action,
+		ResourceType: toNullString("bank_account"),
+		ResourceID:   toNullString(resourceID.String()),
+	}
+
+	if changes != nil {
+		changesJSON, _ := json.Marshal(changes)
+		log.Changes = changesJSON
+	}
+
+	s.activityLogRepo.Create(log)
+}
+
+// randomMicroDepositAmount returns an amount between 0.01 and 0.99 MAD
+func randomMicroDepositAmount() (int64, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(99))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate micro-deposit: %w", err)
+	}
+	return n.Int64() + 1, nil
+}
+
+// normalizeBankAccountNumber validates a Moroccan RIB and/or IBAN and
+// returns both. A RIB is 24 digits (bank, city, account number, 2-digit
+// key) and is valid when the whole number is divisible by 97; the IBAN is
+// "MA", two check digits, then the RIB.
+func normalizeBankAccountNumber(rib, iban string) (string, string, error) {
+	rib = stripBankAccountNumber(rib)
+	iban = strings.ToUpper(stripBankAccountNumber(iban))
+
+	if rib == "" && iban == "" {
+		return "", "", errors.New("rib or iban is required")
+	}
+
+	if iban != "" {
+		if len(iban) != 28 || !strings.HasPrefix(iban, "MA") {
+			return "", "", errors.New("iban must be a 28-character Moroccan IBAN starting with MA")
+		}
+		if !isDigits(iban[2:]) || mod97(iban[4:]+"2210"+iban[2:4]) != 1 {
+			return "", "", errors.New("invalid iban")
+		}
+		if rib != "" && rib != iban[4:] {
+			return "", "", errors.New("rib does not match iban")
+		}
+		rib = iban[4:]
+	}
+
+	if len(rib) != 24 || !isDigits(rib) {
+		return "", "", errors.New("rib must be 24 digits")
+	}
+	if mod97(rib) != 0 {
+		return "", "", errors.New("invalid rib key")
+	}
+
+	if iban == "" {
+		iban = fmt.Sprintf("MA%02d%s", 98-mod97(rib+"221000"), rib)
+	}
+	return rib, iban, nil
+}
+
+// stripBankAccountNumber removes the spaces and dashes people paste in
+func stripBankAccountNumber(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(s))
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// mod97 computes a decimal string modulo 97 without overflowing
+func mod97(digits string) int {
+	rem := 0
+	for _, r := range digits {
+		rem = (rem*10 + int(r-'0')) % 97
+	}
+	return rem
+}
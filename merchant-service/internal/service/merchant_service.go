@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/config"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
 	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/repository"
@@ -48,7 +50,7 @@ type CreateMerchantRequest struct {
 }
 
 // CreateMerchant creates a new merchant account
-func (s *MerchantService) CreateMerchant(req *CreateMerchantRequest) (*model.Merchant, error) {
+func (s *MerchantService) CreateMerchant(ctx context.Context, req *CreateMerchantRequest) (*model.Merchant, error) {
 	// Validate input
 	if err := s.validateMerchantCreation(req); err != nil {
 		return nil, err
@@ -64,6 +66,7 @@ func (s *MerchantService) CreateMerchant(req *CreateMerchantRequest) (*model.Mer
 		CountryCode:  "MA", // Always Morocco
 		CurrencyCode: "MAD",
 		Timezone:     "Africa/Casablanca",
+		Region:       config.Region(),
 	}
 
 	if req.LegalName != "" {
@@ -89,7 +92,7 @@ func (s *MerchantService) CreateMerchant(req *CreateMerchantRequest) (*model.Mer
 	if err := s.createDefaultVerification(merchant.ID); err != nil {
 		return nil, err
 	}
-	if err := s.authClient.AssignMerchantOwnerRole(req.OwnerID, merchant.ID); err != nil {
+	if err := s.authClient.AssignMerchantOwnerRole(ctx, req.OwnerID, merchant.ID); err != nil {
 		fmt.Printf("WARNING: Failed to assign admin role to merchant owner: %v\n", err)
 		return nil, err
 	}
@@ -270,6 +273,9 @@ func (s *MerchantService) createDefaultSettings(merchantID uuid.UUID) error {
 		AutoSettle:        true,
 		SettleSchedule:    "daily",
 		SendEmailReceipts: true,
+		Timezone:          "Africa/Casablanca",
+		Locale:            "fr-MA",
+		NumberFormat:      "space_comma",
 	}
 
 	// Default payment methods and currencies (as JSON)
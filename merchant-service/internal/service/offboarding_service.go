@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultWindDownDays covers the card networks' usual dispute window
+	defaultWindDownDays = 120
+	maxWindDownDays     = 540
+
+	offboardingBatchSize = 20
+)
+
+var (
+	ErrOffboardingInProgress = errors.New("merchant offboarding already in progress")
+	ErrOffboardingNotFound   = errors.New("no offboarding in progress")
+	ErrOffboardingTooLate    = errors.New("offboarding can only be cancelled during the wind-down period")
+	ErrMerchantAlreadyClosed = errors.New("merchant is already closed")
+)
+
+// OffboardingService closes a merchant account. Starting it stops new
+// payments right away; once the wind-down period for refunds and disputes
+// ends, the worker settles the remaining balance, exports the final records,
+// then revokes API keys and card tokens and shreds the encryption keys.
+// Exports must finish before the shred, which makes customer PII unreadable.
+type OffboardingService struct {
+	offboardingRepo *repository.OffboardingRepository
+	merchantRepo    *repository.MerchantRepository
+	merchantService *MerchantService
+	authClient      *client.AuthServiceClient
+	paymentClient   *client.PaymentAPIClient
+	settlement      *client.TransactionAdminClient
+	keyClient       *client.KeyManagementClient
+	pollInterval    time.Duration
+}
+
+// NewOffboardingService creates a new offboarding service
+func NewOffboardingService() *OffboardingService {
+	keyClient, err := client.NewKeyManagementClient()
+	if err != nil {
+		logger.Log.Fatal("failed to create key management client", zap.Error(err))
+	}
+
+	return &OffboardingService{
+		offboardingRepo: repository.NewOffboardingRepository(),
+		merchantRepo:    repository.NewMerchantRepository(),
+		merchantService: NewMerchantService(),
+		authClient:      client.NewAuthServiceClient(),
+		paymentClient:   client.NewPaymentAPIClient(),
+		settlement:      client.NewTransactionAdminClient(),
+		keyClient:       keyClient,
+		pollInterval:    time.Duration(getEnvInt("OFFBOARDING_POLL_MINUTES", 10)) * time.Minute,
+	}
+}
+
+// StartOffboardingRequest represents an account closure request
+type StartOffboardingRequest struct {
+	MerchantID   uuid.UUID
+	RequestedBy  uuid.UUID
+	Reason       string
+	WindDownDays *int // nil uses the default
+}
+
+// OffboardingResponse is an offboarding with its final export download links
+type OffboardingResponse struct {
+	*model.MerchantOffboarding
+	Exports []client.ExportJob `json:"exports,omitempty"`
+}
+
+// StartOffboarding stops new payments for the merchant and schedules the
+// account for closure once the wind-down period ends
+func (s *OffboardingService) StartOffboarding(ctx context.Context, req *StartOffboardingRequest) (*model.MerchantOffboarding, error) {
+	merchant, err := s.merchantRepo.FindByID(req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if merchant.OwnerID != req.RequestedBy {
+		return nil, errors.New("only the owner can close a merchant")
+	}
+	if merchant.Status == model.MerchantStatusClosed {
+		return nil, ErrMerchantAlreadyClosed
+	}
+
+	open, err := s.offboardingRepo.FindOpenByMerchant(req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, ErrOffboardingInProgress
+	}
+
+	windDownDays := defaultWindDownDays
+	if req.WindDownDays != nil {
+		windDownDays = *req.WindDownDays
+	}
+	if windDownDays < 0 || windDownDays > maxWindDownDays {
+		return nil, fmt.Errorf("wind_down_days must be between 0 and %d", maxWindDownDays)
+	}
+
+	now := time.Now()
+	windDownEndsAt := now.AddDate(0, 0, windDownDays)
+
+	// Stop payments first: if this fails nothing has changed yet
+	if err := s.paymentClient.SetLifecycle(ctx, req.MerchantID, "winding_down", &windDownEndsAt); err != nil {
+		return nil, fmt.Errorf("failed to stop payments: %w", err)
+	}
+
+	offboarding := &model.MerchantOffboarding{
+		MerchantID:        req.MerchantID,
+		Status:            model.OffboardingStatusWindingDown,
+		Step:              model.OffboardingStepSettle,
+		Reason:            toNullString(req.Reason),
+		RequestedBy:       req.RequestedBy,
+		PreviousStatus:    merchant.Status,
+		WindDownDays:      windDownDays,
+		WindDownEndsAt:    windDownEndsAt,
+		PaymentsStoppedAt: toNullTime(now),
+	}
+	if err := s.offboardingRepo.Create(offboarding); err != nil {
+		return nil, err
+	}
+
+	if err := s.merchantService.UpdateMerchantStatus(req.MerchantID, model.MerchantStatusClosing, req.RequestedBy); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Warn("Merchant offboarding started",
+		zap.String("merchant_id", req.MerchantID.String()),
+		zap.String("requested_by", req.RequestedBy.String()),
+		zap.Time("wind_down_ends_at", windDownEndsAt),
+	)
+
+	return offboarding, nil
+}
+
+// GetOffboarding returns the merchant's latest offboarding. Once the final
+// exports are done their download links are included, since the merchant's
+// API keys no longer work by then.
+func (s *OffboardingService) GetOffboarding(ctx context.Context, merchantID uuid.UUID) (*OffboardingResponse, error) {
+	offboarding, err := s.offboardingRepo.FindLatestByMerchant(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &OffboardingResponse{MerchantOffboarding: offboarding}
+	if !offboarding.ExportedAt.Valid {
+		return response, nil
+	}
+
+	for _, exportID := range exportJobIDs(offboarding) {
+		export, err := s.paymentClient.GetExport(ctx, merchantID, exportID)
+		if err != nil {
+			logger.Log.Warn("Failed to load final export",
+				zap.String("export_id", exportID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		response.Exports = append(response.Exports, *export)
+	}
+
+	return response, nil
+}
+
+// CancelOffboarding resumes payments. Only possible before the wind-down
+// ends; after that the teardown has started and cannot be undone.
+func (s *OffboardingService) CancelOffboarding(ctx context.Context, merchantID, userID uuid.UUID) (*model.MerchantOffboarding, error) {
+	offboarding, err := s.offboardingRepo.FindOpenByMerchant(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if offboarding == nil {
+		return nil, ErrOffboardingNotFound
+	}
+	if offboarding.Status != model.OffboardingStatusWindingDown || !time.Now().Before(offboarding.WindDownEndsAt) {
+		return nil, ErrOffboardingTooLate
+	}
+
+	if err := s.paymentClient.SetLifecycle(ctx, merchantID, "active", nil); err != nil {
+		return nil, fmt.Errorf("failed to resume payments: %w", err)
+	}
+
+	offboarding.Status = model.OffboardingStatusCancelled
+	offboarding.CancelledAt = toNullTime(time.Now())
+	if err := s.offboardingRepo.Update(offboarding); err != nil {
+		return nil, err
+	}
+
+	if err := s.merchantService.UpdateMerchantStatus(merchantID, offboarding.PreviousStatus, userID); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Merchant offboarding cancelled",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("cancelled_by", userID.String()),
+	)
+
+	return offboarding, nil
+}
+
+// =========================================================================
+// Worker
+// =========================================================================
+
+// RunWorker advances offboardings past their wind-down until ctx is canceled
+func (s *OffboardingService) RunWorker(ctx context.Context) {
+	logger.Log.Info("Starting offboarding worker", zap.Duration("interval", s.pollInterval))
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Offboarding worker stopped")
+			return
+		case <-ticker.C:
+			s.processDue(ctx)
+		}
+	}
+}
+
+func (s *OffboardingService) processDue(ctx context.Context) {
+	due, err := s.offboardingRepo.FindDue(time.Now(), offboardingBatchSize)
+	if err != nil {
+		logger.Log.Error("Failed to load due offboardings", zap.Error(err))
+		return
+	}
+
+	for i := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.advance(ctx, &due[i])
+	}
+}
+
+// advance runs the remaining steps in order. Every step is safe to repeat,
+// so a failure just records the error and the next tick picks up from there.
+func (s *OffboardingService) advance(ctx context.Context, o *model.MerchantOffboarding) {
+	if o.Status == model.OffboardingStatusWindingDown {
+		o.Status = model.OffboardingStatusClosing
+	}
+
+	for o.Step != model.OffboardingStepDone {
+		done, err := s.runStep(ctx, o)
+		if err != nil {
+			o.Attempts++
+			o.LastError = toNullString(fmt.Sprintf("%s: %v", o.Step, err))
+			logger.Log.Error("Offboarding step failed",
+				zap.String("merchant_id", o.MerchantID.String()),
+				zap.String("step", string(o.Step)),
+				zap.Error(err),
+			)
+			s.save(o)
+			return
+		}
+		if !done {
+			// Waiting on something asynchronous, such as the export worker
+			s.save(o)
+			return
+		}
+		o.LastError = toNullString("")
+		s.save(o)
+	}
+}
+
+// runStep runs the current step and moves o to the next one when it is done
+func (s *OffboardingService) runStep(ctx context.Context, o *model.MerchantOffboarding) (bool, error) {
+	now := time.Now()
+
+	switch o.Step {
+	case model.OffboardingStepSettle:
+		batch, err := s.settlement.CreateFinalSettlement(ctx, o.MerchantID)
+		if err != nil {
+			return false, err
+		}
+		if batch != nil {
+			o.SettlementBatchID = toNullString(batch.ID.String())
+		}
+		o.SettledAt = toNullTime(now)
+		o.Step = model.OffboardingStepExport
+
+	case model.OffboardingStepExport:
+		finished, err := s.exportRecords(ctx, o)
+		if err != nil || !finished {
+			return false, err
+		}
+		o.ExportedAt = toNullTime(now)
+		o.Step = model.OffboardingStepRevokeAPIKeys
+
+	case model.OffboardingStepRevokeAPIKeys:
+		if err := s.revokeAPIKeys(ctx, o.MerchantID); err != nil {
+			return false, err
+		}
+		o.APIKeysRevokedAt = toNullTime(now)
+		o.Step = model.OffboardingStepRevokeTokens
+
+	case model.OffboardingStepRevokeTokens:
+		revoked, err := s.keyClient.RevokeMerchantTokens(ctx, o.MerchantID, o.RequestedBy, "merchant offboarding")
+		if err != nil {
+			return false, err
+		}
+		o.RevokedTokens += revoked
+		o.TokensRevokedAt = toNullTime(now)
+		o.Step = model.OffboardingStepShredKeys
+
+	case model.OffboardingStepShredKeys:
+		for _, purpose := range []string{client.KeyPurposePaymentPII, client.KeyPurposeCardData} {
+			if _, err := s.keyClient.ShredMerchantKeys(ctx, o.MerchantID, o.RequestedBy, purpose, "merchant offboarding"); err != nil {
+				return false, fmt.Errorf("shred %s keys: %w", purpose, err)
+			}
+		}
+		o.KeysShreddedAt = toNullTime(now)
+		o.Step = model.OffboardingStepClose
+
+	case model.OffboardingStepClose:
+		if err := s.paymentClient.SetLifecycle(ctx, o.MerchantID, "closed", nil); err != nil {
+			return false, err
+		}
+		if err := s.merchantService.UpdateMerchantStatus(o.MerchantID, model.MerchantStatusClosed, o.RequestedBy); err != nil {
+			return false, err
+		}
+		o.Status = model.OffboardingStatusCompleted
+		o.CompletedAt = toNullTime(now)
+		o.Step = model.OffboardingStepDone
+
+		logger.Log.Warn("Merchant offboarding completed", zap.String("merchant_id", o.MerchantID.String()))
+
+	default:
+		return false, fmt.Errorf("unknown offboarding step %q", o.Step)
+	}
+
+	return true, nil
+}
+
+// exportRecords queues the final exports on first run and then reports
+// whether they have all completed. Failed exports are queued again.
+func (s *OffboardingService) exportRecords(ctx context.Context, o *model.MerchantOffboarding) (bool, error) {
+	ids := exportJobIDs(o)
+	if len(ids) == 0 {
+		jobs, err := s.paymentClient.CreateFinalExports(ctx, o.MerchantID)
+		if err != nil {
+			return false, err
+		}
+		if len(jobs) == 0 {
+			return true, nil // no payment history
+		}
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+		o.ExportJobIDs, _ = json.Marshal(ids)
+		return false, nil
+	}
+
+	for _, id := range ids {
+		job, err := s.paymentClient.GetExport(ctx, o.MerchantID, id)
+		if err != nil {
+			return false, err
+		}
+		switch job.Status {
+		case "completed":
+			continue
+		case "failed", "expired":
+			o.ExportJobIDs = nil
+			return false, fmt.Errorf("final export %s %s, requeueing", id, job.Status)
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *OffboardingService) revokeAPIKeys(ctx context.Context, merchantID uuid.UUID) error {
+	resp, err := s.authClient.GetMerchantAPIKeys(ctx, merchantID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range resp.GetApiKeys() {
+		if !key.GetIsActive() {
+			continue
+		}
+		keyID, err := uuid.Parse(key.GetId())
+		if err != nil {
+			continue
+		}
+		if err := s.authClient.DeactivateAPIKey(ctx, keyID, merchantID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OffboardingService) save(o *model.MerchantOffboarding) {
+	if err := s.offboardingRepo.Update(o); err != nil {
+		logger.Log.Error("Failed to save offboarding progress",
+			zap.String("offboarding_id", o.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func exportJobIDs(o *model.MerchantOffboarding) []uuid.UUID {
+	var ids []uuid.UUID
+	if len(o.ExportJobIDs) > 0 {
+		_ = json.Unmarshal(o.ExportJobIDs, &ids)
+	}
+	return ids
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrNotMerchantMember = errors.New("you are not a member of this merchant")
+
+// Onboarding step states. A step is unknown when the service that owns it
+// could not be reached.
+const (
+	OnboardingStepComplete   = "complete"
+	OnboardingStepIncomplete = "incomplete"
+	OnboardingStepUnknown    = "unknown"
+)
+
+// OnboardingStep is one item of the checklist. Link points at the dashboard
+// page where the merchant can complete it.
+type OnboardingStep struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Link        string     `json:"link"`
+}
+
+// OnboardingStatus is the checklist for a user and, once they have one, a
+// merchant
+type OnboardingStatus struct {
+	MerchantID *uuid.UUID       `json:"merchant_id"`
+	Steps      []OnboardingStep `json:"steps"`
+	Completed  int              `json:"completed"`
+	Total      int              `json:"total"`
+	NextStep   string           `json:"next_step,omitempty"` // Key of the first step left to do
+}
+
+// OnboardingService builds the onboarding checklist from the merchant
+// service's own records, auth-service and payment-api
+type OnboardingService struct {
+	merchantService  *MerchantService
+	teamService      *TeamService
+	bankAccountRepo  *repository.BankAccountRepository
+	verificationRepo *repository.VerificationRepository
+	authClient       *client.AuthServiceClient
+	paymentAPIClient *client.PaymentAPIClient
+	frontendURL      string
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(authClient *client.AuthServiceClient) *OnboardingService {
+	return &OnboardingService{
+		merchantService:  NewMerchantService(),
+		teamService:      NewTeamService(),
+		bankAccountRepo:  repository.NewBankAccountRepository(),
+		verificationRepo: repository.NewVerificationRepository(),
+		authClient:       authClient,
+		paymentAPIClient: client.NewPaymentAPIClient(),
+		frontendURL:      getEnv("FRONTEND_URL", "http://localhost:3000"),
+	}
+}
+
+// GetStatus returns the checklist for the user behind token. Without a
+// merchantID it reports on the user's oldest merchant, if any.
+func (s *OnboardingService) GetStatus(ctx context.Context, userID uuid.UUID, token string, merchantID uuid.UUID) (*OnboardingStatus, error) {
+	merchant, err := s.resolveMerchant(userID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &OnboardingStatus{}
+	add := func(key, state string, completedAt *time.Time, path string) {
+		status.Steps = append(status.Steps, OnboardingStep{
+			Key:         key,
+			Title:       onboardingTitles[key],
+			Status:      state,
+			CompletedAt: completedAt,
+			Link:        s.frontendURL + path,
+		})
+	}
+
+	add("email_verified", s.emailStep(ctx, token), nil, "/account/verify-email")
+
+	// Everything after email verification needs a merchant first
+	if merchant == nil {
+		for _, key := range onboardingSteps[1:] {
+			add(key, OnboardingStepIncomplete, nil, "/merchants/new")
+		}
+		return status.tally(), nil
+	}
+
+	status.MerchantID = &merchant.ID
+	base := "/merchants/" + merchant.ID.String()
+	add("merchant_created", OnboardingStepComplete, &merchant.CreatedAt, base)
+
+	bankState, bankAt := s.bankAccountStep(merchant.ID)
+	add("bank_account_added", bankState, bankAt, base+"/bank-accounts")
+	add("kyc_submitted", s.kycStep(merchant.ID), nil, base+"/verification")
+	add("api_key_created", s.apiKeyStep(ctx, merchant.ID), nil, base+"/api-keys")
+
+	progress, err := s.paymentAPIClient.GetOnboardingProgress(ctx, merchant.ID)
+	if err != nil {
+		logger.Log.Warn("Failed to load onboarding progress from payment-api", zap.String("merchant_id", merchant.ID.String()), zap.Error(err))
+		add("first_test_payment", OnboardingStepUnknown, nil, base+"/payments?mode=test")
+		add("webhook_configured", OnboardingStepUnknown, nil, base+"/webhooks")
+		add("first_live_payment", OnboardingStepUnknown, nil, base+"/payments")
+		return status.tally(), nil
+	}
+	add("first_test_payment", stepState(progress.FirstTestPaymentAt != nil), progress.FirstTestPaymentAt, base+"/payments?mode=test")
+	add("webhook_configured", stepState(progress.WebhookConfigured), nil, base+"/webhooks")
+	add("first_live_payment", stepState(progress.FirstLivePaymentAt != nil), progress.FirstLivePaymentAt, base+"/payments")
+
+	return status.tally(), nil
+}
+
+// onboardingSteps lists the checklist in order
+var onboardingSteps = []string{
+	"email_verified",
+	"merchant_created",
+	"bank_account_added",
+	"kyc_submitted",
+	"api_key_created",
+	"first_test_payment",
+	"webhook_configured",
+	"first_live_payment",
+}
+
+var onboardingTitles = map[string]string{
+	"email_verified":     "Verify your email address",
+	"merchant_created":   "Create your merchant account",
+	"bank_account_added": "Add a payout bank account",
+	"kyc_submitted":      "Submit your verification documents",
+	"api_key_created":    "Create an API key",
+	"first_test_payment": "Make a test payment",
+	"webhook_configured": "Configure a webhook",
+	"first_live_payment": "Take your first live payment",
+}
+
+func (s *OnboardingService) resolveMerchant(userID, merchantID uuid.UUID) (*model.Merchant, error) {
+	if merchantID != uuid.Nil {
+		member, err := s.teamService.IsUserInMerchant(merchantID, userID)
+		if err != nil || !member {
+			return nil, ErrNotMerchantMember
+		}
+		return s.merchantService.GetMerchantByID(merchantID)
+	}
+
+	merchants, err := s.merchantService.GetUserMerchants(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load merchants: %w", err)
+	}
+	if len(merchants) == 0 {
+		return nil, nil
+	}
+	sort.Slice(merchants, func(i, j int) bool {
+		return merchants[i].CreatedAt.Before(merchants[j].CreatedAt)
+	})
+	return &merchants[0], nil
+}
+
+func (s *OnboardingService) emailStep(ctx context.Context, token string) string {
+	verified, err := s.authClient.IsEmailVerified(ctx, token)
+	if err != nil {
+		logger.Log.Warn("Failed to check email verification", zap.Error(err))
+		return OnboardingStepUnknown
+	}
+	return stepState(verified)
+}
+
+func (s *OnboardingService) bankAccountStep(merchantID uuid.UUID) (string, *time.Time) {
+	accounts, err := s.bankAccountRepo.FindByMerchant(merchantID)
+	if err != nil {
+		logger.Log.Warn("Failed to load bank accounts", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+		return OnboardingStepUnknown, nil
+	}
+	if len(accounts) == 0 {
+		return OnboardingStepIncomplete, nil
+	}
+	first := accounts[0].CreatedAt
+	for _, account := range accounts[1:] {
+		if account.CreatedAt.Before(first) {
+			first = account.CreatedAt
+		}
+	}
+	return OnboardingStepComplete, &first
+}
+
+// kycStep counts documents as submitted once verification is under review
+// or done, or any document is on file
+func (s *OnboardingService) kycStep(merchantID uuid.UUID) string {
+	verification, err := s.verificationRepo.FindByMerchantID(merchantID)
+	if err != nil {
+		return OnboardingStepIncomplete
+	}
+	switch verification.VerificationStatus {
+	case model.VerificationStatusPending, model.VerificationStatusVerified:
+		return OnboardingStepComplete
+	}
+	var documents []json.RawMessage
+	if err := json.Unmarshal(verification.DocumentsSubmitted, &documents); err == nil && len(documents) > 0 {
+		return OnboardingStepComplete
+	}
+	return OnboardingStepIncomplete
+}
+
+func (s *OnboardingService) apiKeyStep(ctx context.Context, merchantID uuid.UUID) string {
+	resp, err := s.authClient.GetMerchantAPIKeys(ctx, merchantID)
+	if err != nil {
+		logger.Log.Warn("Failed to load API keys", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+		return OnboardingStepUnknown
+	}
+	return stepState(len(resp.ApiKeys) > 0)
+}
+
+func stepState(done bool) string {
+	if done {
+		return OnboardingStepComplete
+	}
+	return OnboardingStepIncomplete
+}
+
+func (st *OnboardingStatus) tally() *OnboardingStatus {
+	st.Total = len(st.Steps)
+	for _, step := range st.Steps {
+		if step.Status == OnboardingStepComplete {
+			st.Completed++
+		} else if st.NextStep == "" {
+			st.NextStep = step.Key
+		}
+	}
+	return st
+}
@@ -1,23 +1,47 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/merchant-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/merchant-service/internal/client"
 	model "github.com/rhaloubi/payment-gateway/merchant-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/merchant-service/internal/repository"
+	"go.uber.org/zap"
 )
 
+// localePattern accepts a language with an optional region, e.g. "ar" or "fr-MA"
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// statementDescriptorPattern is what card networks print: 5 to 22 Latin
+// letters, digits and a little punctuation, with at least one letter
+var statementDescriptorPattern = regexp.MustCompile(`^[A-Za-z0-9 .,*&-]{5,22}$`)
+
+var hasLetter = regexp.MustCompile(`[A-Za-z]`)
+
 type SettingsService struct {
-	settingsRepo    *repository.SettingsRepository
-	activityLogRepo *repository.ActivityLogRepository
+	settingsRepo      *repository.SettingsRepository
+	merchantRepo      *repository.MerchantRepository
+	activityLogRepo   *repository.ActivityLogRepository
+	transactionClient *client.TransactionAdminClient
+	paymentAPIClient  *client.PaymentAPIClient
 }
 
 // NewSettingsService creates a new settings service
 func NewSettingsService() *SettingsService {
 	return &SettingsService{
-		settingsRepo:    repository.NewSettingsRepository(),
-		activityLogRepo: repository.NewActivityLogRepository(),
+		settingsRepo:      repository.NewSettingsRepository(),
+		merchantRepo:      repository.NewMerchantRepository(),
+		activityLogRepo:   repository.NewActivityLogRepository(),
+		transactionClient: client.NewTransactionAdminClient(),
+		paymentAPIClient:  client.NewPaymentAPIClient(),
 	}
 }
 
@@ -27,7 +51,7 @@ func (s *SettingsService) GetSettings(merchantID uuid.UUID) (*model.MerchantSett
 }
 
 // UpdateSettings updates merchant settings
-func (s *SettingsService) UpdateSettings(merchantID uuid.UUID, updates map[string]interface{}, userID uuid.UUID) error {
+func (s *SettingsService) UpdateSettings(ctx context.Context, merchantID uuid.UUID, updates map[string]interface{}, userID uuid.UUID) error {
 	settings, err := s.settingsRepo.FindByMerchantID(merchantID)
 	if err != nil {
 		return err
@@ -52,14 +76,45 @@ func (s *SettingsService) UpdateSettings(merchantID uuid.UUID, updates map[strin
 		settings.AutoSettle = autoSettle
 	}
 
+	scheduleChanged := false
 	if settleSchedule, ok := updates["settle_schedule"].(string); ok {
 		changes["settle_schedule"] = map[string]interface{}{
 			"old": settings.SettleSchedule,
 			"new": settleSchedule,
 		}
+		// A weekday means nothing to a monthly schedule, so a new schedule
+		// starts from its default day unless one is given
+		if settleSchedule != settings.SettleSchedule {
+			scheduleChanged = true
+			settings.SettleDay = 0
+		}
 		settings.SettleSchedule = settleSchedule
 	}
 
+	if settleDay, ok := updates["settle_day"].(int); ok {
+		changes["settle_day"] = map[string]interface{}{
+			"old": settings.SettleDay,
+			"new": settleDay,
+		}
+		scheduleChanged = scheduleChanged || settleDay != settings.SettleDay
+		settings.SettleDay = settleDay
+	}
+
+	if minPayout, ok := updates["min_payout_amount"].(int64); ok {
+		changes["min_payout_amount"] = map[string]interface{}{
+			"old": settings.MinPayoutAmount,
+			"new": minPayout,
+		}
+		scheduleChanged = scheduleChanged || minPayout != settings.MinPayoutAmount
+		settings.MinPayoutAmount = minPayout
+	}
+
+	if scheduleChanged {
+		if err := validateSettleDay(settings.SettleSchedule, settings.SettleDay); err != nil {
+			return err
+		}
+	}
+
 	if webhookURL, ok := updates["webhook_url"].(string); ok {
 		changes["webhook_url"] = map[string]interface{}{
 			"old": settings.WebhookURL.String,
@@ -68,6 +123,89 @@ func (s *SettingsService) UpdateSettings(merchantID uuid.UUID, updates map[strin
 		settings.WebhookURL = toNullString(webhookURL)
 	}
 
+	localeChanged, timezoneChanged := false, false
+	if timezone, ok := updates["timezone"].(string); ok {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", timezone)
+		}
+		changes["timezone"] = map[string]interface{}{
+			"old": settings.Timezone,
+			"new": timezone,
+		}
+		timezoneChanged = timezone != settings.Timezone
+		localeChanged = localeChanged || timezoneChanged
+		settings.Timezone = timezone
+	}
+
+	if locale, ok := updates["locale"].(string); ok {
+		if !localePattern.MatchString(locale) {
+			return errors.New(`locale must look like "fr" or "fr-MA"`)
+		}
+		changes["locale"] = map[string]interface{}{
+			"old": settings.Locale,
+			"new": locale,
+		}
+		localeChanged = localeChanged || locale != settings.Locale
+		settings.Locale = locale
+	}
+
+	if numberFormat, ok := updates["number_format"].(string); ok {
+		changes["number_format"] = map[string]interface{}{
+			"old": settings.NumberFormat,
+			"new": numberFormat,
+		}
+		localeChanged = localeChanged || numberFormat != settings.NumberFormat
+		settings.NumberFormat = numberFormat
+	}
+
+	// payment-api decides whether to email receipts, so this rides along
+	// with the display settings
+	if sendReceipts, ok := updates["send_email_receipts"].(bool); ok {
+		changes["send_email_receipts"] = map[string]interface{}{
+			"old": settings.SendEmailReceipts,
+			"new": sendReceipts,
+		}
+		localeChanged = localeChanged || sendReceipts != settings.SendEmailReceipts
+		settings.SendEmailReceipts = sendReceipts
+	}
+
+	// So is the address payment-api sends merchant notices to
+	if notificationEmail, ok := updates["notification_email"].(string); ok {
+		changes["notification_email"] = map[string]interface{}{
+			"old": settings.NotificationEmail.String,
+			"new": notificationEmail,
+		}
+		localeChanged = localeChanged || notificationEmail != settings.NotificationEmail.String
+		settings.NotificationEmail = toNullString(notificationEmail)
+	}
+
+	// The descriptor is printed on receipts as well as card statements
+	if descriptor, ok := updates["statement_descriptor"].(string); ok {
+		descriptor = strings.TrimSpace(descriptor)
+		if descriptor != "" && (!statementDescriptorPattern.MatchString(descriptor) || !hasLetter.MatchString(descriptor)) {
+			return errors.New("statement_descriptor must be 5 to 22 letters, digits, spaces or . , * & - and include a letter")
+		}
+		changes["statement_descriptor"] = map[string]interface{}{
+			"old": settings.StatementDescriptor.String,
+			"new": descriptor,
+		}
+		localeChanged = localeChanged || descriptor != settings.StatementDescriptor.String
+		settings.StatementDescriptor = toNullString(descriptor)
+	}
+
+	// Push before saving so the services that cut settlement days and
+	// render exports never disagree with what the merchant sees here
+	if scheduleChanged {
+		if err := s.syncSettlementSchedule(ctx, settings); err != nil {
+			return err
+		}
+	}
+	if localeChanged {
+		if err := s.syncLocaleSettings(ctx, settings, timezoneChanged); err != nil {
+			return err
+		}
+	}
+
 	if err := s.settingsRepo.Update(settings); err != nil {
 		return err
 	}
@@ -78,6 +216,85 @@ func (s *SettingsService) UpdateSettings(merchantID uuid.UUID, updates map[strin
 	return nil
 }
 
+// validateSettleDay checks the day a settlement period ends on against the
+// schedule: an ISO weekday for weekly, a day of the month for monthly, and
+// 0 for the default
+func validateSettleDay(schedule string, day int) error {
+	switch schedule {
+	case "weekly":
+		if day < 0 || day > 7 {
+			return errors.New("settle_day must be 1 (Monday) to 7 (Sunday) for a weekly schedule, or 0 for Sunday")
+		}
+	case "monthly":
+		if day < 0 || day > 28 {
+			return errors.New("settle_day must be 1 to 28 for a monthly schedule, or 0 for the end of the month")
+		}
+	default:
+		if day != 0 {
+			return errors.New("settle_day can only be set for a weekly or monthly schedule")
+		}
+	}
+	return nil
+}
+
+// syncSettlementSchedule pushes the schedule to transaction-service, which
+// cuts the settlement batches. Skipped when its token is not configured, as
+// in local development.
+func (s *SettingsService) syncSettlementSchedule(ctx context.Context, settings *model.MerchantSettings) error {
+	err := s.transactionClient.SetSettlementSchedule(ctx, settings.MerchantID, client.SettlementSchedule{
+		Schedule:        settings.SettleSchedule,
+		Day:             settings.SettleDay,
+		MinPayoutAmount: settings.MinPayoutAmount,
+	})
+	if err != nil {
+		if !errors.Is(err, client.ErrTransactionAdminNotConfigured) {
+			return fmt.Errorf("failed to update settlement schedule: %w", err)
+		}
+		logger.Log.Warn("Settlement schedule not synced", zap.Error(err))
+	}
+	return nil
+}
+
+// syncLocaleSettings pushes the timezone, display and notification settings to the
+// services that use them. A service whose token is not configured is
+// skipped, as in local development.
+func (s *SettingsService) syncLocaleSettings(ctx context.Context, settings *model.MerchantSettings, timezoneChanged bool) error {
+	if timezoneChanged {
+		if err := s.transactionClient.SetSettlementTimezone(ctx, settings.MerchantID, settings.Timezone); err != nil {
+			if !errors.Is(err, client.ErrTransactionAdminNotConfigured) {
+				return fmt.Errorf("failed to update settlement timezone: %w", err)
+			}
+			logger.Log.Warn("Settlement timezone not synced", zap.Error(err))
+		}
+	}
+
+	merchant, err := s.merchantRepo.GetMerchantWithRelations(settings.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to load merchant branding: %w", err)
+	}
+	display := client.DisplaySettings{
+		Timezone:            settings.Timezone,
+		Locale:              settings.Locale,
+		NumberFormat:        settings.NumberFormat,
+		SendEmailReceipts:   settings.SendEmailReceipts,
+		NotificationEmail:   settings.NotificationEmail.String,
+		StatementDescriptor: settings.StatementDescriptor.String,
+		BusinessName:        merchant.BusinessName,
+	}
+	if merchant.Branding != nil {
+		display.LogoURL = merchant.Branding.LogoURL.String
+		display.BrandColor = merchant.Branding.PrimaryColor.String
+	}
+
+	if err := s.paymentAPIClient.SetDisplaySettings(ctx, settings.MerchantID, display); err != nil {
+		if !errors.Is(err, client.ErrPaymentAPINotConfigured) {
+			return fmt.Errorf("failed to update display settings: %w", err)
+		}
+		logger.Log.Warn("Display settings not synced", zap.Error(err))
+	}
+	return nil
+}
+
 // logActivity logs settings activity
 func (s *SettingsService) logActivity(merchantID, userID uuid.UUID, action, resourceType string, resourceID uuid.UUID, changes map[string]interface{}) {
 	log := &model.MerchantActivityLog{
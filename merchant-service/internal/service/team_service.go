@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -94,7 +95,7 @@ func (s *TeamService) InviteTeamMember(req *InviteTeamMemberRequest) (*model.Mer
 	return invitation, nil
 }
 
-func (s *TeamService) AcceptInvitation(token string, userID uuid.UUID) error {
+func (s *TeamService) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID) error {
 	// Find invitation
 	invitation, err := s.invitationRepo.FindByToken(token)
 	if err != nil {
@@ -129,7 +130,7 @@ func (s *TeamService) AcceptInvitation(token string, userID uuid.UUID) error {
 	}
 	merchantUser.JoinedAt = toNullTime(time.Now())
 
-	if err := s.authClient.AssignRoleToUser(userID, invitation.MerchantID, invitation.RoleID, invitation.InvitedBy); err != nil {
+	if err := s.authClient.AssignRoleToUser(ctx, userID, invitation.MerchantID, invitation.RoleID, invitation.InvitedBy); err != nil {
 		fmt.Printf("WARNING: Failed to assign role %s to merchant owner: %v\n", invitation.RoleName, err)
 		return err
 	}
@@ -25,7 +25,9 @@ type CreateAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	CreatedBy     string                 `protobuf:"bytes,3,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"` // UUID of the user creating the key
+	CreatedBy     string                 `protobuf:"bytes,3,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`          // UUID of the user creating the key
+	AllowedCidrs  []string               `protobuf:"bytes,4,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"` // Empty = any IP
+	TestMode      bool                   `protobuf:"varint,5,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"`            // Issue a pg_test_ sandbox key
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,6 +83,20 @@ func (x *CreateAPIKeyRequest) GetCreatedBy() string {
 	return ""
 }
 
+func (x *CreateAPIKeyRequest) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
+func (x *CreateAPIKeyRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
 type CreateAPIKeyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -173,6 +189,7 @@ type APIKey struct {
 	IsActive      bool                   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
 	LastUsedAt    string                 `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	AllowedCidrs  []string               `protobuf:"bytes,7,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -249,6 +266,13 @@ func (x *APIKey) GetCreatedAt() string {
 	return ""
 }
 
+func (x *APIKey) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
 type GetMerchantAPIKeysRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
@@ -529,6 +553,118 @@ func (x *DeleteAPIKeyResponse) GetMessage() string {
 	return ""
 }
 
+type UpdateAPIKeyAllowedCIDRsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	AllowedCidrs  []string               `protobuf:"bytes,3,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"` // Empty clears the allowlist
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) Reset() {
+	*x = UpdateAPIKeyAllowedCIDRsRequest{}
+	mi := &file_proto_api_key_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAPIKeyAllowedCIDRsRequest) ProtoMessage() {}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_api_key_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAPIKeyAllowedCIDRsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAPIKeyAllowedCIDRsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_api_key_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsRequest) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
+type UpdateAPIKeyAllowedCIDRsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AllowedCidrs  []string               `protobuf:"bytes,1,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsResponse) Reset() {
+	*x = UpdateAPIKeyAllowedCIDRsResponse{}
+	mi := &file_proto_api_key_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAPIKeyAllowedCIDRsResponse) ProtoMessage() {}
+
+func (x *UpdateAPIKeyAllowedCIDRsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_api_key_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAPIKeyAllowedCIDRsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAPIKeyAllowedCIDRsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_api_key_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsResponse) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
+func (x *UpdateAPIKeyAllowedCIDRsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 type GetInfoByAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ApiKey        string                 `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
@@ -538,7 +674,7 @@ type GetInfoByAPIKeyRequest struct {
 
 func (x *GetInfoByAPIKeyRequest) Reset() {
 	*x = GetInfoByAPIKeyRequest{}
-	mi := &file_proto_api_key_service_proto_msgTypes[9]
+	mi := &file_proto_api_key_service_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -550,7 +686,7 @@ func (x *GetInfoByAPIKeyRequest) String() string {
 func (*GetInfoByAPIKeyRequest) ProtoMessage() {}
 
 func (x *GetInfoByAPIKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_api_key_service_proto_msgTypes[9]
+	mi := &file_proto_api_key_service_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -563,7 +699,7 @@ func (x *GetInfoByAPIKeyRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetInfoByAPIKeyRequest.ProtoReflect.Descriptor instead.
 func (*GetInfoByAPIKeyRequest) Descriptor() ([]byte, []int) {
-	return file_proto_api_key_service_proto_rawDescGZIP(), []int{9}
+	return file_proto_api_key_service_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetInfoByAPIKeyRequest) GetApiKey() string {
@@ -580,13 +716,14 @@ type GetInfoByAPIKeyResponse struct {
 	MerchantId    string                 `protobuf:"bytes,3,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	AllowedCidrs  []string               `protobuf:"bytes,6,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetInfoByAPIKeyResponse) Reset() {
 	*x = GetInfoByAPIKeyResponse{}
-	mi := &file_proto_api_key_service_proto_msgTypes[10]
+	mi := &file_proto_api_key_service_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -598,7 +735,7 @@ func (x *GetInfoByAPIKeyResponse) String() string {
 func (*GetInfoByAPIKeyResponse) ProtoMessage() {}
 
 func (x *GetInfoByAPIKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_api_key_service_proto_msgTypes[10]
+	mi := &file_proto_api_key_service_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -611,7 +748,7 @@ func (x *GetInfoByAPIKeyResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetInfoByAPIKeyResponse.ProtoReflect.Descriptor instead.
 func (*GetInfoByAPIKeyResponse) Descriptor() ([]byte, []int) {
-	return file_proto_api_key_service_proto_rawDescGZIP(), []int{10}
+	return file_proto_api_key_service_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *GetInfoByAPIKeyResponse) GetId() string {
@@ -649,17 +786,26 @@ func (x *GetInfoByAPIKeyResponse) GetMessage() string {
 	return ""
 }
 
+func (x *GetInfoByAPIKeyResponse) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
 var File_proto_api_key_service_proto protoreflect.FileDescriptor
 
 const file_proto_api_key_service_proto_rawDesc = "" +
 	"\n" +
-	"\x1bproto/api_key_service.proto\x12\x05proto\"i\n" +
+	"\x1bproto/api_key_service.proto\x12\x05proto\"\xab\x01\n" +
 	"\x13CreateAPIKeyRequest\x12\x1f\n" +
 	"\vmerchant_id\x18\x01 \x01(\tR\n" +
 	"merchantId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
 	"\n" +
-	"created_by\x18\x03 \x01(\tR\tcreatedBy\"\xaf\x01\n" +
+	"created_by\x18\x03 \x01(\tR\tcreatedBy\x12#\n" +
+	"\rallowed_cidrs\x18\x04 \x03(\tR\fallowedCidrs\x12\x1b\n" +
+	"\ttest_mode\x18\x05 \x01(\bR\btestMode\"\xaf\x01\n" +
 	"\x14CreateAPIKeyResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
@@ -668,7 +814,7 @@ const file_proto_api_key_service_proto_rawDesc = "" +
 	"\tplain_key\x18\x04 \x01(\tR\bplainKey\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12\x18\n" +
-	"\amessage\x18\x06 \x01(\tR\amessage\"\xa9\x01\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\"\xce\x01\n" +
 	"\x06APIKey\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
@@ -678,7 +824,8 @@ const file_proto_api_key_service_proto_rawDesc = "" +
 	"\flast_used_at\x18\x05 \x01(\tR\n" +
 	"lastUsedAt\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x06 \x01(\tR\tcreatedAt\"<\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12#\n" +
+	"\rallowed_cidrs\x18\a \x03(\tR\fallowedCidrs\"<\n" +
 	"\x19GetMerchantAPIKeysRequest\x12\x1f\n" +
 	"\vmerchant_id\x18\x01 \x01(\tR\n" +
 	"merchantId\"F\n" +
@@ -695,9 +842,17 @@ const file_proto_api_key_service_proto_rawDesc = "" +
 	"\vmerchant_id\x18\x02 \x01(\tR\n" +
 	"merchantId\"0\n" +
 	"\x14DeleteAPIKeyResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"1\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"w\n" +
+	"\x1fUpdateAPIKeyAllowedCIDRsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12#\n" +
+	"\rallowed_cidrs\x18\x03 \x03(\tR\fallowedCidrs\"a\n" +
+	" UpdateAPIKeyAllowedCIDRsResponse\x12#\n" +
+	"\rallowed_cidrs\x18\x01 \x03(\tR\fallowedCidrs\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"1\n" +
 	"\x16GetInfoByAPIKeyRequest\x12\x17\n" +
-	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\"\x97\x01\n" +
+	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\"\xbc\x01\n" +
 	"\x17GetInfoByAPIKeyResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
@@ -705,13 +860,15 @@ const file_proto_api_key_service_proto_rawDesc = "" +
 	"merchantId\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x18\n" +
-	"\amessage\x18\x05 \x01(\tR\amessage2\xa3\x03\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12#\n" +
+	"\rallowed_cidrs\x18\x06 \x03(\tR\fallowedCidrs2\x90\x04\n" +
 	"\rAPIKeyService\x12G\n" +
 	"\fCreateAPIKey\x12\x1a.proto.CreateAPIKeyRequest\x1a\x1b.proto.CreateAPIKeyResponse\x12Y\n" +
 	"\x12GetMerchantAPIKeys\x12 .proto.GetMerchantAPIKeysRequest\x1a!.proto.GetMerchantAPIKeysResponse\x12S\n" +
 	"\x10DeactivateAPIKey\x12\x1e.proto.DeactivateAPIKeyRequest\x1a\x1f.proto.DeactivateAPIKeyResponse\x12G\n" +
 	"\fDeleteAPIKey\x12\x1a.proto.DeleteAPIKeyRequest\x1a\x1b.proto.DeleteAPIKeyResponse\x12P\n" +
-	"\x0fGetInfoByAPIKey\x12\x1d.proto.GetInfoByAPIKeyRequest\x1a\x1e.proto.GetInfoByAPIKeyResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
+	"\x0fGetInfoByAPIKey\x12\x1d.proto.GetInfoByAPIKeyRequest\x1a\x1e.proto.GetInfoByAPIKeyResponse\x12k\n" +
+	"\x18UpdateAPIKeyAllowedCIDRs\x12&.proto.UpdateAPIKeyAllowedCIDRsRequest\x1a'.proto.UpdateAPIKeyAllowedCIDRsResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
 
 var (
 	file_proto_api_key_service_proto_rawDescOnce sync.Once
@@ -725,19 +882,21 @@ func file_proto_api_key_service_proto_rawDescGZIP() []byte {
 	return file_proto_api_key_service_proto_rawDescData
 }
 
-var file_proto_api_key_service_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_api_key_service_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_proto_api_key_service_proto_goTypes = []any{
-	(*CreateAPIKeyRequest)(nil),        // 0: proto.CreateAPIKeyRequest
-	(*CreateAPIKeyResponse)(nil),       // 1: proto.CreateAPIKeyResponse
-	(*APIKey)(nil),                     // 2: proto.APIKey
-	(*GetMerchantAPIKeysRequest)(nil),  // 3: proto.GetMerchantAPIKeysRequest
-	(*GetMerchantAPIKeysResponse)(nil), // 4: proto.GetMerchantAPIKeysResponse
-	(*DeactivateAPIKeyRequest)(nil),    // 5: proto.DeactivateAPIKeyRequest
-	(*DeactivateAPIKeyResponse)(nil),   // 6: proto.DeactivateAPIKeyResponse
-	(*DeleteAPIKeyRequest)(nil),        // 7: proto.DeleteAPIKeyRequest
-	(*DeleteAPIKeyResponse)(nil),       // 8: proto.DeleteAPIKeyResponse
-	(*GetInfoByAPIKeyRequest)(nil),     // 9: proto.GetInfoByAPIKeyRequest
-	(*GetInfoByAPIKeyResponse)(nil),    // 10: proto.GetInfoByAPIKeyResponse
+	(*CreateAPIKeyRequest)(nil),              // 0: proto.CreateAPIKeyRequest
+	(*CreateAPIKeyResponse)(nil),             // 1: proto.CreateAPIKeyResponse
+	(*APIKey)(nil),                           // 2: proto.APIKey
+	(*GetMerchantAPIKeysRequest)(nil),        // 3: proto.GetMerchantAPIKeysRequest
+	(*GetMerchantAPIKeysResponse)(nil),       // 4: proto.GetMerchantAPIKeysResponse
+	(*DeactivateAPIKeyRequest)(nil),          // 5: proto.DeactivateAPIKeyRequest
+	(*DeactivateAPIKeyResponse)(nil),         // 6: proto.DeactivateAPIKeyResponse
+	(*DeleteAPIKeyRequest)(nil),              // 7: proto.DeleteAPIKeyRequest
+	(*DeleteAPIKeyResponse)(nil),             // 8: proto.DeleteAPIKeyResponse
+	(*UpdateAPIKeyAllowedCIDRsRequest)(nil),  // 9: proto.UpdateAPIKeyAllowedCIDRsRequest
+	(*UpdateAPIKeyAllowedCIDRsResponse)(nil), // 10: proto.UpdateAPIKeyAllowedCIDRsResponse
+	(*GetInfoByAPIKeyRequest)(nil),           // 11: proto.GetInfoByAPIKeyRequest
+	(*GetInfoByAPIKeyResponse)(nil),          // 12: proto.GetInfoByAPIKeyResponse
 }
 var file_proto_api_key_service_proto_depIdxs = []int32{
 	2,  // 0: proto.GetMerchantAPIKeysResponse.api_keys:type_name -> proto.APIKey
@@ -745,14 +904,16 @@ var file_proto_api_key_service_proto_depIdxs = []int32{
 	3,  // 2: proto.APIKeyService.GetMerchantAPIKeys:input_type -> proto.GetMerchantAPIKeysRequest
 	5,  // 3: proto.APIKeyService.DeactivateAPIKey:input_type -> proto.DeactivateAPIKeyRequest
 	7,  // 4: proto.APIKeyService.DeleteAPIKey:input_type -> proto.DeleteAPIKeyRequest
-	9,  // 5: proto.APIKeyService.GetInfoByAPIKey:input_type -> proto.GetInfoByAPIKeyRequest
-	1,  // 6: proto.APIKeyService.CreateAPIKey:output_type -> proto.CreateAPIKeyResponse
-	4,  // 7: proto.APIKeyService.GetMerchantAPIKeys:output_type -> proto.GetMerchantAPIKeysResponse
-	6,  // 8: proto.APIKeyService.DeactivateAPIKey:output_type -> proto.DeactivateAPIKeyResponse
-	8,  // 9: proto.APIKeyService.DeleteAPIKey:output_type -> proto.DeleteAPIKeyResponse
-	10, // 10: proto.APIKeyService.GetInfoByAPIKey:output_type -> proto.GetInfoByAPIKeyResponse
-	6,  // [6:11] is the sub-list for method output_type
-	1,  // [1:6] is the sub-list for method input_type
+	11, // 5: proto.APIKeyService.GetInfoByAPIKey:input_type -> proto.GetInfoByAPIKeyRequest
+	9,  // 6: proto.APIKeyService.UpdateAPIKeyAllowedCIDRs:input_type -> proto.UpdateAPIKeyAllowedCIDRsRequest
+	1,  // 7: proto.APIKeyService.CreateAPIKey:output_type -> proto.CreateAPIKeyResponse
+	4,  // 8: proto.APIKeyService.GetMerchantAPIKeys:output_type -> proto.GetMerchantAPIKeysResponse
+	6,  // 9: proto.APIKeyService.DeactivateAPIKey:output_type -> proto.DeactivateAPIKeyResponse
+	8,  // 10: proto.APIKeyService.DeleteAPIKey:output_type -> proto.DeleteAPIKeyResponse
+	12, // 11: proto.APIKeyService.GetInfoByAPIKey:output_type -> proto.GetInfoByAPIKeyResponse
+	10, // 12: proto.APIKeyService.UpdateAPIKeyAllowedCIDRs:output_type -> proto.UpdateAPIKeyAllowedCIDRsResponse
+	7,  // [7:13] is the sub-list for method output_type
+	1,  // [1:7] is the sub-list for method input_type
 	1,  // [1:1] is the sub-list for extension type_name
 	1,  // [1:1] is the sub-list for extension extendee
 	0,  // [0:1] is the sub-list for field type_name
@@ -769,7 +930,7 @@ func file_proto_api_key_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_api_key_service_proto_rawDesc), len(file_proto_api_key_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
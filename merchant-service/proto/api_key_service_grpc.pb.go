@@ -19,11 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	APIKeyService_CreateAPIKey_FullMethodName       = "/proto.APIKeyService/CreateAPIKey"
-	APIKeyService_GetMerchantAPIKeys_FullMethodName = "/proto.APIKeyService/GetMerchantAPIKeys"
-	APIKeyService_DeactivateAPIKey_FullMethodName   = "/proto.APIKeyService/DeactivateAPIKey"
-	APIKeyService_DeleteAPIKey_FullMethodName       = "/proto.APIKeyService/DeleteAPIKey"
-	APIKeyService_GetInfoByAPIKey_FullMethodName    = "/proto.APIKeyService/GetInfoByAPIKey"
+	APIKeyService_CreateAPIKey_FullMethodName             = "/proto.APIKeyService/CreateAPIKey"
+	APIKeyService_GetMerchantAPIKeys_FullMethodName       = "/proto.APIKeyService/GetMerchantAPIKeys"
+	APIKeyService_DeactivateAPIKey_FullMethodName         = "/proto.APIKeyService/DeactivateAPIKey"
+	APIKeyService_DeleteAPIKey_FullMethodName             = "/proto.APIKeyService/DeleteAPIKey"
+	APIKeyService_GetInfoByAPIKey_FullMethodName          = "/proto.APIKeyService/GetInfoByAPIKey"
+	APIKeyService_UpdateAPIKeyAllowedCIDRs_FullMethodName = "/proto.APIKeyService/UpdateAPIKeyAllowedCIDRs"
 )
 
 // APIKeyServiceClient is the client API for APIKeyService service.
@@ -35,6 +36,7 @@ type APIKeyServiceClient interface {
 	DeactivateAPIKey(ctx context.Context, in *DeactivateAPIKeyRequest, opts ...grpc.CallOption) (*DeactivateAPIKeyResponse, error)
 	DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*DeleteAPIKeyResponse, error)
 	GetInfoByAPIKey(ctx context.Context, in *GetInfoByAPIKeyRequest, opts ...grpc.CallOption) (*GetInfoByAPIKeyResponse, error)
+	UpdateAPIKeyAllowedCIDRs(ctx context.Context, in *UpdateAPIKeyAllowedCIDRsRequest, opts ...grpc.CallOption) (*UpdateAPIKeyAllowedCIDRsResponse, error)
 }
 
 type aPIKeyServiceClient struct {
@@ -95,6 +97,16 @@ func (c *aPIKeyServiceClient) GetInfoByAPIKey(ctx context.Context, in *GetInfoBy
 	return out, nil
 }
 
+func (c *aPIKeyServiceClient) UpdateAPIKeyAllowedCIDRs(ctx context.Context, in *UpdateAPIKeyAllowedCIDRsRequest, opts ...grpc.CallOption) (*UpdateAPIKeyAllowedCIDRsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAPIKeyAllowedCIDRsResponse)
+	err := c.cc.Invoke(ctx, APIKeyService_UpdateAPIKeyAllowedCIDRs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIKeyServiceServer is the server API for APIKeyService service.
 // All implementations must embed UnimplementedAPIKeyServiceServer
 // for forward compatibility.
@@ -104,6 +116,7 @@ type APIKeyServiceServer interface {
 	DeactivateAPIKey(context.Context, *DeactivateAPIKeyRequest) (*DeactivateAPIKeyResponse, error)
 	DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error)
 	GetInfoByAPIKey(context.Context, *GetInfoByAPIKeyRequest) (*GetInfoByAPIKeyResponse, error)
+	UpdateAPIKeyAllowedCIDRs(context.Context, *UpdateAPIKeyAllowedCIDRsRequest) (*UpdateAPIKeyAllowedCIDRsResponse, error)
 	mustEmbedUnimplementedAPIKeyServiceServer()
 }
 
@@ -129,6 +142,9 @@ func (UnimplementedAPIKeyServiceServer) DeleteAPIKey(context.Context, *DeleteAPI
 func (UnimplementedAPIKeyServiceServer) GetInfoByAPIKey(context.Context, *GetInfoByAPIKeyRequest) (*GetInfoByAPIKeyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetInfoByAPIKey not implemented")
 }
+func (UnimplementedAPIKeyServiceServer) UpdateAPIKeyAllowedCIDRs(context.Context, *UpdateAPIKeyAllowedCIDRsRequest) (*UpdateAPIKeyAllowedCIDRsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAPIKeyAllowedCIDRs not implemented")
+}
 func (UnimplementedAPIKeyServiceServer) mustEmbedUnimplementedAPIKeyServiceServer() {}
 func (UnimplementedAPIKeyServiceServer) testEmbeddedByValue()                       {}
 
@@ -240,6 +256,24 @@ func _APIKeyService_GetInfoByAPIKey_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _APIKeyService_UpdateAPIKeyAllowedCIDRs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAPIKeyAllowedCIDRsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIKeyServiceServer).UpdateAPIKeyAllowedCIDRs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: APIKeyService_UpdateAPIKeyAllowedCIDRs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIKeyServiceServer).UpdateAPIKeyAllowedCIDRs(ctx, req.(*UpdateAPIKeyAllowedCIDRsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // APIKeyService_ServiceDesc is the grpc.ServiceDesc for APIKeyService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -267,6 +301,10 @@ var APIKeyService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetInfoByAPIKey",
 			Handler:    _APIKeyService_GetInfoByAPIKey_Handler,
 		},
+		{
+			MethodName: "UpdateAPIKeyAllowedCIDRs",
+			Handler:    _APIKeyService_UpdateAPIKeyAllowedCIDRs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/api_key_service.proto",
@@ -0,0 +1,480 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: proto/key_management.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetDataKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Purpose       string                 `protobuf:"bytes,2,opt,name=purpose,proto3" json:"purpose,omitempty"`          // "payment_pii"
+	KeyId         string                 `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"` // empty for the active key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataKeyRequest) Reset() {
+	*x = GetDataKeyRequest{}
+	mi := &file_proto_key_management_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataKeyRequest) ProtoMessage() {}
+
+func (x *GetDataKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataKeyRequest.ProtoReflect.Descriptor instead.
+func (*GetDataKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetDataKeyRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *GetDataKeyRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *GetDataKeyRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+type GetDataKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	Key           []byte                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`            // 32-byte AES-256 key
+	Shredded      bool                   `protobuf:"varint,3,opt,name=shredded,proto3" json:"shredded,omitempty"` // the key was revoked; its data is gone
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataKeyResponse) Reset() {
+	*x = GetDataKeyResponse{}
+	mi := &file_proto_key_management_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataKeyResponse) ProtoMessage() {}
+
+func (x *GetDataKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataKeyResponse.ProtoReflect.Descriptor instead.
+func (*GetDataKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetDataKeyResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *GetDataKeyResponse) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *GetDataKeyResponse) GetShredded() bool {
+	if x != nil {
+		return x.Shredded
+	}
+	return false
+}
+
+func (x *GetDataKeyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ShredMerchantKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Purpose       string                 `protobuf:"bytes,2,opt,name=purpose,proto3" json:"purpose,omitempty"`                            // "payment_pii" or "card_data"
+	RequestedBy   string                 `protobuf:"bytes,3,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"` // UUID
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShredMerchantKeysRequest) Reset() {
+	*x = ShredMerchantKeysRequest{}
+	mi := &file_proto_key_management_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShredMerchantKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShredMerchantKeysRequest) ProtoMessage() {}
+
+func (x *ShredMerchantKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShredMerchantKeysRequest.ProtoReflect.Descriptor instead.
+func (*ShredMerchantKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ShredMerchantKeysRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ShredMerchantKeysRequest) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *ShredMerchantKeysRequest) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *ShredMerchantKeysRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ShredMerchantKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShreddedKeys  int32                  `protobuf:"varint,1,opt,name=shredded_keys,json=shreddedKeys,proto3" json:"shredded_keys,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShredMerchantKeysResponse) Reset() {
+	*x = ShredMerchantKeysResponse{}
+	mi := &file_proto_key_management_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShredMerchantKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShredMerchantKeysResponse) ProtoMessage() {}
+
+func (x *ShredMerchantKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShredMerchantKeysResponse.ProtoReflect.Descriptor instead.
+func (*ShredMerchantKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ShredMerchantKeysResponse) GetShreddedKeys() int32 {
+	if x != nil {
+		return x.ShreddedKeys
+	}
+	return 0
+}
+
+func (x *ShredMerchantKeysResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RevokeMerchantTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	RevokedBy     string                 `protobuf:"bytes,2,opt,name=revoked_by,json=revokedBy,proto3" json:"revoked_by,omitempty"` // UUID
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMerchantTokensRequest) Reset() {
+	*x = RevokeMerchantTokensRequest{}
+	mi := &file_proto_key_management_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMerchantTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMerchantTokensRequest) ProtoMessage() {}
+
+func (x *RevokeMerchantTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMerchantTokensRequest.ProtoReflect.Descriptor instead.
+func (*RevokeMerchantTokensRequest) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RevokeMerchantTokensRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *RevokeMerchantTokensRequest) GetRevokedBy() string {
+	if x != nil {
+		return x.RevokedBy
+	}
+	return ""
+}
+
+func (x *RevokeMerchantTokensRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RevokeMerchantTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RevokedTokens int32                  `protobuf:"varint,1,opt,name=revoked_tokens,json=revokedTokens,proto3" json:"revoked_tokens,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeMerchantTokensResponse) Reset() {
+	*x = RevokeMerchantTokensResponse{}
+	mi := &file_proto_key_management_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeMerchantTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeMerchantTokensResponse) ProtoMessage() {}
+
+func (x *RevokeMerchantTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_key_management_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeMerchantTokensResponse.ProtoReflect.Descriptor instead.
+func (*RevokeMerchantTokensResponse) Descriptor() ([]byte, []int) {
+	return file_proto_key_management_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RevokeMerchantTokensResponse) GetRevokedTokens() int32 {
+	if x != nil {
+		return x.RevokedTokens
+	}
+	return 0
+}
+
+func (x *RevokeMerchantTokensResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_proto_key_management_proto protoreflect.FileDescriptor
+
+const file_proto_key_management_proto_rawDesc = "" +
+	"\n" +
+	"\x1aproto/key_management.proto\x12\ftokenization\"e\n" +
+	"\x11GetDataKeyRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x18\n" +
+	"\apurpose\x18\x02 \x01(\tR\apurpose\x12\x15\n" +
+	"\x06key_id\x18\x03 \x01(\tR\x05keyId\"o\n" +
+	"\x12GetDataKeyResponse\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\fR\x03key\x12\x1a\n" +
+	"\bshredded\x18\x03 \x01(\bR\bshredded\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x90\x01\n" +
+	"\x18ShredMerchantKeysRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x18\n" +
+	"\apurpose\x18\x02 \x01(\tR\apurpose\x12!\n" +
+	"\frequested_by\x18\x03 \x01(\tR\vrequestedBy\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"V\n" +
+	"\x19ShredMerchantKeysResponse\x12#\n" +
+	"\rshredded_keys\x18\x01 \x01(\x05R\fshreddedKeys\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"u\n" +
+	"\x1bRevokeMerchantTokensRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1d\n" +
+	"\n" +
+	"revoked_by\x18\x02 \x01(\tR\trevokedBy\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"[\n" +
+	"\x1cRevokeMerchantTokensResponse\x12%\n" +
+	"\x0erevoked_tokens\x18\x01 \x01(\x05R\rrevokedTokens\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xbc\x02\n" +
+	"\x14KeyManagementService\x12O\n" +
+	"\n" +
+	"GetDataKey\x12\x1f.tokenization.GetDataKeyRequest\x1a .tokenization.GetDataKeyResponse\x12d\n" +
+	"\x11ShredMerchantKeys\x12&.tokenization.ShredMerchantKeysRequest\x1a'.tokenization.ShredMerchantKeysResponse\x12m\n" +
+	"\x14RevokeMerchantTokens\x12).tokenization.RevokeMerchantTokensRequest\x1a*.tokenization.RevokeMerchantTokensResponseB@Z>github.com/rhaloubi/payment-gateway/tokenization-service/protob\x06proto3"
+
+var (
+	file_proto_key_management_proto_rawDescOnce sync.Once
+	file_proto_key_management_proto_rawDescData []byte
+)
+
+func file_proto_key_management_proto_rawDescGZIP() []byte {
+	file_proto_key_management_proto_rawDescOnce.Do(func() {
+		file_proto_key_management_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_key_management_proto_rawDesc), len(file_proto_key_management_proto_rawDesc)))
+	})
+	return file_proto_key_management_proto_rawDescData
+}
+
+var file_proto_key_management_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_key_management_proto_goTypes = []any{
+	(*GetDataKeyRequest)(nil),            // 0: tokenization.GetDataKeyRequest
+	(*GetDataKeyResponse)(nil),           // 1: tokenization.GetDataKeyResponse
+	(*ShredMerchantKeysRequest)(nil),     // 2: tokenization.ShredMerchantKeysRequest
+	(*ShredMerchantKeysResponse)(nil),    // 3: tokenization.ShredMerchantKeysResponse
+	(*RevokeMerchantTokensRequest)(nil),  // 4: tokenization.RevokeMerchantTokensRequest
+	(*RevokeMerchantTokensResponse)(nil), // 5: tokenization.RevokeMerchantTokensResponse
+}
+var file_proto_key_management_proto_depIdxs = []int32{
+	0, // 0: tokenization.KeyManagementService.GetDataKey:input_type -> tokenization.GetDataKeyRequest
+	2, // 1: tokenization.KeyManagementService.ShredMerchantKeys:input_type -> tokenization.ShredMerchantKeysRequest
+	4, // 2: tokenization.KeyManagementService.RevokeMerchantTokens:input_type -> tokenization.RevokeMerchantTokensRequest
+	1, // 3: tokenization.KeyManagementService.GetDataKey:output_type -> tokenization.GetDataKeyResponse
+	3, // 4: tokenization.KeyManagementService.ShredMerchantKeys:output_type -> tokenization.ShredMerchantKeysResponse
+	5, // 5: tokenization.KeyManagementService.RevokeMerchantTokens:output_type -> tokenization.RevokeMerchantTokensResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proto_key_management_proto_init() }
+func file_proto_key_management_proto_init() {
+	if File_proto_key_management_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_key_management_proto_rawDesc), len(file_proto_key_management_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_key_management_proto_goTypes,
+		DependencyIndexes: file_proto_key_management_proto_depIdxs,
+		MessageInfos:      file_proto_key_management_proto_msgTypes,
+	}.Build()
+	File_proto_key_management_proto = out.File
+	file_proto_key_management_proto_goTypes = nil
+	file_proto_key_management_proto_depIdxs = nil
+}
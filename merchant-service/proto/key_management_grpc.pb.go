@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/key_management.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	KeyManagementService_GetDataKey_FullMethodName           = "/tokenization.KeyManagementService/GetDataKey"
+	KeyManagementService_ShredMerchantKeys_FullMethodName    = "/tokenization.KeyManagementService/ShredMerchantKeys"
+	KeyManagementService_RevokeMerchantTokens_FullMethodName = "/tokenization.KeyManagementService/RevokeMerchantTokens"
+)
+
+// KeyManagementServiceClient is the client API for KeyManagementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// KeyManagementService hands out per-merchant data keys to other internal
+// services and tears a merchant's keys and tokens down on account closure.
+// Keys are scoped by purpose; card data keys are never exposed.
+type KeyManagementServiceClient interface {
+	// GetDataKey returns the merchant's active key for a purpose, or a
+	// specific key version when key_id is set (internal only)
+	GetDataKey(ctx context.Context, in *GetDataKeyRequest, opts ...grpc.CallOption) (*GetDataKeyResponse, error)
+	// ShredMerchantKeys revokes every key a merchant has for a purpose,
+	// making the data encrypted under them unrecoverable
+	ShredMerchantKeys(ctx context.Context, in *ShredMerchantKeysRequest, opts ...grpc.CallOption) (*ShredMerchantKeysResponse, error)
+	// RevokeMerchantTokens revokes every active card token of a merchant
+	RevokeMerchantTokens(ctx context.Context, in *RevokeMerchantTokensRequest, opts ...grpc.CallOption) (*RevokeMerchantTokensResponse, error)
+}
+
+type keyManagementServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKeyManagementServiceClient(cc grpc.ClientConnInterface) KeyManagementServiceClient {
+	return &keyManagementServiceClient{cc}
+}
+
+func (c *keyManagementServiceClient) GetDataKey(ctx context.Context, in *GetDataKeyRequest, opts ...grpc.CallOption) (*GetDataKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDataKeyResponse)
+	err := c.cc.Invoke(ctx, KeyManagementService_GetDataKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyManagementServiceClient) ShredMerchantKeys(ctx context.Context, in *ShredMerchantKeysRequest, opts ...grpc.CallOption) (*ShredMerchantKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShredMerchantKeysResponse)
+	err := c.cc.Invoke(ctx, KeyManagementService_ShredMerchantKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyManagementServiceClient) RevokeMerchantTokens(ctx context.Context, in *RevokeMerchantTokensRequest, opts ...grpc.CallOption) (*RevokeMerchantTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeMerchantTokensResponse)
+	err := c.cc.Invoke(ctx, KeyManagementService_RevokeMerchantTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KeyManagementServiceServer is the server API for KeyManagementService service.
+// All implementations must embed UnimplementedKeyManagementServiceServer
+// for forward compatibility.
+//
+// KeyManagementService hands out per-merchant data keys to other internal
+// services and tears a merchant's keys and tokens down on account closure.
+// Keys are scoped by purpose; card data keys are never exposed.
+type KeyManagementServiceServer interface {
+	// GetDataKey returns the merchant's active key for a purpose, or a
+	// specific key version when key_id is set (internal only)
+	GetDataKey(context.Context, *GetDataKeyRequest) (*GetDataKeyResponse, error)
+	// ShredMerchantKeys revokes every key a merchant has for a purpose,
+	// making the data encrypted under them unrecoverable
+	ShredMerchantKeys(context.Context, *ShredMerchantKeysRequest) (*ShredMerchantKeysResponse, error)
+	// RevokeMerchantTokens revokes every active card token of a merchant
+	RevokeMerchantTokens(context.Context, *RevokeMerchantTokensRequest) (*RevokeMerchantTokensResponse, error)
+	mustEmbedUnimplementedKeyManagementServiceServer()
+}
+
+// UnimplementedKeyManagementServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKeyManagementServiceServer struct{}
+
+func (UnimplementedKeyManagementServiceServer) GetDataKey(context.Context, *GetDataKeyRequest) (*GetDataKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDataKey not implemented")
+}
+func (UnimplementedKeyManagementServiceServer) ShredMerchantKeys(context.Context, *ShredMerchantKeysRequest) (*ShredMerchantKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShredMerchantKeys not implemented")
+}
+func (UnimplementedKeyManagementServiceServer) RevokeMerchantTokens(context.Context, *RevokeMerchantTokensRequest) (*RevokeMerchantTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeMerchantTokens not implemented")
+}
+func (UnimplementedKeyManagementServiceServer) mustEmbedUnimplementedKeyManagementServiceServer() {}
+func (UnimplementedKeyManagementServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeKeyManagementServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KeyManagementServiceServer will
+// result in compilation errors.
+type UnsafeKeyManagementServiceServer interface {
+	mustEmbedUnimplementedKeyManagementServiceServer()
+}
+
+func RegisterKeyManagementServiceServer(s grpc.ServiceRegistrar, srv KeyManagementServiceServer) {
+	// If the following call pancis, it indicates UnimplementedKeyManagementServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&KeyManagementService_ServiceDesc, srv)
+}
+
+func _KeyManagementService_GetDataKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDataKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).GetDataKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyManagementService_GetDataKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).GetDataKey(ctx, req.(*GetDataKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyManagementService_ShredMerchantKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShredMerchantKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).ShredMerchantKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyManagementService_ShredMerchantKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).ShredMerchantKeys(ctx, req.(*ShredMerchantKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyManagementService_RevokeMerchantTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeMerchantTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyManagementServiceServer).RevokeMerchantTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyManagementService_RevokeMerchantTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyManagementServiceServer).RevokeMerchantTokens(ctx, req.(*RevokeMerchantTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// KeyManagementService_ServiceDesc is the grpc.ServiceDesc for KeyManagementService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KeyManagementService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tokenization.KeyManagementService",
+	HandlerType: (*KeyManagementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetDataKey",
+			Handler:    _KeyManagementService_GetDataKey_Handler,
+		},
+		{
+			MethodName: "ShredMerchantKeys",
+			Handler:    _KeyManagementService_ShredMerchantKeys_Handler,
+		},
+		{
+			MethodName: "RevokeMerchantTokens",
+			Handler:    _KeyManagementService_RevokeMerchantTokens_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/key_management.proto",
+}
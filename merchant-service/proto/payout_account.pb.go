@@ -0,0 +1,439 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: proto/payout_account.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetPayoutAccountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPayoutAccountRequest) Reset() {
+	*x = GetPayoutAccountRequest{}
+	mi := &file_proto_payout_account_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPayoutAccountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPayoutAccountRequest) ProtoMessage() {}
+
+func (x *GetPayoutAccountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payout_account_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPayoutAccountRequest.ProtoReflect.Descriptor instead.
+func (*GetPayoutAccountRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payout_account_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetPayoutAccountRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type GetPayoutAccountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Account       *PayoutAccount         `protobuf:"bytes,2,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPayoutAccountResponse) Reset() {
+	*x = GetPayoutAccountResponse{}
+	mi := &file_proto_payout_account_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPayoutAccountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPayoutAccountResponse) ProtoMessage() {}
+
+func (x *GetPayoutAccountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payout_account_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPayoutAccountResponse.ProtoReflect.Descriptor instead.
+func (*GetPayoutAccountResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payout_account_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetPayoutAccountResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *GetPayoutAccountResponse) GetAccount() *PayoutAccount {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *GetPayoutAccountResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PayoutAccount struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MerchantId        string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	AccountHolderName string                 `protobuf:"bytes,3,opt,name=account_holder_name,json=accountHolderName,proto3" json:"account_holder_name,omitempty"`
+	BankName          string                 `protobuf:"bytes,4,opt,name=bank_name,json=bankName,proto3" json:"bank_name,omitempty"`
+	BankCode          string                 `protobuf:"bytes,5,opt,name=bank_code,json=bankCode,proto3" json:"bank_code,omitempty"`
+	Iban              string                 `protobuf:"bytes,6,opt,name=iban,proto3" json:"iban,omitempty"`
+	Rib               string                 `protobuf:"bytes,7,opt,name=rib,proto3" json:"rib,omitempty"`
+	Currency          string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	VerifiedAt        string                 `protobuf:"bytes,9,opt,name=verified_at,json=verifiedAt,proto3" json:"verified_at,omitempty"` // RFC 3339
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PayoutAccount) Reset() {
+	*x = PayoutAccount{}
+	mi := &file_proto_payout_account_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayoutAccount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayoutAccount) ProtoMessage() {}
+
+func (x *PayoutAccount) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payout_account_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayoutAccount.ProtoReflect.Descriptor instead.
+func (*PayoutAccount) Descriptor() ([]byte, []int) {
+	return file_proto_payout_account_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PayoutAccount) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetAccountHolderName() string {
+	if x != nil {
+		return x.AccountHolderName
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetBankName() string {
+	if x != nil {
+		return x.BankName
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetBankCode() string {
+	if x != nil {
+		return x.BankCode
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetIban() string {
+	if x != nil {
+		return x.Iban
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetRib() string {
+	if x != nil {
+		return x.Rib
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PayoutAccount) GetVerifiedAt() string {
+	if x != nil {
+		return x.VerifiedAt
+	}
+	return ""
+}
+
+type GetPayoutEligibilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPayoutEligibilityRequest) Reset() {
+	*x = GetPayoutEligibilityRequest{}
+	mi := &file_proto_payout_account_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPayoutEligibilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPayoutEligibilityRequest) ProtoMessage() {}
+
+func (x *GetPayoutEligibilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payout_account_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPayoutEligibilityRequest.ProtoReflect.Descriptor instead.
+func (*GetPayoutEligibilityRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payout_account_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPayoutEligibilityRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type GetPayoutEligibilityResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	MerchantStatus     string                 `protobuf:"bytes,1,opt,name=merchant_status,json=merchantStatus,proto3" json:"merchant_status,omitempty"`             // pending_review, active, suspended, closing, closed
+	VerificationStatus string                 `protobuf:"bytes,2,opt,name=verification_status,json=verificationStatus,proto3" json:"verification_status,omitempty"` // unverified, pending, verified, rejected
+	HasPayoutAccount   bool                   `protobuf:"varint,3,opt,name=has_payout_account,json=hasPayoutAccount,proto3" json:"has_payout_account,omitempty"`    // A verified default bank account exists
+	Error              string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GetPayoutEligibilityResponse) Reset() {
+	*x = GetPayoutEligibilityResponse{}
+	mi := &file_proto_payout_account_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPayoutEligibilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPayoutEligibilityResponse) ProtoMessage() {}
+
+func (x *GetPayoutEligibilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payout_account_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPayoutEligibilityResponse.ProtoReflect.Descriptor instead.
+func (*GetPayoutEligibilityResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payout_account_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPayoutEligibilityResponse) GetMerchantStatus() string {
+	if x != nil {
+		return x.MerchantStatus
+	}
+	return ""
+}
+
+func (x *GetPayoutEligibilityResponse) GetVerificationStatus() string {
+	if x != nil {
+		return x.VerificationStatus
+	}
+	return ""
+}
+
+func (x *GetPayoutEligibilityResponse) GetHasPayoutAccount() bool {
+	if x != nil {
+		return x.HasPayoutAccount
+	}
+	return false
+}
+
+func (x *GetPayoutEligibilityResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_proto_payout_account_proto protoreflect.FileDescriptor
+
+const file_proto_payout_account_proto_rawDesc = "" +
+	"\n" +
+	"\x1aproto/payout_account.proto\x12\bmerchant\":\n" +
+	"\x17GetPayoutAccountRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\"y\n" +
+	"\x18GetPayoutAccountResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x121\n" +
+	"\aaccount\x18\x02 \x01(\v2\x17.merchant.PayoutAccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x8d\x02\n" +
+	"\rPayoutAccount\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12.\n" +
+	"\x13account_holder_name\x18\x03 \x01(\tR\x11accountHolderName\x12\x1b\n" +
+	"\tbank_name\x18\x04 \x01(\tR\bbankName\x12\x1b\n" +
+	"\tbank_code\x18\x05 \x01(\tR\bbankCode\x12\x12\n" +
+	"\x04iban\x18\x06 \x01(\tR\x04iban\x12\x10\n" +
+	"\x03rib\x18\a \x01(\tR\x03rib\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x1f\n" +
+	"\vverified_at\x18\t \x01(\tR\n" +
+	"verifiedAt\">\n" +
+	"\x1bGetPayoutEligibilityRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\"\xbc\x01\n" +
+	"\x1cGetPayoutEligibilityResponse\x12'\n" +
+	"\x0fmerchant_status\x18\x01 \x01(\tR\x0emerchantStatus\x12/\n" +
+	"\x13verification_status\x18\x02 \x01(\tR\x12verificationStatus\x12,\n" +
+	"\x12has_payout_account\x18\x03 \x01(\bR\x10hasPayoutAccount\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error2\xd8\x01\n" +
+	"\x14PayoutAccountService\x12Y\n" +
+	"\x10GetPayoutAccount\x12!.merchant.GetPayoutAccountRequest\x1a\".merchant.GetPayoutAccountResponse\x12e\n" +
+	"\x14GetPayoutEligibility\x12%.merchant.GetPayoutEligibilityRequest\x1a&.merchant.GetPayoutEligibilityResponseB<Z:github.com/rhaloubi/payment-gateway/merchant-service/protob\x06proto3"
+
+var (
+	file_proto_payout_account_proto_rawDescOnce sync.Once
+	file_proto_payout_account_proto_rawDescData []byte
+)
+
+func file_proto_payout_account_proto_rawDescGZIP() []byte {
+	file_proto_payout_account_proto_rawDescOnce.Do(func() {
+		file_proto_payout_account_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_payout_account_proto_rawDesc), len(file_proto_payout_account_proto_rawDesc)))
+	})
+	return file_proto_payout_account_proto_rawDescData
+}
+
+var file_proto_payout_account_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_payout_account_proto_goTypes = []any{
+	(*GetPayoutAccountRequest)(nil),      // 0: merchant.GetPayoutAccountRequest
+	(*GetPayoutAccountResponse)(nil),     // 1: merchant.GetPayoutAccountResponse
+	(*PayoutAccount)(nil),                // 2: merchant.PayoutAccount
+	(*GetPayoutEligibilityRequest)(nil),  // 3: merchant.GetPayoutEligibilityRequest
+	(*GetPayoutEligibilityResponse)(nil), // 4: merchant.GetPayoutEligibilityResponse
+}
+var file_proto_payout_account_proto_depIdxs = []int32{
+	2, // 0: merchant.GetPayoutAccountResponse.account:type_name -> merchant.PayoutAccount
+	0, // 1: merchant.PayoutAccountService.GetPayoutAccount:input_type -> merchant.GetPayoutAccountRequest
+	3, // 2: merchant.PayoutAccountService.GetPayoutEligibility:input_type -> merchant.GetPayoutEligibilityRequest
+	1, // 3: merchant.PayoutAccountService.GetPayoutAccount:output_type -> merchant.GetPayoutAccountResponse
+	4, // 4: merchant.PayoutAccountService.GetPayoutEligibility:output_type -> merchant.GetPayoutEligibilityResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_payout_account_proto_init() }
+func file_proto_payout_account_proto_init() {
+	if File_proto_payout_account_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payout_account_proto_rawDesc), len(file_proto_payout_account_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_payout_account_proto_goTypes,
+		DependencyIndexes: file_proto_payout_account_proto_depIdxs,
+		MessageInfos:      file_proto_payout_account_proto_msgTypes,
+	}.Build()
+	File_proto_payout_account_proto = out.File
+	file_proto_payout_account_proto_goTypes = nil
+	file_proto_payout_account_proto_depIdxs = nil
+}
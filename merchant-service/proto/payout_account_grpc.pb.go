@@ -0,0 +1,173 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/payout_account.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PayoutAccountService_GetPayoutAccount_FullMethodName     = "/merchant.PayoutAccountService/GetPayoutAccount"
+	PayoutAccountService_GetPayoutEligibility_FullMethodName = "/merchant.PayoutAccountService/GetPayoutEligibility"
+)
+
+// PayoutAccountServiceClient is the client API for PayoutAccountService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PayoutAccountService lets internal services look up where a merchant's
+// settlements are paid out to
+type PayoutAccountServiceClient interface {
+	// GetPayoutAccount returns the merchant's default verified bank account.
+	// found is false when the merchant has none.
+	GetPayoutAccount(ctx context.Context, in *GetPayoutAccountRequest, opts ...grpc.CallOption) (*GetPayoutAccountResponse, error)
+	// GetPayoutEligibility returns the merchant's account and KYC status, for
+	// payouts that need more than a bank account, such as instant payouts
+	GetPayoutEligibility(ctx context.Context, in *GetPayoutEligibilityRequest, opts ...grpc.CallOption) (*GetPayoutEligibilityResponse, error)
+}
+
+type payoutAccountServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPayoutAccountServiceClient(cc grpc.ClientConnInterface) PayoutAccountServiceClient {
+	return &payoutAccountServiceClient{cc}
+}
+
+func (c *payoutAccountServiceClient) GetPayoutAccount(ctx context.Context, in *GetPayoutAccountRequest, opts ...grpc.CallOption) (*GetPayoutAccountResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPayoutAccountResponse)
+	err := c.cc.Invoke(ctx, PayoutAccountService_GetPayoutAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payoutAccountServiceClient) GetPayoutEligibility(ctx context.Context, in *GetPayoutEligibilityRequest, opts ...grpc.CallOption) (*GetPayoutEligibilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPayoutEligibilityResponse)
+	err := c.cc.Invoke(ctx, PayoutAccountService_GetPayoutEligibility_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PayoutAccountServiceServer is the server API for PayoutAccountService service.
+// All implementations must embed UnimplementedPayoutAccountServiceServer
+// for forward compatibility.
+//
+// PayoutAccountService lets internal services look up where a merchant's
+// settlements are paid out to
+type PayoutAccountServiceServer interface {
+	// GetPayoutAccount returns the merchant's default verified bank account.
+	// found is false when the merchant has none.
+	GetPayoutAccount(context.Context, *GetPayoutAccountRequest) (*GetPayoutAccountResponse, error)
+	// GetPayoutEligibility returns the merchant's account and KYC status, for
+	// payouts that need more than a bank account, such as instant payouts
+	GetPayoutEligibility(context.Context, *GetPayoutEligibilityRequest) (*GetPayoutEligibilityResponse, error)
+	mustEmbedUnimplementedPayoutAccountServiceServer()
+}
+
+// UnimplementedPayoutAccountServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPayoutAccountServiceServer struct{}
+
+func (UnimplementedPayoutAccountServiceServer) GetPayoutAccount(context.Context, *GetPayoutAccountRequest) (*GetPayoutAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayoutAccount not implemented")
+}
+func (UnimplementedPayoutAccountServiceServer) GetPayoutEligibility(context.Context, *GetPayoutEligibilityRequest) (*GetPayoutEligibilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayoutEligibility not implemented")
+}
+func (UnimplementedPayoutAccountServiceServer) mustEmbedUnimplementedPayoutAccountServiceServer() {}
+func (UnimplementedPayoutAccountServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafePayoutAccountServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PayoutAccountServiceServer will
+// result in compilation errors.
+type UnsafePayoutAccountServiceServer interface {
+	mustEmbedUnimplementedPayoutAccountServiceServer()
+}
+
+func RegisterPayoutAccountServiceServer(s grpc.ServiceRegistrar, srv PayoutAccountServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPayoutAccountServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PayoutAccountService_ServiceDesc, srv)
+}
+
+func _PayoutAccountService_GetPayoutAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPayoutAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutAccountServiceServer).GetPayoutAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutAccountService_GetPayoutAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutAccountServiceServer).GetPayoutAccount(ctx, req.(*GetPayoutAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayoutAccountService_GetPayoutEligibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPayoutEligibilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayoutAccountServiceServer).GetPayoutEligibility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayoutAccountService_GetPayoutEligibility_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayoutAccountServiceServer).GetPayoutEligibility(ctx, req.(*GetPayoutEligibilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PayoutAccountService_ServiceDesc is the grpc.ServiceDesc for PayoutAccountService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PayoutAccountService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "merchant.PayoutAccountService",
+	HandlerType: (*PayoutAccountServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPayoutAccount",
+			Handler:    _PayoutAccountService_GetPayoutAccount_Handler,
+		},
+		{
+			MethodName: "GetPayoutEligibility",
+			Handler:    _PayoutAccountService_GetPayoutEligibility_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/payout_account.proto",
+}
@@ -0,0 +1,368 @@
+// Command loadgen drives authorize+capture traffic against a running stack
+// and reports latency percentiles against an error/latency budget.
+//
+//	go run ./cmd/loadgen -api-key pk_test_... -rps 50 -duration 1m
+//
+// Tokenization happens inside the authorize call, so each iteration
+// exercises tokenize, fraud check, authorize and (optionally) capture.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type options struct {
+	baseURL      string
+	apiKey       string
+	rps          int
+	duration     time.Duration
+	concurrency  int
+	amount       int64
+	currency     string
+	card         string
+	capture      bool
+	timeout      time.Duration
+	maxErrorRate float64
+	p99Budget    time.Duration
+}
+
+func main() {
+	opts := parseFlags()
+	if opts.apiKey == "" {
+		fmt.Fprintln(os.Stderr, "loadgen: -api-key or LOADGEN_API_KEY is required")
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report := run(ctx, opts)
+	report.print(os.Stdout, opts)
+
+	if !report.withinBudget(opts) {
+		os.Exit(1)
+	}
+}
+
+func parseFlags() options {
+	var o options
+	flag.StringVar(&o.baseURL, "url", "http://localhost:8080", "gateway (or payment-api) base URL")
+	flag.StringVar(&o.apiKey, "api-key", os.Getenv("LOADGEN_API_KEY"), "merchant API key")
+	flag.IntVar(&o.rps, "rps", 20, "target iterations per second")
+	flag.DurationVar(&o.duration, "duration", 30*time.Second, "test duration")
+	flag.IntVar(&o.concurrency, "concurrency", 100, "maximum iterations in flight")
+	flag.Int64Var(&o.amount, "amount", 1999, "amount in cents")
+	flag.StringVar(&o.currency, "currency", "USD", "currency")
+	flag.StringVar(&o.card, "card", "4242424242424242", "test card number")
+	flag.BoolVar(&o.capture, "capture", true, "capture each successful authorization")
+	flag.DurationVar(&o.timeout, "timeout", 10*time.Second, "per-request timeout")
+	flag.Float64Var(&o.maxErrorRate, "max-error-rate", 0.01, "error budget (share of failed iterations)")
+	flag.DurationVar(&o.p99Budget, "p99-budget", 0, "fail if end-to-end p99 exceeds this (0 disables)")
+	flag.Parse()
+
+	o.baseURL = strings.TrimRight(o.baseURL, "/")
+	return o
+}
+
+// =========================================================================
+// Load loop
+// =========================================================================
+
+type sample struct {
+	step     string
+	latency  time.Duration
+	status   int
+	failed   bool
+	errorMsg string
+}
+
+func run(ctx context.Context, o options) *report {
+	httpClient := &http.Client{
+		Timeout: o.timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        o.concurrency,
+			MaxIdleConnsPerHost: o.concurrency,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.duration)
+	defer cancel()
+
+	samples := make(chan sample, o.concurrency*4)
+	rep := newReport()
+	collected := make(chan struct{})
+	go func() {
+		for s := range samples {
+			rep.add(s)
+		}
+		close(collected)
+	}()
+
+	// Open-loop pacing: iterations start on schedule even if earlier ones are
+	// slow, so back-pressure shows up as latency instead of lower throughput
+	ticker := time.NewTicker(time.Second / time.Duration(max(o.rps, 1)))
+	defer ticker.Stop()
+
+	inFlight := make(chan struct{}, o.concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				rep.dropped++
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+				iterate(httpClient, o, samples)
+			}()
+		}
+	}
+
+	wg.Wait()
+	close(samples)
+	<-collected
+	rep.elapsed = time.Since(start)
+	return rep
+}
+
+// iterate runs one authorize (+capture) cycle and emits a sample per step
+func iterate(httpClient *http.Client, o options, samples chan<- sample) {
+	started := time.Now()
+
+	authBody := map[string]interface{}{
+		"amount":   o.amount,
+		"currency": o.currency,
+		"card": map[string]interface{}{
+			"number":          o.card,
+			"cardholder_name": "Load Test",
+			"exp_month":       12,
+			"exp_year":        time.Now().Year() + 2,
+			"cvv":             "123",
+		},
+		"description": "loadgen",
+	}
+
+	var authResp struct {
+		Success bool `json:"success"`
+		Data    struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	s := call(httpClient, o, "authorize", "/api/v1/payments/authorize", authBody, &authResp)
+	if !s.failed && authResp.Data.Status != "authorized" {
+		s.failed = true
+		s.errorMsg = "not authorized: " + authResp.Data.Status
+	}
+	samples <- s
+
+	if s.failed || !o.capture {
+		samples <- sample{step: "total", latency: time.Since(started), failed: s.failed}
+		return
+	}
+
+	c := call(httpClient, o, "capture", "/api/v1/payments/"+authResp.Data.ID+"/capture",
+		map[string]interface{}{"amount": o.amount, "currency": o.currency}, nil)
+	samples <- c
+	samples <- sample{step: "total", latency: time.Since(started), failed: c.failed}
+}
+
+func call(httpClient *http.Client, o options, step, path string, body interface{}, out interface{}) sample {
+	payload, _ := json.Marshal(body)
+	req, err := http.NewRequest(http.MethodPost, o.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return sample{step: step, failed: true, errorMsg: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", o.apiKey)
+	req.Header.Set("Idempotency-Key", uuid.NewString())
+
+	started := time.Now()
+	resp, err := httpClient.Do(req)
+	latency := time.Since(started)
+	if err != nil {
+		return sample{step: step, latency: latency, failed: true, errorMsg: errorClass(err)}
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	s := sample{step: step, latency: latency, status: resp.StatusCode}
+	if resp.StatusCode >= 300 {
+		s.failed = true
+		s.errorMsg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		return s
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			s.failed = true
+			s.errorMsg = "invalid response body"
+		}
+	}
+	return s
+}
+
+func errorClass(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Client.Timeout"), strings.Contains(msg, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(msg, "connection refused"):
+		return "connection refused"
+	default:
+		return "transport error"
+	}
+}
+
+// =========================================================================
+// Reporting
+// =========================================================================
+
+type stepStats struct {
+	latencies []time.Duration
+	failures  int
+}
+
+type report struct {
+	steps   map[string]*stepStats
+	errors  map[string]int
+	dropped int
+	elapsed time.Duration
+}
+
+func newReport() *report {
+	return &report{
+		steps:  map[string]*stepStats{},
+		errors: map[string]int{},
+	}
+}
+
+func (r *report) add(s sample) {
+	st, ok := r.steps[s.step]
+	if !ok {
+		st = &stepStats{}
+		r.steps[s.step] = st
+	}
+	st.latencies = append(st.latencies, s.latency)
+	if s.failed {
+		st.failures++
+		if s.step != "total" {
+			r.errors[s.step+": "+s.errorMsg]++
+		}
+	}
+}
+
+func (r *report) errorRate() float64 {
+	total := r.steps["total"]
+	if total == nil || len(total.latencies) == 0 {
+		return 0
+	}
+	return float64(total.failures) / float64(len(total.latencies))
+}
+
+func (r *report) withinBudget(o options) bool {
+	total := r.steps["total"]
+	if total == nil || len(total.latencies) == 0 {
+		return false
+	}
+	if r.errorRate() > o.maxErrorRate {
+		return false
+	}
+	if o.p99Budget > 0 && percentile(total.latencies, 99) > o.p99Budget {
+		return false
+	}
+	return true
+}
+
+func (r *report) print(w io.Writer, o options) {
+	fmt.Fprintf(w, "\nTarget: %s  %d rps for %s (ran %s)\n\n", o.baseURL, o.rps, o.duration, r.elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "%-10s %8s %8s %10s %10s %10s %10s\n", "STEP", "COUNT", "FAILED", "P50", "P95", "P99", "MAX")
+
+	for _, step := range []string{"authorize", "capture", "total"} {
+		st := r.steps[step]
+		if st == nil || len(st.latencies) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%-10s %8d %8d %10s %10s %10s %10s\n",
+			step,
+			len(st.latencies),
+			st.failures,
+			percentile(st.latencies, 50).Round(time.Millisecond),
+			percentile(st.latencies, 95).Round(time.Millisecond),
+			percentile(st.latencies, 99).Round(time.Millisecond),
+			percentile(st.latencies, 100).Round(time.Millisecond),
+		)
+	}
+
+	if total := r.steps["total"]; total != nil && r.elapsed > 0 {
+		fmt.Fprintf(w, "\nThroughput: %.1f iterations/s\n", float64(len(total.latencies))/r.elapsed.Seconds())
+	}
+	if r.dropped > 0 {
+		fmt.Fprintf(w, "Dropped:    %d iterations (concurrency limit %d reached)\n", r.dropped, o.concurrency)
+	}
+
+	fmt.Fprintf(w, "Error rate: %.2f%% (budget %.2f%%)\n", r.errorRate()*100, o.maxErrorRate*100)
+	if o.p99Budget > 0 {
+		fmt.Fprintf(w, "P99 budget: %s\n", o.p99Budget)
+	}
+
+	if len(r.errors) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		keys := make([]string, 0, len(r.errors))
+		for k := range r.errors {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %-40s %d\n", k, r.errors[k])
+		}
+	}
+
+	if r.withinBudget(o) {
+		fmt.Fprintln(w, "\nPASS")
+	} else {
+		fmt.Fprintln(w, "\nFAIL: budget exceeded")
+	}
+}
+
+// percentile returns the p-th percentile (nearest-rank) of the latencies
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -5,23 +5,50 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // merchant timezones must resolve in minimal images
 
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/api"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/pii"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 )
 
+var (
+	exportService         *service.ExportService
+	refundApprovalService *service.RefundApprovalService
+	subscriptionService   *service.SubscriptionService
+	expiryService         *service.AuthorizationExpiryService
+)
+
 func init() {
 	if config.GetEnv("APP_MODE") == "" {
 		inits.InitDotEnv()
 	}
 	logger.Init()
 	inits.InitDB()
+	if err := tenancy.Register(inits.DB, tenancy.ModeFromEnv()); err != nil {
+		logger.Log.Fatal("Failed to register tenancy guard", zap.Error(err))
+	}
 	inits.InitRedis()
-	api.SetupRoutes(inits.R)
+	if _, err := pii.Init(); err != nil {
+		logger.Log.Fatal("Failed to connect PII key management", zap.Error(err))
+	}
+	exportService = service.NewExportService()
+
+	paymentService, err := service.NewPaymentService()
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize payment service", zap.Error(err))
+	}
+	refundApprovalService = service.NewRefundApprovalService(paymentService)
+	subscriptionService = service.NewSubscriptionService(paymentService)
+	expiryService = service.NewAuthorizationExpiryService(paymentService)
+
+	api.SetupRoutes(inits.R, exportService, refundApprovalService, subscriptionService)
 }
 
 func main() {
@@ -34,6 +61,7 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	go client.ListenForPermissionInvalidations(ctx)
 
 	go func() {
 		if err := webhookService.RetryFailedWebhooks(ctx); err != nil {
@@ -42,6 +70,48 @@ func main() {
 	}()
 	logger.Log.Info("Webhook retry worker started")
 
+	go func() {
+		if err := exportService.RunWorker(ctx); err != nil {
+			logger.Log.Error("Export worker failed", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := refundApprovalService.RunExpiryWorker(ctx); err != nil {
+			logger.Log.Error("Refund approval expiry worker failed", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := subscriptionService.RunBillingWorker(ctx); err != nil {
+			logger.Log.Error("Subscription billing worker failed", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := expiryService.RunWorker(ctx); err != nil {
+			logger.Log.Error("Authorization expiry worker failed", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		if err := service.NewIdempotencyService().RunPurgeWorker(ctx); err != nil {
+			logger.Log.Error("Idempotency key purge worker failed", zap.Error(err))
+		}
+	}()
+
+	outboxRelay, err := service.NewOutboxRelayService()
+	if err != nil {
+		logger.Log.Fatal("Failed to configure event streaming", zap.Error(err))
+	}
+	if outboxRelay != nil {
+		go func() {
+			if err := outboxRelay.RunOutboxRelayWorker(ctx); err != nil {
+				logger.Log.Error("Outbox relay worker failed", zap.Error(err))
+			}
+		}()
+	}
+
 	// Setup graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -64,7 +134,7 @@ func main() {
 	<-stop
 	logger.Log.Warn("🛑 Shutting down gracefully...")
 
-	// Stop webhook worker
+	// Stop webhook and export workers
 	cancel()
 
 	// Close Redis connection
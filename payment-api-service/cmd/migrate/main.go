@@ -8,11 +8,12 @@ import (
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/migrations"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/pii"
 )
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("usage: migrate [up|down]")
+		log.Fatal("usage: migrate [up|down|encrypt-pii|import-bins <file.csv>]")
 	}
 	if config.GetEnv("APP_MODE") == "" {
 		inits.InitDotEnv()
@@ -33,6 +34,26 @@ func main() {
 			log.Fatal(err)
 		}
 
+	case "encrypt-pii":
+		log.Println("🔐 encrypting customer PII written before per-merchant keys")
+		keyClient, err := pii.Init()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer keyClient.Close()
+		if err := migrations.EncryptPaymentPII(); err != nil {
+			log.Fatal(err)
+		}
+
+	case "import-bins":
+		if len(os.Args) < 3 {
+			log.Fatal("usage: migrate import-bins <file.csv>")
+		}
+		log.Println("🌍 importing BIN issuing countries")
+		if err := migrations.ImportBINCountries(os.Args[2]); err != nil {
+			log.Fatal(err)
+		}
+
 	default:
 		log.Fatalf("unknown command: %s", os.Args[1])
 	}
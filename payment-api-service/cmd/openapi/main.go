@@ -0,0 +1,1011 @@
+// Command openapi generates the service's OpenAPI 3 spec from its source.
+// Routes come from internal/api, summaries and descriptions from the doc
+// comment of each handler, request bodies from the struct the handler binds
+// and query parameters from the c.Query calls it makes.
+//
+//	go run ./cmd/openapi          # rewrite internal/openapi/openapi.json
+//	go run ./cmd/openapi -check   # fail if the committed spec is stale
+//
+// A handler's doc comment ends with its route, e.g. "// GET /api/v1/events".
+// -check also fails when that line names a different route than the router.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	title      = "Payment API"
+	modulePath = "github.com/rhaloubi/payment-gateway/payment-api-service"
+	routesDir  = "internal/api"
+	handlerDir = "internal/handler"
+	outputFile = "internal/openapi/openapi.json"
+)
+
+// apis are the route prefixes published in the spec and how each is
+// authenticated. Internal and health routes are left out.
+var apis = []struct {
+	prefix   string
+	security string
+}{
+	{"/api/v1", "ApiKeyAuth"},
+	{"/api/public", ""},
+}
+
+var securitySchemes = map[string]interface{}{
+	"ApiKeyAuth": map[string]interface{}{
+		"type": "apiKey",
+		"in":   "header",
+		"name": "X-API-Key",
+	},
+}
+
+func main() {
+	check := flag.Bool("check", false, "verify the committed spec instead of writing it")
+	flag.Parse()
+
+	spec, problems, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "openapi:", err)
+		os.Exit(1)
+	}
+
+	if !*check {
+		if err := os.WriteFile(outputFile, spec, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "openapi:", err)
+			os.Exit(1)
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "warning:", p)
+		}
+		fmt.Println("wrote", outputFile)
+		return
+	}
+
+	committed, err := os.ReadFile(outputFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "openapi:", err)
+		os.Exit(1)
+	}
+	if !bytes.Equal(committed, spec) {
+		problems = append(problems, outputFile+" is out of date, run go run ./cmd/openapi")
+	}
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+// route is one registration in the router
+type route struct {
+	method      string
+	path        string
+	handlerType string
+	handlerFunc string
+}
+
+func generate() ([]byte, []string, error) {
+	routes, err := parseRoutes(routesDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkgs, files, err := loadPackages("internal")
+	if err != nil {
+		return nil, nil, err
+	}
+	handlerPkg := pkgs[modulePath+"/"+handlerDir]
+	if handlerPkg == nil {
+		return nil, nil, fmt.Errorf("no handler package in %s", handlerDir)
+	}
+
+	g := &generator{pkgs: pkgs, files: files, schemas: map[string]interface{}{}}
+	paths := map[string]map[string]interface{}{}
+	operationIDs := map[string]bool{}
+	var problems []string
+
+	for _, r := range routes {
+		security, ok := published(r.path)
+		if !ok {
+			continue
+		}
+		fn := handlerPkg.methods[r.handlerType+"."+r.handlerFunc]
+		if fn == nil {
+			problems = append(problems, fmt.Sprintf("%s %s: handler %s.%s not found", r.method, r.path, r.handlerType, r.handlerFunc))
+			continue
+		}
+		op, problem := g.operation(r, fn.decl, fn.file, security)
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+		// A handler mounted on several routes needs an id per route
+		if id := op["operationId"].(string); operationIDs[id] {
+			op["operationId"] = lowerFirst(camel(tag(r.path))) + fn.decl.Name.Name
+		}
+		operationIDs[op["operationId"].(string)] = true
+
+		path := openAPIPath(r.path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(r.method)] = op
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         g.schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+	out, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(out, '\n'), problems, nil
+}
+
+func published(path string) (string, bool) {
+	for _, api := range apis {
+		if path == api.prefix || strings.HasPrefix(path, api.prefix+"/") {
+			return api.security, true
+		}
+	}
+	return "", false
+}
+
+var pathParam = regexp.MustCompile(`[:*]([A-Za-z_]+)`)
+
+// openAPIPath turns /payments/:id into /payments/{id}
+func openAPIPath(path string) string {
+	return pathParam.ReplaceAllString(path, "{$1}")
+}
+
+// =========================================================================
+// Routes
+// =========================================================================
+
+var routeMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// parseRoutes follows Group calls and handler constructors in source order,
+// which is how the router files are written
+func parseRoutes(dir string) ([]route, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var routes []route
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes := map[string]string{}
+		handlers := map[string]string{}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				call, ok := n.Rhs[0].(*ast.CallExpr)
+				if !ok || len(n.Rhs) != 1 {
+					return true
+				}
+				lhs, ok := n.Lhs[0].(*ast.Ident)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				recv, _ := sel.X.(*ast.Ident)
+				switch {
+				case sel.Sel.Name == "Group" && recv != nil && len(call.Args) > 0:
+					prefixes[lhs.Name] = prefixes[recv.Name] + stringLit(call.Args[0])
+				case recv != nil && recv.Name == "handler" && strings.HasPrefix(sel.Sel.Name, "New"):
+					handlers[lhs.Name] = strings.TrimPrefix(sel.Sel.Name, "New")
+				}
+			case *ast.CallExpr:
+				sel, ok := n.Fun.(*ast.SelectorExpr)
+				if !ok || !routeMethods[sel.Sel.Name] || len(n.Args) < 2 {
+					return true
+				}
+				recv, ok := sel.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				h, ok := n.Args[len(n.Args)-1].(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				hv, ok := h.X.(*ast.Ident)
+				if !ok || handlers[hv.Name] == "" {
+					return true
+				}
+				routes = append(routes, route{
+					method:      sel.Sel.Name,
+					path:        prefixes[recv.Name] + stringLit(n.Args[0]),
+					handlerType: handlers[hv.Name],
+					handlerFunc: h.Sel.Name,
+				})
+			}
+			return true
+		})
+	}
+	return routes, nil
+}
+
+func stringLit(e ast.Expr) string {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// =========================================================================
+// Packages
+// =========================================================================
+
+type funcDecl struct {
+	decl *ast.FuncDecl
+	file *ast.File
+}
+
+type typeDecl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+	pkg  *pkg
+}
+
+type pkg struct {
+	path    string
+	name    string
+	types   map[string]*typeDecl
+	methods map[string]*funcDecl // "Receiver.Method"
+	funcs   map[string]*funcDecl
+}
+
+// loadPackages parses every package under root, keyed by import path, and
+// notes which package each file is in
+func loadPackages(root string) (map[string]*pkg, map[*ast.File]*pkg, error) {
+	fset := token.NewFileSet()
+	pkgs := map[string]*pkg{}
+	files := map[*ast.File]*pkg{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		importPath := modulePath + "/" + filepath.ToSlash(filepath.Dir(path))
+		p := pkgs[importPath]
+		if p == nil {
+			p = &pkg{path: importPath, name: file.Name.Name, types: map[string]*typeDecl{}, methods: map[string]*funcDecl{}, funcs: map[string]*funcDecl{}}
+			pkgs[importPath] = p
+		}
+		files[file] = p
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						p.types[ts.Name.Name] = &typeDecl{spec: ts, file: file, pkg: p}
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 {
+					p.funcs[d.Name.Name] = &funcDecl{decl: d, file: file}
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					p.methods[id.Name+"."+d.Name.Name] = &funcDecl{decl: d, file: file}
+				}
+			}
+		}
+		return nil
+	})
+	return pkgs, files, err
+}
+
+// imports maps the names a file uses for the module's own packages to
+// their import paths
+func (g *generator) imports(file *ast.File) map[string]string {
+	out := map[string]string{}
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		p := g.pkgs[path]
+		if p == nil {
+			continue
+		}
+		name := p.name
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		out[name] = path
+	}
+	return out
+}
+
+// =========================================================================
+// Operations
+// =========================================================================
+
+type generator struct {
+	pkgs    map[string]*pkg
+	files   map[*ast.File]*pkg
+	schemas map[string]interface{}
+}
+
+var routeLine = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE)\s+(/\S*)`)
+
+func (g *generator) operation(r route, fn *ast.FuncDecl, file *ast.File, security string) (map[string]interface{}, string) {
+	op := map[string]interface{}{
+		"operationId": lowerFirst(fn.Name.Name),
+		"tags":        []string{tag(r.path)},
+	}
+
+	// The comment ends with the handler's routes, one per line
+	var problem string
+	var doc, annotations []string
+	if fn.Doc != nil {
+		doc = strings.Split(strings.TrimSpace(fn.Doc.Text()), "\n")
+		for len(doc) > 0 && routeLine.MatchString(doc[len(doc)-1]) {
+			annotations = append(annotations, doc[len(doc)-1])
+			doc = doc[:len(doc)-1]
+		}
+	}
+	if len(annotations) > 0 && !annotates(annotations, r) {
+		problem = fmt.Sprintf("%s %s: doc comment of %s.%s says %s", r.method, r.path, r.handlerType, r.handlerFunc, strings.Join(annotations, ", "))
+	}
+	if len(doc) > 0 {
+		// The comment starts with the function name, which means nothing to API users
+		summary := strings.TrimPrefix(doc[0], fn.Name.Name+" ")
+		op["summary"] = upperFirst(summary)
+		if len(doc) > 1 {
+			op["description"] = upperFirst(strings.TrimPrefix(strings.Join(doc, " "), fn.Name.Name+" "))
+		}
+	}
+
+	var params []interface{}
+	for _, m := range pathParam.FindAllStringSubmatch(r.path, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	body := g.inspectBody(fn, file)
+	for _, name := range body.query {
+		params = append(params, map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	if body.queryStruct != nil {
+		params = append(params, g.queryParams(body.queryStruct)...)
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if body.request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				body.contentType: map[string]interface{}{"schema": g.schemaRef(body.request)},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{}
+	statuses := body.statuses
+	if len(statuses) == 0 {
+		statuses = []int{http.StatusOK}
+	}
+	for _, code := range statuses {
+		envelope := "SuccessResponse"
+		if code >= 400 {
+			envelope = "ErrorResponse"
+		}
+		g.envelopes()
+		responses[strconv.Itoa(code)] = map[string]interface{}{
+			"description": http.StatusText(code),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/" + envelope},
+				},
+			},
+		}
+	}
+	op["responses"] = responses
+
+	if security != "" {
+		op["security"] = []interface{}{map[string]interface{}{security: []string{}}}
+	} else {
+		op["security"] = []interface{}{}
+	}
+	return op, problem
+}
+
+// annotates reports whether one of the route lines of a doc comment names
+// r. Query strings are ignored, and the path may leave out the prefix.
+func annotates(annotations []string, r route) bool {
+	path := strings.TrimSuffix(r.path, "/")
+	for _, line := range annotations {
+		m := routeLine.FindStringSubmatch(line)
+		annotated := strings.TrimSuffix(strings.SplitN(m[2], "?", 2)[0], "/")
+		if m[1] == r.method && strings.HasSuffix(path, annotated) {
+			return true
+		}
+	}
+	return false
+}
+
+func tag(path string) string {
+	for _, api := range apis {
+		if strings.HasPrefix(path, api.prefix+"/") {
+			path = strings.TrimPrefix(path, api.prefix+"/")
+			break
+		}
+	}
+	return strings.SplitN(path, "/", 2)[0]
+}
+
+func (g *generator) envelopes() {
+	g.schemas["SuccessResponse"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"success": map[string]interface{}{"type": "boolean"},
+			"data":    map[string]interface{}{},
+		},
+	}
+	g.schemas["ErrorResponse"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"success": map[string]interface{}{"type": "boolean"},
+			"error":   map[string]interface{}{"type": "string"},
+			"code":    map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// handlerBody is what a handler's code says about its request and responses
+type handlerBody struct {
+	request     *resolved
+	contentType string
+	queryStruct *resolved
+	query       []string
+	statuses    []int
+}
+
+var bindJSON = map[string]bool{"ShouldBindJSON": true, "BindJSON": true, "ShouldBind": true, "Bind": true}
+
+func (g *generator) inspectBody(fn *ast.FuncDecl, file *ast.File) handlerBody {
+	body := handlerBody{contentType: "application/json"}
+	g.walk(fn, file, &body, nil, map[string]bool{}, map[string]bool{}, map[int]bool{})
+	sort.Ints(body.statuses)
+
+	hasSuccess := false
+	for _, code := range body.statuses {
+		hasSuccess = hasSuccess || code < 400
+	}
+	if !hasSuccess {
+		// Written by a shared helper, e.g. an idempotent replay
+		body.statuses = append([]int{http.StatusOK}, body.statuses...)
+	}
+	return body
+}
+
+// walk reads a handler and the helpers in its package it calls. literals
+// maps a helper's parameters to the string literals it was called with, so
+// parseTimeQuery(c, "date_from") documents date_from.
+func (g *generator) walk(fn *ast.FuncDecl, file *ast.File, body *handlerBody, literals map[string]string, visited map[string]bool, seenQuery map[string]bool, seenStatus map[int]bool) {
+	key := fmt.Sprint(fn.Pos(), literals)
+	if fn.Body == nil || visited[key] {
+		return
+	}
+	visited[key] = true
+	vars := map[string]ast.Expr{}
+	p := g.files[file]
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				if n.Type != nil {
+					vars[name.Name] = n.Type
+				}
+			}
+		case *ast.CallExpr:
+			name := callName(n)
+			switch {
+			case bindJSON[name] || strings.HasPrefix(name, "bind"):
+				if t := addressedVar(n, vars); t != nil {
+					body.request = g.resolve(t, file)
+				}
+			case name == "ShouldBindQuery" || name == "BindQuery":
+				if t := addressedVar(n, vars); t != nil {
+					body.queryStruct = g.resolve(t, file)
+				}
+			case name == "FormFile":
+				body.contentType = "multipart/form-data"
+				body.request = &resolved{multipart: stringLit(n.Args[0])}
+			case name == "Query" || name == "DefaultQuery" || name == "GetQuery" || name == "QueryArray":
+				if len(n.Args) > 0 {
+					q := stringLit(n.Args[0])
+					if id, ok := n.Args[0].(*ast.Ident); ok {
+						q = literals[id.Name]
+					}
+					if q != "" && !seenQuery[q] {
+						seenQuery[q] = true
+						body.query = append(body.query, q)
+					}
+				}
+			case name == "JSON" || name == "Data" || name == "Status" || name == "AbortWithStatusJSON" || name == "AbortWithStatus" || name == "Redirect":
+				if len(n.Args) > 0 {
+					if code := statusCode(n.Args[0]); code != 0 && !seenStatus[code] {
+						seenStatus[code] = true
+						body.statuses = append(body.statuses, code)
+					}
+				}
+			default:
+				if helper := g.helper(n, fn, p); helper != nil {
+					g.walk(helper.decl, helper.file, body, boundLiterals(n, helper.decl), visited, seenQuery, seenStatus)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// helper returns the package function or method on the same receiver that
+// call invokes, if it is handed the gin context
+func (g *generator) helper(call *ast.CallExpr, caller *ast.FuncDecl, p *pkg) *funcDecl {
+	if p == nil || !passesContext(call) {
+		return nil
+	}
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return p.funcs[fun.Name]
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		if !ok || caller.Recv == nil || len(caller.Recv.List) == 0 || len(caller.Recv.List[0].Names) == 0 {
+			return nil
+		}
+		if x.Name != caller.Recv.List[0].Names[0].Name {
+			return nil
+		}
+		recv := caller.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if id, ok := recv.(*ast.Ident); ok {
+			return p.methods[id.Name+"."+fun.Sel.Name]
+		}
+	}
+	return nil
+}
+
+// boundLiterals maps fn's parameters to the string literals call passes them
+func boundLiterals(call *ast.CallExpr, fn *ast.FuncDecl) map[string]string {
+	var literals map[string]string
+	i := 0
+	for _, field := range fn.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			i++
+			continue
+		}
+		for _, name := range names {
+			if i < len(call.Args) {
+				if lit := stringLit(call.Args[i]); lit != "" {
+					if literals == nil {
+						literals = map[string]string{}
+					}
+					literals[name.Name] = lit
+				}
+			}
+			i++
+		}
+	}
+	return literals
+}
+
+func passesContext(call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if id, ok := arg.(*ast.Ident); ok && id.Name == "c" {
+			return true
+		}
+	}
+	return false
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	case *ast.Ident:
+		return fn.Name
+	}
+	return ""
+}
+
+// addressedVar returns the declared type of the variable passed as &v
+func addressedVar(call *ast.CallExpr, vars map[string]ast.Expr) ast.Expr {
+	for _, arg := range call.Args {
+		u, ok := arg.(*ast.UnaryExpr)
+		if !ok || u.Op != token.AND {
+			continue
+		}
+		if id, ok := u.X.(*ast.Ident); ok && vars[id.Name] != nil {
+			return vars[id.Name]
+		}
+	}
+	return nil
+}
+
+var statusCodes = map[string]int{}
+
+func init() {
+	for code := 100; code < 600; code++ {
+		if text := http.StatusText(code); text != "" {
+			statusCodes["Status"+strings.NewReplacer(" ", "", "-", "", "'", "").Replace(text)] = code
+		}
+	}
+	// StatusText and the constant names differ for these
+	statusCodes["StatusRequestEntityTooLarge"] = http.StatusRequestEntityTooLarge
+	statusCodes["StatusRequestURITooLong"] = http.StatusRequestURITooLong
+	statusCodes["StatusTeapot"] = http.StatusTeapot
+}
+
+func statusCode(e ast.Expr) int {
+	switch e := e.(type) {
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok && x.Name == "http" {
+			return statusCodes[e.Sel.Name]
+		}
+	case *ast.BasicLit:
+		code, _ := strconv.Atoi(e.Value)
+		return code
+	}
+	return 0
+}
+
+// =========================================================================
+// Schemas
+// =========================================================================
+
+// resolved is a type expression with the file it appears in, so package
+// qualifiers can be looked up
+type resolved struct {
+	expr      ast.Expr
+	file      *ast.File
+	multipart string // Form field of an uploaded file
+}
+
+func (g *generator) resolve(expr ast.Expr, file *ast.File) *resolved {
+	return &resolved{expr: expr, file: file}
+}
+
+func (g *generator) schemaRef(r *resolved) interface{} {
+	if r.multipart != "" {
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				r.multipart: map[string]interface{}{"type": "string", "format": "binary"},
+			},
+			"required": []string{r.multipart},
+		}
+	}
+	return g.schema(r.expr, r.file, 0)
+}
+
+// lookup finds the declaration a type name refers to
+func (g *generator) lookup(expr ast.Expr, file *ast.File) *typeDecl {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if p := g.files[file]; p != nil {
+			return p.types[e.Name]
+		}
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		if p := g.pkgs[g.imports(file)[x.Name]]; p != nil {
+			return p.types[e.Sel.Name]
+		}
+	}
+	return nil
+}
+
+var wellKnown = map[string]map[string]interface{}{
+	"time.Time":            {"type": "string", "format": "date-time"},
+	"time.Duration":        {"type": "integer"},
+	"uuid.UUID":            {"type": "string", "format": "uuid"},
+	"sql.NullString":       {"type": "string", "nullable": true},
+	"sql.NullTime":         {"type": "string", "format": "date-time", "nullable": true},
+	"sql.NullInt64":        {"type": "integer", "nullable": true},
+	"sql.NullBool":         {"type": "boolean", "nullable": true},
+	"json.RawMessage":      {},
+	"datatypes.JSON":       {},
+	"pq.StringArray":       {"type": "array", "items": map[string]interface{}{"type": "string"}},
+	"decimal.Decimal":      {"type": "string"},
+	"multipart.FileHeader": {"type": "string", "format": "binary"},
+}
+
+var basicTypes = map[string]map[string]interface{}{
+	"string":      {"type": "string"},
+	"bool":        {"type": "boolean"},
+	"int":         {"type": "integer"},
+	"int8":        {"type": "integer"},
+	"int16":       {"type": "integer"},
+	"int32":       {"type": "integer", "format": "int32"},
+	"int64":       {"type": "integer", "format": "int64"},
+	"uint":        {"type": "integer"},
+	"uint8":       {"type": "integer"},
+	"uint16":      {"type": "integer"},
+	"uint32":      {"type": "integer"},
+	"uint64":      {"type": "integer"},
+	"float32":     {"type": "number"},
+	"float64":     {"type": "number"},
+	"byte":        {"type": "integer"},
+	"rune":        {"type": "integer"},
+	"interface{}": {},
+	"any":         {},
+}
+
+func (g *generator) schema(expr ast.Expr, file *ast.File, depth int) interface{} {
+	if depth > 8 {
+		return map[string]interface{}{}
+	}
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return g.schema(e.X, file, depth)
+	case *ast.ArrayType:
+		if id, ok := e.Elt.(*ast.Ident); ok && id.Name == "byte" {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(e.Elt, file, depth+1)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(e.Value, file, depth+1)}
+	case *ast.InterfaceType:
+		return map[string]interface{}{}
+	case *ast.StructType:
+		return g.structSchema(e, file, depth)
+	case *ast.Ident:
+		if s, ok := basicTypes[e.Name]; ok {
+			return copySchema(s)
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if s, ok := wellKnown[x.Name+"."+e.Sel.Name]; ok {
+				return copySchema(s)
+			}
+		}
+	}
+
+	td := g.lookup(expr, file)
+	if td == nil {
+		return map[string]interface{}{}
+	}
+	if _, isStruct := td.spec.Type.(*ast.StructType); !isStruct {
+		// Named basic types, e.g. type PaymentStatus string
+		return g.schema(td.spec.Type, td.file, depth+1)
+	}
+
+	name := td.spec.Name.Name
+	if existing, ok := g.schemas[name]; !ok || existing == nil {
+		g.schemas[name] = nil // Placeholder for recursive types
+		g.schemas[name] = g.schema(td.spec.Type, td.file, depth+1)
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *generator) structSchema(st *ast.StructType, file *ast.File, depth int) interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, field := range st.Fields.List {
+		tags := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tags = reflect.StructTag(raw)
+		}
+		jsonTag := tags.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		jsonName := strings.Split(jsonTag, ",")[0]
+
+		if len(field.Names) == 0 {
+			// Embedded struct: its fields are promoted
+			if embedded, ok := g.schema(field.Type, file, depth+1).(map[string]interface{}); ok {
+				g.mergeEmbedded(properties, &required, embedded)
+			}
+			continue
+		}
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name := jsonName
+			if name == "" {
+				name = ident.Name
+			}
+			prop := g.schema(field.Type, file, depth+1)
+			if m, ok := prop.(map[string]interface{}); ok {
+				applyBinding(m, tags.Get("binding"))
+				if _, isRef := m["$ref"]; !isRef && field.Comment != nil {
+					m["description"] = strings.TrimSpace(field.Comment.Text())
+				}
+			}
+			properties[name] = prop
+			if strings.Contains(tags.Get("binding"), "required") && !strings.Contains(tags.Get("binding"), "required_") {
+				required = append(required, name)
+			}
+		}
+	}
+	out := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out
+}
+
+func (g *generator) mergeEmbedded(properties map[string]interface{}, required *[]string, embedded map[string]interface{}) {
+	if ref, ok := embedded["$ref"].(string); ok {
+		embedded, _ = g.schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{})
+	}
+	props, _ := embedded["properties"].(map[string]interface{})
+	for k, v := range props {
+		properties[k] = v
+	}
+	if req, ok := embedded["required"].([]string); ok {
+		*required = append(*required, req...)
+	}
+}
+
+// applyBinding carries gin's validation rules into the schema
+func applyBinding(s map[string]interface{}, binding string) {
+	if _, isRef := s["$ref"]; isRef {
+		return
+	}
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "oneof":
+			s["enum"] = strings.Fields(value)
+		case "min", "max", "gte", "lte":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			limit := map[string]string{"min": "minimum", "gte": "minimum", "max": "maximum", "lte": "maximum"}[key]
+			switch s["type"] {
+			case "string":
+				limit = strings.Replace(limit, "imum", "Length", 1)
+			case "array":
+				limit = strings.Replace(limit, "imum", "Items", 1)
+			}
+			s[limit] = n
+		case "email":
+			s["format"] = "email"
+		case "url":
+			s["format"] = "uri"
+		case "uuid":
+			s["format"] = "uuid"
+		}
+	}
+}
+
+func (g *generator) queryParams(r *resolved) []interface{} {
+	td := g.lookup(r.expr, r.file)
+	if td == nil {
+		return nil
+	}
+	st, ok := td.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	var params []interface{}
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		raw, _ := strconv.Unquote(field.Tag.Value)
+		tags := reflect.StructTag(raw)
+		name := strings.Split(tags.Get("form"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		schema, _ := g.schema(field.Type, td.file, 1).(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{}
+		}
+		applyBinding(schema, tags.Get("binding"))
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "query",
+			"required": strings.Contains(tags.Get("binding"), "required"),
+			"schema":   schema,
+		})
+	}
+	return params
+}
+
+func copySchema(s map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		out[k] = v
+	}
+	return out
+}
+
+// camel turns refund-approvals into RefundApprovals
+func camel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }) {
+		b.WriteString(upperFirst(part))
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
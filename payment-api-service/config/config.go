@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 )
 
 func GetEnv(key string) string {
@@ -26,3 +27,37 @@ func GetEnvWithDefault(key, defaultValue string) string {
 	}
 	return value
 }
+
+// GetDurationWithDefault reads a duration such as "24h" or "90m". Unset,
+// invalid or non-positive values fall back to defaultValue.
+func GetDurationWithDefault(key string, defaultValue time.Duration) time.Duration {
+	value := GetEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// DefaultRegion is the region of a single-region deployment
+const DefaultRegion = "primary"
+
+// Region is the home region this replica serves, from REGION. Resources
+// created here are tagged with it.
+func Region() string {
+	return strings.ToLower(GetEnvWithDefault("REGION", DefaultRegion))
+}
+
+// GetRegionalEnv reads a per-region override of key before key itself, so
+// DATABASE_DSN_EU_WEST_1 wins over DATABASE_DSN when REGION is eu-west-1
+func GetRegionalEnv(key string) string {
+	suffix := strings.ToUpper(strings.ReplaceAll(Region(), "-", "_"))
+	if value := GetEnv(key + "_" + suffix); value != "" {
+		return value
+	}
+	return GetEnv(key)
+}
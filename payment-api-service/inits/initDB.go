@@ -12,7 +12,7 @@ var DB *gorm.DB
 
 func InitDB() {
 	var err error
-	dsn := config.GetEnv("DATABASE_DSN")
+	dsn := config.GetRegionalEnv("DATABASE_DSN")
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect database")
@@ -1,6 +1,8 @@
 package inits
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 )
@@ -17,4 +19,30 @@ func init() {
 
 	// Create Gin router
 	R = gin.New()
+
+	// Only the gateway hop may set X-Forwarded-For; otherwise ClientIP is the
+	// TCP peer, so callers cannot claim an allowlisted address through headers
+	if err := R.SetTrustedProxies(trustedProxies()); err != nil {
+		panic("invalid TRUSTED_PROXIES: " + err.Error())
+	}
+}
+
+// defaultTrustedProxies covers loopback and the private ranges the API
+// gateway runs in
+const defaultTrustedProxies = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+
+// trustedProxies reads TRUSTED_PROXIES, a comma-separated list of CIDRs or
+// addresses whose X-Forwarded-For is believed. "none" trusts no proxy.
+func trustedProxies() []string {
+	value := config.GetEnvWithDefault("TRUSTED_PROXIES", defaultTrustedProxies)
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return nil
+	}
+	var proxies []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			proxies = append(proxies, entry)
+		}
+	}
+	return proxies
 }
@@ -1,19 +1,26 @@
 package api
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/handler"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/middleware"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/openapi"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 )
 
-func SetupRoutes(router *gin.Engine) {
+func SetupRoutes(router *gin.Engine, exportService *service.ExportService, refundApprovalService *service.RefundApprovalService, subscriptionService *service.SubscriptionService) {
 
 	healthHandler := handler.NewHealthHandler()
 
-	paymentHandler, err := handler.NewPaymentHandler()
+	paymentHandler, err := handler.NewPaymentHandler(refundApprovalService)
 	if err != nil {
 		logger.Log.Fatal("Failed to initialize payment handler", zap.Error(err))
 	}
@@ -21,25 +28,83 @@ func SetupRoutes(router *gin.Engine) {
 	// NEW: Initialize payment intent handler
 	paymentService, _ := service.NewPaymentService()
 	paymentIntentHandler := handler.NewPaymentIntentHandler(paymentService)
+	checkoutSessionHandler := handler.NewCheckoutSessionHandler(paymentService)
+	invoiceHandler := handler.NewInvoiceHandler(paymentService)
+
+	checkoutSettingsHandler := handler.NewCheckoutSettingsHandler()
+	webhookSubscriptionHandler := handler.NewWebhookSubscriptionHandler()
+	webhookDeliveryHandler := handler.NewWebhookDeliveryHandler()
+	eventHandler := handler.NewEventHandler()
+	fraudRuleHandler := handler.NewFraudRuleHandler()
+	holdReleaseHandler := handler.NewHoldReleaseHandler()
+	receiptTemplateHandler := handler.NewReceiptTemplateHandler()
+	displaySettingsHandler := handler.NewDisplaySettingsHandler()
+	cardTestingHandler := handler.NewCardTestingHandler()
+	exportHandler := handler.NewExportHandler(exportService)
+	accountingHandler := handler.NewAccountingHandler()
+	settlementHandler := handler.NewSettlementHandler()
+	refundApprovalHandler := handler.NewRefundApprovalHandler(refundApprovalService)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	disputeHandler := handler.NewDisputeHandler()
+	reportHandler := handler.NewReportHandler()
 
 	transactionHandler, err := handler.NewTransactionHandler()
 	if err != nil {
 		logger.Log.Fatal("Failed to initialize transaction handler", zap.Error(err))
 	}
 
+	sandboxHandler, err := handler.NewSandboxHandler()
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize sandbox handler", zap.Error(err))
+	}
+
+	// Capture, void and refund act with the permissions of the API key's owner
+	authClient := client.NewAuthServiceClient()
+	canCapture := middleware.RequirePermission(authClient, "transactions:create")
+	canVoid := middleware.RequirePermission(authClient, "transactions:void")
+	canRefund := middleware.RequirePermission(authClient, "transactions:refund")
+	canUpdateSettings := middleware.RequirePermission(authClient, "settings:update")
+	canReadTransactions := middleware.RequirePermission(authClient, "transactions:read")
+	canReadInvoices := middleware.RequirePermission(authClient, "invoices:read")
+	canCreateInvoices := middleware.RequirePermission(authClient, "invoices:create")
+	canUpdateInvoices := middleware.RequirePermission(authClient, "invoices:update")
+
+	// Internal endpoints only accept peers inside INTERNAL_ALLOWED_CIDRS
+	internalCIDRs, err := middleware.ParseCIDRs(config.GetEnvWithDefault("INTERNAL_ALLOWED_CIDRS", middleware.DefaultInternalCIDRs))
+	if err != nil {
+		logger.Log.Fatal("Invalid INTERNAL_ALLOWED_CIDRS", zap.Error(err))
+	}
+	internalNetwork := middleware.RequireAllowedNetwork("internal", internalCIDRs)
+
+	requestTimeout, err := time.ParseDuration(config.GetEnvWithDefault("REQUEST_TIMEOUT", middleware.DefaultRequestTimeout.String()))
+	if err != nil {
+		logger.Log.Fatal("Invalid REQUEST_TIMEOUT", zap.Error(err))
+	}
+	routeTimeouts, err := middleware.ParseRouteTimeouts(config.GetEnv("REQUEST_TIMEOUT_OVERRIDES"))
+	if err != nil {
+		logger.Log.Fatal("Invalid REQUEST_TIMEOUT_OVERRIDES", zap.Error(err))
+	}
+
 	router.GET("/health", healthHandler.HealthCheck)
+	router.GET("/metrics", internalNetwork, handler.Metrics())
 
 	router.Use(middleware.ErrorHandlerMiddleware())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.RequestLoggerMiddleware())
+	router.Use(middleware.TimeoutMiddleware(requestTimeout, routeTimeouts))
 
 	router.GET("/ready", healthHandler.ReadinessCheck)
 
+	// The spec is public so SDK and CLI generators can fetch it without a key
+	router.GET("/api/v1/openapi.json", openapi.Serve)
+
 	// =========================================================================
 	// EXISTING API (v1) - Requires API Key
 	// =========================================================================
 	v1 := router.Group("/api/v1")
 	v1.Use(middleware.AuthMiddleware())
+	v1.Use(merchantctx.Require())
+	v1.Use(tenancy.Middleware())
 	v1.Use(middleware.RateLimitMiddleware())
 	v1.Use(middleware.IdempotencyMiddleware())
 	v1.Use(middleware.SanitizedBodyLoggerMiddleware())
@@ -49,26 +114,218 @@ func SetupRoutes(router *gin.Engine) {
 		{
 			payments.POST("/authorize", paymentHandler.AuthorizePayment)
 			payments.POST("/sale", paymentHandler.SalePayment)
+			payments.POST("/:id/authenticate", paymentHandler.AuthenticatePayment)
 
-			payments.POST("/:id/capture", paymentHandler.CapturePayment)
-			payments.POST("/:id/void", paymentHandler.VoidPayment)
-			payments.POST("/:id/refund", paymentHandler.RefundPayment)
+			payments.POST("/:id/capture", canCapture, paymentHandler.CapturePayment)
+			payments.POST("/:id/void", canVoid, paymentHandler.VoidPayment)
+			payments.POST("/:id/refund", canRefund, paymentHandler.RefundPayment)
 
+			payments.GET("", paymentHandler.ListPayments)
 			payments.GET("/:id", paymentHandler.GetPayment)
+			payments.GET("/:id/full", paymentHandler.GetPaymentDetail)
+			payments.GET("/:id/refunds", paymentHandler.ListPaymentRefunds)
+			payments.GET("/:id/receipt", paymentHandler.GetReceipt)
+			payments.POST("/:id/timeline-export", exportHandler.ExportTransactionTimeline)
+
+			payments.GET("/:id/notes", transactionHandler.ListNotes)
+			payments.POST("/:id/notes", transactionHandler.AddNote)
+			payments.DELETE("/:id/notes/:note_id", transactionHandler.DeleteNote)
+			payments.POST("/:id/tags", transactionHandler.AddTags)
+			payments.DELETE("/:id/tags/:tag", transactionHandler.RemoveTag)
+		}
+
+		refunds := v1.Group("/refunds")
+		{
+			refunds.GET("", paymentHandler.ListRefunds)
+			refunds.GET("/queue", paymentHandler.GetRefundQueueStatus)
+			refunds.GET("/:id", paymentHandler.GetRefund)
+		}
+
+		refundApprovals := v1.Group("/refund-approvals")
+		{
+			refundApprovals.GET("", refundApprovalHandler.ListRefundApprovals)
+			refundApprovals.GET("/policy", refundApprovalHandler.GetRefundApprovalPolicy)
+			refundApprovals.PUT("/policy", canUpdateSettings, refundApprovalHandler.UpdateRefundApprovalPolicy)
+			refundApprovals.GET("/:id", refundApprovalHandler.GetRefundApproval)
+			refundApprovals.POST("/:id/approve", canRefund, refundApprovalHandler.ApproveRefund)
+			refundApprovals.POST("/:id/reject", canRefund, refundApprovalHandler.RejectRefund)
 		}
 
 		transactions := v1.Group("/transactions")
 		{
 			transactions.GET("/", transactionHandler.ListTransactions)
 			transactions.GET("/:id", transactionHandler.GetTransaction)
+
+			// Internal notes and tags for support investigations
+			transactions.GET("/:id/notes", transactionHandler.ListNotes)
+			transactions.POST("/:id/notes", transactionHandler.AddNote)
+			transactions.DELETE("/:id/notes/:note_id", transactionHandler.DeleteNote)
+			transactions.POST("/:id/tags", transactionHandler.AddTags)
+			transactions.DELETE("/:id/tags/:tag", transactionHandler.RemoveTag)
+		}
+
+		// Contesting or accepting a dispute decides who bears the loss, so
+		// it takes the same permission as a refund
+		disputes := v1.Group("/disputes")
+		{
+			disputes.GET("", disputeHandler.ListDisputes)
+			disputes.GET("/:id", disputeHandler.GetDispute)
+			disputes.POST("/:id/evidence-files", canRefund, disputeHandler.UploadEvidenceFile)
+			disputes.GET("/:id/evidence-files/:file_id", disputeHandler.GetEvidenceFile)
+			disputes.POST("/:id/evidence", canRefund, disputeHandler.SubmitEvidence)
+			disputes.POST("/:id/accept", canRefund, disputeHandler.AcceptDispute)
 		}
 
 		// NEW: Payment Intents (Server-to-Server)
 		paymentIntents := v1.Group("/payment-intents")
 		{
 			paymentIntents.POST("", paymentIntentHandler.CreatePaymentIntent)
-			paymentIntents.POST("/:id/cancel", paymentIntentHandler.CancelPaymentIntent)
+			paymentIntents.POST("/:id/cancel", canVoid, paymentIntentHandler.CancelPaymentIntent)
+		}
+
+		// Itemized checkouts, paid through a payment intent
+		checkoutSessions := v1.Group("/checkout-sessions")
+		{
+			checkoutSessions.POST("", checkoutSessionHandler.CreateCheckoutSession)
+			checkoutSessions.GET("/:id", checkoutSessionHandler.GetCheckoutSession)
+		}
+
+		// Invoices, paid through a hosted link
+		invoices := v1.Group("/invoices")
+		{
+			invoices.POST("", canCreateInvoices, invoiceHandler.CreateInvoice)
+			invoices.GET("", canReadInvoices, invoiceHandler.ListInvoices)
+			invoices.GET("/:id", canReadInvoices, invoiceHandler.GetInvoice)
+			invoices.PUT("/:id", canUpdateInvoices, invoiceHandler.UpdateInvoice)
+			invoices.DELETE("/:id", canUpdateInvoices, invoiceHandler.DeleteInvoice)
+			invoices.GET("/:id/pdf", canReadInvoices, invoiceHandler.GetInvoicePDF)
+			invoices.POST("/:id/finalize", canUpdateInvoices, invoiceHandler.FinalizeInvoice)
+			invoices.POST("/:id/send", canUpdateInvoices, invoiceHandler.SendInvoice)
+			invoices.POST("/:id/void", canUpdateInvoices, invoiceHandler.VoidInvoice)
+			invoices.POST("/:id/mark-uncollectible", canUpdateInvoices, invoiceHandler.MarkInvoiceUncollectible)
+		}
+
+		checkoutSettings := v1.Group("/checkout-settings")
+		{
+			checkoutSettings.GET("", checkoutSettingsHandler.GetCheckoutSettings)
+			checkoutSettings.PUT("", checkoutSettingsHandler.UpdateCheckoutSettings)
+			checkoutSettings.GET("/redirect-secret", canUpdateSettings, checkoutSettingsHandler.GetRedirectSecret)
+			checkoutSettings.POST("/redirect-secret/rotate", canUpdateSettings, checkoutSettingsHandler.RotateRedirectSecret)
+		}
+
+		v1.GET("/display-settings", displaySettingsHandler.GetDisplaySettings)
+
+		webhookSubscriptions := v1.Group("/webhook-subscriptions")
+		{
+			webhookSubscriptions.GET("", webhookSubscriptionHandler.ListWebhookSubscriptions)
+			webhookSubscriptions.POST("", webhookSubscriptionHandler.CreateWebhookSubscription)
+			webhookSubscriptions.GET("/:id", webhookSubscriptionHandler.GetWebhookSubscription)
+			webhookSubscriptions.PATCH("/:id", webhookSubscriptionHandler.UpdateWebhookSubscription)
+			webhookSubscriptions.DELETE("/:id", webhookSubscriptionHandler.DeleteWebhookSubscription)
+			webhookSubscriptions.POST("/:id/rotate-secret", webhookSubscriptionHandler.RotateWebhookSecret)
+			webhookSubscriptions.POST("/:id/verify", webhookSubscriptionHandler.VerifyWebhookSubscription)
+		}
+
+		webhookDeliveries := v1.Group("/webhook-deliveries")
+		{
+			webhookDeliveries.GET("", webhookDeliveryHandler.ListWebhookDeliveries)
+			webhookDeliveries.GET("/:id", webhookDeliveryHandler.GetWebhookDelivery)
+			webhookDeliveries.POST("/:id/redeliver", webhookDeliveryHandler.RedeliverWebhook)
+		}
+
+		// Every payment event, whether or not a webhook was sent for it
+		events := v1.Group("/events")
+		{
+			events.GET("", eventHandler.ListEvents)
+			events.GET("/:id", eventHandler.GetEvent)
 		}
+
+		subscriptionPlans := v1.Group("/subscription-plans")
+		{
+			subscriptionPlans.GET("", subscriptionHandler.ListPlans)
+			subscriptionPlans.POST("", subscriptionHandler.CreatePlan)
+			subscriptionPlans.GET("/:id", subscriptionHandler.GetPlan)
+			subscriptionPlans.DELETE("/:id", subscriptionHandler.ArchivePlan)
+		}
+
+		subscriptions := v1.Group("/subscriptions")
+		{
+			subscriptions.GET("", subscriptionHandler.ListSubscriptions)
+			subscriptions.POST("", subscriptionHandler.CreateSubscription)
+			subscriptions.GET("/:id", subscriptionHandler.GetSubscription)
+			subscriptions.GET("/:id/charges", subscriptionHandler.ListSubscriptionCharges)
+			subscriptions.POST("/:id/change-plan", subscriptionHandler.ChangePlan)
+			subscriptions.POST("/:id/pause", subscriptionHandler.PauseSubscription)
+			subscriptions.POST("/:id/resume", subscriptionHandler.ResumeSubscription)
+			subscriptions.POST("/:id/cancel", subscriptionHandler.CancelSubscription)
+		}
+
+		fraudRules := v1.Group("/fraud/rules")
+		{
+			fraudRules.GET("", fraudRuleHandler.ListFraudRules)
+			fraudRules.POST("", canUpdateSettings, fraudRuleHandler.CreateFraudRule)
+			fraudRules.GET("/:id", fraudRuleHandler.GetFraudRule)
+			fraudRules.PATCH("/:id", canUpdateSettings, fraudRuleHandler.UpdateFraudRule)
+			fraudRules.DELETE("/:id", canUpdateSettings, fraudRuleHandler.DeleteFraudRule)
+		}
+
+		holdRelease := v1.Group("/hold-release-notifications")
+		{
+			holdRelease.GET("", holdReleaseHandler.GetHoldReleaseSettings)
+			holdRelease.PUT("", canUpdateSettings, holdReleaseHandler.UpdateHoldReleaseSettings)
+			holdRelease.PUT("/templates/:language", canUpdateSettings, holdReleaseHandler.PutHoldReleaseTemplate)
+			holdRelease.DELETE("/templates/:language", canUpdateSettings, holdReleaseHandler.DeleteHoldReleaseTemplate)
+		}
+
+		receiptTemplates := v1.Group("/receipt-templates")
+		{
+			receiptTemplates.GET("", receiptTemplateHandler.ListReceiptTemplates)
+			receiptTemplates.PUT("/:kind/:language", canUpdateSettings, receiptTemplateHandler.PutReceiptTemplate)
+			receiptTemplates.DELETE("/:kind/:language", canUpdateSettings, receiptTemplateHandler.DeleteReceiptTemplate)
+		}
+
+		cardTesting := v1.Group("/card-testing")
+		{
+			cardTesting.GET("/incidents", cardTestingHandler.ListIncidents)
+			cardTesting.POST("/incidents/:id/resolve", cardTestingHandler.ResolveIncident)
+		}
+
+		exports := v1.Group("/exports")
+		{
+			exports.POST("", exportHandler.CreateExport)
+			exports.POST("/account", exportHandler.CreateAccountExport)
+			exports.GET("", exportHandler.ListExports)
+			exports.GET("/:id", exportHandler.GetExport)
+		}
+
+		v1.POST("/timeline-exports/verify", exportHandler.VerifyTransactionTimeline)
+
+		v1.GET("/balance", settlementHandler.GetBalance)
+
+		settlements := v1.Group("/settlements")
+		{
+			settlements.GET("/instant-payout", settlementHandler.QuoteInstantPayout)
+			settlements.POST("/instant-payout", canUpdateSettings, settlementHandler.CreateInstantPayout)
+		}
+
+		// Daily and weekly payment statistics for dashboards
+		reports := v1.Group("/reports")
+		{
+			reports.GET("/summary", canReadTransactions, reportHandler.GetReportSummary)
+			reports.GET("/timeseries", canReadTransactions, reportHandler.GetReportTimeseries)
+		}
+
+		accounting := v1.Group("/accounting")
+		{
+			accounting.GET("/mappings/:provider", accountingHandler.GetMapping)
+			accounting.PUT("/mappings/:provider", accountingHandler.UpdateMapping)
+			accounting.GET("/settlements/:id/journal", accountingHandler.SettlementJournal)
+			accounting.GET("/journal", accountingHandler.MonthlyJournal)
+		}
+
+		// Sandbox (test-mode keys only)
+		v1.POST("/test/reset", sandboxHandler.ResetTestData)
+		v1.POST("/test/seed-vault", sandboxHandler.SeedVault)
 	}
 
 	// =========================================================================
@@ -79,12 +336,49 @@ func SetupRoutes(router *gin.Engine) {
 	public.Use(middleware.RequestLoggerMiddleware())
 	{
 		intents := public.Group("/payment-intents")
+		checkoutCORS := middleware.CheckoutCORSMiddleware(paymentIntentHandler.IsCheckoutOriginAllowed)
 		{
 			// Get payment intent (browser-safe, no secrets)
-			intents.GET("/:id", paymentIntentHandler.GetPaymentIntent)
+			intents.OPTIONS("/:id", checkoutCORS)
+			intents.GET("/:id", checkoutCORS, paymentIntentHandler.GetPaymentIntent)
 
 			// Confirm payment intent (process payment)
-			intents.POST("/:id/confirm", paymentIntentHandler.ConfirmPaymentIntent)
+			intents.OPTIONS("/:id/confirm", checkoutCORS)
+			intents.POST("/:id/confirm", checkoutCORS, middleware.ConfirmThrottleMiddleware(), paymentIntentHandler.ConfirmPaymentIntent)
+		}
+
+		// Signed, expiring export download links
+		public.GET("/exports/:id/download", exportHandler.DownloadExport)
+
+		// Hosted invoice links
+		hostedInvoices := public.Group("/invoices")
+		{
+			hostedInvoices.GET("/:token", invoiceHandler.GetHostedInvoice)
+			hostedInvoices.GET("/:token/pdf", invoiceHandler.GetHostedInvoicePDF)
+			hostedInvoices.GET("/:token/pay", invoiceHandler.PayHostedInvoice)
+		}
+	}
+
+	// =========================================================================
+	// INTERNAL API - Service-to-service, only when INTERNAL_API_TOKEN is set
+	// =========================================================================
+	if token := config.GetEnv("INTERNAL_API_TOKEN"); token != "" {
+		lifecycleHandler := handler.NewMerchantLifecycleHandler(exportService)
+		onboardingHandler := handler.NewOnboardingHandler()
+
+		internal := router.Group("/internal/v1")
+		internal.Use(internalNetwork)
+		internal.Use(middleware.RequireInternalToken(token))
+		{
+			merchants := internal.Group("/merchants/:merchant_id")
+			{
+				merchants.GET("/lifecycle", lifecycleHandler.GetLifecycle)
+				merchants.PUT("/lifecycle", lifecycleHandler.SetLifecycle)
+				merchants.POST("/final-exports", lifecycleHandler.CreateFinalExports)
+				merchants.GET("/exports/:id", lifecycleHandler.GetExport)
+				merchants.PUT("/display-settings", displaySettingsHandler.SetDisplaySettings)
+				merchants.GET("/onboarding", onboardingHandler.GetOnboardingProgress)
+			}
 		}
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -22,6 +23,7 @@ type AuthServiceClient struct {
 	grpcConn     *grpc.ClientConn
 	grpcTimeout  time.Duration
 	apiKeyClient pb.APIKeyServiceClient
+	roleClient   pb.RoleServiceClient
 }
 
 func NewAuthServiceClient() *AuthServiceClient {
@@ -36,7 +38,10 @@ func NewAuthServiceClient() *AuthServiceClient {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("auth-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -46,6 +51,7 @@ func NewAuthServiceClient() *AuthServiceClient {
 		httpClient:   &http.Client{Timeout: 10 * time.Second},
 		grpcConn:     conn,
 		apiKeyClient: pb.NewAPIKeyServiceClient(conn),
+		roleClient:   pb.NewRoleServiceClient(conn),
 		grpcTimeout:  400 * time.Millisecond,
 	}
 }
@@ -55,15 +61,17 @@ func NewAuthServiceClient() *AuthServiceClient {
 // =========================================================================
 
 type ValidateAPIKeyResponse struct {
-	Valid       bool      `json:"valid"`
-	MerchantID  uuid.UUID `json:"merchant_id"`
-	KeyID       uuid.UUID `json:"key_id"`
-	Name        string    `json:"name"`
-	Permissions []string  `json:"permissions"`
+	Valid        bool      `json:"valid"`
+	MerchantID   uuid.UUID `json:"merchant_id"`
+	KeyID        uuid.UUID `json:"key_id"`
+	Name         string    `json:"name"`
+	CreatedBy    uuid.UUID `json:"created_by"` // uuid.Nil for keys issued before owners were recorded
+	Permissions  []string  `json:"permissions"`
+	AllowedCIDRs []string  `json:"allowed_cidrs"`
 }
 
-func (c *AuthServiceClient) ValidateAPIKey(apiKey string) (*ValidateAPIKeyResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) ValidateAPIKey(ctx context.Context, apiKey string) (*ValidateAPIKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	resp, err := c.apiKeyClient.GetInfoByAPIKey(ctx, &pb.GetInfoByAPIKeyRequest{
@@ -84,11 +92,61 @@ func (c *AuthServiceClient) ValidateAPIKey(apiKey string) (*ValidateAPIKeyRespon
 		return nil, fmt.Errorf("invalid key ID from auth service: %w", err)
 	}
 
+	// An unparsable owner leaves CreatedBy nil, which permission checks refuse
+	createdBy, _ := uuid.Parse(resp.CreatedBy)
+
 	return &ValidateAPIKeyResponse{
-		Valid:       true,
-		MerchantID:  merchantID,
-		KeyID:       keyID,
-		Name:        resp.Name,
-		Permissions: []string{}, // Permissions are not returned by GetInfoByAPIKey
+		Valid:        true,
+		MerchantID:   merchantID,
+		KeyID:        keyID,
+		Name:         resp.Name,
+		CreatedBy:    createdBy,
+		Permissions:  []string{}, // Permissions are not returned by GetInfoByAPIKey
+		AllowedCIDRs: resp.AllowedCidrs,
 	}, nil
 }
+
+// =========================================================================
+// Permissions
+// =========================================================================
+
+// CheckPermissions checks "resource:action" permissions for a user in a merchant.
+// Answers come from the local cache when fresh; otherwise auth-service is asked
+// with the cached version so an unchanged set is not re-sent.
+func (c *AuthServiceClient) CheckPermissions(ctx context.Context, userID, merchantID uuid.UUID, checks ...string) (map[string]bool, error) {
+	cached := sharedPermissionCache.get(userID, merchantID)
+	fresh := cached != nil && time.Since(cached.fetchedAt) < permissionCacheTTL
+	metrics.CacheLookup("permissions", fresh)
+	if fresh {
+		return cached.answer(checks), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	req := &pb.BatchCheckPermissionsRequest{
+		UserId:     userID.String(),
+		MerchantId: merchantID.String(),
+	}
+	if cached != nil {
+		req.KnownVersion = cached.version
+	}
+
+	resp, err := c.roleClient.BatchCheckPermissions(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC BatchCheckPermissions failed: %w", err)
+	}
+
+	entry := &cachedPermissions{version: resp.Version, fetchedAt: time.Now()}
+	if resp.NotModified && cached != nil {
+		entry.granted = cached.granted
+	} else {
+		entry.granted = make(map[string]bool, len(resp.Permissions))
+		for _, p := range resp.Permissions {
+			entry.granted[p] = true
+		}
+	}
+	sharedPermissionCache.set(userID, merchantID, entry)
+
+	return entry.answer(checks), nil
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+)
+
+// CaptchaClient verifies CAPTCHA tokens with an hCaptcha/reCAPTCHA compatible
+// siteverify endpoint
+type CaptchaClient struct {
+	secret     string
+	verifyURL  string
+	httpClient *http.Client
+}
+
+func NewCaptchaClient() *CaptchaClient {
+	return &CaptchaClient{
+		secret:     config.GetEnv("CAPTCHA_SECRET_KEY"),
+		verifyURL:  config.GetEnvWithDefault("CAPTCHA_VERIFY_URL", "https://hcaptcha.com/siteverify"),
+		httpClient: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Enabled reports whether a CAPTCHA provider is configured
+func (c *CaptchaClient) Enabled() bool {
+	return c.secret != ""
+}
+
+// Verify checks a token solved by the browser
+func (c *CaptchaClient) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{
+		"secret":   {c.secret},
+		"response": {token},
+		"remoteip": {remoteIP},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("invalid captcha verification response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -32,6 +32,30 @@ type FraudCheckRequest struct {
 	CustomerEmail     string
 	CustomerIP        string
 	DeviceFingerprint string
+	IntentVelocity    *IntentVelocity // nil outside payment intent confirmation
+	Features          *FraudFeatures  // from GatherFeatures; gathered here when nil
+}
+
+// FraudFeatureRequest is what is known about a payment before its card is
+// tokenized, enough to start the slow feature lookups early
+type FraudFeatureRequest struct {
+	MerchantID    string
+	Amount        int64
+	Currency      string
+	CustomerEmail string
+	CustomerIP    string
+}
+
+// FraudFeatures are the signals looked up for a payment ahead of scoring
+type FraudFeatures struct {
+	BaseRisk int // 0-100, before card and velocity rules
+}
+
+// IntentVelocity describes earlier attempts on the same payment intent
+type IntentVelocity struct {
+	PaymentIntentID    string
+	DistinctCards      int // Including this attempt's card
+	AttemptsLastMinute int // Including this attempt
 }
 
 // FraudCheckResponse represents fraud check result
@@ -39,9 +63,23 @@ type FraudCheckResponse struct {
 	RiskScore      int    // 0-100
 	Decision       string // "approve", "review", "decline"
 	RulesTriggered []string
+	ReasonCodes    []string // Merchant fraud rules behind Decision
 	Reason         string
 }
 
+// GatherFeatures looks up the signals that do not depend on the card, so it
+// can run while the card is being tokenized
+func (c *FraudClient) GatherFeatures(ctx context.Context, req *FraudFeatureRequest) (*FraudFeatures, error) {
+	// Simulate IP reputation and customer history lookups
+	select {
+	case <-time.After(50 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &FraudFeatures{BaseRisk: calculateMockRiskScore(req.Amount)}, nil
+}
+
 // CheckFraud performs fraud analysis
 func (c *FraudClient) CheckFraud(ctx context.Context, req *FraudCheckRequest) (*FraudCheckResponse, error) {
 	logger.Log.Info("Running fraud check (mock)",
@@ -50,14 +88,41 @@ func (c *FraudClient) CheckFraud(ctx context.Context, req *FraudCheckRequest) (*
 		zap.String("card_last4", req.CardLast4),
 	)
 
-	// Simulate fraud check processing time
-	time.Sleep(50 * time.Millisecond)
+	features := req.Features
+	if features == nil {
+		var err error
+		features, err = c.GatherFeatures(ctx, &FraudFeatureRequest{
+			MerchantID:    req.MerchantID,
+			Amount:        req.Amount,
+			Currency:      req.Currency,
+			CustomerEmail: req.CustomerEmail,
+			CustomerIP:    req.CustomerIP,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Mock fraud scoring logic
-	riskScore := calculateMockRiskScore(req)
-	decision := determineDecision(riskScore)
+	riskScore := features.BaseRisk
 	rulesTriggered := []string{}
 
+	// Intent velocity feeds the decision, unlike the amount rule below
+	if v := req.IntentVelocity; v != nil {
+		if v.DistinctCards >= 3 {
+			rulesTriggered = append(rulesTriggered, "intent_card_churn")
+			riskScore += 25
+		}
+		if v.AttemptsLastMinute >= 3 {
+			rulesTriggered = append(rulesTriggered, "intent_attempt_velocity")
+			riskScore += 15
+		}
+		if riskScore > 100 {
+			riskScore = 100
+		}
+	}
+	decision := determineDecision(riskScore)
+
 	// Add rules based on risk factors
 	if req.Amount > 100000 { // > $1000
 		rulesTriggered = append(rulesTriggered, "high_amount")
@@ -84,16 +149,16 @@ func (c *FraudClient) CheckFraud(ctx context.Context, req *FraudCheckRequest) (*
 }
 
 // calculateMockRiskScore generates a realistic risk score
-func calculateMockRiskScore(req *FraudCheckRequest) int {
+func calculateMockRiskScore(amount int64) int {
 	rand.Seed(time.Now().UnixNano())
 
 	// Base risk: 10-30 (most transactions are low risk)
 	baseRisk := rand.Intn(21) + 10
 
 	// Amount-based risk
-	if req.Amount > 500000 { // > $5000
+	if amount > 500000 { // > $5000
 		baseRisk += 20
-	} else if req.Amount > 100000 { // > $1000
+	} else if amount > 100000 { // > $1000
 		baseRisk += 10
 	}
 
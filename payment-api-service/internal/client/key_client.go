@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// PIIKeyPurpose is the tokenization key purpose for customer PII columns
+const PIIKeyPurpose = "payment_pii"
+
+// ErrKeyShredded means the merchant's key was revoked and the data is gone
+var ErrKeyShredded = errors.New("encryption key has been shredded")
+
+// KeyManagementClient fetches per-merchant data keys from the tokenization
+// service, which owns key generation, rotation and revocation
+type KeyManagementClient struct {
+	grpcConn    *grpc.ClientConn
+	grpcTimeout time.Duration
+	keyClient   pb.KeyManagementServiceClient
+}
+
+func NewKeyManagementClient() (*KeyManagementClient, error) {
+	grpcAddress := config.GetEnv("TOKENIZATION_SERVICE_GRPC_URL")
+	if grpcAddress == "" {
+		grpcAddress = "localhost:50052"
+	}
+
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("tokenization-service")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial key management service: %w", err)
+	}
+
+	return &KeyManagementClient{
+		grpcConn:    conn,
+		grpcTimeout: 2 * time.Second,
+		keyClient:   pb.NewKeyManagementServiceClient(conn),
+	}, nil
+}
+
+// Close closes the gRPC connection
+func (c *KeyManagementClient) Close() error {
+	if c.grpcConn != nil {
+		return c.grpcConn.Close()
+	}
+	return nil
+}
+
+// ActiveKey returns the merchant's current PII key, creating it on first use
+func (c *KeyManagementClient) ActiveKey(ctx context.Context, merchantID uuid.UUID) (string, []byte, error) {
+	resp, err := c.getDataKey(ctx, merchantID, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.KeyId, resp.Key, nil
+}
+
+// KeyByID returns a specific PII key version
+func (c *KeyManagementClient) KeyByID(ctx context.Context, merchantID uuid.UUID, keyID string) ([]byte, error) {
+	resp, err := c.getDataKey(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Key, nil
+}
+
+// ShredMerchantKeys revokes all of a merchant's PII keys
+func (c *KeyManagementClient) ShredMerchantKeys(ctx context.Context, merchantID, requestedBy uuid.UUID, reason string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.keyClient.ShredMerchantKeys(ctx, &pb.ShredMerchantKeysRequest{
+		MerchantId:  merchantID.String(),
+		Purpose:     PIIKeyPurpose,
+		RequestedBy: requestedBy.String(),
+		Reason:      reason,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("key management service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return int(resp.ShreddedKeys), errors.New(resp.Error)
+	}
+
+	logger.Log.Warn("Merchant PII keys shredded",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int32("keys", resp.ShreddedKeys),
+	)
+	return int(resp.ShreddedKeys), nil
+}
+
+func (c *KeyManagementClient) getDataKey(ctx context.Context, merchantID uuid.UUID, keyID string) (*pb.GetDataKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.keyClient.GetDataKey(ctx, &pb.GetDataKeyRequest{
+		MerchantId: merchantID.String(),
+		Purpose:    PIIKeyPurpose,
+		KeyId:      keyID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("key management service unavailable: %w", err)
+	}
+	if resp.Shredded {
+		return nil, ErrKeyShredded
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+)
+
+var ErrMerchantServiceNotConfigured = errors.New("INTERNAL_API_TOKEN is not set")
+
+// MerchantServiceClient calls merchant-service's internal endpoints, which
+// are guarded by the shared INTERNAL_API_TOKEN
+type MerchantServiceClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func NewMerchantServiceClient() *MerchantServiceClient {
+	baseURL := config.GetEnv("MERCHANT_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8002"
+	}
+
+	return &MerchantServiceClient{
+		baseURL:    baseURL,
+		token:      config.GetEnv("INTERNAL_API_TOKEN"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetAccountData returns the merchant's profile, settings, team and bank
+// accounts as merchant-service reports them, without secrets
+func (c *MerchantServiceClient) GetAccountData(ctx context.Context, merchantID uuid.UUID) (json.RawMessage, error) {
+	if c.token == "" {
+		return nil, ErrMerchantServiceNotConfigured
+	}
+
+	url := fmt.Sprintf("%s/internal/v1/merchants/%s/account-data", c.baseURL, merchantID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Internal-Token", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("merchant service unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("unexpected response from merchant service (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode >= 300 || !envelope.Success {
+		return nil, fmt.Errorf("merchant service returned %d: %s", resp.StatusCode, envelope.Error)
+	}
+	return envelope.Data, nil
+}
@@ -2,12 +2,14 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -30,7 +32,10 @@ func NewTokenizationClient() (*TokenizationClient, error) {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("tokenization-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -66,7 +71,7 @@ type TokenizeCardResponse struct {
 
 // TokenizeCard tokenizes card data
 func (c *TokenizationClient) TokenizeCard(ctx context.Context, req *pb.TokenizeCardRequest) (*TokenizeCardResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Tokenizing card (simulated)",
@@ -105,7 +110,7 @@ func (c *TokenizationClient) TokenizeCard(ctx context.Context, req *pb.TokenizeC
 // ValidateToken validates a token
 func (c *TokenizationClient) ValidateToken(ctx context.Context, token string, merchantID string) (bool, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 	resp, err := c.tokenizationClient.ValidateToken(ctx, &pb.ValidateTokenRequest{
 		Token:      token,
@@ -117,3 +122,82 @@ func (c *TokenizationClient) ValidateToken(ctx context.Context, token string, me
 	}
 	return resp.Valid, nil
 }
+
+// GetTokenCard returns the card behind one of the merchant's usable tokens
+func (c *TokenizationClient) GetTokenCard(ctx context.Context, token string, merchantID string) (*pb.CardMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.tokenizationClient.ValidateToken(ctx, &pb.ValidateTokenRequest{
+		Token:      token,
+		MerchantId: merchantID,
+	})
+	if err != nil {
+		logger.Log.Error("Tokenization service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("tokenization service unavailable: %w", err)
+	}
+	if !resp.Valid || resp.Card == nil {
+		return nil, ErrTokenNotUsable
+	}
+	return resp.Card, nil
+}
+
+// ErrTokenNotUsable is returned for unknown, revoked or expired tokens
+var ErrTokenNotUsable = errors.New("card token is not valid for this merchant")
+
+// DeleteTestTokens permanently removes the merchant's sandbox tokens
+func (c *TokenizationClient) DeleteTestTokens(ctx context.Context, merchantID string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.tokenizationClient.DeleteTestTokens(ctx, &pb.DeleteTestTokensRequest{
+		MerchantId: merchantID,
+	})
+	if err != nil {
+		logger.Log.Error("Tokenization service gRPC request failed", zap.Error(err))
+		return 0, fmt.Errorf("tokenization service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return 0, fmt.Errorf("failed to delete test tokens: %s", resp.Error)
+	}
+	return int(resp.DeletedTokens), nil
+}
+
+// ListTokenUsage returns the token usage log entries of the merchant's transactions
+func (c *TokenizationClient) ListTokenUsage(ctx context.Context, merchantID string, transactionIDs []string) ([]*pb.TokenUsageEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.tokenizationClient.ListTokenUsage(ctx, &pb.ListTokenUsageRequest{
+		MerchantId:     merchantID,
+		TransactionIds: transactionIDs,
+	})
+	if err != nil {
+		logger.Log.Error("Tokenization service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("tokenization service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to list token usage: %s", resp.Error)
+	}
+	return resp.Entries, nil
+}
+
+// ListMerchantTokens returns one page of the merchant's token metadata
+func (c *TokenizationClient) ListMerchantTokens(ctx context.Context, merchantID string, limit, offset int) (*pb.ListMerchantTokensResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.tokenizationClient.ListMerchantTokens(ctx, &pb.ListMerchantTokensRequest{
+		MerchantId: merchantID,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		logger.Log.Error("Tokenization service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("tokenization service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("failed to list tokens: %s", resp.Error)
+	}
+	return resp, nil
+}
@@ -2,12 +2,15 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -21,8 +24,12 @@ type TransactionClient struct {
 	grpcConn          *grpc.ClientConn
 	grpcTimeout       time.Duration
 	transactionClient pb.TransactionServiceClient
+	chargebackClient  pb.ChargebackServiceClient
 }
 
+// evidenceUploadTimeout allows for a full-size evidence document in transit
+const evidenceUploadTimeout = 10 * time.Second
+
 func NewTransactionClient() *TransactionClient {
 	grpcAddress := config.GetEnv("TRANSACTION_SERVICE_GRPC_URL")
 	if grpcAddress == "" {
@@ -30,7 +37,10 @@ func NewTransactionClient() *TransactionClient {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("transaction-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -40,6 +50,7 @@ func NewTransactionClient() *TransactionClient {
 		grpcConn:          conn,
 		grpcTimeout:       400 * time.Millisecond,
 		transactionClient: pb.NewTransactionServiceClient(conn),
+		chargebackClient:  pb.NewChargebackServiceClient(conn),
 	}
 }
 
@@ -48,7 +59,7 @@ func NewTransactionClient() *TransactionClient {
 // =========================================================================
 
 func (c *TransactionClient) Authorize(ctx context.Context, req *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing authorization ",
@@ -68,6 +79,9 @@ func (c *TransactionClient) Authorize(ctx context.Context, req *pb.AuthorizeRequ
 		Description:   req.Description,
 		IpAddress:     req.IpAddress,
 		UserAgent:     req.UserAgent,
+		ThreeDsEci:    req.ThreeDsEci,
+		ThreeDsCavv:   req.ThreeDsCavv,
+		TestMode:      req.TestMode,
 	})
 	if err != nil {
 		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
@@ -75,27 +89,87 @@ func (c *TransactionClient) Authorize(ctx context.Context, req *pb.AuthorizeRequ
 	}
 
 	return &pb.AuthorizeResponse{
-		TransactionId:   resp.TransactionId,
-		Status:          resp.Status,
-		Approved:        resp.Approved,
-		AuthCode:        resp.AuthCode,
-		ResponseCode:    resp.ResponseCode,
-		ResponseMessage: resp.ResponseMessage,
-		DeclineReason:   resp.DeclineReason,
-		Amount:          resp.Amount,
-		AmountMad:       resp.AmountMad,
-		ExchangeRate:    resp.ExchangeRate,
-		ProcessingFee:   resp.ProcessingFee,
-		NetAmount:       resp.NetAmount,
+		TransactionId:              resp.TransactionId,
+		Status:                     resp.Status,
+		Approved:                   resp.Approved,
+		AuthCode:                   resp.AuthCode,
+		ResponseCode:               resp.ResponseCode,
+		ResponseMessage:            resp.ResponseMessage,
+		DeclineReason:              resp.DeclineReason,
+		Amount:                     resp.Amount,
+		AmountMad:                  resp.AmountMad,
+		ExchangeRate:               resp.ExchangeRate,
+		ProcessingFee:              resp.ProcessingFee,
+		NetAmount:                  resp.NetAmount,
+		RetryAllowed:               resp.RetryAllowed,
+		SuggestedRetryAfterSeconds: resp.SuggestedRetryAfterSeconds,
 	}, nil
 }
 
+// =========================================================================
+// 3-D Secure
+// =========================================================================
+
+// ErrInvalidChallengeResponse means the ACS could not read the CRes posted
+// back after a 3-D Secure challenge
+var ErrInvalidChallengeResponse = errors.New("invalid 3-D Secure challenge response")
+
+// Authenticate starts 3-D Secure for a card with the issuer's ACS
+func (c *TransactionClient) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.Authenticate(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// CompleteAuthentication returns the outcome of a 3-D Secure challenge
+func (c *TransactionClient) CompleteAuthentication(ctx context.Context, req *pb.CompleteAuthenticationRequest) (*pb.AuthenticateResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.CompleteAuthentication(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidChallengeResponse, resp.Error)
+	}
+	return resp, nil
+}
+
 // =========================================================================
 // Capture
 // =========================================================================
 
+// Capture and refund requests must name the original transaction's currency
+var (
+	ErrCurrencyRequired = errors.New("currency is required")
+	ErrCurrencyMismatch = errors.New("currency does not match the original transaction")
+)
+
+// currencyError turns the transaction service's error code back into a
+// typed error, or returns nil for other failures
+func currencyError(code, message string) error {
+	switch code {
+	case "currency_required":
+		return ErrCurrencyRequired
+	case "currency_mismatch":
+		return fmt.Errorf("%w: %s", ErrCurrencyMismatch, strings.TrimPrefix(message, ErrCurrencyMismatch.Error()+": "))
+	}
+	return nil
+}
+
 func (c *TransactionClient) Capture(ctx context.Context, req *pb.CaptureRequest) (*pb.CaptureResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing capture (mock)",
@@ -107,17 +181,29 @@ func (c *TransactionClient) Capture(ctx context.Context, req *pb.CaptureRequest)
 		TransactionId: req.TransactionId,
 		Amount:        req.Amount,
 		MerchantId:    req.MerchantId,
+		FinalCapture:  req.FinalCapture,
+		Currency:      req.Currency,
 	})
 	if err != nil {
 		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
 		return nil, fmt.Errorf("transaction service unavailable or invalid key: %w", err)
 	}
+	if err := currencyError(resp.ErrorCode, resp.Error); err != nil {
+		return nil, err
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
 
 	return &pb.CaptureResponse{
-		TransactionId:   resp.TransactionId,
-		Status:          resp.Status,
-		CapturedAmount:  resp.CapturedAmount,
-		ResponseMessage: resp.ResponseMessage,
+		TransactionId:       resp.TransactionId,
+		Status:              resp.Status,
+		CapturedAmount:      resp.CapturedAmount,
+		ResponseMessage:     resp.ResponseMessage,
+		CaptureId:           resp.CaptureId,
+		TotalCapturedAmount: resp.TotalCapturedAmount,
+		RemainingAmount:     resp.RemainingAmount,
 	}, nil
 }
 
@@ -127,7 +213,7 @@ func (c *TransactionClient) Capture(ctx context.Context, req *pb.CaptureRequest)
 
 // Void cancels an authorized transaction
 func (c *TransactionClient) Void(ctx context.Context, req *pb.VoidRequest) (*pb.VoidResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing void (mock)",
@@ -158,7 +244,7 @@ func (c *TransactionClient) Void(ctx context.Context, req *pb.VoidRequest) (*pb.
 
 // Refund processes a refund
 func (c *TransactionClient) Refund(ctx context.Context, req *pb.RefundRequest) (*pb.RefundResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing refund (mock)",
@@ -169,22 +255,114 @@ func (c *TransactionClient) Refund(ctx context.Context, req *pb.RefundRequest) (
 	resp, err := c.transactionClient.Refund(ctx, &pb.RefundRequest{
 		TransactionId: req.TransactionId,
 		Amount:        req.Amount,
+		Reason:        req.Reason,
+		ReasonCode:    req.ReasonCode,
 		MerchantId:    req.MerchantId,
+		Currency:      req.Currency,
 	})
 	if err != nil {
 		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
 		return nil, fmt.Errorf("transaction service unavailable or invalid key: %w", err)
 	}
+	if err := currencyError(resp.ErrorCode, resp.Error); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
 
 	return &pb.RefundResponse{
-		RefundId:        resp.RefundId,
-		RefundedAmount:  resp.RefundedAmount,
-		ResponseMessage: resp.ResponseMessage,
+		RefundId:           resp.RefundId,
+		TransactionId:      resp.TransactionId,
+		RefundedAmount:     resp.RefundedAmount,
+		RemainingAmount:    resp.RemainingAmount,
+		ResponseMessage:    resp.ResponseMessage,
+		RefundStatus:       resp.RefundStatus,
+		EstimatedArrivalAt: resp.EstimatedArrivalAt,
 	}, nil
 }
 
+func (c *TransactionClient) GetRefund(ctx context.Context, req *pb.GetRefundRequest) (*pb.RefundDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetRefund(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) ListRefunds(ctx context.Context, req *pb.ListRefundsRequest) ([]*pb.RefundDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.ListRefunds(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Refunds, nil
+}
+
+// ListMerchantRefunds pages through the merchant's refunds
+func (c *TransactionClient) ListMerchantRefunds(ctx context.Context, req *pb.ListMerchantRefundsRequest) (*pb.ListMerchantRefundsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.ListMerchantRefunds(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// GetRefundQueueStatus reports how the merchant's queued refunds are draining
+func (c *TransactionClient) GetRefundQueueStatus(ctx context.Context, req *pb.GetRefundQueueStatusRequest) (*pb.RefundQueueStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetRefundQueueStatus(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// GetTransactionTimeline returns a transaction's events, issuer responses
+// and routing decision
+func (c *TransactionClient) GetTransactionTimeline(ctx context.Context, req *pb.GetTransactionTimelineRequest) (*pb.TransactionTimelineResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetTransactionTimeline(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
 func (c *TransactionClient) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.TransactionResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing get transaction (mock)",
@@ -221,11 +399,17 @@ func (c *TransactionClient) GetTransaction(ctx context.Context, req *pb.GetTrans
 		CreatedAt:      resp.CreatedAt,
 		AuthorizedAt:   resp.AuthorizedAt,
 		CapturedAt:     resp.CapturedAt,
+		VoidedAt:       resp.VoidedAt,
+		VoidedReason:   resp.VoidedReason,
+		Tags:           resp.Tags,
+		NoteCount:      resp.NoteCount,
+		TestMode:       resp.TestMode,
+		Error:          resp.Error,
 	}, nil
 }
 
 func (c *TransactionClient) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	logger.Log.Info("Processing list transactions (mock)",
@@ -233,10 +417,19 @@ func (c *TransactionClient) ListTransactions(ctx context.Context, req *pb.ListTr
 	)
 
 	resp, err := c.transactionClient.ListTransactions(ctx, &pb.ListTransactionsRequest{
-		MerchantId: req.MerchantId,
-		Status:     req.Status,
-		Limit:      req.Limit,
-		Offset:     req.Offset,
+		MerchantId:   req.MerchantId,
+		Status:       req.Status,
+		Limit:        req.Limit,
+		Offset:       req.Offset,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		SortBy:       req.SortBy,
+		SortOrder:    req.SortOrder,
+		Type:         req.Type,
+		VoidedReason: req.VoidedReason,
+		Tag:          req.Tag,
+		Note:         req.Note,
+		TestMode:     req.TestMode,
 	})
 	if err != nil {
 		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
@@ -246,9 +439,237 @@ func (c *TransactionClient) ListTransactions(ctx context.Context, req *pb.ListTr
 	return &pb.ListTransactionsResponse{
 		Transactions: resp.Transactions,
 		Total:        resp.Total,
+		Error:        resp.Error,
+		HasMore:      resp.HasMore,
+		Limit:        resp.Limit,
+		Offset:       resp.Offset,
 	}, nil
 }
 
+// =========================================================================
+// Transaction Notes & Tags
+// =========================================================================
+
+// Note and tag calls return the response as is; a rejected request is
+// reported in its Error field, a failed call as err.
+
+func (c *TransactionClient) AddTransactionNote(ctx context.Context, req *pb.AddTransactionNoteRequest) (*pb.TransactionNoteResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.AddTransactionNote(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) ListTransactionNotes(ctx context.Context, req *pb.ListTransactionNotesRequest) (*pb.ListTransactionNotesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.ListTransactionNotes(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) DeleteTransactionNote(ctx context.Context, req *pb.DeleteTransactionNoteRequest) (*pb.DeleteTransactionNoteResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.DeleteTransactionNote(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) AddTransactionTags(ctx context.Context, req *pb.AddTransactionTagsRequest) (*pb.TransactionTagsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.AddTransactionTags(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) RemoveTransactionTag(ctx context.Context, req *pb.RemoveTransactionTagRequest) (*pb.TransactionTagsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.RemoveTransactionTag(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+// =========================================================================
+// Settlements
+// =========================================================================
+
+func (c *TransactionClient) GetSettlementBatch(ctx context.Context, req *pb.GetSettlementBatchRequest) (*pb.SettlementBatchResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetSettlementBatch(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) ListSettlementBatches(ctx context.Context, req *pb.ListSettlementBatchesRequest) ([]*pb.SettlementBatchResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.ListSettlementBatches(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Batches, nil
+}
+
+// CreateInstantPayout returns the response as is: an ineligible merchant
+// gets the reasons along with the error
+func (c *TransactionClient) CreateInstantPayout(ctx context.Context, req *pb.CreateInstantPayoutRequest) (*pb.InstantPayoutResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.CreateInstantPayout(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) GetBalance(ctx context.Context, merchantID string) (*pb.BalanceResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetBalance(ctx, &pb.GetBalanceRequest{MerchantId: merchantID})
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) GetStatistics(ctx context.Context, req *pb.GetStatisticsRequest) (*pb.StatisticsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.transactionClient.GetStatistics(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// =========================================================================
+// Disputes
+// =========================================================================
+
+// Dispute calls return the response as is; a rejected request is reported
+// in its Error field, a failed call as err.
+
+func (c *TransactionClient) ListDisputes(ctx context.Context, req *pb.ListDisputesRequest) (*pb.ListDisputesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.ListDisputes(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) GetDispute(ctx context.Context, req *pb.GetDisputeRequest) (*pb.DisputeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.GetDispute(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) UploadDisputeEvidence(ctx context.Context, req *pb.UploadDisputeEvidenceRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, evidenceUploadTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.UploadDisputeEvidence(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) GetDisputeEvidenceFile(ctx context.Context, req *pb.GetDisputeEvidenceFileRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, evidenceUploadTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.GetDisputeEvidenceFile(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) SubmitDisputeEvidence(ctx context.Context, req *pb.SubmitDisputeEvidenceRequest) (*pb.DisputeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.SubmitDisputeEvidence(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
+func (c *TransactionClient) AcceptDispute(ctx context.Context, req *pb.AcceptDisputeRequest) (*pb.DisputeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.chargebackClient.AcceptDispute(ctx, req)
+	if err != nil {
+		logger.Log.Error("Transaction service gRPC request failed", zap.Error(err))
+		return nil, fmt.Errorf("transaction service unavailable: %w", err)
+	}
+	return resp, nil
+}
+
 // Close closes the client connection (no-op for mock)
 func (c *TransactionClient) Close() error {
 	return nil
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+)
+
+type AccountingHandler struct {
+	accountingService *service.AccountingService
+}
+
+func NewAccountingHandler() *AccountingHandler {
+	return &AccountingHandler{
+		accountingService: service.NewAccountingService(),
+	}
+}
+
+type UpdateAccountingMappingRequest struct {
+	BankAccount    string `json:"bank_account" binding:"required"`
+	FeesAccount    string `json:"fees_account" binding:"required"`
+	RefundsAccount string `json:"refunds_account" binding:"required"`
+	SalesAccount   string `json:"sales_account" binding:"required"`
+	ReserveAccount string `json:"reserve_account"`
+	TaxRate        string `json:"tax_rate"`
+}
+
+// GetMapping returns the merchant's account mapping for a provider
+// GET /api/v1/accounting/mappings/:provider
+func (h *AccountingHandler) GetMapping(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	provider, ok := accountingProvider(c)
+	if !ok {
+		return
+	}
+
+	mapping, err := h.accountingService.GetMapping(merchantID, provider)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load account mapping",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    mapping,
+	})
+}
+
+// UpdateMapping saves the merchant's account mapping for a provider
+// PUT /api/v1/accounting/mappings/:provider
+func (h *AccountingHandler) UpdateMapping(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	provider, ok := accountingProvider(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateAccountingMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	mapping, err := h.accountingService.UpdateMapping(&model.AccountingMapping{
+		MerchantID:     merchantID,
+		Provider:       provider,
+		BankAccount:    req.BankAccount,
+		FeesAccount:    req.FeesAccount,
+		RefundsAccount: req.RefundsAccount,
+		SalesAccount:   req.SalesAccount,
+		ReserveAccount: req.ReserveAccount,
+		TaxRate:        req.TaxRate,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    mapping,
+	})
+}
+
+// SettlementJournal downloads the journal entry for one settlement batch
+// GET /api/v1/accounting/settlements/:id/journal?format=quickbooks_iif
+func (h *AccountingHandler) SettlementJournal(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	batchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid settlement batch id",
+		})
+		return
+	}
+
+	format, provider, err := service.ParseJournalFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	file, err := h.accountingService.SettlementJournal(c.Request.Context(), merchantID, batchID, format, provider)
+	if err != nil {
+		writeJournalError(c, err)
+		return
+	}
+	writeJournalFile(c, file)
+}
+
+// MonthlyJournal downloads journal entries for every batch paid out in a month
+// GET /api/v1/accounting/journal?month=2024-01&format=xero_csv
+func (h *AccountingHandler) MonthlyJournal(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	format, provider, err := service.ParseJournalFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	file, err := h.accountingService.MonthlyJournal(c.Request.Context(), merchantID, c.Query("month"), format, provider)
+	if err != nil {
+		writeJournalError(c, err)
+		return
+	}
+	writeJournalFile(c, file)
+}
+
+func accountingProvider(c *gin.Context) (model.AccountingProvider, bool) {
+	provider := model.AccountingProvider(c.Param("provider"))
+	if provider != model.AccountingProviderQuickBooks && provider != model.AccountingProviderXero {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "provider must be 'quickbooks' or 'xero'",
+		})
+		return "", false
+	}
+	return provider, true
+}
+
+func writeJournalError(c *gin.Context, err error) {
+	status := http.StatusBadGateway
+	switch {
+	case errors.Is(err, service.ErrSettlementNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrSettlementNotSettled):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+func writeJournalFile(c *gin.Context, file *service.JournalFile) {
+	c.Header("Content-Disposition", `attachment; filename="`+file.FileName+`"`)
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type CardTestingHandler struct {
+	detector *service.CardTestingDetector
+}
+
+func NewCardTestingHandler() *CardTestingHandler {
+	return &CardTestingHandler{
+		detector: service.NewCardTestingDetector(),
+	}
+}
+
+// ListIncidents returns card-testing incidents detected for the merchant
+// along with the protection currently in force
+// GET /api/v1/card-testing/incidents?status=open
+func (h *CardTestingHandler) ListIncidents(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	status := model.CardTestingIncidentStatus(c.Query("status"))
+	if status != "" && status != model.CardTestingIncidentOpen && status != model.CardTestingIncidentResolved {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "status must be 'open' or 'resolved'",
+		})
+		return
+	}
+
+	incidents, err := h.detector.ListIncidents(merchantID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list incidents",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"active_protection": h.detector.ActiveProtection(merchantID),
+			"incidents":         incidents,
+		},
+	})
+}
+
+// ResolveIncident marks an incident as reviewed and lifts the protection
+// POST /api/v1/card-testing/incidents/:id/resolve
+func (h *CardTestingHandler) ResolveIncident(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	incidentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid incident id",
+		})
+		return
+	}
+
+	incident, err := h.detector.ResolveIncident(incidentID, merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "incident not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to resolve incident",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    incident,
+	})
+}
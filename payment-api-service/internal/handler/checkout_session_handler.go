@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CheckoutSessionHandler struct {
+	sessionService *service.CheckoutSessionService
+}
+
+func NewCheckoutSessionHandler(paymentService *service.PaymentService) *CheckoutSessionHandler {
+	return &CheckoutSessionHandler{
+		sessionService: service.NewCheckoutSessionService(service.NewPaymentIntentService(paymentService)),
+	}
+}
+
+type CreateCheckoutSessionRequest struct {
+	Currency        string                          `json:"currency" binding:"required,len=3"`
+	LineItems       []CheckoutLineItemRequest       `json:"line_items" binding:"required,min=1,max=100,dive"`
+	ShippingOptions []CheckoutShippingOptionRequest `json:"shipping_options" binding:"max=10,dive"`
+	Locale          string                          `json:"locale"` // en, fr or ar; empty uses the merchant's locale
+	OrderID         string                          `json:"order_id"`
+	Description     string                          `json:"description"`
+	CaptureMethod   model.CaptureMethod             `json:"capture_method"`
+	SuccessURL      string                          `json:"success_url" binding:"required,url"`
+	CancelURL       string                          `json:"cancel_url" binding:"omitempty,url"`
+	CustomerEmail   string                          `json:"customer_email" binding:"omitempty,email"`
+	Metadata        map[string]interface{}          `json:"metadata"`
+}
+
+type CheckoutLineItemRequest struct {
+	Name       string `json:"name" binding:"required,max=255"`
+	Quantity   int64  `json:"quantity" binding:"required,min=1,max=10000"`
+	UnitAmount int64  `json:"unit_amount" binding:"min=0,max=100000000"`
+	TaxRate    *struct {
+		Name       string  `json:"name" binding:"required,max=100"`
+		Percentage float64 `json:"percentage" binding:"min=0,max=100"`
+	} `json:"tax_rate"`
+}
+
+type CheckoutShippingOptionRequest struct {
+	Code   string `json:"code" binding:"required,max=64"`
+	Name   string `json:"name" binding:"required,max=255"`
+	Amount int64  `json:"amount" binding:"min=0,max=100000000"`
+}
+
+// CreateCheckoutSession creates an itemized checkout and the payment intent
+// that collects its total
+// POST /api/v1/checkout-sessions
+func (h *CheckoutSessionHandler) CreateCheckoutSession(c *gin.Context) {
+	var req CreateCheckoutSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	language, ok := requireLanguage(c, req.Locale)
+	if !ok {
+		return
+	}
+
+	serviceReq := &service.CreateCheckoutSessionRequest{
+		CreatePaymentIntentRequest: service.CreatePaymentIntentRequest{
+			MerchantID:    merchantID,
+			Currency:      req.Currency,
+			OrderID:       req.OrderID,
+			Description:   req.Description,
+			CaptureMethod: req.CaptureMethod,
+			SuccessURL:    req.SuccessURL,
+			CancelURL:     req.CancelURL,
+			CustomerEmail: req.CustomerEmail,
+			Metadata:      req.Metadata,
+			TestMode:      isTestMode(c),
+			Language:      language,
+		},
+	}
+	for _, item := range req.LineItems {
+		input := service.CheckoutLineItemInput{
+			Name:       item.Name,
+			Quantity:   item.Quantity,
+			UnitAmount: item.UnitAmount,
+		}
+		if item.TaxRate != nil {
+			input.TaxRate = &service.CheckoutTaxRateInput{
+				Name:       item.TaxRate.Name,
+				Percentage: item.TaxRate.Percentage,
+			}
+		}
+		serviceReq.LineItems = append(serviceReq.LineItems, input)
+	}
+	for _, option := range req.ShippingOptions {
+		serviceReq.ShippingOptions = append(serviceReq.ShippingOptions, service.CheckoutShippingOptionInput{
+			Code:   option.Code,
+			Name:   option.Name,
+			Amount: option.Amount,
+		})
+	}
+
+	response, err := h.sessionService.CreateCheckoutSession(c.Request.Context(), serviceReq)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCheckoutSession) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		logger.Log.Error("Failed to create checkout session",
+			zap.Error(err),
+			zap.String("merchant_id", merchantID.String()),
+		)
+		c.JSON(paymentErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// GetCheckoutSession returns a session with its line items and status
+// GET /api/v1/checkout-sessions/:id
+func (h *CheckoutSessionHandler) GetCheckoutSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid checkout_session_id",
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.sessionService.GetCheckoutSession(c.Request.Context(), sessionID, merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "checkout session not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load checkout session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
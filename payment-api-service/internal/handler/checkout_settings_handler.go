@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+)
+
+type CheckoutSettingsHandler struct {
+	settingsService *service.CheckoutSettingsService
+}
+
+func NewCheckoutSettingsHandler() *CheckoutSettingsHandler {
+	return &CheckoutSettingsHandler{
+		settingsService: service.NewCheckoutSettingsService(),
+	}
+}
+
+type UpdateCheckoutSettingsRequest struct {
+	AllowedOrigins      []string `json:"allowed_origins"`
+	RequireCaptcha      bool     `json:"require_captcha"`
+	AcceptedBrands      []string `json:"accepted_brands"`
+	SurchargeDisclosure string   `json:"surcharge_disclosure"`
+}
+
+// GetCheckoutSettings returns the merchant's hosted checkout protections
+// GET /api/v1/checkout-settings
+func (h *CheckoutSettingsHandler) GetCheckoutSettings(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.settingsService.GetSettings(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load checkout settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    checkoutSettingsResponse(settings),
+	})
+}
+
+// UpdateCheckoutSettings replaces the merchant's origin allowlist, CAPTCHA
+// requirement, accepted card brands and surcharge disclosure
+// PUT /api/v1/checkout-settings
+func (h *CheckoutSettingsHandler) UpdateCheckoutSettings(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateCheckoutSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	settings, err := h.settingsService.UpdateSettings(merchantID, req.AllowedOrigins, req.RequireCaptcha, req.AcceptedBrands, req.SurchargeDisclosure)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    checkoutSettingsResponse(settings),
+	})
+}
+
+// GetRedirectSecret returns the key success redirects are signed with
+// GET /api/v1/checkout-settings/redirect-secret
+func (h *CheckoutSettingsHandler) GetRedirectSecret(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	secret, err := h.settingsService.RedirectSecret(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load redirect secret",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"secret": secret},
+	})
+}
+
+// RotateRedirectSecret replaces the redirect signing key
+// POST /api/v1/checkout-settings/redirect-secret/rotate
+func (h *CheckoutSettingsHandler) RotateRedirectSecret(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	secret, err := h.settingsService.RotateRedirectSecret(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to rotate redirect secret",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"secret": secret},
+	})
+}
+
+func checkoutSettingsResponse(s *model.CheckoutSettings) gin.H {
+	return gin.H{
+		"merchant_id":          s.MerchantID,
+		"allowed_origins":      s.AllowedOriginList(),
+		"require_captcha":      s.RequireCaptcha,
+		"accepted_brands":      s.AcceptedBrandList(),
+		"surcharge_disclosure": s.SurchargeDisclosure,
+		"updated_at":           s.UpdatedAt,
+	}
+}
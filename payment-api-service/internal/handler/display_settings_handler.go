@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+)
+
+// DisplaySettingsHandler exposes the merchant's timezone, locale and number
+// format. The merchant service owns them and pushes changes internally.
+type DisplaySettingsHandler struct {
+	settingsService *service.DisplaySettingsService
+}
+
+func NewDisplaySettingsHandler() *DisplaySettingsHandler {
+	return &DisplaySettingsHandler{
+		settingsService: service.NewDisplaySettingsService(),
+	}
+}
+
+type SetDisplaySettingsRequest struct {
+	Timezone     string             `json:"timezone" binding:"required"`
+	Locale       string             `json:"locale" binding:"required"`
+	NumberFormat model.NumberFormat `json:"number_format" binding:"required"`
+	// Omitted by pushers that predate the flag; receipts stay on
+	SendEmailReceipts *bool  `json:"send_email_receipts"`
+	NotificationEmail string `json:"notification_email" binding:"omitempty,email"`
+
+	StatementDescriptor string `json:"statement_descriptor"`
+	BusinessName        string `json:"business_name" binding:"max=255"`
+	LogoURL             string `json:"logo_url" binding:"max=500"`
+	BrandColor          string `json:"brand_color"`
+}
+
+// GetDisplaySettings returns how dates and amounts are shown to the merchant
+// GET /api/v1/display-settings
+func (h *DisplaySettingsHandler) GetDisplaySettings(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.settingsService.GetSettings(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load display settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// SetDisplaySettings stores the settings the merchant service pushes
+// PUT /internal/v1/merchants/:merchant_id/display-settings
+func (h *DisplaySettingsHandler) SetDisplaySettings(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req SetDisplaySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	sendEmailReceipts := req.SendEmailReceipts == nil || *req.SendEmailReceipts
+
+	settings, err := h.settingsService.UpdateSettings(&model.MerchantDisplaySettings{
+		MerchantID:          merchantID,
+		Timezone:            req.Timezone,
+		Locale:              req.Locale,
+		NumberFormat:        req.NumberFormat,
+		SendEmailReceipts:   sendEmailReceipts,
+		NotificationEmail:   req.NotificationEmail,
+		StatementDescriptor: req.StatementDescriptor,
+		BusinessName:        req.BusinessName,
+		LogoURL:             req.LogoURL,
+		BrandColor:          req.BrandColor,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidDisplaySettings) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    settings,
+	})
+}
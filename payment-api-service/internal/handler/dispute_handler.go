@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+// DisputeHandler serves the merchant side of chargebacks: reviewing them,
+// uploading evidence documents, and contesting or accepting them before the
+// response deadline
+type DisputeHandler struct {
+	disputeService *service.DisputeService
+}
+
+func NewDisputeHandler() *DisputeHandler {
+	return &DisputeHandler{
+		disputeService: service.NewDisputeService(),
+	}
+}
+
+type SubmitDisputeEvidenceRequest struct {
+	Evidence          map[string]string `json:"evidence"`
+	MerchantStatement string            `json:"merchant_statement"`
+}
+
+type AcceptDisputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// disputeErrorStatus maps an error reported by the transaction service
+func disputeErrorStatus(msg string) int {
+	switch msg {
+	case "dispute not found", "evidence file not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// ListDisputes pages through the merchant's disputes, soonest response
+// deadline first
+// GET /api/v1/disputes?status=&fields=&limit=&offset=
+func (h *DisputeHandler) ListDisputes(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	fields, ok := requireFields(c, &pb.DisputeResponse{})
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	resp, err := h.disputeService.ListDisputes(c.Request.Context(), &pb.ListDisputesRequest{
+		MerchantId: merchantID.String(),
+		Status:     c.Query("status"),
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if resp.Error != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   resp.Error,
+		})
+		return
+	}
+
+	respondList(c, resp, "disputes", fields)
+}
+
+// GetDispute returns one dispute with its deadline and evidence files
+// GET /api/v1/disputes/:id
+func (h *DisputeHandler) GetDispute(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.disputeService.GetDispute(c.Request.Context(), &pb.GetDisputeRequest{
+		DisputeId:  c.Param("id"),
+		MerchantId: merchantID.String(),
+	})
+	h.respondDispute(c, resp, err)
+}
+
+// UploadEvidenceFile attaches a document (PDF, PNG, JPEG or plain text, up
+// to 3MB) to a dispute awaiting the merchant's response
+// POST /api/v1/disputes/:id/evidence-files (multipart: file, description)
+func (h *DisputeHandler) UploadEvidenceFile(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart envelope and the description field
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxDisputeEvidenceSize+64<<10)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "a file field of at most 3MB is required",
+		})
+		return
+	}
+	if fileHeader.Size > service.MaxDisputeEvidenceSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   "evidence file exceeds 3MB",
+		})
+		return
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read file",
+		})
+		return
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, service.MaxDisputeEvidenceSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read file",
+		})
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(content)
+	}
+
+	resp, err := h.disputeService.UploadEvidenceFile(c.Request.Context(), &pb.UploadDisputeEvidenceRequest{
+		DisputeId:   c.Param("id"),
+		MerchantId:  merchantID.String(),
+		FileName:    fileHeader.Filename,
+		ContentType: contentType,
+		Description: c.PostForm("description"),
+		Content:     content,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if resp.Error != "" {
+		c.JSON(disputeErrorStatus(resp.Error), gin.H{
+			"success": false,
+			"error":   resp.Error,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    resp.File,
+	})
+}
+
+// GetEvidenceFile downloads an evidence document as uploaded
+// GET /api/v1/disputes/:id/evidence-files/:file_id
+func (h *DisputeHandler) GetEvidenceFile(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.disputeService.GetEvidenceFile(c.Request.Context(), &pb.GetDisputeEvidenceFileRequest{
+		DisputeId:  c.Param("id"),
+		MerchantId: merchantID.String(),
+		FileId:     c.Param("file_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if resp.Error != "" {
+		c.JSON(disputeErrorStatus(resp.Error), gin.H{
+			"success": false,
+			"error":   resp.Error,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resp.File.FileName}))
+	c.Header("X-Content-SHA256", resp.File.Sha256)
+	c.Data(http.StatusOK, resp.File.ContentType, resp.Content)
+}
+
+// SubmitEvidence contests the dispute. Uploaded evidence files are sent
+// along with the evidence fields and statement; the dispute then waits on
+// the issuer.
+// POST /api/v1/disputes/:id/evidence
+func (h *DisputeHandler) SubmitEvidence(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req SubmitDisputeEvidenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.disputeService.SubmitEvidence(c.Request.Context(), &pb.SubmitDisputeEvidenceRequest{
+		DisputeId:         c.Param("id"),
+		MerchantId:        merchantID.String(),
+		Evidence:          req.Evidence,
+		MerchantStatement: req.MerchantStatement,
+	})
+	h.respondDispute(c, resp, err)
+}
+
+// AcceptDispute concedes the dispute; the amount and fee are not contested
+// POST /api/v1/disputes/:id/accept
+func (h *DisputeHandler) AcceptDispute(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req AcceptDisputeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid request: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	resp, err := h.disputeService.AcceptDispute(c.Request.Context(), &pb.AcceptDisputeRequest{
+		DisputeId:  c.Param("id"),
+		MerchantId: merchantID.String(),
+		Reason:     req.Reason,
+	})
+	h.respondDispute(c, resp, err)
+}
+
+func (h *DisputeHandler) respondDispute(c *gin.Context, resp *pb.DisputeResponse, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if resp.Error != "" {
+		c.JSON(disputeErrorStatus(resp.Error), gin.H{
+			"success": false,
+			"error":   resp.Error,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resp,
+	})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type EventHandler struct {
+	eventLogService *service.EventLogService
+}
+
+func NewEventHandler() *EventHandler {
+	return &EventHandler{
+		eventLogService: service.NewEventLogService(),
+	}
+}
+
+// ListEvents returns the merchant's event log
+// GET /api/v1/events?type=&payment_id=&created_from=&created_to=&test_mode=&fields=&limit=&offset=
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	fields, ok := requireFields(c, &service.Event{})
+	if !ok {
+		return
+	}
+
+	filter := repository.EventFilter{Type: c.Query("type")}
+	if filter.Type != "" && !strings.HasPrefix(filter.Type, "payment.") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "type must be a payment event, e.g. payment.captured",
+		})
+		return
+	}
+
+	if value := c.Query("payment_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid payment_id",
+			})
+			return
+		}
+		filter.PaymentID = id
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTimeQuery(c, "created_from"); err == nil {
+		filter.CreatedTo, err = parseTimeQuery(c, "created_to")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if value := c.Query("test_mode"); value != "" {
+		testMode, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "test_mode must be true or false",
+			})
+			return
+		}
+		filter.TestMode = &testMode
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, err := h.eventLogService.ListEvents(c.Request.Context(), merchantID, filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list events",
+		})
+		return
+	}
+
+	respondList(c, events, "", fields)
+}
+
+// GetEvent returns one event with its full payload
+// GET /api/v1/events/:id
+func (h *EventHandler) GetEvent(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid event id",
+		})
+		return
+	}
+
+	event, err := h.eventLogService.GetEvent(c.Request.Context(), eventID, merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "event not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load event",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    event,
+	})
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxTimelineBundleSize bounds the body of a verification request
+const maxTimelineBundleSize = 32 << 20
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+type CreateExportRequest struct {
+	Resource model.ExportResource `json:"resource" binding:"required"`
+	Format   model.ExportFormat   `json:"format"`
+	DateFrom time.Time            `json:"date_from" binding:"required"`
+	DateTo   time.Time            `json:"date_to" binding:"required"`
+	Status   string               `json:"status"`
+}
+
+// CreateExport queues an asynchronous export of payments or transactions
+// POST /api/v1/exports
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	mc, ok := merchantctx.Get(c)
+	if !ok {
+		requireMerchantID(c)
+		return
+	}
+
+	createdBy := mc.APIKeyID
+	if createdBy == uuid.Nil {
+		createdBy = mc.UserID
+	}
+
+	format := req.Format
+	if format == "" {
+		format = model.ExportFormatCSV
+	}
+
+	export, err := h.exportService.CreateExport(&service.CreateExportRequest{
+		MerchantID: mc.MerchantID,
+		CreatedBy:  createdBy,
+		Resource:   req.Resource,
+		Format:     format,
+		DateFrom:   req.DateFrom,
+		DateTo:     req.DateTo,
+		Status:     req.Status,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+// CreateAccountExport queues a zip of all the merchant's data: payments,
+// transactions, settlements, disputes, token metadata, webhook
+// subscriptions and the merchant profile, team and settings. Poll
+// GET /api/v1/exports/:id for progress.
+// POST /api/v1/exports/account
+func (h *ExportHandler) CreateAccountExport(c *gin.Context) {
+	mc, ok := merchantctx.Get(c)
+	if !ok {
+		requireMerchantID(c)
+		return
+	}
+
+	createdBy := mc.APIKeyID
+	if createdBy == uuid.Nil {
+		createdBy = mc.UserID
+	}
+
+	export, err := h.exportService.CreateAccountExport(mc.MerchantID, createdBy)
+	if err != nil {
+		logger.Log.Error("Failed to queue account export", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to create export",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+// ListExports returns the merchant's recent exports
+// GET /api/v1/exports
+func (h *ExportHandler) ListExports(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	exports, err := h.exportService.ListExports(merchantID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list exports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    exports,
+	})
+}
+
+// GetExport returns an export's status and, once complete, a signed download URL
+// GET /api/v1/exports/:id
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid export id",
+		})
+		return
+	}
+
+	export, err := h.exportService.GetExport(exportID, merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "export not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load export",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+// ExportTransactionTimeline returns a signed bundle of everything recorded
+// about a payment for audit and regulator requests. Payments with many
+// records are bundled in the background: the response is then a 202 with an
+// export to poll at GET /api/v1/exports/:id.
+// POST /api/v1/payments/:id/timeline-export
+func (h *ExportHandler) ExportTransactionTimeline(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid payment ID",
+		})
+		return
+	}
+
+	mc, ok := merchantctx.Get(c)
+	if !ok {
+		requireMerchantID(c)
+		return
+	}
+
+	createdBy := mc.APIKeyID
+	if createdBy == uuid.Nil {
+		createdBy = mc.UserID
+	}
+
+	bundle, export, err := h.exportService.ExportTransactionTimeline(c.Request.Context(), paymentID, mc.MerchantID, createdBy)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   "payment not found",
+			})
+			return
+		}
+		logger.Log.Error("Transaction timeline export failed", zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   "failed to build transaction timeline: " + err.Error(),
+		})
+		return
+	}
+
+	if export != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"data":    export,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    bundle,
+	})
+}
+
+// VerifyTransactionTimeline checks that a timeline bundle is unchanged since
+// the gateway signed it. The body is the bundle as downloaded.
+// POST /api/v1/timeline-exports/verify
+func (h *ExportHandler) VerifyTransactionTimeline(c *gin.Context) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTimelineBundleSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read bundle",
+		})
+		return
+	}
+
+	valid, err := h.exportService.VerifyTransactionTimeline(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"valid": valid},
+	})
+}
+
+// DownloadExport streams a completed export; the signed query string is the credential
+// GET /api/public/exports/:id/download?expires=...&signature=...
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid export id",
+		})
+		return
+	}
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+
+	download, err := h.exportService.OpenDownload(c.Request.Context(), exportID, expires, c.Query("signature"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidDownloadToken):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrExportNotReady):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if download.RedirectURL != "" {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, download.RedirectURL)
+		return
+	}
+	defer download.Content.Close()
+
+	c.Header("Content-Type", download.Job.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+download.Job.FileName()+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, download.Content)
+}
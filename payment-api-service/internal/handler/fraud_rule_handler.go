@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type FraudRuleHandler struct {
+	ruleService *service.FraudRuleService
+}
+
+func NewFraudRuleHandler() *FraudRuleHandler {
+	return &FraudRuleHandler{
+		ruleService: service.NewFraudRuleService(),
+	}
+}
+
+// FraudRuleRequest is used for both create and update; omitted fields keep
+// their current value on update
+type FraudRuleRequest struct {
+	Name          *string   `json:"name"`
+	Type          *string   `json:"type"`
+	Action        *string   `json:"action"`
+	ReasonCode    *string   `json:"reason_code"`
+	Field         *string   `json:"field"`
+	MaxAttempts   *int      `json:"max_attempts"`
+	WindowSeconds *int      `json:"window_seconds"`
+	MinAmount     *int64    `json:"min_amount"`
+	MaxAmount     *int64    `json:"max_amount"`
+	Currency      *string   `json:"currency"`
+	MinScore      *int      `json:"min_score"`
+	Values        *[]string `json:"values"`
+	CountryMatch  *string   `json:"country_match"`
+	Enabled       *bool     `json:"enabled"`
+}
+
+func (r *FraudRuleRequest) input() *service.FraudRuleInput {
+	return &service.FraudRuleInput{
+		Name:          r.Name,
+		Type:          r.Type,
+		Action:        r.Action,
+		ReasonCode:    r.ReasonCode,
+		Field:         r.Field,
+		MaxAttempts:   r.MaxAttempts,
+		WindowSeconds: r.WindowSeconds,
+		MinAmount:     r.MinAmount,
+		MaxAmount:     r.MaxAmount,
+		Currency:      r.Currency,
+		MinScore:      r.MinScore,
+		Values:        r.Values,
+		CountryMatch:  r.CountryMatch,
+		Enabled:       r.Enabled,
+	}
+}
+
+// ListFraudRules returns the merchant's fraud rules
+// GET /api/v1/fraud/rules
+func (h *FraudRuleHandler) ListFraudRules(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.ruleService.ListRules(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load fraud rules",
+		})
+		return
+	}
+
+	data := make([]gin.H, 0, len(rules))
+	for i := range rules {
+		data = append(data, fraudRuleResponse(&rules[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// CreateFraudRule adds a fraud rule, enabled unless enabled is false
+// POST /api/v1/fraud/rules
+func (h *FraudRuleHandler) CreateFraudRule(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req FraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.ruleService.CreateRule(merchantID, actorID(c), req.input())
+	if err != nil {
+		respondFraudRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    fraudRuleResponse(rule),
+	})
+}
+
+// GetFraudRule returns one fraud rule
+// GET /api/v1/fraud/rules/:id
+func (h *FraudRuleHandler) GetFraudRule(c *gin.Context) {
+	merchantID, ruleID, ok := fraudRuleParams(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.ruleService.GetRule(ruleID, merchantID)
+	if err != nil {
+		respondFraudRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fraudRuleResponse(rule),
+	})
+}
+
+// UpdateFraudRule changes a fraud rule's parameters, action or state
+// PATCH /api/v1/fraud/rules/:id
+func (h *FraudRuleHandler) UpdateFraudRule(c *gin.Context) {
+	merchantID, ruleID, ok := fraudRuleParams(c)
+	if !ok {
+		return
+	}
+
+	var req FraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.ruleService.UpdateRule(ruleID, merchantID, req.input())
+	if err != nil {
+		respondFraudRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fraudRuleResponse(rule),
+	})
+}
+
+// DeleteFraudRule removes a fraud rule
+// DELETE /api/v1/fraud/rules/:id
+func (h *FraudRuleHandler) DeleteFraudRule(c *gin.Context) {
+	merchantID, ruleID, ok := fraudRuleParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(ruleID, merchantID); err != nil {
+		respondFraudRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+func fraudRuleParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	ruleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid fraud rule id",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, ruleID, true
+}
+
+func respondFraudRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "fraud rule not found",
+		})
+	case errors.Is(err, service.ErrInvalidFraudRule):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save fraud rule",
+		})
+	}
+}
+
+func fraudRuleResponse(r *model.FraudRule) gin.H {
+	data := gin.H{
+		"id":          r.ID,
+		"name":        r.Name,
+		"type":        r.Type,
+		"action":      r.Action,
+		"reason_code": r.ReasonCode,
+		"enabled":     r.Enabled,
+		"created_at":  r.CreatedAt,
+		"updated_at":  r.UpdatedAt,
+	}
+
+	switch r.Type {
+	case model.FraudRuleVelocity:
+		data["field"] = r.Field
+		data["max_attempts"] = r.MaxAttempts
+		data["window_seconds"] = r.WindowSeconds
+	case model.FraudRuleAmount:
+		data["min_amount"] = r.MinAmount
+		data["max_amount"] = r.MaxAmount
+		data["currency"] = r.Currency
+	case model.FraudRuleBINCountry:
+		data["values"] = r.ValueList()
+		data["country_match"] = r.CountryMatch
+	case model.FraudRuleRiskScore:
+		data["min_score"] = r.MinScore
+	case model.FraudRuleBlocklist, model.FraudRuleAllowlist:
+		data["field"] = r.Field
+		data["values"] = r.ValueList()
+	}
+	return data
+}
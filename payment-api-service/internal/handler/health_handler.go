@@ -26,7 +26,7 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 
 // ReadinessCheck handles GET /ready
 func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
 	defer cancel()
 
 	checks := map[string]bool{
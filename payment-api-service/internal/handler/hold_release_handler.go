@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type HoldReleaseHandler struct {
+	holdRelease *service.HoldReleaseService
+}
+
+func NewHoldReleaseHandler() *HoldReleaseHandler {
+	return &HoldReleaseHandler{
+		holdRelease: service.NewHoldReleaseService(),
+	}
+}
+
+type UpdateHoldReleaseSettingsRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type HoldReleaseTemplateRequest struct {
+	Subject string `json:"subject" binding:"required"`
+	Body    string `json:"body" binding:"required"`
+}
+
+// GetHoldReleaseSettings returns whether customers are emailed when a hold
+// is released, and the merchant's custom templates
+// GET /api/v1/hold-release-notifications
+func (h *HoldReleaseHandler) GetHoldReleaseSettings(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	settings, templates, err := h.holdRelease.GetSettings(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load hold release notifications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    holdReleaseResponse(settings, templates),
+	})
+}
+
+// UpdateHoldReleaseSettings turns hold release emails on or off
+// PUT /api/v1/hold-release-notifications
+func (h *HoldReleaseHandler) UpdateHoldReleaseSettings(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateHoldReleaseSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.holdRelease.UpdateSettings(merchantID, *req.Enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save hold release notifications",
+		})
+		return
+	}
+
+	h.GetHoldReleaseSettings(c)
+}
+
+// PutHoldReleaseTemplate sets the email sent in one language
+// PUT /api/v1/hold-release-notifications/templates/:language
+func (h *HoldReleaseHandler) PutHoldReleaseTemplate(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req HoldReleaseTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	tmpl, err := h.holdRelease.SaveTemplate(merchantID, actorID(c), c.Param("language"), req.Subject, req.Body)
+	if err != nil {
+		respondHoldReleaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tmpl,
+	})
+}
+
+// DeleteHoldReleaseTemplate goes back to the built-in email for a language
+// DELETE /api/v1/hold-release-notifications/templates/:language
+func (h *HoldReleaseHandler) DeleteHoldReleaseTemplate(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.holdRelease.DeleteTemplate(merchantID, c.Param("language")); err != nil {
+		respondHoldReleaseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+func respondHoldReleaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "no template for this language",
+		})
+	case errors.Is(err, service.ErrInvalidHoldReleaseTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save hold release template",
+		})
+	}
+}
+
+func holdReleaseResponse(settings *model.HoldReleaseSettings, templates []model.HoldReleaseTemplate) gin.H {
+	return gin.H{
+		"enabled":   settings.Enabled,
+		"templates": templates,
+	}
+}
@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+func NewInvoiceHandler(paymentService *service.PaymentService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: service.NewInvoiceService(service.NewPaymentIntentService(paymentService)),
+	}
+}
+
+// InvoiceRequest is the content of a draft, for creating or replacing it
+type InvoiceRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+	Customer struct {
+		Email string `json:"email" binding:"omitempty,email"`
+		Name  string `json:"name" binding:"max=255"`
+	} `json:"customer"`
+	DueDate      string               `json:"due_date"`       // YYYY-MM-DD
+	DaysUntilDue int                  `json:"days_until_due"` // used when due_date is empty; defaults to 30
+	LineItems    []InvoiceLineRequest `json:"line_items" binding:"required,min=1,max=100,dive"`
+	Memo         string               `json:"memo" binding:"max=2000"`
+	Language     string               `json:"language"` // en, fr or ar; empty uses the merchant's locale
+	SuccessURL   string               `json:"success_url" binding:"omitempty,url"`
+}
+
+type InvoiceLineRequest struct {
+	Description string `json:"description" binding:"required,max=500"`
+	Quantity    int64  `json:"quantity" binding:"required,min=1,max=10000"`
+	UnitAmount  int64  `json:"unit_amount" binding:"min=0,max=100000000"`
+	TaxRate     *struct {
+		Name       string  `json:"name" binding:"required,max=100"`
+		Percentage float64 `json:"percentage" binding:"min=0,max=100"`
+	} `json:"tax_rate"`
+}
+
+// CreateInvoice creates a draft invoice
+// POST /api/v1/invoices
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	input, ok := readInvoiceRequest(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.CreateInvoice(c.Request.Context(), input)
+	if err != nil {
+		respondInvoiceError(c, err, "failed to create invoice")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    invoice,
+	})
+}
+
+// UpdateInvoice replaces a draft's content
+// PUT /api/v1/invoices/:id
+func (h *InvoiceHandler) UpdateInvoice(c *gin.Context) {
+	invoiceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid invoice id",
+		})
+		return
+	}
+	input, ok := readInvoiceRequest(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.UpdateInvoice(c.Request.Context(), invoiceID, input)
+	if err != nil {
+		respondInvoiceError(c, err, "failed to update invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoice,
+	})
+}
+
+// DeleteInvoice deletes a draft
+// DELETE /api/v1/invoices/:id
+func (h *InvoiceHandler) DeleteInvoice(c *gin.Context) {
+	merchantID, invoiceID, ok := invoiceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.invoiceService.DeleteInvoice(c.Request.Context(), invoiceID, merchantID, isTestMode(c)); err != nil {
+		respondInvoiceError(c, err, "failed to delete invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// ListInvoices lists the merchant's invoices, newest first
+// GET /api/v1/invoices?status=&limit=20&offset=0
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	status := model.InvoiceStatus(c.Query("status"))
+	switch status {
+	case "", model.InvoiceStatusDraft, model.InvoiceStatusOpen, model.InvoiceStatusPaid, model.InvoiceStatusVoid, model.InvoiceStatusUncollectible:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "status must be 'draft', 'open', 'paid', 'void' or 'uncollectible'",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	invoices, err := h.invoiceService.ListInvoices(c.Request.Context(), merchantID, isTestMode(c), status, limit, offset)
+	if err != nil {
+		respondInvoiceError(c, err, "failed to list invoices")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoices,
+	})
+}
+
+// GetInvoice returns an invoice with its lines
+// GET /api/v1/invoices/:id
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	merchantID, invoiceID, ok := invoiceParams(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoice(c.Request.Context(), invoiceID, merchantID, isTestMode(c))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to get invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoice,
+	})
+}
+
+// FinalizeInvoice numbers a draft and opens it for payment
+// POST /api/v1/invoices/:id/finalize
+func (h *InvoiceHandler) FinalizeInvoice(c *gin.Context) {
+	h.transition(c, h.invoiceService.FinalizeInvoice, "failed to finalize invoice")
+}
+
+// SendInvoice emails an open invoice to its customer
+// POST /api/v1/invoices/:id/send
+func (h *InvoiceHandler) SendInvoice(c *gin.Context) {
+	h.transition(c, h.invoiceService.SendInvoice, "failed to send invoice")
+}
+
+// VoidInvoice cancels an open or uncollectible invoice
+// POST /api/v1/invoices/:id/void
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	h.transition(c, h.invoiceService.VoidInvoice, "failed to void invoice")
+}
+
+// MarkInvoiceUncollectible writes off an open invoice
+// POST /api/v1/invoices/:id/mark-uncollectible
+func (h *InvoiceHandler) MarkInvoiceUncollectible(c *gin.Context) {
+	h.transition(c, h.invoiceService.MarkUncollectible, "failed to mark invoice uncollectible")
+}
+
+func (h *InvoiceHandler) transition(c *gin.Context, change func(context.Context, uuid.UUID, uuid.UUID, bool) (*service.InvoiceResponse, error), fallback string) {
+	merchantID, invoiceID, ok := invoiceParams(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := change(c.Request.Context(), invoiceID, merchantID, isTestMode(c))
+	if err != nil {
+		respondInvoiceError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoice,
+	})
+}
+
+// GetInvoicePDF downloads an invoice as a PDF
+// GET /api/v1/invoices/:id/pdf
+func (h *InvoiceHandler) GetInvoicePDF(c *gin.Context) {
+	merchantID, invoiceID, ok := invoiceParams(c)
+	if !ok {
+		return
+	}
+
+	pdf, filename, err := h.invoiceService.RenderInvoicePDF(c.Request.Context(), invoiceID, merchantID, isTestMode(c))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to render invoice")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// =========================================================================
+// Hosted link (public, the token is the credential)
+// =========================================================================
+
+// GetHostedInvoice returns the customer's view of an invoice, for merchants
+// that render their own invoice page
+// GET /api/public/invoices/:token?language=
+func (h *InvoiceHandler) GetHostedInvoice(c *gin.Context) {
+	language, ok := requireLanguage(c, c.Query("language"))
+	if !ok {
+		return
+	}
+
+	doc, err := h.invoiceService.GetHostedInvoice(c.Request.Context(), c.Param("token"), language, c.GetHeader("Accept-Language"))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to get invoice")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    doc,
+	})
+}
+
+// GetHostedInvoicePDF downloads the invoice behind a hosted link
+// GET /api/public/invoices/:token/pdf
+func (h *InvoiceHandler) GetHostedInvoicePDF(c *gin.Context) {
+	pdf, filename, err := h.invoiceService.RenderHostedInvoicePDF(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to render invoice")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// PayHostedInvoice sends the customer to the hosted checkout for the
+// invoice. It answers with JSON when the invoice cannot be paid.
+// GET /api/public/invoices/:token/pay
+func (h *InvoiceHandler) PayHostedInvoice(c *gin.Context) {
+	checkoutURL, err := h.invoiceService.PayHostedInvoice(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		respondInvoiceError(c, err, "failed to open checkout")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, checkoutURL)
+}
+
+// readInvoiceRequest reads a create or replace request into the service's
+// input, writing a 400 if it is invalid
+func readInvoiceRequest(c *gin.Context) (*service.InvoiceInput, bool) {
+	var req InvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return nil, false
+	}
+	language, ok := requireLanguage(c, req.Language)
+	if !ok {
+		return nil, false
+	}
+
+	input := &service.InvoiceInput{
+		MerchantID:    merchantID,
+		TestMode:      isTestMode(c),
+		Currency:      req.Currency,
+		CustomerEmail: req.Customer.Email,
+		CustomerName:  req.Customer.Name,
+		DaysUntilDue:  req.DaysUntilDue,
+		Memo:          req.Memo,
+		Language:      language,
+		SuccessURL:    req.SuccessURL,
+	}
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid due_date, expected YYYY-MM-DD",
+			})
+			return nil, false
+		}
+		input.DueDate = dueDate
+	}
+	for _, line := range req.LineItems {
+		item := service.InvoiceLineItemInput{
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitAmount:  line.UnitAmount,
+		}
+		if line.TaxRate != nil {
+			item.TaxRate = &service.CheckoutTaxRateInput{
+				Name:       line.TaxRate.Name,
+				Percentage: line.TaxRate.Percentage,
+			}
+		}
+		input.LineItems = append(input.LineItems, item)
+	}
+	return input, true
+}
+
+func invoiceParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid invoice id",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, id, true
+}
+
+func respondInvoiceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "invoice not found",
+		})
+	case errors.Is(err, service.ErrInvalidInvoice):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrInvoiceStatus), errors.Is(err, service.ErrInvoicePaymentPending):
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrInvoiceEmailDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrMerchantNotAcceptingPayments):
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		logger.Log.Error(fallback, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fallback,
+		})
+	}
+}
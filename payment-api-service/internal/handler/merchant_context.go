@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
+)
+
+// requireMerchantID returns the authenticated merchant, writing a 401 if the
+// request has no merchant context
+func requireMerchantID(c *gin.Context) (uuid.UUID, bool) {
+	mc, ok := merchantctx.Get(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "invalid merchant context",
+		})
+		return uuid.Nil, false
+	}
+	return mc.MerchantID, true
+}
+
+// actorID returns the user the request acts as, for audit records: the API
+// key's owner, or uuid.Nil if it is unknown
+func actorID(c *gin.Context) uuid.UUID {
+	if mc, ok := merchantctx.Get(c); ok {
+		return mc.ActorID
+	}
+	return uuid.Nil
+}
+
+// isTestMode reports whether the request was made with a sandbox API key
+func isTestMode(c *gin.Context) bool {
+	mc, ok := merchantctx.Get(c)
+	return ok && mc.TestMode
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// MerchantLifecycleHandler serves the internal endpoints the merchant
+// service's offboarding workflow drives
+type MerchantLifecycleHandler struct {
+	lifecycleService *service.MerchantLifecycleService
+	exportService    *service.ExportService
+}
+
+func NewMerchantLifecycleHandler(exportService *service.ExportService) *MerchantLifecycleHandler {
+	return &MerchantLifecycleHandler{
+		lifecycleService: service.NewMerchantLifecycleService(),
+		exportService:    exportService,
+	}
+}
+
+type SetLifecycleRequest struct {
+	Status       model.MerchantLifecycleStatus `json:"status" binding:"required"`
+	RefundsUntil *time.Time                    `json:"refunds_until"`
+}
+
+// GetLifecycle returns whether a merchant can still take payments and refunds
+// GET /internal/v1/merchants/:merchant_id/lifecycle
+func (h *MerchantLifecycleHandler) GetLifecycle(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+
+	lifecycle, err := h.lifecycleService.GetLifecycle(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load merchant lifecycle",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lifecycle,
+	})
+}
+
+// SetLifecycle stops or resumes payments and sets the refund window
+// PUT /internal/v1/merchants/:merchant_id/lifecycle
+func (h *MerchantLifecycleHandler) SetLifecycle(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req SetLifecycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	lifecycle, err := h.lifecycleService.SetLifecycle(merchantID, req.Status, req.RefundsUntil)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidLifecycle) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lifecycle,
+	})
+}
+
+// CreateFinalExports queues exports of the merchant's full history
+// POST /internal/v1/merchants/:merchant_id/final-exports
+func (h *MerchantLifecycleHandler) CreateFinalExports(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+
+	exports, err := h.exportService.CreateFinalExports(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to queue final exports",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to queue final exports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    exports,
+	})
+}
+
+// GetExport returns an export job with a signed download URL once complete
+// GET /internal/v1/merchants/:merchant_id/exports/:id
+func (h *MerchantLifecycleHandler) GetExport(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid export ID",
+		})
+		return
+	}
+
+	export, err := h.exportService.GetExport(exportID, merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "export not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    export,
+	})
+}
+
+func internalMerchantID(c *gin.Context) (uuid.UUID, bool) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return uuid.Nil, false
+	}
+	return merchantID, true
+}
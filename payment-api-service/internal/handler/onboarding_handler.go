@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// OnboardingHandler serves the merchant service's onboarding checklist
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+func NewOnboardingHandler() *OnboardingHandler {
+	return &OnboardingHandler{
+		onboardingService: service.NewOnboardingService(),
+	}
+}
+
+// GetOnboardingProgress returns the merchant's first payments and whether
+// it has a webhook
+// GET /internal/v1/merchants/:merchant_id/onboarding
+func (h *OnboardingHandler) GetOnboardingProgress(c *gin.Context) {
+	merchantID, ok := internalMerchantID(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.onboardingService.GetProgress(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load onboarding progress", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load onboarding progress",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    progress,
+	})
+}
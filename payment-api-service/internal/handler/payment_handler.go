@@ -1,29 +1,41 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type PaymentHandler struct {
-	paymentService *service.PaymentService
-	webhookService *service.WebhookService
+	paymentService  *service.PaymentService
+	webhookService  *service.WebhookService
+	receiptService  *service.ReceiptService
+	refundApprovals *service.RefundApprovalService
+	idempotency     *service.IdempotencyService
 }
 
-func NewPaymentHandler() (*PaymentHandler, error) {
+func NewPaymentHandler(refundApprovals *service.RefundApprovalService) (*PaymentHandler, error) {
 	paymentService, err := service.NewPaymentService()
 	if err != nil {
 		return nil, err
 	}
 
 	return &PaymentHandler{
-		paymentService: paymentService,
-		webhookService: service.NewWebhookService(),
+		paymentService:  paymentService,
+		webhookService:  service.NewWebhookService(),
+		receiptService:  service.NewReceiptService(),
+		refundApprovals: refundApprovals,
+		idempotency:     service.NewIdempotencyService(),
 	}, nil
 }
 
@@ -43,14 +55,31 @@ type CustomerRequest struct {
 type AuthorizeRequest struct {
 	Amount      int64                  `json:"amount" binding:"required,min=1"`
 	Currency    string                 `json:"currency" binding:"required,len=3"`
-	Card        CardRequest            `json:"card" binding:"required"`
+	Card        *CardRequest           `json:"card" binding:"required_without=CardToken"`
+	CardToken   string                 `json:"card_token"` // A stored card instead of card, e.g. one seeded by /test/seed-vault
 	Customer    CustomerRequest        `json:"customer"`
 	Description string                 `json:"description"`
 	Metadata    map[string]interface{} `json:"metadata"`
+	Language    string                 `json:"language"` // receipt language: en, fr or ar
+
+	// Authenticate the cardholder with 3-D Secure before authorizing
+	ThreeDSecure *ThreeDSecureRequest `json:"three_d_secure"`
+}
+
+type ThreeDSecureRequest struct {
+	ReturnURL string `json:"return_url" binding:"required,url"`
+}
+
+type AuthenticateRequest struct {
+	CRes string `json:"cres" binding:"required"` // Challenge response the ACS posted to return_url
 }
 
 type CaptureRequest struct {
-	Amount int64 `json:"amount" binding:"required,min=1"`
+	Amount   int64  `json:"amount" binding:"required,min=1"`
+	Currency string `json:"currency" binding:"required,len=3"` // Must be the payment's currency
+
+	// Multi-capture merchants: close the authorization after this capture
+	FinalCapture bool `json:"final_capture"`
 }
 
 type VoidRequest struct {
@@ -58,8 +87,13 @@ type VoidRequest struct {
 }
 
 type RefundRequest struct {
-	Amount int64  `json:"amount" binding:"required,min=1"`
-	Reason string `json:"reason" binding:"required"`
+	Amount   int64  `json:"amount" binding:"required,min=1"`
+	Currency string `json:"currency" binding:"required,len=3"` // Must be the payment's currency
+	Reason   string `json:"reason" binding:"required"`
+
+	// duplicate, fraudulent, requested_by_customer, product_not_received,
+	// product_unacceptable or other (the default)
+	ReasonCode string `json:"reason_code"`
 }
 
 // =========================================================================
@@ -86,13 +120,13 @@ func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
 	}
 
 	// Get merchant ID from auth middleware
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, err := uuid.Parse(merchantIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "invalid merchant context",
-		})
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	language, ok := requireLanguage(c, req.Language)
+	if !ok {
 		return
 	}
 
@@ -104,11 +138,6 @@ func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
 		MerchantID:     merchantID,
 		Amount:         req.Amount,
 		Currency:       req.Currency,
-		CardNumber:     req.Card.Number,
-		CardholderName: req.Card.CardholderName,
-		ExpMonth:       req.Card.ExpMonth,
-		ExpYear:        req.Card.ExpYear,
-		CVV:            req.Card.CVV,
 		CustomerEmail:  req.Customer.Email,
 		CustomerName:   req.Customer.Name,
 		Description:    req.Description,
@@ -116,6 +145,14 @@ func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
 		IdempotencyKey: idempotencyKey,
 		IPAddress:      c.ClientIP(),
 		UserAgent:      c.Request.UserAgent(),
+		TestMode:       isTestMode(c),
+		CreatedBy:      actorID(c),
+		Language:       language,
+		ThreeDSecure:   threeDSecureRequest(req.ThreeDSecure),
+	}
+
+	if !h.applyCardSource(c, &req, serviceReq) {
+		return
 	}
 
 	// Process authorization
@@ -126,18 +163,14 @@ func (h *PaymentHandler) AuthorizePayment(c *gin.Context) {
 			zap.String("merchant_id", merchantID.String()),
 		)
 
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(paymentErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
 		return
 	}
 
-	// TODO: Send webhook (if configured)
-	// webhookURL := getMerchantWebhookURL(merchantID)
-	// if webhookURL != "" {
-	//     h.webhookService.SendPaymentWebhook(...)
-	// }
+	h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, response.ID, service.GetWebhookEventType(response.Status))
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -159,8 +192,15 @@ func (h *PaymentHandler) SalePayment(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	language, ok := requireLanguage(c, req.Language)
+	if !ok {
+		return
+	}
 
 	idempotencyKey := c.GetHeader("Idempotency-Key")
 
@@ -168,11 +208,6 @@ func (h *PaymentHandler) SalePayment(c *gin.Context) {
 		MerchantID:     merchantID,
 		Amount:         req.Amount,
 		Currency:       req.Currency,
-		CardNumber:     req.Card.Number,
-		CardholderName: req.Card.CardholderName,
-		ExpMonth:       req.Card.ExpMonth,
-		ExpYear:        req.Card.ExpYear,
-		CVV:            req.Card.CVV,
 		CustomerEmail:  req.Customer.Email,
 		CustomerName:   req.Customer.Name,
 		Description:    req.Description,
@@ -180,19 +215,32 @@ func (h *PaymentHandler) SalePayment(c *gin.Context) {
 		IdempotencyKey: idempotencyKey,
 		IPAddress:      c.ClientIP(),
 		UserAgent:      c.Request.UserAgent(),
+		TestMode:       isTestMode(c),
+		CreatedBy:      actorID(c),
+		Language:       language,
+		ThreeDSecure:   threeDSecureRequest(req.ThreeDSecure),
+	}
+
+	if !h.applyCardSource(c, &req, serviceReq) {
+		return
 	}
 
 	// Process sale (authorize + capture)
 	response, err := h.paymentService.SalePayment(c.Request.Context(), serviceReq)
 	if err != nil {
 		logger.Log.Error("Sale failed", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(paymentErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
 		return
 	}
 
+	h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, response.ID, service.GetWebhookEventType(response.Status))
+	if response.Status == model.PaymentStatusCaptured {
+		go h.receiptService.SendReceiptEmail(response.ID, merchantID)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
@@ -200,10 +248,13 @@ func (h *PaymentHandler) SalePayment(c *gin.Context) {
 }
 
 // =========================================================================
-// POST /v1/payments/:id/capture
+// POST /v1/payments/:id/authenticate
 // =========================================================================
 
-func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+// AuthenticatePayment completes a 3-D Secure challenge. The merchant posts
+// the CRes it received on its return URL; the payment is then authorized,
+// or captured if it was a sale.
+func (h *PaymentHandler) AuthenticatePayment(c *gin.Context) {
 	paymentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -213,8 +264,8 @@ func (h *PaymentHandler) CapturePayment(c *gin.Context) {
 		return
 	}
 
-	var req CaptureRequest
-	if err = c.ShouldBindJSON(&req); err != nil {
+	var req AuthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "invalid request: " + err.Error(),
@@ -222,25 +273,86 @@ func (h *PaymentHandler) CapturePayment(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
 
-	response, err := h.paymentService.CapturePayment(c.Request.Context(), paymentID, merchantID, req.Amount)
+	response, err := h.paymentService.AuthenticatePayment(c.Request.Context(), paymentID, merchantID, actorID(c), req.CRes)
 	if err != nil {
-		logger.Log.Error("Capture failed", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
+		logger.Log.Error("3-D Secure authentication failed", zap.Error(err))
+		status := paymentErrorStatus(err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
 		return
 	}
 
+	h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, response.ID, service.GetWebhookEventType(response.Status))
+	if response.Status == model.PaymentStatusCaptured {
+		go h.receiptService.SendReceiptEmail(response.ID, merchantID)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    response,
 	})
 }
 
+// =========================================================================
+// POST /v1/payments/:id/capture
+// =========================================================================
+
+func (h *PaymentHandler) CapturePayment(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid payment ID",
+		})
+		return
+	}
+
+	var req CaptureRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.idempotency.Do(c.Request.Context(), idempotentRequest(c, merchantID, "capture", paymentID, req), func() (int, interface{}, error) {
+		response, err := h.paymentService.CapturePayment(c.Request.Context(), paymentID, merchantID, actorID(c), req.Amount, req.Currency, req.FinalCapture)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, paymentID, service.WebhookEventPaymentCaptured)
+		// The receipt goes out once, when the authorization is closed
+		if response.Status == model.PaymentStatusCaptured {
+			go h.receiptService.SendReceiptEmail(paymentID, merchantID)
+		}
+		return http.StatusOK, response, nil
+	})
+	if err != nil {
+		logger.Log.Error("Capture failed", zap.Error(err))
+		c.JSON(paymentErrorStatus(err), paymentErrorBody(err))
+		return
+	}
+
+	respondIdempotent(c, result)
+}
+
 // =========================================================================
 // POST /v1/payments/:id/void
 // =========================================================================
@@ -264,23 +376,30 @@ func (h *PaymentHandler) VoidPayment(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.idempotency.Do(c.Request.Context(), idempotentRequest(c, merchantID, "void", paymentID, req), func() (int, interface{}, error) {
+		response, err := h.paymentService.VoidPayment(c.Request.Context(), paymentID, merchantID, actorID(c), req.Reason)
+		if err != nil {
+			return 0, nil, err
+		}
 
-	response, err := h.paymentService.VoidPayment(c.Request.Context(), paymentID, merchantID, req.Reason)
+		h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, paymentID, service.WebhookEventPaymentVoided)
+		return http.StatusOK, response, nil
+	})
 	if err != nil {
 		logger.Log.Error("Void failed", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(paymentErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    response,
-	})
+	respondIdempotent(c, result)
 }
 
 // =========================================================================
@@ -306,13 +425,65 @@ func (h *PaymentHandler) RefundPayment(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.idempotency.Do(c.Request.Context(), idempotentRequest(c, merchantID, "refund", paymentID, req), func() (int, interface{}, error) {
+		// Refunds at or above the merchant's threshold wait for a second approver
+		approval, err := h.refundApprovals.RequestIfRequired(c.Request.Context(), paymentID, merchantID, actorID(c), req.Amount, req.Currency, req.Reason, req.ReasonCode)
+		if err != nil {
+			return 0, nil, err
+		}
+		if approval != nil {
+			return http.StatusAccepted, approval, nil
+		}
+
+		response, err := h.paymentService.RefundPayment(c.Request.Context(), paymentID, merchantID, actorID(c), req.Amount, req.Currency, req.Reason, req.ReasonCode)
+		if err != nil {
+			logger.Log.Error("Refund failed", zap.Error(err))
+			return 0, nil, err
+		}
+
+		h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, paymentID, service.WebhookEventPaymentRefunded)
+		go h.receiptService.SendRefundReceiptEmail(paymentID, merchantID, response.Refund)
+		// A queued refund is accepted but not yet sent; poll GET /refunds/:id
+		if response.Refund != nil && response.Refund.Status == "queued" {
+			return http.StatusAccepted, response, nil
+		}
+		return http.StatusOK, response, nil
+	})
+	if err != nil {
+		c.JSON(refundApprovalErrorStatus(err), paymentErrorBody(err))
+		return
+	}
+
+	respondIdempotent(c, result)
+}
+
+// =========================================================================
+// GET /v1/payments/:id/refunds
+// =========================================================================
 
-	response, err := h.paymentService.RefundPayment(c.Request.Context(), paymentID, merchantID, req.Amount, req.Reason)
+func (h *PaymentHandler) ListPaymentRefunds(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		logger.Log.Error("Refund failed", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid payment ID",
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.paymentService.ListPaymentRefunds(c.Request.Context(), paymentID, merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -321,10 +492,209 @@ func (h *PaymentHandler) RefundPayment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    response,
+		"data":    refunds,
 	})
 }
 
+// =========================================================================
+// GET /v1/refunds
+// =========================================================================
+
+// ListRefunds pages through the merchant's refunds across all payments.
+// Filters: payment_id, status, reason_code, date_from and date_to (RFC 3339).
+func (h *PaymentHandler) ListRefunds(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	fields, ok := requireFields(c, &service.RefundDetails{})
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	filter := service.RefundListFilter{
+		Status:     c.Query("status"),
+		ReasonCode: c.Query("reason_code"),
+		TestMode:   isTestMode(c),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	if v := c.Query("payment_id"); v != "" {
+		paymentID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid payment_id",
+			})
+			return
+		}
+		filter.PaymentID = &paymentID
+	}
+	var err error
+	if filter.DateFrom, err = parseTimeQuery(c, "date_from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if filter.DateTo, err = parseTimeQuery(c, "date_to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	refunds, err := h.paymentService.ListRefunds(c.Request.Context(), merchantID, filter)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrInvalidRefundReasonCode):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	respondList(c, refunds, "refunds", fields)
+}
+
+// =========================================================================
+// GET /v1/refunds/:id
+// =========================================================================
+
+func (h *PaymentHandler) GetRefund(c *gin.Context) {
+	refundID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid refund ID",
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	refund, err := h.paymentService.GetRefund(c.Request.Context(), refundID, merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "refund not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    refund,
+	})
+}
+
+// =========================================================================
+// GET /v1/refunds/queue
+// =========================================================================
+
+func (h *PaymentHandler) GetRefundQueueStatus(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.paymentService.GetRefundQueueStatus(c.Request.Context(), merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load refund queue status", zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   "failed to load refund queue status",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// =========================================================================
+// GET /v1/payments
+// =========================================================================
+
+// ListPayments pages through the merchant's payments, newest first, with
+// cursor pagination: pass next_cursor back as cursor for the next page.
+// Filters: status, date_from and date_to (RFC 3339 or YYYY-MM-DD; a bare
+// date_to includes that whole day).
+func (h *PaymentHandler) ListPayments(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	fields, ok := requireFields(c, &service.PaymentResponse{})
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	filter := service.PaymentListFilter{
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	}
+	var err error
+	if filter.DateFrom, err = parseDateQuery(c, "date_from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if filter.DateTo, err = parseDateQuery(c, "date_to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	payments, err := h.paymentService.ListPayments(c.Request.Context(), merchantID, filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidPaymentStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		logger.Log.Error("Failed to list payments", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list payments",
+		})
+		return
+	}
+
+	respondList(c, payments, "payments", fields)
+}
+
 // =========================================================================
 // GET /v1/payments/:id
 // =========================================================================
@@ -339,10 +709,12 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
 
-	payment, err := h.paymentService.GetPayment(paymentID, merchantID)
+	payment, err := h.paymentService.GetPayment(c.Request.Context(), paymentID, merchantID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
@@ -356,3 +728,221 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		"data":    payment,
 	})
 }
+
+// GetPaymentDetail returns the payment with its transaction, card token,
+// fraud decision, webhook deliveries and disputes, for detail pages
+// GET /api/v1/payments/:id/full
+func (h *PaymentHandler) GetPaymentDetail(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid payment ID",
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.paymentService.GetPaymentDetail(c.Request.Context(), paymentID, merchantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "payment not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    detail,
+	})
+}
+
+// GetReceipt renders a payment's receipt in the customer's language
+// GET /api/v1/payments/:id/receipt?language=ar&format=html
+func (h *PaymentHandler) GetReceipt(c *gin.Context) {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid payment ID",
+		})
+		return
+	}
+
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	language, ok := requireLanguage(c, c.Query("language"))
+	if !ok {
+		return
+	}
+
+	receipt, err := h.receiptService.GetReceipt(paymentID, merchantID, language)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "payment not found",
+		})
+		return
+	}
+
+	if c.Query("format") == "html" {
+		page, err := h.receiptService.RenderHTML(receipt)
+		if err != nil {
+			logger.Log.Error("Failed to render receipt", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "failed to render receipt",
+			})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    receipt,
+	})
+}
+
+func threeDSecureRequest(req *ThreeDSecureRequest) *service.ThreeDSecureRequest {
+	if req == nil {
+		return nil
+	}
+	return &service.ThreeDSecureRequest{ReturnURL: req.ReturnURL}
+}
+
+// paymentErrorStatus maps authorization errors to an HTTP status
+// applyCardSource fills in either the posted card or the stored card behind
+// card_token, writing a 400 if neither or both are given or the token is
+// unusable
+func (h *PaymentHandler) applyCardSource(c *gin.Context, req *AuthorizeRequest, serviceReq *service.AuthorizePaymentRequest) bool {
+	if req.Card != nil && req.CardToken != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "send either card or card_token, not both",
+		})
+		return false
+	}
+
+	if req.Card != nil {
+		serviceReq.CardNumber = req.Card.Number
+		serviceReq.CardholderName = req.Card.CardholderName
+		serviceReq.ExpMonth = req.Card.ExpMonth
+		serviceReq.ExpYear = req.Card.ExpYear
+		serviceReq.CVV = req.Card.CVV
+		return true
+	}
+
+	card, err := h.paymentService.ResolveCardToken(c.Request.Context(), serviceReq.MerchantID, req.CardToken, serviceReq.TestMode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	serviceReq.StoredCard = card
+	return true
+}
+
+// idempotentRequest describes a capture, void or refund for the
+// idempotency layer, keyed by the request's Idempotency-Key header
+func idempotentRequest(c *gin.Context, merchantID uuid.UUID, operation string, paymentID uuid.UUID, params interface{}) *service.IdempotentRequest {
+	return &service.IdempotentRequest{
+		MerchantID: merchantID,
+		TestMode:   isTestMode(c),
+		Key:        c.GetHeader("Idempotency-Key"),
+		Operation:  operation,
+		PaymentID:  paymentID,
+		Params:     params,
+	}
+}
+
+// respondIdempotent writes an operation's response, flagging replays of an
+// earlier request with the Idempotent-Replayed header
+func respondIdempotent(c *gin.Context, result *service.IdempotentResult) {
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(result.StatusCode, gin.H{
+		"success": true,
+		"data":    result.Data,
+	})
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrCardTestingThrottled):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrMerchantNotAcceptingPayments), errors.Is(err, service.ErrRefundWindowClosed):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrIdempotencyKeyReused), errors.Is(err, service.ErrIdempotentRequestInProgress),
+		errors.Is(err, service.ErrPaymentNotAwaitingAuthentication):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrCurrencyRequired), errors.Is(err, service.ErrCurrencyMismatch),
+		errors.Is(err, service.ErrCardBrandNotAccepted):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+// parseTimeQuery reads an optional RFC 3339 query parameter
+func parseTimeQuery(c *gin.Context, param string) (*time.Time, error) {
+	v := c.Query(param)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected RFC 3339", param)
+	}
+	return &t, nil
+}
+
+// parseDateQuery is parseTimeQuery that also takes a bare date. The date
+// stands for its start, or with endOfDay for the start of the next day, so
+// it can bound a half-open range.
+func parseDateQuery(c *gin.Context, param string, endOfDay bool) (*time.Time, error) {
+	v := c.Query(param)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected RFC 3339 or YYYY-MM-DD", param)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// paymentErrorBody adds a machine-readable code for errors clients are
+// expected to handle
+func paymentErrorBody(err error) gin.H {
+	body := gin.H{
+		"success": false,
+		"error":   err.Error(),
+	}
+	switch {
+	case errors.Is(err, service.ErrCurrencyRequired):
+		body["code"] = "currency_required"
+	case errors.Is(err, service.ErrCurrencyMismatch):
+		body["code"] = "currency_mismatch"
+	case errors.Is(err, service.ErrCardBrandNotAccepted):
+		body["code"] = "card_brand_not_accepted"
+	}
+	return body
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
 	"go.uber.org/zap"
@@ -35,6 +36,7 @@ type CreateIntentRequest struct {
 	CancelURL     string                 `json:"cancel_url" binding:"omitempty,url"`
 	CustomerEmail string                 `json:"customer_email" binding:"omitempty,email"`
 	Metadata      map[string]interface{} `json:"metadata"`
+	Language      string                 `json:"language"` // en, fr or ar; empty uses the merchant's locale
 }
 
 type ConfirmIntentRequest struct {
@@ -45,7 +47,10 @@ type ConfirmIntentRequest struct {
 		ExpYear        int    `json:"exp_year" binding:"required,min=2024"`
 		CVV            string `json:"cvv" binding:"required,min=3,max=4"`
 	} `json:"card" binding:"required"`
-	CustomerEmail string `json:"customer_email" binding:"omitempty,email"`
+	CustomerEmail  string `json:"customer_email" binding:"omitempty,email"`
+	CaptchaToken   string `json:"captcha_token"`
+	Language       string `json:"language"`        // language the customer picked on the checkout page
+	ShippingOption string `json:"shipping_option"` // checkout sessions: code of the shipping option picked
 }
 
 // =========================================================================
@@ -63,13 +68,13 @@ func (h *PaymentIntentHandler) CreatePaymentIntent(c *gin.Context) {
 	}
 
 	// Get merchant ID from auth middleware
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, err := uuid.Parse(merchantIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "invalid merchant context",
-		})
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	language, ok := requireLanguage(c, req.Language)
+	if !ok {
 		return
 	}
 
@@ -85,6 +90,8 @@ func (h *PaymentIntentHandler) CreatePaymentIntent(c *gin.Context) {
 		CancelURL:     req.CancelURL,
 		CustomerEmail: req.CustomerEmail,
 		Metadata:      req.Metadata,
+		TestMode:      isTestMode(c),
+		Language:      language,
 	}
 
 	response, err := h.intentService.CreatePaymentIntent(c.Request.Context(), serviceReq)
@@ -93,7 +100,7 @@ func (h *PaymentIntentHandler) CreatePaymentIntent(c *gin.Context) {
 			zap.Error(err),
 			zap.String("merchant_id", merchantID.String()),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(paymentErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -120,7 +127,7 @@ func (h *PaymentIntentHandler) GetPaymentIntent(c *gin.Context) {
 		return
 	}
 
-	response, err := h.intentService.GetPaymentIntent(c.Request.Context(), intentID)
+	response, err := h.intentService.GetPaymentIntent(c.Request.Context(), intentID, c.GetHeader("Accept-Language"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
@@ -133,13 +140,23 @@ func (h *PaymentIntentHandler) GetPaymentIntent(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"id":          response.ID,
-			"status":      response.Status,
-			"amount":      response.Amount,
-			"currency":    response.Currency,
-			"success_url": response.SuccessURL,
-			"cancel_url":  response.CancelURL,
-			"expires_at":  response.ExpiresAt,
+			"id":             response.ID,
+			"status":         response.Status,
+			"amount":         response.Amount,
+			"currency":       response.Currency,
+			"success_url":    response.SuccessURL,
+			"cancel_url":     response.CancelURL,
+			"expires_at":     response.ExpiresAt,
+			"test_mode":      response.TestMode,
+			"amount_display": response.AmountDisplay,
+			"language":       response.Language,
+			"direction":      response.Direction,
+			"messages":       response.Messages,
+
+			"accepted_brands":      response.AcceptedBrands,
+			"surcharge_disclosure": response.SurchargeDisclosure,
+
+			"checkout_session": response.CheckoutSession,
 		},
 	})
 }
@@ -186,6 +203,13 @@ func (h *PaymentIntentHandler) ConfirmPaymentIntent(c *gin.Context) {
 		CustomerEmail:   req.CustomerEmail,
 		IPAddress:       c.ClientIP(),
 		UserAgent:       c.Request.UserAgent(),
+		Origin:          requestOrigin(c),
+		CaptchaToken:    req.CaptchaToken,
+		ShippingOption:  req.ShippingOption,
+	}
+	// A language the checkout does not offer is ignored, not an error
+	if language, ok := i18n.Parse(req.Language); ok {
+		serviceReq.Language = language
 	}
 
 	response, err := h.intentService.ConfirmPaymentIntent(c.Request.Context(), serviceReq)
@@ -194,12 +218,16 @@ func (h *PaymentIntentHandler) ConfirmPaymentIntent(c *gin.Context) {
 		// Check if it's a PaymentIntentError
 		if piErr, ok := err.(*service.PaymentIntentError); ok {
 			statusCode := getStatusCodeFromError(piErr.Code)
+			message := piErr.Message
+			if id := "checkout.error." + piErr.Code; i18n.Has(id) {
+				message = i18n.T(i18n.Match(serviceReq.Language, c.GetHeader("Accept-Language")), id, nil)
+			}
 
 			errorResponse := gin.H{
 				"success": false,
 				"error": gin.H{
 					"code":    piErr.Code,
-					"message": piErr.Message,
+					"message": message,
 				},
 			}
 
@@ -244,10 +272,12 @@ func (h *PaymentIntentHandler) CancelPaymentIntent(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, _ := uuid.Parse(merchantIDStr.(string))
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
 
-	err = h.intentService.CancelPaymentIntent(c.Request.Context(), intentID, merchantID)
+	err = h.intentService.CancelPaymentIntent(c.Request.Context(), intentID, merchantID, actorID(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -266,11 +296,56 @@ func getStatusCodeFromError(errorCode string) int {
 	switch errorCode {
 	case "INVALID_CLIENT_SECRET", "INVALID_INTENT_ID":
 		return http.StatusUnauthorized
-	case "INTENT_EXPIRED", "MAX_ATTEMPTS_REACHED":
+	case "INTENT_EXPIRED", "MAX_ATTEMPTS_REACHED", "TOO_MANY_CARDS":
 		return http.StatusGone
 	case "PAYMENT_FAILED", "PAYMENT_DECLINED":
 		return http.StatusPaymentRequired
+	case "TOO_MANY_ATTEMPTS":
+		return http.StatusTooManyRequests
+	case "ORIGIN_NOT_ALLOWED", "CAPTCHA_REQUIRED", "CAPTCHA_FAILED":
+		return http.StatusForbidden
 	default:
 		return http.StatusBadRequest
 	}
 }
+
+// requireLanguage validates an optional language override, writing a 400
+// when it names a language receipts and checkout are not translated into
+func requireLanguage(c *gin.Context, value string) (string, bool) {
+	if value == "" {
+		return "", true
+	}
+	language, ok := i18n.Parse(value)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "unsupported language (en, fr and ar are supported)",
+		})
+		return "", false
+	}
+	return language, true
+}
+
+// IsCheckoutOriginAllowed reports whether origin may call the public
+// endpoints of the intent in the path. Unknown intents are refused; the
+// handler reports them as not found.
+func (h *PaymentIntentHandler) IsCheckoutOriginAllowed(c *gin.Context, origin string) bool {
+	intentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return false
+	}
+	allowed, err := h.intentService.IsCheckoutOriginAllowed(intentID, origin)
+	if err != nil {
+		return false
+	}
+	return allowed
+}
+
+// requestOrigin returns the browser origin of the checkout page, falling
+// back to the Referer when the Origin header is absent
+func requestOrigin(c *gin.Context) string {
+	if origin := c.GetHeader("Origin"); origin != "" && origin != "null" {
+		return service.OriginOf(origin)
+	}
+	return service.OriginOf(c.GetHeader("Referer"))
+}
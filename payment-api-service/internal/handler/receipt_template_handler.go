@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type ReceiptTemplateHandler struct {
+	receiptService *service.ReceiptService
+}
+
+func NewReceiptTemplateHandler() *ReceiptTemplateHandler {
+	return &ReceiptTemplateHandler{
+		receiptService: service.NewReceiptService(),
+	}
+}
+
+type ReceiptTemplateRequest struct {
+	Subject string `json:"subject" binding:"required"`
+	Body    string `json:"body" binding:"required"`
+}
+
+// ListReceiptTemplates returns the merchant's custom receipt emails
+// GET /api/v1/receipt-templates
+func (h *ReceiptTemplateHandler) ListReceiptTemplates(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	templates, err := h.receiptService.ListTemplates(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load receipt templates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    templates,
+	})
+}
+
+// PutReceiptTemplate sets the payment or refund receipt email in one language
+// PUT /api/v1/receipt-templates/:kind/:language
+func (h *ReceiptTemplateHandler) PutReceiptTemplate(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req ReceiptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	tmpl, err := h.receiptService.SaveTemplate(merchantID, actorID(c), c.Param("kind"), c.Param("language"), req.Subject, req.Body)
+	if err != nil {
+		respondReceiptTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tmpl,
+	})
+}
+
+// DeleteReceiptTemplate goes back to the built-in receipt email
+// DELETE /api/v1/receipt-templates/:kind/:language
+func (h *ReceiptTemplateHandler) DeleteReceiptTemplate(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.receiptService.DeleteTemplate(merchantID, c.Param("kind"), c.Param("language")); err != nil {
+		respondReceiptTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+func respondReceiptTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "no template for this kind and language",
+		})
+	case errors.Is(err, service.ErrInvalidReceiptTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save receipt template",
+		})
+	}
+}
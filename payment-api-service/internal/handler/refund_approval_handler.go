@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RefundApprovalHandler struct {
+	approvals      *service.RefundApprovalService
+	webhookService *service.WebhookService
+	receiptService *service.ReceiptService
+}
+
+func NewRefundApprovalHandler(approvals *service.RefundApprovalService) *RefundApprovalHandler {
+	return &RefundApprovalHandler{
+		approvals:      approvals,
+		webhookService: service.NewWebhookService(),
+		receiptService: service.NewReceiptService(),
+	}
+}
+
+type RefundDecisionRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+type RefundApprovalPolicyRequest struct {
+	Threshold *int64 `json:"threshold" binding:"required,min=0"`
+}
+
+// ListRefundApprovals returns the merchant's refunds held for approval
+// GET /api/v1/refund-approvals?status=pending_approval
+func (h *RefundApprovalHandler) ListRefundApprovals(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	status := model.RefundApprovalStatus(c.Query("status"))
+	switch status {
+	case "", model.RefundApprovalPending, model.RefundApprovalApproved, model.RefundApprovalRejected, model.RefundApprovalExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "status must be 'pending_approval', 'approved', 'rejected' or 'expired'",
+		})
+		return
+	}
+
+	approvals, err := h.approvals.ListApprovals(merchantID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list refund approvals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approvals,
+	})
+}
+
+// GetRefundApproval returns a single approval request
+// GET /api/v1/refund-approvals/:id
+func (h *RefundApprovalHandler) GetRefundApproval(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := approvalID(c)
+	if !ok {
+		return
+	}
+
+	approval, err := h.approvals.GetApproval(id, merchantID)
+	if err != nil {
+		c.JSON(refundApprovalErrorStatus(err), gin.H{
+			"success": false,
+			"error":   "refund approval not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// ApproveRefund sends a held refund. The approver must not be the team
+// member who requested it.
+// POST /api/v1/refund-approvals/:id/approve
+func (h *RefundApprovalHandler) ApproveRefund(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := approvalID(c)
+	if !ok {
+		return
+	}
+
+	var req RefundDecisionRequest
+	if !bindDecision(c, &req) {
+		return
+	}
+
+	approval, refund, err := h.approvals.Approve(c.Request.Context(), id, merchantID, actorID(c), req.Note)
+	if err != nil {
+		logger.Log.Error("Refund approval failed", zap.Error(err))
+		c.JSON(refundApprovalErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	h.webhookService.DispatchPaymentEvent(c.Request.Context(), merchantID, approval.PaymentID, service.WebhookEventPaymentRefunded)
+	if refund != nil {
+		go h.receiptService.SendRefundReceiptEmail(approval.PaymentID, merchantID, refund.Refund)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"approval": approval,
+			"payment":  refund,
+		},
+	})
+}
+
+// RejectRefund closes a held refund without sending it
+// POST /api/v1/refund-approvals/:id/reject
+func (h *RefundApprovalHandler) RejectRefund(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := approvalID(c)
+	if !ok {
+		return
+	}
+
+	var req RefundDecisionRequest
+	if !bindDecision(c, &req) {
+		return
+	}
+
+	approval, err := h.approvals.Reject(c.Request.Context(), id, merchantID, actorID(c), req.Note)
+	if err != nil {
+		c.JSON(refundApprovalErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// GetRefundApprovalPolicy returns the amount at which refunds need approval
+// GET /api/v1/refund-approvals/policy
+func (h *RefundApprovalHandler) GetRefundApprovalPolicy(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.approvals.GetPolicy(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load refund approval policy",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// UpdateRefundApprovalPolicy sets the amount at which refunds need approval;
+// a threshold of 0 turns approvals off
+// PUT /api/v1/refund-approvals/policy
+func (h *RefundApprovalHandler) UpdateRefundApprovalPolicy(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req RefundApprovalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.approvals.UpdatePolicy(merchantID, *req.Threshold)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+func approvalID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid refund approval ID",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// bindDecision binds the optional note sent with an approval decision
+func bindDecision(c *gin.Context, req *RefundDecisionRequest) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func refundApprovalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrRefundApprovalPending), errors.Is(err, service.ErrRefundApprovalNotPending):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrRefundSelfApproval):
+		return http.StatusForbidden
+	}
+	return paymentErrorStatus(err)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// defaultReportDays is the range a report covers when it isn't given one
+const defaultReportDays = 30
+
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+func NewReportHandler() *ReportHandler {
+	return &ReportHandler{
+		reportService: service.NewReportService(),
+	}
+}
+
+// GetReportSummary totals the merchant's payments over a range of days
+// GET /api/v1/reports/summary?from=2024-01-01&to=2024-01-31
+func (h *ReportHandler) GetReportSummary(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.reportService.Summary(c.Request.Context(), merchantID, isTestMode(c), from, to)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// GetReportTimeseries splits the merchant's payment statistics into days or weeks
+// GET /api/v1/reports/timeseries?from=2024-01-01&to=2024-03-31&interval=week
+func (h *ReportHandler) GetReportTimeseries(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	from, to, ok := reportRange(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.reportService.Timeseries(c.Request.Context(), merchantID, isTestMode(c), from, to,
+		c.DefaultQuery("interval", service.ReportIntervalDay))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    series,
+	})
+}
+
+// reportRange reads the from and to days, both included. They default to
+// the last 30 days up to today, or to 30 days from whichever one is given.
+func reportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, ok := reportDate(c, "from")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := reportDate(c, "to")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	switch {
+	case from == nil && to == nil:
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		return today.AddDate(0, 0, 1-defaultReportDays), today, true
+	case from == nil:
+		return to.AddDate(0, 0, 1-defaultReportDays), *to, true
+	case to == nil:
+		return *from, from.AddDate(0, 0, defaultReportDays-1), true
+	}
+	return *from, *to, true
+}
+
+// reportDate reads an optional YYYY-MM-DD day. Reports cut days in the
+// merchant's settlement timezone, so they don't take a time of day.
+func reportDate(c *gin.Context, param string) (*time.Time, bool) {
+	v := c.Query(param)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid %s, expected YYYY-MM-DD", param),
+		})
+		return nil, false
+	}
+	return &t, true
+}
+
+func respondReportError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidReport) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Error("Failed to build report", zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"success": false,
+		"error":   "failed to build report",
+	})
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"go.uber.org/zap"
+)
+
+type SandboxHandler struct {
+	sandboxService *service.SandboxService
+}
+
+func NewSandboxHandler() (*SandboxHandler, error) {
+	sandboxService, err := service.NewSandboxService()
+	if err != nil {
+		return nil, err
+	}
+	return &SandboxHandler{sandboxService: sandboxService}, nil
+}
+
+// ResetTestData wipes the merchant's test-mode data
+// POST /api/v1/test/reset
+func (h *SandboxHandler) ResetTestData(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	if !isTestMode(c) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "sandbox reset requires a test-mode API key (pg_test_)",
+		})
+		return
+	}
+
+	result, err := h.sandboxService.Reset(c.Request.Context(), merchantID)
+	if err != nil {
+		logger.Log.Error("Sandbox reset failed",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to reset test data",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// SeedVault creates sandbox tokens for every test card, so integrations can
+// pay with card_token without posting card numbers first. Repeating it
+// returns the same tokens; /test/reset removes them.
+// POST /api/v1/test/seed-vault
+func (h *SandboxHandler) SeedVault(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	if !isTestMode(c) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "seeding the vault requires a test-mode API key (pg_test_)",
+		})
+		return
+	}
+
+	cards, err := h.sandboxService.SeedVault(c.Request.Context(), merchantID)
+	if err != nil {
+		logger.Log.Error("Sandbox vault seeding failed",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to seed sandbox vault",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"cards": cards,
+		},
+	})
+}
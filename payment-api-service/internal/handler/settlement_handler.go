@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+type SettlementHandler struct {
+	settlementService *service.SettlementService
+}
+
+func NewSettlementHandler() *SettlementHandler {
+	return &SettlementHandler{
+		settlementService: service.NewSettlementService(),
+	}
+}
+
+// QuoteInstantPayout shows whether an instant payout is available now, the
+// amount and fee, or why not
+// GET /api/v1/settlements/instant-payout
+func (h *SettlementHandler) QuoteInstantPayout(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.settlementService.QuoteInstantPayout(c.Request.Context(), merchantID)
+	h.respondInstantPayout(c, resp, err, http.StatusOK)
+}
+
+// CreateInstantPayout pays captured funds out immediately for a fee
+// POST /api/v1/settlements/instant-payout
+func (h *SettlementHandler) CreateInstantPayout(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.settlementService.CreateInstantPayout(c.Request.Context(), merchantID)
+	h.respondInstantPayout(c, resp, err, http.StatusCreated)
+}
+
+// GetBalance shows what the merchant's next payout draws on: the available
+// balance, the rolling reserve and when it is next released, and captured
+// funds not yet batched
+// GET /api/v1/balance
+func (h *SettlementHandler) GetBalance(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.settlementService.GetBalance(c.Request.Context(), merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    balance,
+	})
+}
+
+func (h *SettlementHandler) respondInstantPayout(c *gin.Context, resp *pb.InstantPayoutResponse, err error, status int) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if resp.Error != "" {
+		// Ineligible merchants get the reasons with the error
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   resp.Error,
+			"data":    resp,
+		})
+		return
+	}
+
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    resp,
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireFields reads ?fields=, a comma-separated list of the JSON names on
+// item, writing a 400 that lists the valid names if one is unknown. No
+// fields means the caller wants every field.
+func requireFields(c *gin.Context, item interface{}) ([]string, bool) {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil, true
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(item))
+	var fields []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			names := make([]string, 0, len(known))
+			for n := range known {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "unknown field: " + name,
+				"fields":  names,
+			})
+			return nil, false
+		}
+		fields = append(fields, name)
+	}
+	return fields, true
+}
+
+// respondList writes a list response, trimmed to fields when the caller
+// asked for a sparse fieldset
+func respondList(c *gin.Context, data interface{}, listKey string, fields []string) {
+	data, err := selectFields(data, listKey, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to encode response",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// selectFields trims each element of the list under listKey to fields. An
+// empty listKey means data is the list itself. "id" is always kept so rows
+// can still be told apart.
+func selectFields(data interface{}, listKey string, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	keep := map[string]bool{"id": true}
+	for _, f := range fields {
+		keep[f] = true
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var top map[string]json.RawMessage
+	listJSON := json.RawMessage(encoded)
+	if listKey != "" {
+		if err := json.Unmarshal(encoded, &top); err != nil {
+			return nil, err
+		}
+		listJSON = top[listKey]
+		if listJSON == nil {
+			return top, nil
+		}
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(listJSON, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		for name := range item {
+			if !keep[name] {
+				delete(item, name)
+			}
+		}
+	}
+
+	if listKey == "" {
+		return items, nil
+	}
+	trimmed, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	top[listKey] = trimmed
+	return top, nil
+}
+
+// jsonFieldNames lists the names encoding/json gives t's fields, following
+// embedded structs the way the encoder does
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := make(map[string]bool)
+	if t.Kind() != reflect.Struct {
+		return names
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			for n := range jsonFieldNames(field.Type) {
+				names[n] = true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
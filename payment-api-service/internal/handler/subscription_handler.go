@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *service.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptionService *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+type CreatePlanRequest struct {
+	Name          string `json:"name" binding:"required,max=255"`
+	Amount        int64  `json:"amount" binding:"required,min=1"`
+	Currency      string `json:"currency" binding:"required,len=3"`
+	Interval      string `json:"interval" binding:"required"`
+	IntervalCount int    `json:"interval_count"`
+	TrialDays     int    `json:"trial_days"`
+}
+
+type CreateSubscriptionRequest struct {
+	PlanID   string          `json:"plan_id" binding:"required,uuid"`
+	Card     CardRequest     `json:"card" binding:"required"`
+	Customer CustomerRequest `json:"customer"`
+}
+
+type ChangePlanRequest struct {
+	PlanID  string `json:"plan_id" binding:"required,uuid"`
+	Prorate *bool  `json:"prorate"` // Defaults to true
+}
+
+type CancelSubscriptionRequest struct {
+	AtPeriodEnd bool `json:"at_period_end"`
+}
+
+// CreatePlan creates a subscription plan
+// POST /api/v1/subscription-plans
+func (h *SubscriptionHandler) CreatePlan(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	plan, err := h.subscriptionService.CreatePlan(&service.CreatePlanRequest{
+		MerchantID:    merchantID,
+		Name:          req.Name,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Interval:      req.Interval,
+		IntervalCount: req.IntervalCount,
+		TrialDays:     req.TrialDays,
+	})
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to create plan")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// ListPlans returns the merchant's plans; archived ones only with include_archived=true
+// GET /api/v1/subscription-plans?include_archived=
+func (h *SubscriptionHandler) ListPlans(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	plans, err := h.subscriptionService.ListPlans(merchantID, c.Query("include_archived") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list plans",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plans,
+	})
+}
+
+// GetPlan returns one plan
+// GET /api/v1/subscription-plans/:id
+func (h *SubscriptionHandler) GetPlan(c *gin.Context) {
+	merchantID, planID, ok := subscriptionParams(c, "invalid plan id")
+	if !ok {
+		return
+	}
+
+	plan, err := h.subscriptionService.GetPlan(planID, merchantID)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to load plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    plan,
+	})
+}
+
+// ArchivePlan stops new subscriptions to a plan; existing ones keep billing
+// DELETE /api/v1/subscription-plans/:id
+func (h *SubscriptionHandler) ArchivePlan(c *gin.Context) {
+	merchantID, planID, ok := subscriptionParams(c, "invalid plan id")
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.ArchivePlan(planID, merchantID); err != nil {
+		respondSubscriptionError(c, err, "failed to archive plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "plan archived",
+	})
+}
+
+// CreateSubscription subscribes a card to a plan and charges the first
+// period unless the plan has a trial
+// POST /api/v1/subscriptions
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.CreateSubscription(c.Request.Context(), &service.CreateSubscriptionRequest{
+		MerchantID:     merchantID,
+		PlanID:         uuid.MustParse(req.PlanID),
+		CardNumber:     req.Card.Number,
+		CardholderName: req.Card.CardholderName,
+		ExpMonth:       req.Card.ExpMonth,
+		ExpYear:        req.Card.ExpYear,
+		CVV:            req.Card.CVV,
+		CustomerEmail:  req.Customer.Email,
+		IPAddress:      c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		TestMode:       isTestMode(c),
+		CreatedBy:      actorID(c),
+	})
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to create subscription")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// ListSubscriptions returns the merchant's subscriptions
+// GET /api/v1/subscriptions?status=&limit=&offset=
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	status := model.SubscriptionStatus(c.Query("status"))
+	switch status {
+	case "", model.SubscriptionTrialing, model.SubscriptionActive, model.SubscriptionPastDue, model.SubscriptionPaused, model.SubscriptionCanceled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "status must be 'trialing', 'active', 'past_due', 'paused' or 'canceled'",
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	subs, err := h.subscriptionService.ListSubscriptions(merchantID, status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list subscriptions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    subs,
+	})
+}
+
+// GetSubscription returns one subscription
+// GET /api/v1/subscriptions/:id
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.GetSubscription(subID, merchantID)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to load subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// ListSubscriptionCharges returns the subscription's billing attempts
+// GET /api/v1/subscriptions/:id/charges
+func (h *SubscriptionHandler) ListSubscriptionCharges(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	charges, err := h.subscriptionService.ListCharges(subID, merchantID)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to list charges")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    charges,
+	})
+}
+
+// ChangePlan moves the subscription to another plan, prorating by default
+// POST /api/v1/subscriptions/:id/change-plan
+func (h *SubscriptionHandler) ChangePlan(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	var req ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	prorate := req.Prorate == nil || *req.Prorate
+
+	sub, err := h.subscriptionService.ChangePlan(subID, merchantID, uuid.MustParse(req.PlanID), prorate)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to change plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// PauseSubscription stops billing until the subscription is resumed
+// POST /api/v1/subscriptions/:id/pause
+func (h *SubscriptionHandler) PauseSubscription(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.Pause(subID, merchantID)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to pause subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// ResumeSubscription restarts billing for a paused subscription
+// POST /api/v1/subscriptions/:id/resume
+func (h *SubscriptionHandler) ResumeSubscription(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.Resume(subID, merchantID)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to resume subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+// CancelSubscription cancels now, or at the end of the paid period with
+// at_period_end
+// POST /api/v1/subscriptions/:id/cancel
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	merchantID, subID, ok := subscriptionParams(c, "invalid subscription id")
+	if !ok {
+		return
+	}
+
+	var req CancelSubscriptionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
+	sub, err := h.subscriptionService.Cancel(subID, merchantID, req.AtPeriodEnd)
+	if err != nil {
+		respondSubscriptionError(c, err, "failed to cancel subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sub,
+	})
+}
+
+func subscriptionParams(c *gin.Context, invalidIDMessage string) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   invalidIDMessage,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, id, true
+}
+
+func respondSubscriptionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "not found",
+		})
+	case errors.Is(err, service.ErrInvalidSubscription), errors.Is(err, service.ErrSubscriptionCurrencyMatch):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrSubscriptionChargeFailed):
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrSubscriptionPlanArchived), errors.Is(err, service.ErrSubscriptionCanceled),
+		errors.Is(err, service.ErrSubscriptionPaused), errors.Is(err, service.ErrSubscriptionNotPaused):
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, service.ErrMerchantNotAcceptingPayments):
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fallback,
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -36,13 +38,8 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 		return
 	}
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, err := uuid.Parse(merchantIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "invalid merchant context",
-		})
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
 		return
 	}
 	serviceReq := &pb.GetTransactionRequest{
@@ -57,21 +54,34 @@ func (h *TransactionHandler) GetTransaction(c *gin.Context) {
 		})
 		return
 	}
+	// Test keys only see sandbox transactions, and live keys live ones
+	if resp.Error == "" && resp.TestMode != isTestMode(c) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "transaction not found",
+		})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    resp,
 	})
 }
 
+// ListTransactions pages through the merchant's transactions. total counts
+// every match, not just this page. voided_reason=expired lists the
+// authorizations auto-voided after going uncaptured for 7 days.
+// tag takes a comma-separated list and matches transactions carrying all
+// of them; note matches the text of their internal notes.
+// GET /api/v1/transactions?status=&type=&voided_reason=&tag=&note=&created_from=&created_to=&sort_by=&sort_order=&fields=&limit=&offset=
 func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 
-	merchantIDStr, _ := c.Get("merchant_id")
-	merchantID, err := uuid.Parse(merchantIDStr.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"success": false,
-			"error":   "invalid merchant context",
-		})
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+	fields, ok := requireFields(c, &pb.TransactionResponse{})
+	if !ok {
 		return
 	}
 
@@ -79,10 +89,19 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
 	serviceReq := &pb.ListTransactionsRequest{
-		MerchantId: merchantID.String(),
-		Status:     c.Query("status"),
-		Limit:      int32(limit),
-		Offset:     int32(offset),
+		MerchantId:   merchantID.String(),
+		Status:       c.Query("status"),
+		Type:         c.Query("type"),
+		VoidedReason: c.Query("voided_reason"),
+		Tag:          c.Query("tag"),
+		Note:         c.Query("note"),
+		TestMode:     isTestMode(c),
+		CreatedFrom:  c.Query("created_from"),
+		CreatedTo:    c.Query("created_to"),
+		SortBy:       c.Query("sort_by"),
+		SortOrder:    c.Query("sort_order"),
+		Limit:        int32(limit),
+		Offset:       int32(offset),
 	}
 	resp, err := h.transactionService.ListTransactions(c.Request.Context(), serviceReq)
 	if err != nil {
@@ -92,8 +111,228 @@ func (h *TransactionHandler) ListTransactions(c *gin.Context) {
 		})
 		return
 	}
+	if resp.Error != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   resp.Error,
+		})
+		return
+	}
+	respondList(c, resp, "transactions", fields)
+}
+
+// =========================================================================
+// Notes & Tags
+// =========================================================================
+
+// The note and tag endpoints are mounted under both /transactions/:id and
+// /payments/:id; a payment ID is resolved to its transaction first.
+
+type AddTransactionNoteRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type AddTransactionTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1"`
+}
+
+// noteErrorStatus maps an error reported by the transaction service
+func noteErrorStatus(msg string) int {
+	switch msg {
+	case "transaction not found", "note not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// ListNotes returns a transaction's internal notes, oldest first
+// GET /api/v1/transactions/:id/notes
+// GET /api/v1/payments/:id/notes
+func (h *TransactionHandler) ListNotes(c *gin.Context) {
+	merchantID, txnID, ok := h.annotatedTransaction(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.transactionService.ListNotes(c.Request.Context(), &pb.ListTransactionNotesRequest{
+		TransactionId: txnID.String(),
+		MerchantId:    merchantID.String(),
+	})
+	if err != nil {
+		respondNoteError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Error != "" {
+		respondNoteError(c, noteErrorStatus(resp.Error), resp.Error)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    resp,
+		"data":    resp.Notes,
+	})
+}
+
+// AddNote attaches an internal note, signed by the caller, to a transaction
+// POST /api/v1/transactions/:id/notes
+// POST /api/v1/payments/:id/notes
+func (h *TransactionHandler) AddNote(c *gin.Context) {
+	merchantID, txnID, ok := h.annotatedTransaction(c)
+	if !ok {
+		return
+	}
+
+	var req AddTransactionNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondNoteError(c, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	resp, err := h.transactionService.AddNote(c.Request.Context(), &pb.AddTransactionNoteRequest{
+		TransactionId: txnID.String(),
+		MerchantId:    merchantID.String(),
+		AuthorId:      actorID(c).String(),
+		Body:          req.Body,
+	})
+	if err != nil {
+		respondNoteError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Error != "" {
+		respondNoteError(c, noteErrorStatus(resp.Error), resp.Error)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    resp.Note,
+	})
+}
+
+// DeleteNote removes an internal note
+// DELETE /api/v1/transactions/:id/notes/:note_id
+// DELETE /api/v1/payments/:id/notes/:note_id
+func (h *TransactionHandler) DeleteNote(c *gin.Context) {
+	merchantID, txnID, ok := h.annotatedTransaction(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.transactionService.DeleteNote(c.Request.Context(), &pb.DeleteTransactionNoteRequest{
+		NoteId:        c.Param("note_id"),
+		TransactionId: txnID.String(),
+		MerchantId:    merchantID.String(),
+	})
+	if err != nil {
+		respondNoteError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Error != "" {
+		respondNoteError(c, noteErrorStatus(resp.Error), resp.Error)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Note deleted",
+	})
+}
+
+// AddTags tags a transaction and returns all of its tags. Tags are
+// lowercased; ones it already has are ignored.
+// POST /api/v1/transactions/:id/tags
+// POST /api/v1/payments/:id/tags
+func (h *TransactionHandler) AddTags(c *gin.Context) {
+	merchantID, txnID, ok := h.annotatedTransaction(c)
+	if !ok {
+		return
+	}
+
+	var req AddTransactionTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondNoteError(c, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	resp, err := h.transactionService.AddTags(c.Request.Context(), &pb.AddTransactionTagsRequest{
+		TransactionId: txnID.String(),
+		MerchantId:    merchantID.String(),
+		Tags:          req.Tags,
+		CreatedBy:     actorID(c).String(),
+	})
+	h.respondTags(c, resp, err)
+}
+
+// RemoveTag untags a transaction and returns its remaining tags
+// DELETE /api/v1/transactions/:id/tags/:tag
+// DELETE /api/v1/payments/:id/tags/:tag
+func (h *TransactionHandler) RemoveTag(c *gin.Context) {
+	merchantID, txnID, ok := h.annotatedTransaction(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.transactionService.RemoveTag(c.Request.Context(), &pb.RemoveTransactionTagRequest{
+		TransactionId: txnID.String(),
+		MerchantId:    merchantID.String(),
+		Tag:           c.Param("tag"),
+	})
+	h.respondTags(c, resp, err)
+}
+
+func (h *TransactionHandler) respondTags(c *gin.Context, resp *pb.TransactionTagsResponse, err error) {
+	if err != nil {
+		respondNoteError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.Error != "" {
+		respondNoteError(c, noteErrorStatus(resp.Error), resp.Error)
+		return
+	}
+
+	tags := resp.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"tags": tags},
+	})
+}
+
+// annotatedTransaction resolves the :id of a note or tag route to the
+// merchant's transaction ID
+func (h *TransactionHandler) annotatedTransaction(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondNoteError(c, http.StatusBadRequest, "invalid ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !strings.HasPrefix(c.FullPath(), "/api/v1/payments/") {
+		return merchantID, id, true
+	}
+
+	txnID, err := h.transactionService.PaymentTransactionID(c.Request.Context(), id, merchantID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrPaymentTransactionNotFound) {
+			status = http.StatusNotFound
+		}
+		respondNoteError(c, status, err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, txnID, true
+}
+
+func respondNoteError(c *gin.Context, status int, msg string) {
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   msg,
 	})
 }
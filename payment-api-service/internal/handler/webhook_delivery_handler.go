@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type WebhookDeliveryHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookDeliveryHandler() *WebhookDeliveryHandler {
+	return &WebhookDeliveryHandler{
+		webhookService: service.NewWebhookService(),
+	}
+}
+
+// ListWebhookDeliveries returns the merchant's delivery log
+// GET /api/v1/webhook-deliveries?status=&event_type=&payment_id=&subscription_id=&limit=&offset=
+func (h *WebhookDeliveryHandler) ListWebhookDeliveries(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	filter := repository.WebhookDeliveryFilter{
+		Status:    model.WebhookDeliveryStatus(c.Query("status")),
+		EventType: c.Query("event_type"),
+	}
+	switch filter.Status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryRetrying, model.WebhookDeliveryDeadLetter:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "status must be 'pending', 'delivered', 'retrying' or 'dead_letter'",
+		})
+		return
+	}
+
+	for param, target := range map[string]*uuid.UUID{
+		"payment_id":      &filter.PaymentID,
+		"subscription_id": &filter.SubscriptionID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"error":   "invalid " + param,
+				})
+				return
+			}
+			*target = id
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(merchantID, filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to list webhook deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    deliveries,
+	})
+}
+
+// GetWebhookDelivery returns one delivery with its payload and last response
+// GET /api/v1/webhook-deliveries/:id
+func (h *WebhookDeliveryHandler) GetWebhookDelivery(c *gin.Context) {
+	merchantID, deliveryID, ok := webhookDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(deliveryID, merchantID)
+	if err != nil {
+		respondWebhookDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// RedeliverWebhook sends a retrying or dead-lettered delivery again now
+// POST /api/v1/webhook-deliveries/:id/redeliver
+func (h *WebhookDeliveryHandler) RedeliverWebhook(c *gin.Context) {
+	merchantID, deliveryID, ok := webhookDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(deliveryID, merchantID)
+	if err != nil {
+		respondWebhookDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+func webhookDeliveryParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid webhook delivery id",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, deliveryID, true
+}
+
+func respondWebhookDeliveryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "webhook delivery not found",
+		})
+	case errors.Is(err, service.ErrWebhookNotRedeliverable), errors.Is(err, service.ErrWebhookSubscriptionGone):
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load webhook delivery",
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/service"
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionHandler struct {
+	subscriptionService *service.WebhookSubscriptionService
+}
+
+func NewWebhookSubscriptionHandler() *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{
+		subscriptionService: service.NewWebhookSubscriptionService(),
+	}
+}
+
+// WebhookSubscriptionRequest is used for both create and update; omitted
+// fields keep their current value on update
+type WebhookSubscriptionRequest struct {
+	URL         *string   `json:"url"`
+	EventTypes  *[]string `json:"event_types"`
+	MinAmount   *int64    `json:"min_amount"`
+	Currencies  *[]string `json:"currencies"`
+	Mode        *string   `json:"mode"`
+	RoutingKeys *[]string `json:"routing_keys"`
+	Active      *bool     `json:"active"`
+}
+
+func (r *WebhookSubscriptionRequest) input() *service.WebhookSubscriptionInput {
+	return &service.WebhookSubscriptionInput{
+		URL:         r.URL,
+		EventTypes:  r.EventTypes,
+		MinAmount:   r.MinAmount,
+		Currencies:  r.Currencies,
+		Mode:        r.Mode,
+		RoutingKeys: r.RoutingKeys,
+		Active:      r.Active,
+	}
+}
+
+// ListWebhookSubscriptions returns the merchant's webhook subscriptions
+// GET /api/v1/webhook-subscriptions
+func (h *WebhookSubscriptionHandler) ListWebhookSubscriptions(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.subscriptionService.ListSubscriptions(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load webhook subscriptions",
+		})
+		return
+	}
+
+	data := make([]gin.H, 0, len(subs))
+	for i := range subs {
+		data = append(data, webhookSubscriptionResponse(&subs[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// CreateWebhookSubscription registers an endpoint with its event filters.
+// The signing secret is returned here and never again. The endpoint must
+// echo the challenge of a webhook.ping before the subscription is active;
+// verification_error says why it is not.
+// POST /api/v1/webhook-subscriptions
+func (h *WebhookSubscriptionHandler) CreateWebhookSubscription(c *gin.Context) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.CreateSubscription(merchantID, req.input())
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	data := webhookSubscriptionResponse(sub)
+	data["secret"] = sub.Secret
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// GetWebhookSubscription returns one webhook subscription
+// GET /api/v1/webhook-subscriptions/:id
+func (h *WebhookSubscriptionHandler) GetWebhookSubscription(c *gin.Context) {
+	merchantID, subscriptionID, ok := webhookSubscriptionParams(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.GetSubscription(subscriptionID, merchantID)
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhookSubscriptionResponse(sub),
+	})
+}
+
+// UpdateWebhookSubscription changes a subscription's URL, filters or state
+// PATCH /api/v1/webhook-subscriptions/:id
+func (h *WebhookSubscriptionHandler) UpdateWebhookSubscription(c *gin.Context) {
+	merchantID, subscriptionID, ok := webhookSubscriptionParams(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.UpdateSubscription(subscriptionID, merchantID, req.input())
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhookSubscriptionResponse(sub),
+	})
+}
+
+// RotateWebhookSecretRequest sets how long the old secret stays valid.
+// Omitted means service.DefaultWebhookSecretOverlap; 0 retires it at once.
+type RotateWebhookSecretRequest struct {
+	OverlapHours *int `json:"overlap_hours"`
+}
+
+// RotateWebhookSecret replaces a subscription's signing secret. The new
+// secret is returned here and never again.
+// POST /api/v1/webhook-subscriptions/:id/rotate-secret
+func (h *WebhookSubscriptionHandler) RotateWebhookSecret(c *gin.Context) {
+	merchantID, subscriptionID, ok := webhookSubscriptionParams(c)
+	if !ok {
+		return
+	}
+
+	var req RotateWebhookSecretRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid request: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	overlap := service.DefaultWebhookSecretOverlap
+	if req.OverlapHours != nil {
+		overlap = time.Duration(*req.OverlapHours) * time.Hour
+	}
+
+	sub, err := h.subscriptionService.RotateSecret(subscriptionID, merchantID, overlap)
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	data := webhookSubscriptionResponse(sub)
+	data["secret"] = sub.Secret
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// VerifyWebhookSubscription re-sends the ping handshake and activates the
+// subscription if the endpoint echoes the challenge
+// POST /api/v1/webhook-subscriptions/:id/verify
+func (h *WebhookSubscriptionHandler) VerifyWebhookSubscription(c *gin.Context) {
+	merchantID, subscriptionID, ok := webhookSubscriptionParams(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.VerifySubscription(subscriptionID, merchantID)
+	if err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	if sub.VerificationError != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   "webhook endpoint verification failed: " + sub.VerificationError,
+			"data":    webhookSubscriptionResponse(sub),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    webhookSubscriptionResponse(sub),
+	})
+}
+
+// DeleteWebhookSubscription removes a webhook subscription
+// DELETE /api/v1/webhook-subscriptions/:id
+func (h *WebhookSubscriptionHandler) DeleteWebhookSubscription(c *gin.Context) {
+	merchantID, subscriptionID, ok := webhookSubscriptionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.DeleteSubscription(subscriptionID, merchantID); err != nil {
+		respondWebhookSubscriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+func webhookSubscriptionParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := requireMerchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	subscriptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid webhook subscription id",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, subscriptionID, true
+}
+
+func respondWebhookSubscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "webhook subscription not found",
+		})
+	case errors.Is(err, service.ErrInvalidWebhookSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save webhook subscription",
+		})
+	}
+}
+
+func webhookSubscriptionResponse(s *model.WebhookSubscription) gin.H {
+	return gin.H{
+		"id":           s.ID,
+		"url":          s.URL,
+		"event_types":  s.EventTypeList(),
+		"min_amount":   s.MinAmount,
+		"currencies":   s.CurrencyList(),
+		"mode":         s.Mode,
+		"routing_keys": s.RoutingKeyList(),
+		"active":       s.Active,
+		"verified_at":  s.VerifiedAt,
+		"created_at":   s.CreatedAt,
+		"updated_at":   s.UpdatedAt,
+
+		"previous_secret_expires_at": previousSecretExpiry(s),
+		"verification_error":         s.VerificationError,
+	}
+}
+
+// previousSecretExpiry is when the pre-rotation secret stops signing, or nil
+// once no rotation is in progress
+func previousSecretExpiry(s *model.WebhookSubscription) *time.Time {
+	if len(s.SigningSecrets(time.Now())) < 2 {
+		return nil
+	}
+	return s.PreviousSecretExpiresAt
+}
@@ -0,0 +1,133 @@
+// Package i18n translates customer-facing text: the hosted checkout,
+// receipts, invoices and their emails. Messages live in embedded JSON
+// bundles, one per language, keyed by message ID; values may use
+// text/template fields such as {{.Amount}}.
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+// Supported languages. Moroccan customers read Arabic or French; English
+// covers everyone else.
+const (
+	English = "en"
+	French  = "fr"
+	Arabic  = "ar"
+
+	// Default is used when neither the payment nor the merchant picks a language
+	Default = French
+	// fallback provides any message a bundle is missing
+	fallback = English
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	supported = []string{French, Arabic, English}
+	matcher   = language.NewMatcher([]language.Tag{language.French, language.Arabic, language.English})
+	bundles   = mustLoadBundles()
+)
+
+func mustLoadBundles() map[string]map[string]string {
+	loaded := make(map[string]map[string]string, len(supported))
+	for _, lang := range supported {
+		raw, err := localeFS.ReadFile(fmt.Sprintf("locales/active.%s.json", lang))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing bundle for %s: %v", lang, err))
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid bundle for %s: %v", lang, err))
+		}
+		loaded[lang] = messages
+	}
+	return loaded
+}
+
+// Parse maps a language tag or Accept-Language value to a supported
+// language. ok is false when nothing in it is supported.
+func Parse(value string) (lang string, ok bool) {
+	if strings.TrimSpace(value) == "" {
+		return "", false
+	}
+	tags, _, err := language.ParseAcceptLanguage(value)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supported[index], true
+}
+
+// Match returns the first supported language among preferences, ordered
+// from most to least specific (payment override, browser, merchant default)
+func Match(preferences ...string) string {
+	for _, preference := range preferences {
+		if lang, ok := Parse(preference); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// Direction is the text direction HTML renderings should use
+func Direction(lang string) string {
+	if lang == Arabic {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// T translates a message. Missing translations fall back to English, and
+// then to the ID itself so a gap shows up in review rather than as a blank.
+func T(lang, id string, data map[string]interface{}) string {
+	message, ok := bundles[lang][id]
+	if !ok {
+		if message, ok = bundles[fallback][id]; !ok {
+			return id
+		}
+	}
+	if data == nil || !strings.Contains(message, "{{") {
+		return message
+	}
+
+	tmpl, err := template.New(id).Option("missingkey=zero").Parse(message)
+	if err != nil {
+		return message
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return message
+	}
+	return out.String()
+}
+
+// Has reports whether a message exists, so callers can keep their own
+// text for IDs nobody has translated yet
+func Has(id string) bool {
+	_, ok := bundles[fallback][id]
+	return ok
+}
+
+// Messages returns every message whose ID starts with prefix, keyed by the
+// rest of the ID, for clients that render text themselves
+func Messages(lang, prefix string) map[string]string {
+	messages := make(map[string]string)
+	for id := range bundles[fallback] {
+		if strings.HasPrefix(id, prefix) {
+			messages[strings.TrimPrefix(id, prefix)] = T(lang, id, nil)
+		}
+	}
+	return messages
+}
@@ -0,0 +1,75 @@
+// Package merchantctx carries the authenticated merchant through a request as
+// a typed value, instead of untyped strings read back with type assertions.
+package merchantctx
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const contextKey = "merchant_context"
+
+// MerchantContext identifies who a request is acting for
+type MerchantContext struct {
+	MerchantID uuid.UUID
+	UserID     uuid.UUID // uuid.Nil for API key requests
+	APIKeyID   uuid.UUID // uuid.Nil for user requests
+	ActorID    uuid.UUID // user the request acts as: the caller, or the API key's owner
+	Scopes     []string  // empty: unrestricted (API keys are not scoped yet)
+	AuthType   string    // "api_key"
+	TestMode   bool      // authenticated with a pg_test_ sandbox key
+}
+
+// HasScope checks if the request may use a scope
+func (m *MerchantContext) HasScope(scope string) bool {
+	if len(m.Scopes) == 0 {
+		return true
+	}
+	for _, s := range m.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Set stores the merchant context. The legacy string keys are kept for
+// loggers that read them.
+func Set(c *gin.Context, mc *MerchantContext) {
+	c.Set(contextKey, mc)
+	c.Set("merchant_id", mc.MerchantID.String())
+	c.Set("auth_type", mc.AuthType)
+	if mc.APIKeyID != uuid.Nil {
+		c.Set("api_key_id", mc.APIKeyID.String())
+	}
+}
+
+// Get returns the merchant context set by the auth middleware
+func Get(c *gin.Context) (*MerchantContext, bool) {
+	v, exists := c.Get(contextKey)
+	if !exists {
+		return nil, false
+	}
+	mc, ok := v.(*MerchantContext)
+	if !ok || mc.MerchantID == uuid.Nil {
+		return nil, false
+	}
+	return mc, true
+}
+
+// Require refuses requests that have no valid merchant context
+func Require() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := Get(c); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "invalid merchant context",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	paymentAuthorizations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_authorizations_total",
+		Help: "Authorizations and sales by resulting payment status and currency",
+	}, []string{"status", "currency"})
+
+	paymentOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_operations_total",
+		Help: "Captures, voids and refunds sent to the transaction service, by result",
+	}, []string{"operation", "result"})
+
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_webhook_deliveries_total",
+		Help: "Webhook delivery attempts by outcome (delivered, retrying or dead_letter)",
+	}, []string{"outcome"})
+)
+
+// PaymentAuthorized records the status an authorization ended in
+func PaymentAuthorized(status, currency string) {
+	paymentAuthorizations.WithLabelValues(status, currency).Inc()
+}
+
+// PaymentOperation records a capture, void or refund attempt
+func PaymentOperation(operation string, err error) {
+	paymentOperations.WithLabelValues(operation, Result(err)).Inc()
+}
+
+// WebhookDelivery records the state a delivery attempt left the webhook in
+func WebhookDelivery(outcome string) {
+	webhookDeliveries.WithLabelValues(outcome).Inc()
+}
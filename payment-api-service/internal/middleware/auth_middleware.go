@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"hash/crc32"
+	"net"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
 	"go.uber.org/zap"
 )
 
@@ -28,7 +31,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !strings.HasPrefix(apiKey, "pk_") {
+		if !isValidAPIKeyFormat(apiKey) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"success": false,
 				"error":   "invalid API key format",
@@ -37,7 +40,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		apiKeyData, err := authClient.ValidateAPIKey(apiKey)
+		apiKeyData, err := authClient.ValidateAPIKey(c.Request.Context(), apiKey)
 		if err != nil {
 			logger.Log.Warn("API key validation failed",
 				zap.Error(err),
@@ -51,10 +54,28 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("merchant_id", apiKeyData.MerchantID.String())
-		c.Set("api_key_id", apiKeyData.KeyID.String())
+		if !isIPAllowed(c.ClientIP(), apiKeyData.AllowedCIDRs) {
+			logger.Log.Warn("API key used from IP outside allowlist",
+				zap.String("api_key_id", apiKeyData.KeyID.String()),
+				zap.String("merchant_id", apiKeyData.MerchantID.String()),
+				zap.String("ip", c.ClientIP()),
+			)
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "request IP is not allowed for this API key",
+			})
+			c.Abort()
+			return
+		}
+
+		merchantctx.Set(c, &merchantctx.MerchantContext{
+			MerchantID: apiKeyData.MerchantID,
+			APIKeyID:   apiKeyData.KeyID,
+			ActorID:    apiKeyData.CreatedBy,
+			AuthType:   "api_key",
+			TestMode:   strings.HasPrefix(apiKey, testAPIKeyPrefix),
+		})
 		c.Set("api_key_name", apiKeyData.Name)
-		c.Set("auth_type", "api_key")
 
 		logger.Log.Debug("API key authentication successful",
 			zap.String("merchant_id", apiKeyData.MerchantID.String()),
@@ -64,3 +85,64 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isIPAllowed checks the client IP against the API key's CIDR allowlist.
+// An empty allowlist means the key can be used from anywhere.
+func isIPAllowed(ip string, allowedCIDRs []string) bool {
+	if len(allowedCIDRs) == 0 {
+		return true
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, cidr := range allowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// testAPIKeyPrefix marks sandbox keys; auth-service hashes the whole key, so
+// the prefix cannot be swapped on a live key
+const testAPIKeyPrefix = "pg_test_"
+
+// isValidAPIKeyFormat mirrors auth-service's key format check so malformed
+// keys are rejected without a gRPC round-trip.
+// Format: pg_live_ or pg_test_ + <30 base62><6 base62 CRC32>, or legacy pk_<32 chars>.
+func isValidAPIKeyFormat(key string) bool {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	if strings.HasPrefix(key, "pk_") {
+		return len(key) == 35
+	}
+
+	prefix := "pg_live_"
+	if strings.HasPrefix(key, testAPIKeyPrefix) {
+		prefix = testAPIKeyPrefix
+	}
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok || len(rest) != 36 {
+		return false
+	}
+	for _, r := range rest {
+		if !strings.ContainsRune(alphabet, r) {
+			return false
+		}
+	}
+
+	sum := uint64(crc32.ChecksumIEEE([]byte(prefix + rest[:30])))
+	checksum := make([]byte, 6)
+	for i := 5; i >= 0; i-- {
+		checksum[i] = alphabet[sum%62]
+		sum /= 62
+	}
+	return rest[30:] == string(checksum)
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
@@ -32,9 +33,17 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 	}
 }
 
+// checkoutIntentPath is answered by CheckoutCORSMiddleware instead
+const checkoutIntentPath = "/api/public/payment-intents/"
+
 // CORSMiddleware handles CORS headers
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, checkoutIntentPath) {
+			c.Next()
+			return
+		}
+
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key , X-Client-Secret")
@@ -48,3 +57,50 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// CheckoutCORSMiddleware answers CORS for the hosted checkout intent
+// endpoints. The request origin is echoed back only when the intent's
+// merchant allows it, so pages on other sites cannot read intents or
+// confirm them even with a stolen client_secret. Preflights from other
+// origins are refused.
+func CheckoutCORSMiddleware(isAllowed func(c *gin.Context, origin string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		if origin == "" || origin == "null" {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !isAllowed(c, origin) {
+			logger.Log.Warn("Checkout request from disallowed origin",
+				zap.String("intent_id", c.Param("id")),
+				zap.String("origin", origin),
+				zap.String("method", c.Request.Method),
+			)
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Client-Secret")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Max-Age", "600")
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -22,10 +22,7 @@ func RequestLoggerMiddleware() gin.HandlerFunc {
 		startTime := time.Now()
 
 		merchantID, _ := c.Get("merchant_id")
-		merchantIDStr := ""
-		if merchantID != nil {
-			merchantIDStr = merchantID.(string)
-		}
+		merchantIDStr := getString(merchantID)
 
 		logger.Log.Info("Incoming payment request",
 			zap.String("request_id", requestID),
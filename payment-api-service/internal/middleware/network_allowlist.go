@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultInternalCIDRs covers loopback and the private ranges service
+// containers are addressed from
+const DefaultInternalCIDRs = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+
+var internalRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "payment_api_internal_requests_total",
+	Help: "Requests to internal endpoints by allowlist and result (allowed or denied)",
+}, []string{"allowlist", "result"})
+
+// ParseCIDRs parses a comma-separated list of CIDRs. A bare address is
+// treated as a single host.
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	if len(nets) == 0 {
+		return nil, fmt.Errorf("allowlist is empty")
+	}
+	return nets, nil
+}
+
+// RequireAllowedNetwork rejects requests whose peer address is outside nets.
+// It checks the TCP peer, not X-Forwarded-For, so a caller cannot claim an
+// internal address through headers.
+func RequireAllowedNetwork(name string, nets []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.RemoteIP())
+		if ip != nil && containsIP(nets, ip) {
+			internalRequests.WithLabelValues(name, "allowed").Inc()
+			c.Next()
+			return
+		}
+
+		internalRequests.WithLabelValues(name, "denied").Inc()
+		logger.Log.Warn("Internal request from disallowed network",
+			zap.String("allowlist", name),
+			zap.String("remote_ip", c.RemoteIP()),
+			zap.String("forwarded_for", c.GetHeader("X-Forwarded-For")),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("user_agent", c.Request.UserAgent()),
+		)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "forbidden",
+		})
+	}
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
+	"go.uber.org/zap"
+)
+
+// RequirePermission lets a request through only if the user it acts as holds
+// permission ("resource:action") in the merchant's roles. API key requests
+// act as the key's owner, so a key can do no more than the team member who
+// issued it. Errors from auth-service deny the request.
+func RequirePermission(authClient *client.AuthServiceClient, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mc, ok := merchantctx.Get(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "invalid merchant context",
+			})
+			return
+		}
+
+		if mc.ActorID == uuid.Nil {
+			logger.Log.Warn("Permission check without an acting user",
+				zap.String("merchant_id", mc.MerchantID.String()),
+				zap.String("api_key_id", mc.APIKeyID.String()),
+				zap.String("permission", permission),
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "this API key has no owner; issue a new key to use this operation",
+			})
+			return
+		}
+
+		granted, err := authClient.CheckPermissions(c.Request.Context(), mc.ActorID, mc.MerchantID, permission)
+		if err != nil {
+			logger.Log.Error("Permission check failed",
+				zap.String("user_id", mc.ActorID.String()),
+				zap.String("permission", permission),
+				zap.Error(err),
+			)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "unable to verify permissions",
+			})
+			return
+		}
+
+		if !granted[permission] {
+			logger.Log.Warn("Permission denied",
+				zap.String("merchant_id", mc.MerchantID.String()),
+				zap.String("user_id", mc.ActorID.String()),
+				zap.String("permission", permission),
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "missing permission " + permission,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
 	"go.uber.org/zap"
 )
 
@@ -22,14 +23,14 @@ var paymentRateLimit = struct {
 
 func RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		merchantIDStr, exists := c.Get("merchant_id")
-		if !exists {
-			merchantIDStr = c.ClientIP()
+		// Limit per merchant, or per IP when unauthenticated
+		merchantID := c.ClientIP()
+		if mc, ok := merchantctx.Get(c); ok {
+			merchantID = mc.MerchantID.String()
 		}
 
-		merchantID := merchantIDStr.(string)
-
 		allowedSecond, _ := checkRateLimit(
+			c.Request.Context(),
 			merchantID,
 			"second",
 			paymentRateLimit.RequestsPerSecond,
@@ -55,6 +56,7 @@ func RateLimitMiddleware() gin.HandlerFunc {
 		}
 
 		allowedHour, _ := checkRateLimit(
+			c.Request.Context(),
 			merchantID,
 			"hour",
 			paymentRateLimit.RequestsPerHour,
@@ -74,7 +76,7 @@ func RateLimitMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		remaining, _ := getRemainingRequests(merchantID, "second", paymentRateLimit.RequestsPerSecond)
+		remaining, _ := getRemainingRequests(c.Request.Context(), merchantID, "second", paymentRateLimit.RequestsPerSecond)
 		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", paymentRateLimit.RequestsPerSecond))
 		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
 
@@ -82,8 +84,49 @@ func RateLimitMiddleware() gin.HandlerFunc {
 	}
 }
 
-func checkRateLimit(key string, window string, limit int, ttl time.Duration) (bool, error) {
-	ctx := context.Background()
+// confirmThrottle bounds hosted checkout confirmations per client IP, which
+// is the main lever against card-testing through the public endpoint
+var confirmThrottle = struct {
+	AttemptsPerMinute int
+	AttemptsPerHour   int
+}{
+	AttemptsPerMinute: 10,
+	AttemptsPerHour:   30,
+}
+
+// ConfirmThrottleMiddleware limits payment intent confirmations per IP
+func ConfirmThrottleMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "confirm:" + c.ClientIP()
+
+		allowedMinute, _ := checkRateLimit(c.Request.Context(), key, "minute", confirmThrottle.AttemptsPerMinute, time.Minute)
+		allowedHour, _ := checkRateLimit(c.Request.Context(), key, "hour", confirmThrottle.AttemptsPerHour, time.Hour)
+
+		if !allowedMinute || !allowedHour {
+			logger.Log.Warn("Confirm throttle exceeded",
+				zap.String("ip", c.ClientIP()),
+				zap.String("intent_id", c.Param("id")),
+			)
+
+			retryAfter := "60"
+			if !allowedHour {
+				retryAfter = "3600"
+			}
+			c.Header("Retry-After", retryAfter)
+
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "too many payment attempts, please try again later",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func checkRateLimit(ctx context.Context, key string, window string, limit int, ttl time.Duration) (bool, error) {
 	redisKey := fmt.Sprintf("rate_limit:payment:%s:%s", key, window)
 
 	count, err := inits.RDB.Incr(ctx, redisKey).Result()
@@ -98,8 +141,7 @@ func checkRateLimit(key string, window string, limit int, ttl time.Duration) (bo
 	return count <= int64(limit), nil
 }
 
-func getRemainingRequests(key string, window string, limit int) (int, error) {
-	ctx := context.Background()
+func getRemainingRequests(ctx context.Context, key string, window string, limit int) (int, error) {
 	redisKey := fmt.Sprintf("rate_limit:payment:%s:%s", key, window)
 
 	count, err := inits.RDB.Get(ctx, redisKey).Int()
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultRequestTimeout bounds a route that has no entry in the overrides
+const DefaultRequestTimeout = 15 * time.Second
+
+// ErrCodeRequestTimeout is the error code sent with a 504
+const ErrCodeRequestTimeout = "request_timeout"
+
+// DefaultRouteTimeouts holds the routes that need a different budget than
+// REQUEST_TIMEOUT. Authorizations wait on fraud scoring, tokenization and
+// the card network in turn. Downloads stream a file, so their deadline is
+// left to the client.
+var DefaultRouteTimeouts = map[string]time.Duration{
+	"POST /api/v1/payments/authorize":              30 * time.Second,
+	"POST /api/v1/payments/sale":                   30 * time.Second,
+	"POST /api/public/payment-intents/:id/confirm": 30 * time.Second,
+	"GET /api/public/exports/:id/download":         0,
+}
+
+// ParseRouteTimeouts reads REQUEST_TIMEOUT_OVERRIDES, a comma-separated
+// list of "METHOD /route/:param=duration" entries. A duration of 0 turns
+// the deadline off for that route. Entries replace the defaults.
+func ParseRouteTimeouts(raw string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration, len(DefaultRouteTimeouts))
+	for route, timeout := range DefaultRouteTimeouts {
+		routes[route] = timeout
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		eq := strings.LastIndex(entry, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("route timeout %q: expected METHOD /path=duration", entry)
+		}
+		route := strings.Join(strings.Fields(entry[:eq]), " ")
+		if len(strings.Fields(route)) != 2 {
+			return nil, fmt.Errorf("route timeout %q: expected METHOD /path=duration", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(entry[eq+1:]))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("route timeout %q: invalid duration", entry)
+		}
+		method, path, _ := strings.Cut(route, " ")
+		routes[strings.ToUpper(method)+" "+path] = timeout
+	}
+	return routes, nil
+}
+
+// TimeoutMiddleware gives each request a deadline on its context, looked
+// up by method and route pattern. Handlers pass c.Request.Context() down to
+// repositories and gRPC clients, so work stops once the budget is spent or
+// the client goes away. If the deadline passes before the handler has
+// written anything, the response becomes a 504 with code request_timeout
+// in place of whatever error the handler reports for the cancelled call.
+func TimeoutMiddleware(defaultTimeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = writer
+
+		c.Next()
+
+		if !writer.Written() && ctx.Err() == context.DeadlineExceeded {
+			writer.writeTimeout()
+		}
+		if writer.timedOut {
+			requestID, _ := c.Get("request_id")
+			logger.Log.Warn("Request deadline exceeded",
+				zap.Any("request_id", requestID),
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+				zap.Duration("timeout", timeout),
+			)
+		}
+	}
+}
+
+// timeoutWriter swaps the first write after the deadline for the 504 body
+// and drops the rest of the handler's output
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+var timeoutBody = []byte(`{"success":false,"error":"request timed out","code":"` + ErrCodeRequestTimeout + `"}`)
+
+func (w *timeoutWriter) expired() bool {
+	if w.timedOut {
+		return true
+	}
+	if w.ResponseWriter.Written() || w.ctx.Err() != context.DeadlineExceeded {
+		return false
+	}
+	w.writeTimeout()
+	return true
+}
+
+func (w *timeoutWriter) writeTimeout() {
+	w.timedOut = true
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Del("Content-Disposition")
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	w.ResponseWriter.Write(timeoutBody)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.expired() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.expired() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"strings"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// piiColumns are the customer columns stored under the merchant PII key
+var piiColumns = []string{"customer_email", "customer_name"}
+
+// EncryptPaymentPII re-saves rows whose customer columns predate PII
+// encryption. Rows already encrypted are skipped, so it can be re-run.
+func EncryptPaymentPII() error {
+	var payments []model.Payment
+	encrypted := 0
+	err := plaintextPII(inits.DB.Model(&model.Payment{})).
+		FindInBatches(&payments, 500, func(tx *gorm.DB, batch int) error {
+			for i := range payments {
+				if err := inits.DB.Model(&payments[i]).Select(piiColumns).Updates(&payments[i]).Error; err != nil {
+					return err
+				}
+			}
+			encrypted += len(payments)
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+	logger.Log.Info("Encrypted payment PII", zap.Int("rows", encrypted))
+
+	var intents []model.PaymentIntent
+	encrypted = 0
+	err = plaintextPII(inits.DB.Model(&model.PaymentIntent{})).
+		FindInBatches(&intents, 500, func(tx *gorm.DB, batch int) error {
+			for i := range intents {
+				if err := inits.DB.Model(&intents[i]).Select(piiColumns).Updates(&intents[i]).Error; err != nil {
+					return err
+				}
+			}
+			encrypted += len(intents)
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+	logger.Log.Info("Encrypted payment intent PII", zap.Int("rows", encrypted))
+
+	return nil
+}
+
+func plaintextPII(db *gorm.DB) *gorm.DB {
+	conditions := make([]string, 0, len(piiColumns))
+	for _, column := range piiColumns {
+		conditions = append(conditions, "("+column+" IS NOT NULL AND "+column+" <> '' AND "+column+" NOT LIKE 'pii1:%')")
+	}
+	return db.Where(strings.Join(conditions, " OR "))
+}
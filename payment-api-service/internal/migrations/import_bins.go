@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	binPrefixPattern   = regexp.MustCompile(`^[0-9]{6,8}$`)
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// ImportBINCountries loads the BIN issuing countries used by bin_country
+// fraud rules from a CSV of prefix,country rows. A header row is skipped and
+// known prefixes are replaced, so a newer file can be imported over an older one.
+func ImportBINCountries(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	batch := make([]model.BINCountry, 0, 500)
+	imported := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := inits.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "prefix"}},
+			DoUpdates: clause.AssignmentColumns([]string{"country", "updated_at"}),
+		}).Create(&batch).Error
+		imported += len(batch)
+		batch = batch[:0]
+		return err
+	}
+
+	now := time.Now()
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 2 {
+			return fmt.Errorf("line %d: expected prefix,country", line)
+		}
+		prefix := strings.TrimSpace(record[0])
+		country := strings.ToUpper(strings.TrimSpace(record[1]))
+		if line == 1 && !binPrefixPattern.MatchString(prefix) {
+			continue // header
+		}
+		if !binPrefixPattern.MatchString(prefix) || !countryCodePattern.MatchString(country) {
+			return fmt.Errorf("line %d: invalid BIN prefix %q or country %q", line, prefix, country)
+		}
+
+		batch = append(batch, model.BINCountry{Prefix: prefix, Country: country, UpdatedAt: now})
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	logger.Log.Info("Imported BIN countries", zap.Int("rows", imported))
+	return nil
+}
@@ -20,7 +20,32 @@ func RunPaymentApiMigrations() error {
 		&model.Payment{},
 		&model.PaymentEvent{},
 		&model.WebhookDelivery{},
+		&model.WebhookSubscription{},
 		&model.PaymentIntent{}, // NEW
+		&model.CheckoutSettings{},
+		&model.CardTestingIncident{},
+		&model.ExportJob{},
+		&model.AccountingMapping{},
+		&model.MerchantLifecycle{},
+		&model.MerchantDisplaySettings{},
+		&model.RefundApproval{},
+		&model.RefundApprovalPolicy{},
+		&model.SubscriptionPlan{},
+		&model.Subscription{},
+		&model.SubscriptionCharge{},
+		&model.IdempotencyKey{},
+		&model.OutboxEvent{},
+		&model.FraudRule{},
+		&model.BINCountry{},
+		&model.HoldReleaseSettings{},
+		&model.HoldReleaseTemplate{},
+		&model.CheckoutSession{},
+		&model.CheckoutLineItem{},
+		&model.CheckoutShippingOption{},
+		&model.ReceiptTemplate{},
+		&model.Invoice{},
+		&model.InvoiceLineItem{},
+		&model.InvoiceNumberSequence{},
 	}
 
 	for _, m := range models {
@@ -36,6 +61,17 @@ func RunPaymentApiMigrations() error {
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_payment_intents_order_id ON payment_intents(order_id);")
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_intents_client_secret ON payment_intents(client_secret);")
 
+	// Keyset pagination of a merchant's payments
+	db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_merchant_created ON payments(merchant_id, created_at DESC, id DESC);")
+
+	// Deliveries recorded before delivery statuses existed
+	db.Exec("UPDATE webhook_deliveries SET status = 'delivered' WHERE status = 'pending' AND success;")
+	db.Exec("UPDATE webhook_deliveries SET status = 'retrying' WHERE status = 'pending' AND NOT success AND next_retry_at IS NOT NULL AND attempt_count < 5;")
+	db.Exec("UPDATE webhook_deliveries SET status = 'dead_letter' WHERE status = 'pending' AND NOT success AND attempt_count >= 5;")
+
+	// At most one refund awaiting approval per payment
+	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_refund_approvals_pending_payment ON refund_approvals(payment_id) WHERE status = 'pending_approval';")
+
 	return nil
 }
 
@@ -45,6 +81,31 @@ func RollbackPaymentApiMigrations() error {
 
 	// Drop tables in reverse order
 	models := []interface{}{
+		&model.InvoiceNumberSequence{},
+		&model.InvoiceLineItem{},
+		&model.Invoice{},
+		&model.ReceiptTemplate{},
+		&model.CheckoutShippingOption{},
+		&model.CheckoutLineItem{},
+		&model.CheckoutSession{},
+		&model.HoldReleaseTemplate{},
+		&model.HoldReleaseSettings{},
+		&model.BINCountry{},
+		&model.FraudRule{},
+		&model.OutboxEvent{},
+		&model.IdempotencyKey{},
+		&model.SubscriptionCharge{},
+		&model.Subscription{},
+		&model.SubscriptionPlan{},
+		&model.RefundApprovalPolicy{},
+		&model.RefundApproval{},
+		&model.MerchantDisplaySettings{},
+		&model.MerchantLifecycle{},
+		&model.AccountingMapping{},
+		&model.ExportJob{},
+		&model.CardTestingIncident{},
+		&model.CheckoutSettings{},
+		&model.WebhookSubscription{},
 		&model.WebhookDelivery{},
 		&model.PaymentEvent{},
 		&model.Payment{},
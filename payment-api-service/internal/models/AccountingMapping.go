@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AccountingProvider string
+
+const (
+	AccountingProviderQuickBooks AccountingProvider = "quickbooks"
+	AccountingProviderXero       AccountingProvider = "xero"
+)
+
+// AccountingMapping maps settlement lines onto a merchant's chart of
+// accounts. QuickBooks matches accounts by name, Xero by account code.
+type AccountingMapping struct {
+	MerchantID uuid.UUID          `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Provider   AccountingProvider `gorm:"type:varchar(20);primaryKey" json:"provider"`
+
+	BankAccount    string `gorm:"type:varchar(100);not null" json:"bank_account"`               // net payout (debit)
+	FeesAccount    string `gorm:"type:varchar(100);not null" json:"fees_account"`               // processing fees (debit)
+	RefundsAccount string `gorm:"type:varchar(100);not null" json:"refunds_account"`            // refunds (debit)
+	SalesAccount   string `gorm:"type:varchar(100);not null" json:"sales_account"`              // gross captures (credit)
+	ReserveAccount string `gorm:"type:varchar(100);not null;default:''" json:"reserve_account"` // reserve held back from payouts (debit)
+	TaxRate        string `gorm:"type:varchar(50)" json:"tax_rate,omitempty"`                   // Xero only
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (AccountingMapping) TableName() string {
+	return "accounting_mappings"
+}
+
+// DefaultAccountingMapping returns the mapping used until a merchant saves one
+func DefaultAccountingMapping(merchantID uuid.UUID, provider AccountingProvider) *AccountingMapping {
+	if provider == AccountingProviderXero {
+		// Xero's default chart of accounts
+		return &AccountingMapping{
+			MerchantID:     merchantID,
+			Provider:       provider,
+			BankAccount:    "090",
+			FeesAccount:    "404",
+			RefundsAccount: "200",
+			SalesAccount:   "200",
+			ReserveAccount: "610",
+			TaxRate:        "Tax Exempt",
+		}
+	}
+	return &AccountingMapping{
+		MerchantID:     merchantID,
+		Provider:       AccountingProviderQuickBooks,
+		BankAccount:    "Checking",
+		FeesAccount:    "Merchant Account Fees",
+		RefundsAccount: "Refunds",
+		SalesAccount:   "Sales",
+		ReserveAccount: "Merchant Reserve",
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CardTestingDimension is the key a burst was detected on
+type CardTestingDimension string
+
+const (
+	CardTestingDimensionMerchant CardTestingDimension = "merchant"
+	CardTestingDimensionIP       CardTestingDimension = "ip"
+	CardTestingDimensionBIN      CardTestingDimension = "bin"
+)
+
+// CardTestingAction is the protection applied while an incident is active
+type CardTestingAction string
+
+const (
+	CardTestingActionThrottle  CardTestingAction = "throttle"  // stricter per-IP/BIN limits
+	CardTestingActionChallenge CardTestingAction = "challenge" // throttle + CAPTCHA on hosted checkout
+)
+
+type CardTestingIncidentStatus string
+
+const (
+	CardTestingIncidentOpen     CardTestingIncidentStatus = "open"
+	CardTestingIncidentResolved CardTestingIncidentStatus = "resolved"
+)
+
+// CardTestingIncident records a detected burst of low-value, mostly declined
+// authorizations and the protection that was switched on in response
+type CardTestingIncident struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+
+	Dimension      CardTestingDimension `gorm:"type:varchar(20);not null" json:"dimension"`
+	DimensionValue string               `gorm:"type:varchar(64);not null" json:"dimension_value"`
+	Attempts       int                  `gorm:"not null" json:"attempts"`
+	Declines       int                  `gorm:"not null" json:"declines"`
+
+	Action    CardTestingAction         `gorm:"type:varchar(20);not null" json:"action"`
+	Status    CardTestingIncidentStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	ExpiresAt time.Time                 `gorm:"not null" json:"expires_at"`
+
+	ResolvedAt sql.NullTime `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (CardTestingIncident) TableName() string {
+	return "card_testing_incidents"
+}
+
+// DeclineRate returns the share of attempts that were declined
+func (i *CardTestingIncident) DeclineRate() float64 {
+	if i.Attempts == 0 {
+		return 0
+	}
+	return float64(i.Declines) / float64(i.Attempts)
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CheckoutSession is an itemized order paid through the hosted checkout. It
+// owns a payment intent whose amount is always the session's total; picking
+// a different shipping option on the checkout page updates both.
+type CheckoutSession struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID      uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	PaymentIntentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"payment_intent_id"`
+	Currency        string    `gorm:"type:varchar(3);not null" json:"currency"`
+
+	// Totals in minor units. Tax is added on top of the line amounts.
+	Subtotal       int64 `gorm:"not null" json:"subtotal"`
+	TaxAmount      int64 `gorm:"not null;default:0" json:"tax_amount"`
+	ShippingAmount int64 `gorm:"not null;default:0" json:"shipping_amount"`
+	AmountTotal    int64 `gorm:"not null" json:"amount_total"`
+
+	// Code of the chosen shipping option; empty when the session ships nothing
+	ShippingOption string `gorm:"type:varchar(64)" json:"shipping_option,omitempty"`
+
+	LineItems       []CheckoutLineItem       `gorm:"-" json:"line_items"`
+	ShippingOptions []CheckoutShippingOption `gorm:"-" json:"shipping_options"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (CheckoutSession) TableName() string {
+	return "checkout_sessions"
+}
+
+// CheckoutLineItem is one line of a session. TaxRateBps is the tax rate in
+// basis points (2000 is 20%); TaxName is what receipts call it.
+type CheckoutLineItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"-"`
+	SessionID  uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	Position   int       `gorm:"not null" json:"-"`
+
+	Name       string `gorm:"type:varchar(255);not null" json:"name"`
+	Quantity   int64  `gorm:"not null" json:"quantity"`
+	UnitAmount int64  `gorm:"not null" json:"unit_amount"`
+	Amount     int64  `gorm:"not null" json:"amount"` // Quantity * UnitAmount
+	TaxName    string `gorm:"type:varchar(100)" json:"tax_name,omitempty"`
+	TaxRateBps int    `gorm:"not null;default:0" json:"tax_rate_bps"`
+	TaxAmount  int64  `gorm:"not null;default:0" json:"tax_amount"`
+}
+
+func (CheckoutLineItem) TableName() string {
+	return "checkout_line_items"
+}
+
+// CheckoutShippingOption is a delivery choice offered on the checkout page.
+// Code is the merchant's identifier for it.
+type CheckoutShippingOption struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"-"`
+	SessionID  uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	Position   int       `gorm:"not null" json:"-"`
+
+	Code   string `gorm:"type:varchar(64);not null" json:"code"`
+	Name   string `gorm:"type:varchar(255);not null" json:"name"`
+	Amount int64  `gorm:"not null" json:"amount"`
+}
+
+func (CheckoutShippingOption) TableName() string {
+	return "checkout_shipping_options"
+}
+
+// FindShippingOption returns the option with the given code, or nil
+func (s *CheckoutSession) FindShippingOption(code string) *CheckoutShippingOption {
+	for i := range s.ShippingOptions {
+		if s.ShippingOptions[i].Code == code {
+			return &s.ShippingOptions[i]
+		}
+	}
+	return nil
+}
+
+// SelectShipping picks a shipping option and recomputes the total
+func (s *CheckoutSession) SelectShipping(option *CheckoutShippingOption) {
+	s.ShippingOption = option.Code
+	s.ShippingAmount = option.Amount
+	s.AmountTotal = s.Subtotal + s.TaxAmount + s.ShippingAmount
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SupportedCardBrands are the brands the gateway can tokenize and route
+var SupportedCardBrands = []string{"visa", "mastercard"}
+
+// CheckoutSettings holds a merchant's hosted checkout protections and card
+// acceptance. Merchants without a row accept confirmations from any origin
+// without CAPTCHA, and every supported brand.
+type CheckoutSettings struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+
+	// Comma-separated origins (scheme://host[:port]) allowed to fetch and
+	// confirm intents; scheme://*.domain also matches every subdomain.
+	// Empty means any origin.
+	AllowedOrigins string `gorm:"type:text" json:"-"`
+	RequireCaptcha bool   `gorm:"default:false" json:"require_captcha"`
+
+	// Comma-separated card brands the merchant takes. Empty means every
+	// supported brand.
+	AcceptedBrands string `gorm:"type:text" json:"-"`
+	// Shown next to the card form on the hosted checkout, e.g. the notice
+	// some card schemes require before a surcharge is added
+	SurchargeDisclosure string `gorm:"type:varchar(500)" json:"surcharge_disclosure"`
+
+	// Key for the signature on success redirects. Created on first use;
+	// the browser never sees it.
+	RedirectSecret sql.NullString `gorm:"type:text;serializer:pii" json:"-"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (CheckoutSettings) TableName() string {
+	return "checkout_settings"
+}
+
+// AllowedOriginList returns the origin allowlist as a slice
+func (s *CheckoutSettings) AllowedOriginList() []string {
+	if s.AllowedOrigins == "" {
+		return []string{}
+	}
+	return strings.Split(s.AllowedOrigins, ",")
+}
+
+// AcceptedBrandList returns the brands the merchant takes
+func (s *CheckoutSettings) AcceptedBrandList() []string {
+	if s.AcceptedBrands == "" {
+		return append([]string(nil), SupportedCardBrands...)
+	}
+	return strings.Split(s.AcceptedBrands, ",")
+}
+
+// AcceptsBrand reports whether the merchant takes cards of brand. A brand
+// the gateway could not detect is only taken by merchants that have not
+// restricted their brands.
+func (s *CheckoutSettings) AcceptsBrand(brand string) bool {
+	if s.AcceptedBrands == "" {
+		return true
+	}
+	for _, b := range s.AcceptedBrandList() {
+		if strings.EqualFold(b, brand) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOriginAllowed checks an origin against the allowlist (case-insensitive)
+func (s *CheckoutSettings) IsOriginAllowed(origin string) bool {
+	allowed := s.AllowedOriginList()
+	if len(allowed) == 0 {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) || matchesWildcardOrigin(strings.ToLower(o), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWildcardOrigin matches scheme://*.domain[:port] against a
+// subdomain of domain with the same scheme and port
+func matchesWildcardOrigin(pattern, origin string) bool {
+	scheme, domain, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	host, ok := strings.CutPrefix(origin, scheme+"://")
+	if !ok {
+		return false
+	}
+	sub, ok := strings.CutSuffix(host, "."+domain)
+	return ok && sub != "" && !strings.ContainsAny(sub, ":/")
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExportResource string
+
+const (
+	ExportResourceTransactions ExportResource = "transactions"
+	ExportResourcePayments     ExportResource = "payments"
+
+	// ExportResourceTransactionTimeline is a signed bundle of everything
+	// recorded about one payment, for audit and regulator requests
+	ExportResourceTransactionTimeline ExportResource = "transaction_timeline"
+
+	// ExportResourceAccount is a zip of everything the gateway holds about
+	// a merchant, for portability and backups
+	ExportResourceAccount ExportResource = "account"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+	ExportFormatZip  ExportFormat = "zip" // account exports only
+)
+
+type ExportStatus string
+
+const (
+	ExportStatusPending    ExportStatus = "pending"
+	ExportStatusProcessing ExportStatus = "processing"
+	ExportStatusCompleted  ExportStatus = "completed"
+	ExportStatusFailed     ExportStatus = "failed"
+	ExportStatusExpired    ExportStatus = "expired"
+)
+
+// ExportJob is an asynchronous bulk export of a merchant's payments or
+// transactions. The worker writes the file to local storage; merchants
+// download it through a signed URL until ExpiresAt.
+type ExportJob struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+
+	Resource     ExportResource `gorm:"type:varchar(20);not null" json:"resource"`
+	Format       ExportFormat   `gorm:"type:varchar(10);not null" json:"format"`
+	DateFrom     time.Time      `gorm:"not null" json:"date_from"`
+	DateTo       time.Time      `gorm:"not null" json:"date_to"`
+	StatusFilter string         `gorm:"type:varchar(30)" json:"status_filter,omitempty"`
+	PaymentID    *uuid.UUID     `gorm:"type:uuid" json:"payment_id,omitempty"` // transaction_timeline only
+
+	Status   ExportStatus   `gorm:"type:varchar(20);not null;index" json:"status"`
+	RowCount int            `gorm:"default:0" json:"row_count"`
+	Progress int            `gorm:"default:0" json:"progress"`              // Percent done
+	Step     string         `gorm:"type:varchar(40)" json:"step,omitempty"` // Section being written
+	FilePath string         `gorm:"type:text" json:"-"`                     // Storage key; older rows hold a local path
+	Error    sql.NullString `gorm:"type:text" json:"error,omitempty"`
+
+	// RetentionHours overrides how long the file is kept; 0 uses the default
+	RetentionHours int `gorm:"default:0" json:"-"`
+
+	CreatedBy   uuid.UUID    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt   sql.NullTime `json:"started_at,omitempty"`
+	CompletedAt sql.NullTime `json:"completed_at,omitempty"`
+	ExpiresAt   sql.NullTime `gorm:"index" json:"expires_at,omitempty"`
+}
+
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// ContentType is the MIME type the file is served with
+func (j *ExportJob) ContentType() string {
+	switch j.Format {
+	case ExportFormatJSON:
+		return "application/json"
+	case ExportFormatZip:
+		return "application/zip"
+	}
+	return "text/csv"
+}
+
+// FileName is the name offered to the browser on download
+func (j *ExportJob) FileName() string {
+	if j.Resource == ExportResourceAccount {
+		return "account-" + j.CreatedAt.Format("20060102") + "." + string(j.Format)
+	}
+	if j.Resource == ExportResourceTransactionTimeline && j.PaymentID != nil {
+		return "transaction-timeline-" + j.PaymentID.String() + "." + string(j.Format)
+	}
+	return string(j.Resource) + "-" + j.DateFrom.Format("20060102") + "-" + j.DateTo.Format("20060102") + "." + string(j.Format)
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FraudRuleType selects what a fraud rule checks
+type FraudRuleType string
+
+const (
+	FraudRuleVelocity   FraudRuleType = "velocity"    // attempts per card, IP or email within a window
+	FraudRuleAmount     FraudRuleType = "amount"      // amount outside [MinAmount, MaxAmount]
+	FraudRuleBINCountry FraudRuleType = "bin_country" // card issuing country in (or not in) Values
+	FraudRuleBlocklist  FraudRuleType = "blocklist"   // Field value in Values; always declines
+	FraudRuleAllowlist  FraudRuleType = "allowlist"   // Field value in Values; approves and skips other rules
+	FraudRuleRiskScore  FraudRuleType = "risk_score"  // risk score at or above MinScore
+)
+
+// FraudRuleAction is the decision a triggered rule asks for. The strictest
+// triggered action wins.
+type FraudRuleAction string
+
+const (
+	FraudActionApprove FraudRuleAction = "approve"
+	FraudActionReview  FraudRuleAction = "review"
+	FraudActionDecline FraudRuleAction = "decline"
+)
+
+// Fields velocity and list rules key on
+const (
+	FraudFieldCard    = "card" // tokenization fingerprint, or token for stored cards
+	FraudFieldIP      = "ip"
+	FraudFieldEmail   = "email"
+	FraudFieldBIN     = "bin"
+	FraudFieldCountry = "country" // BIN issuing country
+)
+
+// FraudRule is one of a merchant's fraud rules. Merchants without a
+// risk_score rule get the default score thresholds (review from 30,
+// decline from 70); configuring one, even disabled, replaces them.
+type FraudRule struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID       `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Name       string          `gorm:"type:varchar(100);not null" json:"name"`
+	Type       FraudRuleType   `gorm:"type:varchar(20);not null" json:"type"`
+	Action     FraudRuleAction `gorm:"type:varchar(20);not null" json:"action"`
+	ReasonCode string          `gorm:"type:varchar(50);not null" json:"reason_code"` // Reported on payments the rule triggers on
+
+	// Velocity and list rules: card, ip, email; lists also take bin and country
+	Field string `gorm:"type:varchar(20)" json:"field,omitempty"`
+
+	// Velocity: more than MaxAttempts within WindowSeconds triggers
+	MaxAttempts   int `gorm:"not null;default:0" json:"max_attempts,omitempty"`
+	WindowSeconds int `gorm:"not null;default:0" json:"window_seconds,omitempty"`
+
+	// Amount, in minor units; 0 disables a bound. Currency empty means any.
+	MinAmount int64  `gorm:"not null;default:0" json:"min_amount,omitempty"`
+	MaxAmount int64  `gorm:"not null;default:0" json:"max_amount,omitempty"`
+	Currency  string `gorm:"type:varchar(3)" json:"currency,omitempty"`
+
+	// Risk score
+	MinScore int `gorm:"not null;default:0" json:"min_score,omitempty"`
+
+	// Comma-separated list entries, or ISO country codes for bin_country
+	Values string `gorm:"type:text" json:"-"`
+	// bin_country: "in" triggers for listed countries, "not_in" for others
+	CountryMatch string `gorm:"type:varchar(10)" json:"country_match,omitempty"`
+
+	Enabled bool `gorm:"not null" json:"enabled"` // No default: GORM would turn an explicit false into true on create
+
+	CreatedBy uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (FraudRule) TableName() string {
+	return "fraud_rules"
+}
+
+// ValueList returns the list entries or countries as a slice
+func (r *FraudRule) ValueList() []string {
+	return splitList(r.Values)
+}
+
+// Window returns the velocity window
+func (r *FraudRule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// BINCountry maps a card BIN prefix to its issuing country. It is reference
+// data shared by every merchant, loaded with `migrate import-bins`.
+type BINCountry struct {
+	Prefix    string    `gorm:"type:varchar(8);primaryKey" json:"prefix"` // 6 to 8 digits
+	Country   string    `gorm:"type:varchar(2);not null" json:"country"`  // ISO 3166-1 alpha-2
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (BINCountry) TableName() string {
+	return "bin_countries"
+}
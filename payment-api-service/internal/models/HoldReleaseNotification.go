@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why the hold on the customer's card was released
+const (
+	HoldReleaseVoided  = "voided"  // The merchant voided the authorization
+	HoldReleaseExpired = "expired" // Nobody captured it within 7 days
+)
+
+// HoldReleaseSettings turns on the email customers get when an
+// authorization is voided or expires. Merchants without a row send none.
+type HoldReleaseSettings struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Enabled    bool      `gorm:"not null;default:false" json:"enabled"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (HoldReleaseSettings) TableName() string {
+	return "hold_release_settings"
+}
+
+// HoldReleaseTemplate replaces the built-in hold release email for one
+// language. Subject and Body are html/template text; Body is the inside of
+// the email, wrapped in the standard layout.
+type HoldReleaseTemplate struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_hold_release_template_language" json:"merchant_id"`
+	Language   string    `gorm:"type:varchar(5);not null;uniqueIndex:idx_hold_release_template_language" json:"language"`
+	Subject    string    `gorm:"type:varchar(255);not null" json:"subject"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	UpdatedBy  uuid.UUID `gorm:"type:uuid" json:"updated_by"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (HoldReleaseTemplate) TableName() string {
+	return "hold_release_templates"
+}
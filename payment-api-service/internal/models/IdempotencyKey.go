@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IdempotencyKeyStatus string
+
+const (
+	IdempotencyKeyProcessing IdempotencyKeyStatus = "processing"
+	IdempotencyKeyCompleted  IdempotencyKeyStatus = "completed"
+)
+
+// IdempotencyKey is the durable record of an Idempotency-Key sent with a
+// capture, void or refund. While the first request runs the key is locked;
+// once it succeeds the response is kept so retries get it back instead of
+// moving money twice. Redis caches completed keys in front of this table.
+type IdempotencyKey struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_idempotency_keys_key" json:"merchant_id"`
+	TestMode   bool      `gorm:"not null;default:false;uniqueIndex:idx_idempotency_keys_key" json:"test_mode"`
+	Key        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_keys_key" json:"key"`
+
+	Operation   string               `gorm:"type:varchar(20);not null" json:"operation"` // capture, void, refund
+	PaymentID   uuid.UUID            `gorm:"type:uuid;not null" json:"payment_id"`
+	RequestHash string               `gorm:"type:varchar(64);not null" json:"-"` // SHA-256 of operation, payment and body
+	Status      IdempotencyKeyStatus `gorm:"type:varchar(20);not null" json:"status"`
+
+	ResponseStatus int    `json:"response_status,omitempty"`
+	ResponseBody   []byte `gorm:"type:jsonb" json:"-"`
+
+	LockedUntil time.Time `gorm:"not null" json:"-"` // A processing key past this is abandoned
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceStatusDraft         InvoiceStatus = "draft" // Still editable; has no number yet
+	InvoiceStatusOpen          InvoiceStatus = "open"  // Finalized and payable
+	InvoiceStatusPaid          InvoiceStatus = "paid"
+	InvoiceStatusVoid          InvoiceStatus = "void"          // Canceled; can no longer be paid
+	InvoiceStatusUncollectible InvoiceStatus = "uncollectible" // Written off, but still payable
+)
+
+// Invoice is a bill the merchant sends a customer, paid through a hosted
+// link. Drafts can be edited or deleted; finalizing one numbers it and
+// freezes its lines. Amounts are in minor units.
+type Invoice struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID      `gorm:"type:uuid;not null;index;uniqueIndex:idx_invoice_number" json:"merchant_id"`
+	TestMode   bool           `gorm:"not null;default:false;index;uniqueIndex:idx_invoice_number" json:"test_mode"` // made with a sandbox key
+	Number     sql.NullString `gorm:"type:varchar(32);uniqueIndex:idx_invoice_number" json:"number,omitempty"`      // Assigned on finalize, e.g. INV-000042
+	Status     InvoiceStatus  `gorm:"type:varchar(20);not null;index" json:"status"`
+	Currency   string         `gorm:"type:varchar(3);not null" json:"currency"`
+
+	// Totals. Tax is added on top of the line amounts.
+	Subtotal    int64 `gorm:"not null" json:"subtotal"`
+	TaxAmount   int64 `gorm:"not null;default:0" json:"tax_amount"`
+	AmountTotal int64 `gorm:"not null" json:"amount_total"`
+
+	CustomerEmail sql.NullString `gorm:"type:text;serializer:pii" json:"customer_email,omitempty"`
+	CustomerName  sql.NullString `gorm:"type:text;serializer:pii" json:"customer_name,omitempty"`
+	Language      string         `gorm:"type:varchar(5)" json:"language,omitempty"` // email and PDF language; empty uses the merchant's
+	Memo          string         `gorm:"type:text" json:"memo,omitempty"`           // Printed below the lines
+	SuccessURL    string         `gorm:"type:text" json:"success_url,omitempty"`    // Where checkout sends the customer after paying
+
+	DueDate time.Time `gorm:"type:date;not null" json:"due_date"`
+
+	// The hosted link's secret, set on finalize
+	HostedToken sql.NullString `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+
+	// The intent the hosted link collects payment with; a new one replaces
+	// it once it expires unpaid
+	PaymentIntentID sql.NullString `gorm:"type:uuid;index" json:"payment_intent_id,omitempty"`
+	PaymentID       sql.NullString `gorm:"type:uuid" json:"payment_id,omitempty"`
+
+	LineItems []InvoiceLineItem `gorm:"-" json:"line_items"`
+
+	FinalizedAt           sql.NullTime `json:"finalized_at,omitempty"`
+	SentAt                sql.NullTime `json:"sent_at,omitempty"`
+	PaidAt                sql.NullTime `json:"paid_at,omitempty"`
+	VoidedAt              sql.NullTime `json:"voided_at,omitempty"`
+	MarkedUncollectibleAt sql.NullTime `json:"marked_uncollectible_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// IsPayable reports whether the hosted link still collects payment
+func (i *Invoice) IsPayable() bool {
+	return i.Status == InvoiceStatusOpen || i.Status == InvoiceStatusUncollectible
+}
+
+// InvoiceLineItem is one line of an invoice. TaxRateBps is the tax rate in
+// basis points (2000 is 20%); TaxName is what the invoice calls it.
+type InvoiceLineItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"-"`
+	InvoiceID  uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	Position   int       `gorm:"not null" json:"-"`
+
+	Description string `gorm:"type:varchar(500);not null" json:"description"`
+	Quantity    int64  `gorm:"not null" json:"quantity"`
+	UnitAmount  int64  `gorm:"not null" json:"unit_amount"`
+	Amount      int64  `gorm:"not null" json:"amount"` // Quantity * UnitAmount
+	TaxName     string `gorm:"type:varchar(100)" json:"tax_name,omitempty"`
+	TaxRateBps  int    `gorm:"not null;default:0" json:"tax_rate_bps"`
+	TaxAmount   int64  `gorm:"not null;default:0" json:"tax_amount"`
+}
+
+func (InvoiceLineItem) TableName() string {
+	return "invoice_line_items"
+}
+
+// InvoiceNumberSequence hands out a merchant's invoice numbers in order,
+// separately for test and live mode, so live numbering has no gaps
+type InvoiceNumberSequence struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	TestMode   bool      `gorm:"primaryKey" json:"test_mode"`
+	LastNumber int64     `gorm:"not null" json:"last_number"`
+}
+
+func (InvoiceNumberSequence) TableName() string {
+	return "invoice_number_sequences"
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NumberFormat picks the thousands and decimal separators for amounts
+type NumberFormat string
+
+const (
+	NumberFormatSpaceComma NumberFormat = "space_comma" // 1 234,56 (French, Morocco)
+	NumberFormatCommaDot   NumberFormat = "comma_dot"   // 1,234.56 (English)
+	NumberFormatDotComma   NumberFormat = "dot_comma"   // 1.234,56 (much of Europe)
+)
+
+const (
+	DefaultMerchantTimezone = "Africa/Casablanca"
+	DefaultMerchantLocale   = "fr-MA"
+)
+
+// MerchantDisplaySettings is how the merchant's customers and staff see
+// dates, amounts and receipts, pushed here by the merchant service.
+// Merchants without a row use the defaults.
+type MerchantDisplaySettings struct {
+	MerchantID   uuid.UUID    `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Timezone     string       `gorm:"type:varchar(64);not null" json:"timezone"`
+	Locale       string       `gorm:"type:varchar(10);not null" json:"locale"`
+	NumberFormat NumberFormat `gorm:"type:varchar(20);not null" json:"number_format"`
+
+	// Rows are only written by the merchant service, which always sends the
+	// flag; a default of true would make GORM drop an explicit false
+	SendEmailReceipts bool `gorm:"not null;default:false" json:"send_email_receipts"`
+
+	// Where merchant notices such as the expired authorization summary go
+	NotificationEmail string `gorm:"type:varchar(255)" json:"notification_email,omitempty"`
+
+	// Printed on customer receipts
+	StatementDescriptor string `gorm:"type:varchar(22)" json:"statement_descriptor,omitempty"`
+	BusinessName        string `gorm:"type:varchar(255)" json:"business_name,omitempty"`
+	LogoURL             string `gorm:"type:varchar(500)" json:"logo_url,omitempty"`
+	BrandColor          string `gorm:"type:varchar(7)" json:"brand_color,omitempty"` // e.g. "#3B82F6"
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (MerchantDisplaySettings) TableName() string {
+	return "merchant_display_settings"
+}
+
+// DefaultDisplaySettings returns the settings used for merchants that have none
+func DefaultDisplaySettings(merchantID uuid.UUID) *MerchantDisplaySettings {
+	return &MerchantDisplaySettings{
+		MerchantID:        merchantID,
+		Timezone:          DefaultMerchantTimezone,
+		Locale:            DefaultMerchantLocale,
+		NumberFormat:      NumberFormatSpaceComma,
+		SendEmailReceipts: true,
+	}
+}
+
+// Location returns the merchant's timezone, falling back to the default if
+// the stored name no longer loads
+func (s *MerchantDisplaySettings) Location() *time.Location {
+	if loc, err := time.LoadLocation(s.Timezone); err == nil {
+		return loc
+	}
+	loc, err := time.LoadLocation(DefaultMerchantTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// FormatAmount renders an amount in minor units, e.g. "1 234,56 MAD"
+func (s *MerchantDisplaySettings) FormatAmount(amount int64, currency string) string {
+	thousands, decimal := " ", ","
+	switch s.NumberFormat {
+	case NumberFormatCommaDot:
+		thousands, decimal = ",", "."
+	case NumberFormatDotComma:
+		thousands, decimal = ".", ","
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	whole := fmt.Sprintf("%d", amount/100)
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return fmt.Sprintf("%s%s%s%02d %s", sign, grouped.String(), decimal, amount%100, currency)
+}
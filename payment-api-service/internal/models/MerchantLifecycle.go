@@ -0,0 +1,48 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantLifecycleStatus tracks whether a merchant may still transact
+type MerchantLifecycleStatus string
+
+const (
+	MerchantLifecycleActive      MerchantLifecycleStatus = "active"
+	MerchantLifecycleWindingDown MerchantLifecycleStatus = "winding_down" // no new payments, refunds until RefundsUntil
+	MerchantLifecycleClosed      MerchantLifecycleStatus = "closed"
+)
+
+// MerchantLifecycle is set by the merchant service's offboarding workflow.
+// Merchants without a row are active.
+type MerchantLifecycle struct {
+	MerchantID   uuid.UUID               `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Status       MerchantLifecycleStatus `gorm:"type:varchar(20);not null" json:"status"`
+	RefundsUntil sql.NullTime            `gorm:"type:timestamp" json:"refunds_until"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (MerchantLifecycle) TableName() string {
+	return "merchant_lifecycles"
+}
+
+// AcceptsPayments reports whether new payments and intents are allowed
+func (l *MerchantLifecycle) AcceptsPayments() bool {
+	return l.Status == "" || l.Status == MerchantLifecycleActive
+}
+
+// AcceptsRefunds reports whether refunds are allowed at the given time
+func (l *MerchantLifecycle) AcceptsRefunds(at time.Time) bool {
+	switch l.Status {
+	case MerchantLifecycleClosed:
+		return false
+	case MerchantLifecycleWindingDown:
+		return !l.RefundsUntil.Valid || at.Before(l.RefundsUntil.Time)
+	}
+	return true
+}
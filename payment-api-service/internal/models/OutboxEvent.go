@@ -0,0 +1,32 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a payment lifecycle event waiting to be published to the
+// event broker. It is written in the same database transaction as the
+// payment event it mirrors and published at least once; consumers
+// deduplicate on ID.
+type OutboxEvent struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	MerchantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	EventType     string         `gorm:"type:varchar(60);not null" json:"event_type"` // e.g. payment.authorized, payment.refunded
+	AggregateType string         `gorm:"type:varchar(30);not null" json:"aggregate_type"`
+	AggregateID   uuid.UUID      `gorm:"type:uuid;not null" json:"aggregate_id"`
+	TestMode      bool           `gorm:"not null;default:false" json:"test_mode"`
+	Payload       string         `gorm:"type:jsonb;not null" json:"payload"` // The message as published
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	LastError     sql.NullString `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time      `gorm:"not null;index" json:"next_attempt_at"`
+	PublishedAt   sql.NullTime   `gorm:"index" json:"published_at,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
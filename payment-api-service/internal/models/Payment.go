@@ -2,9 +2,11 @@ package model
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	_ "github.com/rhaloubi/payment-gateway/payment-api-service/internal/pii" // "pii" serializer for customer columns
 )
 
 // PaymentStatus represents the status of a payment
@@ -17,6 +19,19 @@ const (
 	PaymentStatusVoided     PaymentStatus = "voided"
 	PaymentStatusRefunded   PaymentStatus = "refunded"
 	PaymentStatusFailed     PaymentStatus = "failed"
+
+	// Waiting for the cardholder to complete a 3-D Secure challenge
+	PaymentStatusRequiresAction PaymentStatus = "requires_action"
+
+	// Captured in part; multi-capture merchants can capture the rest until a
+	// final capture closes the authorization
+	PaymentStatusPartiallyCaptured PaymentStatus = "partially_captured"
+)
+
+// Why an authorization was voided
+const (
+	VoidReasonRequested = "requested" // Voided by the merchant
+	VoidReasonExpired   = "expired"   // Auto-voided after 7 days uncaptured
 )
 
 // PaymentType represents the type of payment operation
@@ -35,6 +50,7 @@ type Payment struct {
 	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	MerchantID    uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
 	TransactionID uuid.UUID `gorm:"type:uuid;index" json:"transaction_id"`
+	TestMode      bool      `gorm:"not null;default:false;index" json:"test_mode"` // made with a sandbox key
 
 	// Payment Details
 	Type     PaymentType   `gorm:"type:varchar(20);not null" json:"type"`
@@ -42,14 +58,18 @@ type Payment struct {
 	Amount   int64         `gorm:"not null" json:"amount"`                   // Amount in cents
 	Currency string        `gorm:"type:varchar(3);not null" json:"currency"` // USD, EUR, etc.
 
+	// Total of the captures taken so far
+	CapturedAmount int64 `gorm:"default:0" json:"captured_amount"`
+
 	// Card/Token Info
 	Token     string `gorm:"type:varchar(255);index" json:"token"`
 	CardBrand string `gorm:"type:varchar(50)" json:"card_brand"`
 	CardLast4 string `gorm:"type:varchar(4)" json:"card_last4"`
 
 	// Customer Info
-	CustomerEmail sql.NullString `gorm:"type:varchar(255)" json:"customer_email,omitempty"`
-	CustomerName  sql.NullString `gorm:"type:varchar(255)" json:"customer_name,omitempty"`
+	CustomerEmail sql.NullString `gorm:"type:text;serializer:pii" json:"customer_email,omitempty"`
+	CustomerName  sql.NullString `gorm:"type:text;serializer:pii" json:"customer_name,omitempty"`
+	Language      string         `gorm:"type:varchar(5)" json:"language,omitempty"` // receipt language; empty uses the merchant's
 
 	// Payment Response
 	AuthCode     sql.NullString `gorm:"type:varchar(50)" json:"auth_code,omitempty"`
@@ -59,16 +79,29 @@ type Payment struct {
 	// Fraud
 	FraudScore    int    `gorm:"default:0" json:"fraud_score"`
 	FraudDecision string `gorm:"type:varchar(20)" json:"fraud_decision"` // approve, review, decline
+	FraudReasons  string `gorm:"type:text" json:"-"`                     // Comma-separated reason codes of the rules behind the decision
 
 	// Related Payments
 	ParentPaymentID sql.NullString `gorm:"type:uuid" json:"parent_payment_id,omitempty"` // For capture/void/refund
 
+	// Checkout session whose line items this payment pays for
+	CheckoutSessionID sql.NullString `gorm:"type:uuid;index" json:"checkout_session_id,omitempty"`
+
 	// Metadata
 	Description sql.NullString `gorm:"type:text" json:"description,omitempty"`
 	Metadata    sql.NullString `gorm:"type:jsonb" json:"metadata,omitempty"` // Custom merchant data
 
 	// Idempotency
-	IdempotencyKey sql.NullString `gorm:"type:varchar(255);uniqueIndex" json:"idempotency_key,omitempty"`
+	IdempotencyKey  sql.NullString `gorm:"type:varchar(255);uniqueIndex" json:"idempotency_key,omitempty"`
+	IdempotencyHash string         `gorm:"type:varchar(64)" json:"-"` // SHA-256 of the request parameters
+
+	// 3-D Secure outcome, and for a challenged sale whether to capture once
+	// the cardholder is authenticated
+	ThreeDS                    PaymentThreeDS `gorm:"embedded;embeddedPrefix:three_ds_" json:"three_d_secure"`
+	CaptureAfterAuthentication bool           `gorm:"not null;default:false" json:"-"`
+
+	// How long each step of the authorize pipeline took
+	Timings PaymentTimings `gorm:"embedded;embeddedPrefix:timing_" json:"timings"`
 
 	// Audit
 	IPAddress string         `gorm:"type:varchar(45)" json:"ip_address"`
@@ -81,12 +114,72 @@ type Payment struct {
 	CapturedAt sql.NullTime `json:"captured_at,omitempty"`
 	VoidedAt   sql.NullTime `json:"voided_at,omitempty"`
 	RefundedAt sql.NullTime `json:"refunded_at,omitempty"`
+
+	// Why an authorization was voided, and for an expired one when it went
+	// out in the merchant's daily summary
+	VoidedReason        string       `gorm:"type:varchar(20);index" json:"voided_reason,omitempty"`
+	ExpirySummarySentAt sql.NullTime `json:"-"`
+}
+
+// PaymentTimings are authorize pipeline stage durations in milliseconds.
+// Tokenization and fraud feature gathering run concurrently, so Total is
+// less than the sum of the stages.
+type PaymentTimings struct {
+	TokenizeMs      int64 `gorm:"not null;default:0" json:"tokenize_ms"`
+	FraudFeaturesMs int64 `gorm:"not null;default:0" json:"fraud_features_ms"`
+	FraudScoreMs    int64 `gorm:"not null;default:0" json:"fraud_score_ms"`
+	AuthenticateMs  int64 `gorm:"not null;default:0" json:"authenticate_ms"`
+	AuthorizeMs     int64 `gorm:"not null;default:0" json:"authorize_ms"`
+	TotalMs         int64 `gorm:"not null;default:0" json:"total_ms"`
+}
+
+// PaymentThreeDS is the result of 3-D Secure authentication. Status is
+// empty when the payment was not authenticated.
+type PaymentThreeDS struct {
+	Status          string `gorm:"column:status;type:varchar(1)" json:"status,omitempty"` // EMV transStatus: Y, A, C, N, R or U
+	Version         string `gorm:"column:version;type:varchar(10)" json:"version,omitempty"`
+	ECI             string `gorm:"column:eci;type:varchar(2)" json:"eci,omitempty"`
+	CAVV            string `gorm:"column:cavv;type:varchar(64)" json:"-"` // Cryptogram, only sent on to the acquirer
+	DSTransactionID string `gorm:"column:ds_transaction_id;type:varchar(64)" json:"ds_transaction_id,omitempty"`
+	ACSURL          string `gorm:"column:acs_url;type:text" json:"-"` // Challenge page while requires_action
+}
+
+// EMV 3DS transStatus values
+const (
+	ThreeDSAuthenticated = "Y"
+	ThreeDSAttempted     = "A" // Issuer not enrolled; liability still shifts
+	ThreeDSChallenge     = "C"
+	ThreeDSFailed        = "N"
+	ThreeDSRejected      = "R"
+	ThreeDSUnavailable   = "U"
+)
+
+// Authenticated reports whether 3-D Secure shifted liability to the issuer
+func (t PaymentThreeDS) Authenticated() bool {
+	return t.Status == ThreeDSAuthenticated || t.Status == ThreeDSAttempted
 }
 
 func (Payment) TableName() string {
 	return "payments"
 }
 
+// MetadataRoutingKey is the metadata field merchants use to route webhooks
+// to one of their internal systems
+const MetadataRoutingKey = "routing_key"
+
+// RoutingKey returns the payment's metadata routing key, or "" if none is set
+func (p *Payment) RoutingKey() string {
+	if !p.Metadata.Valid || p.Metadata.String == "" {
+		return ""
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(p.Metadata.String), &metadata); err != nil {
+		return ""
+	}
+	key, _ := metadata[MetadataRoutingKey].(string)
+	return key
+}
+
 func (p *Payment) IsAuthorized() bool {
 	return p.Status == PaymentStatusAuthorized
 }
@@ -96,7 +189,7 @@ func (p *Payment) IsCaptured() bool {
 }
 
 func (p *Payment) CanCapture() bool {
-	return p.Status == PaymentStatusAuthorized
+	return p.Status == PaymentStatusAuthorized || p.Status == PaymentStatusPartiallyCaptured
 }
 
 func (p *Payment) CanVoid() bool {
@@ -106,3 +199,8 @@ func (p *Payment) CanVoid() bool {
 func (p *Payment) CanRefund() bool {
 	return p.Status == PaymentStatusCaptured
 }
+
+// FraudReasonList returns the fraud reason codes as a slice
+func (p *Payment) FraudReasonList() []string {
+	return splitList(p.FraudReasons)
+}
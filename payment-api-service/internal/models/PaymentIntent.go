@@ -29,6 +29,7 @@ const (
 type PaymentIntent struct {
 	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	TestMode   bool      `gorm:"not null;default:false;index" json:"test_mode"` // made with a sandbox key
 
 	// Order/Reference Info
 	OrderID     sql.NullString `gorm:"type:varchar(255);index" json:"order_id,omitempty"`
@@ -50,8 +51,9 @@ type PaymentIntent struct {
 	CancelURL  string `gorm:"type:text" json:"cancel_url"`
 
 	// Customer Info (optional)
-	CustomerEmail sql.NullString `gorm:"type:varchar(255)" json:"customer_email,omitempty"`
-	CustomerName  sql.NullString `gorm:"type:varchar(255)" json:"customer_name,omitempty"`
+	CustomerEmail sql.NullString `gorm:"type:text;serializer:pii" json:"customer_email,omitempty"`
+	CustomerName  sql.NullString `gorm:"type:text;serializer:pii" json:"customer_name,omitempty"`
+	Language      string         `gorm:"type:varchar(5)" json:"language,omitempty"` // checkout and receipt language; empty uses the merchant's
 
 	// Metadata
 	Metadata sql.NullString `gorm:"type:jsonb" json:"metadata,omitempty"`
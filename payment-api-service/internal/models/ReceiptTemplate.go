@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Receipt emails a merchant can template
+const (
+	ReceiptKindPayment = "payment" // Sent when a payment is captured
+	ReceiptKindRefund  = "refund"  // Sent when a refund is issued
+)
+
+// ReceiptTemplate replaces the subject and opening of one kind of receipt
+// email for one language. Subject and Body are html/template text; the
+// receipt details are always rendered below Body.
+type ReceiptTemplate struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_receipt_template_kind_language" json:"merchant_id"`
+	Kind       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_receipt_template_kind_language" json:"kind"`
+	Language   string    `gorm:"type:varchar(5);not null;uniqueIndex:idx_receipt_template_kind_language" json:"language"`
+	Subject    string    `gorm:"type:varchar(255);not null" json:"subject"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	UpdatedBy  uuid.UUID `gorm:"type:uuid" json:"updated_by"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (ReceiptTemplate) TableName() string {
+	return "receipt_templates"
+}
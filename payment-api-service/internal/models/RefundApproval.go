@@ -0,0 +1,67 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefundApprovalStatus string
+
+const (
+	RefundApprovalPending  RefundApprovalStatus = "pending_approval"
+	RefundApprovalApproved RefundApprovalStatus = "approved"
+	RefundApprovalRejected RefundApprovalStatus = "rejected"
+	RefundApprovalExpired  RefundApprovalStatus = "expired"
+)
+
+// RefundApproval is a refund at or above the merchant's approval threshold,
+// held until a second team member approves or rejects it. The refund is only
+// sent to the transaction service on approval.
+type RefundApproval struct {
+	ID         uuid.UUID            `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID            `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	PaymentID  uuid.UUID            `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Amount     int64                `gorm:"not null" json:"amount"`
+	Currency   string               `gorm:"type:varchar(3);not null" json:"currency"`
+	Reason     string               `gorm:"type:text" json:"reason,omitempty"`
+	ReasonCode string               `gorm:"type:varchar(30)" json:"reason_code,omitempty"`
+	Status     RefundApprovalStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+
+	RequestedBy  uuid.UUID      `gorm:"type:uuid" json:"requested_by"`
+	DecidedBy    *uuid.UUID     `gorm:"type:uuid" json:"decided_by,omitempty"`
+	DecisionNote sql.NullString `gorm:"type:text" json:"-"`
+	RefundID     sql.NullString `gorm:"type:varchar(100)" json:"-"` // transaction-service refund, once approved
+
+	ExpiresAt time.Time    `gorm:"not null;index" json:"expires_at"`
+	DecidedAt sql.NullTime `json:"-"`
+	CreatedAt time.Time    `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (RefundApproval) TableName() string {
+	return "refund_approvals"
+}
+
+// RefundApprovalPolicy is the merchant's threshold for dual approval.
+// Merchants without a row use REFUND_APPROVAL_THRESHOLD.
+type RefundApprovalPolicy struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+
+	// Refunds of at least this amount, in minor units of the payment's
+	// currency, need a second approver. 0 turns approvals off.
+	Threshold int64 `gorm:"not null" json:"threshold"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (RefundApprovalPolicy) TableName() string {
+	return "refund_approval_policies"
+}
+
+// RequiresApproval reports whether a refund of amount must be approved
+func (p *RefundApprovalPolicy) RequiresApproval(amount int64) bool {
+	return p.Threshold > 0 && amount >= p.Threshold
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BillingInterval string
+
+const (
+	BillingIntervalDay   BillingInterval = "day"
+	BillingIntervalWeek  BillingInterval = "week"
+	BillingIntervalMonth BillingInterval = "month"
+	BillingIntervalYear  BillingInterval = "year"
+)
+
+// SubscriptionPlan is what a merchant charges on a schedule. Plans are
+// archived rather than deleted so existing subscriptions keep billing.
+type SubscriptionPlan struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID    uuid.UUID       `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Name          string          `gorm:"type:varchar(255);not null" json:"name"`
+	Amount        int64           `gorm:"not null" json:"amount"` // Minor units per period
+	Currency      string          `gorm:"type:varchar(3);not null" json:"currency"`
+	Interval      BillingInterval `gorm:"type:varchar(10);not null" json:"interval"`
+	IntervalCount int             `gorm:"not null;default:1" json:"interval_count"` // e.g. 3 with month bills quarterly
+	TrialDays     int             `gorm:"not null;default:0" json:"trial_days"`
+	Active        bool            `gorm:"not null" json:"active"` // No default: GORM would turn an explicit false into true on create
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (SubscriptionPlan) TableName() string {
+	return "subscription_plans"
+}
+
+// PeriodEnd returns the end of a billing period starting at start. Month
+// and year periods keep the day of month, clamped to the month's last day,
+// so a period starting January 31 ends February 28 or 29.
+func (p *SubscriptionPlan) PeriodEnd(start time.Time) time.Time {
+	count := p.IntervalCount
+	if count < 1 {
+		count = 1
+	}
+	switch p.Interval {
+	case BillingIntervalDay:
+		return start.AddDate(0, 0, count)
+	case BillingIntervalWeek:
+		return start.AddDate(0, 0, 7*count)
+	case BillingIntervalYear:
+		return addMonthsClamped(start, 12*count)
+	default:
+		return addMonthsClamped(start, count)
+	}
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := firstOfMonth.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionPastDue  SubscriptionStatus = "past_due" // last charge failed; retrying
+	SubscriptionPaused   SubscriptionStatus = "paused"
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription bills a stored card token on its plan's schedule. The card
+// is tokenized once when the subscription is created; the number is never
+// kept here.
+type Subscription struct {
+	ID         uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID          `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	PlanID     uuid.UUID          `gorm:"type:uuid;not null;index" json:"plan_id"`
+	Status     SubscriptionStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	TestMode   bool               `gorm:"not null;default:false;index" json:"test_mode"`
+
+	// Stored card
+	CardToken string `gorm:"type:varchar(100);not null" json:"-"`
+	CardBrand string `gorm:"type:varchar(20)" json:"card_brand"`
+	CardLast4 string `gorm:"type:varchar(4)" json:"card_last4"`
+
+	CustomerEmail sql.NullString `gorm:"type:text;serializer:pii" json:"customer_email,omitempty"`
+
+	CurrentPeriodStart time.Time    `gorm:"not null" json:"current_period_start"`
+	CurrentPeriodEnd   time.Time    `gorm:"not null" json:"current_period_end"`
+	TrialEndsAt        sql.NullTime `json:"trial_ends_at,omitempty"`
+	NextBillingAt      sql.NullTime `gorm:"index" json:"next_billing_at,omitempty"` // Null while paused or canceled
+
+	// Credit (negative) or debit (positive) from plan changes, settled on
+	// the next charge
+	ProrationBalance int64 `gorm:"not null;default:0" json:"proration_balance"`
+
+	FailedAttempts    int          `gorm:"not null;default:0" json:"failed_attempts"`
+	CancelAtPeriodEnd bool         `gorm:"not null;default:false" json:"cancel_at_period_end"`
+	PausedAt          sql.NullTime `json:"paused_at,omitempty"`
+	CanceledAt        sql.NullTime `json:"canceled_at,omitempty"`
+
+	CreatedBy uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// IsBillable reports whether the billing worker should charge the
+// subscription when it comes due
+func (s *Subscription) IsBillable() bool {
+	switch s.Status {
+	case SubscriptionTrialing, SubscriptionActive, SubscriptionPastDue:
+		return true
+	}
+	return false
+}
+
+type SubscriptionChargeStatus string
+
+const (
+	SubscriptionChargeSucceeded SubscriptionChargeStatus = "succeeded"
+	SubscriptionChargeFailed    SubscriptionChargeStatus = "failed"
+)
+
+// SubscriptionCharge is one billing attempt for a period. Periods fully
+// covered by proration credit are recorded with a zero amount and no payment.
+type SubscriptionCharge struct {
+	ID             uuid.UUID                `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	SubscriptionID uuid.UUID                `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	MerchantID     uuid.UUID                `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	PaymentID      *uuid.UUID               `gorm:"type:uuid" json:"payment_id,omitempty"`
+	Amount         int64                    `gorm:"not null" json:"amount"`
+	Proration      int64                    `gorm:"not null;default:0" json:"proration"` // Part of Amount settled from the balance
+	Currency       string                   `gorm:"type:varchar(3);not null" json:"currency"`
+	Status         SubscriptionChargeStatus `gorm:"type:varchar(20);not null" json:"status"`
+	FailureReason  string                   `gorm:"type:text" json:"failure_reason,omitempty"`
+	PeriodStart    time.Time                `gorm:"not null" json:"period_start"`
+	PeriodEnd      time.Time                `gorm:"not null" json:"period_end"`
+	CreatedAt      time.Time                `gorm:"not null;default:now()" json:"created_at"`
+}
+
+func (SubscriptionCharge) TableName() string {
+	return "subscription_charges"
+}
@@ -7,23 +7,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// WebhookDeliveryStatus is where a delivery is in its retry schedule
+type WebhookDeliveryStatus string
 
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // first attempt in flight
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // endpoint answered 2xx
+	WebhookDeliveryRetrying  WebhookDeliveryStatus = "retrying"  // failed; next_retry_at is set
+	// Out of retries. Only a manual redelivery sends it again.
+	WebhookDeliveryDeadLetter WebhookDeliveryStatus = "dead_letter"
+)
 
 // WebhookDelivery tracks webhook delivery attempts
 type WebhookDelivery struct {
-	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	PaymentID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"payment_id"`
-	MerchantID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`
-	EventType    string         `gorm:"type:varchar(50);not null" json:"event_type"`
-	WebhookURL   string         `gorm:"type:text;not null" json:"webhook_url"`
-	Payload      string         `gorm:"type:jsonb" json:"payload"`
-	Response     sql.NullString `gorm:"type:text" json:"response,omitempty"`
-	StatusCode   int            `json:"status_code"`
-	Success      bool           `gorm:"default:false" json:"success"`
-	AttemptCount int            `gorm:"default:1" json:"attempt_count"`
-	NextRetryAt  sql.NullTime   `json:"next_retry_at,omitempty"`
-	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	DeliveredAt  sql.NullTime   `json:"delivered_at,omitempty"`
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	PaymentID      uuid.UUID             `gorm:"type:uuid;not null;index" json:"payment_id"`
+	MerchantID     uuid.UUID             `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	SubscriptionID *uuid.UUID            `gorm:"type:uuid;index" json:"subscription_id,omitempty"` // Nil for deliveries made before subscriptions
+	EventType      string                `gorm:"type:varchar(50);not null" json:"event_type"`
+	WebhookURL     string                `gorm:"type:text;not null" json:"webhook_url"`
+	Payload        string                `gorm:"type:jsonb" json:"payload"`
+	Response       sql.NullString        `gorm:"type:text" json:"response,omitempty"`
+	StatusCode     int                   `json:"status_code"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Success        bool                  `gorm:"default:false" json:"success"`
+	AttemptCount   int                   `gorm:"default:1" json:"attempt_count"`
+	NextRetryAt    sql.NullTime          `json:"next_retry_at,omitempty"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	DeliveredAt    sql.NullTime          `json:"delivered_at,omitempty"`
 }
 
 // TableName specifies the table name
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookMode limits a subscription to live or sandbox payments
+type WebhookMode string
+
+const (
+	WebhookModeAll  WebhookMode = "all"
+	WebhookModeLive WebhookMode = "live"
+	WebhookModeTest WebhookMode = "test"
+)
+
+// WebhookSubscription is one merchant endpoint and the events it wants.
+// Filters are evaluated before a delivery is recorded, so events that do not
+// match never reach the endpoint or the retry queue.
+type WebhookSubscription struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	URL        string    `gorm:"type:text;not null" json:"url"`
+	Secret     string    `gorm:"type:varchar(255);not null" json:"-"` // HMAC key for X-Webhook-Signature
+
+	// After a rotation the old secret keeps signing alongside the new one
+	// until PreviousSecretExpiresAt, so the merchant can switch over
+	// without rejecting deliveries
+	PreviousSecret          string     `gorm:"type:varchar(255)" json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+
+	// Comma-separated event types. Empty means every event.
+	EventTypes string `gorm:"type:text" json:"-"`
+
+	// Filters
+	MinAmount  int64       `gorm:"not null;default:0" json:"min_amount"`                // Minor units; 0 disables
+	Currencies string      `gorm:"type:varchar(100)" json:"-"`                          // Comma-separated; empty means any
+	Mode       WebhookMode `gorm:"type:varchar(10);not null;default:'all'" json:"mode"` // all, live or test
+
+	// Comma-separated metadata routing keys; empty means any. Payments
+	// without a routing key never match a subscription that sets one.
+	RoutingKeys string `gorm:"type:text" json:"-"`
+
+	Active bool `gorm:"not null" json:"active"` // No default: GORM would turn an explicit false into true on create
+
+	// Set when URL last answered the ping handshake. A subscription is only
+	// activated once its URL is verified.
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	VerificationError string     `gorm:"type:text" json:"verification_error,omitempty"` // Why the last handshake failed
+
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventTypeList returns the subscribed event types as a slice
+func (s *WebhookSubscription) EventTypeList() []string {
+	return splitList(s.EventTypes)
+}
+
+// CurrencyList returns the currency filter as a slice
+func (s *WebhookSubscription) CurrencyList() []string {
+	return splitList(s.Currencies)
+}
+
+// RoutingKeyList returns the routing key filter as a slice
+func (s *WebhookSubscription) RoutingKeyList() []string {
+	return splitList(s.RoutingKeys)
+}
+
+// SigningSecrets returns the secrets deliveries are signed with at now: the
+// current one, then the previous one while its overlap window is open
+func (s *WebhookSubscription) SigningSecrets(now time.Time) []string {
+	secrets := []string{s.Secret}
+	if s.PreviousSecret != "" && s.PreviousSecretExpiresAt != nil && now.Before(*s.PreviousSecretExpiresAt) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+	return secrets
+}
+
+// Matches reports whether a payment event passes the subscription's filters
+func (s *WebhookSubscription) Matches(payment *Payment, eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if events := s.EventTypeList(); len(events) > 0 && !containsFold(events, eventType) {
+		return false
+	}
+	if s.MinAmount > 0 && payment.Amount < s.MinAmount {
+		return false
+	}
+	if currencies := s.CurrencyList(); len(currencies) > 0 && !containsFold(currencies, payment.Currency) {
+		return false
+	}
+	if keys := s.RoutingKeyList(); len(keys) > 0 && !containsString(keys, payment.RoutingKey()) {
+		return false
+	}
+	switch s.Mode {
+	case WebhookModeLive:
+		return !payment.TestMode
+	case WebhookModeTest:
+		return payment.TestMode
+	default:
+		return true
+	}
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// containsString is an exact match; routing keys are case-sensitive
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Package pii encrypts customer PII columns under a per-merchant data key
+// held by the tokenization service. Revoking a merchant's keys there makes
+// their customers' PII unrecoverable without touching payment rows.
+package pii
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+)
+
+// keyCacheTTL bounds how long a key stays usable in this process after it
+// is shredded elsewhere
+const keyCacheTTL = 10 * time.Minute
+
+var (
+	ErrNotConfigured = errors.New("pii: no key source configured")
+	ErrKeyShredded   = errors.New("pii: merchant key has been shredded")
+)
+
+// KeySource is the key management backend (the tokenization service)
+type KeySource interface {
+	ActiveKey(ctx context.Context, merchantID uuid.UUID) (string, []byte, error)
+	KeyByID(ctx context.Context, merchantID uuid.UUID, keyID string) ([]byte, error)
+}
+
+type cachedKey struct {
+	merchantID uuid.UUID
+	id         string
+	key        []byte
+	fetchedAt  time.Time
+}
+
+func (k cachedKey) fresh() bool {
+	return time.Since(k.fetchedAt) < keyCacheTTL
+}
+
+// Keyring caches data keys so encrypting a row does not cost a gRPC call
+type Keyring struct {
+	source KeySource
+	// isShredded reports whether a KeySource error means the key is gone
+	isShredded func(error) bool
+
+	mu     sync.RWMutex
+	active map[uuid.UUID]cachedKey
+	byID   map[string]cachedKey
+}
+
+func NewKeyring(source KeySource, isShredded func(error) bool) *Keyring {
+	return &Keyring{
+		source:     source,
+		isShredded: isShredded,
+		active:     make(map[uuid.UUID]cachedKey),
+		byID:       make(map[string]cachedKey),
+	}
+}
+
+// ActiveKey returns the key new values for merchantID are encrypted with
+func (k *Keyring) ActiveKey(ctx context.Context, merchantID uuid.UUID) (string, []byte, error) {
+	k.mu.RLock()
+	cached, ok := k.active[merchantID]
+	k.mu.RUnlock()
+	if ok && cached.fresh() {
+		return cached.id, cached.key, nil
+	}
+
+	keyID, key, err := k.source.ActiveKey(ctx, merchantID)
+	if err != nil {
+		return "", nil, k.wrap(err)
+	}
+
+	entry := cachedKey{merchantID: merchantID, id: keyID, key: key, fetchedAt: time.Now()}
+	k.mu.Lock()
+	k.active[merchantID] = entry
+	k.byID[keyID] = entry
+	k.mu.Unlock()
+	return keyID, key, nil
+}
+
+// KeyByID returns the key a stored value was encrypted with
+func (k *Keyring) KeyByID(ctx context.Context, merchantID uuid.UUID, keyID string) ([]byte, error) {
+	k.mu.RLock()
+	cached, ok := k.byID[keyID]
+	k.mu.RUnlock()
+	if ok && cached.fresh() {
+		return cached.key, nil
+	}
+
+	key, err := k.source.KeyByID(ctx, merchantID, keyID)
+	if err != nil {
+		return nil, k.wrap(err)
+	}
+
+	k.mu.Lock()
+	k.byID[keyID] = cachedKey{merchantID: merchantID, id: keyID, key: key, fetchedAt: time.Now()}
+	k.mu.Unlock()
+	return key, nil
+}
+
+// Forget drops a merchant's keys from the cache, used right after shredding
+func (k *Keyring) Forget(merchantID uuid.UUID) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.active, merchantID)
+	for id, cached := range k.byID {
+		if cached.merchantID == merchantID {
+			delete(k.byID, id)
+		}
+	}
+}
+
+func (k *Keyring) wrap(err error) error {
+	if k.isShredded != nil && k.isShredded(err) {
+		return ErrKeyShredded
+	}
+	return err
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultKeyring *Keyring
+)
+
+// SetKeyring installs the keyring used by the "pii" GORM serializer
+func SetKeyring(k *Keyring) {
+	defaultMu.Lock()
+	defaultKeyring = k
+	defaultMu.Unlock()
+}
+
+func currentKeyring() (*Keyring, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultKeyring == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultKeyring, nil
+}
+
+// Init connects the serializer to the tokenization service's key management
+func Init() (*client.KeyManagementClient, error) {
+	keyClient, err := client.NewKeyManagementClient()
+	if err != nil {
+		return nil, err
+	}
+	SetKeyring(NewKeyring(keyClient, func(err error) bool {
+		return errors.Is(err, client.ErrKeyShredded)
+	}))
+	return keyClient, nil
+}
@@ -0,0 +1,189 @@
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm/schema"
+)
+
+// envelopePrefix marks an encrypted value: pii1:<key_id>:<base64 nonce+ciphertext>.
+// Values without it are rows written before encryption and are read as is.
+const envelopePrefix = "pii1:"
+
+func init() {
+	schema.RegisterSerializer("pii", Serializer{})
+}
+
+// Serializer encrypts sql.NullString columns tagged `serializer:pii` under
+// the row's merchant key. The model must have a MerchantID field.
+type Serializer struct{}
+
+// Value encrypts the field for storage
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value := toNullString(fieldValue)
+	if !value.Valid {
+		return nil, nil
+	}
+	if value.String == "" {
+		return "", nil
+	}
+
+	merchantID, err := rowMerchant(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+	keyID, key, err := keyring.ActiveKey(ctx, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("pii: failed to get key for %s: %w", field.DBName, err)
+	}
+
+	sealed, err := seal(key, []byte(value.String), additionalData(merchantID, field))
+	if err != nil {
+		return nil, err
+	}
+	return envelopePrefix + keyID + ":" + sealed, nil
+}
+
+// Scan decrypts the stored value. Values under a shredded key read as NULL.
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored sql.NullString
+	if err := stored.Scan(dbValue); err != nil {
+		return err
+	}
+
+	if stored.Valid && strings.HasPrefix(stored.String, envelopePrefix) {
+		plaintext, err := open(ctx, field, dst, stored.String)
+		switch {
+		case errors.Is(err, ErrKeyShredded):
+			stored = sql.NullString{}
+		case err != nil:
+			return err
+		default:
+			stored = sql.NullString{String: plaintext, Valid: true}
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(stored))
+	return nil
+}
+
+func open(ctx context.Context, field *schema.Field, dst reflect.Value, envelope string) (string, error) {
+	keyID, sealed, found := strings.Cut(strings.TrimPrefix(envelope, envelopePrefix), ":")
+	if !found {
+		return "", fmt.Errorf("pii: malformed value in %s", field.DBName)
+	}
+
+	merchantID, err := rowMerchant(ctx, field, dst)
+	if err != nil {
+		return "", err
+	}
+	keyring, err := currentKeyring()
+	if err != nil {
+		return "", err
+	}
+	key, err := keyring.KeyByID(ctx, merchantID, keyID)
+	if err != nil {
+		if errors.Is(err, ErrKeyShredded) {
+			logger.Log.Debug("PII column under shredded key",
+				zap.String("column", field.DBName),
+				zap.String("merchant_id", merchantID.String()),
+			)
+		}
+		return "", err
+	}
+
+	plaintext, err := unseal(key, sealed, additionalData(merchantID, field))
+	if err != nil {
+		return "", fmt.Errorf("pii: failed to decrypt %s: %w", field.DBName, err)
+	}
+	return string(plaintext), nil
+}
+
+// rowMerchant reads MerchantID from the model being saved or scanned. On
+// reads it relies on merchant_id being selected before the PII columns,
+// which holds for SELECT * since it precedes them in the table.
+func rowMerchant(ctx context.Context, field *schema.Field, dst reflect.Value) (uuid.UUID, error) {
+	merchantField := field.Schema.LookUpField("MerchantID")
+	if merchantField == nil {
+		return uuid.Nil, fmt.Errorf("pii: %s has no MerchantID field", field.Schema.Name)
+	}
+	value, _ := merchantField.ValueOf(ctx, dst)
+	merchantID, ok := value.(uuid.UUID)
+	if !ok || merchantID == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("pii: %s.%s needs merchant_id to be loaded", field.Schema.Table, field.DBName)
+	}
+	return merchantID, nil
+}
+
+// additionalData binds a ciphertext to its merchant and column so it cannot
+// be copied into another row or field
+func additionalData(merchantID uuid.UUID, field *schema.Field) []byte {
+	return []byte(merchantID.String() + ":" + field.Schema.Table + "." + field.DBName)
+}
+
+func toNullString(v interface{}) sql.NullString {
+	switch s := v.(type) {
+	case sql.NullString:
+		return s
+	case *sql.NullString:
+		if s != nil {
+			return *s
+		}
+	case string:
+		return sql.NullString{String: s, Valid: true}
+	}
+	return sql.NullString{}
+}
+
+func seal(key, plaintext, ad []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("pii: failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, ad)), nil
+}
+
+func unseal(key []byte, sealed string, ad []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, ad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("pii: invalid key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
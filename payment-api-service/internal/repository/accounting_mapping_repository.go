@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AccountingMappingRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewAccountingMappingRepository() *AccountingMappingRepository {
+	return &AccountingMappingRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// Find returns the merchant's mapping for a provider, or the defaults
+func (r *AccountingMappingRepository) Find(merchantID uuid.UUID, provider model.AccountingProvider) (*model.AccountingMapping, error) {
+	var mapping model.AccountingMapping
+	err := r.db.Where("merchant_id = ? AND provider = ?", merchantID, provider).First(&mapping).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.DefaultAccountingMapping(merchantID, provider), nil
+		}
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *AccountingMappingRepository) Upsert(mapping *model.AccountingMapping) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "provider"}},
+		DoUpdates: clause.AssignmentColumns([]string{"bank_account", "fees_account", "refunds_account", "sales_account", "reserve_account", "tax_rate", "updated_at"}),
+	}).Create(mapping).Error
+}
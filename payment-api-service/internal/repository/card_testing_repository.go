@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type CardTestingRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewCardTestingRepository() *CardTestingRepository {
+	return &CardTestingRepository{
+		db:  inits.DB,
+		rdb: inits.RDB,
+		ctx: context.Background(),
+	}
+}
+
+// =========================================================================
+// Redis: sliding counters and active protections
+// =========================================================================
+
+func cardTestingStatsKey(merchantID uuid.UUID, dim model.CardTestingDimension, value string) string {
+	return fmt.Sprintf("card_testing:stats:%s:%s:%s", merchantID, dim, value)
+}
+
+func cardTestingLockdownKey(merchantID uuid.UUID) string {
+	return fmt.Sprintf("card_testing:lockdown:%s", merchantID)
+}
+
+func cardTestingThrottleKey(merchantID uuid.UUID, dim model.CardTestingDimension, value string) string {
+	return fmt.Sprintf("card_testing:throttle:%s:%s:%s", merchantID, dim, value)
+}
+
+// RecordAttempt counts a low-value authorization in the current window and
+// returns the window's running totals
+func (r *CardTestingRepository) RecordAttempt(merchantID uuid.UUID, dim model.CardTestingDimension, value string, declined bool, window time.Duration) (attempts, declines int, err error) {
+	key := cardTestingStatsKey(merchantID, dim, value)
+
+	declineIncr := int64(0)
+	if declined {
+		declineIncr = 1
+	}
+
+	pipe := r.rdb.TxPipeline()
+	attemptsCmd := pipe.HIncrBy(r.ctx, key, "attempts", 1)
+	declinesCmd := pipe.HIncrBy(r.ctx, key, "declines", declineIncr)
+	pipe.ExpireNX(r.ctx, key, window)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, 0, err
+	}
+
+	return int(attemptsCmd.Val()), int(declinesCmd.Val()), nil
+}
+
+// ActivateLockdown switches on a protection for the merchant. It returns
+// false if one is already active, so each burst opens a single incident.
+func (r *CardTestingRepository) ActivateLockdown(merchantID uuid.UUID, action model.CardTestingAction, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(r.ctx, cardTestingLockdownKey(merchantID), string(action), ttl).Result()
+}
+
+// GetLockdown returns the merchant's active protection, if any
+func (r *CardTestingRepository) GetLockdown(merchantID uuid.UUID) (model.CardTestingAction, bool, error) {
+	action, err := r.rdb.Get(r.ctx, cardTestingLockdownKey(merchantID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return model.CardTestingAction(action), true, nil
+}
+
+// ClearLockdown lifts the merchant's active protection
+func (r *CardTestingRepository) ClearLockdown(merchantID uuid.UUID) error {
+	return r.rdb.Del(r.ctx, cardTestingLockdownKey(merchantID)).Err()
+}
+
+// IncrThrottle counts an attempt against the stricter lockdown limit
+func (r *CardTestingRepository) IncrThrottle(merchantID uuid.UUID, dim model.CardTestingDimension, value string, window time.Duration) (int64, error) {
+	key := cardTestingThrottleKey(merchantID, dim, value)
+	count, err := r.rdb.Incr(r.ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		r.rdb.Expire(r.ctx, key, window)
+	}
+	return count, nil
+}
+
+// =========================================================================
+// Postgres: incident log
+// =========================================================================
+
+func (r *CardTestingRepository) CreateIncident(incident *model.CardTestingIncident) error {
+	return r.db.Create(incident).Error
+}
+
+// ListIncidents returns a merchant's incidents, newest first
+func (r *CardTestingRepository) ListIncidents(merchantID uuid.UUID, status model.CardTestingIncidentStatus, limit int) ([]model.CardTestingIncident, error) {
+	var incidents []model.CardTestingIncident
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Limit(limit).Find(&incidents).Error
+	return incidents, err
+}
+
+// ResolveIncident marks an open incident as reviewed
+func (r *CardTestingRepository) ResolveIncident(id, merchantID uuid.UUID) (*model.CardTestingIncident, error) {
+	var incident model.CardTestingIncident
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&incident).Error; err != nil {
+		return nil, err
+	}
+
+	if incident.Status == model.CardTestingIncidentResolved {
+		return &incident, nil
+	}
+
+	now := time.Now()
+	if err := r.db.Model(&incident).Updates(map[string]interface{}{
+		"status":      model.CardTestingIncidentResolved,
+		"resolved_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
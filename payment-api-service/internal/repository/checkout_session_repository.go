@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+)
+
+type CheckoutSessionRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewCheckoutSessionRepository() *CheckoutSessionRepository {
+	return &CheckoutSessionRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// CreateWithIntent stores a session, its lines and shipping options, and
+// the payment intent that collects its total, in one transaction
+func (r *CheckoutSessionRepository) CreateWithIntent(intent *model.PaymentIntent, session *model.CheckoutSession) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(intent).Error; err != nil {
+			return err
+		}
+		session.PaymentIntentID = intent.ID
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		for i := range session.LineItems {
+			item := &session.LineItems[i]
+			item.SessionID = session.ID
+			item.MerchantID = session.MerchantID
+			item.Position = i
+		}
+		if len(session.LineItems) > 0 {
+			if err := tx.Create(&session.LineItems).Error; err != nil {
+				return err
+			}
+		}
+		for i := range session.ShippingOptions {
+			option := &session.ShippingOptions[i]
+			option.SessionID = session.ID
+			option.MerchantID = session.MerchantID
+			option.Position = i
+		}
+		if len(session.ShippingOptions) > 0 {
+			if err := tx.Create(&session.ShippingOptions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByIDAndMerchant loads a session with its lines and shipping options
+func (r *CheckoutSessionRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.CheckoutSession, error) {
+	var session model.CheckoutSession
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return r.loadChildren(&session)
+}
+
+// FindByIntent returns the session behind a payment intent, or nil if the
+// intent was created on its own. The hosted checkout only knows the intent.
+func (r *CheckoutSessionRepository) FindByIntent(intentID uuid.UUID) (*model.CheckoutSession, error) {
+	var session model.CheckoutSession
+	err := tenancy.System(r.db, "public checkout lookup by intent id").
+		Where("payment_intent_id = ?", intentID).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.loadChildren(&session)
+}
+
+// UpdateShipping saves the chosen shipping option and the new totals on the
+// session and its intent
+func (r *CheckoutSessionRepository) UpdateShipping(session *model.CheckoutSession) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.CheckoutSession{}).
+			Where("id = ? AND merchant_id = ?", session.ID, session.MerchantID).
+			Updates(map[string]interface{}{
+				"shipping_option": session.ShippingOption,
+				"shipping_amount": session.ShippingAmount,
+				"amount_total":    session.AmountTotal,
+				"updated_at":      now,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.PaymentIntent{}).
+			Where("id = ? AND merchant_id = ?", session.PaymentIntentID, session.MerchantID).
+			Updates(map[string]interface{}{
+				"amount":     session.AmountTotal,
+				"updated_at": now,
+			}).Error
+	})
+}
+
+func (r *CheckoutSessionRepository) loadChildren(session *model.CheckoutSession) (*model.CheckoutSession, error) {
+	if err := r.db.Where("session_id = ? AND merchant_id = ?", session.ID, session.MerchantID).
+		Order("position ASC").
+		Find(&session.LineItems).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("session_id = ? AND merchant_id = ?", session.ID, session.MerchantID).
+		Order("position ASC").
+		Find(&session.ShippingOptions).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
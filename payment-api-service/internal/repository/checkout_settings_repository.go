@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckoutSettingsRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewCheckoutSettingsRepository() *CheckoutSettingsRepository {
+	return &CheckoutSettingsRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// FindByMerchant returns the merchant's settings, or defaults if none are stored
+func (r *CheckoutSettingsRepository) FindByMerchant(merchantID uuid.UUID) (*model.CheckoutSettings, error) {
+	var settings model.CheckoutSettings
+	err := r.db.Where("merchant_id = ?", merchantID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.CheckoutSettings{MerchantID: merchantID}, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// EnsureRedirectSecret stores secret as the merchant's redirect signing key
+// unless it already has one, and returns the key in effect
+func (r *CheckoutSettingsRepository) EnsureRedirectSecret(merchantID uuid.UUID, secret string) (string, error) {
+	settings := &model.CheckoutSettings{
+		MerchantID:     merchantID,
+		RedirectSecret: sql.NullString{String: secret, Valid: true},
+		UpdatedAt:      time.Now(),
+	}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "redirect_secret"},
+			Value:  gorm.Expr("COALESCE(NULLIF(checkout_settings.redirect_secret, ''), excluded.redirect_secret)"),
+		}},
+	}).Create(settings).Error; err != nil {
+		return "", err
+	}
+
+	stored, err := r.FindByMerchant(merchantID)
+	if err != nil {
+		return "", err
+	}
+	return stored.RedirectSecret.String, nil
+}
+
+// SetRedirectSecret replaces the merchant's redirect signing key
+func (r *CheckoutSettingsRepository) SetRedirectSecret(merchantID uuid.UUID, secret string) error {
+	settings := &model.CheckoutSettings{
+		MerchantID:     merchantID,
+		RedirectSecret: sql.NullString{String: secret, Valid: true},
+		UpdatedAt:      time.Now(),
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"redirect_secret", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// Upsert creates or replaces the merchant's settings
+func (r *CheckoutSettingsRepository) Upsert(settings *model.CheckoutSettings) error {
+	settings.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"allowed_origins", "require_captcha", "accepted_brands", "surcharge_disclosure", "updated_at"}),
+	}).Create(settings).Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DisplaySettingsRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewDisplaySettingsRepository() *DisplaySettingsRepository {
+	return &DisplaySettingsRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// FindByMerchant returns the merchant's display settings, or defaults if none are stored
+func (r *DisplaySettingsRepository) FindByMerchant(merchantID uuid.UUID) (*model.MerchantDisplaySettings, error) {
+	var settings model.MerchantDisplaySettings
+	err := r.db.Where("merchant_id = ?", merchantID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.DefaultDisplaySettings(merchantID), nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Upsert creates or replaces the merchant's display settings
+func (r *DisplaySettingsRepository) Upsert(settings *model.MerchantDisplaySettings) error {
+	settings.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"timezone", "locale", "number_format", "send_email_receipts", "notification_email",
+			"statement_descriptor", "business_name", "logo_url", "brand_color", "updated_at",
+		}),
+	}).Create(settings).Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// EventRepository reads the merchant's event log. payment_events has no
+// merchant_id of its own, so every query joins the payment it belongs to.
+type EventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository() *EventRepository {
+	return &EventRepository{db: inits.DB}
+}
+
+// WithContext returns a copy whose statements carry ctx
+func (r *EventRepository) WithContext(ctx context.Context) *EventRepository {
+	return &EventRepository{db: r.db.WithContext(ctx)}
+}
+
+// MerchantEvent is a payment event with the payment fields a merchant needs
+// to reconcile it
+type MerchantEvent struct {
+	model.PaymentEvent
+	TestMode bool
+	Currency string
+}
+
+// EventFilter narrows the event log. Zero values match everything.
+type EventFilter struct {
+	Type        string // published name, e.g. payment.captured
+	PaymentID   uuid.UUID
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	TestMode    *bool
+}
+
+// outcomeEventTypes are named after the payment's new status
+var outcomeEventTypes = []string{string(model.PaymentTypeAuthorize), string(model.PaymentTypeSale)}
+
+// ListByMerchant returns the merchant's events, newest first
+func (r *EventRepository) ListByMerchant(merchantID uuid.UUID, filter EventFilter, limit, offset int) ([]MerchantEvent, error) {
+	query := r.merchantEvents(merchantID)
+	if filter.Type != "" {
+		name := strings.TrimPrefix(filter.Type, "payment.")
+		query = query.Where(
+			"(payment_events.event_type IN ? AND payment_events.new_status = ?) OR (payment_events.event_type NOT IN ? AND payment_events.event_type = ?)",
+			outcomeEventTypes, name, outcomeEventTypes, name,
+		)
+	}
+	if filter.PaymentID != uuid.Nil {
+		query = query.Where("payment_events.payment_id = ?", filter.PaymentID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("payment_events.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("payment_events.created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.TestMode != nil {
+		query = query.Where("payments.test_mode = ?", *filter.TestMode)
+	}
+
+	var events []MerchantEvent
+	if err := query.Order("payment_events.created_at DESC, payment_events.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FindByIDAndMerchant returns one of the merchant's events
+func (r *EventRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*MerchantEvent, error) {
+	var events []MerchantEvent
+	if err := r.merchantEvents(merchantID).
+		Where("payment_events.id = ?", id).
+		Limit(1).
+		Scan(&events).Error; err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &events[0], nil
+}
+
+func (r *EventRepository) merchantEvents(merchantID uuid.UUID) *gorm.DB {
+	return r.db.Model(&model.PaymentEvent{}).
+		Select("payment_events.*, payments.test_mode, payments.currency").
+		Joins("JOIN payments ON payments.id = payment_events.payment_id").
+		Where("payments.merchant_id = ?", merchantID)
+}
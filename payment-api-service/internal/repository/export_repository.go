@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExportRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewExportRepository() *ExportRepository {
+	return &ExportRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+func (r *ExportRepository) Create(job *model.ExportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ExportRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.ExportJob, error) {
+	var job model.ExportJob
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportRepository) FindByID(id uuid.UUID) (*model.ExportJob, error) {
+	var job model.ExportJob
+	if err := tenancy.System(r.db, "signed download links carry no merchant session").First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *ExportRepository) ListByMerchant(merchantID uuid.UUID, limit, offset int) ([]model.ExportJob, error) {
+	var jobs []model.ExportJob
+	if err := r.db.Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ClaimNextPending moves the oldest pending job to processing. SKIP LOCKED
+// lets several replicas run the worker without picking the same job.
+func (r *ExportRepository) ClaimNextPending() (*model.ExportJob, error) {
+	var job model.ExportJob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tenancy.System(tx, "export worker claims jobs for every merchant").Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", model.ExportStatusPending).
+			Order("created_at ASC").
+			First(&job).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		job.Status = model.ExportStatusProcessing
+		job.StartedAt = sql.NullTime{Time: now, Valid: true}
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":     model.ExportStatusProcessing,
+			"started_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindActiveByResource returns the merchant's pending or processing export
+// of a resource, if any
+func (r *ExportRepository) FindActiveByResource(merchantID uuid.UUID, resource model.ExportResource) (*model.ExportJob, error) {
+	var job model.ExportJob
+	if err := r.db.Where("merchant_id = ? AND resource = ? AND status IN ?", merchantID, resource,
+		[]model.ExportStatus{model.ExportStatusPending, model.ExportStatusProcessing}).
+		First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateProgress records how far the worker is through a multi-section export
+func (r *ExportRepository) UpdateProgress(id uuid.UUID, progress int, step string) error {
+	return r.worker().Model(&model.ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"progress": progress,
+			"step":     step,
+		}).Error
+}
+
+func (r *ExportRepository) MarkCompleted(id uuid.UUID, filePath string, rowCount int, expiresAt time.Time) error {
+	return r.worker().Model(&model.ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.ExportStatusCompleted,
+			"file_path":    filePath,
+			"row_count":    rowCount,
+			"progress":     100,
+			"step":         "",
+			"completed_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *ExportRepository) MarkFailed(id uuid.UUID, reason string) error {
+	return r.worker().Model(&model.ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.ExportStatusFailed,
+			"error":        sql.NullString{String: reason, Valid: true},
+			"completed_at": time.Now(),
+		}).Error
+}
+
+// FindExpired returns completed jobs whose download window has passed
+func (r *ExportRepository) FindExpired(now time.Time) ([]model.ExportJob, error) {
+	var jobs []model.ExportJob
+	if err := r.worker().Where("status = ? AND expires_at < ?", model.ExportStatusCompleted, now).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *ExportRepository) MarkExpired(id uuid.UUID) error {
+	return r.worker().Model(&model.ExportJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":    model.ExportStatusExpired,
+			"file_path": "",
+		}).Error
+}
+
+// ResetStale returns jobs stuck in processing (e.g. after a crash) to pending
+func (r *ExportRepository) ResetStale(olderThan time.Time) error {
+	return r.worker().Model(&model.ExportJob{}).
+		Where("status = ? AND started_at < ?", model.ExportStatusProcessing, olderThan).
+		Update("status", model.ExportStatusPending).Error
+}
+
+// worker is the handle used by the background export worker, which runs
+// outside any merchant request
+func (r *ExportRepository) worker() *gorm.DB {
+	return tenancy.System(r.db, "export worker")
+}
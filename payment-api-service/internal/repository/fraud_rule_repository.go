@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type FraudRuleRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewFraudRuleRepository() *FraudRuleRepository {
+	return &FraudRuleRepository{
+		db:  inits.DB,
+		rdb: inits.RDB,
+		ctx: context.Background(),
+	}
+}
+
+// Create stores a new rule
+func (r *FraudRuleRepository) Create(rule *model.FraudRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindByIDAndMerchant returns one of the merchant's rules
+func (r *FraudRuleRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.FraudRule, error) {
+	var rule model.FraudRule
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByMerchant lists the merchant's rules, enabled or not, oldest first
+func (r *FraudRuleRepository) FindByMerchant(merchantID uuid.UUID) ([]model.FraudRule, error) {
+	var rules []model.FraudRule
+	if err := r.db.Where("merchant_id = ?", merchantID).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Update saves changes to a rule
+func (r *FraudRuleRepository) Update(rule *model.FraudRule) error {
+	rule.UpdatedAt = time.Now()
+	return r.db.Save(rule).Error
+}
+
+// Delete removes one of the merchant's rules
+func (r *FraudRuleRepository) Delete(id, merchantID uuid.UUID) error {
+	result := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).Delete(&model.FraudRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// =========================================================================
+// BIN countries
+// =========================================================================
+
+// FindBINCountry returns the issuing country of the longest known prefix of
+// bin, or "" if none is known
+func (r *FraudRuleRepository) FindBINCountry(bin string) (string, error) {
+	if len(bin) < 6 {
+		return "", nil
+	}
+	prefixes := make([]string, 0, 3)
+	for n := 6; n <= len(bin) && n <= 8; n++ {
+		prefixes = append(prefixes, bin[:n])
+	}
+
+	var entry model.BINCountry
+	err := r.db.Where("prefix IN ?", prefixes).
+		Order("LENGTH(prefix) DESC").
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return entry.Country, nil
+}
+
+// =========================================================================
+// Redis: velocity counters
+// =========================================================================
+
+// Values are hashed so emails and IPs never appear in Redis keys
+func fraudVelocityKey(merchantID uuid.UUID, field, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("fraud:velocity:%s:%s:%s", merchantID, field, hex.EncodeToString(sum[:16]))
+}
+
+// RecordVelocity adds an attempt for the field value and returns, for each
+// window, how many attempts were made within it, this one included. History
+// is kept for the longest window.
+func (r *FraudRuleRepository) RecordVelocity(merchantID uuid.UUID, field, value string, windows []time.Duration) ([]int64, error) {
+	now := time.Now()
+	key := fraudVelocityKey(merchantID, field, value)
+
+	var ttl time.Duration
+	for _, w := range windows {
+		if w > ttl {
+			ttl = w
+		}
+	}
+
+	pipe := r.rdb.TxPipeline()
+	pipe.ZAdd(r.ctx, key, redis.Z{Score: float64(now.UnixNano()), Member: now.UnixNano()})
+	pipe.ZRemRangeByScore(r.ctx, key, "-inf", strconv.FormatInt(now.Add(-ttl).UnixNano(), 10))
+	countCmds := make([]*redis.IntCmd, len(windows))
+	for i, w := range windows {
+		countCmds[i] = pipe.ZCount(r.ctx, key, strconv.FormatInt(now.Add(-w).UnixNano(), 10), "+inf")
+	}
+	pipe.Expire(r.ctx, key, ttl)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, len(windows))
+	for i, cmd := range countCmds {
+		counts[i] = cmd.Val()
+	}
+	return counts, nil
+}
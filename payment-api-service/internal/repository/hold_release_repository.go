@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HoldReleaseRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewHoldReleaseRepository() *HoldReleaseRepository {
+	return &HoldReleaseRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// FindSettings returns the merchant's settings, disabled if none are stored
+func (r *HoldReleaseRepository) FindSettings(merchantID uuid.UUID) (*model.HoldReleaseSettings, error) {
+	var settings model.HoldReleaseSettings
+	err := r.db.Where("merchant_id = ?", merchantID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.HoldReleaseSettings{MerchantID: merchantID}, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpsertSettings creates or replaces the merchant's settings
+func (r *HoldReleaseRepository) UpsertSettings(settings *model.HoldReleaseSettings) error {
+	settings.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// FindTemplates lists the merchant's templates by language
+func (r *HoldReleaseRepository) FindTemplates(merchantID uuid.UUID) ([]model.HoldReleaseTemplate, error) {
+	var templates []model.HoldReleaseTemplate
+	if err := r.db.Where("merchant_id = ?", merchantID).
+		Order("language ASC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// FindTemplate returns the merchant's template for a language, or nil if
+// it has none
+func (r *HoldReleaseRepository) FindTemplate(merchantID uuid.UUID, language string) (*model.HoldReleaseTemplate, error) {
+	var tmpl model.HoldReleaseTemplate
+	err := r.db.Where("merchant_id = ? AND language = ?", merchantID, language).First(&tmpl).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// UpsertTemplate creates or replaces the merchant's template for its language
+func (r *HoldReleaseRepository) UpsertTemplate(tmpl *model.HoldReleaseTemplate) error {
+	tmpl.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updated_by", "updated_at"}),
+	}).Create(tmpl).Error
+}
+
+// DeleteTemplate removes the merchant's template for a language
+func (r *HoldReleaseRepository) DeleteTemplate(merchantID uuid.UUID, language string) error {
+	result := r.db.Where("merchant_id = ? AND language = ?", merchantID, language).Delete(&model.HoldReleaseTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyRepository stores idempotency keys for capture, void and
+// refund in Postgres, so they survive a Redis flush
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository() *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: inits.DB}
+}
+
+// Claim inserts key as processing. If the merchant already used the key it
+// inserts nothing and returns the existing row instead.
+func (r *IdempotencyKeyRepository) Claim(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing model.IdempotencyKey
+	if err := r.db.WithContext(ctx).
+		Where("merchant_id = ? AND test_mode = ? AND key = ?", key.MerchantID, key.TestMode, key.Key).
+		First(&existing).Error; err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// TakeOver locks an expired or abandoned key for a new request. It only
+// succeeds if the row is unchanged since it was read, so two retries racing
+// for the same stale key cannot both run.
+func (r *IdempotencyKeyRepository) TakeOver(ctx context.Context, existing, key *model.IdempotencyKey) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("id = ? AND merchant_id = ? AND updated_at = ?", existing.ID, existing.MerchantID, existing.UpdatedAt).
+		Updates(map[string]interface{}{
+			"operation":       key.Operation,
+			"payment_id":      key.PaymentID,
+			"request_hash":    key.RequestHash,
+			"status":          model.IdempotencyKeyProcessing,
+			"response_status": 0,
+			"response_body":   nil,
+			"locked_until":    key.LockedUntil,
+			"expires_at":      key.ExpiresAt,
+			"updated_at":      time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	key.ID = existing.ID
+	return res.RowsAffected == 1, nil
+}
+
+// Complete stores the response of the request that held the key
+func (r *IdempotencyKeyRepository) Complete(ctx context.Context, key *model.IdempotencyKey, status int, body []byte) error {
+	return r.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("id = ? AND merchant_id = ?", key.ID, key.MerchantID).
+		Updates(map[string]interface{}{
+			"status":          model.IdempotencyKeyCompleted,
+			"response_status": status,
+			"response_body":   body,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// Release frees a key whose request failed, so the client can retry it
+func (r *IdempotencyKeyRepository) Release(ctx context.Context, key *model.IdempotencyKey) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? AND merchant_id = ? AND status = ?", key.ID, key.MerchantID, model.IdempotencyKeyProcessing).
+		Delete(&model.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes keys past their idempotency window
+func (r *IdempotencyKeyRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := tenancy.System(r.db, "idempotency key purge").
+		Where("expires_at < ?", now).
+		Delete(&model.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+)
+
+// IdempotencyRecord is what an idempotency key resolves to: the payment it
+// created or acted on and a hash of the request. Keys used for a capture,
+// void or refund also keep that request's response.
+type IdempotencyRecord struct {
+	PaymentID      uuid.UUID       `json:"payment_id"`
+	RequestHash    string          `json:"request_hash"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Response       json.RawMessage `json:"response,omitempty"`
+}
+
+// IdempotencyRepository caches idempotency keys in Redis so retries are
+// answered without a Postgres lookup
+type IdempotencyRepository struct {
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{
+		rdb: inits.RDB,
+		ctx: context.Background(),
+	}
+}
+
+// Test keys share the idempotency middleware's ":test" namespace so a
+// sandbox reset drops them too
+func idempotencyRecordKey(merchantID uuid.UUID, key string, testMode bool) string {
+	return idempotencyCacheKey("record", merchantID, key, testMode)
+}
+
+func idempotencyCacheKey(kind string, merchantID uuid.UUID, key string, testMode bool) string {
+	namespace := merchantID.String()
+	if testMode {
+		namespace += ":test"
+	}
+	return fmt.Sprintf("idempotency:%s:%s:%s", kind, namespace, key)
+}
+
+// Get returns the cached record for a key, or nil if there is none
+func (r *IdempotencyRepository) Get(merchantID uuid.UUID, key string, testMode bool) (*IdempotencyRecord, error) {
+	data, err := r.rdb.Get(r.ctx, idempotencyRecordKey(merchantID, key, testMode)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Set caches a key for ttl
+func (r *IdempotencyRepository) Set(merchantID uuid.UUID, key string, testMode bool, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(r.ctx, idempotencyRecordKey(merchantID, key, testMode), data, ttl).Err()
+}
+
+// GetOperation returns the cached capture, void or refund for a key, or nil
+// if there is none
+func (r *IdempotencyRepository) GetOperation(merchantID uuid.UUID, key string, testMode bool) (*IdempotencyRecord, error) {
+	data, err := r.rdb.Get(r.ctx, idempotencyCacheKey("operation", merchantID, key, testMode)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// SetOperation caches a completed capture, void or refund for ttl
+func (r *IdempotencyRepository) SetOperation(merchantID uuid.UUID, key string, testMode bool, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(r.ctx, idempotencyCacheKey("operation", merchantID, key, testMode), data, ttl).Err()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+)
+
+// IntentVelocityRepository keeps per-intent attempt history in Redis. Cards
+// are stored by tokenization fingerprint, never by number.
+type IntentVelocityRepository struct {
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewIntentVelocityRepository() *IntentVelocityRepository {
+	return &IntentVelocityRepository{
+		rdb: inits.RDB,
+		ctx: context.Background(),
+	}
+}
+
+func intentCardsKey(intentID uuid.UUID) string {
+	return fmt.Sprintf("intent_velocity:cards:%s", intentID)
+}
+
+func intentAttemptsKey(intentID uuid.UUID) string {
+	return fmt.Sprintf("intent_velocity:attempts:%s", intentID)
+}
+
+// RecordAttempt adds an attempt with the given card to the intent's history
+// and returns the number of distinct cards tried so far and the attempts
+// made within window. Keys expire after ttl.
+func (r *IntentVelocityRepository) RecordAttempt(intentID uuid.UUID, fingerprint string, window, ttl time.Duration) (distinctCards, recentAttempts int64, err error) {
+	now := time.Now()
+	cardsKey := intentCardsKey(intentID)
+	attemptsKey := intentAttemptsKey(intentID)
+
+	pipe := r.rdb.TxPipeline()
+	if fingerprint != "" {
+		pipe.SAdd(r.ctx, cardsKey, fingerprint)
+	}
+	cardsCmd := pipe.SCard(r.ctx, cardsKey)
+	pipe.Expire(r.ctx, cardsKey, ttl)
+
+	pipe.ZAdd(r.ctx, attemptsKey, redis.Z{Score: float64(now.UnixNano()), Member: now.UnixNano()})
+	pipe.ZRemRangeByScore(r.ctx, attemptsKey, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+	attemptsCmd := pipe.ZCard(r.ctx, attemptsKey)
+	pipe.Expire(r.ctx, attemptsKey, ttl)
+
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		return 0, 0, err
+	}
+	return cardsCmd.Val(), attemptsCmd.Val(), nil
+}
+
+// Clear drops an intent's history, e.g. once it is confirmed
+func (r *IntentVelocityRepository) Clear(intentID uuid.UUID) error {
+	return r.rdb.Del(r.ctx, intentCardsKey(intentID), intentAttemptsKey(intentID)).Err()
+}
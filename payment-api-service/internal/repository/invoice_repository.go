@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InvoiceRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewInvoiceRepository() *InvoiceRepository {
+	return &InvoiceRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// WithContext returns a copy whose statements carry ctx, so tenant scoping
+// set on the request applies
+func (r *InvoiceRepository) WithContext(ctx context.Context) *InvoiceRepository {
+	return &InvoiceRepository{db: r.db.WithContext(ctx), ctx: ctx}
+}
+
+// Create stores a new draft and its lines in one transaction
+func (r *InvoiceRepository) Create(invoice *model.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(invoice).Error; err != nil {
+			return err
+		}
+		return createInvoiceLines(tx, invoice)
+	})
+}
+
+// UpdateDraft replaces a draft's fields and lines. It returns
+// gorm.ErrRecordNotFound when the invoice is no longer a draft.
+func (r *InvoiceRepository) UpdateDraft(invoice *model.Invoice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Invoice{}).
+			Where("id = ? AND merchant_id = ? AND status = ?", invoice.ID, invoice.MerchantID, model.InvoiceStatusDraft).
+			Updates(map[string]interface{}{
+				"currency":       invoice.Currency,
+				"subtotal":       invoice.Subtotal,
+				"tax_amount":     invoice.TaxAmount,
+				"amount_total":   invoice.AmountTotal,
+				"customer_email": invoice.CustomerEmail,
+				"customer_name":  invoice.CustomerName,
+				"language":       invoice.Language,
+				"memo":           invoice.Memo,
+				"success_url":    invoice.SuccessURL,
+				"due_date":       invoice.DueDate,
+				"updated_at":     time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("invoice_id = ? AND merchant_id = ?", invoice.ID, invoice.MerchantID).
+			Delete(&model.InvoiceLineItem{}).Error; err != nil {
+			return err
+		}
+		return createInvoiceLines(tx, invoice)
+	})
+}
+
+func createInvoiceLines(tx *gorm.DB, invoice *model.Invoice) error {
+	for i := range invoice.LineItems {
+		item := &invoice.LineItems[i]
+		item.ID = uuid.Nil
+		item.InvoiceID = invoice.ID
+		item.MerchantID = invoice.MerchantID
+		item.Position = i
+	}
+	if len(invoice.LineItems) == 0 {
+		return nil
+	}
+	return tx.Create(&invoice.LineItems).Error
+}
+
+// DeleteDraft deletes a draft and its lines. It returns
+// gorm.ErrRecordNotFound when there is no such draft.
+func (r *InvoiceRepository) DeleteDraft(id, merchantID uuid.UUID, testMode bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND merchant_id = ? AND test_mode = ? AND status = ?", id, merchantID, testMode, model.InvoiceStatusDraft).
+			Delete(&model.Invoice{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("invoice_id = ? AND merchant_id = ?", id, merchantID).
+			Delete(&model.InvoiceLineItem{}).Error
+	})
+}
+
+// FindByIDAndMerchant returns one of the merchant's invoices made in the
+// given mode, with its lines
+func (r *InvoiceRepository) FindByIDAndMerchant(id, merchantID uuid.UUID, testMode bool) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Where("id = ? AND merchant_id = ? AND test_mode = ?", id, merchantID, testMode).
+		First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return r.withLines(r.db, &invoice)
+}
+
+// FindByHostedToken loads an invoice for its public hosted link, which only
+// knows the token
+func (r *InvoiceRepository) FindByHostedToken(token string) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := tenancy.System(r.db, "hosted invoice link resolves the merchant from its token").
+		Where("hosted_token = ?", token).
+		First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return r.withLines(r.db.WithContext(tenancy.WithMerchant(r.ctx, invoice.MerchantID)), &invoice)
+}
+
+func (r *InvoiceRepository) withLines(db *gorm.DB, invoice *model.Invoice) (*model.Invoice, error) {
+	if err := db.Where("invoice_id = ? AND merchant_id = ?", invoice.ID, invoice.MerchantID).
+		Order("position ASC").
+		Find(&invoice.LineItems).Error; err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// ListByMerchant returns the merchant's invoices made in the given mode,
+// newest first, without their lines
+func (r *InvoiceRepository) ListByMerchant(merchantID uuid.UUID, testMode bool, status model.InvoiceStatus, limit, offset int) ([]model.Invoice, error) {
+	query := r.db.Where("merchant_id = ? AND test_mode = ?", merchantID, testMode)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invoices []model.Invoice
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// Transition locks the invoice and hands it to fn, which changes it and
+// returns the columns to save. Nothing is saved if fn fails.
+func (r *InvoiceRepository) Transition(id, merchantID uuid.UUID, testMode bool, fn func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error)) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND merchant_id = ? AND test_mode = ?", id, merchantID, testMode).
+			First(&invoice).Error; err != nil {
+			return err
+		}
+
+		updates, err := fn(tx, &invoice)
+		if err != nil || len(updates) == 0 {
+			return err
+		}
+		updates["updated_at"] = time.Now()
+		return tx.Model(&model.Invoice{}).
+			Where("id = ? AND merchant_id = ?", invoice.ID, invoice.MerchantID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByIDAndMerchant(id, merchantID, testMode)
+}
+
+// NextNumber takes the next invoice number of the merchant's sequence for
+// the mode, inside tx so a rolled back finalize gives it back
+func (r *InvoiceRepository) NextNumber(tx *gorm.DB, merchantID uuid.UUID, testMode bool) (int64, error) {
+	var next int64
+	err := tx.Raw(`INSERT INTO invoice_number_sequences (merchant_id, test_mode, last_number)
+		VALUES (?, ?, 1)
+		ON CONFLICT (merchant_id, test_mode)
+		DO UPDATE SET last_number = invoice_number_sequences.last_number + 1
+		RETURNING last_number`, merchantID, testMode).
+		Scan(&next).Error
+	return next, err
+}
+
+// MarkPaidByIntent marks the payable invoice an intent was collecting as
+// paid. It reports whether there was one.
+func (r *InvoiceRepository) MarkPaidByIntent(intentID, merchantID, paymentID uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&model.Invoice{}).
+		Where("payment_intent_id = ? AND merchant_id = ? AND status IN ?", intentID, merchantID,
+			[]model.InvoiceStatus{model.InvoiceStatusOpen, model.InvoiceStatusUncollectible}).
+		Updates(map[string]interface{}{
+			"status":     model.InvoiceStatusPaid,
+			"payment_id": paymentID.String(),
+			"paid_at":    now,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkPaidByPayment marks the payable invoice whose intent was confirmed
+// with the payment as paid. It reports whether there was one.
+func (r *InvoiceRepository) MarkPaidByPayment(paymentID, merchantID uuid.UUID) (bool, error) {
+	intents := r.db.Model(&model.PaymentIntent{}).
+		Select("id").
+		Where("payment_id = ? AND merchant_id = ?", paymentID, merchantID)
+
+	now := time.Now()
+	result := r.db.Model(&model.Invoice{}).
+		Where("payment_intent_id IN (?) AND merchant_id = ? AND status IN ?", intents, merchantID,
+			[]model.InvoiceStatus{model.InvoiceStatusOpen, model.InvoiceStatusUncollectible}).
+		Updates(map[string]interface{}{
+			"status":     model.InvoiceStatusPaid,
+			"payment_id": paymentID.String(),
+			"paid_at":    now,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
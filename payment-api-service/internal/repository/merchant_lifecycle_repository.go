@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MerchantLifecycleRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewMerchantLifecycleRepository() *MerchantLifecycleRepository {
+	return &MerchantLifecycleRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// FindByMerchant returns the merchant's lifecycle, active if none is stored
+func (r *MerchantLifecycleRepository) FindByMerchant(merchantID uuid.UUID) (*model.MerchantLifecycle, error) {
+	var lifecycle model.MerchantLifecycle
+	err := r.db.Where("merchant_id = ?", merchantID).First(&lifecycle).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.MerchantLifecycle{MerchantID: merchantID, Status: model.MerchantLifecycleActive}, nil
+		}
+		return nil, err
+	}
+	return &lifecycle, nil
+}
+
+// Upsert creates or replaces the merchant's lifecycle
+func (r *MerchantLifecycleRepository) Upsert(lifecycle *model.MerchantLifecycle) error {
+	lifecycle.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "refunds_until", "updated_at"}),
+	}).Create(lifecycle).Error
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	outboxOnce    sync.Once
+	outboxEnabled bool
+)
+
+// OutboxEnabled reports whether lifecycle events are queued for the event
+// broker, which is the case when EVENT_BROKER_URL is set
+func OutboxEnabled() bool {
+	outboxOnce.Do(func() {
+		outboxEnabled = config.GetEnv("EVENT_BROKER_URL") != ""
+	})
+	return outboxEnabled
+}
+
+// OutboxMessage is the envelope published for every lifecycle event
+type OutboxMessage struct {
+	ID            uuid.UUID   `json:"id"`
+	Type          string      `json:"type"`
+	AggregateType string      `json:"aggregate_type"`
+	AggregateID   uuid.UUID   `json:"aggregate_id"`
+	MerchantID    uuid.UUID   `json:"merchant_id"`
+	TestMode      bool        `json:"test_mode"`
+	OccurredAt    time.Time   `json:"occurred_at"`
+	Data          interface{} `json:"data"`
+}
+
+// PaymentEventType names a payment event as it is published and listed.
+// Authorizations and sales are named after their outcome
+// (payment.authorized, payment.captured, payment.failed); everything else
+// after the event itself.
+func PaymentEventType(event *model.PaymentEvent) string {
+	switch model.PaymentType(event.EventType) {
+	case model.PaymentTypeAuthorize, model.PaymentTypeSale:
+		return "payment." + string(event.NewStatus)
+	}
+	return "payment." + event.EventType
+}
+
+// createPaymentOutboxEvent queues event inside tx
+func createPaymentOutboxEvent(tx *gorm.DB, event *model.PaymentEvent) error {
+	if !OutboxEnabled() {
+		return nil
+	}
+
+	var owner struct {
+		MerchantID uuid.UUID
+		TestMode   bool
+	}
+	if err := tenancy.System(tx, "outbox: resolve the merchant of a payment event").
+		Model(&model.Payment{}).
+		Select("merchant_id", "test_mode").
+		Where("id = ?", event.PaymentID).
+		Take(&owner).Error; err != nil {
+		return fmt.Errorf("failed to resolve merchant of payment %s: %w", event.PaymentID, err)
+	}
+
+	msg := OutboxMessage{
+		ID:            uuid.New(),
+		Type:          PaymentEventType(event),
+		AggregateType: "payment",
+		AggregateID:   event.PaymentID,
+		MerchantID:    owner.MerchantID,
+		TestMode:      owner.TestMode,
+		OccurredAt:    event.CreatedAt.UTC(),
+		Data:          event,
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{
+		ID:            msg.ID,
+		MerchantID:    msg.MerchantID,
+		EventType:     msg.Type,
+		AggregateType: msg.AggregateType,
+		AggregateID:   msg.AggregateID,
+		TestMode:      msg.TestMode,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// OutboxRepository reads the outbox for the relay, across all merchants
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{db: inits.DB}
+}
+
+// ProcessPending locks up to limit due events, oldest first, and hands them
+// to publish. publish returns how many of them, from the start, the broker
+// confirmed; those are marked published. When it also returns an error, the
+// next event is scheduled for a retry after retryAfter(attempts) and the
+// error is returned. Rows stay locked until this returns, so concurrent
+// relays skip them.
+func (r *OutboxRepository) ProcessPending(limit int, retryAfter func(attempts int) time.Duration, publish func(events []model.OutboxEvent) (int, error)) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		tx = tenancy.System(tx, "outbox relay publishes every merchant's events")
+
+		var events []model.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		published, publishErr = publish(events)
+		if published > 0 {
+			ids := make([]uuid.UUID, published)
+			for i := range ids {
+				ids[i] = events[i].ID
+			}
+			if err := tx.Model(&model.OutboxEvent{}).
+				Where("id IN ?", ids).
+				Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		if publishErr == nil || published >= len(events) {
+			return nil
+		}
+		failed := events[published]
+		return tx.Model(&model.OutboxEvent{}).
+			Where("id = ?", failed.ID).
+			Updates(map[string]interface{}{
+				"attempts":        failed.Attempts + 1,
+				"last_error":      publishErr.Error(),
+				"next_attempt_at": time.Now().Add(retryAfter(failed.Attempts + 1)),
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, publishErr
+}
+
+// DeletePublishedBefore removes events published before cutoff
+func (r *OutboxRepository) DeletePublishedBefore(cutoff time.Time) (int64, error) {
+	res := tenancy.System(r.db, "outbox purge of published events").
+		Where("published_at IS NOT NULL AND published_at < ?", cutoff).
+		Delete(&model.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -24,6 +25,12 @@ func NewPaymentIntentRepository() *PaymentIntentRepository {
 	}
 }
 
+// WithContext returns a copy whose statements carry ctx, so tenant scoping
+// set on the request applies to lookups that only key on the primary id
+func (r *PaymentIntentRepository) WithContext(ctx context.Context) *PaymentIntentRepository {
+	return &PaymentIntentRepository{db: r.db.WithContext(ctx), ctx: ctx}
+}
+
 // =========================================================================
 // Create Operations
 // =========================================================================
@@ -48,9 +55,21 @@ func (r *PaymentIntentRepository) FindByID(id uuid.UUID) (*model.PaymentIntent,
 	return &intent, nil
 }
 
+// FindPublicByID loads an intent for the browser-facing checkout, which
+// only knows the intent id
+func (r *PaymentIntentRepository) FindPublicByID(id uuid.UUID) (*model.PaymentIntent, error) {
+	var intent model.PaymentIntent
+	if err := tenancy.System(r.db, "public checkout lookup by intent id").
+		Where("id = ?", id).First(&intent).Error; err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
 func (r *PaymentIntentRepository) FindByClientSecret(clientSecret string) (*model.PaymentIntent, error) {
 	var intent model.PaymentIntent
-	if err := r.db.Where("client_secret = ?", clientSecret).First(&intent).Error; err != nil {
+	if err := tenancy.System(r.db, "checkout resolves the merchant from the client secret").
+		Where("client_secret = ?", clientSecret).First(&intent).Error; err != nil {
 		return nil, err
 	}
 	return &intent, nil
@@ -143,9 +162,10 @@ func (r *PaymentIntentRepository) MarkExpired(id uuid.UUID) error {
 
 func (r *PaymentIntentRepository) FindExpired() ([]model.PaymentIntent, error) {
 	var intents []model.PaymentIntent
-	if err := r.db.Where("status = ? AND expires_at < ?",
-		model.PaymentIntentStatusAwaitingPayment,
-		time.Now()).
+	if err := tenancy.System(r.db, "expiry sweep covers all merchants").
+		Where("status = ? AND expires_at < ?",
+			model.PaymentIntentStatusAwaitingPayment,
+			time.Now()).
 		Find(&intents).Error; err != nil {
 		return nil, err
 	}
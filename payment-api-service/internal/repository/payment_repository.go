@@ -9,7 +9,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -26,6 +28,12 @@ func NewPaymentRepository() *PaymentRepository {
 	}
 }
 
+// WithContext returns a copy whose statements carry ctx, so tenant scoping
+// set on the request applies to lookups that only key on the primary id
+func (r *PaymentRepository) WithContext(ctx context.Context) *PaymentRepository {
+	return &PaymentRepository{db: r.db.WithContext(ctx), ctx: ctx}
+}
+
 func (r *PaymentRepository) Create(payment *model.Payment) error {
 	if err := r.db.Create(payment).Error; err != nil {
 		logger.Log.Error("Failed to create payment", zap.Error(err))
@@ -38,8 +46,16 @@ func (r *PaymentRepository) Create(payment *model.Payment) error {
 	return nil
 }
 
+// CreateEvent records a payment event and, when event streaming is on,
+// queues it for the broker in the same transaction
 func (r *PaymentRepository) CreateEvent(event *model.PaymentEvent) error {
-	if err := r.db.Create(event).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return createPaymentOutboxEvent(tx, event)
+	})
+	if err != nil {
 		logger.Log.Error("Failed to create payment event", zap.Error(err))
 		return err
 	}
@@ -67,17 +83,35 @@ func (r *PaymentRepository) FindByID(id uuid.UUID) (*model.Payment, error) {
 	return &payment, nil
 }
 
+// FindByIDAndMerchant finds one of the merchant's payments. A request
+// context scoped to a mode only finds payments made in that mode.
 func (r *PaymentRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.Payment, error) {
 	var payment model.Payment
-	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&payment).Error; err != nil {
+	if err := r.sameMode().Where("id = ? AND merchant_id = ?", id, merchantID).First(&payment).Error; err != nil {
 		return nil, err
 	}
 	return &payment, nil
 }
 
-func (r *PaymentRepository) FindByIdempotencyKey(merchantID uuid.UUID, key string) (*model.Payment, error) {
+func (r *PaymentRepository) FindByTransactionID(transactionID, merchantID uuid.UUID) (*model.Payment, error) {
 	var payment model.Payment
-	if err := r.db.Where("merchant_id = ? AND idempotency_key = ?", merchantID, key).First(&payment).Error; err != nil {
+	if err := r.sameMode().Where("transaction_id = ? AND merchant_id = ?", transactionID, merchantID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// sameMode restricts a lookup to the mode of the request's API key, if any
+func (r *PaymentRepository) sameMode() *gorm.DB {
+	if testMode, ok := tenancy.TestModeFrom(r.ctx); ok {
+		return r.db.Where("test_mode = ?", testMode)
+	}
+	return r.db
+}
+
+func (r *PaymentRepository) FindByIdempotencyKey(merchantID uuid.UUID, key string, testMode bool) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.Where("merchant_id = ? AND idempotency_key = ? AND test_mode = ?", merchantID, key, testMode).First(&payment).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -98,6 +132,73 @@ func (r *PaymentRepository) FindByMerchant(merchantID uuid.UUID, limit, offset i
 	return payments, nil
 }
 
+// PaymentCursor marks where a page of payments ended. Pages are ordered by
+// created_at then id, newest first, so payments made while paging never
+// shift later pages.
+type PaymentCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListPage returns up to limit of the merchant's payments after the cursor,
+// newest first. A nil cursor starts at the newest payment; empty status and
+// nil dates don't filter.
+func (r *PaymentRepository) ListPage(merchantID uuid.UUID, status model.PaymentStatus, from, to *time.Time, after *PaymentCursor, limit int) ([]model.Payment, error) {
+	query := r.sameMode().Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var payments []model.Payment
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// FirstPaymentAt returns when the merchant's oldest payment was created
+func (r *PaymentRepository) FirstPaymentAt(merchantID uuid.UUID) (time.Time, error) {
+	var payment model.Payment
+	if err := r.db.Select("created_at").
+		Where("merchant_id = ?", merchantID).
+		Order("created_at ASC").
+		First(&payment).Error; err != nil {
+		return time.Time{}, err
+	}
+	return payment.CreatedAt, nil
+}
+
+// FirstSuccessfulPaymentAt returns when the merchant's first authorized
+// payment in test or live mode was created, or nil if there is none yet
+func (r *PaymentRepository) FirstSuccessfulPaymentAt(merchantID uuid.UUID, testMode bool) (*time.Time, error) {
+	var payments []model.Payment
+	if err := r.db.Select("created_at").
+		Where("merchant_id = ? AND test_mode = ?", merchantID, testMode).
+		Where("status NOT IN ?", []model.PaymentStatus{
+			model.PaymentStatusPending,
+			model.PaymentStatusRequiresAction,
+			model.PaymentStatusFailed,
+		}).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	return &payments[0].CreatedAt, nil
+}
+
 func (r *PaymentRepository) FindByStatus(merchantID uuid.UUID, status model.PaymentStatus, limit int) ([]model.Payment, error) {
 	var payments []model.Payment
 	if err := r.db.Where("merchant_id = ? AND status = ?", merchantID, status).
@@ -109,6 +210,35 @@ func (r *PaymentRepository) FindByStatus(merchantID uuid.UUID, status model.Paym
 	return payments, nil
 }
 
+// FindInRangeBatches streams a merchant's payments created in [from, to) to
+// fn in batches, oldest first
+func (r *PaymentRepository) FindInRangeBatches(merchantID uuid.UUID, status model.PaymentStatus, from, to time.Time, batchSize int, fn func([]model.Payment) error) error {
+	for offset := 0; ; offset += batchSize {
+		var batch []model.Payment
+		query := r.db.Where("merchant_id = ? AND created_at >= ? AND created_at < ?", merchantID, from, to)
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if err := query.
+			Order("created_at ASC, id ASC").
+			Limit(batchSize).
+			Offset(offset).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
 func (r *PaymentRepository) GetPaymentEvents(paymentID uuid.UUID) ([]model.PaymentEvent, error) {
 	var events []model.PaymentEvent
 	if err := r.db.Where("payment_id = ?", paymentID).
@@ -119,6 +249,12 @@ func (r *PaymentRepository) GetPaymentEvents(paymentID uuid.UUID) ([]model.Payme
 	return events, nil
 }
 
+func (r *PaymentRepository) CountPaymentEvents(paymentID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PaymentEvent{}).Where("payment_id = ?", paymentID).Count(&count).Error
+	return count, err
+}
+
 // =========================================================================
 // Update Operations
 // =========================================================================
@@ -148,14 +284,34 @@ func (r *PaymentRepository) UpdateStatus(id uuid.UUID, status model.PaymentStatu
 	return nil
 }
 
-func (r *PaymentRepository) MarkCaptured(id uuid.UUID) error {
+// TransitionStatus moves a payment from one status to another. It reports
+// false if the payment had already left the from status.
+func (r *PaymentRepository) TransitionStatus(id uuid.UUID, from, to model.PaymentStatus) (bool, error) {
+	result := r.db.Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{
+			"status":     to,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	r.invalidateCache(id)
+	return result.RowsAffected > 0, nil
+}
+
+// MarkCaptured stores the outcome of a capture: the authorization's status
+// and the total captured so far
+func (r *PaymentRepository) MarkCaptured(id uuid.UUID, status model.PaymentStatus, capturedAmount int64) error {
 	now := time.Now()
 	if err := r.db.Model(&model.Payment{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"status":      model.PaymentStatusCaptured,
-			"captured_at": now,
-			"updated_at":  now,
+			"status":          status,
+			"captured_amount": capturedAmount,
+			"captured_at":     now,
+			"updated_at":      now,
 		}).Error; err != nil {
 		return err
 	}
@@ -169,9 +325,10 @@ func (r *PaymentRepository) MarkVoided(id uuid.UUID) error {
 	if err := r.db.Model(&model.Payment{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"status":     model.PaymentStatusVoided,
-			"voided_at":  now,
-			"updated_at": now,
+			"status":        model.PaymentStatusVoided,
+			"voided_reason": model.VoidReasonRequested,
+			"voided_at":     now,
+			"updated_at":    now,
 		}).Error; err != nil {
 		return err
 	}
@@ -180,6 +337,59 @@ func (r *PaymentRepository) MarkVoided(id uuid.UUID) error {
 	return nil
 }
 
+// FindAuthorizedBeforeBatches calls fn with every still-authorized payment,
+// across merchants, created before cutoff
+func (r *PaymentRepository) FindAuthorizedBeforeBatches(cutoff time.Time, batchSize int, fn func([]model.Payment) error) error {
+	var batch []model.Payment
+	return tenancy.System(r.db, "authorization expiry worker checks old authorizations").
+		Where("status = ? AND created_at < ?", model.PaymentStatusAuthorized, cutoff).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// MarkExpired records that the transaction service auto-voided the
+// payment's authorization. It reports false if the payment was no longer
+// authorized.
+func (r *PaymentRepository) MarkExpired(id uuid.UUID, voidedAt time.Time) (bool, error) {
+	result := tenancy.System(r.db, "authorization expiry worker voids expired payments").
+		Model(&model.Payment{}).
+		Where("id = ? AND status = ?", id, model.PaymentStatusAuthorized).
+		Updates(map[string]interface{}{
+			"status":        model.PaymentStatusVoided,
+			"voided_reason": model.VoidReasonExpired,
+			"voided_at":     voidedAt,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	r.invalidateCache(id)
+	return result.RowsAffected > 0, nil
+}
+
+// FindExpiredUnsummarized returns auto-voided payments, across merchants,
+// voided since from that have not been in a daily summary yet
+func (r *PaymentRepository) FindExpiredUnsummarized(from time.Time) ([]model.Payment, error) {
+	var payments []model.Payment
+	if err := tenancy.System(r.db, "authorization expiry worker builds daily summaries").
+		Where("voided_reason = ? AND voided_at >= ? AND expiry_summary_sent_at IS NULL", model.VoidReasonExpired, from).
+		Order("merchant_id, voided_at").
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// MarkExpirySummarySent records that the payments went out in a summary
+func (r *PaymentRepository) MarkExpirySummarySent(ids []uuid.UUID, sentAt time.Time) error {
+	return tenancy.System(r.db, "authorization expiry worker records sent summaries").
+		Model(&model.Payment{}).
+		Where("id IN ?", ids).
+		Update("expiry_summary_sent_at", sentAt).Error
+}
+
 func (r *PaymentRepository) MarkRefunded(id uuid.UUID) error {
 	now := time.Now()
 	if err := r.db.Model(&model.Payment{}).
@@ -200,70 +410,53 @@ func (r *PaymentRepository) MarkRefunded(id uuid.UUID) error {
 // Statistics & Analytics
 // =========================================================================
 
+// PaymentStatistics counts payment attempts, including those stopped before
+// they reached the transaction service, such as fraud declines. Rates are
+// percentages.
 type PaymentStatistics struct {
-	TotalPayments     int64
-	TotalAmount       int64
-	AuthorizedAmount  int64
-	CapturedAmount    int64
-	RefundedAmount    int64
-	SuccessRate       float64
-	AverageFraudScore float64
-}
-
-func (r *PaymentRepository) GetStatistics(merchantID uuid.UUID, startDate, endDate time.Time) (*PaymentStatistics, error) {
-	stats := &PaymentStatistics{}
-
-	// Total payments
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND created_at BETWEEN ? AND ?", merchantID, startDate, endDate).
-		Count(&stats.TotalPayments)
-
-	// Total amount
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND created_at BETWEEN ? AND ?", merchantID, startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&stats.TotalAmount)
-
-	// Authorized amount
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND status = ? AND created_at BETWEEN ? AND ?",
-			merchantID, model.PaymentStatusAuthorized, startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&stats.AuthorizedAmount)
-
-	// Captured amount
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND status = ? AND created_at BETWEEN ? AND ?",
-			merchantID, model.PaymentStatusCaptured, startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&stats.CapturedAmount)
-
-	// Refunded amount
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND status = ? AND created_at BETWEEN ? AND ?",
-			merchantID, model.PaymentStatusRefunded, startDate, endDate).
-		Select("COALESCE(SUM(amount), 0)").
-		Scan(&stats.RefundedAmount)
-
-	// Success rate
-	var successCount int64
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND status IN ? AND created_at BETWEEN ? AND ?",
-			merchantID, []model.PaymentStatus{model.PaymentStatusAuthorized, model.PaymentStatusCaptured},
-			startDate, endDate).
-		Count(&successCount)
+	TotalPayments         int64   `json:"total_payments"`
+	SuccessfulPayments    int64   `json:"successful_payments"`
+	FailedPayments        int64   `json:"failed_payments"`
+	FraudDeclinedPayments int64   `json:"fraud_declined_payments"`
+	RefundedPayments      int64   `json:"refunded_payments"`
+	SuccessRate           float64 `json:"success_rate"`
+	RefundRate            float64 `json:"refund_rate"` // Share of captured payments that were refunded
+	AverageFraudScore     float64 `json:"average_fraud_score"`
+}
 
-	if stats.TotalPayments > 0 {
-		stats.SuccessRate = float64(successCount) / float64(stats.TotalPayments) * 100
-	}
+// GetStatistics aggregates the merchant's payments made in the given mode
+// and created in [startDate, endDate) in a single query. Refunded payments
+// count as successful.
+func (r *PaymentRepository) GetStatistics(merchantID uuid.UUID, testMode bool, startDate, endDate time.Time) (*PaymentStatistics, error) {
+	captured := []model.PaymentStatus{model.PaymentStatusCaptured, model.PaymentStatusPartiallyCaptured, model.PaymentStatusRefunded}
+	successful := append([]model.PaymentStatus{model.PaymentStatusAuthorized}, captured...)
 
-	// Average fraud score
-	r.db.Model(&model.Payment{}).
-		Where("merchant_id = ? AND created_at BETWEEN ? AND ?", merchantID, startDate, endDate).
-		Select("COALESCE(AVG(fraud_score), 0)").
-		Scan(&stats.AverageFraudScore)
+	var row struct {
+		PaymentStatistics
+		CapturedPayments int64
+	}
+	if err := r.db.Model(&model.Payment{}).
+		Select(`COUNT(*) AS total_payments,
+			COUNT(*) FILTER (WHERE status IN ?) AS successful_payments,
+			COUNT(*) FILTER (WHERE status = ?) AS failed_payments,
+			COUNT(*) FILTER (WHERE fraud_decision = ?) AS fraud_declined_payments,
+			COUNT(*) FILTER (WHERE status = ?) AS refunded_payments,
+			COUNT(*) FILTER (WHERE status IN ?) AS captured_payments,
+			COALESCE(AVG(fraud_score), 0) AS average_fraud_score`,
+			successful, model.PaymentStatusFailed, "decline", model.PaymentStatusRefunded, captured).
+		Where("merchant_id = ? AND test_mode = ? AND created_at >= ? AND created_at < ?", merchantID, testMode, startDate, endDate).
+		Scan(&row).Error; err != nil {
+		return nil, err
+	}
 
-	return stats, nil
+	stats := row.PaymentStatistics
+	if stats.TotalPayments > 0 {
+		stats.SuccessRate = float64(stats.SuccessfulPayments) / float64(stats.TotalPayments) * 100
+	}
+	if row.CapturedPayments > 0 {
+		stats.RefundRate = float64(stats.RefundedPayments) / float64(row.CapturedPayments) * 100
+	}
+	return &stats, nil
 }
 
 // =========================================================================
@@ -279,6 +472,7 @@ func (r *PaymentRepository) cachePayment(payment *model.Payment) {
 func (r *PaymentRepository) getCachedPayment(id uuid.UUID) *model.Payment {
 	key := fmt.Sprintf("payment:%s", id.String())
 	data, err := inits.RDB.Get(r.ctx, key).Result()
+	metrics.CacheLookup("payment", err == nil)
 	if err != nil {
 		return nil
 	}
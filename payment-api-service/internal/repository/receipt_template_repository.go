@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReceiptTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewReceiptTemplateRepository() *ReceiptTemplateRepository {
+	return &ReceiptTemplateRepository{
+		db: inits.DB,
+	}
+}
+
+// FindTemplates lists the merchant's templates by kind and language
+func (r *ReceiptTemplateRepository) FindTemplates(merchantID uuid.UUID) ([]model.ReceiptTemplate, error) {
+	var templates []model.ReceiptTemplate
+	if err := r.db.Where("merchant_id = ?", merchantID).
+		Order("kind ASC, language ASC").
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// FindTemplate returns the merchant's template for a kind and language, or
+// nil if it has none
+func (r *ReceiptTemplateRepository) FindTemplate(merchantID uuid.UUID, kind, language string) (*model.ReceiptTemplate, error) {
+	var tmpl model.ReceiptTemplate
+	err := r.db.Where("merchant_id = ? AND kind = ? AND language = ?", merchantID, kind, language).First(&tmpl).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// UpsertTemplate creates or replaces the merchant's template for its kind
+// and language
+func (r *ReceiptTemplateRepository) UpsertTemplate(tmpl *model.ReceiptTemplate) error {
+	tmpl.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "kind"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updated_by", "updated_at"}),
+	}).Create(tmpl).Error
+}
+
+// DeleteTemplate removes the merchant's template for a kind and language
+func (r *ReceiptTemplateRepository) DeleteTemplate(merchantID uuid.UUID, kind, language string) error {
+	result := r.db.Where("merchant_id = ? AND kind = ? AND language = ?", merchantID, kind, language).Delete(&model.ReceiptTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
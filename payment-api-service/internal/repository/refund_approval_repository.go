@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundApprovalRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewRefundApprovalRepository() *RefundApprovalRepository {
+	return &RefundApprovalRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// Create stores a new approval request
+func (r *RefundApprovalRepository) Create(approval *model.RefundApproval) error {
+	return r.db.Create(approval).Error
+}
+
+// FindByIDAndMerchant returns one of the merchant's approval requests
+func (r *RefundApprovalRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.RefundApproval, error) {
+	var approval model.RefundApproval
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&approval).Error; err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// ListByMerchant returns the merchant's approval requests, newest first,
+// optionally filtered by status
+func (r *RefundApprovalRepository) ListByMerchant(merchantID uuid.UUID, status model.RefundApprovalStatus, limit int) ([]model.RefundApproval, error) {
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var approvals []model.RefundApproval
+	if err := query.Order("created_at DESC").Limit(limit).Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// ListByPayment returns every approval request raised on a payment, oldest first
+func (r *RefundApprovalRepository) ListByPayment(paymentID, merchantID uuid.UUID) ([]model.RefundApproval, error) {
+	var approvals []model.RefundApproval
+	if err := r.db.Where("payment_id = ? AND merchant_id = ?", paymentID, merchantID).
+		Order("created_at ASC").
+		Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// HasPending reports whether the payment already has a refund awaiting approval
+func (r *RefundApprovalRepository) HasPending(paymentID, merchantID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RefundApproval{}).
+		Where("payment_id = ? AND merchant_id = ? AND status = ?", paymentID, merchantID, model.RefundApprovalPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Decide moves a pending, unexpired request to status. It returns false if
+// another decision or the expiry sweep got there first.
+func (r *RefundApprovalRepository) Decide(id, merchantID, decidedBy uuid.UUID, status model.RefundApprovalStatus, note string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&model.RefundApproval{}).
+		Where("id = ? AND merchant_id = ? AND status = ? AND expires_at > ?", id, merchantID, model.RefundApprovalPending, now).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"decided_by":    decidedBy,
+			"decision_note": note,
+			"decided_at":    now,
+			"updated_at":    now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Reopen puts an approved request back to pending when its refund failed,
+// so it can be approved again or left to expire
+func (r *RefundApprovalRepository) Reopen(id, merchantID uuid.UUID) error {
+	return r.db.Model(&model.RefundApproval{}).
+		Where("id = ? AND merchant_id = ? AND status = ?", id, merchantID, model.RefundApprovalApproved).
+		Updates(map[string]interface{}{
+			"status":        model.RefundApprovalPending,
+			"decided_by":    nil,
+			"decision_note": nil,
+			"decided_at":    nil,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// SetRefundID records the refund an approval produced
+func (r *RefundApprovalRepository) SetRefundID(id, merchantID uuid.UUID, refundID string) error {
+	return r.db.Model(&model.RefundApproval{}).
+		Where("id = ? AND merchant_id = ?", id, merchantID).
+		Update("refund_id", refundID).Error
+}
+
+// ExpireOverdue marks every pending request past its expiry as expired and
+// returns the ones it expired
+func (r *RefundApprovalRepository) ExpireOverdue(now time.Time) ([]model.RefundApproval, error) {
+	var overdue []model.RefundApproval
+	if err := r.sweep().Where("status = ? AND expires_at <= ?", model.RefundApprovalPending, now).
+		Find(&overdue).Error; err != nil {
+		return nil, err
+	}
+
+	expired := make([]model.RefundApproval, 0, len(overdue))
+	for _, approval := range overdue {
+		// The status check skips requests decided since they were read
+		result := r.sweep().Model(&model.RefundApproval{}).
+			Where("id = ? AND status = ?", approval.ID, model.RefundApprovalPending).
+			Updates(map[string]interface{}{
+				"status":     model.RefundApprovalExpired,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return expired, result.Error
+		}
+		if result.RowsAffected == 1 {
+			approval.Status = model.RefundApprovalExpired
+			expired = append(expired, approval)
+		}
+	}
+	return expired, nil
+}
+
+// FindPolicy returns the merchant's policy, or nil if it has none
+func (r *RefundApprovalRepository) FindPolicy(merchantID uuid.UUID) (*model.RefundApprovalPolicy, error) {
+	var policy model.RefundApprovalPolicy
+	err := r.db.Where("merchant_id = ?", merchantID).First(&policy).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpsertPolicy creates or replaces the merchant's policy
+func (r *RefundApprovalRepository) UpsertPolicy(policy *model.RefundApprovalPolicy) error {
+	policy.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"threshold", "updated_at"}),
+	}).Create(policy).Error
+}
+
+func (r *RefundApprovalRepository) sweep() *gorm.DB {
+	return tenancy.System(r.db, "refund approval expiry sweep")
+}
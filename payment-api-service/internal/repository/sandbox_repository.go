@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// SandboxRepository removes the data merchants create with test-mode keys
+type SandboxRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewSandboxRepository() *SandboxRepository {
+	return &SandboxRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// TestDataCounts reports how many rows a reset removed
+type TestDataCounts struct {
+	Payments          int64 `json:"payments"`
+	PaymentIntents    int64 `json:"payment_intents"`
+	PaymentEvents     int64 `json:"payment_events"`
+	WebhookDeliveries int64 `json:"webhook_deliveries"`
+	Subscriptions     int64 `json:"subscriptions"`
+	Invoices          int64 `json:"invoices"`
+}
+
+// DeleteTestData hard deletes the merchant's test payments, intents,
+// subscriptions, invoices and everything hanging off them, in one
+// transaction. It returns the deleted payment IDs so their cache entries
+// can be dropped.
+func (r *SandboxRepository) DeleteTestData(merchantID uuid.UUID) (*TestDataCounts, []uuid.UUID, error) {
+	counts := &TestDataCounts{}
+	var paymentIDs []uuid.UUID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Payment{}).
+			Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Pluck("id", &paymentIDs).Error; err != nil {
+			return fmt.Errorf("failed to list test payments: %w", err)
+		}
+
+		if len(paymentIDs) > 0 {
+			res := tx.Where("merchant_id = ? AND payment_id IN ?", merchantID, paymentIDs).
+				Delete(&model.WebhookDelivery{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to delete test webhook deliveries: %w", res.Error)
+			}
+			counts.WebhookDeliveries = res.RowsAffected
+
+			res = tx.Where("payment_id IN ?", paymentIDs).Delete(&model.PaymentEvent{})
+			if res.Error != nil {
+				return fmt.Errorf("failed to delete test payment events: %w", res.Error)
+			}
+			counts.PaymentEvents = res.RowsAffected
+		}
+
+		testSubscriptions := tx.Model(&model.Subscription{}).
+			Select("id").
+			Where("merchant_id = ? AND test_mode = ?", merchantID, true)
+		res := tx.Where("merchant_id = ? AND subscription_id IN (?)", merchantID, testSubscriptions).
+			Delete(&model.SubscriptionCharge{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete test subscription charges: %w", res.Error)
+		}
+
+		res = tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.Subscription{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete test subscriptions: %w", res.Error)
+		}
+		counts.Subscriptions = res.RowsAffected
+
+		testInvoices := tx.Model(&model.Invoice{}).
+			Select("id").
+			Where("merchant_id = ? AND test_mode = ?", merchantID, true)
+		if err := tx.Where("merchant_id = ? AND invoice_id IN (?)", merchantID, testInvoices).
+			Delete(&model.InvoiceLineItem{}).Error; err != nil {
+			return fmt.Errorf("failed to delete test invoice lines: %w", err)
+		}
+
+		res = tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.Invoice{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete test invoices: %w", res.Error)
+		}
+		counts.Invoices = res.RowsAffected
+
+		// Test numbering starts over at INV-000001
+		if err := tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.InvoiceNumberSequence{}).Error; err != nil {
+			return fmt.Errorf("failed to reset test invoice numbers: %w", err)
+		}
+
+		res = tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.PaymentIntent{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete test payment intents: %w", res.Error)
+		}
+		counts.PaymentIntents = res.RowsAffected
+
+		if err := tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.IdempotencyKey{}).Error; err != nil {
+			return fmt.Errorf("failed to delete test idempotency keys: %w", err)
+		}
+
+		res = tx.Where("merchant_id = ? AND test_mode = ?", merchantID, true).
+			Delete(&model.Payment{})
+		if res.Error != nil {
+			return fmt.Errorf("failed to delete test payments: %w", res.Error)
+		}
+		counts.Payments = res.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return counts, paymentIDs, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// CreatePlan stores a new plan
+func (r *SubscriptionRepository) CreatePlan(plan *model.SubscriptionPlan) error {
+	return r.db.Create(plan).Error
+}
+
+// FindPlan returns one of the merchant's plans
+func (r *SubscriptionRepository) FindPlan(id, merchantID uuid.UUID) (*model.SubscriptionPlan, error) {
+	var plan model.SubscriptionPlan
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&plan).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ListPlans returns the merchant's plans, newest first
+func (r *SubscriptionRepository) ListPlans(merchantID uuid.UUID, includeArchived bool) ([]model.SubscriptionPlan, error) {
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if !includeArchived {
+		query = query.Where("active = ?", true)
+	}
+
+	var plans []model.SubscriptionPlan
+	if err := query.Order("created_at DESC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// ArchivePlan stops new subscriptions to a plan; existing ones keep billing
+func (r *SubscriptionRepository) ArchivePlan(id, merchantID uuid.UUID) error {
+	result := r.db.Model(&model.SubscriptionPlan{}).
+		Where("id = ? AND merchant_id = ?", id, merchantID).
+		Updates(map[string]interface{}{"active": false, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Create stores a new subscription
+func (r *SubscriptionRepository) Create(sub *model.Subscription) error {
+	return r.db.Create(sub).Error
+}
+
+// FindByIDAndMerchant returns one of the merchant's subscriptions
+func (r *SubscriptionRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.Subscription, error) {
+	var sub model.Subscription
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListByMerchant returns the merchant's subscriptions, newest first,
+// optionally filtered by status
+func (r *SubscriptionRepository) ListByMerchant(merchantID uuid.UUID, status model.SubscriptionStatus, limit, offset int) ([]model.Subscription, error) {
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var subs []model.Subscription
+	if err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Save writes back a subscription
+func (r *SubscriptionRepository) Save(sub *model.Subscription) error {
+	sub.UpdatedAt = time.Now()
+	return r.db.Save(sub).Error
+}
+
+// ClaimDue locks the oldest subscription due for billing and pushes its
+// next_billing_at out by lease, so a crashed worker's claim is retried
+// later and other replicas skip it meanwhile. It returns nil when nothing
+// is due.
+func (r *SubscriptionRepository) ClaimDue(now time.Time, lease time.Duration) (*model.Subscription, error) {
+	var sub model.Subscription
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tenancy.System(tx, "subscription billing worker claims due subscriptions").
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_billing_at <= ?", []model.SubscriptionStatus{
+				model.SubscriptionTrialing, model.SubscriptionActive, model.SubscriptionPastDue,
+			}, now).
+			Order("next_billing_at ASC").
+			First(&sub).Error; err != nil {
+			return err
+		}
+		return tx.Model(&sub).Update("next_billing_at", now.Add(lease)).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// SaveFromWorker writes back a subscription claimed by the billing worker
+func (r *SubscriptionRepository) SaveFromWorker(sub *model.Subscription) error {
+	sub.UpdatedAt = time.Now()
+	return r.worker().Save(sub).Error
+}
+
+// CreateCharge records a billing attempt
+func (r *SubscriptionRepository) CreateCharge(charge *model.SubscriptionCharge) error {
+	return r.worker().Create(charge).Error
+}
+
+// ListCharges returns a subscription's billing attempts, newest first
+func (r *SubscriptionRepository) ListCharges(subscriptionID, merchantID uuid.UUID, limit int) ([]model.SubscriptionCharge, error) {
+	var charges []model.SubscriptionCharge
+	if err := r.db.Where("subscription_id = ? AND merchant_id = ?", subscriptionID, merchantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&charges).Error; err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+func (r *SubscriptionRepository) worker() *gorm.DB {
+	return tenancy.System(r.db, "subscription billing worker")
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -36,24 +38,28 @@ func (r *WebhookRepository) Create(webhook *model.WebhookDelivery) error {
 // MarkDelivered marks webhook as successfully delivered
 func (r *WebhookRepository) MarkDelivered(id uuid.UUID, statusCode int, response string) error {
 	now := time.Now()
-	if err := r.db.Model(&model.WebhookDelivery{}).
+	if err := r.worker().Model(&model.WebhookDelivery{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"success":      true,
-			"status_code":  statusCode,
-			"response":     response,
-			"delivered_at": now,
+			"status":        model.WebhookDeliveryDelivered,
+			"success":       true,
+			"next_retry_at": nil,
+			"status_code":   statusCode,
+			"response":      response,
+			"delivered_at":  now,
 		}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-// MarkFailed marks webhook delivery as failed and schedules retry
-func (r *WebhookRepository) MarkFailed(id uuid.UUID, statusCode int, response string) error {
+// MarkFailed marks webhook delivery as failed and schedules a retry, or
+// moves it to the dead letter state once the retries are used up. It
+// returns the new status.
+func (r *WebhookRepository) MarkFailed(id uuid.UUID, statusCode int, response string) (model.WebhookDeliveryStatus, error) {
 	var webhook model.WebhookDelivery
-	if err := r.db.First(&webhook, id).Error; err != nil {
-		return err
+	if err := r.worker().First(&webhook, id).Error; err != nil {
+		return "", err
 	}
 
 	// Increment attempt count
@@ -73,28 +79,101 @@ func (r *WebhookRepository) MarkFailed(id uuid.UUID, statusCode int, response st
 
 	if webhook.AttemptCount <= len(retryDelays) {
 		nextRetry := time.Now().Add(retryDelays[webhook.AttemptCount-1])
+		webhook.Status = model.WebhookDeliveryRetrying
 		webhook.NextRetryAt.Time = nextRetry
 		webhook.NextRetryAt.Valid = true
+	} else {
+		webhook.Status = model.WebhookDeliveryDeadLetter
+		webhook.NextRetryAt = sql.NullTime{}
 	}
 
-	if err := r.db.Save(&webhook).Error; err != nil {
-		return err
+	if err := r.worker().Save(&webhook).Error; err != nil {
+		return "", err
 	}
 
-	return nil
+	return webhook.Status, nil
+}
+
+// MarkDeadLetter stops retrying a delivery that can never succeed
+func (r *WebhookRepository) MarkDeadLetter(id uuid.UUID, reason string) error {
+	return r.worker().Model(&model.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        model.WebhookDeliveryDeadLetter,
+			"response":      reason,
+			"next_retry_at": nil,
+		}).Error
 }
 
 // FindPendingRetries finds webhooks that need to be retried
 func (r *WebhookRepository) FindPendingRetries() ([]model.WebhookDelivery, error) {
 	var webhooks []model.WebhookDelivery
-	if err := r.db.Where("success = ? AND next_retry_at <= ? AND attempt_count < ?",
-		false, time.Now(), 5).
+	if err := r.worker().Where("status = ? AND next_retry_at <= ?",
+		model.WebhookDeliveryRetrying, time.Now()).
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// WebhookDeliveryFilter narrows a merchant's delivery log; zero fields match
+// everything
+type WebhookDeliveryFilter struct {
+	Status         model.WebhookDeliveryStatus
+	EventType      string
+	PaymentID      uuid.UUID
+	SubscriptionID uuid.UUID
+}
+
+// ListByMerchant returns the merchant's deliveries, newest first
+func (r *WebhookRepository) ListByMerchant(merchantID uuid.UUID, filter WebhookDeliveryFilter, limit, offset int) ([]model.WebhookDelivery, error) {
+	query := r.db.Where("merchant_id = ?", merchantID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.PaymentID != uuid.Nil {
+		query = query.Where("payment_id = ?", filter.PaymentID)
+	}
+	if filter.SubscriptionID != uuid.Nil {
+		query = query.Where("subscription_id = ?", filter.SubscriptionID)
+	}
+
+	var webhooks []model.WebhookDelivery
+	if err := query.Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
 		Find(&webhooks).Error; err != nil {
 		return nil, err
 	}
 	return webhooks, nil
 }
 
+// FindByIDAndMerchant returns one of the merchant's deliveries
+func (r *WebhookRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.WebhookDelivery, error) {
+	var webhook model.WebhookDelivery
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ClaimRedelivery puts a retrying or dead-lettered delivery back to pending
+// for one manual attempt. It reports false if the delivery was not in one of
+// those states, so two concurrent redeliveries cannot both send it.
+func (r *WebhookRepository) ClaimRedelivery(id, merchantID uuid.UUID) (bool, error) {
+	result := r.db.Model(&model.WebhookDelivery{}).
+		Where("id = ? AND merchant_id = ? AND status IN ?", id, merchantID,
+			[]model.WebhookDeliveryStatus{model.WebhookDeliveryRetrying, model.WebhookDeliveryDeadLetter}).
+		Updates(map[string]interface{}{
+			"status":        model.WebhookDeliveryPending,
+			"next_retry_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // FindByPayment finds all webhook deliveries for a payment
 func (r *WebhookRepository) FindByPayment(paymentID uuid.UUID) ([]model.WebhookDelivery, error) {
 	var webhooks []model.WebhookDelivery
@@ -105,3 +184,29 @@ func (r *WebhookRepository) FindByPayment(paymentID uuid.UUID) ([]model.WebhookD
 	}
 	return webhooks, nil
 }
+
+// FindByPaymentAndMerchant returns a merchant's deliveries for a payment, oldest first
+func (r *WebhookRepository) FindByPaymentAndMerchant(paymentID, merchantID uuid.UUID) ([]model.WebhookDelivery, error) {
+	var webhooks []model.WebhookDelivery
+	if err := r.db.Where("payment_id = ? AND merchant_id = ?", paymentID, merchantID).
+		Order("created_at ASC").
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// CountByPayment returns how many deliveries a merchant's payment has
+func (r *WebhookRepository) CountByPayment(paymentID, merchantID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.WebhookDelivery{}).
+		Where("payment_id = ? AND merchant_id = ?", paymentID, merchantID).
+		Count(&count).Error
+	return count, err
+}
+
+// worker is the handle used by webhook delivery and retries, which run in
+// the background for every merchant
+func (r *WebhookRepository) worker() *gorm.DB {
+	return tenancy.System(r.db, "webhook delivery worker")
+}
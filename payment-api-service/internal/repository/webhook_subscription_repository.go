@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionRepository struct {
+	db  *gorm.DB
+	ctx context.Context
+}
+
+func NewWebhookSubscriptionRepository() *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{
+		db:  inits.DB,
+		ctx: context.Background(),
+	}
+}
+
+// Create stores a new subscription
+func (r *WebhookSubscriptionRepository) Create(sub *model.WebhookSubscription) error {
+	return r.db.Create(sub).Error
+}
+
+// FindByIDAndMerchant returns one of the merchant's subscriptions
+func (r *WebhookSubscriptionRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// FindByMerchant lists the merchant's subscriptions, oldest first
+func (r *WebhookSubscriptionRepository) FindByMerchant(merchantID uuid.UUID) ([]model.WebhookSubscription, error) {
+	var subs []model.WebhookSubscription
+	if err := r.db.Where("merchant_id = ?", merchantID).
+		Order("created_at ASC").
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// FindActiveByMerchant lists the subscriptions the dispatcher should consider
+func (r *WebhookSubscriptionRepository) FindActiveByMerchant(merchantID uuid.UUID) ([]model.WebhookSubscription, error) {
+	var subs []model.WebhookSubscription
+	if err := r.db.Where("merchant_id = ? AND active = ?", merchantID, true).
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// FindSecrets returns a subscription with only its signing secrets loaded,
+// for the retry worker
+func (r *WebhookSubscriptionRepository) FindSecrets(id uuid.UUID) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	if err := tenancy.System(r.db, "webhook retry secret lookup").
+		Select("id", "secret", "previous_secret", "previous_secret_expires_at").
+		Where("id = ?", id).
+		First(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update saves changes to a subscription
+func (r *WebhookSubscriptionRepository) Update(sub *model.WebhookSubscription) error {
+	sub.UpdatedAt = time.Now()
+	return r.db.Save(sub).Error
+}
+
+// Delete removes one of the merchant's subscriptions
+func (r *WebhookSubscriptionRepository) Delete(id, merchantID uuid.UUID) error {
+	result := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).Delete(&model.WebhookSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// accountSection is one file of an account export archive. write returns
+// the number of records it wrote.
+type accountSection struct {
+	name  string
+	write func(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error)
+}
+
+// AccountExportManifest is written last as manifest.json and lists what
+// the archive holds
+type AccountExportManifest struct {
+	MerchantID  uuid.UUID                   `json:"merchant_id"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+	DateFrom    time.Time                   `json:"date_from"`
+	DateTo      time.Time                   `json:"date_to"`
+	Files       []AccountExportManifestFile `json:"files"`
+}
+
+type AccountExportManifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Skipped string `json:"skipped,omitempty"` // Why the section is missing
+}
+
+// CreateAccountExport queues a zip of everything the gateway holds about the
+// merchant. A merchant has at most one account export in flight; asking
+// again returns it.
+func (s *ExportService) CreateAccountExport(merchantID, createdBy uuid.UUID) (*ExportResponse, error) {
+	if job, err := s.exportRepo.FindActiveByResource(merchantID, model.ExportResourceAccount); err == nil {
+		return s.buildResponse(job), nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	since, err := s.paymentRepo.FirstPaymentAt(merchantID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find payment history: %w", err)
+		}
+		since = now
+	}
+
+	job := &model.ExportJob{
+		MerchantID: merchantID,
+		Resource:   model.ExportResourceAccount,
+		Format:     model.ExportFormatZip,
+		DateFrom:   since.Truncate(24 * time.Hour),
+		DateTo:     now,
+		Status:     model.ExportStatusPending,
+		CreatedBy:  createdBy,
+	}
+	if err := s.exportRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	logger.Log.Info("Account export queued",
+		zap.String("export_id", job.ID.String()),
+		zap.String("merchant_id", merchantID.String()),
+	)
+
+	return &ExportResponse{ExportJob: job}, nil
+}
+
+func (s *ExportService) accountSections() []accountSection {
+	return []accountSection{
+		{"payments.csv", s.writeAccountPayments},
+		{"transactions.csv", s.writeAccountTransactions},
+		{"settlements.json", s.writeAccountSettlements},
+		{"disputes.json", s.writeAccountDisputes},
+		{"tokens.json", s.writeAccountTokens},
+		{"webhook_subscriptions.json", s.writeAccountWebhooks},
+		{"merchant.json", s.writeAccountMerchant},
+	}
+}
+
+// writeAccountArchive writes each section to the zip in turn, recording
+// progress on the job after every one
+func (s *ExportService) writeAccountArchive(ctx context.Context, job *model.ExportJob, f *os.File) (int, error) {
+	zw := zip.NewWriter(f)
+	manifest := AccountExportManifest{
+		MerchantID:  job.MerchantID,
+		GeneratedAt: time.Now().UTC(),
+		DateFrom:    job.DateFrom.UTC(),
+		DateTo:      job.DateTo.UTC(),
+	}
+
+	sections := s.accountSections()
+	total := 0
+	for i, section := range sections {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		s.exportRepo.UpdateProgress(job.ID, i*100/(len(sections)+1), section.name)
+
+		w, err := zw.Create(section.name)
+		if err != nil {
+			return 0, err
+		}
+		records, err := section.write(ctx, job, w)
+		entry := AccountExportManifestFile{Name: section.name, Records: records}
+		if errors.Is(err, client.ErrMerchantServiceNotConfigured) {
+			entry.Skipped = "merchant service is not configured"
+		} else if err != nil {
+			return 0, fmt.Errorf("%s: %w", section.name, err)
+		}
+		manifest.Files = append(manifest.Files, entry)
+		total += records
+	}
+
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return 0, err
+	}
+	if err := writeIndentedJSON(w, manifest); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return total, f.Sync()
+}
+
+func (s *ExportService) writeAccountPayments(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	loc := s.displaySettings.Location(job.MerchantID)
+	ew := newExportWriter(w, model.ExportFormatCSV, paymentExportColumns)
+	rows := 0
+	err := s.paymentRepo.FindInRangeBatches(job.MerchantID, "", job.DateFrom, job.DateTo, exportBatchSize,
+		func(batch []model.Payment) error {
+			for i := range batch {
+				if err := ew.Write(paymentExportRow(&batch[i], loc)); err != nil {
+					return err
+				}
+				rows++
+			}
+			return ctx.Err()
+		})
+	if err != nil {
+		return 0, err
+	}
+	return rows, ew.Close()
+}
+
+func (s *ExportService) writeAccountTransactions(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	loc := s.displaySettings.Location(job.MerchantID)
+	ew := newExportWriter(w, model.ExportFormatCSV, transactionExportColumns)
+	rows := 0
+	err := s.streamTransactions(ctx, job, loc, func(values []interface{}) error {
+		rows++
+		return ew.Write(values)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rows, ew.Close()
+}
+
+func (s *ExportService) writeAccountSettlements(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	batches, err := s.transactionClient.ListSettlementBatches(ctx, &pb.ListSettlementBatchesRequest{
+		MerchantId: job.MerchantID.String(),
+		DateFrom:   job.DateFrom.UTC().Format("2006-01-02"),
+		DateTo:     job.DateTo.UTC().AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(batches), writeIndentedJSON(w, batches)
+}
+
+func (s *ExportService) writeAccountDisputes(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	disputes := []*pb.DisputeResponse{}
+	for offset := 0; ; offset += exportBatchSize {
+		resp, err := s.transactionClient.ListDisputes(ctx, &pb.ListDisputesRequest{
+			MerchantId: job.MerchantID.String(),
+			Limit:      exportBatchSize,
+			Offset:     int32(offset),
+		})
+		if err != nil {
+			return 0, err
+		}
+		if resp.Error != "" {
+			return 0, errors.New(resp.Error)
+		}
+		disputes = append(disputes, resp.Disputes...)
+		if !resp.HasMore {
+			break
+		}
+	}
+	return len(disputes), writeIndentedJSON(w, disputes)
+}
+
+func (s *ExportService) writeAccountTokens(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	if s.tokenClient == nil {
+		return 0, errors.New("tokenization service unavailable")
+	}
+	tokens := []*pb.MerchantToken{}
+	for offset := 0; ; offset += exportBatchSize {
+		resp, err := s.tokenClient.ListMerchantTokens(ctx, job.MerchantID.String(), exportBatchSize, offset)
+		if err != nil {
+			return 0, err
+		}
+		tokens = append(tokens, resp.Tokens...)
+		if !resp.HasMore {
+			break
+		}
+	}
+	return len(tokens), writeIndentedJSON(w, tokens)
+}
+
+// writeAccountWebhooks leaves signing secrets out; the model never
+// serializes them
+func (s *ExportService) writeAccountWebhooks(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	subs, err := s.webhookSubs.FindByMerchant(job.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+	return len(subs), writeIndentedJSON(w, subs)
+}
+
+func (s *ExportService) writeAccountMerchant(ctx context.Context, job *model.ExportJob, w io.Writer) (int, error) {
+	data, err := s.merchantClient.GetAccountData(ctx, job.MerchantID)
+	if err != nil {
+		if errors.Is(err, client.ErrMerchantServiceNotConfigured) {
+			_, werr := io.WriteString(w, "null\n")
+			if werr != nil {
+				return 0, werr
+			}
+		}
+		return 0, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
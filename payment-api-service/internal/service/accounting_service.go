@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+// JournalFormat is an accounting package's journal import format
+type JournalFormat string
+
+const (
+	JournalFormatQuickBooksIIF JournalFormat = "quickbooks_iif" // QuickBooks Desktop
+	JournalFormatQuickBooksCSV JournalFormat = "quickbooks_csv" // QuickBooks Online journal import
+	JournalFormatXeroCSV       JournalFormat = "xero_csv"       // Xero manual journal import
+)
+
+var (
+	ErrSettlementNotFound   = errors.New("settlement batch not found")
+	ErrSettlementNotSettled = errors.New("settlement batch has not been paid out yet")
+)
+
+type AccountingService struct {
+	mappingRepo       *repository.AccountingMappingRepository
+	transactionClient *client.TransactionClient
+}
+
+func NewAccountingService() *AccountingService {
+	return &AccountingService{
+		mappingRepo:       repository.NewAccountingMappingRepository(),
+		transactionClient: client.NewTransactionClient(),
+	}
+}
+
+// JournalFile is a rendered journal ready to download
+type JournalFile struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// journalEntry is one balanced entry per settlement batch. Amounts are in
+// the settlement currency's minor units; positive is a debit.
+type journalEntry struct {
+	Number string
+	Date   time.Time
+	Memo   string
+	Lines  []journalLine
+}
+
+type journalLine struct {
+	Account string
+	Amount  int64
+}
+
+// ParseJournalFormat validates a format and returns the provider it maps to
+func ParseJournalFormat(format string) (JournalFormat, model.AccountingProvider, error) {
+	switch JournalFormat(format) {
+	case JournalFormatQuickBooksIIF, JournalFormatQuickBooksCSV:
+		return JournalFormat(format), model.AccountingProviderQuickBooks, nil
+	case JournalFormatXeroCSV:
+		return JournalFormatXeroCSV, model.AccountingProviderXero, nil
+	default:
+		return "", "", fmt.Errorf("format must be one of quickbooks_iif, quickbooks_csv, xero_csv")
+	}
+}
+
+// =========================================================================
+// Account Mappings
+// =========================================================================
+
+func (s *AccountingService) GetMapping(merchantID uuid.UUID, provider model.AccountingProvider) (*model.AccountingMapping, error) {
+	return s.mappingRepo.Find(merchantID, provider)
+}
+
+func (s *AccountingService) UpdateMapping(mapping *model.AccountingMapping) (*model.AccountingMapping, error) {
+	fields := map[string]*string{
+		"bank_account":    &mapping.BankAccount,
+		"fees_account":    &mapping.FeesAccount,
+		"refunds_account": &mapping.RefundsAccount,
+		"sales_account":   &mapping.SalesAccount,
+	}
+	for name, value := range fields {
+		*value = strings.TrimSpace(*value)
+		if *value == "" {
+			return nil, fmt.Errorf("%s is required", name)
+		}
+		if strings.ContainsAny(*value, "\t\r\n") {
+			return nil, fmt.Errorf("%s contains invalid characters", name)
+		}
+	}
+	// Only merchants with a settlement reserve need one, so it may be left
+	// to the default
+	mapping.ReserveAccount = strings.TrimSpace(mapping.ReserveAccount)
+	if mapping.ReserveAccount == "" {
+		mapping.ReserveAccount = model.DefaultAccountingMapping(mapping.MerchantID, mapping.Provider).ReserveAccount
+	}
+	if strings.ContainsAny(mapping.ReserveAccount, "\t\r\n") {
+		return nil, fmt.Errorf("reserve_account contains invalid characters")
+	}
+	if mapping.Provider == model.AccountingProviderXero && mapping.TaxRate == "" {
+		mapping.TaxRate = model.DefaultAccountingMapping(mapping.MerchantID, mapping.Provider).TaxRate
+	}
+
+	if err := s.mappingRepo.Upsert(mapping); err != nil {
+		return nil, err
+	}
+	return s.mappingRepo.Find(mapping.MerchantID, mapping.Provider)
+}
+
+// =========================================================================
+// Journals
+// =========================================================================
+
+// SettlementJournal renders the journal entry for a single paid-out batch
+func (s *AccountingService) SettlementJournal(ctx context.Context, merchantID, batchID uuid.UUID, format JournalFormat, provider model.AccountingProvider) (*JournalFile, error) {
+	batch, err := s.transactionClient.GetSettlementBatch(ctx, &pb.GetSettlementBatchRequest{
+		BatchId:    batchID.String(),
+		MerchantId: merchantID.String(),
+	})
+	if err != nil {
+		if err.Error() == ErrSettlementNotFound.Error() {
+			return nil, ErrSettlementNotFound
+		}
+		return nil, err
+	}
+	if batch.Status != "settled" {
+		return nil, ErrSettlementNotSettled
+	}
+
+	mapping, err := s.mappingRepo.Find(merchantID, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "settlement-" + batch.BatchDate
+	return renderJournal(name, format, mapping, []journalEntry{settlementEntry(batch, mapping)})
+}
+
+// MonthlyJournal renders one entry per batch paid out for batches dated in month (YYYY-MM)
+func (s *AccountingService) MonthlyJournal(ctx context.Context, merchantID uuid.UUID, month string, format JournalFormat, provider model.AccountingProvider) (*JournalFile, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("month must be formatted YYYY-MM")
+	}
+
+	batches, err := s.transactionClient.ListSettlementBatches(ctx, &pb.ListSettlementBatchesRequest{
+		MerchantId: merchantID.String(),
+		DateFrom:   start.Format("2006-01-02"),
+		DateTo:     start.AddDate(0, 1, 0).Format("2006-01-02"),
+		Status:     "settled",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := s.mappingRepo.Find(merchantID, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]journalEntry, 0, len(batches))
+	for _, batch := range batches {
+		entries = append(entries, settlementEntry(batch, mapping))
+	}
+	return renderJournal("settlements-"+month, format, mapping, entries)
+}
+
+// settlementEntry books a payout: the bank receives net, fees, refunds and
+// any instant payout fee are expensed, fees given back on refunds are
+// credited back to the fees account, any reserve held back is carried as
+// owed to the merchant, and gross captures are recognised as sales. What the
+// merchant balance added to or took from the payout, such as released
+// reserve or recovered chargebacks, is booked against the reserve account.
+func settlementEntry(batch *pb.SettlementBatchResponse, mapping *model.AccountingMapping) journalEntry {
+	date, err := time.Parse("2006-01-02", batch.SettlementDate)
+	if err != nil {
+		date, _ = time.Parse("2006-01-02", batch.BatchDate)
+	}
+
+	number := batch.ReferenceNumber
+	if number == "" {
+		number = "STL-" + strings.ReplaceAll(batch.BatchDate, "-", "")
+	}
+
+	lines := []journalLine{{Account: mapping.BankAccount, Amount: batch.NetAmount}}
+	if batch.FeeAmount != 0 {
+		lines = append(lines, journalLine{Account: mapping.FeesAccount, Amount: batch.FeeAmount})
+	}
+	if batch.RefundAmount != 0 {
+		lines = append(lines, journalLine{Account: mapping.RefundsAccount, Amount: batch.RefundAmount})
+	}
+	if batch.FeeReversalAmount != 0 {
+		lines = append(lines, journalLine{Account: mapping.FeesAccount, Amount: -batch.FeeReversalAmount})
+	}
+	if batch.PayoutFee != 0 {
+		lines = append(lines, journalLine{Account: mapping.FeesAccount, Amount: batch.PayoutFee})
+	}
+	reserveAccount := mapping.ReserveAccount
+	if reserveAccount == "" {
+		reserveAccount = model.DefaultAccountingMapping(mapping.MerchantID, mapping.Provider).ReserveAccount
+	}
+	if batch.ReserveAmount != 0 {
+		lines = append(lines, journalLine{Account: reserveAccount, Amount: batch.ReserveAmount})
+	}
+	if batch.BalanceAdjustment != 0 {
+		lines = append(lines, journalLine{Account: reserveAccount, Amount: -batch.BalanceAdjustment})
+	}
+	lines = append(lines, journalLine{Account: mapping.SalesAccount, Amount: -batch.GrossAmount})
+
+	kind := "Card settlement"
+	if batch.InstantPayout {
+		kind = "Instant payout"
+	}
+	return journalEntry{
+		Number: number,
+		Date:   date,
+		Memo:   fmt.Sprintf("%s %s (%d payments, %d refunds)", kind, batch.BatchDate, batch.TransactionCount, batch.RefundCount),
+		Lines:  lines,
+	}
+}
+
+func renderJournal(name string, format JournalFormat, mapping *model.AccountingMapping, entries []journalEntry) (*JournalFile, error) {
+	var buf bytes.Buffer
+	var err error
+
+	file := &JournalFile{}
+	switch format {
+	case JournalFormatQuickBooksIIF:
+		err = writeIIF(&buf, entries)
+		file.FileName = name + ".iif"
+		file.ContentType = "application/octet-stream"
+	case JournalFormatQuickBooksCSV:
+		err = writeQuickBooksCSV(&buf, entries)
+		file.FileName = name + "-quickbooks.csv"
+		file.ContentType = "text/csv"
+	case JournalFormatXeroCSV:
+		err = writeXeroCSV(&buf, entries, mapping.TaxRate)
+		file.FileName = name + "-xero.csv"
+		file.ContentType = "text/csv"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file.Content = buf.Bytes()
+	return file, nil
+}
+
+// writeIIF writes QuickBooks Desktop GENERAL JOURNAL transactions
+func writeIIF(buf *bytes.Buffer, entries []journalEntry) error {
+	buf.WriteString("!TRNS\tTRNSTYPE\tDATE\tACCNT\tAMOUNT\tDOCNUM\tMEMO\n")
+	buf.WriteString("!SPL\tTRNSTYPE\tDATE\tACCNT\tAMOUNT\tDOCNUM\tMEMO\n")
+	buf.WriteString("!ENDTRNS\n")
+
+	for _, e := range entries {
+		for i, line := range e.Lines {
+			kind := "SPL"
+			if i == 0 {
+				kind = "TRNS"
+			}
+			fmt.Fprintf(buf, "%s\tGENERAL JOURNAL\t%s\t%s\t%s\t%s\t%s\n",
+				kind,
+				e.Date.Format("01/02/2006"),
+				iifField(line.Account),
+				formatMinorUnits(line.Amount),
+				iifField(e.Number),
+				iifField(e.Memo),
+			)
+		}
+		buf.WriteString("ENDTRNS\n")
+	}
+	return nil
+}
+
+func iifField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// writeQuickBooksCSV writes the QuickBooks Online journal entry import layout
+func writeQuickBooksCSV(buf *bytes.Buffer, entries []journalEntry) error {
+	w := csv.NewWriter(buf)
+	w.Write([]string{"Journal No", "Journal Date", "Account Name", "Debits", "Credits", "Description"})
+
+	for _, e := range entries {
+		for _, line := range e.Lines {
+			debit, credit := "", ""
+			if line.Amount >= 0 {
+				debit = formatMinorUnits(line.Amount)
+			} else {
+				credit = formatMinorUnits(-line.Amount)
+			}
+			w.Write([]string{e.Number, e.Date.Format("01/02/2006"), line.Account, debit, credit, e.Memo})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// writeXeroCSV writes the Xero manual journal import layout (positive = debit)
+func writeXeroCSV(buf *bytes.Buffer, entries []journalEntry, taxRate string) error {
+	w := csv.NewWriter(buf)
+	w.Write([]string{"*Narration", "*Date", "Description", "*AccountCode", "*TaxRate", "*Amount"})
+
+	for _, e := range entries {
+		for _, line := range e.Lines {
+			w.Write([]string{
+				e.Memo + " [" + e.Number + "]",
+				e.Date.Format("02 Jan 2006"),
+				e.Memo,
+				line.Account,
+				taxRate,
+				formatMinorUnits(line.Amount),
+			})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// formatMinorUnits renders cents as a decimal string, e.g. -12345 -> "-123.45"
+func formatMinorUnits(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
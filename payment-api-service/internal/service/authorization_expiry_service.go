@@ -0,0 +1,299 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	authorizationExpirySweepInterval = time.Hour
+	authorizationExpiryBatchSize     = 100
+
+	// The transaction service auto-voids authorizations this long after
+	// they were made
+	authorizationLifetime = 7 * 24 * time.Hour
+
+	// Expired authorizations older than this are left out of summaries, so
+	// a merchant who adds a notification email is not sent a backlog
+	authorizationSummaryLookback = 3 * 24 * time.Hour
+)
+
+// AuthorizationExpiryService catches up with the authorizations the
+// transaction service auto-voided because nobody captured them. It voids
+// the payments here too, sends payment.authorization_expired webhooks and
+// hold release emails, and once a day emails each merchant a summary of
+// what expired.
+type AuthorizationExpiryService struct {
+	paymentRepo       *repository.PaymentRepository
+	transactionClient *client.TransactionClient
+	webhookService    *WebhookService
+	displaySettings   *DisplaySettingsService
+	holdRelease       *HoldReleaseService
+	mailer            *mailer
+}
+
+func NewAuthorizationExpiryService(paymentService *PaymentService) *AuthorizationExpiryService {
+	return &AuthorizationExpiryService{
+		paymentRepo:       repository.NewPaymentRepository(),
+		transactionClient: paymentService.transactionClient,
+		webhookService:    NewWebhookService(),
+		displaySettings:   NewDisplaySettingsService(),
+		holdRelease:       paymentService.holdRelease,
+		mailer:            newMailer(),
+	}
+}
+
+// RunWorker syncs expired authorizations and sends the daily summaries
+// every hour until ctx is canceled
+func (s *AuthorizationExpiryService) RunWorker(ctx context.Context) error {
+	logger.Log.Info("Starting authorization expiry worker")
+
+	ticker := time.NewTicker(authorizationExpirySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Authorization expiry worker stopped")
+			return nil
+		case <-ticker.C:
+			s.syncExpired(ctx)
+			s.sendSummaries(time.Now())
+		}
+	}
+}
+
+// syncExpired asks the transaction service about every authorization old
+// enough to have expired and voids the ones it auto-voided
+func (s *AuthorizationExpiryService) syncExpired(ctx context.Context) {
+	cutoff := time.Now().Add(-authorizationLifetime)
+
+	err := s.paymentRepo.FindAuthorizedBeforeBatches(cutoff, authorizationExpiryBatchSize, func(payments []model.Payment) error {
+		for i := range payments {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.syncPayment(ctx, &payments[i])
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		logger.Log.Error("Failed to sync expired authorizations", zap.Error(err))
+	}
+}
+
+func (s *AuthorizationExpiryService) syncPayment(ctx context.Context, payment *model.Payment) {
+	txn, err := s.transactionClient.GetTransaction(ctx, &pb.GetTransactionRequest{
+		TransactionId: payment.TransactionID.String(),
+		MerchantId:    payment.MerchantID.String(),
+	})
+	if err != nil || txn.Error != "" {
+		logger.Log.Warn("Could not check authorization expiry",
+			zap.String("payment_id", payment.ID.String()),
+			zap.String("error", txn.GetError()),
+			zap.Error(err),
+		)
+		return
+	}
+	if txn.Status != string(model.PaymentStatusVoided) || txn.VoidedReason != model.VoidReasonExpired {
+		return
+	}
+
+	voidedAt, err := time.Parse("2006-01-02T15:04:05Z", txn.VoidedAt)
+	if err != nil {
+		voidedAt = time.Now()
+	}
+
+	voided, err := s.paymentRepo.MarkExpired(payment.ID, voidedAt)
+	if err != nil {
+		logger.Log.Error("Failed to void expired payment",
+			zap.String("payment_id", payment.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if !voided {
+		return // captured or voided by the merchant in the meantime
+	}
+
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
+		PaymentID:   payment.ID,
+		EventType:   "authorization_expired",
+		OldStatus:   model.PaymentStatusAuthorized,
+		NewStatus:   model.PaymentStatusVoided,
+		Amount:      payment.Amount,
+		Description: sql.NullString{String: "Authorization expired after 7 days without capture", Valid: true},
+	})
+
+	logger.Log.Info("Expired authorization voided",
+		zap.String("payment_id", payment.ID.String()),
+		zap.String("merchant_id", payment.MerchantID.String()),
+		zap.Int64("amount", payment.Amount),
+	)
+
+	s.webhookService.DispatchPaymentEvent(ctx, payment.MerchantID, payment.ID, WebhookEventPaymentAuthorizationExpired)
+	s.holdRelease.NotifyHoldReleased(payment.ID, payment.MerchantID, model.HoldReleaseExpired)
+}
+
+// sendSummaries emails each merchant the authorizations that expired on a
+// day that has ended in the merchant's timezone. Merchants without a
+// notification email get none.
+func (s *AuthorizationExpiryService) sendSummaries(now time.Time) {
+	if !s.mailer.enabled() {
+		return
+	}
+
+	expired, err := s.paymentRepo.FindExpiredUnsummarized(now.Add(-authorizationSummaryLookback))
+	if err != nil {
+		logger.Log.Error("Failed to load expired authorizations for summaries", zap.Error(err))
+		return
+	}
+
+	byMerchant := make(map[uuid.UUID][]model.Payment)
+	for _, payment := range expired {
+		byMerchant[payment.MerchantID] = append(byMerchant[payment.MerchantID], payment)
+	}
+
+	for merchantID, payments := range byMerchant {
+		settings := s.displaySettings.Resolve(merchantID)
+		if settings.NotificationEmail == "" {
+			continue
+		}
+
+		loc := settings.Location()
+		local := now.In(loc)
+		startOfToday := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+		var due []model.Payment
+		for _, payment := range payments {
+			if payment.VoidedAt.Time.Before(startOfToday) {
+				due = append(due, payment)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+
+		if err := s.sendSummary(settings, due); err != nil {
+			logger.Log.Error("Failed to send expired authorization summary",
+				zap.String("merchant_id", merchantID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		ids := make([]uuid.UUID, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+		}
+		if err := s.paymentRepo.MarkExpirySummarySent(ids, now); err != nil {
+			logger.Log.Error("Failed to record expired authorization summary",
+				zap.String("merchant_id", merchantID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		logger.Log.Info("Expired authorization summary sent",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Int("count", len(due)),
+		)
+	}
+}
+
+func (s *AuthorizationExpiryService) sendSummary(settings *model.MerchantDisplaySettings, payments []model.Payment) error {
+	loc := settings.Location()
+	page := expirySummaryPage{Count: len(payments)}
+
+	totals := make(map[string]int64)
+	var currencies []string
+	for _, payment := range payments {
+		page.Rows = append(page.Rows, expirySummaryRow{
+			PaymentID:    payment.ID.String(),
+			Card:         fmt.Sprintf("%s •••• %s", payment.CardBrand, payment.CardLast4),
+			Amount:       settings.FormatAmount(payment.Amount, payment.Currency),
+			AuthorizedAt: payment.CreatedAt.In(loc).Format("2006-01-02 15:04"),
+			VoidedAt:     payment.VoidedAt.Time.In(loc).Format("2006-01-02 15:04"),
+		})
+		if _, ok := totals[payment.Currency]; !ok {
+			currencies = append(currencies, payment.Currency)
+		}
+		totals[payment.Currency] += payment.Amount
+	}
+	for _, currency := range currencies {
+		page.Totals = append(page.Totals, settings.FormatAmount(totals[currency], currency))
+	}
+
+	var body bytes.Buffer
+	if err := expirySummaryTemplate.Execute(&body, page); err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("%d authorizations expired without capture", len(payments))
+	if len(payments) == 1 {
+		subject = "1 authorization expired without capture"
+	}
+	return s.mailer.send(settings.NotificationEmail, subject, body.Bytes())
+}
+
+type expirySummaryPage struct {
+	Count  int
+	Totals []string // one per currency
+	Rows   []expirySummaryRow
+}
+
+type expirySummaryRow struct {
+	PaymentID    string
+	Card         string
+	Amount       string
+	AuthorizedAt string
+	VoidedAt     string
+}
+
+var expirySummaryTemplate = template.Must(template.New("expiry_summary").Funcs(template.FuncMap{
+	"year": func() int { return time.Now().Year() },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Expired authorizations</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 700px; margin: 0 auto; padding: 20px; }
+        h1 { font-size: 22px; }
+        table { width: 100%; border-collapse: collapse; font-size: 14px; }
+        th { text-align: left; color: #6b7280; border-bottom: 2px solid #e5e7eb; padding: 8px 4px; }
+        td { padding: 8px 4px; border-bottom: 1px solid #e5e7eb; }
+        .footer { color: #6b7280; font-size: 14px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>Expired authorizations</h1>
+        <p>{{.Count}} authorization(s) were not captured within 7 days and have been voided automatically. The held funds were released to your customers and these payments can no longer be captured.</p>
+        <p>Total released: {{range $i, $t := .Totals}}{{if $i}}, {{end}}<strong>{{$t}}</strong>{{end}}</p>
+        <table>
+            <tr><th>Payment</th><th>Card</th><th>Amount</th><th>Authorized</th><th>Voided</th></tr>
+            {{range .Rows}}<tr><td>{{.PaymentID}}</td><td>{{.Card}}</td><td>{{.Amount}}</td><td>{{.AuthorizedAt}}</td><td>{{.VoidedAt}}</td></tr>
+            {{end}}
+        </table>
+        <div class="footer">
+            <p>List them any time with GET /api/v1/transactions?status=voided&amp;voided_reason=expired.</p>
+            <p>© {{year}} Payment Gateway Morocco</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
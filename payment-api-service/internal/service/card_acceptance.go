@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+var ErrCardBrandNotAccepted = errors.New("this merchant does not accept cards of this brand")
+
+// cardBrandOf names the brand of a card number with the same ranges the
+// tokenization service uses, so a payment can be checked before the card
+// is vaulted
+func cardBrandOf(cardNumber string) string {
+	prefix := cardPrefix(cardNumber, 4)
+	if prefix == "" {
+		return "unknown"
+	}
+	if prefix[0] == '4' {
+		return "visa"
+	}
+	n, _ := strconv.Atoi(prefix)
+	if (n >= 5100 && n <= 5599) || (n >= 2221 && n <= 2720) {
+		return "mastercard"
+	}
+	return "unknown"
+}
+
+// checkCardBrand rejects cards of a brand the merchant has turned off in
+// its checkout settings
+func (s *PaymentService) checkCardBrand(merchantID uuid.UUID, brand string) error {
+	settings, err := s.checkoutRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to load card acceptance settings: %w", err)
+	}
+	if !settings.AcceptsBrand(brand) {
+		return ErrCardBrandNotAccepted
+	}
+	return nil
+}
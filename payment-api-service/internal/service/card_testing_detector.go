@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrCardTestingThrottled = errors.New("too many payment attempts: card-testing protection is active for this merchant")
+
+// cardTestingRules tunes the detector. An authorization at or below
+// LowValueAmount counts toward the window for its merchant, IP and BIN; a
+// dimension trips once it has MinAttempts and DeclineRate of them failed.
+var cardTestingRules = struct {
+	LowValueAmount     int64
+	Window             time.Duration
+	MinAttempts        map[model.CardTestingDimension]int
+	DeclineRate        float64
+	ChallengeRate      float64
+	LockdownTTL        time.Duration
+	ThrottleWindow     time.Duration
+	ThrottleIPLimit    int64
+	ThrottleBINLimit   int64
+	MaxListedIncidents int
+}{
+	LowValueAmount: 500, // $5.00
+	Window:         10 * time.Minute,
+	MinAttempts: map[model.CardTestingDimension]int{
+		model.CardTestingDimensionMerchant: 30,
+		model.CardTestingDimensionIP:       8,
+		model.CardTestingDimensionBIN:      10,
+	},
+	DeclineRate:        0.6,
+	ChallengeRate:      0.85,
+	LockdownTTL:        time.Hour,
+	ThrottleWindow:     10 * time.Minute,
+	ThrottleIPLimit:    3,
+	ThrottleBINLimit:   5,
+	MaxListedIncidents: 100,
+}
+
+// CardTestingDetector watches authorization outcomes for bursts of small,
+// mostly declined attempts and puts the merchant under stricter limits
+type CardTestingDetector struct {
+	repo *repository.CardTestingRepository
+}
+
+func NewCardTestingDetector() *CardTestingDetector {
+	return &CardTestingDetector{
+		repo: repository.NewCardTestingRepository(),
+	}
+}
+
+// CheckThrottle rejects the attempt if the merchant is under card-testing
+// protection and the IP or BIN has used up its reduced allowance
+func (d *CardTestingDetector) CheckThrottle(merchantID uuid.UUID, ip, bin string) error {
+	_, active, err := d.repo.GetLockdown(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to read card-testing lockdown", zap.Error(err))
+		return nil
+	}
+	if !active {
+		return nil
+	}
+
+	if ip != "" {
+		count, err := d.repo.IncrThrottle(merchantID, model.CardTestingDimensionIP, ip, cardTestingRules.ThrottleWindow)
+		if err == nil && count > cardTestingRules.ThrottleIPLimit {
+			return ErrCardTestingThrottled
+		}
+	}
+	if bin != "" {
+		count, err := d.repo.IncrThrottle(merchantID, model.CardTestingDimensionBIN, bin, cardTestingRules.ThrottleWindow)
+		if err == nil && count > cardTestingRules.ThrottleBINLimit {
+			return ErrCardTestingThrottled
+		}
+	}
+	return nil
+}
+
+// RequiresChallenge reports whether hosted checkout must present a CAPTCHA
+func (d *CardTestingDetector) RequiresChallenge(merchantID uuid.UUID) bool {
+	action, active, err := d.repo.GetLockdown(merchantID)
+	if err != nil {
+		return false
+	}
+	return active && action == model.CardTestingActionChallenge
+}
+
+// ActiveProtection returns the merchant's current protection, or "" if none
+func (d *CardTestingDetector) ActiveProtection(merchantID uuid.UUID) model.CardTestingAction {
+	action, active, err := d.repo.GetLockdown(merchantID)
+	if err != nil || !active {
+		return ""
+	}
+	return action
+}
+
+// Record feeds an authorization outcome into the detector. Errors are logged
+// rather than returned so detection never blocks a payment.
+func (d *CardTestingDetector) Record(merchantID uuid.UUID, ip, bin string, amount int64, declined bool) {
+	if amount > cardTestingRules.LowValueAmount {
+		return
+	}
+
+	dimensions := map[model.CardTestingDimension]string{
+		model.CardTestingDimensionMerchant: merchantID.String(),
+		model.CardTestingDimensionIP:       ip,
+		model.CardTestingDimensionBIN:      bin,
+	}
+
+	for dim, value := range dimensions {
+		if value == "" {
+			continue
+		}
+
+		attempts, declines, err := d.repo.RecordAttempt(merchantID, dim, value, declined, cardTestingRules.Window)
+		if err != nil {
+			logger.Log.Error("Failed to record card-testing stats",
+				zap.String("dimension", string(dim)),
+				zap.Error(err),
+			)
+			return
+		}
+
+		if attempts < cardTestingRules.MinAttempts[dim] {
+			continue
+		}
+		rate := float64(declines) / float64(attempts)
+		if rate < cardTestingRules.DeclineRate {
+			continue
+		}
+
+		action := model.CardTestingActionThrottle
+		if rate >= cardTestingRules.ChallengeRate {
+			action = model.CardTestingActionChallenge
+		}
+		d.openIncident(merchantID, dim, value, attempts, declines, action)
+		return
+	}
+}
+
+// ResolveIncident marks an incident reviewed and lifts the protection
+func (d *CardTestingDetector) ResolveIncident(id, merchantID uuid.UUID) (*model.CardTestingIncident, error) {
+	incident, err := d.repo.ResolveIncident(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.repo.ClearLockdown(merchantID); err != nil {
+		logger.Log.Error("Failed to clear card-testing lockdown", zap.Error(err))
+	}
+	return incident, nil
+}
+
+// ListIncidents returns the merchant's incidents, optionally filtered by status
+func (d *CardTestingDetector) ListIncidents(merchantID uuid.UUID, status model.CardTestingIncidentStatus) ([]model.CardTestingIncident, error) {
+	return d.repo.ListIncidents(merchantID, status, cardTestingRules.MaxListedIncidents)
+}
+
+func (d *CardTestingDetector) openIncident(merchantID uuid.UUID, dim model.CardTestingDimension, value string, attempts, declines int, action model.CardTestingAction) {
+	activated, err := d.repo.ActivateLockdown(merchantID, action, cardTestingRules.LockdownTTL)
+	if err != nil {
+		logger.Log.Error("Failed to activate card-testing lockdown", zap.Error(err))
+		return
+	}
+	if !activated {
+		return // already under protection for this burst
+	}
+
+	incident := &model.CardTestingIncident{
+		MerchantID:     merchantID,
+		Dimension:      dim,
+		DimensionValue: value,
+		Attempts:       attempts,
+		Declines:       declines,
+		Action:         action,
+		Status:         model.CardTestingIncidentOpen,
+		ExpiresAt:      time.Now().Add(cardTestingRules.LockdownTTL),
+	}
+	if err := d.repo.CreateIncident(incident); err != nil {
+		logger.Log.Error("Failed to record card-testing incident", zap.Error(err))
+	}
+
+	logger.Log.Warn("Card-testing attack detected",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("dimension", string(dim)),
+		zap.String("value", value),
+		zap.Int("attempts", attempts),
+		zap.Int("declines", declines),
+		zap.String("action", string(action)),
+	)
+}
+
+// cardBIN returns the first six digits of a card number
+func cardBIN(cardNumber string) string {
+	return cardPrefix(cardNumber, 6)
+}
+
+// cardPrefix returns the first n digits of a card number, or "" if it is
+// shorter
+func cardPrefix(cardNumber string, n int) string {
+	digits := make([]rune, 0, n)
+	for _, r := range cardNumber {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+			if len(digits) == n {
+				return string(digits)
+			}
+		}
+	}
+	return ""
+}
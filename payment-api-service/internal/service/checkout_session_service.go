@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidCheckoutSession = errors.New("invalid checkout session")
+
+const (
+	maxCheckoutLineItems       = 100
+	maxCheckoutShippingOptions = 10
+)
+
+// CheckoutSessionService creates itemized checkouts. Each session is paid
+// through its own payment intent, so the hosted checkout, confirmation and
+// expiry all work as they do for intents.
+type CheckoutSessionService struct {
+	sessionRepo   *repository.CheckoutSessionRepository
+	intentRepo    *repository.PaymentIntentRepository
+	intentService *PaymentIntentService
+}
+
+func NewCheckoutSessionService(intentService *PaymentIntentService) *CheckoutSessionService {
+	return &CheckoutSessionService{
+		sessionRepo:   repository.NewCheckoutSessionRepository(),
+		intentRepo:    repository.NewPaymentIntentRepository(),
+		intentService: intentService,
+	}
+}
+
+// CreateCheckoutSessionRequest is a payment intent request whose amount is
+// computed from line items, tax and shipping
+type CreateCheckoutSessionRequest struct {
+	CreatePaymentIntentRequest
+	LineItems       []CheckoutLineItemInput
+	ShippingOptions []CheckoutShippingOptionInput
+}
+
+type CheckoutLineItemInput struct {
+	Name       string
+	Quantity   int64
+	UnitAmount int64
+	TaxRate    *CheckoutTaxRateInput
+}
+
+// CheckoutTaxRateInput is a tax added on top of a line's amount. Percentage
+// allows at most two decimals, e.g. 20 or 5.5.
+type CheckoutTaxRateInput struct {
+	Name       string
+	Percentage float64
+}
+
+type CheckoutShippingOptionInput struct {
+	Code   string
+	Name   string
+	Amount int64
+}
+
+// CheckoutSessionResponse is a session with the state of its intent. The
+// client secret and checkout URL are only returned on creation.
+type CheckoutSessionResponse struct {
+	*model.CheckoutSession
+	Status       model.PaymentIntentStatus `json:"status"`
+	Locale       string                    `json:"locale,omitempty"`
+	PaymentID    string                    `json:"payment_id,omitempty"`
+	ClientSecret string                    `json:"client_secret,omitempty"`
+	CheckoutURL  string                    `json:"checkout_url,omitempty"`
+	ExpiresAt    time.Time                 `json:"expires_at"`
+}
+
+// CreateCheckoutSession prices the order and creates the session with the
+// intent that collects it. The first shipping option is selected until the
+// customer picks another on the checkout page.
+func (s *CheckoutSessionService) CreateCheckoutSession(ctx context.Context, req *CreateCheckoutSessionRequest) (*CheckoutSessionResponse, error) {
+	session, err := priceCheckoutSession(req)
+	if err != nil {
+		return nil, err
+	}
+
+	intentReq := req.CreatePaymentIntentRequest
+	intentReq.Amount = session.AmountTotal
+	intent, err := s.intentService.newPaymentIntent(&intentReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.CreateWithIntent(intent, session); err != nil {
+		return nil, fmt.Errorf("failed to create checkout session: %w", err)
+	}
+
+	logger.Log.Info("Checkout session created",
+		zap.String("session_id", session.ID.String()),
+		zap.String("intent_id", intent.ID.String()),
+		zap.Int("line_items", len(session.LineItems)),
+		zap.Int64("amount_total", session.AmountTotal),
+	)
+
+	response := newCheckoutSessionResponse(session, intent)
+	created := newPaymentIntentResponse(intent)
+	response.ClientSecret = created.ClientSecret
+	response.CheckoutURL = created.CheckoutURL
+	return response, nil
+}
+
+// GetCheckoutSession returns one of the merchant's sessions
+func (s *CheckoutSessionService) GetCheckoutSession(ctx context.Context, sessionID, merchantID uuid.UUID) (*CheckoutSessionResponse, error) {
+	session, err := s.sessionRepo.FindByIDAndMerchant(sessionID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	intent, err := s.intentRepo.FindByIDAndMerchant(session.PaymentIntentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	return newCheckoutSessionResponse(session, intent), nil
+}
+
+func newCheckoutSessionResponse(session *model.CheckoutSession, intent *model.PaymentIntent) *CheckoutSessionResponse {
+	response := &CheckoutSessionResponse{
+		CheckoutSession: session,
+		Status:          intent.Status,
+		Locale:          intent.Language,
+		ExpiresAt:       intent.ExpiresAt,
+	}
+	if intent.PaymentID.Valid {
+		response.PaymentID = intent.PaymentID.String
+	}
+	return response
+}
+
+// priceCheckoutSession validates the order and computes its totals. Tax is
+// rounded half up per line.
+func priceCheckoutSession(req *CreateCheckoutSessionRequest) (*model.CheckoutSession, error) {
+	if len(req.LineItems) == 0 {
+		return nil, fmt.Errorf("%w: at least one line item is required", ErrInvalidCheckoutSession)
+	}
+	if len(req.LineItems) > maxCheckoutLineItems {
+		return nil, fmt.Errorf("%w: at most %d line items", ErrInvalidCheckoutSession, maxCheckoutLineItems)
+	}
+	if len(req.ShippingOptions) > maxCheckoutShippingOptions {
+		return nil, fmt.Errorf("%w: at most %d shipping options", ErrInvalidCheckoutSession, maxCheckoutShippingOptions)
+	}
+
+	session := &model.CheckoutSession{
+		MerchantID: req.MerchantID,
+		Currency:   req.Currency,
+	}
+
+	for i, input := range req.LineItems {
+		if input.Name == "" || input.Quantity <= 0 || input.UnitAmount < 0 {
+			return nil, fmt.Errorf("%w: line_items[%d] needs a name, a positive quantity and a unit_amount of at least 0", ErrInvalidCheckoutSession, i)
+		}
+		item := model.CheckoutLineItem{
+			Name:       input.Name,
+			Quantity:   input.Quantity,
+			UnitAmount: input.UnitAmount,
+			Amount:     input.Quantity * input.UnitAmount,
+		}
+		if input.TaxRate != nil {
+			bps, ok := percentageToBps(input.TaxRate.Percentage)
+			if !ok || input.TaxRate.Name == "" {
+				return nil, fmt.Errorf("%w: line_items[%d].tax_rate needs a name and a percentage from 0 to 100 with at most two decimals", ErrInvalidCheckoutSession, i)
+			}
+			item.TaxName = input.TaxRate.Name
+			item.TaxRateBps = bps
+			item.TaxAmount = (item.Amount*int64(bps) + 5000) / 10000
+		}
+		session.Subtotal += item.Amount
+		session.TaxAmount += item.TaxAmount
+		session.LineItems = append(session.LineItems, item)
+	}
+
+	codes := make(map[string]bool, len(req.ShippingOptions))
+	for i, input := range req.ShippingOptions {
+		if input.Code == "" || len(input.Code) > 64 || input.Name == "" || input.Amount < 0 {
+			return nil, fmt.Errorf("%w: shipping_options[%d] needs a code of up to 64 characters, a name and an amount of at least 0", ErrInvalidCheckoutSession, i)
+		}
+		if codes[input.Code] {
+			return nil, fmt.Errorf("%w: shipping option code %q is used twice", ErrInvalidCheckoutSession, input.Code)
+		}
+		codes[input.Code] = true
+		session.ShippingOptions = append(session.ShippingOptions, model.CheckoutShippingOption{
+			Code:   input.Code,
+			Name:   input.Name,
+			Amount: input.Amount,
+		})
+	}
+
+	session.AmountTotal = session.Subtotal + session.TaxAmount
+	if len(session.ShippingOptions) > 0 {
+		session.SelectShipping(&session.ShippingOptions[0])
+	}
+	if session.AmountTotal <= 0 {
+		return nil, fmt.Errorf("%w: the total must be positive", ErrInvalidCheckoutSession)
+	}
+	return session, nil
+}
+
+// percentageToBps converts a tax percentage to basis points, refusing values
+// out of range or with more than two decimals
+func percentageToBps(percentage float64) (int, bool) {
+	bps := math.Round(percentage * 100)
+	if math.Abs(percentage*100-bps) > 1e-6 || bps < 0 || bps > 10000 {
+		return 0, false
+	}
+	return int(bps), true
+}
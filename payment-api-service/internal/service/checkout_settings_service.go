@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+const (
+	maxAllowedOrigins         = 20
+	maxSurchargeDisclosureLen = 500
+)
+
+type CheckoutSettingsService struct {
+	checkoutRepo  *repository.CheckoutSettingsRepository
+	captchaClient *client.CaptchaClient
+}
+
+func NewCheckoutSettingsService() *CheckoutSettingsService {
+	return &CheckoutSettingsService{
+		checkoutRepo:  repository.NewCheckoutSettingsRepository(),
+		captchaClient: client.NewCaptchaClient(),
+	}
+}
+
+// GetSettings returns a merchant's hosted checkout settings
+func (s *CheckoutSettingsService) GetSettings(merchantID uuid.UUID) (*model.CheckoutSettings, error) {
+	return s.checkoutRepo.FindByMerchant(merchantID)
+}
+
+// UpdateSettings replaces a merchant's origin allowlist, CAPTCHA
+// requirement, accepted card brands and surcharge disclosure
+func (s *CheckoutSettingsService) UpdateSettings(merchantID uuid.UUID, allowedOrigins []string, requireCaptcha bool, acceptedBrands []string, surchargeDisclosure string) (*model.CheckoutSettings, error) {
+	origins, err := NormalizeOrigins(allowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	brands, err := NormalizeCardBrands(acceptedBrands)
+	if err != nil {
+		return nil, err
+	}
+	surchargeDisclosure = strings.TrimSpace(surchargeDisclosure)
+	if utf8.RuneCountInString(surchargeDisclosure) > maxSurchargeDisclosureLen {
+		return nil, fmt.Errorf("surcharge_disclosure must be at most %d characters", maxSurchargeDisclosureLen)
+	}
+
+	if requireCaptcha && !s.captchaClient.Enabled() {
+		return nil, errors.New("CAPTCHA is not configured on this gateway")
+	}
+
+	settings := &model.CheckoutSettings{
+		MerchantID:          merchantID,
+		AllowedOrigins:      strings.Join(origins, ","),
+		RequireCaptcha:      requireCaptcha,
+		AcceptedBrands:      strings.Join(brands, ","),
+		SurchargeDisclosure: surchargeDisclosure,
+	}
+	if err := s.checkoutRepo.Upsert(settings); err != nil {
+		return nil, err
+	}
+
+	return s.checkoutRepo.FindByMerchant(merchantID)
+}
+
+// RedirectSecret returns the key success redirects are signed with,
+// creating it on first use
+func (s *CheckoutSettingsService) RedirectSecret(merchantID uuid.UUID) (string, error) {
+	settings, err := s.checkoutRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return "", err
+	}
+	if settings.RedirectSecret.Valid && settings.RedirectSecret.String != "" {
+		return settings.RedirectSecret.String, nil
+	}
+
+	secret, err := generateRedirectSecret()
+	if err != nil {
+		return "", err
+	}
+	// Two first confirmations may race; the first stored key wins
+	return s.checkoutRepo.EnsureRedirectSecret(merchantID, secret)
+}
+
+// RotateRedirectSecret replaces the redirect signing key. Redirects signed
+// with the old key stop verifying at once.
+func (s *CheckoutSettingsService) RotateRedirectSecret(merchantID uuid.UUID) (string, error) {
+	secret, err := generateRedirectSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkoutRepo.SetRedirectSecret(merchantID, secret); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func generateRedirectSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "rds_" + hex.EncodeToString(bytes), nil
+}
+
+// NormalizeCardBrands lowercases and deduplicates brands, rejecting any the
+// gateway does not support. An empty list accepts every supported brand.
+func NormalizeCardBrands(brands []string) ([]string, error) {
+	normalized := make([]string, 0, len(brands))
+	seen := make(map[string]bool, len(brands))
+	for _, b := range brands {
+		brand := strings.ToLower(strings.TrimSpace(b))
+		if !slices.Contains(model.SupportedCardBrands, brand) {
+			return nil, fmt.Errorf("unsupported card brand %q: expected one of %s", b, strings.Join(model.SupportedCardBrands, ", "))
+		}
+		if !seen[brand] {
+			seen[brand] = true
+			normalized = append(normalized, brand)
+		}
+	}
+	return normalized, nil
+}
+
+// NormalizeOrigins validates origins and reduces them to scheme://host[:port]
+func NormalizeOrigins(origins []string) ([]string, error) {
+	if len(origins) > maxAllowedOrigins {
+		return nil, fmt.Errorf("at most %d allowed origins are supported", maxAllowedOrigins)
+	}
+
+	normalized := make([]string, 0, len(origins))
+	seen := make(map[string]bool, len(origins))
+	for _, o := range origins {
+		origin := OriginOf(strings.TrimSpace(o))
+		if origin == "" || !validOriginWildcard(origin) {
+			return nil, fmt.Errorf("invalid origin %q: expected http(s)://host or http(s)://*.domain", o)
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			normalized = append(normalized, origin)
+		}
+	}
+	return normalized, nil
+}
+
+// validOriginWildcard allows a wildcard only as the first label of a host
+// with at least two more labels, e.g. https://*.shop.example
+func validOriginWildcard(origin string) bool {
+	if !strings.Contains(origin, "*") {
+		return true
+	}
+	_, domain, ok := strings.Cut(origin, "://*.")
+	return ok && !strings.Contains(domain, "*") && strings.Contains(strings.Split(domain, ":")[0], ".")
+}
+
+// OriginOf reduces a URL to its origin (scheme://host[:port]), or "" if invalid
+func OriginOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
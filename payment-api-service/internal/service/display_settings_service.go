@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidDisplaySettings = errors.New("invalid display settings")
+
+// localePattern accepts a language with an optional region, e.g. "ar" or "fr-MA"
+var localePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// brandColorPattern is a six-digit hex color, the only form receipts embed
+var brandColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// DisplaySettingsService holds how dates and amounts are shown to a merchant
+type DisplaySettingsService struct {
+	settingsRepo *repository.DisplaySettingsRepository
+}
+
+func NewDisplaySettingsService() *DisplaySettingsService {
+	return &DisplaySettingsService{
+		settingsRepo: repository.NewDisplaySettingsRepository(),
+	}
+}
+
+// GetSettings returns the merchant's display settings
+func (s *DisplaySettingsService) GetSettings(merchantID uuid.UUID) (*model.MerchantDisplaySettings, error) {
+	return s.settingsRepo.FindByMerchant(merchantID)
+}
+
+// Resolve returns the merchant's settings for rendering. Lookup failures
+// fall back to the defaults so a rendering never fails on them.
+func (s *DisplaySettingsService) Resolve(merchantID uuid.UUID) *model.MerchantDisplaySettings {
+	settings, err := s.settingsRepo.FindByMerchant(merchantID)
+	if err != nil {
+		logger.Log.Warn("Failed to load display settings, using defaults",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		return model.DefaultDisplaySettings(merchantID)
+	}
+	return settings
+}
+
+// Location returns the merchant's timezone
+func (s *DisplaySettingsService) Location(merchantID uuid.UUID) *time.Location {
+	return s.Resolve(merchantID).Location()
+}
+
+// UpdateSettings validates and stores the merchant's display settings
+func (s *DisplaySettingsService) UpdateSettings(settings *model.MerchantDisplaySettings) (*model.MerchantDisplaySettings, error) {
+	if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidDisplaySettings, settings.Timezone)
+	}
+	if !localePattern.MatchString(settings.Locale) {
+		return nil, fmt.Errorf("%w: locale must look like \"fr\" or \"fr-MA\"", ErrInvalidDisplaySettings)
+	}
+	switch settings.NumberFormat {
+	case model.NumberFormatSpaceComma, model.NumberFormatCommaDot, model.NumberFormatDotComma:
+	default:
+		return nil, fmt.Errorf("%w: number_format must be space_comma, comma_dot or dot_comma", ErrInvalidDisplaySettings)
+	}
+	if utf8.RuneCountInString(settings.StatementDescriptor) > 22 {
+		return nil, fmt.Errorf("%w: statement_descriptor is longer than 22 characters", ErrInvalidDisplaySettings)
+	}
+	if settings.BrandColor != "" && !brandColorPattern.MatchString(settings.BrandColor) {
+		return nil, fmt.Errorf("%w: brand_color must look like \"#3B82F6\"", ErrInvalidDisplaySettings)
+	}
+	if settings.LogoURL != "" {
+		// Mail clients block plain-http images, and anything else is not an image
+		if u, err := url.Parse(settings.LogoURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("%w: logo_url must be an https URL", ErrInvalidDisplaySettings)
+		}
+	}
+
+	if err := s.settingsRepo.Upsert(settings); err != nil {
+		return nil, err
+	}
+	return s.settingsRepo.FindByMerchant(settings.MerchantID)
+}
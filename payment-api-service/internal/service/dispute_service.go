@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+// MaxDisputeEvidenceSize matches the transaction service's per-file limit
+const MaxDisputeEvidenceSize = 3 << 20
+
+// DisputeService lets merchants answer chargebacks held by the transaction
+// service
+type DisputeService struct {
+	transactionClient *client.TransactionClient
+}
+
+func NewDisputeService() *DisputeService {
+	return &DisputeService{
+		transactionClient: client.NewTransactionClient(),
+	}
+}
+
+func (s *DisputeService) ListDisputes(ctx context.Context, req *pb.ListDisputesRequest) (*pb.ListDisputesResponse, error) {
+	return s.transactionClient.ListDisputes(ctx, req)
+}
+
+func (s *DisputeService) GetDispute(ctx context.Context, req *pb.GetDisputeRequest) (*pb.DisputeResponse, error) {
+	return s.transactionClient.GetDispute(ctx, req)
+}
+
+func (s *DisputeService) UploadEvidenceFile(ctx context.Context, req *pb.UploadDisputeEvidenceRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	return s.transactionClient.UploadDisputeEvidence(ctx, req)
+}
+
+func (s *DisputeService) GetEvidenceFile(ctx context.Context, req *pb.GetDisputeEvidenceFileRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	return s.transactionClient.GetDisputeEvidenceFile(ctx, req)
+}
+
+func (s *DisputeService) SubmitEvidence(ctx context.Context, req *pb.SubmitDisputeEvidenceRequest) (*pb.DisputeResponse, error) {
+	return s.transactionClient.SubmitDisputeEvidence(ctx, req)
+}
+
+func (s *DisputeService) AcceptDispute(ctx context.Context, req *pb.AcceptDisputeRequest) (*pb.DisputeResponse, error) {
+	return s.transactionClient.AcceptDispute(ctx, req)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+// eventPreviewLength caps the payload shown for each event in a list
+const eventPreviewLength = 200
+
+// EventLogService exposes the payment events recorded for a merchant. The
+// log is written with the payment itself, whether or not a webhook or the
+// event broker ever saw the event, so merchants can reconcile against it.
+type EventLogService struct {
+	eventRepo *repository.EventRepository
+}
+
+func NewEventLogService() *EventLogService {
+	return &EventLogService{
+		eventRepo: repository.NewEventRepository(),
+	}
+}
+
+// Event is one entry of the event log. Lists carry PayloadPreview; a single
+// event carries the full Payload.
+type Event struct {
+	ID             uuid.UUID           `json:"id"`
+	Type           string              `json:"type"`
+	PaymentID      uuid.UUID           `json:"payment_id"`
+	TestMode       bool                `json:"test_mode"`
+	OldStatus      model.PaymentStatus `json:"old_status,omitempty"`
+	NewStatus      model.PaymentStatus `json:"new_status,omitempty"`
+	Amount         int64               `json:"amount"`
+	Currency       string              `json:"currency"`
+	CreatedAt      time.Time           `json:"created_at"`
+	PayloadPreview string              `json:"payload_preview,omitempty"`
+	Payload        json.RawMessage     `json:"payload,omitempty"`
+}
+
+// ListEvents returns the merchant's events, newest first
+func (s *EventLogService) ListEvents(ctx context.Context, merchantID uuid.UUID, filter repository.EventFilter, limit, offset int) ([]Event, error) {
+	rows, err := s.eventRepo.WithContext(ctx).ListByMerchant(merchantID, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(rows))
+	for i := range rows {
+		event, payload, err := newEvent(merchantID, &rows[i])
+		if err != nil {
+			return nil, err
+		}
+		event.PayloadPreview = previewPayload(payload)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// GetEvent returns one of the merchant's events with its full payload
+func (s *EventLogService) GetEvent(ctx context.Context, id, merchantID uuid.UUID) (*Event, error) {
+	row, err := s.eventRepo.WithContext(ctx).FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, payload, err := newEvent(merchantID, row)
+	if err != nil {
+		return nil, err
+	}
+	event.Payload = payload
+	return &event, nil
+}
+
+// newEvent builds the log entry and its payload. The payload is the
+// message the event broker receives for the event, except that its id is
+// the payment event's, so it is stable across reads.
+func newEvent(merchantID uuid.UUID, row *repository.MerchantEvent) (Event, json.RawMessage, error) {
+	event := Event{
+		ID:        row.ID,
+		Type:      repository.PaymentEventType(&row.PaymentEvent),
+		PaymentID: row.PaymentID,
+		TestMode:  row.TestMode,
+		OldStatus: row.OldStatus,
+		NewStatus: row.NewStatus,
+		Amount:    row.Amount,
+		Currency:  row.Currency,
+		CreatedAt: row.CreatedAt,
+	}
+
+	payload, err := json.Marshal(repository.OutboxMessage{
+		ID:            row.ID,
+		Type:          event.Type,
+		AggregateType: "payment",
+		AggregateID:   row.PaymentID,
+		MerchantID:    merchantID,
+		TestMode:      row.TestMode,
+		OccurredAt:    row.CreatedAt.UTC(),
+		Data:          row.PaymentEvent,
+	})
+	if err != nil {
+		return Event{}, nil, err
+	}
+	return event, payload, nil
+}
+
+// previewPayload shortens payload to eventPreviewLength bytes without
+// splitting a character
+func previewPayload(payload []byte) string {
+	if len(payload) <= eventPreviewLength {
+		return string(payload)
+	}
+	cut := eventPreviewLength
+	for cut > 0 && !utf8.RuneStart(payload[cut]) {
+		cut--
+	}
+	return string(payload[:cut]) + "…"
+}
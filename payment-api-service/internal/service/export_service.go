@@ -0,0 +1,688 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/storage"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	exportBatchSize     = 500
+	exportMaxRange      = 366 * 24 * time.Hour
+	exportRetention     = 24 * time.Hour
+	exportURLTTL        = 15 * time.Minute
+	exportPollInterval  = 5 * time.Second
+	exportSweepInterval = 10 * time.Minute
+	exportStaleAfter    = 30 * time.Minute
+
+	// Final exports outlive the merchant's API access, so keep them longer
+	finalExportRetention = 90 * 24 * time.Hour
+)
+
+var (
+	ErrExportNotReady       = errors.New("export is not ready for download")
+	ErrInvalidDownloadToken = errors.New("download link is invalid or has expired")
+)
+
+type ExportService struct {
+	exportRepo        *repository.ExportRepository
+	paymentRepo       *repository.PaymentRepository
+	transactionClient *client.TransactionClient
+	tokenClient       *client.TokenizationClient
+	merchantClient    *client.MerchantServiceClient
+	webhookSubs       *repository.WebhookSubscriptionRepository
+	displaySettings   *DisplaySettingsService
+	timelines         *transactionTimelineBuilder
+	store             storage.Store
+	signingKey        []byte
+}
+
+func NewExportService() *ExportService {
+	// EXPORT_DIR stays the local directory until STORAGE_BACKEND says otherwise
+	store, err := storage.FromEnv(config.GetEnvWithDefault("EXPORT_DIR", filepath.Join(os.TempDir(), "payment-exports")))
+	if err != nil {
+		logger.Log.Fatal("Invalid export storage configuration", zap.Error(err))
+	}
+
+	signingKey := []byte(config.GetEnv("EXPORT_SIGNING_SECRET"))
+	if len(signingKey) == 0 {
+		// Links then only verify on this replica and die with the process
+		logger.Log.Warn("EXPORT_SIGNING_SECRET not set, using an ephemeral key for download links")
+		signingKey = make([]byte, 32)
+		rand.Read(signingKey)
+	}
+
+	transactionClient := client.NewTransactionClient()
+	tokenClient, _ := client.NewTokenizationClient()
+
+	return &ExportService{
+		exportRepo:        repository.NewExportRepository(),
+		paymentRepo:       repository.NewPaymentRepository(),
+		transactionClient: transactionClient,
+		tokenClient:       tokenClient,
+		merchantClient:    client.NewMerchantServiceClient(),
+		webhookSubs:       repository.NewWebhookSubscriptionRepository(),
+		displaySettings:   NewDisplaySettingsService(),
+		timelines:         newTransactionTimelineBuilder(transactionClient, signingKey),
+		store:             store,
+		signingKey:        signingKey,
+	}
+}
+
+type CreateExportRequest struct {
+	MerchantID uuid.UUID
+	CreatedBy  uuid.UUID
+	Resource   model.ExportResource
+	Format     model.ExportFormat
+	DateFrom   time.Time
+	DateTo     time.Time
+	Status     string
+}
+
+type ExportResponse struct {
+	*model.ExportJob
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// CreateExport validates the request and queues the job for the worker
+func (s *ExportService) CreateExport(req *CreateExportRequest) (*ExportResponse, error) {
+	switch req.Resource {
+	case model.ExportResourcePayments, model.ExportResourceTransactions:
+	default:
+		return nil, fmt.Errorf("resource must be 'payments' or 'transactions'")
+	}
+	switch req.Format {
+	case model.ExportFormatCSV, model.ExportFormatJSON:
+	default:
+		return nil, fmt.Errorf("format must be 'csv' or 'json'")
+	}
+	if !req.DateTo.After(req.DateFrom) {
+		return nil, fmt.Errorf("date_to must be after date_from")
+	}
+	if req.DateTo.Sub(req.DateFrom) > exportMaxRange {
+		return nil, fmt.Errorf("date range cannot exceed 366 days")
+	}
+
+	job := &model.ExportJob{
+		MerchantID:   req.MerchantID,
+		Resource:     req.Resource,
+		Format:       req.Format,
+		DateFrom:     req.DateFrom,
+		DateTo:       req.DateTo,
+		StatusFilter: req.Status,
+		Status:       model.ExportStatusPending,
+		CreatedBy:    req.CreatedBy,
+	}
+	if err := s.exportRepo.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	logger.Log.Info("Export job queued",
+		zap.String("export_id", job.ID.String()),
+		zap.String("merchant_id", req.MerchantID.String()),
+		zap.String("resource", string(req.Resource)),
+	)
+
+	return &ExportResponse{ExportJob: job}, nil
+}
+
+// CreateFinalExports queues CSV exports of a merchant's whole payment and
+// transaction history for offboarding, split into ranges the worker accepts
+func (s *ExportService) CreateFinalExports(merchantID uuid.UUID) ([]*ExportResponse, error) {
+	since, err := s.paymentRepo.FirstPaymentAt(merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []*ExportResponse{}, nil
+		}
+		return nil, fmt.Errorf("failed to find payment history: %w", err)
+	}
+
+	until := time.Now()
+	var responses []*ExportResponse
+	for from := since.Truncate(24 * time.Hour); from.Before(until); from = from.Add(exportMaxRange) {
+		to := from.Add(exportMaxRange)
+		if to.After(until) {
+			to = until
+		}
+		for _, resource := range []model.ExportResource{model.ExportResourcePayments, model.ExportResourceTransactions} {
+			job := &model.ExportJob{
+				MerchantID:     merchantID,
+				Resource:       resource,
+				Format:         model.ExportFormatCSV,
+				DateFrom:       from,
+				DateTo:         to,
+				Status:         model.ExportStatusPending,
+				RetentionHours: int(finalExportRetention / time.Hour),
+			}
+			if err := s.exportRepo.Create(job); err != nil {
+				return nil, fmt.Errorf("failed to create final export: %w", err)
+			}
+			responses = append(responses, &ExportResponse{ExportJob: job})
+		}
+	}
+
+	logger.Log.Info("Final exports queued",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int("jobs", len(responses)),
+	)
+
+	return responses, nil
+}
+
+// ExportTransactionTimeline bundles everything recorded about a payment and
+// signs it. Small payments are bundled right away; for large ones a job is
+// queued and returned instead, and the bundle is downloaded like any export.
+func (s *ExportService) ExportTransactionTimeline(ctx context.Context, paymentID, merchantID, createdBy uuid.UUID) (*SignedTimeline, *ExportResponse, error) {
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	large, err := s.timelines.isLarge(paymentID, merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !large {
+		timeline, err := s.timelines.Build(ctx, paymentID, merchantID)
+		if err != nil {
+			return nil, nil, err
+		}
+		signed, err := s.timelines.Sign(timeline)
+		return signed, nil, err
+	}
+
+	job := &model.ExportJob{
+		MerchantID: merchantID,
+		Resource:   model.ExportResourceTransactionTimeline,
+		Format:     model.ExportFormatJSON,
+		PaymentID:  &paymentID,
+		DateFrom:   payment.CreatedAt,
+		DateTo:     time.Now(),
+		Status:     model.ExportStatusPending,
+		CreatedBy:  createdBy,
+	}
+	if err := s.exportRepo.Create(job); err != nil {
+		return nil, nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	logger.Log.Info("Transaction timeline export queued",
+		zap.String("export_id", job.ID.String()),
+		zap.String("payment_id", paymentID.String()),
+	)
+
+	return nil, &ExportResponse{ExportJob: job}, nil
+}
+
+// VerifyTransactionTimeline reports whether a timeline bundle was signed by
+// this gateway and is unchanged
+func (s *ExportService) VerifyTransactionTimeline(data []byte) (bool, error) {
+	return s.timelines.Verify(data)
+}
+
+// GetExport returns the job, with a freshly signed download URL once complete
+func (s *ExportService) GetExport(id, merchantID uuid.UUID) (*ExportResponse, error) {
+	job, err := s.exportRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildResponse(job), nil
+}
+
+func (s *ExportService) ListExports(merchantID uuid.UUID, limit, offset int) ([]*ExportResponse, error) {
+	jobs, err := s.exportRepo.ListByMerchant(merchantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*ExportResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = s.buildResponse(&jobs[i])
+	}
+	return responses, nil
+}
+
+// ExportDownload is either a direct link into the object store or the
+// file's content for the handler to stream
+type ExportDownload struct {
+	Job         *model.ExportJob
+	RedirectURL string
+	Content     io.ReadCloser
+}
+
+// OpenDownload checks a signed link and returns where to fetch the export.
+// Backends that presign URLs get a redirect that lasts no longer than the
+// link the merchant was given.
+func (s *ExportService) OpenDownload(ctx context.Context, id uuid.UUID, expires int64, signature string) (*ExportDownload, error) {
+	if time.Now().Unix() > expires {
+		return nil, ErrInvalidDownloadToken
+	}
+	expected := s.sign(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidDownloadToken
+	}
+
+	job, err := s.exportRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidDownloadToken
+		}
+		return nil, err
+	}
+	if job.Status != model.ExportStatusCompleted || job.FilePath == "" {
+		return nil, ErrExportNotReady
+	}
+
+	if filepath.IsAbs(job.FilePath) {
+		// Written before exports moved to the storage backend
+		f, err := os.Open(job.FilePath)
+		if err != nil {
+			return nil, ErrExportNotReady
+		}
+		return &ExportDownload{Job: job, Content: f}, nil
+	}
+
+	if url, ok := s.store.SignedURL(job.FilePath, job.FileName(), time.Until(time.Unix(expires, 0))); ok {
+		return &ExportDownload{Job: job, RedirectURL: url}, nil
+	}
+	content, err := s.store.Open(ctx, job.FilePath)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logger.Log.Error("Failed to open export", zap.String("export_id", id.String()), zap.Error(err))
+		}
+		return nil, ErrExportNotReady
+	}
+	return &ExportDownload{Job: job, Content: content}, nil
+}
+
+func (s *ExportService) buildResponse(job *model.ExportJob) *ExportResponse {
+	resp := &ExportResponse{ExportJob: job}
+	if job.Status != model.ExportStatusCompleted || !job.ExpiresAt.Valid {
+		return resp
+	}
+
+	expiresAt := time.Now().Add(exportURLTTL)
+	if job.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = job.ExpiresAt.Time
+	}
+	resp.DownloadURL = fmt.Sprintf("/api/public/exports/%s/download?expires=%d&signature=%s",
+		job.ID, expiresAt.Unix(), s.sign(job.ID, expiresAt.Unix()))
+	resp.DownloadExpiresAt = &expiresAt
+	return resp
+}
+
+func (s *ExportService) sign(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(id.String() + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// =========================================================================
+// Worker
+// =========================================================================
+
+// RunWorker processes queued exports and removes expired files until ctx is canceled
+func (s *ExportService) RunWorker(ctx context.Context) error {
+	logger.Log.Info("Starting export worker", zap.String("storage_backend", s.store.Backend()))
+
+	if err := s.exportRepo.ResetStale(time.Now().Add(-exportStaleAfter)); err != nil {
+		logger.Log.Error("Failed to requeue stale exports", zap.Error(err))
+	}
+
+	poll := time.NewTicker(exportPollInterval)
+	defer poll.Stop()
+	sweep := time.NewTicker(exportSweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Export worker stopped")
+			return nil
+		case <-poll.C:
+			s.drainQueue(ctx)
+		case <-sweep.C:
+			s.removeExpired(ctx)
+		}
+	}
+}
+
+func (s *ExportService) drainQueue(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.exportRepo.ClaimNextPending()
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Log.Error("Failed to claim export job", zap.Error(err))
+			}
+			return
+		}
+		s.process(ctx, job)
+	}
+}
+
+func (s *ExportService) process(ctx context.Context, job *model.ExportJob) {
+	startTime := time.Now()
+	key := "exports/" + job.ID.String() + "." + string(job.Format)
+
+	rows, err := s.writeAndStore(ctx, job, key)
+	if err != nil {
+		logger.Log.Error("Export failed",
+			zap.String("export_id", job.ID.String()),
+			zap.Error(err),
+		)
+		s.exportRepo.MarkFailed(job.ID, err.Error())
+		return
+	}
+
+	retention := exportRetention
+	if job.RetentionHours > 0 {
+		retention = time.Duration(job.RetentionHours) * time.Hour
+	}
+	if err := s.exportRepo.MarkCompleted(job.ID, key, rows, time.Now().Add(retention)); err != nil {
+		logger.Log.Error("Failed to mark export completed", zap.Error(err))
+		return
+	}
+
+	logger.Log.Info("Export completed",
+		zap.String("export_id", job.ID.String()),
+		zap.Int("rows", rows),
+		zap.Duration("processing_time", time.Since(startTime)),
+	)
+}
+
+// writeAndStore builds the export in a local scratch file, then uploads it
+// to the storage backend under key
+func (s *ExportService) writeAndStore(ctx context.Context, job *model.ExportJob, key string) (int, error) {
+	f, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	rows, err := s.writeFile(ctx, job, f)
+	if err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := s.store.Put(ctx, key, f, size, job.ContentType()); err != nil {
+		return 0, fmt.Errorf("failed to store export: %w", err)
+	}
+	return rows, nil
+}
+
+func (s *ExportService) writeFile(ctx context.Context, job *model.ExportJob, f *os.File) (int, error) {
+	var err error
+	if job.Resource == model.ExportResourceTransactionTimeline {
+		return s.writeTimeline(ctx, job, f)
+	}
+	if job.Resource == model.ExportResourceAccount {
+		return s.writeAccountArchive(ctx, job, f)
+	}
+
+	var columns []string
+	if job.Resource == model.ExportResourcePayments {
+		columns = paymentExportColumns
+	} else {
+		columns = transactionExportColumns
+	}
+
+	// Timestamps keep RFC 3339 but carry the merchant's offset, so spreadsheet
+	// dates match the settlement days they belong to
+	loc := s.displaySettings.Location(job.MerchantID)
+
+	w := newExportWriter(f, job.Format, columns)
+	rows := 0
+	emit := func(values []interface{}) error {
+		rows++
+		return w.Write(values)
+	}
+
+	if job.Resource == model.ExportResourcePayments {
+		err = s.paymentRepo.FindInRangeBatches(job.MerchantID, model.PaymentStatus(job.StatusFilter),
+			job.DateFrom, job.DateTo, exportBatchSize, func(batch []model.Payment) error {
+				for i := range batch {
+					if err := emit(paymentExportRow(&batch[i], loc)); err != nil {
+						return err
+					}
+				}
+				return ctx.Err()
+			})
+	} else {
+		err = s.streamTransactions(ctx, job, loc, emit)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	return rows, f.Sync()
+}
+
+func (s *ExportService) writeTimeline(ctx context.Context, job *model.ExportJob, f *os.File) (int, error) {
+	if job.PaymentID == nil {
+		return 0, errors.New("timeline export has no payment")
+	}
+
+	timeline, err := s.timelines.Build(ctx, *job.PaymentID, job.MerchantID)
+	if err != nil {
+		return 0, err
+	}
+	signed, err := s.timelines.Sign(timeline)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.NewEncoder(f).Encode(signed); err != nil {
+		return 0, err
+	}
+	return timeline.RecordCount(), f.Sync()
+}
+
+func (s *ExportService) streamTransactions(ctx context.Context, job *model.ExportJob, loc *time.Location, emit func([]interface{}) error) error {
+	for offset := 0; ; offset += exportBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resp, err := s.transactionClient.ListTransactions(ctx, &pb.ListTransactionsRequest{
+			MerchantId:  job.MerchantID.String(),
+			Status:      job.StatusFilter,
+			Limit:       exportBatchSize,
+			Offset:      int32(offset),
+			CreatedFrom: job.DateFrom.UTC().Format(time.RFC3339),
+			CreatedTo:   job.DateTo.UTC().Format(time.RFC3339),
+			SortBy:      "created_at",
+			SortOrder:   "asc",
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+
+		for _, txn := range resp.Transactions {
+			if err := emit(transactionExportRow(txn, loc)); err != nil {
+				return err
+			}
+		}
+		if !resp.HasMore {
+			return nil
+		}
+	}
+}
+
+func (s *ExportService) removeExpired(ctx context.Context) {
+	jobs, err := s.exportRepo.FindExpired(time.Now())
+	if err != nil {
+		logger.Log.Error("Failed to list expired exports", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		if job.FilePath != "" {
+			var err error
+			if filepath.IsAbs(job.FilePath) {
+				if err = os.Remove(job.FilePath); os.IsNotExist(err) {
+					err = nil
+				}
+			} else {
+				err = s.store.Delete(ctx, job.FilePath)
+			}
+			if err != nil {
+				logger.Log.Warn("Failed to remove export file", zap.String("path", job.FilePath), zap.Error(err))
+				continue
+			}
+		}
+		s.exportRepo.MarkExpired(job.ID)
+	}
+}
+
+// =========================================================================
+// Row Mapping
+// =========================================================================
+
+var paymentExportColumns = []string{
+	"id", "created_at", "type", "status", "amount", "currency",
+	"card_brand", "card_last4", "auth_code", "response_code",
+	"fraud_score", "fraud_decision", "transaction_id", "customer_email", "description",
+}
+
+func paymentExportRow(p *model.Payment, loc *time.Location) []interface{} {
+	return []interface{}{
+		p.ID.String(), p.CreatedAt.In(loc).Format(time.RFC3339), string(p.Type), string(p.Status), p.Amount, p.Currency,
+		p.CardBrand, p.CardLast4, p.AuthCode.String, p.ResponseCode.String,
+		p.FraudScore, p.FraudDecision, p.TransactionID.String(), p.CustomerEmail.String, p.Description.String,
+	}
+}
+
+var transactionExportColumns = []string{
+	"id", "created_at", "type", "status", "amount", "currency", "amount_mad",
+	"card_brand", "card_last4", "captured_amount", "refunded_amount", "fraud_score",
+}
+
+func transactionExportRow(t *pb.TransactionResponse, loc *time.Location) []interface{} {
+	createdAt := t.CreatedAt
+	if parsed, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		createdAt = parsed.In(loc).Format(time.RFC3339)
+	}
+	return []interface{}{
+		t.Id, createdAt, t.Type, t.Status, t.Amount, t.Currency, t.AmountMad,
+		t.CardBrand, t.CardLast4, t.CapturedAmount, t.RefundedAmount, t.FraudScore,
+	}
+}
+
+// =========================================================================
+// Writers
+// =========================================================================
+
+type exportWriter interface {
+	Write(values []interface{}) error
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format model.ExportFormat, columns []string) exportWriter {
+	if format == model.ExportFormatJSON {
+		return &jsonExportWriter{w: w, columns: columns}
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	return &csvExportWriter{w: cw}
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) Write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			record[i] = escapeSpreadsheetFormula(s)
+		} else {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeSpreadsheetFormula stops customer-supplied text from being evaluated
+// as a formula when the file is opened in a spreadsheet
+func escapeSpreadsheetFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// jsonExportWriter streams a JSON array of objects without buffering the file
+type jsonExportWriter struct {
+	w       io.Writer
+	columns []string
+	started bool
+}
+
+func (j *jsonExportWriter) Write(values []interface{}) error {
+	obj := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		obj[j.columns[i]] = v
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if !j.started {
+		prefix = "[\n"
+		j.started = true
+	}
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) Close() error {
+	if !j.started {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Score thresholds for merchants that have not configured a risk_score rule
+const (
+	defaultFraudReviewScore  = 30
+	defaultFraudDeclineScore = 70
+)
+
+// FraudRuleEngine turns a payment's risk score and attributes into an
+// approve, review or decline decision using the merchant's fraud rules.
+//
+// Blocklists are checked first and decline outright; an allowlist match then
+// approves without looking at any other rule. Otherwise every enabled rule
+// is evaluated and the strictest triggered action wins, with the reason
+// codes of all triggered rules reported.
+type FraudRuleEngine struct {
+	ruleRepo *repository.FraudRuleRepository
+}
+
+func NewFraudRuleEngine() *FraudRuleEngine {
+	return &FraudRuleEngine{
+		ruleRepo: repository.NewFraudRuleRepository(),
+	}
+}
+
+// FraudRuleSubject is what the rules can see of a payment
+type FraudRuleSubject struct {
+	MerchantID      uuid.UUID
+	Amount          int64
+	Currency        string
+	CardToken       string
+	CardFingerprint string // Empty for stored cards
+	BIN             string // First 8 digits; empty for stored cards
+	Email           string
+	IP              string
+	RiskScore       int
+}
+
+// FraudRuleDecision is the engine's verdict
+type FraudRuleDecision struct {
+	Decision    model.FraudRuleAction
+	ReasonCodes []string
+}
+
+// Evaluate applies the merchant's rules to a payment. Velocity counters are
+// updated as a side effect, so call it once per attempt. It only fails when
+// the rules cannot be loaded; Redis or BIN lookup errors skip the rules that
+// need them.
+func (e *FraudRuleEngine) Evaluate(subject *FraudRuleSubject) (*FraudRuleDecision, error) {
+	rules, err := e.ruleRepo.FindByMerchant(subject.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	eval := &fraudRuleEvaluation{engine: e, subject: subject}
+
+	enabled := make([]*model.FraudRule, 0, len(rules))
+	hasScoreRule := false
+	for i := range rules {
+		if rules[i].Type == model.FraudRuleRiskScore {
+			hasScoreRule = true
+		}
+		if rules[i].Enabled {
+			enabled = append(enabled, &rules[i])
+		}
+	}
+
+	for _, rule := range enabled {
+		if rule.Type == model.FraudRuleBlocklist && eval.listMatches(rule) {
+			return &FraudRuleDecision{Decision: model.FraudActionDecline, ReasonCodes: []string{rule.ReasonCode}}, nil
+		}
+	}
+	for _, rule := range enabled {
+		if rule.Type == model.FraudRuleAllowlist && eval.listMatches(rule) {
+			return &FraudRuleDecision{Decision: model.FraudActionApprove, ReasonCodes: []string{rule.ReasonCode}}, nil
+		}
+	}
+
+	decision := &FraudRuleDecision{Decision: model.FraudActionApprove, ReasonCodes: []string{}}
+	trigger := func(action model.FraudRuleAction, reasonCode string) {
+		if fraudActionSeverity(action) > fraudActionSeverity(decision.Decision) {
+			decision.Decision = action
+		}
+		if !containsString(decision.ReasonCodes, reasonCode) {
+			decision.ReasonCodes = append(decision.ReasonCodes, reasonCode)
+		}
+	}
+
+	for _, rule := range eval.velocityTriggered(enabled) {
+		trigger(rule.Action, rule.ReasonCode)
+	}
+
+	for _, rule := range enabled {
+		triggered := false
+		switch rule.Type {
+		case model.FraudRuleAmount:
+			triggered = (rule.Currency == "" || strings.EqualFold(rule.Currency, subject.Currency)) &&
+				((rule.MaxAmount > 0 && subject.Amount > rule.MaxAmount) || (rule.MinAmount > 0 && subject.Amount < rule.MinAmount))
+		case model.FraudRuleBINCountry:
+			if country := eval.country(); country != "" {
+				listed := containsString(rule.ValueList(), country)
+				triggered = listed == (rule.CountryMatch != "not_in")
+			}
+		case model.FraudRuleRiskScore:
+			triggered = subject.RiskScore >= rule.MinScore
+		}
+		if triggered {
+			trigger(rule.Action, rule.ReasonCode)
+		}
+	}
+
+	if !hasScoreRule {
+		switch {
+		case subject.RiskScore >= defaultFraudDeclineScore:
+			trigger(model.FraudActionDecline, "risk_score_high")
+		case subject.RiskScore >= defaultFraudReviewScore:
+			trigger(model.FraudActionReview, "risk_score_elevated")
+		}
+	}
+
+	return decision, nil
+}
+
+// fraudRuleEvaluation holds per-payment lookups so each runs at most once
+type fraudRuleEvaluation struct {
+	engine        *FraudRuleEngine
+	subject       *FraudRuleSubject
+	countryLoaded bool
+	countryCode   string
+}
+
+// country is the card's BIN issuing country, or "" if unknown
+func (ev *fraudRuleEvaluation) country() string {
+	if ev.countryLoaded {
+		return ev.countryCode
+	}
+	ev.countryLoaded = true
+	if ev.subject.BIN == "" {
+		return ""
+	}
+	country, err := ev.engine.ruleRepo.FindBINCountry(ev.subject.BIN)
+	if err != nil {
+		logger.Log.Warn("BIN country lookup failed", zap.Error(err))
+		return ""
+	}
+	ev.countryCode = country
+	return country
+}
+
+// cardValues are the identifiers a card rule may match: the fingerprint,
+// which is the same for every token of the card, and the token itself
+func (ev *fraudRuleEvaluation) cardValues() []string {
+	values := make([]string, 0, 2)
+	if ev.subject.CardFingerprint != "" {
+		values = append(values, ev.subject.CardFingerprint)
+	}
+	if ev.subject.CardToken != "" {
+		values = append(values, ev.subject.CardToken)
+	}
+	return values
+}
+
+// listMatches reports whether the payment's value for the rule's field is
+// on the rule's list
+func (ev *fraudRuleEvaluation) listMatches(rule *model.FraudRule) bool {
+	entries := rule.ValueList()
+	switch rule.Field {
+	case model.FraudFieldCard:
+		for _, v := range ev.cardValues() {
+			if containsString(entries, v) {
+				return true
+			}
+		}
+	case model.FraudFieldEmail:
+		return ev.subject.Email != "" && containsString(entries, strings.ToLower(strings.TrimSpace(ev.subject.Email)))
+	case model.FraudFieldIP:
+		return ipListed(entries, ev.subject.IP)
+	case model.FraudFieldBIN:
+		for _, entry := range entries {
+			if ev.subject.BIN != "" && strings.HasPrefix(ev.subject.BIN, entry) {
+				return true
+			}
+		}
+	case model.FraudFieldCountry:
+		country := ev.country()
+		return country != "" && containsString(entries, country)
+	}
+	return false
+}
+
+// velocityTriggered records this attempt once per counted field and returns
+// the velocity rules whose limit it exceeds
+func (ev *fraudRuleEvaluation) velocityTriggered(rules []*model.FraudRule) []*model.FraudRule {
+	byField := make(map[string][]*model.FraudRule)
+	for _, rule := range rules {
+		if rule.Type == model.FraudRuleVelocity {
+			byField[rule.Field] = append(byField[rule.Field], rule)
+		}
+	}
+
+	var triggered []*model.FraudRule
+	for _, field := range []string{model.FraudFieldCard, model.FraudFieldIP, model.FraudFieldEmail} {
+		fieldRules := byField[field]
+		if len(fieldRules) == 0 {
+			continue
+		}
+		value := ev.velocityValue(field)
+		if value == "" {
+			continue
+		}
+		windows := make([]time.Duration, len(fieldRules))
+		for i, rule := range fieldRules {
+			windows[i] = rule.Window()
+		}
+		counts, err := ev.engine.ruleRepo.RecordVelocity(ev.subject.MerchantID, field, value, windows)
+		if err != nil {
+			logger.Log.Warn("Fraud velocity counter unavailable, skipping velocity rules",
+				zap.String("field", field),
+				zap.Error(err),
+			)
+			continue
+		}
+		for i, rule := range fieldRules {
+			if counts[i] > int64(rule.MaxAttempts) {
+				triggered = append(triggered, rule)
+			}
+		}
+	}
+	return triggered
+}
+
+func (ev *fraudRuleEvaluation) velocityValue(field string) string {
+	switch field {
+	case model.FraudFieldCard:
+		if values := ev.cardValues(); len(values) > 0 {
+			return values[0]
+		}
+	case model.FraudFieldIP:
+		return ev.subject.IP
+	case model.FraudFieldEmail:
+		return strings.ToLower(strings.TrimSpace(ev.subject.Email))
+	}
+	return ""
+}
+
+// ipListed matches an IP against exact addresses and CIDR ranges
+func ipListed(entries []string, ipAddress string) bool {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+		} else if entry == ip.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func fraudActionSeverity(action model.FraudRuleAction) int {
+	switch action {
+	case model.FraudActionDecline:
+		return 2
+	case model.FraudActionReview:
+		return 1
+	default:
+		return 0
+	}
+}
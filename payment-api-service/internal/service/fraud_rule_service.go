@@ -0,0 +1,333 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+const (
+	maxFraudRules         = 50
+	maxFraudRuleValues    = 1000
+	defaultVelocityWindow = 3600 // seconds
+	minVelocityWindow     = 60
+	maxVelocityWindow     = 7 * 24 * 3600
+)
+
+var ErrInvalidFraudRule = errors.New("invalid fraud rule")
+
+var (
+	fraudReasonCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+	countryCodePattern     = regexp.MustCompile(`^[A-Z]{2}$`)
+	binPattern             = regexp.MustCompile(`^[0-9]{6,8}$`)
+)
+
+// FraudRuleService manages the rules the fraud engine applies to a
+// merchant's payments
+type FraudRuleService struct {
+	ruleRepo *repository.FraudRuleRepository
+}
+
+func NewFraudRuleService() *FraudRuleService {
+	return &FraudRuleService{
+		ruleRepo: repository.NewFraudRuleRepository(),
+	}
+}
+
+// FraudRuleInput carries the fields a merchant can set. Nil fields are left
+// unchanged on update; the type cannot change once created.
+type FraudRuleInput struct {
+	Name          *string
+	Type          *string
+	Action        *string
+	ReasonCode    *string
+	Field         *string
+	MaxAttempts   *int
+	WindowSeconds *int
+	MinAmount     *int64
+	MaxAmount     *int64
+	Currency      *string
+	MinScore      *int
+	Values        *[]string
+	CountryMatch  *string
+	Enabled       *bool
+}
+
+// ListRules returns the merchant's fraud rules
+func (s *FraudRuleService) ListRules(merchantID uuid.UUID) ([]model.FraudRule, error) {
+	return s.ruleRepo.FindByMerchant(merchantID)
+}
+
+// GetRule returns one of the merchant's fraud rules
+func (s *FraudRuleService) GetRule(id, merchantID uuid.UUID) (*model.FraudRule, error) {
+	return s.ruleRepo.FindByIDAndMerchant(id, merchantID)
+}
+
+// CreateRule validates and stores a new rule. Rules are enabled unless the
+// input says otherwise.
+func (s *FraudRuleService) CreateRule(merchantID, createdBy uuid.UUID, input *FraudRuleInput) (*model.FraudRule, error) {
+	if input.Type == nil {
+		return nil, fmt.Errorf("%w: type is required", ErrInvalidFraudRule)
+	}
+
+	existing, err := s.ruleRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxFraudRules {
+		return nil, fmt.Errorf("%w: at most %d rules are supported", ErrInvalidFraudRule, maxFraudRules)
+	}
+
+	rule := &model.FraudRule{
+		MerchantID: merchantID,
+		Type:       model.FraudRuleType(strings.ToLower(strings.TrimSpace(*input.Type))),
+		Enabled:    true,
+		CreatedBy:  createdBy,
+	}
+	if err := applyFraudRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule changes a rule's parameters, action or state
+func (s *FraudRuleService) UpdateRule(id, merchantID uuid.UUID, input *FraudRuleInput) (*model.FraudRule, error) {
+	rule, err := s.ruleRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Type != nil && model.FraudRuleType(strings.ToLower(strings.TrimSpace(*input.Type))) != rule.Type {
+		return nil, fmt.Errorf("%w: the type of a rule cannot be changed", ErrInvalidFraudRule)
+	}
+	if err := applyFraudRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes a rule
+func (s *FraudRuleService) DeleteRule(id, merchantID uuid.UUID) error {
+	return s.ruleRepo.Delete(id, merchantID)
+}
+
+// applyFraudRuleInput sets the input's fields on rule and checks that the
+// result is a complete rule of its type
+func applyFraudRuleInput(rule *model.FraudRule, input *FraudRuleInput) error {
+	if input.Name != nil {
+		rule.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Action != nil {
+		rule.Action = model.FraudRuleAction(strings.ToLower(strings.TrimSpace(*input.Action)))
+	}
+	if input.ReasonCode != nil {
+		rule.ReasonCode = strings.ToLower(strings.TrimSpace(*input.ReasonCode))
+	}
+	if input.Field != nil {
+		rule.Field = strings.ToLower(strings.TrimSpace(*input.Field))
+	}
+	if input.MaxAttempts != nil {
+		rule.MaxAttempts = *input.MaxAttempts
+	}
+	if input.WindowSeconds != nil {
+		rule.WindowSeconds = *input.WindowSeconds
+	}
+	if input.MinAmount != nil {
+		rule.MinAmount = *input.MinAmount
+	}
+	if input.MaxAmount != nil {
+		rule.MaxAmount = *input.MaxAmount
+	}
+	if input.Currency != nil {
+		rule.Currency = strings.ToUpper(strings.TrimSpace(*input.Currency))
+	}
+	if input.MinScore != nil {
+		rule.MinScore = *input.MinScore
+	}
+	if input.CountryMatch != nil {
+		rule.CountryMatch = strings.ToLower(strings.TrimSpace(*input.CountryMatch))
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+
+	if err := validateFraudRule(rule); err != nil {
+		return err
+	}
+
+	// Values are normalized for the rule's field, so this comes last
+	if input.Values != nil {
+		values, err := normalizeFraudRuleValues(rule, *input.Values)
+		if err != nil {
+			return err
+		}
+		rule.Values = strings.Join(values, ",")
+	}
+	if (rule.Type == model.FraudRuleBINCountry || rule.Type == model.FraudRuleBlocklist || rule.Type == model.FraudRuleAllowlist) && rule.Values == "" {
+		return fmt.Errorf("%w: values are required for %s rules", ErrInvalidFraudRule, rule.Type)
+	}
+
+	if rule.Name == "" {
+		rule.Name = strings.ReplaceAll(rule.ReasonCode, "_", " ")
+	}
+	if len(rule.Name) > 100 {
+		return fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidFraudRule)
+	}
+	return nil
+}
+
+// validateFraudRule checks the type-specific parameters and fills in the
+// defaults for the action, reason code and velocity window
+func validateFraudRule(rule *model.FraudRule) error {
+	switch rule.Type {
+	case model.FraudRuleVelocity:
+		if !containsString([]string{model.FraudFieldCard, model.FraudFieldIP, model.FraudFieldEmail}, rule.Field) {
+			return fmt.Errorf("%w: velocity rules count by card, ip or email", ErrInvalidFraudRule)
+		}
+		if rule.MaxAttempts < 1 {
+			return fmt.Errorf("%w: max_attempts must be at least 1", ErrInvalidFraudRule)
+		}
+		if rule.WindowSeconds == 0 {
+			rule.WindowSeconds = defaultVelocityWindow
+		}
+		if rule.WindowSeconds < minVelocityWindow || rule.WindowSeconds > maxVelocityWindow {
+			return fmt.Errorf("%w: window_seconds must be between %d and %d", ErrInvalidFraudRule, minVelocityWindow, maxVelocityWindow)
+		}
+		setDefaultReasonCode(rule, "velocity_"+rule.Field)
+
+	case model.FraudRuleAmount:
+		if rule.MinAmount < 0 || rule.MaxAmount < 0 {
+			return fmt.Errorf("%w: amounts cannot be negative", ErrInvalidFraudRule)
+		}
+		if rule.MinAmount == 0 && rule.MaxAmount == 0 {
+			return fmt.Errorf("%w: set min_amount, max_amount or both", ErrInvalidFraudRule)
+		}
+		if rule.MaxAmount > 0 && rule.MinAmount > rule.MaxAmount {
+			return fmt.Errorf("%w: min_amount cannot exceed max_amount", ErrInvalidFraudRule)
+		}
+		if rule.Currency != "" && !containsString([]string{"USD", "EUR", "MAD"}, rule.Currency) {
+			return fmt.Errorf("%w: unsupported currency %q", ErrInvalidFraudRule, rule.Currency)
+		}
+		setDefaultReasonCode(rule, "amount_limit")
+
+	case model.FraudRuleBINCountry:
+		switch rule.CountryMatch {
+		case "":
+			rule.CountryMatch = "in"
+		case "in", "not_in":
+		default:
+			return fmt.Errorf("%w: country_match must be in or not_in", ErrInvalidFraudRule)
+		}
+		setDefaultReasonCode(rule, "bin_country")
+
+	case model.FraudRuleRiskScore:
+		if rule.MinScore < 1 || rule.MinScore > 100 {
+			return fmt.Errorf("%w: min_score must be between 1 and 100", ErrInvalidFraudRule)
+		}
+		setDefaultReasonCode(rule, "risk_score")
+
+	case model.FraudRuleBlocklist, model.FraudRuleAllowlist:
+		if !containsString([]string{model.FraudFieldCard, model.FraudFieldIP, model.FraudFieldEmail, model.FraudFieldBIN, model.FraudFieldCountry}, rule.Field) {
+			return fmt.Errorf("%w: list rules match card, ip, email, bin or country", ErrInvalidFraudRule)
+		}
+		setDefaultReasonCode(rule, string(rule.Type)+"_"+rule.Field)
+
+	default:
+		return fmt.Errorf("%w: unsupported type %q", ErrInvalidFraudRule, rule.Type)
+	}
+
+	// Lists decide on their own; everything else asks for review or decline
+	switch rule.Type {
+	case model.FraudRuleBlocklist:
+		if rule.Action != "" && rule.Action != model.FraudActionDecline {
+			return fmt.Errorf("%w: blocklist rules always decline", ErrInvalidFraudRule)
+		}
+		rule.Action = model.FraudActionDecline
+	case model.FraudRuleAllowlist:
+		if rule.Action != "" && rule.Action != model.FraudActionApprove {
+			return fmt.Errorf("%w: allowlist rules always approve", ErrInvalidFraudRule)
+		}
+		rule.Action = model.FraudActionApprove
+	default:
+		if rule.Action != model.FraudActionReview && rule.Action != model.FraudActionDecline {
+			return fmt.Errorf("%w: action must be review or decline", ErrInvalidFraudRule)
+		}
+	}
+
+	if !fraudReasonCodePattern.MatchString(rule.ReasonCode) {
+		return fmt.Errorf("%w: reason_code must be 1-50 lowercase letters, digits or underscores", ErrInvalidFraudRule)
+	}
+	return nil
+}
+
+func setDefaultReasonCode(rule *model.FraudRule, code string) {
+	if rule.ReasonCode == "" {
+		rule.ReasonCode = code
+	}
+}
+
+// normalizeFraudRuleValues canonicalizes list entries for the rule's field
+// and drops duplicates
+func normalizeFraudRuleValues(rule *model.FraudRule, values []string) ([]string, error) {
+	if len(values) > maxFraudRuleValues {
+		return nil, fmt.Errorf("%w: at most %d values are supported", ErrInvalidFraudRule, maxFraudRuleValues)
+	}
+
+	field := rule.Field
+	if rule.Type == model.FraudRuleBINCountry {
+		field = model.FraudFieldCountry
+	}
+
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		value := strings.TrimSpace(v)
+		switch field {
+		case model.FraudFieldCountry:
+			value = strings.ToUpper(value)
+			if !countryCodePattern.MatchString(value) {
+				return nil, fmt.Errorf("%w: invalid country code %q", ErrInvalidFraudRule, v)
+			}
+		case model.FraudFieldBIN:
+			if !binPattern.MatchString(value) {
+				return nil, fmt.Errorf("%w: a BIN is 6 to 8 digits, got %q", ErrInvalidFraudRule, v)
+			}
+		case model.FraudFieldIP:
+			if _, network, err := net.ParseCIDR(value); err == nil {
+				value = network.String()
+			} else if ip := net.ParseIP(value); ip != nil {
+				value = ip.String()
+			} else {
+				return nil, fmt.Errorf("%w: invalid IP address or CIDR %q", ErrInvalidFraudRule, v)
+			}
+		case model.FraudFieldEmail:
+			value = strings.ToLower(value)
+			if !strings.Contains(value, "@") || strings.ContainsAny(value, ", ") {
+				return nil, fmt.Errorf("%w: invalid email %q", ErrInvalidFraudRule, v)
+			}
+		case model.FraudFieldCard:
+			// Card token or tokenization fingerprint
+			if value == "" || strings.ContainsAny(value, ", ") {
+				return nil, fmt.Errorf("%w: invalid card token or fingerprint %q", ErrInvalidFraudRule, v)
+			}
+		}
+		if !seen[value] {
+			seen[value] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized, nil
+}
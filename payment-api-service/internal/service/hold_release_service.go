@@ -0,0 +1,328 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidHoldReleaseTemplate = errors.New("invalid hold release template")
+
+const maxHoldReleaseTemplateBody = 20000
+
+// HoldReleaseService emails customers that the hold on their card is being
+// released when an authorization is voided or expires, so they do not
+// contact support about money that looks taken. Merchants opt in and may
+// replace the built-in email with their own template per language.
+type HoldReleaseService struct {
+	holdRepo        *repository.HoldReleaseRepository
+	paymentRepo     *repository.PaymentRepository
+	displaySettings *DisplaySettingsService
+	mailer          *mailer
+}
+
+func NewHoldReleaseService() *HoldReleaseService {
+	return &HoldReleaseService{
+		holdRepo:        repository.NewHoldReleaseRepository(),
+		paymentRepo:     repository.NewPaymentRepository(),
+		displaySettings: NewDisplaySettingsService(),
+		mailer:          newMailer(),
+	}
+}
+
+// HoldReleaseEmail holds the fields merchant templates can use, e.g.
+// {{.Amount}} or {{.Card}}
+type HoldReleaseEmail struct {
+	CustomerName string // Empty when the payment has none
+	Amount       string // Formatted with the merchant's number format
+	Card         string // e.g. "VISA ending in 4242", in the email's language
+	PaymentID    string
+	AuthorizedAt string // Date in the merchant's timezone
+	Reason       string // voided or expired
+}
+
+// sampleHoldReleaseEmail is what templates are checked against before saving
+var sampleHoldReleaseEmail = HoldReleaseEmail{
+	CustomerName: "Amina",
+	Amount:       "1 250,00 MAD",
+	Card:         "VISA ending in 4242",
+	PaymentID:    "00000000-0000-0000-0000-000000000000",
+	AuthorizedAt: "2026-01-15",
+	Reason:       model.HoldReleaseExpired,
+}
+
+// GetSettings returns whether the merchant sends hold release emails and
+// its custom templates
+func (s *HoldReleaseService) GetSettings(merchantID uuid.UUID) (*model.HoldReleaseSettings, []model.HoldReleaseTemplate, error) {
+	settings, err := s.holdRepo.FindSettings(merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := s.holdRepo.FindTemplates(merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, templates, nil
+}
+
+// UpdateSettings turns hold release emails on or off
+func (s *HoldReleaseService) UpdateSettings(merchantID uuid.UUID, enabled bool) (*model.HoldReleaseSettings, error) {
+	settings := &model.HoldReleaseSettings{
+		MerchantID: merchantID,
+		Enabled:    enabled,
+	}
+	if err := s.holdRepo.UpsertSettings(settings); err != nil {
+		return nil, err
+	}
+	return s.holdRepo.FindSettings(merchantID)
+}
+
+// SaveTemplate validates and stores the merchant's template for a language
+func (s *HoldReleaseService) SaveTemplate(merchantID, actorID uuid.UUID, language, subject, body string) (*model.HoldReleaseTemplate, error) {
+	if err := validateHoldReleaseLanguage(language); err != nil {
+		return nil, err
+	}
+	subject = strings.TrimSpace(subject)
+	if subject == "" || len(subject) > 255 {
+		return nil, fmt.Errorf("%w: subject must be 1-255 characters", ErrInvalidHoldReleaseTemplate)
+	}
+	if strings.TrimSpace(body) == "" || len(body) > maxHoldReleaseTemplateBody {
+		return nil, fmt.Errorf("%w: body must be 1-%d characters", ErrInvalidHoldReleaseTemplate, maxHoldReleaseTemplateBody)
+	}
+
+	tmpl := &model.HoldReleaseTemplate{
+		MerchantID: merchantID,
+		Language:   language,
+		Subject:    subject,
+		Body:       body,
+		UpdatedBy:  actorID,
+	}
+	// Catch syntax errors and unknown fields now rather than when a customer's email fails
+	if _, _, err := renderHoldReleaseTemplate(tmpl, &sampleHoldReleaseEmail); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHoldReleaseTemplate, err)
+	}
+
+	if err := s.holdRepo.UpsertTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return s.holdRepo.FindTemplate(merchantID, language)
+}
+
+// DeleteTemplate goes back to the built-in email for a language
+func (s *HoldReleaseService) DeleteTemplate(merchantID uuid.UUID, language string) error {
+	if err := validateHoldReleaseLanguage(language); err != nil {
+		return err
+	}
+	return s.holdRepo.DeleteTemplate(merchantID, language)
+}
+
+// NotifyHoldReleased emails the payment's customer, if there is one and the
+// merchant has turned the email on, that the hold is being released.
+// reason is model.HoldReleaseVoided or model.HoldReleaseExpired. Callers
+// do not wait on it, so errors are logged rather than returned.
+func (s *HoldReleaseService) NotifyHoldReleased(paymentID, merchantID uuid.UUID, reason string) {
+	if !s.mailer.enabled() {
+		return
+	}
+
+	settings, err := s.holdRepo.FindSettings(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load hold release settings", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+		return
+	}
+	if !settings.Enabled {
+		return
+	}
+
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load payment for hold release email", zap.String("payment_id", paymentID.String()), zap.Error(err))
+		return
+	}
+	if !payment.CustomerEmail.Valid || payment.CustomerEmail.String == "" {
+		return
+	}
+
+	display := s.displaySettings.Resolve(merchantID)
+	language := i18n.Match(payment.Language, display.Locale)
+	email := buildHoldReleaseEmail(payment, display, language, reason)
+
+	subject, body, err := s.render(merchantID, language, email)
+	if err != nil {
+		logger.Log.Error("Failed to render hold release email", zap.String("payment_id", paymentID.String()), zap.Error(err))
+		return
+	}
+
+	if err := s.mailer.send(payment.CustomerEmail.String, subject, body); err != nil {
+		logger.Log.Error("Failed to send hold release email",
+			zap.String("payment_id", paymentID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logger.Log.Info("Hold release email sent",
+		zap.String("payment_id", paymentID.String()),
+		zap.String("reason", reason),
+		zap.String("language", language),
+	)
+}
+
+// render uses the merchant's template for the language, or the built-in
+// email when there is none or it no longer renders
+func (s *HoldReleaseService) render(merchantID uuid.UUID, language string, email *HoldReleaseEmail) (string, []byte, error) {
+	custom, err := s.holdRepo.FindTemplate(merchantID, language)
+	if err != nil {
+		logger.Log.Warn("Failed to load hold release template, using the default", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+	}
+	if custom != nil {
+		subject, content, err := renderHoldReleaseTemplate(custom, email)
+		if err == nil {
+			body, err := renderHoldReleaseLayout(language, content)
+			return subject, body, err
+		}
+		logger.Log.Warn("Hold release template failed, using the default",
+			zap.String("merchant_id", merchantID.String()),
+			zap.String("language", language),
+			zap.Error(err),
+		)
+	}
+
+	data := map[string]interface{}{"Amount": email.Amount, "Date": email.AuthorizedAt, "Card": email.Card}
+	greeting := i18n.T(language, "email.receipt.greeting_anonymous", nil)
+	if email.CustomerName != "" {
+		greeting = i18n.T(language, "email.receipt.greeting", map[string]interface{}{"Name": email.CustomerName})
+	}
+
+	var content bytes.Buffer
+	if err := holdReleaseDefaultContent.Execute(&content, holdReleaseDefaultPage{
+		Greeting: greeting,
+		Title:    i18n.T(language, "email.hold_release.title", nil),
+		Message:  i18n.T(language, "email.hold_release."+email.Reason, data),
+		Timing:   i18n.T(language, "email.hold_release.timing", nil),
+	}); err != nil {
+		return "", nil, err
+	}
+
+	body, err := renderHoldReleaseLayout(language, template.HTML(content.String()))
+	return i18n.T(language, "email.hold_release.subject", data), body, err
+}
+
+func buildHoldReleaseEmail(payment *model.Payment, display *model.MerchantDisplaySettings, language, reason string) *HoldReleaseEmail {
+	email := &HoldReleaseEmail{
+		Amount:       display.FormatAmount(payment.Amount, payment.Currency),
+		PaymentID:    payment.ID.String(),
+		AuthorizedAt: payment.CreatedAt.In(display.Location()).Format("2006-01-02"),
+		Reason:       reason,
+	}
+	if payment.CustomerName.Valid {
+		email.CustomerName = payment.CustomerName.String
+	}
+	if payment.CardLast4 != "" {
+		email.Card = i18n.T(language, "receipt.card_value", map[string]interface{}{
+			"Brand": strings.ToUpper(payment.CardBrand),
+			"Last4": payment.CardLast4,
+		})
+	}
+	return email
+}
+
+func validateHoldReleaseLanguage(language string) error {
+	switch language {
+	case i18n.English, i18n.French, i18n.Arabic:
+		return nil
+	}
+	return fmt.Errorf("%w: language must be en, fr or ar", ErrInvalidHoldReleaseTemplate)
+}
+
+// renderHoldReleaseTemplate renders a merchant template. The body goes
+// through html/template so customer and payment values are escaped.
+func renderHoldReleaseTemplate(tmpl *model.HoldReleaseTemplate, email *HoldReleaseEmail) (string, template.HTML, error) {
+	subjectTmpl, err := texttemplate.New("subject").Parse(tmpl.Subject)
+	if err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	bodyTmpl, err := template.New("body").Parse(tmpl.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, email); err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	if err := bodyTmpl.Execute(&body, email); err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+	// Line breaks would let a template add mail headers
+	return strings.Join(strings.Fields(subject.String()), " "), template.HTML(body.String()), nil
+}
+
+func renderHoldReleaseLayout(language string, content template.HTML) ([]byte, error) {
+	var out bytes.Buffer
+	err := holdReleaseLayout.Execute(&out, holdReleaseLayoutPage{
+		Language:  language,
+		Direction: i18n.Direction(language),
+		Title:     i18n.T(language, "email.hold_release.title", nil),
+		Content:   content,
+		NoReply:   i18n.T(language, "email.receipt.no_reply", nil),
+	})
+	return out.Bytes(), err
+}
+
+type holdReleaseDefaultPage struct {
+	Greeting string
+	Title    string
+	Message  string
+	Timing   string
+}
+
+var holdReleaseDefaultContent = template.Must(template.New("hold_release_default").Parse(`<p>{{.Greeting}}</p>
+        <h1>{{.Title}}</h1>
+        <p>{{.Message}}</p>
+        <p>{{.Timing}}</p>`))
+
+type holdReleaseLayoutPage struct {
+	Language  string
+	Direction string
+	Title     string
+	Content   template.HTML
+	NoReply   string
+}
+
+var holdReleaseLayout = template.Must(template.New("hold_release").Funcs(template.FuncMap{
+	"year": func() int { return time.Now().Year() },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}" dir="{{.Direction}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: Arial, "Noto Naskh Arabic", sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        h1 { font-size: 22px; }
+        .footer { color: #6b7280; font-size: 14px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        {{.Content}}
+        <div class="footer">
+            <p>{{.NoReply}}</p>
+            <p>© {{year}} Payment Gateway Morocco</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
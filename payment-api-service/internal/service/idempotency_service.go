@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// idempotencyLockTimeout is how long a key stays locked by a request
+	// that never finished (a crashed pod) before a retry may run it again.
+	// It is well above the transaction service timeouts.
+	idempotencyLockTimeout   = 2 * time.Minute
+	idempotencyPurgeInterval = time.Hour
+)
+
+var ErrIdempotentRequestInProgress = errors.New("a request with this idempotency key is still being processed")
+
+// IdempotencyService makes captures, voids and refunds safe to retry. The
+// first request with an Idempotency-Key locks it in Postgres; when it
+// succeeds its response is stored there and cached in Redis, and every retry
+// within IDEMPOTENCY_WINDOW gets that response back without touching the
+// payment. A failed request releases the key so it can be retried.
+type IdempotencyService struct {
+	keyRepo *repository.IdempotencyKeyRepository
+	cache   *repository.IdempotencyRepository
+	window  time.Duration
+}
+
+func NewIdempotencyService() *IdempotencyService {
+	return &IdempotencyService{
+		keyRepo: repository.NewIdempotencyKeyRepository(),
+		cache:   repository.NewIdempotencyRepository(),
+		window:  config.GetDurationWithDefault("IDEMPOTENCY_WINDOW", 24*time.Hour),
+	}
+}
+
+// IdempotentRequest identifies a capture, void or refund. Params is the
+// request body; together with the operation and payment it makes up the
+// fingerprint a reused key must match.
+type IdempotentRequest struct {
+	MerchantID uuid.UUID
+	TestMode   bool
+	Key        string // Empty runs the operation without idempotency
+	Operation  string
+	PaymentID  uuid.UUID
+	Params     interface{}
+}
+
+// IdempotentResult is the response to send, either fresh or replayed
+type IdempotentResult struct {
+	StatusCode int
+	Data       json.RawMessage
+	Replayed   bool
+}
+
+// Do runs fn once per idempotency key. fn returns the HTTP status and the
+// response data; side effects such as webhooks belong inside it so a replay
+// does not repeat them.
+func (s *IdempotencyService) Do(ctx context.Context, req *IdempotentRequest, fn func() (int, interface{}, error)) (*IdempotentResult, error) {
+	if req.Key == "" {
+		return runIdempotentOperation(fn)
+	}
+
+	requestHash := operationRequestHash(req)
+
+	record, err := s.cache.GetOperation(req.MerchantID, req.Key, req.TestMode)
+	if err != nil {
+		logger.Log.Warn("Idempotency cache unavailable, falling back to database", zap.Error(err))
+	}
+	if record != nil {
+		if record.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		return &IdempotentResult{StatusCode: record.ResponseStatus, Data: record.Response, Replayed: true}, nil
+	}
+
+	key, replay, err := s.claim(ctx, req, requestHash)
+	if err != nil {
+		return nil, err
+	}
+	if replay != nil {
+		return replay, nil
+	}
+
+	result, err := runIdempotentOperation(fn)
+	if err != nil {
+		if releaseErr := s.keyRepo.Release(ctx, key); releaseErr != nil {
+			logger.Log.Error("Failed to release idempotency key",
+				zap.String("merchant_id", req.MerchantID.String()),
+				zap.String("operation", req.Operation),
+				zap.Error(releaseErr),
+			)
+		}
+		return nil, err
+	}
+
+	// The operation already happened, so storing its outcome must not fail
+	// the request; an unstored key is retried once its lock times out
+	if err := s.keyRepo.Complete(ctx, key, result.StatusCode, result.Data); err != nil {
+		logger.Log.Error("Failed to store idempotent response",
+			zap.String("merchant_id", req.MerchantID.String()),
+			zap.String("operation", req.Operation),
+			zap.String("payment_id", req.PaymentID.String()),
+			zap.Error(err),
+		)
+	}
+	s.cacheResult(req, requestHash, result)
+
+	return result, nil
+}
+
+// claim locks the key for this request. It returns the stored response
+// instead when the key already completed the same request.
+func (s *IdempotencyService) claim(ctx context.Context, req *IdempotentRequest, requestHash string) (*model.IdempotencyKey, *IdempotentResult, error) {
+	now := time.Now()
+	key := &model.IdempotencyKey{
+		MerchantID:  req.MerchantID,
+		TestMode:    req.TestMode,
+		Key:         req.Key,
+		Operation:   req.Operation,
+		PaymentID:   req.PaymentID,
+		RequestHash: requestHash,
+		Status:      model.IdempotencyKeyProcessing,
+		LockedUntil: now.Add(idempotencyLockTimeout),
+		ExpiresAt:   now.Add(s.window),
+	}
+
+	existing, err := s.keyRepo.Claim(ctx, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if existing == nil {
+		return key, nil, nil
+	}
+
+	stale := existing.ExpiresAt.Before(now) ||
+		(existing.Status == model.IdempotencyKeyProcessing && existing.LockedUntil.Before(now))
+	if !stale {
+		if existing.RequestHash != requestHash {
+			return nil, nil, ErrIdempotencyKeyReused
+		}
+		if existing.Status == model.IdempotencyKeyProcessing {
+			return nil, nil, ErrIdempotentRequestInProgress
+		}
+		result := &IdempotentResult{StatusCode: existing.ResponseStatus, Data: existing.ResponseBody, Replayed: true}
+		s.cacheResult(req, requestHash, result)
+		return nil, result, nil
+	}
+
+	taken, err := s.keyRepo.TakeOver(ctx, existing, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if !taken {
+		return nil, nil, ErrIdempotentRequestInProgress
+	}
+	return key, nil, nil
+}
+
+func (s *IdempotencyService) cacheResult(req *IdempotentRequest, requestHash string, result *IdempotentResult) {
+	record := &repository.IdempotencyRecord{
+		PaymentID:      req.PaymentID,
+		RequestHash:    requestHash,
+		ResponseStatus: result.StatusCode,
+		Response:       result.Data,
+	}
+	if err := s.cache.SetOperation(req.MerchantID, req.Key, req.TestMode, record, s.window); err != nil {
+		logger.Log.Warn("Failed to cache idempotent response",
+			zap.String("payment_id", req.PaymentID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// RunPurgeWorker deletes keys past the idempotency window every hour until
+// ctx is canceled
+func (s *IdempotencyService) RunPurgeWorker(ctx context.Context) error {
+	logger.Log.Info("Starting idempotency key purge worker")
+
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Idempotency key purge worker stopped")
+			return nil
+		case <-ticker.C:
+			deleted, err := s.keyRepo.DeleteExpired(time.Now())
+			if err != nil {
+				logger.Log.Error("Failed to purge idempotency keys", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.Log.Info("Purged expired idempotency keys", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+func runIdempotentOperation(fn func() (int, interface{}, error)) (*IdempotentResult, error) {
+	status, data, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return &IdempotentResult{StatusCode: status, Data: body}, nil
+}
+
+// operationRequestHash fingerprints the operation, the payment it targets
+// and the request body
+func operationRequestHash(req *IdempotentRequest) string {
+	fields, _ := json.Marshal(struct {
+		Operation string      `json:"operation"`
+		PaymentID uuid.UUID   `json:"payment_id"`
+		Params    interface{} `json:"params"`
+	}{
+		Operation: req.Operation,
+		PaymentID: req.PaymentID,
+		Params:    req.Params,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrIntentCardChurn = errors.New("too many different cards tried on this payment intent")
+
+// intentVelocityRules tunes per-intent velocity. Intents allow seven
+// attempts; a buyer retrying one card or fixing a typo stays well under
+// MaxDistinctCards, someone testing a list of cards does not.
+var intentVelocityRules = struct {
+	MaxDistinctCards int64
+	AttemptWindow    time.Duration
+	HistoryTTL       time.Duration
+}{
+	MaxDistinctCards: 3,
+	AttemptWindow:    time.Minute,
+	HistoryTTL:       24 * time.Hour, // Longer than any intent lives
+}
+
+// IntentVelocityTracker records each confirmation attempt against its
+// payment intent so fraud scoring can see card churn across attempts
+type IntentVelocityTracker struct {
+	repo *repository.IntentVelocityRepository
+}
+
+func NewIntentVelocityTracker() *IntentVelocityTracker {
+	return &IntentVelocityTracker{
+		repo: repository.NewIntentVelocityRepository(),
+	}
+}
+
+// Record adds the attempt and returns the intent's velocity features. A
+// Redis failure yields nil rather than blocking the payment.
+func (t *IntentVelocityTracker) Record(intentID uuid.UUID, fingerprint string) *client.IntentVelocity {
+	cards, attempts, err := t.repo.RecordAttempt(intentID, fingerprint,
+		intentVelocityRules.AttemptWindow, intentVelocityRules.HistoryTTL)
+	if err != nil {
+		logger.Log.Error("Failed to record intent velocity",
+			zap.String("intent_id", intentID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return &client.IntentVelocity{
+		PaymentIntentID:    intentID.String(),
+		DistinctCards:      int(cards),
+		AttemptsLastMinute: int(attempts),
+	}
+}
+
+// Exceeded reports whether the intent has churned through too many cards
+func (t *IntentVelocityTracker) Exceeded(v *client.IntentVelocity) bool {
+	return v != nil && int64(v.DistinctCards) > intentVelocityRules.MaxDistinctCards
+}
+
+// Clear forgets an intent's history
+func (t *IntentVelocityTracker) Clear(intentID uuid.UUID) {
+	if err := t.repo.Clear(intentID); err != nil {
+		logger.Log.Warn("Failed to clear intent velocity",
+			zap.String("intent_id", intentID.String()),
+			zap.Error(err),
+		)
+	}
+}
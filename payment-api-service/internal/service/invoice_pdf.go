@@ -0,0 +1,351 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 in points, with the margins the layout keeps clear
+const (
+	pdfPageWidth   = 595.28
+	pdfPageHeight  = 841.89
+	pdfMargin      = 50.0
+	pdfFooterSpace = 40.0 // below the content, for the page number
+)
+
+// Right edges of the invoice table columns
+const (
+	pdfQuantityRight = 380.0
+	pdfUnitRight     = 470.0
+	pdfAmountRight   = pdfPageWidth - pdfMargin
+	pdfDescription   = 290.0 // width the description column wraps to
+	pdfTotalsLeft    = 330.0
+)
+
+type pdfStyle struct {
+	size float64
+	bold bool
+	gray bool
+}
+
+var (
+	pdfTitleStyle    = pdfStyle{size: 20, bold: true}
+	pdfMerchantStyle = pdfStyle{size: 14, bold: true}
+	pdfBodyStyle     = pdfStyle{size: 10}
+	pdfBoldStyle     = pdfStyle{size: 10, bold: true}
+	pdfLabelStyle    = pdfStyle{size: 10, gray: true}
+	pdfSmallStyle    = pdfStyle{size: 8, gray: true}
+)
+
+// pdfWriter draws text and rules onto A4 pages and serializes them as a
+// PDF 1.4 file using the standard Helvetica fonts, which every viewer has,
+// so nothing needs embedding. Those fonts only cover Windows-1252 text.
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // baseline of the next line on the current page
+}
+
+func (w *pdfWriter) newPage() {
+	w.page = &bytes.Buffer{}
+	w.pages = append(w.pages, w.page)
+	w.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height fits above the footer, and
+// reports whether it did
+func (w *pdfWriter) ensure(height float64) bool {
+	if w.y-height >= pdfMargin+pdfFooterSpace {
+		return false
+	}
+	w.newPage()
+	return true
+}
+
+func (w *pdfWriter) text(x, y float64, style pdfStyle, s string) {
+	font := "F1"
+	if style.bold {
+		font = "F2"
+	}
+	gray := 0.0
+	if style.gray {
+		gray = 0.42
+	}
+	fmt.Fprintf(w.page, "BT %.2f g /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", gray, font, style.size, x, y, pdfEscape(s))
+}
+
+func (w *pdfWriter) textRight(right, y float64, style pdfStyle, s string) {
+	w.text(right-pdfTextWidth(s, style.size), y, style, s)
+}
+
+// rule draws a thin gray line across the page at y
+func (w *pdfWriter) rule(y float64) {
+	fmt.Fprintf(w.page, "0.85 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, y, pdfPageWidth-pdfMargin, y)
+}
+
+// bytes assembles the document: catalog, page tree, the two fonts, an info
+// dictionary, then each page and its content stream
+func (w *pdfWriter) bytes(title string) []byte {
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	count := 5 + 2*len(w.pages)
+	offsets := make([]int, count+1)
+	object := func(id int, body string) {
+		offsets[id] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", id, body)
+	}
+
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	object(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object(4, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(5, fmt.Sprintf("<< /Title (%s) /Producer (Payment Gateway Morocco) >>", pdfEscape(title)))
+	for i, page := range w.pages {
+		object(6+2*i, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 7+2*i))
+		object(7+2*i, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", count+1)
+	for id := 1; id <= count; id++ {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offsets[id])
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", count+1, xref)
+	return out.Bytes()
+}
+
+// renderInvoicePDF lays the invoice out on as many pages as its lines need:
+// merchant and title, details and customer, the line table (its heading is
+// repeated on each page), totals, then memo and pay link
+func renderInvoicePDF(doc *InvoiceDocument) ([]byte, error) {
+	w := &pdfWriter{}
+	w.newPage()
+	messages := doc.Messages
+
+	if doc.Merchant != "" {
+		w.text(pdfMargin, w.y, pdfMerchantStyle, doc.Merchant)
+	}
+	w.textRight(pdfAmountRight, w.y, pdfTitleStyle, doc.Title)
+	w.y -= 36
+
+	// Customer on the left, invoice details on the right
+	left, right := w.y, w.y
+	if len(doc.BillTo) > 0 {
+		w.text(pdfMargin, left, pdfLabelStyle, messages["bill_to"])
+		left -= 14
+		for _, line := range doc.BillTo {
+			w.text(pdfMargin, left, pdfBodyStyle, pdfTruncate(line, pdfTotalsLeft-pdfMargin-10, pdfBodyStyle.size))
+			left -= 14
+		}
+	}
+	for _, line := range doc.Details {
+		w.text(pdfTotalsLeft, right, pdfLabelStyle, line.Label)
+		w.textRight(pdfAmountRight, right, pdfBodyStyle, line.Value)
+		right -= 14
+	}
+	w.y = min(left, right) - 20
+
+	if doc.Notice != "" {
+		for _, line := range pdfWrap(doc.Notice, pdfAmountRight-pdfMargin, pdfBoldStyle.size) {
+			w.text(pdfMargin, w.y, pdfBoldStyle, line)
+			w.y -= 14
+		}
+		w.y -= 10
+	}
+
+	heading := func() {
+		w.text(pdfMargin, w.y, pdfLabelStyle, messages["description"])
+		w.textRight(pdfQuantityRight, w.y, pdfLabelStyle, messages["quantity"])
+		w.textRight(pdfUnitRight, w.y, pdfLabelStyle, messages["unit_price"])
+		w.textRight(pdfAmountRight, w.y, pdfLabelStyle, messages["amount"])
+		w.rule(w.y - 6)
+		w.y -= 22
+	}
+	heading()
+
+	for _, item := range doc.Items {
+		description := pdfWrap(item.Description, pdfDescription, pdfBodyStyle.size)
+		height := float64(len(description))*14 + 8
+		if item.Tax != "" {
+			height += 11
+		}
+		if w.ensure(height) {
+			heading()
+		}
+
+		w.textRight(pdfQuantityRight, w.y, pdfBodyStyle, strconv.FormatInt(item.Quantity, 10))
+		w.textRight(pdfUnitRight, w.y, pdfBodyStyle, item.UnitAmount)
+		w.textRight(pdfAmountRight, w.y, pdfBodyStyle, item.Amount)
+		for _, line := range description {
+			w.text(pdfMargin, w.y, pdfBodyStyle, line)
+			w.y -= 14
+		}
+		if item.Tax != "" {
+			w.text(pdfMargin, w.y+3, pdfSmallStyle, item.Tax)
+			w.y -= 11
+		}
+		w.rule(w.y + 6)
+		w.y -= 8
+	}
+
+	w.ensure(float64(len(doc.Totals))*16 + 10)
+	w.y -= 6
+	for i, line := range doc.Totals {
+		style := pdfBodyStyle
+		if i == len(doc.Totals)-1 {
+			style = pdfBoldStyle
+		}
+		w.text(pdfTotalsLeft, w.y, pdfLabelStyle, line.Label)
+		w.textRight(pdfAmountRight, w.y, style, line.Value)
+		w.y -= 16
+	}
+	w.y -= 14
+
+	if doc.Memo != "" {
+		for _, paragraph := range strings.Split(doc.Memo, "\n") {
+			for _, line := range pdfWrap(paragraph, pdfAmountRight-pdfMargin, pdfBodyStyle.size) {
+				w.ensure(14)
+				w.text(pdfMargin, w.y, pdfBodyStyle, line)
+				w.y -= 14
+			}
+		}
+		w.y -= 14
+	}
+
+	if doc.PayURL != "" {
+		w.ensure(28)
+		w.text(pdfMargin, w.y, pdfBoldStyle, messages["pay_online"])
+		w.y -= 14
+		for _, line := range pdfWrap(doc.PayURL, pdfAmountRight-pdfMargin, pdfBodyStyle.size) {
+			w.ensure(14)
+			w.text(pdfMargin, w.y, pdfBodyStyle, line)
+			w.y -= 14
+		}
+		w.y -= 14
+	}
+
+	w.ensure(14)
+	w.text(pdfMargin, w.y, pdfLabelStyle, doc.Footer)
+
+	// Page numbers, now that the count is known
+	for i, page := range w.pages {
+		w.page = page
+		number := i18n.T(doc.Language, "invoice.page", map[string]interface{}{"Page": i + 1, "Pages": len(w.pages)})
+		if doc.Number != "" {
+			number = doc.Number + " - " + number
+		}
+		w.textRight(pdfAmountRight, pdfMargin, pdfSmallStyle, number)
+	}
+
+	title := doc.Title
+	if doc.Number != "" {
+		title += " " + doc.Number
+	}
+	return w.bytes(title), nil
+}
+
+// pdfEscape converts s to Windows-1252 for the fonts' WinAnsiEncoding,
+// replacing what it cannot show with "?", and escapes it for a PDF string
+func pdfEscape(s string) string {
+	var out strings.Builder
+	for _, r := range s {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		switch b {
+		case '(', ')', '\\':
+			out.WriteByte('\\')
+		case '\n', '\r', '\t':
+			b = ' '
+		}
+		out.WriteByte(b)
+	}
+	return out.String()
+}
+
+// helveticaWidths are Helvetica's advance widths for the printable ASCII
+// characters, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// pdfTextWidth measures s in points. Bold and accented text is measured
+// with the regular widths, which is close enough to right-align amounts
+// and wrap lines with some room to spare.
+func pdfTextWidth(s string, size float64) float64 {
+	width := 0
+	for _, r := range s {
+		if r >= ' ' && r <= '~' {
+			width += helveticaWidths[r-' ']
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// pdfWrap breaks s into lines no wider than width, between words where it
+// can and inside words that are too long on their own
+func pdfWrap(s string, width, size float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if pdfTextWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for pdfTextWidth(word, size) > width {
+			cut := len(word) - 1
+			for cut > 1 && pdfTextWidth(word[:cut], size) > width {
+				cut--
+			}
+			for cut > 1 && !utf8.RuneStart(word[cut]) {
+				cut--
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfTruncate shortens s to fit width, ending it with "..."
+func pdfTruncate(s string, width, size float64) string {
+	if pdfTextWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdfTextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,740 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidInvoice = errors.New("invalid invoice")
+	// ErrInvoiceStatus is returned for a change the invoice's status does
+	// not allow, e.g. editing an open invoice or paying a void one
+	ErrInvoiceStatus = errors.New("invoice status does not allow this")
+	// ErrInvoicePaymentPending is returned by the hosted link while a
+	// payment for the invoice is already being processed
+	ErrInvoicePaymentPending = errors.New("a payment for this invoice is already being processed")
+	ErrInvoiceEmailDisabled  = errors.New("email delivery is not configured")
+)
+
+const (
+	maxInvoiceLineItems   = 100
+	defaultInvoiceDueDays = 30
+	maxInvoiceDueDays     = 365
+)
+
+// InvoiceService manages invoices from draft to payment. A finalized
+// invoice gets the merchant's next number and a hosted link; the link opens
+// the hosted checkout with a payment intent for the amount due, and the
+// invoice is marked paid when that intent's payment is captured.
+type InvoiceService struct {
+	invoiceRepo     *repository.InvoiceRepository
+	intentRepo      *repository.PaymentIntentRepository
+	intentService   *PaymentIntentService
+	displaySettings *DisplaySettingsService
+	mailer          *mailer
+	publicURL       string // where customers reach /api/public, for links in emails and PDFs
+}
+
+func NewInvoiceService(intentService *PaymentIntentService) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo:     repository.NewInvoiceRepository(),
+		intentRepo:      repository.NewPaymentIntentRepository(),
+		intentService:   intentService,
+		displaySettings: NewDisplaySettingsService(),
+		mailer:          newMailer(),
+		publicURL:       strings.TrimRight(config.GetEnv("PUBLIC_API_URL"), "/"),
+	}
+}
+
+// InvoiceInput is the content of a draft. DueDate wins over DaysUntilDue;
+// with neither the invoice is due in 30 days.
+type InvoiceInput struct {
+	MerchantID    uuid.UUID
+	TestMode      bool
+	Currency      string
+	CustomerEmail string
+	CustomerName  string
+	DueDate       time.Time
+	DaysUntilDue  int
+	LineItems     []InvoiceLineItemInput
+	Memo          string
+	Language      string // already validated; empty uses the merchant's locale
+	SuccessURL    string
+}
+
+type InvoiceLineItemInput struct {
+	Description string
+	Quantity    int64
+	UnitAmount  int64
+	TaxRate     *CheckoutTaxRateInput
+}
+
+// InvoiceResponse is an invoice with the customer's links, set once it is
+// finalized
+type InvoiceResponse struct {
+	*model.Invoice
+	HostedInvoiceURL string `json:"hosted_invoice_url,omitempty"` // opens checkout for the amount due
+	InvoicePDFURL    string `json:"invoice_pdf_url,omitempty"`
+}
+
+// InvoiceDocument is an invoice as the customer sees it, translated and
+// with formatted amounts. The hosted page, the PDF and the email all show
+// it.
+type InvoiceDocument struct {
+	Number     string              `json:"number"`
+	Status     model.InvoiceStatus `json:"status"`
+	Currency   string              `json:"currency"`
+	AmountDue  int64               `json:"amount_due"`
+	Payable    bool                `json:"payable"`
+	Language   string              `json:"language"`
+	Direction  string              `json:"direction"`
+	Title      string              `json:"title"`
+	Merchant   string              `json:"merchant,omitempty"`
+	LogoURL    string              `json:"logo_url,omitempty"`
+	BrandColor string              `json:"brand_color,omitempty"`
+	Details    []ReceiptLine       `json:"details"` // number, dates and status
+	BillTo     []string            `json:"bill_to,omitempty"`
+	Items      []InvoiceItemLine   `json:"line_items"`
+	Totals     []ReceiptLine       `json:"totals"`
+	Memo       string              `json:"memo,omitempty"`
+	Notice     string              `json:"notice,omitempty"`
+	Footer     string              `json:"footer"`
+	PayURL     string              `json:"pay_url,omitempty"`
+	PDFURL     string              `json:"pdf_url"`
+
+	// Column headings and labels in the document's language
+	Messages map[string]string `json:"messages"`
+
+	amountDue string // formatted, for the email subject
+	dueDate   string
+}
+
+type InvoiceItemLine struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitAmount  string `json:"unit_amount"`
+	Amount      string `json:"amount"`
+	Tax         string `json:"tax,omitempty"` // e.g. "TVA 20%"
+}
+
+// CreateInvoice prices the lines and stores the invoice as a draft
+func (s *InvoiceService) CreateInvoice(ctx context.Context, input *InvoiceInput) (*InvoiceResponse, error) {
+	invoice, err := s.priceInvoice(input)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Status = model.InvoiceStatusDraft
+
+	if err := s.invoiceRepo.WithContext(ctx).Create(invoice); err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	logger.Log.Info("Invoice created",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.String("merchant_id", invoice.MerchantID.String()),
+		zap.Int64("amount_total", invoice.AmountTotal),
+	)
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// UpdateInvoice replaces the content of a draft
+func (s *InvoiceService) UpdateInvoice(ctx context.Context, invoiceID uuid.UUID, input *InvoiceInput) (*InvoiceResponse, error) {
+	invoiceRepo := s.invoiceRepo.WithContext(ctx)
+	current, err := invoiceRepo.FindByIDAndMerchant(invoiceID, input.MerchantID, input.TestMode)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != model.InvoiceStatusDraft {
+		return nil, fmt.Errorf("%w: only drafts can be edited, this invoice is %s", ErrInvoiceStatus, current.Status)
+	}
+
+	invoice, err := s.priceInvoice(input)
+	if err != nil {
+		return nil, err
+	}
+	invoice.ID = current.ID
+	invoice.Status = current.Status
+	invoice.CreatedAt = current.CreatedAt
+
+	if err := invoiceRepo.UpdateDraft(invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Finalized since we loaded it
+			return nil, fmt.Errorf("%w: only drafts can be edited", ErrInvoiceStatus)
+		}
+		return nil, fmt.Errorf("failed to update invoice: %w", err)
+	}
+	return s.GetInvoice(ctx, invoiceID, input.MerchantID, input.TestMode)
+}
+
+// DeleteInvoice deletes a draft. Finalized invoices keep their number and
+// can only be voided.
+func (s *InvoiceService) DeleteInvoice(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) error {
+	invoiceRepo := s.invoiceRepo.WithContext(ctx)
+	invoice, err := invoiceRepo.FindByIDAndMerchant(invoiceID, merchantID, testMode)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != model.InvoiceStatusDraft {
+		return fmt.Errorf("%w: only drafts can be deleted, void the invoice instead", ErrInvoiceStatus)
+	}
+	if err := invoiceRepo.DeleteDraft(invoiceID, merchantID, testMode); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: only drafts can be deleted, void the invoice instead", ErrInvoiceStatus)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetInvoice returns one of the merchant's invoices with its lines
+func (s *InvoiceService) GetInvoice(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) (*InvoiceResponse, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).FindByIDAndMerchant(invoiceID, merchantID, testMode)
+	if err != nil {
+		return nil, err
+	}
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// ListInvoices returns the merchant's invoices, newest first, optionally of
+// one status
+func (s *InvoiceService) ListInvoices(ctx context.Context, merchantID uuid.UUID, testMode bool, status model.InvoiceStatus, limit, offset int) ([]InvoiceResponse, error) {
+	invoices, err := s.invoiceRepo.WithContext(ctx).ListByMerchant(merchantID, testMode, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]InvoiceResponse, 0, len(invoices))
+	for i := range invoices {
+		responses = append(responses, *s.newInvoiceResponse(&invoices[i]))
+	}
+	return responses, nil
+}
+
+// FinalizeInvoice opens a draft: it takes the merchant's next invoice
+// number and gets a hosted link. The lines can no longer change.
+func (s *InvoiceService) FinalizeInvoice(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) (*InvoiceResponse, error) {
+	invoiceRepo := s.invoiceRepo.WithContext(ctx)
+	today := s.today(merchantID)
+
+	invoice, err := invoiceRepo.Transition(invoiceID, merchantID, testMode, func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error) {
+		if invoice.Status != model.InvoiceStatusDraft {
+			return nil, fmt.Errorf("%w: only drafts can be finalized, this invoice is %s", ErrInvoiceStatus, invoice.Status)
+		}
+		if invoice.DueDate.Before(today) {
+			return nil, fmt.Errorf("%w: due_date is in the past, update the draft first", ErrInvalidInvoice)
+		}
+
+		number, err := invoiceRepo.NextNumber(tx, merchantID, testMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to number invoice: %w", err)
+		}
+		token, err := generateInvoiceToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate hosted link: %w", err)
+		}
+		return map[string]interface{}{
+			"status":       model.InvoiceStatusOpen,
+			"number":       fmt.Sprintf("INV-%06d", number),
+			"hosted_token": token,
+			"finalized_at": time.Now(),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Invoice finalized",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.String("number", invoice.Number.String),
+	)
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// SendInvoice emails an open invoice to its customer with the PDF attached
+// and a link to pay it
+func (s *InvoiceService) SendInvoice(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) (*InvoiceResponse, error) {
+	if !s.mailer.enabled() {
+		return nil, ErrInvoiceEmailDisabled
+	}
+	invoiceRepo := s.invoiceRepo.WithContext(ctx)
+	invoice, err := invoiceRepo.FindByIDAndMerchant(invoiceID, merchantID, testMode)
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.IsPayable() {
+		return nil, fmt.Errorf("%w: only open invoices can be sent, this invoice is %s", ErrInvoiceStatus, invoice.Status)
+	}
+	if !invoice.CustomerEmail.Valid || invoice.CustomerEmail.String == "" {
+		return nil, fmt.Errorf("%w: the invoice has no customer email", ErrInvalidInvoice)
+	}
+
+	settings := s.displaySettings.Resolve(merchantID)
+	language := i18n.Match(invoice.Language, settings.Locale)
+	doc := s.buildDocument(invoice, settings, language)
+	pdf, err := s.renderPDF(invoice, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render invoice PDF: %w", err)
+	}
+
+	receipt := &Receipt{
+		Language:   doc.Language,
+		Direction:  doc.Direction,
+		Title:      doc.Title + " " + doc.Number,
+		Lines:      append(append([]ReceiptLine{}, doc.Details...), doc.Totals...),
+		Notice:     doc.Notice,
+		Footer:     doc.Footer,
+		Merchant:   doc.Merchant,
+		LogoURL:    doc.LogoURL,
+		BrandColor: doc.BrandColor,
+	}
+	for _, item := range doc.Items {
+		receipt.Items = append(receipt.Items, ReceiptItem{
+			Name:     item.Description,
+			Quantity: item.Quantity,
+			Amount:   item.Amount,
+			Tax:      item.Tax,
+		})
+	}
+	page := receiptPage{
+		Receipt:     receipt,
+		Greeting:    i18n.T(language, "email.receipt.greeting_anonymous", nil),
+		Intro:       i18n.T(language, "email.invoice.intro", nil),
+		NoReply:     i18n.T(language, "email.receipt.no_reply", nil),
+		ActionURL:   doc.PayURL,
+		ActionLabel: i18n.T(language, "invoice.pay_online", nil),
+	}
+	if invoice.CustomerName.Valid && invoice.CustomerName.String != "" {
+		page.Greeting = i18n.T(language, "email.receipt.greeting", map[string]interface{}{"Name": invoice.CustomerName.String})
+	}
+
+	var body bytes.Buffer
+	if err := receiptTemplate.Execute(&body, page); err != nil {
+		return nil, fmt.Errorf("failed to render invoice email: %w", err)
+	}
+	subject := i18n.T(language, "email.invoice.subject", map[string]interface{}{
+		"Number":  doc.Number,
+		"Amount":  doc.amountDue,
+		"DueDate": doc.dueDate,
+	})
+	if err := s.mailer.sendWithAttachments(invoice.CustomerEmail.String, subject, body.Bytes(), mailAttachment{
+		Filename:    doc.Number + ".pdf",
+		ContentType: "application/pdf",
+		Data:        pdf,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to send invoice email: %w", err)
+	}
+
+	invoice, err = invoiceRepo.Transition(invoiceID, merchantID, testMode, func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error) {
+		return map[string]interface{}{"sent_at": time.Now()}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Invoice sent",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.String("language", language),
+	)
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// VoidInvoice cancels an open or uncollectible invoice. Its hosted link
+// stops collecting payment.
+func (s *InvoiceService) VoidInvoice(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) (*InvoiceResponse, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).Transition(invoiceID, merchantID, testMode, func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error) {
+		if !invoice.IsPayable() {
+			return nil, fmt.Errorf("%w: only open or uncollectible invoices can be voided, this invoice is %s", ErrInvoiceStatus, invoice.Status)
+		}
+		return map[string]interface{}{
+			"status":    model.InvoiceStatusVoid,
+			"voided_at": time.Now(),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Close the checkout the hosted link may have open
+	if invoice.PaymentIntentID.Valid {
+		intentRepo := s.intentRepo.WithContext(tenancy.WithMerchant(ctx, merchantID))
+		intentID, _ := uuid.Parse(invoice.PaymentIntentID.String)
+		intent, err := intentRepo.FindByIDAndMerchant(intentID, merchantID)
+		if err == nil && intent.Status == model.PaymentIntentStatusAwaitingPayment {
+			err = intentRepo.MarkCanceled(intentID)
+		}
+		if err != nil {
+			logger.Log.Error("Failed to cancel the payment intent of a voided invoice",
+				zap.String("invoice_id", invoice.ID.String()),
+				zap.String("intent_id", intentID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	logger.Log.Info("Invoice voided", zap.String("invoice_id", invoice.ID.String()))
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// MarkUncollectible writes off an open invoice. The customer can still pay
+// it through the hosted link.
+func (s *InvoiceService) MarkUncollectible(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) (*InvoiceResponse, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).Transition(invoiceID, merchantID, testMode, func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error) {
+		if invoice.Status != model.InvoiceStatusOpen {
+			return nil, fmt.Errorf("%w: only open invoices can be marked uncollectible, this invoice is %s", ErrInvoiceStatus, invoice.Status)
+		}
+		return map[string]interface{}{
+			"status":                  model.InvoiceStatusUncollectible,
+			"marked_uncollectible_at": time.Now(),
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.newInvoiceResponse(invoice), nil
+}
+
+// RenderInvoicePDF renders one of the merchant's invoices as a PDF and
+// returns it with its file name
+func (s *InvoiceService) RenderInvoicePDF(ctx context.Context, invoiceID, merchantID uuid.UUID, testMode bool) ([]byte, string, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).FindByIDAndMerchant(invoiceID, merchantID, testMode)
+	if err != nil {
+		return nil, "", err
+	}
+	pdf, err := s.renderPDF(invoice, s.displaySettings.Resolve(merchantID))
+	if err != nil {
+		return nil, "", err
+	}
+	return pdf, invoiceFilename(invoice), nil
+}
+
+// =========================================================================
+// Hosted link
+// =========================================================================
+
+// GetHostedInvoice returns the customer's view of a finalized invoice.
+// language overrides the invoice's own language, then come acceptLanguage
+// and the merchant's locale.
+func (s *InvoiceService) GetHostedInvoice(ctx context.Context, token, language, acceptLanguage string) (*InvoiceDocument, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).FindByHostedToken(token)
+	if err != nil {
+		return nil, err
+	}
+	settings := s.displaySettings.Resolve(invoice.MerchantID)
+	return s.buildDocument(invoice, settings, i18n.Match(language, invoice.Language, acceptLanguage, settings.Locale)), nil
+}
+
+// RenderHostedInvoicePDF renders the invoice behind a hosted link as a PDF
+// and returns it with its file name
+func (s *InvoiceService) RenderHostedInvoicePDF(ctx context.Context, token string) ([]byte, string, error) {
+	invoice, err := s.invoiceRepo.WithContext(ctx).FindByHostedToken(token)
+	if err != nil {
+		return nil, "", err
+	}
+	pdf, err := s.renderPDF(invoice, s.displaySettings.Resolve(invoice.MerchantID))
+	if err != nil {
+		return nil, "", err
+	}
+	return pdf, invoiceFilename(invoice), nil
+}
+
+// PayHostedInvoice returns the hosted checkout URL that collects the
+// invoice. The intent opened by an earlier visit is reused until it
+// expires or fails; a new one is created after that.
+func (s *InvoiceService) PayHostedInvoice(ctx context.Context, token string) (string, error) {
+	found, err := s.invoiceRepo.WithContext(ctx).FindByHostedToken(token)
+	if err != nil {
+		return "", err
+	}
+	// The token identifies the merchant; scope the rest to it
+	ctx = tenancy.WithMerchant(ctx, found.MerchantID)
+	intentRepo := s.intentRepo.WithContext(ctx)
+
+	var checkoutURL string
+	_, err = s.invoiceRepo.WithContext(ctx).Transition(found.ID, found.MerchantID, found.TestMode, func(tx *gorm.DB, invoice *model.Invoice) (map[string]interface{}, error) {
+		if !invoice.IsPayable() {
+			return nil, fmt.Errorf("%w: this invoice is %s", ErrInvoiceStatus, invoice.Status)
+		}
+
+		if invoice.PaymentIntentID.Valid {
+			intentID, _ := uuid.Parse(invoice.PaymentIntentID.String)
+			current, err := intentRepo.FindByIDAndMerchant(intentID, invoice.MerchantID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if current != nil {
+				switch {
+				case current.Status == model.PaymentIntentStatusAwaitingPayment && !current.IsExpired():
+					checkoutURL = newPaymentIntentResponse(current).CheckoutURL
+					return nil, nil
+				case current.Status == model.PaymentIntentStatusAuthorized || current.Status == model.PaymentIntentStatusCaptured:
+					// Paid, or held for review; a second intent could charge twice
+					return nil, ErrInvoicePaymentPending
+				}
+			}
+		}
+
+		successURL := invoice.SuccessURL
+		if successURL == "" {
+			successURL = s.hostedURL(invoice, "")
+		}
+		email := ""
+		if invoice.CustomerEmail.Valid {
+			email = invoice.CustomerEmail.String
+		}
+		intent, err := s.intentService.newPaymentIntent(&CreatePaymentIntentRequest{
+			MerchantID:    invoice.MerchantID,
+			Amount:        invoice.AmountTotal,
+			Currency:      invoice.Currency,
+			OrderID:       invoice.Number.String,
+			Description:   "Invoice " + invoice.Number.String,
+			CaptureMethod: model.CaptureMethodAutomatic,
+			SuccessURL:    successURL,
+			CustomerEmail: email,
+			Metadata:      map[string]interface{}{"invoice_id": invoice.ID.String()},
+			TestMode:      invoice.TestMode,
+			Language:      invoice.Language,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(intent).Error; err != nil {
+			return nil, fmt.Errorf("failed to create payment intent: %w", err)
+		}
+
+		logger.Log.Info("Invoice payment intent created",
+			zap.String("invoice_id", invoice.ID.String()),
+			zap.String("intent_id", intent.ID.String()),
+		)
+		checkoutURL = newPaymentIntentResponse(intent).CheckoutURL
+		return map[string]interface{}{"payment_intent_id": intent.ID.String()}, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return checkoutURL, nil
+}
+
+// =========================================================================
+// Helpers
+// =========================================================================
+
+// priceInvoice validates a draft's content and computes its totals. Tax is
+// rounded half up per line, as for checkout sessions.
+func (s *InvoiceService) priceInvoice(input *InvoiceInput) (*model.Invoice, error) {
+	if input.Currency != "USD" && input.Currency != "EUR" && input.Currency != "MAD" {
+		return nil, fmt.Errorf("%w: unsupported currency", ErrInvalidInvoice)
+	}
+	if len(input.LineItems) == 0 {
+		return nil, fmt.Errorf("%w: at least one line item is required", ErrInvalidInvoice)
+	}
+	if len(input.LineItems) > maxInvoiceLineItems {
+		return nil, fmt.Errorf("%w: at most %d line items", ErrInvalidInvoice, maxInvoiceLineItems)
+	}
+
+	today := s.today(input.MerchantID)
+	dueDate := input.DueDate
+	if dueDate.IsZero() {
+		days := input.DaysUntilDue
+		if days == 0 {
+			days = defaultInvoiceDueDays
+		}
+		if days < 0 || days > maxInvoiceDueDays {
+			return nil, fmt.Errorf("%w: days_until_due must be from 0 to %d", ErrInvalidInvoice, maxInvoiceDueDays)
+		}
+		dueDate = today.AddDate(0, 0, days)
+	}
+	if dueDate.Before(today) {
+		return nil, fmt.Errorf("%w: due_date is in the past", ErrInvalidInvoice)
+	}
+	if dueDate.After(today.AddDate(0, 0, maxInvoiceDueDays)) {
+		return nil, fmt.Errorf("%w: due_date must be within %d days", ErrInvalidInvoice, maxInvoiceDueDays)
+	}
+
+	invoice := &model.Invoice{
+		MerchantID: input.MerchantID,
+		TestMode:   input.TestMode,
+		Currency:   input.Currency,
+		Language:   input.Language,
+		Memo:       input.Memo,
+		SuccessURL: input.SuccessURL,
+		DueDate:    dueDate,
+	}
+	if input.CustomerEmail != "" {
+		invoice.CustomerEmail = sql.NullString{String: input.CustomerEmail, Valid: true}
+	}
+	if input.CustomerName != "" {
+		invoice.CustomerName = sql.NullString{String: input.CustomerName, Valid: true}
+	}
+
+	for i, line := range input.LineItems {
+		if line.Description == "" || line.Quantity <= 0 || line.UnitAmount < 0 {
+			return nil, fmt.Errorf("%w: line_items[%d] needs a description, a positive quantity and a unit_amount of at least 0", ErrInvalidInvoice, i)
+		}
+		item := model.InvoiceLineItem{
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitAmount:  line.UnitAmount,
+			Amount:      line.Quantity * line.UnitAmount,
+		}
+		if line.TaxRate != nil {
+			bps, ok := percentageToBps(line.TaxRate.Percentage)
+			if !ok || line.TaxRate.Name == "" {
+				return nil, fmt.Errorf("%w: line_items[%d].tax_rate needs a name and a percentage from 0 to 100 with at most two decimals", ErrInvalidInvoice, i)
+			}
+			item.TaxName = line.TaxRate.Name
+			item.TaxRateBps = bps
+			item.TaxAmount = (item.Amount*int64(bps) + 5000) / 10000
+		}
+		invoice.Subtotal += item.Amount
+		invoice.TaxAmount += item.TaxAmount
+		invoice.LineItems = append(invoice.LineItems, item)
+	}
+
+	invoice.AmountTotal = invoice.Subtotal + invoice.TaxAmount
+	if invoice.AmountTotal <= 0 {
+		return nil, fmt.Errorf("%w: the total must be positive", ErrInvalidInvoice)
+	}
+	return invoice, nil
+}
+
+// today is the merchant's current date, as a due date is stored
+func (s *InvoiceService) today(merchantID uuid.UUID) time.Time {
+	now := time.Now().In(s.displaySettings.Resolve(merchantID).Location())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (s *InvoiceService) newInvoiceResponse(invoice *model.Invoice) *InvoiceResponse {
+	response := &InvoiceResponse{Invoice: invoice}
+	if invoice.HostedToken.Valid {
+		response.HostedInvoiceURL = s.hostedURL(invoice, "/pay")
+		response.InvoicePDFURL = s.hostedURL(invoice, "/pdf")
+	}
+	return response
+}
+
+// hostedURL is a link under the invoice's public page. Without
+// PUBLIC_API_URL it is relative to the gateway.
+func (s *InvoiceService) hostedURL(invoice *model.Invoice, suffix string) string {
+	return s.publicURL + "/api/public/invoices/" + invoice.HostedToken.String + suffix
+}
+
+// buildDocument lays out the invoice for the customer in language
+func (s *InvoiceService) buildDocument(invoice *model.Invoice, settings *model.MerchantDisplaySettings, language string) *InvoiceDocument {
+	line := func(id, value string) ReceiptLine {
+		return ReceiptLine{Label: i18n.T(language, id, nil), Value: value}
+	}
+	issued := invoice.CreatedAt
+	if invoice.FinalizedAt.Valid {
+		issued = invoice.FinalizedAt.Time
+	}
+
+	doc := &InvoiceDocument{
+		Number:     invoice.Number.String,
+		Status:     invoice.Status,
+		Currency:   invoice.Currency,
+		Payable:    invoice.IsPayable(),
+		Language:   language,
+		Direction:  i18n.Direction(language),
+		Title:      i18n.T(language, "invoice.title", nil),
+		Merchant:   settings.BusinessName,
+		LogoURL:    settings.LogoURL,
+		BrandColor: settings.BrandColor,
+		Memo:       invoice.Memo,
+		Footer:     i18n.T(language, "invoice.footer", nil),
+		Messages:   i18n.Messages(language, "invoice."),
+		dueDate:    invoice.DueDate.Format("2006-01-02"),
+	}
+	if invoice.HostedToken.Valid {
+		doc.PDFURL = s.hostedURL(invoice, "/pdf")
+		if doc.Payable {
+			doc.PayURL = s.hostedURL(invoice, "/pay")
+		}
+	}
+
+	if doc.Number != "" {
+		doc.Details = append(doc.Details, line("invoice.number", doc.Number))
+	}
+	doc.Details = append(doc.Details,
+		line("invoice.issue_date", issued.In(settings.Location()).Format("2006-01-02")),
+		line("invoice.due_date", doc.dueDate),
+		line("invoice.status", i18n.T(language, "invoice.status."+string(invoice.Status), nil)),
+	)
+
+	if invoice.CustomerName.Valid && invoice.CustomerName.String != "" {
+		doc.BillTo = append(doc.BillTo, invoice.CustomerName.String)
+	}
+	if invoice.CustomerEmail.Valid && invoice.CustomerEmail.String != "" {
+		doc.BillTo = append(doc.BillTo, invoice.CustomerEmail.String)
+	}
+
+	for _, item := range invoice.LineItems {
+		itemLine := InvoiceItemLine{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitAmount:  settings.FormatAmount(item.UnitAmount, invoice.Currency),
+			Amount:      settings.FormatAmount(item.Amount, invoice.Currency),
+		}
+		if item.TaxName != "" {
+			itemLine.Tax = fmt.Sprintf("%s %s%%", item.TaxName, strconv.FormatFloat(float64(item.TaxRateBps)/100, 'f', -1, 64))
+		}
+		doc.Items = append(doc.Items, itemLine)
+	}
+
+	doc.Totals = append(doc.Totals, line("invoice.subtotal", settings.FormatAmount(invoice.Subtotal, invoice.Currency)))
+	if invoice.TaxAmount > 0 {
+		doc.Totals = append(doc.Totals, line("invoice.tax", settings.FormatAmount(invoice.TaxAmount, invoice.Currency)))
+	}
+	doc.Totals = append(doc.Totals, line("invoice.total", settings.FormatAmount(invoice.AmountTotal, invoice.Currency)))
+	if doc.Payable || invoice.Status == model.InvoiceStatusDraft {
+		doc.AmountDue = invoice.AmountTotal
+	}
+	doc.amountDue = settings.FormatAmount(doc.AmountDue, invoice.Currency)
+	doc.Totals = append(doc.Totals, line("invoice.amount_due", doc.amountDue))
+
+	if invoice.TestMode {
+		doc.Notice = i18n.T(language, "invoice.test_mode", nil)
+	}
+	return doc
+}
+
+// renderPDF renders the invoice in its language. The PDF's built-in fonts
+// have no Arabic glyphs, so Arabic invoices are printed in French.
+func (s *InvoiceService) renderPDF(invoice *model.Invoice, settings *model.MerchantDisplaySettings) ([]byte, error) {
+	language := i18n.Match(invoice.Language, settings.Locale)
+	if language == i18n.Arabic {
+		language = i18n.French
+	}
+	return renderInvoicePDF(s.buildDocument(invoice, settings, language))
+}
+
+func invoiceFilename(invoice *model.Invoice) string {
+	if invoice.Number.Valid {
+		return invoice.Number.String + ".pdf"
+	}
+	return "draft-" + invoice.ID.String() + ".pdf"
+}
+
+func generateInvoiceToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "inv_" + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+)
+
+// mailer sends HTML emails over SMTP. It is disabled unless EMAIL_SMTP_HOST
+// is set.
+type mailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func newMailer() *mailer {
+	m := &mailer{
+		from: config.GetEnvWithDefault("EMAIL_FROM", "receipts@paymentgateway.ma"),
+	}
+
+	if host := config.GetEnv("EMAIL_SMTP_HOST"); host != "" {
+		m.addr = host + ":" + config.GetEnvWithDefault("EMAIL_SMTP_PORT", "587")
+		if user := config.GetEnv("EMAIL_SMTP_USER"); user != "" {
+			m.auth = smtp.PlainAuth("", user, config.GetEnv("EMAIL_SMTP_PASS"), host)
+		}
+	}
+	return m
+}
+
+func (m *mailer) enabled() bool {
+	return m.addr != ""
+}
+
+func (m *mailer) send(to, subject string, html []byte) error {
+	return m.sendWithAttachments(to, subject, html)
+}
+
+// mailAttachment is a file sent along with an email
+type mailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// sendWithAttachments sends an HTML email; with attachments it becomes a
+// multipart/mixed message
+func (m *mailer) sendWithAttachments(to, subject string, html []byte, attachments ...mailAttachment) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	// Arabic and French subjects need encoded-word headers
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	if len(attachments) == 0 {
+		msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+		msg.Write(html)
+		return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, msg.Bytes())
+	}
+
+	parts := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", parts.Boundary())
+
+	body, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=UTF-8"},
+	})
+	if err != nil {
+		return err
+	}
+	body.Write(html)
+
+	for _, attachment := range attachments {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return err
+		}
+		writeBase64Lines(part, attachment.Data)
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, msg.Bytes())
+}
+
+// writeBase64Lines writes data as base64 in 76 character lines, the longest
+// MIME allows
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		io.WriteString(w, encoded[:76]+"\r\n")
+		encoded = encoded[76:]
+	}
+	io.WriteString(w, encoded+"\r\n")
+}
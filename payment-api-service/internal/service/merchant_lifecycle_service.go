@@ -0,0 +1,91 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrMerchantNotAcceptingPayments = errors.New("merchant account is closing and no longer accepts payments")
+	ErrRefundWindowClosed           = errors.New("merchant account is closed to refunds")
+	ErrInvalidLifecycle             = errors.New("invalid merchant lifecycle")
+)
+
+// MerchantLifecycleService enforces the state set by merchant offboarding
+type MerchantLifecycleService struct {
+	lifecycleRepo *repository.MerchantLifecycleRepository
+}
+
+func NewMerchantLifecycleService() *MerchantLifecycleService {
+	return &MerchantLifecycleService{
+		lifecycleRepo: repository.NewMerchantLifecycleRepository(),
+	}
+}
+
+// CheckCanAcceptPayments refuses new payments for closing merchants
+func (s *MerchantLifecycleService) CheckCanAcceptPayments(merchantID uuid.UUID) error {
+	lifecycle, err := s.lifecycleRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to load merchant lifecycle: %w", err)
+	}
+	if !lifecycle.AcceptsPayments() {
+		return ErrMerchantNotAcceptingPayments
+	}
+	return nil
+}
+
+// CheckCanRefund refuses refunds once the wind-down window has passed
+func (s *MerchantLifecycleService) CheckCanRefund(merchantID uuid.UUID) error {
+	lifecycle, err := s.lifecycleRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to load merchant lifecycle: %w", err)
+	}
+	if !lifecycle.AcceptsRefunds(time.Now()) {
+		return ErrRefundWindowClosed
+	}
+	return nil
+}
+
+// GetLifecycle returns the merchant's current lifecycle
+func (s *MerchantLifecycleService) GetLifecycle(merchantID uuid.UUID) (*model.MerchantLifecycle, error) {
+	return s.lifecycleRepo.FindByMerchant(merchantID)
+}
+
+// SetLifecycle records a transition requested by the offboarding workflow
+func (s *MerchantLifecycleService) SetLifecycle(merchantID uuid.UUID, status model.MerchantLifecycleStatus, refundsUntil *time.Time) (*model.MerchantLifecycle, error) {
+	switch status {
+	case model.MerchantLifecycleActive, model.MerchantLifecycleWindingDown, model.MerchantLifecycleClosed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidLifecycle, status)
+	}
+	if status == model.MerchantLifecycleWindingDown && refundsUntil == nil {
+		return nil, fmt.Errorf("%w: refunds_until is required while winding down", ErrInvalidLifecycle)
+	}
+
+	lifecycle := &model.MerchantLifecycle{
+		MerchantID: merchantID,
+		Status:     status,
+	}
+	if refundsUntil != nil && status == model.MerchantLifecycleWindingDown {
+		lifecycle.RefundsUntil = sql.NullTime{Time: *refundsUntil, Valid: true}
+	}
+
+	if err := s.lifecycleRepo.Upsert(lifecycle); err != nil {
+		return nil, fmt.Errorf("failed to save merchant lifecycle: %w", err)
+	}
+
+	logger.Log.Warn("Merchant lifecycle changed",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("status", string(status)),
+	)
+
+	return lifecycle, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+// OnboardingProgress is the part of a merchant's onboarding checklist that
+// payment-api knows about
+type OnboardingProgress struct {
+	FirstTestPaymentAt *time.Time `json:"first_test_payment_at"`
+	FirstLivePaymentAt *time.Time `json:"first_live_payment_at"`
+	WebhookConfigured  bool       `json:"webhook_configured"`
+}
+
+// OnboardingService reports onboarding progress to the merchant service,
+// which owns the checklist
+type OnboardingService struct {
+	paymentRepo      *repository.PaymentRepository
+	subscriptionRepo *repository.WebhookSubscriptionRepository
+}
+
+func NewOnboardingService() *OnboardingService {
+	return &OnboardingService{
+		paymentRepo:      repository.NewPaymentRepository(),
+		subscriptionRepo: repository.NewWebhookSubscriptionRepository(),
+	}
+}
+
+// GetProgress returns the merchant's first authorized test and live payments
+// and whether it has an active webhook subscription
+func (s *OnboardingService) GetProgress(merchantID uuid.UUID) (*OnboardingProgress, error) {
+	var progress OnboardingProgress
+	var err error
+
+	if progress.FirstTestPaymentAt, err = s.paymentRepo.FirstSuccessfulPaymentAt(merchantID, true); err != nil {
+		return nil, fmt.Errorf("failed to load first test payment: %w", err)
+	}
+	if progress.FirstLivePaymentAt, err = s.paymentRepo.FirstSuccessfulPaymentAt(merchantID, false); err != nil {
+		return nil, fmt.Errorf("failed to load first live payment: %w", err)
+	}
+
+	subs, err := s.subscriptionRepo.FindActiveByMerchant(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions: %w", err)
+	}
+	progress.WebhookConfigured = len(subs) > 0
+
+	return &progress, nil
+}
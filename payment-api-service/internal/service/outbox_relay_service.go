@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxBatchSize      = 100
+	outboxPollInterval   = time.Second
+	outboxPurgeInterval  = time.Hour
+	outboxMaxRetryDelay  = 5 * time.Minute
+	outboxRetentionAfter = 7 * 24 * time.Hour // Published events kept for debugging
+)
+
+// OutboxRelayService publishes queued payment lifecycle events (payment.*)
+// to the event broker, at least once and oldest first
+type OutboxRelayService struct {
+	outboxRepo    *repository.OutboxRepository
+	publisher     client.EventPublisher
+	subjectPrefix string
+}
+
+// NewOutboxRelayService connects the relay to EVENT_BROKER_URL. It returns
+// nil when no broker is configured, in which case no events are queued.
+func NewOutboxRelayService() (*OutboxRelayService, error) {
+	if !repository.OutboxEnabled() {
+		return nil, nil
+	}
+
+	publisher, err := client.NewEventPublisher(config.GetEnv("EVENT_BROKER_URL"), "payment-api-service")
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxRelayService{
+		outboxRepo:    repository.NewOutboxRepository(),
+		publisher:     publisher,
+		subjectPrefix: config.GetEnvWithDefault("EVENT_SUBJECT_PREFIX", "gateway"),
+	}, nil
+}
+
+// RunOutboxRelayWorker publishes queued events every second and purges
+// published ones every hour until ctx is canceled
+func (s *OutboxRelayService) RunOutboxRelayWorker(ctx context.Context) error {
+	logger.Log.Info("Starting outbox relay worker")
+	defer s.publisher.Close()
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(outboxPurgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Outbox relay worker stopped")
+			return nil
+		case <-ticker.C:
+			if _, err := s.PublishPending(); err != nil {
+				logger.Log.Error("Outbox relay failed", zap.Error(err))
+			}
+		case <-purgeTicker.C:
+			deleted, err := s.outboxRepo.DeletePublishedBefore(time.Now().Add(-outboxRetentionAfter))
+			if err != nil {
+				logger.Log.Error("Outbox purge failed", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.Log.Info("Purged published outbox events", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
+
+// PublishPending publishes due events in batches until none are left or
+// the broker fails, and returns how many were published
+func (s *OutboxRelayService) PublishPending() (int, error) {
+	total := 0
+	for {
+		published, err := s.outboxRepo.ProcessPending(outboxBatchSize, outboxRetryDelay, s.publishBatch)
+		total += published
+		if err != nil {
+			return total, err
+		}
+		if published < outboxBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// publishBatch sends every event and confirms them with one flush. Nothing
+// is confirmed when any of it fails, so the whole batch is retried.
+func (s *OutboxRelayService) publishBatch(events []model.OutboxEvent) (int, error) {
+	for _, event := range events {
+		if err := s.publisher.Publish(s.subjectPrefix+"."+event.EventType, []byte(event.Payload)); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.publisher.Flush(); err != nil {
+		return 0, fmt.Errorf("broker did not confirm %d events: %w", len(events), err)
+	}
+	return len(events), nil
+}
+
+// outboxRetryDelay backs off exponentially from 2s up to 5 minutes
+func outboxRetryDelay(attempts int) time.Duration {
+	if attempts > 8 {
+		return outboxMaxRetryDelay
+	}
+	delay := time.Duration(1<<attempts) * time.Second
+	if delay > outboxMaxRetryDelay {
+		return outboxMaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+)
+
+var ErrPaymentNotAwaitingAuthentication = errors.New("payment is not waiting for 3-D Secure authentication")
+
+const threeDSFailedMessage = "3-D Secure authentication failed"
+
+// ThreeDSecureRequest asks for 3-D Secure before authorization
+type ThreeDSecureRequest struct {
+	ReturnURL string // Where the ACS sends the cardholder after a challenge
+}
+
+const NextActionRedirectToURL = "redirect_to_url"
+
+// NextAction tells the integration what the cardholder has to do before
+// the payment can go on
+type NextAction struct {
+	Type        string `json:"type"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+// authenticateCard runs 3-D Secure and records the outcome on the payment.
+// A challenge leaves the payment requires_action and a failed
+// authentication fails it; otherwise it stays pending. If the directory
+// server cannot be reached, the payment goes on without liability shift
+// rather than failing.
+func (s *PaymentService) authenticateCard(ctx context.Context, payment *model.Payment, req *ThreeDSecureRequest) {
+	resp, err := s.transactionClient.Authenticate(ctx, &pb.AuthenticateRequest{
+		MerchantId: payment.MerchantID.String(),
+		CardToken:  payment.Token,
+		CardBrand:  payment.CardBrand,
+		CardLast4:  payment.CardLast4,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		ReturnUrl:  req.ReturnURL,
+	})
+	if err != nil {
+		logger.Log.Warn("3-D Secure unavailable, authorizing without it",
+			zap.String("merchant_id", payment.MerchantID.String()),
+			zap.Error(err),
+		)
+		payment.ThreeDS.Status = model.ThreeDSUnavailable
+		return
+	}
+
+	applyThreeDSResult(payment, resp)
+	switch resp.TransStatus {
+	case model.ThreeDSChallenge:
+		payment.Status = model.PaymentStatusRequiresAction
+		payment.ThreeDS.ACSURL = resp.AcsUrl
+	case model.ThreeDSFailed, model.ThreeDSRejected:
+		failThreeDS(payment, resp.Reason)
+	}
+}
+
+// AuthenticatePayment finishes a 3-D Secure challenge with the CRes the ACS
+// posted to the merchant's return URL. An authenticated payment is sent to
+// the issuer, and captured as well if it was a sale.
+func (s *PaymentService) AuthenticatePayment(ctx context.Context, paymentID, merchantID, actorID uuid.UUID, cres string) (*PaymentResponse, error) {
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != model.PaymentStatusRequiresAction {
+		return nil, ErrPaymentNotAwaitingAuthentication
+	}
+
+	result, err := s.transactionClient.CompleteAuthentication(ctx, &pb.CompleteAuthenticationRequest{
+		MerchantId:      merchantID.String(),
+		DsTransactionId: payment.ThreeDS.DSTransactionID,
+		CardBrand:       payment.CardBrand,
+		Cres:            cres,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one callback may take the payment to the issuer
+	claimed, err := s.paymentRepo.TransitionStatus(payment.ID, model.PaymentStatusRequiresAction, model.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrPaymentNotAwaitingAuthentication
+	}
+
+	payment.Status = model.PaymentStatusPending
+	payment.ThreeDS.ACSURL = ""
+	applyThreeDSResult(payment, result)
+
+	var authResp *pb.AuthorizeResponse
+	if payment.ThreeDS.Authenticated() {
+		stageStart := time.Now()
+		authResp, err = s.authorizeWithIssuer(ctx, payment)
+		payment.Timings.AuthorizeMs = time.Since(stageStart).Milliseconds()
+		if err != nil {
+			// The cardholder is authenticated; let the merchant retry
+			if _, rerr := s.paymentRepo.TransitionStatus(payment.ID, model.PaymentStatusPending, model.PaymentStatusRequiresAction); rerr != nil {
+				logger.Log.Error("Failed to restore requires_action", zap.Error(rerr))
+			}
+			return nil, err
+		}
+	} else {
+		failThreeDS(payment, result.Reason)
+	}
+
+	if err := s.paymentRepo.Update(payment); err != nil {
+		logger.Log.Error("Failed to save authenticated payment", zap.Error(err))
+		return nil, fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
+		PaymentID: payment.ID,
+		EventType: string(model.PaymentTypeAuthorize),
+		OldStatus: model.PaymentStatusRequiresAction,
+		NewStatus: payment.Status,
+		Amount:    payment.Amount,
+		CreatedBy: actorID,
+	})
+
+	logger.Log.Info("3-D Secure challenge completed",
+		zap.String("payment_id", payment.ID.String()),
+		zap.String("trans_status", payment.ThreeDS.Status),
+		zap.String("status", string(payment.Status)),
+	)
+
+	if payment.Status == model.PaymentStatusAuthorized && payment.CaptureAfterAuthentication {
+		captureResp, err := s.CapturePayment(ctx, payment.ID, merchantID, actorID, payment.Amount, payment.Currency, true)
+		if err != nil {
+			logger.Log.Error("Auto-capture failed", zap.Error(err))
+			return s.buildPaymentResponse(payment), nil
+		}
+		return captureResp, nil
+	}
+
+	resp := s.buildPaymentResponse(payment)
+	if authResp != nil && !authResp.Approved {
+		resp.RetryAllowed = &authResp.RetryAllowed
+		resp.SuggestedRetryAfter = authResp.SuggestedRetryAfterSeconds
+	}
+	return resp, nil
+}
+
+// authorizeWithIssuer sends the payment to the transaction service and
+// records the issuer's answer on it
+func (s *PaymentService) authorizeWithIssuer(ctx context.Context, payment *model.Payment) (*pb.AuthorizeResponse, error) {
+	authResp, err := s.transactionClient.Authorize(ctx, &pb.AuthorizeRequest{
+		MerchantId:    payment.MerchantID.String(),
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		CardToken:     payment.Token,
+		CardBrand:     payment.CardBrand,
+		CardLast4:     payment.CardLast4,
+		FraudScore:    int32(payment.FraudScore),
+		CustomerEmail: payment.CustomerEmail.String,
+		Description:   payment.Description.String,
+		ThreeDsEci:    payment.ThreeDS.ECI,
+		ThreeDsCavv:   payment.ThreeDS.CAVV,
+		TestMode:      payment.TestMode,
+	})
+	if err != nil {
+		logger.Log.Error("Transaction authorization failed", zap.Error(err))
+		return nil, fmt.Errorf("authorization failed: %w", err)
+	}
+	if authResp.TransactionId == "" {
+		logger.Log.Error("Transaction service returned empty transaction_id",
+			zap.Bool("approved", authResp.Approved),
+			zap.String("merchant_id", payment.MerchantID.String()),
+		)
+		return nil, fmt.Errorf("transaction service did not return transaction_id")
+	}
+
+	txID, err := uuid.Parse(authResp.TransactionId)
+	if err != nil {
+		logger.Log.Error("Invalid transaction_id returned by transaction service",
+			zap.String("transaction_id", authResp.TransactionId),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("invalid transaction_id from transaction service")
+	}
+	payment.TransactionID = txID
+
+	if authResp.Approved {
+		payment.Status = model.PaymentStatusAuthorized
+		payment.AuthCode = sql.NullString{String: authResp.AuthCode, Valid: true}
+		payment.ResponseCode = sql.NullString{String: authResp.ResponseCode, Valid: true}
+		payment.ResponseMsg = sql.NullString{String: authResp.ResponseMessage, Valid: true}
+	} else {
+		payment.Status = model.PaymentStatusFailed
+		payment.ResponseCode = sql.NullString{String: authResp.ResponseCode, Valid: true}
+		payment.ResponseMsg = sql.NullString{String: authResp.DeclineReason, Valid: true}
+	}
+	return authResp, nil
+}
+
+func applyThreeDSResult(payment *model.Payment, resp *pb.AuthenticateResponse) {
+	payment.ThreeDS.Status = resp.TransStatus
+	payment.ThreeDS.Version = resp.Version
+	payment.ThreeDS.ECI = resp.Eci
+	payment.ThreeDS.CAVV = resp.Cavv
+	payment.ThreeDS.DSTransactionID = resp.DsTransactionId
+}
+
+func failThreeDS(payment *model.Payment, reason string) {
+	message := threeDSFailedMessage
+	if reason != "" {
+		message += ": " + reason
+	}
+	payment.Status = model.PaymentStatusFailed
+	payment.ResponseMsg = sql.NullString{String: message, Valid: true}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Sections of a payment detail view that come from other services or tables.
+// One that cannot be loaded is listed in PaymentDetail.Unavailable instead of
+// failing the whole view.
+const (
+	DetailSectionTransaction       = "transaction"
+	DetailSectionToken             = "token"
+	DetailSectionWebhookDeliveries = "webhook_deliveries"
+	DetailSectionDisputes          = "disputes"
+)
+
+// PaymentDetail is everything a payment detail page shows, in one response
+type PaymentDetail struct {
+	Payment           *PaymentResponse        `json:"payment"`
+	FraudDecision     TimelineFraudDecision   `json:"fraud_decision"`
+	Transaction       *pb.TransactionResponse `json:"transaction,omitempty"`
+	Token             *PaymentDetailToken     `json:"token,omitempty"`
+	WebhookDeliveries []model.WebhookDelivery `json:"webhook_deliveries"`
+	Disputes          []*pb.DisputeResponse   `json:"disputes"`
+	Unavailable       []string                `json:"unavailable,omitempty"`
+}
+
+// PaymentDetailToken is the card token the payment used. Card is only set
+// while the token can still be charged.
+type PaymentDetailToken struct {
+	Token  string           `json:"token"`
+	Usable bool             `json:"usable"`
+	Card   *pb.CardMetadata `json:"card,omitempty"`
+}
+
+// GetPaymentDetail loads the payment and, concurrently, its transaction, card
+// token, webhook deliveries and disputes
+func (s *PaymentService) GetPaymentDetail(ctx context.Context, paymentID, merchantID uuid.UUID) (*PaymentDetail, error) {
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &PaymentDetail{
+		Payment: s.buildPaymentResponse(payment),
+		FraudDecision: TimelineFraudDecision{
+			Score:       payment.FraudScore,
+			Decision:    payment.FraudDecision,
+			ReasonCodes: payment.FraudReasonList(),
+		},
+		WebhookDeliveries: []model.WebhookDelivery{},
+		Disputes:          []*pb.DisputeResponse{},
+	}
+
+	var mu sync.Mutex
+	unavailable := func(section string, err error) {
+		logger.Log.Warn("Payment detail section unavailable",
+			zap.String("payment_id", paymentID.String()),
+			zap.String("section", section),
+			zap.Error(err),
+		)
+		mu.Lock()
+		detail.Unavailable = append(detail.Unavailable, section)
+		mu.Unlock()
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		deliveries, err := s.webhookRepo.FindByPaymentAndMerchant(paymentID, merchantID)
+		if err != nil {
+			unavailable(DetailSectionWebhookDeliveries, err)
+			return nil
+		}
+		detail.WebhookDeliveries = deliveries
+		return nil
+	})
+	if payment.Token != "" {
+		g.Go(func() error {
+			if s.tokenizationClient == nil {
+				unavailable(DetailSectionToken, errors.New("tokenization service unavailable"))
+				return nil
+			}
+			token := &PaymentDetailToken{Token: payment.Token}
+			card, err := s.tokenizationClient.GetTokenCard(gctx, payment.Token, merchantID.String())
+			switch {
+			case errors.Is(err, client.ErrTokenNotUsable):
+			case err != nil:
+				unavailable(DetailSectionToken, err)
+				return nil
+			default:
+				token.Usable = true
+				token.Card = card
+			}
+			detail.Token = token
+			return nil
+		})
+	}
+
+	// Payments declined before reaching the transaction service have no
+	// transaction or disputes
+	if payment.TransactionID != uuid.Nil {
+		g.Go(func() error {
+			txn, err := s.transactionClient.GetTransaction(gctx, &pb.GetTransactionRequest{
+				TransactionId: payment.TransactionID.String(),
+				MerchantId:    merchantID.String(),
+			})
+			if err != nil {
+				unavailable(DetailSectionTransaction, err)
+				return nil
+			}
+			detail.Transaction = txn
+			return nil
+		})
+		g.Go(func() error {
+			resp, err := s.transactionClient.ListDisputes(gctx, &pb.ListDisputesRequest{
+				MerchantId:    merchantID.String(),
+				TransactionId: payment.TransactionID.String(),
+			})
+			if err == nil && resp.Error != "" {
+				err = errors.New(resp.Error)
+			}
+			if err != nil {
+				unavailable(DetailSectionDisputes, err)
+				return nil
+			}
+			detail.Disputes = append(detail.Disputes, resp.Disputes...)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return detail, nil
+}
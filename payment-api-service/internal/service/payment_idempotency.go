@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different request")
+
+// findIdempotentPayment returns the payment an earlier request with the same
+// idempotency key created, or nil if there is none. Redis is checked first;
+// Postgres only answers keys the cache has dropped, and refills it.
+func (s *PaymentService) findIdempotentPayment(req *AuthorizePaymentRequest, requestHash string) (*model.Payment, error) {
+	record, err := s.idempotencyRepo.Get(req.MerchantID, req.IdempotencyKey, req.TestMode)
+	if err != nil {
+		logger.Log.Warn("Idempotency cache unavailable, falling back to database", zap.Error(err))
+	}
+	if record != nil {
+		if record.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		payment, err := s.paymentRepo.FindByIDAndMerchant(record.PaymentID, req.MerchantID)
+		if err == nil {
+			return payment, nil
+		}
+		// The payment is gone (e.g. a sandbox reset); the database decides
+	}
+
+	payment, err := s.paymentRepo.FindByIdempotencyKey(req.MerchantID, req.IdempotencyKey, req.TestMode)
+	if err != nil || payment == nil {
+		return nil, err
+	}
+	// Payments from before request hashes were stored cannot be checked
+	if payment.IdempotencyHash != "" && payment.IdempotencyHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	s.cacheIdempotencyKey(req, payment.ID, requestHash)
+	return payment, nil
+}
+
+func (s *PaymentService) cacheIdempotencyKey(req *AuthorizePaymentRequest, paymentID uuid.UUID, requestHash string) {
+	record := &repository.IdempotencyRecord{PaymentID: paymentID, RequestHash: requestHash}
+	if err := s.idempotencyRepo.Set(req.MerchantID, req.IdempotencyKey, req.TestMode, record, s.idempotencyWindow); err != nil {
+		logger.Log.Warn("Failed to cache idempotency key",
+			zap.String("payment_id", paymentID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// idempotencyRequestHash fingerprints the parameters that define a payment.
+// Only the BIN and last four digits of the card go into it, so the hash is
+// no help in recovering a card number.
+func idempotencyRequestHash(req *AuthorizePaymentRequest) string {
+	fields, _ := json.Marshal(struct {
+		Amount         int64                  `json:"amount"`
+		Currency       string                 `json:"currency"`
+		CardBIN        string                 `json:"card_bin"`
+		CardLast4      string                 `json:"card_last4"`
+		ExpMonth       int                    `json:"exp_month"`
+		ExpYear        int                    `json:"exp_year"`
+		CardholderName string                 `json:"cardholder_name"`
+		CustomerEmail  string                 `json:"customer_email"`
+		CustomerName   string                 `json:"customer_name"`
+		Description    string                 `json:"description"`
+		Metadata       map[string]interface{} `json:"metadata"`
+		IntentID       uuid.UUID              `json:"intent_id"`
+		ThreeDSecure   bool                   `json:"three_d_secure,omitempty"` // omitted so older hashes still match
+		CardToken      string                 `json:"card_token,omitempty"`
+	}{
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		CardBIN:        cardBIN(req.CardNumber),
+		CardLast4:      cardLast4(req.CardNumber),
+		ExpMonth:       req.ExpMonth,
+		ExpYear:        req.ExpYear,
+		CardholderName: req.CardholderName,
+		CustomerEmail:  req.CustomerEmail,
+		CustomerName:   req.CustomerName,
+		Description:    req.Description,
+		Metadata:       req.Metadata,
+		IntentID:       req.IntentID,
+		ThreeDSecure:   req.ThreeDSecure != nil,
+		CardToken:      storedCardToken(req.StoredCard),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+func cardLast4(cardNumber string) string {
+	digits := make([]rune, 0, len(cardNumber))
+	for _, r := range cardNumber {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 4 {
+		return string(digits)
+	}
+	return string(digits[len(digits)-4:])
+}
+
+func storedCardToken(card *StoredCard) string {
+	if card == nil {
+		return ""
+	}
+	return card.Token
+}
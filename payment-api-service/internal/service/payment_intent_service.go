@@ -2,30 +2,52 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	"go.uber.org/zap"
 )
 
 type PaymentIntentService struct {
-	intentRepo     *repository.PaymentIntentRepository
-	paymentService *PaymentService
+	intentRepo      *repository.PaymentIntentRepository
+	checkoutRepo    *repository.CheckoutSettingsRepository
+	checkoutService *CheckoutSettingsService
+	sessionRepo     *repository.CheckoutSessionRepository
+	captchaClient   *client.CaptchaClient
+	displaySettings *DisplaySettingsService
+	receiptService  *ReceiptService
+	invoiceRepo     *repository.InvoiceRepository
+	paymentService  *PaymentService
 }
 
 func NewPaymentIntentService(paymentService *PaymentService) *PaymentIntentService {
 	return &PaymentIntentService{
-		intentRepo:     repository.NewPaymentIntentRepository(),
-		paymentService: paymentService,
+		intentRepo:      repository.NewPaymentIntentRepository(),
+		checkoutRepo:    repository.NewCheckoutSettingsRepository(),
+		checkoutService: NewCheckoutSettingsService(),
+		sessionRepo:     repository.NewCheckoutSessionRepository(),
+		captchaClient:   client.NewCaptchaClient(),
+		displaySettings: NewDisplaySettingsService(),
+		receiptService:  NewReceiptService(),
+		invoiceRepo:     repository.NewInvoiceRepository(),
+		paymentService:  paymentService,
 	}
 }
 
@@ -44,6 +66,8 @@ type CreatePaymentIntentRequest struct {
 	CancelURL     string
 	CustomerEmail string
 	Metadata      map[string]interface{}
+	TestMode      bool   // sandbox intent, made with a pg_test_ key
+	Language      string // checkout language override, already validated
 }
 
 type PaymentIntentResponse struct {
@@ -59,6 +83,56 @@ type PaymentIntentResponse struct {
 	CreatedAt    time.Time                 `json:"created_at"`
 }
 
+// CheckoutIntentResponse is what the hosted checkout page may see: no
+// client secret, plus the text to render in the customer's language
+type CheckoutIntentResponse struct {
+	PaymentIntentResponse
+	TestMode      bool              `json:"test_mode"`
+	AmountDisplay string            `json:"amount_display"`
+	Language      string            `json:"language"`
+	Direction     string            `json:"direction"`
+	Messages      map[string]string `json:"messages"`
+
+	// Card brands to show on the card form, and the surcharge notice
+	AcceptedBrands      []string `json:"accepted_brands"`
+	SurchargeDisclosure string   `json:"surcharge_disclosure,omitempty"`
+
+	// Itemized order, when the intent belongs to a checkout session
+	CheckoutSession *CheckoutSessionDisplay `json:"checkout_session,omitempty"`
+}
+
+// CheckoutSessionDisplay is a checkout session as the hosted checkout shows
+// it, with every amount also formatted for the merchant's display settings
+type CheckoutSessionDisplay struct {
+	ID                    uuid.UUID                 `json:"id"`
+	LineItems             []CheckoutLineDisplay     `json:"line_items"`
+	ShippingOptions       []CheckoutShippingDisplay `json:"shipping_options"`
+	ShippingOption        string                    `json:"shipping_option,omitempty"`
+	Subtotal              int64                     `json:"subtotal"`
+	SubtotalDisplay       string                    `json:"subtotal_display"`
+	TaxAmount             int64                     `json:"tax_amount"`
+	TaxAmountDisplay      string                    `json:"tax_amount_display"`
+	ShippingAmount        int64                     `json:"shipping_amount"`
+	ShippingAmountDisplay string                    `json:"shipping_amount_display"`
+	AmountTotal           int64                     `json:"amount_total"`
+	AmountTotalDisplay    string                    `json:"amount_total_display"`
+}
+
+type CheckoutLineDisplay struct {
+	model.CheckoutLineItem
+	UnitAmountDisplay string `json:"unit_amount_display"`
+	AmountDisplay     string `json:"amount_display"`
+}
+
+// CheckoutShippingDisplay is a shipping option and the order total if the
+// customer picks it
+type CheckoutShippingDisplay struct {
+	model.CheckoutShippingOption
+	AmountDisplay      string `json:"amount_display"`
+	AmountTotal        int64  `json:"amount_total"`
+	AmountTotalDisplay string `json:"amount_total_display"`
+}
+
 type ConfirmPaymentIntentRequest struct {
 	PaymentIntentID string
 	ClientSecret    string
@@ -71,6 +145,10 @@ type ConfirmPaymentIntentRequest struct {
 	IdempotencyKey  string // Optional
 	IPAddress       string
 	UserAgent       string
+	Origin          string // scheme://host of the checkout page, from Origin or Referer
+	CaptchaToken    string
+	Language        string // language the customer switched the checkout to, if any
+	ShippingOption  string // code of the shipping option picked, for checkout sessions
 }
 type PaymentIntentError struct {
 	Code           string
@@ -93,6 +171,26 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 		zap.String("currency", req.Currency),
 	)
 
+	intent, err := s.newPaymentIntent(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.intentRepo.Create(intent); err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	logger.Log.Info("Payment intent created",
+		zap.String("intent_id", intent.ID.String()),
+		zap.Time("expires_at", intent.ExpiresAt),
+	)
+
+	return newPaymentIntentResponse(intent), nil
+}
+
+// newPaymentIntent validates a create request and builds the intent,
+// awaiting payment for an hour. The caller stores it.
+func (s *PaymentIntentService) newPaymentIntent(req *CreatePaymentIntentRequest) (*model.PaymentIntent, error) {
 	// Validate
 	if req.Amount <= 0 {
 		return nil, errors.New("amount must be positive")
@@ -100,9 +198,16 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 	if req.Currency != "USD" && req.Currency != "EUR" && req.Currency != "MAD" {
 		return nil, errors.New("unsupported currency")
 	}
+	if err := s.paymentService.lifecycle.CheckCanAcceptPayments(req.MerchantID); err != nil {
+		return nil, err
+	}
 	if req.SuccessURL == "" {
 		return nil, errors.New("success_url is required")
 	}
+	metadata, err := encodeMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set defaults
 	if req.CaptureMethod == "" {
@@ -118,6 +223,7 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 	// Create payment intent with 1-hour expiration
 	intent := &model.PaymentIntent{
 		MerchantID:    req.MerchantID,
+		TestMode:      req.TestMode,
 		Amount:        req.Amount,
 		Currency:      req.Currency,
 		Status:        model.PaymentIntentStatusAwaitingPayment,
@@ -128,6 +234,8 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 		MaxAttempts:   7,
 		AttemptCount:  0,
 		ExpiresAt:     time.Now().Add(1 * time.Hour), // 1 HOUR EXPIRATION
+		Language:      req.Language,
+		Metadata:      metadata,
 	}
 
 	if req.OrderID != "" {
@@ -140,15 +248,12 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 		intent.CustomerEmail = sql.NullString{String: req.CustomerEmail, Valid: true}
 	}
 
-	if err := s.intentRepo.Create(intent); err != nil {
-		return nil, fmt.Errorf("failed to create payment intent: %w", err)
-	}
-
-	logger.Log.Info("Payment intent created",
-		zap.String("intent_id", intent.ID.String()),
-		zap.Time("expires_at", intent.ExpiresAt),
-	)
+	return intent, nil
+}
 
+// newPaymentIntentResponse is what the merchant gets back on creation,
+// including the client secret and hosted checkout link
+func newPaymentIntentResponse(intent *model.PaymentIntent) *PaymentIntentResponse {
 	return &PaymentIntentResponse{
 		ID:           intent.ID,
 		ClientSecret: intent.ClientSecret,
@@ -158,38 +263,109 @@ func (s *PaymentIntentService) CreatePaymentIntent(ctx context.Context, req *Cre
 		CheckoutURL:  fmt.Sprintf("%s?client_secret=%s", intent.GetCheckoutURL(config.GetEnv("CHECKOUT_URL")), intent.ClientSecret),
 		ExpiresAt:    intent.ExpiresAt,
 		CreatedAt:    intent.CreatedAt,
-	}, nil
+	}
 }
 
 // =========================================================================
 // Get Payment Intent (Browser-Safe)
 // =========================================================================
 
-func (s *PaymentIntentService) GetPaymentIntent(ctx context.Context, intentID uuid.UUID) (*PaymentIntentResponse, error) {
-	intent, err := s.intentRepo.FindByID(intentID)
+// GetPaymentIntent returns the browser-safe view of an intent. The language
+// is the intent's override, else the first of acceptLanguage the checkout
+// supports, else the merchant's locale.
+func (s *PaymentIntentService) GetPaymentIntent(ctx context.Context, intentID uuid.UUID, acceptLanguage string) (*CheckoutIntentResponse, error) {
+	intent, err := s.intentRepo.FindPublicByID(intentID)
 	if err != nil {
 		return nil, fmt.Errorf("payment intent not found: %w", err)
 	}
 
 	// Check expiration
 	if intent.IsExpired() && intent.Status == model.PaymentIntentStatusAwaitingPayment {
-		s.intentRepo.MarkExpired(intentID)
+		s.intentRepo.WithContext(tenancy.WithMerchant(ctx, intent.MerchantID)).MarkExpired(intentID)
 		intent.Status = model.PaymentIntentStatusExpired
 	}
 
+	session, err := s.sessionRepo.FindByIntent(intent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkout session: %w", err)
+	}
+
+	settings := s.displaySettings.Resolve(intent.MerchantID)
+	language := i18n.Match(intent.Language, acceptLanguage, settings.Locale)
+
+	checkout, err := s.checkoutRepo.FindByMerchant(intent.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkout settings: %w", err)
+	}
+
 	// Return safe data (no client_secret)
-	return &PaymentIntentResponse{
-		ID:         intent.ID,
-		Status:     intent.Status,
-		Amount:     intent.Amount,
-		Currency:   intent.Currency,
-		SuccessURL: intent.SuccessURL,
-		CancelURL:  intent.CancelURL,
-		ExpiresAt:  intent.ExpiresAt,
-		CreatedAt:  intent.CreatedAt,
+	return &CheckoutIntentResponse{
+		PaymentIntentResponse: PaymentIntentResponse{
+			ID:         intent.ID,
+			Status:     intent.Status,
+			Amount:     intent.Amount,
+			Currency:   intent.Currency,
+			SuccessURL: intent.SuccessURL,
+			CancelURL:  intent.CancelURL,
+			ExpiresAt:  intent.ExpiresAt,
+			CreatedAt:  intent.CreatedAt,
+		},
+		TestMode:      intent.TestMode,
+		AmountDisplay: settings.FormatAmount(intent.Amount, intent.Currency),
+		Language:      language,
+		Direction:     i18n.Direction(language),
+		Messages:      i18n.Messages(language, "checkout."),
+
+		AcceptedBrands:      checkout.AcceptedBrandList(),
+		SurchargeDisclosure: checkout.SurchargeDisclosure,
+
+		CheckoutSession: displayCheckoutSession(session, settings),
 	}, nil
 }
 
+// displayCheckoutSession formats a session for the hosted checkout; nil
+// for intents created on their own
+func displayCheckoutSession(session *model.CheckoutSession, settings *model.MerchantDisplaySettings) *CheckoutSessionDisplay {
+	if session == nil {
+		return nil
+	}
+	format := func(amount int64) string {
+		return settings.FormatAmount(amount, session.Currency)
+	}
+
+	display := &CheckoutSessionDisplay{
+		ID:                    session.ID,
+		LineItems:             make([]CheckoutLineDisplay, 0, len(session.LineItems)),
+		ShippingOptions:       make([]CheckoutShippingDisplay, 0, len(session.ShippingOptions)),
+		ShippingOption:        session.ShippingOption,
+		Subtotal:              session.Subtotal,
+		SubtotalDisplay:       format(session.Subtotal),
+		TaxAmount:             session.TaxAmount,
+		TaxAmountDisplay:      format(session.TaxAmount),
+		ShippingAmount:        session.ShippingAmount,
+		ShippingAmountDisplay: format(session.ShippingAmount),
+		AmountTotal:           session.AmountTotal,
+		AmountTotalDisplay:    format(session.AmountTotal),
+	}
+	for _, item := range session.LineItems {
+		display.LineItems = append(display.LineItems, CheckoutLineDisplay{
+			CheckoutLineItem:  item,
+			UnitAmountDisplay: format(item.UnitAmount),
+			AmountDisplay:     format(item.Amount),
+		})
+	}
+	for _, option := range session.ShippingOptions {
+		total := session.Subtotal + session.TaxAmount + option.Amount
+		display.ShippingOptions = append(display.ShippingOptions, CheckoutShippingDisplay{
+			CheckoutShippingOption: option,
+			AmountDisplay:          format(option.Amount),
+			AmountTotal:            total,
+			AmountTotalDisplay:     format(total),
+		})
+	}
+	return display
+}
+
 // =========================================================================
 // Confirm Payment Intent (Process Payment)
 // =========================================================================
@@ -217,13 +393,22 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 		}
 	}
 
+	// The secret identifies the merchant; scope the rest of the flow to it
+	ctx = tenancy.WithMerchant(ctx, intent.MerchantID)
+	intentRepo := s.intentRepo.WithContext(ctx)
+
 	// ===================================================================
 	// VALIDATION CHECKS
 	// ===================================================================
 
+	// Merchant's checkout protections (origin allowlist, CAPTCHA)
+	if err := s.checkCheckoutGuards(ctx, intent, req); err != nil {
+		return nil, err
+	}
+
 	// Check if expired
 	if intent.IsExpired() {
-		s.intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusExpired)
+		intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusExpired)
 		return nil, &PaymentIntentError{
 			Code:    "INTENT_EXPIRED",
 			Message: fmt.Sprintf("Payment intent expired at %s. Please create a new payment.", intent.ExpiresAt.Format("15:04:05")),
@@ -232,7 +417,7 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 
 	// Check if max attempts reached
 	if intent.AttemptCount >= intent.MaxAttempts {
-		s.intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
+		intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
 		return nil, &PaymentIntentError{
 			Code:    "MAX_ATTEMPTS_REACHED",
 			Message: fmt.Sprintf("Maximum payment attempts (%d) reached. Please create a new payment intent.", intent.MaxAttempts),
@@ -248,15 +433,22 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 		}
 	}
 
+	// Apply the customer's shipping choice before the attempt is counted, so
+	// an unknown option is not held against them
+	session, err := s.applyShippingOption(ctx, intent, req.ShippingOption)
+	if err != nil {
+		return nil, err
+	}
+
 	// ===================================================================
 	// INCREMENT ATTEMPT COUNTER
 	// ===================================================================
-	if err = s.intentRepo.IncrementAttemptCount(intentID); err != nil {
+	if err = intentRepo.IncrementAttemptCount(intentID); err != nil {
 		logger.Log.Error("Failed to increment attempt count", zap.Error(err))
 	}
 
 	// Refresh intent to get updated attempt count
-	intent, _ = s.intentRepo.FindByID(intentID)
+	intent, _ = intentRepo.FindByID(intentID)
 
 	logger.Log.Info("Processing payment attempt",
 		zap.String("intent_id", intentID.String()),
@@ -280,6 +472,16 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 		IdempotencyKey: req.IdempotencyKey,
 		IPAddress:      req.IPAddress,
 		UserAgent:      req.UserAgent,
+		TestMode:       intent.TestMode,
+		IntentID:       intent.ID,
+		Language:       intent.Language,
+		Metadata:       decodeMetadata(intent.Metadata),
+	}
+	if session != nil {
+		authReq.CheckoutSessionID = session.ID
+	}
+	if req.Language != "" {
+		authReq.Language = req.Language
 	}
 
 	// Use customer email from request or intent
@@ -307,6 +509,11 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 	// ===================================================================
 	// HANDLE PAYMENT RESULT
 	// ===================================================================
+
+	// The charge has been attempted, so record its outcome even if the
+	// caller has gone away; otherwise a retry would charge the card again
+	savedRepo := s.intentRepo.WithContext(context.WithoutCancel(ctx))
+
 	if err != nil {
 		logger.Log.Warn("Payment authorization failed",
 			zap.Error(err),
@@ -315,9 +522,40 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 			zap.Int("remaining", intent.GetRemainingAttempts()),
 		)
 
+		if errors.Is(err, ErrMerchantNotAcceptingPayments) {
+			return nil, &PaymentIntentError{
+				Code:    "MERCHANT_UNAVAILABLE",
+				Message: "This merchant is no longer accepting payments.",
+			}
+		}
+
+		if errors.Is(err, ErrCardBrandNotAccepted) {
+			return nil, &PaymentIntentError{
+				Code:           "CARD_BRAND_NOT_ACCEPTED",
+				Message:        "This card brand is not accepted. Please use another card.",
+				RemainingTries: intent.GetRemainingAttempts(),
+			}
+		}
+
+		if errors.Is(err, ErrIntentCardChurn) {
+			savedRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
+			return nil, &PaymentIntentError{
+				Code:    "TOO_MANY_CARDS",
+				Message: "Too many different cards were tried. Please create a new payment intent.",
+			}
+		}
+
+		if errors.Is(err, ErrCardTestingThrottled) {
+			return nil, &PaymentIntentError{
+				Code:           "TOO_MANY_ATTEMPTS",
+				Message:        "Too many payment attempts. Please try again later.",
+				RemainingTries: intent.GetRemainingAttempts(),
+			}
+		}
+
 		// Check if this was the last attempt
 		if intent.GetRemainingAttempts() == 0 {
-			s.intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
+			savedRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
 			return nil, &PaymentIntentError{
 				Code:           "MAX_ATTEMPTS_REACHED",
 				Message:        "Payment failed. Maximum attempts reached. Please create a new payment intent.",
@@ -347,17 +585,39 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 		paymentResp.Status == model.PaymentStatusCaptured {
 
 		// Mark as confirmed and reset attempts
-		s.intentRepo.MarkConfirmed(intentID, paymentResp.ID)
-		s.intentRepo.ResetAttempts(intentID)
+		if err := savedRepo.MarkConfirmed(intentID, paymentResp.ID); err != nil {
+			logger.Log.Error("Failed to mark payment intent confirmed",
+				zap.String("intent_id", intentID.String()),
+				zap.String("payment_id", paymentResp.ID.String()),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to confirm payment intent: %w", err)
+		}
+		if err := savedRepo.ResetAttempts(intentID); err != nil {
+			logger.Log.Error("Failed to reset attempt count", zap.Error(err))
+		}
+		s.paymentService.intentVelocity.Clear(intentID)
 
 		logger.Log.Info("Payment intent confirmed",
 			zap.String("intent_id", intentID.String()),
 			zap.String("payment_id", paymentResp.ID.String()),
 		)
+
+		// Signed success redirect so the merchant can trust the query params
+		paymentResp.RedirectURL = s.signedRedirectURL(intent, paymentResp)
+
+		if paymentResp.Status == model.PaymentStatusCaptured {
+			// The sale was captured before the intent recorded its payment,
+			// so the capture could not find the invoice; a later capture does
+			if err := s.markInvoicePaid(context.WithoutCancel(ctx), intentID, intent.MerchantID, paymentResp.ID); err != nil {
+				return nil, err
+			}
+			go s.receiptService.SendReceiptEmail(paymentResp.ID, intent.MerchantID)
+		}
 	} else {
 		// Payment was processed but not successful (declined by bank)
 		if intent.GetRemainingAttempts() == 0 {
-			s.intentRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
+			savedRepo.UpdateStatus(intentID, model.PaymentIntentStatusFailed)
 		}
 
 		return nil, &PaymentIntentError{
@@ -370,11 +630,128 @@ func (s *PaymentIntentService) ConfirmPaymentIntent(ctx context.Context, req *Co
 	return paymentResp, nil
 }
 
+// markInvoicePaid marks the invoice a captured intent was collecting, if
+// any, as paid
+func (s *PaymentIntentService) markInvoicePaid(ctx context.Context, intentID, merchantID, paymentID uuid.UUID) error {
+	paid, err := s.invoiceRepo.WithContext(ctx).MarkPaidByIntent(intentID, merchantID, paymentID)
+	if err != nil {
+		logger.Log.Error("Failed to mark invoice paid",
+			zap.String("intent_id", intentID.String()),
+			zap.String("payment_id", paymentID.String()),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to mark invoice paid: %w", err)
+	}
+	if paid {
+		logger.Log.Info("Invoice paid",
+			zap.String("intent_id", intentID.String()),
+			zap.String("payment_id", paymentID.String()),
+		)
+	}
+	return nil
+}
+
+// IsCheckoutOriginAllowed reports whether a browser on origin may call the
+// public endpoints of the intent, per its merchant's origin allowlist
+func (s *PaymentIntentService) IsCheckoutOriginAllowed(intentID uuid.UUID, origin string) (bool, error) {
+	intent, err := s.intentRepo.FindPublicByID(intentID)
+	if err != nil {
+		return false, fmt.Errorf("payment intent not found: %w", err)
+	}
+	settings, err := s.checkoutRepo.FindByMerchant(intent.MerchantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load checkout settings: %w", err)
+	}
+	return settings.IsOriginAllowed(origin), nil
+}
+
+// applyShippingOption loads the intent's checkout session, if it has one,
+// and switches it to the shipping option the customer picked. The intent's
+// amount follows the session total. An empty code keeps the current choice.
+func (s *PaymentIntentService) applyShippingOption(ctx context.Context, intent *model.PaymentIntent, code string) (*model.CheckoutSession, error) {
+	session, err := s.sessionRepo.FindByIntent(intent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkout session: %w", err)
+	}
+	if session == nil || code == "" || code == session.ShippingOption {
+		return session, nil
+	}
+
+	option := session.FindShippingOption(code)
+	if option == nil {
+		return nil, &PaymentIntentError{
+			Code:           "SHIPPING_OPTION_INVALID",
+			Message:        fmt.Sprintf("Unknown shipping option %q", code),
+			RemainingTries: intent.GetRemainingAttempts(),
+		}
+	}
+
+	session.SelectShipping(option)
+	if err := s.sessionRepo.UpdateShipping(session); err != nil {
+		return nil, fmt.Errorf("failed to update shipping option: %w", err)
+	}
+	intent.Amount = session.AmountTotal
+
+	logger.Log.Info("Checkout shipping option selected",
+		zap.String("session_id", session.ID.String()),
+		zap.String("shipping_option", code),
+		zap.Int64("amount_total", session.AmountTotal),
+	)
+	return session, nil
+}
+
+// checkCheckoutGuards enforces the merchant's origin allowlist and CAPTCHA
+// requirement before a confirmation counts as an attempt
+func (s *PaymentIntentService) checkCheckoutGuards(ctx context.Context, intent *model.PaymentIntent, req *ConfirmPaymentIntentRequest) error {
+	settings, err := s.checkoutRepo.FindByMerchant(intent.MerchantID)
+	if err != nil {
+		return fmt.Errorf("failed to load checkout settings: %w", err)
+	}
+
+	if !settings.IsOriginAllowed(req.Origin) {
+		logger.Log.Warn("Payment intent confirmation from disallowed origin",
+			zap.String("intent_id", intent.ID.String()),
+			zap.String("merchant_id", intent.MerchantID.String()),
+			zap.String("origin", req.Origin),
+			zap.String("ip", req.IPAddress),
+		)
+		return &PaymentIntentError{
+			Code:    "ORIGIN_NOT_ALLOWED",
+			Message: "Payments are not accepted from this origin",
+		}
+	}
+
+	requireCaptcha := settings.RequireCaptcha || s.paymentService.cardTesting.RequiresChallenge(intent.MerchantID)
+	if !requireCaptcha || !s.captchaClient.Enabled() {
+		return nil
+	}
+
+	if req.CaptchaToken == "" {
+		return &PaymentIntentError{
+			Code:    "CAPTCHA_REQUIRED",
+			Message: "CAPTCHA verification is required",
+		}
+	}
+
+	ok, err := s.captchaClient.Verify(ctx, req.CaptchaToken, req.IPAddress)
+	if err != nil {
+		logger.Log.Error("CAPTCHA verification error", zap.Error(err))
+	}
+	if !ok {
+		return &PaymentIntentError{
+			Code:    "CAPTCHA_FAILED",
+			Message: "CAPTCHA verification failed",
+		}
+	}
+
+	return nil
+}
+
 // =========================================================================
 // Cancel Payment Intent
 // =========================================================================
 
-func (s *PaymentIntentService) CancelPaymentIntent(ctx context.Context, intentID, merchantID uuid.UUID) error {
+func (s *PaymentIntentService) CancelPaymentIntent(ctx context.Context, intentID, merchantID, actorID uuid.UUID) error {
 	intent, err := s.intentRepo.FindByIDAndMerchant(intentID, merchantID)
 	if err != nil {
 		return fmt.Errorf("payment intent not found: %w", err)
@@ -387,7 +764,7 @@ func (s *PaymentIntentService) CancelPaymentIntent(ctx context.Context, intentID
 	// If already authorized, void the payment
 	if intent.Status == model.PaymentIntentStatusAuthorized && intent.PaymentID.Valid {
 		paymentID, _ := uuid.Parse(intent.PaymentID.String)
-		_, err := s.paymentService.VoidPayment(ctx, paymentID, merchantID, "Payment intent canceled")
+		_, err := s.paymentService.VoidPayment(ctx, paymentID, merchantID, actorID, "Payment intent canceled")
 		if err != nil {
 			logger.Log.Error("Failed to void payment",
 				zap.Error(err),
@@ -397,7 +774,7 @@ func (s *PaymentIntentService) CancelPaymentIntent(ctx context.Context, intentID
 	}
 
 	// Mark intent as canceled
-	if err := s.intentRepo.MarkCanceled(intentID); err != nil {
+	if err := s.intentRepo.WithContext(tenancy.WithMerchant(ctx, merchantID)).MarkCanceled(intentID); err != nil {
 		return err
 	}
 
@@ -419,3 +796,55 @@ func generateClientSecret() (string, error) {
 	}
 	return "pi_secret_" + base64.URLEncoding.EncodeToString(bytes), nil
 }
+
+// signedRedirectURL returns the success redirect for a confirmed intent. If
+// the merchant's signing key cannot be loaded the bare success_url is
+// returned, which the merchant's verification rejects like a forged one.
+func (s *PaymentIntentService) signedRedirectURL(intent *model.PaymentIntent, paymentResp *PaymentResponse) string {
+	secret, err := s.checkoutService.RedirectSecret(intent.MerchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load redirect signing key",
+			zap.String("intent_id", intent.ID.String()),
+			zap.Error(err),
+		)
+		return intent.SuccessURL
+	}
+	return buildSignedRedirectURL(secret, intent, paymentResp)
+}
+
+// buildSignedRedirectURL appends the confirmation outcome to the intent's
+// success_url, signed with HMAC-SHA256 keyed by the merchant's redirect
+// secret. The client secret cannot be the key: the checkout page holds it,
+// so the customer could sign a redirect of their own.
+func buildSignedRedirectURL(secret string, intent *model.PaymentIntent, paymentResp *PaymentResponse) string {
+	redirect, err := url.Parse(intent.SuccessURL)
+	if err != nil {
+		return intent.SuccessURL
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	params := redirect.Query()
+	params.Set("payment_intent", intent.ID.String())
+	params.Set("payment_id", paymentResp.ID.String())
+	params.Set("status", string(paymentResp.Status))
+	params.Set("timestamp", timestamp)
+	params.Set("signature", SignRedirectParams(secret, intent.ID.String(), paymentResp.ID.String(), string(paymentResp.Status), timestamp))
+	redirect.RawQuery = params.Encode()
+
+	return redirect.String()
+}
+
+// SignRedirectParams computes the signature over the success redirect params.
+// Payload format: {payment_intent}.{payment_id}.{status}.{timestamp}
+func SignRedirectParams(secret, intentID, paymentID, status, timestamp string) string {
+	payload := fmt.Sprintf("%s.%s.%s.%s", intentID, paymentID, status, timestamp)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRedirectSignature checks a signature produced by SignRedirectParams
+func VerifyRedirectSignature(secret, intentID, paymentID, status, timestamp, signature string) bool {
+	expected := SignRedirectParams(secret, intentID, paymentID, status, timestamp)
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
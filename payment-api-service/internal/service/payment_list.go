@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+var (
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrInvalidPaymentStatus = errors.New("status must be one of pending, requires_action, authorized, partially_captured, captured, voided, refunded, failed")
+)
+
+var listableStatuses = map[model.PaymentStatus]bool{
+	model.PaymentStatusPending:           true,
+	model.PaymentStatusRequiresAction:    true,
+	model.PaymentStatusAuthorized:        true,
+	model.PaymentStatusPartiallyCaptured: true,
+	model.PaymentStatusCaptured:          true,
+	model.PaymentStatusVoided:            true,
+	model.PaymentStatusRefunded:          true,
+	model.PaymentStatusFailed:            true,
+}
+
+// PaymentListFilter narrows ListPayments. Cursor is the next_cursor of the
+// previous page; DateTo is exclusive.
+type PaymentListFilter struct {
+	Status   string
+	DateFrom *time.Time
+	DateTo   *time.Time
+	Cursor   string
+	Limit    int
+}
+
+// PaymentList is one page of the merchant's payments, newest first.
+// NextCursor is empty on the last page.
+type PaymentList struct {
+	Payments   []*PaymentResponse `json:"payments"`
+	HasMore    bool               `json:"has_more"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// ListPayments pages through the merchant's payments in the mode of the
+// request's API key
+func (s *PaymentService) ListPayments(ctx context.Context, merchantID uuid.UUID, filter PaymentListFilter) (*PaymentList, error) {
+	status := model.PaymentStatus(filter.Status)
+	if status != "" && !listableStatuses[status] {
+		return nil, ErrInvalidPaymentStatus
+	}
+	var after *repository.PaymentCursor
+	if filter.Cursor != "" {
+		cursor, err := decodePaymentCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+	}
+
+	// One extra row tells whether another page follows
+	payments, err := s.paymentRepo.WithContext(ctx).ListPage(merchantID, status, filter.DateFrom, filter.DateTo, after, filter.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &PaymentList{Payments: make([]*PaymentResponse, 0, len(payments))}
+	if len(payments) > filter.Limit {
+		payments = payments[:filter.Limit]
+		last := payments[len(payments)-1]
+		list.HasMore = true
+		list.NextCursor = encodePaymentCursor(last.CreatedAt, last.ID)
+	}
+	for i := range payments {
+		list.Payments = append(list.Payments, s.buildPaymentResponse(&payments[i]))
+	}
+	return list, nil
+}
+
+// Cursors are opaque to clients: the last payment's creation time and ID,
+// base64url encoded
+func encodePaymentCursor(createdAt time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+func decodePaymentCursor(cursor string) (*repository.PaymentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	paymentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.PaymentCursor{CreatedAt: createdAt, ID: paymentID}, nil
+}
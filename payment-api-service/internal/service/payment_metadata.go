@@ -0,0 +1,53 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+)
+
+var ErrInvalidRoutingKey = errors.New("metadata.routing_key must be 1-64 letters, digits, '.', '_', ':' or '-'")
+
+// routingKeyPattern keeps routing keys usable as subscription filters and as
+// keys in the merchant's own dispatch tables
+var routingKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+func validRoutingKey(key string) bool {
+	return routingKeyPattern.MatchString(key)
+}
+
+// encodeMetadata checks the merchant's metadata and encodes it for the jsonb
+// column. Only routing_key has a defined meaning; other keys are stored as sent.
+func encodeMetadata(metadata map[string]interface{}) (sql.NullString, error) {
+	if len(metadata) == 0 {
+		return sql.NullString{}, nil
+	}
+	if value, ok := metadata[model.MetadataRoutingKey]; ok {
+		key, isString := value.(string)
+		if !isString || !validRoutingKey(key) {
+			return sql.NullString{}, ErrInvalidRoutingKey
+		}
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeMetadata is the inverse of encodeMetadata, used when an intent's
+// metadata is carried onto the payment that confirms it
+func decodeMetadata(metadata sql.NullString) map[string]interface{} {
+	if !metadata.Valid || metadata.String == "" {
+		return nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(metadata.String), &decoded); err != nil {
+		return nil
+	}
+	return decoded
+}
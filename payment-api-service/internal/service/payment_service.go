@@ -5,22 +5,37 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 type PaymentService struct {
 	paymentRepo        *repository.PaymentRepository
 	tokenizationClient *client.TokenizationClient
 	fraudClient        *client.FraudClient
+	fraudRules         *FraudRuleEngine
 	transactionClient  *client.TransactionClient
+	cardTesting        *CardTestingDetector
+	intentVelocity     *IntentVelocityTracker
+	lifecycle          *MerchantLifecycleService
+	idempotencyRepo    *repository.IdempotencyRepository
+	idempotencyWindow  time.Duration
+	holdRelease        *HoldReleaseService
+	webhookRepo        *repository.WebhookRepository
+	checkoutRepo       *repository.CheckoutSettingsRepository
+	invoiceRepo        *repository.InvoiceRepository
 }
 
 func NewPaymentService() (*PaymentService, error) {
@@ -34,45 +49,130 @@ func NewPaymentService() (*PaymentService, error) {
 		paymentRepo:        repository.NewPaymentRepository(),
 		tokenizationClient: tokenClient,
 		fraudClient:        client.NewFraudClient(),
+		fraudRules:         NewFraudRuleEngine(),
 		transactionClient:  client.NewTransactionClient(),
+		cardTesting:        NewCardTestingDetector(),
+		intentVelocity:     NewIntentVelocityTracker(),
+		lifecycle:          NewMerchantLifecycleService(),
+		idempotencyRepo:    repository.NewIdempotencyRepository(),
+		idempotencyWindow:  config.GetDurationWithDefault("IDEMPOTENCY_WINDOW", 24*time.Hour),
+		holdRelease:        NewHoldReleaseService(),
+		webhookRepo:        repository.NewWebhookRepository(),
+		checkoutRepo:       repository.NewCheckoutSettingsRepository(),
+		invoiceRepo:        repository.NewInvoiceRepository(),
 	}, nil
 }
 
 // Request/Response DTOs
 type AuthorizePaymentRequest struct {
-	MerchantID     uuid.UUID
-	Amount         int64
-	Currency       string
-	CardNumber     string
-	CardholderName string
-	ExpMonth       int
-	ExpYear        int
-	CVV            string
-	CustomerEmail  string
-	CustomerName   string
-	Description    string
-	Metadata       map[string]interface{}
-	IdempotencyKey string
-	IPAddress      string
-	UserAgent      string
-	CreatedBy      uuid.UUID
+	MerchantID        uuid.UUID
+	Amount            int64
+	Currency          string
+	CardNumber        string
+	CardholderName    string
+	ExpMonth          int
+	ExpYear           int
+	CVV               string
+	CustomerEmail     string
+	CustomerName      string
+	Description       string
+	Metadata          map[string]interface{}
+	IdempotencyKey    string
+	IPAddress         string
+	UserAgent         string
+	CreatedBy         uuid.UUID
+	TestMode          bool      // sandbox payment, made with a pg_test_ key
+	IntentID          uuid.UUID // payment intent being confirmed, if any
+	CheckoutSessionID uuid.UUID // checkout session the intent belongs to, if any
+	Language          string    // customer's language for receipts, already validated
+
+	// StoredCard charges an existing token instead of card details, e.g. for
+	// subscription renewals. The card fields above are ignored when it is set.
+	StoredCard *StoredCard
+
+	// ThreeDSecure authenticates the cardholder before authorization
+	ThreeDSecure *ThreeDSecureRequest
+
+	capture bool // set by SalePayment
+}
+
+// StoredCard is a card tokenized by an earlier request
+type StoredCard struct {
+	Token     string
+	CardBrand string
+	Last4     string
+}
+
+var ErrCardTokenModeMismatch = errors.New("tok_test_ tokens require a test-mode API key, and live tokens a live key")
+
+// ResolveCardToken looks up a token the merchant sent instead of card
+// details. Sandbox tokens only work with test keys and live tokens only with
+// live keys.
+func (s *PaymentService) ResolveCardToken(ctx context.Context, merchantID uuid.UUID, token string, testMode bool) (*StoredCard, error) {
+	if strings.HasPrefix(token, "tok_test_") != testMode {
+		return nil, ErrCardTokenModeMismatch
+	}
+
+	card, err := s.tokenizationClient.GetTokenCard(ctx, token, merchantID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &StoredCard{Token: token, CardBrand: card.Brand, Last4: card.Last4}, nil
 }
 
 type PaymentResponse struct {
-	ID            uuid.UUID           `json:"id"`
-	Status        model.PaymentStatus `json:"status"`
-	Amount        int64               `json:"amount"`
-	Currency      string              `json:"currency"`
-	Token         string              `json:"token,omitempty"`
-	CardBrand     string              `json:"card_brand"`
-	CardLast4     string              `json:"card_last4"`
-	AuthCode      string              `json:"auth_code,omitempty"`
-	FraudScore    int                 `json:"fraud_score"`
-	FraudDecision string              `json:"fraud_decision"`
-	ResponseCode  string              `json:"response_code"`
-	ResponseMsg   string              `json:"response_message"`
-	TransactionID uuid.UUID           `json:"transaction_id,omitempty"`
-	CreatedAt     time.Time           `json:"created_at"`
+	ID             uuid.UUID           `json:"id"`
+	Status         model.PaymentStatus `json:"status"`
+	Amount         int64               `json:"amount"`
+	Currency       string              `json:"currency"`
+	CapturedAmount int64               `json:"captured_amount"`
+	Token          string              `json:"token,omitempty"`
+	CardBrand      string              `json:"card_brand"`
+	CardLast4      string              `json:"card_last4"`
+	AuthCode       string              `json:"auth_code,omitempty"`
+	FraudScore     int                 `json:"fraud_score"`
+	FraudDecision  string              `json:"fraud_decision"`
+	FraudReasons   []string            `json:"fraud_reasons,omitempty"`
+	ResponseCode   string              `json:"response_code"`
+	ResponseMsg    string              `json:"response_message"`
+	TransactionID  uuid.UUID           `json:"transaction_id,omitempty"`
+	RedirectURL    string              `json:"redirect_url,omitempty"`
+	Refund         *RefundDetails      `json:"refund,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+
+	// Set when an authorization is declined, so integrations only retry
+	// declines that can still be approved. SuggestedRetryAfter is in seconds.
+	RetryAllowed        *bool `json:"retry_allowed,omitempty"`
+	SuggestedRetryAfter int64 `json:"suggested_retry_after,omitempty"`
+
+	ThreeDSecure *model.PaymentThreeDS `json:"three_d_secure,omitempty"`
+	NextAction   *NextAction           `json:"next_action,omitempty"` // Set while requires_action
+}
+
+// RefundDetails tracks a refund until it reaches the cardholder
+type RefundDetails struct {
+	ID                 string `json:"id"`
+	PaymentID          string `json:"payment_id,omitempty"`
+	Amount             int64  `json:"amount"`
+	Currency           string `json:"currency,omitempty"`
+	Status             string `json:"status"` // queued, requested, sent_to_issuer, settled, failed
+	Reason             string `json:"reason,omitempty"`
+	ReasonCode         string `json:"reason_code,omitempty"`
+	RequestedAt        string `json:"requested_at,omitempty"`
+	SentToIssuerAt     string `json:"sent_to_issuer_at,omitempty"`
+	SettledAt          string `json:"settled_at,omitempty"`
+	EstimatedArrivalAt string `json:"estimated_arrival_at,omitempty"` // YYYY-MM-DD
+}
+
+// RefundQueueStatus reports the merchant's refunds waiting for the acquirer;
+// sent and failed cover the last 24 hours
+type RefundQueueStatus struct {
+	Queued                int64  `json:"queued"`
+	Processing            int64  `json:"processing"`
+	Sent                  int64  `json:"sent"`
+	Failed                int64  `json:"failed"`
+	OldestQueuedAt        string `json:"oldest_queued_at,omitempty"`
+	EstimatedDrainSeconds int64  `json:"estimated_drain_seconds"`
 }
 
 func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePaymentRequest) (*PaymentResponse, error) {
@@ -84,9 +184,14 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 	)
 
 	// Step 1: Check idempotency
+	var requestHash string
 	if req.IdempotencyKey != "" {
-		existing, err := s.paymentRepo.FindByIdempotencyKey(req.MerchantID, req.IdempotencyKey)
-		if err == nil && existing != nil {
+		requestHash = idempotencyRequestHash(req)
+		existing, err := s.findIdempotentPayment(req, requestHash)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
 			logger.Log.Info("Returning cached payment (idempotency)",
 				zap.String("payment_id", existing.ID.String()),
 			)
@@ -94,33 +199,118 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 		}
 	}
 
-	// Step 2: Tokenize card
-	tokenResp, err := s.tokenizationClient.TokenizeCard(ctx, &pb.TokenizeCardRequest{
-		MerchantId:     req.MerchantID.String(),
-		CardNumber:     req.CardNumber,
-		CardholderName: req.CardholderName,
-		ExpMonth:       int32(req.ExpMonth),
-		ExpYear:        int32(req.ExpYear),
-		Cvv:            req.CVV,
-		IsSingleUse:    false,
-		IpAddress:      req.IPAddress,
-		UserAgent:      req.UserAgent,
-	})
+	metadata, err := encodeMetadata(req.Metadata)
 	if err != nil {
-		logger.Log.Error("Tokenization failed", zap.Error(err))
-		return nil, fmt.Errorf("failed to tokenize card: %w", err)
+		return nil, err
+	}
+
+	// Step 1a: Closing merchants take no new payments
+	if err := s.lifecycle.CheckCanAcceptPayments(req.MerchantID); err != nil {
+		return nil, err
+	}
+
+	// Step 1b: Brands the merchant does not take are turned away before
+	// the card is vaulted
+	brand := cardBrandOf(req.CardNumber)
+	if req.StoredCard != nil {
+		brand = req.StoredCard.CardBrand
+	}
+	if err := s.checkCardBrand(req.MerchantID, brand); err != nil {
+		return nil, err
+	}
+
+	// Step 1c: Stricter limits while card-testing protection is active
+	bin := cardBIN(req.CardNumber)
+	if err := s.cardTesting.CheckThrottle(req.MerchantID, req.IPAddress, bin); err != nil {
+		logger.Log.Warn("Payment blocked by card-testing throttle",
+			zap.String("merchant_id", req.MerchantID.String()),
+			zap.String("ip", req.IPAddress),
+		)
+		return nil, err
+	}
+
+	// Step 2: Tokenize card while the fraud service gathers the features
+	// that do not depend on it
+	var (
+		tokenResp     *client.TokenizeCardResponse
+		fraudFeatures *client.FraudFeatures
+		timings       model.PaymentTimings
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		if card := req.StoredCard; card != nil {
+			tokenResp = &client.TokenizeCardResponse{Token: card.Token, CardBrand: card.CardBrand, Last4: card.Last4}
+			return nil
+		}
+		stageStart := time.Now()
+		resp, err := s.tokenizationClient.TokenizeCard(gctx, &pb.TokenizeCardRequest{
+			MerchantId:     req.MerchantID.String(),
+			CardNumber:     req.CardNumber,
+			CardholderName: req.CardholderName,
+			ExpMonth:       int32(req.ExpMonth),
+			ExpYear:        int32(req.ExpYear),
+			Cvv:            req.CVV,
+			IsSingleUse:    false,
+			IpAddress:      req.IPAddress,
+			UserAgent:      req.UserAgent,
+			TestMode:       req.TestMode,
+		})
+		timings.TokenizeMs = time.Since(stageStart).Milliseconds()
+		if err != nil {
+			logger.Log.Error("Tokenization failed", zap.Error(err))
+			return fmt.Errorf("failed to tokenize card: %w", err)
+		}
+		tokenResp = resp
+		return nil
+	})
+	g.Go(func() error {
+		stageStart := time.Now()
+		features, err := s.fraudClient.GatherFeatures(gctx, &client.FraudFeatureRequest{
+			MerchantID:    req.MerchantID.String(),
+			Amount:        req.Amount,
+			Currency:      req.Currency,
+			CustomerEmail: req.CustomerEmail,
+			CustomerIP:    req.IPAddress,
+		})
+		timings.FraudFeaturesMs = time.Since(stageStart).Milliseconds()
+		if err != nil {
+			// CheckFraud gathers them itself; never fail the payment here
+			logger.Log.Warn("Fraud feature gathering failed", zap.Error(err))
+			return nil
+		}
+		fraudFeatures = features
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Step 2a: Cards tried across attempts on the same payment intent
+	var velocity *client.IntentVelocity
+	if req.IntentID != uuid.Nil {
+		velocity = s.intentVelocity.Record(req.IntentID, tokenResp.Fingerprint)
+		if s.intentVelocity.Exceeded(velocity) {
+			logger.Log.Warn("Payment intent churning through cards",
+				zap.String("intent_id", req.IntentID.String()),
+				zap.Int("distinct_cards", velocity.DistinctCards),
+			)
+			return nil, ErrIntentCardChurn
+		}
 	}
 
 	// Step 3: Fraud check
+	stageStart := time.Now()
 	fraudResp, err := s.fraudClient.CheckFraud(ctx, &client.FraudCheckRequest{
-		MerchantID:    req.MerchantID.String(),
-		Amount:        req.Amount,
-		Currency:      req.Currency,
-		CardToken:     tokenResp.Token,
-		CardBrand:     tokenResp.CardBrand,
-		CardLast4:     tokenResp.Last4,
-		CustomerEmail: req.CustomerEmail,
-		CustomerIP:    req.IPAddress,
+		MerchantID:     req.MerchantID.String(),
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		CardToken:      tokenResp.Token,
+		CardBrand:      tokenResp.CardBrand,
+		CardLast4:      tokenResp.Last4,
+		CustomerEmail:  req.CustomerEmail,
+		CustomerIP:     req.IPAddress,
+		IntentVelocity: velocity,
+		Features:       fraudFeatures,
 	})
 	if err != nil {
 		logger.Log.Error("Fraud check failed", zap.Error(err))
@@ -131,51 +321,46 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 		}
 	}
 
+	// Step 3b: The merchant's fraud rules turn the score into a decision
+	ruleDecision, err := s.fraudRules.Evaluate(&FraudRuleSubject{
+		MerchantID:      req.MerchantID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		CardToken:       tokenResp.Token,
+		CardFingerprint: tokenResp.Fingerprint,
+		BIN:             cardPrefix(req.CardNumber, 8),
+		Email:           req.CustomerEmail,
+		IP:              req.IPAddress,
+		RiskScore:       fraudResp.RiskScore,
+	})
+	timings.FraudScoreMs = time.Since(stageStart).Milliseconds()
+	if err != nil {
+		// Keep the fraud service's own decision
+		logger.Log.Error("Fraud rules evaluation failed", zap.Error(err))
+	} else {
+		fraudResp.Decision = string(ruleDecision.Decision)
+		fraudResp.ReasonCodes = ruleDecision.ReasonCodes
+	}
+
 	// Step 4: Check fraud decision
 	if fraudResp.Decision == "decline" {
 		logger.Log.Warn("Payment declined by fraud system",
 			zap.Int("risk_score", fraudResp.RiskScore),
+			zap.Strings("reason_codes", fraudResp.ReasonCodes),
 		)
-		return s.createFailedPayment(req, tokenResp, fraudResp, "Declined by fraud detection")
-	}
-
-	// Step 5: Authorize transaction
-	authResp, err := s.transactionClient.Authorize(ctx, &pb.AuthorizeRequest{
-		MerchantId:    req.MerchantID.String(),
-		Amount:        req.Amount,
-		Currency:      req.Currency,
-		CardToken:     tokenResp.Token,
-		CardBrand:     tokenResp.CardBrand,
-		CardLast4:     tokenResp.Last4,
-		FraudScore:    int32(fraudResp.RiskScore),
-		CustomerEmail: req.CustomerEmail,
-		Description:   req.Description,
-	})
-	if err != nil {
-		logger.Log.Error("Transaction authorization failed", zap.Error(err))
-		return nil, fmt.Errorf("authorization failed: %w", err)
-	}
-	if authResp.TransactionId == "" {
-		logger.Log.Error("Transaction service returned empty transaction_id",
-			zap.Bool("approved", authResp.Approved),
-			zap.String("merchant_id", req.MerchantID.String()),
-		)
-		return nil, fmt.Errorf("transaction service did not return transaction_id")
+		if req.StoredCard == nil {
+			s.cardTesting.Record(req.MerchantID, req.IPAddress, bin, req.Amount, true)
+		}
+		timings.TotalMs = time.Since(startTime).Milliseconds()
+		return s.createFailedPayment(req, tokenResp, fraudResp, timings, "Declined by fraud detection")
 	}
 
-	txID, err := uuid.Parse(authResp.TransactionId)
-	if err != nil {
-		logger.Log.Error("Invalid transaction_id returned by transaction service",
-			zap.String("transaction_id", authResp.TransactionId),
-			zap.Error(err),
-		)
-		return nil, fmt.Errorf("invalid transaction_id from transaction service")
-	}
-	// Step 6: Create payment record
+	// Step 5: Build the payment record
 	payment := &model.Payment{
 		MerchantID:    req.MerchantID,
-		TransactionID: txID,
+		TestMode:      req.TestMode,
 		Type:          model.PaymentTypeAuthorize,
+		Status:        model.PaymentStatusPending,
 		Amount:        req.Amount,
 		Currency:      req.Currency,
 		Token:         tokenResp.Token,
@@ -183,8 +368,14 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 		CardLast4:     tokenResp.Last4,
 		FraudScore:    fraudResp.RiskScore,
 		FraudDecision: fraudResp.Decision,
+		FraudReasons:  strings.Join(fraudResp.ReasonCodes, ","),
 		IPAddress:     req.IPAddress,
 		CreatedBy:     req.CreatedBy,
+		Language:      req.Language,
+		Metadata:      metadata,
+		Timings:       timings,
+
+		CaptureAfterAuthentication: req.capture,
 	}
 
 	// Set customer info
@@ -197,32 +388,56 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 	if req.Description != "" {
 		payment.Description = sql.NullString{String: req.Description, Valid: true}
 	}
+	if req.CheckoutSessionID != uuid.Nil {
+		payment.CheckoutSessionID = sql.NullString{String: req.CheckoutSessionID.String(), Valid: true}
+	}
 	if req.UserAgent != "" {
 		payment.UserAgent = sql.NullString{String: req.UserAgent, Valid: true}
 	}
 	if req.IdempotencyKey != "" {
 		payment.IdempotencyKey = sql.NullString{String: req.IdempotencyKey, Valid: true}
+		payment.IdempotencyHash = requestHash
 	}
 
-	if authResp.Approved {
-		payment.Status = model.PaymentStatusAuthorized
-		payment.AuthCode = sql.NullString{String: authResp.AuthCode, Valid: true}
-		payment.ResponseCode = sql.NullString{String: authResp.ResponseCode, Valid: true}
-		payment.ResponseMsg = sql.NullString{String: authResp.ResponseMessage, Valid: true}
-	} else {
-		payment.Status = model.PaymentStatusFailed
-		payment.ResponseCode = sql.NullString{String: authResp.ResponseCode, Valid: true}
-		payment.ResponseMsg = sql.NullString{String: authResp.DeclineReason, Valid: true}
+	// Step 6: 3-D Secure, when the merchant asks for it. A challenge or a
+	// failed authentication stops the payment before the issuer sees it.
+	if req.ThreeDSecure != nil {
+		stageStart = time.Now()
+		s.authenticateCard(ctx, payment, req.ThreeDSecure)
+		payment.Timings.AuthenticateMs = time.Since(stageStart).Milliseconds()
 	}
 
-	// Save payment
-	if err := s.paymentRepo.Create(payment); err != nil {
+	// Step 7: Authorize transaction
+	var authResp *pb.AuthorizeResponse
+	if payment.Status == model.PaymentStatusPending {
+		stageStart = time.Now()
+		authResp, err = s.authorizeWithIssuer(ctx, payment)
+		payment.Timings.AuthorizeMs = time.Since(stageStart).Milliseconds()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Save payment. The issuer may have answered, so it is recorded even
+	// if the caller has gone away.
+	payment.Timings.TotalMs = time.Since(startTime).Milliseconds()
+	if err := s.paymentRepo.WithContext(context.WithoutCancel(ctx)).Create(payment); err != nil {
 		logger.Log.Error("Failed to save payment", zap.Error(err))
 		return nil, fmt.Errorf("failed to save payment: %w", err)
 	}
+	metrics.PaymentAuthorized(string(payment.Status), payment.Currency)
+	if req.IdempotencyKey != "" {
+		s.cacheIdempotencyKey(req, payment.ID, requestHash)
+	}
+
+	// Stored-card renewals are not card testing, and their declines would
+	// otherwise count against the merchant
+	if req.StoredCard == nil && payment.Status != model.PaymentStatusRequiresAction {
+		s.cardTesting.Record(req.MerchantID, req.IPAddress, bin, req.Amount, payment.Status == model.PaymentStatusFailed)
+	}
 
 	// Log event
-	go s.paymentRepo.CreateEvent(&model.PaymentEvent{
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
 		PaymentID: payment.ID,
 		EventType: string(payment.Type),
 		OldStatus: model.PaymentStatusPending,
@@ -235,22 +450,34 @@ func (s *PaymentService) AuthorizePayment(ctx context.Context, req *AuthorizePay
 		zap.String("payment_id", payment.ID.String()),
 		zap.String("status", string(payment.Status)),
 		zap.Duration("processing_time", time.Since(startTime)),
+		zap.Int64("tokenize_ms", payment.Timings.TokenizeMs),
+		zap.Int64("fraud_features_ms", payment.Timings.FraudFeaturesMs),
+		zap.Int64("fraud_score_ms", payment.Timings.FraudScoreMs),
+		zap.Int64("authenticate_ms", payment.Timings.AuthenticateMs),
+		zap.Int64("authorize_ms", payment.Timings.AuthorizeMs),
 	)
 
-	return s.buildPaymentResponse(payment), nil
+	resp := s.buildPaymentResponse(payment)
+	if authResp != nil && !authResp.Approved {
+		resp.RetryAllowed = &authResp.RetryAllowed
+		resp.SuggestedRetryAfter = authResp.SuggestedRetryAfterSeconds
+	}
+	return resp, nil
 }
 
 // Sale (Authorize + Capture)
 func (s *PaymentService) SalePayment(ctx context.Context, req *AuthorizePaymentRequest) (*PaymentResponse, error) {
-	// First authorize
-	authResp, err := s.AuthorizePayment(ctx, req)
+	// First authorize; a challenged sale is captured once authenticated
+	saleReq := *req
+	saleReq.capture = true
+	authResp, err := s.AuthorizePayment(ctx, &saleReq)
 	if err != nil {
 		return nil, err
 	}
 
 	// If authorized, immediately capture
 	if authResp.Status == model.PaymentStatusAuthorized {
-		captureResp, err := s.CapturePayment(ctx, authResp.ID, req.MerchantID, authResp.Amount)
+		captureResp, err := s.CapturePayment(ctx, authResp.ID, req.MerchantID, req.CreatedBy, authResp.Amount, authResp.Currency, true)
 		if err != nil {
 			logger.Log.Error("Auto-capture failed", zap.Error(err))
 			return authResp, nil
@@ -261,57 +488,93 @@ func (s *PaymentService) SalePayment(ctx context.Context, req *AuthorizePaymentR
 	return authResp, nil
 }
 
-// Capture Payment
-func (s *PaymentService) CapturePayment(ctx context.Context, paymentID, merchantID uuid.UUID, amount int64) (*PaymentResponse, error) {
+// Capture Payment; currency must be the payment's. finalCapture closes a
+// multi-capture authorization and releases what is left of it; for other
+// merchants every capture is final.
+func (s *PaymentService) CapturePayment(ctx context.Context, paymentID, merchantID, actorID uuid.UUID, amount int64, currency string, finalCapture bool) (*PaymentResponse, error) {
 	// Get payment
-	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("payment not found: %w", err)
 	}
 
 	// Validate can capture
 	if !payment.CanCapture() {
-		return nil, errors.New("payment cannot be captured (not in authorized state)")
+		return nil, errors.New("payment cannot be captured (not authorized or partially captured)")
+	}
+	if err := checkPaymentCurrency(payment, currency); err != nil {
+		return nil, err
 	}
 
 	// Capture via transaction service
-	_, err = s.transactionClient.Capture(ctx, &pb.CaptureRequest{
+	captureResp, err := s.transactionClient.Capture(ctx, &pb.CaptureRequest{
 		TransactionId: payment.TransactionID.String(),
 		MerchantId:    payment.MerchantID.String(),
 		Amount:        amount,
+		FinalCapture:  finalCapture,
+		Currency:      payment.Currency,
 	})
+	metrics.PaymentOperation("capture", err)
 	if err != nil {
 		return nil, fmt.Errorf("capture failed: %w", err)
 	}
 
-	// Update payment status
-	if err := s.paymentRepo.MarkCaptured(paymentID); err != nil {
+	// Mirror the authorization's state: it stays open until the transaction
+	// service reports it captured
+	status := model.PaymentStatusCaptured
+	if captureResp.Status == string(model.PaymentStatusPartiallyCaptured) {
+		status = model.PaymentStatusPartiallyCaptured
+	}
+	// The transaction service has acted, so record it even if the caller
+	// has gone away
+	scoped := s.paymentRepo.WithContext(tenancy.WithMerchant(context.WithoutCancel(ctx), merchantID))
+	if err := scoped.MarkCaptured(paymentID, status, captureResp.TotalCapturedAmount); err != nil {
 		return nil, err
 	}
 
 	// Log event
-	go s.paymentRepo.CreateEvent(&model.PaymentEvent{
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
 		PaymentID: paymentID,
 		EventType: "captured",
-		OldStatus: model.PaymentStatusAuthorized,
-		NewStatus: model.PaymentStatusCaptured,
+		OldStatus: payment.Status,
+		NewStatus: status,
 		Amount:    amount,
+		CreatedBy: actorID,
 	})
 
+	// An invoice collected through a separately captured intent is paid
+	// now; one captured during confirmation is marked by the intent
+	if status == model.PaymentStatusCaptured {
+		paid, err := s.invoiceRepo.WithContext(tenancy.WithMerchant(context.WithoutCancel(ctx), merchantID)).
+			MarkPaidByPayment(paymentID, merchantID)
+		if err != nil {
+			logger.Log.Error("Failed to mark invoice paid",
+				zap.String("payment_id", paymentID.String()),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to mark invoice paid: %w", err)
+		}
+		if paid {
+			logger.Log.Info("Invoice paid", zap.String("payment_id", paymentID.String()))
+		}
+	}
+
 	// Refresh payment
-	payment, _ = s.paymentRepo.FindByID(paymentID)
+	payment, _ = scoped.FindByID(paymentID)
 
 	logger.Log.Info("Payment captured",
 		zap.String("payment_id", paymentID.String()),
 		zap.Int64("amount", amount),
+		zap.Int64("total_captured", captureResp.TotalCapturedAmount),
+		zap.String("status", string(status)),
 	)
 
 	return s.buildPaymentResponse(payment), nil
 }
 
 // Void Payment
-func (s *PaymentService) VoidPayment(ctx context.Context, paymentID, merchantID uuid.UUID, reason string) (*PaymentResponse, error) {
-	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+func (s *PaymentService) VoidPayment(ctx context.Context, paymentID, merchantID, actorID uuid.UUID, reason string) (*PaymentResponse, error) {
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("payment not found: %w", err)
 	}
@@ -326,37 +589,48 @@ func (s *PaymentService) VoidPayment(ctx context.Context, paymentID, merchantID
 		MerchantId:    payment.MerchantID.String(),
 		Reason:        reason,
 	})
+	metrics.PaymentOperation("void", err)
 	if err != nil {
 		return nil, fmt.Errorf("void failed: %w", err)
 	}
 
 	// Update status
-	if err := s.paymentRepo.MarkVoided(paymentID); err != nil {
+	// The transaction service has acted, so record it even if the caller
+	// has gone away
+	scoped := s.paymentRepo.WithContext(tenancy.WithMerchant(context.WithoutCancel(ctx), merchantID))
+	if err := scoped.MarkVoided(paymentID); err != nil {
 		return nil, err
 	}
 
 	// Log event
-	go s.paymentRepo.CreateEvent(&model.PaymentEvent{
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
 		PaymentID:   paymentID,
 		EventType:   "voided",
 		OldStatus:   payment.Status,
 		NewStatus:   model.PaymentStatusVoided,
 		Description: sql.NullString{String: reason, Valid: true},
+		CreatedBy:   actorID,
 	})
 
-	payment, _ = s.paymentRepo.FindByID(paymentID)
+	payment, _ = scoped.FindByID(paymentID)
 
 	logger.Log.Info("Payment voided",
 		zap.String("payment_id", paymentID.String()),
 		zap.String("reason", reason),
 	)
 
+	go s.holdRelease.NotifyHoldReleased(paymentID, merchantID, model.HoldReleaseVoided)
+
 	return s.buildPaymentResponse(payment), nil
 }
 
-// Refund Payment
-func (s *PaymentService) RefundPayment(ctx context.Context, paymentID, merchantID uuid.UUID, amount int64, reason string) (*PaymentResponse, error) {
-	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+// Refund Payment; currency must be the payment's
+func (s *PaymentService) RefundPayment(ctx context.Context, paymentID, merchantID, actorID uuid.UUID, amount int64, currency, reason, reasonCode string) (*PaymentResponse, error) {
+	if err := checkRefundReasonCode(reasonCode); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
 	if err != nil {
 		return nil, fmt.Errorf("payment not found: %w", err)
 	}
@@ -364,55 +638,281 @@ func (s *PaymentService) RefundPayment(ctx context.Context, paymentID, merchantI
 	if !payment.CanRefund() {
 		return nil, errors.New("payment cannot be refunded (not captured)")
 	}
+	if err := checkPaymentCurrency(payment, currency); err != nil {
+		return nil, err
+	}
+
+	if err := s.lifecycle.CheckCanRefund(merchantID); err != nil {
+		return nil, err
+	}
 
 	// Refund via transaction service
-	_, err = s.transactionClient.Refund(ctx, &pb.RefundRequest{
+	refundResp, err := s.transactionClient.Refund(ctx, &pb.RefundRequest{
 		TransactionId: payment.TransactionID.String(),
 		MerchantId:    payment.MerchantID.String(),
 		Amount:        amount,
 		Reason:        reason,
+		ReasonCode:    reasonCode,
+		Currency:      payment.Currency,
 	})
+	metrics.PaymentOperation("refund", err)
 	if err != nil {
 		return nil, fmt.Errorf("refund failed: %w", err)
 	}
 
 	// Update status
-	if err := s.paymentRepo.MarkRefunded(paymentID); err != nil {
+	// The transaction service has acted, so record it even if the caller
+	// has gone away
+	scoped := s.paymentRepo.WithContext(tenancy.WithMerchant(context.WithoutCancel(ctx), merchantID))
+	if err := scoped.MarkRefunded(paymentID); err != nil {
 		return nil, err
 	}
 
 	// Log event
-	go s.paymentRepo.CreateEvent(&model.PaymentEvent{
+	s.paymentRepo.CreateEvent(&model.PaymentEvent{
 		PaymentID:   paymentID,
 		EventType:   "refunded",
 		OldStatus:   payment.Status,
 		NewStatus:   model.PaymentStatusRefunded,
 		Amount:      amount,
 		Description: sql.NullString{String: reason, Valid: true},
+		CreatedBy:   actorID,
 	})
 
-	payment, _ = s.paymentRepo.FindByID(paymentID)
+	payment, _ = scoped.FindByID(paymentID)
 
 	logger.Log.Info("Payment refunded",
 		zap.String("payment_id", paymentID.String()),
 		zap.Int64("amount", amount),
 	)
 
-	return s.buildPaymentResponse(payment), nil
+	resp := s.buildPaymentResponse(payment)
+	resp.Refund = &RefundDetails{
+		ID:                 refundResp.RefundId,
+		PaymentID:          paymentID.String(),
+		Amount:             refundResp.RefundedAmount,
+		Currency:           payment.Currency,
+		Status:             refundResp.RefundStatus,
+		Reason:             reason,
+		ReasonCode:         reasonCode,
+		EstimatedArrivalAt: refundResp.EstimatedArrivalAt,
+	}
+	return resp, nil
+}
+
+// GetRefund returns a refund's progress and estimated arrival date
+func (s *PaymentService) GetRefund(ctx context.Context, refundID, merchantID uuid.UUID) (*RefundDetails, error) {
+	refund, err := s.transactionClient.GetRefund(ctx, &pb.GetRefundRequest{
+		RefundId:   refundID.String(),
+		MerchantId: merchantID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := refundDetailsFromProto(refund)
+	if txnID, err := uuid.Parse(refund.TransactionId); err == nil {
+		if payment, err := s.paymentRepo.WithContext(ctx).FindByTransactionID(txnID, merchantID); err == nil {
+			details.PaymentID = payment.ID.String()
+		}
+	}
+	return details, nil
+}
+
+// ListPaymentRefunds returns every refund issued against a payment
+func (s *PaymentService) ListPaymentRefunds(ctx context.Context, paymentID, merchantID uuid.UUID) ([]*RefundDetails, error) {
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("payment not found: %w", err)
+	}
+	if payment.TransactionID == uuid.Nil {
+		return []*RefundDetails{}, nil
+	}
+
+	refunds, err := s.transactionClient.ListRefunds(ctx, &pb.ListRefundsRequest{
+		TransactionId: payment.TransactionID.String(),
+		MerchantId:    merchantID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*RefundDetails, len(refunds))
+	for i, refund := range refunds {
+		details[i] = refundDetailsFromProto(refund)
+		details[i].PaymentID = paymentID.String()
+	}
+	return details, nil
+}
+
+// RefundListFilter narrows GET /refunds; zero fields match everything
+type RefundListFilter struct {
+	PaymentID  *uuid.UUID
+	Status     string
+	ReasonCode string
+	DateFrom   *time.Time
+	DateTo     *time.Time
+	TestMode   bool // Sandbox refunds instead of live ones
+	Limit      int
+	Offset     int
+}
+
+// RefundList is one page of the merchant's refunds
+type RefundList struct {
+	Refunds []*RefundDetails `json:"refunds"`
+	Total   int64            `json:"total"`
+	HasMore bool             `json:"has_more"`
+}
+
+// ListRefunds pages through the merchant's refunds, newest first
+func (s *PaymentService) ListRefunds(ctx context.Context, merchantID uuid.UUID, filter RefundListFilter) (*RefundList, error) {
+	if err := checkRefundReasonCode(filter.ReasonCode); err != nil {
+		return nil, err
+	}
+
+	req := &pb.ListMerchantRefundsRequest{
+		MerchantId: merchantID.String(),
+		Status:     filter.Status,
+		ReasonCode: filter.ReasonCode,
+		TestMode:   filter.TestMode,
+		Limit:      int32(filter.Limit),
+		Offset:     int32(filter.Offset),
+	}
+	if filter.PaymentID != nil {
+		payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(*filter.PaymentID, merchantID)
+		if err != nil {
+			return nil, fmt.Errorf("payment not found: %w", err)
+		}
+		if payment.TransactionID == uuid.Nil {
+			return &RefundList{Refunds: []*RefundDetails{}}, nil
+		}
+		req.TransactionId = payment.TransactionID.String()
+	}
+	if filter.DateFrom != nil {
+		req.CreatedFrom = filter.DateFrom.UTC().Format(time.RFC3339)
+	}
+	if filter.DateTo != nil {
+		req.CreatedTo = filter.DateTo.UTC().Format(time.RFC3339)
+	}
+
+	resp, err := s.transactionClient.ListMerchantRefunds(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refunds point at transactions; resolve each to its payment once
+	paymentIDs := make(map[string]string)
+	details := make([]*RefundDetails, len(resp.Refunds))
+	for i, refund := range resp.Refunds {
+		details[i] = refundDetailsFromProto(refund)
+		paymentID, seen := paymentIDs[refund.TransactionId]
+		if !seen {
+			if txnID, err := uuid.Parse(refund.TransactionId); err == nil {
+				if payment, err := s.paymentRepo.WithContext(ctx).FindByTransactionID(txnID, merchantID); err == nil {
+					paymentID = payment.ID.String()
+				}
+			}
+			paymentIDs[refund.TransactionId] = paymentID
+		}
+		details[i].PaymentID = paymentID
+	}
+
+	return &RefundList{
+		Refunds: details,
+		Total:   resp.Total,
+		HasMore: resp.HasMore,
+	}, nil
+}
+
+// GetRefundQueueStatus reports how the merchant's queued refunds are draining
+func (s *PaymentService) GetRefundQueueStatus(ctx context.Context, merchantID uuid.UUID) (*RefundQueueStatus, error) {
+	status, err := s.transactionClient.GetRefundQueueStatus(ctx, &pb.GetRefundQueueStatusRequest{
+		MerchantId: merchantID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefundQueueStatus{
+		Queued:                status.Queued,
+		Processing:            status.Processing,
+		Sent:                  status.Sent,
+		Failed:                status.Failed,
+		OldestQueuedAt:        status.OldestQueuedAt,
+		EstimatedDrainSeconds: status.EstimatedDrainSeconds,
+	}, nil
+}
+
+func refundDetailsFromProto(refund *pb.RefundDetailResponse) *RefundDetails {
+	return &RefundDetails{
+		ID:                 refund.RefundId,
+		Amount:             refund.Amount,
+		Currency:           refund.Currency,
+		Status:             refund.Status,
+		Reason:             refund.Reason,
+		ReasonCode:         refund.ReasonCode,
+		RequestedAt:        refund.RequestedAt,
+		SentToIssuerAt:     refund.SentToIssuerAt,
+		SettledAt:          refund.SettledAt,
+		EstimatedArrivalAt: refund.EstimatedArrivalAt,
+	}
 }
 
 // =========================================================================
 // Helper Methods
 // =========================================================================
 
+// ErrInvalidRefundReasonCode rejects reason codes the transaction service
+// does not know, before a refund is queued or held for approval
+var ErrInvalidRefundReasonCode = errors.New("reason_code must be one of duplicate, fraudulent, requested_by_customer, product_not_received, product_unacceptable, other")
+
+var refundReasonCodes = map[string]bool{
+	"duplicate":             true,
+	"fraudulent":            true,
+	"requested_by_customer": true,
+	"product_not_received":  true,
+	"product_unacceptable":  true,
+	"other":                 true,
+}
+
+// checkRefundReasonCode accepts an empty code, which the transaction
+// service records as other
+func checkRefundReasonCode(code string) error {
+	if code != "" && !refundReasonCodes[code] {
+		return ErrInvalidRefundReasonCode
+	}
+	return nil
+}
+
+// Capture and refund amounts are in the payment's currency. Requests must
+// name it, so an amount meant for another currency is rejected rather than
+// captured or refunded in the wrong units.
+var (
+	ErrCurrencyRequired = client.ErrCurrencyRequired
+	ErrCurrencyMismatch = client.ErrCurrencyMismatch
+)
+
+func checkPaymentCurrency(payment *model.Payment, currency string) error {
+	currency = strings.TrimSpace(currency)
+	if currency == "" {
+		return ErrCurrencyRequired
+	}
+	if !strings.EqualFold(currency, payment.Currency) {
+		return fmt.Errorf("%w: payment is in %s, request is in %s", ErrCurrencyMismatch, payment.Currency, strings.ToUpper(currency))
+	}
+	return nil
+}
+
 func (s *PaymentService) createFailedPayment(
 	req *AuthorizePaymentRequest,
 	tokenResp *client.TokenizeCardResponse,
 	fraudResp *client.FraudCheckResponse,
+	timings model.PaymentTimings,
 	reason string,
 ) (*PaymentResponse, error) {
 	payment := &model.Payment{
 		MerchantID:    req.MerchantID,
+		TestMode:      req.TestMode,
 		Type:          model.PaymentTypeAuthorize,
 		Status:        model.PaymentStatusFailed,
 		Amount:        req.Amount,
@@ -422,31 +922,45 @@ func (s *PaymentService) createFailedPayment(
 		CardLast4:     tokenResp.Last4,
 		FraudScore:    fraudResp.RiskScore,
 		FraudDecision: fraudResp.Decision,
+		FraudReasons:  strings.Join(fraudResp.ReasonCodes, ","),
 		ResponseMsg:   sql.NullString{String: reason, Valid: true},
 		IPAddress:     req.IPAddress,
 		CreatedBy:     req.CreatedBy,
+		Language:      req.Language,
+		Timings:       timings,
+	}
+	// Already validated by AuthorizePayment
+	payment.Metadata, _ = encodeMetadata(req.Metadata)
+	if req.CheckoutSessionID != uuid.Nil {
+		payment.CheckoutSessionID = sql.NullString{String: req.CheckoutSessionID.String(), Valid: true}
 	}
 
 	if err := s.paymentRepo.Create(payment); err != nil {
 		return nil, err
 	}
 
-	return s.buildPaymentResponse(payment), nil
+	// A fraud decline would be declined again for the same card and amount
+	retryAllowed := false
+	resp := s.buildPaymentResponse(payment)
+	resp.RetryAllowed = &retryAllowed
+	return resp, nil
 }
 
 func (s *PaymentService) buildPaymentResponse(payment *model.Payment) *PaymentResponse {
 	resp := &PaymentResponse{
-		ID:            payment.ID,
-		Status:        payment.Status,
-		Amount:        payment.Amount,
-		Currency:      payment.Currency,
-		Token:         payment.Token,
-		CardBrand:     payment.CardBrand,
-		CardLast4:     payment.CardLast4,
-		FraudScore:    payment.FraudScore,
-		FraudDecision: payment.FraudDecision,
-		TransactionID: payment.TransactionID,
-		CreatedAt:     payment.CreatedAt,
+		ID:             payment.ID,
+		Status:         payment.Status,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		CapturedAmount: payment.CapturedAmount,
+		Token:          payment.Token,
+		CardBrand:      payment.CardBrand,
+		CardLast4:      payment.CardLast4,
+		FraudScore:     payment.FraudScore,
+		FraudDecision:  payment.FraudDecision,
+		FraudReasons:   payment.FraudReasonList(),
+		TransactionID:  payment.TransactionID,
+		CreatedAt:      payment.CreatedAt,
 	}
 
 	if payment.AuthCode.Valid {
@@ -458,12 +972,22 @@ func (s *PaymentService) buildPaymentResponse(payment *model.Payment) *PaymentRe
 	if payment.ResponseMsg.Valid {
 		resp.ResponseMsg = payment.ResponseMsg.String
 	}
+	if payment.ThreeDS.Status != "" {
+		threeDS := payment.ThreeDS
+		resp.ThreeDSecure = &threeDS
+	}
+	if payment.Status == model.PaymentStatusRequiresAction {
+		resp.NextAction = &NextAction{
+			Type:        NextActionRedirectToURL,
+			RedirectURL: payment.ThreeDS.ACSURL,
+		}
+	}
 
 	return resp
 }
 
-func (s *PaymentService) GetPayment(paymentID, merchantID uuid.UUID) (*PaymentResponse, error) {
-	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+func (s *PaymentService) GetPayment(ctx context.Context, paymentID, merchantID uuid.UUID) (*PaymentResponse, error) {
+	payment, err := s.paymentRepo.WithContext(ctx).FindByIDAndMerchant(paymentID, merchantID)
 	if err != nil {
 		return nil, err
 	}
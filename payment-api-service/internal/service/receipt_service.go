@@ -0,0 +1,422 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Receipt is a payment receipt rendered in the customer's language
+type Receipt struct {
+	PaymentID uuid.UUID     `json:"payment_id"`
+	Language  string        `json:"language"`
+	Direction string        `json:"direction"`
+	Title     string        `json:"title"`
+	Items     []ReceiptItem `json:"items,omitempty"` // checkout session line items
+	Lines     []ReceiptLine `json:"lines"`
+	Notice    string        `json:"notice,omitempty"`
+	Footer    string        `json:"footer"`
+
+	// The merchant's branding, shown above the receipt
+	Merchant   string `json:"merchant,omitempty"`
+	LogoURL    string `json:"logo_url,omitempty"`
+	BrandColor string `json:"brand_color,omitempty"`
+
+	amount string // formatted total, for the email subject
+}
+
+type ReceiptLine struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ReceiptItem is one line of the order a checkout session paid for
+type ReceiptItem struct {
+	Name     string `json:"name"`
+	Quantity int64  `json:"quantity"`
+	Amount   string `json:"amount"`
+	Tax      string `json:"tax,omitempty"` // e.g. "TVA 20%"
+}
+
+// ReceiptService renders receipts and emails them to customers when a
+// payment is captured or refunded. Emails go out only when EMAIL_SMTP_HOST
+// is set and the merchant has not turned receipts off. Merchants may
+// replace the subject and opening of each email with their own template
+// per language.
+type ReceiptService struct {
+	paymentRepo     *repository.PaymentRepository
+	sessionRepo     *repository.CheckoutSessionRepository
+	templateRepo    *repository.ReceiptTemplateRepository
+	displaySettings *DisplaySettingsService
+	mailer          *mailer
+}
+
+func NewReceiptService() *ReceiptService {
+	return &ReceiptService{
+		paymentRepo:     repository.NewPaymentRepository(),
+		sessionRepo:     repository.NewCheckoutSessionRepository(),
+		templateRepo:    repository.NewReceiptTemplateRepository(),
+		displaySettings: NewDisplaySettingsService(),
+		mailer:          newMailer(),
+	}
+}
+
+// GetReceipt renders a payment's receipt. language overrides the payment's
+// own language, which overrides the merchant's locale.
+func (s *ReceiptService) GetReceipt(paymentID, merchantID uuid.UUID, language string) (*Receipt, error) {
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	settings := s.displaySettings.Resolve(merchantID)
+	session, err := s.checkoutSession(payment)
+	if err != nil {
+		return nil, err
+	}
+	return buildReceipt(payment, session, settings, i18n.Match(language, payment.Language, settings.Locale)), nil
+}
+
+// checkoutSession loads the checkout session a payment paid for, or nil
+func (s *ReceiptService) checkoutSession(payment *model.Payment) (*model.CheckoutSession, error) {
+	if !payment.CheckoutSessionID.Valid {
+		return nil, nil
+	}
+	sessionID, err := uuid.Parse(payment.CheckoutSessionID.String)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionRepo.FindByIDAndMerchant(sessionID, payment.MerchantID)
+}
+
+// RenderHTML renders a receipt as a standalone HTML page
+func (s *ReceiptService) RenderHTML(receipt *Receipt) ([]byte, error) {
+	var out bytes.Buffer
+	if err := receiptTemplate.Execute(&out, receiptPage{Receipt: receipt}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// SendReceiptEmail emails the receipt to the payment's customer, if there is
+// one and receipts are enabled. Callers run it in the background, so errors
+// are logged rather than returned.
+func (s *ReceiptService) SendReceiptEmail(paymentID, merchantID uuid.UUID) {
+	s.sendEmail(paymentID, merchantID, nil)
+}
+
+// SendRefundReceiptEmail emails the customer a receipt for a refund, under
+// the same conditions as SendReceiptEmail. Refunds still queued for the
+// acquirer or that failed get none.
+func (s *ReceiptService) SendRefundReceiptEmail(paymentID, merchantID uuid.UUID, refund *RefundDetails) {
+	if refund == nil || refund.Status == "queued" || refund.Status == "failed" {
+		return
+	}
+	s.sendEmail(paymentID, merchantID, refund)
+}
+
+func (s *ReceiptService) sendEmail(paymentID, merchantID uuid.UUID, refund *RefundDetails) {
+	if !s.mailer.enabled() {
+		return
+	}
+
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load payment for receipt", zap.String("payment_id", paymentID.String()), zap.Error(err))
+		return
+	}
+	if !payment.CustomerEmail.Valid || payment.CustomerEmail.String == "" {
+		return
+	}
+
+	settings := s.displaySettings.Resolve(merchantID)
+	if !settings.SendEmailReceipts {
+		return
+	}
+
+	language := i18n.Match(payment.Language, settings.Locale)
+	kind := model.ReceiptKindPayment
+	var receipt *Receipt
+	if refund != nil {
+		kind = model.ReceiptKindRefund
+		receipt = buildRefundReceipt(payment, refund, settings, language)
+	} else {
+		session, err := s.checkoutSession(payment)
+		if err != nil {
+			logger.Log.Error("Failed to load checkout session for receipt", zap.String("payment_id", paymentID.String()), zap.Error(err))
+			return
+		}
+		receipt = buildReceipt(payment, session, settings, language)
+	}
+
+	email := &ReceiptEmail{
+		Amount:              receipt.amount,
+		PaymentID:           payment.ID.String(),
+		Merchant:            settings.BusinessName,
+		StatementDescriptor: settings.StatementDescriptor,
+	}
+	if refund != nil {
+		email.RefundID = refund.ID
+	}
+	if payment.CustomerName.Valid {
+		email.CustomerName = payment.CustomerName.String
+	}
+	if payment.CardLast4 != "" {
+		email.Card = i18n.T(language, "receipt.card_value", map[string]interface{}{
+			"Brand": strings.ToUpper(payment.CardBrand),
+			"Last4": payment.CardLast4,
+		})
+	}
+
+	page := receiptPage{
+		Receipt: receipt,
+		NoReply: i18n.T(language, "email.receipt.no_reply", nil),
+	}
+	subject := s.opening(merchantID, kind, language, email, &page)
+
+	var body bytes.Buffer
+	if err := receiptTemplate.Execute(&body, page); err != nil {
+		logger.Log.Error("Failed to render receipt email", zap.String("payment_id", paymentID.String()), zap.Error(err))
+		return
+	}
+
+	if err := s.mailer.send(payment.CustomerEmail.String, subject, body.Bytes()); err != nil {
+		logger.Log.Error("Failed to send receipt email",
+			zap.String("payment_id", paymentID.String()),
+			zap.String("kind", kind),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logger.Log.Info("Receipt email sent",
+		zap.String("payment_id", paymentID.String()),
+		zap.String("kind", kind),
+		zap.String("language", language),
+	)
+}
+
+// opening fills in the greeting of page and returns the subject, from the
+// merchant's template for the kind and language or the built-in text when
+// there is none or it no longer renders
+func (s *ReceiptService) opening(merchantID uuid.UUID, kind, language string, email *ReceiptEmail, page *receiptPage) string {
+	custom, err := s.templateRepo.FindTemplate(merchantID, kind, language)
+	if err != nil {
+		logger.Log.Warn("Failed to load receipt template, using the default", zap.String("merchant_id", merchantID.String()), zap.Error(err))
+	}
+	if custom != nil {
+		subject, content, err := renderReceiptTemplate(custom, email)
+		if err == nil {
+			page.Custom = content
+			return subject
+		}
+		logger.Log.Warn("Receipt template failed, using the default",
+			zap.String("merchant_id", merchantID.String()),
+			zap.String("kind", kind),
+			zap.String("language", language),
+			zap.Error(err),
+		)
+	}
+
+	page.Greeting = i18n.T(language, "email.receipt.greeting_anonymous", nil)
+	if email.CustomerName != "" {
+		page.Greeting = i18n.T(language, "email.receipt.greeting", map[string]interface{}{"Name": email.CustomerName})
+	}
+	messages := "email.receipt"
+	if kind == model.ReceiptKindRefund {
+		messages = "email.refund"
+	}
+	page.Intro = i18n.T(language, messages+".intro", nil)
+	return i18n.T(language, messages+".subject", map[string]interface{}{"Amount": email.Amount})
+}
+
+func buildReceipt(payment *model.Payment, session *model.CheckoutSession, settings *model.MerchantDisplaySettings, language string) *Receipt {
+	amount := settings.FormatAmount(payment.Amount, payment.Currency)
+	line := func(id, value string) ReceiptLine {
+		return ReceiptLine{Label: i18n.T(language, id, nil), Value: value}
+	}
+
+	receipt := &Receipt{
+		PaymentID: payment.ID,
+		Language:  language,
+		Direction: i18n.Direction(language),
+		Title:     i18n.T(language, "receipt.title", nil),
+		Footer:    i18n.T(language, "receipt.footer", nil),
+		amount:    amount,
+	}
+	applyBranding(receipt, settings)
+
+	receipt.Lines = append(receipt.Lines,
+		line("receipt.payment_id", payment.ID.String()),
+		line("receipt.date", payment.CreatedAt.In(settings.Location()).Format("2006-01-02 15:04")),
+	)
+	if session != nil {
+		for _, item := range session.LineItems {
+			receiptItem := ReceiptItem{
+				Name:     item.Name,
+				Quantity: item.Quantity,
+				Amount:   settings.FormatAmount(item.Amount, session.Currency),
+			}
+			if item.TaxName != "" {
+				receiptItem.Tax = fmt.Sprintf("%s %s%%", item.TaxName, strconv.FormatFloat(float64(item.TaxRateBps)/100, 'f', -1, 64))
+			}
+			receipt.Items = append(receipt.Items, receiptItem)
+		}
+		receipt.Lines = append(receipt.Lines, line("receipt.subtotal", settings.FormatAmount(session.Subtotal, session.Currency)))
+		if session.TaxAmount > 0 {
+			receipt.Lines = append(receipt.Lines, line("receipt.tax", settings.FormatAmount(session.TaxAmount, session.Currency)))
+		}
+		if option := session.FindShippingOption(session.ShippingOption); option != nil {
+			receipt.Lines = append(receipt.Lines, ReceiptLine{
+				Label: i18n.T(language, "receipt.shipping", nil),
+				Value: option.Name + ": " + settings.FormatAmount(session.ShippingAmount, session.Currency),
+			})
+		}
+	}
+	receipt.Lines = append(receipt.Lines,
+		line("receipt.amount", amount),
+		line("receipt.status", i18n.T(language, "receipt.status."+string(payment.Status), nil)),
+	)
+	if payment.CardLast4 != "" {
+		receipt.Lines = append(receipt.Lines, line("receipt.card", i18n.T(language, "receipt.card_value", map[string]interface{}{
+			"Brand": strings.ToUpper(payment.CardBrand),
+			"Last4": payment.CardLast4,
+		})))
+	}
+	if payment.AuthCode.Valid {
+		receipt.Lines = append(receipt.Lines, line("receipt.auth_code", payment.AuthCode.String))
+	}
+	if settings.StatementDescriptor != "" {
+		receipt.Lines = append(receipt.Lines, line("receipt.statement_descriptor", settings.StatementDescriptor))
+	}
+	if payment.Description.Valid {
+		receipt.Lines = append(receipt.Lines, line("receipt.description", payment.Description.String))
+	}
+	if payment.TestMode {
+		receipt.Notice = i18n.T(language, "receipt.test_mode", nil)
+	}
+	return receipt
+}
+
+// buildRefundReceipt renders the receipt for one refund of a payment
+func buildRefundReceipt(payment *model.Payment, refund *RefundDetails, settings *model.MerchantDisplaySettings, language string) *Receipt {
+	currency := refund.Currency
+	if currency == "" {
+		currency = payment.Currency
+	}
+	amount := settings.FormatAmount(refund.Amount, currency)
+	line := func(id, value string) ReceiptLine {
+		return ReceiptLine{Label: i18n.T(language, id, nil), Value: value}
+	}
+
+	receipt := &Receipt{
+		PaymentID: payment.ID,
+		Language:  language,
+		Direction: i18n.Direction(language),
+		Title:     i18n.T(language, "receipt.refund_title", nil),
+		Footer:    i18n.T(language, "receipt.refund_footer", nil),
+		amount:    amount,
+	}
+	applyBranding(receipt, settings)
+
+	receipt.Lines = append(receipt.Lines,
+		line("receipt.refund_id", refund.ID),
+		line("receipt.payment_id", payment.ID.String()),
+		line("receipt.date", time.Now().In(settings.Location()).Format("2006-01-02 15:04")),
+		line("receipt.refund_amount", amount),
+		line("receipt.original_amount", settings.FormatAmount(payment.Amount, payment.Currency)),
+	)
+	if payment.CardLast4 != "" {
+		receipt.Lines = append(receipt.Lines, line("receipt.card", i18n.T(language, "receipt.card_value", map[string]interface{}{
+			"Brand": strings.ToUpper(payment.CardBrand),
+			"Last4": payment.CardLast4,
+		})))
+	}
+	if settings.StatementDescriptor != "" {
+		receipt.Lines = append(receipt.Lines, line("receipt.statement_descriptor", settings.StatementDescriptor))
+	}
+	if payment.TestMode {
+		receipt.Notice = i18n.T(language, "receipt.test_mode", nil)
+	}
+	return receipt
+}
+
+func applyBranding(receipt *Receipt, settings *model.MerchantDisplaySettings) {
+	receipt.Merchant = settings.BusinessName
+	receipt.LogoURL = settings.LogoURL
+	receipt.BrandColor = settings.BrandColor
+}
+
+// receiptPage is the receipt plus the extra text the email version carries
+type receiptPage struct {
+	*Receipt
+	Greeting string
+	Intro    string
+	Custom   template.HTML // The merchant's template, in place of Greeting and Intro
+	NoReply  string
+
+	// An optional button below the receipt, e.g. an invoice's pay link
+	ActionURL   string
+	ActionLabel string
+}
+
+// ItemsLabel and QuantityLabel head the line items table
+func (p receiptPage) ItemsLabel() string    { return i18n.T(p.Language, "receipt.items", nil) }
+func (p receiptPage) QuantityLabel() string { return i18n.T(p.Language, "receipt.quantity", nil) }
+
+var receiptTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"year": func() int { return time.Now().Year() },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}" dir="{{.Direction}}">
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    <style>
+        body { font-family: Arial, "Noto Naskh Arabic", sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        h1 { font-size: 22px; }
+        table { width: 100%; border-collapse: collapse; }
+        td { padding: 8px 0; border-bottom: 1px solid #e5e7eb; }
+        td.label, small.label { color: #6b7280; }
+        table.items { margin-bottom: 20px; }
+        .notice { background-color: #fef3c7; padding: 10px; border-radius: 5px; }
+        a.action { display: inline-block; margin-top: 20px; padding: 10px 20px; border-radius: 5px; background-color: #111827; color: #ffffff; text-decoration: none; }
+        .footer { color: #6b7280; font-size: 14px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        {{if .LogoURL}}<p><img src="{{.LogoURL}}" alt="{{.Merchant}}" style="max-height: 48px"></p>{{end}}
+        {{if .Merchant}}<p class="merchant"{{if .BrandColor}} style="color: {{.BrandColor}}"{{end}}><strong>{{.Merchant}}</strong></p>{{end}}
+        {{if .Custom}}{{.Custom}}{{else}}{{if .Greeting}}<p>{{.Greeting}}</p>{{end}}
+        {{if .Intro}}<p>{{.Intro}}</p>{{end}}{{end}}
+        <h1{{if .BrandColor}} style="color: {{.BrandColor}}"{{end}}>{{.Title}}</h1>
+        {{if .Notice}}<p class="notice">{{.Notice}}</p>{{end}}
+        {{if .Items}}<table class="items">
+            <tr><td class="label">{{.ItemsLabel}}</td><td class="label">{{.QuantityLabel}}</td><td></td></tr>
+            {{range .Items}}<tr><td><bdi>{{.Name}}</bdi>{{if .Tax}}<br><small class="label">{{.Tax}}</small>{{end}}</td><td>{{.Quantity}}</td><td><bdi>{{.Amount}}</bdi></td></tr>
+            {{end}}
+        </table>{{end}}
+        <table>
+            {{range .Lines}}<tr><td class="label">{{.Label}}</td><td><bdi>{{.Value}}</bdi></td></tr>
+            {{end}}
+        </table>
+        {{if .ActionURL}}<p><a class="action" href="{{.ActionURL}}"{{if .BrandColor}} style="background-color: {{.BrandColor}}"{{end}}>{{.ActionLabel}}</a></p>{{end}}
+        <div class="footer">
+            <p>{{.Footer}}</p>
+            {{if .NoReply}}<p>{{.NoReply}}</p>{{end}}
+            <p>© {{year}} Payment Gateway Morocco</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/i18n"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+)
+
+var ErrInvalidReceiptTemplate = errors.New("invalid receipt template")
+
+const maxReceiptTemplateBody = 20000
+
+// ReceiptEmail holds the fields merchant receipt templates can use, e.g.
+// {{.Amount}} or {{.Merchant}}
+type ReceiptEmail struct {
+	CustomerName        string // Empty when the payment has none
+	Amount              string // Payment total, or the refunded amount on a refund receipt
+	Card                string // e.g. "VISA ending in 4242", in the email's language
+	PaymentID           string
+	RefundID            string // Refund receipts only
+	Merchant            string // The merchant's business name
+	StatementDescriptor string // What the customer's card statement shows
+}
+
+// sampleReceiptEmail is what templates are checked against before saving
+var sampleReceiptEmail = ReceiptEmail{
+	CustomerName:        "Amina",
+	Amount:              "1 250,00 MAD",
+	Card:                "VISA ending in 4242",
+	PaymentID:           "00000000-0000-0000-0000-000000000000",
+	RefundID:            "00000000-0000-0000-0000-000000000000",
+	Merchant:            "Atlas Boutique",
+	StatementDescriptor: "ATLAS BOUTIQUE",
+}
+
+// ListTemplates returns the merchant's custom receipt templates
+func (s *ReceiptService) ListTemplates(merchantID uuid.UUID) ([]model.ReceiptTemplate, error) {
+	return s.templateRepo.FindTemplates(merchantID)
+}
+
+// SaveTemplate validates and stores the merchant's template for a kind of
+// receipt in one language
+func (s *ReceiptService) SaveTemplate(merchantID, actorID uuid.UUID, kind, language, subject, body string) (*model.ReceiptTemplate, error) {
+	if err := validateReceiptTemplateKey(kind, language); err != nil {
+		return nil, err
+	}
+	subject = strings.TrimSpace(subject)
+	if subject == "" || len(subject) > 255 {
+		return nil, fmt.Errorf("%w: subject must be 1-255 characters", ErrInvalidReceiptTemplate)
+	}
+	if strings.TrimSpace(body) == "" || len(body) > maxReceiptTemplateBody {
+		return nil, fmt.Errorf("%w: body must be 1-%d characters", ErrInvalidReceiptTemplate, maxReceiptTemplateBody)
+	}
+
+	tmpl := &model.ReceiptTemplate{
+		MerchantID: merchantID,
+		Kind:       kind,
+		Language:   language,
+		Subject:    subject,
+		Body:       body,
+		UpdatedBy:  actorID,
+	}
+	// Catch syntax errors and unknown fields now rather than when a customer's email fails
+	if _, _, err := renderReceiptTemplate(tmpl, &sampleReceiptEmail); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReceiptTemplate, err)
+	}
+
+	if err := s.templateRepo.UpsertTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.FindTemplate(merchantID, kind, language)
+}
+
+// DeleteTemplate goes back to the built-in email for a kind and language
+func (s *ReceiptService) DeleteTemplate(merchantID uuid.UUID, kind, language string) error {
+	if err := validateReceiptTemplateKey(kind, language); err != nil {
+		return err
+	}
+	return s.templateRepo.DeleteTemplate(merchantID, kind, language)
+}
+
+func validateReceiptTemplateKey(kind, language string) error {
+	switch kind {
+	case model.ReceiptKindPayment, model.ReceiptKindRefund:
+	default:
+		return fmt.Errorf("%w: kind must be payment or refund", ErrInvalidReceiptTemplate)
+	}
+	switch language {
+	case i18n.English, i18n.French, i18n.Arabic:
+		return nil
+	}
+	return fmt.Errorf("%w: language must be en, fr or ar", ErrInvalidReceiptTemplate)
+}
+
+// renderReceiptTemplate renders a merchant template. The body goes through
+// html/template so customer and payment values are escaped.
+func renderReceiptTemplate(tmpl *model.ReceiptTemplate, email *ReceiptEmail) (string, template.HTML, error) {
+	subjectTmpl, err := texttemplate.New("subject").Parse(tmpl.Subject)
+	if err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	bodyTmpl, err := template.New("body").Parse(tmpl.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, email); err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	if err := bodyTmpl.Execute(&body, email); err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+	// Line breaks would let a template add mail headers
+	return strings.Join(strings.Fields(subject.String()), " "), template.HTML(body.String()), nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// refundApprovalTTL is how long a large refund waits for a second approver
+	refundApprovalTTL = 72 * time.Hour
+
+	refundApprovalSweepInterval = 10 * time.Minute
+)
+
+var (
+	ErrRefundApprovalPending    = errors.New("payment already has a refund awaiting approval")
+	ErrRefundApprovalNotPending = errors.New("refund approval is no longer pending")
+	ErrRefundSelfApproval       = errors.New("refund must be approved by someone other than its requester")
+)
+
+// RefundApprovalService holds refunds at or above the merchant's threshold
+// until a second team member approves them
+type RefundApprovalService struct {
+	approvalRepo     *repository.RefundApprovalRepository
+	paymentRepo      *repository.PaymentRepository
+	paymentService   *PaymentService
+	webhookService   *WebhookService
+	defaultThreshold int64
+}
+
+func NewRefundApprovalService(paymentService *PaymentService) *RefundApprovalService {
+	threshold, err := strconv.ParseInt(config.GetEnvWithDefault("REFUND_APPROVAL_THRESHOLD", "0"), 10, 64)
+	if err != nil || threshold < 0 {
+		logger.Log.Warn("Invalid REFUND_APPROVAL_THRESHOLD, refund approvals are off by default")
+		threshold = 0
+	}
+
+	return &RefundApprovalService{
+		approvalRepo:     repository.NewRefundApprovalRepository(),
+		paymentRepo:      repository.NewPaymentRepository(),
+		paymentService:   paymentService,
+		webhookService:   NewWebhookService(),
+		defaultThreshold: threshold,
+	}
+}
+
+// GetPolicy returns the merchant's approval threshold
+func (s *RefundApprovalService) GetPolicy(merchantID uuid.UUID) (*model.RefundApprovalPolicy, error) {
+	policy, err := s.approvalRepo.FindPolicy(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &model.RefundApprovalPolicy{MerchantID: merchantID, Threshold: s.defaultThreshold}
+	}
+	return policy, nil
+}
+
+// UpdatePolicy sets the merchant's approval threshold; 0 turns approvals off
+func (s *RefundApprovalService) UpdatePolicy(merchantID uuid.UUID, threshold int64) (*model.RefundApprovalPolicy, error) {
+	if threshold < 0 {
+		return nil, errors.New("threshold cannot be negative")
+	}
+	policy := &model.RefundApprovalPolicy{MerchantID: merchantID, Threshold: threshold}
+	if err := s.approvalRepo.UpsertPolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// RequestIfRequired holds a refund for approval when it reaches the
+// merchant's threshold. It returns nil when the refund can go ahead now.
+func (s *RefundApprovalService) RequestIfRequired(ctx context.Context, paymentID, merchantID, requestedBy uuid.UUID, amount int64, currency, reason, reasonCode string) (*model.RefundApproval, error) {
+	if err := checkRefundReasonCode(reasonCode); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.GetPolicy(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.RequiresApproval(amount) {
+		return nil, nil
+	}
+
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("payment not found: %w", err)
+	}
+	if !payment.CanRefund() {
+		return nil, errors.New("payment cannot be refunded (not captured)")
+	}
+	if err := checkPaymentCurrency(payment, currency); err != nil {
+		return nil, err
+	}
+	if amount > payment.Amount {
+		return nil, errors.New("refund amount exceeds the payment amount")
+	}
+	if err := s.paymentService.lifecycle.CheckCanRefund(merchantID); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.approvalRepo.HasPending(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrRefundApprovalPending
+	}
+
+	approval := &model.RefundApproval{
+		MerchantID:  merchantID,
+		PaymentID:   paymentID,
+		Amount:      amount,
+		Currency:    payment.Currency,
+		Reason:      reason,
+		ReasonCode:  reasonCode,
+		Status:      model.RefundApprovalPending,
+		RequestedBy: requestedBy,
+		ExpiresAt:   time.Now().Add(refundApprovalTTL),
+	}
+	if err := s.approvalRepo.Create(approval); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Refund held for approval",
+		zap.String("approval_id", approval.ID.String()),
+		zap.String("payment_id", paymentID.String()),
+		zap.Int64("amount", amount),
+		zap.Int64("threshold", policy.Threshold),
+	)
+
+	s.webhookService.DispatchRefundApprovalEvent(ctx, approval, WebhookEventRefundApprovalRequested)
+	return approval, nil
+}
+
+// ListApprovals returns the merchant's approval requests, newest first
+func (s *RefundApprovalService) ListApprovals(merchantID uuid.UUID, status model.RefundApprovalStatus) ([]model.RefundApproval, error) {
+	return s.approvalRepo.ListByMerchant(merchantID, status, 100)
+}
+
+// GetApproval returns one of the merchant's approval requests
+func (s *RefundApprovalService) GetApproval(id, merchantID uuid.UUID) (*model.RefundApproval, error) {
+	return s.approvalRepo.FindByIDAndMerchant(id, merchantID)
+}
+
+// Approve sends a held refund. The request is claimed before the refund is
+// sent so two approvers cannot refund twice; if the refund fails it goes
+// back to pending.
+func (s *RefundApprovalService) Approve(ctx context.Context, id, merchantID, approverID uuid.UUID, note string) (*model.RefundApproval, *PaymentResponse, error) {
+	approval, err := s.claim(id, merchantID, approverID, model.RefundApprovalApproved, note)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.paymentService.RefundPayment(ctx, approval.PaymentID, merchantID, approverID, approval.Amount, approval.Currency, approval.Reason, approval.ReasonCode)
+	if err != nil {
+		if reopenErr := s.approvalRepo.Reopen(id, merchantID); reopenErr != nil {
+			logger.Log.Error("Failed to reopen refund approval",
+				zap.String("approval_id", id.String()),
+				zap.Error(reopenErr),
+			)
+		}
+		return nil, nil, err
+	}
+
+	if resp.Refund != nil && resp.Refund.ID != "" {
+		if err := s.approvalRepo.SetRefundID(id, merchantID, resp.Refund.ID); err != nil {
+			logger.Log.Error("Failed to link refund to approval",
+				zap.String("approval_id", id.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	logger.Log.Info("Refund approved",
+		zap.String("approval_id", id.String()),
+		zap.String("approved_by", approverID.String()),
+	)
+
+	approval, err = s.approvalRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return approval, resp, nil
+}
+
+// Reject closes a held refund without sending it
+func (s *RefundApprovalService) Reject(ctx context.Context, id, merchantID, approverID uuid.UUID, note string) (*model.RefundApproval, error) {
+	approval, err := s.claim(id, merchantID, approverID, model.RefundApprovalRejected, note)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Refund rejected",
+		zap.String("approval_id", id.String()),
+		zap.String("rejected_by", approverID.String()),
+	)
+
+	s.webhookService.DispatchRefundApprovalEvent(ctx, approval, WebhookEventRefundApprovalRejected)
+	return approval, nil
+}
+
+// claim records a decision on a pending request, refusing the requester's
+// own decision and requests that were already decided or expired
+func (s *RefundApprovalService) claim(id, merchantID, approverID uuid.UUID, status model.RefundApprovalStatus, note string) (*model.RefundApproval, error) {
+	approval, err := s.approvalRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if approval.RequestedBy != uuid.Nil && approval.RequestedBy == approverID {
+		return nil, ErrRefundSelfApproval
+	}
+
+	claimed, err := s.approvalRepo.Decide(id, merchantID, approverID, status, note)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrRefundApprovalNotPending
+	}
+
+	approval.Status = status
+	approval.DecidedBy = &approverID
+	return approval, nil
+}
+
+// RunExpiryWorker expires requests nobody decided on within 72 hours until
+// ctx is canceled
+func (s *RefundApprovalService) RunExpiryWorker(ctx context.Context) error {
+	logger.Log.Info("Starting refund approval expiry worker")
+
+	ticker := time.NewTicker(refundApprovalSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Refund approval expiry worker stopped")
+			return nil
+		case <-ticker.C:
+			s.expireOverdue(ctx)
+		}
+	}
+}
+
+func (s *RefundApprovalService) expireOverdue(ctx context.Context) {
+	expired, err := s.approvalRepo.ExpireOverdue(time.Now())
+	if err != nil {
+		logger.Log.Error("Failed to expire refund approvals", zap.Error(err))
+	}
+
+	for i := range expired {
+		logger.Log.Info("Refund approval expired",
+			zap.String("approval_id", expired[i].ID.String()),
+			zap.String("merchant_id", expired[i].MerchantID.String()),
+		)
+		s.webhookService.DispatchRefundApprovalEvent(ctx, &expired[i], WebhookEventRefundApprovalExpired)
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+var ErrInvalidReport = errors.New("invalid report")
+
+// Timeseries bucket sizes
+const (
+	ReportIntervalDay  = "day"
+	ReportIntervalWeek = "week"
+)
+
+// MaxReportDays is the longest range a report covers
+const MaxReportDays = 366
+
+// reportCurrency is what the transaction service converts amounts to
+const reportCurrency = "MAD"
+
+// ReportStatistics is the merchant's payments over a period, as the
+// transaction service saw them. Volume is everything authorized and
+// amounts are in reportCurrency minor units; rates are percentages.
+type ReportStatistics struct {
+	Count             int64   `json:"count"`
+	Volume            int64   `json:"volume"`
+	CapturedAmount    int64   `json:"captured_amount"`
+	RefundedAmount    int64   `json:"refunded_amount"`
+	SettledAmount     int64   `json:"settled_amount"`
+	SuccessRate       float64 `json:"success_rate"`
+	RefundRate        float64 `json:"refund_rate"` // Share of captured payments with a refund
+	AverageFraudScore float64 `json:"average_fraud_score"`
+}
+
+// ReportPeriod is the local calendar days a report covers, both included,
+// in the merchant's settlement timezone, and the same range in UTC
+type ReportPeriod struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Timezone string    `json:"timezone"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"` // Excluded
+	TestMode bool      `json:"test_mode"`
+	Currency string    `json:"currency"`
+}
+
+// ReportSummary totals a period. Payments also counts attempts that never
+// reached the transaction service, such as fraud declines.
+type ReportSummary struct {
+	ReportPeriod
+	ReportStatistics
+	Payments *repository.PaymentStatistics `json:"payments"`
+}
+
+// ReportBucket is one day or week of a timeseries; Date is its first day
+type ReportBucket struct {
+	Date string `json:"date"`
+	ReportStatistics
+}
+
+type ReportTimeseries struct {
+	ReportPeriod
+	Interval string         `json:"interval"`
+	Buckets  []ReportBucket `json:"buckets"`
+}
+
+type ReportService struct {
+	paymentRepo       *repository.PaymentRepository
+	transactionClient *client.TransactionClient
+}
+
+func NewReportService() *ReportService {
+	return &ReportService{
+		paymentRepo:       repository.NewPaymentRepository(),
+		transactionClient: client.NewTransactionClient(),
+	}
+}
+
+// Summary totals the merchant's payments made in the given mode on the
+// local days from to to
+func (s *ReportService) Summary(ctx context.Context, merchantID uuid.UUID, testMode bool, from, to time.Time) (*ReportSummary, error) {
+	resp, period, err := s.statistics(ctx, merchantID, testMode, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.paymentRepo.WithContext(ctx).GetStatistics(merchantID, testMode, period.Start, period.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment statistics: %w", err)
+	}
+
+	return &ReportSummary{
+		ReportPeriod:     *period,
+		ReportStatistics: reportStatistics(resp.Totals),
+		Payments:         payments,
+	}, nil
+}
+
+// Timeseries splits the same totals into days or weeks, oldest first.
+// Weeks start on Monday, so the first and last can cover part of the
+// range only.
+func (s *ReportService) Timeseries(ctx context.Context, merchantID uuid.UUID, testMode bool, from, to time.Time, interval string) (*ReportTimeseries, error) {
+	if interval != ReportIntervalDay && interval != ReportIntervalWeek {
+		return nil, fmt.Errorf("%w: interval must be %s or %s", ErrInvalidReport, ReportIntervalDay, ReportIntervalWeek)
+	}
+
+	resp, period, err := s.statistics(ctx, merchantID, testMode, from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &ReportTimeseries{
+		ReportPeriod: *period,
+		Interval:     interval,
+		Buckets:      make([]ReportBucket, 0, len(resp.Buckets)),
+	}
+	for _, bucket := range resp.Buckets {
+		series.Buckets = append(series.Buckets, ReportBucket{
+			Date:             bucket.Date,
+			ReportStatistics: reportStatistics(bucket.Statistics),
+		})
+	}
+	return series, nil
+}
+
+func (s *ReportService) statistics(ctx context.Context, merchantID uuid.UUID, testMode bool, from, to time.Time, interval string) (*pb.StatisticsResponse, *ReportPeriod, error) {
+	if to.Before(from) {
+		return nil, nil, fmt.Errorf("%w: from must not be after to", ErrInvalidReport)
+	}
+	if to.Sub(from) >= MaxReportDays*24*time.Hour {
+		return nil, nil, fmt.Errorf("%w: a report covers at most %d days", ErrInvalidReport, MaxReportDays)
+	}
+
+	resp, err := s.transactionClient.GetStatistics(ctx, &pb.GetStatisticsRequest{
+		MerchantId: merchantID.String(),
+		TestMode:   testMode,
+		From:       from.Format(time.DateOnly),
+		To:         to.Format(time.DateOnly),
+		Interval:   interval,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get transaction statistics: %w", err)
+	}
+
+	period := &ReportPeriod{
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Timezone: resp.Timezone,
+		TestMode: testMode,
+		Currency: reportCurrency,
+	}
+	if period.Start, err = time.Parse(time.RFC3339, resp.Start); err != nil {
+		return nil, nil, fmt.Errorf("invalid statistics start %q: %w", resp.Start, err)
+	}
+	if period.End, err = time.Parse(time.RFC3339, resp.End); err != nil {
+		return nil, nil, fmt.Errorf("invalid statistics end %q: %w", resp.End, err)
+	}
+	return resp, period, nil
+}
+
+func reportStatistics(stats *pb.TransactionStatistics) ReportStatistics {
+	if stats == nil {
+		return ReportStatistics{}
+	}
+	return ReportStatistics{
+		Count:             stats.TotalTransactions,
+		Volume:            stats.TotalAmount,
+		CapturedAmount:    stats.CapturedAmount,
+		RefundedAmount:    stats.RefundedAmount,
+		SettledAmount:     stats.SettledAmount,
+		SuccessRate:       stats.SuccessRate,
+		RefundRate:        stats.RefundRate,
+		AverageFraudScore: stats.AverageFraudScore,
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+)
+
+// SandboxService resets the data a merchant created with test-mode keys, so
+// integration suites can start from a clean slate, and seeds sandbox tokens
+// for the test cards. Live data is never touched.
+type SandboxService struct {
+	sandboxRepo        *repository.SandboxRepository
+	tokenizationClient *client.TokenizationClient
+}
+
+func NewSandboxService() (*SandboxService, error) {
+	tokenizationClient, err := client.NewTokenizationClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SandboxService{
+		sandboxRepo:        repository.NewSandboxRepository(),
+		tokenizationClient: tokenizationClient,
+	}, nil
+}
+
+type SandboxResetResponse struct {
+	*repository.TestDataCounts
+	Tokens int `json:"tokens"`
+}
+
+// Reset deletes the merchant's test payments, intents, webhook deliveries
+// and tokens. It is safe to repeat if a step fails.
+func (s *SandboxService) Reset(ctx context.Context, merchantID uuid.UUID) (*SandboxResetResponse, error) {
+	counts, paymentIDs, err := s.sandboxRepo.DeleteTestData(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range paymentIDs {
+		inits.RDB.Del(ctx, fmt.Sprintf("payment:%s", id.String()))
+	}
+	s.dropTestIdempotencyKeys(ctx, merchantID)
+
+	tokens, err := s.tokenizationClient.DeleteTestTokens(ctx, merchantID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Sandbox data reset",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int64("payments", counts.Payments),
+		zap.Int64("payment_intents", counts.PaymentIntents),
+		zap.Int("tokens", tokens),
+	)
+
+	return &SandboxResetResponse{TestDataCounts: counts, Tokens: tokens}, nil
+}
+
+// dropTestIdempotencyKeys removes cached responses and payment records for
+// test requests, which are stored under "<merchant_id>:test"
+func (s *SandboxService) dropTestIdempotencyKeys(ctx context.Context, merchantID uuid.UUID) {
+	for _, kind := range []string{"payment", "hash", "record", "operation"} {
+		pattern := fmt.Sprintf("idempotency:%s:%s:test:*", kind, merchantID.String())
+		iter := inits.RDB.Scan(ctx, 0, pattern, 200).Iterator()
+		for iter.Next(ctx) {
+			inits.RDB.Del(ctx, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			logger.Log.Warn("Failed to clear test idempotency keys",
+				zap.String("merchant_id", merchantID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// SandboxTestCard is one of the card simulator's magic numbers
+type SandboxTestCard struct {
+	Number       string
+	Scenario     string
+	ResponseCode string // Expected issuer response; empty when 3-D Secure stops the payment first
+}
+
+// SandboxTestCards mirrors the simulator's magic numbers; see "Test Cards"
+// in the README
+var SandboxTestCards = []SandboxTestCard{
+	{Number: "4242424242424242", Scenario: "approved", ResponseCode: "00"},
+	{Number: "5555555555554444", Scenario: "approved_mastercard", ResponseCode: "00"},
+	{Number: "4000000000000002", Scenario: "declined_generic", ResponseCode: "05"},
+	{Number: "4000000000009995", Scenario: "declined_insufficient_funds", ResponseCode: "51"},
+	{Number: "4000000000000069", Scenario: "declined_expired_card", ResponseCode: "54"},
+	{Number: "4000000000000127", Scenario: "declined_cvv_failure", ResponseCode: "N7"},
+	{Number: "4000000000000119", Scenario: "processing_error", ResponseCode: "96"},
+	{Number: "4000000000000259", Scenario: "disputed_fraudulent", ResponseCode: "00"},
+	{Number: "4000000000003220", Scenario: "three_ds_challenge", ResponseCode: "00"},
+	{Number: "4000000000003238", Scenario: "three_ds_failed"},
+	{Number: "4000000000003246", Scenario: "three_ds_attempted", ResponseCode: "00"},
+}
+
+// SandboxCustomer is a sample customer the seeded cards are issued to
+type SandboxCustomer struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+var sandboxCustomers = []SandboxCustomer{
+	{Name: "Amina El Idrissi", Email: "amina.elidrissi@example.com"},
+	{Name: "Youssef Benali", Email: "youssef.benali@example.com"},
+	{Name: "Claire Martin", Email: "claire.martin@example.com"},
+	{Name: "James Carter", Email: "james.carter@example.com"},
+	{Name: "Sofia Rossi", Email: "sofia.rossi@example.com"},
+}
+
+// SeededCard is a sandbox token ready to be sent as card_token
+type SeededCard struct {
+	Token        string          `json:"token"`
+	Brand        string          `json:"brand"`
+	Last4        string          `json:"last4"`
+	ExpMonth     int             `json:"exp_month"`
+	ExpYear      int             `json:"exp_year"`
+	Scenario     string          `json:"scenario"`
+	ResponseCode string          `json:"response_code,omitempty"`
+	Customer     SandboxCustomer `json:"customer"`
+}
+
+// SeedVault tokenizes every magic test card as a sandbox token of the
+// merchant, each issued to one of the sample customers. Tokenization
+// deduplicates cards, so seeding again returns the same tokens.
+func (s *SandboxService) SeedVault(ctx context.Context, merchantID uuid.UUID) ([]SeededCard, error) {
+	expYear := time.Now().Year() + 3
+	seeded := make([]SeededCard, 0, len(SandboxTestCards))
+
+	for i, card := range SandboxTestCards {
+		customer := sandboxCustomers[i%len(sandboxCustomers)]
+
+		resp, err := s.tokenizationClient.TokenizeCard(ctx, &pb.TokenizeCardRequest{
+			MerchantId:     merchantID.String(),
+			CardNumber:     card.Number,
+			CardholderName: customer.Name,
+			ExpMonth:       12,
+			ExpYear:        int32(expYear),
+			Cvv:            "123",
+			TestMode:       true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed card ending %s: %w", card.Number[len(card.Number)-4:], err)
+		}
+
+		seeded = append(seeded, SeededCard{
+			Token:        resp.Token,
+			Brand:        resp.CardBrand,
+			Last4:        resp.Last4,
+			ExpMonth:     resp.ExpMonth,
+			ExpYear:      resp.ExpYear,
+			Scenario:     card.Scenario,
+			ResponseCode: card.ResponseCode,
+			Customer:     customer,
+		})
+	}
+
+	logger.Log.Info("Sandbox vault seeded",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int("tokens", len(seeded)),
+	)
+
+	return seeded, nil
+}
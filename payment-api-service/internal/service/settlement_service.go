@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+// SettlementService exposes the merchant's payouts held by the transaction
+// service
+type SettlementService struct {
+	transactionClient *client.TransactionClient
+}
+
+func NewSettlementService() *SettlementService {
+	return &SettlementService{
+		transactionClient: client.NewTransactionClient(),
+	}
+}
+
+// QuoteInstantPayout reports whether the merchant can take an instant payout
+// now and what it would pay, without creating it
+func (s *SettlementService) QuoteInstantPayout(ctx context.Context, merchantID uuid.UUID) (*pb.InstantPayoutResponse, error) {
+	return s.transactionClient.CreateInstantPayout(ctx, &pb.CreateInstantPayoutRequest{
+		MerchantId: merchantID.String(),
+		DryRun:     true,
+	})
+}
+
+// CreateInstantPayout pays the merchant's captured funds out today, less the
+// instant payout fee
+func (s *SettlementService) CreateInstantPayout(ctx context.Context, merchantID uuid.UUID) (*pb.InstantPayoutResponse, error) {
+	return s.transactionClient.CreateInstantPayout(ctx, &pb.CreateInstantPayoutRequest{
+		MerchantId: merchantID.String(),
+	})
+}
+
+// GetBalance returns the merchant's available balance, rolling reserve and
+// captured funds not yet batched
+func (s *SettlementService) GetBalance(ctx context.Context, merchantID uuid.UUID) (*pb.BalanceResponse, error) {
+	return s.transactionClient.GetBalance(ctx, merchantID.String())
+}
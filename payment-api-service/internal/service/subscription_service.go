@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	subscriptionBillingInterval = time.Minute
+	subscriptionBatchSize       = 100
+
+	// A claimed subscription is skipped by other replicas for this long
+	subscriptionClaimLease = 10 * time.Minute
+
+	// Failed renewals are retried daily; the fourth failure cancels
+	subscriptionRetryDelay     = 24 * time.Hour
+	subscriptionMaxFailedTries = 4
+)
+
+var (
+	ErrInvalidSubscription       = errors.New("invalid subscription")
+	ErrSubscriptionPlanArchived  = errors.New("subscription plan is archived")
+	ErrSubscriptionCanceled      = errors.New("subscription is canceled")
+	ErrSubscriptionPaused        = errors.New("subscription is already paused")
+	ErrSubscriptionNotPaused     = errors.New("subscription is not paused")
+	ErrSubscriptionChargeFailed  = errors.New("first subscription charge failed")
+	ErrSubscriptionCurrencyMatch = errors.New("new plan must use the subscription's currency")
+)
+
+// SubscriptionService bills stored cards on a plan's schedule. Every charge
+// goes through PaymentService.SalePayment, so renewals get the same fraud
+// checks, webhooks and records as any other sale.
+type SubscriptionService struct {
+	subscriptionRepo *repository.SubscriptionRepository
+	paymentService   *PaymentService
+	webhookService   *WebhookService
+}
+
+func NewSubscriptionService(paymentService *PaymentService) *SubscriptionService {
+	return &SubscriptionService{
+		subscriptionRepo: repository.NewSubscriptionRepository(),
+		paymentService:   paymentService,
+		webhookService:   NewWebhookService(),
+	}
+}
+
+type CreatePlanRequest struct {
+	MerchantID    uuid.UUID
+	Name          string
+	Amount        int64
+	Currency      string
+	Interval      string
+	IntervalCount int
+	TrialDays     int
+}
+
+// CreatePlan validates and stores a plan
+func (s *SubscriptionService) CreatePlan(req *CreatePlanRequest) (*model.SubscriptionPlan, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidSubscription)
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidSubscription)
+	}
+	if req.Currency != "USD" && req.Currency != "EUR" && req.Currency != "MAD" {
+		return nil, fmt.Errorf("%w: unsupported currency", ErrInvalidSubscription)
+	}
+
+	interval := model.BillingInterval(strings.ToLower(req.Interval))
+	switch interval {
+	case model.BillingIntervalDay, model.BillingIntervalWeek, model.BillingIntervalMonth, model.BillingIntervalYear:
+	default:
+		return nil, fmt.Errorf("%w: interval must be day, week, month or year", ErrInvalidSubscription)
+	}
+
+	count := req.IntervalCount
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > 12 {
+		return nil, fmt.Errorf("%w: interval_count must be between 1 and 12", ErrInvalidSubscription)
+	}
+	if req.TrialDays < 0 || req.TrialDays > 365 {
+		return nil, fmt.Errorf("%w: trial_days must be between 0 and 365", ErrInvalidSubscription)
+	}
+
+	plan := &model.SubscriptionPlan{
+		MerchantID:    req.MerchantID,
+		Name:          name,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Interval:      interval,
+		IntervalCount: count,
+		TrialDays:     req.TrialDays,
+		Active:        true,
+	}
+	if err := s.subscriptionRepo.CreatePlan(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ListPlans returns the merchant's plans
+func (s *SubscriptionService) ListPlans(merchantID uuid.UUID, includeArchived bool) ([]model.SubscriptionPlan, error) {
+	return s.subscriptionRepo.ListPlans(merchantID, includeArchived)
+}
+
+// GetPlan returns one of the merchant's plans
+func (s *SubscriptionService) GetPlan(id, merchantID uuid.UUID) (*model.SubscriptionPlan, error) {
+	return s.subscriptionRepo.FindPlan(id, merchantID)
+}
+
+// ArchivePlan stops new subscriptions to a plan
+func (s *SubscriptionService) ArchivePlan(id, merchantID uuid.UUID) error {
+	return s.subscriptionRepo.ArchivePlan(id, merchantID)
+}
+
+type CreateSubscriptionRequest struct {
+	MerchantID     uuid.UUID
+	PlanID         uuid.UUID
+	CardNumber     string
+	CardholderName string
+	ExpMonth       int
+	ExpYear        int
+	CVV            string
+	CustomerEmail  string
+	IPAddress      string
+	UserAgent      string
+	TestMode       bool
+	CreatedBy      uuid.UUID
+}
+
+// CreateSubscription tokenizes the card and starts the subscription. Without
+// a trial the first period is charged straight away; if that charge fails
+// the subscription is canceled and ErrSubscriptionChargeFailed returned.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, req *CreateSubscriptionRequest) (*model.Subscription, error) {
+	plan, err := s.subscriptionRepo.FindPlan(req.PlanID, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.Active {
+		return nil, ErrSubscriptionPlanArchived
+	}
+	if err := s.paymentService.lifecycle.CheckCanAcceptPayments(req.MerchantID); err != nil {
+		return nil, err
+	}
+	if s.paymentService.tokenizationClient == nil {
+		return nil, errors.New("tokenization service unavailable")
+	}
+
+	tokenResp, err := s.paymentService.tokenizationClient.TokenizeCard(ctx, &pb.TokenizeCardRequest{
+		MerchantId:     req.MerchantID.String(),
+		CardNumber:     req.CardNumber,
+		CardholderName: req.CardholderName,
+		ExpMonth:       int32(req.ExpMonth),
+		ExpYear:        int32(req.ExpYear),
+		Cvv:            req.CVV,
+		IsSingleUse:    false,
+		IpAddress:      req.IPAddress,
+		UserAgent:      req.UserAgent,
+		TestMode:       req.TestMode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize card: %w", err)
+	}
+
+	// No period has been paid yet, so the current period is empty and
+	// billing starts at its end
+	now := time.Now()
+	sub := &model.Subscription{
+		MerchantID:         req.MerchantID,
+		PlanID:             plan.ID,
+		Status:             model.SubscriptionActive,
+		TestMode:           req.TestMode,
+		CardToken:          tokenResp.Token,
+		CardBrand:          tokenResp.CardBrand,
+		CardLast4:          tokenResp.Last4,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now,
+		CreatedBy:          req.CreatedBy,
+	}
+	if req.CustomerEmail != "" {
+		sub.CustomerEmail = sql.NullString{String: req.CustomerEmail, Valid: true}
+	}
+	if plan.TrialDays > 0 {
+		trialEnd := now.AddDate(0, 0, plan.TrialDays)
+		sub.Status = model.SubscriptionTrialing
+		sub.CurrentPeriodEnd = trialEnd
+		sub.TrialEndsAt = sql.NullTime{Time: trialEnd, Valid: true}
+	}
+	sub.NextBillingAt = sql.NullTime{Time: sub.CurrentPeriodEnd, Valid: true}
+
+	if err := s.subscriptionRepo.Create(sub); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Subscription created",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("plan_id", plan.ID.String()),
+		zap.String("status", string(sub.Status)),
+	)
+
+	if sub.Status == model.SubscriptionTrialing {
+		return sub, nil
+	}
+
+	charge := s.bill(ctx, sub, plan)
+	if charge.Status == model.SubscriptionChargeFailed {
+		s.cancelNow(sub)
+		if err := s.subscriptionRepo.Save(sub); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionChargeFailed, charge.FailureReason)
+	}
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns the merchant's subscriptions, newest first
+func (s *SubscriptionService) ListSubscriptions(merchantID uuid.UUID, status model.SubscriptionStatus, limit, offset int) ([]model.Subscription, error) {
+	return s.subscriptionRepo.ListByMerchant(merchantID, status, limit, offset)
+}
+
+// GetSubscription returns one of the merchant's subscriptions
+func (s *SubscriptionService) GetSubscription(id, merchantID uuid.UUID) (*model.Subscription, error) {
+	return s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+}
+
+// ListCharges returns the subscription's billing attempts
+func (s *SubscriptionService) ListCharges(id, merchantID uuid.UUID) ([]model.SubscriptionCharge, error) {
+	if _, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.ListCharges(id, merchantID, 100)
+}
+
+// ChangePlan moves the subscription to another plan. With prorate, the
+// unused part of the current period is credited at the old price and
+// debited at the new one; the difference is settled on the next charge.
+// The new plan's schedule starts at the next billing date.
+func (s *SubscriptionService) ChangePlan(id, merchantID, planID uuid.UUID, prorate bool) (*model.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == model.SubscriptionCanceled {
+		return nil, ErrSubscriptionCanceled
+	}
+
+	oldPlan, err := s.subscriptionRepo.FindPlan(sub.PlanID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	newPlan, err := s.subscriptionRepo.FindPlan(planID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !newPlan.Active {
+		return nil, ErrSubscriptionPlanArchived
+	}
+	if newPlan.Currency != oldPlan.Currency {
+		return nil, ErrSubscriptionCurrencyMatch
+	}
+
+	// Trials are free, so there is nothing to prorate
+	if prorate && sub.Status != model.SubscriptionTrialing {
+		sub.ProrationBalance += proration(sub, oldPlan.Amount, newPlan.Amount, time.Now())
+	}
+	sub.PlanID = newPlan.ID
+
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Subscription plan changed",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("old_plan_id", oldPlan.ID.String()),
+		zap.String("new_plan_id", newPlan.ID.String()),
+		zap.Int64("proration_balance", sub.ProrationBalance),
+	)
+	return sub, nil
+}
+
+// proration is the new price minus the old one for the unused fraction of
+// the current paid period, rounded to the nearest minor unit
+func proration(sub *model.Subscription, oldAmount, newAmount int64, now time.Time) int64 {
+	period := sub.CurrentPeriodEnd.Sub(sub.CurrentPeriodStart)
+	remaining := sub.CurrentPeriodEnd.Sub(now)
+	if period <= 0 || remaining <= 0 {
+		return 0
+	}
+	if remaining > period {
+		remaining = period
+	}
+	fraction := float64(remaining) / float64(period)
+	diff := float64(newAmount-oldAmount) * fraction
+	if diff < 0 {
+		return -int64(-diff + 0.5)
+	}
+	return int64(diff + 0.5)
+}
+
+// Pause stops billing until Resume
+func (s *SubscriptionService) Pause(id, merchantID uuid.UUID) (*model.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	switch sub.Status {
+	case model.SubscriptionCanceled:
+		return nil, ErrSubscriptionCanceled
+	case model.SubscriptionPaused:
+		return nil, ErrSubscriptionPaused
+	}
+
+	sub.Status = model.SubscriptionPaused
+	sub.PausedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	sub.NextBillingAt = sql.NullTime{}
+
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Resume restarts billing. A period that is still paid for runs to its end;
+// time spent paused after it ended is not billed, and the next period
+// starts now.
+func (s *SubscriptionService) Resume(id, merchantID uuid.UUID) (*model.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status != model.SubscriptionPaused {
+		return nil, ErrSubscriptionNotPaused
+	}
+
+	now := time.Now()
+	if sub.CurrentPeriodEnd.Before(now) {
+		sub.CurrentPeriodEnd = now
+	}
+
+	switch {
+	case sub.TrialEndsAt.Valid && sub.TrialEndsAt.Time.After(now):
+		sub.Status = model.SubscriptionTrialing
+	case sub.FailedAttempts > 0:
+		sub.Status = model.SubscriptionPastDue
+	default:
+		sub.Status = model.SubscriptionActive
+	}
+	sub.PausedAt = sql.NullTime{}
+	sub.NextBillingAt = sql.NullTime{Time: sub.CurrentPeriodEnd, Valid: true}
+
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Cancel ends the subscription now, or with atPeriodEnd once the paid
+// period runs out. Nothing is refunded.
+func (s *SubscriptionService) Cancel(id, merchantID uuid.UUID, atPeriodEnd bool) (*model.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == model.SubscriptionCanceled {
+		return nil, ErrSubscriptionCanceled
+	}
+
+	if atPeriodEnd && sub.Status != model.SubscriptionPaused {
+		sub.CancelAtPeriodEnd = true
+	} else {
+		s.cancelNow(sub)
+	}
+
+	if err := s.subscriptionRepo.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *SubscriptionService) cancelNow(sub *model.Subscription) {
+	sub.Status = model.SubscriptionCanceled
+	sub.CanceledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	sub.NextBillingAt = sql.NullTime{}
+	sub.CancelAtPeriodEnd = false
+}
+
+// RunBillingWorker charges due subscriptions every minute until ctx is
+// canceled
+func (s *SubscriptionService) RunBillingWorker(ctx context.Context) error {
+	logger.Log.Info("Starting subscription billing worker")
+
+	ticker := time.NewTicker(subscriptionBillingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Subscription billing worker stopped")
+			return nil
+		case <-ticker.C:
+			s.billDue(ctx)
+		}
+	}
+}
+
+func (s *SubscriptionService) billDue(ctx context.Context) {
+	for i := 0; i < subscriptionBatchSize && ctx.Err() == nil; i++ {
+		sub, err := s.subscriptionRepo.ClaimDue(time.Now(), subscriptionClaimLease)
+		if err != nil {
+			logger.Log.Error("Failed to claim due subscription", zap.Error(err))
+			return
+		}
+		if sub == nil {
+			return
+		}
+		s.renew(ctx, sub)
+	}
+}
+
+// renew settles a subscription that has come due: it is canceled if that
+// was requested for the period end, otherwise charged for the next period
+func (s *SubscriptionService) renew(ctx context.Context, sub *model.Subscription) {
+	if sub.CancelAtPeriodEnd {
+		s.cancelNow(sub)
+		logger.Log.Info("Subscription canceled at period end", zap.String("subscription_id", sub.ID.String()))
+	} else {
+		plan, err := s.subscriptionRepo.FindPlan(sub.PlanID, sub.MerchantID)
+		if err != nil {
+			logger.Log.Error("Failed to load subscription plan",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(err),
+			)
+			return
+		}
+		s.bill(ctx, sub, plan)
+	}
+
+	if err := s.subscriptionRepo.SaveFromWorker(sub); err != nil {
+		logger.Log.Error("Failed to save subscription after billing",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// bill charges the period starting at the end of the current one and
+// updates sub with the outcome; the caller saves it. The idempotency key is
+// per period and attempt, so a worker that dies mid-charge cannot bill the
+// same attempt twice.
+func (s *SubscriptionService) bill(ctx context.Context, sub *model.Subscription, plan *model.SubscriptionPlan) *model.SubscriptionCharge {
+	periodStart := sub.CurrentPeriodEnd
+	periodEnd := plan.PeriodEnd(periodStart)
+	amount := plan.Amount + sub.ProrationBalance
+
+	charge := &model.SubscriptionCharge{
+		SubscriptionID: sub.ID,
+		MerchantID:     sub.MerchantID,
+		Currency:       plan.Currency,
+		Proration:      sub.ProrationBalance,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+	}
+
+	if amount <= 0 {
+		// Proration credit covers the whole period; the rest carries over
+		charge.Status = model.SubscriptionChargeSucceeded
+		charge.Proration = -plan.Amount
+		sub.ProrationBalance = amount
+	} else {
+		charge.Amount = amount
+		resp, err := s.paymentService.SalePayment(ctx, &AuthorizePaymentRequest{
+			MerchantID:     sub.MerchantID,
+			Amount:         amount,
+			Currency:       plan.Currency,
+			CustomerEmail:  sub.CustomerEmail.String,
+			Description:    "Subscription: " + plan.Name,
+			Metadata:       map[string]interface{}{"subscription_id": sub.ID.String()},
+			IdempotencyKey: fmt.Sprintf("sub_%s_%d_%d", sub.ID, periodStart.Unix(), sub.FailedAttempts),
+			TestMode:       sub.TestMode,
+			CreatedBy:      sub.CreatedBy,
+			StoredCard: &StoredCard{
+				Token:     sub.CardToken,
+				CardBrand: sub.CardBrand,
+				Last4:     sub.CardLast4,
+			},
+		})
+		switch {
+		case err != nil:
+			charge.Status = model.SubscriptionChargeFailed
+			charge.FailureReason = err.Error()
+		case resp.Status != model.PaymentStatusCaptured:
+			charge.Status = model.SubscriptionChargeFailed
+			charge.FailureReason = resp.ResponseMsg
+		default:
+			charge.Status = model.SubscriptionChargeSucceeded
+			sub.ProrationBalance = 0
+		}
+		if resp != nil {
+			paymentID := resp.ID
+			charge.PaymentID = &paymentID
+			s.webhookService.DispatchPaymentEvent(ctx, sub.MerchantID, resp.ID, GetWebhookEventType(resp.Status))
+		}
+	}
+
+	if charge.Status == model.SubscriptionChargeSucceeded {
+		sub.Status = model.SubscriptionActive
+		sub.FailedAttempts = 0
+		sub.CurrentPeriodStart = periodStart
+		sub.CurrentPeriodEnd = periodEnd
+		sub.NextBillingAt = sql.NullTime{Time: periodEnd, Valid: true}
+	} else {
+		sub.FailedAttempts++
+		if sub.FailedAttempts >= subscriptionMaxFailedTries {
+			s.cancelNow(sub)
+		} else {
+			sub.Status = model.SubscriptionPastDue
+			sub.NextBillingAt = sql.NullTime{Time: time.Now().Add(subscriptionRetryDelay), Valid: true}
+		}
+	}
+
+	if err := s.subscriptionRepo.CreateCharge(charge); err != nil {
+		logger.Log.Error("Failed to record subscription charge",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	logger.Log.Info("Subscription billed",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("result", string(charge.Status)),
+		zap.Int64("amount", charge.Amount),
+		zap.String("status", string(sub.Status)),
+	)
+	return charge
+}
@@ -2,19 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/tenancy"
 	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
 )
 
+var ErrPaymentTransactionNotFound = errors.New("payment not found")
+
 type TransactionService struct {
 	transactionClient *client.TransactionClient
+	paymentRepo       *repository.PaymentRepository
 }
 
 func NewTransactionService() (*TransactionService, error) {
 	transactionClient := client.NewTransactionClient()
 	return &TransactionService{
 		transactionClient: transactionClient,
+		paymentRepo:       repository.NewPaymentRepository(),
 	}, nil
 }
 
@@ -33,3 +41,36 @@ func (s *TransactionService) ListTransactions(ctx context.Context, req *pb.ListT
 	}
 	return res, nil
 }
+
+// PaymentTransactionID returns the transaction behind one of the merchant's
+// payments, so notes and tags can be managed from either ID
+func (s *TransactionService) PaymentTransactionID(ctx context.Context, paymentID, merchantID uuid.UUID) (uuid.UUID, error) {
+	scoped := s.paymentRepo.WithContext(tenancy.WithMerchant(ctx, merchantID))
+	payment, err := scoped.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil || payment.TransactionID == uuid.Nil {
+		return uuid.Nil, ErrPaymentTransactionNotFound
+	}
+	return payment.TransactionID, nil
+}
+
+// Notes and tags are internal annotations kept by the transaction service
+
+func (s *TransactionService) AddNote(ctx context.Context, req *pb.AddTransactionNoteRequest) (*pb.TransactionNoteResponse, error) {
+	return s.transactionClient.AddTransactionNote(ctx, req)
+}
+
+func (s *TransactionService) ListNotes(ctx context.Context, req *pb.ListTransactionNotesRequest) (*pb.ListTransactionNotesResponse, error) {
+	return s.transactionClient.ListTransactionNotes(ctx, req)
+}
+
+func (s *TransactionService) DeleteNote(ctx context.Context, req *pb.DeleteTransactionNoteRequest) (*pb.DeleteTransactionNoteResponse, error) {
+	return s.transactionClient.DeleteTransactionNote(ctx, req)
+}
+
+func (s *TransactionService) AddTags(ctx context.Context, req *pb.AddTransactionTagsRequest) (*pb.TransactionTagsResponse, error) {
+	return s.transactionClient.AddTransactionTags(ctx, req)
+}
+
+func (s *TransactionService) RemoveTag(ctx context.Context, req *pb.RemoveTransactionTagRequest) (*pb.TransactionTagsResponse, error) {
+	return s.transactionClient.RemoveTransactionTag(ctx, req)
+}
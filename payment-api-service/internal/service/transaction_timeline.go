@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	pb "github.com/rhaloubi/payment-gateway/payment-api-service/proto"
+)
+
+const (
+	timelineBundleVersion = 1
+	timelineSignatureAlgo = "HMAC-SHA256"
+
+	// Payments with more local records than this are bundled by the export
+	// worker instead of inside the request
+	timelineInlineLimit = 200
+)
+
+var ErrInvalidTimelineBundle = errors.New("bundle is not a signed transaction timeline")
+
+// TransactionTimeline is everything recorded about one payment across the
+// payment API, transaction and tokenization services
+type TransactionTimeline struct {
+	Version     int       `json:"version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	MerchantID  uuid.UUID `json:"merchant_id"`
+
+	Payment         *model.Payment         `json:"payment"`
+	PaymentEvents   []model.PaymentEvent   `json:"payment_events"`
+	FraudDecision   TimelineFraudDecision  `json:"fraud_decision"`
+	RefundApprovals []model.RefundApproval `json:"refund_approvals"`
+
+	Transaction       *pb.TransactionResponse        `json:"transaction,omitempty"`
+	TransactionEvents []*pb.TransactionTimelineEvent `json:"transaction_events"`
+	IssuerResponses   []*pb.IssuerResponseRecord     `json:"issuer_responses"`
+	RoutingDecision   json.RawMessage                `json:"routing_decision,omitempty"`
+	Refunds           []*RefundDetails               `json:"refunds"`
+
+	TokenUsage        []*pb.TokenUsageEntry   `json:"token_usage"`
+	WebhookDeliveries []model.WebhookDelivery `json:"webhook_deliveries"`
+}
+
+type TimelineFraudDecision struct {
+	Score       int      `json:"score"`
+	Decision    string   `json:"decision"`
+	ReasonCodes []string `json:"reason_codes"`
+}
+
+// SignedTimeline is the file handed to auditors. The signature covers the
+// compact JSON encoding of Bundle.
+type SignedTimeline struct {
+	Bundle    json.RawMessage   `json:"bundle"`
+	Signature TimelineSignature `json:"signature"`
+}
+
+type TimelineSignature struct {
+	Algorithm string `json:"algorithm"`
+	SHA256    string `json:"sha256"` // digest of the bundle
+	Value     string `json:"value"`
+}
+
+// transactionTimelineBuilder gathers and signs timelines for ExportService
+type transactionTimelineBuilder struct {
+	paymentRepo        *repository.PaymentRepository
+	webhookRepo        *repository.WebhookRepository
+	approvalRepo       *repository.RefundApprovalRepository
+	transactionClient  *client.TransactionClient
+	tokenizationClient *client.TokenizationClient
+	signingKey         []byte
+}
+
+func newTransactionTimelineBuilder(transactionClient *client.TransactionClient, exportKey []byte) *transactionTimelineBuilder {
+	tokenizationClient, _ := client.NewTokenizationClient()
+
+	// Derived so a bundle signature can never be replayed as a download link
+	mac := hmac.New(sha256.New, exportKey)
+	mac.Write([]byte("transaction-timeline"))
+
+	return &transactionTimelineBuilder{
+		paymentRepo:        repository.NewPaymentRepository(),
+		webhookRepo:        repository.NewWebhookRepository(),
+		approvalRepo:       repository.NewRefundApprovalRepository(),
+		transactionClient:  transactionClient,
+		tokenizationClient: tokenizationClient,
+		signingKey:         mac.Sum(nil),
+	}
+}
+
+// isLarge reports whether the payment has too many records to bundle inline
+func (b *transactionTimelineBuilder) isLarge(paymentID, merchantID uuid.UUID) (bool, error) {
+	events, err := b.paymentRepo.CountPaymentEvents(paymentID)
+	if err != nil {
+		return false, err
+	}
+	deliveries, err := b.webhookRepo.CountByPayment(paymentID, merchantID)
+	if err != nil {
+		return false, err
+	}
+	return events+deliveries > timelineInlineLimit, nil
+}
+
+// Build collects the payment's records from every service. A service that
+// cannot answer fails the bundle rather than leaving a silent gap.
+func (b *transactionTimelineBuilder) Build(ctx context.Context, paymentID, merchantID uuid.UUID) (*TransactionTimeline, error) {
+	payment, err := b.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &TransactionTimeline{
+		Version:     timelineBundleVersion,
+		GeneratedAt: time.Now().UTC(),
+		MerchantID:  merchantID,
+		Payment:     payment,
+		FraudDecision: TimelineFraudDecision{
+			Score:       payment.FraudScore,
+			Decision:    payment.FraudDecision,
+			ReasonCodes: payment.FraudReasonList(),
+		},
+		TransactionEvents: []*pb.TransactionTimelineEvent{},
+		IssuerResponses:   []*pb.IssuerResponseRecord{},
+		Refunds:           []*RefundDetails{},
+		TokenUsage:        []*pb.TokenUsageEntry{},
+	}
+
+	if timeline.PaymentEvents, err = b.paymentRepo.GetPaymentEvents(paymentID); err != nil {
+		return nil, fmt.Errorf("failed to load payment events: %w", err)
+	}
+	if timeline.RefundApprovals, err = b.approvalRepo.ListByPayment(paymentID, merchantID); err != nil {
+		return nil, fmt.Errorf("failed to load refund approvals: %w", err)
+	}
+	if timeline.WebhookDeliveries, err = b.webhookRepo.FindByPaymentAndMerchant(paymentID, merchantID); err != nil {
+		return nil, fmt.Errorf("failed to load webhook deliveries: %w", err)
+	}
+
+	// Payments declined before reaching the transaction service have nothing more
+	if payment.TransactionID == uuid.Nil {
+		return timeline, nil
+	}
+
+	txn, err := b.transactionClient.GetTransactionTimeline(ctx, &pb.GetTransactionTimelineRequest{
+		TransactionId: payment.TransactionID.String(),
+		MerchantId:    merchantID.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction timeline: %w", err)
+	}
+	timeline.Transaction = txn.Transaction
+	timeline.TransactionEvents = append(timeline.TransactionEvents, txn.Events...)
+	timeline.IssuerResponses = append(timeline.IssuerResponses, txn.IssuerResponses...)
+	if txn.RoutingDecision != "" {
+		timeline.RoutingDecision = json.RawMessage(txn.RoutingDecision)
+	}
+
+	refunds, err := b.transactionClient.ListRefunds(ctx, &pb.ListRefundsRequest{
+		TransactionId: payment.TransactionID.String(),
+		MerchantId:    merchantID.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load refunds: %w", err)
+	}
+	for _, refund := range refunds {
+		details := refundDetailsFromProto(refund)
+		details.PaymentID = paymentID.String()
+		timeline.Refunds = append(timeline.Refunds, details)
+	}
+
+	if b.tokenizationClient == nil {
+		return nil, errors.New("tokenization service unavailable")
+	}
+	usage, err := b.tokenizationClient.ListTokenUsage(ctx, merchantID.String(), []string{payment.TransactionID.String()})
+	if err != nil {
+		return nil, err
+	}
+	timeline.TokenUsage = append(timeline.TokenUsage, usage...)
+
+	return timeline, nil
+}
+
+// RecordCount is the number of records in the bundle, reported as the
+// export's row count
+func (t *TransactionTimeline) RecordCount() int {
+	return 1 + len(t.PaymentEvents) + len(t.RefundApprovals) + len(t.TransactionEvents) +
+		len(t.IssuerResponses) + len(t.Refunds) + len(t.TokenUsage) + len(t.WebhookDeliveries)
+}
+
+// Sign encodes the timeline and signs it
+func (b *transactionTimelineBuilder) Sign(timeline *TransactionTimeline) (*SignedTimeline, error) {
+	bundle, err := json.Marshal(timeline)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedTimeline{
+		Bundle:    bundle,
+		Signature: b.signature(bundle),
+	}, nil
+}
+
+// Verify checks a signed timeline. Whitespace changes are tolerated; any
+// change to the content is not.
+func (b *transactionTimelineBuilder) Verify(data []byte) (bool, error) {
+	var signed SignedTimeline
+	if err := json.Unmarshal(data, &signed); err != nil || len(signed.Bundle) == 0 {
+		return false, ErrInvalidTimelineBundle
+	}
+	if signed.Signature.Algorithm != timelineSignatureAlgo {
+		return false, ErrInvalidTimelineBundle
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, signed.Bundle); err != nil {
+		return false, ErrInvalidTimelineBundle
+	}
+	expected := b.signature(compact.Bytes())
+	return hmac.Equal([]byte(expected.Value), []byte(signed.Signature.Value)), nil
+}
+
+func (b *transactionTimelineBuilder) signature(bundle []byte) TimelineSignature {
+	digest := sha256.Sum256(bundle)
+	mac := hmac.New(sha256.New, b.signingKey)
+	mac.Write(bundle)
+	return TimelineSignature{
+		Algorithm: timelineSignatureAlgo,
+		SHA256:    hex.EncodeToString(digest[:]),
+		Value:     hex.EncodeToString(mac.Sum(nil)),
+	}
+}
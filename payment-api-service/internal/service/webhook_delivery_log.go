@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWebhookNotRedeliverable = errors.New("only retrying or dead-lettered deliveries can be redelivered")
+	ErrWebhookSubscriptionGone = errors.New("the delivery's webhook subscription no longer exists")
+)
+
+// ListDeliveries returns the merchant's delivery log, newest first
+func (s *WebhookService) ListDeliveries(merchantID uuid.UUID, filter repository.WebhookDeliveryFilter, limit, offset int) ([]model.WebhookDelivery, error) {
+	return s.webhookRepo.ListByMerchant(merchantID, filter, limit, offset)
+}
+
+// GetDelivery returns one delivery with its payload and last response
+func (s *WebhookService) GetDelivery(id, merchantID uuid.UUID) (*model.WebhookDelivery, error) {
+	return s.webhookRepo.FindByIDAndMerchant(id, merchantID)
+}
+
+// Redeliver sends a retrying or dead-lettered delivery again now, signed with
+// the subscription's current secrets. It is a single attempt: a failure puts
+// the delivery back where the retry schedule says.
+func (s *WebhookService) Redeliver(id, merchantID uuid.UUID) (*model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID == nil {
+		return nil, ErrWebhookSubscriptionGone
+	}
+	sub, err := s.subscriptionRepo.FindSecrets(*delivery.SubscriptionID)
+	if err != nil {
+		return nil, ErrWebhookSubscriptionGone
+	}
+
+	claimed, err := s.webhookRepo.ClaimRedelivery(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrWebhookNotRedeliverable
+	}
+
+	logger.Log.Info("Redelivering webhook",
+		zap.String("webhook_id", id.String()),
+		zap.String("merchant_id", merchantID.String()),
+	)
+	s.deliverWebhook(delivery.ID, delivery.WebhookURL, []byte(delivery.Payload), sub.SigningSecrets(time.Now()))
+
+	return s.webhookRepo.FindByIDAndMerchant(id, merchantID)
+}
@@ -8,23 +8,31 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/metrics"
 	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
 	"go.uber.org/zap"
 )
 
 type WebhookService struct {
-	webhookRepo *repository.WebhookRepository
-	httpClient  *http.Client
+	webhookRepo      *repository.WebhookRepository
+	subscriptionRepo *repository.WebhookSubscriptionRepository
+	paymentRepo      *repository.PaymentRepository
+	sessionRepo      *repository.CheckoutSessionRepository
+	httpClient       *http.Client
 }
 
 func NewWebhookService() *WebhookService {
 	return &WebhookService{
-		webhookRepo: repository.NewWebhookRepository(),
+		webhookRepo:      repository.NewWebhookRepository(),
+		subscriptionRepo: repository.NewWebhookSubscriptionRepository(),
+		paymentRepo:      repository.NewPaymentRepository(),
+		sessionRepo:      repository.NewCheckoutSessionRepository(),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -39,8 +47,80 @@ type WebhookPayload struct {
 	ID        uuid.UUID              `json:"id"`
 }
 
+// DispatchPaymentEvent delivers a payment event to every active subscription
+// of the merchant whose filters it matches. Failures are logged rather than
+// returned so that webhooks never fail the payment operation itself.
+func (s *WebhookService) DispatchPaymentEvent(ctx context.Context, merchantID, paymentID uuid.UUID, eventType string) {
+	s.dispatch(merchantID, paymentID, eventType, nil)
+}
+
+// DispatchRefundApprovalEvent tells subscriptions that a large refund is
+// waiting for a second approver, or will not be sent. The payload is the
+// payment's, plus a refund_approval object.
+func (s *WebhookService) DispatchRefundApprovalEvent(ctx context.Context, approval *model.RefundApproval, eventType string) {
+	s.dispatch(approval.MerchantID, approval.PaymentID, eventType, map[string]interface{}{
+		"refund_approval": map[string]interface{}{
+			"id":           approval.ID,
+			"status":       approval.Status,
+			"amount":       approval.Amount,
+			"currency":     approval.Currency,
+			"reason":       approval.Reason,
+			"requested_by": approval.RequestedBy,
+			"expires_at":   approval.ExpiresAt,
+		},
+	})
+}
+
+func (s *WebhookService) dispatch(merchantID, paymentID uuid.UUID, eventType string, extra map[string]interface{}) {
+	subs, err := s.subscriptionRepo.FindActiveByMerchant(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load webhook subscriptions",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	payment, err := s.paymentRepo.FindByIDAndMerchant(paymentID, merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load payment for webhook",
+			zap.String("payment_id", paymentID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for i := range subs {
+		sub := &subs[i]
+		if !sub.Matches(payment, eventType) {
+			logger.Log.Debug("Webhook filtered out",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.String("event", eventType),
+			)
+			continue
+		}
+		if err := s.sendPaymentWebhook(payment, eventType, sub.URL, sub.SigningSecrets(time.Now()), &sub.ID, extra); err != nil {
+			logger.Log.Error("Failed to queue webhook",
+				zap.String("subscription_id", sub.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
 // SendPaymentWebhook sends a payment event webhook to merchant
 func (s *WebhookService) SendPaymentWebhook(ctx context.Context, payment *model.Payment, eventType string, webhookURL string, webhookSecret string) error {
+	var secrets []string
+	if webhookSecret != "" {
+		secrets = []string{webhookSecret}
+	}
+	return s.sendPaymentWebhook(payment, eventType, webhookURL, secrets, nil, nil)
+}
+
+func (s *WebhookService) sendPaymentWebhook(payment *model.Payment, eventType string, webhookURL string, secrets []string, subscriptionID *uuid.UUID, extra map[string]interface{}) error {
 
 	// Build webhook payload
 	payload := WebhookPayload{
@@ -57,6 +137,7 @@ func (s *WebhookService) SendPaymentWebhook(ctx context.Context, payment *model.
 			"card_last4":     payment.CardLast4,
 			"fraud_score":    payment.FraudScore,
 			"fraud_decision": payment.FraudDecision,
+			"fraud_reasons":  payment.FraudReasonList(),
 			"created_at":     payment.CreatedAt,
 		},
 	}
@@ -74,6 +155,15 @@ func (s *WebhookService) SendPaymentWebhook(ctx context.Context, payment *model.
 	if payment.TransactionID != uuid.Nil {
 		payload.Data["transaction_id"] = payment.TransactionID
 	}
+	if key := payment.RoutingKey(); key != "" {
+		payload.Data["routing_key"] = key
+	}
+	if session := s.checkoutSession(payment); session != nil {
+		payload.Data["checkout_session"] = session
+	}
+	for key, value := range extra {
+		payload.Data[key] = value
+	}
 
 	// Serialize payload
 	payloadJSON, err := json.Marshal(payload)
@@ -84,11 +174,12 @@ func (s *WebhookService) SendPaymentWebhook(ctx context.Context, payment *model.
 
 	// Create webhook delivery record
 	webhookDelivery := &model.WebhookDelivery{
-		PaymentID:  payment.ID,
-		MerchantID: payment.MerchantID,
-		EventType:  eventType,
-		WebhookURL: webhookURL,
-		Payload:    string(payloadJSON),
+		PaymentID:      payment.ID,
+		MerchantID:     payment.MerchantID,
+		SubscriptionID: subscriptionID,
+		EventType:      eventType,
+		WebhookURL:     webhookURL,
+		Payload:        string(payloadJSON),
 	}
 
 	if err := s.webhookRepo.Create(webhookDelivery); err != nil {
@@ -97,17 +188,39 @@ func (s *WebhookService) SendPaymentWebhook(ctx context.Context, payment *model.
 	}
 
 	// Send webhook asynchronously
-	go s.deliverWebhook(webhookDelivery.ID, webhookURL, payloadJSON, webhookSecret)
+	go s.deliverWebhook(webhookDelivery.ID, webhookURL, payloadJSON, secrets)
 
 	return nil
 }
 
+// checkoutSession loads the itemized order a payment paid for. A session
+// that cannot be loaded is logged and left out rather than holding up the
+// event.
+func (s *WebhookService) checkoutSession(payment *model.Payment) *model.CheckoutSession {
+	if !payment.CheckoutSessionID.Valid {
+		return nil
+	}
+	sessionID, err := uuid.Parse(payment.CheckoutSessionID.String)
+	if err != nil {
+		return nil
+	}
+	session, err := s.sessionRepo.FindByIDAndMerchant(sessionID, payment.MerchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load checkout session for webhook",
+			zap.String("payment_id", payment.ID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return session
+}
+
 // deliverWebhook sends the actual HTTP request to merchant's webhook endpoint
 func (s *WebhookService) deliverWebhook(
 	webhookID uuid.UUID,
 	url string,
 	payload []byte,
-	secret string,
+	secrets []string,
 ) {
 	logger.Log.Info("Delivering webhook",
 		zap.String("webhook_id", webhookID.String()),
@@ -118,7 +231,7 @@ func (s *WebhookService) deliverWebhook(
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		logger.Log.Error("Failed to create webhook request", zap.Error(err))
-		s.webhookRepo.MarkFailed(webhookID, 0, err.Error())
+		s.recordFailure(webhookID, 0, err.Error())
 		return
 	}
 
@@ -127,10 +240,13 @@ func (s *WebhookService) deliverWebhook(
 	req.Header.Set("User-Agent", "PaymentGateway-Webhook/1.0")
 	req.Header.Set("X-Webhook-Timestamp", time.Now().Format(time.RFC3339))
 
-	// Generate HMAC signature
-	if secret != "" {
-		signature := s.generateSignature(payload, secret)
-		req.Header.Set("X-Webhook-Signature", signature)
+	// Generate HMAC signature, one per secret during a rotation
+	if len(secrets) > 0 {
+		signatures := make([]string, len(secrets))
+		for i, secret := range secrets {
+			signatures[i] = s.generateSignature(payload, secret)
+		}
+		req.Header.Set("X-Webhook-Signature", strings.Join(signatures, ","))
 	}
 
 	// Send request
@@ -140,7 +256,7 @@ func (s *WebhookService) deliverWebhook(
 			zap.Error(err),
 			zap.String("url", url),
 		)
-		s.webhookRepo.MarkFailed(webhookID, 0, err.Error())
+		s.recordFailure(webhookID, 0, err.Error())
 		return
 	}
 	defer resp.Body.Close()
@@ -156,13 +272,34 @@ func (s *WebhookService) deliverWebhook(
 			zap.Int("status_code", resp.StatusCode),
 		)
 		s.webhookRepo.MarkDelivered(webhookID, resp.StatusCode, string(responseBody))
+		metrics.WebhookDelivery(string(model.WebhookDeliveryDelivered))
 	} else {
 		logger.Log.Warn("Webhook delivery failed",
 			zap.String("webhook_id", webhookID.String()),
 			zap.Int("status_code", resp.StatusCode),
 			zap.String("response", string(responseBody)),
 		)
-		s.webhookRepo.MarkFailed(webhookID, resp.StatusCode, string(responseBody))
+		s.recordFailure(webhookID, resp.StatusCode, string(responseBody))
+	}
+}
+
+// recordFailure schedules the next attempt, or dead-letters the delivery
+// once its retries are used up
+func (s *WebhookService) recordFailure(webhookID uuid.UUID, statusCode int, response string) {
+	status, err := s.webhookRepo.MarkFailed(webhookID, statusCode, response)
+	if err != nil {
+		logger.Log.Error("Failed to record webhook failure",
+			zap.String("webhook_id", webhookID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	metrics.WebhookDelivery(string(status))
+	if status == model.WebhookDeliveryDeadLetter {
+		logger.Log.Warn("Webhook moved to dead letter",
+			zap.String("webhook_id", webhookID.String()),
+			zap.Int("status_code", statusCode),
+		)
 	}
 }
 
@@ -197,13 +334,26 @@ func (s *WebhookService) processRetries() {
 
 	for _, webhook := range webhooks {
 		// Get webhook secret (should be fetched from merchant settings)
-		webhookSecret := "merchant_webhook_secret" // TODO: Fetch from merchant service
+		webhookSecrets := []string{"merchant_webhook_secret"} // TODO: Fetch from merchant service
+		if webhook.SubscriptionID != nil {
+			sub, err := s.subscriptionRepo.FindSecrets(*webhook.SubscriptionID)
+			if err != nil {
+				// The subscription was deleted; don't sign with a guessed key
+				logger.Log.Warn("Skipping retry for missing webhook subscription",
+					zap.String("webhook_id", webhook.ID.String()),
+					zap.Error(err),
+				)
+				s.webhookRepo.MarkDeadLetter(webhook.ID, "webhook subscription no longer exists")
+				continue
+			}
+			webhookSecrets = sub.SigningSecrets(time.Now())
+		}
 
 		s.deliverWebhook(
 			webhook.ID,
 			webhook.WebhookURL,
 			[]byte(webhook.Payload),
-			webhookSecret,
+			webhookSecrets,
 		)
 
 		// Rate limit retries (1 per second)
@@ -213,23 +363,42 @@ func (s *WebhookService) processRetries() {
 
 // generateSignature creates HMAC-SHA256 signature for webhook verification
 func (s *WebhookService) generateSignature(payload []byte, secret string) string {
+	return signWebhookPayload(payload, secret)
+}
+
+func signWebhookPayload(payload []byte, secret string) string {
 	h := hmac.New(sha256.New, []byte(secret))
 	h.Write(payload)
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// VerifyWebhookSignature verifies webhook signature (for testing)
+// VerifyWebhookSignature verifies webhook signature (for testing). The
+// header may carry several comma-separated signatures during a secret
+// rotation; any one of them matching is enough.
 func (s *WebhookService) VerifyWebhookSignature(payload []byte, signature, secret string) bool {
 	expectedSignature := s.generateSignature(payload, secret)
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+	for _, candidate := range strings.Split(signature, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expectedSignature)) {
+			return true
+		}
+	}
+	return false
 }
 
 const (
-	WebhookEventPaymentAuthorized = "payment.authorized"
-	WebhookEventPaymentCaptured   = "payment.captured"
-	WebhookEventPaymentVoided     = "payment.voided"
-	WebhookEventPaymentRefunded   = "payment.refunded"
-	WebhookEventPaymentFailed     = "payment.failed"
+	WebhookEventPaymentAuthorized     = "payment.authorized"
+	WebhookEventPaymentCaptured       = "payment.captured"
+	WebhookEventPaymentVoided         = "payment.voided"
+	WebhookEventPaymentRefunded       = "payment.refunded"
+	WebhookEventPaymentFailed         = "payment.failed"
+	WebhookEventPaymentRequiresAction = "payment.requires_action"
+
+	// The authorization went uncaptured for 7 days and was auto-voided
+	WebhookEventPaymentAuthorizationExpired = "payment.authorization_expired"
+
+	WebhookEventRefundApprovalRequested = "refund.approval_requested"
+	WebhookEventRefundApprovalRejected  = "refund.approval_rejected"
+	WebhookEventRefundApprovalExpired   = "refund.approval_expired"
 )
 
 // GetWebhookEventType returns the appropriate webhook event type for payment status
@@ -237,7 +406,7 @@ func GetWebhookEventType(status model.PaymentStatus) string {
 	switch status {
 	case model.PaymentStatusAuthorized:
 		return WebhookEventPaymentAuthorized
-	case model.PaymentStatusCaptured:
+	case model.PaymentStatusCaptured, model.PaymentStatusPartiallyCaptured:
 		return WebhookEventPaymentCaptured
 	case model.PaymentStatusVoided:
 		return WebhookEventPaymentVoided
@@ -245,6 +414,8 @@ func GetWebhookEventType(status model.PaymentStatus) string {
 		return WebhookEventPaymentRefunded
 	case model.PaymentStatusFailed:
 		return WebhookEventPaymentFailed
+	case model.PaymentStatusRequiresAction:
+		return WebhookEventPaymentRequiresAction
 	default:
 		return "payment.unknown"
 	}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/repository"
+)
+
+const maxWebhookSubscriptions = 10
+
+// Secret rotation overlap: how long the old secret keeps signing deliveries
+const (
+	DefaultWebhookSecretOverlap = 24 * time.Hour
+	MaxWebhookSecretOverlap     = 7 * 24 * time.Hour
+)
+
+var ErrInvalidWebhookSubscription = errors.New("invalid webhook subscription")
+
+// webhookEventTypes are the events a subscription may select
+var webhookEventTypes = []string{
+	WebhookEventPaymentAuthorized,
+	WebhookEventPaymentCaptured,
+	WebhookEventPaymentVoided,
+	WebhookEventPaymentRefunded,
+	WebhookEventPaymentFailed,
+	WebhookEventPaymentRequiresAction,
+	WebhookEventPaymentAuthorizationExpired,
+	WebhookEventRefundApprovalRequested,
+	WebhookEventRefundApprovalRejected,
+	WebhookEventRefundApprovalExpired,
+}
+
+type WebhookSubscriptionService struct {
+	subscriptionRepo *repository.WebhookSubscriptionRepository
+	httpClient       *http.Client
+}
+
+func NewWebhookSubscriptionService() *WebhookSubscriptionService {
+	return &WebhookSubscriptionService{
+		subscriptionRepo: repository.NewWebhookSubscriptionRepository(),
+		httpClient: &http.Client{
+			Timeout: webhookVerificationTimeout,
+			// A redirect would verify a different endpoint than the one
+			// deliveries go to
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// WebhookSubscriptionInput carries the fields a merchant can set. Nil fields
+// are left unchanged on update.
+type WebhookSubscriptionInput struct {
+	URL         *string
+	EventTypes  *[]string
+	MinAmount   *int64
+	Currencies  *[]string
+	Mode        *string
+	RoutingKeys *[]string
+	Active      *bool
+}
+
+// ListSubscriptions returns the merchant's webhook subscriptions
+func (s *WebhookSubscriptionService) ListSubscriptions(merchantID uuid.UUID) ([]model.WebhookSubscription, error) {
+	return s.subscriptionRepo.FindByMerchant(merchantID)
+}
+
+// GetSubscription returns one of the merchant's webhook subscriptions
+func (s *WebhookSubscriptionService) GetSubscription(id, merchantID uuid.UUID) (*model.WebhookSubscription, error) {
+	return s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+}
+
+// CreateSubscription validates the input and stores a subscription with a
+// freshly generated signing secret. The secret is only readable on the
+// returned value. The URL is verified with a ping handshake first; if it
+// fails the subscription is stored inactive with the reason, and can be
+// activated with VerifySubscription once the endpoint is fixed.
+func (s *WebhookSubscriptionService) CreateSubscription(merchantID uuid.UUID, input *WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	if input.URL == nil {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidWebhookSubscription)
+	}
+
+	existing, err := s.subscriptionRepo.FindByMerchant(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhookSubscriptions {
+		return nil, fmt.Errorf("%w: at most %d subscriptions are supported", ErrInvalidWebhookSubscription, maxWebhookSubscriptions)
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &model.WebhookSubscription{
+		ID:         uuid.New(), // Sent in the ping before the row exists
+		MerchantID: merchantID,
+		Secret:     secret,
+		Mode:       model.WebhookModeAll,
+		Active:     true,
+	}
+	if err := applyWebhookSubscriptionInput(sub, input); err != nil {
+		return nil, err
+	}
+	if !s.verifyWebhookEndpoint(sub) {
+		sub.Active = false
+	}
+
+	if err := s.subscriptionRepo.Create(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// UpdateSubscription changes a subscription's endpoint or filters. A new URL
+// is verified again and the subscription deactivated if the handshake
+// fails. An inactive subscription can only be activated once verified.
+func (s *WebhookSubscriptionService) UpdateSubscription(id, merchantID uuid.UUID, input *WebhookSubscriptionInput) (*model.WebhookSubscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	previousURL, wasActive := sub.URL, sub.Active
+	if err := applyWebhookSubscriptionInput(sub, input); err != nil {
+		return nil, err
+	}
+
+	if sub.URL != previousURL {
+		if !s.verifyWebhookEndpoint(sub) {
+			sub.Active = false
+		}
+	} else if sub.Active && !wasActive && sub.VerifiedAt == nil {
+		return nil, fmt.Errorf("%w: the url must pass verification before the subscription is activated", ErrInvalidWebhookSubscription)
+	}
+
+	if err := s.subscriptionRepo.Update(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// VerifySubscription re-runs the ping handshake against a subscription's URL
+// and activates it on success. A failed handshake is recorded but leaves an
+// active subscription active, since it passed verification before.
+func (s *WebhookSubscriptionService) VerifySubscription(id, merchantID uuid.UUID) (*model.WebhookSubscription, error) {
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.verifyWebhookEndpoint(sub) {
+		sub.Active = true
+	}
+
+	if err := s.subscriptionRepo.Update(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RotateSecret gives a subscription a new signing secret. For the overlap
+// window deliveries are signed with both the new and the old secret; a zero
+// overlap retires the old secret at once. Rotating again during a window
+// drops the oldest secret.
+func (s *WebhookSubscriptionService) RotateSecret(id, merchantID uuid.UUID, overlap time.Duration) (*model.WebhookSubscription, error) {
+	if overlap < 0 || overlap > MaxWebhookSecretOverlap {
+		return nil, fmt.Errorf("%w: overlap must be between 0 and %d hours", ErrInvalidWebhookSubscription, int(MaxWebhookSecretOverlap/time.Hour))
+	}
+
+	sub, err := s.subscriptionRepo.FindByIDAndMerchant(id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub.PreviousSecret, sub.PreviousSecretExpiresAt = "", nil
+	if overlap > 0 {
+		expiresAt := time.Now().Add(overlap)
+		sub.PreviousSecret = sub.Secret
+		sub.PreviousSecretExpiresAt = &expiresAt
+	}
+	sub.Secret = secret
+
+	if err := s.subscriptionRepo.Update(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription. Failed deliveries queued for it
+// are not retried, since there is no longer a secret to sign them with.
+func (s *WebhookSubscriptionService) DeleteSubscription(id, merchantID uuid.UUID) error {
+	return s.subscriptionRepo.Delete(id, merchantID)
+}
+
+func applyWebhookSubscriptionInput(sub *model.WebhookSubscription, input *WebhookSubscriptionInput) error {
+	if input.URL != nil {
+		url := strings.TrimSpace(*input.URL)
+		if OriginOf(url) == "" {
+			return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhookSubscription)
+		}
+		sub.URL = url
+	}
+
+	if input.EventTypes != nil {
+		events, err := normalizeWebhookList(*input.EventTypes, webhookEventTypes, strings.ToLower, "event type")
+		if err != nil {
+			return err
+		}
+		sub.EventTypes = strings.Join(events, ",")
+	}
+
+	if input.MinAmount != nil {
+		if *input.MinAmount < 0 {
+			return fmt.Errorf("%w: min_amount cannot be negative", ErrInvalidWebhookSubscription)
+		}
+		sub.MinAmount = *input.MinAmount
+	}
+
+	if input.Currencies != nil {
+		currencies, err := normalizeWebhookList(*input.Currencies, []string{"USD", "EUR", "MAD"}, strings.ToUpper, "currency")
+		if err != nil {
+			return err
+		}
+		sub.Currencies = strings.Join(currencies, ",")
+	}
+
+	if input.Mode != nil {
+		switch mode := model.WebhookMode(strings.ToLower(*input.Mode)); mode {
+		case model.WebhookModeAll, model.WebhookModeLive, model.WebhookModeTest:
+			sub.Mode = mode
+		default:
+			return fmt.Errorf("%w: mode must be all, live or test", ErrInvalidWebhookSubscription)
+		}
+	}
+
+	if input.RoutingKeys != nil {
+		keys, err := normalizeRoutingKeys(*input.RoutingKeys)
+		if err != nil {
+			return err
+		}
+		sub.RoutingKeys = strings.Join(keys, ",")
+	}
+
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	return nil
+}
+
+// normalizeWebhookList canonicalizes values, drops duplicates and rejects
+// anything not in allowed
+func normalizeWebhookList(values, allowed []string, canonical func(string) string, kind string) ([]string, error) {
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		value := canonical(strings.TrimSpace(v))
+		if !containsString(allowed, value) {
+			return nil, fmt.Errorf("%w: unsupported %s %q", ErrInvalidWebhookSubscription, kind, v)
+		}
+		if !seen[value] {
+			seen[value] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeRoutingKeys trims and dedupes routing keys. Unlike event types
+// they are merchant-defined, so only their format is checked.
+func normalizeRoutingKeys(values []string) ([]string, error) {
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		key := strings.TrimSpace(v)
+		if !validRoutingKey(key) {
+			return nil, fmt.Errorf("%w: invalid routing key %q", ErrInvalidWebhookSubscription, v)
+		}
+		if !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, key)
+		}
+	}
+	return normalized, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func generateWebhookSecret() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}
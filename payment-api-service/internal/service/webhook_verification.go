@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/payment-api-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// WebhookEventPing is the handshake sent to an endpoint before it is
+// activated. It is never delivered as a regular event.
+const WebhookEventPing = "webhook.ping"
+
+const webhookVerificationTimeout = 10 * time.Second
+
+// webhookPingResponse is what the endpoint must answer with
+type webhookPingResponse struct {
+	Challenge string `json:"challenge"`
+}
+
+// verifyWebhookEndpoint sends a signed webhook.ping carrying a random
+// challenge and records whether the endpoint echoed it back, either as
+// {"challenge": "..."} or as the plain-text body. It reports success.
+func (s *WebhookSubscriptionService) verifyWebhookEndpoint(sub *model.WebhookSubscription) bool {
+	err := s.pingWebhookEndpoint(sub)
+	if err != nil {
+		logger.Log.Warn("Webhook endpoint verification failed",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.String("url", sub.URL),
+			zap.Error(err),
+		)
+		sub.VerifiedAt = nil
+		sub.VerificationError = err.Error()
+		return false
+	}
+
+	now := time.Now()
+	sub.VerifiedAt = &now
+	sub.VerificationError = ""
+	return true
+}
+
+func (s *WebhookSubscriptionService) pingWebhookEndpoint(sub *model.WebhookSubscription) error {
+	challengeBytes := make([]byte, 16)
+	if _, err := rand.Read(challengeBytes); err != nil {
+		return fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := hex.EncodeToString(challengeBytes)
+
+	payload, err := json.Marshal(WebhookPayload{
+		Event:     WebhookEventPing,
+		Timestamp: time.Now(),
+		ID:        uuid.New(),
+		Data: map[string]interface{}{
+			"subscription_id": sub.ID,
+			"challenge":       challenge,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", sub.URL, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PaymentGateway-Webhook/1.0")
+	req.Header.Set("X-Webhook-Timestamp", time.Now().Format(time.RFC3339))
+
+	secrets := sub.SigningSecrets(time.Now())
+	signatures := make([]string, len(secrets))
+	for i, secret := range secrets {
+		signatures[i] = signWebhookPayload(payload, secret)
+	}
+	req.Header.Set("X-Webhook-Signature", strings.Join(signatures, ","))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("endpoint unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint answered the ping with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("failed to read ping response: %w", err)
+	}
+
+	var echoed webhookPingResponse
+	if json.Unmarshal(body, &echoed) == nil && echoed.Challenge == challenge {
+		return nil
+	}
+	if strings.TrimSpace(string(body)) == challenge {
+		return nil
+	}
+	return fmt.Errorf("endpoint did not echo the ping challenge")
+}
@@ -0,0 +1,198 @@
+// Package storage keeps generated and uploaded files (exports, evidence)
+// behind one interface, so a service can move from local disk to an object
+// store by configuration alone.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/payment-api-service/config"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store is a flat key/value blob store. Keys are slash-separated paths such
+// as "exports/<id>.csv" and are relative to the configured prefix.
+type Store interface {
+	// Put writes size bytes from r under key, replacing any existing object
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object's content. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object. A missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time-limited GET link that bypasses this service.
+	// Backends that cannot hand out direct links return "" and false, and
+	// the caller streams the object from Open instead.
+	SignedURL(key, fileName string, ttl time.Duration) (string, bool)
+	// Backend names the implementation for logs
+	Backend() string
+}
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+)
+
+// gcsEndpoint is the S3-compatible XML API of Google Cloud Storage. It
+// takes HMAC keys created for a service account.
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// FromEnv builds the store named by STORAGE_BACKEND (local, s3 or gcs).
+// localDir is where the local backend writes when STORAGE_LOCAL_DIR is
+// unset, so each feature keeps its old directory until it is configured.
+func FromEnv(localDir string) (Store, error) {
+	backend := strings.ToLower(config.GetEnvWithDefault("STORAGE_BACKEND", BackendLocal))
+	if backend == BackendLocal {
+		return NewLocalStore(config.GetEnvWithDefault("STORAGE_LOCAL_DIR", localDir))
+	}
+
+	cfg := S3Config{
+		Bucket:          config.GetEnv("STORAGE_BUCKET"),
+		Prefix:          config.GetEnv("STORAGE_PREFIX"),
+		Endpoint:        config.GetEnv("STORAGE_ENDPOINT"),
+		Region:          config.GetEnv("STORAGE_REGION"),
+		AccessKeyID:     config.GetEnv("STORAGE_ACCESS_KEY_ID"),
+		SecretAccessKey: config.GetEnv("STORAGE_SECRET_ACCESS_KEY"),
+	}
+	switch backend {
+	case BackendS3:
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+		}
+	case BackendGCS:
+		if cfg.Region == "" {
+			cfg.Region = "auto"
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = gcsEndpoint
+		}
+	default:
+		return nil, fmt.Errorf("storage: unknown STORAGE_BACKEND %q", backend)
+	}
+	store, err := NewS3Store(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store.backend = backend
+	return store, nil
+}
+
+// cleanKey rejects keys that could escape the store's root
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(filepath.ToSlash(key), "/")
+	if key == "" {
+		return "", errors.New("storage: empty key")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return key, nil
+}
+
+// =========================================================================
+// Local Disk
+// =========================================================================
+
+// LocalStore keeps objects as files under a directory. With more than one
+// replica the directory must be a shared volume.
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, errors.New("storage: local directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("storage: failed to create %s: %w", dir, err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// Write beside the target and rename, so readers never see half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err == nil && size >= 0 && n != size {
+		err = fmt.Errorf("storage: wrote %d of %d bytes", n, size)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) SignedURL(key, fileName string, ttl time.Duration) (string, bool) {
+	return "", false
+}
+
+func (s *LocalStore) Backend() string {
+	return BackendLocal
+}
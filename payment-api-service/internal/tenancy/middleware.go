@@ -0,0 +1,20 @@
+package tenancy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/payment-api-service/internal/merchantctx"
+)
+
+// Middleware scopes the request context to the authenticated merchant and
+// to the mode of its API key, so repositories that pass the request context
+// get tenant predicates automatically and test keys never see live data.
+// It must run after merchantctx.Require.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if mc, ok := merchantctx.Get(c); ok {
+			ctx := WithMerchant(c.Request.Context(), mc.MerchantID)
+			c.Request = c.Request.WithContext(WithTestMode(ctx, mc.TestMode))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,372 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: proto/role_service.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PermissionCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionCheck) Reset() {
+	*x = PermissionCheck{}
+	mi := &file_proto_role_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionCheck) ProtoMessage() {}
+
+func (x *PermissionCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionCheck.ProtoReflect.Descriptor instead.
+func (*PermissionCheck) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PermissionCheck) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *PermissionCheck) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type PermissionCheckResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resource      string                 `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Allowed       bool                   `protobuf:"varint,3,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PermissionCheckResult) Reset() {
+	*x = PermissionCheckResult{}
+	mi := &file_proto_role_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PermissionCheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PermissionCheckResult) ProtoMessage() {}
+
+func (x *PermissionCheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PermissionCheckResult.ProtoReflect.Descriptor instead.
+func (*PermissionCheckResult) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PermissionCheckResult) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *PermissionCheckResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PermissionCheckResult) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+// known_version is the version from a previous response; when it still
+// matches, not_modified is set and permissions is left empty.
+type BatchCheckPermissionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Checks        []*PermissionCheck     `protobuf:"bytes,3,rep,name=checks,proto3" json:"checks,omitempty"`
+	KnownVersion  int64                  `protobuf:"varint,4,opt,name=known_version,json=knownVersion,proto3" json:"known_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckPermissionsRequest) Reset() {
+	*x = BatchCheckPermissionsRequest{}
+	mi := &file_proto_role_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckPermissionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckPermissionsRequest) ProtoMessage() {}
+
+func (x *BatchCheckPermissionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckPermissionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCheckPermissionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchCheckPermissionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsRequest) GetChecks() []*PermissionCheck {
+	if x != nil {
+		return x.Checks
+	}
+	return nil
+}
+
+func (x *BatchCheckPermissionsRequest) GetKnownVersion() int64 {
+	if x != nil {
+		return x.KnownVersion
+	}
+	return 0
+}
+
+type BatchCheckPermissionsResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	UserId        string                   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	MerchantId    string                   `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Results       []*PermissionCheckResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	Version       int64                    `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	NotModified   bool                     `protobuf:"varint,5,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	Permissions   []string                 `protobuf:"bytes,6,rep,name=permissions,proto3" json:"permissions,omitempty"` // "resource:action"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckPermissionsResponse) Reset() {
+	*x = BatchCheckPermissionsResponse{}
+	mi := &file_proto_role_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckPermissionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckPermissionsResponse) ProtoMessage() {}
+
+func (x *BatchCheckPermissionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_role_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckPermissionsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCheckPermissionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_role_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchCheckPermissionsResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *BatchCheckPermissionsResponse) GetResults() []*PermissionCheckResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchCheckPermissionsResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *BatchCheckPermissionsResponse) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+func (x *BatchCheckPermissionsResponse) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+var File_proto_role_service_proto protoreflect.FileDescriptor
+
+const file_proto_role_service_proto_rawDesc = "" +
+	"\n" +
+	"\x18proto/role_service.proto\x12\x05proto\"E\n" +
+	"\x0fPermissionCheck\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\"e\n" +
+	"\x15PermissionCheckResult\x12\x1a\n" +
+	"\bresource\x18\x01 \x01(\tR\bresource\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x18\n" +
+	"\aallowed\x18\x03 \x01(\bR\aallowed\"\xad\x01\n" +
+	"\x1cBatchCheckPermissionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12.\n" +
+	"\x06checks\x18\x03 \x03(\v2\x16.proto.PermissionCheckR\x06checks\x12#\n" +
+	"\rknown_version\x18\x04 \x01(\x03R\fknownVersion\"\xf0\x01\n" +
+	"\x1dBatchCheckPermissionsResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x126\n" +
+	"\aresults\x18\x03 \x03(\v2\x1c.proto.PermissionCheckResultR\aresults\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\x12!\n" +
+	"\fnot_modified\x18\x05 \x01(\bR\vnotModified\x12 \n" +
+	"\vpermissions\x18\x06 \x03(\tR\vpermissions2q\n" +
+	"\vRoleService\x12b\n" +
+	"\x15BatchCheckPermissions\x12#.proto.BatchCheckPermissionsRequest\x1a$.proto.BatchCheckPermissionsResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
+
+var (
+	file_proto_role_service_proto_rawDescOnce sync.Once
+	file_proto_role_service_proto_rawDescData []byte
+)
+
+func file_proto_role_service_proto_rawDescGZIP() []byte {
+	file_proto_role_service_proto_rawDescOnce.Do(func() {
+		file_proto_role_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_role_service_proto_rawDesc), len(file_proto_role_service_proto_rawDesc)))
+	})
+	return file_proto_role_service_proto_rawDescData
+}
+
+var file_proto_role_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_role_service_proto_goTypes = []any{
+	(*PermissionCheck)(nil),               // 0: proto.PermissionCheck
+	(*PermissionCheckResult)(nil),         // 1: proto.PermissionCheckResult
+	(*BatchCheckPermissionsRequest)(nil),  // 2: proto.BatchCheckPermissionsRequest
+	(*BatchCheckPermissionsResponse)(nil), // 3: proto.BatchCheckPermissionsResponse
+}
+var file_proto_role_service_proto_depIdxs = []int32{
+	0, // 0: proto.BatchCheckPermissionsRequest.checks:type_name -> proto.PermissionCheck
+	1, // 1: proto.BatchCheckPermissionsResponse.results:type_name -> proto.PermissionCheckResult
+	2, // 2: proto.RoleService.BatchCheckPermissions:input_type -> proto.BatchCheckPermissionsRequest
+	3, // 3: proto.RoleService.BatchCheckPermissions:output_type -> proto.BatchCheckPermissionsResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_role_service_proto_init() }
+func file_proto_role_service_proto_init() {
+	if File_proto_role_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_role_service_proto_rawDesc), len(file_proto_role_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_role_service_proto_goTypes,
+		DependencyIndexes: file_proto_role_service_proto_depIdxs,
+		MessageInfos:      file_proto_role_service_proto_msgTypes,
+	}.Build()
+	File_proto_role_service_proto = out.File
+	file_proto_role_service_proto_goTypes = nil
+	file_proto_role_service_proto_depIdxs = nil
+}
@@ -19,12 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TransactionService_Authorize_FullMethodName        = "/transaction.TransactionService/Authorize"
-	TransactionService_Capture_FullMethodName          = "/transaction.TransactionService/Capture"
-	TransactionService_Void_FullMethodName             = "/transaction.TransactionService/Void"
-	TransactionService_Refund_FullMethodName           = "/transaction.TransactionService/Refund"
-	TransactionService_GetTransaction_FullMethodName   = "/transaction.TransactionService/GetTransaction"
-	TransactionService_ListTransactions_FullMethodName = "/transaction.TransactionService/ListTransactions"
+	TransactionService_Authorize_FullMethodName              = "/transaction.TransactionService/Authorize"
+	TransactionService_Capture_FullMethodName                = "/transaction.TransactionService/Capture"
+	TransactionService_ListCaptures_FullMethodName           = "/transaction.TransactionService/ListCaptures"
+	TransactionService_Void_FullMethodName                   = "/transaction.TransactionService/Void"
+	TransactionService_Refund_FullMethodName                 = "/transaction.TransactionService/Refund"
+	TransactionService_GetTransaction_FullMethodName         = "/transaction.TransactionService/GetTransaction"
+	TransactionService_ListTransactions_FullMethodName       = "/transaction.TransactionService/ListTransactions"
+	TransactionService_GetSettlementBatch_FullMethodName     = "/transaction.TransactionService/GetSettlementBatch"
+	TransactionService_ListSettlementBatches_FullMethodName  = "/transaction.TransactionService/ListSettlementBatches"
+	TransactionService_CreateInstantPayout_FullMethodName    = "/transaction.TransactionService/CreateInstantPayout"
+	TransactionService_GetBalance_FullMethodName             = "/transaction.TransactionService/GetBalance"
+	TransactionService_GetStatistics_FullMethodName          = "/transaction.TransactionService/GetStatistics"
+	TransactionService_GetRefund_FullMethodName              = "/transaction.TransactionService/GetRefund"
+	TransactionService_ListRefunds_FullMethodName            = "/transaction.TransactionService/ListRefunds"
+	TransactionService_ListMerchantRefunds_FullMethodName    = "/transaction.TransactionService/ListMerchantRefunds"
+	TransactionService_GetRefundQueueStatus_FullMethodName   = "/transaction.TransactionService/GetRefundQueueStatus"
+	TransactionService_GetTransactionTimeline_FullMethodName = "/transaction.TransactionService/GetTransactionTimeline"
+	TransactionService_Authenticate_FullMethodName           = "/transaction.TransactionService/Authenticate"
+	TransactionService_CompleteAuthentication_FullMethodName = "/transaction.TransactionService/CompleteAuthentication"
+	TransactionService_AddTransactionNote_FullMethodName     = "/transaction.TransactionService/AddTransactionNote"
+	TransactionService_ListTransactionNotes_FullMethodName   = "/transaction.TransactionService/ListTransactionNotes"
+	TransactionService_DeleteTransactionNote_FullMethodName  = "/transaction.TransactionService/DeleteTransactionNote"
+	TransactionService_AddTransactionTags_FullMethodName     = "/transaction.TransactionService/AddTransactionTags"
+	TransactionService_RemoveTransactionTag_FullMethodName   = "/transaction.TransactionService/RemoveTransactionTag"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
@@ -35,10 +53,39 @@ const (
 type TransactionServiceClient interface {
 	Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error)
 	Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (*CaptureResponse, error)
+	// Capture history of one authorization
+	ListCaptures(ctx context.Context, in *ListCapturesRequest, opts ...grpc.CallOption) (*ListCapturesResponse, error)
 	Void(ctx context.Context, in *VoidRequest, opts ...grpc.CallOption) (*VoidResponse, error)
 	Refund(ctx context.Context, in *RefundRequest, opts ...grpc.CallOption) (*RefundResponse, error)
 	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
 	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	GetSettlementBatch(ctx context.Context, in *GetSettlementBatchRequest, opts ...grpc.CallOption) (*SettlementBatchResponse, error)
+	ListSettlementBatches(ctx context.Context, in *ListSettlementBatchesRequest, opts ...grpc.CallOption) (*ListSettlementBatchesResponse, error)
+	// Expedited payout of captured funds for a fee; dry_run only quotes it
+	CreateInstantPayout(ctx context.Context, in *CreateInstantPayoutRequest, opts ...grpc.CallOption) (*InstantPayoutResponse, error)
+	// Merchant balance: available, rolling reserve and not yet batched
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	// Totals of a merchant's payments over local calendar days, with daily or weekly buckets
+	GetStatistics(ctx context.Context, in *GetStatisticsRequest, opts ...grpc.CallOption) (*StatisticsResponse, error)
+	GetRefund(ctx context.Context, in *GetRefundRequest, opts ...grpc.CallOption) (*RefundDetailResponse, error)
+	ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error)
+	// ListMerchantRefunds pages through all of a merchant's refunds with filters
+	ListMerchantRefunds(ctx context.Context, in *ListMerchantRefundsRequest, opts ...grpc.CallOption) (*ListMerchantRefundsResponse, error)
+	// Progress of the merchant's refunds waiting for a refund slot
+	GetRefundQueueStatus(ctx context.Context, in *GetRefundQueueStatusRequest, opts ...grpc.CallOption) (*RefundQueueStatusResponse, error)
+	// Events, issuer responses and routing of one transaction, for compliance bundles
+	GetTransactionTimeline(ctx context.Context, in *GetTransactionTimelineRequest, opts ...grpc.CallOption) (*TransactionTimelineResponse, error)
+	// 3-D Secure authentication, run before Authorize when the merchant asks for it
+	Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error)
+	// Result of a challenge the cardholder completed at the issuer's ACS
+	CompleteAuthentication(ctx context.Context, in *CompleteAuthenticationRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error)
+	// Internal notes support teams leave on a transaction during investigations
+	AddTransactionNote(ctx context.Context, in *AddTransactionNoteRequest, opts ...grpc.CallOption) (*TransactionNoteResponse, error)
+	ListTransactionNotes(ctx context.Context, in *ListTransactionNotesRequest, opts ...grpc.CallOption) (*ListTransactionNotesResponse, error)
+	DeleteTransactionNote(ctx context.Context, in *DeleteTransactionNoteRequest, opts ...grpc.CallOption) (*DeleteTransactionNoteResponse, error)
+	// Internal tags, searchable through ListTransactions
+	AddTransactionTags(ctx context.Context, in *AddTransactionTagsRequest, opts ...grpc.CallOption) (*TransactionTagsResponse, error)
+	RemoveTransactionTag(ctx context.Context, in *RemoveTransactionTagRequest, opts ...grpc.CallOption) (*TransactionTagsResponse, error)
 }
 
 type transactionServiceClient struct {
@@ -69,6 +116,16 @@ func (c *transactionServiceClient) Capture(ctx context.Context, in *CaptureReque
 	return out, nil
 }
 
+func (c *transactionServiceClient) ListCaptures(ctx context.Context, in *ListCapturesRequest, opts ...grpc.CallOption) (*ListCapturesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCapturesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListCaptures_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *transactionServiceClient) Void(ctx context.Context, in *VoidRequest, opts ...grpc.CallOption) (*VoidResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(VoidResponse)
@@ -109,6 +166,176 @@ func (c *transactionServiceClient) ListTransactions(ctx context.Context, in *Lis
 	return out, nil
 }
 
+func (c *transactionServiceClient) GetSettlementBatch(ctx context.Context, in *GetSettlementBatchRequest, opts ...grpc.CallOption) (*SettlementBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SettlementBatchResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetSettlementBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListSettlementBatches(ctx context.Context, in *ListSettlementBatchesRequest, opts ...grpc.CallOption) (*ListSettlementBatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSettlementBatchesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListSettlementBatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateInstantPayout(ctx context.Context, in *CreateInstantPayoutRequest, opts ...grpc.CallOption) (*InstantPayoutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InstantPayoutResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateInstantPayout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetStatistics(ctx context.Context, in *GetStatisticsRequest, opts ...grpc.CallOption) (*StatisticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatisticsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetStatistics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetRefund(ctx context.Context, in *GetRefundRequest, opts ...grpc.CallOption) (*RefundDetailResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundDetailResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetRefund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListRefunds(ctx context.Context, in *ListRefundsRequest, opts ...grpc.CallOption) (*ListRefundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRefundsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListRefunds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListMerchantRefunds(ctx context.Context, in *ListMerchantRefundsRequest, opts ...grpc.CallOption) (*ListMerchantRefundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMerchantRefundsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListMerchantRefunds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetRefundQueueStatus(ctx context.Context, in *GetRefundQueueStatusRequest, opts ...grpc.CallOption) (*RefundQueueStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundQueueStatusResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetRefundQueueStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetTransactionTimeline(ctx context.Context, in *GetTransactionTimelineRequest, opts ...grpc.CallOption) (*TransactionTimelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionTimelineResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetTransactionTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthenticateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_Authenticate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CompleteAuthentication(ctx context.Context, in *CompleteAuthenticationRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthenticateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CompleteAuthentication_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) AddTransactionNote(ctx context.Context, in *AddTransactionNoteRequest, opts ...grpc.CallOption) (*TransactionNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionNoteResponse)
+	err := c.cc.Invoke(ctx, TransactionService_AddTransactionNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListTransactionNotes(ctx context.Context, in *ListTransactionNotesRequest, opts ...grpc.CallOption) (*ListTransactionNotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTransactionNotesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListTransactionNotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) DeleteTransactionNote(ctx context.Context, in *DeleteTransactionNoteRequest, opts ...grpc.CallOption) (*DeleteTransactionNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTransactionNoteResponse)
+	err := c.cc.Invoke(ctx, TransactionService_DeleteTransactionNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) AddTransactionTags(ctx context.Context, in *AddTransactionTagsRequest, opts ...grpc.CallOption) (*TransactionTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionTagsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_AddTransactionTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) RemoveTransactionTag(ctx context.Context, in *RemoveTransactionTagRequest, opts ...grpc.CallOption) (*TransactionTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionTagsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_RemoveTransactionTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TransactionServiceServer is the server API for TransactionService service.
 // All implementations must embed UnimplementedTransactionServiceServer
 // for forward compatibility.
@@ -117,10 +344,39 @@ func (c *transactionServiceClient) ListTransactions(ctx context.Context, in *Lis
 type TransactionServiceServer interface {
 	Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error)
 	Capture(context.Context, *CaptureRequest) (*CaptureResponse, error)
+	// Capture history of one authorization
+	ListCaptures(context.Context, *ListCapturesRequest) (*ListCapturesResponse, error)
 	Void(context.Context, *VoidRequest) (*VoidResponse, error)
 	Refund(context.Context, *RefundRequest) (*RefundResponse, error)
 	GetTransaction(context.Context, *GetTransactionRequest) (*TransactionResponse, error)
 	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	GetSettlementBatch(context.Context, *GetSettlementBatchRequest) (*SettlementBatchResponse, error)
+	ListSettlementBatches(context.Context, *ListSettlementBatchesRequest) (*ListSettlementBatchesResponse, error)
+	// Expedited payout of captured funds for a fee; dry_run only quotes it
+	CreateInstantPayout(context.Context, *CreateInstantPayoutRequest) (*InstantPayoutResponse, error)
+	// Merchant balance: available, rolling reserve and not yet batched
+	GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error)
+	// Totals of a merchant's payments over local calendar days, with daily or weekly buckets
+	GetStatistics(context.Context, *GetStatisticsRequest) (*StatisticsResponse, error)
+	GetRefund(context.Context, *GetRefundRequest) (*RefundDetailResponse, error)
+	ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error)
+	// ListMerchantRefunds pages through all of a merchant's refunds with filters
+	ListMerchantRefunds(context.Context, *ListMerchantRefundsRequest) (*ListMerchantRefundsResponse, error)
+	// Progress of the merchant's refunds waiting for a refund slot
+	GetRefundQueueStatus(context.Context, *GetRefundQueueStatusRequest) (*RefundQueueStatusResponse, error)
+	// Events, issuer responses and routing of one transaction, for compliance bundles
+	GetTransactionTimeline(context.Context, *GetTransactionTimelineRequest) (*TransactionTimelineResponse, error)
+	// 3-D Secure authentication, run before Authorize when the merchant asks for it
+	Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateResponse, error)
+	// Result of a challenge the cardholder completed at the issuer's ACS
+	CompleteAuthentication(context.Context, *CompleteAuthenticationRequest) (*AuthenticateResponse, error)
+	// Internal notes support teams leave on a transaction during investigations
+	AddTransactionNote(context.Context, *AddTransactionNoteRequest) (*TransactionNoteResponse, error)
+	ListTransactionNotes(context.Context, *ListTransactionNotesRequest) (*ListTransactionNotesResponse, error)
+	DeleteTransactionNote(context.Context, *DeleteTransactionNoteRequest) (*DeleteTransactionNoteResponse, error)
+	// Internal tags, searchable through ListTransactions
+	AddTransactionTags(context.Context, *AddTransactionTagsRequest) (*TransactionTagsResponse, error)
+	RemoveTransactionTag(context.Context, *RemoveTransactionTagRequest) (*TransactionTagsResponse, error)
 	mustEmbedUnimplementedTransactionServiceServer()
 }
 
@@ -137,6 +393,9 @@ func (UnimplementedTransactionServiceServer) Authorize(context.Context, *Authori
 func (UnimplementedTransactionServiceServer) Capture(context.Context, *CaptureRequest) (*CaptureResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Capture not implemented")
 }
+func (UnimplementedTransactionServiceServer) ListCaptures(context.Context, *ListCapturesRequest) (*ListCapturesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCaptures not implemented")
+}
 func (UnimplementedTransactionServiceServer) Void(context.Context, *VoidRequest) (*VoidResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Void not implemented")
 }
@@ -149,6 +408,57 @@ func (UnimplementedTransactionServiceServer) GetTransaction(context.Context, *Ge
 func (UnimplementedTransactionServiceServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListTransactions not implemented")
 }
+func (UnimplementedTransactionServiceServer) GetSettlementBatch(context.Context, *GetSettlementBatchRequest) (*SettlementBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSettlementBatch not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListSettlementBatches(context.Context, *ListSettlementBatchesRequest) (*ListSettlementBatchesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSettlementBatches not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateInstantPayout(context.Context, *CreateInstantPayoutRequest) (*InstantPayoutResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateInstantPayout not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetStatistics(context.Context, *GetStatisticsRequest) (*StatisticsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStatistics not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetRefund(context.Context, *GetRefundRequest) (*RefundDetailResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRefund not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListRefunds(context.Context, *ListRefundsRequest) (*ListRefundsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRefunds not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListMerchantRefunds(context.Context, *ListMerchantRefundsRequest) (*ListMerchantRefundsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMerchantRefunds not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetRefundQueueStatus(context.Context, *GetRefundQueueStatusRequest) (*RefundQueueStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRefundQueueStatus not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransactionTimeline(context.Context, *GetTransactionTimelineRequest) (*TransactionTimelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTransactionTimeline not implemented")
+}
+func (UnimplementedTransactionServiceServer) Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Authenticate not implemented")
+}
+func (UnimplementedTransactionServiceServer) CompleteAuthentication(context.Context, *CompleteAuthenticationRequest) (*AuthenticateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CompleteAuthentication not implemented")
+}
+func (UnimplementedTransactionServiceServer) AddTransactionNote(context.Context, *AddTransactionNoteRequest) (*TransactionNoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddTransactionNote not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListTransactionNotes(context.Context, *ListTransactionNotesRequest) (*ListTransactionNotesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTransactionNotes not implemented")
+}
+func (UnimplementedTransactionServiceServer) DeleteTransactionNote(context.Context, *DeleteTransactionNoteRequest) (*DeleteTransactionNoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTransactionNote not implemented")
+}
+func (UnimplementedTransactionServiceServer) AddTransactionTags(context.Context, *AddTransactionTagsRequest) (*TransactionTagsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddTransactionTags not implemented")
+}
+func (UnimplementedTransactionServiceServer) RemoveTransactionTag(context.Context, *RemoveTransactionTagRequest) (*TransactionTagsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveTransactionTag not implemented")
+}
 func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
 func (UnimplementedTransactionServiceServer) testEmbeddedByValue()                            {}
 
@@ -206,6 +516,24 @@ func _TransactionService_Capture_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TransactionService_ListCaptures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCapturesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListCaptures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListCaptures_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListCaptures(ctx, req.(*ListCapturesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TransactionService_Void_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VoidRequest)
 	if err := dec(in); err != nil {
@@ -278,36 +606,716 @@ func _TransactionService_ListTransactions_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
-// TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var TransactionService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "transaction.TransactionService",
-	HandlerType: (*TransactionServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Authorize",
-			Handler:    _TransactionService_Authorize_Handler,
-		},
-		{
-			MethodName: "Capture",
-			Handler:    _TransactionService_Capture_Handler,
-		},
-		{
-			MethodName: "Void",
-			Handler:    _TransactionService_Void_Handler,
-		},
-		{
-			MethodName: "Refund",
-			Handler:    _TransactionService_Refund_Handler,
-		},
-		{
-			MethodName: "GetTransaction",
-			Handler:    _TransactionService_GetTransaction_Handler,
-		},
-		{
-			MethodName: "ListTransactions",
-			Handler:    _TransactionService_ListTransactions_Handler,
+func _TransactionService_GetSettlementBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSettlementBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetSettlementBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetSettlementBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetSettlementBatch(ctx, req.(*GetSettlementBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListSettlementBatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSettlementBatchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListSettlementBatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListSettlementBatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListSettlementBatches(ctx, req.(*ListSettlementBatchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CreateInstantPayout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateInstantPayoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateInstantPayout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateInstantPayout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateInstantPayout(ctx, req.(*CreateInstantPayoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetStatistics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatisticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetStatistics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetStatistics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetStatistics(ctx, req.(*GetStatisticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetRefund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRefundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetRefund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetRefund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetRefund(ctx, req.(*GetRefundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListRefunds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRefundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListRefunds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListRefunds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListRefunds(ctx, req.(*ListRefundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListMerchantRefunds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMerchantRefundsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListMerchantRefunds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListMerchantRefunds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListMerchantRefunds(ctx, req.(*ListMerchantRefundsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetRefundQueueStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRefundQueueStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetRefundQueueStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetRefundQueueStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetRefundQueueStatus(ctx, req.(*GetRefundQueueStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransactionTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransactionTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransactionTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransactionTimeline(ctx, req.(*GetTransactionTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_Authenticate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthenticateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).Authenticate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_Authenticate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).Authenticate(ctx, req.(*AuthenticateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CompleteAuthentication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteAuthenticationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CompleteAuthentication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CompleteAuthentication_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CompleteAuthentication(ctx, req.(*CompleteAuthenticationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_AddTransactionNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTransactionNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).AddTransactionNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_AddTransactionNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).AddTransactionNote(ctx, req.(*AddTransactionNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListTransactionNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListTransactionNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListTransactionNotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListTransactionNotes(ctx, req.(*ListTransactionNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_DeleteTransactionNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTransactionNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).DeleteTransactionNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_DeleteTransactionNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).DeleteTransactionNote(ctx, req.(*DeleteTransactionNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_AddTransactionTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTransactionTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).AddTransactionTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_AddTransactionTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).AddTransactionTags(ctx, req.(*AddTransactionTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_RemoveTransactionTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTransactionTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).RemoveTransactionTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_RemoveTransactionTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).RemoveTransactionTag(ctx, req.(*RemoveTransactionTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TransactionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transaction.TransactionService",
+	HandlerType: (*TransactionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Authorize",
+			Handler:    _TransactionService_Authorize_Handler,
+		},
+		{
+			MethodName: "Capture",
+			Handler:    _TransactionService_Capture_Handler,
+		},
+		{
+			MethodName: "ListCaptures",
+			Handler:    _TransactionService_ListCaptures_Handler,
+		},
+		{
+			MethodName: "Void",
+			Handler:    _TransactionService_Void_Handler,
+		},
+		{
+			MethodName: "Refund",
+			Handler:    _TransactionService_Refund_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _TransactionService_GetTransaction_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _TransactionService_ListTransactions_Handler,
+		},
+		{
+			MethodName: "GetSettlementBatch",
+			Handler:    _TransactionService_GetSettlementBatch_Handler,
+		},
+		{
+			MethodName: "ListSettlementBatches",
+			Handler:    _TransactionService_ListSettlementBatches_Handler,
+		},
+		{
+			MethodName: "CreateInstantPayout",
+			Handler:    _TransactionService_CreateInstantPayout_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _TransactionService_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetStatistics",
+			Handler:    _TransactionService_GetStatistics_Handler,
+		},
+		{
+			MethodName: "GetRefund",
+			Handler:    _TransactionService_GetRefund_Handler,
+		},
+		{
+			MethodName: "ListRefunds",
+			Handler:    _TransactionService_ListRefunds_Handler,
+		},
+		{
+			MethodName: "ListMerchantRefunds",
+			Handler:    _TransactionService_ListMerchantRefunds_Handler,
+		},
+		{
+			MethodName: "GetRefundQueueStatus",
+			Handler:    _TransactionService_GetRefundQueueStatus_Handler,
+		},
+		{
+			MethodName: "GetTransactionTimeline",
+			Handler:    _TransactionService_GetTransactionTimeline_Handler,
+		},
+		{
+			MethodName: "Authenticate",
+			Handler:    _TransactionService_Authenticate_Handler,
+		},
+		{
+			MethodName: "CompleteAuthentication",
+			Handler:    _TransactionService_CompleteAuthentication_Handler,
+		},
+		{
+			MethodName: "AddTransactionNote",
+			Handler:    _TransactionService_AddTransactionNote_Handler,
+		},
+		{
+			MethodName: "ListTransactionNotes",
+			Handler:    _TransactionService_ListTransactionNotes_Handler,
+		},
+		{
+			MethodName: "DeleteTransactionNote",
+			Handler:    _TransactionService_DeleteTransactionNote_Handler,
+		},
+		{
+			MethodName: "AddTransactionTags",
+			Handler:    _TransactionService_AddTransactionTags_Handler,
+		},
+		{
+			MethodName: "RemoveTransactionTag",
+			Handler:    _TransactionService_RemoveTransactionTag_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/transaction.proto",
+}
+
+const (
+	ChargebackService_ListDisputes_FullMethodName           = "/transaction.ChargebackService/ListDisputes"
+	ChargebackService_GetDispute_FullMethodName             = "/transaction.ChargebackService/GetDispute"
+	ChargebackService_UploadDisputeEvidence_FullMethodName  = "/transaction.ChargebackService/UploadDisputeEvidence"
+	ChargebackService_GetDisputeEvidenceFile_FullMethodName = "/transaction.ChargebackService/GetDisputeEvidenceFile"
+	ChargebackService_SubmitDisputeEvidence_FullMethodName  = "/transaction.ChargebackService/SubmitDisputeEvidence"
+	ChargebackService_AcceptDispute_FullMethodName          = "/transaction.ChargebackService/AcceptDispute"
+)
+
+// ChargebackServiceClient is the client API for ChargebackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChargebackService lets merchants answer disputes raised by issuers
+type ChargebackServiceClient interface {
+	ListDisputes(ctx context.Context, in *ListDisputesRequest, opts ...grpc.CallOption) (*ListDisputesResponse, error)
+	GetDispute(ctx context.Context, in *GetDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	// Attach a document before submitting evidence
+	UploadDisputeEvidence(ctx context.Context, in *UploadDisputeEvidenceRequest, opts ...grpc.CallOption) (*DisputeEvidenceFileResponse, error)
+	// One evidence document, with content
+	GetDisputeEvidenceFile(ctx context.Context, in *GetDisputeEvidenceFileRequest, opts ...grpc.CallOption) (*DisputeEvidenceFileResponse, error)
+	// Contest the dispute with evidence and any uploaded documents
+	SubmitDisputeEvidence(ctx context.Context, in *SubmitDisputeEvidenceRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+	AcceptDispute(ctx context.Context, in *AcceptDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error)
+}
+
+type chargebackServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChargebackServiceClient(cc grpc.ClientConnInterface) ChargebackServiceClient {
+	return &chargebackServiceClient{cc}
+}
+
+func (c *chargebackServiceClient) ListDisputes(ctx context.Context, in *ListDisputesRequest, opts ...grpc.CallOption) (*ListDisputesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDisputesResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_ListDisputes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chargebackServiceClient) GetDispute(ctx context.Context, in *GetDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_GetDispute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chargebackServiceClient) UploadDisputeEvidence(ctx context.Context, in *UploadDisputeEvidenceRequest, opts ...grpc.CallOption) (*DisputeEvidenceFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeEvidenceFileResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_UploadDisputeEvidence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chargebackServiceClient) GetDisputeEvidenceFile(ctx context.Context, in *GetDisputeEvidenceFileRequest, opts ...grpc.CallOption) (*DisputeEvidenceFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeEvidenceFileResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_GetDisputeEvidenceFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chargebackServiceClient) SubmitDisputeEvidence(ctx context.Context, in *SubmitDisputeEvidenceRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_SubmitDisputeEvidence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chargebackServiceClient) AcceptDispute(ctx context.Context, in *AcceptDisputeRequest, opts ...grpc.CallOption) (*DisputeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DisputeResponse)
+	err := c.cc.Invoke(ctx, ChargebackService_AcceptDispute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChargebackServiceServer is the server API for ChargebackService service.
+// All implementations must embed UnimplementedChargebackServiceServer
+// for forward compatibility.
+//
+// ChargebackService lets merchants answer disputes raised by issuers
+type ChargebackServiceServer interface {
+	ListDisputes(context.Context, *ListDisputesRequest) (*ListDisputesResponse, error)
+	GetDispute(context.Context, *GetDisputeRequest) (*DisputeResponse, error)
+	// Attach a document before submitting evidence
+	UploadDisputeEvidence(context.Context, *UploadDisputeEvidenceRequest) (*DisputeEvidenceFileResponse, error)
+	// One evidence document, with content
+	GetDisputeEvidenceFile(context.Context, *GetDisputeEvidenceFileRequest) (*DisputeEvidenceFileResponse, error)
+	// Contest the dispute with evidence and any uploaded documents
+	SubmitDisputeEvidence(context.Context, *SubmitDisputeEvidenceRequest) (*DisputeResponse, error)
+	AcceptDispute(context.Context, *AcceptDisputeRequest) (*DisputeResponse, error)
+	mustEmbedUnimplementedChargebackServiceServer()
+}
+
+// UnimplementedChargebackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChargebackServiceServer struct{}
+
+func (UnimplementedChargebackServiceServer) ListDisputes(context.Context, *ListDisputesRequest) (*ListDisputesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDisputes not implemented")
+}
+func (UnimplementedChargebackServiceServer) GetDispute(context.Context, *GetDisputeRequest) (*DisputeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDispute not implemented")
+}
+func (UnimplementedChargebackServiceServer) UploadDisputeEvidence(context.Context, *UploadDisputeEvidenceRequest) (*DisputeEvidenceFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UploadDisputeEvidence not implemented")
+}
+func (UnimplementedChargebackServiceServer) GetDisputeEvidenceFile(context.Context, *GetDisputeEvidenceFileRequest) (*DisputeEvidenceFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDisputeEvidenceFile not implemented")
+}
+func (UnimplementedChargebackServiceServer) SubmitDisputeEvidence(context.Context, *SubmitDisputeEvidenceRequest) (*DisputeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitDisputeEvidence not implemented")
+}
+func (UnimplementedChargebackServiceServer) AcceptDispute(context.Context, *AcceptDisputeRequest) (*DisputeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcceptDispute not implemented")
+}
+func (UnimplementedChargebackServiceServer) mustEmbedUnimplementedChargebackServiceServer() {}
+func (UnimplementedChargebackServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeChargebackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChargebackServiceServer will
+// result in compilation errors.
+type UnsafeChargebackServiceServer interface {
+	mustEmbedUnimplementedChargebackServiceServer()
+}
+
+func RegisterChargebackServiceServer(s grpc.ServiceRegistrar, srv ChargebackServiceServer) {
+	// If the following call panics, it indicates UnimplementedChargebackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChargebackService_ServiceDesc, srv)
+}
+
+func _ChargebackService_ListDisputes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDisputesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).ListDisputes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_ListDisputes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).ListDisputes(ctx, req.(*ListDisputesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChargebackService_GetDispute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDisputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).GetDispute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_GetDispute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).GetDispute(ctx, req.(*GetDisputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChargebackService_UploadDisputeEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadDisputeEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).UploadDisputeEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_UploadDisputeEvidence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).UploadDisputeEvidence(ctx, req.(*UploadDisputeEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChargebackService_GetDisputeEvidenceFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDisputeEvidenceFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).GetDisputeEvidenceFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_GetDisputeEvidenceFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).GetDisputeEvidenceFile(ctx, req.(*GetDisputeEvidenceFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChargebackService_SubmitDisputeEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitDisputeEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).SubmitDisputeEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_SubmitDisputeEvidence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).SubmitDisputeEvidence(ctx, req.(*SubmitDisputeEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChargebackService_AcceptDispute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcceptDisputeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChargebackServiceServer).AcceptDispute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChargebackService_AcceptDispute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChargebackServiceServer).AcceptDispute(ctx, req.(*AcceptDisputeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChargebackService_ServiceDesc is the grpc.ServiceDesc for ChargebackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChargebackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transaction.ChargebackService",
+	HandlerType: (*ChargebackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDisputes",
+			Handler:    _ChargebackService_ListDisputes_Handler,
+		},
+		{
+			MethodName: "GetDispute",
+			Handler:    _ChargebackService_GetDispute_Handler,
+		},
+		{
+			MethodName: "UploadDisputeEvidence",
+			Handler:    _ChargebackService_UploadDisputeEvidence_Handler,
+		},
+		{
+			MethodName: "GetDisputeEvidenceFile",
+			Handler:    _ChargebackService_GetDisputeEvidenceFile_Handler,
+		},
+		{
+			MethodName: "SubmitDisputeEvidence",
+			Handler:    _ChargebackService_SubmitDisputeEvidence_Handler,
+		},
+		{
+			MethodName: "AcceptDispute",
+			Handler:    _ChargebackService_AcceptDispute_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,211 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// User is a dashboard account
+type User struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Status        string `json:"status"`
+}
+
+// Session is a signed-in user's tokens. Pass AccessToken to
+// WithAccessToken; once it expires, Auth.Refresh trades RefreshToken for a
+// new session.
+type Session struct {
+	User         *User  `json:"user,omitempty"` // Only set by Login
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Seconds
+}
+
+// ExpiresAt is when the access token of a session issued at issued stops
+// working
+func (s *Session) ExpiresAt(issued time.Time) time.Time {
+	return issued.Add(time.Duration(s.ExpiresIn) * time.Second)
+}
+
+// AuthService calls /api/v1/auth
+type AuthService struct {
+	client *Client
+}
+
+// Register creates a dashboard account. The user then signs in with Login.
+func (s *AuthService) Register(ctx context.Context, name, email, password string, opts ...RequestOption) (*User, error) {
+	var data struct {
+		User *User `json:"user"`
+	}
+	body := map[string]string{"name": name, "email": email, "password": password}
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/auth/register", body: body, public: true}, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data.User, nil
+}
+
+// Login signs a user in with their email and password
+func (s *AuthService) Login(ctx context.Context, email, password string, opts ...RequestOption) (*Session, error) {
+	return s.session(ctx, "/api/v1/auth/login", map[string]string{"email": email, "password": password}, opts)
+}
+
+// Refresh trades a session's refresh token for a new session
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, opts ...RequestOption) (*Session, error) {
+	return s.session(ctx, "/api/v1/auth/refresh", map[string]string{"refresh_token": refreshToken}, opts)
+}
+
+// Logout revokes the client's access token
+func (s *AuthService) Logout(ctx context.Context, opts ...RequestOption) error {
+	return s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/auth/logout"}, nil, opts...)
+}
+
+func (s *AuthService) session(ctx context.Context, path string, body any, opts []RequestOption) (*Session, error) {
+	var session Session
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: path, body: body, public: true}, &session, opts...); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Merchant is a business taking payments. A user owns at most one.
+type Merchant struct {
+	ID           string    `json:"id"`
+	MerchantCode string    `json:"merchant_code"`
+	BusinessName string    `json:"business_name"`
+	LegalName    string    `json:"legal_name"`
+	Email        string    `json:"email"`
+	Status       string    `json:"status"`
+	BusinessType string    `json:"business_type"`
+	CountryCode  string    `json:"country_code"`
+	CurrencyCode string    `json:"currency_code"`
+	Timezone     string    `json:"timezone"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MerchantParams creates a merchant. BusinessType is individual,
+// sole_proprietor, partnership, corporation or non_profit.
+type MerchantParams struct {
+	BusinessName string `json:"business_name"`
+	LegalName    string `json:"legal_name,omitempty"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone,omitempty"`
+	Website      string `json:"website,omitempty"`
+	BusinessType string `json:"business_type"`
+}
+
+// APIKeyParams creates an API key. An empty AllowedCIDRs accepts any
+// address.
+type APIKeyParams struct {
+	MerchantID   string   `json:"merchant_id"`
+	Name         string   `json:"name"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
+	TestMode     bool     `json:"test_mode"`
+}
+
+// APIKey is a merchant API key. Key, the secret itself, is only returned
+// by CreateAPIKey.
+type APIKey struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	KeyPrefix    string   `json:"key_prefix"`
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+	CreatedAt    string   `json:"created_at"`
+	Key          string   `json:"-"`
+}
+
+// MerchantsService calls /api/v1/merchants. It needs WithAccessToken.
+type MerchantsService struct {
+	client *Client
+}
+
+// Create creates the signed-in user's merchant
+func (s *MerchantsService) Create(ctx context.Context, params *MerchantParams, opts ...RequestOption) (*Merchant, error) {
+	var data struct {
+		Merchant *Merchant `json:"merchant"`
+	}
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/merchants", body: params}, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data.Merchant, nil
+}
+
+// List returns the merchants the signed-in user belongs to
+func (s *MerchantsService) List(ctx context.Context, opts ...RequestOption) ([]*Merchant, error) {
+	var data struct {
+		Merchants []*Merchant `json:"merchants"`
+	}
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/merchants"}, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data.Merchants, nil
+}
+
+// CreateAPIKey creates an API key for one of the user's merchants. Keep
+// the returned Key: it can't be read again.
+func (s *MerchantsService) CreateAPIKey(ctx context.Context, params *APIKeyParams, opts ...RequestOption) (*APIKey, error) {
+	var data struct {
+		APIKey   *APIKey `json:"api_key"`
+		PlainKey string  `json:"plain_key"`
+	}
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/merchants/api-keys", body: params}, &data, opts...); err != nil {
+		return nil, err
+	}
+	if data.APIKey == nil {
+		data.APIKey = &APIKey{}
+	}
+	data.APIKey.Key = data.PlainKey
+	return data.APIKey, nil
+}
+
+// OnboardingStep is one item of the onboarding checklist. Status is
+// complete, incomplete or unknown, when the service that knows could not be
+// reached.
+type OnboardingStep struct {
+	Key         string     `json:"key"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Link        string     `json:"link"`
+}
+
+// OnboardingStatus is the checklist of a user and their merchant. NextStep
+// is the key of the first step left to do.
+type OnboardingStatus struct {
+	MerchantID string            `json:"merchant_id"`
+	Steps      []*OnboardingStep `json:"steps"`
+	Completed  int               `json:"completed"`
+	Total      int               `json:"total"`
+	NextStep   string            `json:"next_step,omitempty"`
+}
+
+// Onboarding step statuses
+const (
+	OnboardingStepComplete   = "complete"
+	OnboardingStepIncomplete = "incomplete"
+	OnboardingStepUnknown    = "unknown"
+)
+
+// OnboardingService calls /api/v1/onboarding. It needs WithAccessToken.
+type OnboardingService struct {
+	client *Client
+}
+
+// Status returns the checklist for merchantID, or for the user's oldest
+// merchant when it is empty
+func (s *OnboardingService) Status(ctx context.Context, merchantID string, opts ...RequestOption) (*OnboardingStatus, error) {
+	query := url.Values{}
+	if merchantID != "" {
+		query.Set("merchant_id", merchantID)
+	}
+
+	var status OnboardingStatus
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/onboarding/status", query: query}, &status, opts...); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
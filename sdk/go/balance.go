@@ -0,0 +1,32 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+)
+
+// Balance is what the merchant's next payout draws on, in MAD minor units.
+// Available is negative while refunds and chargebacks outrun captures.
+type Balance struct {
+	MerchantID        string `json:"merchant_id"`
+	Currency          string `json:"currency"`
+	Available         int64  `json:"available"`
+	Reserve           int64  `json:"reserve"`             // Rolling reserve not yet released
+	Pending           int64  `json:"pending"`             // Captured, net of fees, not yet in a settlement batch
+	PendingCount      int32  `json:"pending_count"`       // Payments and refunds in Pending
+	NextReleaseAt     string `json:"next_release_at"`     // RFC 3339; empty without a reserve
+	NextReleaseAmount int64  `json:"next_release_amount"` // Reserve released at NextReleaseAt
+}
+
+// BalanceService calls /api/v1/balance
+type BalanceService struct {
+	client *Client
+}
+
+func (s *BalanceService) Get(ctx context.Context, opts ...RequestOption) (*Balance, error) {
+	var balance Balance
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/balance"}, &balance, opts...); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
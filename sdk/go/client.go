@@ -0,0 +1,362 @@
+// Package paymentgateway is a Go client for the payment gateway's public
+// REST API: payments, payment intents, refunds, disputes, sandbox card
+// tokens and webhook signature checks, plus the account routes a signed-in
+// user calls to set up a merchant.
+package paymentgateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the API gateway of a local deployment
+const DefaultBaseURL = "http://localhost:8080"
+
+const (
+	defaultMaxRetries = 2
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 8 * time.Second
+	defaultTimeout    = 60 * time.Second
+)
+
+// Client calls the API with one merchant API key, or as a signed-in user
+// for the account routes. It is safe for concurrent use.
+type Client struct {
+	apiKey      string
+	accessToken string
+	baseURL     string
+	httpClient  *http.Client
+	maxRetries  int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+
+	Payments       *PaymentsService
+	PaymentIntents *PaymentIntentsService
+	Refunds        *RefundsService
+	Disputes       *DisputesService
+	Tokens         *TokensService
+	Reports        *ReportsService
+	Balance        *BalanceService
+	Auth           *AuthService
+	Merchants      *MerchantsService
+	Onboarding     *OnboardingService
+
+	WebhookSubscriptions *WebhookSubscriptionsService
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at another gateway, e.g. https://api.example.com
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(baseURL, "/") }
+}
+
+// WithHTTPClient replaces the default http.Client, which times out after 60s
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithAccessToken signs the client in as a user, with an access token from
+// Auth.Login, for the account routes: merchants and onboarding. Pass an
+// empty API key to New when the client only calls those.
+func WithAccessToken(token string) Option {
+	return func(c *Client) { c.accessToken = token }
+}
+
+// WithMaxRetries sets how many times a failed request is retried. 0 turns
+// retries off.
+func WithMaxRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = max(n, 0) }
+}
+
+// WithBackoff sets the first retry delay and the cap it doubles up to
+func WithBackoff(minDelay, maxDelay time.Duration) Option {
+	return func(c *Client) { c.minBackoff, c.maxBackoff = minDelay, maxDelay }
+}
+
+// TestMode reports whether the client uses a sandbox (pg_test_) key
+func (c *Client) TestMode() bool {
+	return strings.HasPrefix(c.apiKey, "pg_test_")
+}
+
+// New creates a client for the merchant behind apiKey (pg_live_ or pg_test_)
+func New(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:     apiKey,
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.Payments = &PaymentsService{client: c}
+	c.PaymentIntents = &PaymentIntentsService{client: c}
+	c.Refunds = &RefundsService{client: c}
+	c.Disputes = &DisputesService{client: c}
+	c.Tokens = &TokensService{client: c}
+	c.Reports = &ReportsService{client: c}
+	c.Balance = &BalanceService{client: c}
+	c.Auth = &AuthService{client: c}
+	c.Merchants = &MerchantsService{client: c}
+	c.Onboarding = &OnboardingService{client: c}
+	c.WebhookSubscriptions = &WebhookSubscriptionsService{client: c}
+	return c
+}
+
+// APIError is an error response from the API
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"error"`
+	Code       string `json:"code,omitempty"`
+	RequestID  string `json:"-"`
+
+	// Raw is the whole response body, for fields this package doesn't map
+	Raw json.RawMessage `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("payment gateway: %d %s (%s)", e.StatusCode, e.Message, e.Code)
+	}
+	return fmt.Sprintf("payment gateway: %d %s", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the same request may succeed if sent again
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// RequestOption changes a single call
+type RequestOption func(*request)
+
+// WithIdempotencyKey sends key instead of a generated one. Use it to make
+// a call safe to repeat across process restarts. Keys are 16-255
+// characters.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(r *request) { r.idempotencyKey = key }
+}
+
+// WithHeader adds a header to the call, e.g. Accept-Language
+func WithHeader(name, value string) RequestOption {
+	return func(r *request) { r.header.Set(name, value) }
+}
+
+type request struct {
+	method         string
+	path           string
+	query          url.Values
+	body           any
+	public         bool // public routes are called without the API key or an Idempotency-Key
+	idempotencyKey string
+	header         http.Header
+}
+
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// do sends the request and decodes the data field of the envelope into out.
+// POSTs to /api/v1 always carry an Idempotency-Key, kept across retries, so
+// a retry after a lost response can't charge the card twice. Public POSTs
+// have no idempotency on the server and are never retried.
+func (c *Client) do(ctx context.Context, req *request, out any, opts ...RequestOption) error {
+	req.header = http.Header{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	maxRetries := c.maxRetries
+	if req.method == http.MethodPost {
+		if req.public {
+			maxRetries = 0
+		} else if req.idempotencyKey == "" {
+			req.idempotencyKey = newIdempotencyKey()
+		}
+	}
+
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("payment gateway: encode request: %w", err)
+		}
+	}
+
+	endpoint := c.baseURL + req.path
+	if len(req.query) > 0 {
+		endpoint += "?" + req.query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req, endpoint, payload)
+		if err == nil {
+			err = decode(resp, out)
+		}
+		if err == nil || attempt >= maxRetries || !retryable(ctx, err) {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(after, c.maxBackoff)
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request, endpoint string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("payment gateway: build request: %w", err)
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if !req.public && c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+	}
+	if !req.public && c.accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	if req.idempotencyKey != "" && !req.public {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &networkError{err: err}
+	}
+	return resp, nil
+}
+
+func decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &networkError{err: err}
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			RequestID:  resp.Header.Get("X-Request-ID"),
+			Raw:        raw,
+		}
+		if json.Unmarshal(raw, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return decodeError(err)
+	}
+	if len(env.Data) == 0 || string(env.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return decodeError(err)
+	}
+	return nil
+}
+
+func decodeError(err error) error {
+	return fmt.Errorf("payment gateway: decode response: %w", err)
+}
+
+// networkError wraps a failure to get a response at all
+type networkError struct{ err error }
+
+func (e *networkError) Error() string { return "payment gateway: " + e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// backoff doubles from minBackoff up to maxBackoff, with up to 50% jitter
+// taken off so clients that failed together don't retry together
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := time.Duration(float64(c.minBackoff) * math.Pow(2, float64(attempt)))
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	var b [1]byte
+	if _, err := rand.Read(b[:]); err == nil {
+		delay -= delay / 2 * time.Duration(b[0]) / 255
+	}
+	return delay
+}
+
+// retryAfter reads Retry-After as seconds or an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func newIdempotencyKey() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand doesn't fail on supported platforms; fall back to the
+		// clock so the request still goes out with a unique key
+		return "sdk_" + strconv.FormatInt(time.Now().UnixNano(), 36) + "_retry"
+	}
+	return "sdk_" + hex.EncodeToString(b[:])
+}
+
+func pathID(id string) string {
+	return url.PathEscape(id)
+}
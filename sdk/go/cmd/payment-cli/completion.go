@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+	"github.com/rhaloubi/payment-gateway/sdk/go/scenario"
+)
+
+// completeCommand is the hidden command the completion scripts run with
+// the words typed so far, the last one being the word to complete. It
+// prints a candidate per line, as the value, a tab and a description.
+const completeCommand = "__complete"
+
+// completer returns the candidates for a flag value or an argument. fs
+// holds the flags typed so far, so API lookups use the same key and URL
+// the command will.
+type completer func(ctx context.Context, fs *flag.FlagSet) []string
+
+// completionScripts delegate to completeCommand, so dynamic candidates
+// come from the same code in every shell
+var completionScripts = map[string]string{
+	"bash": `# bash completion for payment-cli
+# Load it with: source <(payment-cli completion bash)
+_payment_cli() {
+	local IFS=$'\n' line
+	COMPREPLY=()
+	for line in $(payment-cli __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+		COMPREPLY+=("${line%%$'\t'*}")
+	done
+}
+complete -o default -F _payment_cli payment-cli
+`,
+	"zsh": `#compdef payment-cli
+# zsh completion for payment-cli
+# Load it with: source <(payment-cli completion zsh)
+_payment_cli() {
+	local -a candidates
+	local line value description
+	for line in "${(@f)$(payment-cli __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+		[[ -n $line ]] || continue
+		value=${${line%%$'\t'*}//:/\\:}
+		description=${line#*$'\t'}
+		if [[ -n $description ]]; then
+			candidates+=("$value:$description")
+		else
+			candidates+=("$value")
+		fi
+	done
+	(( ${#candidates} )) && _describe 'payment-cli' candidates || _files
+}
+compdef _payment_cli payment-cli
+`,
+	"fish": `# fish completion for payment-cli
+# Load it with: payment-cli completion fish | source
+function __payment_cli_complete
+	set -l words (commandline -opc)[2..-1] (commandline -ct)
+	payment-cli __complete $words 2>/dev/null
+end
+complete -c payment-cli -f -a '(__payment_cli_complete)'
+`,
+	"powershell": `# powershell completion for payment-cli
+# Load it with: payment-cli completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName 'payment-cli' -ScriptBlock {
+	param($WordToComplete, $CommandAst, $CursorPosition)
+	# Legacy passing turns '""' into an empty argument on every version
+	$PSNativeCommandArgumentPassing = 'Legacy'
+	$words = @($CommandAst.CommandElements | Select-Object -Skip 1 |
+		Where-Object { $_.Extent.StartOffset -lt $CursorPosition } |
+		ForEach-Object { $_.ToString() })
+	if ($WordToComplete -eq '') { $words += '""' }
+	payment-cli __complete @words 2>$null | ForEach-Object {
+		$value, $description = $_ -split "` + "`" + `t", 2
+		if (-not $description) { $description = $value }
+		[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+	}
+}
+`,
+}
+
+func setupCompletion(*flag.FlagSet) func(args []string) {
+	return func(args []string) {
+		if len(args) != 1 {
+			fail("completion takes one shell: bash, zsh, fish or powershell")
+		}
+		script, ok := completionScripts[args[0]]
+		if !ok {
+			fail("no completion for %q; pick bash, zsh, fish or powershell", args[0])
+		}
+		fmt.Print(script)
+	}
+}
+
+// runComplete prints the candidates for the last of words. It stays quiet
+// on errors: a failed lookup just completes nothing.
+func runComplete(words []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	current := words[len(words)-1]
+	for _, candidate := range complete(ctx, words[:len(words)-1], current) {
+		if strings.HasPrefix(candidate, current) {
+			if !strings.Contains(candidate, "\t") {
+				candidate += "\t"
+			}
+			fmt.Println(candidate)
+		}
+	}
+}
+
+func complete(ctx context.Context, typed []string, current string) []string {
+	cmd, path := root, root.name
+	for cmd.setup == nil {
+		if len(typed) == 0 {
+			var candidates []string
+			for _, sub := range cmd.subcommands {
+				candidates = append(candidates, sub.name+"\t"+sub.description)
+			}
+			return candidates
+		}
+		cmd = findSubcommand(cmd, typed[0])
+		if cmd == nil {
+			return nil
+		}
+		path += " " + typed[0]
+		typed = typed[1:]
+	}
+
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cmd.setup(fs)
+	// A trailing flag still waiting for its value fails to parse, but the
+	// flags before it are set all the same
+	parseErr := fs.Parse(typed)
+
+	if parseErr != nil || fs.NArg() == 0 {
+		if len(typed) > 0 {
+			if f := valueFlag(fs, typed[len(typed)-1]); f != nil {
+				if values := cmd.completeFlags[f.Name]; values != nil {
+					return values(ctx, fs)
+				}
+				return nil
+			}
+		}
+		if strings.HasPrefix(current, "-") {
+			var candidates []string
+			fs.VisitAll(func(f *flag.Flag) {
+				candidates = append(candidates, "-"+f.Name+"\t"+f.Usage)
+			})
+			return candidates
+		}
+	}
+	if cmd.completeArgs == nil {
+		return nil
+	}
+	return cmd.completeArgs(ctx, fs)
+}
+
+func findSubcommand(cmd *command, name string) *command {
+	for _, sub := range cmd.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// valueFlag returns the flag named by word when it takes its value from the
+// next word
+func valueFlag(fs *flag.FlagSet, word string) *flag.Flag {
+	name, ok := strings.CutPrefix(word, "-")
+	if !ok || strings.Contains(name, "=") {
+		return nil
+	}
+	f := fs.Lookup(strings.TrimPrefix(name, "-"))
+	if f == nil {
+		return nil
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return nil
+	}
+	return f
+}
+
+func completeWords(words ...string) completer {
+	return func(context.Context, *flag.FlagSet) []string {
+		return words
+	}
+}
+
+func completePaymentStatuses(context.Context, *flag.FlagSet) []string {
+	return []string{
+		paymentgateway.PaymentStatusPending,
+		paymentgateway.PaymentStatusRequiresAction,
+		paymentgateway.PaymentStatusAuthorized,
+		paymentgateway.PaymentStatusCaptured,
+		paymentgateway.PaymentStatusPartiallyCaptured,
+		paymentgateway.PaymentStatusVoided,
+		paymentgateway.PaymentStatusRefunded,
+		paymentgateway.PaymentStatusFailed,
+	}
+}
+
+func completeScenarios(context.Context, *flag.FlagSet) []string {
+	var candidates []string
+	for _, s := range scenario.All() {
+		candidates = append(candidates, s.Name+"\t"+s.Description)
+	}
+	return candidates
+}
+
+// completePayments offers the key's latest payments
+func completePayments(ctx context.Context, fs *flag.FlagSet) []string {
+	apiKey := flagValue(fs, "key")
+	if apiKey == "" {
+		return nil
+	}
+	client := paymentgateway.New(apiKey, paymentgateway.WithBaseURL(flagValue(fs, "base-url")))
+	list, err := client.Payments.List(ctx, &paymentgateway.ListPaymentsParams{Limit: 50})
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	for _, p := range list.Payments {
+		candidates = append(candidates, fmt.Sprintf("%s\t%s %s %s, %s", p.ID, formatAmount(p.Amount), p.Currency, p.Status,
+			p.CreatedAt.Local().Format(time.DateTime)))
+	}
+	return candidates
+}
+
+// completeMerchants offers the signed-in user's merchants
+func completeMerchants(ctx context.Context, fs *flag.FlagSet) []string {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	account, err := accountClient(ctx, cfg, flagValue(fs, "base-url"))
+	if err != nil {
+		return nil
+	}
+	merchants, err := account.Merchants.List(ctx)
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	for _, m := range merchants {
+		candidates = append(candidates, m.ID+"\t"+m.BusinessName)
+	}
+	return candidates
+}
+
+func flagValue(fs *flag.FlagSet, name string) string {
+	if f := fs.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// demoState is how far the demo got, kept in the config file so an
+// interrupted demo picks up at the step it stopped on
+type demoState struct {
+	RunID      string `json:"run_id"` // Keys the demo's payment requests
+	Currency   string `json:"currency"`
+	Amount     int64  `json:"amount"`
+	Email      string `json:"email"`
+	Registered bool   `json:"registered"`
+	MerchantID string `json:"merchant_id"`
+	APIKey     string `json:"api_key"` // Test-mode key created for the demo
+	CardToken  string `json:"card_token"`
+	CardLabel  string `json:"card_label"`
+	PaymentID  string `json:"payment_id"`
+	RefundID   string `json:"refund_id"`
+	Finished   bool   `json:"finished"`
+}
+
+// demo is one run of the wizard
+type demo struct {
+	cfg      *config
+	state    *demoState
+	baseURL  string
+	password string // Asked for once per run, never saved
+}
+
+type demoStep struct {
+	title string
+	done  bool
+	run   func(ctx context.Context) error
+}
+
+// steps lists the tour in order, marking those an earlier run finished
+func (d *demo) steps() []demoStep {
+	return []demoStep{
+		{"Create an account", d.state.Registered, d.register},
+		{"Sign in", d.cfg.AccessToken != "" && d.cfg.Email == d.state.Email, d.login},
+		{"Create a merchant", d.state.MerchantID != "", d.createMerchant},
+		{"Create a test API key", d.state.APIKey != "", d.createAPIKey},
+		{"Tokenize a test card", d.state.CardToken != "", d.tokenizeCard},
+		{"Charge the card", d.state.PaymentID != "", d.sale},
+		{"Refund half of it", d.state.RefundID != "", d.refund},
+		{"View the settlement", d.state.Finished, d.settlement},
+	}
+}
+
+// setupDemo walks a new user through the gateway, from signing up to the
+// settlement of a refunded sale
+func setupDemo(flags *flag.FlagSet) func(args []string) {
+	baseURL := accountFlags(flags)
+	restart := flags.Bool("restart", false, "forget the progress of an earlier demo and start over")
+	currency := flags.String("currency", "USD", "currency of the demo payment; a resumed demo keeps its own")
+	amount := flags.Int64("amount", 5000, "amount of the demo payment, in minor units; a resumed demo keeps its own")
+
+	return func([]string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fail("%v", err)
+		}
+		if cfg.Demo == nil || *restart || cfg.Demo.Finished {
+			cfg.Demo = &demoState{RunID: newRunID(), Currency: *currency, Amount: *amount}
+		}
+		d := &demo{cfg: cfg, state: cfg.Demo, baseURL: *baseURL}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		for i, step := range d.steps() {
+			if step.done {
+				fmt.Printf("[x] %d. %s\n", i+1, step.title)
+				continue
+			}
+			fmt.Printf("\n==> %d. %s\n", i+1, step.title)
+			if err := step.run(ctx); err != nil {
+				fail("%s: %v\nRun payment-cli demo again to retry from this step.", strings.ToLower(step.title), err)
+			}
+			if err := cfg.save(); err != nil {
+				fail("%v", err)
+			}
+		}
+		fmt.Println("\nThat's the whole flow. Run payment-cli demo -restart to go through it again.")
+	}
+}
+
+func (d *demo) register(ctx context.Context) error {
+	name := prompt("Your name", "Demo User")
+	d.state.Email = prompt("Email", d.cfg.Email)
+	d.password = promptPassword("Password (at least 8 characters)")
+
+	_, err := d.publicClient().Auth.Register(ctx, name, d.state.Email, d.password)
+	var apiErr *paymentgateway.APIError
+	switch {
+	case errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "already registered"):
+		fmt.Println("That email already has an account; the demo signs in to it.")
+	case err != nil:
+		return err
+	default:
+		fmt.Printf("Registered %s. Email verification is one of the onboarding steps; the demo doesn't need it.\n", d.state.Email)
+	}
+	d.state.Registered = true
+	return nil
+}
+
+func (d *demo) login(ctx context.Context) error {
+	if d.password == "" {
+		d.password = promptPassword(fmt.Sprintf("Password for %s", d.state.Email))
+	}
+	session, err := d.publicClient().Auth.Login(ctx, d.state.Email, d.password)
+	if err != nil {
+		return err
+	}
+	d.cfg.signIn(d.state.Email, session, d.baseURL)
+	fmt.Printf("Signed in; the session is kept for the other account commands.\n")
+	return nil
+}
+
+func (d *demo) createMerchant(ctx context.Context) error {
+	account, err := accountClient(ctx, d.cfg, d.baseURL)
+	if err != nil {
+		return err
+	}
+
+	// An account owns one merchant; reuse it when it already has one
+	merchants, err := account.Merchants.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(merchants) > 0 {
+		d.state.MerchantID = merchants[0].ID
+		fmt.Printf("Using your merchant %s (%s)\n", merchants[0].BusinessName, merchants[0].ID)
+		return nil
+	}
+
+	merchant, err := account.Merchants.Create(ctx, &paymentgateway.MerchantParams{
+		BusinessName: prompt("Business name", "Demo Shop"),
+		Email:        prompt("Business email", d.state.Email),
+		BusinessType: "individual",
+	})
+	if err != nil {
+		return err
+	}
+	d.state.MerchantID = merchant.ID
+	fmt.Printf("Created merchant %s (%s), status %s\n", merchant.BusinessName, merchant.ID, merchant.Status)
+	return nil
+}
+
+func (d *demo) createAPIKey(ctx context.Context) error {
+	account, err := accountClient(ctx, d.cfg, d.baseURL)
+	if err != nil {
+		return err
+	}
+	key, err := account.Merchants.CreateAPIKey(ctx, &paymentgateway.APIKeyParams{
+		MerchantID: d.state.MerchantID,
+		Name:       "payment-cli demo",
+		TestMode:   true,
+	})
+	if err != nil {
+		return err
+	}
+	if key.Key == "" {
+		return errors.New("the response has no key")
+	}
+	d.state.APIKey = key.Key
+	fmt.Printf("Created test key %s...; the demo keeps it to take payments.\n", key.KeyPrefix)
+	fmt.Println("Use it with the other commands: export PAYMENT_API_KEY=" + key.Key)
+	return nil
+}
+
+func (d *demo) tokenizeCard(ctx context.Context) error {
+	cards, err := d.merchantClient().Tokens.SeedSandbox(ctx)
+	if err != nil {
+		return err
+	}
+	for _, card := range cards {
+		if card.Scenario == "approved" {
+			d.state.CardToken = card.Token
+			d.state.CardLabel = fmt.Sprintf("%s •••• %s", card.Brand, card.Last4)
+			fmt.Printf("Tokenized the sandbox cards; the demo pays with %s (%s)\n", d.state.CardLabel, card.Token)
+			return nil
+		}
+	}
+	return errors.New("the sandbox has no approved test card")
+}
+
+func (d *demo) sale(ctx context.Context) error {
+	// Keyed to this run of the demo, so retrying the step after a lost
+	// response returns the same payment instead of charging again
+	payment, err := d.merchantClient().Payments.Sale(ctx, &paymentgateway.AuthorizeRequest{
+		Amount:      d.state.Amount,
+		Currency:    d.state.Currency,
+		CardToken:   d.state.CardToken,
+		Description: "payment-cli demo",
+	}, paymentgateway.WithIdempotencyKey("payment-cli-demo-sale-"+d.state.RunID))
+	if err != nil {
+		return err
+	}
+	if payment.Status != paymentgateway.PaymentStatusCaptured {
+		return fmt.Errorf("payment %s is %s, not captured: %s", payment.ID, payment.Status, payment.ResponseMsg)
+	}
+	d.state.PaymentID = payment.ID
+	fmt.Printf("Charged %s %s to %s: payment %s, auth code %s, fraud score %d\n",
+		formatAmount(payment.Amount), payment.Currency, d.state.CardLabel, payment.ID, payment.AuthCode, payment.FraudScore)
+	return nil
+}
+
+func (d *demo) refund(ctx context.Context) error {
+	result, err := d.merchantClient().Payments.Refund(ctx, d.state.PaymentID, &paymentgateway.RefundRequest{
+		Amount:     d.state.Amount / 2,
+		Currency:   d.state.Currency,
+		Reason:     "payment-cli demo",
+		ReasonCode: "requested_by_customer",
+	}, paymentgateway.WithIdempotencyKey("payment-cli-demo-refund-"+d.state.RunID))
+	if err != nil {
+		return err
+	}
+	if result.Approval != nil {
+		d.state.RefundID = result.Approval.ID
+		fmt.Printf("The refund is above the merchant's approval threshold and waits for approval (%s)\n", result.Approval.ID)
+		return nil
+	}
+	if result.Payment == nil || result.Payment.Refund == nil {
+		return errors.New("the response has no refund")
+	}
+	refund := result.Payment.Refund
+	d.state.RefundID = refund.ID
+	fmt.Printf("Refunded %s %s: refund %s is %s\n", formatAmount(d.state.Amount/2), d.state.Currency, refund.ID, refund.Status)
+	return nil
+}
+
+func (d *demo) settlement(ctx context.Context) error {
+	client := d.merchantClient()
+	today := time.Now().Format(time.DateOnly)
+	summary, err := client.Reports.Summary(ctx, &paymentgateway.ReportParams{From: today, To: today})
+	if err != nil {
+		return err
+	}
+	balance, err := client.Balance.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Today in test mode: %s payments, %s %s captured, %s %s refunded\n",
+		formatCount(summary.Count), formatAmount(summary.CapturedAmount), summary.Currency,
+		formatAmount(summary.RefundedAmount), summary.Currency)
+	fmt.Printf("Balance: %s %s available, %s %s pending in %d transactions, %s %s in reserve\n",
+		formatAmount(balance.Available), balance.Currency, formatAmount(balance.Pending), balance.Currency,
+		balance.PendingCount, formatAmount(balance.Reserve), balance.Currency)
+	fmt.Println("Test payments are never batched, so they don't reach the balance. In live mode the sale, net of")
+	fmt.Println("fees and the refund, would be pending until the next daily settlement batch pays it out.")
+	d.state.Finished = true
+	return nil
+}
+
+func newRunID() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// publicClient calls the routes that need neither a key nor a session
+func (d *demo) publicClient() *paymentgateway.Client {
+	return paymentgateway.New("", paymentgateway.WithBaseURL(d.baseURL))
+}
+
+// merchantClient calls the API with the demo's test key
+func (d *demo) merchantClient() *paymentgateway.Client {
+	return paymentgateway.New(d.state.APIKey, paymentgateway.WithBaseURL(d.baseURL))
+}
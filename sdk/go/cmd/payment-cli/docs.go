@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// docPage is one command of the tree, documented on a page of its own
+type docPage struct {
+	cmd    *command
+	path   string
+	parent *docPage
+	flags  *flag.FlagSet // Nil for a group
+}
+
+// setupDocs writes a page per command, as man pages for man1 or as
+// markdown for a docs site
+func setupDocs(flags *flag.FlagSet) func(args []string) {
+	dir := flags.String("dir", ".", "directory to write the pages to")
+
+	return func(args []string) {
+		if len(args) != 1 || (args[0] != "man" && args[0] != "markdown") {
+			fail("docs takes one format: man or markdown")
+		}
+		// Flag defaults come from the environment, which must not end up
+		// in the pages: the API key above all
+		os.Unsetenv("PAYMENT_API_KEY")
+		os.Unsetenv("PAYMENT_API_URL")
+
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			fail("%v", err)
+		}
+		pages := docPages(root, root.name, nil)
+		for _, page := range pages {
+			name, content := page.markdown()
+			if args[0] == "man" {
+				name, content = page.man()
+			}
+			if err := os.WriteFile(filepath.Join(*dir, name), content, 0o644); err != nil {
+				fail("%v", err)
+			}
+		}
+		fmt.Printf("Wrote %d pages to %s\n", len(pages), *dir)
+	}
+}
+
+func docPages(cmd *command, path string, parent *docPage) []*docPage {
+	page := &docPage{cmd: cmd, path: path, parent: parent}
+	if cmd.setup != nil {
+		page.flags = flag.NewFlagSet(path, flag.ContinueOnError)
+		cmd.setup(page.flags)
+	}
+	pages := []*docPage{page}
+	for _, sub := range cmd.subcommands {
+		pages = append(pages, docPages(sub, path+" "+sub.name, page)...)
+	}
+	return pages
+}
+
+// fileName is the page's name without extension: payment-cli-payment-list
+func (p *docPage) fileName() string {
+	return strings.ReplaceAll(p.path, " ", "-")
+}
+
+func (p *docPage) synopsis() string {
+	if p.flags == nil {
+		return p.path + " <command>"
+	}
+	return strings.TrimSpace(p.path + " [flags] " + p.cmd.args)
+}
+
+func (p *docPage) markdown() (string, []byte) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## %s\n\n%s\n\n```\n%s\n```\n", p.path, sentence(p.cmd.description), p.synopsis())
+	if p.flags != nil {
+		var defaults bytes.Buffer
+		p.flags.SetOutput(&defaults)
+		p.flags.PrintDefaults()
+		if defaults.Len() > 0 {
+			fmt.Fprintf(&b, "\n### Flags\n\n```\n%s```\n", defaults.String())
+		}
+	}
+	if len(p.cmd.subcommands) > 0 {
+		b.WriteString("\n### Commands\n\n")
+		for _, sub := range p.cmd.subcommands {
+			path := p.path + " " + sub.name
+			fmt.Fprintf(&b, "* [%s](%s.md) - %s\n", path, strings.ReplaceAll(path, " ", "-"), sub.description)
+		}
+	}
+	if p.parent != nil {
+		fmt.Fprintf(&b, "\n### See also\n\n* [%s](%s.md) - %s\n", p.parent.path, p.parent.fileName(), p.parent.cmd.description)
+	}
+	return p.fileName() + ".md", b.Bytes()
+}
+
+func (p *docPage) man() (string, []byte) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, ".TH %q 1 \"\" %q %q\n", strings.ToUpper(p.fileName()), root.name, root.name+" manual")
+	fmt.Fprintf(&b, ".SH NAME\n%s \\- %s\n", roff(p.fileName()), roff(p.cmd.description))
+	fmt.Fprintf(&b, ".SH SYNOPSIS\n\\fB%s\\fR%s\n", roff(p.path), roff(strings.TrimPrefix(p.synopsis(), p.path)))
+	fmt.Fprintf(&b, ".SH DESCRIPTION\n%s\n", roff(sentence(p.cmd.description)))
+	if p.flags != nil {
+		var options bytes.Buffer
+		p.flags.VisitAll(func(f *flag.Flag) {
+			typeName, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(&options, ".TP\n\\fB\\-%s\\fR", roff(f.Name))
+			if typeName != "" {
+				fmt.Fprintf(&options, " \\fI%s\\fR", typeName)
+			}
+			if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" && f.DefValue != "0s" {
+				if typeName == "string" {
+					usage += fmt.Sprintf(" (default %q)", f.DefValue)
+				} else {
+					usage += fmt.Sprintf(" (default %s)", f.DefValue)
+				}
+			}
+			fmt.Fprintf(&options, "\n%s\n", roff(usage))
+		})
+		if options.Len() > 0 {
+			fmt.Fprintf(&b, ".SH OPTIONS\n%s", options.String())
+		}
+	}
+	if len(p.cmd.subcommands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range p.cmd.subcommands {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roff(sub.name), roff(sub.description))
+		}
+	}
+
+	var related []string
+	if p.parent != nil {
+		related = append(related, fmt.Sprintf("\\fB%s\\fR(1)", roff(p.parent.fileName())))
+	}
+	for _, sub := range p.cmd.subcommands {
+		related = append(related, fmt.Sprintf("\\fB%s\\fR(1)", roff(p.fileName()+"-"+sub.name)))
+	}
+	if len(related) > 0 {
+		fmt.Fprintf(&b, ".SH SEE ALSO\n%s\n", strings.Join(related, ", "))
+	}
+	return p.fileName() + ".1", b.Bytes()
+}
+
+// roff escapes text for a man page line
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// sentence turns a command description into a sentence
+func sentence(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:] + "."
+}
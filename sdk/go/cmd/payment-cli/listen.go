@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/sdk/go/listener"
+)
+
+// setupListen registers a temporary webhook subscription for the key's
+// mode, prints each event as it arrives and deletes the subscription on
+// Ctrl-C
+func setupListen(flags *flag.FlagSet) func(args []string) {
+	baseURL, apiKey := apiFlags(flags, "API key")
+	addr := flags.String("addr", "127.0.0.1:4242", "local address to receive webhooks on")
+	publicURL := flags.String("public-url", "", "URL the payment API delivers to, when it can't reach -addr directly")
+	forwardTo := flags.String("forward-to", "", "URL to forward verified events to")
+	events := flags.String("events", "", "comma-separated event types; all when empty")
+	printData := flags.Bool("print-json", false, "print each event's data")
+
+	return func([]string) {
+		cfg := listener.Config{
+			Client:     newClient(*baseURL, *apiKey),
+			Addr:       *addr,
+			PublicURL:  *publicURL,
+			ForwardTo:  *forwardTo,
+			EventTypes: splitList(*events),
+			OnDelivery: func(d *listener.Delivery) { printDelivery(d, *printData) },
+		}
+		runListen(cfg)
+	}
+}
+
+func runListen(cfg listener.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	l, err := listener.Listen(ctx, cfg)
+	if err != nil {
+		fail("%v", err)
+	}
+	fmt.Printf("Ready. Listening for %s events on %s\n", l.Subscription.Mode, l.Subscription.URL)
+	fmt.Printf("Webhook signing secret: %s\n", l.Subscription.Secret)
+	if cfg.ForwardTo != "" {
+		fmt.Printf("Forwarding to %s\n", cfg.ForwardTo)
+	}
+
+	<-ctx.Done()
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.Close(shutdown); err != nil {
+		fail("failed to remove webhook subscription %s: %v", l.Subscription.ID, err)
+	}
+	fmt.Println("\nRemoved the temporary webhook subscription")
+}
+
+func printDelivery(d *listener.Delivery, printData bool) {
+	at := d.ReceivedAt.Format("15:04:05")
+	if d.Err != nil {
+		fmt.Printf("%s  rejected: %v\n", at, d.Err)
+		return
+	}
+
+	line := fmt.Sprintf("%s  --> %-34s [%s]", at, d.Event.Event, d.Event.ID)
+	switch {
+	case d.ForwardErr != nil:
+		line += fmt.Sprintf("  forward failed: %v", d.ForwardErr)
+	case d.ForwardStatus != 0:
+		line += fmt.Sprintf("  <-- %d (%s)", d.ForwardStatus, d.ForwardTime.Round(time.Millisecond))
+	}
+	fmt.Println(line)
+
+	if printData {
+		data, _ := json.MarshalIndent(d.Event.Data, "      ", "  ")
+		fmt.Printf("      %s\n", data)
+	}
+}
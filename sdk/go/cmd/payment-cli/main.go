@@ -0,0 +1,162 @@
+// Command payment-cli works with the payment gateway from a terminal:
+//
+//	go run ./cmd/payment-cli login && go run ./cmd/payment-cli onboarding
+//	PAYMENT_API_KEY=pg_test_... go run ./cmd/payment-cli listen -forward-to http://localhost:3000/webhooks
+//	PAYMENT_API_KEY=pg_test_... go run ./cmd/payment-cli test scenario run chargeback
+//	PAYMENT_API_KEY=pg_live_... go run ./cmd/payment-cli report -from 2024-01-01 -to 2024-03-31 -interval week
+//
+// Run a command with -h for its flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// command is a node of the command tree: either a group of subcommands or
+// a command that runs
+type command struct {
+	name        string
+	args        string // Positional arguments, for usage
+	description string
+	// setup defines the command's flags on fs and returns what runs it
+	// with the arguments left after them
+	setup       func(fs *flag.FlagSet) func(args []string)
+	subcommands []*command
+
+	// For shell completion: the candidates for positional arguments, and
+	// for the values of flags by name
+	completeArgs  completer
+	completeFlags map[string]completer
+}
+
+// root is set in init because the completion and docs commands walk it
+var root *command
+
+func init() {
+	root = &command{
+		name:        "payment-cli",
+		description: "work with the payment gateway from a terminal",
+		subcommands: []*command{
+			{name: "demo", description: "guided tour from sign-up to a settled refund, resumable", setup: setupDemo},
+			{name: "login", description: "sign in to the dashboard account, for the account commands", setup: setupLogin},
+			{name: "logout", description: "sign out and forget the stored session", setup: setupLogout},
+			{name: "onboarding", description: "the account's onboarding checklist", setup: setupOnboarding,
+				completeFlags: map[string]completer{"merchant": completeMerchants}},
+			{name: "listen", description: "receive webhooks locally and forward them to a development server", setup: setupListen},
+			{name: "payment", description: "the payments made with the API key", subcommands: []*command{
+				{name: "list", description: "a page of payments, newest first, optionally redrawn as they come in", setup: setupPaymentList,
+					completeFlags: map[string]completer{"status": completePaymentStatuses}},
+				{name: "get", args: "<payment-id>", description: "one payment", setup: setupPaymentGet,
+					completeArgs: completePayments},
+			}},
+			{name: "report", description: "payment statistics over a range of days, as a table and chart", setup: setupReport,
+				completeFlags: map[string]completer{"interval": completeWords(paymentgateway.ReportIntervalDay, paymentgateway.ReportIntervalWeek)}},
+			{name: "test", description: "sandbox tooling", subcommands: []*command{
+				{name: "scenario", description: "canned flows that smoke-test an integration", subcommands: []*command{
+					{name: "run", args: "[scenario...]", description: "run scenarios against the sandbox, every one when none are named", setup: setupScenarioRun,
+						completeArgs: completeScenarios},
+					{name: "list", description: "list the scenarios", setup: setupScenarioList},
+				}},
+			}},
+			{name: "completion", args: "bash|zsh|fish|powershell", description: "print the shell completion script", setup: setupCompletion,
+				completeArgs: completeWords("bash", "zsh", "fish", "powershell")},
+			{name: "docs", args: "man|markdown", description: "write a man page or markdown file per command", setup: setupDocs,
+				completeArgs: completeWords("man", "markdown")},
+		},
+	}
+}
+
+func main() {
+	// The completion scripts call back with the words typed so far
+	if len(os.Args) > 1 && os.Args[1] == completeCommand {
+		runComplete(os.Args[2:])
+		return
+	}
+	dispatch(root, root.name, os.Args[1:])
+}
+
+// dispatch walks args down the tree from cmd, whose full name is path, and
+// runs the command they name
+func dispatch(cmd *command, path string, args []string) {
+	if cmd.setup != nil {
+		fs := flag.NewFlagSet(path, flag.ExitOnError)
+		run := cmd.setup(fs)
+		fs.Usage = func() { commandUsage(cmd, path, fs) }
+		fs.Parse(args)
+		run(fs.Args())
+		return
+	}
+
+	if len(args) == 0 {
+		groupUsage(cmd, path)
+		os.Exit(2)
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		groupUsage(cmd, path)
+		return
+	}
+	for _, sub := range cmd.subcommands {
+		if sub.name == name {
+			dispatch(sub, path+" "+name, args[1:])
+			return
+		}
+	}
+	fail("unknown command %q; run %s help", name, path)
+}
+
+func groupUsage(cmd *command, path string) {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags]\n", path)
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, sub := range cmd.subcommands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", sub.name, sub.description)
+	}
+}
+
+func commandUsage(cmd *command, path string, fs *flag.FlagSet) {
+	fmt.Fprintf(os.Stderr, "usage: %s\n\n%s\n\nflags:\n", strings.TrimSpace(path+" [flags] "+cmd.args), cmd.description)
+	fs.PrintDefaults()
+}
+
+// apiFlags defines the flags every API command takes
+func apiFlags(fs *flag.FlagSet, keyUsage string) (baseURL, apiKey *string) {
+	baseURL = fs.String("base-url", envOr("PAYMENT_API_URL", paymentgateway.DefaultBaseURL), "API gateway URL")
+	apiKey = fs.String("key", os.Getenv("PAYMENT_API_KEY"), keyUsage)
+	return baseURL, apiKey
+}
+
+// newClient checks the key is set and returns a client for it
+func newClient(baseURL, apiKey string) *paymentgateway.Client {
+	if apiKey == "" {
+		fail("an API key is required (-key or PAYMENT_API_KEY)")
+	}
+	return paymentgateway.New(apiKey, paymentgateway.WithBaseURL(baseURL))
+}
+
+// splitList splits a comma-separated flag, dropping blanks
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "payment-cli: "+format+"\n", args...)
+	os.Exit(1)
+}
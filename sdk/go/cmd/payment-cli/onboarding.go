@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// setupOnboarding prints the signed-in user's onboarding checklist
+func setupOnboarding(flags *flag.FlagSet) func(args []string) {
+	baseURL := accountFlags(flags)
+	merchantID := flags.String("merchant", "", "merchant ID (default the user's oldest merchant)")
+	printJSON := flags.Bool("json", false, "print the checklist as JSON instead")
+
+	return func([]string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fail("%v", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		client, err := accountClient(ctx, cfg, *baseURL)
+		if err != nil {
+			fail("%v", err)
+		}
+		status, err := client.Onboarding.Status(ctx, *merchantID)
+		if err != nil {
+			fail("%v", err)
+		}
+
+		if *printJSON {
+			out, _ := json.MarshalIndent(status, "", "  ")
+			fmt.Println(string(out))
+			return
+		}
+		printChecklist(status)
+	}
+}
+
+func printChecklist(status *paymentgateway.OnboardingStatus) {
+	for _, step := range status.Steps {
+		mark := "[ ]"
+		switch step.Status {
+		case paymentgateway.OnboardingStepComplete:
+			mark = "[x]"
+		case paymentgateway.OnboardingStepUnknown:
+			mark = "[?]"
+		}
+		fmt.Printf("%s %s\n", mark, step.Title)
+		if step.Status != paymentgateway.OnboardingStepComplete && step.Link != "" {
+			fmt.Printf("    %s\n", step.Link)
+		}
+	}
+
+	fmt.Printf("\n%d of %d steps complete\n", status.Completed, status.Total)
+	for _, step := range status.Steps {
+		if step.Key == status.NextStep {
+			fmt.Printf("Next: %s\n", step.Title)
+		}
+	}
+}
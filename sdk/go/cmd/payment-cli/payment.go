@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// setupPaymentList prints a page of the key's payments, newest first. With
+// -watch it redraws the first page until interrupted.
+func setupPaymentList(flags *flag.FlagSet) func(args []string) {
+	baseURL, apiKey := apiFlags(flags, "API key")
+	status := flags.String("status", "", "only payments in this status, e.g. captured")
+	from := flags.String("from", "", "only payments created from this day (YYYY-MM-DD) or time (RFC 3339)")
+	to := flags.String("to", "", "only payments created up to this day, included (YYYY-MM-DD), or before this time (RFC 3339)")
+	limit := flags.Int("limit", 20, "payments per page, at most 100")
+	cursor := flags.String("cursor", "", "next page cursor printed by the previous page")
+	watch := flags.Bool("watch", false, "redraw the first page until interrupted")
+	every := flags.Duration("every", 5*time.Second, "how often -watch redraws")
+	printJSON := flags.Bool("json", false, "print the page as JSON instead")
+
+	return func([]string) {
+		client := newClient(*baseURL, *apiKey)
+		params := &paymentgateway.ListPaymentsParams{Status: *status, Limit: *limit, Cursor: *cursor}
+		var err error
+		if params.DateFrom, _, err = parseDay(*from); err != nil {
+			fail("invalid -from: %v", err)
+		}
+		var wholeDay bool
+		if params.DateTo, wholeDay, err = parseDay(*to); err != nil {
+			fail("invalid -to: %v", err)
+		}
+		if wholeDay {
+			params.DateTo = params.DateTo.AddDate(0, 0, 1)
+		}
+		if *watch && *cursor != "" {
+			fail("-watch redraws the first page and can't be combined with -cursor")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if !*watch {
+			list, err := client.Payments.List(ctx, params)
+			if err != nil {
+				fail("%v", err)
+			}
+			printPaymentList(list, *printJSON)
+			return
+		}
+		watchPayments(ctx, client, params, max(*every, time.Second), *printJSON)
+	}
+}
+
+// watchPayments redraws the first page every interval. A failed refresh is
+// shown and retried rather than ending the watch.
+func watchPayments(ctx context.Context, client *paymentgateway.Client, params *paymentgateway.ListPaymentsParams, every time.Duration, printJSON bool) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		list, err := client.Payments.List(ctx, params)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("Every %s, updated %s. Ctrl-C to stop.\n\n", every, time.Now().Format("15:04:05"))
+		if err != nil {
+			fmt.Printf("payment-cli: %v\n", err)
+		} else {
+			printPaymentList(list, printJSON)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func printPaymentList(list *paymentgateway.PaymentList, printJSON bool) {
+	if printJSON {
+		out, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	if len(list.Payments) == 0 {
+		fmt.Println("No payments")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAmount\tStatus\tCard\tCreated")
+	for _, p := range list.Payments {
+		card := p.CardBrand
+		if p.CardLast4 != "" {
+			card += " •••• " + p.CardLast4
+		}
+		fmt.Fprintf(w, "%s\t%s %s\t%s\t%s\t%s\n", p.ID, formatAmount(p.Amount), p.Currency, p.Status, card,
+			p.CreatedAt.Local().Format(time.DateTime))
+	}
+	w.Flush()
+
+	if list.HasMore {
+		fmt.Printf("\nNext page: -cursor %s\n", list.NextCursor)
+	}
+}
+
+// setupPaymentGet prints one payment
+func setupPaymentGet(flags *flag.FlagSet) func(args []string) {
+	baseURL, apiKey := apiFlags(flags, "API key")
+	printJSON := flags.Bool("json", false, "print the payment as JSON instead")
+
+	return func(args []string) {
+		if len(args) != 1 {
+			fail("payment get takes one payment ID")
+		}
+		client := newClient(*baseURL, *apiKey)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		p, err := client.Payments.Get(ctx, args[0])
+		if err != nil {
+			fail("%v", err)
+		}
+		if *printJSON {
+			out, _ := json.MarshalIndent(p, "", "  ")
+			fmt.Println(string(out))
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "ID\t%s\n", p.ID)
+		fmt.Fprintf(w, "Status\t%s\n", p.Status)
+		fmt.Fprintf(w, "Amount\t%s %s\n", formatAmount(p.Amount), p.Currency)
+		fmt.Fprintf(w, "Captured\t%s %s\n", formatAmount(p.CapturedAmount), p.Currency)
+		if p.CardLast4 != "" {
+			fmt.Fprintf(w, "Card\t%s •••• %s\n", p.CardBrand, p.CardLast4)
+		}
+		fmt.Fprintf(w, "Response\t%s %s\n", p.ResponseCode, p.ResponseMsg)
+		fmt.Fprintf(w, "Fraud\t%d, %s\n", p.FraudScore, p.FraudDecision)
+		fmt.Fprintf(w, "Created\t%s\n", p.CreatedAt.Local().Format(time.DateTime))
+		w.Flush()
+	}
+}
+
+// parseDay reads a YYYY-MM-DD day, as local midnight, or an RFC 3339 time.
+// It reports whether v was a day.
+func parseDay(v string) (time.Time, bool, error) {
+	if v == "" {
+		return time.Time{}, false, nil
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, v, time.Local); err == nil {
+		return day, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// chartMetrics are the bucket values report can chart
+var chartMetrics = map[string]func(*paymentgateway.ReportStatistics) float64{
+	"volume":       func(s *paymentgateway.ReportStatistics) float64 { return float64(s.Volume) },
+	"count":        func(s *paymentgateway.ReportStatistics) float64 { return float64(s.Count) },
+	"success_rate": func(s *paymentgateway.ReportStatistics) float64 { return s.SuccessRate },
+	"refund_rate":  func(s *paymentgateway.ReportStatistics) float64 { return s.RefundRate },
+	"fraud_score":  func(s *paymentgateway.ReportStatistics) float64 { return s.AverageFraudScore },
+}
+
+// setupReport prints the summary of a range of days, then a row per day or
+// week with a bar for the charted metric
+func setupReport(flags *flag.FlagSet) func(args []string) {
+	baseURL, apiKey := apiFlags(flags, "API key")
+	from := flags.String("from", "", "first day, YYYY-MM-DD (default 30 days ago)")
+	to := flags.String("to", "", "last day, YYYY-MM-DD (default today)")
+	interval := flags.String("interval", paymentgateway.ReportIntervalDay, "bucket size: day or week")
+	metric := flags.String("chart", "volume", "metric to chart: "+strings.Join(metricNames(), ", "))
+	width := flags.Int("width", 40, "widest bar, in characters")
+	printJSON := flags.Bool("json", false, "print the summary and timeseries as JSON instead")
+
+	return func([]string) {
+		client := newClient(*baseURL, *apiKey)
+		value, ok := chartMetrics[*metric]
+		if !ok {
+			fail("unknown chart metric %q; use one of %s", *metric, strings.Join(metricNames(), ", "))
+		}
+		runReport(client, &paymentgateway.ReportParams{From: *from, To: *to, Interval: *interval},
+			*metric, value, max(*width, 1), *printJSON)
+	}
+}
+
+func runReport(client *paymentgateway.Client, params *paymentgateway.ReportParams, metric string, value func(*paymentgateway.ReportStatistics) float64, width int, printJSON bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	summary, err := client.Reports.Summary(ctx, params)
+	if err != nil {
+		fail("%v", err)
+	}
+	series, err := client.Reports.Timeseries(ctx, params)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	if printJSON {
+		out, _ := json.MarshalIndent(map[string]any{"summary": summary, "timeseries": series}, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printSummary(summary)
+	fmt.Println()
+	printBuckets(series, metric, value, width)
+}
+
+func printSummary(s *paymentgateway.ReportSummary) {
+	mode := "Live"
+	if s.TestMode {
+		mode = "Test"
+	}
+	fmt.Printf("%s payments, %s to %s (%s)\n\n", mode, s.From, s.To, s.Timezone)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Volume\t%s %s\tSuccess rate\t%s\n", formatAmount(s.Volume), s.Currency, formatPercent(s.SuccessRate))
+	fmt.Fprintf(w, "Captured\t%s %s\tRefund rate\t%s\n", formatAmount(s.CapturedAmount), s.Currency, formatPercent(s.RefundRate))
+	fmt.Fprintf(w, "Refunded\t%s %s\tAvg fraud score\t%.1f\n", formatAmount(s.RefundedAmount), s.Currency, s.AverageFraudScore)
+	fmt.Fprintf(w, "Settled\t%s %s\tPayments\t%s\n", formatAmount(s.SettledAmount), s.Currency, formatCount(s.Count))
+	w.Flush()
+
+	if p := s.Payments; p != nil && p.TotalPayments > 0 {
+		fmt.Printf("\n%s attempts, %s successful (%s), %s declined by fraud checks\n",
+			formatCount(p.TotalPayments), formatCount(p.SuccessfulPayments), formatPercent(p.SuccessRate),
+			formatCount(p.FraudDeclinedPayments))
+	}
+}
+
+func printBuckets(series *paymentgateway.ReportTimeseries, metric string, value func(*paymentgateway.ReportStatistics) float64, width int) {
+	peak := 0.0
+	for _, b := range series.Buckets {
+		peak = math.Max(peak, value(&b.ReportStatistics))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Date\tCount\tVolume\tSuccess\tRefunds\tFraud\t %s\n", metric)
+	for _, b := range series.Buckets {
+		stats := &b.ReportStatistics
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.1f\t %s\n",
+			b.Date, formatCount(stats.Count), formatAmount(stats.Volume),
+			formatPercent(stats.SuccessRate), formatPercent(stats.RefundRate), stats.AverageFraudScore,
+			bar(value(stats), peak, width))
+	}
+	w.Flush()
+}
+
+// bar draws v against peak, with eighth blocks for the remainder so small
+// differences still show
+func bar(v, peak float64, width int) string {
+	if peak <= 0 || v <= 0 {
+		return ""
+	}
+	eighths := int(math.Round(v / peak * float64(width*8)))
+	if eighths == 0 {
+		eighths = 1
+	}
+	partial := []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	return strings.Repeat("█", eighths/8) + partial[eighths%8]
+}
+
+func metricNames() []string {
+	names := make([]string, 0, len(chartMetrics))
+	for name := range chartMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatAmount prints minor units with two decimals and thousands separators
+func formatAmount(minor int64) string {
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, formatCount(minor/100), minor%100)
+}
+
+func formatCount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	start := 0
+	if n < 0 {
+		start = 1
+	}
+	for i := len(s) - 3; i > start; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+	"github.com/rhaloubi/payment-gateway/sdk/go/scenario"
+)
+
+// setupScenarioRun runs the named scenarios, or all of them, with a
+// test-mode key and exits 1 if any step fails
+func setupScenarioRun(flags *flag.FlagSet) func(args []string) {
+	baseURL, apiKey := apiFlags(flags, "test-mode API key (pg_test_...)")
+	currency := flags.String("currency", "USD", "currency of the test payments")
+	amount := flags.Int64("amount", 5000, "amount of the test payments, in minor units")
+	timeout := flags.Duration("timeout", 15*time.Second, "how long to wait for asynchronous results such as disputes")
+
+	return func(names []string) {
+		selected := scenario.All()
+		if len(names) > 0 {
+			selected = nil
+			for _, name := range names {
+				s, ok := scenario.Find(name)
+				if !ok {
+					fail("unknown scenario %q; see payment-cli test scenario list", name)
+				}
+				selected = append(selected, s)
+			}
+		}
+
+		client := newClient(*baseURL, *apiKey)
+		if !client.TestMode() {
+			fail("%v", scenario.ErrLiveKey)
+		}
+		runScenarios(client, selected, scenario.Options{Currency: *currency, Amount: *amount, Timeout: *timeout})
+	}
+}
+
+func setupScenarioList(*flag.FlagSet) func(args []string) {
+	return func([]string) {
+		for _, s := range scenario.All() {
+			fmt.Printf("%-16s %s\n", s.Name, s.Description)
+		}
+	}
+}
+
+func runScenarios(client *paymentgateway.Client, selected []scenario.Scenario, opts scenario.Options) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	passed := 0
+	for _, s := range selected {
+		result, err := s.Run(ctx, client, opts)
+		if err != nil {
+			fail("%v", err)
+		}
+		printResult(result)
+		if result.Passed {
+			passed++
+		}
+	}
+
+	fmt.Printf("\n%d/%d scenarios passed\n", passed, len(selected))
+	if passed != len(selected) {
+		os.Exit(1)
+	}
+}
+
+func printResult(result *scenario.Result) {
+	mark := "PASS"
+	if !result.Passed {
+		mark = "FAIL"
+	}
+	fmt.Printf("%s %s\n", mark, result.Scenario)
+	for _, step := range result.Steps {
+		status := "ok  "
+		if !step.Passed {
+			status = "fail"
+		}
+		fmt.Printf("  %s %-28s %6s  %s\n", status, step.Name, step.Duration.Round(time.Millisecond), step.Detail)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// config is what payment-cli keeps between runs: the signed-in user's
+// session and the progress of the demo. It holds tokens, so it is only
+// readable by its owner.
+type config struct {
+	BaseURL      string     `json:"base_url"`
+	Email        string     `json:"email"`
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	Demo         *demoState `json:"demo,omitempty"`
+}
+
+// configPath is PAYMENT_CLI_CONFIG, or config.json in the user's config
+// directory
+func configPath() (string, error) {
+	if path := os.Getenv("PAYMENT_CLI_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "payment-cli", "config.json"), nil
+}
+
+// loadConfig returns an empty config when there is no file yet
+func loadConfig() (*config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o600)
+}
+
+func (c *config) signIn(email string, session *paymentgateway.Session, baseURL string) {
+	c.BaseURL = baseURL
+	if email != "" {
+		c.Email = email
+	}
+	c.AccessToken = session.AccessToken
+	c.RefreshToken = session.RefreshToken
+	c.ExpiresAt = session.ExpiresAt(time.Now())
+}
+
+// accountClient returns a client signed in as the stored user, refreshing
+// the session first when its access token is about to expire
+func accountClient(ctx context.Context, cfg *config, baseURL string) (*paymentgateway.Client, error) {
+	if cfg.AccessToken == "" {
+		return nil, errors.New("not logged in; run payment-cli login")
+	}
+	if cfg.BaseURL != "" && cfg.BaseURL != baseURL {
+		return nil, fmt.Errorf("logged in to %s, not %s; run payment-cli login -base-url %s", cfg.BaseURL, baseURL, baseURL)
+	}
+	if time.Until(cfg.ExpiresAt) < time.Minute {
+		session, err := paymentgateway.New("", paymentgateway.WithBaseURL(baseURL)).Auth.Refresh(ctx, cfg.RefreshToken)
+		if err != nil {
+			return nil, fmt.Errorf("session expired; run payment-cli login: %w", err)
+		}
+		cfg.signIn("", session, baseURL)
+		if err := cfg.save(); err != nil {
+			return nil, err
+		}
+	}
+	return paymentgateway.New("", paymentgateway.WithBaseURL(baseURL), paymentgateway.WithAccessToken(cfg.AccessToken)), nil
+}
+
+// accountFlags defines the flag of commands that run as the signed-in user
+func accountFlags(fs *flag.FlagSet) *string {
+	return fs.String("base-url", envOr("PAYMENT_API_URL", paymentgateway.DefaultBaseURL), "API gateway URL")
+}
+
+func setupLogin(flags *flag.FlagSet) func(args []string) {
+	baseURL := accountFlags(flags)
+	email := flags.String("email", "", "account email (prompted for when empty)")
+
+	return func([]string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fail("%v", err)
+		}
+		if *email == "" {
+			*email = prompt("Email", cfg.Email)
+		}
+		password := os.Getenv("PAYMENT_CLI_PASSWORD")
+		if password == "" {
+			password = promptPassword("Password")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		session, err := paymentgateway.New("", paymentgateway.WithBaseURL(*baseURL)).Auth.Login(ctx, *email, password)
+		if err != nil {
+			fail("%v", err)
+		}
+		cfg.signIn(*email, session, *baseURL)
+		if err := cfg.save(); err != nil {
+			fail("%v", err)
+		}
+		fmt.Printf("Logged in as %s\n", *email)
+	}
+}
+
+func setupLogout(flags *flag.FlagSet) func(args []string) {
+	baseURL := accountFlags(flags)
+
+	return func([]string) {
+		cfg, err := loadConfig()
+		if err != nil {
+			fail("%v", err)
+		}
+		if cfg.AccessToken == "" {
+			fmt.Println("Not logged in")
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		// The session is forgotten locally even if the server can't be told
+		client := paymentgateway.New("", paymentgateway.WithBaseURL(*baseURL), paymentgateway.WithAccessToken(cfg.AccessToken))
+		if err := client.Auth.Logout(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "payment-cli: could not revoke the session: %v\n", err)
+		}
+		cfg.AccessToken, cfg.RefreshToken, cfg.ExpiresAt = "", "", time.Time{}
+		if err := cfg.save(); err != nil {
+			fail("%v", err)
+		}
+		fmt.Println("Logged out")
+	}
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// prompt reads a line from stdin, returning fallback for an empty one
+func prompt(label, fallback string) string {
+	if fallback != "" {
+		fmt.Printf("%s [%s]: ", label, fallback)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		fail("no input")
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return fallback
+	}
+	return line
+}
+
+// promptPassword reads a line without echoing it, when stdin is a
+// terminal stty can switch echo off on
+func promptPassword(label string) string {
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Println()
+		}()
+	}
+	return prompt(label, "")
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
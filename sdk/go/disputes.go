@@ -0,0 +1,80 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Dispute is a chargeback the cardholder's issuer opened on a payment.
+// TransactionID matches Payment.TransactionID.
+type Dispute struct {
+	ID                  string `json:"id"`
+	TransactionID       string `json:"transaction_id"`
+	Status              string `json:"status"`
+	Reason              string `json:"reason"`
+	ReasonCode          string `json:"reason_code"`
+	Amount              int64  `json:"amount"`
+	Currency            string `json:"currency"`
+	ChargebackFee       int64  `json:"chargeback_fee"`
+	NetLoss             int64  `json:"net_loss"`
+	CustomerStatement   string `json:"customer_statement,omitempty"`
+	DisputedAt          string `json:"disputed_at"`
+	ResponseDueAt       string `json:"response_due_at,omitempty"`
+	Overdue             bool   `json:"overdue,omitempty"`
+	SecondsUntilDue     int64  `json:"seconds_until_due,omitempty"`
+	ResponseSubmittedAt string `json:"response_submitted_at,omitempty"`
+	ResolutionReason    string `json:"resolution_reason,omitempty"`
+	ResolvedAt          string `json:"resolved_at,omitempty"`
+	CreatedAt           string `json:"created_at"`
+}
+
+// ListDisputesParams filters DisputesService.List. Limit defaults to 20.
+type ListDisputesParams struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+type DisputeList struct {
+	Disputes []*Dispute `json:"disputes"`
+	Total    int32      `json:"total"`
+	HasMore  bool       `json:"has_more"`
+}
+
+// DisputesService calls /api/v1/disputes
+type DisputesService struct {
+	client *Client
+}
+
+// List pages through the merchant's disputes, soonest response deadline
+// first
+func (s *DisputesService) List(ctx context.Context, params *ListDisputesParams, opts ...RequestOption) (*DisputeList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+		if params.Limit > 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Offset > 0 {
+			query.Set("offset", strconv.Itoa(params.Offset))
+		}
+	}
+
+	var list DisputeList
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/disputes", query: query}, &list, opts...); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (s *DisputesService) Get(ctx context.Context, disputeID string, opts ...RequestOption) (*Dispute, error) {
+	var dispute Dispute
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/disputes/" + pathID(disputeID)}, &dispute, opts...); err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
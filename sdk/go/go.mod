@@ -0,0 +1,3 @@
+module github.com/rhaloubi/payment-gateway/sdk/go
+
+go 1.25.2
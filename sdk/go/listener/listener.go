@@ -0,0 +1,230 @@
+// Package listener receives webhooks on a local port for development. It
+// registers a temporary webhook subscription pointing at itself, checks the
+// signature of each delivery, reports it and forwards it unchanged to the
+// developer's own endpoint. The subscription is deleted when it stops.
+package listener
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// pingEvent is the handshake the API sends before activating a subscription
+const pingEvent = "webhook.ping"
+
+// maxBody caps a delivery; events are a few kilobytes
+const maxBody = 1 << 20
+
+// forwardedHeaders are copied onto the forwarded request so the local
+// endpoint can verify the signature itself
+var forwardedHeaders = []string{"Content-Type", "User-Agent", "X-Webhook-Signature", "X-Webhook-Timestamp"}
+
+// Delivery is one webhook the listener received
+type Delivery struct {
+	ReceivedAt time.Time
+	Event      *paymentgateway.WebhookEvent // nil when Err is a bad signature or body
+	Err        error
+
+	// Outcome of forwarding, when ForwardTo is set
+	ForwardStatus int
+	ForwardErr    error
+	ForwardTime   time.Duration
+}
+
+// Config sets up a Listener
+type Config struct {
+	Client *paymentgateway.Client
+
+	// Addr is the local address to listen on, e.g. 127.0.0.1:4242
+	Addr string
+
+	// PublicURL is the URL the payment API delivers to. It defaults to
+	// http://<Addr>/webhooks and needs setting when the API can't reach
+	// Addr directly, e.g. through a tunnel or from inside Docker.
+	PublicURL string
+
+	// ForwardTo receives every verified event, e.g.
+	// http://localhost:3000/webhooks. Empty only reports events.
+	ForwardTo string
+
+	// EventTypes to subscribe to; empty subscribes to all of them
+	EventTypes []string
+
+	// OnDelivery is called for every delivery, after forwarding. It must
+	// not block for long.
+	OnDelivery func(*Delivery)
+}
+
+// Listener is a running local webhook endpoint
+type Listener struct {
+	cfg        Config
+	server     *http.Server
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	secret string // empty until the subscription is created
+
+	Subscription *paymentgateway.WebhookSubscription
+}
+
+// Listen starts the local endpoint and registers it as a webhook
+// subscription, in the mode of the client's API key. It returns once the
+// subscription is active; call Close to delete it.
+func Listen(ctx context.Context, cfg Config) (*Listener, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("listener: Client is required")
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:4242"
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("listener: %w", err)
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = "http://" + ln.Addr().String() + "/webhooks"
+	}
+
+	l := &Listener{cfg: cfg, httpClient: &http.Client{Timeout: 10 * time.Second}}
+	l.server = &http.Server{Handler: l, ReadHeaderTimeout: 5 * time.Second}
+	go l.server.Serve(ln)
+
+	mode := "live"
+	if cfg.Client.TestMode() {
+		mode = "test"
+	}
+	params := &paymentgateway.WebhookSubscriptionParams{URL: &cfg.PublicURL, Mode: &mode}
+	if len(cfg.EventTypes) > 0 {
+		params.EventTypes = &cfg.EventTypes
+	}
+
+	// The ping handshake arrives while Create is in flight, before the
+	// secret is known, and is answered unverified
+	sub, err := cfg.Client.WebhookSubscriptions.Create(ctx, params)
+	if err != nil {
+		l.server.Close()
+		return nil, fmt.Errorf("listener: register webhook endpoint: %w", err)
+	}
+	l.Subscription = sub
+	l.mu.Lock()
+	l.secret = sub.Secret
+	l.mu.Unlock()
+
+	if !sub.Active {
+		cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		cfg.Client.WebhookSubscriptions.Delete(cleanup, sub.ID)
+		l.server.Close()
+		return nil, fmt.Errorf("listener: the payment API could not reach %s: %s", cfg.PublicURL, sub.VerificationError)
+	}
+	return l, nil
+}
+
+// Close deletes the temporary subscription and stops the endpoint
+func (l *Listener) Close(ctx context.Context) error {
+	err := l.cfg.Client.WebhookSubscriptions.Delete(ctx, l.Subscription.ID)
+	if shutdownErr := l.server.Shutdown(ctx); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// ServeHTTP handles one delivery
+func (l *Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	l.mu.RLock()
+	secret := l.secret
+	l.mu.RUnlock()
+
+	if secret == "" {
+		// Registration handshake: echo the challenge, nothing else gets in
+		// before the secret is known
+		var ping struct {
+			Event string `json:"event"`
+			Data  struct {
+				Challenge string `json:"challenge"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(body, &ping) != nil || ping.Event != pingEvent {
+			http.Error(w, "not registered yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"challenge": ping.Data.Challenge})
+		return
+	}
+
+	delivery := &Delivery{ReceivedAt: time.Now()}
+	delivery.Event, delivery.Err = paymentgateway.ParseWebhook(body, r.Header.Get(paymentgateway.SignatureHeader), secret, paymentgateway.DefaultWebhookTolerance)
+	if delivery.Err != nil {
+		l.report(delivery)
+		http.Error(w, delivery.Err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if delivery.Event.Event == pingEvent {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"challenge": delivery.Event.Data["challenge"]})
+		return
+	}
+
+	// Acknowledge at once so a slow local server doesn't trigger retries
+	w.WriteHeader(http.StatusOK)
+	go func() {
+		if l.cfg.ForwardTo != "" {
+			l.forward(delivery, body, r.Header)
+		}
+		l.report(delivery)
+	}()
+}
+
+func (l *Listener) forward(delivery *Delivery, body []byte, header http.Header) {
+	start := time.Now()
+	defer func() { delivery.ForwardTime = time.Since(start) }()
+
+	req, err := http.NewRequest(http.MethodPost, l.cfg.ForwardTo, bytes.NewReader(body))
+	if err != nil {
+		delivery.ForwardErr = err
+		return
+	}
+	for _, name := range forwardedHeaders {
+		if v := header.Get(name); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		delivery.ForwardErr = err
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxBody))
+	resp.Body.Close()
+	delivery.ForwardStatus = resp.StatusCode
+}
+
+func (l *Listener) report(delivery *Delivery) {
+	if l.cfg.OnDelivery != nil {
+		l.cfg.OnDelivery(delivery)
+	}
+}
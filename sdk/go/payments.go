@@ -0,0 +1,283 @@
+package paymentgateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending           = "pending"
+	PaymentStatusRequiresAction    = "requires_action"
+	PaymentStatusAuthorized        = "authorized"
+	PaymentStatusCaptured          = "captured"
+	PaymentStatusPartiallyCaptured = "partially_captured"
+	PaymentStatusVoided            = "voided"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusFailed            = "failed"
+)
+
+// Card is a card entered by the customer. Prefer a card token where you
+// have one, so the number never passes through your servers.
+type Card struct {
+	Number         string `json:"number"`
+	CardholderName string `json:"cardholder_name"`
+	ExpMonth       int    `json:"exp_month"`
+	ExpYear        int    `json:"exp_year"`
+	CVV            string `json:"cvv"`
+}
+
+type Customer struct {
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ThreeDSecureOptions asks for 3-D Secure. The customer comes back to
+// ReturnURL after a challenge.
+type ThreeDSecureOptions struct {
+	ReturnURL string `json:"return_url"`
+}
+
+// AuthorizeRequest holds a card or a card token. Amount is in the
+// currency's minor unit, e.g. cents.
+type AuthorizeRequest struct {
+	Amount       int64                `json:"amount"`
+	Currency     string               `json:"currency"`
+	Card         *Card                `json:"card,omitempty"`
+	CardToken    string               `json:"card_token,omitempty"`
+	Customer     *Customer            `json:"customer,omitempty"`
+	Description  string               `json:"description,omitempty"`
+	Metadata     map[string]any       `json:"metadata,omitempty"`
+	Language     string               `json:"language,omitempty"` // en, fr or ar
+	ThreeDSecure *ThreeDSecureOptions `json:"three_d_secure,omitempty"`
+}
+
+// CaptureRequest captures an authorization. A zero Amount captures the rest
+// of it; FinalCapture releases whatever is left uncaptured.
+type CaptureRequest struct {
+	Amount       int64  `json:"amount,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	FinalCapture *bool  `json:"final_capture,omitempty"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// RefundRequest refunds a captured payment. A zero Amount refunds what is
+// left of it.
+type RefundRequest struct {
+	Amount     int64  `json:"amount,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reason_code,omitempty"`
+}
+
+// AuthenticateRequest completes a 3-D Secure challenge with the CRes the
+// ACS posted to your return URL
+type AuthenticateRequest struct {
+	CRes string `json:"cres"`
+}
+
+type ThreeDSecure struct {
+	Status          string `json:"status,omitempty"` // EMV transStatus: Y, A, C, N, R or U
+	Version         string `json:"version,omitempty"`
+	ECI             string `json:"eci,omitempty"`
+	DSTransactionID string `json:"ds_transaction_id,omitempty"`
+}
+
+// NextAction tells you where to send the customer while a payment is
+// requires_action
+type NextAction struct {
+	Type        string `json:"type"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+type Payment struct {
+	ID             string        `json:"id"`
+	Status         string        `json:"status"`
+	Amount         int64         `json:"amount"`
+	Currency       string        `json:"currency"`
+	CapturedAmount int64         `json:"captured_amount"`
+	Token          string        `json:"token,omitempty"` // Card token, reusable with CardToken
+	CardBrand      string        `json:"card_brand"`
+	CardLast4      string        `json:"card_last4"`
+	AuthCode       string        `json:"auth_code,omitempty"`
+	FraudScore     int           `json:"fraud_score"`
+	FraudDecision  string        `json:"fraud_decision"`
+	FraudReasons   []string      `json:"fraud_reasons,omitempty"`
+	ResponseCode   string        `json:"response_code"`
+	ResponseMsg    string        `json:"response_message"`
+	TransactionID  string        `json:"transaction_id,omitempty"`
+	RedirectURL    string        `json:"redirect_url,omitempty"`
+	Refund         *Refund       `json:"refund,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ThreeDSecure   *ThreeDSecure `json:"three_d_secure,omitempty"`
+	NextAction     *NextAction   `json:"next_action,omitempty"`
+
+	// Set on declines. Only retry when RetryAllowed is true, after
+	// SuggestedRetryAfter seconds.
+	RetryAllowed        *bool `json:"retry_allowed,omitempty"`
+	SuggestedRetryAfter int64 `json:"suggested_retry_after,omitempty"`
+}
+
+// PaymentsService calls /api/v1/payments
+type PaymentsService struct {
+	client *Client
+}
+
+// Authorize holds the amount on the card for a later Capture
+func (s *PaymentsService) Authorize(ctx context.Context, req *AuthorizeRequest, opts ...RequestOption) (*Payment, error) {
+	return s.post(ctx, "/api/v1/payments/authorize", req, opts)
+}
+
+// Sale authorizes and captures in one call
+func (s *PaymentsService) Sale(ctx context.Context, req *AuthorizeRequest, opts ...RequestOption) (*Payment, error) {
+	return s.post(ctx, "/api/v1/payments/sale", req, opts)
+}
+
+// Authenticate finishes a payment left requires_action by 3-D Secure
+func (s *PaymentsService) Authenticate(ctx context.Context, paymentID string, req *AuthenticateRequest, opts ...RequestOption) (*Payment, error) {
+	return s.post(ctx, "/api/v1/payments/"+pathID(paymentID)+"/authenticate", req, opts)
+}
+
+func (s *PaymentsService) Capture(ctx context.Context, paymentID string, req *CaptureRequest, opts ...RequestOption) (*Payment, error) {
+	if req == nil {
+		req = &CaptureRequest{}
+	}
+	return s.post(ctx, "/api/v1/payments/"+pathID(paymentID)+"/capture", req, opts)
+}
+
+func (s *PaymentsService) Void(ctx context.Context, paymentID string, req *VoidRequest, opts ...RequestOption) (*Payment, error) {
+	if req == nil {
+		req = &VoidRequest{}
+	}
+	return s.post(ctx, "/api/v1/payments/"+pathID(paymentID)+"/void", req, opts)
+}
+
+// RefundApproval is a refund at or above the merchant's approval threshold,
+// waiting for a second team member to approve it
+type RefundApproval struct {
+	ID          string    `json:"id"`
+	PaymentID   string    `json:"payment_id"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency"`
+	Reason      string    `json:"reason,omitempty"`
+	ReasonCode  string    `json:"reason_code,omitempty"`
+	Status      string    `json:"status"` // pending_approval, approved, rejected or expired
+	RequestedBy string    `json:"requested_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RefundResult holds either the refunded payment, whose Refund tracks the
+// refund, or the approval the refund is waiting on
+type RefundResult struct {
+	Payment  *Payment
+	Approval *RefundApproval
+}
+
+// Refund refunds a captured payment
+func (s *PaymentsService) Refund(ctx context.Context, paymentID string, req *RefundRequest, opts ...RequestOption) (*RefundResult, error) {
+	if req == nil {
+		req = &RefundRequest{}
+	}
+	var raw json.RawMessage
+	err := s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/payments/" + pathID(paymentID) + "/refund", body: req}, &raw, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		RequestedBy string `json:"requested_by"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, decodeError(err)
+	}
+	result := &RefundResult{}
+	if probe.RequestedBy != "" {
+		err = json.Unmarshal(raw, &result.Approval)
+	} else {
+		err = json.Unmarshal(raw, &result.Payment)
+	}
+	if err != nil {
+		return nil, decodeError(err)
+	}
+	return result, nil
+}
+
+func (s *PaymentsService) Get(ctx context.Context, paymentID string, opts ...RequestOption) (*Payment, error) {
+	var payment Payment
+	err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/payments/" + pathID(paymentID)}, &payment, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListPaymentsParams filters PaymentsService.List. DateTo is exclusive;
+// Limit defaults to 20 and is capped at 100.
+type ListPaymentsParams struct {
+	Status   string
+	DateFrom time.Time
+	DateTo   time.Time
+	Limit    int
+	Cursor   string // NextCursor of the previous page
+}
+
+// PaymentList is one page of payments, newest first
+type PaymentList struct {
+	Payments   []*Payment `json:"payments"`
+	HasMore    bool       `json:"has_more"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// List pages through the payments made in the API key's mode
+func (s *PaymentsService) List(ctx context.Context, params *ListPaymentsParams, opts ...RequestOption) (*PaymentList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+		if !params.DateFrom.IsZero() {
+			query.Set("date_from", params.DateFrom.Format(time.RFC3339))
+		}
+		if !params.DateTo.IsZero() {
+			query.Set("date_to", params.DateTo.Format(time.RFC3339))
+		}
+		if params.Limit > 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Cursor != "" {
+			query.Set("cursor", params.Cursor)
+		}
+	}
+
+	var list PaymentList
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/payments", query: query}, &list, opts...); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ListRefunds returns every refund of one payment
+func (s *PaymentsService) ListRefunds(ctx context.Context, paymentID string, opts ...RequestOption) ([]*Refund, error) {
+	var refunds []*Refund
+	err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/payments/" + pathID(paymentID) + "/refunds"}, &refunds, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (s *PaymentsService) post(ctx context.Context, path string, body any, opts []RequestOption) (*Payment, error) {
+	var payment Payment
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: path, body: body}, &payment, opts...); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
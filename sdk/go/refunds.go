@@ -0,0 +1,97 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Refund statuses. A refund is queued while the acquirer can't take it and
+// sent automatically once it can.
+const (
+	RefundStatusQueued       = "queued"
+	RefundStatusRequested    = "requested"
+	RefundStatusSentToIssuer = "sent_to_issuer"
+	RefundStatusSettled      = "settled"
+	RefundStatusFailed       = "failed"
+)
+
+type Refund struct {
+	ID                 string `json:"id"`
+	PaymentID          string `json:"payment_id,omitempty"`
+	Amount             int64  `json:"amount"`
+	Currency           string `json:"currency,omitempty"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	ReasonCode         string `json:"reason_code,omitempty"`
+	RequestedAt        string `json:"requested_at,omitempty"`
+	SentToIssuerAt     string `json:"sent_to_issuer_at,omitempty"`
+	SettledAt          string `json:"settled_at,omitempty"`
+	EstimatedArrivalAt string `json:"estimated_arrival_at,omitempty"` // YYYY-MM-DD
+}
+
+// ListRefundsParams filters RefundsService.List. Limit defaults to 20 and
+// is capped at 100.
+type ListRefundsParams struct {
+	PaymentID  string
+	Status     string
+	ReasonCode string
+	DateFrom   time.Time
+	DateTo     time.Time
+	Limit      int
+	Offset     int
+}
+
+type RefundList struct {
+	Refunds []*Refund `json:"refunds"`
+	Total   int64     `json:"total"`
+	HasMore bool      `json:"has_more"`
+}
+
+// RefundsService calls /api/v1/refunds
+type RefundsService struct {
+	client *Client
+}
+
+func (s *RefundsService) Get(ctx context.Context, refundID string, opts ...RequestOption) (*Refund, error) {
+	var refund Refund
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/refunds/" + pathID(refundID)}, &refund, opts...); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// List pages through the merchant's refunds, newest first
+func (s *RefundsService) List(ctx context.Context, params *ListRefundsParams, opts ...RequestOption) (*RefundList, error) {
+	query := url.Values{}
+	if params != nil {
+		set := func(key, value string) {
+			if value != "" {
+				query.Set(key, value)
+			}
+		}
+		set("payment_id", params.PaymentID)
+		set("status", params.Status)
+		set("reason_code", params.ReasonCode)
+		if !params.DateFrom.IsZero() {
+			query.Set("date_from", params.DateFrom.Format(time.RFC3339))
+		}
+		if !params.DateTo.IsZero() {
+			query.Set("date_to", params.DateTo.Format(time.RFC3339))
+		}
+		if params.Limit > 0 {
+			query.Set("limit", strconv.Itoa(params.Limit))
+		}
+		if params.Offset > 0 {
+			query.Set("offset", strconv.Itoa(params.Offset))
+		}
+	}
+
+	var list RefundList
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/refunds", query: query}, &list, opts...); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
@@ -0,0 +1,120 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Timeseries bucket sizes
+const (
+	ReportIntervalDay  = "day"
+	ReportIntervalWeek = "week"
+)
+
+// ReportStatistics is the merchant's payments over a period. Amounts are in
+// MAD minor units and rates are percentages.
+type ReportStatistics struct {
+	Count             int64   `json:"count"`
+	Volume            int64   `json:"volume"`
+	CapturedAmount    int64   `json:"captured_amount"`
+	RefundedAmount    int64   `json:"refunded_amount"`
+	SettledAmount     int64   `json:"settled_amount"`
+	SuccessRate       float64 `json:"success_rate"`
+	RefundRate        float64 `json:"refund_rate"`
+	AverageFraudScore float64 `json:"average_fraud_score"`
+}
+
+// ReportPeriod is the days a report covers, both included, in the
+// merchant's settlement timezone. Start and End are RFC 3339, End excluded.
+type ReportPeriod struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TestMode bool   `json:"test_mode"`
+	Currency string `json:"currency"`
+}
+
+// PaymentStatistics counts every payment attempt, including those declined
+// by fraud checks before they reached the acquirer
+type PaymentStatistics struct {
+	TotalPayments         int64   `json:"total_payments"`
+	SuccessfulPayments    int64   `json:"successful_payments"`
+	FailedPayments        int64   `json:"failed_payments"`
+	FraudDeclinedPayments int64   `json:"fraud_declined_payments"`
+	RefundedPayments      int64   `json:"refunded_payments"`
+	SuccessRate           float64 `json:"success_rate"`
+	RefundRate            float64 `json:"refund_rate"`
+	AverageFraudScore     float64 `json:"average_fraud_score"`
+}
+
+type ReportSummary struct {
+	ReportPeriod
+	ReportStatistics
+	Payments *PaymentStatistics `json:"payments"`
+}
+
+// ReportBucket is one day or week; Date is its first day
+type ReportBucket struct {
+	Date string `json:"date"`
+	ReportStatistics
+}
+
+type ReportTimeseries struct {
+	ReportPeriod
+	Interval string          `json:"interval"`
+	Buckets  []*ReportBucket `json:"buckets"`
+}
+
+// ReportParams selects the days of a report as YYYY-MM-DD, both included.
+// Left empty, a report covers the last 30 days. Interval is
+// ReportIntervalDay (the default) or ReportIntervalWeek and only applies to
+// Timeseries.
+type ReportParams struct {
+	From     string
+	To       string
+	Interval string
+}
+
+// ReportsService calls /api/v1/reports
+type ReportsService struct {
+	client *Client
+}
+
+// Summary totals the merchant's payments over the days in params
+func (s *ReportsService) Summary(ctx context.Context, params *ReportParams, opts ...RequestOption) (*ReportSummary, error) {
+	var summary ReportSummary
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/reports/summary", query: reportQuery(params, false)}, &summary, opts...); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Timeseries splits the same statistics into days or weeks, oldest first.
+// Weeks start on Monday.
+func (s *ReportsService) Timeseries(ctx context.Context, params *ReportParams, opts ...RequestOption) (*ReportTimeseries, error) {
+	var series ReportTimeseries
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/reports/timeseries", query: reportQuery(params, true)}, &series, opts...); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func reportQuery(params *ReportParams, withInterval bool) url.Values {
+	query := url.Values{}
+	if params == nil {
+		return query
+	}
+	if params.From != "" {
+		query.Set("from", params.From)
+	}
+	if params.To != "" {
+		query.Set("to", params.To)
+	}
+	if withInterval && params.Interval != "" {
+		query.Set("interval", params.Interval)
+	}
+	return query
+}
@@ -0,0 +1,267 @@
+package scenario
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// Sandbox test cards, see the payment API's test card table
+const (
+	cardApproved         = "4242424242424242"
+	cardDeclined         = "4000000000000002"
+	cardDisputed         = "4000000000000259"
+	cardThreeDSChallenge = "4000000000003220"
+)
+
+var scenarios = []Scenario{
+	{Name: "successful-sale", Description: "Sale with an approved card, read back as captured", run: successfulSale},
+	{Name: "declined-card", Description: "Authorization with a card the issuer declines", run: declinedCard},
+	{Name: "partial-refund", Description: "Sale, then a refund of part of it", run: partialRefund},
+	{Name: "chargeback", Description: "Sale with the dispute card, which the issuer charges back", run: chargeback},
+	{Name: "3ds-challenge", Description: "Authorization that needs a 3-D Secure challenge, then capture", run: threeDSChallenge},
+}
+
+func successfulSale(ctx context.Context, r *run) error {
+	var payment *paymentgateway.Payment
+	if err := r.step("sale", func() (string, error) {
+		var err error
+		payment, err = r.client.Payments.Sale(ctx, &paymentgateway.AuthorizeRequest{
+			Amount:      r.opts.Amount,
+			Currency:    r.opts.Currency,
+			Card:        r.card(cardApproved),
+			Description: "scenario: successful-sale",
+		})
+		if err != nil {
+			return "", err
+		}
+		return payment.ID, expectStatus(payment, paymentgateway.PaymentStatusCaptured)
+	}); err != nil {
+		return err
+	}
+
+	return r.step("get payment", func() (string, error) {
+		got, err := r.client.Payments.Get(ctx, payment.ID)
+		if err != nil {
+			return "", err
+		}
+		if err := expectStatus(got, paymentgateway.PaymentStatusCaptured); err != nil {
+			return "", err
+		}
+		if got.CapturedAmount != r.opts.Amount {
+			return "", fmt.Errorf("captured %d, want %d", got.CapturedAmount, r.opts.Amount)
+		}
+		return fmt.Sprintf("captured %d %s", got.CapturedAmount, got.Currency), nil
+	})
+}
+
+func declinedCard(ctx context.Context, r *run) error {
+	return r.step("authorize declined card", func() (string, error) {
+		payment, err := r.client.Payments.Authorize(ctx, &paymentgateway.AuthorizeRequest{
+			Amount:      r.opts.Amount,
+			Currency:    r.opts.Currency,
+			Card:        r.card(cardDeclined),
+			Description: "scenario: declined-card",
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := expectStatus(payment, paymentgateway.PaymentStatusFailed); err != nil {
+			return "", err
+		}
+		if payment.ResponseCode != "05" {
+			return "", fmt.Errorf("response code %s, want 05", payment.ResponseCode)
+		}
+		return fmt.Sprintf("declined %s: %s", payment.ResponseCode, payment.ResponseMsg), nil
+	})
+}
+
+func partialRefund(ctx context.Context, r *run) error {
+	var payment *paymentgateway.Payment
+	if err := r.step("sale", func() (string, error) {
+		var err error
+		payment, err = r.client.Payments.Sale(ctx, &paymentgateway.AuthorizeRequest{
+			Amount:      r.opts.Amount,
+			Currency:    r.opts.Currency,
+			Card:        r.card(cardApproved),
+			Description: "scenario: partial-refund",
+		})
+		if err != nil {
+			return "", err
+		}
+		return payment.ID, expectStatus(payment, paymentgateway.PaymentStatusCaptured)
+	}); err != nil {
+		return err
+	}
+
+	amount := r.opts.Amount / 2
+	if err := r.step("refund half", func() (string, error) {
+		result, err := r.client.Payments.Refund(ctx, payment.ID, &paymentgateway.RefundRequest{
+			Amount:     amount,
+			Currency:   r.opts.Currency,
+			Reason:     "scenario: partial-refund",
+			ReasonCode: "requested_by_customer",
+		})
+		if err != nil {
+			return "", err
+		}
+		if result.Approval != nil {
+			return "", errors.New("refund is waiting for approval; lower the amount or the merchant's approval threshold")
+		}
+		refund := result.Payment.Refund
+		if refund == nil {
+			return "", errors.New("response has no refund")
+		}
+		if refund.Status == paymentgateway.RefundStatusFailed {
+			return "", errors.New("refund failed")
+		}
+		return fmt.Sprintf("refund %s %s", refund.ID, refund.Status), nil
+	}); err != nil {
+		return err
+	}
+
+	return r.step("list refunds", func() (string, error) {
+		refunds, err := r.client.Payments.ListRefunds(ctx, payment.ID)
+		if err != nil {
+			return "", err
+		}
+		var total int64
+		for _, refund := range refunds {
+			if refund.Status != paymentgateway.RefundStatusFailed {
+				total += refund.Amount
+			}
+		}
+		if total != amount {
+			return "", fmt.Errorf("refunded %d, want %d", total, amount)
+		}
+		return fmt.Sprintf("refunded %d of %d", total, r.opts.Amount), nil
+	})
+}
+
+func chargeback(ctx context.Context, r *run) error {
+	var payment *paymentgateway.Payment
+	if err := r.step("sale with dispute card", func() (string, error) {
+		var err error
+		payment, err = r.client.Payments.Sale(ctx, &paymentgateway.AuthorizeRequest{
+			Amount:      r.opts.Amount,
+			Currency:    r.opts.Currency,
+			Card:        r.card(cardDisputed),
+			Description: "scenario: chargeback",
+		})
+		if err != nil {
+			return "", err
+		}
+		return payment.ID, expectStatus(payment, paymentgateway.PaymentStatusCaptured)
+	}); err != nil {
+		return err
+	}
+
+	return r.step("dispute opened", func() (string, error) {
+		deadline := time.Now().Add(r.opts.Timeout)
+		for {
+			dispute, err := findDispute(ctx, r.client, payment.TransactionID)
+			if err != nil {
+				return "", err
+			}
+			if dispute != nil {
+				if dispute.Amount != r.opts.Amount {
+					return "", fmt.Errorf("dispute %s is for %d, want %d", dispute.ID, dispute.Amount, r.opts.Amount)
+				}
+				return fmt.Sprintf("dispute %s %s (%s)", dispute.ID, dispute.Status, dispute.Reason), nil
+			}
+			if time.Now().After(deadline) {
+				return "", fmt.Errorf("no dispute for transaction %s after %s", payment.TransactionID, r.opts.Timeout)
+			}
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	})
+}
+
+// findDispute looks through the merchant's disputes for the transaction's
+func findDispute(ctx context.Context, client *paymentgateway.Client, transactionID string) (*paymentgateway.Dispute, error) {
+	params := &paymentgateway.ListDisputesParams{Limit: 100}
+	for {
+		list, err := client.Disputes.List(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, dispute := range list.Disputes {
+			if dispute.TransactionID == transactionID {
+				return dispute, nil
+			}
+		}
+		if !list.HasMore {
+			return nil, nil
+		}
+		params.Offset += len(list.Disputes)
+	}
+}
+
+func threeDSChallenge(ctx context.Context, r *run) error {
+	var payment *paymentgateway.Payment
+	if err := r.step("authorize with 3-D Secure", func() (string, error) {
+		var err error
+		payment, err = r.client.Payments.Authorize(ctx, &paymentgateway.AuthorizeRequest{
+			Amount:       r.opts.Amount,
+			Currency:     r.opts.Currency,
+			Card:         r.card(cardThreeDSChallenge),
+			Description:  "scenario: 3ds-challenge",
+			ThreeDSecure: &paymentgateway.ThreeDSecureOptions{ReturnURL: "https://example.com/3ds/return"},
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := expectStatus(payment, paymentgateway.PaymentStatusRequiresAction); err != nil {
+			return "", err
+		}
+		if payment.NextAction == nil || payment.ThreeDSecure == nil {
+			return "", errors.New("requires_action without next_action or three_d_secure")
+		}
+		return "challenge at " + payment.NextAction.RedirectURL, nil
+	}); err != nil {
+		return err
+	}
+
+	if err := r.step("complete challenge", func() (string, error) {
+		// The sandbox ACS has no challenge page; the CRes is built here
+		cres, err := json.Marshal(map[string]string{
+			"threeDSServerTransID": payment.ThreeDSecure.DSTransactionID,
+			"transStatus":          "Y",
+		})
+		if err != nil {
+			return "", err
+		}
+		payment, err = r.client.Payments.Authenticate(ctx, payment.ID, &paymentgateway.AuthenticateRequest{
+			CRes: base64.RawURLEncoding.EncodeToString(cres),
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := expectStatus(payment, paymentgateway.PaymentStatusAuthorized); err != nil {
+			return "", err
+		}
+		if payment.ThreeDSecure == nil || payment.ThreeDSecure.Status != "Y" {
+			return "", errors.New("payment is not marked 3-D Secure authenticated")
+		}
+		return "authenticated, ECI " + payment.ThreeDSecure.ECI, nil
+	}); err != nil {
+		return err
+	}
+
+	return r.step("capture", func() (string, error) {
+		captured, err := r.client.Payments.Capture(ctx, payment.ID, nil)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("captured %d %s", captured.CapturedAmount, captured.Currency), expectStatus(captured, paymentgateway.PaymentStatusCaptured)
+	})
+}
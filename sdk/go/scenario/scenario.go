@@ -0,0 +1,143 @@
+// Package scenario drives canned payment flows against the sandbox and
+// reports each step, so an integration can smoke-test its API key, network
+// path and webhook setup before going live.
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	paymentgateway "github.com/rhaloubi/payment-gateway/sdk/go"
+)
+
+// ErrLiveKey is returned when a scenario would run with a live key
+var ErrLiveKey = errors.New("scenarios only run with a test-mode API key (pg_test_)")
+
+// Step is the outcome of one call or check in a scenario
+type Step struct {
+	Name     string
+	Passed   bool
+	Detail   string // What was seen, or why the step failed
+	Duration time.Duration
+}
+
+// Result is the outcome of one scenario. It passed if every step did; a
+// scenario stops at its first failed step.
+type Result struct {
+	Scenario string
+	Steps    []Step
+	Passed   bool
+}
+
+// Scenario is a canned flow
+type Scenario struct {
+	Name        string
+	Description string
+	run         func(ctx context.Context, r *run) error
+}
+
+// Options tune the payments the scenarios make
+type Options struct {
+	Currency string // Defaults to USD
+	Amount   int64  // Minor units; defaults to 5000
+
+	// How long to wait for something the sandbox does asynchronously, such
+	// as opening a dispute. Defaults to 15s.
+	Timeout time.Duration
+}
+
+// All lists the scenarios in the order RunAll runs them
+func All() []Scenario {
+	return scenarios
+}
+
+// Find returns the scenario called name
+func Find(name string) (Scenario, bool) {
+	for _, s := range scenarios {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Scenario{}, false
+}
+
+// Run runs one scenario with client, which must use a pg_test_ key
+func (s Scenario) Run(ctx context.Context, client *paymentgateway.Client, opts Options) (*Result, error) {
+	if !client.TestMode() {
+		return nil, ErrLiveKey
+	}
+	if opts.Currency == "" {
+		opts.Currency = "USD"
+	}
+	if opts.Amount <= 0 {
+		opts.Amount = 5000
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+
+	r := &run{client: client, opts: opts, result: &Result{Scenario: s.Name}}
+	err := s.run(ctx, r)
+	r.result.Passed = err == nil
+	if err != nil && !errors.Is(err, errStepFailed) {
+		// A failure outside a step, e.g. the context was cancelled
+		r.result.Steps = append(r.result.Steps, Step{Name: "run", Detail: err.Error()})
+	}
+	return r.result, nil
+}
+
+// RunAll runs every scenario in turn
+func RunAll(ctx context.Context, client *paymentgateway.Client, opts Options) ([]*Result, error) {
+	results := make([]*Result, 0, len(scenarios))
+	for _, s := range scenarios {
+		result, err := s.Run(ctx, client, opts)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+var errStepFailed = errors.New("step failed")
+
+type run struct {
+	client *paymentgateway.Client
+	opts   Options
+	result *Result
+}
+
+// step runs fn and records it. fn returns a short description of what it
+// saw; an error fails the step and ends the scenario.
+func (r *run) step(name string, fn func() (string, error)) error {
+	start := time.Now()
+	detail, err := fn()
+	step := Step{Name: name, Passed: err == nil, Detail: detail, Duration: time.Since(start)}
+	if err != nil {
+		step.Detail = err.Error()
+	}
+	r.result.Steps = append(r.result.Steps, step)
+	if err != nil {
+		return errStepFailed
+	}
+	return nil
+}
+
+func (r *run) card(number string) *paymentgateway.Card {
+	return &paymentgateway.Card{
+		Number:         number,
+		CardholderName: "Scenario Runner",
+		ExpMonth:       12,
+		ExpYear:        time.Now().Year() + 3,
+		CVV:            "123",
+	}
+}
+
+func expectStatus(p *paymentgateway.Payment, want string) error {
+	if p.Status != want {
+		return fmt.Errorf("status %s, want %s (%s %s)", p.Status, want, p.ResponseCode, p.ResponseMsg)
+	}
+	return nil
+}
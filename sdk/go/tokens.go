@@ -0,0 +1,45 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+)
+
+// SandboxCustomer is a sample customer a seeded card is issued to
+type SandboxCustomer struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// SandboxCard is a sandbox card token ready to be sent as CardToken.
+// Scenario names the outcome the card triggers, e.g. declined_generic.
+type SandboxCard struct {
+	Token        string          `json:"token"`
+	Brand        string          `json:"brand"`
+	Last4        string          `json:"last4"`
+	ExpMonth     int             `json:"exp_month"`
+	ExpYear      int             `json:"exp_year"`
+	Scenario     string          `json:"scenario"`
+	ResponseCode string          `json:"response_code,omitempty"`
+	Customer     SandboxCustomer `json:"customer"`
+}
+
+// TokensService covers card tokens. Cards are only tokenized as part of a
+// payment: Payment.Token holds the token of the card it was made with,
+// ready to be charged again through AuthorizeRequest.CardToken.
+type TokensService struct {
+	client *Client
+}
+
+// SeedSandbox tokenizes every test card for the merchant, so integrations
+// can pay with tokens without posting card numbers first. It needs a
+// pg_test_ key and returns the same tokens when repeated.
+func (s *TokensService) SeedSandbox(ctx context.Context, opts ...RequestOption) ([]*SandboxCard, error) {
+	var data struct {
+		Cards []*SandboxCard `json:"cards"`
+	}
+	if err := s.client.do(ctx, &request{method: http.MethodPost, path: "/api/v1/test/seed-vault"}, &data, opts...); err != nil {
+		return nil, err
+	}
+	return data.Cards, nil
+}
@@ -0,0 +1,90 @@
+package paymentgateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WebhookSubscriptionParams creates or updates a subscription. Nil fields
+// are left unchanged on update. Mode is all, live or test.
+type WebhookSubscriptionParams struct {
+	URL         *string   `json:"url,omitempty"`
+	EventTypes  *[]string `json:"event_types,omitempty"`
+	MinAmount   *int64    `json:"min_amount,omitempty"`
+	Currencies  *[]string `json:"currencies,omitempty"`
+	Mode        *string   `json:"mode,omitempty"`
+	RoutingKeys *[]string `json:"routing_keys,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// WebhookSubscription is an endpoint events are delivered to. Secret is
+// only set by Create and RotateSecret. A subscription whose URL failed the
+// ping handshake is inactive, with VerificationError saying why.
+type WebhookSubscription struct {
+	ID                      string     `json:"id"`
+	URL                     string     `json:"url"`
+	EventTypes              []string   `json:"event_types"`
+	MinAmount               int64      `json:"min_amount"`
+	Currencies              []string   `json:"currencies"`
+	Mode                    string     `json:"mode"`
+	RoutingKeys             []string   `json:"routing_keys"`
+	Active                  bool       `json:"active"`
+	Secret                  string     `json:"secret,omitempty"`
+	VerifiedAt              *time.Time `json:"verified_at"`
+	VerificationError       string     `json:"verification_error"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
+
+// WebhookSubscriptionsService calls /api/v1/webhook-subscriptions
+type WebhookSubscriptionsService struct {
+	client *Client
+}
+
+// Create registers an endpoint. The endpoint must echo the challenge of a
+// webhook.ping, sent before Create returns, to be activated.
+func (s *WebhookSubscriptionsService) Create(ctx context.Context, params *WebhookSubscriptionParams, opts ...RequestOption) (*WebhookSubscription, error) {
+	return s.call(ctx, http.MethodPost, "", params, opts)
+}
+
+func (s *WebhookSubscriptionsService) List(ctx context.Context, opts ...RequestOption) ([]*WebhookSubscription, error) {
+	var subs []*WebhookSubscription
+	if err := s.client.do(ctx, &request{method: http.MethodGet, path: "/api/v1/webhook-subscriptions"}, &subs, opts...); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (s *WebhookSubscriptionsService) Get(ctx context.Context, subscriptionID string, opts ...RequestOption) (*WebhookSubscription, error) {
+	return s.call(ctx, http.MethodGet, "/"+pathID(subscriptionID), nil, opts)
+}
+
+func (s *WebhookSubscriptionsService) Update(ctx context.Context, subscriptionID string, params *WebhookSubscriptionParams, opts ...RequestOption) (*WebhookSubscription, error) {
+	return s.call(ctx, http.MethodPatch, "/"+pathID(subscriptionID), params, opts)
+}
+
+func (s *WebhookSubscriptionsService) Delete(ctx context.Context, subscriptionID string, opts ...RequestOption) error {
+	return s.client.do(ctx, &request{method: http.MethodDelete, path: "/api/v1/webhook-subscriptions/" + pathID(subscriptionID)}, nil, opts...)
+}
+
+// RotateSecret gives the subscription a new secret. The old one keeps
+// signing alongside it for the server's default overlap of 24 hours.
+func (s *WebhookSubscriptionsService) RotateSecret(ctx context.Context, subscriptionID string, opts ...RequestOption) (*WebhookSubscription, error) {
+	return s.call(ctx, http.MethodPost, "/"+pathID(subscriptionID)+"/rotate-secret", nil, opts)
+}
+
+// Verify re-runs the ping handshake and activates the subscription if it
+// passes
+func (s *WebhookSubscriptionsService) Verify(ctx context.Context, subscriptionID string, opts ...RequestOption) (*WebhookSubscription, error) {
+	return s.call(ctx, http.MethodPost, "/"+pathID(subscriptionID)+"/verify", nil, opts)
+}
+
+func (s *WebhookSubscriptionsService) call(ctx context.Context, method, path string, body any, opts []RequestOption) (*WebhookSubscription, error) {
+	var sub WebhookSubscription
+	if err := s.client.do(ctx, &request{method: method, path: "/api/v1/webhook-subscriptions" + path, body: body}, &sub, opts...); err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
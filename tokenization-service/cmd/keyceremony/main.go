@@ -0,0 +1,172 @@
+// Command keyceremony runs dual-control ceremonies for platform secrets.
+//
+//	keyceremony propose -operator alice -secret key_encryption_key -action rotate -reason "annual rotation"
+//	keyceremony approve -operator bob -id <ceremony>
+//	keyceremony approve -operator carol -id <ceremony>
+//	keyceremony run -id <ceremony> -out /secrets/key_encryption_key
+//	keyceremony verify
+//
+// run prompts each approver for a passphrase component without echo. The
+// secret is the XOR of the components' SHA-256 digests, so nobody sees it
+// whole; only key check values are printed and logged.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/service"
+)
+
+const usage = "usage: keyceremony [propose|approve|cancel|run|list|verify] [flags]"
+
+func main() {
+	if len(os.Args) < 2 {
+		fatal(usage)
+	}
+	if config.GetEnv("APP_MODE") == "" {
+		inits.InitDotEnv()
+	}
+	logger.Init()
+	inits.InitDB()
+
+	ceremonies := service.NewKeyCeremonyService()
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	operator := flags.String("operator", "", "operator name from KEY_CEREMONY_OPERATORS")
+	id := flags.String("id", "", "ceremony ID")
+
+	switch os.Args[1] {
+	case "propose":
+		secret := flags.String("secret", "", "secret to set: "+secretNames())
+		action := flags.String("action", "rotate", "initialize or rotate")
+		reason := flags.String("reason", "", "why the secret is being set")
+		flags.Parse(os.Args[2:])
+
+		ceremony, err := ceremonies.Propose(*secret, *action, *reason, *operator)
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Printf("ceremony %s proposed, needs 2 approvals by %s\n", ceremony.ID, ceremony.ExpiresAt.Format("2006-01-02 15:04 MST"))
+
+	case "approve":
+		flags.Parse(os.Args[2:])
+		ceremony, err := ceremonies.Approve(parseID(*id), *operator)
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Printf("ceremony %s approved by %s (%d/2), status %s\n", ceremony.ID, *operator, len(ceremony.Approvals), ceremony.Status)
+
+	case "cancel":
+		reason := flags.String("reason", "", "why the ceremony is cancelled")
+		flags.Parse(os.Args[2:])
+		if err := ceremonies.Cancel(parseID(*id), *operator, *reason); err != nil {
+			fatal(err.Error())
+		}
+		fmt.Printf("ceremony %s cancelled\n", *id)
+
+	case "run":
+		out := flags.String("out", "", "file to write the secret to; must not exist")
+		flags.Parse(os.Args[2:])
+		if *out == "" {
+			fatal("-out is required")
+		}
+
+		var components []service.CeremonyComponent
+		reader := bufio.NewReader(os.Stdin)
+		for i := 1; i <= 2; i++ {
+			name := prompt(reader, fmt.Sprintf("Custodian %d, operator name: ", i))
+			passphrase := promptSecret(reader, fmt.Sprintf("%s, passphrase component: ", name))
+			if promptSecret(reader, fmt.Sprintf("%s, repeat passphrase component: ", name)) != passphrase {
+				fatal("passphrase components do not match")
+			}
+			components = append(components, service.CeremonyComponent{Operator: name, Passphrase: passphrase})
+		}
+
+		kcv, err := ceremonies.Run(parseID(*id), components, *out)
+		if err != nil {
+			fatal(err.Error())
+		}
+		fmt.Printf("secret written to %s, KCV %s\n", *out, kcv)
+
+	case "list":
+		flags.Parse(os.Args[2:])
+		list, err := ceremonies.List(50)
+		if err != nil {
+			fatal(err.Error())
+		}
+		for _, c := range list {
+			fmt.Printf("%s  %-16s %-10s %-10s proposed by %-12s approvals %d  kcv %s\n",
+				c.ID, c.Secret, c.Action, c.Status, c.ProposedBy, len(c.Approvals), c.KeyCheckValue.String)
+		}
+
+	case "verify":
+		flags.Parse(os.Args[2:])
+		checked, err := ceremonies.VerifyLog()
+		if err != nil {
+			fatal(fmt.Sprintf("ceremony log is NOT intact after %d events: %v", checked, err))
+		}
+		fmt.Printf("ceremony log intact, %d events\n", checked)
+
+	default:
+		fatal(usage)
+	}
+}
+
+func parseID(id string) uuid.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		fatal("-id must be a ceremony UUID")
+	}
+	return parsed
+}
+
+func secretNames() string {
+	names := make([]string, 0, len(service.CeremonySecrets))
+	for name := range service.CeremonySecrets {
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func prompt(reader *bufio.Reader, label string) string {
+	fmt.Fprint(os.Stderr, label)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		fatal("failed to read input")
+	}
+	return strings.TrimSpace(line)
+}
+
+// promptSecret turns terminal echo off while the component is typed
+func promptSecret(reader *bufio.Reader, label string) string {
+	if setEcho(false) == nil {
+		defer func() {
+			setEcho(true)
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	return prompt(reader, label)
+}
+
+func setEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func fatal(msg string) {
+	fmt.Fprintln(os.Stderr, "keyceremony:", msg)
+	os.Exit(1)
+}
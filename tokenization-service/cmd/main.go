@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/grpc"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/metrics"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/service"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/util"
 	pb "github.com/rhaloubi/payment-gateway/tokenization-service/proto"
 	"go.uber.org/zap"
+	grpclib "google.golang.org/grpc"
 )
 
 func init() {
@@ -26,9 +32,40 @@ func init() {
 func main() {
 	defer logger.Sync()
 
+	// Detokenize and key management only answer peers inside INTERNAL_ALLOWED_CIDRS
+	allowed, err := grpc.ParseCIDRs(config.GetEnvWithDefault("INTERNAL_ALLOWED_CIDRS", grpc.DefaultInternalCIDRs))
+	if err != nil {
+		logger.Log.Fatal("Invalid INTERNAL_ALLOWED_CIDRS", zap.Error(err))
+	}
+
 	// Initialize gRPC server and register service
-	grpcServer, lis := util.InitGRPC()
-	pb.RegisterTokenizationServiceServer(grpcServer, grpc.NewTokenizationServer())
+	grpcServer, lis := util.InitGRPC(grpclib.ChainUnaryInterceptor(
+		metrics.UnaryServerInterceptor(),
+		grpc.AllowlistInterceptor(allowed),
+	))
+	tokenizationService := service.NewTokenizationService()
+	pb.RegisterTokenizationServiceServer(grpcServer, grpc.NewTokenizationServer(tokenizationService))
+	pb.RegisterKeyManagementServiceServer(grpcServer, grpc.NewKeyManagementServer(tokenizationService))
+
+	// grpc.health.v1 reports NOT_SERVING while Postgres or Redis is
+	// unreachable, or Vault is unreachable or sealed
+	readiness := grpc.RegisterHealth(grpcServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go readiness.Run(ctx)
+
+	keyManagement := tokenizationService.KeyManagement()
+	go keyManagement.ListenForKeyInvalidations(ctx)
+	go keyManagement.RunVaultHealthCheck(ctx, config.GetDurationWithDefault("VAULT_HEALTH_INTERVAL", 30*time.Second), func(err error) {
+		readiness.Report("vault", err)
+	})
+
+	// BIN lookups are served from memory; BIN_TABLE_REFRESH sets how stale it may get
+	go tokenizationService.RunBINRefresher(ctx, config.GetDurationWithDefault("BIN_TABLE_REFRESH", 10*time.Minute))
+
+	// Flags cards close to expiry so merchants can refresh them via UpdateCardDetails
+	go tokenizationService.RunAccountUpdater(ctx, config.GetDurationWithDefault("ACCOUNT_UPDATER_INTERVAL", 24*time.Hour))
 
 	// Start gRPC server in a goroutine
 	go func() {
@@ -38,16 +75,29 @@ func main() {
 		}
 	}()
 
+	// Prometheus metrics are served only when METRICS_PORT is set
+	if metricsPort := config.GetEnv("METRICS_PORT"); metricsPort != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Log.Error("Metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
+
 	// Shutdown channel
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	<-stop
 	logger.Log.Warn("🛑 Shutting down gracefully...")
+	cancel()
 
 	// Shutdown gRPC server
 	if grpcServer != nil {
 		logger.Log.Info("🧹 Stopping gRPC server...")
+		readiness.Shutdown()
 		grpcServer.GracefulStop()
 	}
 
@@ -12,7 +12,7 @@ import (
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("usage: migrate [up|down]")
+		log.Fatal("usage: migrate [up|down|sign-vault]")
 	}
 	if config.GetEnv("APP_MODE") == "" {
 		inits.InitDotEnv()
@@ -33,6 +33,13 @@ func main() {
 			log.Fatal(err)
 		}
 
+	case "sign-vault":
+		log.Println("🔏 signing card vault rows")
+		inits.InitRedis() // cached tokens are dropped as they are signed
+		if err := migrations.SignCardVault(); err != nil {
+			log.Fatal(err)
+		}
+
 	default:
 		log.Fatalf("unknown command: %s", os.Args[1])
 	}
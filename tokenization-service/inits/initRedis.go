@@ -12,7 +12,7 @@ var RDB *redis.Client
 var Ctx = context.Background()
 
 func InitRedis() {
-	dsn := config.GetEnv("REDIS_DSN")
+	dsn := config.GetRegionalEnv("REDIS_DSN")
 
 	opt, err := redis.ParseURL(dsn)
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/tokenization-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -35,7 +36,10 @@ func NewAuthServiceClient() *AuthServiceClient {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("auth-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -61,8 +65,8 @@ type ValidateAPIKeyResponse struct {
 	Permissions []string  `json:"permissions"`
 }
 
-func (c *AuthServiceClient) ValidateAPIKey(apiKey string) (*ValidateAPIKeyResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+func (c *AuthServiceClient) ValidateAPIKey(ctx context.Context, apiKey string) (*ValidateAPIKeyResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 
 	resp, err := c.apiKeyClient.GetInfoByAPIKey(ctx, &pb.GetInfoByAPIKeyRequest{
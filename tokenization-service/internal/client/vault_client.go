@@ -0,0 +1,314 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
+)
+
+// Vault key backends
+const (
+	VaultBackendTransit = "transit" // data keys wrapped by a Transit key, ciphertext kept in Postgres
+	VaultBackendKV      = "kv"      // data keys stored in a KV v2 secrets engine
+)
+
+var ErrVaultKeyNotFound = errors.New("key not found in Vault")
+
+// VaultClient talks to the HashiCorp Vault HTTP API. It authenticates with
+// VAULT_TOKEN, or with AppRole (VAULT_ROLE_ID/VAULT_SECRET_ID), in which case
+// the short-lived login token is renewed before its lease runs out.
+type VaultClient struct {
+	addr       string
+	namespace  string
+	backend    string
+	mount      string
+	transitKey string
+	kvPrefix   string
+	roleID     string
+	secretID   string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time // zero for a static token
+}
+
+// NewVaultClientFromEnv configures the client from the VAULT_* variables
+func NewVaultClientFromEnv() (*VaultClient, error) {
+	addr := strings.TrimRight(config.GetEnv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR is required when VAULT_ENABLED is true")
+	}
+	if _, err := url.Parse(addr); err != nil {
+		return nil, fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+
+	c := &VaultClient{
+		addr:       addr,
+		namespace:  config.GetEnv("VAULT_NAMESPACE"),
+		backend:    config.GetEnvWithDefault("VAULT_KEY_BACKEND", VaultBackendTransit),
+		transitKey: config.GetEnvWithDefault("VAULT_TRANSIT_KEY", "tokenization"),
+		kvPrefix:   strings.Trim(config.GetEnvWithDefault("VAULT_KV_PATH", "tokenization/keys"), "/"),
+		roleID:     config.GetEnv("VAULT_ROLE_ID"),
+		secretID:   config.GetEnv("VAULT_SECRET_ID"),
+		token:      config.GetEnv("VAULT_TOKEN"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	switch c.backend {
+	case VaultBackendTransit:
+		c.mount = config.GetEnvWithDefault("VAULT_MOUNT", "transit")
+	case VaultBackendKV:
+		c.mount = config.GetEnvWithDefault("VAULT_MOUNT", "secret")
+	default:
+		return nil, fmt.Errorf("unsupported VAULT_KEY_BACKEND %q, expected transit or kv", c.backend)
+	}
+	c.mount = strings.Trim(c.mount, "/")
+
+	if c.token == "" && (c.roleID == "" || c.secretID == "") {
+		return nil, errors.New("set VAULT_TOKEN or VAULT_ROLE_ID and VAULT_SECRET_ID")
+	}
+	return c, nil
+}
+
+// Backend returns the configured key backend
+func (c *VaultClient) Backend() string {
+	return c.backend
+}
+
+// CreateKey generates a 256-bit data key for keyID. With Transit the
+// returned ref is the wrapped key, which the caller stores to unwrap it
+// later; with KV the key is written to Vault and ref is empty.
+func (c *VaultClient) CreateKey(keyID string) (key []byte, ref string, err error) {
+	if c.backend == VaultBackendTransit {
+		var resp struct {
+			Data struct {
+				Plaintext  string `json:"plaintext"`
+				Ciphertext string `json:"ciphertext"`
+			} `json:"data"`
+		}
+		if err := c.do(http.MethodPost, "/v1/"+c.mount+"/datakey/plaintext/"+url.PathEscape(c.transitKey), map[string]interface{}{"bits": 256}, &resp); err != nil {
+			return nil, "", err
+		}
+		key, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid data key from Vault: %w", err)
+		}
+		return key, resp.Data.Ciphertext, nil
+	}
+
+	var resp struct {
+		Data struct {
+			RandomBytes string `json:"random_bytes"`
+		} `json:"data"`
+	}
+	if err := c.do(http.MethodPost, "/v1/sys/tools/random/32", map[string]interface{}{"format": "base64"}, &resp); err != nil {
+		return nil, "", err
+	}
+	key, err = base64.StdEncoding.DecodeString(resp.Data.RandomBytes)
+	if err != nil || len(key) != 32 {
+		return nil, "", errors.New("invalid random bytes from Vault")
+	}
+
+	// cas=0 refuses to overwrite an existing key
+	body := map[string]interface{}{
+		"data":    map[string]string{"key": base64.StdEncoding.EncodeToString(key)},
+		"options": map[string]int{"cas": 0},
+	}
+	if err := c.do(http.MethodPost, c.kvPath("data", keyID), body, nil); err != nil {
+		return nil, "", err
+	}
+	return key, "", nil
+}
+
+// ReadKey returns the data key for keyID, unwrapping ref with Transit or
+// reading it from KV
+func (c *VaultClient) ReadKey(keyID, ref string) ([]byte, error) {
+	var encoded string
+	if c.backend == VaultBackendTransit {
+		if ref == "" {
+			return nil, ErrVaultKeyNotFound
+		}
+		var resp struct {
+			Data struct {
+				Plaintext string `json:"plaintext"`
+			} `json:"data"`
+		}
+		if err := c.do(http.MethodPost, "/v1/"+c.mount+"/decrypt/"+url.PathEscape(c.transitKey), map[string]string{"ciphertext": ref}, &resp); err != nil {
+			return nil, err
+		}
+		encoded = resp.Data.Plaintext
+	} else {
+		var resp struct {
+			Data struct {
+				Data struct {
+					Key string `json:"key"`
+				} `json:"data"`
+			} `json:"data"`
+		}
+		if err := c.do(http.MethodGet, c.kvPath("data", keyID), nil, &resp); err != nil {
+			return nil, err
+		}
+		encoded = resp.Data.Data.Key
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("invalid data key from Vault")
+	}
+	return key, nil
+}
+
+// DestroyKey permanently deletes a KV key and all its versions. Transit
+// keys are destroyed by discarding their wrapped copy, so this is a no-op.
+func (c *VaultClient) DestroyKey(keyID string) error {
+	if c.backend == VaultBackendTransit {
+		return nil
+	}
+	err := c.do(http.MethodDelete, c.kvPath("metadata", keyID), nil, nil)
+	if errors.Is(err, ErrVaultKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Health checks that Vault is initialized, unsealed and accepts our token
+func (c *VaultClient) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+"/v1/sys/health?standbyok=true&perfstandbyok=true", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault unreachable: %w", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotImplemented:
+		return errors.New("vault is not initialized")
+	case http.StatusServiceUnavailable:
+		return errors.New("vault is sealed")
+	default:
+		return fmt.Errorf("vault health returned %d", resp.StatusCode)
+	}
+
+	return c.do(http.MethodGet, "/v1/auth/token/lookup-self", nil, nil)
+}
+
+func (c *VaultClient) kvPath(kind, keyID string) string {
+	return "/v1/" + c.mount + "/" + kind + "/" + c.kvPrefix + "/" + url.PathEscape(keyID)
+}
+
+// do sends an authenticated request and decodes the JSON response into out
+func (c *VaultClient) do(method, path string, body, out interface{}) error {
+	token, err := c.currentToken()
+	if err != nil {
+		return err
+	}
+	status, err := c.send(method, path, token, body, out)
+	if status == http.StatusForbidden && c.roleID != "" {
+		// The login token was revoked or expired early; log in again next call
+		c.mu.Lock()
+		if c.token == token {
+			c.token = ""
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// send makes one request and returns the HTTP status alongside any error
+func (c *VaultClient) send(method, path, token string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.addr+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, ErrVaultKeyNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&vaultErr)
+		return resp.StatusCode, fmt.Errorf("vault %s %s returned %d: %s", method, path, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// currentToken returns the static token, or an AppRole token that still
+// has at least a third of its lease left, logging in again otherwise
+func (c *VaultClient) currentToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.roleID == "" {
+		return c.token, nil
+	}
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if _, err := c.send(http.MethodPost, "/v1/auth/approle/login", "", map[string]string{
+		"role_id":   c.roleID,
+		"secret_id": c.secretID,
+	}, &resp); err != nil {
+		return "", fmt.Errorf("vault AppRole login failed: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault AppRole login returned no token")
+	}
+
+	lease := time.Duration(resp.Auth.LeaseDuration) * time.Second
+	if lease <= 0 {
+		lease = time.Hour
+	}
+	c.token = resp.Auth.ClientToken
+	c.tokenExpiry = time.Now().Add(lease * 2 / 3)
+	return c.token, nil
+}
@@ -201,3 +201,9 @@ func (s *EncryptionService) GenerateKey() ([]byte, error) {
 func (s *EncryptionService) GenerateKeyID(merchantID string, version int) string {
 	return fmt.Sprintf("key_%s_v%d", merchantID, version)
 }
+
+// GeneratePurposeKeyID generates a key identifier for keys other than card
+// data, keeping their versions apart from the card key sequence
+func (s *EncryptionService) GeneratePurposeKeyID(merchantID, purpose string, version int) string {
+	return fmt.Sprintf("key_%s_%s_v%d", merchantID, purpose, version)
+}
@@ -0,0 +1,136 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/tokenization-service/proto"
+	"go.uber.org/zap"
+)
+
+// exportablePurposes lists the key purposes other services may fetch. Card
+// data keys stay inside this service.
+var exportablePurposes = map[string]bool{
+	model.KeyPurposePaymentPII: true,
+}
+
+// shreddablePurposes lists the purposes ShredMerchantKeys accepts
+var shreddablePurposes = map[string]bool{
+	model.KeyPurposePaymentPII: true,
+	model.KeyPurposeCardData:   true,
+}
+
+type KeyManagementServer struct {
+	pb.UnimplementedKeyManagementServiceServer
+	keyService          *service.KeyManagementService
+	tokenizationService *service.TokenizationService
+}
+
+// NewKeyManagementServer shares the tokenization service's key cache, so a
+// shredded card key stops decrypting tokens right away
+func NewKeyManagementServer(tokenizationService *service.TokenizationService) *KeyManagementServer {
+	return &KeyManagementServer{
+		keyService:          tokenizationService.KeyManagement(),
+		tokenizationService: tokenizationService,
+	}
+}
+
+// =========================================================================
+// GetDataKey (Internal Only)
+// =========================================================================
+
+func (s *KeyManagementServer) GetDataKey(ctx context.Context, req *pb.GetDataKeyRequest) (*pb.GetDataKeyResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.GetDataKeyResponse{Error: "invalid merchant_id"}, nil
+	}
+	if !exportablePurposes[req.Purpose] {
+		return &pb.GetDataKeyResponse{Error: "unsupported key purpose"}, nil
+	}
+
+	if req.KeyId == "" {
+		key, keyID, err := s.keyService.GetOrCreatePurposeKey(merchantID, req.Purpose)
+		if err != nil {
+			logger.Log.Error("gRPC GetDataKey failed", zap.Error(err))
+			return &pb.GetDataKeyResponse{Error: err.Error()}, nil
+		}
+		return &pb.GetDataKeyResponse{KeyId: keyID, Key: key}, nil
+	}
+
+	key, metadata, err := s.keyService.GetPurposeKeyByID(req.KeyId, req.Purpose)
+	if metadata != nil && metadata.MerchantID != merchantID {
+		logger.Log.Warn("Data key requested for another merchant",
+			zap.String("key_id", req.KeyId),
+			zap.String("merchant_id", req.MerchantId),
+		)
+		return &pb.GetDataKeyResponse{Error: "encryption key not found"}, nil
+	}
+	if errors.Is(err, service.ErrKeyShredded) {
+		return &pb.GetDataKeyResponse{KeyId: req.KeyId, Shredded: true}, nil
+	}
+	if err != nil {
+		return &pb.GetDataKeyResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.GetDataKeyResponse{KeyId: req.KeyId, Key: key}, nil
+}
+
+// =========================================================================
+// ShredMerchantKeys
+// =========================================================================
+
+func (s *KeyManagementServer) ShredMerchantKeys(ctx context.Context, req *pb.ShredMerchantKeysRequest) (*pb.ShredMerchantKeysResponse, error) {
+	logger.Log.Warn("gRPC ShredMerchantKeys called",
+		zap.String("merchant_id", req.MerchantId),
+		zap.String("purpose", req.Purpose),
+		zap.String("requested_by", req.RequestedBy),
+		zap.String("reason", req.Reason),
+	)
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ShredMerchantKeysResponse{Error: "invalid merchant_id"}, nil
+	}
+	if !shreddablePurposes[req.Purpose] {
+		return &pb.ShredMerchantKeysResponse{Error: "unsupported key purpose"}, nil
+	}
+
+	var requestedBy uuid.UUID
+	if req.RequestedBy != "" {
+		requestedBy, _ = uuid.Parse(req.RequestedBy)
+	}
+
+	shredded, err := s.keyService.ShredMerchantKeys(merchantID, req.Purpose, requestedBy)
+	if err != nil {
+		return &pb.ShredMerchantKeysResponse{ShreddedKeys: int32(shredded), Error: err.Error()}, nil
+	}
+
+	return &pb.ShredMerchantKeysResponse{ShreddedKeys: int32(shredded)}, nil
+}
+
+// =========================================================================
+// RevokeMerchantTokens
+// =========================================================================
+
+func (s *KeyManagementServer) RevokeMerchantTokens(ctx context.Context, req *pb.RevokeMerchantTokensRequest) (*pb.RevokeMerchantTokensResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.RevokeMerchantTokensResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	var revokedBy uuid.UUID
+	if req.RevokedBy != "" {
+		revokedBy, _ = uuid.Parse(req.RevokedBy)
+	}
+
+	revoked, err := s.tokenizationService.RevokeMerchantTokens(merchantID, revokedBy, req.Reason)
+	if err != nil {
+		return &pb.RevokeMerchantTokensResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.RevokeMerchantTokensResponse{RevokedTokens: int32(revoked)}, nil
+}
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	pb "github.com/rhaloubi/payment-gateway/tokenization-service/proto"
+	"go.uber.org/zap"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultInternalCIDRs covers loopback and the private ranges service
+// containers are addressed from
+const DefaultInternalCIDRs = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+
+// restrictedMethods return card data or control key material, so only
+// callers inside the allowlist may reach them
+var restrictedMethods = map[string]bool{
+	pb.TokenizationService_Detokenize_FullMethodName:            true,
+	pb.KeyManagementService_GetDataKey_FullMethodName:           true,
+	pb.KeyManagementService_ShredMerchantKeys_FullMethodName:    true,
+	pb.KeyManagementService_RevokeMerchantTokens_FullMethodName: true,
+	pb.TokenizationService_ListTokenUsage_FullMethodName:        true,
+	pb.TokenizationService_DeleteTestTokens_FullMethodName:      true,
+	pb.TokenizationService_UpdateCardDetails_FullMethodName:     true,
+	pb.TokenizationService_ListExpiringTokens_FullMethodName:    true,
+	pb.TokenizationService_ListMerchantTokens_FullMethodName:    true,
+}
+
+var internalCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "tokenization_internal_calls_total",
+	Help: "Calls to restricted gRPC methods by method and result (allowed or denied)",
+}, []string{"method", "result"})
+
+// ParseCIDRs parses a comma-separated list of CIDRs. A bare address is
+// treated as a single host.
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	if len(nets) == 0 {
+		return nil, fmt.Errorf("allowlist is empty")
+	}
+	return nets, nil
+}
+
+// AllowlistInterceptor rejects calls to restricted methods from peers
+// outside nets with PermissionDenied. Other methods are not checked.
+func AllowlistInterceptor(nets []*net.IPNet) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+		if !restrictedMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		remote := peerAddress(ctx)
+		if ip := net.ParseIP(remote); ip != nil && containsIP(nets, ip) {
+			internalCalls.WithLabelValues(info.FullMethod, "allowed").Inc()
+			return handler(ctx, req)
+		}
+
+		internalCalls.WithLabelValues(info.FullMethod, "denied").Inc()
+		logger.Log.Warn("Internal call from disallowed network",
+			zap.String("method", info.FullMethod),
+			zap.String("remote_ip", remote),
+		)
+		return nil, status.Error(codes.PermissionDenied, "caller network not allowed")
+	}
+}
+
+func peerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
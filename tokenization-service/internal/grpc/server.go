@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
@@ -15,9 +16,9 @@ type TokenizationServer struct {
 	tokenizationService *service.TokenizationService
 }
 
-func NewTokenizationServer() *TokenizationServer {
+func NewTokenizationServer(tokenizationService *service.TokenizationService) *TokenizationServer {
 	return &TokenizationServer{
-		tokenizationService: service.NewTokenizationService(),
+		tokenizationService: tokenizationService,
 	}
 }
 
@@ -58,6 +59,7 @@ func (s *TokenizationServer) TokenizeCard(ctx context.Context, req *pb.TokenizeC
 		IPAddress:      req.IpAddress,
 		UserAgent:      req.UserAgent,
 		CreatedBy:      createdBy,
+		TestMode:       req.TestMode,
 	}
 
 	// Tokenize card
@@ -134,6 +136,7 @@ func (s *TokenizationServer) Detokenize(ctx context.Context, req *pb.DetokenizeR
 		ExpYear:        int32(response.ExpiryYear),
 		CardBrand:      string(response.CardBrand),
 		Last4:          response.Last4Digits,
+		BinCountry:     response.BINCountry,
 	}, nil
 }
 
@@ -210,3 +213,183 @@ func (s *TokenizationServer) RevokeToken(ctx context.Context, req *pb.RevokeToke
 		Message: "token revoked successfully",
 	}, nil
 }
+
+// =========================================================================
+// DeleteTestTokens
+// =========================================================================
+
+func (s *TokenizationServer) DeleteTestTokens(ctx context.Context, req *pb.DeleteTestTokensRequest) (*pb.DeleteTestTokensResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.DeleteTestTokensResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	deleted, err := s.tokenizationService.DeleteTestTokens(merchantID)
+	if err != nil {
+		return &pb.DeleteTestTokensResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.DeleteTestTokensResponse{DeletedTokens: int32(deleted)}, nil
+}
+
+// =========================================================================
+// ListTokenUsage
+// =========================================================================
+
+func (s *TokenizationServer) ListTokenUsage(ctx context.Context, req *pb.ListTokenUsageRequest) (*pb.ListTokenUsageResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListTokenUsageResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	transactionIDs := make([]uuid.UUID, 0, len(req.TransactionIds))
+	for _, id := range req.TransactionIds {
+		txnID, err := uuid.Parse(id)
+		if err != nil {
+			return &pb.ListTokenUsageResponse{Error: "invalid transaction_id: " + id}, nil
+		}
+		transactionIDs = append(transactionIDs, txnID)
+	}
+
+	logs, err := s.tokenizationService.ListTokenUsage(merchantID, transactionIDs)
+	if err != nil {
+		logger.Log.Error("Failed to list token usage", zap.Error(err))
+		return &pb.ListTokenUsageResponse{Error: "failed to list token usage"}, nil
+	}
+
+	entries := make([]*pb.TokenUsageEntry, len(logs))
+	for i, log := range logs {
+		entries[i] = &pb.TokenUsageEntry{
+			TokenId:         log.TokenID.String(),
+			TransactionId:   log.TransactionID.String(),
+			TransactionType: log.TransactionType,
+			UsageType:       log.UsageType,
+			Amount:          log.Amount,
+			Currency:        log.Currency,
+			Success:         log.Success,
+			ErrorCode:       log.ErrorCode.String,
+			IpAddress:       log.IPAddress,
+			CreatedAt:       log.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+
+	return &pb.ListTokenUsageResponse{Entries: entries}, nil
+}
+
+// =========================================================================
+// Account updater (Internal Only)
+// =========================================================================
+
+func (s *TokenizationServer) UpdateCardDetails(ctx context.Context, req *pb.UpdateCardDetailsRequest) (*pb.UpdateCardDetailsResponse, error) {
+	logger.Log.Info("gRPC UpdateCardDetails called",
+		zap.String("token", req.Token),
+		zap.String("merchant_id", req.MerchantId),
+		zap.String("source", req.Source),
+	)
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.UpdateCardDetailsResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	updatedBy, _ := uuid.Parse(req.UpdatedBy)
+
+	cardVault, err := s.tokenizationService.UpdateCardDetails(&service.UpdateCardDetailsRequest{
+		Token:       req.Token,
+		MerchantID:  merchantID,
+		CardNumber:  req.CardNumber,
+		ExpiryMonth: int(req.ExpMonth),
+		ExpiryYear:  int(req.ExpYear),
+		UpdatedBy:   updatedBy,
+		Source:      req.Source,
+	})
+	if err != nil {
+		logger.Log.Error("gRPC card update failed", zap.Error(err))
+		return &pb.UpdateCardDetailsResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.UpdateCardDetailsResponse{
+		Card: &pb.CardMetadata{
+			Brand:       string(cardVault.CardBrand),
+			Type:        string(cardVault.CardType),
+			Last4:       cardVault.Last4Digits,
+			ExpMonth:    int32(cardVault.ExpiryMonth),
+			ExpYear:     int32(cardVault.ExpiryYear),
+			Fingerprint: cardVault.Fingerprint,
+		},
+		Status: string(cardVault.Status),
+	}, nil
+}
+
+func (s *TokenizationServer) ListExpiringTokens(ctx context.Context, req *pb.ListExpiringTokensRequest) (*pb.ListExpiringTokensResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListExpiringTokensResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	cards, err := s.tokenizationService.ListExpiringTokens(merchantID, int(req.Limit))
+	if err != nil {
+		logger.Log.Error("Failed to list expiring tokens", zap.Error(err))
+		return &pb.ListExpiringTokensResponse{Error: "failed to list expiring tokens"}, nil
+	}
+
+	tokens := make([]*pb.ExpiringToken, len(cards))
+	for i, card := range cards {
+		tokens[i] = &pb.ExpiringToken{
+			Token: card.Token,
+			Card: &pb.CardMetadata{
+				Brand:       string(card.CardBrand),
+				Type:        string(card.CardType),
+				Last4:       card.Last4Digits,
+				ExpMonth:    int32(card.ExpiryMonth),
+				ExpYear:     int32(card.ExpiryYear),
+				Fingerprint: card.Fingerprint,
+			},
+		}
+		if card.LastUsedAt.Valid {
+			tokens[i].LastUsedAt = card.LastUsedAt.Time.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	return &pb.ListExpiringTokensResponse{Tokens: tokens}, nil
+}
+
+func (s *TokenizationServer) ListMerchantTokens(ctx context.Context, req *pb.ListMerchantTokensRequest) (*pb.ListMerchantTokensResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListMerchantTokensResponse{Error: "invalid merchant_id"}, nil
+	}
+
+	cards, hasMore, err := s.tokenizationService.ListMerchantTokens(merchantID, int(req.Limit), int(req.Offset))
+	if err != nil {
+		logger.Log.Error("Failed to list merchant tokens", zap.Error(err))
+		return &pb.ListMerchantTokensResponse{Error: "failed to list tokens"}, nil
+	}
+
+	tokens := make([]*pb.MerchantToken, len(cards))
+	for i, card := range cards {
+		tokens[i] = &pb.MerchantToken{
+			Token: card.Token,
+			Card: &pb.CardMetadata{
+				Brand:       string(card.CardBrand),
+				Type:        string(card.CardType),
+				Last4:       card.Last4Digits,
+				ExpMonth:    int32(card.ExpiryMonth),
+				ExpYear:     int32(card.ExpiryYear),
+				Fingerprint: card.Fingerprint,
+			},
+			Status:      string(card.Status),
+			IsSingleUse: card.IsSingleUse,
+			UsageCount:  int32(card.UsageCount),
+			CreatedAt:   card.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+		if card.LastUsedAt.Valid {
+			tokens[i].LastUsedAt = card.LastUsedAt.Time.UTC().Format(time.RFC3339Nano)
+		}
+		if card.RevokedAt.Valid {
+			tokens[i].RevokedAt = card.RevokedAt.Time.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	return &pb.ListMerchantTokensResponse{Tokens: tokens, HasMore: hasMore}, nil
+}
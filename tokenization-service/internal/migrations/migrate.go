@@ -4,6 +4,7 @@ import (
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
 	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/repository"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +23,9 @@ func RunMigrations() error {
 		&model.EncryptionKeyMetadata{},
 		&model.TokenUsageLog{},
 		&model.TokenizationRequest{},
+		&model.KeyCeremony{},
+		&model.KeyCeremonyApproval{},
+		&model.KeyCeremonyEvent{},
 	}
 
 	for _, m := range models {
@@ -33,12 +37,33 @@ func RunMigrations() error {
 	return nil
 }
 
+// SignCardVault signs card vault rows that predate integrity checks and
+// upgrades older MACs to cover status and revocation. Run it once after
+// setting CARD_INTEGRITY_KEY; rows already signed with the current layout
+// are skipped.
+func SignCardVault() error {
+	repo := repository.NewCardVaultRepository()
+	signed, err := repo.SignUnsigned()
+	if err != nil {
+		return err
+	}
+	upgraded, err := repo.UpgradeSignatures()
+	if err != nil {
+		return err
+	}
+	logger.Log.Info("Signed card vault rows", zap.Int("rows", signed), zap.Int("upgraded", upgraded))
+	return nil
+}
+
 // RollbackMigrations rolls back all tokenization service migrations
 func RollbackMigrations() error {
 	db := inits.DB
 
 	// Drop tables in reverse order
 	models := []interface{}{
+		&model.KeyCeremonyEvent{},
+		&model.KeyCeremonyApproval{},
+		&model.KeyCeremony{},
 		&model.CardBINInfo{},
 		&model.CardVault{},
 		&model.EncryptionKeyMetadata{},
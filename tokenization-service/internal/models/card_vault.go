@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +12,20 @@ import (
 type TokenStatus string
 
 const (
-	TokenStatusActive  TokenStatus = "active"
-	TokenStatusExpired TokenStatus = "expired"
-	TokenStatusRevoked TokenStatus = "revoked"
-	TokenStatusUsed    TokenStatus = "used"
+	TokenStatusActive       TokenStatus = "active"
+	TokenStatusExpiringSoon TokenStatus = "expiring_soon" // card expires within the account updater window
+	TokenStatusExpired      TokenStatus = "expired"
+	TokenStatusRevoked      TokenStatus = "revoked"
+	TokenStatusUsed         TokenStatus = "used"
 )
 
+// UsableTokenStatuses can still be detokenized; an expiring_soon card works
+// until its expiry month ends
+var UsableTokenStatuses = []TokenStatus{TokenStatusActive, TokenStatusExpiringSoon}
+
+// TestTokenPrefix marks sandbox tokens issued for test-mode API keys
+const TestTokenPrefix = "tok_test_"
+
 type CardBrand string
 
 const (
@@ -38,8 +47,9 @@ type CardVault struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
 
-	Token       string `gorm:"type:varchar(100);uniqueIndex;not null"` // e.g., tok_live_4xJ3kL9mN2pQ
-	TokenPrefix string `gorm:"type:varchar(20);not null;index"`        // e.g., tok_live_, tok_test_
+	Token       string `gorm:"type:varchar(100);uniqueIndex;not null"`            // e.g., tok_live_4xJ3kL9mN2pQ
+	TokenPrefix string `gorm:"type:varchar(20);not null;index"`                   // e.g., tok_live_, tok_test_
+	Region      string `gorm:"type:varchar(32);not null;default:'primary';index"` // Region whose vault holds the card
 
 	// Encrypted card data (AES-256-GCM)
 	// These fields store base64-encoded encrypted data
@@ -63,10 +73,18 @@ type CardVault struct {
 	// Hash of: card_number + exp_month + exp_year
 	Fingerprint string `gorm:"type:varchar(64);not null;index"`
 
+	// HMAC over IntegrityFields, checked on every read. Version 1 MACs
+	// predate status and revocation being covered.
+	IntegrityMAC     string `gorm:"type:varchar(80)"`
+	IntegrityVersion int    `gorm:"type:integer;not null;default:1"`
+
 	Status      TokenStatus  `gorm:"type:varchar(20);not null;default:'active';index"`
 	IsSingleUse bool         `gorm:"type:boolean;default:false"`
 	ExpiresAt   sql.NullTime `gorm:"type:timestamp;index"`
 
+	// Set when the account updater replaced the PAN or expiry
+	CardUpdatedAt sql.NullTime `gorm:"type:timestamp"`
+
 	// Usage tracking
 	UsageCount  int          `gorm:"type:integer;default:0"` // How many times token was used
 	LastUsedAt  sql.NullTime `gorm:"type:timestamp"`         // Last time token was used for a transaction
@@ -97,8 +115,58 @@ func (cv *CardVault) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// CurrentIntegrityVersion is the IntegrityFields layout new MACs use
+const CurrentIntegrityVersion = 2
+
+// IntegrityFields are the columns covered by IntegrityMAC: the encrypted
+// card, its key, the metadata that identifies and describes it, and from
+// version 2 its status and revocation time, so a revoked token can't be
+// brought back by editing the row. Usage counters change on every use and
+// are not covered.
+func (cv *CardVault) IntegrityFields() []string {
+	fields := cv.integrityFieldsV1()
+	if cv.IntegrityVersion < 2 {
+		return fields
+	}
+	revokedAt := ""
+	if cv.RevokedAt.Valid {
+		revokedAt = cv.RevokedAt.Time.Format("2006-01-02T15:04:05")
+	}
+	return append(fields, string(cv.Status), revokedAt)
+}
+
+func (cv *CardVault) integrityFieldsV1() []string {
+	// Wall clock to the second: the column has no time zone and keeps microseconds
+	expiresAt := ""
+	if cv.ExpiresAt.Valid {
+		expiresAt = cv.ExpiresAt.Time.Format("2006-01-02T15:04:05")
+	}
+	return []string{
+		cv.ID.String(),
+		cv.MerchantID.String(),
+		cv.Token,
+		cv.Region,
+		cv.EncryptedCardNumber,
+		cv.EncryptedCardholderName,
+		cv.EncryptedExpiryMonth,
+		cv.EncryptedExpiryYear,
+		cv.KeyID,
+		strconv.Itoa(cv.EncryptionKeyVersion),
+		cv.Last4Digits,
+		cv.First6Digits,
+		string(cv.CardBrand),
+		string(cv.CardType),
+		strconv.Itoa(cv.ExpiryMonth),
+		strconv.Itoa(cv.ExpiryYear),
+		cv.Fingerprint,
+		strconv.FormatBool(cv.IsSingleUse),
+		expiresAt,
+		cv.CreatedBy.String(),
+	}
+}
+
 func (cv *CardVault) IsValid() bool {
-	if cv.Status != TokenStatusActive {
+	if cv.Status != TokenStatusActive && cv.Status != TokenStatusExpiringSoon {
 		return false
 	}
 
@@ -8,12 +8,20 @@ import (
 	"gorm.io/gorm"
 )
 
+// Key purposes. Each purpose has its own active key per merchant, so
+// rotating or shredding one never touches the other.
+const (
+	KeyPurposeCardData   = "card_data"
+	KeyPurposePaymentPII = "payment_pii"
+)
+
 type EncryptionKeyMetadata struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
 
 	KeyID      string `gorm:"type:varchar(100);not null;uniqueIndex"` // Reference to key in Vault
 	KeyVersion int    `gorm:"type:integer;not null;default:1"`        // For key rotation
+	WrappedKey string `gorm:"type:text"`                              // Data key wrapped by Vault Transit; empty for KV and development keys
 
 	// Key metadata
 	Algorithm string `gorm:"type:varchar(50);not null;default:'AES-256-GCM'"` // Encryption algorithm
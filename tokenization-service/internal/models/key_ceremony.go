@@ -0,0 +1,116 @@
+package model
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Key ceremony statuses
+const (
+	KeyCeremonyPending   = "pending"  // waiting for approvals
+	KeyCeremonyApproved  = "approved" // dual control satisfied, ready to run
+	KeyCeremonyCompleted = "completed"
+	KeyCeremonyCancelled = "cancelled"
+)
+
+// KeyCeremony is a request to initialize or change a platform secret. Two
+// operators other than the proposer must approve it, and the secret is then
+// built from passphrase components entered separately by both approvers.
+type KeyCeremony struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Secret     string    `gorm:"type:varchar(50);not null;index"`
+	Action     string    `gorm:"type:varchar(20);not null"` // "initialize" or "rotate"
+	Reason     string    `gorm:"type:text;not null"`
+	ProposedBy string    `gorm:"type:varchar(100);not null"`
+	Status     string    `gorm:"type:varchar(20);not null;default:'pending';index"`
+
+	KeyCheckValue sql.NullString `gorm:"type:varchar(16)"` // KCV of the generated secret, never the secret
+	OutputPath    sql.NullString `gorm:"type:text"`
+
+	ExpiresAt   time.Time    `gorm:"not null"` // approvals lapse after this
+	CompletedAt sql.NullTime `gorm:"type:timestamp"`
+
+	Approvals []KeyCeremonyApproval `gorm:"foreignKey:CeremonyID"`
+
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for KeyCeremony
+func (KeyCeremony) TableName() string {
+	return "key_ceremonies"
+}
+
+// BeforeCreate hook
+func (k *KeyCeremony) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsOpen reports whether the ceremony can still be approved or run
+func (k *KeyCeremony) IsOpen() bool {
+	return (k.Status == KeyCeremonyPending || k.Status == KeyCeremonyApproved) && time.Now().Before(k.ExpiresAt)
+}
+
+// KeyCeremonyApproval is one operator's sign-off on a ceremony
+type KeyCeremonyApproval struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CeremonyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_key_ceremony_operator"`
+	Operator   string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_key_ceremony_operator"`
+	CreatedAt  time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for KeyCeremonyApproval
+func (KeyCeremonyApproval) TableName() string {
+	return "key_ceremony_approvals"
+}
+
+// BeforeCreate hook
+func (a *KeyCeremonyApproval) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// KeyCeremonyEvent is one entry of the append-only ceremony log. Each entry
+// hashes the one before it, so editing, reordering or deleting rows breaks
+// the chain.
+type KeyCeremonyEvent struct {
+	Sequence   int64     `gorm:"primaryKey;autoIncrement:false"`
+	CeremonyID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Event      string    `gorm:"type:varchar(30);not null"` // proposed, approved, cancelled, component_entered, completed
+	Operator   string    `gorm:"type:varchar(100);not null"`
+	Detail     string    `gorm:"type:text"`
+	PrevHash   string    `gorm:"type:char(64)"`
+	Hash       string    `gorm:"type:char(64);not null;uniqueIndex"`
+	CreatedAt  time.Time `gorm:"type:timestamptz;not null"`
+}
+
+// TableName specifies the table name for KeyCeremonyEvent
+func (KeyCeremonyEvent) TableName() string {
+	return "key_ceremony_events"
+}
+
+// ComputeHash returns the chain hash of the event over its fields and the
+// previous event's hash
+func (e *KeyCeremonyEvent) ComputeHash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s",
+		e.Sequence,
+		e.CeremonyID,
+		e.Event,
+		e.Operator,
+		e.Detail,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.PrevHash,
+	)))
+	return hex.EncodeToString(sum[:])
+}
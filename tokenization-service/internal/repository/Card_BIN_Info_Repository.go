@@ -18,11 +18,36 @@ func NewCardBINRepository() *CardBINRepository {
 }
 
 func (r *CardBINRepository) Create(binInfo *model.CardBINInfo) error {
-	return inits.DB.Create(binInfo).Error
+	if err := inits.DB.Create(binInfo).Error; err != nil {
+		return err
+	}
+	bins.put(*binInfo)
+	return nil
 }
 
-// FindByBIN finds card info by BIN (first 6 digits)
+// LoadAll reloads the in-memory BIN table from Postgres
+func (r *CardBINRepository) LoadAll() (int, error) {
+	var infos []model.CardBINInfo
+	if err := inits.DB.Find(&infos).Error; err != nil {
+		return 0, err
+	}
+	bins.replace(infos)
+	return len(infos), nil
+}
+
+// FindByBIN finds card info by BIN (first 6 digits). The in-memory table
+// answers once loaded; before that Redis and Postgres do.
 func (r *CardBINRepository) FindByBIN(bin string) (*model.CardBINInfo, error) {
+	if info, found, loaded := bins.lookup(bin); loaded {
+		if !found {
+			binLookups.WithLabelValues("miss").Inc()
+			return nil, nil
+		}
+		binLookups.WithLabelValues("hit").Inc()
+		return &info, nil
+	}
+	binLookups.WithLabelValues("fallback").Inc()
+
 	cacheKey := fmt.Sprintf("bin:%s", bin)
 	cachedData, err := inits.RDB.Get(inits.Ctx, cacheKey).Result()
 
@@ -54,6 +79,7 @@ func (r *CardBINRepository) Update(binInfo *model.CardBINInfo) error {
 	if err != nil {
 		return err
 	}
+	bins.put(*binInfo)
 
 	cacheKey := fmt.Sprintf("bin:%s", binInfo.BIN)
 	inits.RDB.Del(inits.Ctx, cacheKey)
@@ -66,6 +92,7 @@ func (r *CardBINRepository) Delete(bin string) error {
 	if err != nil {
 		return err
 	}
+	bins.remove(bin)
 
 	// Invalidate cache
 	cacheKey := fmt.Sprintf("bin:%s", bin)
@@ -82,5 +109,11 @@ func (r *CardBINRepository) FindByCardBrand(cardBrand model.CardBrand) ([]model.
 
 // BulkCreate creates multiple BIN entries at once
 func (r *CardBINRepository) BulkCreate(binInfos []model.CardBINInfo) error {
-	return inits.DB.CreateInBatches(binInfos, 100).Error
+	if err := inits.DB.CreateInBatches(binInfos, 100).Error; err != nil {
+		return err
+	}
+	for _, info := range binInfos {
+		bins.put(info)
+	}
+	return nil
 }
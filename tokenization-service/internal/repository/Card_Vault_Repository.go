@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CardVaultRepository struct{}
@@ -26,6 +28,12 @@ const (
 )
 
 func (r *CardVaultRepository) Create(cardVault *model.CardVault) error {
+	// The ID is covered by the MAC, so it is set before signing
+	if cardVault.ID == uuid.Nil {
+		cardVault.ID = uuid.New()
+	}
+	signCard(cardVault)
+
 	err := inits.DB.Create(cardVault).Error
 	if err != nil {
 		return err
@@ -43,7 +51,10 @@ func (r *CardVaultRepository) FindByToken(token string) (*model.CardVault, error
 	if err == nil && cachedData != "" {
 		var cardVault model.CardVault
 		if err = json.Unmarshal([]byte(cachedData), &cardVault); err == nil {
-			return &cardVault, nil
+			if verifyCard(&cardVault, "cache") == nil {
+				return &cardVault, nil
+			}
+			r.invalidateTokenCache(token)
 		}
 	}
 
@@ -55,6 +66,9 @@ func (r *CardVaultRepository) FindByToken(token string) (*model.CardVault, error
 		}
 		return nil, err
 	}
+	if err := verifyCard(&cardVault, "db"); err != nil {
+		return nil, err
+	}
 
 	r.cacheToken(&cardVault)
 
@@ -70,14 +84,25 @@ func (r *CardVaultRepository) FindByID(id uuid.UUID) (*model.CardVault, error) {
 		}
 		return nil, err
 	}
+	if err := verifyCard(&cardVault, "db"); err != nil {
+		return nil, err
+	}
 	return &cardVault, nil
 }
 
-func (r *CardVaultRepository) FindByFingerprint(merchantID uuid.UUID, fingerprint string) (*model.CardVault, error) {
+// FindByFingerprint finds an active token for the card. Test and live
+// tokens are never reused for each other.
+func (r *CardVaultRepository) FindByFingerprint(merchantID uuid.UUID, fingerprint string, testMode bool) (*model.CardVault, error) {
+	query := inits.DB.Where("merchant_id = ? AND fingerprint = ? AND status IN ? AND deleted_at IS NULL",
+		merchantID, fingerprint, model.UsableTokenStatuses)
+	if testMode {
+		query = query.Where("token LIKE ?", model.TestTokenPrefix+"%")
+	} else {
+		query = query.Where("token NOT LIKE ?", model.TestTokenPrefix+"%")
+	}
+
 	var cardVault model.CardVault
-	err := inits.DB.Where("merchant_id = ? AND fingerprint = ? AND status = ? AND deleted_at IS NULL",
-		merchantID, fingerprint, model.TokenStatusActive).
-		First(&cardVault).Error
+	err := query.First(&cardVault).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -85,21 +110,31 @@ func (r *CardVaultRepository) FindByFingerprint(merchantID uuid.UUID, fingerprin
 		}
 		return nil, err
 	}
+
+	// A tampered row is never reused; the card gets a fresh token
+	if verifyCard(&cardVault, "db") != nil {
+		return nil, nil
+	}
 	return &cardVault, nil
 }
 
 func (r *CardVaultRepository) FindByMerchantAndLast4(merchantID uuid.UUID, last4 string) ([]model.CardVault, error) {
 	var cards []model.CardVault
-	err := inits.DB.Where("merchant_id = ? AND last4_digits = ? AND status = ? AND deleted_at IS NULL",
-		merchantID, last4, model.TokenStatusActive).
+	err := inits.DB.Where("merchant_id = ? AND last4_digits = ? AND status IN ? AND deleted_at IS NULL",
+		merchantID, last4, model.UsableTokenStatuses).
 		Order("created_at DESC").
 		Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
 
-	return cards, err
+	return verifiedCards(cards), nil
 }
 
-// Update updates a card vault entry
+// Update updates a card vault entry and re-signs it
 func (r *CardVaultRepository) Update(cardVault *model.CardVault) error {
+	signCard(cardVault)
+
 	err := inits.DB.Save(cardVault).Error
 	if err != nil {
 		return err
@@ -113,18 +148,12 @@ func (r *CardVaultRepository) Update(cardVault *model.CardVault) error {
 
 // UpdateStatus updates token status
 func (r *CardVaultRepository) UpdateStatus(token string, status model.TokenStatus) error {
-	err := inits.DB.Model(&model.CardVault{}).
-		Where("token = ?", token).
-		Update("status", status).Error
-
-	if err != nil {
-		return err
-	}
-
-	// Invalidate cache
-	r.invalidateTokenCache(token)
-
-	return nil
+	_, err := r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("token = ?", token)
+	}, func(cardVault *model.CardVault) {
+		cardVault.Status = status
+	})
+	return err
 }
 
 // IncrementUsageCount increments the usage count and updates last used timestamp
@@ -171,23 +200,64 @@ func (r *CardVaultRepository) SetFirstUsed(token string) error {
 
 // RevokeToken revokes a token
 func (r *CardVaultRepository) RevokeToken(token string, revokedBy uuid.UUID, reason string) error {
-	err := inits.DB.Model(&model.CardVault{}).
-		Where("token = ?", token).
-		Updates(map[string]interface{}{
-			"status":            model.TokenStatusRevoked,
-			"revoked_by":        revokedBy,
-			"revoked_at":        time.Now(),
-			"revocation_reason": reason,
-		}).Error
+	_, err := r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("token = ?", token)
+	}, revoke(revokedBy, reason, time.Now()))
+	return err
+}
+
+// RevokeMerchantTokens revokes every token a merchant still has usable
+func (r *CardVaultRepository) RevokeMerchantTokens(merchantID uuid.UUID, revokedBy uuid.UUID, reason string) (int, error) {
+	return r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("merchant_id = ? AND status IN ? AND deleted_at IS NULL", merchantID, model.UsableTokenStatuses)
+	}, revoke(revokedBy, reason, time.Now()))
+}
+
+func revoke(revokedBy uuid.UUID, reason string, now time.Time) func(*model.CardVault) {
+	return func(cardVault *model.CardVault) {
+		cardVault.Status = model.TokenStatusRevoked
+		cardVault.RevokedBy = revokedBy
+		cardVault.RevokedAt = sql.NullTime{Time: now, Valid: true}
+		cardVault.RevocationReason = sql.NullString{String: reason, Valid: true}
+	}
+}
+
+// DeleteTestTokens permanently deletes a merchant's sandbox tokens along
+// with their request and usage logs
+func (r *CardVaultRepository) DeleteTestTokens(merchantID uuid.UUID) (int, error) {
+	var tokens []model.CardVault
+	if err := inits.DB.Unscoped().Select("id", "token").
+		Where("merchant_id = ? AND token LIKE ?", merchantID, model.TestTokenPrefix+"%").
+		Find(&tokens).Error; err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, nil
+	}
 
+	ids := make([]uuid.UUID, len(tokens))
+	for i, t := range tokens {
+		ids[i] = t.ID
+	}
+
+	err := inits.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_id IN ?", ids).Delete(&model.TokenUsageLog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("token_id IN ?", ids).Delete(&model.TokenizationRequest{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&model.CardVault{}).Error
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	// Invalidate cache
-	r.invalidateTokenCache(token)
+	for _, t := range tokens {
+		r.invalidateTokenCache(t.Token)
+	}
 
-	return nil
+	return len(tokens), nil
 }
 
 // Delete soft deletes a card vault entry
@@ -213,7 +283,7 @@ func (r *CardVaultRepository) Delete(id uuid.UUID) error {
 func (r *CardVaultRepository) CountByMerchant(merchantID uuid.UUID) (int64, error) {
 	var count int64
 	err := inits.DB.Model(&model.CardVault{}).
-		Where("merchant_id = ? AND status = ? AND deleted_at IS NULL", merchantID, model.TokenStatusActive).
+		Where("merchant_id = ? AND status IN ? AND deleted_at IS NULL", merchantID, model.UsableTokenStatuses).
 		Count(&count).Error
 
 	return count, err
@@ -234,9 +304,182 @@ func (r *CardVaultRepository) FindExpiredTokens(limit int) ([]model.CardVault, e
 
 // MarkExpiredTokens marks tokens as expired
 func (r *CardVaultRepository) MarkExpiredTokens(tokenIDs []uuid.UUID) error {
-	return inits.DB.Model(&model.CardVault{}).
-		Where("id IN ?", tokenIDs).
-		Update("status", model.TokenStatusExpired).Error
+	_, err := r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id IN ?", tokenIDs)
+	}, func(cardVault *model.CardVault) {
+		cardVault.Status = model.TokenStatusExpired
+	})
+	return err
+}
+
+// MarkExpiringCards flags usable tokens whose card expires in or before the
+// given month as expiring_soon, and those whose card has already expired as
+// expired. It returns how many tokens each status gained.
+func (r *CardVaultRepository) MarkExpiringCards(now time.Time, horizonYear, horizonMonth int) (expiring int, expired int, err error) {
+	nowMonths := now.Year()*12 + int(now.Month())
+	horizonMonths := horizonYear*12 + horizonMonth
+
+	expired, err = r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("status IN ? AND deleted_at IS NULL AND expiry_year * 12 + expiry_month < ?",
+			model.UsableTokenStatuses, nowMonths)
+	}, func(cardVault *model.CardVault) {
+		cardVault.Status = model.TokenStatusExpired
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	expiring, err = r.updateSigned(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("status = ? AND deleted_at IS NULL AND expiry_year * 12 + expiry_month <= ?",
+			model.TokenStatusActive, horizonMonths)
+	}, func(cardVault *model.CardVault) {
+		cardVault.Status = model.TokenStatusExpiringSoon
+	})
+	if err != nil {
+		return 0, expired, err
+	}
+
+	return expiring, expired, nil
+}
+
+// FindExpiringByMerchant lists a merchant's expiring_soon tokens, soonest
+// card expiry first
+func (r *CardVaultRepository) FindExpiringByMerchant(merchantID uuid.UUID, limit int) ([]model.CardVault, error) {
+	var cards []model.CardVault
+	err := inits.DB.Where("merchant_id = ? AND status = ? AND deleted_at IS NULL",
+		merchantID, model.TokenStatusExpiringSoon).
+		Order("expiry_year ASC, expiry_month ASC").
+		Limit(limit).
+		Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return verifiedCards(cards), nil
+}
+
+// FindByMerchant pages through a merchant's tokens in creation order. The
+// second result reports whether more rows follow.
+func (r *CardVaultRepository) FindByMerchant(merchantID uuid.UUID, limit, offset int) ([]model.CardVault, bool, error) {
+	var cards []model.CardVault
+	err := inits.DB.Where("merchant_id = ? AND deleted_at IS NULL", merchantID).
+		Order("created_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit + 1).
+		Find(&cards).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(cards) > limit
+	if hasMore {
+		cards = cards[:limit]
+	}
+	return verifiedCards(cards), hasMore, nil
+}
+
+// SignUnsigned signs rows written before integrity checks existed and
+// returns how many it signed. Rows that already have a MAC are left alone.
+func (r *CardVaultRepository) SignUnsigned() (int, error) {
+	if cardIntegrity() == nil {
+		return 0, errors.New("CARD_INTEGRITY_KEY is not set")
+	}
+
+	var cards []model.CardVault
+	signed := 0
+	err := inits.DB.Unscoped().
+		Where("integrity_mac IS NULL OR integrity_mac = ''").
+		FindInBatches(&cards, 500, func(tx *gorm.DB, batch int) error {
+			for i := range cards {
+				signCard(&cards[i])
+				if err := inits.DB.Unscoped().Model(&cards[i]).
+					UpdateColumn("integrity_mac", cards[i].IntegrityMAC).Error; err != nil {
+					return err
+				}
+				r.invalidateTokenCache(cards[i].Token)
+			}
+			signed += len(cards)
+			return nil
+		}).Error
+	return signed, err
+}
+
+// updateSigned changes the rows a query selects and signs them again, since
+// status and revocation are covered by the MAC. Rows are locked while they
+// change. A row that already fails its integrity check is left as it is, so
+// a tampered row is never re-signed; the alert has been raised by then. It
+// returns how many rows changed.
+func (r *CardVaultRepository) updateSigned(query func(*gorm.DB) *gorm.DB, change func(*model.CardVault)) (int, error) {
+	var tokens []string
+	err := inits.DB.Transaction(func(tx *gorm.DB) error {
+		var cards []model.CardVault
+		if err := query(tx.Clauses(clause.Locking{Strength: "UPDATE"})).Find(&cards).Error; err != nil {
+			return err
+		}
+		for i := range cards {
+			card := &cards[i]
+			if verifyCard(card, "db") != nil {
+				continue
+			}
+			change(card)
+			signCard(card)
+			if err := tx.Model(card).
+				Updates(map[string]interface{}{
+					"status":            card.Status,
+					"revoked_by":        card.RevokedBy,
+					"revoked_at":        card.RevokedAt,
+					"revocation_reason": card.RevocationReason,
+					"integrity_mac":     card.IntegrityMAC,
+					"integrity_version": card.IntegrityVersion,
+					"updated_at":        time.Now(),
+				}).Error; err != nil {
+				return err
+			}
+			tokens = append(tokens, card.Token)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, token := range tokens {
+		r.invalidateTokenCache(token)
+	}
+	return len(tokens), nil
+}
+
+// UpgradeSignatures re-signs version 1 rows so their MAC also covers status
+// and revocation. Only rows whose version 1 MAC still verifies are upgraded.
+// It returns how many it upgraded.
+func (r *CardVaultRepository) UpgradeSignatures() (int, error) {
+	if cardIntegrity() == nil {
+		return 0, errors.New("CARD_INTEGRITY_KEY is not set")
+	}
+
+	var cards []model.CardVault
+	upgraded := 0
+	err := inits.DB.Unscoped().
+		Where("integrity_version < ? AND integrity_mac IS NOT NULL AND integrity_mac <> ''", model.CurrentIntegrityVersion).
+		FindInBatches(&cards, 500, func(tx *gorm.DB, batch int) error {
+			for i := range cards {
+				if verifyCard(&cards[i], "db") != nil {
+					continue
+				}
+				signCard(&cards[i])
+				if err := inits.DB.Unscoped().Model(&cards[i]).
+					UpdateColumns(map[string]interface{}{
+						"integrity_mac":     cards[i].IntegrityMAC,
+						"integrity_version": cards[i].IntegrityVersion,
+					}).Error; err != nil {
+					return err
+				}
+				r.invalidateTokenCache(cards[i].Token)
+				upgraded++
+			}
+			return nil
+		}).Error
+	return upgraded, err
 }
 
 func (r *CardVaultRepository) cacheToken(cardVault *model.CardVault) {
@@ -48,6 +48,33 @@ func (r *EncryptionKeyRepository) FindActiveByMerchant(merchantID uuid.UUID) (*m
 	return &keyMetadata, nil
 }
 
+// FindActiveByMerchantAndPurpose returns the merchant's active key for one purpose
+func (r *EncryptionKeyRepository) FindActiveByMerchantAndPurpose(merchantID uuid.UUID, purpose string) (*model.EncryptionKeyMetadata, error) {
+	var keyMetadata model.EncryptionKeyMetadata
+	err := inits.DB.Where("merchant_id = ? AND purpose = ? AND is_active = ? AND deleted_at IS NULL",
+		merchantID, purpose, true).
+		Order("created_at DESC").
+		First(&keyMetadata).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no active encryption key found for merchant")
+		}
+		return nil, err
+	}
+	return &keyMetadata, nil
+}
+
+// FindByMerchantAndPurpose returns every key version a merchant has for one purpose
+func (r *EncryptionKeyRepository) FindByMerchantAndPurpose(merchantID uuid.UUID, purpose string) ([]model.EncryptionKeyMetadata, error) {
+	var keys []model.EncryptionKeyMetadata
+	err := inits.DB.Where("merchant_id = ? AND purpose = ? AND deleted_at IS NULL", merchantID, purpose).
+		Order("key_version DESC").
+		Find(&keys).Error
+
+	return keys, err
+}
+
 func (r *EncryptionKeyRepository) FindByMerchant(merchantID uuid.UUID) ([]model.EncryptionKeyMetadata, error) {
 	var keys []model.EncryptionKeyMetadata
 	err := inits.DB.Where("merchant_id = ? AND deleted_at IS NULL", merchantID).
@@ -80,9 +107,10 @@ func (r *EncryptionKeyRepository) RevokeKey(keyID string, revokedBy uuid.UUID) e
 	return inits.DB.Model(&model.EncryptionKeyMetadata{}).
 		Where("key_id = ?", keyID).
 		Updates(map[string]interface{}{
-			"is_active":  false,
-			"revoked_by": revokedBy,
-			"revoked_at": time.Now(),
+			"is_active":   false,
+			"revoked_by":  revokedBy,
+			"revoked_at":  time.Now(),
+			"wrapped_key": "", // Without its wrapped copy a Transit data key is unrecoverable
 		}).Error
 }
 
@@ -40,6 +40,15 @@ func (r *TokenUsageLogRepository) FindByTransaction(transactionID uuid.UUID) (*m
 	return &log, nil
 }
 
+func (r *TokenUsageLogRepository) FindByTransactions(merchantID uuid.UUID, transactionIDs []uuid.UUID) ([]model.TokenUsageLog, error) {
+	var logs []model.TokenUsageLog
+	err := inits.DB.Where("merchant_id = ? AND transaction_id IN ?", merchantID, transactionIDs).
+		Order("created_at ASC").
+		Find(&logs).Error
+
+	return logs, err
+}
+
 func (r *TokenUsageLogRepository) FindByMerchant(merchantID uuid.UUID, limit int, offset int) ([]model.TokenUsageLog, error) {
 	var logs []model.TokenUsageLog
 	err := inits.DB.Where("merchant_id = ?", merchantID).
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+)
+
+var (
+	binLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tokenization_bin_lookups_total",
+		Help: "BIN lookups by result: hit or miss in the in-memory table, or fallback before it is loaded",
+	}, []string{"result"})
+
+	binTableEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "tokenization_bin_table_entries",
+		Help: "BINs held in the in-memory table",
+	})
+)
+
+// binTable is an in-memory copy of card_bin_info. Once loaded it answers
+// every lookup, including unknown BINs, which would otherwise reach
+// Postgres each time.
+type binTable struct {
+	mu      sync.RWMutex
+	entries map[string]model.CardBINInfo
+}
+
+// bins is shared by every CardBINRepository so writes through one are seen
+// by lookups through another
+var bins = &binTable{}
+
+// lookup returns the BIN's info, whether it was found, and whether the
+// table has been loaded at all
+func (t *binTable) lookup(bin string) (model.CardBINInfo, bool, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.entries == nil {
+		return model.CardBINInfo{}, false, false
+	}
+	info, ok := t.entries[bin]
+	return info, ok, true
+}
+
+func (t *binTable) replace(infos []model.CardBINInfo) {
+	entries := make(map[string]model.CardBINInfo, len(infos))
+	for _, info := range infos {
+		entries[info.BIN] = info
+	}
+
+	t.mu.Lock()
+	t.entries = entries
+	t.mu.Unlock()
+	binTableEntries.Set(float64(len(entries)))
+}
+
+// put and remove keep a loaded table current between reloads
+func (t *binTable) put(info model.CardBINInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries != nil {
+		t.entries[info.BIN] = info
+		binTableEntries.Set(float64(len(t.entries)))
+	}
+}
+
+func (t *binTable) remove(bin string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries != nil {
+		delete(t.entries, bin)
+		binTableEntries.Set(float64(len(t.entries)))
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/crypto"
+	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrIntegrityCheckFailed is returned for card vault rows whose MAC does
+// not match, i.e. rows changed outside this service or corrupted
+var ErrIntegrityCheckFailed = errors.New("card vault integrity check failed")
+
+var integrityFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "tokenization_vault_integrity_failures_total",
+	Help: "Card vault rows that failed their integrity check, by reason (mismatch, unsigned) and source (db, cache).",
+}, []string{"reason", "source"})
+
+var (
+	integrityOnce sync.Once
+	integrity     *crypto.IntegrityService
+)
+
+// cardIntegrity returns the row signer keyed by CARD_INTEGRITY_KEY, or nil
+// when no key is set
+func cardIntegrity() *crypto.IntegrityService {
+	integrityOnce.Do(func() {
+		key := config.GetEnv("CARD_INTEGRITY_KEY")
+		if key == "" {
+			logger.Log.Warn("CARD_INTEGRITY_KEY not set, card vault rows are not signed - NOT PRODUCTION SAFE")
+			return
+		}
+		svc, err := crypto.NewIntegrityService([]byte(key))
+		if err != nil {
+			logger.Log.Fatal("Invalid CARD_INTEGRITY_KEY", zap.Error(err))
+		}
+		integrity = svc
+	})
+	return integrity
+}
+
+// signCard sets the row's MAC from its current fields
+func signCard(cardVault *model.CardVault) {
+	if svc := cardIntegrity(); svc != nil {
+		cardVault.IntegrityVersion = model.CurrentIntegrityVersion
+		cardVault.IntegrityMAC = svc.Sign(cardVault.IntegrityFields()...)
+	}
+}
+
+// verifyCard checks the row's MAC and raises an alert when it fails
+func verifyCard(cardVault *model.CardVault, source string) error {
+	svc := cardIntegrity()
+	if svc == nil {
+		return nil
+	}
+
+	reason := ""
+	switch {
+	case cardVault.IntegrityMAC == "":
+		reason = "unsigned"
+	case !svc.Verify(cardVault.IntegrityMAC, cardVault.IntegrityFields()...):
+		reason = "mismatch"
+	default:
+		return nil
+	}
+
+	integrityFailures.WithLabelValues(reason, source).Inc()
+	logger.Log.Error("ALERT: card vault integrity check failed",
+		zap.String("card_id", cardVault.ID.String()),
+		zap.String("merchant_id", cardVault.MerchantID.String()),
+		zap.String("token_prefix", cardVault.TokenPrefix),
+		zap.String("reason", reason),
+		zap.String("source", source),
+	)
+	return ErrIntegrityCheckFailed
+}
+
+// verifiedCards drops rows that fail their integrity check
+func verifiedCards(cards []model.CardVault) []model.CardVault {
+	verified := cards[:0]
+	for i := range cards {
+		if verifyCard(&cards[i], "db") == nil {
+			verified = append(verified, cards[i])
+		}
+	}
+	return verified
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits"
+	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type KeyCeremonyRepository struct{}
+
+// NewKeyCeremonyRepository creates a new key ceremony repository
+func NewKeyCeremonyRepository() *KeyCeremonyRepository {
+	return &KeyCeremonyRepository{}
+}
+
+// Create creates a new key ceremony
+func (r *KeyCeremonyRepository) Create(ceremony *model.KeyCeremony) error {
+	return inits.DB.Create(ceremony).Error
+}
+
+// FindByID finds a key ceremony with its approvals
+func (r *KeyCeremonyRepository) FindByID(id uuid.UUID) (*model.KeyCeremony, error) {
+	var ceremony model.KeyCeremony
+	err := inits.DB.Preload("Approvals").Where("id = ?", id).First(&ceremony).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("key ceremony not found")
+		}
+		return nil, err
+	}
+	return &ceremony, nil
+}
+
+// FindOpenBySecret finds a pending or approved ceremony for a secret
+func (r *KeyCeremonyRepository) FindOpenBySecret(secret string) (*model.KeyCeremony, error) {
+	var ceremony model.KeyCeremony
+	err := inits.DB.Where("secret = ? AND status IN ? AND expires_at > ?",
+		secret, []string{model.KeyCeremonyPending, model.KeyCeremonyApproved}, time.Now()).
+		First(&ceremony).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ceremony, nil
+}
+
+// List lists key ceremonies, newest first
+func (r *KeyCeremonyRepository) List(limit int) ([]model.KeyCeremony, error) {
+	var ceremonies []model.KeyCeremony
+	err := inits.DB.Preload("Approvals").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&ceremonies).Error
+	return ceremonies, err
+}
+
+// Update saves a key ceremony
+func (r *KeyCeremonyRepository) Update(ceremony *model.KeyCeremony) error {
+	return inits.DB.Omit("Approvals").Save(ceremony).Error
+}
+
+// AddApproval records an operator's approval
+func (r *KeyCeremonyRepository) AddApproval(approval *model.KeyCeremonyApproval) error {
+	return inits.DB.Create(approval).Error
+}
+
+// AppendEvent adds an event to the end of the ceremony log. The table is
+// locked so concurrent writers cannot fork the hash chain.
+func (r *KeyCeremonyRepository) AppendEvent(event *model.KeyCeremonyEvent) error {
+	return inits.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE key_ceremony_events IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		var last model.KeyCeremonyEvent
+		err := tx.Order("sequence DESC").First(&last).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			event.Sequence = 1
+			event.PrevHash = ""
+		case err != nil:
+			return err
+		default:
+			event.Sequence = last.Sequence + 1
+			event.PrevHash = last.Hash
+		}
+
+		// Postgres keeps microseconds; hash what will be read back
+		event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		event.Hash = event.ComputeHash()
+
+		return tx.Create(event).Error
+	})
+}
+
+// ListEvents returns the whole ceremony log in order
+func (r *KeyCeremonyRepository) ListEvents() ([]model.KeyCeremonyEvent, error) {
+	var events []model.KeyCeremonyEvent
+	err := inits.DB.Order("sequence ASC").Find(&events).Error
+	return events, err
+}
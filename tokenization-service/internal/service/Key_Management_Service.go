@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,43 +9,86 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/client"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/crypto"
 	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/repository"
 	"go.uber.org/zap"
 )
 
+// ErrKeyShredded is returned for keys that were revoked on purpose; the data
+// they protected is gone
+var ErrKeyShredded = errors.New("encryption key has been shredded")
+
+// keyInvalidationChannel carries IDs of revoked keys so every replica drops
+// them from its cache
+const keyInvalidationChannel = "tokenization:key_invalidations"
+
+var vaultUp = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "tokenization_vault_up",
+	Help: "1 when the last Vault health check passed, 0 otherwise.",
+})
+
+// cachedKey is a data key held in memory. Keys read from Vault are leased
+// for VAULT_KEY_CACHE_TTL; development keys never expire.
+type cachedKey struct {
+	key       []byte
+	expiresAt time.Time
+}
+
 type KeyManagementService struct {
 	keyRepo           *repository.EncryptionKeyRepository
 	encryptionService *crypto.EncryptionService
-	keyCache          map[string][]byte
+	keyCache          map[string]cachedKey
 	cacheMutex        sync.RWMutex
 	vaultEnabled      bool
+	vault             *client.VaultClient
+	cacheTTL          time.Duration
 }
 
 func NewKeyManagementService() *KeyManagementService {
-	vaultEnabled := config.GetEnv("VAULT_ENABLED") == "true"
-
-	return &KeyManagementService{
+	s := &KeyManagementService{
 		keyRepo:           repository.NewEncryptionKeyRepository(),
 		encryptionService: crypto.NewEncryptionService(),
-		keyCache:          make(map[string][]byte),
-		vaultEnabled:      vaultEnabled,
+		keyCache:          make(map[string]cachedKey),
+		vaultEnabled:      config.GetEnv("VAULT_ENABLED") == "true",
+		cacheTTL:          config.GetDurationWithDefault("VAULT_KEY_CACHE_TTL", 5*time.Minute),
 	}
+
+	if s.vaultEnabled {
+		vault, err := client.NewVaultClientFromEnv()
+		if err != nil {
+			logger.Log.Fatal("Invalid Vault configuration", zap.Error(err))
+		}
+		s.vault = vault
+		logger.Log.Info("Vault key storage enabled", zap.String("backend", vault.Backend()))
+	}
+
+	return s
 }
 
 func (s *KeyManagementService) GetOrCreateMerchantKey(merchantID uuid.UUID) ([]byte, string, error) {
+	return s.GetOrCreatePurposeKey(merchantID, model.KeyPurposeCardData)
+}
+
+// GetOrCreatePurposeKey returns the merchant's active key for purpose,
+// creating the first version if there is none
+func (s *KeyManagementService) GetOrCreatePurposeKey(merchantID uuid.UUID, purpose string) ([]byte, string, error) {
 	// Try to get existing active key
-	keyMetadata, err := s.keyRepo.FindActiveByMerchant(merchantID)
+	keyMetadata, err := s.keyRepo.FindActiveByMerchantAndPurpose(merchantID, purpose)
 
 	if err != nil {
 		// No active key found, create one
 		logger.Log.Info("No active key found for merchant, creating new one",
 			zap.String("merchant_id", merchantID.String()),
+			zap.String("purpose", purpose),
 		)
-		return s.CreateMerchantKey(merchantID)
+		return s.CreatePurposeKey(merchantID, purpose)
 	}
 
 	// Check if key is still valid
@@ -53,7 +97,7 @@ func (s *KeyManagementService) GetOrCreateMerchantKey(merchantID uuid.UUID) ([]b
 			zap.String("merchant_id", merchantID.String()),
 			zap.String("key_id", keyMetadata.KeyID),
 		)
-		return s.CreateMerchantKey(merchantID)
+		return s.CreatePurposeKey(merchantID, purpose)
 	}
 
 	// Get the actual key (from cache or Vault)
@@ -65,16 +109,34 @@ func (s *KeyManagementService) GetOrCreateMerchantKey(merchantID uuid.UUID) ([]b
 	return key, keyMetadata.KeyID, nil
 }
 
+// GetPurposeKeyByID is GetKeyByID for callers outside this service, which
+// may only read keys issued for their own purpose
+func (s *KeyManagementService) GetPurposeKeyByID(keyID, purpose string) ([]byte, *model.EncryptionKeyMetadata, error) {
+	keyMetadata, err := s.keyRepo.FindByKeyID(keyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("key metadata not found: %w", err)
+	}
+	if keyMetadata.Purpose != purpose {
+		return nil, nil, errors.New("key was not issued for this purpose")
+	}
+	if keyMetadata.RevokedAt.Valid {
+		return nil, keyMetadata, ErrKeyShredded
+	}
+
+	key, err := s.GetKeyByID(keyID)
+	if err != nil {
+		return nil, keyMetadata, err
+	}
+	return key, keyMetadata, nil
+}
+
 // GetKeyByID retrieves an encryption key by its ID
 func (s *KeyManagementService) GetKeyByID(keyID string) ([]byte, error) {
 	// Check cache first
-	s.cacheMutex.RLock()
-	if cachedKey, exists := s.keyCache[keyID]; exists {
-		s.cacheMutex.RUnlock()
+	if key, ok := s.getCachedKey(keyID); ok {
 		logger.Log.Debug("Key retrieved from cache", zap.String("key_id", keyID))
-		return cachedKey, nil
+		return key, nil
 	}
-	s.cacheMutex.RUnlock()
 
 	// Get key metadata
 	keyMetadata, err := s.keyRepo.FindByKeyID(keyID)
@@ -82,13 +144,18 @@ func (s *KeyManagementService) GetKeyByID(keyID string) ([]byte, error) {
 		return nil, fmt.Errorf("key metadata not found: %w", err)
 	}
 
-	if !keyMetadata.IsValid() {
-		return nil, errors.New("key is inactive or expired")
+	// Rotated keys are still served so data encrypted before a rotation
+	// stays readable
+	if keyMetadata.RevokedAt.Valid {
+		return nil, ErrKeyShredded
+	}
+	if keyMetadata.ExpiresAt.Valid && time.Now().After(keyMetadata.ExpiresAt.Time) {
+		return nil, errors.New("key is expired")
 	}
 
 	var key []byte
 	if s.vaultEnabled {
-		key, err = s.fetchKeyFromVault(keyID)
+		key, err = s.fetchKeyFromVault(keyMetadata)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch key from Vault: %w", err)
 		}
@@ -103,10 +170,7 @@ func (s *KeyManagementService) GetKeyByID(keyID string) ([]byte, error) {
 		)
 	}
 
-	// Cache the key
-	s.cacheMutex.Lock()
-	s.keyCache[keyID] = key
-	s.cacheMutex.Unlock()
+	s.cacheKey(keyID, key)
 
 	logger.Log.Debug("Key retrieved and cached", zap.String("key_id", keyID))
 	return key, nil
@@ -114,8 +178,14 @@ func (s *KeyManagementService) GetKeyByID(keyID string) ([]byte, error) {
 
 // Returns: (key bytes, keyID, error)
 func (s *KeyManagementService) CreateMerchantKey(merchantID uuid.UUID) ([]byte, string, error) {
+	return s.CreatePurposeKey(merchantID, model.KeyPurposeCardData)
+}
+
+// CreatePurposeKey creates a new key version for purpose and deactivates
+// the merchant's previous keys for that purpose
+func (s *KeyManagementService) CreatePurposeKey(merchantID uuid.UUID, purpose string) ([]byte, string, error) {
 	// Deactivate existing active keys
-	existingKeys, _ := s.keyRepo.FindByMerchant(merchantID)
+	existingKeys, _ := s.keyRepo.FindByMerchantAndPurpose(merchantID, purpose)
 	for _, existingKey := range existingKeys {
 		if existingKey.IsActive {
 			s.keyRepo.DeactivateKey(existingKey.KeyID)
@@ -125,13 +195,16 @@ func (s *KeyManagementService) CreateMerchantKey(merchantID uuid.UUID) ([]byte,
 	keyVersion := len(existingKeys) + 1
 
 	keyID := s.encryptionService.GenerateKeyID(merchantID.String(), keyVersion)
+	if purpose != model.KeyPurposeCardData {
+		keyID = s.encryptionService.GeneratePurposeKeyID(merchantID.String(), purpose, keyVersion)
+	}
 
 	var key []byte
+	var wrappedKey string
 	var err error
 
 	if s.vaultEnabled {
-
-		key, err = s.createKeyInVault(keyID, merchantID)
+		key, wrappedKey, err = s.createKeyInVault(keyID)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to create key in Vault: %w", err)
 		}
@@ -151,8 +224,9 @@ func (s *KeyManagementService) CreateMerchantKey(merchantID uuid.UUID) ([]byte,
 		MerchantID:       merchantID,
 		KeyID:            keyID,
 		KeyVersion:       keyVersion,
+		WrappedKey:       wrappedKey,
 		Algorithm:        "AES-256-GCM",
-		Purpose:          "card_data",
+		Purpose:          purpose,
 		IsActive:         true,
 		EncryptedRecords: 0,
 		LastUsedAt:       time.Now(),
@@ -162,13 +236,12 @@ func (s *KeyManagementService) CreateMerchantKey(merchantID uuid.UUID) ([]byte,
 		return nil, "", fmt.Errorf("failed to save key metadata: %w", err)
 	}
 
-	s.cacheMutex.Lock()
-	s.keyCache[keyID] = key
-	s.cacheMutex.Unlock()
+	s.cacheKey(keyID, key)
 
 	logger.Log.Info("Created new encryption key",
 		zap.String("merchant_id", merchantID.String()),
 		zap.String("key_id", keyID),
+		zap.String("purpose", purpose),
 		zap.Int("version", keyVersion),
 	)
 
@@ -177,7 +250,7 @@ func (s *KeyManagementService) CreateMerchantKey(merchantID uuid.UUID) ([]byte,
 
 func (s *KeyManagementService) RotateMerchantKey(merchantID uuid.UUID, rotatedBy uuid.UUID) (string, error) {
 	// Get current active key
-	currentKey, err := s.keyRepo.FindActiveByMerchant(merchantID)
+	currentKey, err := s.keyRepo.FindActiveByMerchantAndPurpose(merchantID, model.KeyPurposeCardData)
 	if err != nil {
 		return "", fmt.Errorf("no active key found: %w", err)
 	}
@@ -212,10 +285,20 @@ func (s *KeyManagementService) RevokeMerchantKey(keyID string, revokedBy uuid.UU
 		return fmt.Errorf("failed to revoke key: %w", err)
 	}
 
-	// Remove from cache
-	s.cacheMutex.Lock()
-	delete(s.keyCache, keyID)
-	s.cacheMutex.Unlock()
+	if s.vaultEnabled {
+		if err := s.vault.DestroyKey(keyID); err != nil {
+			return fmt.Errorf("failed to destroy key in Vault: %w", err)
+		}
+	}
+
+	// Remove from this replica's cache and tell the others
+	s.evictKey(keyID)
+	if err := inits.RDB.Publish(inits.Ctx, keyInvalidationChannel, keyID).Err(); err != nil {
+		logger.Log.Warn("Failed to publish key invalidation, other replicas drop it when its cache lease ends",
+			zap.String("key_id", keyID),
+			zap.Error(err),
+		)
+	}
 
 	logger.Log.Info("Key revoked",
 		zap.String("key_id", keyID),
@@ -225,6 +308,35 @@ func (s *KeyManagementService) RevokeMerchantKey(keyID string, revokedBy uuid.UU
 	return nil
 }
 
+// ShredMerchantKeys revokes every key a merchant has for purpose and drops
+// them from the cache. Data encrypted under those keys can no longer be
+// decrypted, which is how a merchant's data is destroyed on offboarding.
+func (s *KeyManagementService) ShredMerchantKeys(merchantID uuid.UUID, purpose string, revokedBy uuid.UUID) (int, error) {
+	keys, err := s.keyRepo.FindByMerchantAndPurpose(merchantID, purpose)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load keys: %w", err)
+	}
+
+	shredded := 0
+	for _, key := range keys {
+		if key.RevokedAt.Valid {
+			continue
+		}
+		if err := s.RevokeMerchantKey(key.KeyID, revokedBy); err != nil {
+			return shredded, err
+		}
+		shredded++
+	}
+
+	logger.Log.Warn("Merchant keys shredded",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("purpose", purpose),
+		zap.Int("keys", shredded),
+	)
+
+	return shredded, nil
+}
+
 // =========================================================================
 // Key Statistics & Monitoring
 // =========================================================================
@@ -284,7 +396,7 @@ func (s *KeyManagementService) GetKeyStatistics(merchantID uuid.UUID) (*KeyStati
 // CheckKeyRotationNeeded checks if key rotation is needed
 func (s *KeyManagementService) CheckKeyRotationNeeded(merchantID uuid.UUID) (bool, string) {
 	// Get current active key
-	currentKey, err := s.keyRepo.FindActiveByMerchant(merchantID)
+	currentKey, err := s.keyRepo.FindActiveByMerchantAndPurpose(merchantID, model.KeyPurposeCardData)
 	if err != nil {
 		return true, "No active key found"
 	}
@@ -303,12 +415,12 @@ func (s *KeyManagementService) CheckKeyRotationNeeded(merchantID uuid.UUID) (boo
 	return false, ""
 }
 
-func (s *KeyManagementService) fetchKeyFromVault(keyID string) ([]byte, error) {
-	return nil, errors.New("Vault integration not yet implemented")
+func (s *KeyManagementService) fetchKeyFromVault(keyMetadata *model.EncryptionKeyMetadata) ([]byte, error) {
+	return s.vault.ReadKey(keyMetadata.KeyID, keyMetadata.WrappedKey)
 }
 
-func (s *KeyManagementService) createKeyInVault(keyID string, merchantID uuid.UUID) ([]byte, error) {
-	return nil, errors.New("Vault integration not yet implemented")
+func (s *KeyManagementService) createKeyInVault(keyID string) ([]byte, string, error) {
+	return s.vault.CreateKey(keyID)
 }
 
 func (s *KeyManagementService) generateDevelopmentKey(keyID string) ([]byte, error) {
@@ -319,11 +431,96 @@ func (s *KeyManagementService) ClearKeyCache() {
 	s.cacheMutex.Lock()
 	defer s.cacheMutex.Unlock()
 
-	s.keyCache = make(map[string][]byte)
+	s.keyCache = make(map[string]cachedKey)
 
 	logger.Log.Info("Key cache cleared")
 }
 
+func (s *KeyManagementService) getCachedKey(keyID string) ([]byte, bool) {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+
+	entry, exists := s.keyCache[keyID]
+	if !exists || (!entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)) {
+		return nil, false
+	}
+	return entry.key, true
+}
+
+func (s *KeyManagementService) cacheKey(keyID string, key []byte) {
+	entry := cachedKey{key: key}
+	if s.vaultEnabled {
+		entry.expiresAt = time.Now().Add(s.cacheTTL)
+	}
+
+	s.cacheMutex.Lock()
+	s.keyCache[keyID] = entry
+	s.cacheMutex.Unlock()
+}
+
+func (s *KeyManagementService) evictKey(keyID string) {
+	s.cacheMutex.Lock()
+	delete(s.keyCache, keyID)
+	s.cacheMutex.Unlock()
+}
+
+// ListenForKeyInvalidations drops keys other replicas revoked from this
+// replica's cache until ctx is canceled
+func (s *KeyManagementService) ListenForKeyInvalidations(ctx context.Context) {
+	pubsub := inits.RDB.Subscribe(ctx, keyInvalidationChannel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			s.evictKey(msg.Payload)
+			logger.Log.Debug("Key evicted from cache", zap.String("key_id", msg.Payload))
+		}
+	}
+}
+
+// RunVaultHealthCheck checks Vault every interval until ctx is canceled and
+// calls report with each result. Without Vault it reports healthy once.
+func (s *KeyManagementService) RunVaultHealthCheck(ctx context.Context, interval time.Duration, report func(err error)) {
+	if !s.vaultEnabled {
+		report(nil)
+		return
+	}
+
+	check := func() {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		err := s.vault.Health(checkCtx)
+		if err != nil {
+			vaultUp.Set(0)
+			logger.Log.Error("Vault health check failed", zap.Error(err))
+		} else {
+			vaultUp.Set(1)
+		}
+		report(err)
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
 func (s *KeyManagementService) GetCacheSize() int {
 	s.cacheMutex.RLock()
 	defer s.cacheMutex.RUnlock()
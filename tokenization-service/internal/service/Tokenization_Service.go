@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/config"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/crypto"
 	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
@@ -27,6 +28,9 @@ type TokenizationService struct {
 	encryptionService *crypto.EncryptionService
 	validationService *validation.CardValidator
 	keyManagementSvc  *KeyManagementService
+
+	// Cards expiring within this many months are flagged expiring_soon
+	expiringWindowMonths int
 }
 
 func NewTokenizationService() *TokenizationService {
@@ -39,6 +43,8 @@ func NewTokenizationService() *TokenizationService {
 		encryptionService: crypto.NewEncryptionService(),
 		validationService: validation.NewCardValidator(),
 		keyManagementSvc:  NewKeyManagementService(),
+
+		expiringWindowMonths: config.GetIntWithDefault("ACCOUNT_UPDATER_WINDOW_MONTHS", 1),
 	}
 }
 
@@ -57,6 +63,8 @@ type TokenizeCardRequest struct {
 	IPAddress string
 	UserAgent string
 	CreatedBy uuid.UUID
+
+	TestMode bool // sandbox token for a test-mode API key
 }
 
 type TokenizeCardResponse struct {
@@ -88,6 +96,7 @@ type DetokenizeResponse struct {
 	ExpiryYear     int
 	CardBrand      model.CardBrand
 	Last4Digits    string
+	BINCountry     string
 }
 
 func (s *TokenizationService) TokenizeCard(req *TokenizeCardRequest) (*TokenizeCardResponse, error) {
@@ -108,7 +117,7 @@ func (s *TokenizationService) TokenizeCard(req *TokenizeCardRequest) (*TokenizeC
 		strconv.Itoa(req.ExpiryYear),
 	)
 
-	existingCard, err := s.cardVaultRepo.FindByFingerprint(req.MerchantID, fingerprint)
+	existingCard, err := s.cardVaultRepo.FindByFingerprint(req.MerchantID, fingerprint, req.TestMode)
 	if err != nil {
 		logger.Log.Error("Error checking for duplicate", zap.Error(err))
 	}
@@ -151,7 +160,11 @@ func (s *TokenizationService) TokenizeCard(req *TokenizeCardRequest) (*TokenizeC
 		return nil, fmt.Errorf("encryption failed: %w", err)
 	}
 
-	token := s.generateToken("live")
+	environment := "live"
+	if req.TestMode {
+		environment = "test"
+	}
+	token := s.generateToken(environment)
 
 	last4 := s.validationService.GetLast4Digits(req.CardNumber)
 	first6 := s.validationService.GetFirst6Digits(req.CardNumber)
@@ -166,6 +179,7 @@ func (s *TokenizationService) TokenizeCard(req *TokenizeCardRequest) (*TokenizeC
 		MerchantID:              req.MerchantID,
 		Token:                   token,
 		TokenPrefix:             s.getTokenPrefix(token),
+		Region:                  config.Region(),
 		EncryptedCardNumber:     encryptedData.EncryptedCardNumber,
 		EncryptedCardholderName: encryptedData.EncryptedCardholderName,
 		EncryptedExpiryMonth:    encryptedData.EncryptedExpiryMonth,
@@ -290,6 +304,11 @@ func (s *TokenizationService) Detokenize(req *DetokenizeRequest) (*DetokenizeRes
 		Last4Digits:    cardVault.Last4Digits,
 	}
 
+	// Issuer country is used by the transaction service for acquirer routing
+	if binInfo, err := s.binRepo.FindByBIN(cardVault.First6Digits); err == nil && binInfo != nil {
+		response.BINCountry = binInfo.BankCountry
+	}
+
 	logger.Log.Info("Token detokenized successfully",
 		zap.String("token", req.Token),
 		zap.String("merchant_id", req.MerchantID.String()),
@@ -340,6 +359,53 @@ func (s *TokenizationService) RevokeToken(token string, merchantID uuid.UUID, re
 	return nil
 }
 
+// KeyManagement returns the key service whose cache backs this service, so
+// key revocations elsewhere take effect here immediately
+func (s *TokenizationService) KeyManagement() *KeyManagementService {
+	return s.keyManagementSvc
+}
+
+// RevokeMerchantTokens revokes all of a merchant's active tokens, used when
+// the merchant account is closed
+func (s *TokenizationService) RevokeMerchantTokens(merchantID uuid.UUID, revokedBy uuid.UUID, reason string) (int, error) {
+	revoked, err := s.cardVaultRepo.RevokeMerchantTokens(merchantID, revokedBy, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke merchant tokens: %w", err)
+	}
+
+	logger.Log.Warn("Merchant tokens revoked",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int("tokens", revoked),
+		zap.String("reason", reason),
+	)
+
+	return revoked, nil
+}
+
+// DeleteTestTokens wipes a merchant's sandbox tokens for a test data reset
+func (s *TokenizationService) DeleteTestTokens(merchantID uuid.UUID) (int, error) {
+	deleted, err := s.cardVaultRepo.DeleteTestTokens(merchantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete test tokens: %w", err)
+	}
+
+	logger.Log.Info("Merchant test tokens deleted",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int("tokens", deleted),
+	)
+
+	return deleted, nil
+}
+
+// ListTokenUsage returns the usage log entries recorded for a merchant's
+// transactions, oldest first
+func (s *TokenizationService) ListTokenUsage(merchantID uuid.UUID, transactionIDs []uuid.UUID) ([]model.TokenUsageLog, error) {
+	if len(transactionIDs) == 0 {
+		return []model.TokenUsageLog{}, nil
+	}
+	return s.tokenUsageRepo.FindByTransactions(merchantID, transactionIDs)
+}
+
 // GetTokenInfo retrieves token metadata (without decrypting)
 func (s *TokenizationService) GetTokenInfo(token string, merchantID uuid.UUID) (*model.CardVault, error) {
 	cardVault, err := s.cardVaultRepo.FindByToken(token)
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/tokenization-service/internal/crypto"
+	model "github.com/rhaloubi/payment-gateway/tokenization-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// UpdateCardDetailsRequest replaces the card behind a token, as reported by a
+// card network account updater or the cardholder's issuer
+type UpdateCardDetailsRequest struct {
+	Token       string
+	MerchantID  uuid.UUID
+	CardNumber  string // empty keeps the current PAN
+	ExpiryMonth int
+	ExpiryYear  int
+	UpdatedBy   uuid.UUID
+	Source      string // e.g. "visa_vau", "mastercard_abu", "manual"
+}
+
+// RunAccountUpdater flags cards expiring within the updater window every
+// interval until ctx is canceled, so merchants can refresh them before
+// recurring charges start failing
+func (s *TokenizationService) RunAccountUpdater(ctx context.Context, interval time.Duration) {
+	logger.Log.Info("Starting account updater",
+		zap.Duration("interval", interval),
+		zap.Int("window_months", s.expiringWindowMonths),
+	)
+	s.markExpiringCards()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("Account updater stopped")
+			return
+		case <-ticker.C:
+			s.markExpiringCards()
+		}
+	}
+}
+
+func (s *TokenizationService) markExpiringCards() {
+	now := time.Now()
+	horizon := s.expiringHorizon(now)
+
+	expiring, expired, err := s.cardVaultRepo.MarkExpiringCards(now, horizon.Year(), int(horizon.Month()))
+	if err != nil {
+		logger.Log.Error("Failed to mark expiring cards", zap.Error(err))
+		return
+	}
+	if expiring > 0 || expired > 0 {
+		logger.Log.Info("Account updater marked cards",
+			zap.Int("expiring_soon", expiring),
+			zap.Int("expired", expired),
+		)
+	}
+}
+
+// ListExpiringTokens returns a merchant's tokens whose cards expire soon
+func (s *TokenizationService) ListExpiringTokens(merchantID uuid.UUID, limit int) ([]model.CardVault, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+	return s.cardVaultRepo.FindExpiringByMerchant(merchantID, limit)
+}
+
+// ListMerchantTokens pages through all of a merchant's tokens, oldest first
+func (s *TokenizationService) ListMerchantTokens(merchantID uuid.UUID, limit, offset int) ([]model.CardVault, bool, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.cardVaultRepo.FindByMerchant(merchantID, limit, offset)
+}
+
+// UpdateCardDetails swaps the PAN and/or expiry behind an existing token.
+// The card is re-encrypted with the merchant's active key and the token
+// string is unchanged, so stored payment methods keep working.
+func (s *TokenizationService) UpdateCardDetails(req *UpdateCardDetailsRequest) (*model.CardVault, error) {
+	cardVault, err := s.cardVaultRepo.FindByToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if cardVault.MerchantID != req.MerchantID {
+		return nil, errors.New("access denied: token does not belong to merchant")
+	}
+
+	if cardVault.Status == model.TokenStatusRevoked || cardVault.Status == model.TokenStatusUsed {
+		return nil, fmt.Errorf("token is %s and cannot be updated", cardVault.Status)
+	}
+
+	if err := s.validationService.ValidateExpiryDate(req.ExpiryMonth, req.ExpiryYear); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Decrypt with the key the card was stored under; it may have been rotated
+	currentKey, err := s.keyManagementSvc.GetKeyByID(cardVault.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decryption key: %w", err)
+	}
+
+	current, err := s.encryptionService.DecryptCardData(crypto.EncryptedCardData{
+		EncryptedCardNumber:     cardVault.EncryptedCardNumber,
+		EncryptedCardholderName: cardVault.EncryptedCardholderName,
+		EncryptedExpiryMonth:    cardVault.EncryptedExpiryMonth,
+		EncryptedExpiryYear:     cardVault.EncryptedExpiryYear,
+	}, currentKey)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+
+	cardNumber := current.CardNumber
+	if req.CardNumber != "" {
+		cardNumber = s.validationService.SanitizeCardNumber(req.CardNumber)
+		if err := s.validationService.ValidateCardNumber(cardNumber); err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+
+	activeKey, keyID, err := s.keyManagementSvc.GetOrCreateMerchantKey(req.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key: %w", err)
+	}
+
+	encryptedData, err := s.encryptionService.EncryptCardData(crypto.CardData{
+		CardNumber:     cardNumber,
+		CardholderName: current.CardholderName,
+		ExpiryMonth:    strconv.Itoa(req.ExpiryMonth),
+		ExpiryYear:     strconv.Itoa(req.ExpiryYear),
+	}, activeKey)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+
+	previousKeyID := cardVault.KeyID
+
+	cardVault.EncryptedCardNumber = encryptedData.EncryptedCardNumber
+	cardVault.EncryptedCardholderName = encryptedData.EncryptedCardholderName
+	cardVault.EncryptedExpiryMonth = encryptedData.EncryptedExpiryMonth
+	cardVault.EncryptedExpiryYear = encryptedData.EncryptedExpiryYear
+	cardVault.KeyID = keyID
+	cardVault.ExpiryMonth = req.ExpiryMonth
+	cardVault.ExpiryYear = req.ExpiryYear
+	cardVault.Last4Digits = s.validationService.GetLast4Digits(cardNumber)
+	cardVault.First6Digits = s.validationService.GetFirst6Digits(cardNumber)
+	cardVault.CardBrand = s.validationService.DetectCardBrand(cardNumber)
+	cardVault.Fingerprint = s.encryptionService.GenerateCardFingerprint(
+		cardNumber,
+		strconv.Itoa(req.ExpiryMonth),
+		strconv.Itoa(req.ExpiryYear),
+	)
+	cardVault.CardType = model.CardTypeUnknown
+	if binInfo, _ := s.binRepo.FindByBIN(cardVault.First6Digits); binInfo != nil {
+		cardVault.CardType = binInfo.CardType
+	}
+
+	horizon := s.expiringHorizon(time.Now())
+	cardVault.Status = model.TokenStatusActive
+	if req.ExpiryYear*12+req.ExpiryMonth <= horizon.Year()*12+int(horizon.Month()) {
+		cardVault.Status = model.TokenStatusExpiringSoon
+	}
+
+	cardVault.CardUpdatedAt.Time = time.Now()
+	cardVault.CardUpdatedAt.Valid = true
+
+	if err := s.cardVaultRepo.Update(cardVault); err != nil {
+		return nil, fmt.Errorf("failed to save card update: %w", err)
+	}
+
+	if keyID != previousKeyID {
+		s.keyRepo.IncrementEncryptedRecords(keyID)
+	}
+
+	logger.Log.Info("Card details updated behind token",
+		zap.String("token", req.Token),
+		zap.String("merchant_id", req.MerchantID.String()),
+		zap.String("source", req.Source),
+		zap.String("updated_by", req.UpdatedBy.String()),
+		zap.Bool("pan_changed", cardNumber != current.CardNumber),
+		zap.Bool("key_changed", keyID != previousKeyID),
+	)
+
+	return cardVault, nil
+}
+
+// expiringHorizon is the last expiry month still counted as expiring soon.
+// It steps from the first of the month so month-end dates don't overflow.
+func (s *TokenizationService) expiringHorizon(now time.Time) time.Time {
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return firstOfMonth.AddDate(0, s.expiringWindowMonths, 0)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/tokenization-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// RunBINRefresher loads the BIN table into memory, then reloads it every
+// interval until ctx is canceled. Until the first load succeeds lookups go
+// to Redis and Postgres as before.
+func (s *TokenizationService) RunBINRefresher(ctx context.Context, interval time.Duration) {
+	logger.Log.Info("Starting BIN table refresher", zap.Duration("interval", interval))
+	s.reloadBINs()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("BIN table refresher stopped")
+			return
+		case <-ticker.C:
+			s.reloadBINs()
+		}
+	}
+}
+
+func (s *TokenizationService) reloadBINs() {
+	start := time.Now()
+	count, err := s.binRepo.LoadAll()
+	if err != nil {
+		logger.Log.Error("Failed to load BIN table", zap.Error(err))
+		return
+	}
+	logger.Log.Debug("BIN table loaded",
+		zap.Int("entries", count),
+		zap.Duration("took", time.Since(start)),
+	)
+}
@@ -9,13 +9,13 @@ import (
 )
 
 // InitGRPC initializes and returns the gRPC server and listener (without starting it)
-func InitGRPC() (*grpc.Server, net.Listener) {
+func InitGRPC(opts ...grpc.ServerOption) (*grpc.Server, net.Listener) {
 	lis, err := net.Listen("tcp", ":"+config.GetEnv("GRPC_PORT"))
 	if err != nil {
 		log.Fatalf("❌ Failed to listen on port %s: %v", config.GetEnv("GRPC_PORT"), err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(opts...)
 
 	return grpcServer, lis
 }
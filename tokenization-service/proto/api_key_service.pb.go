@@ -72,6 +72,7 @@ type GetInfoByAPIKeyResponse struct {
 	MerchantId    string                 `protobuf:"bytes,3,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	AllowedCidrs  []string               `protobuf:"bytes,6,rep,name=allowed_cidrs,json=allowedCidrs,proto3" json:"allowed_cidrs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -141,13 +142,20 @@ func (x *GetInfoByAPIKeyResponse) GetMessage() string {
 	return ""
 }
 
+func (x *GetInfoByAPIKeyResponse) GetAllowedCidrs() []string {
+	if x != nil {
+		return x.AllowedCidrs
+	}
+	return nil
+}
+
 var File_proto_api_key_service_proto protoreflect.FileDescriptor
 
 const file_proto_api_key_service_proto_rawDesc = "" +
 	"\n" +
 	"\x1bproto/api_key_service.proto\x12\x05proto\"1\n" +
 	"\x16GetInfoByAPIKeyRequest\x12\x17\n" +
-	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\"\x97\x01\n" +
+	"\aapi_key\x18\x01 \x01(\tR\x06apiKey\"\xbc\x01\n" +
 	"\x17GetInfoByAPIKeyResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1f\n" +
@@ -155,7 +163,8 @@ const file_proto_api_key_service_proto_rawDesc = "" +
 	"merchantId\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12\x18\n" +
-	"\amessage\x18\x05 \x01(\tR\amessage2a\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12#\n" +
+	"\rallowed_cidrs\x18\x06 \x03(\tR\fallowedCidrs2a\n" +
 	"\rAPIKeyService\x12P\n" +
 	"\x0fGetInfoByAPIKey\x12\x1d.proto.GetInfoByAPIKeyRequest\x1a\x1e.proto.GetInfoByAPIKeyResponseB>Z<github.com/rhaloubi/payment-gateway/auth-service/proto;protob\x06proto3"
 
@@ -34,6 +34,7 @@ type TokenizeCardRequest struct {
 	IpAddress      string                 `protobuf:"bytes,9,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
 	UserAgent      string                 `protobuf:"bytes,10,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
 	CreatedBy      string                 `protobuf:"bytes,11,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"` // UUID
+	TestMode       bool                   `protobuf:"varint,12,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"`   // issue a tok_test_ sandbox token
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -145,6 +146,13 @@ func (x *TokenizeCardRequest) GetCreatedBy() string {
 	return ""
 }
 
+func (x *TokenizeCardRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
 type TokenizeCardResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -406,6 +414,7 @@ type DetokenizeResponse struct {
 	CardBrand      string                 `protobuf:"bytes,5,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
 	Last4          string                 `protobuf:"bytes,6,opt,name=last4,proto3" json:"last4,omitempty"`
 	Error          string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	BinCountry     string                 `protobuf:"bytes,8,opt,name=bin_country,json=binCountry,proto3" json:"bin_country,omitempty"` // ISO 3166-1 alpha-2 of the issuing bank, empty if unknown
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -489,6 +498,13 @@ func (x *DetokenizeResponse) GetError() string {
 	return ""
 }
 
+func (x *DetokenizeResponse) GetBinCountry() string {
+	if x != nil {
+		return x.BinCountry
+	}
+	return ""
+}
+
 type ValidateTokenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -753,95 +769,1033 @@ func (x *RevokeTokenResponse) GetError() string {
 	return ""
 }
 
-var File_proto_tokenization_proto protoreflect.FileDescriptor
+type DeleteTestTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_tokenization_proto_rawDesc = "" +
-	"\n" +
-	"\x18proto/tokenization.proto\x12\ftokenization\"\xea\x02\n" +
-	"\x13TokenizeCardRequest\x12\x1f\n" +
-	"\vmerchant_id\x18\x01 \x01(\tR\n" +
-	"merchantId\x12\x1f\n" +
-	"\vcard_number\x18\x02 \x01(\tR\n" +
-	"cardNumber\x12'\n" +
-	"\x0fcardholder_name\x18\x03 \x01(\tR\x0ecardholderName\x12\x1b\n" +
-	"\texp_month\x18\x04 \x01(\x05R\bexpMonth\x12\x19\n" +
-	"\bexp_year\x18\x05 \x01(\x05R\aexpYear\x12\x10\n" +
-	"\x03cvv\x18\x06 \x01(\tR\x03cvv\x12\"\n" +
-	"\ris_single_use\x18\a \x01(\bR\visSingleUse\x12\x1d\n" +
-	"\n" +
-	"request_id\x18\b \x01(\tR\trequestId\x12\x1d\n" +
-	"\n" +
-	"ip_address\x18\t \x01(\tR\tipAddress\x12\x1d\n" +
-	"\n" +
-	"user_agent\x18\n" +
-	" \x01(\tR\tuserAgent\x12\x1d\n" +
-	"\n" +
-	"created_by\x18\v \x01(\tR\tcreatedBy\"\x94\x01\n" +
-	"\x14TokenizeCardResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12.\n" +
-	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12 \n" +
-	"\fis_new_token\x18\x03 \x01(\bR\n" +
-	"isNewToken\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\"\xa8\x01\n" +
-	"\fCardMetadata\x12\x14\n" +
-	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x12\n" +
-	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
-	"\x05last4\x18\x03 \x01(\tR\x05last4\x12\x1b\n" +
-	"\texp_month\x18\x04 \x01(\x05R\bexpMonth\x12\x19\n" +
-	"\bexp_year\x18\x05 \x01(\x05R\aexpYear\x12 \n" +
-	"\vfingerprint\x18\x06 \x01(\tR\vfingerprint\"\x82\x02\n" +
-	"\x11DetokenizeRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
-	"\vmerchant_id\x18\x02 \x01(\tR\n" +
-	"merchantId\x12%\n" +
-	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12\x1d\n" +
-	"\n" +
-	"usage_type\x18\x04 \x01(\tR\tusageType\x12\x16\n" +
-	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1a\n" +
-	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x1d\n" +
-	"\n" +
-	"ip_address\x18\a \x01(\tR\tipAddress\x12\x1d\n" +
-	"\n" +
-	"user_agent\x18\b \x01(\tR\tuserAgent\"\xe1\x01\n" +
-	"\x12DetokenizeResponse\x12\x1f\n" +
-	"\vcard_number\x18\x01 \x01(\tR\n" +
-	"cardNumber\x12'\n" +
-	"\x0fcardholder_name\x18\x02 \x01(\tR\x0ecardholderName\x12\x1b\n" +
-	"\texp_month\x18\x03 \x01(\x05R\bexpMonth\x12\x19\n" +
-	"\bexp_year\x18\x04 \x01(\x05R\aexpYear\x12\x1d\n" +
-	"\n" +
-	"card_brand\x18\x05 \x01(\tR\tcardBrand\x12\x14\n" +
-	"\x05last4\x18\x06 \x01(\tR\x05last4\x12\x14\n" +
-	"\x05error\x18\a \x01(\tR\x05error\"M\n" +
-	"\x14ValidateTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
-	"\vmerchant_id\x18\x02 \x01(\tR\n" +
-	"merchantId\"\xd0\x01\n" +
-	"\x15ValidateTokenResponse\x12\x14\n" +
-	"\x05valid\x18\x01 \x01(\bR\x05valid\x12.\n" +
-	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1f\n" +
-	"\vusage_count\x18\x04 \x01(\x05R\n" +
-	"usageCount\x12\"\n" +
-	"\ris_single_use\x18\x05 \x01(\bR\visSingleUse\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"\x82\x01\n" +
-	"\x12RevokeTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
-	"\vmerchant_id\x18\x02 \x01(\tR\n" +
-	"merchantId\x12\x1d\n" +
-	"\n" +
-	"revoked_by\x18\x03 \x01(\tR\trevokedBy\x12\x16\n" +
-	"\x06reason\x18\x04 \x01(\tR\x06reason\"_\n" +
-	"\x13RevokeTokenResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error2\xeb\x02\n" +
+func (x *DeleteTestTokensRequest) Reset() {
+	*x = DeleteTestTokensRequest{}
+	mi := &file_proto_tokenization_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTestTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTestTokensRequest) ProtoMessage() {}
+
+func (x *DeleteTestTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTestTokensRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTestTokensRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteTestTokensRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type DeleteTestTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeletedTokens int32                  `protobuf:"varint,1,opt,name=deleted_tokens,json=deletedTokens,proto3" json:"deleted_tokens,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTestTokensResponse) Reset() {
+	*x = DeleteTestTokensResponse{}
+	mi := &file_proto_tokenization_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTestTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTestTokensResponse) ProtoMessage() {}
+
+func (x *DeleteTestTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTestTokensResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTestTokensResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteTestTokensResponse) GetDeletedTokens() int32 {
+	if x != nil {
+		return x.DeletedTokens
+	}
+	return 0
+}
+
+func (x *DeleteTestTokensResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListTokenUsageRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId     string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	TransactionIds []string               `protobuf:"bytes,2,rep,name=transaction_ids,json=transactionIds,proto3" json:"transaction_ids,omitempty"` // UUIDs
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListTokenUsageRequest) Reset() {
+	*x = ListTokenUsageRequest{}
+	mi := &file_proto_tokenization_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTokenUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTokenUsageRequest) ProtoMessage() {}
+
+func (x *ListTokenUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTokenUsageRequest.ProtoReflect.Descriptor instead.
+func (*ListTokenUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListTokenUsageRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListTokenUsageRequest) GetTransactionIds() []string {
+	if x != nil {
+		return x.TransactionIds
+	}
+	return nil
+}
+
+type TokenUsageEntry struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TokenId         string                 `protobuf:"bytes,1,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	TransactionId   string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	TransactionType string                 `protobuf:"bytes,3,opt,name=transaction_type,json=transactionType,proto3" json:"transaction_type,omitempty"`
+	UsageType       string                 `protobuf:"bytes,4,opt,name=usage_type,json=usageType,proto3" json:"usage_type,omitempty"`
+	Amount          int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Success         bool                   `protobuf:"varint,7,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorCode       string                 `protobuf:"bytes,8,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	IpAddress       string                 `protobuf:"bytes,9,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TokenUsageEntry) Reset() {
+	*x = TokenUsageEntry{}
+	mi := &file_proto_tokenization_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenUsageEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenUsageEntry) ProtoMessage() {}
+
+func (x *TokenUsageEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenUsageEntry.ProtoReflect.Descriptor instead.
+func (*TokenUsageEntry) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TokenUsageEntry) GetTokenId() string {
+	if x != nil {
+		return x.TokenId
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetTransactionType() string {
+	if x != nil {
+		return x.TransactionType
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetUsageType() string {
+	if x != nil {
+		return x.UsageType
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TokenUsageEntry) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TokenUsageEntry) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *TokenUsageEntry) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListTokenUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*TokenUsageEntry     `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTokenUsageResponse) Reset() {
+	*x = ListTokenUsageResponse{}
+	mi := &file_proto_tokenization_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTokenUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTokenUsageResponse) ProtoMessage() {}
+
+func (x *ListTokenUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTokenUsageResponse.ProtoReflect.Descriptor instead.
+func (*ListTokenUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTokenUsageResponse) GetEntries() []*TokenUsageEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListTokenUsageResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateCardDetailsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,3,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"` // empty keeps the current PAN
+	ExpMonth      int32                  `protobuf:"varint,4,opt,name=exp_month,json=expMonth,proto3" json:"exp_month,omitempty"`
+	ExpYear       int32                  `protobuf:"varint,5,opt,name=exp_year,json=expYear,proto3" json:"exp_year,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,6,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"` // UUID
+	Source        string                 `protobuf:"bytes,7,opt,name=source,proto3" json:"source,omitempty"`                        // "visa_vau", "mastercard_abu", "manual"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCardDetailsRequest) Reset() {
+	*x = UpdateCardDetailsRequest{}
+	mi := &file_proto_tokenization_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCardDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCardDetailsRequest) ProtoMessage() {}
+
+func (x *UpdateCardDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCardDetailsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCardDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateCardDetailsRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UpdateCardDetailsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *UpdateCardDetailsRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *UpdateCardDetailsRequest) GetExpMonth() int32 {
+	if x != nil {
+		return x.ExpMonth
+	}
+	return 0
+}
+
+func (x *UpdateCardDetailsRequest) GetExpYear() int32 {
+	if x != nil {
+		return x.ExpYear
+	}
+	return 0
+}
+
+func (x *UpdateCardDetailsRequest) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *UpdateCardDetailsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type UpdateCardDetailsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Card          *CardMetadata          `protobuf:"bytes,1,opt,name=card,proto3" json:"card,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // "active" or "expiring_soon"
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCardDetailsResponse) Reset() {
+	*x = UpdateCardDetailsResponse{}
+	mi := &file_proto_tokenization_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCardDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCardDetailsResponse) ProtoMessage() {}
+
+func (x *UpdateCardDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCardDetailsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCardDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateCardDetailsResponse) GetCard() *CardMetadata {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *UpdateCardDetailsResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateCardDetailsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListExpiringTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // defaults to 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExpiringTokensRequest) Reset() {
+	*x = ListExpiringTokensRequest{}
+	mi := &file_proto_tokenization_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExpiringTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpiringTokensRequest) ProtoMessage() {}
+
+func (x *ListExpiringTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpiringTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListExpiringTokensRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListExpiringTokensRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListExpiringTokensRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ExpiringToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Card          *CardMetadata          `protobuf:"bytes,2,opt,name=card,proto3" json:"card,omitempty"`
+	LastUsedAt    string                 `protobuf:"bytes,3,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpiringToken) Reset() {
+	*x = ExpiringToken{}
+	mi := &file_proto_tokenization_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpiringToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpiringToken) ProtoMessage() {}
+
+func (x *ExpiringToken) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpiringToken.ProtoReflect.Descriptor instead.
+func (*ExpiringToken) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExpiringToken) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ExpiringToken) GetCard() *CardMetadata {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *ExpiringToken) GetLastUsedAt() string {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return ""
+}
+
+type ListExpiringTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*ExpiringToken       `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExpiringTokensResponse) Reset() {
+	*x = ListExpiringTokensResponse{}
+	mi := &file_proto_tokenization_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExpiringTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpiringTokensResponse) ProtoMessage() {}
+
+func (x *ListExpiringTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpiringTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListExpiringTokensResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListExpiringTokensResponse) GetTokens() []*ExpiringToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ListExpiringTokensResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListMerchantTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // defaults to 500
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchantTokensRequest) Reset() {
+	*x = ListMerchantTokensRequest{}
+	mi := &file_proto_tokenization_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchantTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchantTokensRequest) ProtoMessage() {}
+
+func (x *ListMerchantTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchantTokensRequest.ProtoReflect.Descriptor instead.
+func (*ListMerchantTokensRequest) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListMerchantTokensRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListMerchantTokensRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListMerchantTokensRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type MerchantToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Card          *CardMetadata          `protobuf:"bytes,2,opt,name=card,proto3" json:"card,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	IsSingleUse   bool                   `protobuf:"varint,4,opt,name=is_single_use,json=isSingleUse,proto3" json:"is_single_use,omitempty"`
+	UsageCount    int32                  `protobuf:"varint,5,opt,name=usage_count,json=usageCount,proto3" json:"usage_count,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastUsedAt    string                 `protobuf:"bytes,7,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	RevokedAt     string                 `protobuf:"bytes,8,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerchantToken) Reset() {
+	*x = MerchantToken{}
+	mi := &file_proto_tokenization_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerchantToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerchantToken) ProtoMessage() {}
+
+func (x *MerchantToken) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerchantToken.ProtoReflect.Descriptor instead.
+func (*MerchantToken) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *MerchantToken) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *MerchantToken) GetCard() *CardMetadata {
+	if x != nil {
+		return x.Card
+	}
+	return nil
+}
+
+func (x *MerchantToken) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *MerchantToken) GetIsSingleUse() bool {
+	if x != nil {
+		return x.IsSingleUse
+	}
+	return false
+}
+
+func (x *MerchantToken) GetUsageCount() int32 {
+	if x != nil {
+		return x.UsageCount
+	}
+	return 0
+}
+
+func (x *MerchantToken) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *MerchantToken) GetLastUsedAt() string {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return ""
+}
+
+func (x *MerchantToken) GetRevokedAt() string {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return ""
+}
+
+type ListMerchantTokensResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tokens        []*MerchantToken       `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	HasMore       bool                   `protobuf:"varint,2,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchantTokensResponse) Reset() {
+	*x = ListMerchantTokensResponse{}
+	mi := &file_proto_tokenization_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchantTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchantTokensResponse) ProtoMessage() {}
+
+func (x *ListMerchantTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_tokenization_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchantTokensResponse.ProtoReflect.Descriptor instead.
+func (*ListMerchantTokensResponse) Descriptor() ([]byte, []int) {
+	return file_proto_tokenization_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListMerchantTokensResponse) GetTokens() []*MerchantToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ListMerchantTokensResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ListMerchantTokensResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_proto_tokenization_proto protoreflect.FileDescriptor
+
+const file_proto_tokenization_proto_rawDesc = "" +
+	"\n" +
+	"\x18proto/tokenization.proto\x12\ftokenization\"\x87\x03\n" +
+	"\x13TokenizeCardRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1f\n" +
+	"\vcard_number\x18\x02 \x01(\tR\n" +
+	"cardNumber\x12'\n" +
+	"\x0fcardholder_name\x18\x03 \x01(\tR\x0ecardholderName\x12\x1b\n" +
+	"\texp_month\x18\x04 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x05 \x01(\x05R\aexpYear\x12\x10\n" +
+	"\x03cvv\x18\x06 \x01(\tR\x03cvv\x12\"\n" +
+	"\ris_single_use\x18\a \x01(\bR\visSingleUse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\b \x01(\tR\trequestId\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\t \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\n" +
+	" \x01(\tR\tuserAgent\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\v \x01(\tR\tcreatedBy\x12\x1b\n" +
+	"\ttest_mode\x18\f \x01(\bR\btestMode\"\x94\x01\n" +
+	"\x14TokenizeCardResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12.\n" +
+	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12 \n" +
+	"\fis_new_token\x18\x03 \x01(\bR\n" +
+	"isNewToken\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xa8\x01\n" +
+	"\fCardMetadata\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05last4\x18\x03 \x01(\tR\x05last4\x12\x1b\n" +
+	"\texp_month\x18\x04 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x05 \x01(\x05R\aexpYear\x12 \n" +
+	"\vfingerprint\x18\x06 \x01(\tR\vfingerprint\"\x82\x02\n" +
+	"\x11DetokenizeRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12%\n" +
+	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12\x1d\n" +
+	"\n" +
+	"usage_type\x18\x04 \x01(\tR\tusageType\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\a \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\b \x01(\tR\tuserAgent\"\x82\x02\n" +
+	"\x12DetokenizeResponse\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber\x12'\n" +
+	"\x0fcardholder_name\x18\x02 \x01(\tR\x0ecardholderName\x12\x1b\n" +
+	"\texp_month\x18\x03 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x04 \x01(\x05R\aexpYear\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x05 \x01(\tR\tcardBrand\x12\x14\n" +
+	"\x05last4\x18\x06 \x01(\tR\x05last4\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1f\n" +
+	"\vbin_country\x18\b \x01(\tR\n" +
+	"binCountry\"M\n" +
+	"\x14ValidateTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\xd0\x01\n" +
+	"\x15ValidateTokenResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12.\n" +
+	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1f\n" +
+	"\vusage_count\x18\x04 \x01(\x05R\n" +
+	"usageCount\x12\"\n" +
+	"\ris_single_use\x18\x05 \x01(\bR\visSingleUse\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\x82\x01\n" +
+	"\x12RevokeTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x1d\n" +
+	"\n" +
+	"revoked_by\x18\x03 \x01(\tR\trevokedBy\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"_\n" +
+	"\x13RevokeTokenResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\":\n" +
+	"\x17DeleteTestTokensRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\"W\n" +
+	"\x18DeleteTestTokensResponse\x12%\n" +
+	"\x0edeleted_tokens\x18\x01 \x01(\x05R\rdeletedTokens\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"a\n" +
+	"\x15ListTokenUsageRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12'\n" +
+	"\x0ftransaction_ids\x18\x02 \x03(\tR\x0etransactionIds\"\xc8\x02\n" +
+	"\x0fTokenUsageEntry\x12\x19\n" +
+	"\btoken_id\x18\x01 \x01(\tR\atokenId\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12)\n" +
+	"\x10transaction_type\x18\x03 \x01(\tR\x0ftransactionType\x12\x1d\n" +
+	"\n" +
+	"usage_type\x18\x04 \x01(\tR\tusageType\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x18\n" +
+	"\asuccess\x18\a \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\b \x01(\tR\terrorCode\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\t \x01(\tR\tipAddress\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\"g\n" +
+	"\x16ListTokenUsageResponse\x127\n" +
+	"\aentries\x18\x01 \x03(\v2\x1d.tokenization.TokenUsageEntryR\aentries\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xe1\x01\n" +
+	"\x18UpdateCardDetailsRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x1f\n" +
+	"\vcard_number\x18\x03 \x01(\tR\n" +
+	"cardNumber\x12\x1b\n" +
+	"\texp_month\x18\x04 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x05 \x01(\x05R\aexpYear\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x06 \x01(\tR\tupdatedBy\x12\x16\n" +
+	"\x06source\x18\a \x01(\tR\x06source\"y\n" +
+	"\x19UpdateCardDetailsResponse\x12.\n" +
+	"\x04card\x18\x01 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"R\n" +
+	"\x19ListExpiringTokensRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"w\n" +
+	"\rExpiringToken\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12.\n" +
+	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12 \n" +
+	"\flast_used_at\x18\x03 \x01(\tR\n" +
+	"lastUsedAt\"g\n" +
+	"\x1aListExpiringTokensResponse\x123\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x1b.tokenization.ExpiringTokenR\x06tokens\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"j\n" +
+	"\x19ListMerchantTokensRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\x92\x02\n" +
+	"\rMerchantToken\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12.\n" +
+	"\x04card\x18\x02 \x01(\v2\x1a.tokenization.CardMetadataR\x04card\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\"\n" +
+	"\ris_single_use\x18\x04 \x01(\bR\visSingleUse\x12\x1f\n" +
+	"\vusage_count\x18\x05 \x01(\x05R\n" +
+	"usageCount\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12 \n" +
+	"\flast_used_at\x18\a \x01(\tR\n" +
+	"lastUsedAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\b \x01(\tR\trevokedAt\"\x82\x01\n" +
+	"\x1aListMerchantTokensResponse\x123\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x1b.tokenization.MerchantTokenR\x06tokens\x12\x19\n" +
+	"\bhas_more\x18\x02 \x01(\bR\ahasMore\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error2\xe3\x06\n" +
 	"\x13TokenizationService\x12U\n" +
 	"\fTokenizeCard\x12!.tokenization.TokenizeCardRequest\x1a\".tokenization.TokenizeCardResponse\x12O\n" +
 	"\n" +
 	"Detokenize\x12\x1f.tokenization.DetokenizeRequest\x1a .tokenization.DetokenizeResponse\x12X\n" +
 	"\rValidateToken\x12\".tokenization.ValidateTokenRequest\x1a#.tokenization.ValidateTokenResponse\x12R\n" +
-	"\vRevokeToken\x12 .tokenization.RevokeTokenRequest\x1a!.tokenization.RevokeTokenResponseB@Z>github.com/rhaloubi/payment-gateway/tokenization-service/protob\x06proto3"
+	"\vRevokeToken\x12 .tokenization.RevokeTokenRequest\x1a!.tokenization.RevokeTokenResponse\x12a\n" +
+	"\x10DeleteTestTokens\x12%.tokenization.DeleteTestTokensRequest\x1a&.tokenization.DeleteTestTokensResponse\x12[\n" +
+	"\x0eListTokenUsage\x12#.tokenization.ListTokenUsageRequest\x1a$.tokenization.ListTokenUsageResponse\x12d\n" +
+	"\x11UpdateCardDetails\x12&.tokenization.UpdateCardDetailsRequest\x1a'.tokenization.UpdateCardDetailsResponse\x12g\n" +
+	"\x12ListExpiringTokens\x12'.tokenization.ListExpiringTokensRequest\x1a(.tokenization.ListExpiringTokensResponse\x12g\n" +
+	"\x12ListMerchantTokens\x12'.tokenization.ListMerchantTokensRequest\x1a(.tokenization.ListMerchantTokensResponseB@Z>github.com/rhaloubi/payment-gateway/tokenization-service/protob\x06proto3"
 
 var (
 	file_proto_tokenization_proto_rawDescOnce sync.Once
@@ -855,34 +1809,63 @@ func file_proto_tokenization_proto_rawDescGZIP() []byte {
 	return file_proto_tokenization_proto_rawDescData
 }
 
-var file_proto_tokenization_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_tokenization_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_proto_tokenization_proto_goTypes = []any{
-	(*TokenizeCardRequest)(nil),   // 0: tokenization.TokenizeCardRequest
-	(*TokenizeCardResponse)(nil),  // 1: tokenization.TokenizeCardResponse
-	(*CardMetadata)(nil),          // 2: tokenization.CardMetadata
-	(*DetokenizeRequest)(nil),     // 3: tokenization.DetokenizeRequest
-	(*DetokenizeResponse)(nil),    // 4: tokenization.DetokenizeResponse
-	(*ValidateTokenRequest)(nil),  // 5: tokenization.ValidateTokenRequest
-	(*ValidateTokenResponse)(nil), // 6: tokenization.ValidateTokenResponse
-	(*RevokeTokenRequest)(nil),    // 7: tokenization.RevokeTokenRequest
-	(*RevokeTokenResponse)(nil),   // 8: tokenization.RevokeTokenResponse
+	(*TokenizeCardRequest)(nil),        // 0: tokenization.TokenizeCardRequest
+	(*TokenizeCardResponse)(nil),       // 1: tokenization.TokenizeCardResponse
+	(*CardMetadata)(nil),               // 2: tokenization.CardMetadata
+	(*DetokenizeRequest)(nil),          // 3: tokenization.DetokenizeRequest
+	(*DetokenizeResponse)(nil),         // 4: tokenization.DetokenizeResponse
+	(*ValidateTokenRequest)(nil),       // 5: tokenization.ValidateTokenRequest
+	(*ValidateTokenResponse)(nil),      // 6: tokenization.ValidateTokenResponse
+	(*RevokeTokenRequest)(nil),         // 7: tokenization.RevokeTokenRequest
+	(*RevokeTokenResponse)(nil),        // 8: tokenization.RevokeTokenResponse
+	(*DeleteTestTokensRequest)(nil),    // 9: tokenization.DeleteTestTokensRequest
+	(*DeleteTestTokensResponse)(nil),   // 10: tokenization.DeleteTestTokensResponse
+	(*ListTokenUsageRequest)(nil),      // 11: tokenization.ListTokenUsageRequest
+	(*TokenUsageEntry)(nil),            // 12: tokenization.TokenUsageEntry
+	(*ListTokenUsageResponse)(nil),     // 13: tokenization.ListTokenUsageResponse
+	(*UpdateCardDetailsRequest)(nil),   // 14: tokenization.UpdateCardDetailsRequest
+	(*UpdateCardDetailsResponse)(nil),  // 15: tokenization.UpdateCardDetailsResponse
+	(*ListExpiringTokensRequest)(nil),  // 16: tokenization.ListExpiringTokensRequest
+	(*ExpiringToken)(nil),              // 17: tokenization.ExpiringToken
+	(*ListExpiringTokensResponse)(nil), // 18: tokenization.ListExpiringTokensResponse
+	(*ListMerchantTokensRequest)(nil),  // 19: tokenization.ListMerchantTokensRequest
+	(*MerchantToken)(nil),              // 20: tokenization.MerchantToken
+	(*ListMerchantTokensResponse)(nil), // 21: tokenization.ListMerchantTokensResponse
 }
 var file_proto_tokenization_proto_depIdxs = []int32{
-	2, // 0: tokenization.TokenizeCardResponse.card:type_name -> tokenization.CardMetadata
-	2, // 1: tokenization.ValidateTokenResponse.card:type_name -> tokenization.CardMetadata
-	0, // 2: tokenization.TokenizationService.TokenizeCard:input_type -> tokenization.TokenizeCardRequest
-	3, // 3: tokenization.TokenizationService.Detokenize:input_type -> tokenization.DetokenizeRequest
-	5, // 4: tokenization.TokenizationService.ValidateToken:input_type -> tokenization.ValidateTokenRequest
-	7, // 5: tokenization.TokenizationService.RevokeToken:input_type -> tokenization.RevokeTokenRequest
-	1, // 6: tokenization.TokenizationService.TokenizeCard:output_type -> tokenization.TokenizeCardResponse
-	4, // 7: tokenization.TokenizationService.Detokenize:output_type -> tokenization.DetokenizeResponse
-	6, // 8: tokenization.TokenizationService.ValidateToken:output_type -> tokenization.ValidateTokenResponse
-	8, // 9: tokenization.TokenizationService.RevokeToken:output_type -> tokenization.RevokeTokenResponse
-	6, // [6:10] is the sub-list for method output_type
-	2, // [2:6] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2,  // 0: tokenization.TokenizeCardResponse.card:type_name -> tokenization.CardMetadata
+	2,  // 1: tokenization.ValidateTokenResponse.card:type_name -> tokenization.CardMetadata
+	12, // 2: tokenization.ListTokenUsageResponse.entries:type_name -> tokenization.TokenUsageEntry
+	2,  // 3: tokenization.UpdateCardDetailsResponse.card:type_name -> tokenization.CardMetadata
+	2,  // 4: tokenization.ExpiringToken.card:type_name -> tokenization.CardMetadata
+	17, // 5: tokenization.ListExpiringTokensResponse.tokens:type_name -> tokenization.ExpiringToken
+	2,  // 6: tokenization.MerchantToken.card:type_name -> tokenization.CardMetadata
+	20, // 7: tokenization.ListMerchantTokensResponse.tokens:type_name -> tokenization.MerchantToken
+	0,  // 8: tokenization.TokenizationService.TokenizeCard:input_type -> tokenization.TokenizeCardRequest
+	3,  // 9: tokenization.TokenizationService.Detokenize:input_type -> tokenization.DetokenizeRequest
+	5,  // 10: tokenization.TokenizationService.ValidateToken:input_type -> tokenization.ValidateTokenRequest
+	7,  // 11: tokenization.TokenizationService.RevokeToken:input_type -> tokenization.RevokeTokenRequest
+	9,  // 12: tokenization.TokenizationService.DeleteTestTokens:input_type -> tokenization.DeleteTestTokensRequest
+	11, // 13: tokenization.TokenizationService.ListTokenUsage:input_type -> tokenization.ListTokenUsageRequest
+	14, // 14: tokenization.TokenizationService.UpdateCardDetails:input_type -> tokenization.UpdateCardDetailsRequest
+	16, // 15: tokenization.TokenizationService.ListExpiringTokens:input_type -> tokenization.ListExpiringTokensRequest
+	19, // 16: tokenization.TokenizationService.ListMerchantTokens:input_type -> tokenization.ListMerchantTokensRequest
+	1,  // 17: tokenization.TokenizationService.TokenizeCard:output_type -> tokenization.TokenizeCardResponse
+	4,  // 18: tokenization.TokenizationService.Detokenize:output_type -> tokenization.DetokenizeResponse
+	6,  // 19: tokenization.TokenizationService.ValidateToken:output_type -> tokenization.ValidateTokenResponse
+	8,  // 20: tokenization.TokenizationService.RevokeToken:output_type -> tokenization.RevokeTokenResponse
+	10, // 21: tokenization.TokenizationService.DeleteTestTokens:output_type -> tokenization.DeleteTestTokensResponse
+	13, // 22: tokenization.TokenizationService.ListTokenUsage:output_type -> tokenization.ListTokenUsageResponse
+	15, // 23: tokenization.TokenizationService.UpdateCardDetails:output_type -> tokenization.UpdateCardDetailsResponse
+	18, // 24: tokenization.TokenizationService.ListExpiringTokens:output_type -> tokenization.ListExpiringTokensResponse
+	21, // 25: tokenization.TokenizationService.ListMerchantTokens:output_type -> tokenization.ListMerchantTokensResponse
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_proto_tokenization_proto_init() }
@@ -896,7 +1879,7 @@ func file_proto_tokenization_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_tokenization_proto_rawDesc), len(file_proto_tokenization_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
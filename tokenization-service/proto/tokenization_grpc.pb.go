@@ -19,10 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TokenizationService_TokenizeCard_FullMethodName  = "/tokenization.TokenizationService/TokenizeCard"
-	TokenizationService_Detokenize_FullMethodName    = "/tokenization.TokenizationService/Detokenize"
-	TokenizationService_ValidateToken_FullMethodName = "/tokenization.TokenizationService/ValidateToken"
-	TokenizationService_RevokeToken_FullMethodName   = "/tokenization.TokenizationService/RevokeToken"
+	TokenizationService_TokenizeCard_FullMethodName       = "/tokenization.TokenizationService/TokenizeCard"
+	TokenizationService_Detokenize_FullMethodName         = "/tokenization.TokenizationService/Detokenize"
+	TokenizationService_ValidateToken_FullMethodName      = "/tokenization.TokenizationService/ValidateToken"
+	TokenizationService_RevokeToken_FullMethodName        = "/tokenization.TokenizationService/RevokeToken"
+	TokenizationService_DeleteTestTokens_FullMethodName   = "/tokenization.TokenizationService/DeleteTestTokens"
+	TokenizationService_ListTokenUsage_FullMethodName     = "/tokenization.TokenizationService/ListTokenUsage"
+	TokenizationService_UpdateCardDetails_FullMethodName  = "/tokenization.TokenizationService/UpdateCardDetails"
+	TokenizationService_ListExpiringTokens_FullMethodName = "/tokenization.TokenizationService/ListExpiringTokens"
+	TokenizationService_ListMerchantTokens_FullMethodName = "/tokenization.TokenizationService/ListMerchantTokens"
 )
 
 // TokenizationServiceClient is the client API for TokenizationService service.
@@ -39,6 +44,16 @@ type TokenizationServiceClient interface {
 	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
 	// RevokeToken invalidates a token
 	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error)
+	// DeleteTestTokens permanently removes a merchant's sandbox tokens
+	DeleteTestTokens(ctx context.Context, in *DeleteTestTokensRequest, opts ...grpc.CallOption) (*DeleteTestTokensResponse, error)
+	// ListTokenUsage returns the token usage log entries of a merchant's transactions
+	ListTokenUsage(ctx context.Context, in *ListTokenUsageRequest, opts ...grpc.CallOption) (*ListTokenUsageResponse, error)
+	// UpdateCardDetails replaces the PAN and/or expiry behind a token (internal only)
+	UpdateCardDetails(ctx context.Context, in *UpdateCardDetailsRequest, opts ...grpc.CallOption) (*UpdateCardDetailsResponse, error)
+	// ListExpiringTokens returns a merchant's tokens whose cards expire soon (internal only)
+	ListExpiringTokens(ctx context.Context, in *ListExpiringTokensRequest, opts ...grpc.CallOption) (*ListExpiringTokensResponse, error)
+	// ListMerchantTokens pages through a merchant's token metadata for account exports (internal only)
+	ListMerchantTokens(ctx context.Context, in *ListMerchantTokensRequest, opts ...grpc.CallOption) (*ListMerchantTokensResponse, error)
 }
 
 type tokenizationServiceClient struct {
@@ -89,6 +104,56 @@ func (c *tokenizationServiceClient) RevokeToken(ctx context.Context, in *RevokeT
 	return out, nil
 }
 
+func (c *tokenizationServiceClient) DeleteTestTokens(ctx context.Context, in *DeleteTestTokensRequest, opts ...grpc.CallOption) (*DeleteTestTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTestTokensResponse)
+	err := c.cc.Invoke(ctx, TokenizationService_DeleteTestTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tokenizationServiceClient) ListTokenUsage(ctx context.Context, in *ListTokenUsageRequest, opts ...grpc.CallOption) (*ListTokenUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTokenUsageResponse)
+	err := c.cc.Invoke(ctx, TokenizationService_ListTokenUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tokenizationServiceClient) UpdateCardDetails(ctx context.Context, in *UpdateCardDetailsRequest, opts ...grpc.CallOption) (*UpdateCardDetailsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateCardDetailsResponse)
+	err := c.cc.Invoke(ctx, TokenizationService_UpdateCardDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tokenizationServiceClient) ListExpiringTokens(ctx context.Context, in *ListExpiringTokensRequest, opts ...grpc.CallOption) (*ListExpiringTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExpiringTokensResponse)
+	err := c.cc.Invoke(ctx, TokenizationService_ListExpiringTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tokenizationServiceClient) ListMerchantTokens(ctx context.Context, in *ListMerchantTokensRequest, opts ...grpc.CallOption) (*ListMerchantTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMerchantTokensResponse)
+	err := c.cc.Invoke(ctx, TokenizationService_ListMerchantTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TokenizationServiceServer is the server API for TokenizationService service.
 // All implementations must embed UnimplementedTokenizationServiceServer
 // for forward compatibility.
@@ -103,6 +168,16 @@ type TokenizationServiceServer interface {
 	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
 	// RevokeToken invalidates a token
 	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	// DeleteTestTokens permanently removes a merchant's sandbox tokens
+	DeleteTestTokens(context.Context, *DeleteTestTokensRequest) (*DeleteTestTokensResponse, error)
+	// ListTokenUsage returns the token usage log entries of a merchant's transactions
+	ListTokenUsage(context.Context, *ListTokenUsageRequest) (*ListTokenUsageResponse, error)
+	// UpdateCardDetails replaces the PAN and/or expiry behind a token (internal only)
+	UpdateCardDetails(context.Context, *UpdateCardDetailsRequest) (*UpdateCardDetailsResponse, error)
+	// ListExpiringTokens returns a merchant's tokens whose cards expire soon (internal only)
+	ListExpiringTokens(context.Context, *ListExpiringTokensRequest) (*ListExpiringTokensResponse, error)
+	// ListMerchantTokens pages through a merchant's token metadata for account exports (internal only)
+	ListMerchantTokens(context.Context, *ListMerchantTokensRequest) (*ListMerchantTokensResponse, error)
 	mustEmbedUnimplementedTokenizationServiceServer()
 }
 
@@ -125,6 +200,21 @@ func (UnimplementedTokenizationServiceServer) ValidateToken(context.Context, *Va
 func (UnimplementedTokenizationServiceServer) RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RevokeToken not implemented")
 }
+func (UnimplementedTokenizationServiceServer) DeleteTestTokens(context.Context, *DeleteTestTokensRequest) (*DeleteTestTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTestTokens not implemented")
+}
+func (UnimplementedTokenizationServiceServer) ListTokenUsage(context.Context, *ListTokenUsageRequest) (*ListTokenUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTokenUsage not implemented")
+}
+func (UnimplementedTokenizationServiceServer) UpdateCardDetails(context.Context, *UpdateCardDetailsRequest) (*UpdateCardDetailsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCardDetails not implemented")
+}
+func (UnimplementedTokenizationServiceServer) ListExpiringTokens(context.Context, *ListExpiringTokensRequest) (*ListExpiringTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExpiringTokens not implemented")
+}
+func (UnimplementedTokenizationServiceServer) ListMerchantTokens(context.Context, *ListMerchantTokensRequest) (*ListMerchantTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMerchantTokens not implemented")
+}
 func (UnimplementedTokenizationServiceServer) mustEmbedUnimplementedTokenizationServiceServer() {}
 func (UnimplementedTokenizationServiceServer) testEmbeddedByValue()                             {}
 
@@ -218,6 +308,96 @@ func _TokenizationService_RevokeToken_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TokenizationService_DeleteTestTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTestTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenizationServiceServer).DeleteTestTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenizationService_DeleteTestTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenizationServiceServer).DeleteTestTokens(ctx, req.(*DeleteTestTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TokenizationService_ListTokenUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTokenUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenizationServiceServer).ListTokenUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenizationService_ListTokenUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenizationServiceServer).ListTokenUsage(ctx, req.(*ListTokenUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TokenizationService_UpdateCardDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCardDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenizationServiceServer).UpdateCardDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenizationService_UpdateCardDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenizationServiceServer).UpdateCardDetails(ctx, req.(*UpdateCardDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TokenizationService_ListExpiringTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExpiringTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenizationServiceServer).ListExpiringTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenizationService_ListExpiringTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenizationServiceServer).ListExpiringTokens(ctx, req.(*ListExpiringTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TokenizationService_ListMerchantTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMerchantTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TokenizationServiceServer).ListMerchantTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TokenizationService_ListMerchantTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TokenizationServiceServer).ListMerchantTokens(ctx, req.(*ListMerchantTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TokenizationService_ServiceDesc is the grpc.ServiceDesc for TokenizationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -241,6 +421,26 @@ var TokenizationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RevokeToken",
 			Handler:    _TokenizationService_RevokeToken_Handler,
 		},
+		{
+			MethodName: "DeleteTestTokens",
+			Handler:    _TokenizationService_DeleteTestTokens_Handler,
+		},
+		{
+			MethodName: "ListTokenUsage",
+			Handler:    _TokenizationService_ListTokenUsage_Handler,
+		},
+		{
+			MethodName: "UpdateCardDetails",
+			Handler:    _TokenizationService_UpdateCardDetails_Handler,
+		},
+		{
+			MethodName: "ListExpiringTokens",
+			Handler:    _TokenizationService_ListExpiringTokens_Handler,
+		},
+		{
+			MethodName: "ListMerchantTokens",
+			Handler:    _TokenizationService_ListMerchantTokens_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/tokenization.proto",
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/handler"
+	"go.uber.org/zap"
+)
+
+// =========================================================================
+// Admin Server
+// =========================================================================
+
+// startAdminServer serves the simulator fault injection, acquirer connector
+// routing, settlement, clearing reconciliation, capture settings and ledger
+// endpoints. It is only started when ADMIN_API_TOKEN is set, and only
+// answers peers inside ADMIN_ALLOWED_CIDRS.
+func startAdminServer(port, token string) {
+	addr := port
+	if !strings.Contains(port, ":") {
+		addr = ":" + port
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	allowed, err := handler.ParseCIDRs(config.GetEnvWithDefault("ADMIN_ALLOWED_CIDRS", handler.DefaultAdminCIDRs))
+	if err != nil {
+		logger.Log.Fatal("Invalid ADMIN_ALLOWED_CIDRS", zap.Error(err))
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(handler.RequireAllowedNetwork("admin", allowed))
+
+	router.GET("/metrics", handler.Metrics())
+
+	simulatorHandler := handler.NewSimulatorAdminHandler()
+	connectorHandler := handler.NewConnectorAdminHandler()
+	settlementHandler := handler.NewSettlementAdminHandler()
+	reconciliationHandler := handler.NewReconciliationAdminHandler()
+	captureHandler := handler.NewCaptureAdminHandler()
+	ledgerHandler := handler.NewLedgerAdminHandler()
+
+	faults := router.Group("/admin/simulator/faults")
+	faults.Use(handler.RequireAdminToken(token))
+	{
+		faults.GET("", simulatorHandler.ListFaults)
+		faults.DELETE("", simulatorHandler.ClearFaults)
+		faults.PUT("/global", simulatorHandler.SetGlobalFault)
+		faults.DELETE("/global", simulatorHandler.DeleteGlobalFault)
+		faults.PUT("/merchants/:merchant_id", simulatorHandler.SetMerchantFault)
+		faults.DELETE("/merchants/:merchant_id", simulatorHandler.DeleteMerchantFault)
+	}
+
+	accounts := router.Group("/admin/simulator/accounts")
+	accounts.Use(handler.RequireAdminToken(token))
+	{
+		accounts.PUT("", simulatorHandler.SetIssuerAccount)
+		accounts.GET("/:account_id", simulatorHandler.GetIssuerAccount)
+		accounts.DELETE("/:account_id", simulatorHandler.DeleteIssuerAccount)
+	}
+
+	router.POST("/admin/simulator/refunds/:refund_id/posted",
+		handler.RequireAdminToken(token), simulatorHandler.ConfirmRefundPosted)
+	router.GET("/admin/simulator/clearing-files/:date",
+		handler.RequireAdminToken(token), reconciliationHandler.DownloadClearingFile)
+
+	connectors := router.Group("/admin/connectors")
+	connectors.Use(handler.RequireAdminToken(token))
+	{
+		connectors.GET("", connectorHandler.ListConnectors)
+		connectors.PUT("/routes/:merchant_id", connectorHandler.SetMerchantRoute)
+		connectors.DELETE("/routes/:merchant_id", connectorHandler.DeleteMerchantRoute)
+	}
+
+	routing := router.Group("/admin/routing")
+	routing.Use(handler.RequireAdminToken(token))
+	{
+		routing.GET("/rules", connectorHandler.ListRoutingRules)
+		routing.POST("/rules", connectorHandler.CreateRoutingRule)
+		routing.PUT("/rules/:id", connectorHandler.UpdateRoutingRule)
+		routing.DELETE("/rules/:id", connectorHandler.DeleteRoutingRule)
+		routing.GET("/costs", connectorHandler.ListConnectorCosts)
+		routing.PUT("/costs/:connector", connectorHandler.SetConnectorCost)
+		routing.GET("/decisions", connectorHandler.ListRoutingDecisions)
+		routing.GET("/decisions/:transaction_id", connectorHandler.GetRoutingDecision)
+	}
+
+	settlements := router.Group("/admin/settlements")
+	settlements.Use(handler.RequireAdminToken(token))
+	{
+		settlements.GET("/guardrails", settlementHandler.GetGuardrails)
+		settlements.GET("/held", settlementHandler.ListHeldSettlements)
+		settlements.POST("/:batch_id/release", settlementHandler.ReleaseSettlement)
+	}
+
+	reconciliation := router.Group("/admin/reconciliation")
+	reconciliation.Use(handler.RequireAdminToken(token))
+	{
+		reconciliation.POST("/run", reconciliationHandler.RunReconciliation)
+		reconciliation.GET("/clearing-files", reconciliationHandler.ListClearingFiles)
+		reconciliation.POST("/clearing-files", reconciliationHandler.UploadClearingFile)
+		reconciliation.GET("/clearing-files/:id", reconciliationHandler.GetClearingFile)
+		reconciliation.GET("/report", reconciliationHandler.GetReconciliationReport)
+	}
+
+	ledger := router.Group("/admin/ledger")
+	ledger.Use(handler.RequireAdminToken(token))
+	{
+		ledger.POST("/check", ledgerHandler.CheckLedger)
+	}
+
+	merchants := router.Group("/admin/merchants/:merchant_id")
+	merchants.Use(handler.RequireAdminToken(token))
+	{
+		merchants.POST("/final-settlement", settlementHandler.CreateFinalSettlement)
+		merchants.GET("/settlement-timezone", settlementHandler.GetSettlementTimezone)
+		merchants.PUT("/settlement-timezone", settlementHandler.SetSettlementTimezone)
+		merchants.DELETE("/settlement-timezone", settlementHandler.DeleteSettlementTimezone)
+		merchants.GET("/settlement-schedule", settlementHandler.GetSettlementSchedule)
+		merchants.PUT("/settlement-schedule", settlementHandler.UpdateSettlementSchedule)
+		merchants.DELETE("/settlement-schedule", settlementHandler.DeleteSettlementSchedule)
+		merchants.GET("/capture-settings", captureHandler.GetCaptureSettings)
+		merchants.PUT("/capture-settings", captureHandler.SetCaptureSettings)
+		merchants.DELETE("/capture-settings", captureHandler.DeleteCaptureSettings)
+		merchants.GET("/ledger", ledgerHandler.GetMerchantLedger)
+	}
+
+	logger.Log.Info("Admin server starting", zap.String("port", port))
+
+	if err := router.Run(addr); err != nil {
+		logger.Log.Fatal("Failed to serve admin API", zap.Error(err))
+	}
+}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strings"
 	"time"
 
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
 	grpcServer "github.com/rhaloubi/payment-gateway/transaction-service/internal/grpc"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/metrics"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
 	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
 	"go.uber.org/zap"
@@ -18,7 +20,7 @@ import (
 // gRPC Server
 // =========================================================================
 
-func startGRPCServer(port string) {
+func startGRPCServer(ctx context.Context, port string) {
 	// Handle address with or without host
 	addr := port
 	if !strings.Contains(port, ":") {
@@ -32,7 +34,7 @@ func startGRPCServer(port string) {
 	}
 
 	// Create gRPC server
-	grpcSrv := grpc.NewServer()
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(metrics.UnaryServerInterceptor()))
 
 	// Register transaction service
 	transactionServer, err := grpcServer.NewTransactionServer()
@@ -41,6 +43,17 @@ func startGRPCServer(port string) {
 	}
 	pb.RegisterTransactionServiceServer(grpcSrv, transactionServer)
 
+	// Register chargeback service
+	pb.RegisterChargebackServiceServer(grpcSrv, grpcServer.NewChargebackServer())
+
+	// grpc.health.v1 reports NOT_SERVING while Postgres or Redis is unreachable
+	readiness := grpcServer.RegisterHealth(grpcSrv)
+	go readiness.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		readiness.Shutdown()
+	}()
+
 	logger.Log.Info("gRPC server starting", zap.String("port", port))
 
 	// Start serving
@@ -53,39 +66,30 @@ func startGRPCServer(port string) {
 // Background Workers
 // =========================================================================
 
-// Settlement Worker - Runs daily at midnight
-func startSettlementWorker(ctx context.Context, settlementService *service.SettlementService) {
+// Settlement Worker - Runs at the top of every UTC hour, so each merchant's
+// day is batched shortly after midnight in its own timezone
+func startSettlementWorker(ctx context.Context, settlementService *service.SettlementService, balanceService *service.BalanceService) {
 	logger.Log.Info("Settlement worker started")
 
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
+	for {
+		// Recompute the wait every run so clock adjustments and slow runs
+		// do not make the schedule drift
+		now := time.Now().UTC()
+		nextRun := now.Truncate(time.Hour).Add(time.Hour)
 
-	// Calculate time until next midnight
-	now := time.Now()
-	nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-	initialDelay := time.Until(nextMidnight)
-
-	logger.Log.Info("Next settlement batch creation scheduled",
-		zap.Duration("in", initialDelay),
-		zap.Time("at", nextMidnight),
-	)
-
-	// Wait until midnight
-	select {
-	case <-time.After(initialDelay):
-		// Run settlement
-		if err := settlementService.CreateDailySettlementBatches(ctx); err != nil {
-			logger.Log.Error("Settlement batch creation failed", zap.Error(err))
-		}
-	case <-ctx.Done():
-		return
-	}
+		logger.Log.Info("Next settlement run scheduled",
+			zap.Duration("in", nextRun.Sub(now)),
+			zap.Time("at", nextRun),
+		)
 
-	// Then run daily
-	for {
 		select {
-		case <-ticker.C:
-			logger.Log.Info("Running daily settlement batch creation")
+		case <-time.After(nextRun.Sub(now)):
+			// Reserve released first is paid out with this run's batches
+			if err := balanceService.ReleaseDueReserves(ctx); err != nil {
+				logger.Log.Error("Reserve release failed", zap.Error(err))
+			}
+
+			logger.Log.Info("Running settlement batch creation")
 			if err := settlementService.CreateDailySettlementBatches(ctx); err != nil {
 				logger.Log.Error("Settlement batch creation failed", zap.Error(err))
 			}
@@ -129,6 +133,138 @@ func startAutoVoidWorker(ctx context.Context, settlementService *service.Settlem
 	}
 }
 
+// Reconciliation Worker - Runs daily at 01:00 UTC, once the network has
+// closed the previous day's clearing file
+func startReconciliationWorker(ctx context.Context, reconciliationService *service.ReconciliationService) {
+	logger.Log.Info("Reconciliation worker started")
+
+	for {
+		now := time.Now().UTC()
+		nextRun := now.Truncate(24 * time.Hour).Add(time.Hour)
+		if !nextRun.After(now) {
+			nextRun = nextRun.Add(24 * time.Hour)
+		}
+
+		select {
+		case <-time.After(nextRun.Sub(now)):
+			day := nextRun.AddDate(0, 0, -1)
+			logger.Log.Info("Reconciling clearing file", zap.String("date", day.Format("2006-01-02")))
+			if _, err := reconciliationService.ReconcileDay(ctx, day); err != nil && !errors.Is(err, service.ErrClearingFileExists) {
+				logger.Log.Error("Clearing reconciliation failed", zap.Error(err))
+			}
+
+		case <-ctx.Done():
+			logger.Log.Info("Reconciliation worker stopped")
+			return
+		}
+	}
+}
+
+// Ledger Check Worker - Runs daily at 02:00 UTC, after the reconciliation
+// worker, and reconciles the ledger against the transaction records
+func startLedgerCheckWorker(ctx context.Context, ledgerService *service.LedgerService) {
+	logger.Log.Info("Ledger check worker started")
+
+	for {
+		now := time.Now().UTC()
+		nextRun := now.Truncate(24 * time.Hour).Add(2 * time.Hour)
+		if !nextRun.After(now) {
+			nextRun = nextRun.Add(24 * time.Hour)
+		}
+
+		select {
+		case <-time.After(nextRun.Sub(now)):
+			report, err := ledgerService.CheckInvariants(ctx)
+			if err != nil {
+				logger.Log.Error("Ledger invariant check failed", zap.Error(err))
+			} else if len(report.Violations) > 0 {
+				logger.Log.Error("Ledger invariants violated", zap.Int("violations", len(report.Violations)))
+			}
+
+		case <-ctx.Done():
+			logger.Log.Info("Ledger check worker stopped")
+			return
+		}
+	}
+}
+
+// Dispute Deadline Worker - Runs every hour and closes disputes whose
+// response deadline passed without evidence
+func startDisputeDeadlineWorker(ctx context.Context, chargebackService *service.ChargebackService) {
+	logger.Log.Info("Dispute deadline worker started")
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := chargebackService.ExpireOverdueDisputes(ctx); err != nil {
+				logger.Log.Error("Dispute deadline check failed", zap.Error(err))
+			}
+
+		case <-ctx.Done():
+			logger.Log.Info("Dispute deadline worker stopped")
+			return
+		}
+	}
+}
+
+// Outbox Relay Worker - Publishes queued lifecycle events every second and
+// purges published ones every hour
+func startOutboxRelayWorker(ctx context.Context, relay *service.OutboxRelayService) {
+	logger.Log.Info("Outbox relay worker started")
+	defer relay.Close()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(1 * time.Hour)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := relay.PublishPending(); err != nil {
+				logger.Log.Error("Outbox relay failed", zap.Error(err))
+			}
+
+		case <-purgeTicker.C:
+			deleted, err := relay.PurgePublished()
+			if err != nil {
+				logger.Log.Error("Outbox purge failed", zap.Error(err))
+			} else if deleted > 0 {
+				logger.Log.Info("Purged published outbox events", zap.Int64("count", deleted))
+			}
+
+		case <-ctx.Done():
+			logger.Log.Info("Outbox relay worker stopped")
+			return
+		}
+	}
+}
+
+// Refund Queue Worker - Sends queued refunds every second as each
+// merchant's refund slots free up
+func startRefundQueueWorker(ctx context.Context, transactionService *service.TransactionService) {
+	logger.Log.Info("Refund queue worker started")
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := transactionService.ProcessRefundQueue(ctx); err != nil {
+				logger.Log.Error("Refund queue processing failed", zap.Error(err))
+			}
+
+		case <-ctx.Done():
+			logger.Log.Info("Refund queue worker stopped")
+			return
+		}
+	}
+}
+
 // Currency Update Worker - Updates exchange rates every 24 hour
 func startCurrencyUpdateWorker(ctx context.Context, currencyService *service.CurrencyService) {
 	logger.Log.Info("Currency update worker started")
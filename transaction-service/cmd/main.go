@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // settlement timezones must resolve in minimal images
 
 	"github.com/rhaloubi/payment-gateway/transaction-service/config"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
@@ -30,15 +31,36 @@ func main() {
 	// Create services
 	settlementService := service.NewSettlementService()
 	currencyService := service.NewCurrencyService()
+	reconciliationService := service.NewReconciliationService()
+	chargebackService := service.NewChargebackService()
+	balanceService := service.NewBalanceService()
+	ledgerService := service.NewLedgerService()
+	transactionService, err := service.NewTransactionService()
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize transaction service", zap.Error(err))
+	}
 
 	// Context for background workers
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Start background workers
-	go startSettlementWorker(ctx, settlementService)
+	go startSettlementWorker(ctx, settlementService, balanceService)
 	go startAutoVoidWorker(ctx, settlementService)
 	go startCurrencyUpdateWorker(ctx, currencyService)
+	go startReconciliationWorker(ctx, reconciliationService)
+	go startLedgerCheckWorker(ctx, ledgerService)
+	go startDisputeDeadlineWorker(ctx, chargebackService)
+	go startRefundQueueWorker(ctx, transactionService)
+
+	// Lifecycle events go to the event broker only when EVENT_BROKER_URL is set
+	outboxRelay, err := service.NewOutboxRelayService()
+	if err != nil {
+		logger.Log.Fatal("Failed to initialize outbox relay", zap.Error(err))
+	}
+	if outboxRelay != nil {
+		go startOutboxRelayWorker(ctx, outboxRelay)
+	}
 
 	// Get gRPC port
 	grpcPort := config.GetEnv("GRPC_PORT")
@@ -47,7 +69,7 @@ func main() {
 	}
 
 	// Start gRPC server
-	go startGRPCServer(grpcPort)
+	go startGRPCServer(ctx, grpcPort)
 
 	// Setup graceful shutdown
 	stop := make(chan os.Signal, 1)
@@ -58,6 +80,13 @@ func main() {
 		port = "8005"
 	}
 
+	// The admin API (fault injection, connector routing) is opt-in
+	if adminToken := config.GetEnv("ADMIN_API_TOKEN"); adminToken != "" {
+		go startAdminServer(port, adminToken)
+	} else {
+		logger.Log.Info("Admin API disabled (ADMIN_API_TOKEN not set)")
+	}
+
 	logger.Log.Info("✅ Transaction Service running",
 		zap.String("grpc_port", grpcPort),
 	)
@@ -12,8 +12,11 @@ var DB *gorm.DB
 
 func InitDB() {
 	var err error
-	dsn := config.GetEnv("DATABASE_DSN")
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	dsn := config.GetRegionalEnv("DATABASE_DSN")
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+		// Timestamps are written in UTC whatever the host timezone
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
 	if err != nil {
 		panic("failed to connect database")
 	}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// EMV 3DS transStatus values
+const (
+	ThreeDSAuthenticated = "Y"
+	ThreeDSAttempted     = "A" // Issuer not enrolled; liability still shifts
+	ThreeDSChallenge     = "C"
+	ThreeDSFailed        = "N"
+	ThreeDSRejected      = "R"
+	ThreeDSUnavailable   = "U"
+)
+
+const threeDSVersion = "2.2.0"
+
+var ErrInvalidChallengeResponse = errors.New("invalid 3-D Secure challenge response")
+
+// =========================================================================
+// 3-D Secure Request/Response Types
+// =========================================================================
+
+type AuthenticateCardRequest struct {
+	MerchantID string
+	CardBrand  string
+	CardLast4  string
+	Amount     int64
+	Currency   string
+	ReturnURL  string
+}
+
+type AuthenticateCardResponse struct {
+	TransStatus     string
+	ECI             string
+	CAVV            string
+	DSTransactionID string
+	ACSURL          string
+	Version         string
+	Reason          string
+}
+
+type CompleteChallengeRequest struct {
+	MerchantID      string
+	DSTransactionID string
+	CardBrand       string
+	CRes            string
+}
+
+// challengeResponse is the decoded CRes the ACS posts back after a challenge
+type challengeResponse struct {
+	ThreeDSServerTransID string `json:"threeDSServerTransID"`
+	TransStatus          string `json:"transStatus"`
+}
+
+// =========================================================================
+// 3-D Secure
+// =========================================================================
+
+// Authenticate simulates a 3DS2 authentication request to the issuer's ACS.
+// The outcome depends on the card's last 4 digits:
+//
+//	3220  challenge required
+//	3238  authentication failed
+//	3246  issuer not enrolled (attempted)
+//	other frictionless success
+func (c *CardSimulatorClient) Authenticate(ctx context.Context, req *AuthenticateCardRequest) (*AuthenticateCardResponse, error) {
+	logger.Log.Info("Simulating 3-D Secure authentication",
+		zap.String("card_last4", req.CardLast4),
+		zap.Int64("amount", req.Amount),
+	)
+
+	if _, err := c.injectFaults(ctx, "authenticate", req.MerchantID); err != nil {
+		return nil, err
+	}
+
+	resp := &AuthenticateCardResponse{
+		DSTransactionID: uuid.New().String(),
+		Version:         threeDSVersion,
+	}
+
+	switch req.CardLast4 {
+	case "3220":
+		resp.TransStatus = ThreeDSChallenge
+		resp.ACSURL = challengeURL(resp.DSTransactionID, req.ReturnURL)
+	case "3238":
+		resp.TransStatus = ThreeDSFailed
+		resp.Reason = "Cardholder not authenticated"
+	case "3246":
+		resp.TransStatus = ThreeDSAttempted
+	default:
+		resp.TransStatus = ThreeDSAuthenticated
+	}
+	c.setCryptogram(resp, req.CardBrand)
+
+	logger.Log.Info("3-D Secure simulation complete",
+		zap.String("trans_status", resp.TransStatus),
+		zap.String("ds_transaction_id", resp.DSTransactionID),
+	)
+
+	return resp, nil
+}
+
+// CompleteChallenge reads the challenge outcome from the CRes. The simulated
+// ACS has no challenge page of its own, so sandbox users build the CRes
+// themselves: base64url JSON with threeDSServerTransID and transStatus.
+func (c *CardSimulatorClient) CompleteChallenge(ctx context.Context, req *CompleteChallengeRequest) (*AuthenticateCardResponse, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(req.CRes, "="))
+	if err != nil {
+		return nil, ErrInvalidChallengeResponse
+	}
+
+	var cres challengeResponse
+	if err := json.Unmarshal(raw, &cres); err != nil {
+		return nil, ErrInvalidChallengeResponse
+	}
+	if cres.ThreeDSServerTransID != req.DSTransactionID {
+		return nil, ErrInvalidChallengeResponse
+	}
+
+	resp := &AuthenticateCardResponse{
+		DSTransactionID: req.DSTransactionID,
+		Version:         threeDSVersion,
+	}
+	switch cres.TransStatus {
+	case ThreeDSAuthenticated:
+		resp.TransStatus = ThreeDSAuthenticated
+	case ThreeDSFailed, ThreeDSRejected:
+		resp.TransStatus = cres.TransStatus
+		resp.Reason = "Cardholder failed the challenge"
+	default:
+		return nil, ErrInvalidChallengeResponse
+	}
+	c.setCryptogram(resp, req.CardBrand)
+
+	logger.Log.Info("3-D Secure challenge completed",
+		zap.String("trans_status", resp.TransStatus),
+		zap.String("ds_transaction_id", resp.DSTransactionID),
+	)
+
+	return resp, nil
+}
+
+// setCryptogram fills the ECI for the outcome and, when liability shifts,
+// a CAVV. Mastercard uses its own ECI values.
+func (c *CardSimulatorClient) setCryptogram(resp *AuthenticateCardResponse, cardBrand string) {
+	mastercard := strings.EqualFold(cardBrand, "mastercard")
+
+	switch resp.TransStatus {
+	case ThreeDSAuthenticated:
+		resp.ECI = "05"
+		if mastercard {
+			resp.ECI = "02"
+		}
+	case ThreeDSAttempted:
+		resp.ECI = "06"
+		if mastercard {
+			resp.ECI = "01"
+		}
+	case ThreeDSFailed, ThreeDSRejected:
+		resp.ECI = "07"
+		if mastercard {
+			resp.ECI = "00"
+		}
+		return
+	default:
+		return
+	}
+
+	cavv := make([]byte, 20)
+	_, _ = rand.Read(cavv)
+	resp.CAVV = base64.StdEncoding.EncodeToString(cavv)
+}
+
+func challengeURL(dsTransactionID, returnURL string) string {
+	base := config.GetEnvWithDefault("SIMULATOR_ACS_URL", "https://acs.simulator.local/challenge")
+	query := url.Values{}
+	query.Set("threeDSServerTransID", dsTransactionID)
+	if returnURL != "" {
+		query.Set("return_url", returnURL)
+	}
+	return base + "?" + query.Encode()
+}
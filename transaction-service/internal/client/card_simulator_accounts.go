@@ -0,0 +1,362 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+)
+
+// Simulated issuer accounts live in Redis so every replica sees the same
+// balances. A card gets an account with the default balance on its first
+// authorization. Amounts are minor units of whatever currency the
+// transaction uses; the simulator does not convert.
+const (
+	issuerAccountKeyPrefix     = "card_simulator:accounts:"
+	issuerTransactionKeyPrefix = "card_simulator:transactions:"
+	issuerAccountRetention     = 90 * 24 * time.Hour
+	issuerAccountField         = "account" // Transaction hash field naming its account
+
+	defaultIssuerBalance   = 1000000 // 10,000.00
+	defaultIssuerHoldHours = 168     // Matches the 7-day authorization expiry
+)
+
+var (
+	ErrIssuerAccountNotFound = errors.New("simulated issuer account not found")
+	ErrInvalidIssuerAccount  = errors.New("balance and credit_limit must not be negative")
+)
+
+// Results of the issuer account scripts
+const (
+	issuerOK          = 1
+	issuerNoHold      = -1 // Capture: hold expired, voided or already closed
+	issuerOverCapture = -2 // Capture: more than the hold has left
+	issuerOverRefund  = -3 // Refund: more than was captured and not yet refunded
+	issuerNotTracked  = -4 // Transaction authorized before accounts existed
+)
+
+// IssuerAccount is a simulated cardholder account. Available is what a new
+// authorization can take: Balance + CreditLimit - Held.
+type IssuerAccount struct {
+	ID          string       `json:"account_id"`
+	CardLast4   string       `json:"card_last4"`
+	Balance     int64        `json:"balance"`
+	CreditLimit int64        `json:"credit_limit"`
+	Held        int64        `json:"held"`
+	Available   int64        `json:"available"`
+	Holds       []IssuerHold `json:"holds"`
+}
+
+// IssuerHold is an open authorization hold on an account
+type IssuerHold struct {
+	TransactionID string    `json:"transaction_id"`
+	Amount        int64     `json:"amount"` // Not yet captured
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// IssuerAccountStore keeps the simulator's cardholder accounts and holds
+type IssuerAccountStore struct {
+	rdb            *redis.Client
+	defaultBalance int64
+	defaultLimit   int64
+	holdTTL        time.Duration
+}
+
+func NewIssuerAccountStore() *IssuerAccountStore {
+	return &IssuerAccountStore{
+		rdb:            inits.RDB,
+		defaultBalance: simulatorEnvInt64("SIMULATOR_DEFAULT_BALANCE", defaultIssuerBalance),
+		defaultLimit:   simulatorEnvInt64("SIMULATOR_DEFAULT_CREDIT_LIMIT", 0),
+		holdTTL:        time.Duration(simulatorEnvInt64("SIMULATOR_HOLD_HOURS", defaultIssuerHoldHours)) * time.Hour,
+	}
+}
+
+func simulatorEnvInt64(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(config.GetEnvWithDefault(key, ""), 10, 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// IssuerAccountID identifies a card's account without keeping its number
+func IssuerAccountID(cardNumber string) string {
+	sum := sha256.Sum256([]byte(cardNumber))
+	return hex.EncodeToString(sum[:8])
+}
+
+func issuerAccountKeys(accountID string) []string {
+	key := issuerAccountKeyPrefix + accountID
+	return []string{key, key + ":holds", key + ":hold_amounts"}
+}
+
+// purgeExpiredHolds releases holds past their expiry, so every script sees
+// the account as the cardholder would
+const purgeExpiredHolds = `
+local function purge(account, holds, amounts, now)
+	local expired = redis.call('ZRANGEBYSCORE', holds, '-inf', now)
+	for _, id in ipairs(expired) do
+		local amount = tonumber(redis.call('HGET', amounts, id) or '0')
+		redis.call('HINCRBY', account, 'held', -amount)
+		redis.call('HDEL', amounts, id)
+	end
+	if #expired > 0 then
+		redis.call('ZREMRANGEBYSCORE', holds, '-inf', now)
+	end
+end
+
+local function touch(ttl, ...)
+	for _, key in ipairs({...}) do
+		redis.call('PEXPIRE', key, ttl)
+	end
+end
+`
+
+// KEYS: account, holds, hold amounts, transaction
+// ARGV: now ms, hold expiry ms, amount, default balance, default limit,
+// last4, force, transaction ID, retention ms, account ID
+var placeIssuerHold = redis.NewScript(purgeExpiredHolds + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('HSET', KEYS[1], 'balance', ARGV[4], 'credit_limit', ARGV[5], 'held', 0, 'last4', ARGV[6])
+end
+purge(KEYS[1], KEYS[2], KEYS[3], ARGV[1])
+
+local amount = tonumber(ARGV[3])
+if ARGV[7] ~= '1' then
+	local available = tonumber(redis.call('HGET', KEYS[1], 'balance'))
+		+ tonumber(redis.call('HGET', KEYS[1], 'credit_limit'))
+		- tonumber(redis.call('HGET', KEYS[1], 'held'))
+	if amount > available then
+		touch(ARGV[9], KEYS[1])
+		return 0
+	end
+end
+
+redis.call('HINCRBY', KEYS[1], 'held', amount)
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[8])
+redis.call('HSET', KEYS[3], ARGV[8], amount)
+redis.call('HSET', KEYS[4], 'account', ARGV[10], 'captured', 0, 'refunded', 0)
+touch(ARGV[9], KEYS[1], KEYS[2], KEYS[3], KEYS[4])
+return 1
+`)
+
+// KEYS: account, holds, hold amounts, transaction
+// ARGV: now ms, amount, final, transaction ID, retention ms
+var captureIssuerHold = redis.NewScript(purgeExpiredHolds + `
+purge(KEYS[1], KEYS[2], KEYS[3], ARGV[1])
+
+local remaining = redis.call('HGET', KEYS[3], ARGV[4])
+if not remaining then
+	return -1
+end
+remaining = tonumber(remaining)
+local amount = tonumber(ARGV[2])
+if amount > remaining then
+	return -2
+end
+
+redis.call('HINCRBY', KEYS[1], 'balance', -amount)
+if ARGV[3] == '1' or amount == remaining then
+	redis.call('HINCRBY', KEYS[1], 'held', -remaining)
+	redis.call('HDEL', KEYS[3], ARGV[4])
+	redis.call('ZREM', KEYS[2], ARGV[4])
+else
+	redis.call('HINCRBY', KEYS[1], 'held', -amount)
+	redis.call('HINCRBY', KEYS[3], ARGV[4], -amount)
+end
+redis.call('HINCRBY', KEYS[4], 'captured', amount)
+touch(ARGV[5], KEYS[1], KEYS[2], KEYS[3], KEYS[4])
+return 1
+`)
+
+// KEYS: account, holds, hold amounts
+// ARGV: now ms, transaction ID
+var releaseIssuerHold = redis.NewScript(purgeExpiredHolds + `
+purge(KEYS[1], KEYS[2], KEYS[3], ARGV[1])
+
+local remaining = redis.call('HGET', KEYS[3], ARGV[2])
+if remaining then
+	redis.call('HINCRBY', KEYS[1], 'held', -tonumber(remaining))
+	redis.call('HDEL', KEYS[3], ARGV[2])
+	redis.call('ZREM', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// KEYS: account, transaction
+// ARGV: amount, retention ms
+var creditIssuerRefund = redis.NewScript(`
+local amount = tonumber(ARGV[1])
+local captured = tonumber(redis.call('HGET', KEYS[2], 'captured') or '0')
+local refunded = tonumber(redis.call('HGET', KEYS[2], 'refunded') or '0')
+if amount > captured - refunded then
+	return -3
+end
+redis.call('HINCRBY', KEYS[1], 'balance', amount)
+redis.call('HINCRBY', KEYS[2], 'refunded', amount)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return 1
+`)
+
+// placeHold holds amount on the card's account for the transaction. A
+// forced hold skips the funds check, for approvals injected by a fault
+// profile.
+func (s *IssuerAccountStore) placeHold(ctx context.Context, cardNumber, txnID string, amount int64, force bool) (bool, error) {
+	accountID := IssuerAccountID(cardNumber)
+	keys := append(issuerAccountKeys(accountID), issuerTransactionKeyPrefix+txnID)
+
+	last4 := cardNumber
+	if len(cardNumber) > 4 {
+		last4 = cardNumber[len(cardNumber)-4:]
+	}
+	forced := "0"
+	if force {
+		forced = "1"
+	}
+
+	now := time.Now()
+	result, err := placeIssuerHold.Run(ctx, s.rdb, keys,
+		now.UnixMilli(), now.Add(s.holdTTL).UnixMilli(), amount,
+		s.defaultBalance, s.defaultLimit, last4, forced, txnID,
+		issuerAccountRetention.Milliseconds(), accountID,
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return result == issuerOK, nil
+}
+
+// transactionAccount returns the account a transaction's hold was placed
+// on, or "" for transactions the accounts do not track
+func (s *IssuerAccountStore) transactionAccount(ctx context.Context, txnID string) (string, error) {
+	accountID, err := s.rdb.HGet(ctx, issuerTransactionKeyPrefix+txnID, issuerAccountField).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return accountID, err
+}
+
+// captureHold posts a capture against the transaction's hold. A final
+// capture releases whatever the hold has left.
+func (s *IssuerAccountStore) captureHold(ctx context.Context, txnID string, amount int64, final bool) (int, error) {
+	accountID, err := s.transactionAccount(ctx, txnID)
+	if err != nil || accountID == "" {
+		return issuerNotTracked, err
+	}
+	keys := append(issuerAccountKeys(accountID), issuerTransactionKeyPrefix+txnID)
+
+	finalArg := "0"
+	if final {
+		finalArg = "1"
+	}
+	return captureIssuerHold.Run(ctx, s.rdb, keys,
+		time.Now().UnixMilli(), amount, finalArg, txnID, issuerAccountRetention.Milliseconds(),
+	).Int()
+}
+
+// releaseHold drops what is left of the transaction's hold
+func (s *IssuerAccountStore) releaseHold(ctx context.Context, txnID string) error {
+	accountID, err := s.transactionAccount(ctx, txnID)
+	if err != nil || accountID == "" {
+		return err
+	}
+	return releaseIssuerHold.Run(ctx, s.rdb, issuerAccountKeys(accountID),
+		time.Now().UnixMilli(), txnID,
+	).Err()
+}
+
+// creditRefund credits a refund back to the account the transaction was
+// captured from
+func (s *IssuerAccountStore) creditRefund(ctx context.Context, txnID string, amount int64) (int, error) {
+	accountID, err := s.transactionAccount(ctx, txnID)
+	if err != nil || accountID == "" {
+		return issuerNotTracked, err
+	}
+	return creditIssuerRefund.Run(ctx, s.rdb,
+		[]string{issuerAccountKeys(accountID)[0], issuerTransactionKeyPrefix + txnID},
+		amount, issuerAccountRetention.Milliseconds(),
+	).Int()
+}
+
+// Get returns an account with its open holds
+func (s *IssuerAccountStore) Get(ctx context.Context, accountID string) (*IssuerAccount, error) {
+	keys := issuerAccountKeys(accountID)
+	fields, err := s.rdb.HGetAll(ctx, keys[0]).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrIssuerAccountNotFound
+	}
+
+	account := &IssuerAccount{ID: accountID, CardLast4: fields["last4"], Holds: []IssuerHold{}}
+	account.Balance, _ = strconv.ParseInt(fields["balance"], 10, 64)
+	account.CreditLimit, _ = strconv.ParseInt(fields["credit_limit"], 10, 64)
+
+	// Expired holds are only purged on the next write, so leave them out here
+	open, err := s.rdb.ZRangeByScoreWithScores(ctx, keys[1], &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, z := range open {
+		txnID := z.Member.(string)
+		amount, err := s.rdb.HGet(ctx, keys[2], txnID).Int64()
+		if err != nil {
+			continue
+		}
+		account.Held += amount
+		account.Holds = append(account.Holds, IssuerHold{
+			TransactionID: txnID,
+			Amount:        amount,
+			ExpiresAt:     time.UnixMilli(int64(z.Score)).UTC(),
+		})
+	}
+	account.Available = account.Balance + account.CreditLimit - account.Held
+	return account, nil
+}
+
+// Set creates or updates a card's account. Open holds are kept.
+func (s *IssuerAccountStore) Set(ctx context.Context, cardNumber string, balance, creditLimit int64) (*IssuerAccount, error) {
+	if balance < 0 || creditLimit < 0 {
+		return nil, ErrInvalidIssuerAccount
+	}
+	accountID := IssuerAccountID(cardNumber)
+	key := issuerAccountKeys(accountID)[0]
+
+	last4 := cardNumber
+	if len(cardNumber) > 4 {
+		last4 = cardNumber[len(cardNumber)-4:]
+	}
+
+	pipe := s.rdb.TxPipeline()
+	pipe.HSet(ctx, key, "balance", balance, "credit_limit", creditLimit, "last4", last4)
+	pipe.HSetNX(ctx, key, "held", 0)
+	pipe.Expire(ctx, key, issuerAccountRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, accountID)
+}
+
+// Delete removes an account and its holds; the card starts again from the
+// default balance on its next authorization
+func (s *IssuerAccountStore) Delete(ctx context.Context, accountID string) error {
+	deleted, err := s.rdb.Del(ctx, issuerAccountKeys(accountID)...).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrIssuerAccountNotFound
+	}
+	return nil
+}
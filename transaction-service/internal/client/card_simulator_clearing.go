@@ -0,0 +1,285 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// The simulated network keeps what it captured per UTC day in Redis, so
+// every replica contributes to the same clearing file
+const (
+	clearingRedisKeyPrefix = "card_simulator:clearing:"
+	clearingRetention      = 35 * 24 * time.Hour
+	clearingDateLayout     = "2006-01-02"
+)
+
+var ErrInvalidClearingFile = errors.New("invalid clearing file")
+
+// A clearing file lists what the network captured and refunded that day.
+// Files from before refunds were cleared have the legacy header and hold
+// only captures.
+var (
+	clearingFileHeader       = []string{"record_type", "transaction_id", "original_transaction_id", "merchant_id", "amount", "currency", "processed_at"}
+	legacyClearingFileHeader = []string{"transaction_id", "merchant_id", "amount", "currency", "captured_at"}
+)
+
+// ClearingRecordType is the money movement a clearing record reports
+type ClearingRecordType string
+
+const (
+	ClearingRecordCapture ClearingRecordType = "capture"
+	ClearingRecordRefund  ClearingRecordType = "refund"
+)
+
+// ClearingRecord is one capture or refund the network cleared. For a
+// refund, TransactionID is our refund transaction and OriginalTransactionID
+// the payment it refunds. Amount is positive, in minor units of Currency.
+type ClearingRecord struct {
+	Type                  ClearingRecordType `json:"type"`
+	TransactionID         uuid.UUID          `json:"transaction_id"`
+	OriginalTransactionID uuid.UUID          `json:"original_transaction_id,omitempty"`
+	MerchantID            uuid.UUID          `json:"merchant_id"`
+	Amount                int64              `json:"amount"`
+	Currency              string             `json:"currency"`
+	ProcessedAt           time.Time          `json:"processed_at"`
+}
+
+// recordClearing adds a successful capture to today's clearing file
+func (c *CardSimulatorClient) recordClearing(ctx context.Context, req *CaptureCardRequest) {
+	txnID, err := uuid.Parse(req.TransactionID)
+	if err != nil {
+		return
+	}
+	merchantID, _ := uuid.Parse(req.MerchantID)
+
+	c.appendClearing(ctx, ClearingRecord{
+		Type:          ClearingRecordCapture,
+		TransactionID: txnID,
+		MerchantID:    merchantID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	})
+}
+
+// recordRefundClearing adds a successful refund to today's clearing file
+func (c *CardSimulatorClient) recordRefundClearing(ctx context.Context, req *RefundCardRequest) {
+	refundID, err := uuid.Parse(req.RefundTransactionID)
+	if err != nil {
+		return
+	}
+	originalID, _ := uuid.Parse(req.TransactionID)
+	merchantID, _ := uuid.Parse(req.MerchantID)
+
+	c.appendClearing(ctx, ClearingRecord{
+		Type:                  ClearingRecordRefund,
+		TransactionID:         refundID,
+		OriginalTransactionID: originalID,
+		MerchantID:            merchantID,
+		Amount:                req.Amount,
+		Currency:              req.Currency,
+	})
+}
+
+// appendClearing stores a record in today's clearing file. The movement has
+// already happened at the network, so a failure here is only logged and
+// shows up later as a reconciliation mismatch.
+func (c *CardSimulatorClient) appendClearing(ctx context.Context, record ClearingRecord) {
+	now := time.Now().UTC()
+	record.ProcessedAt = now
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	key := clearingRedisKeyPrefix + now.Format(clearingDateLayout)
+	pipe := inits.RDB.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.Expire(ctx, key, clearingRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Log.Warn("Failed to record clearing",
+			zap.String("type", string(record.Type)),
+			zap.String("transaction_id", record.TransactionID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// ClearingRecords returns the captures and refunds the network cleared on a
+// UTC day
+func (c *CardSimulatorClient) ClearingRecords(ctx context.Context, day time.Time) ([]ClearingRecord, error) {
+	raw, err := inits.RDB.LRange(ctx, clearingRedisKeyPrefix+day.UTC().Format(clearingDateLayout), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]ClearingRecord, 0, len(raw))
+	for _, item := range raw {
+		// Records stored before refunds were cleared are captures with a
+		// captured_at time
+		var stored struct {
+			ClearingRecord
+			CapturedAt time.Time `json:"captured_at"`
+		}
+		if err := json.Unmarshal([]byte(item), &stored); err != nil {
+			logger.Log.Warn("Skipping unreadable clearing record", zap.Error(err))
+			continue
+		}
+		record := stored.ClearingRecord
+		if record.Type == "" {
+			record.Type = ClearingRecordCapture
+			record.ProcessedAt = stored.CapturedAt
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GenerateClearingFile renders a UTC day's clearing records as the CSV file
+// the network sends the acquirer
+func (c *CardSimulatorClient) GenerateClearingFile(ctx context.Context, day time.Time) ([]byte, error) {
+	records, err := c.ClearingRecords(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(clearingFileHeader)
+	for _, r := range records {
+		original := ""
+		if r.OriginalTransactionID != uuid.Nil {
+			original = r.OriginalTransactionID.String()
+		}
+		_ = w.Write([]string{
+			string(r.Type),
+			r.TransactionID.String(),
+			original,
+			r.MerchantID.String(),
+			strconv.FormatInt(r.Amount, 10),
+			r.Currency,
+			r.ProcessedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseClearingFile reads a clearing file written by GenerateClearingFile,
+// or one with the legacy capture-only layout
+func ParseClearingFile(data []byte) ([]ClearingRecord, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidClearingFile)
+	}
+	parseRow := parseClearingRow
+	expected := clearingFileHeader
+	if len(header) == len(legacyClearingFileHeader) {
+		parseRow = parseLegacyClearingRow
+		expected = legacyClearingFileHeader
+	}
+	if len(header) != len(expected) {
+		return nil, fmt.Errorf("%w: expected %d columns", ErrInvalidClearingFile, len(clearingFileHeader))
+	}
+	for i, name := range expected {
+		if header[i] != name {
+			return nil, fmt.Errorf("%w: unexpected column %q", ErrInvalidClearingFile, header[i])
+		}
+	}
+
+	var records []ClearingRecord
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidClearingFile, err)
+		}
+		if len(row) != len(expected) {
+			return nil, fmt.Errorf("%w: line %d: expected %d columns", ErrInvalidClearingFile, line, len(expected))
+		}
+
+		record, err := parseRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidClearingFile, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseClearingRow(row []string) (ClearingRecord, error) {
+	var record ClearingRecord
+	var err error
+
+	record.Type = ClearingRecordType(row[0])
+	switch record.Type {
+	case ClearingRecordCapture:
+		if row[2] != "" {
+			return record, errors.New("original_transaction_id is only set on refunds")
+		}
+	case ClearingRecordRefund:
+		if record.OriginalTransactionID, err = uuid.Parse(row[2]); err != nil {
+			return record, errors.New("invalid original_transaction_id")
+		}
+	default:
+		return record, errors.New("invalid record_type")
+	}
+	if record.TransactionID, err = uuid.Parse(row[1]); err != nil {
+		return record, errors.New("invalid transaction_id")
+	}
+	if err := parseClearingAmounts(&record, row[3], row[4], row[5], row[6]); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+func parseLegacyClearingRow(row []string) (ClearingRecord, error) {
+	record := ClearingRecord{Type: ClearingRecordCapture}
+	var err error
+
+	if record.TransactionID, err = uuid.Parse(row[0]); err != nil {
+		return record, errors.New("invalid transaction_id")
+	}
+	if err := parseClearingAmounts(&record, row[1], row[2], row[3], row[4]); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// parseClearingAmounts reads the columns both layouts share
+func parseClearingAmounts(record *ClearingRecord, merchantID, amount, currency, processedAt string) error {
+	var err error
+	if record.MerchantID, err = uuid.Parse(merchantID); err != nil {
+		return errors.New("invalid merchant_id")
+	}
+	if record.Amount, err = strconv.ParseInt(amount, 10, 64); err != nil || record.Amount < 0 {
+		return errors.New("invalid amount")
+	}
+	if len(currency) != 3 {
+		return errors.New("invalid currency")
+	}
+	record.Currency = currency
+	if record.ProcessedAt, err = time.Parse(time.RFC3339, processedAt); err != nil {
+		return errors.New("invalid processed_at")
+	}
+	return nil
+}
@@ -12,12 +12,16 @@ import (
 
 // CardSimulatorClient simulates issuer bank responses
 type CardSimulatorClient struct {
-	enabled bool
+	enabled  bool
+	faults   *FaultStore
+	accounts *IssuerAccountStore
 }
 
 func NewCardSimulatorClient() *CardSimulatorClient {
 	return &CardSimulatorClient{
-		enabled: true,
+		enabled:  true,
+		faults:   NewFaultStore(),
+		accounts: NewIssuerAccountStore(),
 	}
 }
 
@@ -26,12 +30,15 @@ func NewCardSimulatorClient() *CardSimulatorClient {
 // =========================================================================
 
 type AuthorizeCardRequest struct {
-	CardNumber string
-	ExpMonth   int32
-	ExpYear    int32
-	Amount     int64
-	Currency   string
-	MerchantID string
+	TransactionID string // Holds on the simulator's issuer accounts are kept per transaction
+	CardNumber    string
+	ExpMonth      int32
+	ExpYear       int32
+	Amount        int64
+	Currency      string
+	MerchantID    string
+	ECI           string // 3-D Secure result, empty when the cardholder was not authenticated
+	CAVV          string
 }
 
 type AuthorizeCardResponse struct {
@@ -46,7 +53,10 @@ type AuthorizeCardResponse struct {
 
 type CaptureCardRequest struct {
 	TransactionID string
+	MerchantID    string
 	Amount        int64
+	Currency      string
+	FinalCapture  bool // Releases what is left of the authorization hold
 }
 
 type CaptureCardResponse struct {
@@ -56,6 +66,7 @@ type CaptureCardResponse struct {
 
 type VoidCardRequest struct {
 	TransactionID string
+	MerchantID    string
 	Reason        string
 }
 
@@ -65,9 +76,12 @@ type VoidCardResponse struct {
 }
 
 type RefundCardRequest struct {
-	TransactionID string
-	Amount        int64
-	Reason        string
+	TransactionID       string // The payment being refunded
+	RefundTransactionID string // Our refund transaction, named in the clearing file
+	MerchantID          string
+	Amount              int64
+	Currency            string
+	Reason              string
 }
 
 type RefundCardResponse struct {
@@ -97,8 +111,32 @@ func (c *CardSimulatorClient) Authorize(ctx context.Context, req *AuthorizeCardR
 	processingTime := time.Duration(100+rand.Intn(400)) * time.Millisecond
 	time.Sleep(processingTime) */
 
+	forcedCode, err := c.injectFaults(ctx, "authorize", req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Simulate authorization based on test cards
 	response := c.simulateAuthorization(cardLast4)
+	if forcedCode != "" {
+		response = forcedAuthorization(forcedCode, c.generateAuthCode())
+	}
+
+	// Approvals hold funds on the cardholder's account. Injected approvals
+	// are held even past the available balance.
+	if response.Approved && req.TransactionID != "" {
+		held, err := c.accounts.placeHold(ctx, req.CardNumber, req.TransactionID, req.Amount, forcedCode != "")
+		if err != nil {
+			return nil, fmt.Errorf("simulated issuer account unavailable: %w", err)
+		}
+		if !held {
+			response = &AuthorizeCardResponse{
+				Approved:      false,
+				ResponseCode:  "51",
+				DeclineReason: "Insufficient funds",
+			}
+		}
+	}
 
 	logger.Log.Info("Authorization simulation complete",
 		zap.Bool("approved", response.Approved),
@@ -123,6 +161,26 @@ func (c *CardSimulatorClient) simulateAuthorization(last4 string) *AuthorizeCard
 			CVVResult:       "M", // CVV match
 		}
 
+	case "0259": // Success - Visa; sandbox captures are then disputed
+		return &AuthorizeCardResponse{
+			Approved:        true,
+			AuthCode:        c.generateAuthCode(),
+			ResponseCode:    "00",
+			ResponseMessage: "Approved",
+			AVSResult:       "Y",
+			CVVResult:       "M",
+		}
+
+	case "3220", "3246": // Success - Visa, 3-D Secure challenge / not enrolled
+		return &AuthorizeCardResponse{
+			Approved:        true,
+			AuthCode:        c.generateAuthCode(),
+			ResponseCode:    "00",
+			ResponseMessage: "Approved",
+			AVSResult:       "Y",
+			CVVResult:       "M",
+		}
+
 	case "4444": // Success - Mastercard
 		return &AuthorizeCardResponse{
 			Approved:        true,
@@ -192,7 +250,37 @@ func (c *CardSimulatorClient) Capture(ctx context.Context, req *CaptureCardReque
 	// Simulate processing
 	time.Sleep(30 * time.Millisecond)
 
-	// Mock: Always succeed
+	forcedCode, err := c.injectFaults(ctx, "capture", req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if forcedCode != "" && forcedCode != "00" {
+		return &CaptureCardResponse{
+			Success:         false,
+			ResponseMessage: "Capture failed (" + forcedCode + ")",
+		}, nil
+	}
+
+	result, err := c.accounts.captureHold(ctx, req.TransactionID, req.Amount, req.FinalCapture)
+	if err != nil {
+		return nil, fmt.Errorf("simulated issuer account unavailable: %w", err)
+	}
+	switch result {
+	case issuerNoHold:
+		return &CaptureCardResponse{
+			Success:         false,
+			ResponseMessage: "Capture failed: authorization hold expired or released",
+		}, nil
+	case issuerOverCapture:
+		return &CaptureCardResponse{
+			Success:         false,
+			ResponseMessage: "Capture failed: amount exceeds the authorization hold",
+		}, nil
+	}
+
+	// The network clears whatever it captured at the end of the day
+	c.recordClearing(ctx, req)
+
 	return &CaptureCardResponse{
 		Success:         true,
 		ResponseMessage: "Capture successful",
@@ -211,7 +299,21 @@ func (c *CardSimulatorClient) Void(ctx context.Context, req *VoidCardRequest) (*
 	// Simulate processing
 	time.Sleep(30 * time.Millisecond)
 
-	// Mock: Always succeed
+	forcedCode, err := c.injectFaults(ctx, "void", req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if forcedCode != "" && forcedCode != "00" {
+		return &VoidCardResponse{
+			Success:         false,
+			ResponseMessage: "Void failed (" + forcedCode + ")",
+		}, nil
+	}
+
+	if err := c.accounts.releaseHold(ctx, req.TransactionID); err != nil {
+		return nil, fmt.Errorf("simulated issuer account unavailable: %w", err)
+	}
+
 	return &VoidCardResponse{
 		Success:         true,
 		ResponseMessage: "Authorization voided successfully",
@@ -231,7 +333,31 @@ func (c *CardSimulatorClient) Refund(ctx context.Context, req *RefundCardRequest
 	// Simulate processing
 	time.Sleep(50 * time.Millisecond)
 
-	// Mock: Always succeed
+	forcedCode, err := c.injectFaults(ctx, "refund", req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if forcedCode != "" && forcedCode != "00" {
+		return &RefundCardResponse{
+			Success:         false,
+			ResponseMessage: "Refund failed (" + forcedCode + ")",
+		}, nil
+	}
+
+	result, err := c.accounts.creditRefund(ctx, req.TransactionID, req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("simulated issuer account unavailable: %w", err)
+	}
+	if result == issuerOverRefund {
+		return &RefundCardResponse{
+			Success:         false,
+			ResponseMessage: "Refund failed: amount exceeds what was captured",
+		}, nil
+	}
+
+	// Refunds are cleared with the day's captures
+	c.recordRefundClearing(ctx, req)
+
 	return &RefundCardResponse{
 		Success:         true,
 		RefundID:        c.generateRefundID(),
@@ -239,6 +365,61 @@ func (c *CardSimulatorClient) Refund(ctx context.Context, req *RefundCardRequest
 	}, nil
 }
 
+// =========================================================================
+// Fault Injection
+// =========================================================================
+
+// injectFaults applies the active fault profile for the call: it sleeps for
+// the sampled latency, then either fails the call or returns a response code
+// that should replace the simulated result
+func (c *CardSimulatorClient) injectFaults(ctx context.Context, op, merchantID string) (string, error) {
+	profile := c.faults.resolve(ctx, merchantID, op)
+	if profile == nil {
+		return "", nil
+	}
+
+	if profile.Latency != nil {
+		delay := profile.Latency.sampleLatency()
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	if profile.ErrorRate > 0 && rand.Float64() < profile.ErrorRate {
+		logger.Log.Info("Injected simulator failure",
+			zap.String("operation", op),
+			zap.String("merchant_id", merchantID),
+		)
+		return "", ErrSimulatedIssuerFailure
+	}
+
+	if profile.ResponseCodeRate > 0 && rand.Float64() < profile.ResponseCodeRate {
+		return profile.ResponseCode, nil
+	}
+	return "", nil
+}
+
+// forcedAuthorization builds an issuer response for an injected response code
+func forcedAuthorization(code, authCode string) *AuthorizeCardResponse {
+	if code == "00" {
+		return &AuthorizeCardResponse{
+			Approved:        true,
+			AuthCode:        authCode,
+			ResponseCode:    code,
+			ResponseMessage: "Approved",
+			AVSResult:       "Y",
+			CVVResult:       "M",
+		}
+	}
+	return &AuthorizeCardResponse{
+		Approved:      false,
+		ResponseCode:  code,
+		DeclineReason: "Simulated issuer response " + code,
+	}
+}
+
 // =========================================================================
 // Helper Methods
 // =========================================================================
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// EventPublisher sends messages to the event broker. Publish may buffer;
+// a message counts as delivered only once Flush returns nil.
+type EventPublisher interface {
+	Publish(subject string, payload []byte) error
+	Flush() error
+	Close() error
+}
+
+// NewEventPublisher returns a publisher for brokerURL. Only NATS
+// (nats://[user:pass@]host:port) is supported.
+func NewEventPublisher(brokerURL, clientName string) (EventPublisher, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event broker URL: %w", err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported event broker scheme %q, expected nats://", u.Scheme)
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATSPublisher{url: u, name: clientName, timeout: 5 * time.Second}, nil
+}
+
+// NATSPublisher speaks the NATS client protocol over plain TCP. It only
+// publishes: Flush sends a PING and waits for the PONG, which NATS sends
+// after processing every message before it. The connection is opened
+// lazily and dropped on any error, so the next call reconnects.
+type NATSPublisher struct {
+	url     *url.URL
+	name    string
+	timeout time.Duration
+
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func (p *NATSPublisher) Publish(subject string, payload []byte) error {
+	if err := p.connect(); err != nil {
+		return err
+	}
+	p.conn.SetDeadline(time.Now().Add(p.timeout))
+
+	fmt.Fprintf(p.w, "PUB %s %d\r\n", subject, len(payload))
+	p.w.Write(payload)
+	if _, err := p.w.WriteString("\r\n"); err != nil {
+		p.reset()
+		return fmt.Errorf("nats publish failed: %w", err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Flush() error {
+	if err := p.connect(); err != nil {
+		return err
+	}
+	p.conn.SetDeadline(time.Now().Add(p.timeout))
+
+	if err := p.ping(); err != nil {
+		p.reset()
+		return fmt.Errorf("nats flush failed: %w", err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+	p.w.Flush()
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+func (p *NATSPublisher) connect() error {
+	if p.conn != nil {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", p.url.Host, p.timeout)
+	if err != nil {
+		return fmt.Errorf("nats connect failed: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(p.timeout))
+	p.conn, p.r, p.w = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+
+	// The server greets with INFO before anything else
+	line, err := p.readLine()
+	if err == nil && !strings.HasPrefix(line, "INFO ") {
+		err = fmt.Errorf("unexpected greeting %q", line)
+	}
+	if err != nil {
+		p.reset()
+		return fmt.Errorf("nats handshake failed: %w", err)
+	}
+
+	options := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     p.name,
+		"lang":     "go",
+		"protocol": 0,
+	}
+	if user := p.url.User; user != nil {
+		if pass, ok := user.Password(); ok {
+			options["user"], options["pass"] = user.Username(), pass
+		} else {
+			options["auth_token"] = user.Username()
+		}
+	}
+	connectJSON, _ := json.Marshal(options)
+	fmt.Fprintf(p.w, "CONNECT %s\r\n", connectJSON)
+
+	// A bad CONNECT is answered with -ERR before the PONG
+	if err := p.ping(); err != nil {
+		p.reset()
+		return fmt.Errorf("nats handshake failed: %w", err)
+	}
+	return nil
+}
+
+// ping sends PING and reads until the matching PONG, answering the
+// server's own PINGs on the way
+func (p *NATSPublisher) ping() error {
+	if _, err := p.w.WriteString("PING\r\n"); err != nil {
+		return err
+	}
+	if err := p.w.Flush(); err != nil {
+		return err
+	}
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			p.w.WriteString("PONG\r\n")
+			if err := p.w.Flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates need no answer
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) reset() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn = nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/metrics"
+	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// MerchantClient looks up merchant payout details in the merchant service
+type MerchantClient struct {
+	grpcConn            *grpc.ClientConn
+	grpcTimeout         time.Duration
+	payoutAccountClient pb.PayoutAccountServiceClient
+}
+
+func NewMerchantClient() *MerchantClient {
+	grpcAddress := config.GetEnv("MERCHANT_SERVICE_GRPC_URL")
+	if grpcAddress == "" {
+		grpcAddress = "localhost:50054"
+	}
+
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("merchant-service")),
+	)
+	if err != nil {
+		logger.Log.Fatal("failed to dial merchant service gRPC", zap.Error(err))
+	}
+
+	return &MerchantClient{
+		grpcConn:            conn,
+		grpcTimeout:         2 * time.Second,
+		payoutAccountClient: pb.NewPayoutAccountServiceClient(conn),
+	}
+}
+
+// Close closes the gRPC connection
+func (c *MerchantClient) Close() error {
+	if c.grpcConn != nil {
+		return c.grpcConn.Close()
+	}
+	return nil
+}
+
+// GetPayoutAccount returns the merchant's default verified bank account, or
+// nil when the merchant has not set one up
+func (c *MerchantClient) GetPayoutAccount(ctx context.Context, merchantID string) (*pb.PayoutAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.payoutAccountClient.GetPayoutAccount(ctx, &pb.GetPayoutAccountRequest{
+		MerchantId: merchantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if !resp.Found {
+		return nil, nil
+	}
+	return resp.Account, nil
+}
+
+// GetPayoutEligibility returns the merchant's account and verification
+// status and whether it has a verified payout account
+func (c *MerchantClient) GetPayoutEligibility(ctx context.Context, merchantID string) (*pb.GetPayoutEligibilityResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
+	defer cancel()
+
+	resp, err := c.payoutAccountClient.GetPayoutEligibility(ctx, &pb.GetPayoutEligibilityRequest{
+		MerchantId: merchantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
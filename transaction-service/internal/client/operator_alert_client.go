@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// OperatorAlert is a platform-level event an operator has to act on
+type OperatorAlert struct {
+	Type       string                 `json:"type"`
+	MerchantID string                 `json:"merchant_id,omitempty"`
+	BatchID    string                 `json:"batch_id,omitempty"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	RaisedAt   time.Time              `json:"raised_at"`
+}
+
+// OperatorAlertClient logs operator alerts and, when OPERATOR_ALERT_WEBHOOK_URL
+// is set, posts them there as JSON (a Slack or PagerDuty relay, for example)
+type OperatorAlertClient struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+func NewOperatorAlertClient() *OperatorAlertClient {
+	return &OperatorAlertClient{
+		webhookURL: config.GetEnv("OPERATOR_ALERT_WEBHOOK_URL"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Raise records the alert. Delivery failures are logged, never returned, so
+// an unreachable webhook cannot block settlement.
+func (c *OperatorAlertClient) Raise(ctx context.Context, alert OperatorAlert) {
+	if alert.RaisedAt.IsZero() {
+		alert.RaisedAt = time.Now().UTC()
+	}
+
+	logger.Log.Warn("Operator alert",
+		zap.String("type", alert.Type),
+		zap.String("merchant_id", alert.MerchantID),
+		zap.String("batch_id", alert.BatchID),
+		zap.String("message", alert.Message),
+		zap.Any("details", alert.Details),
+	)
+
+	if c.webhookURL == "" {
+		return
+	}
+	if err := c.post(ctx, alert); err != nil {
+		logger.Log.Error("Failed to deliver operator alert",
+			zap.String("type", alert.Type),
+			zap.Error(err),
+		)
+	}
+}
+
+func (c *OperatorAlertClient) post(ctx context.Context, alert OperatorAlert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
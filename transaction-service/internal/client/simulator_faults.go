@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// Faults are kept in Redis so every transaction-service replica injects the
+// same behavior; each replica re-reads them at most once per faultCacheTTL.
+const (
+	faultRedisKey   = "card_simulator:faults"
+	faultGlobalKey  = "global"
+	faultCacheTTL   = 2 * time.Second
+	maxFaultLatency = 60 * time.Second
+)
+
+var ErrSimulatedIssuerFailure = errors.New("simulated issuer failure")
+
+type LatencyDistribution string
+
+const (
+	LatencyFixed       LatencyDistribution = "fixed"       // always MeanMs
+	LatencyUniform     LatencyDistribution = "uniform"     // MeanMs ± JitterMs
+	LatencyNormal      LatencyDistribution = "normal"      // mean MeanMs, stddev JitterMs
+	LatencyExponential LatencyDistribution = "exponential" // mean MeanMs, long tail
+)
+
+// FaultProfile describes the latency and failures injected into simulator calls
+type FaultProfile struct {
+	Latency *LatencyProfile `json:"latency,omitempty"`
+
+	// ErrorRate is the share of calls that fail outright, as a transport
+	// error would (0-1)
+	ErrorRate float64 `json:"error_rate"`
+
+	// ResponseCode is returned instead of the simulated result for
+	// ResponseCodeRate of calls (e.g. "91" issuer unavailable, "05" decline)
+	ResponseCode     string  `json:"response_code,omitempty"`
+	ResponseCodeRate float64 `json:"response_code_rate"`
+
+	// Operations limits the profile to authenticate/authorize/capture/void/refund;
+	// empty means all
+	Operations []string `json:"operations,omitempty"`
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type LatencyProfile struct {
+	Distribution LatencyDistribution `json:"distribution"`
+	MeanMs       int                 `json:"mean_ms"`
+	JitterMs     int                 `json:"jitter_ms"`
+	MaxMs        int                 `json:"max_ms,omitempty"`
+}
+
+// Validate checks rates and latency bounds
+func (p *FaultProfile) Validate() error {
+	if p.ErrorRate < 0 || p.ErrorRate > 1 || p.ResponseCodeRate < 0 || p.ResponseCodeRate > 1 {
+		return errors.New("rates must be between 0 and 1")
+	}
+	if p.ResponseCodeRate > 0 && len(p.ResponseCode) != 2 {
+		return errors.New("response_code must be a 2-character ISO 8583 code")
+	}
+	for _, op := range p.Operations {
+		switch op {
+		case "authenticate", "authorize", "capture", "void", "refund":
+		default:
+			return fmt.Errorf("unknown operation %q", op)
+		}
+	}
+	if l := p.Latency; l != nil {
+		switch l.Distribution {
+		case LatencyFixed, LatencyUniform, LatencyNormal, LatencyExponential:
+		default:
+			return fmt.Errorf("unknown latency distribution %q", l.Distribution)
+		}
+		if l.MeanMs < 0 || l.JitterMs < 0 || l.MaxMs < 0 {
+			return errors.New("latency values must be positive")
+		}
+		if time.Duration(l.MeanMs)*time.Millisecond > maxFaultLatency ||
+			time.Duration(l.MaxMs)*time.Millisecond > maxFaultLatency {
+			return fmt.Errorf("latency cannot exceed %s", maxFaultLatency)
+		}
+	}
+	return nil
+}
+
+func (p *FaultProfile) appliesTo(op string) bool {
+	if p.ExpiresAt != nil && time.Now().After(*p.ExpiresAt) {
+		return false
+	}
+	if len(p.Operations) == 0 {
+		return true
+	}
+	for _, o := range p.Operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleLatency draws a delay from the profile's distribution
+func (l *LatencyProfile) sampleLatency() time.Duration {
+	mean := float64(l.MeanMs)
+	jitter := float64(l.JitterMs)
+
+	var ms float64
+	switch l.Distribution {
+	case LatencyUniform:
+		ms = mean - jitter + rand.Float64()*2*jitter
+	case LatencyNormal:
+		ms = rand.NormFloat64()*jitter + mean
+	case LatencyExponential:
+		ms = rand.ExpFloat64() * mean
+	default:
+		ms = mean
+	}
+
+	maxMs := float64(maxFaultLatency / time.Millisecond)
+	if l.MaxMs > 0 {
+		maxMs = float64(l.MaxMs)
+	}
+	ms = math.Max(0, math.Min(ms, maxMs))
+	return time.Duration(ms) * time.Millisecond
+}
+
+// FaultStore reads and writes fault profiles, globally or per merchant
+type FaultStore struct {
+	mu       sync.RWMutex
+	cached   map[string]FaultProfile
+	cachedAt time.Time
+}
+
+func NewFaultStore() *FaultStore {
+	return &FaultStore{}
+}
+
+// List returns every stored profile keyed by "global" or merchant ID
+func (s *FaultStore) List(ctx context.Context) (map[string]FaultProfile, error) {
+	raw, err := inits.RDB.HGetAll(ctx, faultRedisKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]FaultProfile, len(raw))
+	for scope, value := range raw {
+		var p FaultProfile
+		if err := json.Unmarshal([]byte(value), &p); err != nil {
+			logger.Log.Warn("Skipping malformed fault profile", zap.String("scope", scope), zap.Error(err))
+			continue
+		}
+		profiles[scope] = p
+	}
+	return profiles, nil
+}
+
+// Set stores the profile for a scope ("global" or a merchant ID)
+func (s *FaultStore) Set(ctx context.Context, scope string, p *FaultProfile) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := inits.RDB.HSet(ctx, faultRedisKey, scope, data).Err(); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Delete removes the profile for a scope
+func (s *FaultStore) Delete(ctx context.Context, scope string) error {
+	if err := inits.RDB.HDel(ctx, faultRedisKey, scope).Err(); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Clear removes every profile
+func (s *FaultStore) Clear(ctx context.Context) error {
+	if err := inits.RDB.Del(ctx, faultRedisKey).Err(); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// resolve returns the profile for a call: the merchant's if set, otherwise global
+func (s *FaultStore) resolve(ctx context.Context, merchantID, op string) *FaultProfile {
+	profiles := s.snapshot(ctx)
+	if len(profiles) == 0 {
+		return nil
+	}
+	if p, ok := profiles[merchantID]; ok && merchantID != "" && p.appliesTo(op) {
+		return &p
+	}
+	if p, ok := profiles[faultGlobalKey]; ok && p.appliesTo(op) {
+		return &p
+	}
+	return nil
+}
+
+func (s *FaultStore) snapshot(ctx context.Context) map[string]FaultProfile {
+	s.mu.RLock()
+	if time.Since(s.cachedAt) < faultCacheTTL {
+		cached := s.cached
+		s.mu.RUnlock()
+		return cached
+	}
+	s.mu.RUnlock()
+
+	profiles, err := s.List(ctx)
+	if err != nil {
+		// Keep serving the last known profiles if Redis is unavailable
+		logger.Log.Warn("Failed to load simulator fault profiles", zap.Error(err))
+		profiles = s.cached
+	}
+
+	s.mu.Lock()
+	s.cached = profiles
+	s.cachedAt = time.Now()
+	s.mu.Unlock()
+	return profiles
+}
+
+func (s *FaultStore) invalidate() {
+	s.mu.Lock()
+	s.cachedAt = time.Time{}
+	s.mu.Unlock()
+}
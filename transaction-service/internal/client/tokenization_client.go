@@ -8,6 +8,7 @@ import (
 
 	"github.com/rhaloubi/payment-gateway/transaction-service/config"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/metrics"
 	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -29,7 +30,10 @@ func NewTokenizationClient() (*TokenizationClient, error) {
 	}
 
 	// Dial gRPC connection (insecure for dev)
-	conn, err := grpc.Dial(grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(grpcAddress,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor("tokenization-service")),
+	)
 	if err != nil {
 		logger.Log.Fatal("failed to dial gRPC", zap.Error(err))
 	}
@@ -53,7 +57,7 @@ func (c *TokenizationClient) Close() error {
 // ValidateToken validates a token
 func (c *TokenizationClient) ValidateToken(ctx context.Context, token string, merchantID string) (bool, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 	resp, err := c.tokenizationClient.ValidateToken(ctx, &pb.ValidateTokenRequest{
 		Token:      token,
@@ -66,12 +70,18 @@ func (c *TokenizationClient) ValidateToken(ctx context.Context, token string, me
 	return resp.Valid, nil
 }
 
-func (c *TokenizationClient) Detokenize(ctx context.Context, token string, merchantID string) (*pb.DetokenizeResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.grpcTimeout)
+// Detokenize returns the card behind token. The tokenization service logs
+// the use against transactionID.
+func (c *TokenizationClient) Detokenize(ctx context.Context, token, merchantID, transactionID string, amount int64, currency string) (*pb.DetokenizeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.grpcTimeout)
 	defer cancel()
 	resp, err := c.tokenizationClient.Detokenize(ctx, &pb.DetokenizeRequest{
-		Token:      token,
-		MerchantId: merchantID,
+		Token:         token,
+		MerchantId:    merchantID,
+		TransactionId: transactionID,
+		UsageType:     "payment",
+		Amount:        amount,
+		Currency:      currency,
 	})
 	if err != nil {
 		logger.Log.Error("Tokenization service gRPC request failed", zap.Error(err))
@@ -0,0 +1,37 @@
+package connector
+
+import (
+	"context"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+)
+
+// AcquirerConnector is the contract every acquirer integration implements.
+// TransactionService only talks to acquirers through this interface, so a
+// new acquirer is added by registering a connector, not by editing the
+// authorization flow.
+type AcquirerConnector interface {
+	// Name is the stable identifier stored on transactions and used in
+	// merchant routing configuration
+	Name() string
+	Authorize(ctx context.Context, req *client.AuthorizeCardRequest) (*client.AuthorizeCardResponse, error)
+	Capture(ctx context.Context, req *client.CaptureCardRequest) (*client.CaptureCardResponse, error)
+	Void(ctx context.Context, req *client.VoidCardRequest) (*client.VoidCardResponse, error)
+	Refund(ctx context.Context, req *client.RefundCardRequest) (*client.RefundCardResponse, error)
+}
+
+// SimulatorConnectorName identifies the built-in card simulator
+const SimulatorConnectorName = "card_simulator"
+
+// simulatorConnector adapts the in-process card simulator
+type simulatorConnector struct {
+	*client.CardSimulatorClient
+}
+
+func NewSimulatorConnector() AcquirerConnector {
+	return &simulatorConnector{CardSimulatorClient: client.NewCardSimulatorClient()}
+}
+
+func (c *simulatorConnector) Name() string {
+	return SimulatorConnectorName
+}
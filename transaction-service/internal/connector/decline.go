@@ -0,0 +1,56 @@
+package connector
+
+import "time"
+
+// DeclineCategory groups ISO 8583 decline codes by what a merchant can do
+// about them
+type DeclineCategory string
+
+const (
+	// DeclineIssuerUnavailable means the acquirer or issuer path failed, not
+	// the card. Another connector, or the same one shortly after, may approve.
+	DeclineIssuerUnavailable DeclineCategory = "issuer_unavailable"
+	// DeclineTryLater means the card is fine but cannot cover the payment
+	// right now, e.g. insufficient funds or a spending limit
+	DeclineTryLater DeclineCategory = "try_later"
+	// DeclineHard means the same payment will never be approved on this card
+	DeclineHard DeclineCategory = "hard"
+)
+
+type declineRule struct {
+	category   DeclineCategory
+	retryAfter time.Duration
+}
+
+// declineTaxonomy classifies the response codes issuers and the simulator
+// return. Codes not listed are treated as hard declines.
+var declineTaxonomy = map[string]declineRule{
+	"19": {DeclineIssuerUnavailable, 5 * time.Second},  // Re-enter transaction
+	"68": {DeclineIssuerUnavailable, 30 * time.Second}, // Response received too late
+	"91": {DeclineIssuerUnavailable, time.Minute},      // Issuer or switch inoperative
+	"92": {DeclineIssuerUnavailable, time.Minute},      // Unable to route
+	"96": {DeclineIssuerUnavailable, time.Minute},      // System malfunction
+
+	"51": {DeclineTryLater, 24 * time.Hour}, // Insufficient funds
+	"61": {DeclineTryLater, 24 * time.Hour}, // Exceeds withdrawal amount limit
+	"65": {DeclineTryLater, 24 * time.Hour}, // Exceeds withdrawal frequency limit
+}
+
+// ClassifyDecline returns the category of a declined response code
+func ClassifyDecline(responseCode string) DeclineCategory {
+	if rule, ok := declineTaxonomy[responseCode]; ok {
+		return rule.category
+	}
+	return DeclineHard
+}
+
+// RetryAdvice reports whether a declined payment may be retried as is, and
+// how long the merchant should wait first. Hard declines (stolen or expired
+// card, CVV mismatch, do not honor) are never worth retrying unchanged.
+func RetryAdvice(responseCode string) (bool, time.Duration) {
+	rule, ok := declineTaxonomy[responseCode]
+	if !ok {
+		return false, 0
+	}
+	return true, rule.retryAfter
+}
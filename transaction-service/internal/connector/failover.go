@@ -0,0 +1,23 @@
+package connector
+
+import (
+	"context"
+	"errors"
+)
+
+// IsSoftDecline reports whether a declined response is worth retrying on a
+// different connector. Only codes that describe the acquirer or issuer path
+// qualify; a card that lacks funds would be declined everywhere.
+func IsSoftDecline(responseCode string) bool {
+	return ClassifyDecline(responseCode) == DeclineIssuerUnavailable
+}
+
+// IsSoftError reports whether a connector error is worth retrying on a
+// different connector. Errors caused by the caller's own context ending are
+// not, since a second attempt would fail the same way.
+func IsSoftError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
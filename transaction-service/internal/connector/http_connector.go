@@ -0,0 +1,170 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+)
+
+// HTTPConnectorName identifies the reference HTTP acquirer connector
+const HTTPConnectorName = "http_acquirer"
+
+// HTTPConnector is a reference connector for acquirers that expose a JSON
+// REST API. It posts each operation to {baseURL}/{operation} and expects the
+// acquirer to answer with the matching response shape. Real integrations
+// usually need their own message mapping, but can start from this one.
+type HTTPConnector struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewHTTPConnector(baseURL, apiKey string) *HTTPConnector {
+	return &HTTPConnector{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *HTTPConnector) Name() string {
+	return HTTPConnectorName
+}
+
+type httpAuthorizeRequest struct {
+	CardNumber string `json:"card_number"`
+	ExpMonth   int32  `json:"exp_month"`
+	ExpYear    int32  `json:"exp_year"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	MerchantID string `json:"merchant_id"`
+	ECI        string `json:"eci,omitempty"`
+	CAVV       string `json:"cavv,omitempty"`
+}
+
+type httpAuthorizeResponse struct {
+	Approved        bool   `json:"approved"`
+	AuthCode        string `json:"auth_code"`
+	ResponseCode    string `json:"response_code"`
+	ResponseMessage string `json:"response_message"`
+	DeclineReason   string `json:"decline_reason"`
+	AVSResult       string `json:"avs_result"`
+	CVVResult       string `json:"cvv_result"`
+}
+
+type httpOperationRequest struct {
+	TransactionID string `json:"transaction_id"`
+	MerchantID    string `json:"merchant_id"`
+	Amount        int64  `json:"amount,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+type httpOperationResponse struct {
+	Success         bool   `json:"success"`
+	RefundID        string `json:"refund_id,omitempty"`
+	ResponseMessage string `json:"response_message"`
+}
+
+func (c *HTTPConnector) Authorize(ctx context.Context, req *client.AuthorizeCardRequest) (*client.AuthorizeCardResponse, error) {
+	var resp httpAuthorizeResponse
+	if err := c.post(ctx, "authorize", httpAuthorizeRequest{
+		CardNumber: req.CardNumber,
+		ExpMonth:   req.ExpMonth,
+		ExpYear:    req.ExpYear,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		MerchantID: req.MerchantID,
+		ECI:        req.ECI,
+		CAVV:       req.CAVV,
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	return &client.AuthorizeCardResponse{
+		Approved:        resp.Approved,
+		AuthCode:        resp.AuthCode,
+		ResponseCode:    resp.ResponseCode,
+		ResponseMessage: resp.ResponseMessage,
+		DeclineReason:   resp.DeclineReason,
+		AVSResult:       resp.AVSResult,
+		CVVResult:       resp.CVVResult,
+	}, nil
+}
+
+func (c *HTTPConnector) Capture(ctx context.Context, req *client.CaptureCardRequest) (*client.CaptureCardResponse, error) {
+	var resp httpOperationResponse
+	if err := c.post(ctx, "capture", httpOperationRequest{
+		TransactionID: req.TransactionID,
+		MerchantID:    req.MerchantID,
+		Amount:        req.Amount,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &client.CaptureCardResponse{Success: resp.Success, ResponseMessage: resp.ResponseMessage}, nil
+}
+
+func (c *HTTPConnector) Void(ctx context.Context, req *client.VoidCardRequest) (*client.VoidCardResponse, error) {
+	var resp httpOperationResponse
+	if err := c.post(ctx, "void", httpOperationRequest{
+		TransactionID: req.TransactionID,
+		MerchantID:    req.MerchantID,
+		Reason:        req.Reason,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &client.VoidCardResponse{Success: resp.Success, ResponseMessage: resp.ResponseMessage}, nil
+}
+
+func (c *HTTPConnector) Refund(ctx context.Context, req *client.RefundCardRequest) (*client.RefundCardResponse, error) {
+	var resp httpOperationResponse
+	if err := c.post(ctx, "refund", httpOperationRequest{
+		TransactionID: req.TransactionID,
+		MerchantID:    req.MerchantID,
+		Amount:        req.Amount,
+		Reason:        req.Reason,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &client.RefundCardResponse{
+		Success:         resp.Success,
+		RefundID:        resp.RefundID,
+		ResponseMessage: resp.ResponseMessage,
+	}, nil
+}
+
+func (c *HTTPConnector) post(ctx context.Context, operation string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", operation, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+operation, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%s %s request failed: %w", HTTPConnectorName, operation, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned status %d", HTTPConnectorName, operation, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", operation, err)
+	}
+	return nil
+}
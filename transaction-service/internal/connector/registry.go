@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"go.uber.org/zap"
+)
+
+// Registry holds the connectors available to this service instance
+type Registry struct {
+	mu           sync.RWMutex
+	connectors   map[string]AcquirerConnector
+	defaultName  string
+	fallbackName string
+}
+
+func NewRegistry(defaultName string) *Registry {
+	return &Registry{
+		connectors:  make(map[string]AcquirerConnector),
+		defaultName: defaultName,
+	}
+}
+
+// NewDefaultRegistry registers the card simulator plus every connector
+// whose configuration is present in the environment
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry(config.GetEnvWithDefault("DEFAULT_ACQUIRER_CONNECTOR", SimulatorConnectorName))
+	registry.Register(NewSimulatorConnector())
+
+	if baseURL := config.GetEnv("HTTP_ACQUIRER_URL"); baseURL != "" {
+		registry.Register(NewHTTPConnector(baseURL, config.GetEnv("HTTP_ACQUIRER_API_KEY")))
+	}
+
+	if _, err := registry.Default(); err != nil {
+		logger.Log.Warn("Default acquirer connector not registered, falling back to simulator",
+			zap.String("connector", registry.defaultName),
+		)
+		registry.defaultName = SimulatorConnectorName
+	}
+	registry.fallbackName = config.GetEnv("FALLBACK_ACQUIRER_CONNECTOR")
+
+	logger.Log.Info("Acquirer connectors registered", zap.Strings("connectors", registry.Names()))
+	return registry
+}
+
+// Register adds a connector, replacing any existing one with the same name
+func (r *Registry) Register(c AcquirerConnector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connectors[c.Name()] = c
+}
+
+// Get returns the connector registered under name
+func (r *Registry) Get(name string) (AcquirerConnector, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.connectors[name]
+	if !ok {
+		return nil, fmt.Errorf("acquirer connector %q is not registered", name)
+	}
+	return c, nil
+}
+
+// Default returns the connector used when a merchant has no routing config
+func (r *Registry) Default() (AcquirerConnector, error) {
+	return r.Get(r.defaultName)
+}
+
+// DefaultName returns the name of the default connector
+func (r *Registry) DefaultName() string {
+	return r.defaultName
+}
+
+// Fallback returns the connector tried after a soft failure when no routing
+// rule names one, or nil if none is configured
+func (r *Registry) Fallback() AcquirerConnector {
+	if r.fallbackName == "" {
+		return nil
+	}
+	c, err := r.Get(r.fallbackName)
+	if err != nil {
+		return nil
+	}
+	return c
+}
+
+// Names lists registered connectors in alphabetical order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.connectors))
+	for name := range r.connectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
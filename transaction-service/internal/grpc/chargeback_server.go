@@ -0,0 +1,285 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
+	"go.uber.org/zap"
+)
+
+// ChargebackServer exposes dispute handling to payment-api-service
+type ChargebackServer struct {
+	pb.UnimplementedChargebackServiceServer
+	chargebackService *service.ChargebackService
+}
+
+func NewChargebackServer() *ChargebackServer {
+	return &ChargebackServer{
+		chargebackService: service.NewChargebackService(),
+	}
+}
+
+// parseDisputeIDs parses the dispute and merchant IDs every dispute RPC carries
+func parseDisputeIDs(disputeID, merchantID string) (uuid.UUID, uuid.UUID, string) {
+	cbID, err := uuid.Parse(disputeID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "invalid dispute_id"
+	}
+	mID, err := uuid.Parse(merchantID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "invalid merchant_id"
+	}
+	return cbID, mID, ""
+}
+
+// =========================================================================
+// List / Get
+// =========================================================================
+
+func (s *ChargebackServer) ListDisputes(ctx context.Context, req *pb.ListDisputesRequest) (*pb.ListDisputesResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListDisputesResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	listReq := &service.ListDisputesRequest{
+		MerchantID: merchantID,
+		Status:     model.ChargebackStatus(req.Status),
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+	if req.TransactionId != "" {
+		if listReq.TransactionID, err = uuid.Parse(req.TransactionId); err != nil {
+			return &pb.ListDisputesResponse{
+				Error: "invalid transaction_id",
+			}, nil
+		}
+	}
+	chargebacks, total, err := s.chargebackService.ListDisputes(listReq)
+	if err != nil {
+		logger.Log.Error("Failed to list disputes", zap.Error(err))
+		return &pb.ListDisputesResponse{
+			Error: "failed to list disputes",
+		}, nil
+	}
+
+	disputes := make([]*pb.DisputeResponse, len(chargebacks))
+	for i := range chargebacks {
+		disputes[i] = disputeToProto(&chargebacks[i], nil)
+	}
+
+	return &pb.ListDisputesResponse{
+		Disputes: disputes,
+		Total:    int32(total),
+		HasMore:  int64(listReq.Offset+len(chargebacks)) < total,
+	}, nil
+}
+
+func (s *ChargebackServer) GetDispute(ctx context.Context, req *pb.GetDisputeRequest) (*pb.DisputeResponse, error) {
+	cbID, merchantID, msg := parseDisputeIDs(req.DisputeId, req.MerchantId)
+	if msg != "" {
+		return &pb.DisputeResponse{Error: msg}, nil
+	}
+
+	chargeback, err := s.chargebackService.GetDispute(merchantID, cbID)
+	if err != nil {
+		return &pb.DisputeResponse{Error: disputeError(err)}, nil
+	}
+
+	return s.disputeWithFiles(chargeback), nil
+}
+
+// =========================================================================
+// Evidence Files
+// =========================================================================
+
+func (s *ChargebackServer) UploadDisputeEvidence(ctx context.Context, req *pb.UploadDisputeEvidenceRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	cbID, merchantID, msg := parseDisputeIDs(req.DisputeId, req.MerchantId)
+	if msg != "" {
+		return &pb.DisputeEvidenceFileResponse{Error: msg}, nil
+	}
+
+	file, err := s.chargebackService.UploadEvidenceFile(ctx, &service.UploadEvidenceFileRequest{
+		ChargebackID: cbID,
+		MerchantID:   merchantID,
+		FileName:     req.FileName,
+		ContentType:  req.ContentType,
+		Description:  req.Description,
+		Content:      req.Content,
+	})
+	if err != nil {
+		return &pb.DisputeEvidenceFileResponse{Error: disputeError(err)}, nil
+	}
+
+	return &pb.DisputeEvidenceFileResponse{
+		File: evidenceFileToProto(file),
+	}, nil
+}
+
+func (s *ChargebackServer) GetDisputeEvidenceFile(ctx context.Context, req *pb.GetDisputeEvidenceFileRequest) (*pb.DisputeEvidenceFileResponse, error) {
+	cbID, merchantID, msg := parseDisputeIDs(req.DisputeId, req.MerchantId)
+	if msg != "" {
+		return &pb.DisputeEvidenceFileResponse{Error: msg}, nil
+	}
+	fileID, err := uuid.Parse(req.FileId)
+	if err != nil {
+		return &pb.DisputeEvidenceFileResponse{
+			Error: "invalid file_id",
+		}, nil
+	}
+
+	file, err := s.chargebackService.GetEvidenceFile(ctx, merchantID, cbID, fileID)
+	if err != nil {
+		return &pb.DisputeEvidenceFileResponse{Error: disputeError(err)}, nil
+	}
+
+	return &pb.DisputeEvidenceFileResponse{
+		File:    evidenceFileToProto(file),
+		Content: file.Content,
+	}, nil
+}
+
+// =========================================================================
+// Submit Evidence / Accept
+// =========================================================================
+
+func (s *ChargebackServer) SubmitDisputeEvidence(ctx context.Context, req *pb.SubmitDisputeEvidenceRequest) (*pb.DisputeResponse, error) {
+	cbID, merchantID, msg := parseDisputeIDs(req.DisputeId, req.MerchantId)
+	if msg != "" {
+		return &pb.DisputeResponse{Error: msg}, nil
+	}
+
+	evidence := make(map[string]interface{}, len(req.Evidence))
+	for k, v := range req.Evidence {
+		evidence[k] = v
+	}
+
+	if err := s.chargebackService.SubmitEvidence(ctx, &service.SubmitEvidenceRequest{
+		ChargebackID:      cbID,
+		MerchantID:        merchantID,
+		Evidence:          evidence,
+		MerchantStatement: req.MerchantStatement,
+	}); err != nil {
+		return &pb.DisputeResponse{Error: err.Error()}, nil
+	}
+
+	return s.reloadDispute(merchantID, cbID), nil
+}
+
+func (s *ChargebackServer) AcceptDispute(ctx context.Context, req *pb.AcceptDisputeRequest) (*pb.DisputeResponse, error) {
+	cbID, merchantID, msg := parseDisputeIDs(req.DisputeId, req.MerchantId)
+	if msg != "" {
+		return &pb.DisputeResponse{Error: msg}, nil
+	}
+
+	if err := s.chargebackService.AcceptChargeback(ctx, &service.AcceptChargebackRequest{
+		ChargebackID: cbID,
+		MerchantID:   merchantID,
+		Reason:       req.Reason,
+	}); err != nil {
+		return &pb.DisputeResponse{Error: err.Error()}, nil
+	}
+
+	return s.reloadDispute(merchantID, cbID), nil
+}
+
+// =========================================================================
+// Helpers
+// =========================================================================
+
+func (s *ChargebackServer) reloadDispute(merchantID, cbID uuid.UUID) *pb.DisputeResponse {
+	chargeback, err := s.chargebackService.GetDispute(merchantID, cbID)
+	if err != nil {
+		return &pb.DisputeResponse{Error: disputeError(err)}
+	}
+	return s.disputeWithFiles(chargeback)
+}
+
+func (s *ChargebackServer) disputeWithFiles(chargeback *model.Chargeback) *pb.DisputeResponse {
+	files, err := s.chargebackService.GetEvidenceFiles(chargeback.ID)
+	if err != nil {
+		logger.Log.Error("Failed to load evidence files",
+			zap.String("chargeback_id", chargeback.ID.String()),
+			zap.Error(err),
+		)
+	}
+	return disputeToProto(chargeback, files)
+}
+
+// disputeError hides lookup details; a dispute of another merchant reads as
+// not found
+func disputeError(err error) string {
+	if errors.Is(err, service.ErrDisputeAccessDenied) {
+		return service.ErrDisputeNotFound.Error()
+	}
+	return err.Error()
+}
+
+func disputeToProto(cb *model.Chargeback, files []model.ChargebackEvidenceFile) *pb.DisputeResponse {
+	resp := &pb.DisputeResponse{
+		Id:            cb.ID.String(),
+		TransactionId: cb.TransactionID.String(),
+		MerchantId:    cb.MerchantID.String(),
+		Status:        string(cb.Status),
+		Reason:        string(cb.Reason),
+		ReasonCode:    cb.ReasonCode,
+		Amount:        cb.Amount,
+		Currency:      cb.Currency,
+		ChargebackFee: cb.ChargebackFee,
+		NetLoss:       cb.NetLoss,
+		DisputedAt:    cb.DisputedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Overdue:       cb.IsOverdue(),
+		CreatedAt:     cb.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+
+	if cb.CustomerStatement.Valid {
+		resp.CustomerStatement = cb.CustomerStatement.String
+	}
+	if cb.ResponseDueDate.Valid {
+		resp.ResponseDueAt = cb.ResponseDueDate.Time.UTC().Format("2006-01-02T15:04:05Z")
+		if cb.NeedsResponse() {
+			resp.SecondsUntilDue = int64(time.Until(cb.ResponseDueDate.Time).Seconds())
+		}
+	}
+	if cb.ResponseSubmittedAt.Valid {
+		resp.ResponseSubmittedAt = cb.ResponseSubmittedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if cb.MerchantEvidence.Valid {
+		resp.MerchantEvidence = cb.MerchantEvidence.String
+	}
+	if cb.ResolutionReason.Valid {
+		resp.ResolutionReason = cb.ResolutionReason.String
+	}
+	if cb.ResolvedAt.Valid {
+		resp.ResolvedAt = cb.ResolvedAt.Time.UTC().Format("2006-01-02T15:04:05Z")
+	}
+
+	for i := range files {
+		resp.EvidenceFiles = append(resp.EvidenceFiles, evidenceFileToProto(&files[i]))
+	}
+
+	return resp
+}
+
+func evidenceFileToProto(file *model.ChargebackEvidenceFile) *pb.DisputeEvidenceFile {
+	pbFile := &pb.DisputeEvidenceFile{
+		Id:          file.ID.String(),
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		SizeBytes:   file.SizeBytes,
+		Sha256:      file.SHA256,
+		CreatedAt:   file.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if file.Description.Valid {
+		pbFile.Description = file.Description.String
+	}
+	return pbFile
+}
@@ -0,0 +1,154 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
+	"go.uber.org/zap"
+)
+
+// =========================================================================
+// Transaction Notes & Tags
+// =========================================================================
+
+// parseNoteIDs parses the transaction and merchant IDs of a note or tag
+// request. The returned string is the error to send back, if any.
+func parseNoteIDs(transactionID, merchantID string) (uuid.UUID, uuid.UUID, string) {
+	txnID, err := uuid.Parse(transactionID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "invalid transaction_id"
+	}
+	merchantUUID, err := uuid.Parse(merchantID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "invalid merchant_id"
+	}
+	return txnID, merchantUUID, ""
+}
+
+func (s *TransactionServer) AddTransactionNote(ctx context.Context, req *pb.AddTransactionNoteRequest) (*pb.TransactionNoteResponse, error) {
+	txnID, merchantID, errMsg := parseNoteIDs(req.TransactionId, req.MerchantId)
+	if errMsg != "" {
+		return &pb.TransactionNoteResponse{Error: errMsg}, nil
+	}
+	authorID, err := uuid.Parse(req.AuthorId)
+	if err != nil {
+		return &pb.TransactionNoteResponse{Error: "invalid author_id"}, nil
+	}
+
+	note, err := s.noteService.AddNote(txnID, merchantID, authorID, req.Body)
+	if err != nil {
+		return &pb.TransactionNoteResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.TransactionNoteResponse{Note: noteToProto(note)}, nil
+}
+
+func (s *TransactionServer) ListTransactionNotes(ctx context.Context, req *pb.ListTransactionNotesRequest) (*pb.ListTransactionNotesResponse, error) {
+	txnID, merchantID, errMsg := parseNoteIDs(req.TransactionId, req.MerchantId)
+	if errMsg != "" {
+		return &pb.ListTransactionNotesResponse{Error: errMsg}, nil
+	}
+
+	notes, err := s.noteService.ListNotes(txnID, merchantID)
+	if err != nil {
+		return &pb.ListTransactionNotesResponse{Error: err.Error()}, nil
+	}
+
+	response := &pb.ListTransactionNotesResponse{
+		Notes: make([]*pb.TransactionNote, len(notes)),
+	}
+	for i := range notes {
+		response.Notes[i] = noteToProto(&notes[i])
+	}
+	return response, nil
+}
+
+func (s *TransactionServer) DeleteTransactionNote(ctx context.Context, req *pb.DeleteTransactionNoteRequest) (*pb.DeleteTransactionNoteResponse, error) {
+	txnID, merchantID, errMsg := parseNoteIDs(req.TransactionId, req.MerchantId)
+	if errMsg != "" {
+		return &pb.DeleteTransactionNoteResponse{Error: errMsg}, nil
+	}
+	noteID, err := uuid.Parse(req.NoteId)
+	if err != nil {
+		return &pb.DeleteTransactionNoteResponse{Error: "invalid note_id"}, nil
+	}
+
+	if err := s.noteService.DeleteNote(noteID, txnID, merchantID); err != nil {
+		if !errors.Is(err, service.ErrNoteNotFound) {
+			logger.Log.Error("Failed to delete transaction note",
+				zap.String("note_id", req.NoteId),
+				zap.Error(err),
+			)
+		}
+		return &pb.DeleteTransactionNoteResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.DeleteTransactionNoteResponse{Deleted: true}, nil
+}
+
+func (s *TransactionServer) AddTransactionTags(ctx context.Context, req *pb.AddTransactionTagsRequest) (*pb.TransactionTagsResponse, error) {
+	txnID, merchantID, errMsg := parseNoteIDs(req.TransactionId, req.MerchantId)
+	if errMsg != "" {
+		return &pb.TransactionTagsResponse{Error: errMsg}, nil
+	}
+	createdBy, err := uuid.Parse(req.CreatedBy)
+	if err != nil {
+		return &pb.TransactionTagsResponse{Error: "invalid created_by"}, nil
+	}
+
+	tags, err := s.noteService.AddTags(txnID, merchantID, createdBy, req.Tags)
+	if err != nil {
+		return &pb.TransactionTagsResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.TransactionTagsResponse{Tags: tags}, nil
+}
+
+func (s *TransactionServer) RemoveTransactionTag(ctx context.Context, req *pb.RemoveTransactionTagRequest) (*pb.TransactionTagsResponse, error) {
+	txnID, merchantID, errMsg := parseNoteIDs(req.TransactionId, req.MerchantId)
+	if errMsg != "" {
+		return &pb.TransactionTagsResponse{Error: errMsg}, nil
+	}
+
+	tags, err := s.noteService.RemoveTag(txnID, merchantID, req.Tag)
+	if err != nil {
+		return &pb.TransactionTagsResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.TransactionTagsResponse{Tags: tags}, nil
+}
+
+// attachAnnotations fills in the tags and note counts of transactions.
+// They are informational, so a lookup failure leaves them empty rather than
+// failing the read.
+func (s *TransactionServer) attachAnnotations(transactions []*pb.TransactionResponse, txnIDs []uuid.UUID) {
+	if len(txnIDs) == 0 {
+		return
+	}
+
+	tags, counts, err := s.noteService.Annotations(txnIDs)
+	if err != nil {
+		logger.Log.Warn("Failed to load transaction notes and tags", zap.Error(err))
+		return
+	}
+
+	for i, id := range txnIDs {
+		transactions[i].Tags = tags[id]
+		transactions[i].NoteCount = int32(counts[id])
+	}
+}
+
+func noteToProto(note *model.TransactionNote) *pb.TransactionNote {
+	return &pb.TransactionNote{
+		Id:            note.ID.String(),
+		TransactionId: note.TransactionID.String(),
+		AuthorId:      note.AuthorID.String(),
+		Body:          note.Body,
+		CreatedAt:     note.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -2,18 +2,37 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
 	pb "github.com/rhaloubi/payment-gateway/transaction-service/proto"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500 // Matches the payment-api export page size
 )
 
 type TransactionServer struct {
 	pb.UnimplementedTransactionServiceServer
 	transactionService *service.TransactionService
+	settlementService  *service.SettlementService
+	refundService      *service.RefundTrackingService
+	authService        *service.AuthenticationService
+	noteService        *service.TransactionNoteService
+	balanceService     *service.BalanceService
+	statisticsService  *service.StatisticsService
 }
 
 func NewTransactionServer() (*TransactionServer, error) {
@@ -22,8 +41,15 @@ func NewTransactionServer() (*TransactionServer, error) {
 		return nil, err
 	}
 
+	settlementService := service.NewSettlementService()
 	return &TransactionServer{
 		transactionService: txnService,
+		settlementService:  settlementService,
+		refundService:      service.NewRefundTrackingService(),
+		authService:        service.NewAuthenticationService(),
+		noteService:        service.NewTransactionNoteService(),
+		balanceService:     service.NewBalanceService(),
+		statisticsService:  service.NewStatisticsService(settlementService),
 	}, nil
 }
 
@@ -59,6 +85,9 @@ func (s *TransactionServer) Authorize(ctx context.Context, req *pb.AuthorizeRequ
 		Description:   req.Description,
 		IPAddress:     req.IpAddress,
 		UserAgent:     req.UserAgent,
+		ThreeDSECI:    req.ThreeDsEci,
+		ThreeDSCAVV:   req.ThreeDsCavv,
+		TestMode:      req.TestMode,
 	}
 
 	// Process authorization
@@ -72,18 +101,20 @@ func (s *TransactionServer) Authorize(ctx context.Context, req *pb.AuthorizeRequ
 
 	// Build gRPC response
 	return &pb.AuthorizeResponse{
-		TransactionId:   response.TransactionID.String(),
-		Status:          string(response.Status),
-		Approved:        response.Approved,
-		AuthCode:        response.AuthCode,
-		ResponseCode:    response.ResponseCode,
-		ResponseMessage: response.ResponseMessage,
-		DeclineReason:   response.DeclineReason,
-		Amount:          response.Amount,
-		AmountMad:       response.AmountMAD,
-		ExchangeRate:    response.ExchangeRate,
-		ProcessingFee:   response.ProcessingFee,
-		NetAmount:       response.NetAmount,
+		TransactionId:              response.TransactionID.String(),
+		Status:                     string(response.Status),
+		Approved:                   response.Approved,
+		AuthCode:                   response.AuthCode,
+		ResponseCode:               response.ResponseCode,
+		ResponseMessage:            response.ResponseMessage,
+		DeclineReason:              response.DeclineReason,
+		Amount:                     response.Amount,
+		AmountMad:                  response.AmountMAD,
+		ExchangeRate:               response.ExchangeRate,
+		ProcessingFee:              response.ProcessingFee,
+		NetAmount:                  response.NetAmount,
+		RetryAllowed:               response.RetryAllowed,
+		SuggestedRetryAfterSeconds: int64(response.RetryAfter / time.Second),
 	}, nil
 }
 
@@ -117,6 +148,8 @@ func (s *TransactionServer) Capture(ctx context.Context, req *pb.CaptureRequest)
 		TransactionID: txnID,
 		Amount:        req.Amount,
 		MerchantID:    merchantID,
+		FinalCapture:  req.FinalCapture,
+		Currency:      req.Currency,
 	}
 
 	// Process capture
@@ -124,15 +157,68 @@ func (s *TransactionServer) Capture(ctx context.Context, req *pb.CaptureRequest)
 	if err != nil {
 		logger.Log.Error("gRPC capture failed", zap.Error(err))
 		return &pb.CaptureResponse{
-			Error: err.Error(),
+			Error:     err.Error(),
+			ErrorCode: currencyErrorCode(err),
 		}, nil
 	}
 
 	return &pb.CaptureResponse{
-		TransactionId:   response.TransactionID.String(),
-		Status:          string(response.Status),
-		CapturedAmount:  response.CapturedAmount,
-		ResponseMessage: response.ResponseMessage,
+		TransactionId:       response.TransactionID.String(),
+		Status:              string(response.Status),
+		CapturedAmount:      response.CapturedAmount,
+		ResponseMessage:     response.ResponseMessage,
+		CaptureId:           response.CaptureID.String(),
+		TotalCapturedAmount: response.TotalCapturedAmount,
+		RemainingAmount:     response.RemainingAmount,
+	}, nil
+}
+
+// ListCaptures returns the capture history of one authorization
+func (s *TransactionServer) ListCaptures(ctx context.Context, req *pb.ListCapturesRequest) (*pb.ListCapturesResponse, error) {
+	txnID, err := uuid.Parse(req.TransactionId)
+	if err != nil {
+		return &pb.ListCapturesResponse{
+			Error: "invalid transaction_id",
+		}, nil
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListCapturesResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	txn, captures, err := s.transactionService.GetCaptures(ctx, txnID, merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to list captures", zap.Error(err))
+		return &pb.ListCapturesResponse{
+			Error: "failed to list captures",
+		}, nil
+	}
+
+	pbCaptures := make([]*pb.CaptureRecord, len(captures))
+	for i, capture := range captures {
+		pbCaptures[i] = &pb.CaptureRecord{
+			Id:           capture.ID.String(),
+			Sequence:     int32(capture.Sequence),
+			Amount:       capture.Amount,
+			Currency:     capture.Currency,
+			FinalCapture: capture.FinalCapture,
+			CreatedAt:    capture.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		}
+	}
+
+	var remaining int64
+	if txn.CanCapture() {
+		remaining = txn.RemainingCapturableAmount()
+	}
+
+	return &pb.ListCapturesResponse{
+		Captures:         pbCaptures,
+		AuthorizedAmount: txn.Amount,
+		CapturedAmount:   txn.CapturedAmount,
+		RemainingAmount:  remaining,
 	}, nil
 }
 
@@ -208,12 +294,21 @@ func (s *TransactionServer) Refund(ctx context.Context, req *pb.RefundRequest) (
 		}, nil
 	}
 
+	reasonCode := model.RefundReasonCode(req.ReasonCode)
+	if reasonCode != "" && !model.ValidRefundReasonCode(reasonCode) {
+		return &pb.RefundResponse{
+			Error: "invalid reason_code",
+		}, nil
+	}
+
 	// Build service request
 	serviceReq := &service.RefundRequest{
 		TransactionID: txnID,
 		Amount:        req.Amount,
 		Reason:        req.Reason,
+		ReasonCode:    reasonCode,
 		MerchantID:    merchantID,
+		Currency:      req.Currency,
 	}
 
 	// Process refund
@@ -221,17 +316,23 @@ func (s *TransactionServer) Refund(ctx context.Context, req *pb.RefundRequest) (
 	if err != nil {
 		logger.Log.Error("gRPC refund failed", zap.Error(err))
 		return &pb.RefundResponse{
-			Error: err.Error(),
+			Error:     err.Error(),
+			ErrorCode: currencyErrorCode(err),
 		}, nil
 	}
 
-	return &pb.RefundResponse{
+	resp := &pb.RefundResponse{
 		RefundId:        response.RefundID.String(),
 		TransactionId:   response.TransactionID.String(),
 		RefundedAmount:  response.RefundedAmount,
 		RemainingAmount: response.RemainingAmount,
 		ResponseMessage: response.ResponseMessage,
-	}, nil
+		RefundStatus:    string(response.RefundStatus),
+	}
+	if !response.EstimatedArrival.IsZero() {
+		resp.EstimatedArrivalAt = response.EstimatedArrival.Format("2006-01-02")
+	}
+	return resp, nil
 }
 
 // =========================================================================
@@ -255,14 +356,123 @@ func (s *TransactionServer) GetTransaction(ctx context.Context, req *pb.GetTrans
 	}
 
 	// Get transaction
-	txn, err := s.transactionService.GetTransaction(txnID, merchantID)
+	txn, err := s.transactionService.GetTransaction(ctx, txnID, merchantID)
 	if err != nil {
 		return &pb.TransactionResponse{
 			Error: "transaction not found",
 		}, nil
 	}
 
+	response := transactionToProto(txn)
+	s.attachAnnotations([]*pb.TransactionResponse{response}, []uuid.UUID{txn.ID})
+
+	return response, nil
+}
+
+// =========================================================================
+// ListTransactions
+// =========================================================================
+
+func (s *TransactionServer) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	// Parse merchant ID
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListTransactionsResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	filter := repository.TransactionListFilter{
+		MerchantID: merchantID,
+		Status:     model.TransactionStatus(req.Status),
+		Type:       model.TransactionType(req.Type),
+		VoidReason: model.VoidReason(req.VoidedReason),
+		NoteText:   strings.TrimSpace(req.Note),
+		TestMode:   req.TestMode,
+		SortBy:     req.SortBy,
+		SortDesc:   req.SortOrder != "asc",
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = "created_at"
+	} else if !repository.ValidTransactionSort(filter.SortBy) {
+		return &pb.ListTransactionsResponse{
+			Error: "invalid sort_by: " + req.SortBy,
+		}, nil
+	}
+	if req.SortOrder != "" && req.SortOrder != "asc" && req.SortOrder != "desc" {
+		return &pb.ListTransactionsResponse{
+			Error: "invalid sort_order: " + req.SortOrder,
+		}, nil
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	} else if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if req.CreatedFrom != "" || req.CreatedTo != "" {
+		filter.CreatedFrom, filter.CreatedTo, err = parseCreatedRange(req.CreatedFrom, req.CreatedTo)
+		if err != nil {
+			return &pb.ListTransactionsResponse{
+				Error: err.Error(),
+			}, nil
+		}
+	}
+	if req.Tag != "" {
+		filter.Tags, err = service.NormalizeTransactionTags(strings.Split(req.Tag, ","))
+		if err != nil {
+			return &pb.ListTransactionsResponse{
+				Error: err.Error(),
+			}, nil
+		}
+	}
+
+	txns, total, err := s.transactionService.ListTransactions(ctx, filter)
+	if err != nil {
+		return &pb.ListTransactionsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
 	// Build response
+	transactions := make([]*pb.TransactionResponse, len(txns))
+	txnIDs := make([]uuid.UUID, len(txns))
+	for i, txn := range txns {
+		txnIDs[i] = txn.ID
+		transactions[i] = &pb.TransactionResponse{
+			Id:             txn.ID.String(),
+			MerchantId:     txn.MerchantID.String(),
+			Type:           string(txn.Type),
+			Status:         string(txn.Status),
+			Amount:         txn.Amount,
+			Currency:       txn.Currency,
+			AmountMad:      txn.AmountMAD,
+			CardBrand:      txn.CardBrand,
+			CardLast4:      txn.CardLast4,
+			FraudScore:     int32(txn.FraudScore),
+			CapturedAmount: txn.CapturedAmount,
+			RefundedAmount: txn.RefundedAmount,
+			CreatedAt:      txn.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			VoidedReason:   string(txn.VoidReason),
+			TestMode:       txn.TestMode,
+		}
+	}
+	s.attachAnnotations(transactions, txnIDs)
+
+	return &pb.ListTransactionsResponse{
+		Transactions: transactions,
+		Total:        int32(total),
+		HasMore:      int64(filter.Offset+len(txns)) < total,
+		Limit:        int32(filter.Limit),
+		Offset:       int32(filter.Offset),
+	}, nil
+}
+
+func transactionToProto(txn *model.Transaction) *pb.TransactionResponse {
 	response := &pb.TransactionResponse{
 		Id:             txn.ID.String(),
 		MerchantId:     txn.MerchantID.String(),
@@ -280,6 +490,7 @@ func (s *TransactionServer) GetTransaction(ctx context.Context, req *pb.GetTrans
 		ProcessingFee:  txn.ProcessingFee,
 		NetAmount:      txn.NetAmount,
 		CreatedAt:      txn.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		TestMode:       txn.TestMode,
 	}
 
 	if txn.AuthCode.Valid {
@@ -291,65 +502,599 @@ func (s *TransactionServer) GetTransaction(ctx context.Context, req *pb.GetTrans
 	if txn.CapturedAt.Valid {
 		response.CapturedAt = txn.CapturedAt.Time.Format("2006-01-02T15:04:05Z")
 	}
+	if txn.VoidedAt.Valid {
+		response.VoidedAt = txn.VoidedAt.Time.Format("2006-01-02T15:04:05Z")
+		response.VoidedReason = string(txn.VoidReason)
+	}
 
-	return response, nil
+	return response
 }
 
 // =========================================================================
-// ListTransactions
+// Transaction Timeline
 // =========================================================================
 
-func (s *TransactionServer) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
-	// Parse merchant ID
+func (s *TransactionServer) GetTransactionTimeline(ctx context.Context, req *pb.GetTransactionTimelineRequest) (*pb.TransactionTimelineResponse, error) {
+	txnID, err := uuid.Parse(req.TransactionId)
+	if err != nil {
+		return &pb.TransactionTimelineResponse{
+			Error: "invalid transaction_id",
+		}, nil
+	}
+
 	merchantID, err := uuid.Parse(req.MerchantId)
 	if err != nil {
-		return &pb.ListTransactionsResponse{
+		return &pb.TransactionTimelineResponse{
 			Error: "invalid merchant_id",
 		}, nil
 	}
 
-	// Get transactions
-	var txns []model.Transaction
-	if req.Status != "" {
-		status := model.TransactionStatus(req.Status)
-		txns, err = s.transactionService.FindByStatus(merchantID, status)
-	} else {
-		limit := int(req.Limit)
-		if limit == 0 {
-			limit = 50
+	timeline, err := s.transactionService.GetTransactionTimeline(ctx, txnID, merchantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &pb.TransactionTimelineResponse{
+				Error: "transaction not found",
+			}, nil
 		}
-		offset := int(req.Offset)
-		txns, err = s.transactionService.FindByMerchant(merchantID, limit, offset)
+		logger.Log.Error("Failed to load transaction timeline", zap.Error(err))
+		return &pb.TransactionTimelineResponse{
+			Error: "failed to load transaction timeline",
+		}, nil
 	}
 
+	resp := &pb.TransactionTimelineResponse{
+		Transaction:     transactionToProto(timeline.Transaction),
+		Events:          make([]*pb.TransactionTimelineEvent, len(timeline.Events)),
+		IssuerResponses: make([]*pb.IssuerResponseRecord, len(timeline.IssuerResponses)),
+	}
+	for i, event := range timeline.Events {
+		resp.Events[i] = &pb.TransactionTimelineEvent{
+			EventType: event.EventType,
+			OldStatus: string(event.OldStatus),
+			NewStatus: string(event.NewStatus),
+			Amount:    event.Amount,
+			Metadata:  event.Metadata.String,
+			CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+		if event.CreatedBy != uuid.Nil {
+			resp.Events[i].CreatedBy = event.CreatedBy.String()
+		}
+	}
+	for i, issuer := range timeline.IssuerResponses {
+		resp.IssuerResponses[i] = &pb.IssuerResponseRecord{
+			Approved:         issuer.Approved,
+			AuthCode:         issuer.AuthCode.String,
+			ResponseCode:     issuer.ResponseCode.String,
+			ResponseMessage:  issuer.ResponseMessage.String,
+			DeclineReason:    issuer.DeclineReason.String,
+			AvsResult:        issuer.AVSResult.String,
+			CvvResult:        issuer.CVVResult.String,
+			ProcessingTimeMs: int32(issuer.ProcessingTimeMs),
+			CreatedAt:        issuer.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	if timeline.RoutingDecision != nil {
+		if decision, err := json.Marshal(timeline.RoutingDecision); err == nil {
+			resp.RoutingDecision = string(decision)
+		}
+	}
+
+	return resp, nil
+}
+
+// =========================================================================
+// Settlements
+// =========================================================================
+
+func (s *TransactionServer) GetSettlementBatch(ctx context.Context, req *pb.GetSettlementBatchRequest) (*pb.SettlementBatchResponse, error) {
+	batchID, err := uuid.Parse(req.BatchId)
 	if err != nil {
-		return &pb.ListTransactionsResponse{
+		return &pb.SettlementBatchResponse{
+			Error: "invalid batch_id",
+		}, nil
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.SettlementBatchResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	batch, err := s.settlementService.GetMerchantSettlement(batchID, merchantID)
+	if err != nil {
+		return &pb.SettlementBatchResponse{
+			Error: "settlement batch not found",
+		}, nil
+	}
+
+	return settlementBatchToProto(batch), nil
+}
+
+func (s *TransactionServer) ListSettlementBatches(ctx context.Context, req *pb.ListSettlementBatchesRequest) (*pb.ListSettlementBatchesResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListSettlementBatchesResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	from, err := time.Parse("2006-01-02", req.DateFrom)
+	if err != nil {
+		return &pb.ListSettlementBatchesResponse{
+			Error: "invalid date_from",
+		}, nil
+	}
+	to, err := time.Parse("2006-01-02", req.DateTo)
+	if err != nil {
+		return &pb.ListSettlementBatchesResponse{
+			Error: "invalid date_to",
+		}, nil
+	}
+
+	batches, err := s.settlementService.GetMerchantSettlements(merchantID, model.SettlementStatus(req.Status), from, to)
+	if err != nil {
+		return &pb.ListSettlementBatchesResponse{
 			Error: err.Error(),
 		}, nil
 	}
 
-	// Build response
-	transactions := make([]*pb.TransactionResponse, len(txns))
-	for i, txn := range txns {
-		transactions[i] = &pb.TransactionResponse{
-			Id:             txn.ID.String(),
-			MerchantId:     txn.MerchantID.String(),
-			Type:           string(txn.Type),
-			Status:         string(txn.Status),
-			Amount:         txn.Amount,
-			Currency:       txn.Currency,
-			AmountMad:      txn.AmountMAD,
-			CardBrand:      txn.CardBrand,
-			CardLast4:      txn.CardLast4,
-			FraudScore:     int32(txn.FraudScore),
-			CapturedAmount: txn.CapturedAmount,
-			RefundedAmount: txn.RefundedAmount,
-			CreatedAt:      txn.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	response := make([]*pb.SettlementBatchResponse, len(batches))
+	for i := range batches {
+		response[i] = settlementBatchToProto(&batches[i])
+	}
+
+	return &pb.ListSettlementBatchesResponse{
+		Batches: response,
+	}, nil
+}
+
+// CreateInstantPayout quotes, or with dry_run unset creates and pays, an
+// instant payout of the merchant's captured funds
+func (s *TransactionServer) CreateInstantPayout(ctx context.Context, req *pb.CreateInstantPayoutRequest) (*pb.InstantPayoutResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.InstantPayoutResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	var quote *service.InstantPayoutQuote
+	if req.DryRun {
+		quote, _, err = s.settlementService.QuoteInstantPayout(ctx, merchantID)
+	} else {
+		quote, err = s.settlementService.CreateInstantPayout(ctx, merchantID)
+	}
+	if quote == nil {
+		return &pb.InstantPayoutResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	batch := quote.Batch
+	resp := &pb.InstantPayoutResponse{
+		Eligible:         quote.Eligible,
+		Reasons:          quote.Reasons,
+		ChargebackRateBp: quote.ChargebackRateBp,
+		AvailableAmount:  batch.NetAmount + batch.PayoutFee,
+		PayoutFee:        batch.PayoutFee,
+		PayoutAmount:     batch.NetAmount,
+		TransactionCount: int32(batch.TransactionCount + batch.RefundCount),
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	} else if !req.DryRun {
+		resp.Batch = settlementBatchToProto(batch)
+	}
+	return resp, nil
+}
+
+// GetBalance returns the merchant's available balance, rolling reserve and
+// funds not yet batched
+func (s *TransactionServer) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.BalanceResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.BalanceResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	balance, err := s.balanceService.GetBalance(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to get merchant balance",
+			zap.String("merchant_id", req.MerchantId),
+			zap.Error(err),
+		)
+		return &pb.BalanceResponse{
+			Error: "failed to get balance",
+		}, nil
+	}
+
+	resp := &pb.BalanceResponse{
+		MerchantId:        balance.MerchantID.String(),
+		Currency:          "MAD",
+		Available:         balance.Available,
+		Reserve:           balance.Reserve,
+		Pending:           balance.Pending,
+		PendingCount:      int32(balance.PendingCount),
+		NextReleaseAmount: balance.NextReleaseAmount,
+	}
+	if balance.NextReleaseAt.Valid {
+		resp.NextReleaseAt = balance.NextReleaseAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp, nil
+}
+
+// GetStatistics aggregates the merchant's payments over local calendar
+// days, with daily or weekly buckets when an interval is given
+func (s *TransactionServer) GetStatistics(ctx context.Context, req *pb.GetStatisticsRequest) (*pb.StatisticsResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.StatisticsResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return &pb.StatisticsResponse{
+			Error: "invalid from",
+		}, nil
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		return &pb.StatisticsResponse{
+			Error: "invalid to",
+		}, nil
+	}
+
+	report, err := s.statisticsService.GetStatistics(merchantID, req.TestMode, from, to, req.Interval)
+	if errors.Is(err, service.ErrInvalidStatisticsRange) {
+		return &pb.StatisticsResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		logger.Log.Error("Failed to get transaction statistics",
+			zap.String("merchant_id", req.MerchantId),
+			zap.Error(err),
+		)
+		return &pb.StatisticsResponse{
+			Error: "failed to get statistics",
+		}, nil
+	}
+
+	resp := &pb.StatisticsResponse{
+		Timezone: report.Timezone,
+		Start:    report.Start.Format(time.RFC3339),
+		End:      report.End.Format(time.RFC3339),
+		Totals:   statisticsToProto(report.Totals),
+	}
+	for i := range report.Buckets {
+		bucket := &report.Buckets[i]
+		resp.Buckets = append(resp.Buckets, &pb.StatisticsBucket{
+			Date:       bucket.Date.Format("2006-01-02"),
+			Statistics: statisticsToProto(&bucket.TransactionStatistics),
+		})
+	}
+	return resp, nil
+}
+
+func statisticsToProto(stats *repository.TransactionStatistics) *pb.TransactionStatistics {
+	return &pb.TransactionStatistics{
+		TotalTransactions: stats.TotalTransactions,
+		TotalAmount:       stats.TotalAmountMAD,
+		AuthorizedAmount:  stats.AuthorizedAmount,
+		CapturedAmount:    stats.CapturedAmount,
+		RefundedAmount:    stats.RefundedAmount,
+		SettledAmount:     stats.SettledAmount,
+		SuccessRate:       stats.SuccessRate,
+		RefundRate:        stats.RefundRate,
+		AverageFraudScore: stats.AverageFraudScore,
+	}
+}
+
+func settlementBatchToProto(batch *model.SettlementBatch) *pb.SettlementBatchResponse {
+	resp := &pb.SettlementBatchResponse{
+		Id:                batch.ID.String(),
+		MerchantId:        batch.MerchantID.String(),
+		BatchDate:         batch.BatchDate.Format("2006-01-02"),
+		GrossAmount:       batch.GrossAmount,
+		RefundAmount:      batch.RefundAmount,
+		FeeAmount:         batch.FeeAmount,
+		NetAmount:         batch.NetAmount,
+		FeeReversalAmount: batch.FeeReversalAmount,
+		TransactionCount:  int32(batch.TransactionCount),
+		RefundCount:       int32(batch.RefundCount),
+		Status:            string(batch.Status),
+		SettlementDate:    batch.SettlementDate.Format("2006-01-02"),
+		InstantPayout:     batch.InstantPayout,
+		PayoutFee:         batch.PayoutFee,
+		ReserveAmount:     batch.ReserveAmount,
+		BalanceAdjustment: batch.BalanceAdjustment,
+	}
+	if batch.ReferenceNumber.Valid {
+		resp.ReferenceNumber = batch.ReferenceNumber.String
+	}
+	if batch.SettledAt.Valid {
+		resp.SettledAt = batch.SettledAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	return resp
+}
+
+// =========================================================================
+// Refund Tracking
+// =========================================================================
+
+func (s *TransactionServer) GetRefund(ctx context.Context, req *pb.GetRefundRequest) (*pb.RefundDetailResponse, error) {
+	refundID, err := uuid.Parse(req.RefundId)
+	if err != nil {
+		return &pb.RefundDetailResponse{
+			Error: "invalid refund_id",
+		}, nil
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.RefundDetailResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	refund, err := s.refundService.GetRefund(refundID, merchantID)
+	if err != nil {
+		return &pb.RefundDetailResponse{
+			Error: "refund not found",
+		}, nil
+	}
+
+	return refundToProto(refund), nil
+}
+
+func (s *TransactionServer) ListRefunds(ctx context.Context, req *pb.ListRefundsRequest) (*pb.ListRefundsResponse, error) {
+	txnID, err := uuid.Parse(req.TransactionId)
+	if err != nil {
+		return &pb.ListRefundsResponse{
+			Error: "invalid transaction_id",
+		}, nil
+	}
+
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListRefundsResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	refunds, err := s.refundService.ListRefunds(txnID, merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to list refunds", zap.Error(err))
+		return &pb.ListRefundsResponse{
+			Error: "failed to list refunds",
+		}, nil
+	}
+
+	pbRefunds := make([]*pb.RefundDetailResponse, len(refunds))
+	for i := range refunds {
+		pbRefunds[i] = refundToProto(&refunds[i])
+	}
+
+	return &pb.ListRefundsResponse{
+		Refunds: pbRefunds,
+	}, nil
+}
+
+func (s *TransactionServer) ListMerchantRefunds(ctx context.Context, req *pb.ListMerchantRefundsRequest) (*pb.ListMerchantRefundsResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.ListMerchantRefundsResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	filter := repository.RefundFilter{
+		Status:     model.RefundStatus(req.Status),
+		ReasonCode: model.RefundReasonCode(req.ReasonCode),
+		TestMode:   req.TestMode,
+		Limit:      int(req.Limit),
+		Offset:     int(req.Offset),
+	}
+	if req.TransactionId != "" {
+		txnID, err := uuid.Parse(req.TransactionId)
+		if err != nil {
+			return &pb.ListMerchantRefundsResponse{
+				Error: "invalid transaction_id",
+			}, nil
+		}
+		filter.TransactionID = &txnID
+	}
+	if req.CreatedFrom != "" {
+		t, err := time.Parse(time.RFC3339, req.CreatedFrom)
+		if err != nil {
+			return &pb.ListMerchantRefundsResponse{
+				Error: "invalid created_from",
+			}, nil
+		}
+		filter.CreatedFrom = &t
+	}
+	if req.CreatedTo != "" {
+		t, err := time.Parse(time.RFC3339, req.CreatedTo)
+		if err != nil {
+			return &pb.ListMerchantRefundsResponse{
+				Error: "invalid created_to",
+			}, nil
 		}
+		filter.CreatedTo = &t
 	}
 
-	return &pb.ListTransactionsResponse{
-		Transactions: transactions,
-		Total:        int32(len(txns)),
+	refunds, total, err := s.refundService.ListMerchantRefunds(merchantID, filter)
+	if err != nil {
+		logger.Log.Error("Failed to list merchant refunds", zap.Error(err))
+		return &pb.ListMerchantRefundsResponse{
+			Error: "failed to list refunds",
+		}, nil
+	}
+
+	pbRefunds := make([]*pb.RefundDetailResponse, len(refunds))
+	for i := range refunds {
+		pbRefunds[i] = refundToProto(&refunds[i])
+	}
+
+	return &pb.ListMerchantRefundsResponse{
+		Refunds: pbRefunds,
+		Total:   total,
+		HasMore: int64(filter.Offset+len(refunds)) < total,
 	}, nil
 }
+
+func (s *TransactionServer) GetRefundQueueStatus(ctx context.Context, req *pb.GetRefundQueueStatusRequest) (*pb.RefundQueueStatusResponse, error) {
+	merchantID, err := uuid.Parse(req.MerchantId)
+	if err != nil {
+		return &pb.RefundQueueStatusResponse{
+			Error: "invalid merchant_id",
+		}, nil
+	}
+
+	status, err := s.transactionService.GetRefundQueueStatus(merchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load refund queue status", zap.Error(err))
+		return &pb.RefundQueueStatusResponse{
+			Error: "failed to load refund queue status",
+		}, nil
+	}
+
+	resp := &pb.RefundQueueStatusResponse{
+		Queued:                status.Queued,
+		Processing:            status.Processing,
+		Sent:                  status.Sent,
+		Failed:                status.Failed,
+		EstimatedDrainSeconds: status.EstimatedDrainSeconds,
+	}
+	if status.OldestQueuedAt != nil {
+		resp.OldestQueuedAt = status.OldestQueuedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return resp, nil
+}
+
+func refundToProto(refund *model.Transaction) *pb.RefundDetailResponse {
+	resp := &pb.RefundDetailResponse{
+		RefundId:    refund.ID.String(),
+		Amount:      -refund.Amount,
+		Currency:    refund.Currency,
+		Status:      string(refund.RefundStatus),
+		ReasonCode:  string(refund.RefundReasonCode),
+		RequestedAt: refund.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+
+	// Refunds created before tracking existed had already reached the issuer
+	if refund.RefundStatus == "" {
+		resp.Status = string(model.RefundStatusSentToIssuer)
+	}
+
+	if refund.ParentTransactionID.Valid {
+		resp.TransactionId = refund.ParentTransactionID.String
+	}
+	if refund.Description.Valid {
+		resp.Reason = refund.Description.String
+	}
+	if refund.SentToIssuerAt.Valid {
+		resp.SentToIssuerAt = refund.SentToIssuerAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	if refund.RefundStatus == model.RefundStatusSettled && refund.SettledAt.Valid {
+		resp.SettledAt = refund.SettledAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+	if refund.EstimatedArrivalAt.Valid {
+		resp.EstimatedArrivalAt = refund.EstimatedArrivalAt.Time.Format("2006-01-02")
+	}
+
+	return resp
+}
+
+// =========================================================================
+// 3-D Secure
+// =========================================================================
+
+func (s *TransactionServer) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (*pb.AuthenticateResponse, error) {
+	logger.Log.Info("gRPC Authenticate called",
+		zap.String("merchant_id", req.MerchantId),
+		zap.Int64("amount", req.Amount),
+	)
+
+	resp, err := s.authService.Authenticate(ctx, &client.AuthenticateCardRequest{
+		MerchantID: req.MerchantId,
+		CardBrand:  req.CardBrand,
+		CardLast4:  req.CardLast4,
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		ReturnURL:  req.ReturnUrl,
+	})
+	if err != nil {
+		return &pb.AuthenticateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return authenticationToProto(resp), nil
+}
+
+func (s *TransactionServer) CompleteAuthentication(ctx context.Context, req *pb.CompleteAuthenticationRequest) (*pb.AuthenticateResponse, error) {
+	resp, err := s.authService.CompleteAuthentication(ctx, &client.CompleteChallengeRequest{
+		MerchantID:      req.MerchantId,
+		DSTransactionID: req.DsTransactionId,
+		CardBrand:       req.CardBrand,
+		CRes:            req.Cres,
+	})
+	if err != nil {
+		return &pb.AuthenticateResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return authenticationToProto(resp), nil
+}
+
+func authenticationToProto(resp *client.AuthenticateCardResponse) *pb.AuthenticateResponse {
+	return &pb.AuthenticateResponse{
+		TransStatus:     resp.TransStatus,
+		Eci:             resp.ECI,
+		Cavv:            resp.CAVV,
+		DsTransactionId: resp.DSTransactionID,
+		AcsUrl:          resp.ACSURL,
+		Version:         resp.Version,
+		Reason:          resp.Reason,
+	}
+}
+
+// parseCreatedRange parses an RFC3339 [from, to) window; either bound may be empty
+func parseCreatedRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from := time.Time{}
+	to := time.Now().Add(time.Minute)
+
+	if fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid created_from: %w", err)
+		}
+		from = t
+	}
+	if toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid created_to: %w", err)
+		}
+		to = t
+	}
+	return from, to, nil
+}
+
+// currencyErrorCode lets callers tell currency rejections apart from other
+// capture and refund failures
+func currencyErrorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrCurrencyRequired):
+		return "currency_required"
+	case errors.Is(err, service.ErrCurrencyMismatch):
+		return "currency_mismatch"
+	}
+	return ""
+}
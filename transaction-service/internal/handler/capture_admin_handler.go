@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// CaptureAdminHandler manages per-merchant multi-capture settings
+type CaptureAdminHandler struct {
+	captureSettings *service.CaptureSettingsService
+}
+
+func NewCaptureAdminHandler() *CaptureAdminHandler {
+	return &CaptureAdminHandler{
+		captureSettings: service.NewCaptureSettingsService(),
+	}
+}
+
+type SetCaptureSettingsRequest struct {
+	MultiCaptureEnabled *bool `json:"multi_capture_enabled" binding:"required"`
+}
+
+// GetCaptureSettings returns whether a merchant may capture an
+// authorization more than once
+// GET /admin/merchants/:merchant_id/capture-settings
+func (h *CaptureAdminHandler) GetCaptureSettings(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	settings, explicit, err := h.captureSettings.GetMerchantSettings(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load capture settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id":           merchantID,
+			"multi_capture_enabled": settings.MultiCaptureEnabled,
+			"default":               !explicit,
+		},
+	})
+}
+
+// SetCaptureSettings allows or denies multi-capture for a merchant
+// PUT /admin/merchants/:merchant_id/capture-settings
+func (h *CaptureAdminHandler) SetCaptureSettings(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	var req SetCaptureSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	settings, err := h.captureSettings.SetMerchantSettings(merchantID, *req.MultiCaptureEnabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Merchant capture settings set",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Bool("multi_capture_enabled", settings.MultiCaptureEnabled),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// DeleteCaptureSettings returns a merchant to single capture
+// DELETE /admin/merchants/:merchant_id/capture-settings
+func (h *CaptureAdminHandler) DeleteCaptureSettings(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	if err := h.captureSettings.DeleteMerchantSettings(merchantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete capture settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "capture settings reset to single capture",
+	})
+}
@@ -0,0 +1,354 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/connector"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ConnectorAdminHandler manages acquirer connector routes, smart routing
+// rules and connector costs
+type ConnectorAdminHandler struct {
+	routing *service.ConnectorRoutingService
+}
+
+func NewConnectorAdminHandler() *ConnectorAdminHandler {
+	return &ConnectorAdminHandler{
+		routing: service.NewConnectorRoutingService(connector.NewDefaultRegistry()),
+	}
+}
+
+type SetConnectorRouteRequest struct {
+	Connector string `json:"connector" binding:"required"`
+}
+
+// ListConnectors returns registered connectors and the current routes
+// GET /admin/connectors
+func (h *ConnectorAdminHandler) ListConnectors(c *gin.Context) {
+	routes, err := h.routing.ListRoutes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load connector routes",
+		})
+		return
+	}
+
+	names, defaultName := h.routing.Connectors()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"connectors": names,
+			"default":    defaultName,
+			"routes":     routes,
+		},
+	})
+}
+
+// SetMerchantRoute sends a merchant's new authorizations to a connector
+// PUT /admin/connectors/routes/:merchant_id
+func (h *ConnectorAdminHandler) SetMerchantRoute(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	var req SetConnectorRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	route, err := h.routing.SetRoute(merchantID, req.Connector)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUnknownConnector) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Merchant connector route set",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("connector", route.Connector),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    route,
+	})
+}
+
+// DeleteMerchantRoute returns a merchant to the default connector
+// DELETE /admin/connectors/routes/:merchant_id
+func (h *ConnectorAdminHandler) DeleteMerchantRoute(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	if err := h.routing.DeleteRoute(merchantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete connector route",
+		})
+		return
+	}
+
+	logger.Log.Info("Merchant connector route removed", zap.String("merchant_id", merchantID.String()))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "merchant uses the default connector",
+	})
+}
+
+// =========================================================================
+// Smart Routing
+// =========================================================================
+
+// ListRoutingRules returns every routing rule
+// GET /admin/routing/rules
+func (h *ConnectorAdminHandler) ListRoutingRules(c *gin.Context) {
+	rules, err := h.routing.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load routing rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// CreateRoutingRule adds a routing rule
+// POST /admin/routing/rules
+func (h *ConnectorAdminHandler) CreateRoutingRule(c *gin.Context) {
+	rule := model.RoutingRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+	rule.ID = uuid.Nil
+
+	if err := h.routing.CreateRule(&rule); err != nil {
+		c.JSON(routingErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Routing rule created",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("strategy", string(rule.Strategy)),
+	)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// UpdateRoutingRule replaces a routing rule
+// PUT /admin/routing/rules/:id
+func (h *ConnectorAdminHandler) UpdateRoutingRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid rule id",
+		})
+		return
+	}
+
+	rule := model.RoutingRule{Enabled: true}
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.routing.UpdateRule(id, &rule)
+	if err != nil {
+		c.JSON(routingErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// DeleteRoutingRule removes a routing rule
+// DELETE /admin/routing/rules/:id
+func (h *ConnectorAdminHandler) DeleteRoutingRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid rule id",
+		})
+		return
+	}
+
+	if err := h.routing.DeleteRule(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete routing rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "routing rule removed",
+	})
+}
+
+// ListConnectorCosts returns the configured per-connector fees
+// GET /admin/routing/costs
+func (h *ConnectorAdminHandler) ListConnectorCosts(c *gin.Context) {
+	costs, err := h.routing.ListCosts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load connector costs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    costs,
+	})
+}
+
+// SetConnectorCost sets the fee used by lowest_cost routing
+// PUT /admin/routing/costs/:connector
+func (h *ConnectorAdminHandler) SetConnectorCost(c *gin.Context) {
+	var cost model.ConnectorCost
+	if err := c.ShouldBindJSON(&cost); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+	cost.Connector = c.Param("connector")
+
+	if err := h.routing.SetCost(&cost); err != nil {
+		c.JSON(routingErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    cost,
+	})
+}
+
+// GetRoutingDecision returns how a transaction was routed
+// GET /admin/routing/decisions/:transaction_id
+func (h *ConnectorAdminHandler) GetRoutingDecision(c *gin.Context) {
+	transactionID, err := uuid.Parse(c.Param("transaction_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid transaction_id",
+		})
+		return
+	}
+
+	decision, err := h.routing.GetDecision(transactionID)
+	if err != nil {
+		c.JSON(routingErrorStatus(err), gin.H{
+			"success": false,
+			"error":   "routing decision not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    decision,
+	})
+}
+
+// ListRoutingDecisions returns a merchant's most recent routing decisions
+// GET /admin/routing/decisions?merchant_id=&limit=
+func (h *ConnectorAdminHandler) ListRoutingDecisions(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Query("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "merchant_id is required",
+		})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	decisions, err := h.routing.ListDecisions(merchantID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load routing decisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    decisions,
+	})
+}
+
+func routingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUnknownConnector), errors.Is(err, service.ErrInvalidRoutingConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLedgerEntryLimit = 50
+	maxLedgerEntryLimit     = 500
+)
+
+// LedgerAdminHandler exposes the double-entry ledger and its invariant check
+type LedgerAdminHandler struct {
+	ledgerService *service.LedgerService
+}
+
+func NewLedgerAdminHandler() *LedgerAdminHandler {
+	return &LedgerAdminHandler{
+		ledgerService: service.NewLedgerService(),
+	}
+}
+
+// GetMerchantLedger returns a merchant's ledger account balances and latest
+// journal entries
+// GET /admin/merchants/:merchant_id/ledger?limit=50
+func (h *LedgerAdminHandler) GetMerchantLedger(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	limit := defaultLedgerEntryLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLedgerEntryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "limit must be from 1 to " + strconv.Itoa(maxLedgerEntryLimit),
+			})
+			return
+		}
+	}
+
+	ledger, err := h.ledgerService.GetMerchantLedger(merchantID, limit)
+	if err != nil {
+		logger.Log.Error("Failed to load merchant ledger",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load ledger",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ledger,
+	})
+}
+
+// CheckLedger runs the ledger invariant check now, instead of waiting for
+// the nightly run
+// POST /admin/ledger/check
+func (h *LedgerAdminHandler) CheckLedger(c *gin.Context) {
+	report, err := h.ledgerService.CheckInvariants(c.Request.Context())
+	if err != nil {
+		logger.Log.Error("Ledger invariant check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "ledger invariant check failed",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Clearing files are small, but a runaway upload should not be read whole
+const maxClearingFileBytes = 32 << 20
+
+// Reconciliation reports cover the last 30 days by default, and at most a
+// quarter
+const (
+	defaultReconciliationReportDays = 30
+	maxReconciliationReportDays     = 92
+)
+
+// ReconciliationAdminHandler exposes the simulator's clearing files and the
+// reconciliation of them against our captures
+type ReconciliationAdminHandler struct {
+	reconciliationService *service.ReconciliationService
+}
+
+func NewReconciliationAdminHandler() *ReconciliationAdminHandler {
+	return &ReconciliationAdminHandler{
+		reconciliationService: service.NewReconciliationService(),
+	}
+}
+
+// DownloadClearingFile returns the CSV the simulated network clears for a
+// UTC day
+// GET /admin/simulator/clearing-files/:date
+func (h *ReconciliationAdminHandler) DownloadClearingFile(c *gin.Context) {
+	day, ok := parseClearingDate(c, c.Param("date"))
+	if !ok {
+		return
+	}
+
+	data, err := h.reconciliationService.GenerateClearingFile(c.Request.Context(), day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to generate clearing file",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=clearing-"+day.Format("2006-01-02")+".csv")
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// UploadClearingFile reconciles a clearing file sent as the CSV body
+// POST /admin/reconciliation/clearing-files?date=YYYY-MM-DD
+func (h *ReconciliationAdminHandler) UploadClearingFile(c *gin.Context) {
+	day, ok := parseClearingDate(c, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxClearingFileBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "failed to read clearing file",
+		})
+		return
+	}
+
+	file, err := h.reconciliationService.IngestClearingFile(c.Request.Context(), day, data)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, client.ErrInvalidClearingFile):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrClearingFileExists):
+			status = http.StatusConflict
+		default:
+			logger.Log.Error("Clearing file reconciliation failed", zap.Error(err))
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    file,
+	})
+}
+
+// RunReconciliation reconciles the simulator's clearing file for a day now,
+// instead of waiting for the nightly run
+// POST /admin/reconciliation/run?date=YYYY-MM-DD
+func (h *ReconciliationAdminHandler) RunReconciliation(c *gin.Context) {
+	day, ok := parseClearingDate(c, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	file, err := h.reconciliationService.ReconcileDay(c.Request.Context(), day)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrClearingFileExists) {
+			status = http.StatusConflict
+		} else {
+			logger.Log.Error("Clearing file reconciliation failed", zap.Error(err))
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    file,
+	})
+}
+
+// ListClearingFiles lists recently reconciled clearing files
+// GET /admin/reconciliation/clearing-files?limit=
+func (h *ReconciliationAdminHandler) ListClearingFiles(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if limit <= 0 || limit > 365 {
+		limit = 30
+	}
+
+	files, err := h.reconciliationService.ListClearingFiles(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load clearing files",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    files,
+	})
+}
+
+// GetClearingFile returns a clearing file and the mismatches it flagged
+// GET /admin/reconciliation/clearing-files/:id
+func (h *ReconciliationAdminHandler) GetClearingFile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid id",
+		})
+		return
+	}
+
+	file, err := h.reconciliationService.GetClearingFile(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   "clearing file not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    file,
+	})
+}
+
+// GetReconciliationReport sums up the clearing files reconciled for a range
+// of UTC days: mismatches by type, and days whose file was never reconciled
+// GET /admin/reconciliation/report?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *ReconciliationAdminHandler) GetReconciliationReport(c *gin.Context) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today.AddDate(0, 0, -1)
+	if value := c.Query("to"); value != "" {
+		var ok bool
+		if to, ok = parseClearingDate(c, value); !ok {
+			return
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultReconciliationReportDays)
+	if value := c.Query("from"); value != "" {
+		var ok bool
+		if from, ok = parseClearingDate(c, value); !ok {
+			return
+		}
+	}
+
+	if from.After(to) || to.Sub(from) >= maxReconciliationReportDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "from must not be after to, and the range must be at most " + strconv.Itoa(maxReconciliationReportDays) + " days",
+		})
+		return
+	}
+
+	report, err := h.reconciliationService.GetReport(from, to)
+	if err != nil {
+		logger.Log.Error("Failed to build reconciliation report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to build reconciliation report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+func parseClearingDate(c *gin.Context, value string) (time.Time, bool) {
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "date must be YYYY-MM-DD",
+		})
+		return time.Time{}, false
+	}
+	return day, true
+}
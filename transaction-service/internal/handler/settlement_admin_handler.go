@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// SettlementAdminHandler exposes settlement operations for other services,
+// such as the merchant offboarding workflow
+type SettlementAdminHandler struct {
+	settlementService *service.SettlementService
+}
+
+type SetSettlementTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+// UpdateSettlementScheduleRequest changes the fields that are present.
+// merchant-service sends the schedule, day and minimum payout when a
+// merchant changes them; the delay and reserve are set by operators.
+type UpdateSettlementScheduleRequest struct {
+	Schedule        *string `json:"schedule"`
+	Day             *int    `json:"day"`
+	DelayDays       *int    `json:"delay_days"`
+	MinPayoutAmount *int64  `json:"min_payout_amount"`
+	ReserveBps      *int    `json:"reserve_bps"`
+	ReserveDays     *int    `json:"reserve_days"`
+}
+
+type ReleaseSettlementRequest struct {
+	ReleasedBy string `json:"released_by" binding:"required"`
+}
+
+func NewSettlementAdminHandler() *SettlementAdminHandler {
+	return &SettlementAdminHandler{
+		settlementService: service.NewSettlementService(),
+	}
+}
+
+// CreateFinalSettlement batches a closing merchant's remaining balance
+// POST /admin/merchants/:merchant_id/final-settlement
+func (h *SettlementAdminHandler) CreateFinalSettlement(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	batch, err := h.settlementService.CreateFinalSettlementBatch(c.Request.Context(), merchantID)
+	if err != nil {
+		logger.Log.Error("Final settlement failed",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to create final settlement batch",
+		})
+		return
+	}
+
+	// A nil batch means everything was already settled
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    batch,
+	})
+}
+
+// GetSettlementTimezone returns the timezone a merchant's batch days are cut in
+// GET /admin/merchants/:merchant_id/settlement-timezone
+func (h *SettlementAdminHandler) GetSettlementTimezone(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	timezone, explicit, err := h.settlementService.GetMerchantTimezone(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load settlement timezone",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"merchant_id": merchantID,
+			"timezone":    timezone,
+			"default":     !explicit,
+		},
+	})
+}
+
+// SetSettlementTimezone sets the IANA timezone for a merchant's batch days
+// PUT /admin/merchants/:merchant_id/settlement-timezone
+func (h *SettlementAdminHandler) SetSettlementTimezone(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	var req SetSettlementTimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	tz, err := h.settlementService.SetMerchantTimezone(merchantID, req.Timezone)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidTimezone) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Merchant settlement timezone set",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("timezone", tz.Timezone),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tz,
+	})
+}
+
+// DeleteSettlementTimezone returns a merchant to SETTLEMENT_TIMEZONE
+// DELETE /admin/merchants/:merchant_id/settlement-timezone
+func (h *SettlementAdminHandler) DeleteSettlementTimezone(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	if err := h.settlementService.DeleteMerchantTimezone(merchantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete settlement timezone",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "settlement timezone reset to default",
+	})
+}
+
+// GetSettlementSchedule returns how often a merchant is paid out and on
+// what terms
+// GET /admin/merchants/:merchant_id/settlement-schedule
+func (h *SettlementAdminHandler) GetSettlementSchedule(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	schedule, explicit, err := h.settlementService.GetMerchantSchedule(merchantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load settlement schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"schedule": schedule,
+			"default":  !explicit,
+		},
+	})
+}
+
+// UpdateSettlementSchedule changes part of a merchant's settlement schedule
+// PUT /admin/merchants/:merchant_id/settlement-schedule
+func (h *SettlementAdminHandler) UpdateSettlementSchedule(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	var req UpdateSettlementScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	schedule, err := h.settlementService.UpdateMerchantSchedule(merchantID, service.SettlementScheduleUpdate{
+		Schedule:        req.Schedule,
+		Day:             req.Day,
+		DelayDays:       req.DelayDays,
+		MinPayoutAmount: req.MinPayoutAmount,
+		ReserveBps:      req.ReserveBps,
+		ReserveDays:     req.ReserveDays,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSettlementSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		logger.Log.Error("Failed to update settlement schedule",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to update settlement schedule",
+		})
+		return
+	}
+
+	logger.Log.Info("Merchant settlement schedule updated",
+		zap.String("merchant_id", merchantID.String()),
+		zap.String("schedule", schedule.Schedule),
+		zap.Int("day", schedule.Day),
+		zap.Int("delay_days", schedule.DelayDays),
+		zap.Int64("min_payout_amount", schedule.MinPayoutAmount),
+		zap.Int("reserve_bps", schedule.ReserveBps),
+		zap.Int("reserve_days", schedule.ReserveDays),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    schedule,
+	})
+}
+
+// DeleteSettlementSchedule returns a merchant to daily T+2 settlement
+// DELETE /admin/merchants/:merchant_id/settlement-schedule
+func (h *SettlementAdminHandler) DeleteSettlementSchedule(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+
+	if err := h.settlementService.DeleteMerchantSchedule(merchantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete settlement schedule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "settlement schedule reset to default",
+	})
+}
+
+// GetGuardrails returns the payout limits and anomaly threshold in force
+// GET /admin/settlements/guardrails
+func (h *SettlementAdminHandler) GetGuardrails(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.settlementService.GetGuardrails(),
+	})
+}
+
+// ListHeldSettlements lists batches a guardrail is holding
+// GET /admin/settlements/held
+func (h *SettlementAdminHandler) ListHeldSettlements(c *gin.Context) {
+	batches, err := h.settlementService.GetHeldSettlements()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load held settlements",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    batches,
+	})
+}
+
+// ReleaseSettlement approves a held batch for payout
+// POST /admin/settlements/:batch_id/release
+func (h *SettlementAdminHandler) ReleaseSettlement(c *gin.Context) {
+	batchID, err := uuid.Parse(c.Param("batch_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid batch_id",
+		})
+		return
+	}
+
+	var req ReleaseSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	batch, err := h.settlementService.ReleaseHeldSettlement(batchID, req.ReleasedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrBatchNotHeld) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Held settlement released",
+		zap.String("batch_id", batchID.String()),
+		zap.String("released_by", req.ReleasedBy),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    batch,
+	})
+}
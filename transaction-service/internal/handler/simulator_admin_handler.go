@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/service"
+	"go.uber.org/zap"
+)
+
+// SimulatorAdminHandler exposes the card simulator's fault injection
+// controls, its issuer accounts and the issuer callbacks it can send
+type SimulatorAdminHandler struct {
+	faults   *client.FaultStore
+	accounts *client.IssuerAccountStore
+	refunds  *service.RefundTrackingService
+}
+
+func NewSimulatorAdminHandler() *SimulatorAdminHandler {
+	return &SimulatorAdminHandler{
+		faults:   client.NewFaultStore(),
+		accounts: client.NewIssuerAccountStore(),
+		refunds:  service.NewRefundTrackingService(),
+	}
+}
+
+type SetFaultRequest struct {
+	client.FaultProfile
+	// TTLSeconds removes the profile automatically after the experiment
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// SetIssuerAccountRequest takes the card number in the body so it stays out
+// of access logs
+type SetIssuerAccountRequest struct {
+	CardNumber  string `json:"card_number" binding:"required,min=12,max=19,numeric"`
+	Balance     int64  `json:"balance"`
+	CreditLimit int64  `json:"credit_limit"`
+}
+
+// RequireAdminToken rejects requests without the configured admin token
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "invalid admin token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListFaults returns all active fault profiles
+// GET /admin/simulator/faults
+func (h *SimulatorAdminHandler) ListFaults(c *gin.Context) {
+	profiles, err := h.faults.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to load fault profiles",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    profiles,
+	})
+}
+
+// SetGlobalFault applies a profile to every merchant without its own
+// PUT /admin/simulator/faults/global
+func (h *SimulatorAdminHandler) SetGlobalFault(c *gin.Context) {
+	h.setFault(c, "global")
+}
+
+// SetMerchantFault applies a profile to a single merchant
+// PUT /admin/simulator/faults/merchants/:merchant_id
+func (h *SimulatorAdminHandler) SetMerchantFault(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+	h.setFault(c, merchantID.String())
+}
+
+// DeleteGlobalFault removes the global profile
+// DELETE /admin/simulator/faults/global
+func (h *SimulatorAdminHandler) DeleteGlobalFault(c *gin.Context) {
+	h.deleteFault(c, "global")
+}
+
+// DeleteMerchantFault removes a merchant's profile
+// DELETE /admin/simulator/faults/merchants/:merchant_id
+func (h *SimulatorAdminHandler) DeleteMerchantFault(c *gin.Context) {
+	merchantID, err := uuid.Parse(c.Param("merchant_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid merchant_id",
+		})
+		return
+	}
+	h.deleteFault(c, merchantID.String())
+}
+
+// ClearFaults removes every profile
+// DELETE /admin/simulator/faults
+func (h *SimulatorAdminHandler) ClearFaults(c *gin.Context) {
+	if err := h.faults.Clear(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to clear fault profiles",
+		})
+		return
+	}
+
+	logger.Log.Warn("Simulator fault profiles cleared")
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "all fault profiles removed",
+	})
+}
+
+func (h *SimulatorAdminHandler) setFault(c *gin.Context, scope string) {
+	var req SetFaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	profile := req.FaultProfile
+	if req.TTLSeconds > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.TTLSeconds) * time.Second)
+		profile.ExpiresAt = &expiresAt
+	}
+
+	if err := profile.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := h.faults.Set(c.Request.Context(), scope, &profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to save fault profile",
+		})
+		return
+	}
+
+	logger.Log.Warn("Simulator fault profile set",
+		zap.String("scope", scope),
+		zap.Float64("error_rate", profile.ErrorRate),
+		zap.String("response_code", profile.ResponseCode),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+func (h *SimulatorAdminHandler) deleteFault(c *gin.Context, scope string) {
+	if err := h.faults.Delete(c.Request.Context(), scope); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "failed to delete fault profile",
+		})
+		return
+	}
+
+	logger.Log.Warn("Simulator fault profile removed", zap.String("scope", scope))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "fault profile removed",
+	})
+}
+
+// ConfirmRefundPosted simulates the issuer's callback that a refund reached
+// the cardholder's account before its settlement batch
+// POST /admin/simulator/refunds/:refund_id/posted
+func (h *SimulatorAdminHandler) ConfirmRefundPosted(c *gin.Context) {
+	refundID, err := uuid.Parse(c.Param("refund_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid refund_id",
+		})
+		return
+	}
+
+	refund, err := h.refunds.ConfirmPosted(refundID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrRefundNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrRefundNotInFlight):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Simulated refund posting", zap.String("refund_id", refundID.String()))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"refund_id":            refund.ID,
+			"refund_status":        refund.RefundStatus,
+			"estimated_arrival_at": refund.EstimatedArrivalAt.Time.Format("2006-01-02"),
+		},
+	})
+}
+
+// SetIssuerAccount sets a test card's balance and credit limit, creating its
+// account if needed. Open holds are kept.
+// PUT /admin/simulator/accounts
+func (h *SimulatorAdminHandler) SetIssuerAccount(c *gin.Context) {
+	var req SetIssuerAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := h.accounts.Set(c.Request.Context(), req.CardNumber, req.Balance, req.CreditLimit)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, client.ErrInvalidIssuerAccount) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logger.Log.Info("Simulator issuer account set",
+		zap.String("account_id", account.ID),
+		zap.Int64("balance", account.Balance),
+		zap.Int64("credit_limit", account.CreditLimit),
+	)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// GetIssuerAccount returns a test card's balance and open holds
+// GET /admin/simulator/accounts/:account_id
+func (h *SimulatorAdminHandler) GetIssuerAccount(c *gin.Context) {
+	account, err := h.accounts.Get(c.Request.Context(), c.Param("account_id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, client.ErrIssuerAccountNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+	})
+}
+
+// DeleteIssuerAccount drops a test card's account and holds, so its next
+// authorization starts from the default balance
+// DELETE /admin/simulator/accounts/:account_id
+func (h *SimulatorAdminHandler) DeleteIssuerAccount(c *gin.Context) {
+	if err := h.accounts.Delete(c.Request.Context(), c.Param("account_id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, client.ErrIssuerAccountNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "issuer account removed",
+	})
+}
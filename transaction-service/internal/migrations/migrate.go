@@ -24,6 +24,23 @@ func RunMigrations() error {
 		&model.Chargeback{},
 		&model.SettlementBatch{},
 		&model.IssuerResponse{},
+		&model.MerchantConnectorRoute{},
+		&model.RoutingRule{},
+		&model.ConnectorCost{},
+		&model.RoutingDecision{},
+		&model.MerchantSettlementTimezone{},
+		&model.MerchantSettlementSchedule{},
+		&model.MerchantBalanceEntry{},
+		&model.JournalEntry{},
+		&model.JournalLine{},
+		&model.ClearingFile{},
+		&model.ReconciliationMismatch{},
+		&model.TransactionCapture{},
+		&model.MerchantCaptureSettings{},
+		&model.ChargebackEvidenceFile{},
+		&model.TransactionNote{},
+		&model.TransactionTag{},
+		&model.OutboxEvent{},
 	}
 
 	for _, m := range models {
@@ -47,6 +64,23 @@ func RollbackMigrations() error {
 		&model.Chargeback{},
 		&model.SettlementBatch{},
 		&model.IssuerResponse{},
+		&model.MerchantConnectorRoute{},
+		&model.RoutingRule{},
+		&model.ConnectorCost{},
+		&model.RoutingDecision{},
+		&model.MerchantSettlementTimezone{},
+		&model.MerchantSettlementSchedule{},
+		&model.MerchantBalanceEntry{},
+		&model.JournalEntry{},
+		&model.JournalLine{},
+		&model.ClearingFile{},
+		&model.ReconciliationMismatch{},
+		&model.TransactionCapture{},
+		&model.MerchantCaptureSettings{},
+		&model.ChargebackEvidenceFile{},
+		&model.TransactionNote{},
+		&model.TransactionTag{},
+		&model.OutboxEvent{},
 	}
 
 	for _, m := range models {
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantConnectorRoute pins a merchant's new authorizations to an acquirer
+// connector. Merchants without a row use the registry default.
+type MerchantConnectorRoute struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Connector  string    `gorm:"type:varchar(50);not null" json:"connector"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MerchantConnectorRoute) TableName() string {
+	return "merchant_connector_routes"
+}
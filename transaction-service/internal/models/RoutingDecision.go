@@ -0,0 +1,42 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoutingDecision records how an authorization was routed, for analysis of
+// acquirer performance and cost
+type RoutingDecision struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TransactionID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"transaction_id"`
+	MerchantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	RuleID        *uuid.UUID     `gorm:"type:uuid;index" json:"rule_id,omitempty"`
+	Source        string         `gorm:"type:varchar(30);not null" json:"source"` // merchant_rule, merchant_route, global_rule, default
+	Strategy      string         `gorm:"type:varchar(20)" json:"strategy"`
+	Currency      string         `gorm:"type:varchar(3)" json:"currency"`
+	CardBrand     string         `gorm:"type:varchar(50)" json:"card_brand"`
+	BINCountry    string         `gorm:"type:varchar(2)" json:"bin_country"`
+	Candidates    string         `gorm:"type:text" json:"candidates"` // Comma-separated, in attempt order
+	Selected      string         `gorm:"type:varchar(50);index" json:"selected"`
+	FailedOver    bool           `gorm:"not null;default:false;index" json:"failed_over"`
+	Attempts      sql.NullString `gorm:"type:jsonb" json:"attempts,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (RoutingDecision) TableName() string {
+	return "routing_decisions"
+}
+
+// RoutingAttempt is one connector call within a decision
+type RoutingAttempt struct {
+	Connector    string `json:"connector"`
+	Approved     bool   `json:"approved"`
+	ResponseCode string `json:"response_code,omitempty"`
+	Error        string `json:"error,omitempty"`
+	SoftFailure  bool   `json:"soft_failure"`
+	LatencyMs    int64  `json:"latency_ms"`
+}
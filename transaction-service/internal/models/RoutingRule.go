@@ -0,0 +1,80 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoutingStrategy decides how a matching rule picks connectors
+type RoutingStrategy string
+
+const (
+	// RoutingStrategyFixed uses the rule's connector, then its fallback
+	RoutingStrategyFixed RoutingStrategy = "fixed"
+	// RoutingStrategyLowestCost orders every registered connector by estimated fee
+	RoutingStrategyLowestCost RoutingStrategy = "lowest_cost"
+)
+
+// RoutingRule selects acquirer connectors for authorizations matching its
+// criteria. Empty criteria match anything. Rules without a merchant apply
+// platform-wide; merchant rules are evaluated first, each group by priority.
+type RoutingRule struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	MerchantID *uuid.UUID `gorm:"type:uuid;index" json:"merchant_id,omitempty"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Priority   int        `gorm:"not null;default:100" json:"priority"` // Lower runs first
+	Enabled    bool       `gorm:"not null" json:"enabled"`
+
+	// Match criteria
+	Currency   string `gorm:"type:varchar(3)" json:"currency,omitempty"`
+	CardBrand  string `gorm:"type:varchar(50)" json:"card_brand,omitempty"`
+	BINCountry string `gorm:"type:varchar(2)" json:"bin_country,omitempty"`
+
+	// Target
+	Strategy          RoutingStrategy `gorm:"type:varchar(20);not null;default:'fixed'" json:"strategy"`
+	Connector         string          `gorm:"type:varchar(50)" json:"connector,omitempty"`
+	FallbackConnector string          `gorm:"type:varchar(50)" json:"fallback_connector,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (RoutingRule) TableName() string {
+	return "routing_rules"
+}
+
+// Matches reports whether the rule applies to an authorization
+func (r *RoutingRule) Matches(currency, cardBrand, binCountry string) bool {
+	if r.Currency != "" && !strings.EqualFold(r.Currency, currency) {
+		return false
+	}
+	if r.CardBrand != "" && !strings.EqualFold(r.CardBrand, cardBrand) {
+		return false
+	}
+	if r.BINCountry != "" && !strings.EqualFold(r.BINCountry, binCountry) {
+		return false
+	}
+	return true
+}
+
+// ConnectorCost is the fee an acquirer charges per authorization, used by
+// the lowest_cost strategy
+type ConnectorCost struct {
+	Connector   string    `gorm:"type:varchar(50);primaryKey" json:"connector"`
+	PercentBps  int64     `gorm:"not null;default:0" json:"percent_bps"`   // 1 bps = 0.01%
+	FixedFeeMAD int64     `gorm:"not null;default:0" json:"fixed_fee_mad"` // In cents
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ConnectorCost) TableName() string {
+	return "connector_costs"
+}
+
+// Estimate returns the expected fee for an amount in MAD cents
+func (c *ConnectorCost) Estimate(amountMAD int64) int64 {
+	return amountMAD*c.PercentBps/10000 + c.FixedFeeMAD
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BalanceEntryType says what moved money on a merchant's balance
+type BalanceEntryType string
+
+const (
+	BalanceEntryCapture            BalanceEntryType = "capture"             // Gross captures in a settlement batch
+	BalanceEntryRefund             BalanceEntryType = "refund"              // Refunds in a settlement batch
+	BalanceEntryFee                BalanceEntryType = "fee"                 // Processing, payout and chargeback fees, less fees given back on refunds
+	BalanceEntryChargeback         BalanceEntryType = "chargeback"          // Disputed amount, taken when the dispute opens
+	BalanceEntryChargebackReversal BalanceEntryType = "chargeback_reversal" // Given back when the merchant wins
+	BalanceEntryReserveHold        BalanceEntryType = "reserve_hold"        // Moved from available to the rolling reserve
+	BalanceEntryReserveRelease     BalanceEntryType = "reserve_release"     // Moved back once the hold period is over
+	BalanceEntryPayout             BalanceEntryType = "payout"              // Paid out in a settlement batch
+)
+
+// MerchantBalanceEntry is one movement on a merchant's balance, in MAD minor
+// units. Entries are only ever added; a merchant's balance is the sum of
+// them. Available is what can be paid out and may go negative when
+// refunds and chargebacks outrun captures. Reserve is held back for a time
+// and never negative.
+type MerchantBalanceEntry struct {
+	ID         uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	MerchantID uuid.UUID        `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Type       BalanceEntryType `gorm:"type:varchar(30);not null;index" json:"type"`
+	Available  int64            `gorm:"not null" json:"available"` // Change to the available balance
+	Reserve    int64            `gorm:"not null" json:"reserve"`   // Change to the reserve balance
+
+	SettlementBatchID sql.NullString `gorm:"type:uuid;index" json:"settlement_batch_id,omitempty"`
+	ChargebackID      sql.NullString `gorm:"type:uuid;index" json:"chargeback_id,omitempty"`
+
+	// Reserve holds are released at ReleaseAt by a reserve_release entry
+	// pointing back at them with HoldEntryID
+	ReleaseAt   sql.NullTime   `gorm:"index" json:"release_at,omitempty"`
+	HoldEntryID sql.NullString `gorm:"type:uuid;uniqueIndex" json:"hold_entry_id,omitempty"`
+
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (MerchantBalanceEntry) TableName() string {
+	return "merchant_balance_entries"
+}
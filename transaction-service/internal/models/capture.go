@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CaptureStatus tracks a capture through the acquirer
+type CaptureStatus string
+
+const (
+	CaptureStatusPending   CaptureStatus = "pending"   // Reserved, waiting on the acquirer
+	CaptureStatusSucceeded CaptureStatus = "succeeded" // Accepted by the acquirer
+)
+
+// TransactionCapture is one capture taken against an authorization. A
+// single-capture authorization has exactly one; merchants with multi-capture
+// enabled can take several until the authorized amount is used up or a
+// capture is marked final.
+type TransactionCapture struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TransactionID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_transaction_capture_sequence" json:"transaction_id"`
+	MerchantID    uuid.UUID     `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Sequence      int           `gorm:"not null;uniqueIndex:idx_transaction_capture_sequence" json:"sequence"` // 1 for the first capture
+	Amount        int64         `gorm:"not null" json:"amount"`                                                // In the authorization's currency
+	Currency      string        `gorm:"type:varchar(3);not null" json:"currency"`
+	FinalCapture  bool          `gorm:"default:false" json:"final_capture"` // The uncaptured remainder was released
+	Status        CaptureStatus `gorm:"type:varchar(20);not null;default:'succeeded'" json:"status"`
+	CreatedAt     time.Time     `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (TransactionCapture) TableName() string {
+	return "transaction_captures"
+}
+
+// MerchantCaptureSettings lets a merchant take more than one capture per
+// authorization. Merchants without a row capture once, and that capture
+// closes the authorization.
+type MerchantCaptureSettings struct {
+	MerchantID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	MultiCaptureEnabled bool      `gorm:"not null;default:false" json:"multi_capture_enabled"`
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MerchantCaptureSettings) TableName() string {
+	return "merchant_capture_settings"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChargebackEvidenceFile is a document a merchant uploaded to contest a
+// chargeback (receipt, proof of delivery, correspondence). The content is
+// kept with the dispute so it can be forwarded to the network as submitted:
+// in Content, or in the storage backend under StorageKey when one is set up.
+type ChargebackEvidenceFile struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ChargebackID uuid.UUID      `gorm:"type:uuid;not null;index" json:"chargeback_id"`
+	MerchantID   uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	FileName     string         `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType  string         `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes    int64          `gorm:"not null" json:"size_bytes"`
+	SHA256       string         `gorm:"type:char(64);not null" json:"sha256"`
+	Description  sql.NullString `gorm:"type:text" json:"description,omitempty"`
+	Content      []byte         `gorm:"type:bytea;not null" json:"-"`
+	StorageKey   string         `gorm:"type:varchar(255)" json:"-"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (ChargebackEvidenceFile) TableName() string {
+	return "chargeback_evidence_files"
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccount is an account in the double-entry ledger. Every account is
+// kept per merchant.
+type LedgerAccount string
+
+const (
+	LedgerAccountClearing        LedgerAccount = "clearing"         // Funds with the acquirer: captures in, refunds, payouts and lost disputes out
+	LedgerAccountMerchantPayable LedgerAccount = "merchant_payable" // Owed to the merchant
+	LedgerAccountFees            LedgerAccount = "fees"             // Processing, payout and chargeback fees earned
+	LedgerAccountReserves        LedgerAccount = "reserves"         // Rolling reserve held back from payouts
+	LedgerAccountChargebacks     LedgerAccount = "chargebacks"      // Disputed funds held until the dispute is decided
+)
+
+// JournalEntryType is the money movement a journal entry records
+type JournalEntryType string
+
+const (
+	JournalEntryCapture        JournalEntryType = "capture"
+	JournalEntryRefund         JournalEntryType = "refund"
+	JournalEntrySettlement     JournalEntryType = "settlement" // Payout, reserve held and instant payout fee of a batch
+	JournalEntryReserveRelease JournalEntryType = "reserve_release"
+	JournalEntryChargeback     JournalEntryType = "chargeback" // Dispute opened, with its fee
+	JournalEntryChargebackWon  JournalEntryType = "chargeback_won"
+	JournalEntryChargebackLost JournalEntryType = "chargeback_lost" // Lost, accepted or expired
+)
+
+// JournalEntry records one money movement as lines that add up to zero.
+// Entries are never changed or deleted; a mistake is corrected by a new
+// entry. Reference is unique per movement, so posting the same movement
+// twice records it once.
+type JournalEntry struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	MerchantID  uuid.UUID        `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Type        JournalEntryType `gorm:"type:varchar(30);not null;index" json:"type"`
+	Reference   string           `gorm:"type:varchar(100);not null;uniqueIndex" json:"reference"` // e.g. capture:<capture id>
+	SourceID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"source_id"`               // Capture, refund, batch, reserve hold or chargeback
+	Description string           `gorm:"type:text" json:"description,omitempty"`
+	OccurredAt  time.Time        `gorm:"not null;index" json:"occurred_at"` // When the money moved
+	CreatedAt   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+
+	Lines []JournalLine `gorm:"foreignKey:EntryID" json:"lines"`
+}
+
+// TableName specifies the table name
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// JournalLine moves an amount on one account, in MAD minor units. Debits
+// are positive and credits negative, so an account's balance is the sum of
+// its lines: positive for clearing, negative for the accounts that hold
+// what is owed to others or earned.
+type JournalLine struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	EntryID    uuid.UUID     `gorm:"type:uuid;not null;index" json:"entry_id"`
+	MerchantID uuid.UUID     `gorm:"type:uuid;not null;index:idx_journal_lines_merchant_account" json:"merchant_id"`
+	Account    LedgerAccount `gorm:"type:varchar(30);not null;index:idx_journal_lines_merchant_account" json:"account"`
+	Amount     int64         `gorm:"not null" json:"amount"`
+}
+
+// TableName specifies the table name
+func (JournalLine) TableName() string {
+	return "journal_lines"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a lifecycle event waiting to be published to the event
+// broker. It is written in the same database transaction as the event row
+// it mirrors, and the outbox relay publishes it at least once; consumers
+// deduplicate on ID.
+type OutboxEvent struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	EventType     string         `gorm:"type:varchar(60);not null" json:"event_type"`     // e.g. transaction.captured, chargeback.created
+	AggregateType string         `gorm:"type:varchar(30);not null" json:"aggregate_type"` // transaction, chargeback
+	AggregateID   uuid.UUID      `gorm:"type:uuid;not null" json:"aggregate_id"`
+	MerchantID    uuid.UUID      `gorm:"type:uuid;index" json:"merchant_id"`
+	Payload       string         `gorm:"type:jsonb;not null" json:"payload"` // The message as published
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	LastError     sql.NullString `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time      `gorm:"not null;index" json:"next_attempt_at"`
+	PublishedAt   sql.NullTime   `gorm:"index" json:"published_at,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClearingFileStatus is the outcome of reconciling a clearing file
+type ClearingFileStatus string
+
+const (
+	ClearingFileReconciled ClearingFileStatus = "reconciled" // Every record matched
+	ClearingFileMismatched ClearingFileStatus = "mismatched" // At least one mismatch was flagged
+)
+
+// ReconciliationMismatchType says how a clearing record and our books disagree
+type ReconciliationMismatchType string
+
+const (
+	MismatchUnknownTransaction ReconciliationMismatchType = "unknown_transaction"   // Cleared, but no such transaction here
+	MismatchNotCaptured        ReconciliationMismatchType = "not_captured"          // Cleared, but not captured here
+	MismatchAmount             ReconciliationMismatchType = "amount_mismatch"       // Cleared amount differs from captured amount
+	MismatchCurrency           ReconciliationMismatchType = "currency_mismatch"     // Cleared in another currency
+	MismatchDuplicateClearing  ReconciliationMismatchType = "duplicate_clearing"    // Cleared more than once
+	MismatchMissingFromFile    ReconciliationMismatchType = "missing_from_clearing" // Captured or refunded here, but never cleared
+	MismatchOrphanRefund       ReconciliationMismatchType = "orphan_refund"         // Refunded at the network, but no refund was sent here
+)
+
+// Reconciliation status of a settlement batch
+const (
+	BatchReconciliationMatched    = "matched"
+	BatchReconciliationMismatched = "mismatched"
+)
+
+// ClearingFile is a daily clearing file received from the card network
+type ClearingFile struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Connector     string             `gorm:"type:varchar(50);not null;uniqueIndex:idx_clearing_file_day" json:"connector"`
+	FileDate      time.Time          `gorm:"type:date;not null;uniqueIndex:idx_clearing_file_day" json:"file_date"` // UTC day the captures happened
+	RecordCount   int                `gorm:"not null" json:"record_count"`
+	MatchedCount  int                `gorm:"not null" json:"matched_count"`
+	MismatchCount int                `gorm:"not null" json:"mismatch_count"`
+	Status        ClearingFileStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	IngestedAt    time.Time          `gorm:"autoCreateTime" json:"ingested_at"`
+
+	Mismatches []ReconciliationMismatch `gorm:"foreignKey:ClearingFileID" json:"mismatches,omitempty"`
+}
+
+// TableName specifies the table name
+func (ClearingFile) TableName() string {
+	return "clearing_files"
+}
+
+// ReconciliationMismatch is a disagreement between a clearing file and our
+// transactions, left for an operator to investigate. Amounts are in minor
+// units of Currency.
+type ReconciliationMismatch struct {
+	ID                uuid.UUID                  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ClearingFileID    uuid.UUID                  `gorm:"type:uuid;not null;index" json:"clearing_file_id"`
+	TransactionID     uuid.UUID                  `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	MerchantID        uuid.UUID                  `gorm:"type:uuid;index" json:"merchant_id"`
+	SettlementBatchID sql.NullString             `gorm:"type:uuid;index" json:"settlement_batch_id,omitempty"`
+	Type              ReconciliationMismatchType `gorm:"type:varchar(30);not null;index" json:"type"`
+	ExpectedAmount    int64                      `gorm:"not null;default:0" json:"expected_amount"` // Captured or refunded here
+	ClearedAmount     int64                      `gorm:"not null;default:0" json:"cleared_amount"`  // In the clearing file
+	Currency          string                     `gorm:"type:varchar(3)" json:"currency"`
+	Detail            string                     `gorm:"type:text" json:"detail"`
+	CreatedAt         time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (ReconciliationMismatch) TableName() string {
+	return "reconciliation_mismatches"
+}
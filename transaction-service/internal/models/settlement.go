@@ -15,19 +15,50 @@ const (
 	SettlementStatusProcessing SettlementStatus = "processing"
 	SettlementStatusSettled   SettlementStatus = "settled"
 	SettlementStatusFailed    SettlementStatus = "failed"
+	SettlementStatusHeld      SettlementStatus = "held" // Stopped by a guardrail until an operator releases it
 )
 
-// SettlementBatch represents a daily settlement batch
+// SettlementHoldReason says which guardrail held a batch
+type SettlementHoldReason string
+
+const (
+	SettlementHoldBatchLimit    SettlementHoldReason = "batch_limit"    // Net amount above the per-settlement maximum
+	SettlementHoldDailyLimit    SettlementHoldReason = "daily_limit"    // Would push today's payouts above the daily maximum
+	SettlementHoldVolumeAnomaly SettlementHoldReason = "volume_anomaly" // Gross volume far above the merchant's 30-day baseline
+)
+
+// SettlementBatch represents a settlement batch covering one schedule period
 type SettlementBatch struct {
 	ID                uuid.UUID        `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	MerchantID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"merchant_id"`
-	BatchDate         time.Time        `gorm:"type:date;not null;index" json:"batch_date"`
+	BatchDate         time.Time        `gorm:"type:date;not null;index" json:"batch_date"` // Last calendar day of the period in Timezone
+
+	// Period boundaries in UTC; PeriodEnd is exclusive
+	Timezone          string           `gorm:"type:varchar(64)" json:"timezone"`
+	PeriodStart       time.Time        `json:"period_start"`
+	PeriodEnd         time.Time        `json:"period_end"`
 	
 	// Amounts (all in MAD after conversion)
 	GrossAmount       int64            `gorm:"not null" json:"gross_amount"`       // Total captures
 	RefundAmount      int64            `gorm:"default:0" json:"refund_amount"`     // Total refunds
 	FeeAmount         int64            `gorm:"not null" json:"fee_amount"`         // Processing fees
+	FeeReversalAmount int64            `gorm:"default:0" json:"fee_reversal_amount"` // Fees given back on refunds
 	NetAmount         int64            `gorm:"not null" json:"net_amount"`         // Amount to merchant
+
+	// Instant payouts are paid out the day they are requested for a fee,
+	// which is taken from the net amount
+	InstantPayout     bool             `gorm:"default:false;index" json:"instant_payout"`
+	PayoutFee         int64            `gorm:"default:0" json:"payout_fee"`
+
+	// Reserve held back from the net amount under the merchant's
+	// settlement schedule
+	ReserveAmount     int64            `gorm:"default:0" json:"reserve_amount"`
+
+	// What the merchant's balance changed the payout by: negative when it
+	// recovered refunds or disputes the merchant owed, positive when it paid
+	// out released reserve or carried a shortfall onto the balance rather
+	// than paying out less than zero. NetAmount already includes it.
+	BalanceAdjustment int64            `gorm:"default:0" json:"balance_adjustment"`
 	
 	// Transaction Counts
 	TransactionCount  int              `gorm:"not null" json:"transaction_count"`
@@ -38,7 +69,7 @@ type SettlementBatch struct {
 	
 	// Settlement Details
 	Status            SettlementStatus `gorm:"type:varchar(20);not null" json:"status"`
-	SettlementDate    time.Time        `gorm:"type:date" json:"settlement_date"` // T+2
+	SettlementDate    time.Time        `gorm:"type:date" json:"settlement_date"` // Period end plus the schedule's delay (T+N)
 	SettlementMethod  string           `gorm:"type:varchar(50)" json:"settlement_method"` // bank_transfer, ach, wire
 	
 	// Bank Information (from merchant settings)
@@ -48,6 +79,18 @@ type SettlementBatch struct {
 	// Report & Reference
 	ReportURL         sql.NullString   `gorm:"type:text" json:"report_url,omitempty"`
 	ReferenceNumber   sql.NullString   `gorm:"type:varchar(100)" json:"reference_number,omitempty"`
+
+	// Guardrail Hold
+	HoldReason        sql.NullString   `gorm:"type:varchar(30)" json:"hold_reason,omitempty"`
+	HoldDetail        sql.NullString   `gorm:"type:text" json:"hold_detail,omitempty"`
+	HeldAt            sql.NullTime     `json:"held_at,omitempty"`
+	ReleasedAt        sql.NullTime     `json:"released_at,omitempty"` // Operator approval; guardrails are not re-checked
+	ReleasedBy        sql.NullString   `gorm:"type:varchar(100)" json:"released_by,omitempty"`
+
+	// Clearing Reconciliation: matched or mismatched once every capture in
+	// the batch has been through a clearing file
+	ReconciliationStatus sql.NullString `gorm:"type:varchar(20);index" json:"reconciliation_status,omitempty"`
+	ReconciledAt         sql.NullTime   `json:"reconciled_at,omitempty"`
 	
 	// Timestamps
 	CreatedAt         time.Time        `gorm:"autoCreateTime" json:"created_at"`
@@ -65,7 +108,53 @@ func (s *SettlementBatch) IsSettled() bool {
 	return s.Status == SettlementStatusSettled
 }
 
+// IsHeld checks if a guardrail is holding the batch
+func (s *SettlementBatch) IsHeld() bool {
+	return s.Status == SettlementStatusHeld
+}
+
 // IsPending checks if batch is pending
 func (s *SettlementBatch) IsPending() bool {
 	return s.Status == SettlementStatusPending
 }
+
+// MerchantSettlementTimezone sets the timezone a merchant's settlement days
+// are cut in. Merchants without a row use SETTLEMENT_TIMEZONE.
+type MerchantSettlementTimezone struct {
+	MerchantID uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Timezone   string    `gorm:"type:varchar(64);not null" json:"timezone"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MerchantSettlementTimezone) TableName() string {
+	return "merchant_settlement_timezones"
+}
+
+// Settlement schedules
+const (
+	SettlementScheduleDaily   = "daily"
+	SettlementScheduleWeekly  = "weekly"
+	SettlementScheduleMonthly = "monthly"
+)
+
+// MerchantSettlementSchedule sets how often a merchant is paid out and on
+// what terms. Merchants without a row are batched daily and paid at T+2
+// with no minimum and no reserve.
+type MerchantSettlementSchedule struct {
+	MerchantID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"merchant_id"`
+	Schedule        string    `gorm:"type:varchar(20);not null" json:"schedule"` // daily, weekly, monthly
+	Day             int       `gorm:"not null" json:"day"`                       // Day the period ends on: ISO weekday 1-7 for weekly, 1-28 for monthly; 0 is Sunday or month end
+	DelayDays       int       `gorm:"not null" json:"delay_days"`                // Days from the end of the period to payout (T+N)
+	MinPayoutAmount int64     `gorm:"not null" json:"min_payout_amount"`         // MAD minor units; smaller periods roll into the next one
+	ReserveBps      int       `gorm:"not null" json:"reserve_bps"`               // Share of gross volume held back, in basis points
+	ReserveDays     int       `gorm:"not null;default:90" json:"reserve_days"`   // Days after the settlement date the reserve is released
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MerchantSettlementSchedule) TableName() string {
+	return "merchant_settlement_schedules"
+}
@@ -24,6 +24,7 @@ type TransactionStatus string
 const (
 	TransactionStatusPending           TransactionStatus = "pending"
 	TransactionStatusAuthorized        TransactionStatus = "authorized"
+	TransactionStatusPartiallyCaptured TransactionStatus = "partially_captured" // Multi-capture: more can still be captured
 	TransactionStatusCaptured          TransactionStatus = "captured"
 	TransactionStatusVoided            TransactionStatus = "voided"
 	TransactionStatusSettled           TransactionStatus = "settled"
@@ -32,11 +33,55 @@ const (
 	TransactionStatusFailed            TransactionStatus = "failed"
 )
 
+// RefundStatus tracks a refund transaction until the funds reach the cardholder
+type RefundStatus string
+
+const (
+	RefundStatusQueued       RefundStatus = "queued" // Waiting for the merchant's refund rate limit
+	RefundStatusRequested    RefundStatus = "requested"
+	RefundStatusSentToIssuer RefundStatus = "sent_to_issuer"
+	RefundStatusSettled      RefundStatus = "settled"
+	RefundStatusFailed       RefundStatus = "failed"
+)
+
+// RefundReasonCode classifies why a refund was issued, for reconciliation
+// and reporting. The free-text reason stays in Description.
+type RefundReasonCode string
+
+const (
+	RefundReasonDuplicate           RefundReasonCode = "duplicate"
+	RefundReasonFraudulent          RefundReasonCode = "fraudulent"
+	RefundReasonRequestedByCustomer RefundReasonCode = "requested_by_customer"
+	RefundReasonProductNotReceived  RefundReasonCode = "product_not_received"
+	RefundReasonProductUnacceptable RefundReasonCode = "product_unacceptable"
+	RefundReasonOther               RefundReasonCode = "other"
+)
+
+// ValidRefundReasonCode reports whether code is a known reason code
+func ValidRefundReasonCode(code RefundReasonCode) bool {
+	switch code {
+	case RefundReasonDuplicate, RefundReasonFraudulent, RefundReasonRequestedByCustomer,
+		RefundReasonProductNotReceived, RefundReasonProductUnacceptable, RefundReasonOther:
+		return true
+	}
+	return false
+}
+
+// VoidReason records why an authorization was voided
+type VoidReason string
+
+const (
+	VoidReasonRequested VoidReason = "requested" // Voided by the merchant
+	VoidReasonExpired   VoidReason = "expired"   // Auto-voided after 7 days uncaptured
+)
+
 // Transaction represents a payment transaction
 type Transaction struct {
 	ID                  uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	MerchantID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"merchant_id"`
-	ParentTransactionID sql.NullString `gorm:"type:uuid;index" json:"parent_transaction_id,omitempty"` // For refunds
+	Region              string         `gorm:"type:varchar(32);not null;default:'primary';index" json:"region"` // Region that processed it
+	TestMode            bool           `gorm:"not null;default:false;index" json:"test_mode"`                   // Made with a sandbox key; never settled
+	ParentTransactionID sql.NullString `gorm:"type:uuid;index" json:"parent_transaction_id,omitempty"`          // For refunds
 
 	// Transaction Details
 	Type         TransactionType   `gorm:"type:varchar(20);not null" json:"type"`
@@ -58,6 +103,9 @@ type Transaction struct {
 	AVSResult       sql.NullString `gorm:"type:varchar(1)" json:"avs_result,omitempty"` // Address Verification
 	CVVResult       sql.NullString `gorm:"type:varchar(1)" json:"cvv_result,omitempty"` // CVV Check
 
+	// Acquirer that authorized the transaction; follow-up operations use it too
+	Connector string `gorm:"type:varchar(50);index" json:"connector"`
+
 	// Fraud Information
 	FraudScore    int    `gorm:"default:0" json:"fraud_score"`
 	FraudDecision string `gorm:"type:varchar(20)" json:"fraud_decision"` // approve, review, decline
@@ -66,12 +114,23 @@ type Transaction struct {
 	CapturedAmount int64 `gorm:"default:0" json:"captured_amount"`
 	RefundedAmount int64 `gorm:"default:0" json:"refunded_amount"`
 
+	// Set while a capture is with the acquirer, so a second capture can't be
+	// sent for the same funds
+	CapturePendingAt sql.NullTime `json:"-"`
+
 	// Processing Fees (2.9% + $0.30)
 	ProcessingFee int64 `gorm:"default:0" json:"processing_fee"` // In cents
 	NetAmount     int64 `gorm:"default:0" json:"net_amount"`     // Amount - Fee
 
 	// Settlement Information
 	SettlementBatchID sql.NullString `gorm:"type:uuid" json:"settlement_batch_id,omitempty"`
+	ClearedAt         sql.NullTime   `gorm:"index" json:"cleared_at,omitempty"` // Matched in the network's clearing file
+
+	// Refund Tracking (refund transactions only)
+	RefundStatus       RefundStatus     `gorm:"type:varchar(20);index" json:"refund_status,omitempty"`
+	RefundReasonCode   RefundReasonCode `gorm:"type:varchar(30);index" json:"refund_reason_code,omitempty"`
+	SentToIssuerAt     sql.NullTime     `json:"sent_to_issuer_at,omitempty"`
+	EstimatedArrivalAt sql.NullTime     `gorm:"type:date" json:"estimated_arrival_at,omitempty"`
 
 	// Metadata
 	Description sql.NullString `gorm:"type:text" json:"description,omitempty"`
@@ -85,6 +144,7 @@ type Transaction struct {
 	AuthorizedAt sql.NullTime `json:"authorized_at,omitempty"`
 	CapturedAt   sql.NullTime `json:"captured_at,omitempty"`
 	VoidedAt     sql.NullTime `json:"voided_at,omitempty"`
+	VoidReason   VoidReason   `gorm:"type:varchar(20);index" json:"voided_reason,omitempty"`
 	RefundedAt   sql.NullTime `json:"refunded_at,omitempty"`
 	SettledAt    sql.NullTime `json:"settled_at,omitempty"`
 	ExpiresAt    sql.NullTime `json:"expires_at,omitempty"` // Auto-void after 7 days
@@ -106,11 +166,21 @@ func (t *Transaction) IsCaptured() bool {
 }
 
 func (t *Transaction) CanCapture() bool {
-	return t.Status == TransactionStatusAuthorized && !t.IsExpired()
+	return (t.Status == TransactionStatusAuthorized || t.Status == TransactionStatusPartiallyCaptured) &&
+		!t.IsExpired() && t.RemainingCapturableAmount() > 0
 }
 
 func (t *Transaction) CanVoid() bool {
-	return t.Status == TransactionStatusAuthorized && !t.IsExpired()
+	return t.Status == TransactionStatusAuthorized && !t.IsExpired() && !t.CaptureInProgress()
+}
+
+// CaptureReservationTimeout is how long a capture may wait on the acquirer
+// before its reservation is considered abandoned
+const CaptureReservationTimeout = 10 * time.Minute
+
+// CaptureInProgress reports whether a capture is currently with the acquirer
+func (t *Transaction) CaptureInProgress() bool {
+	return t.CapturePendingAt.Valid && time.Since(t.CapturePendingAt.Time) < CaptureReservationTimeout
 }
 
 func (t *Transaction) CanRefund() bool {
@@ -127,6 +197,11 @@ func (t *Transaction) IsExpired() bool {
 	return time.Now().After(t.ExpiresAt.Time)
 }
 
+// RemainingCapturableAmount is the part of the authorization not captured yet
+func (t *Transaction) RemainingCapturableAmount() int64 {
+	return t.Amount - t.CapturedAmount
+}
+
 func (t *Transaction) RemainingRefundableAmount() int64 {
 	return t.CapturedAmount - t.RefundedAmount
 }
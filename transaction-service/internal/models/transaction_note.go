@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionNote is an internal note a merchant's support team attaches to
+// a transaction while investigating it. Notes are never shown to customers.
+type TransactionNote struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TransactionID uuid.UUID `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	MerchantID    uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	AuthorID      uuid.UUID `gorm:"type:uuid" json:"author_id"` // Team member behind the API key, nil if unknown
+	Body          string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (TransactionNote) TableName() string {
+	return "transaction_notes"
+}
+
+// TransactionTag is a short label on a transaction, such as "fraud-review"
+// or "escalated", that the transaction list can be filtered by
+type TransactionTag struct {
+	TransactionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"transaction_id"`
+	Tag           string    `gorm:"type:varchar(40);primaryKey;index:idx_transaction_tags_merchant_tag,priority:2" json:"tag"`
+	MerchantID    uuid.UUID `gorm:"type:uuid;not null;index:idx_transaction_tags_merchant_tag,priority:1" json:"merchant_id"`
+	CreatedBy     uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (TransactionTag) TableName() string {
+	return "transaction_tags"
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type BalanceRepository struct {
+	db *gorm.DB
+}
+
+func NewBalanceRepository() *BalanceRepository {
+	return &BalanceRepository{db: inits.DB}
+}
+
+// BalanceTotals is the sum of a merchant's balance entries
+type BalanceTotals struct {
+	Available int64
+	Reserve   int64
+}
+
+// Transaction runs fn in a database transaction with the merchant's balance
+// locked, so entries fn posts are based on a balance nobody else is
+// changing. tx is also handed to fn for writes outside the balance.
+func (r *BalanceRepository) Transaction(merchantID uuid.UUID, fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.Lock(tx, merchantID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// Lock takes a transaction-scoped advisory lock on the merchant's balance,
+// for callers already in a transaction
+func (r *BalanceRepository) Lock(tx *gorm.DB, merchantID uuid.UUID) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "merchant_balance:"+merchantID.String()).Error
+}
+
+// Totals sums the merchant's balance. Pass the tx from Transaction to read
+// it under the lock, or nil to read it as it stands.
+func (r *BalanceRepository) Totals(tx *gorm.DB, merchantID uuid.UUID) (BalanceTotals, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var totals BalanceTotals
+	err := tx.Model(&model.MerchantBalanceEntry{}).
+		Where("merchant_id = ?", merchantID).
+		Select("COALESCE(SUM(available), 0) AS available, COALESCE(SUM(reserve), 0) AS reserve").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// CreateEntries posts entries; pass the tx from Transaction
+func (r *BalanceRepository) CreateEntries(tx *gorm.DB, entries []model.MerchantBalanceEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// FindDueReserveHolds returns reserve holds whose release time has passed
+// and that have not been released yet, oldest first
+func (r *BalanceRepository) FindDueReserveHolds(now time.Time, limit int) ([]model.MerchantBalanceEntry, error) {
+	var holds []model.MerchantBalanceEntry
+	err := r.db.
+		Where("type = ? AND release_at <= ?", model.BalanceEntryReserveHold, now).
+		Where("NOT EXISTS (SELECT 1 FROM merchant_balance_entries r WHERE r.hold_entry_id = merchant_balance_entries.id)").
+		Order("release_at ASC").
+		Limit(limit).
+		Find(&holds).Error
+	return holds, err
+}
+
+// IsReleased reports whether a reserve hold already has its release entry
+func (r *BalanceRepository) IsReleased(tx *gorm.DB, holdID uuid.UUID) (bool, error) {
+	var count int64
+	err := tx.Model(&model.MerchantBalanceEntry{}).Where("hold_entry_id = ?", holdID).Count(&count).Error
+	return count > 0, err
+}
+
+// ChargebackTotal sums the disputed amount a chargeback has taken from the
+// available balance and given back, leaving out its fee
+func (r *BalanceRepository) ChargebackTotal(tx *gorm.DB, chargebackID uuid.UUID) (int64, error) {
+	var total int64
+	err := tx.Model(&model.MerchantBalanceEntry{}).
+		Where("chargeback_id = ? AND type IN ?", chargebackID,
+			[]model.BalanceEntryType{model.BalanceEntryChargeback, model.BalanceEntryChargebackReversal}).
+		Select("COALESCE(SUM(available), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// NextReserveRelease returns when the merchant's next reserve hold is due,
+// and how much it releases
+func (r *BalanceRepository) NextReserveRelease(merchantID uuid.UUID) (*model.MerchantBalanceEntry, error) {
+	var holds []model.MerchantBalanceEntry
+	err := r.db.
+		Where("merchant_id = ? AND type = ?", merchantID, model.BalanceEntryReserveHold).
+		Where("NOT EXISTS (SELECT 1 FROM merchant_balance_entries r WHERE r.hold_entry_id = merchant_balance_entries.id)").
+		Order("release_at ASC").
+		Limit(1).
+		Find(&holds).Error
+	if err != nil || len(holds) == 0 {
+		return nil, err
+	}
+	return &holds[0], nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CaptureSettingsRepository struct {
+	db *gorm.DB
+}
+
+func NewCaptureSettingsRepository() *CaptureSettingsRepository {
+	return &CaptureSettingsRepository{db: inits.DB}
+}
+
+func (r *CaptureSettingsRepository) FindByMerchant(merchantID uuid.UUID) (*model.MerchantCaptureSettings, error) {
+	var settings model.MerchantCaptureSettings
+	if err := r.db.Where("merchant_id = ?", merchantID).First(&settings).Error; err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Upsert creates or replaces a merchant's capture settings
+func (r *CaptureSettingsRepository) Upsert(settings *model.MerchantCaptureSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"multi_capture_enabled", "updated_at"}),
+	}).Create(settings).Error
+}
+
+func (r *CaptureSettingsRepository) Delete(merchantID uuid.UUID) error {
+	return r.db.Where("merchant_id = ?", merchantID).Delete(&model.MerchantCaptureSettings{}).Error
+}
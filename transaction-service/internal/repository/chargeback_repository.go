@@ -1,11 +1,14 @@
 package repository
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -21,8 +24,29 @@ func (r *ChargebackRepository) Create(chargeback *model.Chargeback) error {
 	return r.db.Create(chargeback).Error
 }
 
+// CountSince counts the merchant's chargebacks disputed since the given time
+func (r *ChargebackRepository) CountSince(merchantID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Chargeback{}).
+		Where("merchant_id = ? AND disputed_at >= ?", merchantID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateEvent records a chargeback event and queues it for the event broker
+// as chargeback.<event>, e.g. chargeback_created becomes chargeback.created
 func (r *ChargebackRepository) CreateEvent(event *model.ChargebackEvent) error {
-	return r.db.Create(event).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		eventType := "chargeback." + strings.TrimPrefix(event.EventType, "chargeback_")
+		return createOutboxEvent(tx, eventType, "chargeback", "chargebacks", event.ChargebackID, event.CreatedAt, event)
+	})
+	if err != nil {
+		logger.Log.Error("Failed to create chargeback event", zap.Error(err))
+	}
+	return err
 }
 
 func (r *ChargebackRepository) FindByID(id uuid.UUID) (*model.Chargeback, error) {
@@ -51,6 +75,79 @@ func (r *ChargebackRepository) FindByMerchant(merchantID uuid.UUID) ([]model.Cha
 	return chargebacks, nil
 }
 
+// FindByMerchantPaged lists a merchant's chargebacks, optionally of one
+// status or transaction, with the soonest response deadline first. total
+// counts every match.
+func (r *ChargebackRepository) FindByMerchantPaged(merchantID uuid.UUID, status model.ChargebackStatus, transactionID uuid.UUID, limit, offset int) ([]model.Chargeback, int64, error) {
+	query := r.db.Model(&model.Chargeback{}).Where("merchant_id = ?", merchantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if transactionID != uuid.Nil {
+		query = query.Where("transaction_id = ?", transactionID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var chargebacks []model.Chargeback
+	if err := query.
+		Order("response_due_date ASC NULLS LAST, created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&chargebacks).Error; err != nil {
+		return nil, 0, err
+	}
+	return chargebacks, total, nil
+}
+
+// FindOverdue returns chargebacks still waiting on the merchant after their
+// response deadline
+func (r *ChargebackRepository) FindOverdue() ([]model.Chargeback, error) {
+	var chargebacks []model.Chargeback
+	if err := r.db.Where("status = ? AND response_due_date <= ?",
+		model.ChargebackStatusNeedsResponse,
+		time.Now()).
+		Find(&chargebacks).Error; err != nil {
+		return nil, err
+	}
+	return chargebacks, nil
+}
+
+func (r *ChargebackRepository) CreateEvidenceFile(file *model.ChargebackEvidenceFile) error {
+	return r.db.Create(file).Error
+}
+
+// FindEvidenceFiles lists a chargeback's evidence files without their content
+func (r *ChargebackRepository) FindEvidenceFiles(chargebackID uuid.UUID) ([]model.ChargebackEvidenceFile, error) {
+	var files []model.ChargebackEvidenceFile
+	if err := r.db.Omit("content").
+		Where("chargeback_id = ?", chargebackID).
+		Order("created_at ASC").
+		Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *ChargebackRepository) FindEvidenceFile(chargebackID, fileID uuid.UUID) (*model.ChargebackEvidenceFile, error) {
+	var file model.ChargebackEvidenceFile
+	if err := r.db.Where("id = ? AND chargeback_id = ?", fileID, chargebackID).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *ChargebackRepository) CountEvidenceFiles(chargebackID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ChargebackEvidenceFile{}).
+		Where("chargeback_id = ?", chargebackID).
+		Count(&count).Error
+	return count, err
+}
+
 func (r *ChargebackRepository) FindNeedingResponse() ([]model.Chargeback, error) {
 	var chargebacks []model.Chargeback
 	if err := r.db.Where("status = ? AND response_due_date > ?",
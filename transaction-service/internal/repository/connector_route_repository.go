@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConnectorRouteRepository struct {
+	db *gorm.DB
+}
+
+func NewConnectorRouteRepository() *ConnectorRouteRepository {
+	return &ConnectorRouteRepository{db: inits.DB}
+}
+
+func (r *ConnectorRouteRepository) FindByMerchant(merchantID uuid.UUID) (*model.MerchantConnectorRoute, error) {
+	var route model.MerchantConnectorRoute
+	if err := r.db.Where("merchant_id = ?", merchantID).First(&route).Error; err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (r *ConnectorRouteRepository) FindAll() ([]model.MerchantConnectorRoute, error) {
+	var routes []model.MerchantConnectorRoute
+	if err := r.db.Order("merchant_id ASC").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// Upsert creates or replaces a merchant's route
+func (r *ConnectorRouteRepository) Upsert(route *model.MerchantConnectorRoute) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"connector", "updated_at"}),
+	}).Create(route).Error
+}
+
+func (r *ConnectorRouteRepository) Delete(merchantID uuid.UUID) error {
+	return r.db.Where("merchant_id = ?", merchantID).Delete(&model.MerchantConnectorRoute{}).Error
+}
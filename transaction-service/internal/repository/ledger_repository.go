@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{db: inits.DB}
+}
+
+// Post saves a journal entry and its lines, in tx when it is set. It
+// reports false without saving anything when an entry with the same
+// reference exists.
+func (r *LedgerRepository) Post(tx *gorm.DB, entry *model.JournalEntry) (bool, error) {
+	if tx == nil {
+		posted := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			posted, err = r.Post(tx, entry)
+			return err
+		})
+		return posted, err
+	}
+
+	result := tx.Omit("Lines").
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "reference"}}, DoNothing: true}).
+		Create(entry)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	if err := tx.Create(&entry.Lines).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindEntry returns the entry posted under reference, with its lines
+func (r *LedgerRepository) FindEntry(tx *gorm.DB, reference string) (*model.JournalEntry, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	var entry model.JournalEntry
+	if err := tx.Preload("Lines").Where("reference = ?", reference).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Balances sums the merchant's lines per account
+func (r *LedgerRepository) Balances(merchantID uuid.UUID) (map[model.LedgerAccount]int64, error) {
+	var rows []struct {
+		Account model.LedgerAccount
+		Balance int64
+	}
+	if err := r.db.Model(&model.JournalLine{}).
+		Select("account, SUM(amount) AS balance").
+		Where("merchant_id = ?", merchantID).
+		Group("account").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	balances := make(map[model.LedgerAccount]int64, len(rows))
+	for _, row := range rows {
+		balances[row.Account] = row.Balance
+	}
+	return balances, nil
+}
+
+// FindEntries returns the merchant's most recent entries, with their lines
+func (r *LedgerRepository) FindEntries(merchantID uuid.UUID, limit int) ([]model.JournalEntry, error) {
+	var entries []model.JournalEntry
+	err := r.db.Preload("Lines").
+		Where("merchant_id = ?", merchantID).
+		Order("occurred_at DESC, created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// =========================================================================
+// Invariant checks
+// =========================================================================
+
+// LedgerStart returns when the oldest movement in the ledger happened, or
+// the zero time for an empty ledger
+func (r *LedgerRepository) LedgerStart() (time.Time, error) {
+	var start struct{ Start *time.Time }
+	if err := r.db.Model(&model.JournalEntry{}).Select("MIN(occurred_at) AS start").Scan(&start).Error; err != nil {
+		return time.Time{}, err
+	}
+	if start.Start == nil {
+		return time.Time{}, nil
+	}
+	return *start.Start, nil
+}
+
+// UnbalancedEntry is a journal entry whose lines do not add up to zero
+type UnbalancedEntry struct {
+	EntryID    uuid.UUID
+	MerchantID uuid.UUID
+	Total      int64
+}
+
+func (r *LedgerRepository) FindUnbalancedEntries(limit int) ([]UnbalancedEntry, error) {
+	var rows []UnbalancedEntry
+	err := r.db.Table("journal_lines l").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id").
+		Select("l.entry_id, e.merchant_id, SUM(l.amount) AS total").
+		Group("l.entry_id, e.merchant_id").
+		Having("SUM(l.amount) <> 0").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// MerchantAmount is a per-merchant total
+type MerchantAmount struct {
+	MerchantID uuid.UUID
+	Amount     int64
+}
+
+// AccountTotals sums one account's lines on entries of a type that
+// happened in [since, until), per merchant
+func (r *LedgerRepository) AccountTotals(entryType model.JournalEntryType, account model.LedgerAccount, since, until time.Time) ([]MerchantAmount, error) {
+	var rows []MerchantAmount
+	err := r.db.Table("journal_lines l").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id").
+		Select("l.merchant_id, SUM(l.amount) AS amount").
+		Where("e.type = ? AND l.account = ?", entryType, account).
+		Where("e.occurred_at >= ? AND e.occurred_at < ?", since, until).
+		Group("l.merchant_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CaptureTotals sums accepted live captures taken in [since, until) per
+// merchant, in MAD, with the share of the processing fee each one carries
+func (r *LedgerRepository) CaptureTotals(since, until time.Time) (gross, fees []MerchantAmount, err error) {
+	var rows []struct {
+		MerchantID uuid.UUID
+		Gross      int64
+		Fee        int64
+	}
+	err = r.db.Table("transaction_captures c").
+		Joins("JOIN transactions t ON t.id = c.transaction_id").
+		Select("c.merchant_id, SUM(c.amount * t.amount_mad / t.amount) AS gross, SUM(t.processing_fee * c.amount / t.amount) AS fee").
+		Where("c.status = ? AND NOT t.test_mode AND t.amount > 0", model.CaptureStatusSucceeded).
+		Where("c.created_at >= ? AND c.created_at < ?", since, until).
+		Group("c.merchant_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		gross = append(gross, MerchantAmount{MerchantID: row.MerchantID, Amount: row.Gross})
+		fees = append(fees, MerchantAmount{MerchantID: row.MerchantID, Amount: row.Fee})
+	}
+	return gross, fees, err
+}
+
+// RefundTotals sums live refunds sent to the issuer in [since, until) per
+// merchant, in MAD, with the fees given back on them
+func (r *LedgerRepository) RefundTotals(since, until time.Time) (gross, feesReversed []MerchantAmount, err error) {
+	var rows []struct {
+		MerchantID  uuid.UUID
+		Gross       int64
+		FeeReversed int64
+	}
+	err = r.db.Model(&model.Transaction{}).
+		Select("merchant_id, SUM(-amount_mad) AS gross, SUM(-processing_fee) AS fee_reversed").
+		Where("type = ? AND NOT test_mode", model.TransactionTypeRefund).
+		Where("refund_status IN ?", []model.RefundStatus{model.RefundStatusSentToIssuer, model.RefundStatusSettled}).
+		Where("sent_to_issuer_at >= ? AND sent_to_issuer_at < ?", since, until).
+		Group("merchant_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		gross = append(gross, MerchantAmount{MerchantID: row.MerchantID, Amount: row.Gross})
+		feesReversed = append(feesReversed, MerchantAmount{MerchantID: row.MerchantID, Amount: row.FeeReversed})
+	}
+	return gross, feesReversed, err
+}
+
+// AmountMismatch is a transaction whose running total disagrees with the
+// records it is the sum of
+type AmountMismatch struct {
+	TransactionID uuid.UUID
+	MerchantID    uuid.UUID
+	Recorded      int64
+	Expected      int64
+}
+
+// FindCapturedAmountMismatches returns transactions with a capture in the
+// ledger whose captured_amount is not the sum of their accepted captures
+func (r *LedgerRepository) FindCapturedAmountMismatches(limit int) ([]AmountMismatch, error) {
+	var rows []AmountMismatch
+	err := r.db.Table("transactions t").
+		Joins("JOIN transaction_captures c ON c.transaction_id = t.id AND c.status = ?", model.CaptureStatusSucceeded).
+		Select("t.id AS transaction_id, t.merchant_id, t.captured_amount AS recorded, SUM(c.amount) AS expected").
+		Where(`t.id IN (
+			SELECT c2.transaction_id FROM transaction_captures c2
+			JOIN journal_entries e ON e.source_id = c2.id AND e.type = ?)`, model.JournalEntryCapture).
+		Group("t.id, t.merchant_id, t.captured_amount").
+		Having("t.captured_amount <> SUM(c.amount)").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindRefundedAmountMismatches returns transactions with a refund in the
+// ledger whose refunded_amount is not the sum of their sent refunds.
+// Transactions with a refund sent since until are left for the next check,
+// as their total may not be updated yet.
+func (r *LedgerRepository) FindRefundedAmountMismatches(until time.Time, limit int) ([]AmountMismatch, error) {
+	sent := []model.RefundStatus{model.RefundStatusSentToIssuer, model.RefundStatusSettled}
+	var rows []AmountMismatch
+	err := r.db.Table("transactions t").
+		Joins("JOIN transactions r ON r.parent_transaction_id = t.id AND r.type = ? AND r.refund_status IN ?", model.TransactionTypeRefund, sent).
+		Select("t.id AS transaction_id, t.merchant_id, t.refunded_amount AS recorded, SUM(-r.amount) AS expected").
+		Where(`t.id IN (
+			SELECT r2.parent_transaction_id FROM transactions r2
+			JOIN journal_entries e ON e.source_id = r2.id AND e.type = ?)`, model.JournalEntryRefund).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM transactions r3
+			WHERE r3.parent_transaction_id = t.id AND r3.sent_to_issuer_at >= ?)`, until).
+		Group("t.id, t.merchant_id, t.refunded_amount").
+		Having("t.refunded_amount <> SUM(-r.amount)").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ReserveTotals sums the merchant balance reserve held for batches that
+// are in the ledger, per merchant
+func (r *LedgerRepository) ReserveTotals() ([]MerchantAmount, error) {
+	var rows []MerchantAmount
+	err := r.db.Model(&model.MerchantBalanceEntry{}).
+		Select("merchant_id, SUM(reserve) AS amount").
+		Where("settlement_batch_id IN (SELECT source_id FROM journal_entries WHERE type = ?)", model.JournalEntrySettlement).
+		Group("merchant_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// AccountBalances sums an account's lines per merchant
+func (r *LedgerRepository) AccountBalances(account model.LedgerAccount) ([]MerchantAmount, error) {
+	var rows []MerchantAmount
+	err := r.db.Model(&model.JournalLine{}).
+		Select("merchant_id, SUM(amount) AS amount").
+		Where("account = ?", account).
+		Group("merchant_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ChargebackTotals compares, per merchant, what the chargebacks account
+// holds for disputes in the ledger with what their opening entries put
+// there for the disputes still open. Disputes changed since until are left
+// for the next check, as their entry may not be posted yet.
+func (r *LedgerRepository) ChargebackTotals(until time.Time) (held, open []MerchantAmount, err error) {
+	openStatuses := []model.ChargebackStatus{
+		model.ChargebackStatusOpen, model.ChargebackStatusUnderReview, model.ChargebackStatusNeedsResponse,
+	}
+	settled := r.db.Table("chargebacks cb").
+		Select("cb.id").
+		Joins("JOIN journal_entries o ON o.source_id = cb.id AND o.type = ?", model.JournalEntryChargeback).
+		Where("cb.updated_at < ?", until)
+
+	err = r.db.Table("journal_lines l").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id").
+		Select("l.merchant_id, SUM(l.amount) AS amount").
+		Where("l.account = ? AND e.source_id IN (?)", model.LedgerAccountChargebacks, settled).
+		Group("l.merchant_id").
+		Scan(&held).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = r.db.Table("journal_lines l").
+		Joins("JOIN journal_entries e ON e.id = l.entry_id AND e.type = ?", model.JournalEntryChargeback).
+		Joins("JOIN chargebacks cb ON cb.id = e.source_id").
+		Select("l.merchant_id, SUM(l.amount) AS amount").
+		Where("l.account = ? AND cb.status IN ? AND cb.updated_at < ?", model.LedgerAccountChargebacks, openStatuses, until).
+		Group("l.merchant_id").
+		Scan(&open).Error
+	return held, open, err
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	outboxOnce    sync.Once
+	outboxEnabled bool
+)
+
+// OutboxEnabled reports whether lifecycle events are queued for the event
+// broker, which is the case when EVENT_BROKER_URL is set
+func OutboxEnabled() bool {
+	outboxOnce.Do(func() {
+		outboxEnabled = config.GetEnv("EVENT_BROKER_URL") != ""
+	})
+	return outboxEnabled
+}
+
+// OutboxMessage is the envelope published for every lifecycle event
+type OutboxMessage struct {
+	ID            uuid.UUID   `json:"id"`
+	Type          string      `json:"type"`
+	AggregateType string      `json:"aggregate_type"`
+	AggregateID   uuid.UUID   `json:"aggregate_id"`
+	MerchantID    uuid.UUID   `json:"merchant_id"`
+	OccurredAt    time.Time   `json:"occurred_at"`
+	Data          interface{} `json:"data"`
+}
+
+// createOutboxEvent queues an event inside tx. ownerTable is the table of
+// the aggregate, which holds its merchant_id.
+func createOutboxEvent(tx *gorm.DB, eventType, aggregateType, ownerTable string, aggregateID uuid.UUID, occurredAt time.Time, data interface{}) error {
+	if !OutboxEnabled() {
+		return nil
+	}
+
+	var merchantID uuid.UUID
+	if err := tx.Table(ownerTable).Select("merchant_id").Where("id = ?", aggregateID).Limit(1).Scan(&merchantID).Error; err != nil {
+		return fmt.Errorf("failed to resolve merchant of %s %s: %w", aggregateType, aggregateID, err)
+	}
+
+	msg := OutboxMessage{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		MerchantID:    merchantID,
+		OccurredAt:    occurredAt.UTC(),
+		Data:          data,
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{
+		ID:            msg.ID,
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		MerchantID:    merchantID,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{db: inits.DB}
+}
+
+// ProcessPending locks up to limit due events, oldest first, and hands them
+// to publish. publish returns how many of them, from the start, the broker
+// confirmed; those are marked published. When it also returns an error, the
+// next event is scheduled for a retry after retryAfter(attempts) and the
+// error is returned. Rows stay locked until this returns, so concurrent
+// relays skip them.
+func (r *OutboxRepository) ProcessPending(limit int, retryAfter func(attempts int) time.Duration, publish func(events []model.OutboxEvent) (int, error)) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var events []model.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		published, publishErr = publish(events)
+		if published > 0 {
+			ids := make([]uuid.UUID, published)
+			for i := range ids {
+				ids[i] = events[i].ID
+			}
+			if err := tx.Model(&model.OutboxEvent{}).
+				Where("id IN ?", ids).
+				Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		if publishErr == nil || published >= len(events) {
+			return nil
+		}
+		failed := events[published]
+		return tx.Model(&model.OutboxEvent{}).
+			Where("id = ?", failed.ID).
+			Updates(map[string]interface{}{
+				"attempts":        failed.Attempts + 1,
+				"last_error":      publishErr.Error(),
+				"next_attempt_at": time.Now().Add(retryAfter(failed.Attempts + 1)),
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, publishErr
+}
+
+// DeletePublishedBefore removes events published before cutoff
+func (r *OutboxRepository) DeletePublishedBefore(cutoff time.Time) (int64, error) {
+	res := r.db.Where("published_at IS NOT NULL AND published_at < ?", cutoff).Delete(&model.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository() *ReconciliationRepository {
+	return &ReconciliationRepository{db: inits.DB}
+}
+
+// CreateFile stores a reconciled clearing file along with its mismatches
+func (r *ReconciliationRepository) CreateFile(file *model.ClearingFile) error {
+	return r.db.Create(file).Error
+}
+
+func (r *ReconciliationRepository) FindFile(id uuid.UUID) (*model.ClearingFile, error) {
+	var file model.ClearingFile
+	if err := r.db.Preload("Mismatches", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("id = ?", id).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// FileExists reports whether a connector's clearing file for day was
+// already ingested
+func (r *ReconciliationRepository) FileExists(connector string, day time.Time) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.ClearingFile{}).
+		Where("connector = ? AND file_date = ?", connector, day).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListFiles returns the most recent clearing files, without their mismatches
+func (r *ReconciliationRepository) ListFiles(limit int) ([]model.ClearingFile, error) {
+	var files []model.ClearingFile
+	if err := r.db.Order("file_date DESC").Limit(limit).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// CountMismatchesInBatch counts mismatches flagged on the batch's
+// transactions, including ones flagged before the batch was cut
+func (r *ReconciliationRepository) CountMismatchesInBatch(batchID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ReconciliationMismatch{}).
+		Joins("JOIN transactions ON transactions.id = reconciliation_mismatches.transaction_id").
+		Where("transactions.settlement_batch_id = ?", batchID).
+		Count(&count).Error
+	return count, err
+}
+
+// FindFilesBetween returns the clearing files for UTC days in [from, to],
+// without their mismatches
+func (r *ReconciliationRepository) FindFilesBetween(from, to time.Time) ([]model.ClearingFile, error) {
+	var files []model.ClearingFile
+	if err := r.db.Where("file_date >= ? AND file_date <= ?", from, to).
+		Order("file_date ASC").
+		Find(&files).Error; err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// MismatchSummary totals one type of mismatch in one currency
+type MismatchSummary struct {
+	Type           model.ReconciliationMismatchType `json:"type"`
+	Currency       string                           `json:"currency"`
+	Count          int64                            `json:"count"`
+	ExpectedAmount int64                            `json:"expected_amount"`
+	ClearedAmount  int64                            `json:"cleared_amount"`
+}
+
+// SummarizeMismatches totals the mismatches flagged by clearing files for
+// UTC days in [from, to]
+func (r *ReconciliationRepository) SummarizeMismatches(from, to time.Time) ([]MismatchSummary, error) {
+	var summaries []MismatchSummary
+	err := r.db.Model(&model.ReconciliationMismatch{}).
+		Select(`reconciliation_mismatches.type, reconciliation_mismatches.currency,
+			COUNT(*) AS count,
+			COALESCE(SUM(reconciliation_mismatches.expected_amount), 0) AS expected_amount,
+			COALESCE(SUM(reconciliation_mismatches.cleared_amount), 0) AS cleared_amount`).
+		Joins("JOIN clearing_files ON clearing_files.id = reconciliation_mismatches.clearing_file_id").
+		Where("clearing_files.file_date >= ? AND clearing_files.file_date <= ?", from, to).
+		Group("reconciliation_mismatches.type, reconciliation_mismatches.currency").
+		Order("reconciliation_mismatches.type, reconciliation_mismatches.currency").
+		Scan(&summaries).Error
+	return summaries, err
+}
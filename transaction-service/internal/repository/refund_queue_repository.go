@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// RefundQueueRepository holds queued refunds in Postgres and the per-merchant
+// refund slots in Redis
+type RefundQueueRepository struct {
+	db  *gorm.DB
+	rdb *redis.Client
+	ctx context.Context
+}
+
+func NewRefundQueueRepository() *RefundQueueRepository {
+	return &RefundQueueRepository{
+		db:  inits.DB,
+		rdb: inits.RDB,
+		ctx: context.Background(),
+	}
+}
+
+// RefundQueueStats counts a merchant's refunds by where they are in the queue
+type RefundQueueStats struct {
+	Queued         int64
+	Processing     int64 // Claimed and waiting on the acquirer
+	Sent           int64 // Accepted by the acquirer since the cutoff
+	Failed         int64 // Failed since the cutoff
+	OldestQueuedAt *time.Time
+}
+
+// =========================================================================
+// Redis: refund slots
+// =========================================================================
+
+// acquireRefundSlot takes a slot when the merchant has fewer than
+// max_in_flight refunds in flight and has started fewer than rate_limit in
+// the current second. Slots are leases that expire on their own, so a pod
+// that dies mid-refund cannot hold one forever.
+var acquireRefundSlot = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local lease_ms = tonumber(ARGV[2])
+local max_in_flight = tonumber(ARGV[3])
+local rate_limit = tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if max_in_flight > 0 and redis.call('ZCARD', KEYS[1]) >= max_in_flight then
+	return 0
+end
+if rate_limit > 0 then
+	local started = tonumber(redis.call('GET', KEYS[2]) or '0')
+	if started >= rate_limit then
+		return 0
+	end
+	redis.call('INCR', KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], 2000)
+end
+redis.call('ZADD', KEYS[1], now + lease_ms, ARGV[5])
+redis.call('PEXPIRE', KEYS[1], lease_ms)
+return 1
+`)
+
+func refundInFlightKey(merchantID uuid.UUID) string {
+	return fmt.Sprintf("refund_queue:in_flight:%s", merchantID)
+}
+
+func refundRateKey(merchantID uuid.UUID, second int64) string {
+	return fmt.Sprintf("refund_queue:rate:%s:%d", merchantID, second)
+}
+
+// AcquireRefundSlot tries to take one of the merchant's refund slots for
+// lease. A limit of 0 disables that check.
+func (r *RefundQueueRepository) AcquireRefundSlot(merchantID uuid.UUID, lease string, maxInFlight, ratePerSecond int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	acquired, err := acquireRefundSlot.Run(r.ctx, r.rdb,
+		[]string{refundInFlightKey(merchantID), refundRateKey(merchantID, now.Unix())},
+		now.UnixMilli(), ttl.Milliseconds(), maxInFlight, ratePerSecond, lease,
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// ReleaseRefundSlot gives a slot back once its refund is done
+func (r *RefundQueueRepository) ReleaseRefundSlot(merchantID uuid.UUID, lease string) error {
+	return r.rdb.ZRem(r.ctx, refundInFlightKey(merchantID), lease).Err()
+}
+
+// =========================================================================
+// Queued refunds
+// =========================================================================
+
+// HasQueued reports whether the merchant has refunds waiting, so new ones
+// queue behind them instead of overtaking
+func (r *RefundQueueRepository) HasQueued(merchantID uuid.UUID) (bool, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&model.Transaction{}).
+		Where("merchant_id = ? AND type = ? AND refund_status = ?", merchantID, model.TransactionTypeRefund, model.RefundStatusQueued).
+		Limit(1).
+		Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// QueuedAmountByParent totals the refunds of a transaction still waiting in
+// the queue. They are not in its refunded amount until they are sent.
+func (r *RefundQueueRepository) QueuedAmountByParent(parentID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.Model(&model.Transaction{}).
+		Where("parent_transaction_id = ? AND type = ? AND refund_status = ?", parentID, model.TransactionTypeRefund, model.RefundStatusQueued).
+		Select("COALESCE(SUM(-amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// FindQueued returns the oldest queued refunds across merchants
+func (r *RefundQueueRepository) FindQueued(limit int) ([]model.Transaction, error) {
+	var refunds []model.Transaction
+	err := r.db.Where("type = ? AND refund_status = ?", model.TransactionTypeRefund, model.RefundStatusQueued).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// Claim moves a queued refund to requested. It returns false if another
+// worker claimed it first.
+func (r *RefundQueueRepository) Claim(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&model.Transaction{}).
+		Where("id = ? AND refund_status = ?", id, model.RefundStatusQueued).
+		Updates(map[string]interface{}{
+			"refund_status": model.RefundStatusRequested,
+			"updated_at":    time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Stats counts the merchant's queued refunds, and those claimed, sent or
+// failed since the cutoff
+func (r *RefundQueueRepository) Stats(merchantID uuid.UUID, since time.Time) (*RefundQueueStats, error) {
+	var rows []struct {
+		RefundStatus model.RefundStatus
+		Count        int64
+		Oldest       time.Time
+	}
+	err := r.db.Model(&model.Transaction{}).
+		Select("refund_status, COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("merchant_id = ? AND type = ?", merchantID, model.TransactionTypeRefund).
+		Where("refund_status = ? OR (refund_status IS NOT NULL AND updated_at >= ?)", model.RefundStatusQueued, since).
+		Group("refund_status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &RefundQueueStats{}
+	for _, row := range rows {
+		switch row.RefundStatus {
+		case model.RefundStatusQueued:
+			stats.Queued = row.Count
+			oldest := row.Oldest
+			stats.OldestQueuedAt = &oldest
+		case model.RefundStatusRequested:
+			stats.Processing = row.Count
+		case model.RefundStatusSentToIssuer, model.RefundStatusSettled:
+			stats.Sent += row.Count
+		case model.RefundStatusFailed:
+			stats.Failed = row.Count
+		}
+	}
+	return stats, nil
+}
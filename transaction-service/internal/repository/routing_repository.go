@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoutingRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutingRepository() *RoutingRepository {
+	return &RoutingRepository{db: inits.DB}
+}
+
+// =========================================================================
+// Rules
+// =========================================================================
+
+func (r *RoutingRepository) CreateRule(rule *model.RoutingRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *RoutingRepository) UpdateRule(rule *model.RoutingRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *RoutingRepository) DeleteRule(id uuid.UUID) error {
+	return r.db.Where("id = ?", id).Delete(&model.RoutingRule{}).Error
+}
+
+func (r *RoutingRepository) FindRuleByID(id uuid.UUID) (*model.RoutingRule, error) {
+	var rule model.RoutingRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *RoutingRepository) FindAllRules() ([]model.RoutingRule, error) {
+	var rules []model.RoutingRule
+	if err := r.db.Order("merchant_id NULLS LAST, priority ASC, created_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindActiveRules returns enabled rules for a merchant followed by the
+// global ones, each group ordered by priority
+func (r *RoutingRepository) FindActiveRules(merchantID uuid.UUID) ([]model.RoutingRule, error) {
+	var rules []model.RoutingRule
+	err := r.db.Where("enabled = ? AND (merchant_id = ? OR merchant_id IS NULL)", true, merchantID).
+		Order("merchant_id NULLS LAST, priority ASC, created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// =========================================================================
+// Costs
+// =========================================================================
+
+func (r *RoutingRepository) FindAllCosts() ([]model.ConnectorCost, error) {
+	var costs []model.ConnectorCost
+	if err := r.db.Order("connector ASC").Find(&costs).Error; err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+func (r *RoutingRepository) UpsertCost(cost *model.ConnectorCost) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "connector"}},
+		DoUpdates: clause.AssignmentColumns([]string{"percent_bps", "fixed_fee_mad", "updated_at"}),
+	}).Create(cost).Error
+}
+
+// =========================================================================
+// Decisions
+// =========================================================================
+
+func (r *RoutingRepository) CreateDecision(decision *model.RoutingDecision) error {
+	return r.db.Create(decision).Error
+}
+
+func (r *RoutingRepository) FindDecisionByTransaction(transactionID uuid.UUID) (*model.RoutingDecision, error) {
+	var decision model.RoutingDecision
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&decision).Error; err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+func (r *RoutingRepository) FindDecisionsByMerchant(merchantID uuid.UUID, limit int) ([]model.RoutingDecision, error) {
+	var decisions []model.RoutingDecision
+	err := r.db.Where("merchant_id = ?", merchantID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&decisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SettlementRepository struct {
@@ -37,17 +39,115 @@ func (r *SettlementRepository) FindByMerchantAndDate(merchantID uuid.UUID, date
 	return &batch, nil
 }
 
+func (r *SettlementRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*model.SettlementBatch, error) {
+	var batch model.SettlementBatch
+	if err := r.db.Where("id = ? AND merchant_id = ?", id, merchantID).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindByMerchantInRange lists a merchant's batches with batch_date in [from, to)
+func (r *SettlementRepository) FindByMerchantInRange(merchantID uuid.UUID, status model.SettlementStatus, from, to time.Time) ([]model.SettlementBatch, error) {
+	var batches []model.SettlementBatch
+	query := r.db.Where("merchant_id = ? AND batch_date >= ? AND batch_date < ?", merchantID, from, to)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("batch_date ASC").Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
 func (r *SettlementRepository) FindPendingBatches() ([]model.SettlementBatch, error) {
 	var batches []model.SettlementBatch
 	if err := r.db.Where("status = ? AND settlement_date <= ?",
 		model.SettlementStatusPending,
-		time.Now()).
+		time.Now().UTC()).
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *SettlementRepository) FindHeldBatches() ([]model.SettlementBatch, error) {
+	var batches []model.SettlementBatch
+	if err := r.db.Where("status = ?", model.SettlementStatusHeld).
+		Order("held_at ASC").
 		Find(&batches).Error; err != nil {
 		return nil, err
 	}
 	return batches, nil
 }
 
+// SumSettledSince totals the net amount of batches paid out since from
+func (r *SettlementRepository) SumSettledSince(from time.Time) (int64, error) {
+	var total int64
+	if err := r.db.Model(&model.SettlementBatch{}).
+		Where("status = ? AND settled_at >= ?", model.SettlementStatusSettled, from).
+		Select("COALESCE(SUM(net_amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GrossVolumeInRange totals a merchant's gross batch volume dated in
+// [from, to) and counts the days those batches cover. A weekly batch counts
+// seven days; batches from before periods were recorded count one.
+func (r *SettlementRepository) GrossVolumeInRange(merchantID uuid.UUID, from, to time.Time) (int64, int, error) {
+	var total int64
+	if err := r.db.Model(&model.SettlementBatch{}).
+		Where("merchant_id = ? AND batch_date >= ? AND batch_date < ?", merchantID, from, to).
+		Select("COALESCE(SUM(gross_amount), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, 0, err
+	}
+
+	var days int
+	if err := r.db.Raw(`
+		SELECT COUNT(DISTINCT covered.day)
+		FROM settlement_batches b
+		CROSS JOIN LATERAL generate_series(
+			b.batch_date - (COALESCE(GREATEST(ROUND(EXTRACT(EPOCH FROM b.period_end - b.period_start) / 86400)::int, 1), 1) - 1),
+			b.batch_date,
+			INTERVAL '1 day'
+		) AS covered(day)
+		WHERE b.merchant_id = ? AND b.batch_date >= ? AND b.batch_date < ?`,
+		merchantID, from, to,
+	).Scan(&days).Error; err != nil {
+		return 0, 0, err
+	}
+	return total, days, nil
+}
+
+// Hold stops a pending batch from paying out. It only moves pending
+// batches, so a batch already being settled is left alone.
+func (r *SettlementRepository) Hold(id uuid.UUID, reason model.SettlementHoldReason, detail string) error {
+	return r.db.Model(&model.SettlementBatch{}).
+		Where("id = ? AND status = ?", id, model.SettlementStatusPending).
+		Updates(map[string]interface{}{
+			"status":      model.SettlementStatusHeld,
+			"hold_reason": string(reason),
+			"hold_detail": detail,
+			"held_at":     time.Now().UTC(),
+		}).Error
+}
+
+// Release returns a held batch to pending. It reports false when the batch
+// was not held.
+func (r *SettlementRepository) Release(id uuid.UUID, releasedBy string) (bool, error) {
+	result := r.db.Model(&model.SettlementBatch{}).
+		Where("id = ? AND status = ?", id, model.SettlementStatusHeld).
+		Updates(map[string]interface{}{
+			"status":      model.SettlementStatusPending,
+			"released_at": time.Now().UTC(),
+			"released_by": releasedBy,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *SettlementRepository) Update(batch *model.SettlementBatch) error {
 	return r.db.Save(batch).Error
 }
@@ -57,6 +157,124 @@ func (r *SettlementRepository) MarkSettled(id uuid.UUID) error {
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"status":     model.SettlementStatusSettled,
-			"settled_at": time.Now(),
+			"settled_at": time.Now().UTC(),
 		}).Error
 }
+
+// FindUnreconciledSince returns batches created since from whose clearing
+// reconciliation is not settled yet
+func (r *SettlementRepository) FindUnreconciledSince(from time.Time) ([]model.SettlementBatch, error) {
+	var batches []model.SettlementBatch
+	if err := r.db.Where("reconciliation_status IS NULL AND created_at >= ?", from).
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// SetReconciliation records whether the batch agreed with the clearing files
+func (r *SettlementRepository) SetReconciliation(id uuid.UUID, status string) error {
+	return r.db.Model(&model.SettlementBatch{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reconciliation_status": status,
+			"reconciled_at":         time.Now().UTC(),
+		}).Error
+}
+
+// Now reads the database clock, so every replica cuts settlement days
+// against the same time whatever its own clock says
+func (r *SettlementRepository) Now() (time.Time, error) {
+	var now time.Time
+	if err := r.db.Raw("SELECT now()").Scan(&now).Error; err != nil {
+		return time.Time{}, err
+	}
+	return now.UTC(), nil
+}
+
+func (r *SettlementRepository) FindTimezone(merchantID uuid.UUID) (*model.MerchantSettlementTimezone, error) {
+	var tz model.MerchantSettlementTimezone
+	if err := r.db.Where("merchant_id = ?", merchantID).First(&tz).Error; err != nil {
+		return nil, err
+	}
+	return &tz, nil
+}
+
+func (r *SettlementRepository) FindTimezones(merchantIDs []uuid.UUID) ([]model.MerchantSettlementTimezone, error) {
+	var tzs []model.MerchantSettlementTimezone
+	if len(merchantIDs) == 0 {
+		return tzs, nil
+	}
+	if err := r.db.Where("merchant_id IN ?", merchantIDs).Find(&tzs).Error; err != nil {
+		return nil, err
+	}
+	return tzs, nil
+}
+
+// UpsertTimezone creates or replaces a merchant's settlement timezone
+func (r *SettlementRepository) UpsertTimezone(tz *model.MerchantSettlementTimezone) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "updated_at"}),
+	}).Create(tz).Error
+}
+
+func (r *SettlementRepository) DeleteTimezone(merchantID uuid.UUID) error {
+	return r.db.Where("merchant_id = ?", merchantID).Delete(&model.MerchantSettlementTimezone{}).Error
+}
+
+func (r *SettlementRepository) FindSchedule(merchantID uuid.UUID) (*model.MerchantSettlementSchedule, error) {
+	var schedule model.MerchantSettlementSchedule
+	if err := r.db.Where("merchant_id = ?", merchantID).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *SettlementRepository) FindSchedules(merchantIDs []uuid.UUID) ([]model.MerchantSettlementSchedule, error) {
+	var schedules []model.MerchantSettlementSchedule
+	if len(merchantIDs) == 0 {
+		return schedules, nil
+	}
+	if err := r.db.Where("merchant_id IN ?", merchantIDs).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule applies update to a merchant's schedule, starting from
+// fallback when the merchant has none. The row is locked while update runs
+// so a merchant's change and an operator's don't overwrite each other.
+func (r *SettlementRepository) UpdateSchedule(
+	merchantID uuid.UUID,
+	fallback model.MerchantSettlementSchedule,
+	update func(*model.MerchantSettlementSchedule) error,
+) (*model.MerchantSettlementSchedule, error) {
+	var schedule model.MerchantSettlementSchedule
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("merchant_id = ?", merchantID).
+			First(&schedule).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			schedule = fallback
+		} else if err != nil {
+			return err
+		}
+
+		if err := update(&schedule); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "merchant_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"schedule", "day", "delay_days", "min_payout_amount", "reserve_bps", "reserve_days", "updated_at"}),
+		}).Create(&schedule).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (r *SettlementRepository) DeleteSchedule(merchantID uuid.UUID) error {
+	return r.db.Where("merchant_id = ?", merchantID).Delete(&model.MerchantSettlementSchedule{}).Error
+}
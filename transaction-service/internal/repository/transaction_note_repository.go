@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransactionNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionNoteRepository() *TransactionNoteRepository {
+	return &TransactionNoteRepository{db: inits.DB}
+}
+
+func (r *TransactionNoteRepository) CreateNote(note *model.TransactionNote) error {
+	return r.db.Create(note).Error
+}
+
+// FindNotes returns a transaction's notes, oldest first
+func (r *TransactionNoteRepository) FindNotes(txnID, merchantID uuid.UUID) ([]model.TransactionNote, error) {
+	var notes []model.TransactionNote
+	if err := r.db.Where("transaction_id = ? AND merchant_id = ?", txnID, merchantID).
+		Order("created_at ASC").
+		Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// DeleteNote deletes one note and reports whether it existed
+func (r *TransactionNoteRepository) DeleteNote(noteID, txnID, merchantID uuid.UUID) (bool, error) {
+	res := r.db.Where("id = ? AND transaction_id = ? AND merchant_id = ?", noteID, txnID, merchantID).
+		Delete(&model.TransactionNote{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountNotes returns the number of notes on each of the given transactions
+func (r *TransactionNoteRepository) CountNotes(txnIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(txnIDs))
+	if len(txnIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TransactionID uuid.UUID
+		Count         int
+	}
+	if err := r.db.Model(&model.TransactionNote{}).
+		Select("transaction_id, COUNT(*) AS count").
+		Where("transaction_id IN ?", txnIDs).
+		Group("transaction_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.TransactionID] = row.Count
+	}
+	return counts, nil
+}
+
+// AddTags tags a transaction; tags it already has are left as they are
+func (r *TransactionNoteRepository) AddTags(tags []model.TransactionTag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+}
+
+func (r *TransactionNoteRepository) RemoveTag(txnID, merchantID uuid.UUID, tag string) error {
+	return r.db.Where("transaction_id = ? AND merchant_id = ? AND tag = ?", txnID, merchantID, tag).
+		Delete(&model.TransactionTag{}).Error
+}
+
+// FindTags returns each given transaction's tags in alphabetical order
+func (r *TransactionNoteRepository) FindTags(txnIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string, len(txnIDs))
+	if len(txnIDs) == 0 {
+		return tags, nil
+	}
+
+	var rows []model.TransactionTag
+	if err := r.db.Where("transaction_id IN ?", txnIDs).
+		Order("tag ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tags[row.TransactionID] = append(tags[row.TransactionID], row.Tag)
+	}
+	return tags, nil
+}
+
+// escapeLike makes % and _ in user text match literally in a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -26,6 +28,12 @@ func NewTransactionRepository() *TransactionRepository {
 	}
 }
 
+// WithContext returns a copy whose statements and cache reads carry ctx, so
+// they stop when the caller's deadline passes or it goes away
+func (r *TransactionRepository) WithContext(ctx context.Context) *TransactionRepository {
+	return &TransactionRepository{db: r.db.WithContext(ctx), ctx: ctx}
+}
+
 // Create Operations
 func (r *TransactionRepository) Create(txn *model.Transaction) error {
 	if err := r.db.Create(txn).Error; err != nil {
@@ -39,8 +47,15 @@ func (r *TransactionRepository) Create(txn *model.Transaction) error {
 	return nil
 }
 
+// CreateEvent records a transaction event and queues it for the event broker
 func (r *TransactionRepository) CreateEvent(event *model.TransactionEvent) error {
-	if err := r.db.Create(event).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return createOutboxEvent(tx, "transaction."+event.EventType, "transaction", "transactions", event.TransactionID, event.CreatedAt, event)
+	})
+	if err != nil {
 		logger.Log.Error("Failed to create transaction event", zap.Error(err))
 		return err
 	}
@@ -75,50 +90,154 @@ func (r *TransactionRepository) FindByIDAndMerchant(id, merchantID uuid.UUID) (*
 	return &txn, nil
 }
 
-func (r *TransactionRepository) FindByMerchant(merchantID uuid.UUID, limit, offset int) ([]model.Transaction, error) {
+// transactionSortColumns maps the sort_by values clients may send to columns
+var transactionSortColumns = map[string]string{
+	"created_at":  "created_at",
+	"amount":      "amount",
+	"status":      "status",
+	"type":        "type",
+	"captured_at": "captured_at",
+}
+
+// TransactionListFilter narrows and orders a merchant's transaction list.
+// Zero values mean no filter; CreatedTo is exclusive.
+type TransactionListFilter struct {
+	MerchantID  uuid.UUID
+	Status      model.TransactionStatus
+	Type        model.TransactionType
+	VoidReason  model.VoidReason
+	Tags        []string // Transactions carrying every one of these tags
+	NoteText    string   // Transactions with a note containing this text
+	TestMode    bool     // Sandbox transactions instead of live ones
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	SortBy      string
+	SortDesc    bool
+	Limit       int
+	Offset      int
+}
+
+// ValidTransactionSort reports whether sortBy can be passed in a filter
+func ValidTransactionSort(sortBy string) bool {
+	_, ok := transactionSortColumns[sortBy]
+	return ok
+}
+
+// ListByMerchant returns one page of a merchant's transactions and the
+// number of transactions matching the filter across all pages. Ties are
+// broken by id so paging stays stable.
+func (r *TransactionRepository) ListByMerchant(filter TransactionListFilter) ([]model.Transaction, int64, error) {
+	query := r.db.Model(&model.Transaction{}).
+		Where("merchant_id = ? AND test_mode = ?", filter.MerchantID, filter.TestMode)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.VoidReason != "" {
+		query = query.Where("void_reason = ?", filter.VoidReason)
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = transactions.id AND tt.tag = ?)", tag)
+	}
+	if filter.NoteText != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM transaction_notes tn WHERE tn.transaction_id = transactions.id AND tn.body ILIKE ?)",
+			"%"+escapeLike(filter.NoteText)+"%")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := transactionSortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	// NULL captured_at sorts last either way
+	order := fmt.Sprintf("%s %s NULLS LAST, id %s", column, direction, direction)
+
 	var txns []model.Transaction
-	if err := r.db.Where("merchant_id = ?", merchantID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
+	if err := query.
+		Order(order).
+		Limit(filter.Limit).
+		Offset(filter.Offset).
 		Find(&txns).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return txns, nil
+	return txns, total, nil
 }
 
-func (r *TransactionRepository) FindByStatus(merchantID uuid.UUID, status model.TransactionStatus) ([]model.Transaction, error) {
+// FindExpiredAuthorizations finds authorizations that have expired (> 7 days)
+func (r *TransactionRepository) FindExpiredAuthorizations() ([]model.Transaction, error) {
 	var txns []model.Transaction
-	if err := r.db.Where("merchant_id = ? AND status = ?", merchantID, status).
-		Order("created_at DESC").
+	if err := r.db.Where("status = ? AND expires_at < ?",
+		model.TransactionStatusAuthorized,
+		time.Now()).
 		Find(&txns).Error; err != nil {
 		return nil, err
 	}
 	return txns, nil
 }
 
-// FindExpiredAuthorizations finds authorizations that have expired (> 7 days)
-func (r *TransactionRepository) FindExpiredAuthorizations() ([]model.Transaction, error) {
+// CloseExpiredPartialCaptures closes partially captured authorizations whose
+// hold has expired. What was captured stays captured and settles as usual;
+// the uncaptured remainder is released. It returns the closed transactions.
+func (r *TransactionRepository) CloseExpiredPartialCaptures() ([]model.Transaction, error) {
 	var txns []model.Transaction
 	if err := r.db.Where("status = ? AND expires_at < ?",
-		model.TransactionStatusAuthorized,
+		model.TransactionStatusPartiallyCaptured,
 		time.Now()).
 		Find(&txns).Error; err != nil {
 		return nil, err
 	}
+	if len(txns) == 0 {
+		return txns, nil
+	}
+
+	ids := make([]uuid.UUID, len(txns))
+	for i, txn := range txns {
+		ids[i] = txn.ID
+	}
+	if err := r.db.Model(&model.Transaction{}).
+		Where("id IN ? AND status = ?", ids, model.TransactionStatusPartiallyCaptured).
+		Updates(map[string]interface{}{
+			"status":     model.TransactionStatusCaptured,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		r.invalidateCache(id)
+	}
 	return txns, nil
 }
 
-// FindCapturedForSettlement finds captured transactions for settlement batch
-func (r *TransactionRepository) FindCapturedForSettlement(batchDate time.Time) ([]model.Transaction, error) {
-	startDate := batchDate.Truncate(24 * time.Hour)
-	endDate := startDate.Add(24 * time.Hour)
-
+// FindUnsettledBefore returns captured live transactions and sent refunds
+// not yet in a settlement batch that happened before cutoff. The caller
+// assigns them to a batch day in each merchant's timezone.
+func (r *TransactionRepository) FindUnsettledBefore(cutoff time.Time) ([]model.Transaction, error) {
 	var txns []model.Transaction
-	if err := r.db.Where("status = ? AND captured_at >= ? AND captured_at < ? AND settlement_batch_id IS NULL",
-		model.TransactionStatusCaptured,
-		startDate,
-		endDate).
+	if err := r.db.Where("settlement_batch_id IS NULL AND test_mode = ?", false).
+		Where("(status = ? AND type <> ? AND captured_at < ?) OR (type = ? AND refund_status = ? AND sent_to_issuer_at < ?)",
+			model.TransactionStatusCaptured,
+			model.TransactionTypeRefund,
+			cutoff,
+			model.TransactionTypeRefund,
+			model.RefundStatusSentToIssuer,
+			cutoff).
 		Find(&txns).Error; err != nil {
 		return nil, err
 	}
@@ -135,6 +254,16 @@ func (r *TransactionRepository) GetTransactionEvents(txnID uuid.UUID) ([]model.T
 	return events, nil
 }
 
+func (r *TransactionRepository) GetIssuerResponses(txnID uuid.UUID) ([]model.IssuerResponse, error) {
+	var responses []model.IssuerResponse
+	if err := r.db.Where("transaction_id = ?", txnID).
+		Order("created_at ASC").
+		Find(&responses).Error; err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
 // Update Operations
 func (r *TransactionRepository) Update(txn *model.Transaction) error {
 	if err := r.db.Save(txn).Error; err != nil {
@@ -180,31 +309,161 @@ func (r *TransactionRepository) MarkAuthorized(id uuid.UUID, authCode string) er
 	return nil
 }
 
-func (r *TransactionRepository) MarkCaptured(id uuid.UUID, amount int64) error {
+// ReserveCapture claims an authorization for a capture before it is sent to
+// the acquirer, and stores the capture as pending. Only one capture can be
+// reserved at a time, so two racing captures can't both reach the issuer.
+// It reports false when the authorization is no longer capturable, the
+// capture would exceed the authorized amount, or another capture is in
+// progress.
+func (r *TransactionRepository) ReserveCapture(capture *model.TransactionCapture) (bool, error) {
+	reserved := false
 	now := time.Now()
-	if err := r.db.Model(&model.Transaction{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":          model.TransactionStatusCaptured,
-			"captured_at":     now,
-			"captured_amount": amount,
-			"updated_at":      now,
-		}).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Transaction{}).
+			Where("id = ? AND status IN ? AND captured_amount + ? <= amount AND (capture_pending_at IS NULL OR capture_pending_at < ?)",
+				capture.TransactionID,
+				[]model.TransactionStatus{model.TransactionStatusAuthorized, model.TransactionStatusPartiallyCaptured},
+				capture.Amount,
+				now.Add(-model.CaptureReservationTimeout)).
+			Updates(map[string]interface{}{
+				"capture_pending_at": now,
+				"updated_at":         now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		// Drop a reservation that was abandoned mid-flight
+		if err := tx.Where("transaction_id = ? AND status = ?", capture.TransactionID, model.CaptureStatusPending).
+			Delete(&model.TransactionCapture{}).Error; err != nil {
+			return err
+		}
+
+		// The update above locks the row, so concurrent captures number in order
+		if err := tx.Model(&model.TransactionCapture{}).
+			Where("transaction_id = ?", capture.TransactionID).
+			Select("COALESCE(MAX(sequence), 0) + 1").
+			Scan(&capture.Sequence).Error; err != nil {
+			return err
+		}
+		capture.Status = model.CaptureStatusPending
+		if err := tx.Create(capture).Error; err != nil {
+			return err
+		}
+		reserved = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	r.invalidateCache(capture.TransactionID)
+	return reserved, nil
+}
+
+// CompleteCapture records a reserved capture the acquirer accepted. A final
+// capture closes the authorization; otherwise it stays open for further
+// captures. It reports false if the reservation was lost in the meantime.
+// after, when set, runs in the same database transaction once the capture
+// is recorded.
+func (r *TransactionRepository) CompleteCapture(capture *model.TransactionCapture, after func(tx *gorm.DB) error) (bool, error) {
+	status := model.TransactionStatusPartiallyCaptured
+	if capture.FinalCapture {
+		status = model.TransactionStatusCaptured
+	}
+
+	completed := false
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TransactionCapture{}).
+			Where("id = ? AND status = ?", capture.ID, model.CaptureStatusPending).
+			Update("status", model.CaptureStatusSucceeded)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.Transaction{}).
+			Where("id = ?", capture.TransactionID).
+			Updates(map[string]interface{}{
+				"status":             status,
+				"captured_at":        now,
+				"captured_amount":    gorm.Expr("captured_amount + ?", capture.Amount),
+				"capture_pending_at": nil,
+				"updated_at":         now,
+			}).Error; err != nil {
+			return err
+		}
+		if after != nil {
+			if err := after(tx); err != nil {
+				return err
+			}
+		}
+		capture.Status = model.CaptureStatusSucceeded
+		completed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	r.invalidateCache(capture.TransactionID)
+	return completed, nil
+}
+
+// ReleaseCapture drops a reserved capture the acquirer refused, leaving the
+// authorization as it was
+func (r *TransactionRepository) ReleaseCapture(capture *model.TransactionCapture) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND status = ?", capture.ID, model.CaptureStatusPending).
+			Delete(&model.TransactionCapture{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Another capture took over the abandoned reservation
+			return nil
+		}
+		return tx.Model(&model.Transaction{}).
+			Where("id = ?", capture.TransactionID).
+			Updates(map[string]interface{}{
+				"capture_pending_at": nil,
+				"updated_at":         time.Now(),
+			}).Error
+	})
+	if err != nil {
 		return err
 	}
 
-	r.invalidateCache(id)
+	r.invalidateCache(capture.TransactionID)
 	return nil
 }
 
-func (r *TransactionRepository) MarkVoided(id uuid.UUID) error {
+// FindCaptures returns an authorization's accepted captures, oldest first
+func (r *TransactionRepository) FindCaptures(txnID uuid.UUID) ([]model.TransactionCapture, error) {
+	var captures []model.TransactionCapture
+	if err := r.db.Where("transaction_id = ? AND status = ?", txnID, model.CaptureStatusSucceeded).
+		Order("sequence ASC").
+		Find(&captures).Error; err != nil {
+		return nil, err
+	}
+	return captures, nil
+}
+
+func (r *TransactionRepository) MarkVoided(id uuid.UUID, reason model.VoidReason) error {
 	now := time.Now()
 	if err := r.db.Model(&model.Transaction{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"status":     model.TransactionStatusVoided,
-			"voided_at":  now,
-			"updated_at": now,
+			"status":      model.TransactionStatusVoided,
+			"void_reason": reason,
+			"voided_at":   now,
+			"updated_at":  now,
 		}).Error; err != nil {
 		return err
 	}
@@ -213,32 +472,216 @@ func (r *TransactionRepository) MarkVoided(id uuid.UUID) error {
 	return nil
 }
 
-func (r *TransactionRepository) AddRefundAmount(id uuid.UUID, refundAmount int64) error {
-	// Get current transaction
-	txn, err := r.FindByID(id)
+// AddRefundAmount adds a sent refund to the transaction's refunded amount.
+// after, when set, runs in the same database transaction.
+func (r *TransactionRepository) AddRefundAmount(id uuid.UUID, refundAmount int64, after func(tx *gorm.DB) error) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var txn model.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&txn).Error; err != nil {
+			return err
+		}
+
+		newRefundedAmount := txn.RefundedAmount + refundAmount
+
+		// Determine new status
+		var newStatus model.TransactionStatus
+		if newRefundedAmount >= txn.CapturedAmount {
+			newStatus = model.TransactionStatusRefunded
+		} else {
+			newStatus = model.TransactionStatusPartiallyRefunded
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.Transaction{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"refunded_amount": newRefundedAmount,
+				"status":          newStatus,
+				"refunded_at":     now,
+				"updated_at":      now,
+			}).Error; err != nil {
+			return err
+		}
+
+		if after != nil {
+			return after(tx)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	newRefundedAmount := txn.RefundedAmount + refundAmount
+	r.invalidateCache(id)
+	return nil
+}
+
+// =========================================================================
+// Refund Tracking
+// =========================================================================
 
-	// Determine new status
-	var newStatus model.TransactionStatus
-	if newRefundedAmount >= txn.CapturedAmount {
-		newStatus = model.TransactionStatusRefunded
-	} else {
-		newStatus = model.TransactionStatusPartiallyRefunded
+func (r *TransactionRepository) FindRefundByIDAndMerchant(id, merchantID uuid.UUID) (*model.Transaction, error) {
+	var txn model.Transaction
+	if err := r.db.Where("id = ? AND merchant_id = ? AND type = ?", id, merchantID, model.TransactionTypeRefund).
+		First(&txn).Error; err != nil {
+		return nil, err
 	}
+	return &txn, nil
+}
 
-	now := time.Now()
+// FindRefundsByParent lists the refunds issued against a transaction, oldest first
+func (r *TransactionRepository) FindRefundsByParent(parentID, merchantID uuid.UUID) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	if err := r.db.Where("parent_transaction_id = ? AND merchant_id = ? AND type = ?",
+		parentID, merchantID, model.TransactionTypeRefund).
+		Order("created_at ASC").
+		Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// RefundFilter narrows a merchant's refund listing; zero fields match everything
+type RefundFilter struct {
+	TransactionID *uuid.UUID
+	Status        model.RefundStatus
+	ReasonCode    model.RefundReasonCode
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	TestMode      bool // Sandbox refunds instead of live ones
+	Limit         int
+	Offset        int
+}
+
+// FindRefundsByMerchant lists a merchant's refunds, newest first, with the
+// total matching the filter
+func (r *TransactionRepository) FindRefundsByMerchant(merchantID uuid.UUID, filter RefundFilter) ([]model.Transaction, int64, error) {
+	query := r.db.Model(&model.Transaction{}).
+		Where("merchant_id = ? AND type = ? AND test_mode = ?", merchantID, model.TransactionTypeRefund, filter.TestMode)
+	if filter.TransactionID != nil {
+		query = query.Where("parent_transaction_id = ?", *filter.TransactionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("refund_status = ?", filter.Status)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("refund_reason_code = ?", filter.ReasonCode)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var txns []model.Transaction
+	if err := query.Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&txns).Error; err != nil {
+		return nil, 0, err
+	}
+	return txns, total, nil
+}
+
+// SumRefundsByParent totals the MAD refunded and processing fee reversed by
+// a transaction's refunds, leaving out refunds that failed
+func (r *TransactionRepository) SumRefundsByParent(parentID uuid.UUID) (int64, int64, error) {
+	var result struct {
+		RefundedMAD int64
+		ReversedFee int64
+	}
 	if err := r.db.Model(&model.Transaction{}).
-		Where("id = ?", id).
+		Where("parent_transaction_id = ? AND type = ? AND refund_status <> ?",
+			parentID, model.TransactionTypeRefund, model.RefundStatusFailed).
+		Select("COALESCE(SUM(-amount_mad), 0) AS refunded_mad, COALESCE(SUM(-processing_fee), 0) AS reversed_fee").
+		Scan(&result).Error; err != nil {
+		return 0, 0, err
+	}
+	return result.RefundedMAD, result.ReversedFee, nil
+}
+
+// FindUnsettledForMerchant returns every captured live transaction and sent
+// refund of a merchant not yet in a settlement batch, whatever the date
+func (r *TransactionRepository) FindUnsettledForMerchant(merchantID uuid.UUID) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	if err := r.db.Where("merchant_id = ? AND settlement_batch_id IS NULL AND test_mode = ?", merchantID, false).
+		Where("(status = ? AND type <> ?) OR (type = ? AND refund_status = ?)",
+			model.TransactionStatusCaptured,
+			model.TransactionTypeRefund,
+			model.TransactionTypeRefund,
+			model.RefundStatusSentToIssuer).
+		Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+func (r *TransactionRepository) MarkRefundSent(id uuid.UUID, sentAt, estimatedArrival time.Time) error {
+	return r.updateRefund(id, map[string]interface{}{
+		"refund_status":        model.RefundStatusSentToIssuer,
+		"sent_to_issuer_at":    sentAt,
+		"estimated_arrival_at": estimatedArrival,
+	})
+}
+
+func (r *TransactionRepository) MarkRefundFailed(id uuid.UUID, reason string) error {
+	return r.updateRefund(id, map[string]interface{}{
+		"refund_status":    model.RefundStatusFailed,
+		"status":           model.TransactionStatusFailed,
+		"response_message": reason,
+	})
+}
+
+func (r *TransactionRepository) MarkRefundSettled(id uuid.UUID, settledAt, estimatedArrival time.Time) error {
+	return r.updateRefund(id, map[string]interface{}{
+		"refund_status":        model.RefundStatusSettled,
+		"settled_at":           settledAt,
+		"estimated_arrival_at": estimatedArrival,
+	})
+}
+
+// MarkBatchRefundsSettled settles every in-flight refund in a batch and
+// returns how many were updated
+func (r *TransactionRepository) MarkBatchRefundsSettled(batchID uuid.UUID, settledAt, estimatedArrival time.Time) (int64, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&model.Transaction{}).
+		Where("settlement_batch_id = ? AND type = ? AND refund_status = ?",
+			batchID, model.TransactionTypeRefund, model.RefundStatusSentToIssuer).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := r.db.Model(&model.Transaction{}).
+		Where("id IN ?", ids).
 		Updates(map[string]interface{}{
-			"refunded_amount": newRefundedAmount,
-			"status":          newStatus,
-			"refunded_at":     now,
-			"updated_at":      now,
+			"refund_status":        model.RefundStatusSettled,
+			"settled_at":           settledAt,
+			"estimated_arrival_at": estimatedArrival,
+			"updated_at":           time.Now(),
 		}).Error; err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		r.invalidateCache(id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *TransactionRepository) updateRefund(id uuid.UUID, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	if err := r.db.Model(&model.Transaction{}).
+		Where("id = ? AND type = ?", id, model.TransactionTypeRefund).
+		Updates(updates).Error; err != nil {
 		return err
 	}
 
@@ -266,73 +709,290 @@ func (r *TransactionRepository) LinkToSettlementBatch(txnIDs []uuid.UUID, batchI
 	return nil
 }
 
+// ErrTransactionsAlreadySettled means another batch linked some of the
+// transactions first
+var ErrTransactionsAlreadySettled = errors.New("transactions were settled by another batch")
+
+// CreateSettlementBatch saves the batch and links the transactions to it in
+// one database transaction. Unlike LinkToSettlementBatch it only takes
+// transactions no batch holds yet, and saves nothing if any were taken.
+// before, when set, runs first in the same transaction and may still change
+// the batch.
+func (r *TransactionRepository) CreateSettlementBatch(batch *model.SettlementBatch, txnIDs []uuid.UUID, before func(tx *gorm.DB) error) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if before != nil {
+			if err := before(tx); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(txnIDs) == 0 {
+			return nil
+		}
+		now := time.Now()
+		result := tx.Model(&model.Transaction{}).
+			Where("id IN ? AND settlement_batch_id IS NULL", txnIDs).
+			Updates(map[string]interface{}{
+				"settlement_batch_id": batch.ID,
+				"status":              model.TransactionStatusSettled,
+				"settled_at":          now,
+				"updated_at":          now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(txnIDs)) {
+			return ErrTransactionsAlreadySettled
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range txnIDs {
+		r.invalidateCache(id)
+	}
+	return nil
+}
+
+// FindByIDs loads the transactions with the given ids, across merchants
+func (r *TransactionRepository) FindByIDs(ids []uuid.UUID) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	if len(ids) == 0 {
+		return txns, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// MarkCleared records that the network's clearing file included the
+// transactions
+func (r *TransactionRepository) MarkCleared(ids []uuid.UUID, clearedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Model(&model.Transaction{}).
+		Where("id IN ?", ids).
+		Update("cleared_at", clearedAt).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		r.invalidateCache(id)
+	}
+	return nil
+}
+
+// FindUnclearedCaptures returns transactions captured through connector in
+// [from, to) that no clearing file has included
+func (r *TransactionRepository) FindUnclearedCaptures(connector string, from, to time.Time) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	if err := r.db.Where("connector = ? AND captured_at >= ? AND captured_at < ? AND cleared_at IS NULL", connector, from, to).
+		Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// FindUnclearedRefunds returns refunds sent through connector in [from, to)
+// that no clearing file has included
+func (r *TransactionRepository) FindUnclearedRefunds(connector string, from, to time.Time) ([]model.Transaction, error) {
+	var txns []model.Transaction
+	if err := r.db.Where("connector = ? AND type = ? AND refund_status IN ? AND sent_to_issuer_at >= ? AND sent_to_issuer_at < ? AND cleared_at IS NULL",
+		connector, model.TransactionTypeRefund,
+		[]model.RefundStatus{model.RefundStatusSentToIssuer, model.RefundStatusSettled}, from, to).
+		Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
+
+// CountCapturedSince counts the merchant's captured payments since the given
+// time, the denominator of its chargeback rate
+func (r *TransactionRepository) CountCapturedSince(merchantID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Transaction{}).
+		Where("merchant_id = ? AND type <> ? AND captured_at >= ?", merchantID, model.TransactionTypeRefund, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountUnclearedInBatch counts the batch's captures and refunds through
+// connector that no clearing file has included yet
+func (r *TransactionRepository) CountUnclearedInBatch(batchID uuid.UUID, connector string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Transaction{}).
+		Where("settlement_batch_id = ? AND connector = ? AND (captured_at IS NOT NULL OR sent_to_issuer_at IS NOT NULL) AND cleared_at IS NULL", batchID, connector).
+		Count(&count).Error
+	return count, err
+}
+
 // Statistics
+
+// TransactionStatistics aggregates payment transactions. Refunds are rows of
+// their own but count through the payment they return. Amounts are MAD
+// cents, converted at each payment's rate, and rates are percentages.
 type TransactionStatistics struct {
+	TotalTransactions int64   `json:"total_transactions"`
+	TotalAmountMAD    int64   `json:"total_amount_mad"`
+	AuthorizedAmount  int64   `json:"authorized_amount"` // Authorized, not captured yet
+	CapturedAmount    int64   `json:"captured_amount"`
+	RefundedAmount    int64   `json:"refunded_amount"`
+	SettledAmount     int64   `json:"settled_amount"`
+	AverageFraudScore float64 `json:"average_fraud_score"`
+	SuccessRate       float64 `json:"success_rate"`
+	RefundRate        float64 `json:"refund_rate"` // Share of captured payments with a refund
+}
+
+// TransactionStatisticsBucket is the statistics of one day or week. Date is
+// midnight UTC on the bucket's first local calendar day.
+type TransactionStatisticsBucket struct {
+	Date time.Time
+	TransactionStatistics
+}
+
+// Rows in past ranges still change when they are captured, refunded or
+// settled, so every range is only cached briefly
+const statisticsCacheTTL = time.Minute
+
+// statisticsRow is what statisticsColumns selects
+type statisticsRow struct {
 	TotalTransactions int64
-	TotalAmount       int64
 	TotalAmountMAD    int64
 	AuthorizedAmount  int64
 	CapturedAmount    int64
 	RefundedAmount    int64
 	SettledAmount     int64
 	AverageFraudScore float64
-	SuccessRate       float64
+	SuccessCount      int64
+	CapturedCount     int64
+	RefundedCount     int64
 }
 
-func (r *TransactionRepository) GetStatistics(merchantID uuid.UUID, startDate, endDate time.Time) (*TransactionStatistics, error) {
-	stats := &TransactionStatistics{}
-
-	query := r.db.Model(&model.Transaction{}).
-		Where("merchant_id = ? AND created_at BETWEEN ? AND ?", merchantID, startDate, endDate)
-
-	// Total transactions
-	query.Count(&stats.TotalTransactions)
+// statisticsColumns selects a statisticsRow. Payments that were refunded
+// in full or in part still count as successful.
+func statisticsColumns() (string, []interface{}) {
+	return `COUNT(*) AS total_transactions,
+			COALESCE(SUM(amount_mad), 0) AS total_amount_mad,
+			COALESCE(SUM(amount_mad) FILTER (WHERE status = ?), 0) AS authorized_amount,
+			COALESCE(SUM(captured_amount * amount_mad / amount), 0) AS captured_amount,
+			COALESCE(SUM(refunded_amount * amount_mad / amount), 0) AS refunded_amount,
+			COALESCE(SUM(captured_amount * amount_mad / amount) FILTER (WHERE status = ? OR settled_at IS NOT NULL), 0) AS settled_amount,
+			COALESCE(AVG(fraud_score), 0) AS average_fraud_score,
+			COUNT(*) FILTER (WHERE status IN ?) AS success_count,
+			COUNT(*) FILTER (WHERE captured_amount > 0) AS captured_count,
+			COUNT(*) FILTER (WHERE refunded_amount > 0) AS refunded_count`,
+		[]interface{}{
+			model.TransactionStatusAuthorized,
+			model.TransactionStatusSettled,
+			[]model.TransactionStatus{
+				model.TransactionStatusAuthorized,
+				model.TransactionStatusPartiallyCaptured,
+				model.TransactionStatusCaptured,
+				model.TransactionStatusSettled,
+				model.TransactionStatusPartiallyRefunded,
+				model.TransactionStatusRefunded,
+			},
+		}
+}
 
-	// Total amount in original currency
-	query.Select("COALESCE(SUM(amount), 0)").Scan(&stats.TotalAmount)
+func (row *statisticsRow) statistics() TransactionStatistics {
+	stats := TransactionStatistics{
+		TotalTransactions: row.TotalTransactions,
+		TotalAmountMAD:    row.TotalAmountMAD,
+		AuthorizedAmount:  row.AuthorizedAmount,
+		CapturedAmount:    row.CapturedAmount,
+		RefundedAmount:    row.RefundedAmount,
+		SettledAmount:     row.SettledAmount,
+		AverageFraudScore: row.AverageFraudScore,
+	}
+	if row.TotalTransactions > 0 {
+		stats.SuccessRate = float64(row.SuccessCount) / float64(row.TotalTransactions) * 100
+	}
+	if row.CapturedCount > 0 {
+		stats.RefundRate = float64(row.RefundedCount) / float64(row.CapturedCount) * 100
+	}
+	return stats
+}
 
-	// Total amount in MAD
-	query.Select("COALESCE(SUM(amount_mad), 0)").Scan(&stats.TotalAmountMAD)
+// statisticsScope is a merchant's payment transactions in one mode created
+// in [startDate, endDate)
+func (r *TransactionRepository) statisticsScope(merchantID uuid.UUID, testMode bool, startDate, endDate time.Time) *gorm.DB {
+	return r.db.Model(&model.Transaction{}).
+		Where("merchant_id = ? AND test_mode = ? AND type <> ? AND amount > 0", merchantID, testMode, model.TransactionTypeRefund).
+		Where("created_at >= ? AND created_at < ?", startDate, endDate)
+}
 
-	// Authorized amount
-	r.db.Model(&model.Transaction{}).
-		Where("merchant_id = ? AND status = ? AND created_at BETWEEN ? AND ?",
-			merchantID, model.TransactionStatusAuthorized, startDate, endDate).
-		Select("COALESCE(SUM(amount_mad), 0)").
-		Scan(&stats.AuthorizedAmount)
+// GetStatistics aggregates a merchant's payment transactions made in the
+// given mode and created in [startDate, endDate) in a single query.
+// Day-aligned ranges, which is what dashboards ask for, are cached in Redis
+// for a minute.
+func (r *TransactionRepository) GetStatistics(merchantID uuid.UUID, testMode bool, startDate, endDate time.Time) (*TransactionStatistics, error) {
+	cacheable := isDayAligned(startDate) && isDayAligned(endDate)
+	key := fmt.Sprintf("transaction_stats:%s:%t:%d:%d", merchantID, testMode, startDate.Unix(), endDate.Unix())
+	if cacheable {
+		if data, err := inits.RDB.Get(r.ctx, key).Result(); err == nil {
+			var stats TransactionStatistics
+			if json.Unmarshal([]byte(data), &stats) == nil {
+				return &stats, nil
+			}
+		}
+	}
 
-	// Captured amount
-	r.db.Model(&model.Transaction{}).
-		Where("merchant_id = ? AND status IN ? AND created_at BETWEEN ? AND ?",
-			merchantID, []model.TransactionStatus{model.TransactionStatusCaptured, model.TransactionStatusSettled},
-			startDate, endDate).
-		Select("COALESCE(SUM(captured_amount), 0)").
-		Scan(&stats.CapturedAmount)
+	columns, args := statisticsColumns()
+	var row statisticsRow
+	if err := r.statisticsScope(merchantID, testMode, startDate, endDate).
+		Select(columns, args...).
+		Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	stats := row.statistics()
 
-	// Refunded amount
-	r.db.Model(&model.Transaction{}).
-		Where("merchant_id = ? AND created_at BETWEEN ? AND ?",
-			merchantID, startDate, endDate).
-		Select("COALESCE(SUM(refunded_amount), 0)").
-		Scan(&stats.RefundedAmount)
+	if cacheable {
+		if data, err := json.Marshal(stats); err == nil {
+			inits.RDB.Set(r.ctx, key, data, statisticsCacheTTL)
+		}
+	}
 
-	// Average fraud score
-	query.Select("COALESCE(AVG(fraud_score), 0)").Scan(&stats.AverageFraudScore)
+	return &stats, nil
+}
 
-	// Success rate
-	var successCount int64
-	r.db.Model(&model.Transaction{}).
-		Where("merchant_id = ? AND status IN ? AND created_at BETWEEN ? AND ?",
-			merchantID,
-			[]model.TransactionStatus{model.TransactionStatusAuthorized, model.TransactionStatusCaptured, model.TransactionStatusSettled},
-			startDate, endDate).
-		Count(&successCount)
+// GetStatisticsBuckets is GetStatistics split into the days or weeks
+// (interval "day" or "week") of timezone, oldest first. Weeks start on
+// Monday. Buckets without transactions are left out.
+func (r *TransactionRepository) GetStatisticsBuckets(merchantID uuid.UUID, testMode bool, startDate, endDate time.Time, interval, timezone string) ([]TransactionStatisticsBucket, error) {
+	columns, args := statisticsColumns()
+	var rows []struct {
+		Bucket time.Time
+		Row    statisticsRow `gorm:"embedded"`
+	}
+	if err := r.statisticsScope(merchantID, testMode, startDate, endDate).
+		Select("date_trunc(?, created_at AT TIME ZONE ?) AS bucket, "+columns,
+			append([]interface{}{interval, timezone}, args...)...).
+		Group("bucket").
+		Order("bucket ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
 
-	if stats.TotalTransactions > 0 {
-		stats.SuccessRate = float64(successCount) / float64(stats.TotalTransactions) * 100
+	buckets := make([]TransactionStatisticsBucket, 0, len(rows))
+	for _, row := range rows {
+		y, m, d := row.Bucket.Date()
+		buckets = append(buckets, TransactionStatisticsBucket{
+			Date:                  time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+			TransactionStatistics: row.Row.statistics(),
+		})
 	}
+	return buckets, nil
+}
 
-	return stats, nil
+func isDayAligned(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
 }
 
 // =========================================================================
@@ -342,7 +1002,8 @@ func (r *TransactionRepository) GetStatistics(merchantID uuid.UUID, startDate, e
 func (r *TransactionRepository) cacheTransaction(txn *model.Transaction) {
 	key := fmt.Sprintf("transaction:%s", txn.ID.String())
 	data, _ := json.Marshal(txn)
-	inits.RDB.Set(r.ctx, key, data, 5*time.Minute)
+	// Runs after the request may have returned
+	inits.RDB.Set(context.WithoutCancel(r.ctx), key, data, 5*time.Minute)
 }
 
 func (r *TransactionRepository) getCachedTransaction(id uuid.UUID) *model.Transaction {
@@ -362,5 +1023,6 @@ func (r *TransactionRepository) getCachedTransaction(id uuid.UUID) *model.Transa
 
 func (r *TransactionRepository) invalidateCache(id uuid.UUID) {
 	key := fmt.Sprintf("transaction:%s", id.String())
-	inits.RDB.Del(r.ctx, key)
+	// A write that landed must not leave the old copy cached
+	inits.RDB.Del(context.WithoutCancel(r.ctx), key)
 }
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidAuthenticationRequest = errors.New("invalid authentication request")
+
+// AuthenticationService runs 3-D Secure with the card issuer's ACS. Only the
+// card simulator is wired up; acquirer connectors authorize with the ECI and
+// CAVV it returns.
+type AuthenticationService struct {
+	acs *client.CardSimulatorClient
+}
+
+func NewAuthenticationService() *AuthenticationService {
+	return &AuthenticationService{
+		acs: client.NewCardSimulatorClient(),
+	}
+}
+
+// Authenticate starts 3-D Secure for a card. A "C" outcome means the
+// cardholder must complete a challenge at ACSURL first.
+func (s *AuthenticationService) Authenticate(ctx context.Context, req *client.AuthenticateCardRequest) (*client.AuthenticateCardResponse, error) {
+	if req.MerchantID == "" || req.CardLast4 == "" || req.Amount <= 0 {
+		return nil, ErrInvalidAuthenticationRequest
+	}
+
+	resp, err := s.acs.Authenticate(ctx, req)
+	if err != nil {
+		logger.Log.Error("3-D Secure authentication failed",
+			zap.String("merchant_id", req.MerchantID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CompleteAuthentication returns the outcome of a challenge from the CRes
+// the ACS posted back
+func (s *AuthenticationService) CompleteAuthentication(ctx context.Context, req *client.CompleteChallengeRequest) (*client.AuthenticateCardResponse, error) {
+	if req.DSTransactionID == "" || req.CRes == "" {
+		return nil, ErrInvalidAuthenticationRequest
+	}
+	return s.acs.CompleteChallenge(ctx, req)
+}
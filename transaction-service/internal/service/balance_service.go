@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// reserveReleaseBatchSize bounds how many reserve holds one release run loads
+// at a time
+const reserveReleaseBatchSize = 500
+
+// BalanceService keeps each merchant's balance: settlement batches credit
+// captures and debit refunds, fees and payouts, disputes debit it when they
+// open, and the rolling reserve moves funds out of the available balance
+// until their hold period is over.
+type BalanceService struct {
+	balanceRepo *repository.BalanceRepository
+	txnRepo     *repository.TransactionRepository
+	ledger      *LedgerService
+}
+
+func NewBalanceService() *BalanceService {
+	return &BalanceService{
+		balanceRepo: repository.NewBalanceRepository(),
+		txnRepo:     repository.NewTransactionRepository(),
+		ledger:      NewLedgerService(),
+	}
+}
+
+// MerchantBalance is a merchant's balance in MAD minor units. Pending is
+// captured but not batched yet, net of fees, and isn't in the ledger until
+// it is batched.
+type MerchantBalance struct {
+	MerchantID        uuid.UUID
+	Available         int64
+	Reserve           int64
+	Pending           int64
+	NextReleaseAt     sql.NullTime // When the oldest reserve hold is due
+	NextReleaseAmount int64
+	PendingCount      int // Transactions not yet batched
+}
+
+// GetBalance returns the merchant's current balance
+func (s *BalanceService) GetBalance(merchantID uuid.UUID) (*MerchantBalance, error) {
+	totals, err := s.balanceRepo.Totals(nil, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total balance: %w", err)
+	}
+
+	unsettled, err := s.txnRepo.FindUnsettledForMerchant(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unsettled transactions: %w", err)
+	}
+	pending := newSettlementBatch(merchantID, settlementPeriod{}, unsettled)
+
+	balance := &MerchantBalance{
+		MerchantID:   merchantID,
+		Available:    totals.Available,
+		Reserve:      totals.Reserve,
+		Pending:      pending.NetAmount,
+		PendingCount: len(unsettled),
+	}
+
+	next, err := s.balanceRepo.NextReserveRelease(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find next reserve release: %w", err)
+	}
+	if next != nil {
+		balance.NextReleaseAt = next.ReleaseAt
+		balance.NextReleaseAmount = next.Reserve
+	}
+	return balance, nil
+}
+
+// available returns what the merchant's balance could pay out right now
+func (s *BalanceService) available(merchantID uuid.UUID) (int64, error) {
+	totals, err := s.balanceRepo.Totals(nil, merchantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to total balance: %w", err)
+	}
+	return totals.Available, nil
+}
+
+// batchBalanceEntries are the entries a new batch posts before its payout:
+// captures in, refunds and fees out, and the reserve moved aside until
+// releaseAt. They add up to the batch's net amount.
+func batchBalanceEntries(batch *model.SettlementBatch, releaseAt time.Time) []model.MerchantBalanceEntry {
+	batchID := sql.NullString{String: batch.ID.String(), Valid: true}
+	entry := func(entryType model.BalanceEntryType, available, reserve int64) model.MerchantBalanceEntry {
+		return model.MerchantBalanceEntry{
+			ID:                uuid.New(),
+			MerchantID:        batch.MerchantID,
+			Type:              entryType,
+			Available:         available,
+			Reserve:           reserve,
+			SettlementBatchID: batchID,
+		}
+	}
+
+	var entries []model.MerchantBalanceEntry
+	if batch.GrossAmount != 0 {
+		entries = append(entries, entry(model.BalanceEntryCapture, batch.GrossAmount, 0))
+	}
+	if batch.RefundAmount != 0 {
+		entries = append(entries, entry(model.BalanceEntryRefund, -batch.RefundAmount, 0))
+	}
+	if fees := batch.FeeAmount - batch.FeeReversalAmount + batch.PayoutFee; fees != 0 {
+		entries = append(entries, entry(model.BalanceEntryFee, -fees, 0))
+	}
+	if batch.ReserveAmount > 0 {
+		hold := entry(model.BalanceEntryReserveHold, -batch.ReserveAmount, batch.ReserveAmount)
+		hold.ReleaseAt = sql.NullTime{Time: releaseAt, Valid: true}
+		entries = append(entries, hold)
+	}
+	return entries
+}
+
+// postBatch posts a new batch to the merchant's balance inside tx, and
+// settles what it pays out against the balance: a merchant left negative by
+// refunds or disputes is paid that much less, and reserve released since the
+// last batch is paid on top. The payout never goes below zero; whatever is
+// still owed stays on the balance for the next batch.
+func (s *BalanceService) postBatch(tx *gorm.DB, batch *model.SettlementBatch, reserveDays int) error {
+	if err := s.balanceRepo.Lock(tx, batch.MerchantID); err != nil {
+		return err
+	}
+	totals, err := s.balanceRepo.Totals(tx, batch.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	if batch.ID == uuid.Nil {
+		batch.ID = uuid.New()
+	}
+	releaseAt := batch.SettlementDate.AddDate(0, 0, reserveDays)
+	entries := batchBalanceEntries(batch, releaseAt)
+
+	applyBalance(batch, totals.Available)
+	if payout := batch.NetAmount; payout > 0 {
+		entries = append(entries, model.MerchantBalanceEntry{
+			ID:                uuid.New(),
+			MerchantID:        batch.MerchantID,
+			Type:              model.BalanceEntryPayout,
+			Available:         -payout,
+			SettlementBatchID: sql.NullString{String: batch.ID.String(), Valid: true},
+		})
+	}
+
+	if err := s.balanceRepo.CreateEntries(tx, entries); err != nil {
+		return err
+	}
+	return s.ledger.postSettlement(tx, batch)
+}
+
+// applyBalance settles the batch's payout against the merchant's available
+// balance before the batch. Calling it again with the same balance changes
+// nothing, so a quote can preview it and the batch can then apply it.
+func applyBalance(batch *model.SettlementBatch, available int64) {
+	base := batch.NetAmount - batch.BalanceAdjustment
+	payout := max(available+base, 0)
+	batch.BalanceAdjustment = payout - base
+	batch.NetAmount = payout
+}
+
+// amountMAD converts an amount in the transaction's currency to MAD at the
+// rate the transaction was booked at
+func amountMAD(amount int64, txn *model.Transaction) int64 {
+	if txn.Currency == "MAD" || txn.Amount == 0 {
+		return amount
+	}
+	return amount * txn.AmountMAD / txn.Amount
+}
+
+// PostChargeback takes a newly opened dispute's amount and fee from the
+// merchant's balance. Sandbox transactions never touch the balance.
+func (s *BalanceService) PostChargeback(chargeback *model.Chargeback, txn *model.Transaction) error {
+	if txn.TestMode {
+		return nil
+	}
+
+	chargebackID := sql.NullString{String: chargeback.ID.String(), Valid: true}
+	amount, fee := amountMAD(chargeback.Amount, txn), amountMAD(chargeback.ChargebackFee, txn)
+	return s.balanceRepo.Transaction(chargeback.MerchantID, func(tx *gorm.DB) error {
+		err := s.balanceRepo.CreateEntries(tx, []model.MerchantBalanceEntry{
+			{
+				ID:           uuid.New(),
+				MerchantID:   chargeback.MerchantID,
+				Type:         model.BalanceEntryChargeback,
+				Available:    -amount,
+				ChargebackID: chargebackID,
+				Description:  fmt.Sprintf("Dispute %s on transaction %s", chargeback.ID, chargeback.TransactionID),
+			},
+			{
+				ID:           uuid.New(),
+				MerchantID:   chargeback.MerchantID,
+				Type:         model.BalanceEntryFee,
+				Available:    -fee,
+				ChargebackID: chargebackID,
+				Description:  fmt.Sprintf("Chargeback fee for dispute %s", chargeback.ID),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return s.ledger.postChargeback(tx, chargeback, amount, fee)
+	})
+}
+
+// PostChargebackReversal gives back the disputed amount once the merchant
+// wins; the chargeback fee stays charged. It is safe to call more than once.
+func (s *BalanceService) PostChargebackReversal(chargeback *model.Chargeback) error {
+	return s.balanceRepo.Transaction(chargeback.MerchantID, func(tx *gorm.DB) error {
+		taken, err := s.balanceRepo.ChargebackTotal(tx, chargeback.ID)
+		if err != nil || taken >= 0 {
+			return err
+		}
+		err = s.balanceRepo.CreateEntries(tx, []model.MerchantBalanceEntry{{
+			ID:           uuid.New(),
+			MerchantID:   chargeback.MerchantID,
+			Type:         model.BalanceEntryChargebackReversal,
+			Available:    -taken,
+			ChargebackID: sql.NullString{String: chargeback.ID.String(), Valid: true},
+			Description:  fmt.Sprintf("Dispute %s won", chargeback.ID),
+		}})
+		if err != nil {
+			return err
+		}
+		return s.ledger.postChargebackResolution(tx, chargeback, true)
+	})
+}
+
+// ReleaseDueReserves moves every reserve hold whose period is over back to
+// the available balance. The released funds are paid out with the
+// merchant's next batch.
+func (s *BalanceService) ReleaseDueReserves(ctx context.Context) error {
+	released := 0
+	for {
+		holds, err := s.balanceRepo.FindDueReserveHolds(time.Now(), reserveReleaseBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find due reserve holds: %w", err)
+		}
+
+		progress := 0
+		for _, hold := range holds {
+			if err := s.releaseHold(hold); err != nil {
+				// Left for the next run rather than retried here
+				logger.Log.Error("Failed to release reserve hold",
+					zap.String("hold_id", hold.ID.String()),
+					zap.String("merchant_id", hold.MerchantID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			progress++
+		}
+		released += progress
+
+		if len(holds) < reserveReleaseBatchSize || progress == 0 || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if released > 0 {
+		logger.Log.Info("Released reserve holds", zap.Int("count", released))
+	}
+	return nil
+}
+
+func (s *BalanceService) releaseHold(hold model.MerchantBalanceEntry) error {
+	return s.balanceRepo.Transaction(hold.MerchantID, func(tx *gorm.DB) error {
+		done, err := s.balanceRepo.IsReleased(tx, hold.ID)
+		if err != nil || done {
+			return err
+		}
+		err = s.balanceRepo.CreateEntries(tx, []model.MerchantBalanceEntry{{
+			ID:                uuid.New(),
+			MerchantID:        hold.MerchantID,
+			Type:              model.BalanceEntryReserveRelease,
+			Available:         hold.Reserve,
+			Reserve:           -hold.Reserve,
+			SettlementBatchID: hold.SettlementBatchID,
+			HoldEntryID:       sql.NullString{String: hold.ID.String(), Valid: true},
+		}})
+		if err != nil {
+			return err
+		}
+		return s.ledger.postReserveRelease(tx, &hold)
+	})
+}
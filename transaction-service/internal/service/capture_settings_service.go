@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// CaptureSettingsService manages which merchants may capture an
+// authorization more than once
+type CaptureSettingsService struct {
+	settingsRepo *repository.CaptureSettingsRepository
+}
+
+func NewCaptureSettingsService() *CaptureSettingsService {
+	return &CaptureSettingsService{
+		settingsRepo: repository.NewCaptureSettingsRepository(),
+	}
+}
+
+// GetMerchantSettings returns a merchant's capture settings and whether they
+// were set explicitly. Merchants without settings are single-capture.
+func (s *CaptureSettingsService) GetMerchantSettings(merchantID uuid.UUID) (*model.MerchantCaptureSettings, bool, error) {
+	settings, err := s.settingsRepo.FindByMerchant(merchantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.MerchantCaptureSettings{MerchantID: merchantID}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return settings, true, nil
+}
+
+// MultiCaptureEnabled reports whether a merchant may take several captures
+// against one authorization
+func (s *CaptureSettingsService) MultiCaptureEnabled(merchantID uuid.UUID) (bool, error) {
+	settings, _, err := s.GetMerchantSettings(merchantID)
+	if err != nil {
+		return false, err
+	}
+	return settings.MultiCaptureEnabled, nil
+}
+
+// SetMerchantSettings allows or denies multi-capture for a merchant.
+// Authorizations already partially captured can still be captured to the end.
+func (s *CaptureSettingsService) SetMerchantSettings(merchantID uuid.UUID, multiCapture bool) (*model.MerchantCaptureSettings, error) {
+	settings := &model.MerchantCaptureSettings{
+		MerchantID:          merchantID,
+		MultiCaptureEnabled: multiCapture,
+	}
+	if err := s.settingsRepo.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save capture settings: %w", err)
+	}
+	return settings, nil
+}
+
+// DeleteMerchantSettings returns a merchant to single capture
+func (s *CaptureSettingsService) DeleteMerchantSettings(merchantID uuid.UUID) error {
+	return s.settingsRepo.Delete(merchantID)
+}
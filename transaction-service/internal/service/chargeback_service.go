@@ -1,28 +1,53 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/storage"
 	"go.uber.org/zap"
 )
 
 type ChargebackService struct {
 	chargebackRepo *repository.ChargebackRepository
 	txnRepo        *repository.TransactionRepository
+	evidenceStore  storage.Store // nil keeps evidence in the database
+	balances       *BalanceService
+	ledger         *LedgerService
 }
 
 func NewChargebackService() *ChargebackService {
+	// Evidence moves out of Postgres only once a backend is chosen, since a
+	// local default would split files across replicas
+	var evidenceStore storage.Store
+	if config.GetEnv("STORAGE_BACKEND") != "" {
+		store, err := storage.FromEnv("")
+		if err != nil {
+			logger.Log.Fatal("Invalid evidence storage configuration", zap.Error(err))
+		}
+		evidenceStore = store
+	}
+
 	return &ChargebackService{
 		chargebackRepo: repository.NewChargebackRepository(),
 		txnRepo:        repository.NewTransactionRepository(),
+		evidenceStore:  evidenceStore,
+		balances:       NewBalanceService(),
+		ledger:         NewLedgerService(),
 	}
 }
 
@@ -47,6 +72,50 @@ type SubmitEvidenceRequest struct {
 	MerchantStatement string
 }
 
+type UploadEvidenceFileRequest struct {
+	ChargebackID uuid.UUID
+	MerchantID   uuid.UUID
+	FileName     string
+	ContentType  string
+	Description  string
+	Content      []byte
+}
+
+type ListDisputesRequest struct {
+	MerchantID    uuid.UUID
+	Status        model.ChargebackStatus
+	TransactionID uuid.UUID // uuid.Nil for every transaction
+	Limit         int
+	Offset        int
+}
+
+// DisputeEvidence is what SubmitEvidence stores in Chargeback.MerchantEvidence
+type DisputeEvidence struct {
+	Evidence          map[string]interface{} `json:"evidence"`
+	MerchantStatement string                 `json:"merchant_statement,omitempty"`
+	FileIDs           []string               `json:"files,omitempty"`
+}
+
+const (
+	// MaxEvidenceFileSize keeps an upload well inside the 4MB gRPC message
+	// limit between payment-api-service and this service
+	MaxEvidenceFileSize = 3 << 20
+	// MaxEvidenceFiles caps the documents attached to one dispute
+	MaxEvidenceFiles = 10
+)
+
+var allowedEvidenceContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"text/plain":      true,
+}
+
+var (
+	ErrDisputeNotFound     = errors.New("dispute not found")
+	ErrDisputeAccessDenied = errors.New("access denied: dispute belongs to different merchant")
+)
+
 type AcceptChargebackRequest struct {
 	ChargebackID uuid.UUID
 	MerchantID   uuid.UUID
@@ -124,13 +193,23 @@ func (s *ChargebackService) CreateChargeback(ctx context.Context, req *CreateCha
 	}
 
 	// Step 7: Log event
-	go s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
+	s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
 		ChargebackID: chargeback.ID,
 		EventType:    "chargeback_created",
 		OldStatus:    "",
 		NewStatus:    model.ChargebackStatusNeedsResponse,
 	})
 
+	// Step 8: Take the disputed amount and fee from the merchant's balance
+	// and post them to the ledger. A failure is logged rather than failing a
+	// dispute the network already opened.
+	if err := s.balances.PostChargeback(chargeback, txn); err != nil {
+		logger.Log.Error("Failed to post chargeback to merchant balance",
+			zap.String("chargeback_id", chargeback.ID.String()),
+			zap.Error(err),
+		)
+	}
+
 	logger.Log.Info("Chargeback created",
 		zap.String("chargeback_id", chargeback.ID.String()),
 		zap.String("transaction_id", req.TransactionID.String()),
@@ -167,9 +246,26 @@ func (s *ChargebackService) SubmitEvidence(ctx context.Context, req *SubmitEvide
 		return errors.New("chargeback is not in a state that accepts evidence")
 	}
 
-	// Step 4: Store evidence (as JSON)
-	evidenceJSON, _ := sql.NullString{String: fmt.Sprintf("%v", req.Evidence), Valid: true}.Value()
-	chargeback.MerchantEvidence = sql.NullString{String: evidenceJSON.(string), Valid: true}
+	// Step 4: Store evidence (as JSON), referencing any uploaded documents
+	files, err := s.chargebackRepo.FindEvidenceFiles(req.ChargebackID)
+	if err != nil {
+		return fmt.Errorf("failed to load evidence files: %w", err)
+	}
+	evidence := DisputeEvidence{
+		Evidence:          req.Evidence,
+		MerchantStatement: req.MerchantStatement,
+	}
+	for _, f := range files {
+		evidence.FileIDs = append(evidence.FileIDs, f.ID.String())
+	}
+	if len(evidence.Evidence) == 0 && evidence.MerchantStatement == "" && len(evidence.FileIDs) == 0 {
+		return errors.New("evidence, merchant statement or at least one evidence file is required")
+	}
+	evidenceJSON, err := json.Marshal(evidence)
+	if err != nil {
+		return fmt.Errorf("invalid evidence: %w", err)
+	}
+	chargeback.MerchantEvidence = sql.NullString{String: string(evidenceJSON), Valid: true}
 	chargeback.ResponseSubmittedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	chargeback.Status = model.ChargebackStatusUnderReview
 
@@ -179,7 +275,7 @@ func (s *ChargebackService) SubmitEvidence(ctx context.Context, req *SubmitEvide
 	}
 
 	// Step 6: Log event
-	go s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
+	s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
 		ChargebackID: req.ChargebackID,
 		EventType:    "evidence_submitted",
 		OldStatus:    model.ChargebackStatusNeedsResponse,
@@ -196,6 +292,127 @@ func (s *ChargebackService) SubmitEvidence(ctx context.Context, req *SubmitEvide
 	return nil
 }
 
+// =========================================================================
+// Evidence Files (documents attached before evidence is submitted)
+// =========================================================================
+
+// UploadEvidenceFile attaches a document to a dispute that is still waiting
+// on the merchant. Files are referenced by SubmitEvidence.
+func (s *ChargebackService) UploadEvidenceFile(ctx context.Context, req *UploadEvidenceFileRequest) (*model.ChargebackEvidenceFile, error) {
+	chargeback, err := s.GetDispute(req.MerchantID, req.ChargebackID)
+	if err != nil {
+		return nil, err
+	}
+	if !chargeback.NeedsResponse() {
+		return nil, errors.New("chargeback is not in a state that accepts evidence")
+	}
+
+	if len(req.Content) == 0 {
+		return nil, errors.New("evidence file is empty")
+	}
+	if len(req.Content) > MaxEvidenceFileSize {
+		return nil, fmt.Errorf("evidence file exceeds %d bytes", MaxEvidenceFileSize)
+	}
+	contentType := strings.ToLower(strings.TrimSpace(strings.SplitN(req.ContentType, ";", 2)[0]))
+	if !allowedEvidenceContentTypes[contentType] {
+		return nil, fmt.Errorf("unsupported evidence content type: %s", req.ContentType)
+	}
+	fileName := strings.TrimSpace(req.FileName)
+	if fileName == "" {
+		return nil, errors.New("file name is required")
+	}
+	if len(fileName) > 255 {
+		fileName = fileName[:255]
+	}
+
+	count, err := s.chargebackRepo.CountEvidenceFiles(chargeback.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count evidence files: %w", err)
+	}
+	if count >= MaxEvidenceFiles {
+		return nil, fmt.Errorf("a dispute can have at most %d evidence files", MaxEvidenceFiles)
+	}
+
+	sum := sha256.Sum256(req.Content)
+	file := &model.ChargebackEvidenceFile{
+		ChargebackID: chargeback.ID,
+		MerchantID:   chargeback.MerchantID,
+		FileName:     fileName,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(req.Content)),
+		SHA256:       hex.EncodeToString(sum[:]),
+		Content:      req.Content,
+	}
+	if req.Description != "" {
+		file.Description = sql.NullString{String: req.Description, Valid: true}
+	}
+
+	if s.evidenceStore != nil {
+		file.ID = uuid.New()
+		file.StorageKey = "chargeback-evidence/" + chargeback.MerchantID.String() + "/" + file.ID.String()
+		if err := s.evidenceStore.Put(ctx, file.StorageKey, bytes.NewReader(req.Content), file.SizeBytes, contentType); err != nil {
+			return nil, fmt.Errorf("failed to store evidence file: %w", err)
+		}
+		file.Content = []byte{}
+	}
+
+	if err := s.chargebackRepo.CreateEvidenceFile(file); err != nil {
+		if file.StorageKey != "" {
+			s.evidenceStore.Delete(context.WithoutCancel(ctx), file.StorageKey)
+		}
+		return nil, fmt.Errorf("failed to save evidence file: %w", err)
+	}
+
+	s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
+		ChargebackID: chargeback.ID,
+		EventType:    "evidence_file_uploaded",
+		OldStatus:    chargeback.Status,
+		NewStatus:    chargeback.Status,
+		Note:         sql.NullString{String: fileName, Valid: true},
+	})
+
+	logger.Log.Info("Evidence file uploaded",
+		zap.String("chargeback_id", chargeback.ID.String()),
+		zap.String("file_id", file.ID.String()),
+		zap.Int64("size_bytes", file.SizeBytes),
+	)
+
+	return file, nil
+}
+
+// GetEvidenceFiles lists a dispute's documents without their content
+func (s *ChargebackService) GetEvidenceFiles(chargebackID uuid.UUID) ([]model.ChargebackEvidenceFile, error) {
+	return s.chargebackRepo.FindEvidenceFiles(chargebackID)
+}
+
+// GetEvidenceFile returns one of a merchant's evidence documents with content
+func (s *ChargebackService) GetEvidenceFile(ctx context.Context, merchantID, chargebackID, fileID uuid.UUID) (*model.ChargebackEvidenceFile, error) {
+	if _, err := s.GetDispute(merchantID, chargebackID); err != nil {
+		return nil, err
+	}
+	file, err := s.chargebackRepo.FindEvidenceFile(chargebackID, fileID)
+	if err != nil {
+		return nil, errors.New("evidence file not found")
+	}
+	if file.StorageKey == "" {
+		return file, nil
+	}
+
+	if s.evidenceStore == nil {
+		return nil, errors.New("evidence file is in a storage backend that is not configured")
+	}
+	content, err := s.evidenceStore.Open(ctx, file.StorageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evidence file: %w", err)
+	}
+	defer content.Close()
+	file.Content, err = io.ReadAll(io.LimitReader(content, MaxEvidenceFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read evidence file: %w", err)
+	}
+	return file, nil
+}
+
 // =========================================================================
 // Accept Chargeback (Merchant accepts and won't dispute)
 // =========================================================================
@@ -232,7 +449,7 @@ func (s *ChargebackService) AcceptChargeback(ctx context.Context, req *AcceptCha
 	}
 
 	// Step 5: Log event
-	go s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
+	s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
 		ChargebackID: req.ChargebackID,
 		EventType:    "chargeback_accepted",
 		OldStatus:    oldStatus,
@@ -240,6 +457,9 @@ func (s *ChargebackService) AcceptChargeback(ctx context.Context, req *AcceptCha
 		Note:         sql.NullString{String: req.Reason, Valid: true},
 	})
 
+	// Step 6: The disputed funds go back to the issuer
+	s.postChargebackLoss(chargeback)
+
 	logger.Log.Info("Chargeback accepted",
 		zap.String("chargeback_id", req.ChargebackID.String()),
 	)
@@ -271,7 +491,7 @@ func (s *ChargebackService) ResolveChargeback(ctx context.Context, chargebackID
 		return err
 	}
 
-	go s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
+	s.chargebackRepo.CreateEvent(&model.ChargebackEvent{
 		ChargebackID: chargebackID,
 		EventType:    "chargeback_resolved",
 		OldStatus:    oldStatus,
@@ -279,6 +499,17 @@ func (s *ChargebackService) ResolveChargeback(ctx context.Context, chargebackID
 		Note:         sql.NullString{String: reason, Valid: true},
 	})
 
+	if merchantWon {
+		if err := s.balances.PostChargebackReversal(chargeback); err != nil {
+			logger.Log.Error("Failed to give back won chargeback on merchant balance",
+				zap.String("chargeback_id", chargebackID.String()),
+				zap.Error(err),
+			)
+		}
+	} else {
+		s.postChargebackLoss(chargeback)
+	}
+
 	logger.Log.Info("Chargeback resolved",
 		zap.String("chargeback_id", chargebackID.String()),
 		zap.Bool("merchant_won", merchantWon),
@@ -287,6 +518,18 @@ func (s *ChargebackService) ResolveChargeback(ctx context.Context, chargebackID
 	return nil
 }
 
+// postChargebackLoss books a dispute the merchant lost, accepted or let
+// expire. A failure is logged; the ledger check reports the dispute as
+// still held.
+func (s *ChargebackService) postChargebackLoss(chargeback *model.Chargeback) {
+	if err := s.ledger.PostChargebackLoss(chargeback); err != nil {
+		logger.Log.Error("Failed to post lost chargeback to the ledger",
+			zap.String("chargeback_id", chargeback.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
 // =========================================================================
 // Get Merchant Chargebacks
 // =========================================================================
@@ -299,3 +542,53 @@ func (s *ChargebackService) GetMerchantChargebacks(merchantID uuid.UUID) ([]mode
 func (s *ChargebackService) GetChargebackByID(chargebackID uuid.UUID) (*model.Chargeback, error) {
 	return s.chargebackRepo.FindByID(chargebackID)
 }
+
+// ListDisputes pages through a merchant's chargebacks, soonest deadline first
+func (s *ChargebackService) ListDisputes(req *ListDisputesRequest) ([]model.Chargeback, int64, error) {
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	return s.chargebackRepo.FindByMerchantPaged(req.MerchantID, req.Status, req.TransactionID, req.Limit, req.Offset)
+}
+
+// GetDispute retrieves a chargeback the merchant owns
+func (s *ChargebackService) GetDispute(merchantID, chargebackID uuid.UUID) (*model.Chargeback, error) {
+	chargeback, err := s.chargebackRepo.FindByID(chargebackID)
+	if err != nil {
+		return nil, ErrDisputeNotFound
+	}
+	if chargeback.MerchantID != merchantID {
+		return nil, ErrDisputeAccessDenied
+	}
+	return chargeback, nil
+}
+
+// =========================================================================
+// Response Deadlines
+// =========================================================================
+
+// ExpireOverdueDisputes closes disputes the merchant did not answer in time.
+// A missed deadline is a loss with the card networks.
+func (s *ChargebackService) ExpireOverdueDisputes(ctx context.Context) error {
+	overdue, err := s.chargebackRepo.FindOverdue()
+	if err != nil {
+		return fmt.Errorf("failed to load overdue disputes: %w", err)
+	}
+
+	for _, cb := range overdue {
+		if err := s.ResolveChargeback(ctx, cb.ID, false, "response deadline passed without merchant response"); err != nil {
+			logger.Log.Error("Failed to expire overdue dispute",
+				zap.String("chargeback_id", cb.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if len(overdue) > 0 {
+		logger.Log.Info("Expired overdue disputes", zap.Int("count", len(overdue)))
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/connector"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownConnector     = errors.New("unknown acquirer connector")
+	ErrInvalidRoutingConfig = errors.New("invalid routing configuration")
+)
+
+// ConnectorRoutingService decides which acquirer connector handles a
+// merchant's transactions
+type ConnectorRoutingService struct {
+	registry    *connector.Registry
+	routeRepo   *repository.ConnectorRouteRepository
+	routingRepo *repository.RoutingRepository
+}
+
+func NewConnectorRoutingService(registry *connector.Registry) *ConnectorRoutingService {
+	return &ConnectorRoutingService{
+		registry:    registry,
+		routeRepo:   repository.NewConnectorRouteRepository(),
+		routingRepo: repository.NewRoutingRepository(),
+	}
+}
+
+// maxRoutingAttempts caps an authorization at a primary and one secondary
+const maxRoutingAttempts = 2
+
+// Routing decision sources, in evaluation order
+const (
+	RoutingSourceMerchantRule  = "merchant_rule"
+	RoutingSourceMerchantRoute = "merchant_route"
+	RoutingSourceGlobalRule    = "global_rule"
+	RoutingSourceDefault       = "default"
+)
+
+// RoutingContext carries the authorization attributes rules match on
+type RoutingContext struct {
+	MerchantID uuid.UUID
+	Currency   string
+	CardBrand  string
+	BINCountry string
+	AmountMAD  int64
+}
+
+// RoutingPlan lists the connectors to try, in order
+type RoutingPlan struct {
+	Connectors []connector.AcquirerConnector
+	Source     string
+	Strategy   model.RoutingStrategy
+	RuleID     *uuid.UUID
+}
+
+// RoutedAuthorization is the outcome of authorizing through a plan
+type RoutedAuthorization struct {
+	Response   *client.AuthorizeCardResponse
+	Connector  connector.AcquirerConnector
+	Attempts   []model.RoutingAttempt
+	FailedOver bool
+}
+
+// Plan picks the connectors for an authorization. Merchant rules win, then
+// the merchant's pinned route, then platform rules, then the default.
+func (s *ConnectorRoutingService) Plan(rc *RoutingContext) (*RoutingPlan, error) {
+	rules, err := s.routingRepo.FindActiveRules(rc.MerchantID)
+	if err != nil {
+		logger.Log.Error("Failed to load routing rules, ignoring them",
+			zap.String("merchant_id", rc.MerchantID.String()),
+			zap.Error(err),
+		)
+	}
+
+	var globalRules []model.RoutingRule
+	for i := range rules {
+		if rules[i].MerchantID == nil {
+			globalRules = append(globalRules, rules[i])
+			continue
+		}
+		if plan := s.planFromRule(&rules[i], rc, RoutingSourceMerchantRule); plan != nil {
+			return plan, nil
+		}
+	}
+
+	if primary := s.merchantRoute(rc.MerchantID); primary != nil {
+		return s.singlePlan(primary, RoutingSourceMerchantRoute), nil
+	}
+
+	for i := range globalRules {
+		if plan := s.planFromRule(&globalRules[i], rc, RoutingSourceGlobalRule); plan != nil {
+			return plan, nil
+		}
+	}
+
+	primary, err := s.registry.Default()
+	if err != nil {
+		return nil, err
+	}
+	return s.singlePlan(primary, RoutingSourceDefault), nil
+}
+
+// Authorize runs the plan, moving to the next connector only after a soft
+// failure. A hard decline from any connector is final.
+func (s *ConnectorRoutingService) Authorize(ctx context.Context, plan *RoutingPlan, req *client.AuthorizeCardRequest) (*RoutedAuthorization, error) {
+	routed := &RoutedAuthorization{}
+
+	for i, candidate := range plan.Connectors {
+		hasNext := i < len(plan.Connectors)-1
+		started := time.Now()
+		resp, err := candidate.Authorize(ctx, req)
+
+		attempt := model.RoutingAttempt{
+			Connector: candidate.Name(),
+			LatencyMs: time.Since(started).Milliseconds(),
+		}
+
+		if err != nil {
+			attempt.Error = err.Error()
+			attempt.SoftFailure = connector.IsSoftError(ctx, err)
+			routed.Attempts = append(routed.Attempts, attempt)
+
+			if attempt.SoftFailure && hasNext {
+				logger.Log.Warn("Connector failed, failing over",
+					zap.String("connector", candidate.Name()),
+					zap.String("next", plan.Connectors[i+1].Name()),
+					zap.Error(err),
+				)
+				routed.FailedOver = true
+				continue
+			}
+			if routed.Response != nil {
+				// An earlier connector's decline is still a valid answer
+				return routed, nil
+			}
+			return routed, err
+		}
+
+		attempt.Approved = resp.Approved
+		attempt.ResponseCode = resp.ResponseCode
+		attempt.SoftFailure = !resp.Approved && connector.IsSoftDecline(resp.ResponseCode)
+		routed.Attempts = append(routed.Attempts, attempt)
+		routed.Response = resp
+		routed.Connector = candidate
+
+		if attempt.SoftFailure && hasNext {
+			logger.Log.Warn("Connector soft decline, failing over",
+				zap.String("connector", candidate.Name()),
+				zap.String("response_code", resp.ResponseCode),
+				zap.String("next", plan.Connectors[i+1].Name()),
+			)
+			routed.FailedOver = true
+			continue
+		}
+		break
+	}
+
+	return routed, nil
+}
+
+// RecordDecision stores how a transaction was routed
+func (s *ConnectorRoutingService) RecordDecision(txn *model.Transaction, rc *RoutingContext, plan *RoutingPlan, routed *RoutedAuthorization) {
+	names := make([]string, 0, len(plan.Connectors))
+	for _, c := range plan.Connectors {
+		names = append(names, c.Name())
+	}
+
+	decision := &model.RoutingDecision{
+		TransactionID: txn.ID,
+		MerchantID:    txn.MerchantID,
+		RuleID:        plan.RuleID,
+		Source:        plan.Source,
+		Strategy:      string(plan.Strategy),
+		Currency:      rc.Currency,
+		CardBrand:     rc.CardBrand,
+		BINCountry:    rc.BINCountry,
+		Candidates:    strings.Join(names, ","),
+		Selected:      routed.Connector.Name(),
+		FailedOver:    routed.FailedOver,
+	}
+	if attempts, err := json.Marshal(routed.Attempts); err == nil {
+		decision.Attempts = sql.NullString{String: string(attempts), Valid: true}
+	}
+
+	if err := s.routingRepo.CreateDecision(decision); err != nil {
+		logger.Log.Error("Failed to record routing decision",
+			zap.String("transaction_id", txn.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ConnectorRoutingService) planFromRule(rule *model.RoutingRule, rc *RoutingContext, source string) *RoutingPlan {
+	if !rule.Matches(rc.Currency, rc.CardBrand, rc.BINCountry) {
+		return nil
+	}
+
+	var candidates []connector.AcquirerConnector
+	switch rule.Strategy {
+	case model.RoutingStrategyLowestCost:
+		candidates = s.byCost(rc.AmountMAD)
+	default:
+		for _, name := range []string{rule.Connector, rule.FallbackConnector} {
+			if name == "" {
+				continue
+			}
+			if c, err := s.registry.Get(name); err == nil {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		logger.Log.Warn("Routing rule has no registered connector, skipping",
+			zap.String("rule_id", rule.ID.String()),
+			zap.String("connector", rule.Connector),
+		)
+		return nil
+	}
+	if len(candidates) > maxRoutingAttempts {
+		candidates = candidates[:maxRoutingAttempts]
+	}
+
+	ruleID := rule.ID
+	return &RoutingPlan{
+		Connectors: candidates,
+		Source:     source,
+		Strategy:   rule.Strategy,
+		RuleID:     &ruleID,
+	}
+}
+
+// singlePlan routes to primary, with the registry fallback as secondary
+func (s *ConnectorRoutingService) singlePlan(primary connector.AcquirerConnector, source string) *RoutingPlan {
+	plan := &RoutingPlan{
+		Connectors: []connector.AcquirerConnector{primary},
+		Source:     source,
+		Strategy:   model.RoutingStrategyFixed,
+	}
+	if fallback := s.registry.Fallback(); fallback != nil && fallback.Name() != primary.Name() {
+		plan.Connectors = append(plan.Connectors, fallback)
+	}
+	return plan
+}
+
+// byCost orders registered connectors by estimated fee; connectors without a
+// configured cost go last
+func (s *ConnectorRoutingService) byCost(amountMAD int64) []connector.AcquirerConnector {
+	costs, err := s.routingRepo.FindAllCosts()
+	if err != nil {
+		logger.Log.Error("Failed to load connector costs", zap.Error(err))
+	}
+
+	fees := make(map[string]int64, len(costs))
+	for i := range costs {
+		fees[costs[i].Connector] = costs[i].Estimate(amountMAD)
+	}
+
+	names := s.registry.Names()
+	sort.SliceStable(names, func(i, j int) bool {
+		fi, okI := fees[names[i]]
+		fj, okJ := fees[names[j]]
+		if okI != okJ {
+			return okI
+		}
+		return fi < fj
+	})
+
+	candidates := make([]connector.AcquirerConnector, 0, len(names))
+	for _, name := range names {
+		if c, err := s.registry.Get(name); err == nil {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// merchantRoute returns the merchant's pinned connector, if any
+func (s *ConnectorRoutingService) merchantRoute(merchantID uuid.UUID) connector.AcquirerConnector {
+	route, err := s.routeRepo.FindByMerchant(merchantID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Log.Error("Failed to load connector route",
+				zap.String("merchant_id", merchantID.String()),
+				zap.Error(err),
+			)
+		}
+		return nil
+	}
+
+	conn, err := s.registry.Get(route.Connector)
+	if err != nil {
+		// A route can outlive the connector's configuration on this instance
+		logger.Log.Warn("Routed connector unavailable",
+			zap.String("merchant_id", merchantID.String()),
+			zap.String("connector", route.Connector),
+		)
+		return nil
+	}
+	return conn
+}
+
+// ForTransaction returns the connector that authorized txn, so follow-up
+// operations reach the same acquirer
+func (s *ConnectorRoutingService) ForTransaction(txn *model.Transaction) (connector.AcquirerConnector, error) {
+	if txn.Connector == "" {
+		// Transactions created before connectors were introduced
+		return s.registry.Get(connector.SimulatorConnectorName)
+	}
+	return s.registry.Get(txn.Connector)
+}
+
+func (s *ConnectorRoutingService) SetRoute(merchantID uuid.UUID, name string) (*model.MerchantConnectorRoute, error) {
+	if _, err := s.registry.Get(name); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownConnector, name)
+	}
+
+	route := &model.MerchantConnectorRoute{
+		MerchantID: merchantID,
+		Connector:  name,
+	}
+	if err := s.routeRepo.Upsert(route); err != nil {
+		return nil, fmt.Errorf("failed to save connector route: %w", err)
+	}
+	return route, nil
+}
+
+// =========================================================================
+// Rule, cost and decision management
+// =========================================================================
+
+func (s *ConnectorRoutingService) ListRules() ([]model.RoutingRule, error) {
+	return s.routingRepo.FindAllRules()
+}
+
+func (s *ConnectorRoutingService) CreateRule(rule *model.RoutingRule) error {
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+	return s.routingRepo.CreateRule(rule)
+}
+
+func (s *ConnectorRoutingService) UpdateRule(id uuid.UUID, rule *model.RoutingRule) (*model.RoutingRule, error) {
+	existing, err := s.routingRepo.FindRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.routingRepo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *ConnectorRoutingService) DeleteRule(id uuid.UUID) error {
+	return s.routingRepo.DeleteRule(id)
+}
+
+func (s *ConnectorRoutingService) ListCosts() ([]model.ConnectorCost, error) {
+	return s.routingRepo.FindAllCosts()
+}
+
+func (s *ConnectorRoutingService) SetCost(cost *model.ConnectorCost) error {
+	if _, err := s.registry.Get(cost.Connector); err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownConnector, cost.Connector)
+	}
+	if cost.PercentBps < 0 || cost.FixedFeeMAD < 0 {
+		return fmt.Errorf("%w: costs cannot be negative", ErrInvalidRoutingConfig)
+	}
+	return s.routingRepo.UpsertCost(cost)
+}
+
+func (s *ConnectorRoutingService) GetDecision(transactionID uuid.UUID) (*model.RoutingDecision, error) {
+	return s.routingRepo.FindDecisionByTransaction(transactionID)
+}
+
+func (s *ConnectorRoutingService) ListDecisions(merchantID uuid.UUID, limit int) ([]model.RoutingDecision, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.routingRepo.FindDecisionsByMerchant(merchantID, limit)
+}
+
+func (s *ConnectorRoutingService) validateRule(rule *model.RoutingRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRoutingConfig)
+	}
+	if rule.Strategy == "" {
+		rule.Strategy = model.RoutingStrategyFixed
+	}
+	rule.Currency = strings.ToUpper(rule.Currency)
+	rule.BINCountry = strings.ToUpper(rule.BINCountry)
+
+	switch rule.Strategy {
+	case model.RoutingStrategyFixed:
+		if rule.Connector == "" {
+			return fmt.Errorf("%w: connector is required for the fixed strategy", ErrInvalidRoutingConfig)
+		}
+		if _, err := s.registry.Get(rule.Connector); err != nil {
+			return fmt.Errorf("%w: %s", ErrUnknownConnector, rule.Connector)
+		}
+		if rule.FallbackConnector != "" {
+			if _, err := s.registry.Get(rule.FallbackConnector); err != nil {
+				return fmt.Errorf("%w: %s", ErrUnknownConnector, rule.FallbackConnector)
+			}
+		}
+	case model.RoutingStrategyLowestCost:
+	default:
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidRoutingConfig, rule.Strategy)
+	}
+	return nil
+}
+
+func (s *ConnectorRoutingService) DeleteRoute(merchantID uuid.UUID) error {
+	return s.routeRepo.Delete(merchantID)
+}
+
+func (s *ConnectorRoutingService) ListRoutes() ([]model.MerchantConnectorRoute, error) {
+	return s.routeRepo.FindAll()
+}
+
+// Connectors lists registered connector names and the default
+func (s *ConnectorRoutingService) Connectors() ([]string, string) {
+	return s.registry.Names(), s.registry.DefaultName()
+}
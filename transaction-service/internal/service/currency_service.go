@@ -132,13 +132,18 @@ func (s *CurrencyService) UpdateExchangeRates(ctx context.Context) error {
 	return nil
 }
 
+const (
+	// Base fee: $0.30 = 300 MAD cents (assuming 1 USD = 10 MAD)
+	processingFeeFixedMAD = int64(300)
+	processingFeeRate     = 0.029
+)
+
 // CalculateProcessingFee calculates fee: 2.9% + $0.30 (converted to MAD)
 func (s *CurrencyService) CalculateProcessingFee(amountMAD int64) int64 {
-	// Base fee: $0.30 = 300 MAD cents (assuming 1 USD = 10 MAD)
-	baseFeeMAD := int64(300) // 3 MAD in cents
+	baseFeeMAD := processingFeeFixedMAD
 
 	// Percentage fee: 2.9%
-	percentageFee := int64(float64(amountMAD) * 0.029)
+	percentageFee := int64(float64(amountMAD) * processingFeeRate)
 
 	totalFee := baseFeeMAD + percentageFee
 
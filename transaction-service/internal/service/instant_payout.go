@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInstantPayoutFeeBps          = 100  // 1% of the net amount
+	defaultInstantPayoutMinFee          = 1000 // 10.00 MAD
+	defaultInstantPayoutMaxChargebackBp = 100  // 1% of captures
+	instantPayoutChargebackWindow       = 90 * 24 * time.Hour
+)
+
+var ErrInstantPayoutIneligible = errors.New("merchant is not eligible for instant payouts")
+
+// InstantPayoutSettings price instant payouts and bound who may use them
+type InstantPayoutSettings struct {
+	FeeBps          int64 `json:"fee_bps"`           // Basis points of the net amount
+	MinFee          int64 `json:"min_fee"`           // MAD minor units
+	MaxChargebackBp int64 `json:"max_chargeback_bp"` // Highest 90-day chargeback rate allowed
+}
+
+// LoadInstantPayoutSettings reads the instant payout settings from the environment
+func LoadInstantPayoutSettings() InstantPayoutSettings {
+	return InstantPayoutSettings{
+		FeeBps:          envInt64("INSTANT_PAYOUT_FEE_BPS", defaultInstantPayoutFeeBps),
+		MinFee:          envInt64("INSTANT_PAYOUT_MIN_FEE", defaultInstantPayoutMinFee),
+		MaxChargebackBp: envInt64("INSTANT_PAYOUT_MAX_CHARGEBACK_BP", defaultInstantPayoutMaxChargebackBp),
+	}
+}
+
+// Fee returns the instant payout fee on a net amount
+func (s InstantPayoutSettings) Fee(netAmount int64) int64 {
+	fee := netAmount * s.FeeBps / 10000
+	if fee < s.MinFee {
+		fee = s.MinFee
+	}
+	return fee
+}
+
+// InstantPayoutQuote is what an instant payout would pay right now, or why
+// the merchant cannot have one
+type InstantPayoutQuote struct {
+	Eligible         bool
+	Reasons          []string // Why not, when not eligible
+	ChargebackRateBp int64
+	Batch            *model.SettlementBatch // Unsaved; NetAmount is after the reserve, fee and balance
+}
+
+// QuoteInstantPayout checks eligibility and prices paying out every captured
+// transaction not yet in a settlement batch, settled against the merchant's
+// balance as the batch would be
+func (s *SettlementService) QuoteInstantPayout(ctx context.Context, merchantID uuid.UUID) (*InstantPayoutQuote, []uuid.UUID, error) {
+	quote := &InstantPayoutQuote{}
+
+	eligibility, err := s.merchants.GetPayoutEligibility(ctx, merchantID.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check payout eligibility: %w", err)
+	}
+	if eligibility.MerchantStatus != "active" {
+		quote.Reasons = append(quote.Reasons, fmt.Sprintf("merchant status is %q", eligibility.MerchantStatus))
+	}
+	if eligibility.VerificationStatus != "verified" {
+		quote.Reasons = append(quote.Reasons, fmt.Sprintf("business verification is %q", eligibility.VerificationStatus))
+	}
+	if !eligibility.HasPayoutAccount {
+		quote.Reasons = append(quote.Reasons, "no verified payout bank account")
+	}
+
+	since := time.Now().Add(-instantPayoutChargebackWindow)
+	captures, err := s.txnRepo.CountCapturedSince(merchantID, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	chargebacks, err := s.chargebackRepo.CountSince(merchantID, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	if captures > 0 {
+		quote.ChargebackRateBp = chargebacks * 10000 / captures
+	}
+	if limit := s.instantPayouts.MaxChargebackBp; quote.ChargebackRateBp > limit {
+		quote.Reasons = append(quote.Reasons,
+			fmt.Sprintf("90-day chargeback rate %d bp is above %d bp", quote.ChargebackRateBp, limit))
+	}
+
+	loc, err := s.merchantLocation(merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := s.txnRepo.FindUnsettledForMerchant(merchantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find unsettled transactions: %w", err)
+	}
+
+	schedule, err := s.merchantSchedule(merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	period := settlementDayOf(time.Now(), loc)
+	batch := newSettlementBatch(merchantID, period, transactions)
+	applyReserve(batch, schedule.ReserveBps)
+	batch.InstantPayout = true
+	batch.PayoutFee = s.instantPayouts.Fee(batch.NetAmount)
+	batch.NetAmount -= batch.PayoutFee
+	batch.SettlementDate = period.Date
+	batch.SettlementMethod = "instant_transfer"
+
+	available, err := s.balances.available(merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	applyBalance(batch, available)
+	quote.Batch = batch
+	if batch.NetAmount <= 0 {
+		quote.Reasons = append(quote.Reasons, "available funds do not cover the instant payout fee")
+	}
+
+	txnIDs := make([]uuid.UUID, len(transactions))
+	for i, txn := range transactions {
+		txnIDs[i] = txn.ID
+	}
+
+	quote.Eligible = len(quote.Reasons) == 0
+	return quote, txnIDs, nil
+}
+
+// CreateInstantPayout batches the merchant's captured funds now and pays them
+// out straight away, less the instant payout fee. The guardrails apply as
+// they do to daily batches, so a held batch waits for an operator instead.
+func (s *SettlementService) CreateInstantPayout(ctx context.Context, merchantID uuid.UUID) (*InstantPayoutQuote, error) {
+	quote, txnIDs, err := s.QuoteInstantPayout(ctx, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !quote.Eligible {
+		return quote, ErrInstantPayoutIneligible
+	}
+
+	schedule, err := s.merchantSchedule(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := quote.Batch
+	s.attachPayoutAccount(ctx, batch)
+
+	// Transactions a concurrent batch claimed first fail the whole payout.
+	// The balance is settled again under its lock, since it may have moved
+	// since the quote.
+	if err := s.txnRepo.CreateSettlementBatch(batch, txnIDs, func(tx *gorm.DB) error {
+		if err := s.balances.postBatch(tx, batch, schedule.ReserveDays); err != nil {
+			return err
+		}
+		if batch.NetAmount <= 0 {
+			return ErrInstantPayoutIneligible
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, ErrInstantPayoutIneligible) {
+			quote.Eligible = false
+			quote.Reasons = append(quote.Reasons, "available funds do not cover the instant payout fee")
+			return quote, err
+		}
+		return nil, fmt.Errorf("failed to save instant payout batch: %w", err)
+	}
+
+	logger.Log.Info("Instant payout batch created",
+		zap.String("batch_id", batch.ID.String()),
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int64("net_amount", batch.NetAmount),
+		zap.Int64("payout_fee", batch.PayoutFee),
+		zap.Int64("balance_adjustment", batch.BalanceAdjustment),
+	)
+
+	s.checkNewBatch(batch)
+	if batch.IsHeld() {
+		return quote, nil
+	}
+
+	paidToday, err := s.payoutsToday()
+	if err != nil {
+		return nil, fmt.Errorf("failed to total today's payouts: %w", err)
+	}
+	if s.exceedsDailyLimit(batch, paidToday) {
+		s.holdForDailyLimit(batch, paidToday)
+		return quote, nil
+	}
+
+	// A failed payout leaves the batch pending for the next payout run
+	if err := s.processSettlementBatch(batch); err != nil {
+		logger.Log.Error("Failed to pay instant payout batch",
+			zap.String("batch_id", batch.ID.String()),
+			zap.Error(err),
+		)
+		return quote, nil
+	}
+	if settled, err := s.settlementRepo.FindByID(batch.ID); err == nil {
+		quote.Batch = settled
+	}
+	return quote, nil
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// ledgerCheckSettleWindow leaves recent movements out of the invariant
+	// check: a capture can wait on the acquirer for up to
+	// CaptureReservationTimeout before it is posted
+	ledgerCheckSettleWindow = 15 * time.Minute
+
+	// maxLedgerViolations bounds how many violations of each kind one check
+	// reports
+	maxLedgerViolations = 100
+)
+
+var ErrUnbalancedJournalEntry = errors.New("journal entry does not balance")
+
+// LedgerService keeps the double-entry ledger. Every money movement posts a
+// journal entry whose lines add up to zero:
+//
+//	capture          Dr clearing          Cr merchant_payable, fees
+//	refund           Dr merchant_payable, fees   Cr clearing
+//	settlement       Dr merchant_payable  Cr clearing (payout), reserves, fees (instant payout fee)
+//	reserve_release  Dr reserves          Cr merchant_payable
+//	chargeback       Dr merchant_payable  Cr chargebacks, fees (chargeback fee)
+//	chargeback_won   Dr chargebacks       Cr merchant_payable
+//	chargeback_lost  Dr chargebacks       Cr clearing
+//
+// Sandbox transactions are never posted.
+type LedgerService struct {
+	ledgerRepo *repository.LedgerRepository
+	alerts     *client.OperatorAlertClient
+}
+
+func NewLedgerService() *LedgerService {
+	return &LedgerService{
+		ledgerRepo: repository.NewLedgerRepository(),
+		alerts:     client.NewOperatorAlertClient(),
+	}
+}
+
+// journalLine is one side of an entry before it is posted
+type journalLine struct {
+	account model.LedgerAccount
+	amount  int64 // Debit positive, credit negative
+}
+
+// post checks the lines balance and saves them as one entry, in tx when it
+// is set. Zero lines are left out, and an entry with nothing left is not
+// posted.
+func (s *LedgerService) post(tx *gorm.DB, entry model.JournalEntry, lines ...journalLine) error {
+	var total int64
+	for _, line := range lines {
+		total += line.amount
+		if line.amount == 0 {
+			continue
+		}
+		entry.Lines = append(entry.Lines, model.JournalLine{
+			ID:         uuid.New(),
+			EntryID:    entry.ID,
+			MerchantID: entry.MerchantID,
+			Account:    line.account,
+			Amount:     line.amount,
+		})
+	}
+	if total != 0 {
+		return fmt.Errorf("%w: %s lines total %d", ErrUnbalancedJournalEntry, entry.Reference, total)
+	}
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+
+	_, err := s.ledgerRepo.Post(tx, &entry)
+	return err
+}
+
+func newJournalEntry(entryType model.JournalEntryType, merchantID, sourceID uuid.UUID, occurredAt time.Time, description string) model.JournalEntry {
+	return model.JournalEntry{
+		ID:          uuid.New(),
+		MerchantID:  merchantID,
+		Type:        entryType,
+		Reference:   string(entryType) + ":" + sourceID.String(),
+		SourceID:    sourceID,
+		Description: description,
+		OccurredAt:  occurredAt,
+	}
+}
+
+// captureAmountsMAD is the capture's share of the authorization's MAD
+// amount and processing fee. Each is the rounded share of everything
+// captured so far less the rounded share of the captures before it, so the
+// last capture takes the remainder and the captures add up to what refunds
+// prorate against. txn is as loaded before the capture.
+func captureAmountsMAD(txn *model.Transaction, capture *model.TransactionCapture) (gross, fee int64, err error) {
+	if txn.Amount <= 0 {
+		return 0, 0, nil
+	}
+	before, after := txn.CapturedAmount, txn.CapturedAmount+capture.Amount
+	if gross, err = prorateBetween(txn.AmountMAD, before, after, txn.Amount); err != nil {
+		return 0, 0, err
+	}
+	if fee, err = prorateBetween(txn.ProcessingFee, before, after, txn.Amount); err != nil {
+		return 0, 0, err
+	}
+	return gross, fee, nil
+}
+
+// prorateBetween is amount's share of whole from part from to part to,
+// rounded the way prorate rounds each end
+func prorateBetween(amount, from, to, whole int64) (int64, error) {
+	start, err := prorate(amount, from, whole)
+	if err != nil {
+		return 0, err
+	}
+	end, err := prorate(amount, to, whole)
+	if err != nil {
+		return 0, err
+	}
+	return end - start, nil
+}
+
+// postCapture books an accepted capture inside tx
+func (s *LedgerService) postCapture(tx *gorm.DB, txn *model.Transaction, capture *model.TransactionCapture) error {
+	if txn.TestMode {
+		return nil
+	}
+	occurredAt := capture.CreatedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	gross, fee, err := captureAmountsMAD(txn, capture)
+	if err != nil {
+		return err
+	}
+	entry := newJournalEntry(model.JournalEntryCapture, txn.MerchantID, capture.ID, occurredAt,
+		fmt.Sprintf("Capture %d on transaction %s", capture.Sequence, txn.ID))
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountClearing, gross},
+		journalLine{model.LedgerAccountMerchantPayable, -(gross - fee)},
+		journalLine{model.LedgerAccountFees, -fee},
+	)
+}
+
+// postRefund books a refund the acquirer accepted inside tx
+func (s *LedgerService) postRefund(tx *gorm.DB, refund *model.Transaction) error {
+	if refund.TestMode {
+		return nil
+	}
+	occurredAt := time.Now()
+	if refund.SentToIssuerAt.Valid {
+		occurredAt = refund.SentToIssuerAt.Time
+	}
+	gross, feeReversed := -refund.AmountMAD, -refund.ProcessingFee
+	entry := newJournalEntry(model.JournalEntryRefund, refund.MerchantID, refund.ID, occurredAt,
+		fmt.Sprintf("Refund of transaction %s", refund.ParentTransactionID.String))
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountMerchantPayable, gross - feeReversed},
+		journalLine{model.LedgerAccountFees, feeReversed},
+		journalLine{model.LedgerAccountClearing, -gross},
+	)
+}
+
+// postSettlement books a new batch's payout, the reserve it held back and
+// its instant payout fee inside tx
+func (s *LedgerService) postSettlement(tx *gorm.DB, batch *model.SettlementBatch) error {
+	entry := newJournalEntry(model.JournalEntrySettlement, batch.MerchantID, batch.ID, time.Now(),
+		fmt.Sprintf("Settlement batch %s", batch.BatchDate.Format("2006-01-02")))
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountMerchantPayable, batch.NetAmount + batch.ReserveAmount + batch.PayoutFee},
+		journalLine{model.LedgerAccountClearing, -batch.NetAmount},
+		journalLine{model.LedgerAccountReserves, -batch.ReserveAmount},
+		journalLine{model.LedgerAccountFees, -batch.PayoutFee},
+	)
+}
+
+// postReserveRelease books a released reserve hold inside tx. Holds of
+// batches from before the ledger have nothing to release in it.
+func (s *LedgerService) postReserveRelease(tx *gorm.DB, hold *model.MerchantBalanceEntry) error {
+	if _, err := s.ledgerRepo.FindEntry(tx, string(model.JournalEntrySettlement)+":"+hold.SettlementBatchID.String); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	entry := newJournalEntry(model.JournalEntryReserveRelease, hold.MerchantID, hold.ID, time.Now(),
+		fmt.Sprintf("Reserve released from batch %s", hold.SettlementBatchID.String))
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountReserves, hold.Reserve},
+		journalLine{model.LedgerAccountMerchantPayable, -hold.Reserve},
+	)
+}
+
+// postChargeback books a newly opened dispute and its fee inside tx, both
+// already in MAD
+func (s *LedgerService) postChargeback(tx *gorm.DB, chargeback *model.Chargeback, amount, fee int64) error {
+	entry := newJournalEntry(model.JournalEntryChargeback, chargeback.MerchantID, chargeback.ID, chargeback.DisputedAt,
+		fmt.Sprintf("Dispute on transaction %s", chargeback.TransactionID))
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountMerchantPayable, amount + fee},
+		journalLine{model.LedgerAccountChargebacks, -amount},
+		journalLine{model.LedgerAccountFees, -fee},
+	)
+}
+
+// postChargebackResolution releases what a dispute holds, inside tx when it
+// is set: back to the merchant when they won, out to the issuer when they
+// did not. Disputes opened before the ledger, or on sandbox transactions,
+// hold nothing.
+func (s *LedgerService) postChargebackResolution(tx *gorm.DB, chargeback *model.Chargeback, merchantWon bool) error {
+	opened, err := s.ledgerRepo.FindEntry(tx, string(model.JournalEntryChargeback)+":"+chargeback.ID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var held int64
+	for _, line := range opened.Lines {
+		if line.Account == model.LedgerAccountChargebacks {
+			held -= line.Amount
+		}
+	}
+
+	// Won and lost share a reference, so a dispute is only ever resolved
+	// once in the ledger
+	entryType, to := model.JournalEntryChargebackLost, model.LedgerAccountClearing
+	if merchantWon {
+		entryType, to = model.JournalEntryChargebackWon, model.LedgerAccountMerchantPayable
+	}
+	entry := newJournalEntry(entryType, chargeback.MerchantID, chargeback.ID, time.Now(),
+		fmt.Sprintf("Dispute on transaction %s %s", chargeback.TransactionID, chargeback.Status))
+	entry.Reference = "chargeback_resolved:" + chargeback.ID.String()
+	return s.post(tx, entry,
+		journalLine{model.LedgerAccountChargebacks, held},
+		journalLine{to, -held},
+	)
+}
+
+// PostChargebackLoss releases a dispute the merchant lost, accepted or let
+// expire to the issuer
+func (s *LedgerService) PostChargebackLoss(chargeback *model.Chargeback) error {
+	return s.postChargebackResolution(nil, chargeback, false)
+}
+
+// MerchantLedger is a merchant's account balances and latest entries
+type MerchantLedger struct {
+	MerchantID uuid.UUID                     `json:"merchant_id"`
+	Balances   map[model.LedgerAccount]int64 `json:"balances"`
+	Entries    []model.JournalEntry          `json:"entries"`
+}
+
+// GetMerchantLedger returns the merchant's account balances and up to limit
+// of their latest journal entries
+func (s *LedgerService) GetMerchantLedger(merchantID uuid.UUID, limit int) (*MerchantLedger, error) {
+	balances, err := s.ledgerRepo.Balances(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total ledger balances: %w", err)
+	}
+	entries, err := s.ledgerRepo.FindEntries(merchantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find journal entries: %w", err)
+	}
+	return &MerchantLedger{MerchantID: merchantID, Balances: balances, Entries: entries}, nil
+}
+
+// =========================================================================
+// Invariant Checks
+// =========================================================================
+
+// LedgerViolation is one place the ledger and the records it should agree
+// with disagree. Amounts are MAD minor units, signed as in the ledger,
+// except for captured_amount and refunded_amount, which are in the
+// transaction's currency.
+type LedgerViolation struct {
+	Check      string    `json:"check"`
+	MerchantID uuid.UUID `json:"merchant_id"`
+	SubjectID  uuid.UUID `json:"subject_id,omitempty"` // The entry or transaction, for per-record checks
+	Actual     int64     `json:"actual"`
+	Expected   int64     `json:"expected"`
+}
+
+// LedgerCheckReport is the outcome of one invariant check. Movements in
+// [Since, Until) were compared; later ones are checked next time.
+type LedgerCheckReport struct {
+	CheckedAt  time.Time         `json:"checked_at"`
+	Since      time.Time         `json:"since"`
+	Until      time.Time         `json:"until"`
+	Violations []LedgerViolation `json:"violations"`
+}
+
+// CheckInvariants reconciles the ledger against the transaction records it
+// is posted from:
+//
+//   - every entry balances;
+//   - captured_amount and refunded_amount are the sum of a transaction's
+//     captures and sent refunds;
+//   - per merchant, captures, refunds and their fees in the ledger match
+//     the transaction totals over the same period;
+//   - the reserves account matches the merchant balance's reserve, and the
+//     chargebacks account matches the disputes still open.
+//
+// Violations raise one operator alert.
+func (s *LedgerService) CheckInvariants(ctx context.Context) (*LedgerCheckReport, error) {
+	now := time.Now()
+	report := &LedgerCheckReport{CheckedAt: now, Until: now.Add(-ledgerCheckSettleWindow), Violations: []LedgerViolation{}}
+
+	since, err := s.ledgerRepo.LedgerStart()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ledger start: %w", err)
+	}
+	if since.IsZero() || !since.Before(report.Until) {
+		return report, nil
+	}
+	report.Since = since
+
+	unbalanced, err := s.ledgerRepo.FindUnbalancedEntries(maxLedgerViolations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check entry balance: %w", err)
+	}
+	for _, entry := range unbalanced {
+		report.Violations = append(report.Violations, LedgerViolation{
+			Check: "entry_balance", MerchantID: entry.MerchantID, SubjectID: entry.EntryID, Actual: entry.Total,
+		})
+	}
+
+	captured, err := s.ledgerRepo.FindCapturedAmountMismatches(maxLedgerViolations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check captured amounts: %w", err)
+	}
+	report.addAmountMismatches("captured_amount", captured)
+
+	refunded, err := s.ledgerRepo.FindRefundedAmountMismatches(report.Until, maxLedgerViolations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check refunded amounts: %w", err)
+	}
+	report.addAmountMismatches("refunded_amount", refunded)
+
+	if err := s.checkTotals(report); err != nil {
+		return nil, err
+	}
+
+	if len(report.Violations) > 0 {
+		s.alerts.Raise(ctx, client.OperatorAlert{
+			Type:    "ledger_invariant_violated",
+			Message: fmt.Sprintf("%d ledger invariant violations", len(report.Violations)),
+			Details: map[string]interface{}{"violations": report.Violations},
+		})
+	} else {
+		logger.Log.Info("Ledger invariants hold",
+			zap.Time("since", report.Since),
+			zap.Time("until", report.Until),
+		)
+	}
+	return report, nil
+}
+
+// checkTotals compares the ledger's per-merchant totals with the records'
+func (s *LedgerService) checkTotals(report *LedgerCheckReport) error {
+	since, until := report.Since, report.Until
+
+	captureGross, captureFees, err := s.ledgerRepo.CaptureTotals(since, until)
+	if err != nil {
+		return fmt.Errorf("failed to total captures: %w", err)
+	}
+	refundGross, refundFees, err := s.ledgerRepo.RefundTotals(since, until)
+	if err != nil {
+		return fmt.Errorf("failed to total refunds: %w", err)
+	}
+	reserves, err := s.ledgerRepo.ReserveTotals()
+	if err != nil {
+		return fmt.Errorf("failed to total balance reserves: %w", err)
+	}
+	heldChargebacks, openChargebacks, err := s.ledgerRepo.ChargebackTotals(until)
+	if err != nil {
+		return fmt.Errorf("failed to total open disputes: %w", err)
+	}
+
+	ledgerTotals := func(entryType model.JournalEntryType, account model.LedgerAccount) func() ([]repository.MerchantAmount, error) {
+		return func() ([]repository.MerchantAmount, error) {
+			return s.ledgerRepo.AccountTotals(entryType, account, since, until)
+		}
+	}
+	checks := []struct {
+		name     string
+		actual   func() ([]repository.MerchantAmount, error)
+		expected []repository.MerchantAmount
+		sign     int64 // How the expected totals are signed in the ledger
+	}{
+		{"captures", ledgerTotals(model.JournalEntryCapture, model.LedgerAccountClearing), captureGross, 1},
+		{"capture_fees", ledgerTotals(model.JournalEntryCapture, model.LedgerAccountFees), captureFees, -1},
+		{"refunds", ledgerTotals(model.JournalEntryRefund, model.LedgerAccountClearing), refundGross, -1},
+		{"refund_fees", ledgerTotals(model.JournalEntryRefund, model.LedgerAccountFees), refundFees, 1},
+		{"reserves", func() ([]repository.MerchantAmount, error) {
+			return s.ledgerRepo.AccountBalances(model.LedgerAccountReserves)
+		}, reserves, -1},
+		{"chargebacks", func() ([]repository.MerchantAmount, error) {
+			return heldChargebacks, nil
+		}, openChargebacks, 1},
+	}
+	for _, check := range checks {
+		actual, err := check.actual()
+		if err != nil {
+			return fmt.Errorf("failed to total %s in the ledger: %w", check.name, err)
+		}
+		report.compareTotals(check.name, actual, check.expected, check.sign)
+	}
+	return nil
+}
+
+func (r *LedgerCheckReport) addAmountMismatches(check string, mismatches []repository.AmountMismatch) {
+	for _, m := range mismatches {
+		r.Violations = append(r.Violations, LedgerViolation{
+			Check: check, MerchantID: m.MerchantID, SubjectID: m.TransactionID, Actual: m.Recorded, Expected: m.Expected,
+		})
+	}
+}
+
+// compareTotals flags every merchant whose ledger total differs from the
+// expected total, signed by sign
+func (r *LedgerCheckReport) compareTotals(check string, actual, expected []repository.MerchantAmount, sign int64) {
+	totals := make(map[uuid.UUID][2]int64)
+	for _, a := range actual {
+		t := totals[a.MerchantID]
+		t[0] = a.Amount
+		totals[a.MerchantID] = t
+	}
+	for _, e := range expected {
+		t := totals[e.MerchantID]
+		t[1] = sign * e.Amount
+		totals[e.MerchantID] = t
+	}
+
+	flagged := 0
+	for merchantID, t := range totals {
+		if t[0] == t[1] {
+			continue
+		}
+		if flagged++; flagged > maxLedgerViolations {
+			return
+		}
+		r.Violations = append(r.Violations, LedgerViolation{
+			Check: check, MerchantID: merchantID, Actual: t[0], Expected: t[1],
+		})
+	}
+}
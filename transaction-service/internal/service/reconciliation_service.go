@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/connector"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+var ErrClearingFileExists = errors.New("clearing file already ingested for this day")
+
+// Batches still waiting on a clearing file after this long are left alone
+const reconciliationBatchLookback = 14 * 24 * time.Hour
+
+// The nightly worker reconciles a day at 01:00 UTC the day after; a day
+// with no clearing file by then was missed
+const reconciliationRunDelay = 25 * time.Hour
+
+// ReconciliationService checks the card network's daily clearing files
+// against our captures, refunds and settlement batches, the way an acquirer
+// reconciles what the network says it moved with what it paid out. Only
+// the card simulator produces clearing files.
+type ReconciliationService struct {
+	txnRepo        *repository.TransactionRepository
+	settlementRepo *repository.SettlementRepository
+	reconRepo      *repository.ReconciliationRepository
+	network        *client.CardSimulatorClient
+	alerts         *client.OperatorAlertClient
+}
+
+func NewReconciliationService() *ReconciliationService {
+	return &ReconciliationService{
+		txnRepo:        repository.NewTransactionRepository(),
+		settlementRepo: repository.NewSettlementRepository(),
+		reconRepo:      repository.NewReconciliationRepository(),
+		network:        client.NewCardSimulatorClient(),
+		alerts:         client.NewOperatorAlertClient(),
+	}
+}
+
+// ReconcileDay fetches the simulator's clearing file for a UTC day and
+// reconciles it
+func (s *ReconciliationService) ReconcileDay(ctx context.Context, day time.Time) (*model.ClearingFile, error) {
+	records, err := s.network.ClearingRecords(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clearing file: %w", err)
+	}
+	return s.reconcile(ctx, day, records)
+}
+
+// IngestClearingFile reconciles a clearing file received out of band, such
+// as one an operator re-sends after a failed run
+func (s *ReconciliationService) IngestClearingFile(ctx context.Context, day time.Time, data []byte) (*model.ClearingFile, error) {
+	records, err := client.ParseClearingFile(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.reconcile(ctx, day, records)
+}
+
+func (s *ReconciliationService) reconcile(ctx context.Context, day time.Time, records []client.ClearingRecord) (*model.ClearingFile, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	exists, err := s.reconRepo.FileExists(connector.SimulatorConnectorName, from)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrClearingFileExists
+	}
+
+	ids := make([]uuid.UUID, len(records))
+	for i, r := range records {
+		ids[i] = r.TransactionID
+	}
+	txns, err := s.txnRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*model.Transaction, len(txns))
+	for i := range txns {
+		byID[txns[i].ID] = &txns[i]
+	}
+
+	file := &model.ClearingFile{
+		Connector:   connector.SimulatorConnectorName,
+		FileDate:    from,
+		RecordCount: len(records),
+	}
+
+	var cleared []uuid.UUID
+	for _, record := range records {
+		txn := byID[record.TransactionID]
+		mismatch := checkClearingRecord(record, txn)
+
+		if txn != nil && !txn.ClearedAt.Valid {
+			cleared = append(cleared, txn.ID)
+			txn.ClearedAt.Valid = true
+		}
+		if mismatch != nil {
+			file.Mismatches = append(file.Mismatches, *mismatch)
+		} else {
+			file.MatchedCount++
+		}
+	}
+
+	if err := s.txnRepo.MarkCleared(cleared, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	// Anything we captured or refunded that day and no file has cleared
+	missing, err := s.txnRepo.FindUnclearedCaptures(connector.SimulatorConnectorName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range missing {
+		txn := &missing[i]
+		file.Mismatches = append(file.Mismatches, model.ReconciliationMismatch{
+			TransactionID:     txn.ID,
+			MerchantID:        txn.MerchantID,
+			SettlementBatchID: txn.SettlementBatchID,
+			Type:              model.MismatchMissingFromFile,
+			ExpectedAmount:    txn.CapturedAmount,
+			Currency:          txn.Currency,
+			Detail:            "captured but not in the network's clearing file",
+		})
+	}
+
+	missingRefunds, err := s.txnRepo.FindUnclearedRefunds(connector.SimulatorConnectorName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range missingRefunds {
+		txn := &missingRefunds[i]
+		file.Mismatches = append(file.Mismatches, model.ReconciliationMismatch{
+			TransactionID:     txn.ID,
+			MerchantID:        txn.MerchantID,
+			SettlementBatchID: txn.SettlementBatchID,
+			Type:              model.MismatchMissingFromFile,
+			ExpectedAmount:    -txn.Amount,
+			Currency:          txn.Currency,
+			Detail:            "refunded but not in the network's clearing file",
+		})
+	}
+
+	file.MismatchCount = len(file.Mismatches)
+	file.Status = model.ClearingFileReconciled
+	if file.MismatchCount > 0 {
+		file.Status = model.ClearingFileMismatched
+	}
+	if err := s.reconRepo.CreateFile(file); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Clearing file reconciled",
+		zap.String("file_date", from.Format("2006-01-02")),
+		zap.Int("records", file.RecordCount),
+		zap.Int("matched", file.MatchedCount),
+		zap.Int("mismatches", file.MismatchCount),
+	)
+	for _, m := range file.Mismatches {
+		logger.Log.Warn("Clearing mismatch flagged",
+			zap.String("type", string(m.Type)),
+			zap.String("transaction_id", m.TransactionID.String()),
+			zap.Int64("expected_amount", m.ExpectedAmount),
+			zap.Int64("cleared_amount", m.ClearedAmount),
+		)
+	}
+
+	if file.MismatchCount > 0 {
+		s.alerts.Raise(ctx, client.OperatorAlert{
+			Type:    "clearing_mismatch",
+			Message: fmt.Sprintf("%d clearing mismatches on %s", file.MismatchCount, from.Format("2006-01-02")),
+			Details: map[string]interface{}{"clearing_file_id": file.ID, "file_date": from.Format("2006-01-02")},
+		})
+	}
+
+	s.reconcileBatches()
+	return file, nil
+}
+
+// checkClearingRecord compares a clearing record with the transaction it
+// names. It returns nil when they agree. A transaction already marked
+// cleared, by an earlier file or row, is a duplicate.
+func checkClearingRecord(record client.ClearingRecord, txn *model.Transaction) *model.ReconciliationMismatch {
+	mismatch := &model.ReconciliationMismatch{
+		TransactionID: record.TransactionID,
+		MerchantID:    record.MerchantID,
+		ClearedAmount: record.Amount,
+		Currency:      record.Currency,
+	}
+
+	refund := record.Type == client.ClearingRecordRefund
+	if txn == nil {
+		mismatch.Type = model.MismatchUnknownTransaction
+		mismatch.Detail = "no transaction with this id"
+		if refund {
+			mismatch.Type = model.MismatchOrphanRefund
+			mismatch.Detail = fmt.Sprintf("no refund with this id on transaction %s", record.OriginalTransactionID)
+		}
+		return mismatch
+	}
+	mismatch.MerchantID = txn.MerchantID
+	mismatch.SettlementBatchID = txn.SettlementBatchID
+
+	// What we moved, as a positive amount like the record's
+	var expected int64
+	var done bool
+	if refund {
+		expected = -txn.Amount
+		done = txn.Type == model.TransactionTypeRefund &&
+			(txn.RefundStatus == model.RefundStatusSentToIssuer || txn.RefundStatus == model.RefundStatusSettled)
+	} else {
+		expected = txn.CapturedAmount
+		done = txn.Type != model.TransactionTypeRefund && txn.CapturedAt.Valid
+	}
+	mismatch.ExpectedAmount = expected
+
+	switch {
+	case txn.ClearedAt.Valid:
+		mismatch.Type = model.MismatchDuplicateClearing
+		mismatch.Detail = "transaction was already cleared"
+	case refund && txn.Type != model.TransactionTypeRefund:
+		mismatch.Type = model.MismatchOrphanRefund
+		mismatch.Detail = fmt.Sprintf("transaction is a %s here, not a refund", txn.Type)
+	case refund && !done:
+		mismatch.Type = model.MismatchOrphanRefund
+		mismatch.Detail = fmt.Sprintf("refund is %s here", txn.RefundStatus)
+	case !done:
+		mismatch.Type = model.MismatchNotCaptured
+		mismatch.Detail = fmt.Sprintf("transaction is %s here", txn.Status)
+	case txn.Currency != record.Currency:
+		mismatch.Type = model.MismatchCurrency
+		mismatch.Detail = fmt.Sprintf("%s in %s, cleared in %s", record.Type, txn.Currency, record.Currency)
+	case expected != record.Amount:
+		mismatch.Type = model.MismatchAmount
+		mismatch.Detail = fmt.Sprintf("%s %d here, cleared %d", record.Type, expected, record.Amount)
+	default:
+		return nil
+	}
+	return mismatch
+}
+
+// reconcileBatches marks recent batches matched or mismatched once every
+// capture and refund in them has been through a clearing file
+func (s *ReconciliationService) reconcileBatches() {
+	batches, err := s.settlementRepo.FindUnreconciledSince(time.Now().Add(-reconciliationBatchLookback))
+	if err != nil {
+		logger.Log.Error("Failed to load batches for reconciliation", zap.Error(err))
+		return
+	}
+
+	for _, batch := range batches {
+		uncleared, err := s.txnRepo.CountUnclearedInBatch(batch.ID, connector.SimulatorConnectorName)
+		if err != nil {
+			logger.Log.Error("Failed to check batch clearing", zap.String("batch_id", batch.ID.String()), zap.Error(err))
+			continue
+		}
+		mismatches, err := s.reconRepo.CountMismatchesInBatch(batch.ID)
+		if err != nil {
+			logger.Log.Error("Failed to check batch mismatches", zap.String("batch_id", batch.ID.String()), zap.Error(err))
+			continue
+		}
+
+		var status string
+		switch {
+		case mismatches > 0:
+			status = model.BatchReconciliationMismatched
+		case uncleared == 0:
+			status = model.BatchReconciliationMatched
+		default:
+			continue // Wait for the next clearing file
+		}
+
+		if err := s.settlementRepo.SetReconciliation(batch.ID, status); err != nil {
+			logger.Log.Error("Failed to record batch reconciliation", zap.String("batch_id", batch.ID.String()), zap.Error(err))
+			continue
+		}
+		if status == model.BatchReconciliationMismatched {
+			logger.Log.Warn("Settlement batch does not match clearing",
+				zap.String("batch_id", batch.ID.String()),
+				zap.String("merchant_id", batch.MerchantID.String()),
+				zap.Int64("mismatches", mismatches),
+			)
+		}
+	}
+}
+
+// GenerateClearingFile returns the simulator's clearing file for a UTC day
+func (s *ReconciliationService) GenerateClearingFile(ctx context.Context, day time.Time) ([]byte, error) {
+	return s.network.GenerateClearingFile(ctx, day)
+}
+
+func (s *ReconciliationService) ListClearingFiles(limit int) ([]model.ClearingFile, error) {
+	return s.reconRepo.ListFiles(limit)
+}
+
+// GetClearingFile returns a clearing file with its mismatches
+func (s *ReconciliationService) GetClearingFile(id uuid.UUID) (*model.ClearingFile, error) {
+	return s.reconRepo.FindFile(id)
+}
+
+// ReconciliationReport sums up the clearing files for a range of UTC days.
+// Amounts are in minor units of each summary's currency.
+type ReconciliationReport struct {
+	From        time.Time                    `json:"from"`
+	To          time.Time                    `json:"to"`
+	Records     int                          `json:"records"`
+	Matched     int                          `json:"matched"`
+	Mismatches  int                          `json:"mismatches"`
+	ByType      []repository.MismatchSummary `json:"by_type"`
+	MissingDays []string                     `json:"missing_days"` // Days due a simulator clearing file that was never reconciled
+	Files       []model.ClearingFile         `json:"files"`
+}
+
+// GetReport sums up the clearing files reconciled for UTC days in
+// [from, to]
+func (s *ReconciliationService) GetReport(from, to time.Time) (*ReconciliationReport, error) {
+	files, err := s.reconRepo.FindFilesBetween(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load clearing files: %w", err)
+	}
+	byType, err := s.reconRepo.SummarizeMismatches(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize mismatches: %w", err)
+	}
+
+	report := &ReconciliationReport{
+		From:        from,
+		To:          to,
+		ByType:      byType,
+		MissingDays: []string{},
+		Files:       files,
+	}
+	reconciled := make(map[string]bool, len(files))
+	for _, file := range files {
+		report.Records += file.RecordCount
+		report.Matched += file.MatchedCount
+		report.Mismatches += file.MismatchCount
+		if file.Connector == connector.SimulatorConnectorName {
+			reconciled[file.FileDate.UTC().Format("2006-01-02")] = true
+		}
+	}
+
+	now := time.Now()
+	for day := from; !day.After(to) && day.Add(reconciliationRunDelay).Before(now); day = day.AddDate(0, 0, 1) {
+		if date := day.Format("2006-01-02"); !reconciled[date] {
+			report.MissingDays = append(report.MissingDays, date)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// FeeReversalPolicy decides how much of the processing fee a refund gives
+// back to the merchant
+type FeeReversalPolicy string
+
+const (
+	FeeReversalFull    FeeReversalPolicy = "full"    // Whole fee, pro rata to the amount refunded
+	FeeReversalPartial FeeReversalPolicy = "partial" // Percentage part only; the fixed fee is kept
+	FeeReversalNone    FeeReversalPolicy = "none"    // Fees are never returned
+)
+
+var ErrInvalidProrationBase = errors.New("cannot prorate against a zero amount")
+
+// LoadFeeReversalPolicy reads REFUND_FEE_REVERSAL, defaulting to full
+func LoadFeeReversalPolicy() FeeReversalPolicy {
+	raw := strings.ToLower(config.GetEnvWithDefault("REFUND_FEE_REVERSAL", string(FeeReversalFull)))
+	switch policy := FeeReversalPolicy(raw); policy {
+	case FeeReversalFull, FeeReversalPartial, FeeReversalNone:
+		return policy
+	default:
+		logger.Log.Warn("Unknown REFUND_FEE_REVERSAL, using full", zap.String("value", raw))
+		return FeeReversalFull
+	}
+}
+
+// prorate returns amount * part / whole rounded half away from zero. It
+// refuses a zero whole instead of dividing by it.
+func prorate(amount, part, whole int64) (int64, error) {
+	if whole <= 0 {
+		return 0, ErrInvalidProrationBase
+	}
+	product := amount * part
+	if product < 0 {
+		return -((-product + whole/2) / whole), nil
+	}
+	return (product + whole/2) / whole, nil
+}
+
+// refundAmounts is the MAD side of one refund
+type refundAmounts struct {
+	AmountMAD   int64 // Positive; stored negated on the refund transaction
+	FeeReversed int64 // Positive; stored negated as the refund's ProcessingFee
+}
+
+// calculateRefundAmounts splits a refund of amount (in the original
+// currency) into MAD and fee reversal.
+//
+// AmountMAD and ProcessingFee on the original were computed on the full
+// authorized Amount, so both are first scaled to what was actually
+// captured, then to the share refunded. The refund that empties the
+// transaction takes whatever is left, so rounding never refunds more MAD
+// or fee than was captured. refundedMAD and reversedFee are the totals of
+// earlier refunds that did not fail, queued ones included; remaining is what
+// is still refundable once those queued refunds are taken out.
+func calculateRefundAmounts(original *model.Transaction, amount, remaining, refundedMAD, reversedFee int64, policy FeeReversalPolicy) (*refundAmounts, error) {
+	if original.CapturedAmount <= 0 || original.Amount <= 0 {
+		return nil, ErrInvalidProrationBase
+	}
+
+	capturedMAD, err := prorate(original.AmountMAD, original.CapturedAmount, original.Amount)
+	if err != nil {
+		return nil, err
+	}
+	capturedFee, err := prorate(reversibleFee(original.ProcessingFee, policy), original.CapturedAmount, original.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &refundAmounts{}
+	if amount >= remaining {
+		result.AmountMAD = capturedMAD - refundedMAD
+		result.FeeReversed = capturedFee - reversedFee
+	} else {
+		if result.AmountMAD, err = prorate(capturedMAD, amount, original.CapturedAmount); err != nil {
+			return nil, err
+		}
+		if result.FeeReversed, err = prorate(capturedFee, amount, original.CapturedAmount); err != nil {
+			return nil, err
+		}
+	}
+
+	// Earlier refunds may have been booked before the fix with other math
+	if result.AmountMAD < 0 {
+		result.AmountMAD = 0
+	}
+	if result.FeeReversed < 0 {
+		result.FeeReversed = 0
+	}
+	return result, nil
+}
+
+// reversibleFee is the part of a transaction's fee the policy lets a full
+// refund give back
+func reversibleFee(fee int64, policy FeeReversalPolicy) int64 {
+	switch policy {
+	case FeeReversalNone:
+		return 0
+	case FeeReversalPartial:
+		if fee <= processingFeeFixedMAD {
+			return 0
+		}
+		return fee - processingFeeFixedMAD
+	default:
+		return fee
+	}
+}
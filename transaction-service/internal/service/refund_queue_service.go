@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRefundMaxInFlight = 2
+	defaultRefundRate        = 5 // refunds started per second
+	// refundSlotLease bounds how long a dead pod can hold a slot; it is
+	// well above the acquirer timeout
+	refundSlotLease = 2 * time.Minute
+	// refundQueueBatchSize is how many queued refunds one drain pass looks at
+	refundQueueBatchSize = 200
+	// refundQueueStatsWindow is how far back sent and failed refunds are counted
+	refundQueueStatsWindow = 24 * time.Hour
+)
+
+// RefundQueue paces refunds to the acquirer per merchant. A refund starts
+// right away when the merchant has a free slot (REFUND_MAX_IN_FLIGHT
+// concurrent, REFUND_RATE_PER_SECOND started per second); otherwise it is
+// stored as queued and drained in order by the refund queue worker, so a
+// bulk refund run neither overwhelms the acquirer nor fails.
+type RefundQueue struct {
+	queueRepo     *repository.RefundQueueRepository
+	maxInFlight   int64
+	ratePerSecond int64
+}
+
+func NewRefundQueue() *RefundQueue {
+	return &RefundQueue{
+		queueRepo:     repository.NewRefundQueueRepository(),
+		maxInFlight:   envInt64("REFUND_MAX_IN_FLIGHT", defaultRefundMaxInFlight),
+		ratePerSecond: envInt64("REFUND_RATE_PER_SECOND", defaultRefundRate),
+	}
+}
+
+// RefundQueueStatus reports how a merchant's refunds are draining
+type RefundQueueStatus struct {
+	Queued                int64
+	Processing            int64
+	Sent                  int64
+	Failed                int64
+	OldestQueuedAt        *time.Time
+	EstimatedDrainSeconds int64
+}
+
+// acquire takes one of the merchant's refund slots and returns its lease.
+// Without Redis refunds are not held back.
+func (q *RefundQueue) acquire(merchantID uuid.UUID) (string, bool) {
+	lease := uuid.NewString()
+	ok, err := q.queueRepo.AcquireRefundSlot(merchantID, lease, q.maxInFlight, q.ratePerSecond, refundSlotLease)
+	if err != nil {
+		logger.Log.Warn("Refund limiter unavailable, sending refund without pacing",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+		return "", true
+	}
+	return lease, ok
+}
+
+func (q *RefundQueue) release(merchantID uuid.UUID, lease string) {
+	if lease == "" {
+		return
+	}
+	if err := q.queueRepo.ReleaseRefundSlot(merchantID, lease); err != nil {
+		logger.Log.Warn("Failed to release refund slot",
+			zap.String("merchant_id", merchantID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// admit decides whether a new refund can go to the acquirer now. It queues
+// behind refunds the merchant already has waiting, so refunds stay in order.
+func (q *RefundQueue) admit(merchantID uuid.UUID) (string, bool, error) {
+	waiting, err := q.queueRepo.HasQueued(merchantID)
+	if err != nil {
+		return "", false, err
+	}
+	if waiting {
+		return "", false, nil
+	}
+	lease, ok := q.acquire(merchantID)
+	return lease, ok, nil
+}
+
+// QueuedAmount is what the transaction's queued refunds will give back
+func (q *RefundQueue) QueuedAmount(transactionID uuid.UUID) (int64, error) {
+	return q.queueRepo.QueuedAmountByParent(transactionID)
+}
+
+// Status reports the merchant's queue and the last day's refund outcomes
+func (q *RefundQueue) Status(merchantID uuid.UUID) (*RefundQueueStatus, error) {
+	stats, err := q.queueRepo.Stats(merchantID, time.Now().Add(-refundQueueStatsWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RefundQueueStatus{
+		Queued:         stats.Queued,
+		Processing:     stats.Processing,
+		Sent:           stats.Sent,
+		Failed:         stats.Failed,
+		OldestQueuedAt: stats.OldestQueuedAt,
+	}
+	if q.ratePerSecond > 0 {
+		status.EstimatedDrainSeconds = (stats.Queued + q.ratePerSecond - 1) / q.ratePerSecond
+	}
+	return status, nil
+}
+
+// GetRefundQueueStatus reports how the merchant's refunds are draining
+func (s *TransactionService) GetRefundQueueStatus(merchantID uuid.UUID) (*RefundQueueStatus, error) {
+	return s.refundQueue.Status(merchantID)
+}
+
+// ProcessRefundQueue sends queued refunds, oldest first, as their merchants'
+// slots allow, and returns how many it sent. A merchant whose slots are all
+// taken is skipped until the next pass, so one backlog does not hold up others.
+func (s *TransactionService) ProcessRefundQueue(ctx context.Context) (int, error) {
+	queued, err := s.refundQueue.queueRepo.FindQueued(refundQueueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		sent int
+	)
+	full := make(map[uuid.UUID]bool)
+	for i := range queued {
+		refund := &queued[i]
+		if full[refund.MerchantID] {
+			continue
+		}
+
+		lease, ok := s.refundQueue.acquire(refund.MerchantID)
+		if !ok {
+			full[refund.MerchantID] = true
+			continue
+		}
+
+		claimed, err := s.refundQueue.queueRepo.Claim(refund.ID)
+		if err != nil || !claimed {
+			s.refundQueue.release(refund.MerchantID, lease)
+			if err != nil {
+				logger.Log.Error("Failed to claim queued refund",
+					zap.String("refund_id", refund.ID.String()),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		refund.RefundStatus = model.RefundStatusRequested
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.refundQueue.release(refund.MerchantID, lease)
+
+			if _, err := s.sendQueuedRefund(ctx, refund); err != nil {
+				logger.Log.Warn("Queued refund failed",
+					zap.String("refund_id", refund.ID.String()),
+					zap.String("merchant_id", refund.MerchantID.String()),
+					zap.Error(err),
+				)
+				return
+			}
+			mu.Lock()
+			sent++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return sent, nil
+}
+
+// sendQueuedRefund sends a claimed refund with its original transaction
+func (s *TransactionService) sendQueuedRefund(ctx context.Context, refund *model.Transaction) (time.Time, error) {
+	parentID, err := uuid.Parse(refund.ParentTransactionID.String)
+	if err != nil {
+		s.refundTracking.MarkFailed(refund, "refund has no original transaction")
+		return time.Time{}, err
+	}
+	original, err := s.txnRepo.FindByID(parentID)
+	if err != nil {
+		// Leave it requested; failing it would hide that the original is unreadable
+		return time.Time{}, err
+	}
+	return s.sendRefund(ctx, original, refund)
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+// refundArrivalBusinessDays is how long issuers usually take to post a
+// refund after receiving it, by card brand
+var refundArrivalBusinessDays = map[string]int{
+	"visa":       5,
+	"mastercard": 5,
+	"amex":       7,
+	"discover":   7,
+}
+
+const (
+	// defaultRefundArrivalBusinessDays covers brands without a specific estimate
+	defaultRefundArrivalBusinessDays = 10
+	// settledRefundArrivalBusinessDays is the posting delay once the refund
+	// has been settled with the issuer
+	settledRefundArrivalBusinessDays = 2
+)
+
+var (
+	ErrRefundNotFound    = errors.New("refund not found")
+	ErrRefundNotInFlight = errors.New("refund is not awaiting issuer confirmation")
+)
+
+// RefundTrackingService follows refund transactions from request to the
+// cardholder's statement
+type RefundTrackingService struct {
+	txnRepo *repository.TransactionRepository
+}
+
+func NewRefundTrackingService() *RefundTrackingService {
+	return &RefundTrackingService{
+		txnRepo: repository.NewTransactionRepository(),
+	}
+}
+
+// MarkSent records that the acquirer accepted the refund and returns the
+// estimated arrival date
+func (s *RefundTrackingService) MarkSent(refund *model.Transaction, sentAt time.Time) (time.Time, error) {
+	eta := EstimateRefundArrival(sentAt, refund.CardBrand, model.RefundStatusSentToIssuer)
+	if err := s.txnRepo.MarkRefundSent(refund.ID, sentAt, eta); err != nil {
+		return time.Time{}, err
+	}
+
+	refund.RefundStatus = model.RefundStatusSentToIssuer
+	refund.SentToIssuerAt.Time, refund.SentToIssuerAt.Valid = sentAt, true
+	refund.EstimatedArrivalAt.Time, refund.EstimatedArrivalAt.Valid = eta, true
+	return eta, nil
+}
+
+func (s *RefundTrackingService) MarkFailed(refund *model.Transaction, reason string) {
+	if err := s.txnRepo.MarkRefundFailed(refund.ID, reason); err != nil {
+		logger.Log.Error("Failed to mark refund as failed",
+			zap.String("refund_id", refund.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// SettleBatch moves a settled batch's refunds to settled
+func (s *RefundTrackingService) SettleBatch(batchID uuid.UUID, settledAt time.Time) error {
+	eta := EstimateRefundArrival(settledAt, "", model.RefundStatusSettled)
+	count, err := s.txnRepo.MarkBatchRefundsSettled(batchID, settledAt, eta)
+	if err != nil {
+		return fmt.Errorf("failed to settle batch refunds: %w", err)
+	}
+
+	if count > 0 {
+		logger.Log.Info("Refunds settled with batch",
+			zap.String("batch_id", batchID.String()),
+			zap.Int64("refund_count", count),
+		)
+	}
+	return nil
+}
+
+// ConfirmPosted handles an issuer callback confirming that a refund was
+// posted ahead of the settlement batch
+func (s *RefundTrackingService) ConfirmPosted(refundID uuid.UUID) (*model.Transaction, error) {
+	refund, err := s.txnRepo.FindByID(refundID)
+	if err != nil || refund.Type != model.TransactionTypeRefund {
+		return nil, ErrRefundNotFound
+	}
+	if refund.RefundStatus != model.RefundStatusSentToIssuer {
+		return nil, ErrRefundNotInFlight
+	}
+
+	now := time.Now()
+	eta := EstimateRefundArrival(now, refund.CardBrand, model.RefundStatusSettled)
+	if err := s.txnRepo.MarkRefundSettled(refund.ID, now, eta); err != nil {
+		return nil, err
+	}
+
+	s.txnRepo.CreateEvent(&model.TransactionEvent{
+		TransactionID: refund.ID,
+		EventType:     "refund_settled",
+		OldStatus:     refund.Status,
+		NewStatus:     refund.Status,
+		Amount:        -refund.Amount,
+	})
+
+	refund.RefundStatus = model.RefundStatusSettled
+	refund.SettledAt.Time, refund.SettledAt.Valid = now, true
+	refund.EstimatedArrivalAt.Time, refund.EstimatedArrivalAt.Valid = eta, true
+	return refund, nil
+}
+
+func (s *RefundTrackingService) GetRefund(refundID, merchantID uuid.UUID) (*model.Transaction, error) {
+	return s.txnRepo.FindRefundByIDAndMerchant(refundID, merchantID)
+}
+
+func (s *RefundTrackingService) ListRefunds(transactionID, merchantID uuid.UUID) ([]model.Transaction, error) {
+	return s.txnRepo.FindRefundsByParent(transactionID, merchantID)
+}
+
+// ListMerchantRefunds pages through the merchant's refunds, newest first,
+// and returns how many match the filter
+func (s *RefundTrackingService) ListMerchantRefunds(merchantID uuid.UUID, filter repository.RefundFilter) ([]model.Transaction, int64, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.txnRepo.FindRefundsByMerchant(merchantID, filter)
+}
+
+// EstimateRefundArrival returns the date the cardholder should see the
+// refund, counting business days from the last status change
+func EstimateRefundArrival(from time.Time, cardBrand string, status model.RefundStatus) time.Time {
+	days := defaultRefundArrivalBusinessDays
+	if status == model.RefundStatusSettled {
+		days = settledRefundArrivalBusinessDays
+	} else if d, ok := refundArrivalBusinessDays[strings.ToLower(cardBrand)]; ok {
+		days = d
+	}
+	return addBusinessDays(from, days)
+}
+
+func addBusinessDays(from time.Time, days int) time.Time {
+	from = from.UTC()
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return date
+}
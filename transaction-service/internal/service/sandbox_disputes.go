@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
+)
+
+// sandboxDisputeLast4 ends the test card (4000000000000259) whose captures
+// the simulated issuer disputes as fraudulent
+const sandboxDisputeLast4 = "0259"
+
+// openSandboxDispute opens a chargeback for the full captured amount when a
+// sandbox authorization on the dispute test card is fully captured, so
+// integrations can exercise the dispute flow. Live transactions are only
+// disputed by their issuer.
+func (s *TransactionService) openSandboxDispute(ctx context.Context, txn *model.Transaction, capturedAmount int64) {
+	if !txn.TestMode || txn.CardLast4 != sandboxDisputeLast4 {
+		return
+	}
+
+	_, err := s.chargebacks.CreateChargeback(ctx, &CreateChargebackRequest{
+		TransactionID:     txn.ID,
+		Reason:            model.ChargebackReasonFraud,
+		ReasonCode:        "10.4", // Visa: other fraud, card-absent environment
+		Amount:            capturedAmount,
+		CustomerStatement: "I did not authorize this payment.",
+		IssuerReference:   "sandbox_" + uuid.NewString()[:8],
+		IssuerBank:        "Sandbox Issuer",
+	})
+	if err != nil {
+		logger.Log.Error("Failed to open sandbox dispute",
+			zap.String("transaction_id", txn.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAnomalyThresholdPct = 200
+	defaultAnomalyMinDays      = 7
+	anomalyBaselineDays        = 30
+)
+
+// SettlementGuardrails are platform-wide limits on money leaving the
+// platform. Amounts are MAD in minor units; zero disables a limit.
+type SettlementGuardrails struct {
+	MaxDailyPayout      int64 `json:"max_daily_payout"`      // Total net paid out per UTC day
+	MaxBatchPayout      int64 `json:"max_batch_payout"`      // Net amount of a single batch
+	AnomalyThresholdPct int64 `json:"anomaly_threshold_pct"` // Hold when gross exceeds the baseline by more than this
+	AnomalyMinDays      int   `json:"anomaly_min_days"`      // Days of history needed before the baseline is trusted
+}
+
+// LoadSettlementGuardrails reads the guardrails from the environment
+func LoadSettlementGuardrails() SettlementGuardrails {
+	return SettlementGuardrails{
+		MaxDailyPayout:      envInt64("SETTLEMENT_MAX_DAILY_PAYOUT", 0),
+		MaxBatchPayout:      envInt64("SETTLEMENT_MAX_BATCH_PAYOUT", 0),
+		AnomalyThresholdPct: envInt64("SETTLEMENT_ANOMALY_THRESHOLD_PCT", defaultAnomalyThresholdPct),
+		AnomalyMinDays:      int(envInt64("SETTLEMENT_ANOMALY_MIN_DAYS", defaultAnomalyMinDays)),
+	}
+}
+
+func envInt64(key string, defaultValue int64) int64 {
+	raw := config.GetEnv(key)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		logger.Log.Warn("Ignoring invalid guardrail setting",
+			zap.String("key", key),
+			zap.String("value", raw),
+		)
+		return defaultValue
+	}
+	return value
+}
+
+// checkNewBatch applies the per-batch guardrails to a freshly created batch
+// and holds it if one trips
+func (s *SettlementService) checkNewBatch(batch *model.SettlementBatch) {
+	if limit := s.guardrails.MaxBatchPayout; limit > 0 && batch.NetAmount > limit {
+		s.holdBatch(batch, model.SettlementHoldBatchLimit,
+			fmt.Sprintf("net amount %d exceeds the per-settlement maximum %d", batch.NetAmount, limit),
+			map[string]interface{}{"net_amount": batch.NetAmount, "limit": limit},
+		)
+		return
+	}
+
+	pct := s.guardrails.AnomalyThresholdPct
+	if pct == 0 || batch.GrossAmount <= 0 {
+		return
+	}
+
+	// Weekly and monthly batches are compared day for day, over a window
+	// long enough to hold earlier batches of the same length
+	batchDays := batchPeriodDays(batch)
+	lookback := max(anomalyBaselineDays, 2*batchDays)
+
+	from := batch.BatchDate.AddDate(0, 0, -lookback)
+	total, days, err := s.settlementRepo.GrossVolumeInRange(batch.MerchantID, from, batch.BatchDate)
+	if err != nil {
+		logger.Log.Error("Failed to load volume baseline",
+			zap.String("merchant_id", batch.MerchantID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	// New merchants have no meaningful baseline yet
+	if days < s.guardrails.AnomalyMinDays || total <= 0 {
+		return
+	}
+
+	// Only spikes are held; a quiet day moves less money, not more
+	baseline := total / int64(days)
+	daily := batch.GrossAmount / int64(batchDays)
+	if daily*100 <= baseline*(100+pct) {
+		return
+	}
+
+	deviation := (daily - baseline) * 100 / baseline
+	s.holdBatch(batch, model.SettlementHoldVolumeAnomaly,
+		fmt.Sprintf("daily gross volume %d is %d%% above the %d-day daily average %d", daily, deviation, lookback, baseline),
+		map[string]interface{}{
+			"gross_amount":  batch.GrossAmount,
+			"batch_days":    batchDays,
+			"baseline":      baseline,
+			"baseline_days": days,
+			"deviation_pct": deviation,
+			"threshold_pct": pct,
+		},
+	)
+}
+
+// batchPeriodDays is how many local days a batch covers, at least one.
+// Rounding absorbs the hour a DST change adds or removes.
+func batchPeriodDays(batch *model.SettlementBatch) int {
+	days := int(math.Round(batch.PeriodEnd.Sub(batch.PeriodStart).Hours() / 24))
+	return max(days, 1)
+}
+
+// exceedsDailyLimit reports whether paying the batch would take today's
+// payouts over the daily maximum. Batches an operator released are let
+// through.
+func (s *SettlementService) exceedsDailyLimit(batch *model.SettlementBatch, paidToday int64) bool {
+	limit := s.guardrails.MaxDailyPayout
+	return limit > 0 && !batch.ReleasedAt.Valid && paidToday+batch.NetAmount > limit
+}
+
+// holdForDailyLimit holds a batch that would take today's payouts over the
+// daily maximum
+func (s *SettlementService) holdForDailyLimit(batch *model.SettlementBatch, paidToday int64) {
+	s.holdBatch(batch, model.SettlementHoldDailyLimit,
+		fmt.Sprintf("paying %d would take today's payouts from %d over the daily maximum %d",
+			batch.NetAmount, paidToday, s.guardrails.MaxDailyPayout),
+		map[string]interface{}{
+			"net_amount": batch.NetAmount,
+			"paid_today": paidToday,
+			"limit":      s.guardrails.MaxDailyPayout,
+		},
+	)
+}
+
+// payoutsToday returns the net amount paid out since midnight UTC
+func (s *SettlementService) payoutsToday() (int64, error) {
+	now := time.Now().UTC()
+	return s.settlementRepo.SumSettledSince(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+func (s *SettlementService) holdBatch(batch *model.SettlementBatch, reason model.SettlementHoldReason, detail string, details map[string]interface{}) {
+	if err := s.settlementRepo.Hold(batch.ID, reason, detail); err != nil {
+		logger.Log.Error("Failed to hold settlement batch",
+			zap.String("batch_id", batch.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	batch.Status = model.SettlementStatusHeld
+	batch.HoldReason = sql.NullString{String: string(reason), Valid: true}
+	batch.HoldDetail = sql.NullString{String: detail, Valid: true}
+
+	s.alerts.Raise(context.Background(), client.OperatorAlert{
+		Type:       "settlement_held." + string(reason),
+		MerchantID: batch.MerchantID.String(),
+		BatchID:    batch.ID.String(),
+		Message:    detail,
+		Details:    details,
+	})
+}
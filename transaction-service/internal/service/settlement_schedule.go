@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSettlementDelayDays = 2
+	maxSettlementDelayDays     = 30
+	maxReserveBps              = 10000
+	defaultReserveDays         = 90
+	maxReserveDays             = 365
+)
+
+var ErrInvalidSettlementSchedule = errors.New("invalid settlement schedule")
+
+// SettlementScheduleUpdate changes the fields that are set and leaves the
+// rest as they are
+type SettlementScheduleUpdate struct {
+	Schedule        *string
+	Day             *int
+	DelayDays       *int
+	MinPayoutAmount *int64
+	ReserveBps      *int
+	ReserveDays     *int
+}
+
+// defaultSettlementSchedule is what merchants without a schedule get: daily
+// batches paid at T+2, with no minimum and no reserve. A reserve set later
+// is held for 90 days.
+func defaultSettlementSchedule(merchantID uuid.UUID) model.MerchantSettlementSchedule {
+	return model.MerchantSettlementSchedule{
+		MerchantID:  merchantID,
+		Schedule:    model.SettlementScheduleDaily,
+		DelayDays:   defaultSettlementDelayDays,
+		ReserveDays: defaultReserveDays,
+	}
+}
+
+func validateSettlementSchedule(schedule *model.MerchantSettlementSchedule) error {
+	switch schedule.Schedule {
+	case model.SettlementScheduleDaily:
+		if schedule.Day != 0 {
+			return fmt.Errorf("%w: day must be 0 for a daily schedule", ErrInvalidSettlementSchedule)
+		}
+	case model.SettlementScheduleWeekly:
+		if schedule.Day < 0 || schedule.Day > 7 {
+			return fmt.Errorf("%w: day must be an ISO weekday from 1 (Monday) to 7 (Sunday)", ErrInvalidSettlementSchedule)
+		}
+	case model.SettlementScheduleMonthly:
+		if schedule.Day < 0 || schedule.Day > 28 {
+			return fmt.Errorf("%w: day must be from 1 to 28, or 0 for the end of the month", ErrInvalidSettlementSchedule)
+		}
+	default:
+		return fmt.Errorf("%w: schedule must be daily, weekly or monthly", ErrInvalidSettlementSchedule)
+	}
+	if schedule.DelayDays < 0 || schedule.DelayDays > maxSettlementDelayDays {
+		return fmt.Errorf("%w: delay_days must be from 0 to %d", ErrInvalidSettlementSchedule, maxSettlementDelayDays)
+	}
+	if schedule.MinPayoutAmount < 0 {
+		return fmt.Errorf("%w: min_payout_amount cannot be negative", ErrInvalidSettlementSchedule)
+	}
+	if schedule.ReserveBps < 0 || schedule.ReserveBps > maxReserveBps {
+		return fmt.Errorf("%w: reserve_bps must be from 0 to %d", ErrInvalidSettlementSchedule, maxReserveBps)
+	}
+	if schedule.ReserveDays < 1 || schedule.ReserveDays > maxReserveDays {
+		return fmt.Errorf("%w: reserve_days must be from 1 to %d", ErrInvalidSettlementSchedule, maxReserveDays)
+	}
+	return nil
+}
+
+// schedulePeriodOf returns the settlement period t falls in: its local day,
+// the week ending on the schedule's weekday, or the month ending on the
+// schedule's day of the month
+func schedulePeriodOf(t time.Time, loc *time.Location, schedule *model.MerchantSettlementSchedule) settlementPeriod {
+	day := settlementDayOf(t, loc)
+	y, m, d := day.Date.Date()
+
+	var first, last time.Time
+	switch schedule.Schedule {
+	case model.SettlementScheduleWeekly:
+		endDay := schedule.Day
+		if endDay == 0 {
+			endDay = 7
+		}
+		weekday := int(day.Date.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		last = day.Date.AddDate(0, 0, (endDay-weekday+7)%7)
+		first = last.AddDate(0, 0, -6)
+	case model.SettlementScheduleMonthly:
+		switch endDay := schedule.Day; {
+		case endDay == 0:
+			first = time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+			last = first.AddDate(0, 1, -1)
+		case d <= endDay:
+			first = time.Date(y, m-1, endDay+1, 0, 0, 0, 0, time.UTC)
+			last = time.Date(y, m, endDay, 0, 0, 0, 0, time.UTC)
+		default:
+			first = time.Date(y, m, endDay+1, 0, 0, 0, 0, time.UTC)
+			last = time.Date(y, m+1, endDay, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		return day
+	}
+
+	fy, fm, fd := first.Date()
+	ly, lm, ld := last.Date()
+	return settlementPeriod{
+		Date:     last,
+		Start:    time.Date(fy, fm, fd, 0, 0, 0, 0, loc).UTC(),
+		End:      time.Date(ly, lm, ld+1, 0, 0, 0, 0, loc).UTC(),
+		Timezone: loc.String(),
+	}
+}
+
+// applyReserve holds back the schedule's share of gross volume from the
+// batch's net amount. It never takes more than the net amount, so a batch
+// with more refunds than captures isn't made worse.
+func applyReserve(batch *model.SettlementBatch, reserveBps int) {
+	if reserveBps <= 0 || batch.NetAmount <= 0 {
+		return
+	}
+	reserve := batch.GrossAmount * int64(reserveBps) / 10000
+	if reserve > batch.NetAmount {
+		reserve = batch.NetAmount
+	}
+	batch.ReserveAmount = reserve
+	batch.NetAmount -= reserve
+}
+
+// merchantSchedules loads the settlement schedule of each merchant, using
+// the default for merchants without one
+func (s *SettlementService) merchantSchedules(merchantTxns map[uuid.UUID][]model.Transaction) (map[uuid.UUID]model.MerchantSettlementSchedule, error) {
+	ids := make([]uuid.UUID, 0, len(merchantTxns))
+	for id := range merchantTxns {
+		ids = append(ids, id)
+	}
+
+	saved, err := s.settlementRepo.FindSchedules(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make(map[uuid.UUID]model.MerchantSettlementSchedule, len(ids))
+	for _, id := range ids {
+		schedules[id] = defaultSettlementSchedule(id)
+	}
+	for _, schedule := range saved {
+		if err := validateSettlementSchedule(&schedule); err != nil {
+			logger.Log.Warn("Ignoring invalid settlement schedule",
+				zap.String("merchant_id", schedule.MerchantID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		schedules[schedule.MerchantID] = schedule
+	}
+	return schedules, nil
+}
+
+// merchantSchedule loads one merchant's settlement schedule
+func (s *SettlementService) merchantSchedule(merchantID uuid.UUID) (model.MerchantSettlementSchedule, error) {
+	schedule, _, err := s.GetMerchantSchedule(merchantID)
+	if err != nil {
+		return model.MerchantSettlementSchedule{}, err
+	}
+	return *schedule, nil
+}
+
+// GetMerchantSchedule returns a merchant's settlement schedule and whether
+// it was set explicitly
+func (s *SettlementService) GetMerchantSchedule(merchantID uuid.UUID) (*model.MerchantSettlementSchedule, bool, error) {
+	schedule, err := s.settlementRepo.FindSchedule(merchantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fallback := defaultSettlementSchedule(merchantID)
+		return &fallback, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return schedule, true, nil
+}
+
+// UpdateMerchantSchedule changes part of a merchant's settlement schedule.
+// Changing the schedule without a day resets the day to the default, since
+// a weekday means nothing to a monthly schedule. Batches already created
+// keep their dates and amounts.
+func (s *SettlementService) UpdateMerchantSchedule(merchantID uuid.UUID, update SettlementScheduleUpdate) (*model.MerchantSettlementSchedule, error) {
+	schedule, err := s.settlementRepo.UpdateSchedule(merchantID, defaultSettlementSchedule(merchantID), func(schedule *model.MerchantSettlementSchedule) error {
+		if update.Schedule != nil && *update.Schedule != schedule.Schedule {
+			schedule.Schedule = *update.Schedule
+			schedule.Day = 0
+		}
+		if update.Day != nil {
+			schedule.Day = *update.Day
+		}
+		if update.DelayDays != nil {
+			schedule.DelayDays = *update.DelayDays
+		}
+		if update.MinPayoutAmount != nil {
+			schedule.MinPayoutAmount = *update.MinPayoutAmount
+		}
+		if update.ReserveBps != nil {
+			schedule.ReserveBps = *update.ReserveBps
+		}
+		if update.ReserveDays != nil {
+			schedule.ReserveDays = *update.ReserveDays
+		}
+		return validateSettlementSchedule(schedule)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidSettlementSchedule) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save settlement schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// DeleteMerchantSchedule returns a merchant to daily T+2 settlement
+func (s *SettlementService) DeleteMerchantSchedule(merchantID uuid.UUID) error {
+	return s.settlementRepo.DeleteSchedule(merchantID)
+}
@@ -4,43 +4,68 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type SettlementService struct {
 	settlementRepo  *repository.SettlementRepository
 	txnRepo         *repository.TransactionRepository
 	currencyService *CurrencyService
+	refundTracking  *RefundTrackingService
+	guardrails      SettlementGuardrails
+	alerts          *client.OperatorAlertClient
+	merchants       *client.MerchantClient
+	chargebackRepo  *repository.ChargebackRepository
+	instantPayouts  InstantPayoutSettings
+	balances        *BalanceService
 }
 
+var ErrBatchNotHeld = errors.New("settlement batch is not held")
+
 func NewSettlementService() *SettlementService {
 	return &SettlementService{
 		settlementRepo:  repository.NewSettlementRepository(),
 		txnRepo:         repository.NewTransactionRepository(),
 		currencyService: NewCurrencyService(),
+		refundTracking:  NewRefundTrackingService(),
+		guardrails:      LoadSettlementGuardrails(),
+		alerts:          client.NewOperatorAlertClient(),
+		merchants:       client.NewMerchantClient(),
+		chargebackRepo:  repository.NewChargebackRepository(),
+		instantPayouts:  LoadInstantPayoutSettings(),
+		balances:        NewBalanceService(),
 	}
 }
 
 // =========================================================================
-// Daily Settlement Batch Creation (Runs at midnight)
+// Settlement Batch Creation (Runs hourly, per merchant timezone)
 // =========================================================================
 
+// CreateDailySettlementBatches batches every settlement period that has
+// ended in the merchant's timezone: a day, or a week or month for merchants
+// on those schedules. A period whose net amount is below the merchant's
+// minimum payout is left unbatched and rolls into the next period. Periods
+// are cut against the database clock, and anything already batched is
+// skipped, so it is safe to run every hour and catches up after downtime.
 func (s *SettlementService) CreateDailySettlementBatches(ctx context.Context) error {
-	batchDate := time.Now().AddDate(0, 0, -1).Truncate(24 * time.Hour) // Yesterday
-
-	logger.Log.Info("Creating daily settlement batches",
-		zap.Time("batch_date", batchDate),
-	)
+	now, err := s.settlementRepo.Now()
+	if err != nil {
+		logger.Log.Warn("Failed to read database clock, using local clock", zap.Error(err))
+		now = time.Now().UTC()
+	}
 
-	// Get all captured transactions from yesterday
-	transactions, err := s.txnRepo.FindCapturedForSettlement(batchDate)
+	transactions, err := s.txnRepo.FindUnsettledBefore(now)
 	if err != nil {
 		logger.Log.Error("Failed to find transactions for settlement", zap.Error(err))
 		return err
@@ -54,38 +79,174 @@ func (s *SettlementService) CreateDailySettlementBatches(ctx context.Context) er
 	// Group transactions by merchant
 	merchantTxns := s.groupTransactionsByMerchant(transactions)
 
-	// Create batch for each merchant
+	locations, err := s.merchantLocations(merchantTxns)
+	if err != nil {
+		logger.Log.Error("Failed to load merchant settlement timezones", zap.Error(err))
+		return err
+	}
+
+	schedules, err := s.merchantSchedules(merchantTxns)
+	if err != nil {
+		logger.Log.Error("Failed to load merchant settlement schedules", zap.Error(err))
+		return err
+	}
+
+	batchCount := 0
 	for merchantID, txns := range merchantTxns {
-		if err := s.createMerchantSettlementBatch(merchantID, batchDate, txns); err != nil {
-			logger.Log.Error("Failed to create settlement batch",
-				zap.Error(err),
-				zap.String("merchant_id", merchantID.String()),
-			)
+		loc := locations[merchantID]
+		schedule := schedules[merchantID]
+
+		// Only periods that are over in the merchant's timezone are batched
+		periods := make(map[time.Time]settlementPeriod)
+		periodTxns := make(map[time.Time][]model.Transaction)
+		for _, txn := range txns {
+			period := schedulePeriodOf(settlementEventTime(&txn), loc, &schedule)
+			if period.End.After(now) {
+				continue
+			}
+			periods[period.Date] = period
+			periodTxns[period.Date] = append(periodTxns[period.Date], txn)
+		}
+
+		dates := make([]time.Time, 0, len(periods))
+		for date := range periods {
+			dates = append(dates, date)
+		}
+		sort.Slice(dates, func(a, b int) bool { return dates[a].Before(dates[b]) })
+
+		// Periods below the minimum payout are carried into the next one;
+		// whatever is still below it after the last ended period waits for
+		// a later run
+		var carried []model.Transaction
+		var carriedStart time.Time
+		for _, date := range dates {
+			period := periods[date]
+			if len(carried) == 0 {
+				carriedStart = period.Start
+			}
+			carried = append(carried, periodTxns[date]...)
+			period.Start = carriedStart
+
+			preview := newSettlementBatch(merchantID, period, carried)
+			applyReserve(preview, schedule.ReserveBps)
+			if net := preview.NetAmount; schedule.MinPayoutAmount > 0 && net < schedule.MinPayoutAmount {
+				logger.Log.Info("Settlement period below minimum payout, carrying forward",
+					zap.String("merchant_id", merchantID.String()),
+					zap.String("batch_date", date.Format("2006-01-02")),
+					zap.Int64("net_amount", net),
+					zap.Int64("min_payout_amount", schedule.MinPayoutAmount),
+				)
+				continue
+			}
+
+			if _, err := s.createMerchantSettlementBatch(ctx, merchantID, period, carried, schedule); err != nil {
+				logger.Log.Error("Failed to create settlement batch",
+					zap.Error(err),
+					zap.String("merchant_id", merchantID.String()),
+					zap.String("batch_date", date.Format("2006-01-02")),
+				)
+			} else {
+				batchCount++
+			}
+			carried = nil
 		}
 	}
 
-	logger.Log.Info("Daily settlement batches created",
+	logger.Log.Info("Settlement batches created",
 		zap.Int("merchant_count", len(merchantTxns)),
-		zap.Int("transaction_count", len(transactions)),
+		zap.Int("batch_count", batchCount),
 	)
 
 	return nil
 }
 
+// merchantLocations resolves the settlement timezone of each merchant,
+// falling back to SETTLEMENT_TIMEZONE
+func (s *SettlementService) merchantLocations(merchantTxns map[uuid.UUID][]model.Transaction) (map[uuid.UUID]*time.Location, error) {
+	ids := make([]uuid.UUID, 0, len(merchantTxns))
+	for id := range merchantTxns {
+		ids = append(ids, id)
+	}
+
+	overrides, err := s.settlementRepo.FindTimezones(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := defaultSettlementLocation()
+	locations := make(map[uuid.UUID]*time.Location, len(ids))
+	for _, id := range ids {
+		locations[id] = fallback
+	}
+	for _, o := range overrides {
+		loc, err := loadSettlementLocation(o.Timezone)
+		if err != nil {
+			logger.Log.Warn("Ignoring unknown settlement timezone",
+				zap.String("merchant_id", o.MerchantID.String()),
+				zap.String("timezone", o.Timezone),
+			)
+			continue
+		}
+		locations[o.MerchantID] = loc
+	}
+	return locations, nil
+}
+
 func (s *SettlementService) createMerchantSettlementBatch(
+	ctx context.Context,
 	merchantID uuid.UUID,
-	batchDate time.Time,
+	period settlementPeriod,
 	transactions []model.Transaction,
-) error {
+	schedule model.MerchantSettlementSchedule,
+) (*model.SettlementBatch, error) {
 	logger.Log.Info("Creating settlement batch for merchant",
 		zap.String("merchant_id", merchantID.String()),
 		zap.Int("transaction_count", len(transactions)),
 	)
 
-	// Calculate totals
+	batch := newSettlementBatch(merchantID, period, transactions)
+	applyReserve(batch, schedule.ReserveBps)
+	batch.SettlementDate = period.Date.AddDate(0, 0, schedule.DelayDays) // T+N settlement
+	batch.SettlementMethod = "bank_transfer"
+
+	s.attachPayoutAccount(ctx, batch)
+
+	txnIDs := make([]uuid.UUID, len(transactions))
+	for i, txn := range transactions {
+		txnIDs[i] = txn.ID
+	}
+
+	// The batch posts to the merchant's balance in the same transaction, and
+	// its payout is settled against what the balance holds
+	if err := s.txnRepo.CreateSettlementBatch(batch, txnIDs, func(tx *gorm.DB) error {
+		return s.balances.postBatch(tx, batch, schedule.ReserveDays)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save settlement batch: %w", err)
+	}
+
+	logger.Log.Info("Settlement batch created",
+		zap.String("batch_id", batch.ID.String()),
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int64("net_amount", batch.NetAmount),
+		zap.Int64("balance_adjustment", batch.BalanceAdjustment),
+		zap.Int("transaction_count", batch.TransactionCount),
+	)
+
+	s.checkNewBatch(batch)
+
+	// TODO: Send notification to merchant
+	// TODO: Generate settlement report (CSV)
+
+	return batch, nil
+}
+
+// newSettlementBatch totals the transactions into a pending batch for the
+// period. The caller sets the settlement date and method.
+func newSettlementBatch(merchantID uuid.UUID, period settlementPeriod, transactions []model.Transaction) *model.SettlementBatch {
 	var grossAmount int64
 	var refundAmount int64
 	var feeAmount int64
+	var feeReversalAmount int64
 	transactionCount := 0
 	refundCount := 0
 	currencyBreakdown := make(map[string]int64)
@@ -93,6 +254,7 @@ func (s *SettlementService) createMerchantSettlementBatch(
 	for _, txn := range transactions {
 		if txn.Type == model.TransactionTypeRefund {
 			refundAmount += -txn.AmountMAD // Refunds are negative
+			feeReversalAmount += -txn.ProcessingFee
 			refundCount++
 		} else {
 			grossAmount += txn.AmountMAD
@@ -104,61 +266,88 @@ func (s *SettlementService) createMerchantSettlementBatch(
 		currencyBreakdown[txn.Currency] += txn.Amount
 	}
 
-	netAmount := grossAmount - refundAmount - feeAmount
+	netAmount := grossAmount - refundAmount - feeAmount + feeReversalAmount
 
 	// Serialize currency breakdown
 	breakdownJSON, _ := json.Marshal(currencyBreakdown)
 
-	// Create settlement batch
-	batch := &model.SettlementBatch{
+	return &model.SettlementBatch{
 		MerchantID:        merchantID,
-		BatchDate:         batchDate,
+		BatchDate:         period.Date,
+		Timezone:          period.Timezone,
+		PeriodStart:       period.Start,
+		PeriodEnd:         period.End,
 		GrossAmount:       grossAmount,
 		RefundAmount:      refundAmount,
 		FeeAmount:         feeAmount,
+		FeeReversalAmount: feeReversalAmount,
 		NetAmount:         netAmount,
 		TransactionCount:  transactionCount,
 		RefundCount:       refundCount,
 		CurrencyBreakdown: sql.NullString{String: string(breakdownJSON), Valid: true},
 		Status:            model.SettlementStatusPending,
-		SettlementDate:    batchDate.AddDate(0, 0, 2), // T+2 settlement
-		SettlementMethod:  "bank_transfer",
-	}
-
-	// TODO: Get merchant bank details from merchant service
-	// batch.BankAccount = merchantBankAccount
-	// batch.BankName = merchantBankName
-
-	// Save batch
-	if err := s.settlementRepo.Create(batch); err != nil {
-		return fmt.Errorf("failed to save settlement batch: %w", err)
 	}
+}
 
-	// Link transactions to batch
-	txnIDs := make([]uuid.UUID, len(transactions))
-	for i, txn := range transactions {
-		txnIDs[i] = txn.ID
+// attachPayoutAccount records the merchant's default verified bank account on
+// the batch. A failed lookup or a merchant without a verified account is
+// logged and leaves the bank details empty rather than failing settlement.
+func (s *SettlementService) attachPayoutAccount(ctx context.Context, batch *model.SettlementBatch) {
+	account, err := s.merchants.GetPayoutAccount(ctx, batch.MerchantID.String())
+	if err != nil {
+		logger.Log.Warn("Failed to look up merchant payout account",
+			zap.String("merchant_id", batch.MerchantID.String()),
+			zap.Error(err),
+		)
+		return
 	}
-
-	if err := s.txnRepo.LinkToSettlementBatch(txnIDs, batch.ID); err != nil {
-		return fmt.Errorf("failed to link transactions to batch: %w", err)
+	if account == nil {
+		logger.Log.Warn("Merchant has no verified payout account",
+			zap.String("merchant_id", batch.MerchantID.String()),
+		)
+		return
 	}
 
-	logger.Log.Info("Settlement batch created",
-		zap.String("batch_id", batch.ID.String()),
-		zap.String("merchant_id", merchantID.String()),
-		zap.Int64("net_amount", netAmount),
-		zap.Int("transaction_count", transactionCount),
-	)
+	batch.BankAccount = sql.NullString{String: account.Iban, Valid: true}
+	batch.BankName = sql.NullString{String: account.BankName, Valid: true}
+}
 
-	// TODO: Send notification to merchant
-	// TODO: Generate settlement report (CSV)
+// CreateFinalSettlementBatch sweeps everything a closing merchant has left
+// unsettled into one batch dated today in the merchant's timezone, along with
+// any available balance. The schedule's delay still applies, but not its
+// minimum payout or reserve; reserve already held is paid out once released,
+// by calling this again. It returns nil when there is nothing to settle, so
+// it is safe to call again after a retry.
+func (s *SettlementService) CreateFinalSettlementBatch(ctx context.Context, merchantID uuid.UUID) (*model.SettlementBatch, error) {
+	transactions, err := s.txnRepo.FindUnsettledForMerchant(merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unsettled transactions: %w", err)
+	}
+	available, err := s.balances.available(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 && available <= 0 {
+		logger.Log.Info("No remaining balance to settle",
+			zap.String("merchant_id", merchantID.String()),
+		)
+		return nil, nil
+	}
 
-	return nil
+	loc, err := s.merchantLocation(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.merchantSchedule(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	schedule.ReserveBps = 0
+	return s.createMerchantSettlementBatch(ctx, merchantID, settlementDayOf(time.Now(), loc), transactions, schedule)
 }
 
 // =========================================================================
-// Process Pending Settlements (Runs on T+2)
+// Process Pending Settlements (Runs on each batch's settlement date)
 // =========================================================================
 
 // ProcessPendingSettlements processes settlements that are due
@@ -177,14 +366,27 @@ func (s *SettlementService) ProcessPendingSettlements(ctx context.Context) error
 		return nil
 	}
 
+	paidToday, err := s.payoutsToday()
+	if err != nil {
+		logger.Log.Error("Failed to total today's payouts", zap.Error(err))
+		return err
+	}
+
 	for _, batch := range batches {
+		if s.exceedsDailyLimit(&batch, paidToday) {
+			s.holdForDailyLimit(&batch, paidToday)
+			continue
+		}
+
 		if err := s.processSettlementBatch(&batch); err != nil {
 			logger.Log.Error("Failed to process settlement batch",
 				zap.Error(err),
 				zap.String("batch_id", batch.ID.String()),
 			)
 			// Continue with other batches
+			continue
 		}
+		paidToday += batch.NetAmount
 	}
 
 	logger.Log.Info("Pending settlements processed",
@@ -213,6 +415,14 @@ func (s *SettlementService) processSettlementBatch(batch *model.SettlementBatch)
 		return fmt.Errorf("failed to mark batch as settled: %w", err)
 	}
 
+	// Refunds in the batch are now with the issuer for posting
+	if err := s.refundTracking.SettleBatch(batch.ID, time.Now()); err != nil {
+		logger.Log.Error("Failed to update refund statuses",
+			zap.String("batch_id", batch.ID.String()),
+			zap.Error(err),
+		)
+	}
+
 	logger.Log.Info("Settlement batch processed successfully",
 		zap.String("batch_id", batch.ID.String()),
 	)
@@ -231,6 +441,9 @@ func (s *SettlementService) processSettlementBatch(batch *model.SettlementBatch)
 func (s *SettlementService) AutoVoidExpiredAuthorizations(ctx context.Context) error {
 	logger.Log.Info("Auto-voiding expired authorizations")
 
+	// Partially captured authorizations are closed rather than voided
+	s.closeExpiredPartialCaptures()
+
 	// Find expired authorizations
 	expiredTxns, err := s.txnRepo.FindExpiredAuthorizations()
 	if err != nil {
@@ -246,7 +459,7 @@ func (s *SettlementService) AutoVoidExpiredAuthorizations(ctx context.Context) e
 	voidedCount := 0
 	for _, txn := range expiredTxns {
 		// Mark as voided
-		if err := s.txnRepo.MarkVoided(txn.ID); err != nil {
+		if err := s.txnRepo.MarkVoided(txn.ID, model.VoidReasonExpired); err != nil {
 			logger.Log.Error("Failed to auto-void transaction",
 				zap.Error(err),
 				zap.String("transaction_id", txn.ID.String()),
@@ -279,6 +492,33 @@ func (s *SettlementService) AutoVoidExpiredAuthorizations(ctx context.Context) e
 	return nil
 }
 
+// closeExpiredPartialCaptures releases the uncaptured remainder of expired
+// multi-capture authorizations so what was captured can settle
+func (s *SettlementService) closeExpiredPartialCaptures() {
+	closed, err := s.txnRepo.CloseExpiredPartialCaptures()
+	if err != nil {
+		logger.Log.Error("Failed to close expired partial captures", zap.Error(err))
+		return
+	}
+
+	for _, txn := range closed {
+		s.txnRepo.CreateEvent(&model.TransactionEvent{
+			TransactionID: txn.ID,
+			EventType:     "capture_closed",
+			OldStatus:     model.TransactionStatusPartiallyCaptured,
+			NewStatus:     model.TransactionStatusCaptured,
+			Amount:        txn.Amount - txn.CapturedAmount,
+			Metadata:      sql.NullString{String: `{"reason":"Authorization expired after 7 days"}`, Valid: true},
+		})
+
+		logger.Log.Info("Expired partial capture closed",
+			zap.String("transaction_id", txn.ID.String()),
+			zap.String("merchant_id", txn.MerchantID.String()),
+			zap.Int64("captured_amount", txn.CapturedAmount),
+		)
+	}
+}
+
 // =========================================================================
 // Helper Methods
 // =========================================================================
@@ -293,13 +533,101 @@ func (s *SettlementService) groupTransactionsByMerchant(txns []model.Transaction
 	return grouped
 }
 
-// GetMerchantSettlements retrieves settlement history for a merchant
-func (s *SettlementService) GetMerchantSettlements(merchantID uuid.UUID) ([]model.SettlementBatch, error) {
-	// This would be implemented in the repository
-	return nil, nil
+// GetMerchantSettlements retrieves a merchant's settlement batches dated in [from, to)
+func (s *SettlementService) GetMerchantSettlements(merchantID uuid.UUID, status model.SettlementStatus, from, to time.Time) ([]model.SettlementBatch, error) {
+	return s.settlementRepo.FindByMerchantInRange(merchantID, status, from, to)
+}
+
+// GetMerchantSettlement retrieves a batch owned by the merchant
+func (s *SettlementService) GetMerchantSettlement(batchID, merchantID uuid.UUID) (*model.SettlementBatch, error) {
+	return s.settlementRepo.FindByIDAndMerchant(batchID, merchantID)
 }
 
 // GetSettlementByID retrieves a specific settlement batch
 func (s *SettlementService) GetSettlementByID(batchID uuid.UUID) (*model.SettlementBatch, error) {
 	return s.settlementRepo.FindByID(batchID)
 }
+
+// =========================================================================
+// Guardrail Holds
+// =========================================================================
+
+// GetGuardrails returns the limits in force
+func (s *SettlementService) GetGuardrails() SettlementGuardrails {
+	return s.guardrails
+}
+
+// GetHeldSettlements lists batches waiting for an operator
+func (s *SettlementService) GetHeldSettlements() ([]model.SettlementBatch, error) {
+	return s.settlementRepo.FindHeldBatches()
+}
+
+// ReleaseHeldSettlement approves a held batch. It goes back to pending and
+// pays out on the next run without the guardrails being checked again.
+func (s *SettlementService) ReleaseHeldSettlement(batchID uuid.UUID, releasedBy string) (*model.SettlementBatch, error) {
+	released, err := s.settlementRepo.Release(batchID, releasedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release settlement batch: %w", err)
+	}
+	if !released {
+		return nil, ErrBatchNotHeld
+	}
+	return s.settlementRepo.FindByID(batchID)
+}
+
+// =========================================================================
+// Settlement Timezones
+// =========================================================================
+
+// merchantLocation returns the timezone a merchant's settlement days are
+// cut in
+func (s *SettlementService) merchantLocation(merchantID uuid.UUID) (*time.Location, error) {
+	tz, err := s.settlementRepo.FindTimezone(merchantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultSettlementLocation(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadSettlementLocation(tz.Timezone)
+	if err != nil {
+		return defaultSettlementLocation(), nil
+	}
+	return loc, nil
+}
+
+// GetMerchantTimezone returns the merchant's settlement timezone and
+// whether it was set explicitly rather than taken from SETTLEMENT_TIMEZONE
+func (s *SettlementService) GetMerchantTimezone(merchantID uuid.UUID) (string, bool, error) {
+	tz, err := s.settlementRepo.FindTimezone(merchantID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultSettlementLocation().String(), false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return tz.Timezone, true, nil
+}
+
+// SetMerchantTimezone sets the IANA timezone a merchant's settlement days
+// are cut in. Batches already created keep their dates.
+func (s *SettlementService) SetMerchantTimezone(merchantID uuid.UUID, name string) (*model.MerchantSettlementTimezone, error) {
+	loc, err := loadSettlementLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tz := &model.MerchantSettlementTimezone{
+		MerchantID: merchantID,
+		Timezone:   loc.String(),
+	}
+	if err := s.settlementRepo.UpsertTimezone(tz); err != nil {
+		return nil, fmt.Errorf("failed to save settlement timezone: %w", err)
+	}
+	return tz, nil
+}
+
+// DeleteMerchantTimezone returns a merchant to SETTLEMENT_TIMEZONE
+func (s *SettlementService) DeleteMerchantTimezone(merchantID uuid.UUID) error {
+	return s.settlementRepo.DeleteTimezone(merchantID)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+)
+
+// defaultSettlementTimezone matches the timezone merchant-service gives new
+// merchants
+const defaultSettlementTimezone = "Africa/Casablanca"
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// settlementPeriod is one calendar day in a merchant's timezone. Start and
+// End are in UTC and End is exclusive, so a day is 23 or 25 hours long when
+// the clocks change.
+type settlementPeriod struct {
+	Date     time.Time // midnight UTC on the local calendar date, for date columns
+	Start    time.Time
+	End      time.Time
+	Timezone string
+}
+
+// settlementDayOf returns the local calendar day t falls in
+func settlementDayOf(t time.Time, loc *time.Location) settlementPeriod {
+	local := t.In(loc)
+	y, m, d := local.Date()
+	return settlementPeriod{
+		Date:     time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		Start:    time.Date(y, m, d, 0, 0, 0, 0, loc).UTC(),
+		End:      time.Date(y, m, d+1, 0, 0, 0, 0, loc).UTC(),
+		Timezone: loc.String(),
+	}
+}
+
+// loadSettlementLocation resolves an IANA timezone name. An empty name is
+// rejected rather than silently meaning UTC.
+func loadSettlementLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// defaultSettlementLocation reads SETTLEMENT_TIMEZONE, falling back to the
+// merchant-service default when it is unset or unknown
+func defaultSettlementLocation() *time.Location {
+	name := config.GetEnvWithDefault("SETTLEMENT_TIMEZONE", defaultSettlementTimezone)
+	if loc, err := loadSettlementLocation(name); err == nil {
+		return loc
+	}
+	loc, err := loadSettlementLocation(defaultSettlementTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// settlementEventTime is the moment that places a transaction in a batch:
+// capture for payments, hand-off to the issuer for refunds
+func settlementEventTime(txn *model.Transaction) time.Time {
+	if txn.Type == model.TransactionTypeRefund && txn.SentToIssuerAt.Valid {
+		return txn.SentToIssuerAt.Time
+	}
+	if txn.CapturedAt.Valid {
+		return txn.CapturedAt.Time
+	}
+	return txn.CreatedAt
+}
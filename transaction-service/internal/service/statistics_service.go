@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+)
+
+var ErrInvalidStatisticsRange = errors.New("invalid statistics range")
+
+// Statistics bucket sizes
+const (
+	StatisticsIntervalDay  = "day"
+	StatisticsIntervalWeek = "week"
+)
+
+// maxStatisticsDays bounds a report to about a year of daily buckets
+const maxStatisticsDays = 366
+
+// StatisticsReport covers the local calendar days From to To, both
+// included, in the merchant's settlement timezone. Start and End are the
+// same range in UTC with End excluded.
+type StatisticsReport struct {
+	Timezone string
+	Start    time.Time
+	End      time.Time
+	Totals   *repository.TransactionStatistics
+	Buckets  []repository.TransactionStatisticsBucket // Every day or week, empty ones included
+}
+
+type StatisticsService struct {
+	txnRepo     *repository.TransactionRepository
+	settlements *SettlementService
+}
+
+// NewStatisticsService cuts days in the timezones settlements uses
+func NewStatisticsService(settlements *SettlementService) *StatisticsService {
+	return &StatisticsService{
+		txnRepo:     repository.NewTransactionRepository(),
+		settlements: settlements,
+	}
+}
+
+// GetStatistics aggregates the merchant's payments made in the given mode
+// on the local days from to to (midnight UTC dates), with a bucket per day
+// or week when interval is set. Weekly buckets start on Monday, so the
+// first and last can cover part of the range only.
+func (s *StatisticsService) GetStatistics(merchantID uuid.UUID, testMode bool, from, to time.Time, interval string) (*StatisticsReport, error) {
+	switch interval {
+	case "", StatisticsIntervalDay, StatisticsIntervalWeek:
+	default:
+		return nil, ErrInvalidStatisticsRange
+	}
+	if to.Before(from) || to.Sub(from) >= maxStatisticsDays*24*time.Hour {
+		return nil, ErrInvalidStatisticsRange
+	}
+
+	loc, err := s.settlements.merchantLocation(merchantID)
+	if err != nil {
+		return nil, err
+	}
+	report := &StatisticsReport{
+		Timezone: loc.String(),
+		Start:    time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc).UTC(),
+		End:      time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc).UTC(),
+	}
+
+	if report.Totals, err = s.txnRepo.GetStatistics(merchantID, testMode, report.Start, report.End); err != nil {
+		return nil, err
+	}
+	if interval == "" {
+		return report, nil
+	}
+
+	buckets, err := s.txnRepo.GetStatisticsBuckets(merchantID, testMode, report.Start, report.End, interval, report.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	report.Buckets = fillStatisticsBuckets(buckets, from, to, interval)
+	return report, nil
+}
+
+// fillStatisticsBuckets adds an empty bucket for every day or week from
+// from to to that had no transactions, so charts keep their time axis
+func fillStatisticsBuckets(buckets []repository.TransactionStatisticsBucket, from, to time.Time, interval string) []repository.TransactionStatisticsBucket {
+	step := 1
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == StatisticsIntervalWeek {
+		step = 7
+		date = date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+	}
+
+	filled := make([]repository.TransactionStatisticsBucket, 0, len(buckets))
+	i := 0
+	for ; !date.After(to); date = date.AddDate(0, 0, step) {
+		if i < len(buckets) && buckets[i].Date.Equal(date) {
+			filled = append(filled, buckets[i])
+			i++
+			continue
+		}
+		filled = append(filled, repository.TransactionStatisticsBucket{Date: date})
+	}
+	return filled
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
+)
+
+const (
+	MaxTransactionNoteLength = 4000
+	MaxTransactionTagLength  = 40
+	MaxTransactionTags       = 20
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrNoteNotFound        = errors.New("note not found")
+)
+
+// Tags are lowercase words joined by - _ or :, e.g. "fraud-review"
+var transactionTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_:-]*$`)
+
+// TransactionNoteService keeps the internal notes and tags a merchant's
+// support team attaches to transactions during investigations
+type TransactionNoteService struct {
+	txnRepo  *repository.TransactionRepository
+	noteRepo *repository.TransactionNoteRepository
+}
+
+func NewTransactionNoteService() *TransactionNoteService {
+	return &TransactionNoteService{
+		txnRepo:  repository.NewTransactionRepository(),
+		noteRepo: repository.NewTransactionNoteRepository(),
+	}
+}
+
+// AddNote attaches a note to one of the merchant's transactions
+func (s *TransactionNoteService) AddNote(txnID, merchantID, authorID uuid.UUID, body string) (*model.TransactionNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.New("note body is required")
+	}
+	if len(body) > MaxTransactionNoteLength {
+		return nil, fmt.Errorf("note body exceeds %d characters", MaxTransactionNoteLength)
+	}
+	if err := s.checkTransaction(txnID, merchantID); err != nil {
+		return nil, err
+	}
+
+	note := &model.TransactionNote{
+		TransactionID: txnID,
+		MerchantID:    merchantID,
+		AuthorID:      authorID,
+		Body:          body,
+	}
+	if err := s.noteRepo.CreateNote(note); err != nil {
+		return nil, fmt.Errorf("failed to save note: %w", err)
+	}
+	return note, nil
+}
+
+// ListNotes returns a transaction's notes, oldest first
+func (s *TransactionNoteService) ListNotes(txnID, merchantID uuid.UUID) ([]model.TransactionNote, error) {
+	if err := s.checkTransaction(txnID, merchantID); err != nil {
+		return nil, err
+	}
+	return s.noteRepo.FindNotes(txnID, merchantID)
+}
+
+// DeleteNote removes a note from a transaction
+func (s *TransactionNoteService) DeleteNote(noteID, txnID, merchantID uuid.UUID) error {
+	deleted, err := s.noteRepo.DeleteNote(noteID, txnID, merchantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	if !deleted {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+// AddTags tags a transaction and returns all of its tags
+func (s *TransactionNoteService) AddTags(txnID, merchantID, createdBy uuid.UUID, tags []string) ([]string, error) {
+	normalized, err := NormalizeTransactionTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, errors.New("at least one tag is required")
+	}
+	if err := s.checkTransaction(txnID, merchantID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.Tags(txnID)
+	if err != nil {
+		return nil, err
+	}
+	if len(mergeTags(current, normalized)) > MaxTransactionTags {
+		return nil, fmt.Errorf("a transaction can have at most %d tags", MaxTransactionTags)
+	}
+
+	rows := make([]model.TransactionTag, len(normalized))
+	for i, tag := range normalized {
+		rows[i] = model.TransactionTag{
+			TransactionID: txnID,
+			MerchantID:    merchantID,
+			Tag:           tag,
+			CreatedBy:     createdBy,
+		}
+	}
+	if err := s.noteRepo.AddTags(rows); err != nil {
+		return nil, fmt.Errorf("failed to save tags: %w", err)
+	}
+	return s.Tags(txnID)
+}
+
+// RemoveTag untags a transaction and returns its remaining tags
+func (s *TransactionNoteService) RemoveTag(txnID, merchantID uuid.UUID, tag string) ([]string, error) {
+	if err := s.checkTransaction(txnID, merchantID); err != nil {
+		return nil, err
+	}
+	if err := s.noteRepo.RemoveTag(txnID, merchantID, strings.ToLower(strings.TrimSpace(tag))); err != nil {
+		return nil, fmt.Errorf("failed to remove tag: %w", err)
+	}
+	return s.Tags(txnID)
+}
+
+// Tags returns a transaction's tags in alphabetical order
+func (s *TransactionNoteService) Tags(txnID uuid.UUID) ([]string, error) {
+	tags, err := s.noteRepo.FindTags([]uuid.UUID{txnID})
+	if err != nil {
+		return nil, err
+	}
+	return tags[txnID], nil
+}
+
+// Annotations returns the tags and note counts of several transactions, for
+// transaction lists
+func (s *TransactionNoteService) Annotations(txnIDs []uuid.UUID) (map[uuid.UUID][]string, map[uuid.UUID]int, error) {
+	tags, err := s.noteRepo.FindTags(txnIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := s.noteRepo.CountNotes(txnIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tags, counts, nil
+}
+
+func (s *TransactionNoteService) checkTransaction(txnID, merchantID uuid.UUID) error {
+	if _, err := s.txnRepo.FindByIDAndMerchant(txnID, merchantID); err != nil {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// NormalizeTransactionTags lowercases and deduplicates tags and rejects
+// malformed ones
+func NormalizeTransactionTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxTransactionTagLength || !transactionTagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: use up to %d lowercase letters, digits, -, _ or :", tag, MaxTransactionTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+func mergeTags(current, added []string) []string {
+	merged := append([]string{}, current...)
+	for _, tag := range added {
+		found := false
+		for _, existing := range current {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
@@ -5,21 +5,31 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rhaloubi/payment-gateway/transaction-service/config"
 	"github.com/rhaloubi/payment-gateway/transaction-service/inits/logger"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/client"
+	"github.com/rhaloubi/payment-gateway/transaction-service/internal/connector"
 	model "github.com/rhaloubi/payment-gateway/transaction-service/internal/models"
 	"github.com/rhaloubi/payment-gateway/transaction-service/internal/repository"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type TransactionService struct {
-	txnRepo             *repository.TransactionRepository
-	currencyService     *CurrencyService
-	tokenizationClient  *client.TokenizationClient
-	cardSimulatorClient *client.CardSimulatorClient
+	txnRepo            *repository.TransactionRepository
+	currencyService    *CurrencyService
+	tokenizationClient *client.TokenizationClient
+	connectorRouting   *ConnectorRoutingService
+	refundTracking     *RefundTrackingService
+	captureSettings    *CaptureSettingsService
+	feeReversal        FeeReversalPolicy
+	refundQueue        *RefundQueue
+	chargebacks        *ChargebackService
+	ledger             *LedgerService
 }
 
 func NewTransactionService() (*TransactionService, error) {
@@ -29,10 +39,16 @@ func NewTransactionService() (*TransactionService, error) {
 	}
 
 	return &TransactionService{
-		txnRepo:             repository.NewTransactionRepository(),
-		currencyService:     NewCurrencyService(),
-		tokenizationClient:  tokenClient,
-		cardSimulatorClient: client.NewCardSimulatorClient(),
+		txnRepo:            repository.NewTransactionRepository(),
+		currencyService:    NewCurrencyService(),
+		tokenizationClient: tokenClient,
+		connectorRouting:   NewConnectorRoutingService(connector.NewDefaultRegistry()),
+		refundTracking:     NewRefundTrackingService(),
+		captureSettings:    NewCaptureSettingsService(),
+		feeReversal:        LoadFeeReversalPolicy(),
+		refundQueue:        NewRefundQueue(),
+		chargebacks:        NewChargebackService(),
+		ledger:             NewLedgerService(),
 	}, nil
 }
 
@@ -48,6 +64,9 @@ type AuthorizeRequest struct {
 	Description   string
 	IPAddress     string
 	UserAgent     string
+	ThreeDSECI    string // Set when the cardholder passed 3-D Secure
+	ThreeDSCAVV   string
+	TestMode      bool // Sandbox authorization; kept out of settlements
 }
 
 type AuthorizeResponse struct {
@@ -63,19 +82,26 @@ type AuthorizeResponse struct {
 	ExchangeRate    float64
 	ProcessingFee   int64
 	NetAmount       int64
+	RetryAllowed    bool          // set on declines from the decline taxonomy
+	RetryAfter      time.Duration // how long to wait before retrying, if allowed
 }
 
 type CaptureRequest struct {
 	TransactionID uuid.UUID
 	Amount        int64
 	MerchantID    uuid.UUID
+	FinalCapture  bool // Multi-capture only: release whatever is left uncaptured
+	Currency      string
 }
 
 type CaptureResponse struct {
-	TransactionID   uuid.UUID
-	Status          model.TransactionStatus
-	CapturedAmount  int64
-	ResponseMessage string
+	TransactionID       uuid.UUID
+	CaptureID           uuid.UUID
+	Status              model.TransactionStatus
+	CapturedAmount      int64 // This capture
+	TotalCapturedAmount int64 // All captures against the authorization
+	RemainingAmount     int64 // Still capturable; 0 once the authorization is closed
+	ResponseMessage     string
 }
 
 type VoidRequest struct {
@@ -94,15 +120,20 @@ type RefundRequest struct {
 	TransactionID uuid.UUID
 	Amount        int64
 	Reason        string
+	ReasonCode    model.RefundReasonCode // Defaults to other
 	MerchantID    uuid.UUID
+	Currency      string
 }
 
 type RefundResponse struct {
-	RefundID        uuid.UUID
-	TransactionID   uuid.UUID
-	RefundedAmount  int64
-	RemainingAmount int64
-	ResponseMessage string
+	RefundID         uuid.UUID
+	TransactionID    uuid.UUID
+	RefundedAmount   int64
+	RemainingAmount  int64
+	FeeReversed      int64 // MAD cents given back to the merchant
+	ResponseMessage  string
+	RefundStatus     model.RefundStatus
+	EstimatedArrival time.Time
 }
 
 // =========================================================================
@@ -140,30 +171,59 @@ func (s *TransactionService) Authorize(ctx context.Context, req *AuthorizeReques
 		return s.createFailedTransaction(req, "Declined by fraud detection", amountMAD, exchangeRate, processingFee)
 	}
 
-	// Step 5: Detokenize card data
-	cardData, err := s.tokenizationClient.Detokenize(ctx, req.CardToken, req.MerchantID.String())
+	// Step 5: Detokenize card data. The ID is assigned now so the token's
+	// usage log points at this transaction.
+	txnID := uuid.New()
+	cardData, err := s.tokenizationClient.Detokenize(ctx, req.CardToken, req.MerchantID.String(), txnID.String(), req.Amount, req.Currency)
 	if err != nil {
 		logger.Log.Error("Detokenization failed", zap.Error(err))
 		return nil, fmt.Errorf("failed to retrieve card data: %w", err)
 	}
 
-	// Step 6: Call Card Simulator (issuer authorization)
-	issuerResp, err := s.cardSimulatorClient.Authorize(ctx, &client.AuthorizeCardRequest{
-		CardNumber: cardData.CardNumber,
-		ExpMonth:   cardData.ExpMonth,
-		ExpYear:    cardData.ExpYear,
-		Amount:     req.Amount,
+	// Step 6: Route to an acquirer connector, failing over on soft failures
+	cardBrand := req.CardBrand
+	if cardBrand == "" {
+		cardBrand = cardData.CardBrand
+	}
+	routingCtx := &RoutingContext{
+		MerchantID: req.MerchantID,
 		Currency:   req.Currency,
-		MerchantID: req.MerchantID.String(),
+		CardBrand:  cardBrand,
+		BINCountry: cardData.BinCountry,
+		AmountMAD:  amountMAD,
+	}
+	plan, err := s.connectorRouting.Plan(routingCtx)
+	if err != nil {
+		return nil, fmt.Errorf("no acquirer connector available: %w", err)
+	}
+
+	routed, err := s.connectorRouting.Authorize(ctx, plan, &client.AuthorizeCardRequest{
+		TransactionID: txnID.String(),
+		CardNumber:    cardData.CardNumber,
+		ExpMonth:      cardData.ExpMonth,
+		ExpYear:       cardData.ExpYear,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		MerchantID:    req.MerchantID.String(),
+		ECI:           req.ThreeDSECI,
+		CAVV:          req.ThreeDSCAVV,
 	})
 	if err != nil {
-		logger.Log.Error("Issuer authorization failed", zap.Error(err))
+		logger.Log.Error("Issuer authorization failed",
+			zap.Int("attempts", len(routed.Attempts)),
+			zap.Error(err),
+		)
 		return nil, fmt.Errorf("issuer authorization failed: %w", err)
 	}
+	issuerResp := routed.Response
+	acquirer := routed.Connector
 
 	// Step 7: Create transaction record
 	txn := &model.Transaction{
+		ID:            txnID,
 		MerchantID:    req.MerchantID,
+		Region:        config.Region(),
+		TestMode:      req.TestMode,
 		Type:          model.TransactionTypeAuthorize,
 		Amount:        req.Amount,
 		Currency:      req.Currency,
@@ -176,6 +236,7 @@ func (s *TransactionService) Authorize(ctx context.Context, req *AuthorizeReques
 		ProcessingFee: processingFee,
 		NetAmount:     netAmount,
 		IPAddress:     req.IPAddress,
+		Connector:     acquirer.Name(),
 	}
 
 	if req.UserAgent != "" {
@@ -207,14 +268,16 @@ func (s *TransactionService) Authorize(ctx context.Context, req *AuthorizeReques
 		txn.ResponseMessage = sql.NullString{String: issuerResp.DeclineReason, Valid: true}
 	}
 
-	// Step 9: Save transaction
-	if err := s.txnRepo.Create(txn); err != nil {
+	// Step 9: Save transaction. The issuer has answered, so the result is
+	// recorded even if the caller has gone away.
+	txnRepo := s.txnRepo.WithContext(context.WithoutCancel(ctx))
+	if err := txnRepo.Create(txn); err != nil {
 		logger.Log.Error("Failed to save transaction", zap.Error(err))
 		return nil, fmt.Errorf("failed to save transaction: %w", err)
 	}
 
 	// Step 10: Log transaction event
-	go s.txnRepo.CreateEvent(&model.TransactionEvent{
+	txnRepo.CreateEvent(&model.TransactionEvent{
 		TransactionID: txn.ID,
 		EventType:     "authorized",
 		OldStatus:     model.TransactionStatusPending,
@@ -222,12 +285,14 @@ func (s *TransactionService) Authorize(ctx context.Context, req *AuthorizeReques
 		Amount:        txn.Amount,
 	})
 
-	// Step 11: Store issuer response for debugging
+	// Step 11: Store issuer response and routing decision for analysis
 	s.storeIssuerResponse(txn.ID, issuerResp, time.Since(startTime))
+	go s.connectorRouting.RecordDecision(txn, routingCtx, plan, routed)
 
 	logger.Log.Info("Authorization completed",
 		zap.String("transaction_id", txn.ID.String()),
 		zap.String("status", string(txn.Status)),
+		zap.String("connector", txn.Connector),
 		zap.Bool("approved", issuerResp.Approved),
 		zap.Duration("processing_time", time.Since(startTime)),
 	)
@@ -251,6 +316,7 @@ func (s *TransactionService) Authorize(ctx context.Context, req *AuthorizeReques
 	} else {
 		response.ResponseCode = issuerResp.ResponseCode
 		response.DeclineReason = issuerResp.DeclineReason
+		response.RetryAllowed, response.RetryAfter = connector.RetryAdvice(issuerResp.ResponseCode)
 	}
 
 	return response, nil
@@ -266,8 +332,11 @@ func (s *TransactionService) Capture(ctx context.Context, req *CaptureRequest) (
 		zap.Int64("amount", req.Amount),
 	)
 
-	// Step 1: Get transaction
-	txn, err := s.txnRepo.FindByIDAndMerchant(req.TransactionID, req.MerchantID)
+	// Step 1: Get transaction. Once the acquirer is contacted, the outcome
+	// is recorded through recorder, which outlives the caller.
+	txnRepo := s.txnRepo.WithContext(ctx)
+	recorder := s.txnRepo.WithContext(context.WithoutCancel(ctx))
+	txn, err := txnRepo.FindByIDAndMerchant(req.TransactionID, req.MerchantID)
 	if err != nil {
 		return nil, fmt.Errorf("transaction not found: %w", err)
 	}
@@ -277,52 +346,162 @@ func (s *TransactionService) Capture(ctx context.Context, req *CaptureRequest) (
 		return nil, errors.New("transaction cannot be captured (not in authorized state or expired)")
 	}
 
-	// Step 3: Validate capture amount
-	if req.Amount > txn.Amount {
-		return nil, errors.New("capture amount exceeds authorized amount")
+	// Step 3: Validate capture amount, which is in the authorization's currency
+	if err := checkRequestCurrency(txn, req.Currency); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, errors.New("capture amount must be greater than 0")
+	}
+	if req.Amount > txn.RemainingCapturableAmount() {
+		return nil, fmt.Errorf("capture amount exceeds remaining authorized amount (%d)",
+			txn.RemainingCapturableAmount())
+	}
+
+	// Step 4: A single-capture merchant's capture closes the authorization.
+	// Partially captured authorizations stay multi-capture even if the
+	// merchant has since turned it off.
+	final := true
+	if txn.Status == model.TransactionStatusPartiallyCaptured {
+		final = req.FinalCapture
+	} else {
+		multiCapture, err := s.captureSettings.MultiCaptureEnabled(req.MerchantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load capture settings: %w", err)
+		}
+		if multiCapture {
+			final = req.FinalCapture
+		}
+	}
+	if req.Amount == txn.RemainingCapturableAmount() {
+		final = true
 	}
 
-	// Step 4: Call card simulator to finalize capture
-	captureResp, err := s.cardSimulatorClient.Capture(ctx, &client.CaptureCardRequest{
-		TransactionID: req.TransactionID.String(),
-		Amount:        req.Amount,
-	})
+	acquirer, err := s.connectorRouting.ForTransaction(txn)
 	if err != nil {
-		logger.Log.Error("Capture failed at issuer", zap.Error(err))
 		return nil, fmt.Errorf("capture failed: %w", err)
 	}
 
-	if !captureResp.Success {
+	// Step 5: Reserve the capture so a concurrent one can't reach the issuer
+	capture := &model.TransactionCapture{
+		TransactionID: req.TransactionID,
+		MerchantID:    req.MerchantID,
+		Amount:        req.Amount,
+		Currency:      txn.Currency,
+		FinalCapture:  final,
+	}
+	reserved, err := txnRepo.ReserveCapture(capture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve capture: %w", err)
+	}
+	if !reserved {
+		return nil, errors.New("capture conflicts with another capture on this transaction")
+	}
+
+	// Step 6: Finalize capture with the acquirer that authorized it
+	captureResp, err := acquirer.Capture(ctx, &client.CaptureCardRequest{
+		TransactionID: req.TransactionID.String(),
+		MerchantID:    req.MerchantID.String(),
+		Amount:        req.Amount,
+		Currency:      txn.Currency,
+		FinalCapture:  final,
+	})
+	if err != nil || !captureResp.Success {
+		if releaseErr := recorder.ReleaseCapture(capture); releaseErr != nil {
+			logger.Log.Error("Failed to release capture reservation",
+				zap.String("transaction_id", req.TransactionID.String()),
+				zap.Error(releaseErr),
+			)
+		}
+		if err != nil {
+			logger.Log.Error("Capture failed at issuer", zap.Error(err))
+			return nil, fmt.Errorf("capture failed: %w", err)
+		}
 		return nil, errors.New("capture declined by issuer")
 	}
 
-	// Step 5: Update transaction
-	if err := s.txnRepo.MarkCaptured(req.TransactionID, req.Amount); err != nil {
-		return nil, err
+	// Step 7: Record the capture, update the authorization and post it to
+	// the ledger
+	completed, err := recorder.CompleteCapture(capture, func(tx *gorm.DB) error {
+		return s.ledger.postCapture(tx, txn, capture)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record capture: %w", err)
+	}
+	if !completed {
+		logger.Log.Error("Capture accepted by issuer but its reservation was lost",
+			zap.String("transaction_id", req.TransactionID.String()),
+			zap.String("capture_id", capture.ID.String()),
+			zap.Int64("amount", req.Amount),
+		)
+		return nil, errors.New("capture conflicts with another capture on this transaction")
+	}
+
+	status := model.TransactionStatusPartiallyCaptured
+	if final {
+		status = model.TransactionStatusCaptured
+	}
+	totalCaptured := txn.CapturedAmount + req.Amount
+	remaining := txn.Amount - totalCaptured
+	if final {
+		remaining = 0
 	}
 
-	// Step 6: Log event
-	go s.txnRepo.CreateEvent(&model.TransactionEvent{
+	// Step 8: Log event
+	recorder.CreateEvent(&model.TransactionEvent{
 		TransactionID: req.TransactionID,
 		EventType:     "captured",
-		OldStatus:     model.TransactionStatusAuthorized,
-		NewStatus:     model.TransactionStatusCaptured,
+		OldStatus:     txn.Status,
+		NewStatus:     status,
 		Amount:        req.Amount,
+		Metadata: sql.NullString{
+			String: fmt.Sprintf(`{"capture_id":%q,"sequence":%d,"final":%t}`, capture.ID, capture.Sequence, final),
+			Valid:  true,
+		},
 	})
 
 	logger.Log.Info("Capture completed",
 		zap.String("transaction_id", req.TransactionID.String()),
+		zap.String("capture_id", capture.ID.String()),
 		zap.Int64("amount", req.Amount),
+		zap.Int64("total_captured", totalCaptured),
+		zap.Bool("final", final),
 	)
 
+	if final {
+		s.openSandboxDispute(context.WithoutCancel(ctx), txn, totalCaptured)
+	}
+
+	message := "Capture successful"
+	if !final {
+		message = "Partial capture successful"
+	}
 	return &CaptureResponse{
-		TransactionID:   req.TransactionID,
-		Status:          model.TransactionStatusCaptured,
-		CapturedAmount:  req.Amount,
-		ResponseMessage: "Capture successful",
+		TransactionID:       req.TransactionID,
+		CaptureID:           capture.ID,
+		Status:              status,
+		CapturedAmount:      req.Amount,
+		TotalCapturedAmount: totalCaptured,
+		RemainingAmount:     remaining,
+		ResponseMessage:     message,
 	}, nil
 }
 
+// GetCaptures returns a merchant's authorization with the captures taken
+// against it
+func (s *TransactionService) GetCaptures(ctx context.Context, txnID, merchantID uuid.UUID) (*model.Transaction, []model.TransactionCapture, error) {
+	txnRepo := s.txnRepo.WithContext(ctx)
+	txn, err := txnRepo.FindByIDAndMerchant(txnID, merchantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	captures, err := txnRepo.FindCaptures(txnID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load captures: %w", err)
+	}
+	return txn, captures, nil
+}
+
 // =========================================================================
 // VOID - Cancel authorization before capture
 // =========================================================================
@@ -333,7 +512,7 @@ func (s *TransactionService) Void(ctx context.Context, req *VoidRequest) (*VoidR
 	)
 
 	// Step 1: Get transaction
-	txn, err := s.txnRepo.FindByIDAndMerchant(req.TransactionID, req.MerchantID)
+	txn, err := s.txnRepo.WithContext(ctx).FindByIDAndMerchant(req.TransactionID, req.MerchantID)
 	if err != nil {
 		return nil, fmt.Errorf("transaction not found: %w", err)
 	}
@@ -343,9 +522,15 @@ func (s *TransactionService) Void(ctx context.Context, req *VoidRequest) (*VoidR
 		return nil, errors.New("transaction cannot be voided (not in authorized state)")
 	}
 
-	// Step 3: Call card simulator to void
-	voidResp, err := s.cardSimulatorClient.Void(ctx, &client.VoidCardRequest{
+	// Step 3: Void with the acquirer that authorized it
+	acquirer, err := s.connectorRouting.ForTransaction(txn)
+	if err != nil {
+		return nil, fmt.Errorf("void failed: %w", err)
+	}
+
+	voidResp, err := acquirer.Void(ctx, &client.VoidCardRequest{
 		TransactionID: req.TransactionID.String(),
+		MerchantID:    req.MerchantID.String(),
 		Reason:        req.Reason,
 	})
 	if err != nil {
@@ -357,13 +542,14 @@ func (s *TransactionService) Void(ctx context.Context, req *VoidRequest) (*VoidR
 		return nil, errors.New("void declined by issuer")
 	}
 
-	// Step 4: Update transaction
-	if err := s.txnRepo.MarkVoided(req.TransactionID); err != nil {
+	// Step 4: Update transaction, even if the caller has gone away
+	recorder := s.txnRepo.WithContext(context.WithoutCancel(ctx))
+	if err := recorder.MarkVoided(req.TransactionID, model.VoidReasonRequested); err != nil {
 		return nil, err
 	}
 
 	// Step 5: Log event
-	go s.txnRepo.CreateEvent(&model.TransactionEvent{
+	recorder.CreateEvent(&model.TransactionEvent{
 		TransactionID: req.TransactionID,
 		EventType:     "voided",
 		OldStatus:     model.TransactionStatusAuthorized,
@@ -394,7 +580,8 @@ func (s *TransactionService) Refund(ctx context.Context, req *RefundRequest) (*R
 	)
 
 	// Step 1: Get original transaction
-	originalTxn, err := s.txnRepo.FindByIDAndMerchant(req.TransactionID, req.MerchantID)
+	txnRepo := s.txnRepo.WithContext(ctx)
+	originalTxn, err := txnRepo.FindByIDAndMerchant(req.TransactionID, req.MerchantID)
 	if err != nil {
 		return nil, fmt.Errorf("transaction not found: %w", err)
 	}
@@ -404,87 +591,221 @@ func (s *TransactionService) Refund(ctx context.Context, req *RefundRequest) (*R
 		return nil, errors.New("transaction cannot be refunded")
 	}
 
-	// Step 3: Validate refund amount
-	if req.Amount > originalTxn.RemainingRefundableAmount() {
-		return nil, fmt.Errorf("refund amount exceeds remaining refundable amount (%d)",
-			originalTxn.RemainingRefundableAmount())
+	// Step 3: Validate refund amount; queued refunds have not been added to
+	// the refunded amount yet but are already spoken for
+	if err := checkRequestCurrency(originalTxn, req.Currency); err != nil {
+		return nil, err
+	}
+	queuedAmount, err := s.refundQueue.QueuedAmount(req.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queued refunds: %w", err)
+	}
+	remaining := originalTxn.RemainingRefundableAmount() - queuedAmount
+	if req.Amount > remaining {
+		return nil, fmt.Errorf("refund amount exceeds remaining refundable amount (%d)", remaining)
 	}
 
-	// Step 4: Call card simulator to process refund
-	refundResp, err := s.cardSimulatorClient.Refund(ctx, &client.RefundCardRequest{
-		TransactionID: req.TransactionID.String(),
-		Amount:        req.Amount,
-		Reason:        req.Reason,
-	})
+	// Step 4: Work out the MAD amount and fee reversal, then record the
+	// refund before contacting the acquirer. It is queued when the merchant
+	// already has refunds waiting or no free refund slot.
+	refundedMAD, reversedFee, err := txnRepo.SumRefundsByParent(req.TransactionID)
 	if err != nil {
-		logger.Log.Error("Refund failed at issuer", zap.Error(err))
-		return nil, fmt.Errorf("refund failed: %w", err)
+		return nil, fmt.Errorf("failed to load previous refunds: %w", err)
+	}
+	amounts, err := calculateRefundAmounts(originalTxn, req.Amount, remaining, refundedMAD, reversedFee, s.feeReversal)
+	if err != nil {
+		return nil, fmt.Errorf("transaction cannot be refunded: %w", err)
 	}
 
-	if !refundResp.Success {
-		return nil, errors.New("refund declined by issuer")
+	lease, sendNow, err := s.refundQueue.admit(req.MerchantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check refund queue: %w", err)
+	}
+	defer s.refundQueue.release(req.MerchantID, lease)
+
+	refundStatus := model.RefundStatusRequested
+	if !sendNow {
+		refundStatus = model.RefundStatusQueued
 	}
 
-	// Step 5: Create refund transaction record
 	refundTxn := &model.Transaction{
 		MerchantID:          req.MerchantID,
+		Region:              originalTxn.Region,
+		TestMode:            originalTxn.TestMode,
 		ParentTransactionID: sql.NullString{String: req.TransactionID.String(), Valid: true},
 		Type:                model.TransactionTypeRefund,
 		Status:              model.TransactionStatusRefunded,
+		RefundStatus:        refundStatus,
 		Amount:              -req.Amount, // Negative amount for refund
 		Currency:            originalTxn.Currency,
-		AmountMAD:           -originalTxn.AmountMAD * req.Amount / originalTxn.CapturedAmount,
+		AmountMAD:           -amounts.AmountMAD,
 		ExchangeRate:        originalTxn.ExchangeRate,
+		ProcessingFee:       -amounts.FeeReversed, // Negative: the fee goes back to the merchant
+		NetAmount:           -amounts.AmountMAD + amounts.FeeReversed,
 		CardToken:           originalTxn.CardToken,
 		CardBrand:           originalTxn.CardBrand,
 		CardLast4:           originalTxn.CardLast4,
+		Connector:           originalTxn.Connector,
 		Description:         sql.NullString{String: req.Reason, Valid: true},
+		RefundReasonCode:    req.ReasonCode,
+	}
+	if refundTxn.RefundReasonCode == "" {
+		refundTxn.RefundReasonCode = model.RefundReasonOther
 	}
 
 	now := time.Now()
 	refundTxn.RefundedAt = sql.NullTime{Time: now, Valid: true}
 
-	// Step 6: Save refund transaction
-	if err := s.txnRepo.Create(refundTxn); err != nil {
+	if err := txnRepo.Create(refundTxn); err != nil {
 		return nil, fmt.Errorf("failed to save refund transaction: %w", err)
 	}
 
-	// Step 7: Update original transaction refunded amount
-	if err := s.txnRepo.AddRefundAmount(req.TransactionID, req.Amount); err != nil {
+	if !sendNow {
+		logger.Log.Info("Refund queued",
+			zap.String("refund_id", refundTxn.ID.String()),
+			zap.String("transaction_id", req.TransactionID.String()),
+			zap.String("merchant_id", req.MerchantID.String()),
+			zap.Int64("amount", req.Amount),
+		)
+
+		return &RefundResponse{
+			RefundID:        refundTxn.ID,
+			TransactionID:   req.TransactionID,
+			RefundedAmount:  req.Amount,
+			RemainingAmount: remaining - req.Amount,
+			FeeReversed:     amounts.FeeReversed,
+			ResponseMessage: "Refund queued",
+			RefundStatus:    refundTxn.RefundStatus,
+		}, nil
+	}
+
+	// Steps 5-8: Send it to the acquirer
+	estimatedArrival, err := s.sendRefund(ctx, originalTxn, refundTxn)
+	if err != nil {
 		return nil, err
 	}
 
+	// Refresh original transaction to get updated amounts
+	originalTxn, _ = s.txnRepo.WithContext(context.WithoutCancel(ctx)).FindByID(req.TransactionID)
+
+	return &RefundResponse{
+		RefundID:         refundTxn.ID,
+		TransactionID:    req.TransactionID,
+		RefundedAmount:   req.Amount,
+		RemainingAmount:  originalTxn.RemainingRefundableAmount(),
+		FeeReversed:      amounts.FeeReversed,
+		ResponseMessage:  "Refund processed successfully",
+		RefundStatus:     refundTxn.RefundStatus,
+		EstimatedArrival: estimatedArrival,
+	}, nil
+}
+
+// sendRefund refunds a stored refund transaction through the acquirer that
+// authorized the original, then records it against the original
+func (s *TransactionService) sendRefund(ctx context.Context, originalTxn, refundTxn *model.Transaction) (time.Time, error) {
+	amount := -refundTxn.Amount
+	feeReversed := -refundTxn.ProcessingFee
+
+	// Step 5: Refund through the acquirer that authorized it
+	acquirer, err := s.connectorRouting.ForTransaction(originalTxn)
+	if err != nil {
+		s.refundTracking.MarkFailed(refundTxn, err.Error())
+		return time.Time{}, fmt.Errorf("refund failed: %w", err)
+	}
+
+	refundResp, err := acquirer.Refund(ctx, &client.RefundCardRequest{
+		TransactionID:       originalTxn.ID.String(),
+		RefundTransactionID: refundTxn.ID.String(),
+		MerchantID:          refundTxn.MerchantID.String(),
+		Amount:              amount,
+		Currency:            refundTxn.Currency,
+		Reason:              refundTxn.Description.String,
+	})
+	if err != nil {
+		logger.Log.Error("Refund failed at issuer", zap.Error(err))
+		s.refundTracking.MarkFailed(refundTxn, err.Error())
+		return time.Time{}, fmt.Errorf("refund failed: %w", err)
+	}
+
+	if !refundResp.Success {
+		s.refundTracking.MarkFailed(refundTxn, refundResp.ResponseMessage)
+		return time.Time{}, errors.New("refund declined by issuer")
+	}
+
+	// Step 6: The acquirer accepted it; start tracking arrival
+	estimatedArrival, err := s.refundTracking.MarkSent(refundTxn, time.Now())
+	if err != nil {
+		logger.Log.Error("Failed to update refund status",
+			zap.String("refund_id", refundTxn.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	// Step 7: Update original transaction refunded amount and post the
+	// refund to the ledger, even if the caller has gone away
+	recorder := s.txnRepo.WithContext(context.WithoutCancel(ctx))
+	if err := recorder.AddRefundAmount(originalTxn.ID, amount, func(tx *gorm.DB) error {
+		return s.ledger.postRefund(tx, refundTxn)
+	}); err != nil {
+		return time.Time{}, err
+	}
+
 	// Step 8: Log event
-	go s.txnRepo.CreateEvent(&model.TransactionEvent{
-		TransactionID: req.TransactionID,
+	recorder.CreateEvent(&model.TransactionEvent{
+		TransactionID: originalTxn.ID,
 		EventType:     "refunded",
 		OldStatus:     originalTxn.Status,
 		NewStatus:     model.TransactionStatusRefunded,
-		Amount:        req.Amount,
+		Amount:        amount,
 	})
+	if feeReversed > 0 {
+		recorder.CreateEvent(&model.TransactionEvent{
+			TransactionID: originalTxn.ID,
+			EventType:     "fee_reversed",
+			OldStatus:     originalTxn.Status,
+			NewStatus:     model.TransactionStatusRefunded,
+			Amount:        feeReversed,
+			Metadata: sql.NullString{
+				String: fmt.Sprintf(`{"refund_id":%q,"policy":%q}`, refundTxn.ID, s.feeReversal),
+				Valid:  true,
+			},
+		})
+	}
 
 	logger.Log.Info("Refund completed",
 		zap.String("refund_id", refundTxn.ID.String()),
-		zap.String("transaction_id", req.TransactionID.String()),
-		zap.Int64("amount", req.Amount),
+		zap.String("transaction_id", originalTxn.ID.String()),
+		zap.Int64("amount", amount),
+		zap.Int64("amount_mad", -refundTxn.AmountMAD),
+		zap.Int64("fee_reversed", feeReversed),
 	)
 
-	// Refresh original transaction to get updated amounts
-	originalTxn, _ = s.txnRepo.FindByID(req.TransactionID)
-
-	return &RefundResponse{
-		RefundID:        refundTxn.ID,
-		TransactionID:   req.TransactionID,
-		RefundedAmount:  req.Amount,
-		RemainingAmount: originalTxn.RemainingRefundableAmount(),
-		ResponseMessage: "Refund processed successfully",
-	}, nil
+	return estimatedArrival, nil
 }
 
 // =========================================================================
 // Helper Methods
 // =========================================================================
 
+// Capture and refund amounts are in the original transaction's currency;
+// requests must name it so a unit mistake is rejected instead of moving the
+// wrong amount
+var (
+	ErrCurrencyRequired = errors.New("currency is required")
+	ErrCurrencyMismatch = errors.New("currency does not match the original transaction")
+)
+
+func checkRequestCurrency(txn *model.Transaction, currency string) error {
+	currency = strings.TrimSpace(currency)
+	if currency == "" {
+		return ErrCurrencyRequired
+	}
+	if !strings.EqualFold(currency, txn.Currency) {
+		return fmt.Errorf("%w: transaction is in %s, request is in %s", ErrCurrencyMismatch, txn.Currency, currency)
+	}
+	return nil
+}
+
 func (s *TransactionService) validateAuthorizationRequest(req *AuthorizeRequest) error {
 	if req.Amount <= 0 {
 		return errors.New("amount must be greater than 0")
@@ -511,6 +832,8 @@ func (s *TransactionService) validateAuthorizationRequest(req *AuthorizeRequest)
 func (s *TransactionService) createFailedTransaction(req *AuthorizeRequest, reason string, amountMAD int64, exchangeRate float64, processingFee int64) (*AuthorizeResponse, error) {
 	txn := &model.Transaction{
 		MerchantID:      req.MerchantID,
+		Region:          config.Region(),
+		TestMode:        req.TestMode,
 		Type:            model.TransactionTypeAuthorize,
 		Status:          model.TransactionStatusFailed,
 		Amount:          req.Amount,
@@ -553,14 +876,52 @@ func (s *TransactionService) storeIssuerResponse(txnID uuid.UUID, resp *client.A
 	})
 }
 
-func (s *TransactionService) GetTransaction(txnID, merchantID uuid.UUID) (*model.Transaction, error) {
-	return s.txnRepo.FindByIDAndMerchant(txnID, merchantID)
+func (s *TransactionService) GetTransaction(ctx context.Context, txnID, merchantID uuid.UUID) (*model.Transaction, error) {
+	return s.txnRepo.WithContext(ctx).FindByIDAndMerchant(txnID, merchantID)
 }
 
-func (s *TransactionService) FindByStatus(merchantID uuid.UUID, status model.TransactionStatus) ([]model.Transaction, error) {
-	return s.txnRepo.FindByStatus(merchantID, status)
+// TransactionTimeline is everything recorded about one transaction
+type TransactionTimeline struct {
+	Transaction     *model.Transaction
+	Events          []model.TransactionEvent
+	IssuerResponses []model.IssuerResponse
+	RoutingDecision *model.RoutingDecision // nil when the transaction was not routed
+}
+
+// GetTransactionTimeline gathers a merchant's transaction with its events,
+// issuer responses and routing decision
+func (s *TransactionService) GetTransactionTimeline(ctx context.Context, txnID, merchantID uuid.UUID) (*TransactionTimeline, error) {
+	txnRepo := s.txnRepo.WithContext(ctx)
+	txn, err := txnRepo.FindByIDAndMerchant(txnID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := txnRepo.GetTransactionEvents(txnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	responses, err := txnRepo.GetIssuerResponses(txnID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issuer responses: %w", err)
+	}
+
+	timeline := &TransactionTimeline{
+		Transaction:     txn,
+		Events:          events,
+		IssuerResponses: responses,
+	}
+	decision, err := s.connectorRouting.GetDecision(txnID)
+	if err == nil {
+		timeline.RoutingDecision = decision
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load routing decision: %w", err)
+	}
+	return timeline, nil
 }
 
-func (s *TransactionService) FindByMerchant(merchantID uuid.UUID, limit, offset int) ([]model.Transaction, error) {
-	return s.txnRepo.FindByMerchant(merchantID, limit, offset)
+// ListTransactions returns a page of a merchant's transactions and the total
+// number matching the filter
+func (s *TransactionService) ListTransactions(ctx context.Context, filter repository.TransactionListFilter) ([]model.Transaction, int64, error) {
+	return s.txnRepo.WithContext(ctx).ListByMerchant(filter)
 }
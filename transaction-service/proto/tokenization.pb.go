@@ -406,6 +406,7 @@ type DetokenizeResponse struct {
 	CardBrand      string                 `protobuf:"bytes,5,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
 	Last4          string                 `protobuf:"bytes,6,opt,name=last4,proto3" json:"last4,omitempty"`
 	Error          string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	BinCountry     string                 `protobuf:"bytes,8,opt,name=bin_country,json=binCountry,proto3" json:"bin_country,omitempty"` // ISO 3166-1 alpha-2 of the issuing bank, empty if unknown
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -489,6 +490,13 @@ func (x *DetokenizeResponse) GetError() string {
 	return ""
 }
 
+func (x *DetokenizeResponse) GetBinCountry() string {
+	if x != nil {
+		return x.BinCountry
+	}
+	return ""
+}
+
 type ValidateTokenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
@@ -802,7 +810,7 @@ const file_proto_tokenization_proto_rawDesc = "" +
 	"\n" +
 	"ip_address\x18\a \x01(\tR\tipAddress\x12\x1d\n" +
 	"\n" +
-	"user_agent\x18\b \x01(\tR\tuserAgent\"\xe1\x01\n" +
+	"user_agent\x18\b \x01(\tR\tuserAgent\"\x82\x02\n" +
 	"\x12DetokenizeResponse\x12\x1f\n" +
 	"\vcard_number\x18\x01 \x01(\tR\n" +
 	"cardNumber\x12'\n" +
@@ -812,7 +820,9 @@ const file_proto_tokenization_proto_rawDesc = "" +
 	"\n" +
 	"card_brand\x18\x05 \x01(\tR\tcardBrand\x12\x14\n" +
 	"\x05last4\x18\x06 \x01(\tR\x05last4\x12\x14\n" +
-	"\x05error\x18\a \x01(\tR\x05error\"M\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1f\n" +
+	"\vbin_country\x18\b \x01(\tR\n" +
+	"binCountry\"M\n" +
 	"\x14ValidateTokenRequest\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1f\n" +
 	"\vmerchant_id\x18\x02 \x01(\tR\n" +
@@ -35,6 +35,9 @@ type AuthorizeRequest struct {
 	Description   string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
 	IpAddress     string                 `protobuf:"bytes,10,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
 	UserAgent     string                 `protobuf:"bytes,11,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	ThreeDsEci    string                 `protobuf:"bytes,12,opt,name=three_ds_eci,json=threeDsEci,proto3" json:"three_ds_eci,omitempty"` // Set when the cardholder was authenticated with 3-D Secure
+	ThreeDsCavv   string                 `protobuf:"bytes,13,opt,name=three_ds_cavv,json=threeDsCavv,proto3" json:"three_ds_cavv,omitempty"`
+	TestMode      bool                   `protobuf:"varint,14,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"` // Made with a sandbox key; never settled or paid out
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -146,23 +149,46 @@ func (x *AuthorizeRequest) GetUserAgent() string {
 	return ""
 }
 
+func (x *AuthorizeRequest) GetThreeDsEci() string {
+	if x != nil {
+		return x.ThreeDsEci
+	}
+	return ""
+}
+
+func (x *AuthorizeRequest) GetThreeDsCavv() string {
+	if x != nil {
+		return x.ThreeDsCavv
+	}
+	return ""
+}
+
+func (x *AuthorizeRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
 type AuthorizeResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Approved        bool                   `protobuf:"varint,3,opt,name=approved,proto3" json:"approved,omitempty"`
-	AuthCode        string                 `protobuf:"bytes,4,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`
-	ResponseCode    string                 `protobuf:"bytes,5,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
-	ResponseMessage string                 `protobuf:"bytes,6,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
-	DeclineReason   string                 `protobuf:"bytes,7,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`
-	Amount          int64                  `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
-	AmountMad       int64                  `protobuf:"varint,9,opt,name=amount_mad,json=amountMad,proto3" json:"amount_mad,omitempty"`
-	ExchangeRate    float64                `protobuf:"fixed64,10,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
-	ProcessingFee   int64                  `protobuf:"varint,11,opt,name=processing_fee,json=processingFee,proto3" json:"processing_fee,omitempty"`
-	NetAmount       int64                  `protobuf:"varint,12,opt,name=net_amount,json=netAmount,proto3" json:"net_amount,omitempty"`
-	Error           string                 `protobuf:"bytes,13,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state                      protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId              string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Status                     string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Approved                   bool                   `protobuf:"varint,3,opt,name=approved,proto3" json:"approved,omitempty"`
+	AuthCode                   string                 `protobuf:"bytes,4,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`
+	ResponseCode               string                 `protobuf:"bytes,5,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	ResponseMessage            string                 `protobuf:"bytes,6,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	DeclineReason              string                 `protobuf:"bytes,7,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`
+	Amount                     int64                  `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
+	AmountMad                  int64                  `protobuf:"varint,9,opt,name=amount_mad,json=amountMad,proto3" json:"amount_mad,omitempty"`
+	ExchangeRate               float64                `protobuf:"fixed64,10,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	ProcessingFee              int64                  `protobuf:"varint,11,opt,name=processing_fee,json=processingFee,proto3" json:"processing_fee,omitempty"`
+	NetAmount                  int64                  `protobuf:"varint,12,opt,name=net_amount,json=netAmount,proto3" json:"net_amount,omitempty"`
+	Error                      string                 `protobuf:"bytes,13,opt,name=error,proto3" json:"error,omitempty"`
+	RetryAllowed               bool                   `protobuf:"varint,14,opt,name=retry_allowed,json=retryAllowed,proto3" json:"retry_allowed,omitempty"` // Declines only: may the same payment be retried
+	SuggestedRetryAfterSeconds int64                  `protobuf:"varint,15,opt,name=suggested_retry_after_seconds,json=suggestedRetryAfterSeconds,proto3" json:"suggested_retry_after_seconds,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
 }
 
 func (x *AuthorizeResponse) Reset() {
@@ -286,11 +312,27 @@ func (x *AuthorizeResponse) GetError() string {
 	return ""
 }
 
+func (x *AuthorizeResponse) GetRetryAllowed() bool {
+	if x != nil {
+		return x.RetryAllowed
+	}
+	return false
+}
+
+func (x *AuthorizeResponse) GetSuggestedRetryAfterSeconds() int64 {
+	if x != nil {
+		return x.SuggestedRetryAfterSeconds
+	}
+	return 0
+}
+
 type CaptureRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
 	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // Can be partial
 	MerchantId    string                 `protobuf:"bytes,3,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	FinalCapture  bool                   `protobuf:"varint,4,opt,name=final_capture,json=finalCapture,proto3" json:"final_capture,omitempty"` // Multi-capture merchants: release the uncaptured remainder
+	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`                              // Required; must match the authorization
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -346,15 +388,33 @@ func (x *CaptureRequest) GetMerchantId() string {
 	return ""
 }
 
+func (x *CaptureRequest) GetFinalCapture() bool {
+	if x != nil {
+		return x.FinalCapture
+	}
+	return false
+}
+
+func (x *CaptureRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 type CaptureResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // captured
-	CapturedAmount  int64                  `protobuf:"varint,3,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
-	ResponseMessage string                 `protobuf:"bytes,4,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
-	Error           string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId       string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Status              string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                                        // captured, partially_captured
+	CapturedAmount      int64                  `protobuf:"varint,3,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"` // This capture
+	ResponseMessage     string                 `protobuf:"bytes,4,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	Error               string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	CaptureId           string                 `protobuf:"bytes,6,opt,name=capture_id,json=captureId,proto3" json:"capture_id,omitempty"`
+	TotalCapturedAmount int64                  `protobuf:"varint,7,opt,name=total_captured_amount,json=totalCapturedAmount,proto3" json:"total_captured_amount,omitempty"`
+	RemainingAmount     int64                  `protobuf:"varint,8,opt,name=remaining_amount,json=remainingAmount,proto3" json:"remaining_amount,omitempty"` // Still capturable; 0 once the authorization is closed
+	ErrorCode           string                 `protobuf:"bytes,9,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`                    // currency_required, currency_mismatch; empty for other errors
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *CaptureResponse) Reset() {
@@ -422,29 +482,56 @@ func (x *CaptureResponse) GetError() string {
 	return ""
 }
 
-type VoidRequest struct {
+func (x *CaptureResponse) GetCaptureId() string {
+	if x != nil {
+		return x.CaptureId
+	}
+	return ""
+}
+
+func (x *CaptureResponse) GetTotalCapturedAmount() int64 {
+	if x != nil {
+		return x.TotalCapturedAmount
+	}
+	return 0
+}
+
+func (x *CaptureResponse) GetRemainingAmount() int64 {
+	if x != nil {
+		return x.RemainingAmount
+	}
+	return 0
+}
+
+func (x *CaptureResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+type ListCapturesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
 	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VoidRequest) Reset() {
-	*x = VoidRequest{}
+func (x *ListCapturesRequest) Reset() {
+	*x = ListCapturesRequest{}
 	mi := &file_proto_transaction_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VoidRequest) String() string {
+func (x *ListCapturesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VoidRequest) ProtoMessage() {}
+func (*ListCapturesRequest) ProtoMessage() {}
 
-func (x *VoidRequest) ProtoReflect() protoreflect.Message {
+func (x *ListCapturesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_transaction_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -456,56 +543,51 @@ func (x *VoidRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VoidRequest.ProtoReflect.Descriptor instead.
-func (*VoidRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListCapturesRequest.ProtoReflect.Descriptor instead.
+func (*ListCapturesRequest) Descriptor() ([]byte, []int) {
 	return file_proto_transaction_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *VoidRequest) GetTransactionId() string {
+func (x *ListCapturesRequest) GetTransactionId() string {
 	if x != nil {
 		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *VoidRequest) GetMerchantId() string {
+func (x *ListCapturesRequest) GetMerchantId() string {
 	if x != nil {
 		return x.MerchantId
 	}
 	return ""
 }
 
-func (x *VoidRequest) GetReason() string {
-	if x != nil {
-		return x.Reason
-	}
-	return ""
-}
-
-type VoidResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	ResponseMessage string                 `protobuf:"bytes,3,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
-	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type CaptureRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sequence      int32                  `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Amount        int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	FinalCapture  bool                   `protobuf:"varint,5,opt,name=final_capture,json=finalCapture,proto3" json:"final_capture,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *VoidResponse) Reset() {
-	*x = VoidResponse{}
+func (x *CaptureRecord) Reset() {
+	*x = CaptureRecord{}
 	mi := &file_proto_transaction_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *VoidResponse) String() string {
+func (x *CaptureRecord) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VoidResponse) ProtoMessage() {}
+func (*CaptureRecord) ProtoMessage() {}
 
-func (x *VoidResponse) ProtoReflect() protoreflect.Message {
+func (x *CaptureRecord) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_transaction_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -517,63 +599,78 @@ func (x *VoidResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VoidResponse.ProtoReflect.Descriptor instead.
-func (*VoidResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use CaptureRecord.ProtoReflect.Descriptor instead.
+func (*CaptureRecord) Descriptor() ([]byte, []int) {
 	return file_proto_transaction_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *VoidResponse) GetTransactionId() string {
+func (x *CaptureRecord) GetId() string {
 	if x != nil {
-		return x.TransactionId
+		return x.Id
 	}
 	return ""
 }
 
-func (x *VoidResponse) GetStatus() string {
+func (x *CaptureRecord) GetSequence() int32 {
 	if x != nil {
-		return x.Status
+		return x.Sequence
 	}
-	return ""
+	return 0
 }
 
-func (x *VoidResponse) GetResponseMessage() string {
+func (x *CaptureRecord) GetAmount() int64 {
 	if x != nil {
-		return x.ResponseMessage
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CaptureRecord) GetCurrency() string {
+	if x != nil {
+		return x.Currency
 	}
 	return ""
 }
 
-func (x *VoidResponse) GetError() string {
+func (x *CaptureRecord) GetFinalCapture() bool {
 	if x != nil {
-		return x.Error
+		return x.FinalCapture
+	}
+	return false
+}
+
+func (x *CaptureRecord) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return ""
 }
 
-type RefundRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // Can be partial
-	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
-	MerchantId    string                 `protobuf:"bytes,4,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type ListCapturesResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Captures         []*CaptureRecord       `protobuf:"bytes,1,rep,name=captures,proto3" json:"captures,omitempty"`
+	AuthorizedAmount int64                  `protobuf:"varint,2,opt,name=authorized_amount,json=authorizedAmount,proto3" json:"authorized_amount,omitempty"`
+	CapturedAmount   int64                  `protobuf:"varint,3,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	RemainingAmount  int64                  `protobuf:"varint,4,opt,name=remaining_amount,json=remainingAmount,proto3" json:"remaining_amount,omitempty"`
+	Error            string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *RefundRequest) Reset() {
-	*x = RefundRequest{}
+func (x *ListCapturesResponse) Reset() {
+	*x = ListCapturesResponse{}
 	mi := &file_proto_transaction_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RefundRequest) String() string {
+func (x *ListCapturesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RefundRequest) ProtoMessage() {}
+func (*ListCapturesResponse) ProtoMessage() {}
 
-func (x *RefundRequest) ProtoReflect() protoreflect.Message {
+func (x *ListCapturesResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_transaction_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -585,65 +682,69 @@ func (x *RefundRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RefundRequest.ProtoReflect.Descriptor instead.
-func (*RefundRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListCapturesResponse.ProtoReflect.Descriptor instead.
+func (*ListCapturesResponse) Descriptor() ([]byte, []int) {
 	return file_proto_transaction_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *RefundRequest) GetTransactionId() string {
+func (x *ListCapturesResponse) GetCaptures() []*CaptureRecord {
 	if x != nil {
-		return x.TransactionId
+		return x.Captures
 	}
-	return ""
+	return nil
 }
 
-func (x *RefundRequest) GetAmount() int64 {
+func (x *ListCapturesResponse) GetAuthorizedAmount() int64 {
 	if x != nil {
-		return x.Amount
+		return x.AuthorizedAmount
 	}
 	return 0
 }
 
-func (x *RefundRequest) GetReason() string {
+func (x *ListCapturesResponse) GetCapturedAmount() int64 {
 	if x != nil {
-		return x.Reason
+		return x.CapturedAmount
 	}
-	return ""
+	return 0
 }
 
-func (x *RefundRequest) GetMerchantId() string {
+func (x *ListCapturesResponse) GetRemainingAmount() int64 {
 	if x != nil {
-		return x.MerchantId
+		return x.RemainingAmount
+	}
+	return 0
+}
+
+func (x *ListCapturesResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type RefundResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	RefundId        string                 `protobuf:"bytes,1,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
-	TransactionId   string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	RefundedAmount  int64                  `protobuf:"varint,3,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
-	RemainingAmount int64                  `protobuf:"varint,4,opt,name=remaining_amount,json=remainingAmount,proto3" json:"remaining_amount,omitempty"`
-	ResponseMessage string                 `protobuf:"bytes,5,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
-	Error           string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type VoidRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RefundResponse) Reset() {
-	*x = RefundResponse{}
+func (x *VoidRequest) Reset() {
+	*x = VoidRequest{}
 	mi := &file_proto_transaction_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RefundResponse) String() string {
+func (x *VoidRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RefundResponse) ProtoMessage() {}
+func (*VoidRequest) ProtoMessage() {}
 
-func (x *RefundResponse) ProtoReflect() protoreflect.Message {
+func (x *VoidRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_transaction_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -655,75 +756,56 @@ func (x *RefundResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RefundResponse.ProtoReflect.Descriptor instead.
-func (*RefundResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use VoidRequest.ProtoReflect.Descriptor instead.
+func (*VoidRequest) Descriptor() ([]byte, []int) {
 	return file_proto_transaction_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *RefundResponse) GetRefundId() string {
-	if x != nil {
-		return x.RefundId
-	}
-	return ""
-}
-
-func (x *RefundResponse) GetTransactionId() string {
+func (x *VoidRequest) GetTransactionId() string {
 	if x != nil {
 		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *RefundResponse) GetRefundedAmount() int64 {
-	if x != nil {
-		return x.RefundedAmount
-	}
-	return 0
-}
-
-func (x *RefundResponse) GetRemainingAmount() int64 {
-	if x != nil {
-		return x.RemainingAmount
-	}
-	return 0
-}
-
-func (x *RefundResponse) GetResponseMessage() string {
+func (x *VoidRequest) GetMerchantId() string {
 	if x != nil {
-		return x.ResponseMessage
+		return x.MerchantId
 	}
 	return ""
 }
 
-func (x *RefundResponse) GetError() string {
+func (x *VoidRequest) GetReason() string {
 	if x != nil {
-		return x.Error
+		return x.Reason
 	}
 	return ""
 }
 
-type GetTransactionRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
-	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type VoidResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	ResponseMessage string                 `protobuf:"bytes,3,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *GetTransactionRequest) Reset() {
-	*x = GetTransactionRequest{}
+func (x *VoidResponse) Reset() {
+	*x = VoidResponse{}
 	mi := &file_proto_transaction_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionRequest) String() string {
+func (x *VoidResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionRequest) ProtoMessage() {}
+func (*VoidResponse) ProtoMessage() {}
 
-func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+func (x *VoidResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_transaction_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -735,30 +817,288 @@ func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
-func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use VoidResponse.ProtoReflect.Descriptor instead.
+func (*VoidResponse) Descriptor() ([]byte, []int) {
 	return file_proto_transaction_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetTransactionRequest) GetTransactionId() string {
+func (x *VoidResponse) GetTransactionId() string {
 	if x != nil {
 		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *GetTransactionRequest) GetMerchantId() string {
+func (x *VoidResponse) GetStatus() string {
 	if x != nil {
-		return x.MerchantId
+		return x.Status
 	}
 	return ""
 }
 
-type TransactionResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	MerchantId     string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
-	Type           string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+func (x *VoidResponse) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
+func (x *VoidResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RefundRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount        int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"` // Can be partial
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,4,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`                       // Required; must match the original transaction
+	ReasonCode    string                 `protobuf:"bytes,6,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"` // duplicate, fraudulent, requested_by_customer, product_not_received, product_unacceptable, other (default)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundRequest) Reset() {
+	*x = RefundRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundRequest) ProtoMessage() {}
+
+func (x *RefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundRequest.ProtoReflect.Descriptor instead.
+func (*RefundRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RefundRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+type RefundResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	RefundId           string                 `protobuf:"bytes,1,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
+	TransactionId      string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	RefundedAmount     int64                  `protobuf:"varint,3,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
+	RemainingAmount    int64                  `protobuf:"varint,4,opt,name=remaining_amount,json=remainingAmount,proto3" json:"remaining_amount,omitempty"`
+	ResponseMessage    string                 `protobuf:"bytes,5,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	Error              string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	RefundStatus       string                 `protobuf:"bytes,7,opt,name=refund_status,json=refundStatus,proto3" json:"refund_status,omitempty"`                     // queued, requested, sent_to_issuer, settled, failed
+	EstimatedArrivalAt string                 `protobuf:"bytes,8,opt,name=estimated_arrival_at,json=estimatedArrivalAt,proto3" json:"estimated_arrival_at,omitempty"` // YYYY-MM-DD
+	ErrorCode          string                 `protobuf:"bytes,9,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`                              // currency_required, currency_mismatch; empty for other errors
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RefundResponse) Reset() {
+	*x = RefundResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundResponse) ProtoMessage() {}
+
+func (x *RefundResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundResponse.ProtoReflect.Descriptor instead.
+func (*RefundResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RefundResponse) GetRefundId() string {
+	if x != nil {
+		return x.RefundId
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetRefundedAmount() int64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+func (x *RefundResponse) GetRemainingAmount() int64 {
+	if x != nil {
+		return x.RemainingAmount
+	}
+	return 0
+}
+
+func (x *RefundResponse) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetRefundStatus() string {
+	if x != nil {
+		return x.RefundStatus
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetEstimatedArrivalAt() string {
+	if x != nil {
+		return x.EstimatedArrivalAt
+	}
+	return ""
+}
+
+func (x *RefundResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetTransactionRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type TransactionResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MerchantId     string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Type           string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
 	Status         string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
 	Amount         int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
 	Currency       string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
@@ -776,13 +1116,18 @@ type TransactionResponse struct {
 	AuthorizedAt   string                 `protobuf:"bytes,18,opt,name=authorized_at,json=authorizedAt,proto3" json:"authorized_at,omitempty"`
 	CapturedAt     string                 `protobuf:"bytes,19,opt,name=captured_at,json=capturedAt,proto3" json:"captured_at,omitempty"`
 	Error          string                 `protobuf:"bytes,20,opt,name=error,proto3" json:"error,omitempty"`
+	VoidedAt       string                 `protobuf:"bytes,21,opt,name=voided_at,json=voidedAt,proto3" json:"voided_at,omitempty"`
+	VoidedReason   string                 `protobuf:"bytes,22,opt,name=voided_reason,json=voidedReason,proto3" json:"voided_reason,omitempty"` // requested, expired
+	Tags           []string               `protobuf:"bytes,23,rep,name=tags,proto3" json:"tags,omitempty"`
+	NoteCount      int32                  `protobuf:"varint,24,opt,name=note_count,json=noteCount,proto3" json:"note_count,omitempty"`
+	TestMode       bool                   `protobuf:"varint,25,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *TransactionResponse) Reset() {
 	*x = TransactionResponse{}
-	mi := &file_proto_transaction_proto_msgTypes[9]
+	mi := &file_proto_transaction_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -793,8 +1138,3760 @@ func (x *TransactionResponse) String() string {
 
 func (*TransactionResponse) ProtoMessage() {}
 
-func (x *TransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_transaction_proto_msgTypes[9]
+func (x *TransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionResponse.ProtoReflect.Descriptor instead.
+func (*TransactionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TransactionResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetAmountMad() int64 {
+	if x != nil {
+		return x.AmountMad
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetCardBrand() string {
+	if x != nil {
+		return x.CardBrand
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetCardLast4() string {
+	if x != nil {
+		return x.CardLast4
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetAuthCode() string {
+	if x != nil {
+		return x.AuthCode
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetFraudScore() int32 {
+	if x != nil {
+		return x.FraudScore
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetCapturedAmount() int64 {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetRefundedAmount() int64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetProcessingFee() int64 {
+	if x != nil {
+		return x.ProcessingFee
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetNetAmount() int64 {
+	if x != nil {
+		return x.NetAmount
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetAuthorizedAt() string {
+	if x != nil {
+		return x.AuthorizedAt
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetCapturedAt() string {
+	if x != nil {
+		return x.CapturedAt
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetVoidedAt() string {
+	if x != nil {
+		return x.VoidedAt
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetVoidedReason() string {
+	if x != nil {
+		return x.VoidedReason
+	}
+	return ""
+}
+
+func (x *TransactionResponse) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *TransactionResponse) GetNoteCount() int32 {
+	if x != nil {
+		return x.NoteCount
+	}
+	return 0
+}
+
+func (x *TransactionResponse) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
+type ListTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedFrom   string                 `protobuf:"bytes,5,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`     // RFC3339, inclusive
+	CreatedTo     string                 `protobuf:"bytes,6,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`           // RFC3339, exclusive
+	SortBy        string                 `protobuf:"bytes,7,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`                    // created_at (default), amount, status, type, captured_at
+	SortOrder     string                 `protobuf:"bytes,8,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`           // desc (default) or asc
+	Type          string                 `protobuf:"bytes,9,opt,name=type,proto3" json:"type,omitempty"`                                      // authorize, capture, sale, refund, void
+	VoidedReason  string                 `protobuf:"bytes,10,opt,name=voided_reason,json=voidedReason,proto3" json:"voided_reason,omitempty"` // requested, expired
+	Tag           string                 `protobuf:"bytes,11,opt,name=tag,proto3" json:"tag,omitempty"`                                       // Comma-separated, transactions must have all of them
+	Note          string                 `protobuf:"bytes,12,opt,name=note,proto3" json:"note,omitempty"`                                     // Matches note text, case-insensitive
+	TestMode      bool                   `protobuf:"varint,13,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"`            // Sandbox transactions instead of live ones
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTransactionsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListTransactionsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetCreatedFrom() string {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetCreatedTo() string {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetVoidedReason() string {
+	if x != nil {
+		return x.VoidedReason
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*TransactionResponse `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"` // Matches across all pages
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"` // Page size actually applied
+	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*TransactionResponse {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListTransactionsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ListTransactionsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ListTransactionsResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTransactionsResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type GetSettlementBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchId       string                 `protobuf:"bytes,1,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSettlementBatchRequest) Reset() {
+	*x = GetSettlementBatchRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSettlementBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSettlementBatchRequest) ProtoMessage() {}
+
+func (x *GetSettlementBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSettlementBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetSettlementBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetSettlementBatchRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *GetSettlementBatchRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type SettlementBatchResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	MerchantId        string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	BatchDate         string                 `protobuf:"bytes,3,opt,name=batch_date,json=batchDate,proto3" json:"batch_date,omitempty"`        // YYYY-MM-DD
+	GrossAmount       int64                  `protobuf:"varint,4,opt,name=gross_amount,json=grossAmount,proto3" json:"gross_amount,omitempty"` // MAD cents
+	RefundAmount      int64                  `protobuf:"varint,5,opt,name=refund_amount,json=refundAmount,proto3" json:"refund_amount,omitempty"`
+	FeeAmount         int64                  `protobuf:"varint,6,opt,name=fee_amount,json=feeAmount,proto3" json:"fee_amount,omitempty"`
+	NetAmount         int64                  `protobuf:"varint,7,opt,name=net_amount,json=netAmount,proto3" json:"net_amount,omitempty"`
+	TransactionCount  int32                  `protobuf:"varint,8,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	RefundCount       int32                  `protobuf:"varint,9,opt,name=refund_count,json=refundCount,proto3" json:"refund_count,omitempty"`
+	Status            string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	SettlementDate    string                 `protobuf:"bytes,11,opt,name=settlement_date,json=settlementDate,proto3" json:"settlement_date,omitempty"` // YYYY-MM-DD
+	ReferenceNumber   string                 `protobuf:"bytes,12,opt,name=reference_number,json=referenceNumber,proto3" json:"reference_number,omitempty"`
+	SettledAt         string                 `protobuf:"bytes,13,opt,name=settled_at,json=settledAt,proto3" json:"settled_at,omitempty"`
+	Error             string                 `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	FeeReversalAmount int64                  `protobuf:"varint,15,opt,name=fee_reversal_amount,json=feeReversalAmount,proto3" json:"fee_reversal_amount,omitempty"` // Fees given back on refunds in the batch
+	InstantPayout     bool                   `protobuf:"varint,16,opt,name=instant_payout,json=instantPayout,proto3" json:"instant_payout,omitempty"`
+	PayoutFee         int64                  `protobuf:"varint,17,opt,name=payout_fee,json=payoutFee,proto3" json:"payout_fee,omitempty"`                         // Instant payout fee, already taken from net_amount
+	ReserveAmount     int64                  `protobuf:"varint,18,opt,name=reserve_amount,json=reserveAmount,proto3" json:"reserve_amount,omitempty"`             // Reserve held back, already taken from net_amount
+	BalanceAdjustment int64                  `protobuf:"varint,19,opt,name=balance_adjustment,json=balanceAdjustment,proto3" json:"balance_adjustment,omitempty"` // Settled against the merchant balance, already in net_amount
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SettlementBatchResponse) Reset() {
+	*x = SettlementBatchResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettlementBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettlementBatchResponse) ProtoMessage() {}
+
+func (x *SettlementBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettlementBatchResponse.ProtoReflect.Descriptor instead.
+func (*SettlementBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SettlementBatchResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetBatchDate() string {
+	if x != nil {
+		return x.BatchDate
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetGrossAmount() int64 {
+	if x != nil {
+		return x.GrossAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetRefundAmount() int64 {
+	if x != nil {
+		return x.RefundAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetFeeAmount() int64 {
+	if x != nil {
+		return x.FeeAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetNetAmount() int64 {
+	if x != nil {
+		return x.NetAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetTransactionCount() int32 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetRefundCount() int32 {
+	if x != nil {
+		return x.RefundCount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetSettlementDate() string {
+	if x != nil {
+		return x.SettlementDate
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetReferenceNumber() string {
+	if x != nil {
+		return x.ReferenceNumber
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetSettledAt() string {
+	if x != nil {
+		return x.SettledAt
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SettlementBatchResponse) GetFeeReversalAmount() int64 {
+	if x != nil {
+		return x.FeeReversalAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetInstantPayout() bool {
+	if x != nil {
+		return x.InstantPayout
+	}
+	return false
+}
+
+func (x *SettlementBatchResponse) GetPayoutFee() int64 {
+	if x != nil {
+		return x.PayoutFee
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetReserveAmount() int64 {
+	if x != nil {
+		return x.ReserveAmount
+	}
+	return 0
+}
+
+func (x *SettlementBatchResponse) GetBalanceAdjustment() int64 {
+	if x != nil {
+		return x.BalanceAdjustment
+	}
+	return 0
+}
+
+type ListSettlementBatchesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	DateFrom      string                 `protobuf:"bytes,2,opt,name=date_from,json=dateFrom,proto3" json:"date_from,omitempty"` // YYYY-MM-DD, inclusive
+	DateTo        string                 `protobuf:"bytes,3,opt,name=date_to,json=dateTo,proto3" json:"date_to,omitempty"`       // YYYY-MM-DD, exclusive
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSettlementBatchesRequest) Reset() {
+	*x = ListSettlementBatchesRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSettlementBatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSettlementBatchesRequest) ProtoMessage() {}
+
+func (x *ListSettlementBatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSettlementBatchesRequest.ProtoReflect.Descriptor instead.
+func (*ListSettlementBatchesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListSettlementBatchesRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListSettlementBatchesRequest) GetDateFrom() string {
+	if x != nil {
+		return x.DateFrom
+	}
+	return ""
+}
+
+func (x *ListSettlementBatchesRequest) GetDateTo() string {
+	if x != nil {
+		return x.DateTo
+	}
+	return ""
+}
+
+func (x *ListSettlementBatchesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListSettlementBatchesResponse struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Batches       []*SettlementBatchResponse `protobuf:"bytes,1,rep,name=batches,proto3" json:"batches,omitempty"`
+	Error         string                     `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSettlementBatchesResponse) Reset() {
+	*x = ListSettlementBatchesResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSettlementBatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSettlementBatchesResponse) ProtoMessage() {}
+
+func (x *ListSettlementBatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSettlementBatchesResponse.ProtoReflect.Descriptor instead.
+func (*ListSettlementBatchesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListSettlementBatchesResponse) GetBatches() []*SettlementBatchResponse {
+	if x != nil {
+		return x.Batches
+	}
+	return nil
+}
+
+func (x *ListSettlementBatchesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateInstantPayoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInstantPayoutRequest) Reset() {
+	*x = CreateInstantPayoutRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInstantPayoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInstantPayoutRequest) ProtoMessage() {}
+
+func (x *CreateInstantPayoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInstantPayoutRequest.ProtoReflect.Descriptor instead.
+func (*CreateInstantPayoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateInstantPayoutRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *CreateInstantPayoutRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type InstantPayoutResponse struct {
+	state            protoimpl.MessageState   `protogen:"open.v1"`
+	Eligible         bool                     `protobuf:"varint,1,opt,name=eligible,proto3" json:"eligible,omitempty"`
+	Reasons          []string                 `protobuf:"bytes,2,rep,name=reasons,proto3" json:"reasons,omitempty"`                                              // Why not, when not eligible
+	ChargebackRateBp int64                    `protobuf:"varint,3,opt,name=chargeback_rate_bp,json=chargebackRateBp,proto3" json:"chargeback_rate_bp,omitempty"` // 90-day chargebacks per 10,000 captures
+	AvailableAmount  int64                    `protobuf:"varint,4,opt,name=available_amount,json=availableAmount,proto3" json:"available_amount,omitempty"`      // Net of processing fees and refunds, before the payout fee
+	PayoutFee        int64                    `protobuf:"varint,5,opt,name=payout_fee,json=payoutFee,proto3" json:"payout_fee,omitempty"`
+	PayoutAmount     int64                    `protobuf:"varint,6,opt,name=payout_amount,json=payoutAmount,proto3" json:"payout_amount,omitempty"`
+	TransactionCount int32                    `protobuf:"varint,7,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	Batch            *SettlementBatchResponse `protobuf:"bytes,8,opt,name=batch,proto3" json:"batch,omitempty"` // Set once the payout is created
+	Error            string                   `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *InstantPayoutResponse) Reset() {
+	*x = InstantPayoutResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstantPayoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstantPayoutResponse) ProtoMessage() {}
+
+func (x *InstantPayoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstantPayoutResponse.ProtoReflect.Descriptor instead.
+func (*InstantPayoutResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *InstantPayoutResponse) GetEligible() bool {
+	if x != nil {
+		return x.Eligible
+	}
+	return false
+}
+
+func (x *InstantPayoutResponse) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *InstantPayoutResponse) GetChargebackRateBp() int64 {
+	if x != nil {
+		return x.ChargebackRateBp
+	}
+	return 0
+}
+
+func (x *InstantPayoutResponse) GetAvailableAmount() int64 {
+	if x != nil {
+		return x.AvailableAmount
+	}
+	return 0
+}
+
+func (x *InstantPayoutResponse) GetPayoutFee() int64 {
+	if x != nil {
+		return x.PayoutFee
+	}
+	return 0
+}
+
+func (x *InstantPayoutResponse) GetPayoutAmount() int64 {
+	if x != nil {
+		return x.PayoutAmount
+	}
+	return 0
+}
+
+func (x *InstantPayoutResponse) GetTransactionCount() int32 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *InstantPayoutResponse) GetBatch() *SettlementBatchResponse {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *InstantPayoutResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetBalanceRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type BalanceResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId        string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Currency          string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	Available         int64                  `protobuf:"varint,3,opt,name=available,proto3" json:"available,omitempty"` // MAD cents; negative when the merchant owes
+	Reserve           int64                  `protobuf:"varint,4,opt,name=reserve,proto3" json:"reserve,omitempty"`     // Rolling reserve not yet released
+	Pending           int64                  `protobuf:"varint,5,opt,name=pending,proto3" json:"pending,omitempty"`     // Captured, net of fees, not yet in a batch
+	PendingCount      int32                  `protobuf:"varint,6,opt,name=pending_count,json=pendingCount,proto3" json:"pending_count,omitempty"`
+	NextReleaseAt     string                 `protobuf:"bytes,7,opt,name=next_release_at,json=nextReleaseAt,proto3" json:"next_release_at,omitempty"` // RFC 3339, empty without a reserve
+	NextReleaseAmount int64                  `protobuf:"varint,8,opt,name=next_release_amount,json=nextReleaseAmount,proto3" json:"next_release_amount,omitempty"`
+	Error             string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BalanceResponse) Reset() {
+	*x = BalanceResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceResponse) ProtoMessage() {}
+
+func (x *BalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceResponse.ProtoReflect.Descriptor instead.
+func (*BalanceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BalanceResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *BalanceResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *BalanceResponse) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetReserve() int64 {
+	if x != nil {
+		return x.Reserve
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetPending() int64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetPendingCount() int32 {
+	if x != nil {
+		return x.PendingCount
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetNextReleaseAt() string {
+	if x != nil {
+		return x.NextReleaseAt
+	}
+	return ""
+}
+
+func (x *BalanceResponse) GetNextReleaseAmount() int64 {
+	if x != nil {
+		return x.NextReleaseAmount
+	}
+	return 0
+}
+
+func (x *BalanceResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetStatisticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	TestMode      bool                   `protobuf:"varint,2,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"`
+	From          string                 `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`         // YYYY-MM-DD in the merchant's settlement timezone
+	To            string                 `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`             // YYYY-MM-DD, included
+	Interval      string                 `protobuf:"bytes,5,opt,name=interval,proto3" json:"interval,omitempty"` // day or week; empty for totals only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatisticsRequest) Reset() {
+	*x = GetStatisticsRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatisticsRequest) ProtoMessage() {}
+
+func (x *GetStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetStatisticsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *GetStatisticsRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
+func (x *GetStatisticsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetStatisticsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetStatisticsRequest) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+// Amounts are MAD cents and rates are percentages
+type TransactionStatistics struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TotalTransactions int64                  `protobuf:"varint,1,opt,name=total_transactions,json=totalTransactions,proto3" json:"total_transactions,omitempty"`
+	TotalAmount       int64                  `protobuf:"varint,2,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
+	AuthorizedAmount  int64                  `protobuf:"varint,3,opt,name=authorized_amount,json=authorizedAmount,proto3" json:"authorized_amount,omitempty"` // Authorized and not captured yet
+	CapturedAmount    int64                  `protobuf:"varint,4,opt,name=captured_amount,json=capturedAmount,proto3" json:"captured_amount,omitempty"`
+	RefundedAmount    int64                  `protobuf:"varint,5,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
+	SettledAmount     int64                  `protobuf:"varint,6,opt,name=settled_amount,json=settledAmount,proto3" json:"settled_amount,omitempty"`
+	SuccessRate       float64                `protobuf:"fixed64,7,opt,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"`
+	RefundRate        float64                `protobuf:"fixed64,8,opt,name=refund_rate,json=refundRate,proto3" json:"refund_rate,omitempty"` // Share of captured payments with a refund
+	AverageFraudScore float64                `protobuf:"fixed64,9,opt,name=average_fraud_score,json=averageFraudScore,proto3" json:"average_fraud_score,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *TransactionStatistics) Reset() {
+	*x = TransactionStatistics{}
+	mi := &file_proto_transaction_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionStatistics) ProtoMessage() {}
+
+func (x *TransactionStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionStatistics.ProtoReflect.Descriptor instead.
+func (*TransactionStatistics) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TransactionStatistics) GetTotalTransactions() int64 {
+	if x != nil {
+		return x.TotalTransactions
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetTotalAmount() int64 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetAuthorizedAmount() int64 {
+	if x != nil {
+		return x.AuthorizedAmount
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetCapturedAmount() int64 {
+	if x != nil {
+		return x.CapturedAmount
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetRefundedAmount() int64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetSettledAmount() int64 {
+	if x != nil {
+		return x.SettledAmount
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetSuccessRate() float64 {
+	if x != nil {
+		return x.SuccessRate
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetRefundRate() float64 {
+	if x != nil {
+		return x.RefundRate
+	}
+	return 0
+}
+
+func (x *TransactionStatistics) GetAverageFraudScore() float64 {
+	if x != nil {
+		return x.AverageFraudScore
+	}
+	return 0
+}
+
+type StatisticsBucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"` // First local day in the bucket
+	Statistics    *TransactionStatistics `protobuf:"bytes,2,opt,name=statistics,proto3" json:"statistics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatisticsBucket) Reset() {
+	*x = StatisticsBucket{}
+	mi := &file_proto_transaction_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatisticsBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatisticsBucket) ProtoMessage() {}
+
+func (x *StatisticsBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatisticsBucket.ProtoReflect.Descriptor instead.
+func (*StatisticsBucket) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StatisticsBucket) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *StatisticsBucket) GetStatistics() *TransactionStatistics {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+type StatisticsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timezone      string                 `protobuf:"bytes,1,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Start         string                 `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"` // RFC 3339
+	End           string                 `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`     // RFC 3339, excluded
+	Totals        *TransactionStatistics `protobuf:"bytes,4,opt,name=totals,proto3" json:"totals,omitempty"`
+	Buckets       []*StatisticsBucket    `protobuf:"bytes,5,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatisticsResponse) Reset() {
+	*x = StatisticsResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatisticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatisticsResponse) ProtoMessage() {}
+
+func (x *StatisticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatisticsResponse.ProtoReflect.Descriptor instead.
+func (*StatisticsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StatisticsResponse) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *StatisticsResponse) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *StatisticsResponse) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *StatisticsResponse) GetTotals() *TransactionStatistics {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *StatisticsResponse) GetBuckets() []*StatisticsBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *StatisticsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetRefundRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefundId      string                 `protobuf:"bytes,1,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRefundRequest) Reset() {
+	*x = GetRefundRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRefundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRefundRequest) ProtoMessage() {}
+
+func (x *GetRefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRefundRequest.ProtoReflect.Descriptor instead.
+func (*GetRefundRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetRefundRequest) GetRefundId() string {
+	if x != nil {
+		return x.RefundId
+	}
+	return ""
+}
+
+func (x *GetRefundRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type ListRefundsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRefundsRequest) Reset() {
+	*x = ListRefundsRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRefundsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRefundsRequest) ProtoMessage() {}
+
+func (x *ListRefundsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRefundsRequest.ProtoReflect.Descriptor instead.
+func (*ListRefundsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListRefundsRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ListRefundsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type RefundDetailResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	RefundId           string                 `protobuf:"bytes,1,opt,name=refund_id,json=refundId,proto3" json:"refund_id,omitempty"`
+	TransactionId      string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount             int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency           string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status             string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"` // queued, requested, sent_to_issuer, settled, failed
+	Reason             string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestedAt        string                 `protobuf:"bytes,7,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	SentToIssuerAt     string                 `protobuf:"bytes,8,opt,name=sent_to_issuer_at,json=sentToIssuerAt,proto3" json:"sent_to_issuer_at,omitempty"`
+	SettledAt          string                 `protobuf:"bytes,9,opt,name=settled_at,json=settledAt,proto3" json:"settled_at,omitempty"`
+	EstimatedArrivalAt string                 `protobuf:"bytes,10,opt,name=estimated_arrival_at,json=estimatedArrivalAt,proto3" json:"estimated_arrival_at,omitempty"` // YYYY-MM-DD
+	Error              string                 `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`
+	ReasonCode         string                 `protobuf:"bytes,12,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RefundDetailResponse) Reset() {
+	*x = RefundDetailResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundDetailResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundDetailResponse) ProtoMessage() {}
+
+func (x *RefundDetailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundDetailResponse.ProtoReflect.Descriptor instead.
+func (*RefundDetailResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RefundDetailResponse) GetRefundId() string {
+	if x != nil {
+		return x.RefundId
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundDetailResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetRequestedAt() string {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetSentToIssuerAt() string {
+	if x != nil {
+		return x.SentToIssuerAt
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetSettledAt() string {
+	if x != nil {
+		return x.SettledAt
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetEstimatedArrivalAt() string {
+	if x != nil {
+		return x.EstimatedArrivalAt
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RefundDetailResponse) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+type ListRefundsResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Refunds       []*RefundDetailResponse `protobuf:"bytes,1,rep,name=refunds,proto3" json:"refunds,omitempty"`
+	Error         string                  `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRefundsResponse) Reset() {
+	*x = ListRefundsResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRefundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRefundsResponse) ProtoMessage() {}
+
+func (x *ListRefundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRefundsResponse.ProtoReflect.Descriptor instead.
+func (*ListRefundsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListRefundsResponse) GetRefunds() []*RefundDetailResponse {
+	if x != nil {
+		return x.Refunds
+	}
+	return nil
+}
+
+func (x *ListRefundsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListMerchantRefundsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"` // Optional: refunds of one transaction
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                                    // queued, requested, sent_to_issuer, settled, failed
+	ReasonCode    string                 `protobuf:"bytes,4,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	CreatedFrom   string                 `protobuf:"bytes,5,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"` // RFC 3339, inclusive
+	CreatedTo     string                 `protobuf:"bytes,6,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`       // RFC 3339, exclusive
+	Limit         int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`                               // defaults to 20, at most 100
+	Offset        int32                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`
+	TestMode      bool                   `protobuf:"varint,9,opt,name=test_mode,json=testMode,proto3" json:"test_mode,omitempty"` // Sandbox refunds instead of live ones
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchantRefundsRequest) Reset() {
+	*x = ListMerchantRefundsRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchantRefundsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchantRefundsRequest) ProtoMessage() {}
+
+func (x *ListMerchantRefundsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchantRefundsRequest.ProtoReflect.Descriptor instead.
+func (*ListMerchantRefundsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListMerchantRefundsRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetCreatedFrom() string {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetCreatedTo() string {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return ""
+}
+
+func (x *ListMerchantRefundsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListMerchantRefundsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListMerchantRefundsRequest) GetTestMode() bool {
+	if x != nil {
+		return x.TestMode
+	}
+	return false
+}
+
+type ListMerchantRefundsResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Refunds       []*RefundDetailResponse `protobuf:"bytes,1,rep,name=refunds,proto3" json:"refunds,omitempty"`
+	Total         int64                   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"` // Matches across all pages
+	HasMore       bool                    `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Error         string                  `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchantRefundsResponse) Reset() {
+	*x = ListMerchantRefundsResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchantRefundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchantRefundsResponse) ProtoMessage() {}
+
+func (x *ListMerchantRefundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchantRefundsResponse.ProtoReflect.Descriptor instead.
+func (*ListMerchantRefundsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListMerchantRefundsResponse) GetRefunds() []*RefundDetailResponse {
+	if x != nil {
+		return x.Refunds
+	}
+	return nil
+}
+
+func (x *ListMerchantRefundsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListMerchantRefundsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ListMerchantRefundsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetRefundQueueStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRefundQueueStatusRequest) Reset() {
+	*x = GetRefundQueueStatusRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRefundQueueStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRefundQueueStatusRequest) ProtoMessage() {}
+
+func (x *GetRefundQueueStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRefundQueueStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetRefundQueueStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetRefundQueueStatusRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type RefundQueueStatusResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Queued                int64                  `protobuf:"varint,1,opt,name=queued,proto3" json:"queued,omitempty"`
+	Processing            int64                  `protobuf:"varint,2,opt,name=processing,proto3" json:"processing,omitempty"`                                // Sent to the acquirer, awaiting its answer
+	Sent                  int64                  `protobuf:"varint,3,opt,name=sent,proto3" json:"sent,omitempty"`                                            // Last 24 hours
+	Failed                int64                  `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`                                        // Last 24 hours
+	OldestQueuedAt        string                 `protobuf:"bytes,5,opt,name=oldest_queued_at,json=oldestQueuedAt,proto3" json:"oldest_queued_at,omitempty"` // RFC 3339; empty when nothing is queued
+	EstimatedDrainSeconds int64                  `protobuf:"varint,6,opt,name=estimated_drain_seconds,json=estimatedDrainSeconds,proto3" json:"estimated_drain_seconds,omitempty"`
+	Error                 string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *RefundQueueStatusResponse) Reset() {
+	*x = RefundQueueStatusResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundQueueStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundQueueStatusResponse) ProtoMessage() {}
+
+func (x *RefundQueueStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundQueueStatusResponse.ProtoReflect.Descriptor instead.
+func (*RefundQueueStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RefundQueueStatusResponse) GetQueued() int64 {
+	if x != nil {
+		return x.Queued
+	}
+	return 0
+}
+
+func (x *RefundQueueStatusResponse) GetProcessing() int64 {
+	if x != nil {
+		return x.Processing
+	}
+	return 0
+}
+
+func (x *RefundQueueStatusResponse) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *RefundQueueStatusResponse) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *RefundQueueStatusResponse) GetOldestQueuedAt() string {
+	if x != nil {
+		return x.OldestQueuedAt
+	}
+	return ""
+}
+
+func (x *RefundQueueStatusResponse) GetEstimatedDrainSeconds() int64 {
+	if x != nil {
+		return x.EstimatedDrainSeconds
+	}
+	return 0
+}
+
+func (x *RefundQueueStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetTransactionTimelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionTimelineRequest) Reset() {
+	*x = GetTransactionTimelineRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionTimelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionTimelineRequest) ProtoMessage() {}
+
+func (x *GetTransactionTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionTimelineRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetTransactionTimelineRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *GetTransactionTimelineRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type TransactionTimelineEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventType     string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	OldStatus     string                 `protobuf:"bytes,2,opt,name=old_status,json=oldStatus,proto3" json:"old_status,omitempty"`
+	NewStatus     string                 `protobuf:"bytes,3,opt,name=new_status,json=newStatus,proto3" json:"new_status,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Metadata      string                 `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON, empty when none
+	CreatedBy     string                 `protobuf:"bytes,6,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionTimelineEvent) Reset() {
+	*x = TransactionTimelineEvent{}
+	mi := &file_proto_transaction_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionTimelineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionTimelineEvent) ProtoMessage() {}
+
+func (x *TransactionTimelineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionTimelineEvent.ProtoReflect.Descriptor instead.
+func (*TransactionTimelineEvent) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *TransactionTimelineEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *TransactionTimelineEvent) GetOldStatus() string {
+	if x != nil {
+		return x.OldStatus
+	}
+	return ""
+}
+
+func (x *TransactionTimelineEvent) GetNewStatus() string {
+	if x != nil {
+		return x.NewStatus
+	}
+	return ""
+}
+
+func (x *TransactionTimelineEvent) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TransactionTimelineEvent) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+func (x *TransactionTimelineEvent) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *TransactionTimelineEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type IssuerResponseRecord struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Approved         bool                   `protobuf:"varint,1,opt,name=approved,proto3" json:"approved,omitempty"`
+	AuthCode         string                 `protobuf:"bytes,2,opt,name=auth_code,json=authCode,proto3" json:"auth_code,omitempty"`
+	ResponseCode     string                 `protobuf:"bytes,3,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	ResponseMessage  string                 `protobuf:"bytes,4,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	DeclineReason    string                 `protobuf:"bytes,5,opt,name=decline_reason,json=declineReason,proto3" json:"decline_reason,omitempty"`
+	AvsResult        string                 `protobuf:"bytes,6,opt,name=avs_result,json=avsResult,proto3" json:"avs_result,omitempty"`
+	CvvResult        string                 `protobuf:"bytes,7,opt,name=cvv_result,json=cvvResult,proto3" json:"cvv_result,omitempty"`
+	ProcessingTimeMs int32                  `protobuf:"varint,8,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"`
+	CreatedAt        string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *IssuerResponseRecord) Reset() {
+	*x = IssuerResponseRecord{}
+	mi := &file_proto_transaction_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssuerResponseRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssuerResponseRecord) ProtoMessage() {}
+
+func (x *IssuerResponseRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssuerResponseRecord.ProtoReflect.Descriptor instead.
+func (*IssuerResponseRecord) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *IssuerResponseRecord) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *IssuerResponseRecord) GetAuthCode() string {
+	if x != nil {
+		return x.AuthCode
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetResponseCode() string {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetDeclineReason() string {
+	if x != nil {
+		return x.DeclineReason
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetAvsResult() string {
+	if x != nil {
+		return x.AvsResult
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetCvvResult() string {
+	if x != nil {
+		return x.CvvResult
+	}
+	return ""
+}
+
+func (x *IssuerResponseRecord) GetProcessingTimeMs() int32 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *IssuerResponseRecord) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type TransactionTimelineResponse struct {
+	state           protoimpl.MessageState      `protogen:"open.v1"`
+	Transaction     *TransactionResponse        `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Events          []*TransactionTimelineEvent `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	IssuerResponses []*IssuerResponseRecord     `protobuf:"bytes,3,rep,name=issuer_responses,json=issuerResponses,proto3" json:"issuer_responses,omitempty"`
+	RoutingDecision string                      `protobuf:"bytes,4,opt,name=routing_decision,json=routingDecision,proto3" json:"routing_decision,omitempty"` // JSON, empty when the transaction was not routed
+	Error           string                      `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransactionTimelineResponse) Reset() {
+	*x = TransactionTimelineResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionTimelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionTimelineResponse) ProtoMessage() {}
+
+func (x *TransactionTimelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionTimelineResponse.ProtoReflect.Descriptor instead.
+func (*TransactionTimelineResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TransactionTimelineResponse) GetTransaction() *TransactionResponse {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *TransactionTimelineResponse) GetEvents() []*TransactionTimelineEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *TransactionTimelineResponse) GetIssuerResponses() []*IssuerResponseRecord {
+	if x != nil {
+		return x.IssuerResponses
+	}
+	return nil
+}
+
+func (x *TransactionTimelineResponse) GetRoutingDecision() string {
+	if x != nil {
+		return x.RoutingDecision
+	}
+	return ""
+}
+
+func (x *TransactionTimelineResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 3-D Secure
+type AuthenticateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	CardToken     string                 `protobuf:"bytes,2,opt,name=card_token,json=cardToken,proto3" json:"card_token,omitempty"`
+	CardBrand     string                 `protobuf:"bytes,3,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
+	CardLast4     string                 `protobuf:"bytes,4,opt,name=card_last4,json=cardLast4,proto3" json:"card_last4,omitempty"`
+	Amount        int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency      string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	ReturnUrl     string                 `protobuf:"bytes,7,opt,name=return_url,json=returnUrl,proto3" json:"return_url,omitempty"` // Where the ACS sends the cardholder after a challenge
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthenticateRequest) Reset() {
+	*x = AuthenticateRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthenticateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateRequest) ProtoMessage() {}
+
+func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateRequest.ProtoReflect.Descriptor instead.
+func (*AuthenticateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *AuthenticateRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetCardToken() string {
+	if x != nil {
+		return x.CardToken
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetCardBrand() string {
+	if x != nil {
+		return x.CardBrand
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetCardLast4() string {
+	if x != nil {
+		return x.CardLast4
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *AuthenticateRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetReturnUrl() string {
+	if x != nil {
+		return x.ReturnUrl
+	}
+	return ""
+}
+
+type AuthenticateResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransStatus     string                 `protobuf:"bytes,1,opt,name=trans_status,json=transStatus,proto3" json:"trans_status,omitempty"` // Y, A, C, N, R or U, as in the EMV 3DS spec
+	Eci             string                 `protobuf:"bytes,2,opt,name=eci,proto3" json:"eci,omitempty"`
+	Cavv            string                 `protobuf:"bytes,3,opt,name=cavv,proto3" json:"cavv,omitempty"`
+	DsTransactionId string                 `protobuf:"bytes,4,opt,name=ds_transaction_id,json=dsTransactionId,proto3" json:"ds_transaction_id,omitempty"`
+	AcsUrl          string                 `protobuf:"bytes,5,opt,name=acs_url,json=acsUrl,proto3" json:"acs_url,omitempty"` // Challenge page, when trans_status is C
+	Version         string                 `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`
+	Reason          string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	Error           string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AuthenticateResponse) Reset() {
+	*x = AuthenticateResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthenticateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateResponse) ProtoMessage() {}
+
+func (x *AuthenticateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateResponse.ProtoReflect.Descriptor instead.
+func (*AuthenticateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *AuthenticateResponse) GetTransStatus() string {
+	if x != nil {
+		return x.TransStatus
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetEci() string {
+	if x != nil {
+		return x.Eci
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetCavv() string {
+	if x != nil {
+		return x.Cavv
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetDsTransactionId() string {
+	if x != nil {
+		return x.DsTransactionId
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetAcsUrl() string {
+	if x != nil {
+		return x.AcsUrl
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AuthenticateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CompleteAuthenticationRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId      string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	DsTransactionId string                 `protobuf:"bytes,2,opt,name=ds_transaction_id,json=dsTransactionId,proto3" json:"ds_transaction_id,omitempty"`
+	CardBrand       string                 `protobuf:"bytes,3,opt,name=card_brand,json=cardBrand,proto3" json:"card_brand,omitempty"`
+	Cres            string                 `protobuf:"bytes,4,opt,name=cres,proto3" json:"cres,omitempty"` // Base64url challenge response the ACS posted to return_url
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CompleteAuthenticationRequest) Reset() {
+	*x = CompleteAuthenticationRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteAuthenticationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteAuthenticationRequest) ProtoMessage() {}
+
+func (x *CompleteAuthenticationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteAuthenticationRequest.ProtoReflect.Descriptor instead.
+func (*CompleteAuthenticationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CompleteAuthenticationRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *CompleteAuthenticationRequest) GetDsTransactionId() string {
+	if x != nil {
+		return x.DsTransactionId
+	}
+	return ""
+}
+
+func (x *CompleteAuthenticationRequest) GetCardBrand() string {
+	if x != nil {
+		return x.CardBrand
+	}
+	return ""
+}
+
+func (x *CompleteAuthenticationRequest) GetCres() string {
+	if x != nil {
+		return x.Cres
+	}
+	return ""
+}
+
+type ListDisputesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // open, needs_response, under_review, won, lost, accepted, closed
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	TransactionId string                 `protobuf:"bytes,5,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"` // Optional: only disputes of this transaction
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDisputesRequest) Reset() {
+	*x = ListDisputesRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDisputesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDisputesRequest) ProtoMessage() {}
+
+func (x *ListDisputesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDisputesRequest.ProtoReflect.Descriptor instead.
+func (*ListDisputesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListDisputesRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *ListDisputesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListDisputesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDisputesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListDisputesRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+type ListDisputesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Disputes      []*DisputeResponse     `protobuf:"bytes,1,rep,name=disputes,proto3" json:"disputes,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"` // Matches across all pages
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDisputesResponse) Reset() {
+	*x = ListDisputesResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDisputesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDisputesResponse) ProtoMessage() {}
+
+func (x *ListDisputesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDisputesResponse.ProtoReflect.Descriptor instead.
+func (*ListDisputesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ListDisputesResponse) GetDisputes() []*DisputeResponse {
+	if x != nil {
+		return x.Disputes
+	}
+	return nil
+}
+
+func (x *ListDisputesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListDisputesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *ListDisputesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetDisputeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisputeId     string                 `protobuf:"bytes,1,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDisputeRequest) Reset() {
+	*x = GetDisputeRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDisputeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDisputeRequest) ProtoMessage() {}
+
+func (x *GetDisputeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDisputeRequest.ProtoReflect.Descriptor instead.
+func (*GetDisputeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *GetDisputeRequest) GetDisputeId() string {
+	if x != nil {
+		return x.DisputeId
+	}
+	return ""
+}
+
+func (x *GetDisputeRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type DisputeEvidenceFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FileName      string                 `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisputeEvidenceFile) Reset() {
+	*x = DisputeEvidenceFile{}
+	mi := &file_proto_transaction_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisputeEvidenceFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisputeEvidenceFile) ProtoMessage() {}
+
+func (x *DisputeEvidenceFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisputeEvidenceFile.ProtoReflect.Descriptor instead.
+func (*DisputeEvidenceFile) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *DisputeEvidenceFile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DisputeEvidenceFile) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *DisputeEvidenceFile) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *DisputeEvidenceFile) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *DisputeEvidenceFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *DisputeEvidenceFile) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DisputeEvidenceFile) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type DisputeResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransactionId       string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId          string                 `protobuf:"bytes,3,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Status              string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Reason              string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReasonCode          string                 `protobuf:"bytes,6,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Amount              int64                  `protobuf:"varint,7,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency            string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	ChargebackFee       int64                  `protobuf:"varint,9,opt,name=chargeback_fee,json=chargebackFee,proto3" json:"chargeback_fee,omitempty"`
+	NetLoss             int64                  `protobuf:"varint,10,opt,name=net_loss,json=netLoss,proto3" json:"net_loss,omitempty"`
+	CustomerStatement   string                 `protobuf:"bytes,11,opt,name=customer_statement,json=customerStatement,proto3" json:"customer_statement,omitempty"`
+	DisputedAt          string                 `protobuf:"bytes,12,opt,name=disputed_at,json=disputedAt,proto3" json:"disputed_at,omitempty"`
+	ResponseDueAt       string                 `protobuf:"bytes,13,opt,name=response_due_at,json=responseDueAt,proto3" json:"response_due_at,omitempty"` // Evidence must be submitted before this
+	Overdue             bool                   `protobuf:"varint,14,opt,name=overdue,proto3" json:"overdue,omitempty"`
+	SecondsUntilDue     int64                  `protobuf:"varint,15,opt,name=seconds_until_due,json=secondsUntilDue,proto3" json:"seconds_until_due,omitempty"` // 0 once the deadline passed or no response is needed
+	ResponseSubmittedAt string                 `protobuf:"bytes,16,opt,name=response_submitted_at,json=responseSubmittedAt,proto3" json:"response_submitted_at,omitempty"`
+	MerchantEvidence    string                 `protobuf:"bytes,17,opt,name=merchant_evidence,json=merchantEvidence,proto3" json:"merchant_evidence,omitempty"` // JSON as submitted, empty until then
+	EvidenceFiles       []*DisputeEvidenceFile `protobuf:"bytes,18,rep,name=evidence_files,json=evidenceFiles,proto3" json:"evidence_files,omitempty"`
+	ResolutionReason    string                 `protobuf:"bytes,19,opt,name=resolution_reason,json=resolutionReason,proto3" json:"resolution_reason,omitempty"`
+	ResolvedAt          string                 `protobuf:"bytes,20,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	CreatedAt           string                 `protobuf:"bytes,21,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Error               string                 `protobuf:"bytes,22,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DisputeResponse) Reset() {
+	*x = DisputeResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisputeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisputeResponse) ProtoMessage() {}
+
+func (x *DisputeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisputeResponse.ProtoReflect.Descriptor instead.
+func (*DisputeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *DisputeResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *DisputeResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetChargebackFee() int64 {
+	if x != nil {
+		return x.ChargebackFee
+	}
+	return 0
+}
+
+func (x *DisputeResponse) GetNetLoss() int64 {
+	if x != nil {
+		return x.NetLoss
+	}
+	return 0
+}
+
+func (x *DisputeResponse) GetCustomerStatement() string {
+	if x != nil {
+		return x.CustomerStatement
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetDisputedAt() string {
+	if x != nil {
+		return x.DisputedAt
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetResponseDueAt() string {
+	if x != nil {
+		return x.ResponseDueAt
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetOverdue() bool {
+	if x != nil {
+		return x.Overdue
+	}
+	return false
+}
+
+func (x *DisputeResponse) GetSecondsUntilDue() int64 {
+	if x != nil {
+		return x.SecondsUntilDue
+	}
+	return 0
+}
+
+func (x *DisputeResponse) GetResponseSubmittedAt() string {
+	if x != nil {
+		return x.ResponseSubmittedAt
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetMerchantEvidence() string {
+	if x != nil {
+		return x.MerchantEvidence
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetEvidenceFiles() []*DisputeEvidenceFile {
+	if x != nil {
+		return x.EvidenceFiles
+	}
+	return nil
+}
+
+func (x *DisputeResponse) GetResolutionReason() string {
+	if x != nil {
+		return x.ResolutionReason
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetResolvedAt() string {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *DisputeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UploadDisputeEvidenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisputeId     string                 `protobuf:"bytes,1,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	FileName      string                 `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // application/pdf, image/png, image/jpeg or text/plain
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Content       []byte                 `protobuf:"bytes,6,opt,name=content,proto3" json:"content,omitempty"` // At most 3MB
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadDisputeEvidenceRequest) Reset() {
+	*x = UploadDisputeEvidenceRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadDisputeEvidenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadDisputeEvidenceRequest) ProtoMessage() {}
+
+func (x *UploadDisputeEvidenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadDisputeEvidenceRequest.ProtoReflect.Descriptor instead.
+func (*UploadDisputeEvidenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *UploadDisputeEvidenceRequest) GetDisputeId() string {
+	if x != nil {
+		return x.DisputeId
+	}
+	return ""
+}
+
+func (x *UploadDisputeEvidenceRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *UploadDisputeEvidenceRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UploadDisputeEvidenceRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *UploadDisputeEvidenceRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UploadDisputeEvidenceRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type GetDisputeEvidenceFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisputeId     string                 `protobuf:"bytes,1,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	FileId        string                 `protobuf:"bytes,3,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDisputeEvidenceFileRequest) Reset() {
+	*x = GetDisputeEvidenceFileRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDisputeEvidenceFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDisputeEvidenceFileRequest) ProtoMessage() {}
+
+func (x *GetDisputeEvidenceFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDisputeEvidenceFileRequest.ProtoReflect.Descriptor instead.
+func (*GetDisputeEvidenceFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetDisputeEvidenceFileRequest) GetDisputeId() string {
+	if x != nil {
+		return x.DisputeId
+	}
+	return ""
+}
+
+func (x *GetDisputeEvidenceFileRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *GetDisputeEvidenceFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type DisputeEvidenceFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *DisputeEvidenceFile   `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Content       []byte                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // Only set by GetDisputeEvidenceFile
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisputeEvidenceFileResponse) Reset() {
+	*x = DisputeEvidenceFileResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisputeEvidenceFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisputeEvidenceFileResponse) ProtoMessage() {}
+
+func (x *DisputeEvidenceFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisputeEvidenceFileResponse.ProtoReflect.Descriptor instead.
+func (*DisputeEvidenceFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DisputeEvidenceFileResponse) GetFile() *DisputeEvidenceFile {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *DisputeEvidenceFileResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *DisputeEvidenceFileResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SubmitDisputeEvidenceRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	DisputeId         string                 `protobuf:"bytes,1,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	MerchantId        string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Evidence          map[string]string      `protobuf:"bytes,3,rep,name=evidence,proto3" json:"evidence,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // e.g. tracking_number, customer_communication
+	MerchantStatement string                 `protobuf:"bytes,4,opt,name=merchant_statement,json=merchantStatement,proto3" json:"merchant_statement,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SubmitDisputeEvidenceRequest) Reset() {
+	*x = SubmitDisputeEvidenceRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitDisputeEvidenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitDisputeEvidenceRequest) ProtoMessage() {}
+
+func (x *SubmitDisputeEvidenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitDisputeEvidenceRequest.ProtoReflect.Descriptor instead.
+func (*SubmitDisputeEvidenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SubmitDisputeEvidenceRequest) GetDisputeId() string {
+	if x != nil {
+		return x.DisputeId
+	}
+	return ""
+}
+
+func (x *SubmitDisputeEvidenceRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *SubmitDisputeEvidenceRequest) GetEvidence() map[string]string {
+	if x != nil {
+		return x.Evidence
+	}
+	return nil
+}
+
+func (x *SubmitDisputeEvidenceRequest) GetMerchantStatement() string {
+	if x != nil {
+		return x.MerchantStatement
+	}
+	return ""
+}
+
+type AcceptDisputeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisputeId     string                 `protobuf:"bytes,1,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptDisputeRequest) Reset() {
+	*x = AcceptDisputeRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptDisputeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptDisputeRequest) ProtoMessage() {}
+
+func (x *AcceptDisputeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptDisputeRequest.ProtoReflect.Descriptor instead.
+func (*AcceptDisputeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *AcceptDisputeRequest) GetDisputeId() string {
+	if x != nil {
+		return x.DisputeId
+	}
+	return ""
+}
+
+func (x *AcceptDisputeRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *AcceptDisputeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type AddTransactionNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	AuthorId      string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"` // At most 4000 characters
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTransactionNoteRequest) Reset() {
+	*x = AddTransactionNoteRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTransactionNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTransactionNoteRequest) ProtoMessage() {}
+
+func (x *AddTransactionNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTransactionNoteRequest.ProtoReflect.Descriptor instead.
+func (*AddTransactionNoteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *AddTransactionNoteRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *AddTransactionNoteRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+func (x *AddTransactionNoteRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *AddTransactionNoteRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type TransactionNote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AuthorId      string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionNote) Reset() {
+	*x = TransactionNote{}
+	mi := &file_proto_transaction_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionNote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionNote) ProtoMessage() {}
+
+func (x *TransactionNote) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionNote.ProtoReflect.Descriptor instead.
+func (*TransactionNote) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *TransactionNote) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionNote) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *TransactionNote) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *TransactionNote) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *TransactionNote) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type TransactionNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *TransactionNote       `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionNoteResponse) Reset() {
+	*x = TransactionNoteResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionNoteResponse) ProtoMessage() {}
+
+func (x *TransactionNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionNoteResponse.ProtoReflect.Descriptor instead.
+func (*TransactionNoteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *TransactionNoteResponse) GetNote() *TransactionNote {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *TransactionNoteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListTransactionNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionNotesRequest) Reset() {
+	*x = ListTransactionNotesRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionNotesRequest) ProtoMessage() {}
+
+func (x *ListTransactionNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionNotesRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionNotesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ListTransactionNotesRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ListTransactionNotesRequest) GetMerchantId() string {
+	if x != nil {
+		return x.MerchantId
+	}
+	return ""
+}
+
+type ListTransactionNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notes         []*TransactionNote     `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"` // Oldest first
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionNotesResponse) Reset() {
+	*x = ListTransactionNotesResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionNotesResponse) ProtoMessage() {}
+
+func (x *ListTransactionNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionNotesResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionNotesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ListTransactionNotesResponse) GetNotes() []*TransactionNote {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+func (x *ListTransactionNotesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeleteTransactionNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteId        string                 `protobuf:"bytes,1,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,3,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTransactionNoteRequest) Reset() {
+	*x = DeleteTransactionNoteRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTransactionNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTransactionNoteRequest) ProtoMessage() {}
+
+func (x *DeleteTransactionNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -805,176 +4902,176 @@ func (x *TransactionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TransactionResponse.ProtoReflect.Descriptor instead.
-func (*TransactionResponse) Descriptor() ([]byte, []int) {
-	return file_proto_transaction_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use DeleteTransactionNoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTransactionNoteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{57}
 }
 
-func (x *TransactionResponse) GetId() string {
+func (x *DeleteTransactionNoteRequest) GetNoteId() string {
 	if x != nil {
-		return x.Id
+		return x.NoteId
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetMerchantId() string {
+func (x *DeleteTransactionNoteRequest) GetTransactionId() string {
 	if x != nil {
-		return x.MerchantId
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetType() string {
+func (x *DeleteTransactionNoteRequest) GetMerchantId() string {
 	if x != nil {
-		return x.Type
+		return x.MerchantId
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+type DeleteTransactionNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TransactionResponse) GetAmount() int64 {
-	if x != nil {
-		return x.Amount
-	}
-	return 0
+func (x *DeleteTransactionNoteResponse) Reset() {
+	*x = DeleteTransactionNoteResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *TransactionResponse) GetCurrency() string {
-	if x != nil {
-		return x.Currency
-	}
-	return ""
+func (x *DeleteTransactionNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *TransactionResponse) GetAmountMad() int64 {
-	if x != nil {
-		return x.AmountMad
-	}
-	return 0
-}
+func (*DeleteTransactionNoteResponse) ProtoMessage() {}
 
-func (x *TransactionResponse) GetExchangeRate() float64 {
+func (x *DeleteTransactionNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[58]
 	if x != nil {
-		return x.ExchangeRate
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *TransactionResponse) GetCardBrand() string {
-	if x != nil {
-		return x.CardBrand
-	}
-	return ""
+// Deprecated: Use DeleteTransactionNoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTransactionNoteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{58}
 }
 
-func (x *TransactionResponse) GetCardLast4() string {
+func (x *DeleteTransactionNoteResponse) GetDeleted() bool {
 	if x != nil {
-		return x.CardLast4
+		return x.Deleted
 	}
-	return ""
+	return false
 }
 
-func (x *TransactionResponse) GetAuthCode() string {
+func (x *DeleteTransactionNoteResponse) GetError() string {
 	if x != nil {
-		return x.AuthCode
+		return x.Error
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetFraudScore() int32 {
-	if x != nil {
-		return x.FraudScore
-	}
-	return 0
+type AddTransactionTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"` // Lowercase letters, digits, -, _ and :
+	CreatedBy     string                 `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TransactionResponse) GetCapturedAmount() int64 {
-	if x != nil {
-		return x.CapturedAmount
-	}
-	return 0
+func (x *AddTransactionTagsRequest) Reset() {
+	*x = AddTransactionTagsRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *TransactionResponse) GetRefundedAmount() int64 {
-	if x != nil {
-		return x.RefundedAmount
-	}
-	return 0
+func (x *AddTransactionTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *TransactionResponse) GetProcessingFee() int64 {
+func (*AddTransactionTagsRequest) ProtoMessage() {}
+
+func (x *AddTransactionTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[59]
 	if x != nil {
-		return x.ProcessingFee
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *TransactionResponse) GetNetAmount() int64 {
-	if x != nil {
-		return x.NetAmount
-	}
-	return 0
+// Deprecated: Use AddTransactionTagsRequest.ProtoReflect.Descriptor instead.
+func (*AddTransactionTagsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *TransactionResponse) GetCreatedAt() string {
+func (x *AddTransactionTagsRequest) GetTransactionId() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetAuthorizedAt() string {
+func (x *AddTransactionTagsRequest) GetMerchantId() string {
 	if x != nil {
-		return x.AuthorizedAt
+		return x.MerchantId
 	}
 	return ""
 }
 
-func (x *TransactionResponse) GetCapturedAt() string {
+func (x *AddTransactionTagsRequest) GetTags() []string {
 	if x != nil {
-		return x.CapturedAt
+		return x.Tags
 	}
-	return ""
+	return nil
 }
 
-func (x *TransactionResponse) GetError() string {
+func (x *AddTransactionTagsRequest) GetCreatedBy() string {
 	if x != nil {
-		return x.Error
+		return x.CreatedBy
 	}
 	return ""
 }
 
-type ListTransactionsRequest struct {
+type RemoveTransactionTagRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MerchantId    string                 `protobuf:"bytes,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	MerchantId    string                 `protobuf:"bytes,2,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListTransactionsRequest) Reset() {
-	*x = ListTransactionsRequest{}
-	mi := &file_proto_transaction_proto_msgTypes[10]
+func (x *RemoveTransactionTagRequest) Reset() {
+	*x = RemoveTransactionTagRequest{}
+	mi := &file_proto_transaction_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTransactionsRequest) String() string {
+func (x *RemoveTransactionTagRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTransactionsRequest) ProtoMessage() {}
+func (*RemoveTransactionTagRequest) ProtoMessage() {}
 
-func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_transaction_proto_msgTypes[10]
+func (x *RemoveTransactionTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -985,63 +5082,55 @@ func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
-func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_transaction_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RemoveTransactionTagRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTransactionTagRequest) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *ListTransactionsRequest) GetMerchantId() string {
+func (x *RemoveTransactionTagRequest) GetTransactionId() string {
 	if x != nil {
-		return x.MerchantId
+		return x.TransactionId
 	}
 	return ""
 }
 
-func (x *ListTransactionsRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-func (x *ListTransactionsRequest) GetOffset() int32 {
+func (x *RemoveTransactionTagRequest) GetMerchantId() string {
 	if x != nil {
-		return x.Offset
+		return x.MerchantId
 	}
-	return 0
+	return ""
 }
 
-func (x *ListTransactionsRequest) GetStatus() string {
+func (x *RemoveTransactionTagRequest) GetTag() string {
 	if x != nil {
-		return x.Status
+		return x.Tag
 	}
 	return ""
 }
 
-type ListTransactionsResponse struct {
+type TransactionTagsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transactions  []*TransactionResponse `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"` // All of the transaction's tags
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListTransactionsResponse) Reset() {
-	*x = ListTransactionsResponse{}
-	mi := &file_proto_transaction_proto_msgTypes[11]
+func (x *TransactionTagsResponse) Reset() {
+	*x = TransactionTagsResponse{}
+	mi := &file_proto_transaction_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListTransactionsResponse) String() string {
+func (x *TransactionTagsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListTransactionsResponse) ProtoMessage() {}
+func (*TransactionTagsResponse) ProtoMessage() {}
 
-func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_transaction_proto_msgTypes[11]
+func (x *TransactionTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_transaction_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1052,26 +5141,19 @@ func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
-func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_transaction_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use TransactionTagsResponse.ProtoReflect.Descriptor instead.
+func (*TransactionTagsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_transaction_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *ListTransactionsResponse) GetTransactions() []*TransactionResponse {
+func (x *TransactionTagsResponse) GetTags() []string {
 	if x != nil {
-		return x.Transactions
+		return x.Tags
 	}
 	return nil
 }
 
-func (x *ListTransactionsResponse) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *ListTransactionsResponse) GetError() string {
+func (x *TransactionTagsResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -1082,7 +5164,7 @@ var File_proto_transaction_proto protoreflect.FileDescriptor
 
 const file_proto_transaction_proto_rawDesc = "" +
 	"\n" +
-	"\x17proto/transaction.proto\x12\vtransaction\"\xec\x02\n" +
+	"\x17proto/transaction.proto\x12\vtransaction\"\xcf\x03\n" +
 	"\x10AuthorizeRequest\x12\x1f\n" +
 	"\vmerchant_id\x18\x01 \x01(\tR\n" +
 	"merchantId\x12\x16\n" +
@@ -1102,7 +5184,11 @@ const file_proto_transaction_proto_rawDesc = "" +
 	"ip_address\x18\n" +
 	" \x01(\tR\tipAddress\x12\x1d\n" +
 	"\n" +
-	"user_agent\x18\v \x01(\tR\tuserAgent\"\xba\x03\n" +
+	"user_agent\x18\v \x01(\tR\tuserAgent\x12 \n" +
+	"\fthree_ds_eci\x18\f \x01(\tR\n" +
+	"threeDsEci\x12\"\n" +
+	"\rthree_ds_cavv\x18\r \x01(\tR\vthreeDsCavv\x12\x1b\n" +
+	"\ttest_mode\x18\x0e \x01(\bR\btestMode\"\xa2\x04\n" +
 	"\x11AuthorizeResponse\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
@@ -1119,17 +5205,45 @@ const file_proto_transaction_proto_rawDesc = "" +
 	"\x0eprocessing_fee\x18\v \x01(\x03R\rprocessingFee\x12\x1d\n" +
 	"\n" +
 	"net_amount\x18\f \x01(\x03R\tnetAmount\x12\x14\n" +
-	"\x05error\x18\r \x01(\tR\x05error\"p\n" +
+	"\x05error\x18\r \x01(\tR\x05error\x12#\n" +
+	"\rretry_allowed\x18\x0e \x01(\bR\fretryAllowed\x12A\n" +
+	"\x1dsuggested_retry_after_seconds\x18\x0f \x01(\x03R\x1asuggestedRetryAfterSeconds\"\xb1\x01\n" +
 	"\x0eCaptureRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1f\n" +
 	"\vmerchant_id\x18\x03 \x01(\tR\n" +
-	"merchantId\"\xba\x01\n" +
+	"merchantId\x12#\n" +
+	"\rfinal_capture\x18\x04 \x01(\bR\ffinalCapture\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\"\xd7\x02\n" +
 	"\x0fCaptureResponse\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12'\n" +
 	"\x0fcaptured_amount\x18\x03 \x01(\x03R\x0ecapturedAmount\x12)\n" +
 	"\x10response_message\x18\x04 \x01(\tR\x0fresponseMessage\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"capture_id\x18\x06 \x01(\tR\tcaptureId\x122\n" +
+	"\x15total_captured_amount\x18\a \x01(\x03R\x13totalCapturedAmount\x12)\n" +
+	"\x10remaining_amount\x18\b \x01(\x03R\x0fremainingAmount\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\t \x01(\tR\terrorCode\"]\n" +
+	"\x13ListCapturesRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\xb3\x01\n" +
+	"\rCaptureRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x05R\bsequence\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12#\n" +
+	"\rfinal_capture\x18\x05 \x01(\bR\ffinalCapture\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\"\xe5\x01\n" +
+	"\x14ListCapturesResponse\x126\n" +
+	"\bcaptures\x18\x01 \x03(\v2\x1a.transaction.CaptureRecordR\bcaptures\x12+\n" +
+	"\x11authorized_amount\x18\x02 \x01(\x03R\x10authorizedAmount\x12'\n" +
+	"\x0fcaptured_amount\x18\x03 \x01(\x03R\x0ecapturedAmount\x12)\n" +
+	"\x10remaining_amount\x18\x04 \x01(\x03R\x0fremainingAmount\x12\x14\n" +
 	"\x05error\x18\x05 \x01(\tR\x05error\"m\n" +
 	"\vVoidRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
@@ -1140,24 +5254,31 @@ const file_proto_transaction_proto_rawDesc = "" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12)\n" +
 	"\x10response_message\x18\x03 \x01(\tR\x0fresponseMessage\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\"\x87\x01\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xc4\x01\n" +
 	"\rRefundRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
 	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x16\n" +
 	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1f\n" +
 	"\vmerchant_id\x18\x04 \x01(\tR\n" +
-	"merchantId\"\xe9\x01\n" +
+	"merchantId\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x1f\n" +
+	"\vreason_code\x18\x06 \x01(\tR\n" +
+	"reasonCode\"\xdf\x02\n" +
 	"\x0eRefundResponse\x12\x1b\n" +
 	"\trefund_id\x18\x01 \x01(\tR\brefundId\x12%\n" +
 	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12'\n" +
 	"\x0frefunded_amount\x18\x03 \x01(\x03R\x0erefundedAmount\x12)\n" +
 	"\x10remaining_amount\x18\x04 \x01(\x03R\x0fremainingAmount\x12)\n" +
 	"\x10response_message\x18\x05 \x01(\tR\x0fresponseMessage\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"_\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12#\n" +
+	"\rrefund_status\x18\a \x01(\tR\frefundStatus\x120\n" +
+	"\x14estimated_arrival_at\x18\b \x01(\tR\x12estimatedArrivalAt\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\t \x01(\tR\terrorCode\"_\n" +
 	"\x15GetTransactionRequest\x12%\n" +
 	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
 	"\vmerchant_id\x18\x02 \x01(\tR\n" +
-	"merchantId\"\xf9\x04\n" +
+	"merchantId\"\x8b\x06\n" +
 	"\x13TransactionResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
 	"\vmerchant_id\x18\x02 \x01(\tR\n" +
@@ -1187,24 +5308,431 @@ const file_proto_transaction_proto_rawDesc = "" +
 	"\rauthorized_at\x18\x12 \x01(\tR\fauthorizedAt\x12\x1f\n" +
 	"\vcaptured_at\x18\x13 \x01(\tR\n" +
 	"capturedAt\x12\x14\n" +
-	"\x05error\x18\x14 \x01(\tR\x05error\"\x80\x01\n" +
+	"\x05error\x18\x14 \x01(\tR\x05error\x12\x1b\n" +
+	"\tvoided_at\x18\x15 \x01(\tR\bvoidedAt\x12#\n" +
+	"\rvoided_reason\x18\x16 \x01(\tR\fvoidedReason\x12\x12\n" +
+	"\x04tags\x18\x17 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"note_count\x18\x18 \x01(\x05R\tnoteCount\x12\x1b\n" +
+	"\ttest_mode\x18\x19 \x01(\bR\btestMode\"\xf6\x02\n" +
 	"\x17ListTransactionsRequest\x12\x1f\n" +
 	"\vmerchant_id\x18\x01 \x01(\tR\n" +
 	"merchantId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
 	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\"\x8c\x01\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12!\n" +
+	"\fcreated_from\x18\x05 \x01(\tR\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\x06 \x01(\tR\tcreatedTo\x12\x17\n" +
+	"\asort_by\x18\a \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\b \x01(\tR\tsortOrder\x12\x12\n" +
+	"\x04type\x18\t \x01(\tR\x04type\x12#\n" +
+	"\rvoided_reason\x18\n" +
+	" \x01(\tR\fvoidedReason\x12\x10\n" +
+	"\x03tag\x18\v \x01(\tR\x03tag\x12\x12\n" +
+	"\x04note\x18\f \x01(\tR\x04note\x12\x1b\n" +
+	"\ttest_mode\x18\r \x01(\bR\btestMode\"\xd5\x01\n" +
 	"\x18ListTransactionsResponse\x12D\n" +
 	"\ftransactions\x18\x01 \x03(\v2 .transaction.TransactionResponseR\ftransactions\x12\x14\n" +
 	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error2\xdf\x03\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x05R\x06offset\"W\n" +
+	"\x19GetSettlementBatchRequest\x12\x19\n" +
+	"\bbatch_id\x18\x01 \x01(\tR\abatchId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\xac\x05\n" +
+	"\x17SettlementBatchResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x1d\n" +
+	"\n" +
+	"batch_date\x18\x03 \x01(\tR\tbatchDate\x12!\n" +
+	"\fgross_amount\x18\x04 \x01(\x03R\vgrossAmount\x12#\n" +
+	"\rrefund_amount\x18\x05 \x01(\x03R\frefundAmount\x12\x1d\n" +
+	"\n" +
+	"fee_amount\x18\x06 \x01(\x03R\tfeeAmount\x12\x1d\n" +
+	"\n" +
+	"net_amount\x18\a \x01(\x03R\tnetAmount\x12+\n" +
+	"\x11transaction_count\x18\b \x01(\x05R\x10transactionCount\x12!\n" +
+	"\frefund_count\x18\t \x01(\x05R\vrefundCount\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12'\n" +
+	"\x0fsettlement_date\x18\v \x01(\tR\x0esettlementDate\x12)\n" +
+	"\x10reference_number\x18\f \x01(\tR\x0freferenceNumber\x12\x1d\n" +
+	"\n" +
+	"settled_at\x18\r \x01(\tR\tsettledAt\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\x12.\n" +
+	"\x13fee_reversal_amount\x18\x0f \x01(\x03R\x11feeReversalAmount\x12%\n" +
+	"\x0einstant_payout\x18\x10 \x01(\bR\rinstantPayout\x12\x1d\n" +
+	"\n" +
+	"payout_fee\x18\x11 \x01(\x03R\tpayoutFee\x12%\n" +
+	"\x0ereserve_amount\x18\x12 \x01(\x03R\rreserveAmount\x12-\n" +
+	"\x12balance_adjustment\x18\x13 \x01(\x03R\x11balanceAdjustment\"\x8d\x01\n" +
+	"\x1cListSettlementBatchesRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1b\n" +
+	"\tdate_from\x18\x02 \x01(\tR\bdateFrom\x12\x17\n" +
+	"\adate_to\x18\x03 \x01(\tR\x06dateTo\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"u\n" +
+	"\x1dListSettlementBatchesResponse\x12>\n" +
+	"\abatches\x18\x01 \x03(\v2$.transaction.SettlementBatchResponseR\abatches\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x1aCreateInstantPayoutRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xe9\x02\n" +
+	"\x15InstantPayoutResponse\x12\x1a\n" +
+	"\beligible\x18\x01 \x01(\bR\beligible\x12\x18\n" +
+	"\areasons\x18\x02 \x03(\tR\areasons\x12,\n" +
+	"\x12chargeback_rate_bp\x18\x03 \x01(\x03R\x10chargebackRateBp\x12)\n" +
+	"\x10available_amount\x18\x04 \x01(\x03R\x0favailableAmount\x12\x1d\n" +
+	"\n" +
+	"payout_fee\x18\x05 \x01(\x03R\tpayoutFee\x12#\n" +
+	"\rpayout_amount\x18\x06 \x01(\x03R\fpayoutAmount\x12+\n" +
+	"\x11transaction_count\x18\a \x01(\x05R\x10transactionCount\x12:\n" +
+	"\x05batch\x18\b \x01(\v2$.transaction.SettlementBatchResponseR\x05batch\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"4\n" +
+	"\x11GetBalanceRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\"\xb3\x02\n" +
+	"\x0fBalanceResponse\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\x03R\tavailable\x12\x18\n" +
+	"\areserve\x18\x04 \x01(\x03R\areserve\x12\x18\n" +
+	"\apending\x18\x05 \x01(\x03R\apending\x12#\n" +
+	"\rpending_count\x18\x06 \x01(\x05R\fpendingCount\x12&\n" +
+	"\x0fnext_release_at\x18\a \x01(\tR\rnextReleaseAt\x12.\n" +
+	"\x13next_release_amount\x18\b \x01(\x03R\x11nextReleaseAmount\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"\x94\x01\n" +
+	"\x14GetStatisticsRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1b\n" +
+	"\ttest_mode\x18\x02 \x01(\bR\btestMode\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\x12\x1a\n" +
+	"\binterval\x18\x05 \x01(\tR\binterval\"\x83\x03\n" +
+	"\x15TransactionStatistics\x12-\n" +
+	"\x12total_transactions\x18\x01 \x01(\x03R\x11totalTransactions\x12!\n" +
+	"\ftotal_amount\x18\x02 \x01(\x03R\vtotalAmount\x12+\n" +
+	"\x11authorized_amount\x18\x03 \x01(\x03R\x10authorizedAmount\x12'\n" +
+	"\x0fcaptured_amount\x18\x04 \x01(\x03R\x0ecapturedAmount\x12'\n" +
+	"\x0frefunded_amount\x18\x05 \x01(\x03R\x0erefundedAmount\x12%\n" +
+	"\x0esettled_amount\x18\x06 \x01(\x03R\rsettledAmount\x12!\n" +
+	"\fsuccess_rate\x18\a \x01(\x01R\vsuccessRate\x12\x1f\n" +
+	"\vrefund_rate\x18\b \x01(\x01R\n" +
+	"refundRate\x12.\n" +
+	"\x13average_fraud_score\x18\t \x01(\x01R\x11averageFraudScore\"j\n" +
+	"\x10StatisticsBucket\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12B\n" +
+	"\n" +
+	"statistics\x18\x02 \x01(\v2\".transaction.TransactionStatisticsR\n" +
+	"statistics\"\xe3\x01\n" +
+	"\x12StatisticsResponse\x12\x1a\n" +
+	"\btimezone\x18\x01 \x01(\tR\btimezone\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\tR\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\tR\x03end\x12:\n" +
+	"\x06totals\x18\x04 \x01(\v2\".transaction.TransactionStatisticsR\x06totals\x127\n" +
+	"\abuckets\x18\x05 \x03(\v2\x1d.transaction.StatisticsBucketR\abuckets\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"P\n" +
+	"\x10GetRefundRequest\x12\x1b\n" +
+	"\trefund_id\x18\x01 \x01(\tR\brefundId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\\\n" +
+	"\x12ListRefundsRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\x94\x03\n" +
+	"\x14RefundDetailResponse\x12\x1b\n" +
+	"\trefund_id\x18\x01 \x01(\tR\brefundId\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12!\n" +
+	"\frequested_at\x18\a \x01(\tR\vrequestedAt\x12)\n" +
+	"\x11sent_to_issuer_at\x18\b \x01(\tR\x0esentToIssuerAt\x12\x1d\n" +
+	"\n" +
+	"settled_at\x18\t \x01(\tR\tsettledAt\x120\n" +
+	"\x14estimated_arrival_at\x18\n" +
+	" \x01(\tR\x12estimatedArrivalAt\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\x12\x1f\n" +
+	"\vreason_code\x18\f \x01(\tR\n" +
+	"reasonCode\"h\n" +
+	"\x13ListRefundsResponse\x12;\n" +
+	"\arefunds\x18\x01 \x03(\v2!.transaction.RefundDetailResponseR\arefunds\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xaa\x02\n" +
+	"\x1aListMerchantRefundsRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1f\n" +
+	"\vreason_code\x18\x04 \x01(\tR\n" +
+	"reasonCode\x12!\n" +
+	"\fcreated_from\x18\x05 \x01(\tR\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\x06 \x01(\tR\tcreatedTo\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\b \x01(\x05R\x06offset\x12\x1b\n" +
+	"\ttest_mode\x18\t \x01(\bR\btestMode\"\xa1\x01\n" +
+	"\x1bListMerchantRefundsResponse\x12;\n" +
+	"\arefunds\x18\x01 \x03(\v2!.transaction.RefundDetailResponseR\arefunds\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\">\n" +
+	"\x1bGetRefundQueueStatusRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\"\xf7\x01\n" +
+	"\x19RefundQueueStatusResponse\x12\x16\n" +
+	"\x06queued\x18\x01 \x01(\x03R\x06queued\x12\x1e\n" +
+	"\n" +
+	"processing\x18\x02 \x01(\x03R\n" +
+	"processing\x12\x12\n" +
+	"\x04sent\x18\x03 \x01(\x03R\x04sent\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x03R\x06failed\x12(\n" +
+	"\x10oldest_queued_at\x18\x05 \x01(\tR\x0eoldestQueuedAt\x126\n" +
+	"\x17estimated_drain_seconds\x18\x06 \x01(\x03R\x15estimatedDrainSeconds\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"g\n" +
+	"\x1dGetTransactionTimelineRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\xe9\x01\n" +
+	"\x18TransactionTimelineEvent\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12\x1d\n" +
+	"\n" +
+	"old_status\x18\x02 \x01(\tR\toldStatus\x12\x1d\n" +
+	"\n" +
+	"new_status\x18\x03 \x01(\tR\tnewStatus\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bmetadata\x18\x05 \x01(\tR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x06 \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\"\xd1\x02\n" +
+	"\x14IssuerResponseRecord\x12\x1a\n" +
+	"\bapproved\x18\x01 \x01(\bR\bapproved\x12\x1b\n" +
+	"\tauth_code\x18\x02 \x01(\tR\bauthCode\x12#\n" +
+	"\rresponse_code\x18\x03 \x01(\tR\fresponseCode\x12)\n" +
+	"\x10response_message\x18\x04 \x01(\tR\x0fresponseMessage\x12%\n" +
+	"\x0edecline_reason\x18\x05 \x01(\tR\rdeclineReason\x12\x1d\n" +
+	"\n" +
+	"avs_result\x18\x06 \x01(\tR\tavsResult\x12\x1d\n" +
+	"\n" +
+	"cvv_result\x18\a \x01(\tR\tcvvResult\x12,\n" +
+	"\x12processing_time_ms\x18\b \x01(\x05R\x10processingTimeMs\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\"\xaf\x02\n" +
+	"\x1bTransactionTimelineResponse\x12B\n" +
+	"\vtransaction\x18\x01 \x01(\v2 .transaction.TransactionResponseR\vtransaction\x12=\n" +
+	"\x06events\x18\x02 \x03(\v2%.transaction.TransactionTimelineEventR\x06events\x12L\n" +
+	"\x10issuer_responses\x18\x03 \x03(\v2!.transaction.IssuerResponseRecordR\x0fissuerResponses\x12)\n" +
+	"\x10routing_decision\x18\x04 \x01(\tR\x0froutingDecision\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xe6\x01\n" +
+	"\x13AuthenticateRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x1d\n" +
+	"\n" +
+	"card_token\x18\x02 \x01(\tR\tcardToken\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x03 \x01(\tR\tcardBrand\x12\x1d\n" +
+	"\n" +
+	"card_last4\x18\x04 \x01(\tR\tcardLast4\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x1d\n" +
+	"\n" +
+	"return_url\x18\a \x01(\tR\treturnUrl\"\xec\x01\n" +
+	"\x14AuthenticateResponse\x12!\n" +
+	"\ftrans_status\x18\x01 \x01(\tR\vtransStatus\x12\x10\n" +
+	"\x03eci\x18\x02 \x01(\tR\x03eci\x12\x12\n" +
+	"\x04cavv\x18\x03 \x01(\tR\x04cavv\x12*\n" +
+	"\x11ds_transaction_id\x18\x04 \x01(\tR\x0fdsTransactionId\x12\x17\n" +
+	"\aacs_url\x18\x05 \x01(\tR\x06acsUrl\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\"\x9f\x01\n" +
+	"\x1dCompleteAuthenticationRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12*\n" +
+	"\x11ds_transaction_id\x18\x02 \x01(\tR\x0fdsTransactionId\x12\x1d\n" +
+	"\n" +
+	"card_brand\x18\x03 \x01(\tR\tcardBrand\x12\x12\n" +
+	"\x04cres\x18\x04 \x01(\tR\x04cres\"\xa3\x01\n" +
+	"\x13ListDisputesRequest\x12\x1f\n" +
+	"\vmerchant_id\x18\x01 \x01(\tR\n" +
+	"merchantId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12%\n" +
+	"\x0etransaction_id\x18\x05 \x01(\tR\rtransactionId\"\x97\x01\n" +
+	"\x14ListDisputesResponse\x128\n" +
+	"\bdisputes\x18\x01 \x03(\v2\x1c.transaction.DisputeResponseR\bdisputes\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"S\n" +
+	"\x11GetDisputeRequest\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x01 \x01(\tR\tdisputeId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"\xdd\x01\n" +
+	"\x13DisputeEvidenceFile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\"\x9b\x06\n" +
+	"\x0fDisputeResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x03 \x01(\tR\n" +
+	"merchantId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vreason_code\x18\x06 \x01(\tR\n" +
+	"reasonCode\x12\x16\n" +
+	"\x06amount\x18\a \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12%\n" +
+	"\x0echargeback_fee\x18\t \x01(\x03R\rchargebackFee\x12\x19\n" +
+	"\bnet_loss\x18\n" +
+	" \x01(\x03R\anetLoss\x12-\n" +
+	"\x12customer_statement\x18\v \x01(\tR\x11customerStatement\x12\x1f\n" +
+	"\vdisputed_at\x18\f \x01(\tR\n" +
+	"disputedAt\x12&\n" +
+	"\x0fresponse_due_at\x18\r \x01(\tR\rresponseDueAt\x12\x18\n" +
+	"\aoverdue\x18\x0e \x01(\bR\aoverdue\x12*\n" +
+	"\x11seconds_until_due\x18\x0f \x01(\x03R\x0fsecondsUntilDue\x122\n" +
+	"\x15response_submitted_at\x18\x10 \x01(\tR\x13responseSubmittedAt\x12+\n" +
+	"\x11merchant_evidence\x18\x11 \x01(\tR\x10merchantEvidence\x12G\n" +
+	"\x0eevidence_files\x18\x12 \x03(\v2 .transaction.DisputeEvidenceFileR\revidenceFiles\x12+\n" +
+	"\x11resolution_reason\x18\x13 \x01(\tR\x10resolutionReason\x12\x1f\n" +
+	"\vresolved_at\x18\x14 \x01(\tR\n" +
+	"resolvedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x15 \x01(\tR\tcreatedAt\x12\x14\n" +
+	"\x05error\x18\x16 \x01(\tR\x05error\"\xda\x01\n" +
+	"\x1cUploadDisputeEvidenceRequest\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x01 \x01(\tR\tdisputeId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x1b\n" +
+	"\tfile_name\x18\x03 \x01(\tR\bfileName\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x18\n" +
+	"\acontent\x18\x06 \x01(\fR\acontent\"x\n" +
+	"\x1dGetDisputeEvidenceFileRequest\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x01 \x01(\tR\tdisputeId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x17\n" +
+	"\afile_id\x18\x03 \x01(\tR\x06fileId\"\x83\x01\n" +
+	"\x1bDisputeEvidenceFileResponse\x124\n" +
+	"\x04file\x18\x01 \x01(\v2 .transaction.DisputeEvidenceFileR\x04file\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x9f\x02\n" +
+	"\x1cSubmitDisputeEvidenceRequest\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x01 \x01(\tR\tdisputeId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12S\n" +
+	"\bevidence\x18\x03 \x03(\v27.transaction.SubmitDisputeEvidenceRequest.EvidenceEntryR\bevidence\x12-\n" +
+	"\x12merchant_statement\x18\x04 \x01(\tR\x11merchantStatement\x1a;\n" +
+	"\rEvidenceEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\x14AcceptDisputeRequest\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x01 \x01(\tR\tdisputeId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x94\x01\n" +
+	"\x19AddTransactionNoteRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\"\x98\x01\n" +
+	"\x0fTransactionNote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"a\n" +
+	"\x17TransactionNoteResponse\x120\n" +
+	"\x04note\x18\x01 \x01(\v2\x1c.transaction.TransactionNoteR\x04note\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"e\n" +
+	"\x1bListTransactionNotesRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\"h\n" +
+	"\x1cListTransactionNotesResponse\x122\n" +
+	"\x05notes\x18\x01 \x03(\v2\x1c.transaction.TransactionNoteR\x05notes\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x7f\n" +
+	"\x1cDeleteTransactionNoteRequest\x12\x17\n" +
+	"\anote_id\x18\x01 \x01(\tR\x06noteId\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x03 \x01(\tR\n" +
+	"merchantId\"O\n" +
+	"\x1dDeleteTransactionNoteResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\bR\adeleted\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x96\x01\n" +
+	"\x19AddTransactionTagsRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x04 \x01(\tR\tcreatedBy\"w\n" +
+	"\x1bRemoveTransactionTagRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x1f\n" +
+	"\vmerchant_id\x18\x02 \x01(\tR\n" +
+	"merchantId\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\"C\n" +
+	"\x17TransactionTagsResponse\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xbd\x11\n" +
 	"\x12TransactionService\x12J\n" +
 	"\tAuthorize\x12\x1d.transaction.AuthorizeRequest\x1a\x1e.transaction.AuthorizeResponse\x12D\n" +
-	"\aCapture\x12\x1b.transaction.CaptureRequest\x1a\x1c.transaction.CaptureResponse\x12;\n" +
+	"\aCapture\x12\x1b.transaction.CaptureRequest\x1a\x1c.transaction.CaptureResponse\x12S\n" +
+	"\fListCaptures\x12 .transaction.ListCapturesRequest\x1a!.transaction.ListCapturesResponse\x12;\n" +
 	"\x04Void\x12\x18.transaction.VoidRequest\x1a\x19.transaction.VoidResponse\x12A\n" +
 	"\x06Refund\x12\x1a.transaction.RefundRequest\x1a\x1b.transaction.RefundResponse\x12V\n" +
 	"\x0eGetTransaction\x12\".transaction.GetTransactionRequest\x1a .transaction.TransactionResponse\x12_\n" +
-	"\x10ListTransactions\x12$.transaction.ListTransactionsRequest\x1a%.transaction.ListTransactionsResponseB?Z=github.com/rhaloubi/payment-gateway/transaction-service/protob\x06proto3"
+	"\x10ListTransactions\x12$.transaction.ListTransactionsRequest\x1a%.transaction.ListTransactionsResponse\x12b\n" +
+	"\x12GetSettlementBatch\x12&.transaction.GetSettlementBatchRequest\x1a$.transaction.SettlementBatchResponse\x12n\n" +
+	"\x15ListSettlementBatches\x12).transaction.ListSettlementBatchesRequest\x1a*.transaction.ListSettlementBatchesResponse\x12b\n" +
+	"\x13CreateInstantPayout\x12'.transaction.CreateInstantPayoutRequest\x1a\".transaction.InstantPayoutResponse\x12J\n" +
+	"\n" +
+	"GetBalance\x12\x1e.transaction.GetBalanceRequest\x1a\x1c.transaction.BalanceResponse\x12S\n" +
+	"\rGetStatistics\x12!.transaction.GetStatisticsRequest\x1a\x1f.transaction.StatisticsResponse\x12M\n" +
+	"\tGetRefund\x12\x1d.transaction.GetRefundRequest\x1a!.transaction.RefundDetailResponse\x12P\n" +
+	"\vListRefunds\x12\x1f.transaction.ListRefundsRequest\x1a .transaction.ListRefundsResponse\x12h\n" +
+	"\x13ListMerchantRefunds\x12'.transaction.ListMerchantRefundsRequest\x1a(.transaction.ListMerchantRefundsResponse\x12h\n" +
+	"\x14GetRefundQueueStatus\x12(.transaction.GetRefundQueueStatusRequest\x1a&.transaction.RefundQueueStatusResponse\x12n\n" +
+	"\x16GetTransactionTimeline\x12*.transaction.GetTransactionTimelineRequest\x1a(.transaction.TransactionTimelineResponse\x12S\n" +
+	"\fAuthenticate\x12 .transaction.AuthenticateRequest\x1a!.transaction.AuthenticateResponse\x12g\n" +
+	"\x16CompleteAuthentication\x12*.transaction.CompleteAuthenticationRequest\x1a!.transaction.AuthenticateResponse\x12b\n" +
+	"\x12AddTransactionNote\x12&.transaction.AddTransactionNoteRequest\x1a$.transaction.TransactionNoteResponse\x12k\n" +
+	"\x14ListTransactionNotes\x12(.transaction.ListTransactionNotesRequest\x1a).transaction.ListTransactionNotesResponse\x12n\n" +
+	"\x15DeleteTransactionNote\x12).transaction.DeleteTransactionNoteRequest\x1a*.transaction.DeleteTransactionNoteResponse\x12b\n" +
+	"\x12AddTransactionTags\x12&.transaction.AddTransactionTagsRequest\x1a$.transaction.TransactionTagsResponse\x12f\n" +
+	"\x14RemoveTransactionTag\x12(.transaction.RemoveTransactionTagRequest\x1a$.transaction.TransactionTagsResponse2\xc6\x04\n" +
+	"\x11ChargebackService\x12S\n" +
+	"\fListDisputes\x12 .transaction.ListDisputesRequest\x1a!.transaction.ListDisputesResponse\x12J\n" +
+	"\n" +
+	"GetDispute\x12\x1e.transaction.GetDisputeRequest\x1a\x1c.transaction.DisputeResponse\x12l\n" +
+	"\x15UploadDisputeEvidence\x12).transaction.UploadDisputeEvidenceRequest\x1a(.transaction.DisputeEvidenceFileResponse\x12n\n" +
+	"\x16GetDisputeEvidenceFile\x12*.transaction.GetDisputeEvidenceFileRequest\x1a(.transaction.DisputeEvidenceFileResponse\x12`\n" +
+	"\x15SubmitDisputeEvidence\x12).transaction.SubmitDisputeEvidenceRequest\x1a\x1c.transaction.DisputeResponse\x12P\n" +
+	"\rAcceptDispute\x12!.transaction.AcceptDisputeRequest\x1a\x1c.transaction.DisputeResponseB?Z=github.com/rhaloubi/payment-gateway/transaction-service/protob\x06proto3"
 
 var (
 	file_proto_transaction_proto_rawDescOnce sync.Once
@@ -1218,40 +5746,156 @@ func file_proto_transaction_proto_rawDescGZIP() []byte {
 	return file_proto_transaction_proto_rawDescData
 }
 
-var file_proto_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 63)
 var file_proto_transaction_proto_goTypes = []any{
-	(*AuthorizeRequest)(nil),         // 0: transaction.AuthorizeRequest
-	(*AuthorizeResponse)(nil),        // 1: transaction.AuthorizeResponse
-	(*CaptureRequest)(nil),           // 2: transaction.CaptureRequest
-	(*CaptureResponse)(nil),          // 3: transaction.CaptureResponse
-	(*VoidRequest)(nil),              // 4: transaction.VoidRequest
-	(*VoidResponse)(nil),             // 5: transaction.VoidResponse
-	(*RefundRequest)(nil),            // 6: transaction.RefundRequest
-	(*RefundResponse)(nil),           // 7: transaction.RefundResponse
-	(*GetTransactionRequest)(nil),    // 8: transaction.GetTransactionRequest
-	(*TransactionResponse)(nil),      // 9: transaction.TransactionResponse
-	(*ListTransactionsRequest)(nil),  // 10: transaction.ListTransactionsRequest
-	(*ListTransactionsResponse)(nil), // 11: transaction.ListTransactionsResponse
+	(*AuthorizeRequest)(nil),              // 0: transaction.AuthorizeRequest
+	(*AuthorizeResponse)(nil),             // 1: transaction.AuthorizeResponse
+	(*CaptureRequest)(nil),                // 2: transaction.CaptureRequest
+	(*CaptureResponse)(nil),               // 3: transaction.CaptureResponse
+	(*ListCapturesRequest)(nil),           // 4: transaction.ListCapturesRequest
+	(*CaptureRecord)(nil),                 // 5: transaction.CaptureRecord
+	(*ListCapturesResponse)(nil),          // 6: transaction.ListCapturesResponse
+	(*VoidRequest)(nil),                   // 7: transaction.VoidRequest
+	(*VoidResponse)(nil),                  // 8: transaction.VoidResponse
+	(*RefundRequest)(nil),                 // 9: transaction.RefundRequest
+	(*RefundResponse)(nil),                // 10: transaction.RefundResponse
+	(*GetTransactionRequest)(nil),         // 11: transaction.GetTransactionRequest
+	(*TransactionResponse)(nil),           // 12: transaction.TransactionResponse
+	(*ListTransactionsRequest)(nil),       // 13: transaction.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),      // 14: transaction.ListTransactionsResponse
+	(*GetSettlementBatchRequest)(nil),     // 15: transaction.GetSettlementBatchRequest
+	(*SettlementBatchResponse)(nil),       // 16: transaction.SettlementBatchResponse
+	(*ListSettlementBatchesRequest)(nil),  // 17: transaction.ListSettlementBatchesRequest
+	(*ListSettlementBatchesResponse)(nil), // 18: transaction.ListSettlementBatchesResponse
+	(*CreateInstantPayoutRequest)(nil),    // 19: transaction.CreateInstantPayoutRequest
+	(*InstantPayoutResponse)(nil),         // 20: transaction.InstantPayoutResponse
+	(*GetBalanceRequest)(nil),             // 21: transaction.GetBalanceRequest
+	(*BalanceResponse)(nil),               // 22: transaction.BalanceResponse
+	(*GetStatisticsRequest)(nil),          // 23: transaction.GetStatisticsRequest
+	(*TransactionStatistics)(nil),         // 24: transaction.TransactionStatistics
+	(*StatisticsBucket)(nil),              // 25: transaction.StatisticsBucket
+	(*StatisticsResponse)(nil),            // 26: transaction.StatisticsResponse
+	(*GetRefundRequest)(nil),              // 27: transaction.GetRefundRequest
+	(*ListRefundsRequest)(nil),            // 28: transaction.ListRefundsRequest
+	(*RefundDetailResponse)(nil),          // 29: transaction.RefundDetailResponse
+	(*ListRefundsResponse)(nil),           // 30: transaction.ListRefundsResponse
+	(*ListMerchantRefundsRequest)(nil),    // 31: transaction.ListMerchantRefundsRequest
+	(*ListMerchantRefundsResponse)(nil),   // 32: transaction.ListMerchantRefundsResponse
+	(*GetRefundQueueStatusRequest)(nil),   // 33: transaction.GetRefundQueueStatusRequest
+	(*RefundQueueStatusResponse)(nil),     // 34: transaction.RefundQueueStatusResponse
+	(*GetTransactionTimelineRequest)(nil), // 35: transaction.GetTransactionTimelineRequest
+	(*TransactionTimelineEvent)(nil),      // 36: transaction.TransactionTimelineEvent
+	(*IssuerResponseRecord)(nil),          // 37: transaction.IssuerResponseRecord
+	(*TransactionTimelineResponse)(nil),   // 38: transaction.TransactionTimelineResponse
+	(*AuthenticateRequest)(nil),           // 39: transaction.AuthenticateRequest
+	(*AuthenticateResponse)(nil),          // 40: transaction.AuthenticateResponse
+	(*CompleteAuthenticationRequest)(nil), // 41: transaction.CompleteAuthenticationRequest
+	(*ListDisputesRequest)(nil),           // 42: transaction.ListDisputesRequest
+	(*ListDisputesResponse)(nil),          // 43: transaction.ListDisputesResponse
+	(*GetDisputeRequest)(nil),             // 44: transaction.GetDisputeRequest
+	(*DisputeEvidenceFile)(nil),           // 45: transaction.DisputeEvidenceFile
+	(*DisputeResponse)(nil),               // 46: transaction.DisputeResponse
+	(*UploadDisputeEvidenceRequest)(nil),  // 47: transaction.UploadDisputeEvidenceRequest
+	(*GetDisputeEvidenceFileRequest)(nil), // 48: transaction.GetDisputeEvidenceFileRequest
+	(*DisputeEvidenceFileResponse)(nil),   // 49: transaction.DisputeEvidenceFileResponse
+	(*SubmitDisputeEvidenceRequest)(nil),  // 50: transaction.SubmitDisputeEvidenceRequest
+	(*AcceptDisputeRequest)(nil),          // 51: transaction.AcceptDisputeRequest
+	(*AddTransactionNoteRequest)(nil),     // 52: transaction.AddTransactionNoteRequest
+	(*TransactionNote)(nil),               // 53: transaction.TransactionNote
+	(*TransactionNoteResponse)(nil),       // 54: transaction.TransactionNoteResponse
+	(*ListTransactionNotesRequest)(nil),   // 55: transaction.ListTransactionNotesRequest
+	(*ListTransactionNotesResponse)(nil),  // 56: transaction.ListTransactionNotesResponse
+	(*DeleteTransactionNoteRequest)(nil),  // 57: transaction.DeleteTransactionNoteRequest
+	(*DeleteTransactionNoteResponse)(nil), // 58: transaction.DeleteTransactionNoteResponse
+	(*AddTransactionTagsRequest)(nil),     // 59: transaction.AddTransactionTagsRequest
+	(*RemoveTransactionTagRequest)(nil),   // 60: transaction.RemoveTransactionTagRequest
+	(*TransactionTagsResponse)(nil),       // 61: transaction.TransactionTagsResponse
+	nil,                                   // 62: transaction.SubmitDisputeEvidenceRequest.EvidenceEntry
 }
 var file_proto_transaction_proto_depIdxs = []int32{
-	9,  // 0: transaction.ListTransactionsResponse.transactions:type_name -> transaction.TransactionResponse
-	0,  // 1: transaction.TransactionService.Authorize:input_type -> transaction.AuthorizeRequest
-	2,  // 2: transaction.TransactionService.Capture:input_type -> transaction.CaptureRequest
-	4,  // 3: transaction.TransactionService.Void:input_type -> transaction.VoidRequest
-	6,  // 4: transaction.TransactionService.Refund:input_type -> transaction.RefundRequest
-	8,  // 5: transaction.TransactionService.GetTransaction:input_type -> transaction.GetTransactionRequest
-	10, // 6: transaction.TransactionService.ListTransactions:input_type -> transaction.ListTransactionsRequest
-	1,  // 7: transaction.TransactionService.Authorize:output_type -> transaction.AuthorizeResponse
-	3,  // 8: transaction.TransactionService.Capture:output_type -> transaction.CaptureResponse
-	5,  // 9: transaction.TransactionService.Void:output_type -> transaction.VoidResponse
-	7,  // 10: transaction.TransactionService.Refund:output_type -> transaction.RefundResponse
-	9,  // 11: transaction.TransactionService.GetTransaction:output_type -> transaction.TransactionResponse
-	11, // 12: transaction.TransactionService.ListTransactions:output_type -> transaction.ListTransactionsResponse
-	7,  // [7:13] is the sub-list for method output_type
-	1,  // [1:7] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	5,  // 0: transaction.ListCapturesResponse.captures:type_name -> transaction.CaptureRecord
+	12, // 1: transaction.ListTransactionsResponse.transactions:type_name -> transaction.TransactionResponse
+	16, // 2: transaction.ListSettlementBatchesResponse.batches:type_name -> transaction.SettlementBatchResponse
+	16, // 3: transaction.InstantPayoutResponse.batch:type_name -> transaction.SettlementBatchResponse
+	24, // 4: transaction.StatisticsBucket.statistics:type_name -> transaction.TransactionStatistics
+	24, // 5: transaction.StatisticsResponse.totals:type_name -> transaction.TransactionStatistics
+	25, // 6: transaction.StatisticsResponse.buckets:type_name -> transaction.StatisticsBucket
+	29, // 7: transaction.ListRefundsResponse.refunds:type_name -> transaction.RefundDetailResponse
+	29, // 8: transaction.ListMerchantRefundsResponse.refunds:type_name -> transaction.RefundDetailResponse
+	12, // 9: transaction.TransactionTimelineResponse.transaction:type_name -> transaction.TransactionResponse
+	36, // 10: transaction.TransactionTimelineResponse.events:type_name -> transaction.TransactionTimelineEvent
+	37, // 11: transaction.TransactionTimelineResponse.issuer_responses:type_name -> transaction.IssuerResponseRecord
+	46, // 12: transaction.ListDisputesResponse.disputes:type_name -> transaction.DisputeResponse
+	45, // 13: transaction.DisputeResponse.evidence_files:type_name -> transaction.DisputeEvidenceFile
+	45, // 14: transaction.DisputeEvidenceFileResponse.file:type_name -> transaction.DisputeEvidenceFile
+	62, // 15: transaction.SubmitDisputeEvidenceRequest.evidence:type_name -> transaction.SubmitDisputeEvidenceRequest.EvidenceEntry
+	53, // 16: transaction.TransactionNoteResponse.note:type_name -> transaction.TransactionNote
+	53, // 17: transaction.ListTransactionNotesResponse.notes:type_name -> transaction.TransactionNote
+	0,  // 18: transaction.TransactionService.Authorize:input_type -> transaction.AuthorizeRequest
+	2,  // 19: transaction.TransactionService.Capture:input_type -> transaction.CaptureRequest
+	4,  // 20: transaction.TransactionService.ListCaptures:input_type -> transaction.ListCapturesRequest
+	7,  // 21: transaction.TransactionService.Void:input_type -> transaction.VoidRequest
+	9,  // 22: transaction.TransactionService.Refund:input_type -> transaction.RefundRequest
+	11, // 23: transaction.TransactionService.GetTransaction:input_type -> transaction.GetTransactionRequest
+	13, // 24: transaction.TransactionService.ListTransactions:input_type -> transaction.ListTransactionsRequest
+	15, // 25: transaction.TransactionService.GetSettlementBatch:input_type -> transaction.GetSettlementBatchRequest
+	17, // 26: transaction.TransactionService.ListSettlementBatches:input_type -> transaction.ListSettlementBatchesRequest
+	19, // 27: transaction.TransactionService.CreateInstantPayout:input_type -> transaction.CreateInstantPayoutRequest
+	21, // 28: transaction.TransactionService.GetBalance:input_type -> transaction.GetBalanceRequest
+	23, // 29: transaction.TransactionService.GetStatistics:input_type -> transaction.GetStatisticsRequest
+	27, // 30: transaction.TransactionService.GetRefund:input_type -> transaction.GetRefundRequest
+	28, // 31: transaction.TransactionService.ListRefunds:input_type -> transaction.ListRefundsRequest
+	31, // 32: transaction.TransactionService.ListMerchantRefunds:input_type -> transaction.ListMerchantRefundsRequest
+	33, // 33: transaction.TransactionService.GetRefundQueueStatus:input_type -> transaction.GetRefundQueueStatusRequest
+	35, // 34: transaction.TransactionService.GetTransactionTimeline:input_type -> transaction.GetTransactionTimelineRequest
+	39, // 35: transaction.TransactionService.Authenticate:input_type -> transaction.AuthenticateRequest
+	41, // 36: transaction.TransactionService.CompleteAuthentication:input_type -> transaction.CompleteAuthenticationRequest
+	52, // 37: transaction.TransactionService.AddTransactionNote:input_type -> transaction.AddTransactionNoteRequest
+	55, // 38: transaction.TransactionService.ListTransactionNotes:input_type -> transaction.ListTransactionNotesRequest
+	57, // 39: transaction.TransactionService.DeleteTransactionNote:input_type -> transaction.DeleteTransactionNoteRequest
+	59, // 40: transaction.TransactionService.AddTransactionTags:input_type -> transaction.AddTransactionTagsRequest
+	60, // 41: transaction.TransactionService.RemoveTransactionTag:input_type -> transaction.RemoveTransactionTagRequest
+	42, // 42: transaction.ChargebackService.ListDisputes:input_type -> transaction.ListDisputesRequest
+	44, // 43: transaction.ChargebackService.GetDispute:input_type -> transaction.GetDisputeRequest
+	47, // 44: transaction.ChargebackService.UploadDisputeEvidence:input_type -> transaction.UploadDisputeEvidenceRequest
+	48, // 45: transaction.ChargebackService.GetDisputeEvidenceFile:input_type -> transaction.GetDisputeEvidenceFileRequest
+	50, // 46: transaction.ChargebackService.SubmitDisputeEvidence:input_type -> transaction.SubmitDisputeEvidenceRequest
+	51, // 47: transaction.ChargebackService.AcceptDispute:input_type -> transaction.AcceptDisputeRequest
+	1,  // 48: transaction.TransactionService.Authorize:output_type -> transaction.AuthorizeResponse
+	3,  // 49: transaction.TransactionService.Capture:output_type -> transaction.CaptureResponse
+	6,  // 50: transaction.TransactionService.ListCaptures:output_type -> transaction.ListCapturesResponse
+	8,  // 51: transaction.TransactionService.Void:output_type -> transaction.VoidResponse
+	10, // 52: transaction.TransactionService.Refund:output_type -> transaction.RefundResponse
+	12, // 53: transaction.TransactionService.GetTransaction:output_type -> transaction.TransactionResponse
+	14, // 54: transaction.TransactionService.ListTransactions:output_type -> transaction.ListTransactionsResponse
+	16, // 55: transaction.TransactionService.GetSettlementBatch:output_type -> transaction.SettlementBatchResponse
+	18, // 56: transaction.TransactionService.ListSettlementBatches:output_type -> transaction.ListSettlementBatchesResponse
+	20, // 57: transaction.TransactionService.CreateInstantPayout:output_type -> transaction.InstantPayoutResponse
+	22, // 58: transaction.TransactionService.GetBalance:output_type -> transaction.BalanceResponse
+	26, // 59: transaction.TransactionService.GetStatistics:output_type -> transaction.StatisticsResponse
+	29, // 60: transaction.TransactionService.GetRefund:output_type -> transaction.RefundDetailResponse
+	30, // 61: transaction.TransactionService.ListRefunds:output_type -> transaction.ListRefundsResponse
+	32, // 62: transaction.TransactionService.ListMerchantRefunds:output_type -> transaction.ListMerchantRefundsResponse
+	34, // 63: transaction.TransactionService.GetRefundQueueStatus:output_type -> transaction.RefundQueueStatusResponse
+	38, // 64: transaction.TransactionService.GetTransactionTimeline:output_type -> transaction.TransactionTimelineResponse
+	40, // 65: transaction.TransactionService.Authenticate:output_type -> transaction.AuthenticateResponse
+	40, // 66: transaction.TransactionService.CompleteAuthentication:output_type -> transaction.AuthenticateResponse
+	54, // 67: transaction.TransactionService.AddTransactionNote:output_type -> transaction.TransactionNoteResponse
+	56, // 68: transaction.TransactionService.ListTransactionNotes:output_type -> transaction.ListTransactionNotesResponse
+	58, // 69: transaction.TransactionService.DeleteTransactionNote:output_type -> transaction.DeleteTransactionNoteResponse
+	61, // 70: transaction.TransactionService.AddTransactionTags:output_type -> transaction.TransactionTagsResponse
+	61, // 71: transaction.TransactionService.RemoveTransactionTag:output_type -> transaction.TransactionTagsResponse
+	43, // 72: transaction.ChargebackService.ListDisputes:output_type -> transaction.ListDisputesResponse
+	46, // 73: transaction.ChargebackService.GetDispute:output_type -> transaction.DisputeResponse
+	49, // 74: transaction.ChargebackService.UploadDisputeEvidence:output_type -> transaction.DisputeEvidenceFileResponse
+	49, // 75: transaction.ChargebackService.GetDisputeEvidenceFile:output_type -> transaction.DisputeEvidenceFileResponse
+	46, // 76: transaction.ChargebackService.SubmitDisputeEvidence:output_type -> transaction.DisputeResponse
+	46, // 77: transaction.ChargebackService.AcceptDispute:output_type -> transaction.DisputeResponse
+	48, // [48:78] is the sub-list for method output_type
+	18, // [18:48] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_proto_transaction_proto_init() }
@@ -1265,9 +5909,9 @@ func file_proto_transaction_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_transaction_proto_rawDesc), len(file_proto_transaction_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   63,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_transaction_proto_goTypes,
 		DependencyIndexes: file_proto_transaction_proto_depIdxs,